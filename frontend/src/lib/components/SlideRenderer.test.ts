@@ -112,6 +112,31 @@ describe('SlideRenderer', () => {
 			expect(slideEl?.getAttribute('style')).toContain('background-size: cover');
 		});
 
+		it('renders a muted video background behind the content', () => {
+			const slide = createSlide({
+				background: { type: 'video', value: '/local/media/loop.mp4', loop: true, muted: true }
+			});
+
+			const { container } = render(SlideRenderer, { props: { slide } });
+			const video = container.querySelector('video.slide-background-video') as HTMLVideoElement | null;
+
+			expect(video).toBeInTheDocument();
+			expect(video?.getAttribute('src')).toBe('/local/media/loop.mp4');
+			expect(video?.muted).toBe(true);
+			expect(video?.loop).toBe(true);
+			expect(container.querySelector('.slide-renderer')?.getAttribute('style') ?? '').not.toContain('loop.mp4');
+		});
+
+		it('does not render a video for other backgrounds', () => {
+			const slide = createSlide({
+				background: { type: 'image', value: '/images/bg.jpg' }
+			});
+
+			const { container } = render(SlideRenderer, { props: { slide } });
+
+			expect(container.querySelector('video')).not.toBeInTheDocument();
+		});
+
 		it('handles missing background gracefully', () => {
 			const slide = createSlide();
 			// No background property
@@ -124,6 +149,61 @@ describe('SlideRenderer', () => {
 		});
 	});
 
+	describe('style overrides', () => {
+		it('applies accent, text color, and padding as CSS custom properties', () => {
+			const slide = createSlide({
+				style: { accent: '#ff5500', textColor: 'white', padding: '40px' }
+			});
+
+			const { container } = render(SlideRenderer, { props: { slide } });
+			const style = container.querySelector('.slide-renderer')?.getAttribute('style') ?? '';
+
+			expect(style).toContain('--color-accent: #ff5500');
+			expect(style).toContain('--color-text: white');
+			expect(style).toContain('--slide-padding: 40px');
+		});
+
+		it('keeps the background when overriding styles', () => {
+			const slide = createSlide({
+				background: { type: 'color', value: '#000000' },
+				style: { accent: 'tomato' }
+			});
+
+			const { container } = render(SlideRenderer, { props: { slide } });
+			const style = container.querySelector('.slide-renderer')?.getAttribute('style') ?? '';
+
+			expect(style).toContain('background-color: #000000');
+			expect(style).toContain('--color-accent: tomato');
+		});
+
+		it('scales the content by fontScale', () => {
+			const slide = createSlide({ style: { fontScale: '1.5' } });
+
+			const { container } = render(SlideRenderer, { props: { slide } });
+			const content = container.querySelector('.slide-content');
+
+			expect(content).toHaveClass('auto-scaled');
+			expect(content?.getAttribute('style')).toContain('--font-scale: 1.5');
+		});
+
+		it('adds table classes for wide tables and the table style', () => {
+			const slide = createSlide({ wideTable: true, style: { tableStyle: 'striped' } });
+
+			const { container } = render(SlideRenderer, { props: { slide } });
+			const renderer = container.querySelector('.slide-renderer');
+
+			expect(renderer).toHaveClass('has-wide-table');
+			expect(renderer).toHaveClass('table-striped');
+		});
+
+		it('adds no table classes by default', () => {
+			const { container } = render(SlideRenderer, { props: { slide: createSlide() } });
+			const renderer = container.querySelector('.slide-renderer');
+
+			expect(renderer?.className).not.toMatch(/has-wide-table|table-/);
+		});
+	});
+
 	describe('fragment handling', () => {
 		it('adds has-fragments class when slide has fragments', () => {
 			const slide = createSlide({
@@ -172,6 +252,26 @@ describe('SlideRenderer', () => {
 			expect(fragmentElements[2]).toHaveClass('fragment-hidden');
 		});
 
+		it('keeps the heading of a slide with list item fragments', () => {
+			const slide = createSlide({
+				html:
+					'<h1>Agenda</h1>\n<ul>\n<li class="fragment fragment-hidden" data-fragment-index="0">One</li>\n' +
+					'<li class="fragment fragment-hidden" data-fragment-index="1">Two</li>\n</ul>',
+				fragments: [
+					{ index: 0, content: 'One', inline: true },
+					{ index: 1, content: 'Two', inline: true }
+				]
+			});
+
+			const { container } = render(SlideRenderer, {
+				props: { slide, visibleFragments: 0 }
+			});
+
+			expect(container.querySelector('h1')).toHaveTextContent('Agenda');
+			expect(container.querySelectorAll('li.fragment')).toHaveLength(2);
+			expect(container.querySelector('div.fragment')).not.toBeInTheDocument();
+		});
+
 		it('hides all fragments when visibleFragments is -1', () => {
 			const slide = createSlide({
 				fragments: [
@@ -271,6 +371,36 @@ describe('SlideRenderer', () => {
 		});
 	});
 
+	describe('embed', () => {
+		const embed = {
+			url: 'https://localhost:5173',
+			height: '80%',
+			allowFullscreen: true,
+			sandbox: ['allow-scripts', 'allow-forms']
+		};
+
+		it('renders the embedded page in a sandboxed iframe', () => {
+			const slide = createSlide({ layout: 'embed', embed });
+
+			const { container } = render(SlideRenderer, { props: { slide } });
+
+			const iframe = container.querySelector('iframe.embed-frame');
+			expect(iframe).toHaveAttribute('src', 'https://localhost:5173');
+			expect(iframe).toHaveAttribute('sandbox', 'allow-scripts allow-forms');
+			expect(iframe).toHaveAttribute('allowfullscreen');
+			expect(iframe?.getAttribute('style')).toContain('height: 80%');
+		});
+
+		it('renders a placeholder card with the URL in print mode', () => {
+			const slide = createSlide({ layout: 'embed', embed });
+
+			const { container } = render(SlideRenderer, { props: { slide, isPrintMode: true } });
+
+			expect(container.querySelector('iframe')).not.toBeInTheDocument();
+			expect(container.querySelector('.embed-placeholder')).toHaveTextContent('https://localhost:5173');
+		});
+	});
+
 	describe('reduced motion', () => {
 		it('respects reduced motion preference', () => {
 			// Mock reduced motion preference
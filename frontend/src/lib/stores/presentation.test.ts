@@ -20,7 +20,8 @@ import {
 	resetPresentation,
 	loadPresentation,
 	initializeFromURL,
-	setupHashChangeListener
+	setupHashChangeListener,
+	applySlidesUpdate
 } from './presentation';
 import type { Presentation } from '$lib/types';
 
@@ -387,6 +388,113 @@ describe('presentation store', () => {
 		});
 	});
 
+	describe('applySlidesUpdate', () => {
+		const slide = (index: number, text: string) => ({
+			index,
+			layout: 'default' as const,
+			html: `<p>${text}</p>`
+		});
+
+		it('should replace changed slides and keep the others', () => {
+			presentation.set(createTestPresentation(3));
+
+			const applied = applySlidesUpdate(
+				{ changed: [{ slide: slide(1, 'Edited'), index: 1, oldIndex: 1 }], total: 3 },
+				true
+			);
+
+			expect(applied).toBe(true);
+			expect(get(presentation)?.slides.map((s) => s.html)).toEqual([
+				'<p>Slide 1</p>',
+				'<p>Edited</p>',
+				'<p>Slide 3</p>'
+			]);
+		});
+
+		it('should shift kept slides around a slide inserted in the middle', () => {
+			presentation.set(createTestPresentation(4));
+
+			applySlidesUpdate({ added: [{ slide: slide(2, 'New'), index: 2, oldIndex: -1 }], total: 5 }, true);
+
+			const slides = get(presentation)?.slides ?? [];
+			expect(slides.map((s) => s.html)).toEqual([
+				'<p>Slide 1</p>',
+				'<p>Slide 2</p>',
+				'<p>New</p>',
+				'<p>Slide 3</p>',
+				'<p>Slide 4</p>'
+			]);
+			expect(slides.map((s) => s.index)).toEqual([0, 1, 2, 3, 4]);
+		});
+
+		it('should update the lines of kept slides', () => {
+			presentation.set(createTestPresentation(2));
+
+			applySlidesUpdate(
+				{
+					changed: [{ slide: { ...slide(0, 'Longer'), startLine: 1, endLine: 3 }, index: 0, oldIndex: 0 }],
+					total: 2,
+					lines: [
+						[1, 3],
+						[7, 9]
+					]
+				},
+				true
+			);
+
+			const slides = get(presentation)?.slides ?? [];
+			expect(slides.map((s) => [s.startLine, s.endLine])).toEqual([
+				[1, 3],
+				[7, 9]
+			]);
+		});
+
+		it('should keep the current slide and fragments when still valid', () => {
+			presentation.set(createTestPresentation(3, [2, 2, 2]));
+			currentSlideIndex.set(2);
+			currentFragmentIndex.set(1);
+
+			applySlidesUpdate(
+				{ changed: [{ slide: slide(0, 'Edited'), index: 0, oldIndex: 0 }], total: 3 },
+				true
+			);
+
+			expect(get(currentSlideIndex)).toBe(2);
+			expect(get(currentFragmentIndex)).toBe(1);
+		});
+
+		it('should follow the current slide when a slide is inserted before it', () => {
+			presentation.set(createTestPresentation(3, [2, 2, 2]));
+			currentSlideIndex.set(1);
+			currentFragmentIndex.set(1);
+
+			applySlidesUpdate({ added: [{ slide: slide(0, 'New'), index: 0, oldIndex: -1 }], total: 4 }, false);
+
+			expect(get(currentSlideIndex)).toBe(2);
+			expect(get(currentFragmentIndex)).toBe(-1);
+		});
+
+		it('should stay in range when the current slide is removed', () => {
+			presentation.set(createTestPresentation(3));
+			currentSlideIndex.set(2);
+
+			applySlidesUpdate({ removed: [2], total: 2 }, false);
+
+			expect(get(totalSlides)).toBe(2);
+			expect(get(currentSlideIndex)).toBe(1);
+		});
+
+		it('should reject updates that do not fit the presentation', () => {
+			presentation.set(createTestPresentation(3));
+
+			expect(applySlidesUpdate({ removed: [1], total: 3 }, true)).toBe(false);
+			expect(get(presentation)?.slides).toHaveLength(3);
+
+			presentation.set(null);
+			expect(applySlidesUpdate({ total: 0 }, true)).toBe(false);
+		});
+	});
+
 	describe('resetPresentation', () => {
 		it('should reset all stores to initial values', () => {
 			const testPresentation = createTestPresentation(5, [3, 3, 3, 3, 3]);
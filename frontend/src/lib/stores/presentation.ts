@@ -3,8 +3,8 @@
  * Uses Svelte 5 runes for reactive state management.
  */
 
-import { writable, derived, type Readable } from 'svelte/store';
-import type { Presentation, Slide, Theme } from '$lib/types';
+import { writable, derived, get, type Readable } from 'svelte/store';
+import type { Presentation, Slide, SlidesUpdate, Theme } from '$lib/types';
 
 // ============================================================================
 // Writable Stores
@@ -479,6 +479,63 @@ export function loadPresentation(data: Presentation): void {
 	}
 }
 
+/**
+ * Apply the slides that changed on reload to the loaded presentation.
+ * If currentSlideStillValid is set, the current slide and its revealed
+ * fragments are kept; otherwise the current slide follows its slide when that
+ * is unchanged, or stays at its index, and its fragments are reset.
+ * Returns false if the update doesn't fit the loaded presentation, in which
+ * case the page should be reloaded.
+ */
+export function applySlidesUpdate(update: SlidesUpdate, currentSlideStillValid: boolean): boolean {
+	const current = get(presentation);
+	if (!current) return false;
+	const oldSlides = current.slides;
+
+	// New slides by index, and the unchanged old slides in order
+	const replacements = new Map<number, Slide>();
+	const replaced = new Set<number>(update.removed ?? []);
+	for (const change of [...(update.changed ?? []), ...(update.added ?? [])]) {
+		replacements.set(change.index, change.slide);
+		if (change.oldIndex >= 0) {
+			replaced.add(change.oldIndex);
+		}
+	}
+	const kept = oldSlides.map((_, i) => i).filter((i) => !replaced.has(i));
+	if (kept.length + replacements.size !== update.total) return false;
+
+	const slides: Slide[] = [];
+	const newIndexOf = new Map<number, number>();
+	let next = 0;
+	for (let i = 0; i < update.total; i++) {
+		const slide = replacements.get(i);
+		if (slide) {
+			slides.push(slide);
+		} else {
+			// Unchanged slides may have moved in the markdown
+			const oldIndex = kept[next++];
+			const [startLine, endLine] = update.lines?.[i] ?? [
+				oldSlides[oldIndex].startLine,
+				oldSlides[oldIndex].endLine
+			];
+			slides.push({ ...oldSlides[oldIndex], index: i, startLine, endLine });
+			newIndexOf.set(oldIndex, i);
+		}
+	}
+
+	const updated: Presentation = { ...current, slides, toc: update.toc };
+	presentation.set(updated);
+	if (typeof window !== 'undefined') {
+		(window as unknown as { presentation: Presentation }).presentation = updated;
+	}
+
+	if (!currentSlideStillValid && slides.length > 0) {
+		const slideIndex = get(currentSlideIndex);
+		goToSlide(newIndexOf.get(slideIndex) ?? Math.min(slideIndex, slides.length - 1));
+	}
+	return true;
+}
+
 /**
  * Set the theme override from WebSocket message.
  * This temporarily overrides the theme without modifying the markdown file.
@@ -10,7 +10,12 @@ import {
 	WEBSOCKET_CONSTANTS,
 	getWebSocketClient,
 	connectWebSocket,
-	disconnectWebSocket
+	disconnectWebSocket,
+	presenterTokenFromLocation,
+	timerState,
+	autoplay,
+	annotations,
+	mergeAnnotations
 } from './websocket';
 import { presentation, currentSlideIndex, currentFragmentIndex } from './presentation';
 import type { Presentation, WebSocketMessage } from '$lib/types';
@@ -257,6 +262,64 @@ describe('WebSocketClient', () => {
 			unsubscribe();
 		});
 
+		it('should handle "slides" message by updating slides in place', () => {
+			const reloadSpy = vi.fn();
+			vi.stubGlobal('window', {
+				location: { protocol: 'http:', host: 'localhost:3000', reload: reloadSpy }
+			});
+			presentation.set({
+				config: {},
+				slides: [
+					{ index: 0, layout: 'default', html: '<p>Slide 1</p>' },
+					{ index: 1, layout: 'default', html: '<p>Slide 2</p>' }
+				]
+			});
+			currentSlideIndex.set(1);
+			currentFragmentIndex.set(0);
+
+			client.connect();
+			mockWs?.simulateOpen();
+			mockWs?.simulateMessage({
+				type: 'slides',
+				slides: {
+					changed: [
+						{ slide: { index: 0, layout: 'default', html: '<p>Edited</p>' }, index: 0, oldIndex: 0 }
+					],
+					total: 2
+				},
+				currentSlideStillValid: true
+			});
+
+			let slides: string[] = [];
+			const unsubscribe = presentation.subscribe((value) => {
+				slides = value?.slides.map((slide) => slide.html) ?? [];
+			});
+
+			expect(slides).toEqual(['<p>Edited</p>', '<p>Slide 2</p>']);
+			expect(reloadSpy).not.toHaveBeenCalled();
+			unsubscribe();
+
+			let fragmentIndex = -1;
+			currentFragmentIndex.subscribe((value) => {
+				fragmentIndex = value;
+			})();
+			expect(fragmentIndex).toBe(0);
+		});
+
+		it('should reload the page when a "slides" message does not fit', () => {
+			const reloadSpy = vi.fn();
+			vi.stubGlobal('window', {
+				location: { protocol: 'http:', host: 'localhost:3000', reload: reloadSpy }
+			});
+			presentation.set(null);
+
+			client.connect();
+			mockWs?.simulateOpen();
+			mockWs?.simulateMessage({ type: 'slides', slides: { total: 3 } });
+
+			expect(reloadSpy).toHaveBeenCalled();
+		});
+
 		it('should ignore "slide" message with undefined slideIndex', () => {
 			const testPresentation: Presentation = {
 				config: {},
@@ -299,6 +362,64 @@ describe('WebSocketClient', () => {
 			unsubscribe();
 		});
 
+		it('should handle "timer" message by updating the timer state', () => {
+			timerState.set(null);
+
+			client.connect();
+			mockWs?.simulateOpen();
+			mockWs?.simulateMessage({
+				type: 'timer',
+				timer: { running: true, elapsed: 90000, duration: 1800000 }
+			});
+
+			let state: unknown = null;
+			const unsubscribe = timerState.subscribe((value) => {
+				state = value;
+			});
+
+			expect(state).toMatchObject({ running: true, elapsed: 90000, duration: 1800000 });
+			unsubscribe();
+		});
+
+		it('should handle "autoplay" message by updating the autoplay state', () => {
+			autoplay.set(true);
+
+			client.connect();
+			mockWs?.simulateOpen();
+			mockWs?.simulateMessage({ type: 'autoplay', autoplay: false });
+
+			let state: unknown = null;
+			const unsubscribe = autoplay.subscribe((value) => {
+				state = value;
+			});
+			expect(state).toBe(false);
+
+			mockWs?.simulateMessage({ type: 'autoplay', autoplay: true });
+			expect(state).toBe(true);
+			unsubscribe();
+		});
+
+		it('should handle "annotate" and "clear-annotations" messages', () => {
+			annotations.set(null);
+
+			client.connect();
+			mockWs?.simulateOpen();
+			mockWs?.simulateMessage({
+				type: 'annotate',
+				annotations: { slideIndex: 2, strokes: [{ id: 's1', points: [[0.1, 0.2]] }] }
+			});
+
+			let state: unknown = null;
+			const unsubscribe = annotations.subscribe((value) => {
+				state = value;
+			});
+			expect(state).toMatchObject({ slideIndex: 2, strokes: [{ id: 's1' }] });
+
+			mockWs?.simulateMessage({ type: 'clear-annotations' });
+			expect(state).toBeNull();
+			unsubscribe();
+		});
+
 		it('should ignore invalid JSON messages', () => {
 			client.connect();
 			mockWs?.simulateOpen();
@@ -398,6 +519,49 @@ describe('WebSocketClient', () => {
 		});
 	});
 
+	describe('position reporting', () => {
+		it('should report the current slide when connected', () => {
+			currentSlideIndex.set(3);
+			client.connect();
+			const sendSpy = vi.spyOn(mockWs!, 'send');
+			mockWs?.simulateOpen();
+
+			expect(sendSpy).toHaveBeenCalledWith(JSON.stringify({ type: 'position', slideIndex: 3 }));
+		});
+
+		it('should report slide changes, including those from other clients', () => {
+			presentation.set({
+				config: {},
+				slides: [
+					{ index: 0, layout: 'default', html: '<p>Slide 1</p>' },
+					{ index: 1, layout: 'default', html: '<p>Slide 2</p>' },
+					{ index: 2, layout: 'default', html: '<p>Slide 3</p>' }
+				]
+			});
+			client.connect();
+			mockWs?.simulateOpen();
+			const sendSpy = vi.spyOn(mockWs!, 'send');
+
+			currentSlideIndex.set(1);
+			mockWs?.simulateMessage({ type: 'slide', slideIndex: 2 });
+
+			expect(sendSpy).toHaveBeenCalledWith(JSON.stringify({ type: 'position', slideIndex: 1 }));
+			expect(sendSpy).toHaveBeenCalledWith(JSON.stringify({ type: 'position', slideIndex: 2 }));
+		});
+
+		it('should stop reporting after disconnecting', () => {
+			client.connect();
+			mockWs?.simulateOpen();
+			const ws = mockWs!;
+			client.disconnect();
+			const sendSpy = vi.spyOn(ws, 'send');
+
+			currentSlideIndex.set(4);
+
+			expect(sendSpy).not.toHaveBeenCalled();
+		});
+	});
+
 	describe('isConnected', () => {
 		it('should return false when not connected', () => {
 			expect(client.isConnected()).toBe(false);
@@ -494,3 +658,49 @@ describe('WEBSOCKET_CONSTANTS', () => {
 		expect(WEBSOCKET_CONSTANTS.RECONNECT_BACKOFF_MULTIPLIER).toBe(2);
 	});
 });
+
+describe('presenterTokenFromLocation', () => {
+	it('should read the token query parameter', () => {
+		expect(presenterTokenFromLocation('/presenter', '?token=abc123')).toBe('abc123');
+	});
+
+	it('should read the older key query parameter', () => {
+		expect(presenterTokenFromLocation('/presenter', '?key=secret')).toBe('secret');
+	});
+
+	it('should read the token from the path', () => {
+		expect(presenterTokenFromLocation('/presenter/abc%20123', '')).toBe('abc 123');
+	});
+
+	it('should return an empty string without a token', () => {
+		expect(presenterTokenFromLocation('/presenter', '')).toBe('');
+	});
+});
+
+describe('mergeAnnotations', () => {
+	it('should start the annotations of a slide', () => {
+		const update = { slideIndex: 1, strokes: [{ id: 'a', points: [[0, 0]] as [number, number][] }] };
+		expect(mergeAnnotations(null, update)).toEqual(update);
+	});
+
+	it('should replace strokes with the same ID and add new ones', () => {
+		const current = { slideIndex: 1, strokes: [{ id: 'a', points: [[0, 0]] as [number, number][] }] };
+		const merged = mergeAnnotations(current, {
+			slideIndex: 1,
+			strokes: [
+				{ id: 'a', points: [[0, 0], [0.5, 0.5]] },
+				{ id: 'b', points: [[1, 1]] }
+			]
+		});
+		expect(merged.strokes.map((s) => s.id)).toEqual(['a', 'b']);
+		expect(merged.strokes[0].points).toHaveLength(2);
+		// The current annotations are not modified
+		expect(current.strokes[0].points).toHaveLength(1);
+	});
+
+	it('should drop the strokes of another slide', () => {
+		const current = { slideIndex: 1, strokes: [{ id: 'a', points: [[0, 0]] as [number, number][] }] };
+		const merged = mergeAnnotations(current, { slideIndex: 2, strokes: [{ id: 'b', points: [[1, 1]] }] });
+		expect(merged).toEqual({ slideIndex: 2, strokes: [{ id: 'b', points: [[1, 1]] }] });
+	});
+});
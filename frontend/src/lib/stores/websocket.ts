@@ -4,8 +4,14 @@
  */
 
 import { writable, type Writable, type Readable, derived } from 'svelte/store';
-import type { WebSocketMessage, Theme } from '$lib/types';
-import { goToSlide, presentation, currentSlideIndex, setThemeOverride } from '$lib/stores/presentation';
+import type { Annotations, WebSocketMessage, Theme, TimerState } from '$lib/types';
+import {
+	applySlidesUpdate,
+	goToSlide,
+	presentation,
+	currentSlideIndex,
+	setThemeOverride
+} from '$lib/stores/presentation';
 
 // ============================================================================
 // Constants
@@ -92,6 +98,46 @@ export const reconnecting: Writable<boolean> = writable(false);
  */
 export const reconnectAttempt: Writable<number> = writable(0);
 
+/**
+ * Talk timer state from the dev server, with the time it was received so a
+ * running timer can be advanced locally. Null until the server sends one,
+ * in which case the presenter view runs its own timer.
+ */
+export const timerState: Writable<(TimerState & { receivedAt: number }) | null> = writable(null);
+
+/**
+ * Whether slides with autoAdvance move on by themselves. The dev TUI's kiosk
+ * toggle turns it off and on.
+ */
+export const autoplay: Writable<boolean> = writable(true);
+
+/**
+ * Strokes drawn on a slide in the presenter view. The audience view gets them
+ * from the dev server, and the presenter view keeps its own here. Null when
+ * nothing is drawn.
+ */
+export const annotations: Writable<Annotations | null> = writable(null);
+
+/**
+ * Merge strokes into the annotations of a slide. Strokes replace the ones
+ * with the same ID, and strokes for another slide replace all of them.
+ */
+export function mergeAnnotations(current: Annotations | null, update: Annotations): Annotations {
+	if (!current || current.slideIndex !== update.slideIndex) {
+		return { slideIndex: update.slideIndex, strokes: [...update.strokes] };
+	}
+	const strokes = [...current.strokes];
+	for (const stroke of update.strokes) {
+		const index = strokes.findIndex((s) => s.id === stroke.id);
+		if (index >= 0) {
+			strokes[index] = stroke;
+		} else {
+			strokes.push(stroke);
+		}
+	}
+	return { slideIndex: current.slideIndex, strokes };
+}
+
 /**
  * Whether live code execution is available.
  * This is true when connected to a WebSocket server (not in static mode).
@@ -115,6 +161,7 @@ export class WebSocketClient {
 	private reconnectTimeout: ReturnType<typeof setTimeout> | null = null;
 	private shouldReconnect: boolean = true;
 	private url: string;
+	private unsubscribePosition: (() => void) | null = null;
 
 	constructor(url?: string) {
 		// Default to current host with /ws path
@@ -123,13 +170,26 @@ export class WebSocketClient {
 
 	/**
 	 * Get the default WebSocket URL based on current location.
+	 * The presenter view connects as a presenter with its token, so the server
+	 * can disconnect it when the token is regenerated.
 	 */
 	private getDefaultURL(): string {
 		if (typeof window === 'undefined') {
 			return 'ws://localhost:3000/ws';
 		}
 		const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:';
-		return `${protocol}//${window.location.host}/ws`;
+		const url = `${protocol}//${window.location.host}/ws`;
+
+		const pathname = window.location.pathname ?? '';
+		if (pathname !== '/presenter' && !pathname.startsWith('/presenter/')) {
+			return url;
+		}
+		const params = new URLSearchParams({ role: 'presenter' });
+		const token = presenterTokenFromLocation(pathname, window.location.search ?? '');
+		if (token) {
+			params.set('token', token);
+		}
+		return `${url}?${params.toString()}`;
 	}
 
 	/**
@@ -162,10 +222,12 @@ export class WebSocketClient {
 			reconnectAttempt.set(0);
 			// Reset reconnect delay on successful connection
 			this.reconnectDelay = INITIAL_RECONNECT_DELAY;
+			this.reportPosition();
 		};
 
 		this.ws.onclose = () => {
 			connected.set(false);
+			this.stopReportingPosition();
 			this.ws = null;
 			this.scheduleReconnect();
 		};
@@ -205,6 +267,16 @@ export class WebSocketClient {
 				this.handleReload();
 				break;
 
+			case 'slides':
+				// Slides changed - update them in place, or reload if they don't fit
+				if (
+					!message.slides ||
+					!applySlidesUpdate(message.slides, message.currentSlideStillValid ?? false)
+				) {
+					this.handleReload();
+				}
+				break;
+
 			case 'slide':
 				// Sync to specific slide
 				this.handleSlideNavigation(message.slideIndex);
@@ -214,6 +286,58 @@ export class WebSocketClient {
 				// Switch to a different theme
 				this.handleThemeChange(message.theme);
 				break;
+
+			case 'timer':
+				// Follow the talk timer controlled from the terminal
+				if (message.timer) {
+					timerState.set({ ...message.timer, receivedAt: Date.now() });
+				}
+				break;
+
+			case 'annotate':
+				// Strokes drawn in the presenter view
+				if (message.annotations) {
+					const update = message.annotations;
+					annotations.update((current) => mergeAnnotations(current, update));
+				}
+				break;
+
+			case 'clear-annotations':
+				annotations.set(null);
+				break;
+
+			case 'autoplay':
+				// Kiosk toggle from the terminal
+				autoplay.set(message.autoplay !== false);
+				break;
+
+			case 'revoked':
+				// Presenter token was regenerated - stop reconnecting and reload,
+				// which shows that this URL no longer grants access
+				this.disconnect();
+				this.handleReload();
+				break;
+		}
+	}
+
+	/**
+	 * Report the slide this client shows now and whenever it changes, so the
+	 * dev TUI can follow the talk. The server is not asked to broadcast it.
+	 */
+	private reportPosition(): void {
+		this.stopReportingPosition();
+		this.unsubscribePosition = currentSlideIndex.subscribe((slideIndex) => {
+			this.send({ type: 'position', slideIndex });
+		});
+	}
+
+	/**
+	 * Stop reporting the slide this client shows.
+	 */
+	private stopReportingPosition(): void {
+		if (this.unsubscribePosition) {
+			this.unsubscribePosition();
+			this.unsubscribePosition = null;
 		}
 	}
 
@@ -259,9 +383,8 @@ export class WebSocketClient {
 	private handleThemeChange(theme: string | undefined): void {
 		if (!theme) return;
 
-		// Validate theme is one of the known themes
-		const validThemes = ['paper', 'noir', 'aurora', 'phosphor', 'poster'];
-		if (validThemes.includes(theme)) {
+		// Accept built-in and custom theme names (used in the theme-<name> class)
+		if (/^[a-z0-9][a-z0-9-]*$/.test(theme)) {
 			setThemeOverride(theme as Theme);
 		}
 	}
@@ -299,6 +422,7 @@ export class WebSocketClient {
 			this.reconnectTimeout = null;
 		}
 
+		this.stopReportingPosition();
 		if (this.ws) {
 			this.ws.close();
 			this.ws = null;
@@ -340,6 +464,20 @@ export class WebSocketClient {
 	}
 }
 
+/**
+ * Get the presenter token from the presenter view's location: the ?token=
+ * query parameter, the older ?key= parameter, or the /presenter/<token> path.
+ */
+export function presenterTokenFromLocation(pathname: string, search: string): string {
+	const params = new URLSearchParams(search);
+	const token = params.get('token') || params.get('key');
+	if (token) {
+		return token;
+	}
+	const match = pathname.match(/^\/presenter\/([^/]+)/);
+	return match ? decodeURIComponent(match[1]) : '';
+}
+
 // ============================================================================
 // Singleton Instance
 // ============================================================================
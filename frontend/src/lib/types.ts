@@ -25,7 +25,9 @@ export type Layout =
 	| 'cover'
 	| 'sidebar'
 	| 'split-media'
-	| 'blank';
+	| 'image-focus'
+	| 'blank'
+	| 'embed';
 
 // ============================================================================
 // Transition Types
@@ -36,6 +38,23 @@ export type Layout =
  */
 export type Transition = 'none' | 'fade' | 'slide' | 'push' | 'zoom';
 
+/**
+ * Direction slides move when advancing with the slide and push transitions.
+ */
+export type TransitionMovement = 'left' | 'right' | 'up' | 'down';
+
+/**
+ * Resolved transition into a slide. Going back plays it in reverse.
+ * Matches Go's config.TransitionSpec struct.
+ */
+export interface TransitionSpec {
+	name: Transition;
+	/** Direction slides move when advancing (default: left) */
+	direction?: TransitionMovement;
+	/** Duration in milliseconds (default: the presentation's transitionDuration) */
+	duration?: number;
+}
+
 // ============================================================================
 // Theme Types
 // ============================================================================
@@ -68,25 +87,11 @@ export const DEFAULT_THEME: Theme = 'paper';
 // Config Types (matches internal/config/config.go)
 // ============================================================================
 
-/**
- * Connection configuration for a driver.
- * Matches Go's ConnectionConfig struct.
- */
-export interface ConnectionConfig {
-	host?: string;
-	user?: string;
-	password?: string;
-	database?: string;
-	path?: string;
-	port?: number;
-}
-
 /**
  * Driver configuration for code execution.
- * Matches Go's DriverConfig struct.
+ * Matches Go's DriverConfig struct. Connections are never sent to the browser.
  */
 export interface DriverConfig {
-	connections?: Record<string, ConnectionConfig>;
 	command?: string;
 	args?: string[];
 	timeout?: number;
@@ -128,8 +133,12 @@ export interface PresentationConfig {
 	transitionDuration?: number;
 	codeTheme?: string;
 	fragments?: boolean;
+	/** Whether raw HTML such as scripts and iframes is kept in slides (default: false) */
+	allowHTML?: boolean;
 	/** Whether to show the progress bar (default: true) */
 	showProgressBar?: boolean;
+	/** Whether auto-play wraps from the last slide to the first */
+	loop?: boolean;
 }
 
 // ============================================================================
@@ -180,7 +189,25 @@ export interface MapConfig {
  */
 export interface BackgroundConfig {
 	value: string;
-	type: 'color' | 'image' | 'gradient';
+	type: 'color' | 'image' | 'gradient' | 'video';
+	/** Whether a video background loops */
+	loop?: boolean;
+	/** Whether a video background is muted (true unless the directive sets muted: false) */
+	muted?: boolean;
+}
+
+/**
+ * Web page shown in an iframe on embed slides.
+ * Matches Go's EmbedConfig struct.
+ */
+export interface EmbedConfig {
+	url: string;
+	/** CSS height of the frame, such as "90%"; the rest of the slide if unset */
+	height?: string;
+	/** Whether the page can switch to fullscreen */
+	allowFullscreen?: boolean;
+	/** Sandbox flags of the iframe; empty applies every restriction */
+	sandbox: string[];
 }
 
 /**
@@ -192,6 +219,38 @@ export interface CodeBlock {
 	code: string;
 	driver?: string;
 	connection?: string;
+	highlight?: number[];
+	/** Whether the block can be run with the run API (tap dev --allow-exec) */
+	runnable?: boolean;
+	/** How the block is rendered instead of as highlighted code ('mermaid' for diagrams, 'cast' for terminal recordings) */
+	render?: 'mermaid' | 'cast';
+	/** Recording and playback options of cast blocks */
+	cast?: Cast;
+}
+
+/**
+ * The number a big-stat slide is about, such as "42%".
+ * Matches Go's transformer.Stat struct.
+ */
+export interface Stat {
+	value: string;
+	/** The line of text explaining the number */
+	caption?: string;
+}
+
+/**
+ * Terminal recording of a cast block, played with the asciinema player.
+ * Matches Go's transformer.Cast struct.
+ */
+export interface Cast {
+	/** URL of the .cast file */
+	src?: string;
+	/** Inline asciinema v2 recording */
+	data?: string;
+	autoplay?: boolean;
+	loop?: boolean;
+	/** Seconds into the recording to start at, and the frame shown in print mode */
+	startAt?: number;
 }
 
 /**
@@ -201,8 +260,32 @@ export interface CodeBlock {
 export interface FragmentGroup {
 	content: string;
 	index: number;
+	/** A list item of a fragments: true slide, revealed in place in the slide HTML; content is a copy of the item */
+	inline?: boolean;
 }
 
+/**
+ * Style overrides set by a slide's directives.
+ * Values are validated by the Go transformer.
+ */
+export interface SlideStyle {
+	/** Accent color, applied as --color-accent */
+	accent?: string;
+	/** Content scale, from 0.5 to 3 */
+	fontScale?: string;
+	/** Text color, applied as --color-text */
+	textColor?: string;
+	/** Slide padding, such as "40px" or "2rem 4rem" */
+	padding?: string;
+	/** Look of the slide's tables, applied as a table-<style> class */
+	tableStyle?: TableStyle;
+}
+
+/**
+ * Table styles a slide can set with the tableStyle directive.
+ */
+export type TableStyle = 'compact' | 'striped' | 'minimal';
+
 /**
  * Slide ready for frontend rendering.
  * Matches Go's TransformedSlide struct.
@@ -211,25 +294,74 @@ export interface Slide {
 	index: number;
 	layout: Layout;
 	html: string;
+	/** Speaker notes as written, in markdown */
 	notes?: string;
+	/** Speaker notes rendered and sanitized like the slide HTML */
+	notesHTML?: string;
+	/** @deprecated The name of transitionSpec, kept for one release */
 	transition?: Transition;
+	/** Resolved transition into the slide */
+	transitionSpec?: TransitionSpec;
 	fragments?: FragmentGroup[];
 	background?: BackgroundConfig;
 	codeBlocks?: CodeBlock[];
+	/** HTML of each column for column layouts, split at the ||| separator */
+	columns?: string[];
+	/** Source of the image on image-focus slides */
+	imageSrc?: string;
 	/** Decorative metadata label (e.g., "// workshop") */
 	tag?: string;
 	/** Decorative metadata badge (e.g., "v2.0") */
 	badge?: string;
+	/** CSS classes for the slide's wrapper, from the class directive */
+	classes?: string[];
 	/** Enable scroll reveal for long content */
 	scroll?: boolean;
 	/** Animation duration in milliseconds (default: 2000) */
 	scrollSpeed?: number;
+	/** Omitted from PDF exports (hidden: true or skip: true directive) */
+	hidden?: boolean;
+	/** Milliseconds after which auto-play moves to the next slide */
+	autoAdvance?: number;
+	/** The number on big-stat slides, and its caption */
+	stat?: Stat;
+	/** Page shown on embed slides */
+	embed?: EmbedConfig;
+	/** Estimated size of the slide's content */
+	contentScore?: number;
+	/** Content is estimated not to fit on the slide */
+	overflow?: boolean;
+	/** Suggested scale for overflowing content to fit (e.g., 0.8) */
+	fontScale?: number;
+	/** A table is estimated too wide for the slide */
+	wideTable?: boolean;
+	/** Style overrides from the slide's directives */
+	style?: SlideStyle;
+	/** One-based line of the slide's first non-blank line in the markdown */
+	startLine?: number;
+	/** One-based line of the slide's last non-blank line in the markdown */
+	endLine?: number;
 }
 
 // ============================================================================
 // Presentation Types
 // ============================================================================
 
+/**
+ * Table of contents entry for a slide with an h1 or h2 heading.
+ * Matches Go's TOCEntry struct.
+ */
+export interface TOCEntry {
+	slideIndex: number;
+	title: string;
+	/** 1 for h1, 2 for h2 */
+	level: number;
+	/** True for section slides, which start a chapter */
+	section?: boolean;
+	/** Slides in the chapter started by a section slide */
+	children?: TOCEntry[];
+}
+
 /**
  * Complete presentation data from the backend.
  * Matches Go's TransformedPresentation struct.
@@ -237,6 +369,12 @@ export interface Slide {
 export interface Presentation {
 	config: PresentationConfig;
 	slides: Slide[];
+	/** Table of contents; slides without a heading are omitted */
+	toc?: TOCEntry[];
+	/** Slide index of each heading ID, for links to headings on other slides */
+	anchors?: Record<string, number>;
+	/** Custom theme name to stylesheet URL, for themes in the presentation's themes directory */
+	themes?: Record<string, string>;
 }
 
 // ============================================================================
@@ -246,7 +384,18 @@ export interface Presentation {
 /**
  * WebSocket message types for hot reload and sync.
  */
-export type WebSocketMessageType = 'connected' | 'reload' | 'slide' | 'theme';
+export type WebSocketMessageType =
+	| 'connected'
+	| 'reload'
+	| 'slide'
+	| 'slides'
+	| 'theme'
+	| 'timer'
+	| 'revoked'
+	| 'annotate'
+	| 'clear-annotations'
+	| 'autoplay'
+	| 'position';
 
 /**
  * WebSocket message from the server.
@@ -256,6 +405,82 @@ export interface WebSocketMessage {
 	slideIndex?: number;
 	/** Theme name for theme switching messages */
 	theme?: string;
+	/** Talk timer state for timer messages */
+	timer?: TimerState;
+	/** Changed slides for slides messages */
+	slides?: SlidesUpdate;
+	/** Whether the slide the presentation is on is unchanged at the same index, for slides messages */
+	currentSlideStillValid?: boolean;
+	/** Strokes drawn in the presenter view, for annotate messages */
+	annotations?: Annotations;
+	/** Whether slides with autoAdvance move on by themselves, for autoplay messages */
+	autoplay?: boolean;
+}
+
+/**
+ * A slide that changed or was added on reload.
+ */
+export interface SlideChange {
+	slide: Slide;
+	/** Index in the new presentation */
+	index: number;
+	/** Index in the old presentation; -1 for added slides */
+	oldIndex: number;
+}
+
+/**
+ * The slides that changed when the dev server reloaded the presentation.
+ * Matches Go's slidediff.Diff struct. Slides that are neither changed nor
+ * removed are kept, in order, at the indices not taken by changed and added
+ * slides.
+ */
+export interface SlidesUpdate {
+	changed?: SlideChange[];
+	added?: SlideChange[];
+	/** Indices in the old presentation */
+	removed?: number[];
+	/** Table of contents of the new presentation */
+	toc?: TOCEntry[];
+	/** Number of slides in the new presentation */
+	total: number;
+	/** Start and end line of each slide in the new presentation */
+	lines?: [number, number][];
+}
+
+/**
+ * State of the talk timer, controlled from the dev server's terminal UI.
+ * Times are in milliseconds.
+ */
+export interface TimerState {
+	/** Whether the timer is running */
+	running: boolean;
+	/** Elapsed time when the state was sent */
+	elapsed: number;
+	/** Target length of the talk, from the duration frontmatter option */
+	duration?: number;
+}
+
+/**
+ * A line drawn on a slide in the presenter view. Matches Go's server.Stroke
+ * struct. A newer version of a stroke, with the same ID, replaces the older.
+ */
+export interface Stroke {
+	id: string;
+	/** Pen strokes stay until the slide changes; laser strokes fade out */
+	tool?: 'pen' | 'laser';
+	color?: string;
+	/** Line width in pixels */
+	width?: number;
+	/** x, y pairs relative to the slide's size, from 0 to 1 */
+	points: [number, number][];
+}
+
+/**
+ * Strokes drawn on a slide. Matches Go's server.Annotations struct.
+ */
+export interface Annotations {
+	slideIndex: number;
+	strokes: Stroke[];
 }
 
 // ============================================================================
@@ -263,12 +488,13 @@ export interface WebSocketMessage {
 // ============================================================================
 
 /**
- * Request body for code execution API.
+ * Request body for the run API, which runs a code block of the presentation.
  */
-export interface ExecuteRequest {
-	driver: string;
-	code: string;
-	connection?: string;
+export interface RunRequest {
+	/** 0-based slide index */
+	slide: number;
+	/** 0-based code block index within the slide */
+	block: number;
 }
 
 /**
@@ -279,4 +505,6 @@ export interface ExecuteResponse {
 	output?: string;
 	error?: string;
 	data?: Record<string, unknown>[];
+	/** Whether output or data was cut off at the server's size limits */
+	truncated?: boolean;
 }
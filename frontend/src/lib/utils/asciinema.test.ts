@@ -0,0 +1,51 @@
+import { describe, it, expect } from 'vitest';
+import { castPlayerOptions, castSource } from './asciinema';
+
+describe('castPlayerOptions', () => {
+	it('passes the playback options to the player', () => {
+		const options = castPlayerOptions({ src: '/local/demo.cast', autoplay: true, loop: true, startAt: 12 }, false);
+
+		expect(options.autoPlay).toBe(true);
+		expect(options.loop).toBe(true);
+		expect(options.startAt).toBe(12);
+		expect(options.controls).toBe(true);
+		expect(options.poster).toBeUndefined();
+	});
+
+	it('defaults to a paused player without looping', () => {
+		const options = castPlayerOptions({ src: '/local/demo.cast' }, false);
+
+		expect(options.autoPlay).toBe(false);
+		expect(options.loop).toBe(false);
+		expect(options.startAt).toBeUndefined();
+	});
+
+	it('shows the frame at startAt in print mode instead of playing', () => {
+		const options = castPlayerOptions({ src: '/local/demo.cast', autoplay: true, loop: true, startAt: 12.5 }, true);
+
+		expect(options.autoPlay).toBe(false);
+		expect(options.loop).toBe(false);
+		expect(options.controls).toBe(false);
+		expect(options.poster).toBe('npt:12.5');
+	});
+
+	it('shows the first frame in print mode without startAt', () => {
+		expect(castPlayerOptions({ src: '/local/demo.cast' }, true).poster).toBe('npt:0');
+	});
+});
+
+describe('castSource', () => {
+	it('prefers the inline recording', () => {
+		const data = '{"version": 2, "width": 80, "height": 24}\n[0.5, "o", "hi"]\n';
+
+		expect(castSource({ data, src: '/local/demo.cast' })).toEqual({ data });
+	});
+
+	it('uses the file URL without an inline recording', () => {
+		expect(castSource({ src: 'assets/demo.1a2b3c4d.cast' })).toBe('assets/demo.1a2b3c4d.cast');
+	});
+
+	it('returns null without a recording', () => {
+		expect(castSource({})).toBeNull();
+	});
+});
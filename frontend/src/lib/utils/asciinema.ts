@@ -1,9 +1,11 @@
 /**
  * Asciinema player utilities for rendering terminal recordings in slides.
- * Finds <pre><code class="language-asciinema"> blocks and replaces them
- * with interactive asciinema-player instances.
+ * Finds <pre><code class="language-asciinema"> and <pre><code class="language-cast">
+ * blocks and replaces them with interactive asciinema-player instances.
  */
 
+import type { Cast } from '$lib/types';
+
 /** CDN URLs for asciinema-player */
 const ASCIINEMA_PLAYER_JS =
 	'https://cdn.jsdelivr.net/npm/asciinema-player@3.9.0/dist/bundle/asciinema-player.min.js';
@@ -277,3 +279,130 @@ export async function renderAsciinemaBlocksInElement(element: HTMLElement): Prom
 		}
 	}
 }
+
+/** How long print mode waits for a player to show its frame before moving on */
+const CAST_FRAME_TIMEOUT = 3000;
+
+/**
+ * Build the asciinema-player options for a cast block. In print mode the
+ * recording isn't played; the player shows the frame at startAt instead.
+ */
+export function castPlayerOptions(cast: Cast, printMode: boolean): Record<string, unknown> {
+	const options: Record<string, unknown> = {
+		autoPlay: printMode ? false : (cast.autoplay ?? false),
+		loop: printMode ? false : (cast.loop ?? false),
+		preload: true,
+		fit: 'width',
+		controls: !printMode,
+		theme: 'monokai'
+	};
+
+	if (cast.startAt) options.startAt = cast.startAt;
+	if (printMode) options.poster = `npt:${cast.startAt ?? 0}`;
+
+	return options;
+}
+
+/**
+ * The source asciinema-player loads a cast block's recording from: the
+ * inline recording, or the URL of its .cast file.
+ */
+export function castSource(cast: Cast): string | { data: string } | null {
+	if (cast.data) return { data: cast.data };
+	return cast.src || null;
+}
+
+/**
+ * Create the wrapper shown in place of a cast block that can't be played.
+ */
+function castError(message: string): HTMLElement {
+	const errorDiv = document.createElement('div');
+	errorDiv.className = 'asciinema-player-wrapper error';
+	errorDiv.dataset.cast = '';
+	errorDiv.dataset.castReady = '';
+	errorDiv.innerHTML =
+		'<div class="error-state">' +
+		'<span class="error-icon">\u26A0</span>' +
+		'<span class="error-text"></span>' +
+		'</div>';
+	errorDiv.querySelector('.error-text')!.textContent = message;
+	return errorDiv;
+}
+
+/**
+ * Mark a cast player ready once its terminal is in the page, so the PDF
+ * exporter knows the frame is shown. Gives up after CAST_FRAME_TIMEOUT.
+ */
+function markReadyWhenShown(wrapper: HTMLElement): void {
+	const started = Date.now();
+	const check = () => {
+		if (wrapper.querySelector('.ap-terminal') || Date.now() - started > CAST_FRAME_TIMEOUT) {
+			requestAnimationFrame(() => {
+				wrapper.dataset.castReady = '';
+			});
+			return;
+		}
+		setTimeout(check, 50);
+	};
+	check();
+}
+
+/**
+ * Find and render all cast blocks within an element. Replaces
+ * <pre><code class="language-cast"> blocks with asciinema players, playing
+ * the recording of the cast at the same position in casts.
+ */
+export async function renderCastBlocksInElement(
+	element: HTMLElement,
+	casts: Cast[],
+	printMode = false
+): Promise<void> {
+	const codeBlocks = element.querySelectorAll<HTMLElement>('pre > code.language-cast');
+
+	if (codeBlocks.length === 0) {
+		return;
+	}
+
+	try {
+		await loadLibrary();
+	} catch (err) {
+		console.error('Failed to load asciinema-player library:', err);
+		codeBlocks.forEach((codeBlock) => {
+			codeBlock.parentElement?.replaceWith(castError('Failed to load asciinema player library'));
+		});
+		return;
+	}
+
+	const AsciinemaPlayer = (window as any).AsciinemaPlayer;
+	if (!AsciinemaPlayer) return;
+
+	Array.from(codeBlocks).forEach((codeBlock, i) => {
+		const pre = codeBlock.parentElement;
+		if (!pre) return;
+
+		const cast = casts[i] ?? {};
+		const source = castSource(cast);
+		if (!source) {
+			pre.replaceWith(castError('Missing recording: write asciinema v2 JSON or a .cast file path in the block'));
+			return;
+		}
+
+		const wrapper = document.createElement('div');
+		wrapper.className = 'asciinema-player-wrapper';
+		wrapper.dataset.cast = '';
+
+		const playerContainer = document.createElement('div');
+		playerContainer.className = 'player-container';
+		wrapper.appendChild(playerContainer);
+		pre.replaceWith(wrapper);
+
+		try {
+			AsciinemaPlayer.create(source, playerContainer, castPlayerOptions(cast, printMode));
+			markReadyWhenShown(wrapper);
+		} catch (err) {
+			wrapper.replaceWith(
+				castError(`Failed to create asciinema player: ${err instanceof Error ? err.message : 'Unknown error'}`)
+			);
+		}
+	});
+}
@@ -0,0 +1,65 @@
+/**
+ * Unit tests for links between slides.
+ */
+
+import { describe, it, expect, vi, beforeEach, afterEach } from 'vitest';
+import { setupSlideLinks, slideLinkTarget } from './links';
+import * as presentationStore from '$lib/stores/presentation';
+
+vi.mock('$lib/stores/presentation', () => ({
+	goToSlide: vi.fn(() => true)
+}));
+
+describe('slide links', () => {
+	let cleanup: () => void;
+
+	beforeEach(() => {
+		vi.clearAllMocks();
+		document.body.innerHTML =
+			'<p><a id="internal" href="#benchmarks" data-goto-slide="3"><code>benchmarks</code></a>' +
+			'<a id="external" href="https://example.com/#benchmarks">site</a></p>';
+	});
+
+	afterEach(() => {
+		cleanup?.();
+		document.body.innerHTML = '';
+	});
+
+	describe('slideLinkTarget', () => {
+		it('returns the slide index of a link to another slide', () => {
+			expect(slideLinkTarget(document.querySelector('#internal code'))).toBe(3);
+		});
+
+		it('returns null for other links', () => {
+			expect(slideLinkTarget(document.getElementById('external'))).toBeNull();
+			expect(slideLinkTarget(null)).toBeNull();
+		});
+	});
+
+	describe('setupSlideLinks', () => {
+		it('goes to the slide of the heading and calls onNavigate', () => {
+			const onNavigate = vi.fn();
+			cleanup = setupSlideLinks({ onNavigate });
+
+			const event = new MouseEvent('click', { bubbles: true, cancelable: true, button: 0 });
+			document.querySelector('#internal code')!.dispatchEvent(event);
+
+			expect(presentationStore.goToSlide).toHaveBeenCalledWith(3);
+			expect(onNavigate).toHaveBeenCalled();
+			expect(event.defaultPrevented).toBe(true);
+		});
+
+		it('leaves external links and modified clicks alone', () => {
+			cleanup = setupSlideLinks();
+
+			const external = new MouseEvent('click', { bubbles: true, cancelable: true, button: 0 });
+			document.getElementById('external')!.dispatchEvent(external);
+			const modified = new MouseEvent('click', { bubbles: true, cancelable: true, button: 0, metaKey: true });
+			document.getElementById('internal')!.dispatchEvent(modified);
+
+			expect(presentationStore.goToSlide).not.toHaveBeenCalled();
+			expect(external.defaultPrevented).toBe(false);
+			expect(modified.defaultPrevented).toBe(false);
+		});
+	});
+});
@@ -0,0 +1,65 @@
+/**
+ * Navigation for links between slides.
+ *
+ * Links to a heading on another slide, like [see the benchmarks](#benchmarks),
+ * carry a data-goto-slide attribute with the index of the slide the heading
+ * is on. Clicking one goes to that slide instead of changing the URL hash.
+ */
+
+import { goToSlide } from '$lib/stores/presentation';
+
+/**
+ * Options for slide link navigation.
+ */
+export interface SlideLinkOptions {
+	/** Called after a link moved to another slide */
+	onNavigate?: () => void;
+}
+
+/**
+ * Return the index of the slide a click on target should go to, or null if
+ * target is not inside a link to another slide.
+ */
+export function slideLinkTarget(target: EventTarget | null): number | null {
+	if (!(target instanceof Element)) {
+		return null;
+	}
+	const link = target.closest('a[data-goto-slide]');
+	if (!link) {
+		return null;
+	}
+	const index = Number(link.getAttribute('data-goto-slide'));
+	return Number.isInteger(index) && index >= 0 ? index : null;
+}
+
+/**
+ * Set up click handling for links to headings on other slides.
+ * Returns a cleanup function that removes the listener.
+ */
+export function setupSlideLinks(options: SlideLinkOptions = {}): () => void {
+	if (typeof document === 'undefined') {
+		return () => {};
+	}
+
+	function handleClick(event: MouseEvent): void {
+		// Let modified clicks open the link as the browser would
+		const modified = event.metaKey || event.ctrlKey || event.shiftKey || event.altKey;
+		if (event.defaultPrevented || event.button !== 0 || modified) {
+			return;
+		}
+		const index = slideLinkTarget(event.target);
+		if (index === null) {
+			return;
+		}
+		event.preventDefault();
+		if (goToSlide(index)) {
+			options.onNavigate?.();
+		}
+	}
+
+	document.addEventListener('click', handleClick);
+
+	return () => {
+		document.removeEventListener('click', handleClick);
+	};
+}
@@ -0,0 +1,61 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest';
+import { mount, unmount } from 'svelte';
+import { mountLiveCodeBlocks, unmountLiveCodeBlocks } from './liveCode';
+import type { CodeBlock } from '$lib/types';
+
+vi.mock('svelte', async (importOriginal) => ({
+	...(await importOriginal<typeof import('svelte')>()),
+	mount: vi.fn(() => ({})),
+	unmount: vi.fn()
+}));
+
+describe('mountLiveCodeBlocks', () => {
+	let element: HTMLElement;
+
+	beforeEach(() => {
+		vi.clearAllMocks();
+		element = document.createElement('div');
+		element.innerHTML =
+			'<pre><code class="language-bash">echo hi\n</code></pre>' +
+			'<pre><code class="language-go">fmt.Println()</code></pre>' +
+			'<pre><code class="language-bash">echo hi\n</code></pre>';
+	});
+
+	const blocks: CodeBlock[] = [
+		{ language: 'bash', code: 'echo hi' },
+		{ language: 'go', code: 'fmt.Println()' },
+		{ language: 'bash', code: 'echo hi', driver: 'shell', runnable: true }
+	];
+
+	it('mounts a LiveCodeBlock for each block with a driver, with its slide and block index', () => {
+		const mounts = mountLiveCodeBlocks(element, blocks, 3, 'paper');
+
+		expect(mounts).toHaveLength(1);
+		expect(mount).toHaveBeenCalledTimes(1);
+		const props = vi.mocked(mount).mock.calls[0]![1].props as Record<string, unknown>;
+		expect(props.slideIndex).toBe(3);
+		expect(props.blockIndex).toBe(2);
+		expect(props.codeBlock).toBe(blocks[2]);
+
+		// The third <pre> is replaced; the first block with the same code is left alone
+		const children = Array.from(element.children);
+		expect(children[0]!.tagName).toBe('PRE');
+		expect(children[2]!.className).toBe('live-code-mount');
+	});
+
+	it('mounts nothing for slides without driver blocks', () => {
+		expect(mountLiveCodeBlocks(element, blocks.slice(0, 2), 0)).toEqual([]);
+		expect(mountLiveCodeBlocks(element, undefined, 0)).toEqual([]);
+		expect(mount).not.toHaveBeenCalled();
+	});
+
+	it('puts back the code blocks when unmounted', () => {
+		const mounts = mountLiveCodeBlocks(element, blocks, 0);
+		unmountLiveCodeBlocks(mounts);
+
+		expect(unmount).toHaveBeenCalledTimes(1);
+		expect(mounts).toHaveLength(0);
+		expect(element.querySelectorAll('pre')).toHaveLength(3);
+		expect(element.querySelector('.live-code-mount')).toBeNull();
+	});
+});
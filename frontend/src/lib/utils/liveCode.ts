@@ -0,0 +1,76 @@
+/**
+ * Live code utilities. Code blocks with a driver are rendered by the
+ * LiveCodeBlock component, which runs them with the run API, instead of as
+ * plain highlighted code.
+ */
+
+import { mount, unmount } from 'svelte';
+import type { CodeBlock } from '$lib/types';
+import LiveCodeBlock from '$lib/components/LiveCodeBlock.svelte';
+import { getShikiTheme } from '$lib/utils/highlighting';
+
+/** A mounted LiveCodeBlock and the <pre> it replaced */
+export interface LiveCodeMount {
+	component: ReturnType<typeof mount>;
+	pre: HTMLElement;
+	target: HTMLElement;
+}
+
+/**
+ * Find the <pre> of each code block with a driver in the slide's HTML,
+ * matching blocks to the rendered code in order, and replace it with a
+ * LiveCodeBlock for the block. Returns the mounted components, to be passed
+ * to unmountLiveCodeBlocks when the slide changes.
+ */
+export function mountLiveCodeBlocks(
+	element: HTMLElement,
+	codeBlocks: CodeBlock[] | undefined,
+	slideIndex: number,
+	tapTheme?: string
+): LiveCodeMount[] {
+	const mounts: LiveCodeMount[] = [];
+	if (!codeBlocks?.some((block) => block.driver)) {
+		return mounts;
+	}
+
+	const claimed = new Set<HTMLElement>();
+	const rendered = Array.from(element.querySelectorAll<HTMLElement>('pre > code'));
+
+	codeBlocks.forEach((codeBlock, blockIndex) => {
+		// Blocks without a driver are matched too, so blocks with the same code
+		// are matched in order
+		const code = rendered.find(
+			(el) => !claimed.has(el) && (el.textContent ?? '').trimEnd() === codeBlock.code.trimEnd()
+		);
+		if (!code) return;
+		claimed.add(code);
+
+		const pre = code.parentElement;
+		if (!codeBlock.driver || !pre) return;
+
+		const target = document.createElement('div');
+		target.className = 'live-code-mount';
+		pre.replaceWith(target);
+		const component = mount(LiveCodeBlock, {
+			target,
+			props: { codeBlock, slideIndex, blockIndex, theme: getShikiTheme(tapTheme) }
+		});
+		mounts.push({ component, pre, target });
+	});
+
+	return mounts;
+}
+
+/**
+ * Unmount LiveCodeBlocks mounted by mountLiveCodeBlocks and put back the
+ * <pre> each replaced, so they can be mounted again, such as with another
+ * theme. Blocks whose slide HTML was replaced in the meantime are only
+ * unmounted.
+ */
+export function unmountLiveCodeBlocks(mounts: LiveCodeMount[]): void {
+	for (const { component, pre, target } of mounts) {
+		unmount(component);
+		target.replaceWith(pre);
+	}
+	mounts.length = 0;
+}
@@ -316,6 +316,8 @@ export async function renderMermaidDiagram(
  * Find and render all mermaid code blocks within an element.
  * Replaces <pre><code class="language-mermaid"> blocks with rendered SVGs.
  * Also re-renders existing mermaid diagrams when theme changes.
+ * Rendered diagrams and errors get the mermaid class and a data-processed
+ * attribute, which the PDF export waits for.
  *
  * @param element The DOM element to search within
  * @param theme Optional tap theme to use for styling diagrams
@@ -355,7 +357,8 @@ export async function renderMermaidBlocksInElement(
     if (result.success) {
       // Create container for the rendered diagram, storing the code for re-rendering
       const container = document.createElement('div')
-      container.className = 'mermaid-diagram'
+      container.className = 'mermaid mermaid-diagram'
+      container.dataset.processed = 'true'
       container.dataset.mermaidCode = code
       container.dataset.mermaidTheme = theme ?? ''
       container.innerHTML = result.svg
@@ -365,7 +368,8 @@ export async function renderMermaidBlocksInElement(
     } else {
       // Show error message, storing the code for potential re-render
       const errorContainer = document.createElement('div')
-      errorContainer.className = 'mermaid-error'
+      errorContainer.className = 'mermaid mermaid-error'
+      errorContainer.dataset.processed = 'true'
       errorContainer.dataset.mermaidCode = code
       errorContainer.dataset.mermaidTheme = theme ?? ''
       errorContainer.innerHTML = `
@@ -396,7 +400,8 @@ export async function renderMermaidBlocksInElement(
     } else {
       // Convert to error container
       const errorContainer = document.createElement('div')
-      errorContainer.className = 'mermaid-error'
+      errorContainer.className = 'mermaid mermaid-error'
+      errorContainer.dataset.processed = 'true'
       errorContainer.dataset.mermaidCode = code
       errorContainer.dataset.mermaidTheme = theme ?? ''
       errorContainer.innerHTML = `
@@ -422,7 +427,8 @@ export async function renderMermaidBlocksInElement(
     if (result.success) {
       // Convert to successful diagram
       const container = document.createElement('div')
-      container.className = 'mermaid-diagram'
+      container.className = 'mermaid mermaid-diagram'
+      container.dataset.processed = 'true'
       container.dataset.mermaidCode = code
       container.dataset.mermaidTheme = theme ?? ''
       container.innerHTML = result.svg
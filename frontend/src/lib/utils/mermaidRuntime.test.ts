@@ -0,0 +1,88 @@
+/**
+ * Unit tests for the mermaid runtime loader.
+ */
+
+import { describe, it, expect, vi, beforeEach } from 'vitest'
+import {
+  loadMermaidRuntime,
+  renderMermaidDiagrams,
+  resetMermaidRuntime,
+  MERMAID_READY_EVENT,
+  MERMAID_RUNTIME_JS,
+  type MermaidRuntime,
+} from './mermaidRuntime'
+
+function setRuntime(runtime: MermaidRuntime) {
+  ;(window as unknown as { __tapMermaid: MermaidRuntime }).__tapMermaid = runtime
+}
+
+describe('loadMermaidRuntime', () => {
+  beforeEach(() => {
+    resetMermaidRuntime()
+    document.head.querySelectorAll('script').forEach((script) => script.remove())
+  })
+
+  it('uses a runtime that is already loaded without adding a script', async () => {
+    const runtime = { renderMermaidBlocksInElement: vi.fn() }
+    setRuntime(runtime)
+
+    expect(await loadMermaidRuntime()).toBe(runtime)
+    expect(document.querySelector(`script[src="${MERMAID_RUNTIME_JS}"]`)).toBeNull()
+  })
+
+  it('adds the runtime script once and resolves when it is ready', async () => {
+    const first = loadMermaidRuntime()
+    const second = loadMermaidRuntime()
+    expect(document.querySelectorAll(`script[src="${MERMAID_RUNTIME_JS}"]`)).toHaveLength(1)
+
+    const runtime = { renderMermaidBlocksInElement: vi.fn() }
+    setRuntime(runtime)
+    window.dispatchEvent(new Event(MERMAID_READY_EVENT))
+
+    expect(await first).toBe(runtime)
+    expect(await second).toBe(runtime)
+  })
+
+  it('waits for a runtime script already in the page', async () => {
+    const script = document.createElement('script')
+    script.src = MERMAID_RUNTIME_JS
+    document.head.appendChild(script)
+
+    const loading = loadMermaidRuntime()
+    expect(document.querySelectorAll(`script[src="${MERMAID_RUNTIME_JS}"]`)).toHaveLength(1)
+
+    setRuntime({ renderMermaidBlocksInElement: vi.fn() })
+    window.dispatchEvent(new Event(MERMAID_READY_EVENT))
+    await expect(loading).resolves.toBeDefined()
+  })
+
+  it('waits for a runtime script inlined by a single-file build', async () => {
+    const script = document.createElement('script')
+    script.type = 'module'
+    script.setAttribute('data-tap-mermaid', '')
+    document.head.appendChild(script)
+
+    const loading = loadMermaidRuntime()
+    expect(document.querySelector(`script[src="${MERMAID_RUNTIME_JS}"]`)).toBeNull()
+    expect(document.querySelectorAll('script')).toHaveLength(1)
+
+    setRuntime({ renderMermaidBlocksInElement: vi.fn() })
+    window.dispatchEvent(new Event(MERMAID_READY_EVENT))
+    await expect(loading).resolves.toBeDefined()
+  })
+})
+
+describe('renderMermaidDiagrams', () => {
+  beforeEach(() => {
+    resetMermaidRuntime()
+  })
+
+  it('renders the diagrams with the runtime', async () => {
+    const runtime = { renderMermaidBlocksInElement: vi.fn().mockResolvedValue(undefined) }
+    setRuntime(runtime)
+    const element = document.createElement('div')
+
+    await renderMermaidDiagrams(element, 'paper')
+    expect(runtime.renderMermaidBlocksInElement).toHaveBeenCalledWith(element, 'paper')
+  })
+})
@@ -0,0 +1,80 @@
+/**
+ * Loader for the mermaid runtime, which is built as a separate script so
+ * decks without diagrams don't load it. Built decks with diagrams include the
+ * script in the page; elsewhere it is loaded on first use.
+ */
+import type { Theme } from '$lib/types'
+
+/** URL of the mermaid runtime script */
+export const MERMAID_RUNTIME_JS = '/assets/mermaid.js'
+
+/**
+ * Selector for the runtime script. Builds mark the script with
+ * data-tap-mermaid, which single-file builds keep when they inline it.
+ */
+const MERMAID_SCRIPT_SELECTOR = `script[data-tap-mermaid], script[src="${MERMAID_RUNTIME_JS}"]`
+
+/** Event dispatched on window once the runtime script has run */
+export const MERMAID_READY_EVENT = 'tap:mermaid-ready'
+
+/** Functions exposed by the mermaid runtime on window.__tapMermaid */
+export interface MermaidRuntime {
+  renderMermaidBlocksInElement(element: HTMLElement, theme?: Theme): Promise<void>
+}
+
+/** Pending or completed load of the runtime */
+let runtimePromise: Promise<MermaidRuntime> | undefined
+
+/**
+ * Get the runtime from window, if its script has run.
+ */
+function loadedRuntime(): MermaidRuntime | undefined {
+  return (window as unknown as { __tapMermaid?: MermaidRuntime }).__tapMermaid
+}
+
+/**
+ * Load the mermaid runtime, adding its script to the page unless it is
+ * already there.
+ */
+export function loadMermaidRuntime(): Promise<MermaidRuntime> {
+  const runtime = loadedRuntime()
+  if (runtime) {
+    return Promise.resolve(runtime)
+  }
+  if (runtimePromise) {
+    return runtimePromise
+  }
+
+  runtimePromise = new Promise<MermaidRuntime>((resolve, reject) => {
+    window.addEventListener(MERMAID_READY_EVENT, () => resolve(loadedRuntime()!), { once: true })
+
+    if (!document.querySelector(MERMAID_SCRIPT_SELECTOR)) {
+      const script = document.createElement('script')
+      script.type = 'module'
+      script.dataset.tapMermaid = ''
+      script.src = MERMAID_RUNTIME_JS
+      script.onerror = () => {
+        runtimePromise = undefined
+        reject(new Error('Failed to load the mermaid runtime'))
+      }
+      document.head.appendChild(script)
+    }
+  })
+  return runtimePromise
+}
+
+/**
+ * Render the mermaid diagrams in an element, loading the runtime first.
+ */
+export async function renderMermaidDiagrams(element: HTMLElement, theme?: Theme): Promise<void> {
+  const runtime = await loadMermaidRuntime()
+  await runtime.renderMermaidBlocksInElement(element, theme)
+}
+
+/**
+ * Reset the loader (primarily for testing).
+ */
+export function resetMermaidRuntime(): void {
+  runtimePromise = undefined
+  delete (window as unknown as { __tapMermaid?: MermaidRuntime }).__tapMermaid
+}
@@ -0,0 +1,24 @@
+import { describe, it, expect } from 'vitest';
+import { markStatBlocks } from './stat';
+
+describe('markStatBlocks', () => {
+	it('marks the number and caption', () => {
+		const html = '<h1 id="42">42%</h1>\n<p>of developers use Go daily</p>';
+
+		expect(markStatBlocks(html, { value: '42%', caption: 'of developers use Go daily' })).toBe(
+			'<h1 class="stat-value" id="42">42%</h1>\n<p class="stat-caption">of developers use Go daily</p>'
+		);
+	});
+
+	it('marks a number written as a paragraph', () => {
+		expect(markStatBlocks('<p><strong>$1.2M</strong></p>', { value: '$1.2M' })).toBe(
+			'<p class="stat-value"><strong>$1.2M</strong></p>'
+		);
+	});
+
+	it('leaves slides without a stat unchanged', () => {
+		const html = '<h1>42%</h1>\n<p>of developers</p>';
+
+		expect(markStatBlocks(html, undefined)).toBe(html);
+	});
+});
@@ -0,0 +1,32 @@
+/**
+ * Big-stat utilities. The transformer detects slides that are a single
+ * number with an optional caption and names the number in slide.stat; these
+ * helpers mark the number and caption in the slide HTML so themes can style
+ * them, whichever heading or paragraph they were written as.
+ */
+
+import type { Stat } from '$lib/types';
+
+/** Matches the opening tag of a heading or paragraph */
+const BLOCK_OPEN_PATTERN = /<(h[1-6]|p)(\s[^>]*)?>/g;
+
+/**
+ * Add the stat-value class to the first block of a big-stat slide's HTML,
+ * and stat-caption to the second if the stat has a caption. HTML of slides
+ * without a stat is returned unchanged.
+ */
+export function markStatBlocks(html: string, stat: Stat | undefined): string {
+	if (!stat) {
+		return html;
+	}
+
+	const classes = stat.caption ? ['stat-value', 'stat-caption'] : ['stat-value'];
+	let index = 0;
+	return html.replace(BLOCK_OPEN_PATTERN, (tag, name: string, attrs: string | undefined) => {
+		const className = classes[index++];
+		if (!className) {
+			return tag;
+		}
+		return `<${name} class="${className}"${attrs ?? ''}>`;
+	});
+}
@@ -0,0 +1,20 @@
+import { describe, it, expect } from 'vitest';
+import { getFlyOffset } from './transitions';
+
+describe('getFlyOffset', () => {
+	it('enters from the right when moving left, the default', () => {
+		expect(getFlyOffset(100)).toEqual({ x: 100, y: 0 });
+		expect(getFlyOffset(100, 'left', 'forward')).toEqual({ x: 100, y: 0 });
+	});
+
+	it('reverses when going back', () => {
+		expect(getFlyOffset(100, 'left', 'backward')).toEqual({ x: -100, y: 0 });
+		expect(getFlyOffset(50, 'up', 'backward')).toEqual({ x: 0, y: -50 });
+	});
+
+	it('supports every direction', () => {
+		expect(getFlyOffset(100, 'right')).toEqual({ x: -100, y: 0 });
+		expect(getFlyOffset(100, 'up')).toEqual({ x: 0, y: 100 });
+		expect(getFlyOffset(100, 'down')).toEqual({ x: 0, y: -100 });
+	});
+});
@@ -5,14 +5,14 @@
  * Supports 5 transition types:
  * - none: Instant transition (no animation)
  * - fade: Opacity crossfade (default)
- * - slide: Horizontal slide in/out
- * - push: Horizontal slide with slight overlap
+ * - slide: Slide in/out, to the left by default
+ * - push: Slide with slight overlap, to the left by default
  * - zoom: Scale in/out
  */
 
 import { fade, fly, scale, crossfade } from 'svelte/transition';
 import type { TransitionConfig as SvelteTransitionConfig } from 'svelte/transition';
-import type { Transition } from '$lib/types';
+import type { Transition, TransitionMovement } from '$lib/types';
 
 // ============================================================================
 // Types
@@ -33,6 +33,8 @@ export interface TransitionOptions {
 	duration?: number;
 	/** Direction for directional transitions */
 	direction?: TransitionDirection;
+	/** Direction slides move when advancing with slide and push (default: left) */
+	movement?: TransitionMovement;
 	/** Delay before transition starts in milliseconds */
 	delay?: number;
 }
@@ -161,7 +163,31 @@ export function transitionFade(
 }
 
 /**
- * Create a "slide" transition (horizontal slide in/out).
+ * Get the offset a slide enters from for the slide and push transitions.
+ * Slides move in the movement direction when advancing and the opposite
+ * way when going back.
+ */
+export function getFlyOffset(
+	distance: number,
+	movement: TransitionMovement = 'left',
+	direction: TransitionDirection = 'forward'
+): { x: number; y: number } {
+	const sign = direction === 'forward' ? 1 : -1;
+	switch (movement) {
+		case 'right':
+			return { x: -distance * sign, y: 0 };
+		case 'up':
+			return { x: 0, y: distance * sign };
+		case 'down':
+			return { x: 0, y: -distance * sign };
+		case 'left':
+		default:
+			return { x: distance * sign, y: 0 };
+	}
+}
+
+/**
+ * Create a "slide" transition (slide in/out).
  */
 export function transitionSlide(
 	node: Element,
@@ -171,16 +197,14 @@ export function transitionSlide(
 		options.duration ?? TRANSITION_DEFAULTS.defaultDuration
 	);
 	const delay = options.delay ?? 0;
-	const direction = options.direction ?? 'forward';
-
-	// Slide from right when going forward, from left when going backward
-	const x = direction === 'forward' ? 100 : -100;
+	// Enter from the side the slides move away from, reversed when going back
+	const { x, y } = getFlyOffset(100, options.movement, options.direction);
 
-	return fly(node, { x, duration, delay });
+	return fly(node, { x, y, duration, delay });
 }
 
 /**
- * Create a "push" transition (horizontal slide with opacity).
+ * Create a "push" transition (slide with opacity).
  * Similar to slide but with a softer overlap effect.
  */
 export function transitionPush(
@@ -191,12 +215,10 @@ export function transitionPush(
 		options.duration ?? TRANSITION_DEFAULTS.defaultDuration
 	);
 	const delay = options.delay ?? 0;
-	const direction = options.direction ?? 'forward';
-
 	// Smaller distance than slide for push effect
-	const x = direction === 'forward' ? 50 : -50;
+	const { x, y } = getFlyOffset(50, options.movement, options.direction);
 
-	return fly(node, { x, duration, delay, opacity: 0.5 });
+	return fly(node, { x, y, duration, delay, opacity: 0.5 });
 }
 
 /**
@@ -357,7 +379,7 @@ export function getTransitionDescription(type: Transition): string {
 		case 'fade':
 			return 'Crossfade (opacity)';
 		case 'slide':
-			return 'Slide horizontally';
+			return 'Slide in a direction';
 		case 'push':
 			return 'Push with overlap';
 		case 'zoom':
@@ -70,10 +70,6 @@ import '@fontsource/plus-jakarta-sans/800.css'
 
 import './app.css'
 import App from './App.svelte'
-import { initializeMermaid } from '$lib/utils/mermaid'
-
-// Initialize mermaid for diagram rendering (startOnLoad: false for manual control)
-initializeMermaid()
 
 const app = mount(App, {
   target: document.getElementById('app')!,
@@ -0,0 +1,12 @@
+/**
+ * Mermaid runtime entry point, built as assets/mermaid.js.
+ * Mermaid is large, so it is kept out of the main bundle: decks with mermaid
+ * diagrams load this script (see lib/utils/mermaidRuntime.ts), and built decks
+ * without diagrams don't ship it.
+ */
+import { renderMermaidBlocksInElement } from '$lib/utils/mermaid'
+import { MERMAID_READY_EVENT, type MermaidRuntime } from '$lib/utils/mermaidRuntime'
+
+const runtime: MermaidRuntime = { renderMermaidBlocksInElement }
+;(window as unknown as { __tapMermaid: MermaidRuntime }).__tapMermaid = runtime
+window.dispatchEvent(new Event(MERMAID_READY_EVENT))
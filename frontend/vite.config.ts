@@ -91,6 +91,8 @@ export default defineConfig({
       input: {
         main: resolve(__dirname, 'index.html'),
         presenter: resolve(__dirname, 'presenter.html'),
+        // Mermaid runtime, loaded only by decks with diagrams
+        mermaid: resolve(__dirname, 'src/mermaid.ts'),
       },
       output: {
         // Single JS and CSS file output
@@ -2,14 +2,16 @@
 package builder
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
+	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,31 +21,57 @@ import (
 	"github.com/MiniCodeMonkey/tap/internal/transformer"
 )
 
+// DefaultMaxVideoSize is the default size above which a background video
+// produces a build warning. The video is still copied.
+const DefaultMaxVideoSize = 50 * 1024 * 1024 // 50MB
+
 // BuildResult contains statistics about the completed build.
 type BuildResult struct {
-	OutputDir string        // Output directory path
-	BuildTime time.Duration // Total build duration
-	FileCount int           // Number of files generated
-	TotalSize int64         // Total size of all files in bytes
+	OutputDir string        `json:"outputDir"`   // Output directory path
+	BuildTime time.Duration `json:"buildTimeNs"` // Total build duration
+	FileCount int           `json:"fileCount"`   // Number of files generated
+	TotalSize int64         `json:"totalSize"`   // Total size of all files in bytes
+	Warnings  []string      `json:"warnings"`    // Non-fatal issues encountered during the build
+
+	// Incremental build statistics. FilesCopied and FilesSkipped add up to FileCount.
+	FilesCopied  int `json:"filesCopied"`  // Files written by this build
+	FilesSkipped int `json:"filesSkipped"` // Files left as the previous build wrote them
+	FilesPruned  int `json:"filesPruned"`  // Files from the previous build that were removed
+
+	Offline bool `json:"offline"` // Whether a service worker was written for offline use
+
+	ManifestPath string `json:"manifestPath"` // Path of the asset manifest; empty for single-file builds
 }
 
 // Builder generates static files from a tap presentation.
 type Builder struct {
-	outputDir string
-	baseDir   string // Base directory for resolving relative paths
+	outputDir  string
+	baseDir    string // Base directory for resolving relative paths
+	singleFile bool   // Inline all assets into a single index.html
+	multiPage  bool   // Write one HTML page per slide
+	offline    bool   // Write a service worker that precaches the build
+	force      bool   // Rewrite every file, ignoring the previous build's manifest
+
+	maxVideoSize int64 // Size above which background videos produce a warning; 0 disables the warning
+
+	// Manifests of the previous and current build, set while Build runs
+	prevManifest *manifest
+	manifest     *manifest
 }
 
 // New creates a new Builder with the default output directory "dist".
 func New() *Builder {
 	return &Builder{
-		outputDir: "dist",
+		outputDir:    "dist",
+		maxVideoSize: DefaultMaxVideoSize,
 	}
 }
 
 // NewWithOutput creates a new Builder with a custom output directory.
 func NewWithOutput(outputDir string) *Builder {
 	return &Builder{
-		outputDir: outputDir,
+		outputDir:    outputDir,
+		maxVideoSize: DefaultMaxVideoSize,
 	}
 }
 
@@ -57,6 +85,12 @@ func (b *Builder) SetOutputDir(outputDir string) {
 	b.outputDir = outputDir
 }
 
+// SetMaxVideoSize sets the size in bytes above which a background video
+// produces a build warning. Zero disables the warning. Default is 50MB.
+func (b *Builder) SetMaxVideoSize(size int64) {
+	b.maxVideoSize = size
+}
+
 // OutputDir returns the configured output directory.
 func (b *Builder) OutputDir() string {
 	return b.outputDir
@@ -65,8 +99,28 @@ func (b *Builder) OutputDir() string {
 // Build generates static files for the given presentation.
 // It copies the embedded Vite-built frontend (JS, CSS, fonts) and creates
 // an index.html with the presentation JSON embedded, so themes render correctly.
+//
+// When single-file mode is enabled, a single self-contained index.html is
+// written instead (see SetSingleFile). When multi-page mode is enabled, each
+// slide gets its own page (see SetMultiPage). When offline mode is enabled,
+// a service worker precaching the build is written too (see SetOffline).
+//
+// Builds are incremental: a manifest in the output directory records the
+// files each build wrote, so files that haven't changed since the previous
+// build are not written again, and files that are no longer part of the build
+// are removed. Use SetForce to rewrite every file.
 func (b *Builder) Build(cfg *config.Config, pres *parser.Presentation) (*BuildResult, error) {
 	startTime := time.Now()
+	if b.singleFile && b.multiPage {
+		return nil, fmt.Errorf("single-file and multi-page builds cannot be combined")
+	}
+	if b.singleFile && b.offline {
+		return nil, fmt.Errorf("single-file and offline builds cannot be combined")
+	}
+	if b.singleFile {
+		return b.buildSingleFile(cfg, pres, startTime)
+	}
+
 	result := &BuildResult{
 		OutputDir: b.outputDir,
 	}
@@ -81,23 +135,83 @@ func (b *Builder) Build(cfg *config.Config, pres *parser.Presentation) (*BuildRe
 		return nil, fmt.Errorf("failed to create assets directory: %w", err)
 	}
 
-	// Copy embedded frontend assets (JS, CSS, fonts) for proper theme rendering
-	assetCount, assetSize, err := b.CopyEmbeddedAssets()
-	if err != nil {
-		return nil, fmt.Errorf("failed to copy frontend assets: %w", err)
-	}
-	result.FileCount += assetCount
-	result.TotalSize += assetSize
+	b.prevManifest = b.loadManifest()
+	b.manifest = newManifest()
+	defer func() {
+		b.prevManifest, b.manifest = nil, nil
+	}()
 
 	// Transform presentation to frontend-ready format
 	trans := transformer.NewWithBaseDir(cfg, b.baseDir)
 	transformed := trans.Transform(pres)
+	result.Warnings = append(result.Warnings, trans.Warnings(pres)...)
+	if numbers := transformer.OverflowingSlides(transformed); len(numbers) > 0 {
+		result.Warnings = append(result.Warnings, overflowWarning(numbers))
+	}
+
+	// Copy embedded frontend assets (JS, CSS, fonts) for proper theme rendering
+	if err := b.copyEmbeddedAssets(result, transformer.UsesMermaid(transformed)); err != nil {
+		return nil, fmt.Errorf("failed to copy frontend assets: %w", err)
+	}
+
+	// Copy referenced assets once and rewrite the slides to point at them
+	b.copyReferencedAssets(transformed, assetsDir, result)
+	meta := pageMeta(transformed.Config, filepath.ToSlash(b.copyPreviewImage(transformed.Config, assetsDir, result)))
+
+	// In multi-page mode, write one page per slide and an index listing them
+	if b.multiPage {
+		if err := b.writeSlidePages(transformed, meta, result); err != nil {
+			return nil, err
+		}
+	} else {
+		// Generate index.html with embedded presentation JSON
+		page, err := b.renderPageHTML(transformed, meta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate index.html: %w", err)
+		}
+		if err := b.writeOutput("page:index.html", "index.html", []byte(page), result); err != nil {
+			return nil, fmt.Errorf("failed to generate index.html: %w", err)
+		}
+	}
+
+	// Precache everything written so far, so the build works offline
+	if b.offline {
+		if err := b.writeServiceWorker(result); err != nil {
+			return nil, fmt.Errorf("failed to generate service worker: %w", err)
+		}
+	}
+
+	// List every file with its integrity hash for deploy pipelines
+	if err := b.writeAssetManifest(result); err != nil {
+		return nil, fmt.Errorf("failed to generate asset manifest: %w", err)
+	}
+
+	// Remove files from the previous build that are no longer written
+	pruned, err := b.pruneOutputs()
+	if err != nil {
+		return nil, err
+	}
+	result.FilesPruned = pruned
 
+	if err := b.saveManifest(b.manifest); err != nil {
+		return nil, err
+	}
+
+	result.BuildTime = time.Since(startTime)
+	return result, nil
+}
+
+// copyReferencedAssets copies the images, including those in speaker notes,
+// .cast files, background images and videos, and theme stylesheets referenced by the
+// presentation into assetsDir, and rewrites the presentation to use the
+// copied paths. Each asset is copied once, however
+// many slides reference it. Missing backgrounds and themes add build warnings.
+func (b *Builder) copyReferencedAssets(transformed *transformer.TransformedPresentation, assetsDir string, result *BuildResult) {
 	// Find and copy all referenced images, building a path mapping
 	pathMapping := make(map[string]string)
 	for i := range transformed.Slides {
 		slide := &transformed.Slides[i]
-		images := extractImagePaths(slide.HTML)
+		images := extractImagePaths(slide.HTML + slide.NotesHTML)
 		for _, imgPath := range images {
 			if _, exists := pathMapping[imgPath]; exists {
 				continue // Already processed
@@ -122,15 +236,13 @@ func (b *Builder) Build(cfg *config.Config, pres *parser.Presentation) (*BuildRe
 			}
 
 			// Copy the image with content hash
-			hashedPath, size, err := b.copyWithHash(sourcePath, assetsDir)
+			hashedPath, err := b.copyAsset(sourcePath, assetsDir, result)
 			if err != nil {
 				// Skip images that can't be found (might be external URLs or invalid)
 				continue
 			}
 
 			pathMapping[imgPath] = hashedPath
-			result.TotalSize += size
-			result.FileCount++
 		}
 	}
 
@@ -156,35 +268,60 @@ func (b *Builder) Build(cfg *config.Config, pres *parser.Presentation) (*BuildRe
 				sourcePath = filepath.Join(b.baseDir, resolvedPath)
 			}
 
-			hashedPath, size, err := b.copyWithHash(sourcePath, assetsDir)
+			hashedPath, err := b.copyAsset(sourcePath, assetsDir, result)
 			if err != nil {
 				continue
 			}
 
 			pathMapping[castPath] = hashedPath
-			result.TotalSize += size
-			result.FileCount++
 		}
 	}
+	b.copyCastFiles(transformed, assetsDir, pathMapping, result)
 
-	// Rewrite image and asciinema paths in transformed slides
+	// Copy background images and videos set via slide directives
 	for i := range transformed.Slides {
-		slide := &transformed.Slides[i]
-		slide.HTML = rewriteImagePaths(slide.HTML, pathMapping)
-		slide.HTML = rewriteAsciinemaPaths(slide.HTML, pathMapping)
+		bg := transformed.Slides[i].Background
+		if !isLocalBackgroundFile(bg) {
+			continue
+		}
+		if hashedPath, exists := pathMapping[bg.Value]; exists {
+			bg.Value = hashedPath
+			continue
+		}
+
+		sourcePath := b.resolveSourcePath(bg.Value)
+		hashedPath, err := b.copyAsset(sourcePath, assetsDir, result)
+		if err != nil {
+			result.Warnings = append(result.Warnings, backgroundWarning(i, bg))
+			continue
+		}
+		if bg.Type == "video" && b.maxVideoSize > 0 {
+			if info, err := os.Stat(sourcePath); err == nil && info.Size() > b.maxVideoSize {
+				result.Warnings = append(result.Warnings, videoSizeWarning(i, bg.Value, info.Size(), b.maxVideoSize))
+			}
+		}
+
+		pathMapping[bg.Value] = hashedPath
+		bg.Value = hashedPath
 	}
 
-	// Generate index.html with embedded presentation JSON
-	indexPath := filepath.Join(b.outputDir, "index.html")
-	indexSize, err := b.generateIndexHTML(indexPath, transformed)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate index.html: %w", err)
+	// Copy custom theme stylesheets
+	for _, name := range themeNames(transformed.Themes) {
+		stylesheet := transformed.Themes[name]
+		hashedPath, err := b.copyAsset(b.resolveSourcePath(stylesheet), assetsDir, result)
+		if err != nil {
+			result.Warnings = append(result.Warnings, themeWarning(name))
+			delete(transformed.Themes, name)
+			continue
+		}
+
+		transformed.Themes[name] = hashedPath
 	}
-	result.FileCount++
-	result.TotalSize += indexSize
 
-	result.BuildTime = time.Since(startTime)
-	return result, nil
+	// Rewrite image and asciinema paths in transformed slides
+	for i := range transformed.Slides {
+		rewriteSlidePaths(&transformed.Slides[i], pathMapping)
+	}
 }
 
 // imgSrcPattern matches img src attributes in HTML.
@@ -208,6 +345,20 @@ func extractImagePaths(html string) []string {
 	return paths
 }
 
+// rewriteSlidePaths replaces the image and asciinema paths in a slide using
+// the provided mapping: in its HTML, the HTML of each column, its notes, and
+// its image-focus image.
+func rewriteSlidePaths(slide *transformer.TransformedSlide, pathMapping map[string]string) {
+	slide.HTML = rewriteAsciinemaPaths(rewriteImagePaths(slide.HTML, pathMapping), pathMapping)
+	for i, column := range slide.Columns {
+		slide.Columns[i] = rewriteAsciinemaPaths(rewriteImagePaths(column, pathMapping), pathMapping)
+	}
+	slide.NotesHTML = rewriteImagePaths(slide.NotesHTML, pathMapping)
+	if newPath, exists := pathMapping[slide.ImageSrc]; exists {
+		slide.ImageSrc = newPath
+	}
+}
+
 // rewriteImagePaths replaces image paths in HTML using the provided mapping.
 func rewriteImagePaths(html string, pathMapping map[string]string) string {
 	return imgSrcPattern.ReplaceAllStringFunc(html, func(match string) string {
@@ -243,14 +394,8 @@ func (b *Builder) copyWithHash(sourcePath, destDir string) (string, int64, error
 		return "", 0, fmt.Errorf("failed to read source file: %w", err)
 	}
 
-	// Compute content hash (first 8 chars of SHA256)
-	hash := sha256.Sum256(content)
-	hashStr := hex.EncodeToString(hash[:])[:8]
-
-	// Build destination filename with hash
-	ext := filepath.Ext(sourcePath)
-	baseName := strings.TrimSuffix(filepath.Base(sourcePath), ext)
-	hashedName := fmt.Sprintf("%s.%s%s", baseName, hashStr, ext)
+	// Build destination filename with the content hash
+	hashedName := hashedFileName(sourcePath, contentHash(content))
 	destPath := filepath.Join(destDir, hashedName)
 
 	// Write to destination
@@ -304,6 +449,109 @@ func rewriteAsciinemaPaths(html string, pathMapping map[string]string) string {
 	})
 }
 
+// AssetPaths returns the local files a presentation references: images,
+// including those in speaker notes, .cast files, background images and
+// videos, and custom theme stylesheets. These are the files a build copies.
+// Relative paths are resolved against baseDir. Each file is listed once,
+// whether or not it exists.
+func AssetPaths(transformed *transformer.TransformedPresentation, baseDir string) []string {
+	var refs []string
+	for _, slide := range transformed.Slides {
+		refs = append(refs, extractImagePaths(slide.HTML+slide.NotesHTML)...)
+		refs = append(refs, extractAsciinemaPaths(slide.HTML)...)
+		if slide.ImageSrc != "" {
+			refs = append(refs, slide.ImageSrc)
+		}
+		if isLocalBackgroundFile(slide.Background) {
+			refs = append(refs, slide.Background.Value)
+		}
+	}
+	for _, name := range themeNames(transformed.Themes) {
+		refs = append(refs, transformed.Themes[name])
+	}
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if isAbsoluteURL(ref) || strings.HasPrefix(ref, "data:") || strings.HasPrefix(ref, "//") {
+			continue
+		}
+		ref = html.UnescapeString(strings.TrimPrefix(ref, "/local/"))
+		if unescaped, err := url.PathUnescape(ref); err == nil {
+			ref = unescaped
+		}
+		if !filepath.IsAbs(ref) {
+			ref = filepath.Join(baseDir, ref)
+		}
+		ref = filepath.Clean(ref)
+		if !seen[ref] {
+			seen[ref] = true
+			paths = append(paths, ref)
+		}
+	}
+	return paths
+}
+
+// themeNames returns the names of the custom themes in sorted order, so builds
+// copy and list them in the same order every time.
+func themeNames(themes map[string]string) []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isLocalBackgroundFile reports whether a slide background is an image or
+// video file on disk, as opposed to a color, gradient, absolute URL, or data URI.
+func isLocalBackgroundFile(bg *transformer.BackgroundConfig) bool {
+	return bg != nil && (bg.Type == "image" || bg.Type == "video") && !isAbsoluteURL(bg.Value) && !strings.HasPrefix(bg.Value, "data:")
+}
+
+// backgroundWarning returns the build warning for a missing background image
+// or video on a slide.
+func backgroundWarning(slideIndex int, bg *transformer.BackgroundConfig) string {
+	return fmt.Sprintf("slide %d: background %s %s not found", slideIndex+1, bg.Type, strings.TrimPrefix(bg.Value, "/local/"))
+}
+
+// videoSizeWarning returns the build warning for a background video larger than the limit.
+func videoSizeWarning(slideIndex int, path string, size, limit int64) string {
+	return fmt.Sprintf("slide %d: background video %s is %s, above the %s limit; consider compressing it",
+		slideIndex+1, strings.TrimPrefix(path, "/local/"), formatBytes(size), formatBytes(limit))
+}
+
+// formatBytes formats a size in bytes for warnings, such as "52.4MB".
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
+
+// themeWarning returns the build warning for a custom theme stylesheet that can't be read.
+func themeWarning(name string) string {
+	return fmt.Sprintf("custom theme %s could not be read", name)
+}
+
+// overflowWarning returns the build warning for slides whose content is
+// estimated not to fit, given their 1-based numbers.
+func overflowWarning(numbers []int) string {
+	list := make([]string, len(numbers))
+	for i, n := range numbers {
+		list[i] = strconv.Itoa(n)
+	}
+	noun := "slide"
+	if len(numbers) > 1 {
+		noun = "slides"
+	}
+	return fmt.Sprintf("%s %s may overflow; their text is scaled down to fit, consider splitting them", noun, strings.Join(list, ", "))
+}
+
 // isAbsoluteURL checks if the path is an absolute URL (http:// or https://).
 func isAbsoluteURL(path string) bool {
 	lowerPath := strings.ToLower(path)
@@ -352,38 +600,74 @@ func (b *Builder) CopyEmbeddedAssets() (int, int64, error) {
 	return count, totalSize, nil
 }
 
+// copyEmbeddedAssets copies the embedded frontend assets like
+// CopyEmbeddedAssets, skipping those the previous build already wrote. The
+// mermaid runtime is only copied when mermaid is set.
+func (b *Builder) copyEmbeddedAssets(result *BuildResult, mermaid bool) error {
+	files, err := embedded.ListAll()
+	if err != nil {
+		return fmt.Errorf("failed to list embedded assets: %w", err)
+	}
+
+	for _, file := range files {
+		// Skip index.html as we generate our own with embedded JSON
+		if file == "index.html" || (file == MermaidRuntime && !mermaid) {
+			continue
+		}
+
+		content, err := embedded.GetFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read embedded file %s: %w", file, err)
+		}
+		if err := b.writeOutput("embedded:"+file, filepath.FromSlash(file), content, result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
 // generateIndexHTML creates the index.html file by injecting presentation JSON
 // into the real Vite-built frontend template, so all themes, fonts, and styles work.
 func (b *Builder) generateIndexHTML(path string, pres *transformer.TransformedPresentation) (int64, error) {
+	html, err := b.renderPageHTML(pres, pageMeta(pres.Config, ""))
+	if err != nil {
+		return 0, err
+	}
+
+	// Write to file
+	if err := os.WriteFile(path, []byte(html), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write index.html: %w", err)
+	}
+
+	return int64(len(html)), nil
+}
+
+// renderPageHTML returns the embedded index.html template with the title and
+// metadata tags of meta and the presentation JSON injected.
+func (b *Builder) renderPageHTML(pres *transformer.TransformedPresentation, meta PageMeta) (string, error) {
 	// Serialize presentation to JSON
 	presJSON, err := json.Marshal(pres)
 	if err != nil {
-		return 0, fmt.Errorf("failed to marshal presentation: %w", err)
+		return "", fmt.Errorf("failed to marshal presentation: %w", err)
 	}
 
 	// Read the embedded index.html template from the Vite build
 	templateHTML, err := embedded.GetIndexHTML()
 	if err != nil {
-		return 0, fmt.Errorf("failed to read embedded index.html: %w", err)
+		return "", fmt.Errorf("failed to read embedded index.html: %w", err)
 	}
 
-	// Set the title
-	title := pres.Config.Title
-	if title == "" {
-		title = "Tap Presentation"
-	}
-	html := strings.Replace(string(templateHTML), "<title>Tap Presentation</title>", "<title>"+title+"</title>", 1)
+	// Set the title and metadata
+	page := strings.Replace(string(templateHTML), "<title>Tap Presentation</title>", meta.HeadHTML(), 1)
 
 	// Inject embedded presentation JSON before the closing </body> tag.
 	// The Svelte App.svelte checks for this element and uses it instead of fetching /api/presentation.
 	dataScript := fmt.Sprintf(`<script id="presentation-data" type="application/json">%s</script>`, string(presJSON))
-	html = strings.Replace(html, "</body>", dataScript+"\n</body>", 1)
-
-	// Write to file
-	if err := os.WriteFile(path, []byte(html), 0644); err != nil {
-		return 0, fmt.Errorf("failed to write index.html: %w", err)
-	}
+	page = strings.Replace(page, "</body>", dataScript+"\n</body>", 1)
 
-	return int64(len(html)), nil
+	page = includeMermaidRuntime(page, pres)
+	page = includeCastPlayer(page, pres)
+	page = b.addIntegrity(page)
+	return b.registerServiceWorker(page), nil
 }
@@ -1,14 +1,18 @@
 package builder
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
 	"github.com/MiniCodeMonkey/tap/internal/config"
 	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/themes"
 	"github.com/MiniCodeMonkey/tap/internal/transformer"
 )
 
@@ -49,6 +53,44 @@ func TestSetBaseDir(t *testing.T) {
 	}
 }
 
+func TestAssetPaths(t *testing.T) {
+	baseDir := "/talks/demo"
+	pres := &transformer.TransformedPresentation{
+		Slides: []transformer.TransformedSlide{
+			{
+				HTML:      `<img src="/local/images/logo.png"><img src="https://example.com/remote.png"><img src="/local/images/my%20photo.png">`,
+				NotesHTML: `<img src="/local/images/notes.png">`,
+			},
+			{
+				HTML:       `<pre><code class="language-asciinema">src: /local/demo.cast</code></pre><img src="/local/images/logo.png">`,
+				Background: &transformer.BackgroundConfig{Type: "image", Value: "/local/backgrounds/city.jpg"},
+			},
+			{
+				ImageSrc:   "/local/images/focus.png",
+				Background: &transformer.BackgroundConfig{Type: "color", Value: "#000"},
+			},
+			{
+				Background: &transformer.BackgroundConfig{Type: "image", Value: "data:image/png;base64,AAAA"},
+			},
+		},
+		Themes: map[string]string{"brand": "/local/themes/brand.css"},
+	}
+
+	got := AssetPaths(pres, baseDir)
+	want := []string{
+		"/talks/demo/images/logo.png",
+		"/talks/demo/images/my photo.png",
+		"/talks/demo/images/notes.png",
+		"/talks/demo/demo.cast",
+		"/talks/demo/backgrounds/city.jpg",
+		"/talks/demo/images/focus.png",
+		"/talks/demo/themes/brand.css",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("AssetPaths() =\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
 func TestExtractImagePaths(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -348,7 +390,7 @@ func TestBuild_EmbedsPresentationJSON(t *testing.T) {
 	cfg.Title = "JSON Test"
 	pres := &parser.Presentation{
 		Slides: []parser.Slide{
-			{Index: 0, HTML: "<p>Content</p>"},
+			{Index: 0, HTML: "<h1>Intro</h1><p>Content</p>"},
 		},
 	}
 
@@ -395,6 +437,49 @@ func TestBuild_EmbedsPresentationJSON(t *testing.T) {
 	if _, ok := data["slides"]; !ok {
 		t.Error("missing 'slides' in embedded JSON")
 	}
+	toc, ok := data["toc"].([]interface{})
+	if !ok || len(toc) != 1 {
+		t.Errorf("expected one 'toc' entry in embedded JSON, got %v", data["toc"])
+	}
+}
+
+func TestBuild_EmbedsSanitizedHTML(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+
+	b := NewWithOutput(outputDir)
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<h1>Intro</h1><script>alert("hi")</script><img src="https://example.com/a.png" onerror="alert(1)">`},
+		},
+	}
+
+	if _, err := b.Build(config.DefaultConfig(), pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("failed to read index.html: %v", err)
+	}
+	// Check the embedded presentation JSON, not the bundled frontend
+	html := string(content)
+	startMarker := `<script id="presentation-data" type="application/json">`
+	startIdx := strings.Index(html, startMarker)
+	if startIdx == -1 {
+		t.Fatal("presentation data script tag not found")
+	}
+	data := html[startIdx+len(startMarker):]
+	data = data[:strings.Index(data, "</script>")]
+
+	for _, unsafe := range []string{"alert", "onerror"} {
+		if strings.Contains(data, unsafe) {
+			t.Errorf("embedded JSON should not contain %q after sanitizing: %s", unsafe, data)
+		}
+	}
+	if !strings.Contains(data, "https://example.com/a.png") {
+		t.Error("expected the image to be kept")
+	}
 }
 
 func TestBuild_CopiesImagesWithHash(t *testing.T) {
@@ -501,6 +586,143 @@ func TestBuild_RewritesImagePathsInHTML(t *testing.T) {
 	}
 }
 
+func TestBuild_RewritesImageFocusImageSrc(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "photo.jpg"), []byte("jpg content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	cfg := config.DefaultConfig()
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<p><img src="photo.jpg" alt="Photo"></p>`},
+		},
+	}
+
+	if _, err := b.Build(cfg, pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The image-focus slide's image source points at the copied asset
+	if !strings.Contains(string(content), `"imageSrc":"assets/photo.`) {
+		t.Error("imageSrc should be rewritten to assets/")
+	}
+}
+
+func TestBuild_RewritesColumnImagePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "a.png"), []byte("png content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	pres, err := parser.New().Parse([]byte("<!-- layout: two-column -->\n\n![a](a.png)\n\n|||\n\nRight"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Build(config.DefaultConfig(), pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`(?s)<script id="presentation-data" type="application/json">(.*?)</script>`).FindSubmatch(content)
+	if match == nil {
+		t.Fatal("presentation data not found in index.html")
+	}
+	var data transformer.TransformedPresentation
+	if err := json.Unmarshal(match[1], &data); err != nil {
+		t.Fatalf("failed to decode presentation data: %v", err)
+	}
+
+	// The columns point at the copied asset, like the slide HTML
+	columns := data.Slides[0].Columns
+	if len(columns) != 2 {
+		t.Fatalf("expected 2 columns, got %q", columns)
+	}
+	if !strings.Contains(columns[0], `src="assets/a.`) || strings.Contains(columns[0], "/local/") {
+		t.Errorf("column image should be rewritten to assets/, got %q", columns[0])
+	}
+}
+
+func TestBuild_EmbedsNotesHTML(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "chart.png"), []byte("png content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	pres, err := parser.New().Parse([]byte("# Results\n\n???\nStress the **growth**.\n\n![chart](chart.png)\n\n<script>alert(1)</script>"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if _, err := b.Build(config.DefaultConfig(), pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	html := string(content)
+	data := html[strings.Index(html, `<script id="presentation-data" type="application/json">`):]
+	data = data[:strings.Index(data, "</script>")]
+
+	var embedded struct {
+		Slides []struct {
+			Notes     string `json:"notes"`
+			NotesHTML string `json:"notesHTML"`
+		} `json:"slides"`
+	}
+	if err := json.Unmarshal([]byte(data[strings.Index(data, ">")+1:]), &embedded); err != nil {
+		t.Fatalf("failed to parse embedded JSON: %v", err)
+	}
+	slide := embedded.Slides[0]
+
+	if !strings.Contains(slide.Notes, "Stress the **growth**.") {
+		t.Errorf("notes should keep the markdown, got %q", slide.Notes)
+	}
+	if !strings.Contains(slide.NotesHTML, "<strong>growth</strong>") {
+		t.Errorf("notesHTML should be rendered, got %q", slide.NotesHTML)
+	}
+	if strings.Contains(slide.NotesHTML, "alert") {
+		t.Errorf("notesHTML should be sanitized, got %q", slide.NotesHTML)
+	}
+	if !strings.Contains(slide.NotesHTML, `src="assets/chart.`) {
+		t.Errorf("images in notes should be copied to assets/, got %q", slide.NotesHTML)
+	}
+}
+
 func TestBuild_SkipsAbsoluteURLs(t *testing.T) {
 	tmpDir := t.TempDir()
 	outputDir := filepath.Join(tmpDir, "dist")
@@ -538,6 +760,302 @@ func TestBuild_SkipsAbsoluteURLs(t *testing.T) {
 	}
 }
 
+func TestBuild_LeavesEmbedURLs(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(tmpDir)
+	pres, err := parser.New().Parse([]byte("<!-- embed: http://localhost:5173/demo.png -->\n# Demo"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	result, err := b.Build(config.DefaultConfig(), pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+
+	// The page is loaded by the browser, not copied as an asset
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"url":"http://localhost:5173/demo.png"`) {
+		t.Error("embed URL should be preserved unchanged")
+	}
+}
+
+func TestBuild_CopiesBackgroundImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(filepath.Join(baseDir, "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "images", "hero.jpg"), []byte("hero content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	cfg := config.DefaultConfig()
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>Hero</h1>", Directives: parser.SlideDirectives{Background: "./images/hero.jpg"}},
+			{Index: 1, HTML: "<h1>Remote</h1>", Directives: parser.SlideDirectives{Background: "https://example.com/bg.png"}},
+			{Index: 2, HTML: "<h1>Gradient</h1>", Directives: parser.SlideDirectives{Background: "linear-gradient(#000, #fff)"}},
+			{Index: 3, HTML: "<h1>Color</h1>", Directives: parser.SlideDirectives{Background: "#ff0000"}},
+			{Index: 4, HTML: "<h1>Missing</h1>", Directives: parser.SlideDirectives{Background: "images/missing.png"}},
+		},
+	}
+
+	result, err := b.Build(cfg, pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`(?s)<script id="presentation-data" type="application/json">(.*?)</script>`).FindSubmatch(content)
+	if match == nil {
+		t.Fatal("presentation data not found in index.html")
+	}
+	var data transformer.TransformedPresentation
+	if err := json.Unmarshal(match[1], &data); err != nil {
+		t.Fatalf("failed to decode presentation data: %v", err)
+	}
+
+	hero := data.Slides[0].Background.Value
+	if !strings.HasPrefix(hero, "assets/hero.") || !strings.HasSuffix(hero, ".jpg") {
+		t.Errorf("background should be rewritten to a hashed asset, got %q", hero)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, hero)); err != nil {
+		t.Errorf("background image should be copied: %v", err)
+	}
+
+	passthrough := map[int]string{1: "https://example.com/bg.png", 2: "linear-gradient(#000, #fff)", 3: "#ff0000"}
+	for i, want := range passthrough {
+		if got := data.Slides[i].Background.Value; got != want {
+			t.Errorf("slide %d background = %q, want %q", i+1, got, want)
+		}
+	}
+
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "slide 5") ||
+		!strings.Contains(result.Warnings[0], "images/missing.png") {
+		t.Errorf("expected a warning for the missing background on slide 5, got %v", result.Warnings)
+	}
+}
+
+func TestBuild_CopiesBackgroundVideos(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+
+	if err := os.MkdirAll(filepath.Join(tmpDir, "media"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "media", "loop.mp4"), []byte("small video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "media", "big.webm"), make([]byte, 2048), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(tmpDir)
+	b.SetMaxVideoSize(1024)
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>Title</h1>", Directives: parser.SlideDirectives{Background: "./media/loop.mp4"}},
+			{Index: 1, HTML: "<h1>Big</h1>", Directives: parser.SlideDirectives{Background: "media/big.webm"}},
+			{Index: 2, HTML: "<h1>Missing</h1>", Directives: parser.SlideDirectives{Background: "media/missing.mov"}},
+			{Index: 3, HTML: "<h1>Remote</h1>", Directives: parser.SlideDirectives{Background: "https://example.com/loop.mp4"}},
+		},
+	}
+
+	result, err := b.Build(config.DefaultConfig(), pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`(?s)<script id="presentation-data" type="application/json">(.*?)</script>`).FindSubmatch(content)
+	if match == nil {
+		t.Fatal("presentation data not found in index.html")
+	}
+	var data transformer.TransformedPresentation
+	if err := json.Unmarshal(match[1], &data); err != nil {
+		t.Fatalf("failed to decode presentation data: %v", err)
+	}
+
+	video := data.Slides[0].Background
+	if video.Type != "video" || !video.Muted {
+		t.Errorf("expected a muted video background, got %+v", video)
+	}
+	if !regexp.MustCompile(`^assets/loop\.[0-9a-f]{8}\.mp4$`).MatchString(video.Value) {
+		t.Errorf("background video should be rewritten to a hashed asset, got %q", video.Value)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, video.Value)); err != nil {
+		t.Errorf("background video should be copied: %v", err)
+	}
+	if got := data.Slides[3].Background.Value; got != "https://example.com/loop.mp4" {
+		t.Errorf("remote video should be left alone, got %q", got)
+	}
+
+	// The large video is copied with a warning, and the missing one is reported
+	if !strings.HasPrefix(data.Slides[1].Background.Value, "assets/big.") {
+		t.Errorf("large background video should still be copied, got %q", data.Slides[1].Background.Value)
+	}
+	warnings := strings.Join(result.Warnings, "\n")
+	if len(result.Warnings) != 2 ||
+		!strings.Contains(warnings, "slide 2: background video media/big.webm is 2.0KB, above the 1.0KB limit") ||
+		!strings.Contains(warnings, "slide 3: background video media/missing.mov not found") {
+		t.Errorf("expected size and missing video warnings, got %v", result.Warnings)
+	}
+}
+
+func TestBuild_CopiesCustomThemes(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(filepath.Join(baseDir, "themes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "themes", "my-brand.css"), []byte(".theme-my-brand {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	cfg := config.DefaultConfig()
+	cfg.Theme = "my-brand"
+	cfg.SetCustomThemes([]themes.Theme{
+		{Name: "my-brand", Path: filepath.Join(baseDir, "themes", "my-brand.css")},
+		{Name: "deleted", Path: filepath.Join(baseDir, "themes", "deleted.css")},
+	})
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{{Index: 0, HTML: "<h1>Brand</h1>"}},
+	}
+
+	result, err := b.Build(cfg, pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`(?s)<script id="presentation-data" type="application/json">(.*?)</script>`).FindSubmatch(content)
+	if match == nil {
+		t.Fatal("presentation data not found in index.html")
+	}
+	var data transformer.TransformedPresentation
+	if err := json.Unmarshal(match[1], &data); err != nil {
+		t.Fatalf("failed to decode presentation data: %v", err)
+	}
+
+	stylesheet := data.Themes["my-brand"]
+	if !strings.HasPrefix(stylesheet, "assets/my-brand.") || !strings.HasSuffix(stylesheet, ".css") {
+		t.Errorf("theme stylesheet should be rewritten to a hashed asset, got %q", stylesheet)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, stylesheet)); err != nil {
+		t.Errorf("theme stylesheet should be copied: %v", err)
+	}
+	if _, exists := data.Themes["deleted"]; exists {
+		t.Error("unreadable theme should be removed from the presentation data")
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "custom theme deleted") {
+		t.Errorf("expected a warning for the unreadable theme, got %v", result.Warnings)
+	}
+}
+
+func TestBuild_IsReproducible(t *testing.T) {
+	baseDir := t.TempDir()
+	for name, content := range map[string]string{
+		"images/logo.png":  "logo",
+		"images/city.jpg":  "city",
+		"themes/brand.css": ".theme-brand {}",
+		"themes/dark.css":  ".theme-dark {}",
+		"themes/night.css": ".theme-night {}",
+	} {
+		path := filepath.Join(baseDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.SetCustomThemes([]themes.Theme{
+		{Name: "night", Path: filepath.Join(baseDir, "themes", "night.css")},
+		{Name: "brand", Path: filepath.Join(baseDir, "themes", "brand.css")},
+		{Name: "dark", Path: filepath.Join(baseDir, "themes", "dark.css")},
+		{Name: "gone", Path: filepath.Join(baseDir, "themes", "gone.css")},
+		{Name: "missing", Path: filepath.Join(baseDir, "themes", "missing.css")},
+	})
+	pres, err := parser.New().Parse([]byte("---\ntitle: Talk\n---\n\n<!-- background: images/city.jpg -->\n\n# One\n\n![Logo](images/logo.png)\n\n---\n\n# Two\n"))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// Build into two directories, as from two checkouts
+	type build struct {
+		index    []byte
+		assets   []string
+		warnings []string
+	}
+	var builds []build
+	for i := 0; i < 2; i++ {
+		outputDir := filepath.Join(t.TempDir(), "dist")
+		b := NewWithOutput(outputDir)
+		b.SetBaseDir(baseDir)
+		result, err := b.Build(cfg, pres)
+		if err != nil {
+			t.Fatalf("Build failed: %v", err)
+		}
+
+		index, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, err := os.ReadDir(filepath.Join(outputDir, "assets"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var assets []string
+		for _, entry := range entries {
+			assets = append(assets, entry.Name())
+		}
+		builds = append(builds, build{index: index, assets: assets, warnings: result.Warnings})
+	}
+
+	if !bytes.Equal(builds[0].index, builds[1].index) {
+		t.Error("expected index.html to have the same bytes in both builds")
+	}
+	if !reflect.DeepEqual(builds[0].assets, builds[1].assets) {
+		t.Errorf("asset names differ:\n%v\n%v", builds[0].assets, builds[1].assets)
+	}
+	want := []string{themeWarning("gone"), themeWarning("missing")}
+	for _, build := range builds {
+		if !reflect.DeepEqual(build.warnings, want) {
+			t.Errorf("warnings = %v, want %v", build.warnings, want)
+		}
+	}
+}
+
 func TestCopyWithHash(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -740,3 +1258,141 @@ func TestBuildResult_Stats(t *testing.T) {
 		t.Error("expected positive build time")
 	}
 }
+
+func TestBuild_WarnsAboutOverflowingSlides(t *testing.T) {
+	b := NewWithOutput(filepath.Join(t.TempDir(), "dist"))
+	long := "<p>" + strings.Repeat("word ", 500) + "</p>"
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>Title</h1>"},
+			{Index: 1, HTML: long},
+			{Index: 2, HTML: long},
+		},
+	}
+
+	result, err := b.Build(config.DefaultConfig(), pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	want := "slides 2, 3 may overflow"
+	found := false
+	for _, warning := range result.Warnings {
+		if strings.HasPrefix(warning, want) {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("warnings = %v, want one starting with %q", result.Warnings, want)
+	}
+}
+
+func TestBuild_WarnsAboutInvalidTransitions(t *testing.T) {
+	b := NewWithOutput(filepath.Join(t.TempDir(), "dist"))
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>Title</h1>"},
+			{Index: 1, HTML: "<p>Body</p>", Directives: parser.SlideDirectives{Transition: "slid-left"}},
+		},
+	}
+
+	result, err := b.Build(config.DefaultConfig(), pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if len(result.Warnings) != 1 || !strings.HasPrefix(result.Warnings[0], `slide 2: invalid transition "slid"`) {
+		t.Errorf("expected a warning for the invalid transition on slide 2, got %v", result.Warnings)
+	}
+}
+
+func TestBuild_EmbedsSlideClasses(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "dist")
+	b := NewWithOutput(outputDir)
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>Title</h1>", Directives: parser.SlideDirectives{Class: "invert center bad.name"}},
+		},
+	}
+
+	result, err := b.Build(config.DefaultConfig(), pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatalf("failed to read index.html: %v", err)
+	}
+	if !strings.Contains(string(content), `"classes":["invert","center"]`) {
+		t.Error("expected the slide's classes in the embedded JSON")
+	}
+
+	if len(result.Warnings) != 1 || !strings.HasPrefix(result.Warnings[0], `slide 1: invalid class "bad.name"`) {
+		t.Errorf("expected a warning for the invalid class on slide 1, got %v", result.Warnings)
+	}
+}
+
+func TestBuild_SkipsImageCache(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	// A generated image and the cache of AI images next to it
+	cacheDir := filepath.Join(baseDir, "images", ".tap-cache")
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "images", "hero.png"), []byte("hero image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cacheDir, "abc123"), []byte("cached image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<img src="images/hero.png">`},
+		},
+	}
+	if _, err := b.Build(config.DefaultConfig(), pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	err := filepath.WalkDir(outputDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Name() == ".tap-cache" || strings.HasPrefix(d.Name(), "abc123") {
+			t.Errorf("image cache copied to the output: %s", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuildResultJSON(t *testing.T) {
+	data, err := json.Marshal(BuildResult{OutputDir: "dist", FileCount: 3})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	// Field names are part of the JSON output of tap build --json
+	for _, name := range []string{"outputDir", "buildTimeNs", "fileCount", "totalSize", "warnings",
+		"filesCopied", "filesSkipped", "filesPruned", "offline", "manifestPath"} {
+		if _, ok := fields[name]; !ok {
+			t.Errorf("JSON %s has no %q field", data, name)
+		}
+	}
+	if len(fields) != 10 {
+		t.Errorf("JSON %s has %d fields, want 10", data, len(fields))
+	}
+}
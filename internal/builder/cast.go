@@ -0,0 +1,90 @@
+package builder
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// URLs of the asciinema player, which plays the terminal recordings of cast
+// and asciinema blocks. They match the URLs the frontend loads the player
+// from, so it uses the copy in the page instead of loading another.
+const (
+	castPlayerJS  = "https://cdn.jsdelivr.net/npm/asciinema-player@3.9.0/dist/bundle/asciinema-player.min.js"
+	castPlayerCSS = "https://cdn.jsdelivr.net/npm/asciinema-player@3.9.0/dist/bundle/asciinema-player.min.css"
+)
+
+// castPlayerTags loads the asciinema player's stylesheet and script.
+const castPlayerTags = `<link rel="stylesheet" href="` + castPlayerCSS + `">` + "\n" +
+	`<script src="` + castPlayerJS + `"></script>`
+
+// includeCastPlayer adds the asciinema player to a page when its presentation
+// plays terminal recordings, and returns the page unchanged otherwise.
+func includeCastPlayer(page string, pres *transformer.TransformedPresentation) string {
+	if !transformer.UsesCastPlayer(pres) {
+		return page
+	}
+	return strings.Replace(page, "</head>", castPlayerTags+"\n</head>", 1)
+}
+
+// copyCastFiles copies the local .cast files played by cast blocks into
+// assetsDir and points the blocks at the copies. pathMapping holds the
+// assets copied so far, so each file is copied once. Missing files add build
+// warnings.
+func (b *Builder) copyCastFiles(transformed *transformer.TransformedPresentation, assetsDir string, pathMapping map[string]string, result *BuildResult) {
+	for i := range transformed.Slides {
+		for _, cast := range slideCasts(&transformed.Slides[i]) {
+			if hashedPath, exists := pathMapping[cast.Src]; exists {
+				cast.Src = hashedPath
+				continue
+			}
+
+			hashedPath, err := b.copyAsset(b.resolveSourcePath(cast.Src), assetsDir, result)
+			if err != nil {
+				result.Warnings = append(result.Warnings, castWarning(i, cast.Src))
+				continue
+			}
+			pathMapping[cast.Src] = hashedPath
+			cast.Src = hashedPath
+		}
+	}
+}
+
+// inlineCastFiles reads the local .cast files played by cast blocks into the
+// blocks, for single-file builds. Missing files add build warnings.
+func (b *Builder) inlineCastFiles(transformed *transformer.TransformedPresentation, result *BuildResult) {
+	for i := range transformed.Slides {
+		for _, cast := range slideCasts(&transformed.Slides[i]) {
+			content, err := os.ReadFile(b.resolveSourcePath(cast.Src))
+			if err != nil {
+				result.Warnings = append(result.Warnings, castWarning(i, cast.Src))
+				continue
+			}
+			if len(content) > LargeAssetThreshold {
+				result.Warnings = append(result.Warnings, fmt.Sprintf(
+					"%s is %d bytes and was inlined; consider compressing it", cast.Src, len(content)))
+			}
+			cast.Data = string(content)
+			cast.Src = ""
+		}
+	}
+}
+
+// slideCasts returns the recordings of the slide's cast blocks that play a
+// local .cast file.
+func slideCasts(slide *transformer.TransformedSlide) []*transformer.Cast {
+	var casts []*transformer.Cast
+	for _, block := range slide.CodeBlocks {
+		if block.Cast != nil && block.Cast.Src != "" && !isAbsoluteURL(block.Cast.Src) {
+			casts = append(casts, block.Cast)
+		}
+	}
+	return casts
+}
+
+// castWarning returns the build warning for a .cast file that couldn't be found.
+func castWarning(slideIndex int, path string) string {
+	return fmt.Sprintf("slide %d: cast file %s not found", slideIndex+1, strings.TrimPrefix(path, "/local/"))
+}
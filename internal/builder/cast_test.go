@@ -0,0 +1,114 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// castRecording is a short asciinema v2 recording.
+const castRecording = `{"version": 2, "width": 80, "height": 24}` + "\n" + `[0.5, "o", "$ ls\r\n"]` + "\n"
+
+// newCastBuild returns a builder for a presentation directory with a
+// demo.cast recording, and the presentation playing it on its first slide.
+func newCastBuild(t *testing.T) (*Builder, *parser.Presentation, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "demo.cast"), []byte(castRecording), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pres, err := parser.New().Parse([]byte("# Demo\n\n```cast {start: 2}\ndemo.cast\n```\n\n---\n\n# Again\n\n```cast\ndemo.cast\n```\n"))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	return b, pres, outputDir
+}
+
+func TestBuild_CastFiles(t *testing.T) {
+	b, pres, outputDir := newCastBuild(t)
+
+	result, err := b.Build(config.DefaultConfig(), pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+
+	embedded := embeddedPresentation(t, readFile(t, filepath.Join(outputDir, "index.html")))
+	first := embedded.Slides[0].CodeBlocks[0].Cast
+	second := embedded.Slides[1].CodeBlocks[0].Cast
+	if first == nil || !regexp.MustCompile(`^assets/demo\.[0-9a-f]+\.cast$`).MatchString(first.Src) {
+		t.Fatalf("expected the recording to point at a hashed asset, got %+v", first)
+	}
+	if second == nil || second.Src != first.Src {
+		t.Errorf("expected both blocks to share the copied recording, got %+v", second)
+	}
+	if first.StartAt != 2 {
+		t.Errorf("expected the start time to be kept, got %v", first.StartAt)
+	}
+	if readFile(t, filepath.Join(outputDir, first.Src)) != castRecording {
+		t.Error("expected the recording to be copied into assets")
+	}
+	if !strings.Contains(readFile(t, filepath.Join(outputDir, "index.html")), castPlayerTags) {
+		t.Error("index.html should load the asciinema player")
+	}
+}
+
+func TestBuild_CastFileMissing(t *testing.T) {
+	b, pres, _ := newCastBuild(t)
+	pres.Slides[1].CodeBlocks[0].Code = "missing.cast"
+
+	result, err := b.Build(config.DefaultConfig(), pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(result.Warnings) != 1 || result.Warnings[0] != "slide 2: cast file missing.cast not found" {
+		t.Errorf("expected a warning for the missing recording, got %v", result.Warnings)
+	}
+}
+
+func TestBuild_CastSingleFile(t *testing.T) {
+	b, pres, outputDir := newCastBuild(t)
+	b.SetSingleFile(true)
+
+	if _, err := b.Build(config.DefaultConfig(), pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	embedded := embeddedPresentation(t, readFile(t, filepath.Join(outputDir, "index.html")))
+	cast := embedded.Slides[0].CodeBlocks[0].Cast
+	if cast == nil || cast.Src != "" || cast.Data != castRecording {
+		t.Errorf("expected the recording to be inlined, got %+v", cast)
+	}
+}
+
+func TestBuild_NoCastPlayer(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "dist")
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<h1>Welcome</h1>`, CodeBlocks: []parser.CodeBlock{{Language: "go", Code: "x := 1"}}},
+		},
+	}
+
+	if _, err := NewWithOutput(outputDir).Build(config.DefaultConfig(), pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if strings.Contains(readFile(t, filepath.Join(outputDir, "index.html")), "asciinema-player") {
+		t.Error("index.html should not load the asciinema player")
+	}
+}
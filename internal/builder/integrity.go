@@ -0,0 +1,115 @@
+package builder
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// AssetManifestFileName is the name of the asset manifest in the output
+// directory, which maps each file of the build to its output path and its
+// subresource integrity hash for deploy pipelines.
+const AssetManifestFileName = "manifest.json"
+
+// assetManifest is the content of the asset manifest.
+type assetManifest struct {
+	// Files maps the logical name of each file to the file written for it.
+	// Logical names are the paths of copied assets relative to the
+	// presentation, and the output paths of frontend assets and pages.
+	Files map[string]assetManifestEntry `json:"files"`
+}
+
+// assetManifestEntry describes a file of the build in the asset manifest.
+type assetManifestEntry struct {
+	File      string `json:"file"`      // Path relative to the output directory, with a content hash for copied assets
+	Integrity string `json:"integrity"` // Subresource integrity hash of the content, "sha384-<base64>"
+}
+
+// integrityHash returns the subresource integrity hash of content.
+func integrityHash(content []byte) string {
+	hash := sha512.Sum384(content)
+	return "sha384-" + base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// writeAssetManifest writes the asset manifest for the files recorded in the
+// current build's manifest, which must already hold every other file of the
+// build. Like the build manifest, it isn't counted as a file of the build and
+// doesn't list itself or the build manifest.
+func (b *Builder) writeAssetManifest(result *BuildResult) error {
+	m := assetManifest{Files: make(map[string]assetManifestEntry, len(b.manifest.Files))}
+	for source, entry := range b.manifest.Files {
+		m.Files[b.logicalName(source)] = assetManifestEntry{
+			File:      filepath.ToSlash(entry.Output),
+			Integrity: entry.Integrity,
+		}
+	}
+
+	content, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal asset manifest: %w", err)
+	}
+	path := filepath.Join(b.outputDir, AssetManifestFileName)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write asset manifest: %w", err)
+	}
+	result.ManifestPath = path
+	return nil
+}
+
+// logicalName returns the name of a build manifest source in the asset
+// manifest: the path of a copied file relative to the presentation, or the
+// output path of an embedded asset or a page.
+func (b *Builder) logicalName(source string) string {
+	for _, prefix := range []string{"embedded:", "page:"} {
+		if name, ok := strings.CutPrefix(source, prefix); ok {
+			return filepath.ToSlash(name)
+		}
+	}
+	if b.baseDir != "" {
+		if baseDir, err := filepath.Abs(b.baseDir); err == nil {
+			if rel, err := filepath.Rel(baseDir, source); err == nil {
+				return filepath.ToSlash(rel)
+			}
+		}
+	}
+	return filepath.ToSlash(source)
+}
+
+// assetTagPattern matches the opening tags of scripts and stylesheets.
+var assetTagPattern = regexp.MustCompile(`<(?:script|link)\b[^>]*>`)
+
+// assetRefPattern matches the src or href attribute of an asset tag.
+var assetRefPattern = regexp.MustCompile(`\s(?:src|href)="([^"]+)"`)
+
+// addIntegrity adds integrity and crossorigin attributes to the script and
+// link tags of a page that load the build's own frontend assets, so browsers
+// refuse a file that was changed after the build, such as on a CDN. Tags of
+// other files are left alone, and so are pages rendered outside Build.
+func (b *Builder) addIntegrity(page string) string {
+	if b.manifest == nil {
+		return page
+	}
+	return assetTagPattern.ReplaceAllStringFunc(page, func(tag string) string {
+		match := assetRefPattern.FindStringSubmatch(tag)
+		if match == nil || strings.Contains(tag, " integrity=") {
+			return tag
+		}
+		ref := strings.TrimPrefix(strings.TrimPrefix(match[1], "."), "/")
+		entry, ok := b.manifest.Files["embedded:"+ref]
+		if !ok || entry.Integrity == "" {
+			return tag
+		}
+
+		attrs := fmt.Sprintf(` integrity="%s"`, entry.Integrity)
+		if !strings.Contains(tag, " crossorigin") {
+			attrs += ` crossorigin="anonymous"`
+		}
+		end := strings.TrimRight(strings.TrimSuffix(strings.TrimSuffix(tag, ">"), "/"), " ")
+		return end + attrs + tag[len(end):]
+	})
+}
@@ -0,0 +1,191 @@
+package builder
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+)
+
+// readAssetManifest reads the asset manifest from an output directory.
+func readAssetManifest(t *testing.T, outputDir string) assetManifest {
+	t.Helper()
+	var m assetManifest
+	if err := json.Unmarshal([]byte(readFile(t, filepath.Join(outputDir, AssetManifestFileName))), &m); err != nil {
+		t.Fatalf("failed to parse asset manifest: %v", err)
+	}
+	return m
+}
+
+func TestBuild_AssetManifest(t *testing.T) {
+	b, outputDir, _ := setupIncrementalBuild(t)
+	b.SetOffline(true)
+
+	// A rebuild skips every file, and still lists them with their hashes
+	for _, build := range []string{"first", "second"} {
+		result, err := b.Build(config.DefaultConfig(), incrementalPresentation())
+		if err != nil {
+			t.Fatalf("%s build failed: %v", build, err)
+		}
+		if want := filepath.Join(outputDir, AssetManifestFileName); result.ManifestPath != want {
+			t.Errorf("%s build: ManifestPath = %q, want %q", build, result.ManifestPath, want)
+		}
+
+		m := readAssetManifest(t, outputDir)
+		listed := make(map[string]bool)
+		for name, entry := range m.Files {
+			listed[entry.File] = true
+			content, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(entry.File)))
+			if err != nil {
+				t.Errorf("%s build: %s is listed as %s, which is missing", build, name, entry.File)
+				continue
+			}
+			if want := integrityHash(content); entry.Integrity != want {
+				t.Errorf("%s build: integrity of %s = %q, want %q", build, name, entry.Integrity, want)
+			}
+		}
+
+		// The manifest covers exactly the files on disk, besides the manifests
+		var onDisk []string
+		err = filepath.WalkDir(outputDir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(outputDir, path)
+			if err != nil {
+				return err
+			}
+			if rel != ManifestFileName && rel != AssetManifestFileName {
+				onDisk = append(onDisk, filepath.ToSlash(rel))
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range onDisk {
+			if !listed[file] {
+				t.Errorf("%s build: %s is not in the asset manifest", build, file)
+			}
+		}
+		if len(onDisk) != len(listed) {
+			t.Errorf("%s build: asset manifest lists %d files, want the %d on disk", build, len(listed), len(onDisk))
+		}
+	}
+
+	m := readAssetManifest(t, outputDir)
+	if entry, ok := m.Files["photo.png"]; !ok || entry.File != "assets/"+photoAssets(t, outputDir)[0] {
+		t.Errorf("photo.png = %+v, want its hashed copy", entry)
+	}
+	for _, name := range []string{"index.html", "sw.js", "assets/main.js"} {
+		if _, ok := m.Files[name]; !ok {
+			t.Errorf("asset manifest has no entry for %s", name)
+		}
+	}
+}
+
+func TestBuild_IntegrityAttributes(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "dist")
+
+	b := NewWithOutput(outputDir)
+	if _, err := b.Build(config.DefaultConfig(), parseFixture(t, "mermaid-test.md")); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, filepath.FromSlash(MermaidRuntime)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := readFile(t, filepath.Join(outputDir, "index.html"))
+	tag := regexp.MustCompile(`<script[^>]*data-tap-mermaid[^>]*>`).FindString(index)
+	if want := `integrity="` + integrityHash(content) + `"`; !strings.Contains(tag, want) {
+		t.Errorf("mermaid script tag = %q, want %s", tag, want)
+	}
+	if !strings.Contains(tag, `crossorigin="anonymous"`) {
+		t.Errorf("mermaid script tag = %q, want crossorigin", tag)
+	}
+}
+
+func TestAddIntegrity(t *testing.T) {
+	b := New()
+	b.manifest = newManifest()
+	b.manifest.Files["embedded:assets/main.js"] = manifestEntry{Output: "assets/main.js", Integrity: "sha384-js"}
+	b.manifest.Files["embedded:assets/main.css"] = manifestEntry{Output: "assets/main.css", Integrity: "sha384-css"}
+
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{
+			name: "module script",
+			tag:  `<script type="module" crossorigin src="/assets/main.js"></script>`,
+			want: `<script type="module" crossorigin src="/assets/main.js" integrity="sha384-js"></script>`,
+		},
+		{
+			name: "stylesheet",
+			tag:  `<link rel="stylesheet" href="./assets/main.css">`,
+			want: `<link rel="stylesheet" href="./assets/main.css" integrity="sha384-css" crossorigin="anonymous">`,
+		},
+		{
+			name: "self-closing link",
+			tag:  `<link rel="stylesheet" href="assets/main.css" />`,
+			want: `<link rel="stylesheet" href="assets/main.css" integrity="sha384-css" crossorigin="anonymous" />`,
+		},
+		{
+			name: "existing integrity",
+			tag:  `<script src="/assets/main.js" integrity="sha384-other"></script>`,
+			want: `<script src="/assets/main.js" integrity="sha384-other"></script>`,
+		},
+		{
+			name: "external script",
+			tag:  `<script src="https://cdn.example.com/player.js"></script>`,
+			want: `<script src="https://cdn.example.com/player.js"></script>`,
+		},
+		{
+			name: "inline script",
+			tag:  `<script>console.log("hi")</script>`,
+			want: `<script>console.log("hi")</script>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := b.addIntegrity(tt.tag); got != tt.want {
+				t.Errorf("addIntegrity() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// Pages rendered outside Build have no hashes to add
+	page := `<script src="/assets/main.js"></script>`
+	if got := New().addIntegrity(page); got != page {
+		t.Errorf("addIntegrity() without a build = %q, want the page unchanged", got)
+	}
+}
+
+func TestBuild_SingleFileRemovesAssetManifest(t *testing.T) {
+	b, outputDir, _ := setupIncrementalBuild(t)
+	cfg := config.DefaultConfig()
+
+	if _, err := b.Build(cfg, incrementalPresentation()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	b.SetSingleFile(true)
+	result, err := b.Build(cfg, incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.ManifestPath != "" {
+		t.Errorf("ManifestPath = %q for a single-file build, want empty", result.ManifestPath)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, AssetManifestFileName)); !os.IsNotExist(err) {
+		t.Error("single-file build should remove the asset manifest")
+	}
+}
@@ -0,0 +1,280 @@
+package builder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ManifestFileName is the name of the build manifest in the output directory.
+const ManifestFileName = ".tap-manifest.json"
+
+// manifestVersion is the version of the manifest format. Manifests with a
+// different version are ignored.
+const manifestVersion = 2
+
+// manifest records the files written by a build, so the next build into the
+// same output directory can skip files that haven't changed and remove the
+// ones it no longer writes.
+type manifest struct {
+	Version int `json:"version"`
+	// Files maps each source to the file written for it. Sources are file
+	// paths for copied assets, "embedded:<name>" for frontend assets, and
+	// "page:<name>" for generated HTML pages.
+	Files map[string]manifestEntry `json:"files"`
+}
+
+// manifestEntry describes a file written to the output directory.
+type manifestEntry struct {
+	Output        string    `json:"output"`    // Path relative to the output directory
+	Hash          string    `json:"hash"`      // SHA-256 of the content
+	Integrity     string    `json:"integrity"` // Subresource integrity hash of the content
+	Size          int64     `json:"size"`
+	SourceModTime time.Time `json:"sourceModTime,omitzero"` // Modification time of a copied source file
+	OutputModTime time.Time `json:"outputModTime"`
+}
+
+// newManifest returns an empty manifest.
+func newManifest() *manifest {
+	return &manifest{
+		Version: manifestVersion,
+		Files:   make(map[string]manifestEntry),
+	}
+}
+
+// SetForce enables or disables forced builds.
+// A forced build ignores the manifest of the previous build and rewrites every
+// file. Files from the previous build that are no longer written are still removed.
+func (b *Builder) SetForce(force bool) {
+	b.force = force
+}
+
+// Force returns whether forced builds are enabled.
+func (b *Builder) Force() bool {
+	return b.force
+}
+
+// loadManifest reads the manifest of the previous build from the output
+// directory. A missing, unreadable, or outdated manifest yields an empty one,
+// so the build falls back to writing every file.
+func (b *Builder) loadManifest() *manifest {
+	data, err := os.ReadFile(filepath.Join(b.outputDir, ManifestFileName))
+	if err != nil {
+		return newManifest()
+	}
+
+	var m manifest
+	if err := json.Unmarshal(data, &m); err != nil || m.Version != manifestVersion || m.Files == nil {
+		return newManifest()
+	}
+	return &m
+}
+
+// saveManifest writes the manifest of the current build to the output directory.
+func (b *Builder) saveManifest(m *manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal build manifest: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(b.outputDir, ManifestFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write build manifest: %w", err)
+	}
+	return nil
+}
+
+// removeManifest deletes the manifest and the asset manifest from the output
+// directory, for builds that don't track their files.
+func (b *Builder) removeManifest() error {
+	err := os.Remove(filepath.Join(b.outputDir, ManifestFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove build manifest: %w", err)
+	}
+	err = os.Remove(filepath.Join(b.outputDir, AssetManifestFileName))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove asset manifest: %w", err)
+	}
+	return nil
+}
+
+// reusable returns the previous build's entry for a source if its output is
+// still on disk as that build left it. It always returns false for forced builds.
+func (b *Builder) reusable(source string) (manifestEntry, bool) {
+	if b.force || b.prevManifest == nil {
+		return manifestEntry{}, false
+	}
+	entry, ok := b.prevManifest.Files[source]
+	if !ok {
+		return manifestEntry{}, false
+	}
+
+	info, err := os.Stat(filepath.Join(b.outputDir, entry.Output))
+	if err != nil || info.Size() != entry.Size || !info.ModTime().Equal(entry.OutputModTime) {
+		return manifestEntry{}, false
+	}
+	return entry, true
+}
+
+// record adds a written or skipped file to the current build's manifest and
+// the build statistics.
+func (b *Builder) record(source string, entry manifestEntry, skipped bool, result *BuildResult) {
+	if b.manifest != nil {
+		b.manifest.Files[source] = entry
+	}
+	result.FileCount++
+	result.TotalSize += entry.Size
+	if skipped {
+		result.FilesSkipped++
+	} else {
+		result.FilesCopied++
+	}
+}
+
+// writeOutput writes generated content to a path relative to the output
+// directory, unless the previous build wrote the same content there.
+func (b *Builder) writeOutput(source, output string, content []byte, result *BuildResult) error {
+	hash := contentHash(content)
+	if entry, ok := b.reusable(source); ok && entry.Output == output && entry.Hash == hash {
+		b.record(source, entry, true, result)
+		return nil
+	}
+
+	destPath := filepath.Join(b.outputDir, output)
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", output, err)
+	}
+	if err := os.WriteFile(destPath, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	entry, err := writtenEntry(destPath, output, hash, content)
+	if err != nil {
+		return err
+	}
+	b.record(source, entry, false, result)
+	return nil
+}
+
+// copyAsset copies a source file into destDir with a content hash in its name,
+// like copyWithHash, and returns the path of the copy relative to the output
+// directory. The file isn't read again if it hasn't been modified since the
+// previous build, and isn't rewritten if its content is unchanged.
+func (b *Builder) copyAsset(sourcePath, destDir string, result *BuildResult) (string, error) {
+	source, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve source path: %w", err)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to stat source file: %w", err)
+	}
+
+	prev, reusable := b.reusable(source)
+	if reusable && prev.Size == info.Size() && prev.SourceModTime.Equal(info.ModTime()) {
+		b.record(source, prev, true, result)
+		return prev.Output, nil
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return "", fmt.Errorf("failed to read source file: %w", err)
+	}
+	hash := contentHash(content)
+	output := filepath.Join("assets", hashedFileName(source, hash))
+
+	// Touched but unchanged: keep the existing copy
+	if reusable && prev.Output == output && prev.Hash == hash {
+		prev.SourceModTime = info.ModTime()
+		b.record(source, prev, true, result)
+		return output, nil
+	}
+
+	destPath := filepath.Join(destDir, filepath.Base(output))
+	if err := os.WriteFile(destPath, content, 0644); err != nil {
+		return "", fmt.Errorf("failed to write destination file: %w", err)
+	}
+
+	entry, err := writtenEntry(destPath, output, hash, content)
+	if err != nil {
+		return "", err
+	}
+	entry.SourceModTime = info.ModTime()
+	b.record(source, entry, false, result)
+	return output, nil
+}
+
+// pruneOutputs removes the files written by the previous build that the
+// current build no longer writes, such as the old copy of an image whose
+// content changed. It returns the number of files removed.
+func (b *Builder) pruneOutputs() (int, error) {
+	if b.prevManifest == nil || b.manifest == nil {
+		return 0, nil
+	}
+
+	current := make(map[string]bool, len(b.manifest.Files))
+	for _, entry := range b.manifest.Files {
+		current[entry.Output] = true
+	}
+
+	stale := make(map[string]bool)
+	for _, entry := range b.prevManifest.Files {
+		if !current[entry.Output] && filepath.IsLocal(entry.Output) {
+			stale[entry.Output] = true
+		}
+	}
+
+	outputs := make([]string, 0, len(stale))
+	for output := range stale {
+		outputs = append(outputs, output)
+	}
+	sort.Strings(outputs)
+
+	removed := 0
+	for _, output := range outputs {
+		err := os.Remove(filepath.Join(b.outputDir, output))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to remove stale file %s: %w", output, err)
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// writtenEntry returns the manifest entry for a file that was just written
+// with content.
+func writtenEntry(destPath, output, hash string, content []byte) (manifestEntry, error) {
+	info, err := os.Stat(destPath)
+	if err != nil {
+		return manifestEntry{}, fmt.Errorf("failed to stat %s: %w", output, err)
+	}
+	return manifestEntry{
+		Output:        output,
+		Hash:          hash,
+		Integrity:     integrityHash(content),
+		Size:          info.Size(),
+		OutputModTime: info.ModTime(),
+	}, nil
+}
+
+// contentHash returns the hex-encoded SHA-256 of content.
+func contentHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// hashedFileName returns the name of the copy of a source file: its base name
+// with the first 8 characters of the content hash before the extension.
+func hashedFileName(sourcePath, hash string) string {
+	ext := filepath.Ext(sourcePath)
+	baseName := filepath.Base(sourcePath)
+	return fmt.Sprintf("%s.%s%s", baseName[:len(baseName)-len(ext)], hash[:8], ext)
+}
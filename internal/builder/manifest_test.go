@@ -0,0 +1,337 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// setupIncrementalBuild creates a presentation directory with one image and
+// returns a builder for it and the image path.
+func setupIncrementalBuild(t *testing.T) (*Builder, string, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	imgPath := filepath.Join(baseDir, "photo.png")
+	if err := os.WriteFile(imgPath, []byte("first version"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	return b, outputDir, imgPath
+}
+
+func incrementalPresentation() *parser.Presentation {
+	return &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<h1>Photo</h1><img src="photo.png">`},
+		},
+	}
+}
+
+// photoAssets returns the names of the copies of photo.png in the assets directory.
+func photoAssets(t *testing.T, outputDir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(filepath.Join(outputDir, "assets"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "photo.") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names
+}
+
+func TestBuild_WritesManifest(t *testing.T) {
+	b, outputDir, imgPath := setupIncrementalBuild(t)
+
+	result, err := b.Build(config.DefaultConfig(), incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesCopied != result.FileCount || result.FilesSkipped != 0 {
+		t.Errorf("first build copied %d and skipped %d of %d files, want all copied", result.FilesCopied, result.FilesSkipped, result.FileCount)
+	}
+
+	m := b.loadManifest()
+	if len(m.Files) != result.FileCount {
+		t.Errorf("manifest has %d files, want %d", len(m.Files), result.FileCount)
+	}
+	source, _ := filepath.Abs(imgPath)
+	entry, ok := m.Files[source]
+	if !ok {
+		t.Fatalf("manifest has no entry for %s: %v", source, m.Files)
+	}
+	if entry.Output != filepath.Join("assets", photoAssets(t, outputDir)[0]) {
+		t.Errorf("entry output = %q, want the hashed copy", entry.Output)
+	}
+	if _, ok := m.Files["page:index.html"]; !ok {
+		t.Error("manifest has no entry for index.html")
+	}
+}
+
+func TestBuild_SkipsUnchangedFiles(t *testing.T) {
+	b, outputDir, _ := setupIncrementalBuild(t)
+	cfg := config.DefaultConfig()
+
+	first, err := b.Build(cfg, incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	indexPath := filepath.Join(outputDir, "index.html")
+	before, err := os.Stat(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := b.Build(cfg, incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if second.FilesCopied != 0 || second.FilesSkipped != first.FileCount {
+		t.Errorf("rebuild copied %d and skipped %d files, want 0 and %d", second.FilesCopied, second.FilesSkipped, first.FileCount)
+	}
+	if second.FileCount != first.FileCount || second.TotalSize != first.TotalSize {
+		t.Errorf("rebuild reported %d files (%d bytes), want %d (%d bytes)", second.FileCount, second.TotalSize, first.FileCount, first.TotalSize)
+	}
+
+	after, err := os.Stat(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !after.ModTime().Equal(before.ModTime()) {
+		t.Error("index.html should not be rewritten when unchanged")
+	}
+}
+
+func TestBuild_RewritesChangedIndex(t *testing.T) {
+	b, outputDir, _ := setupIncrementalBuild(t)
+	cfg := config.DefaultConfig()
+
+	if _, err := b.Build(cfg, incrementalPresentation()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	pres := incrementalPresentation()
+	pres.Slides = append(pres.Slides, parser.Slide{Index: 1, HTML: "<p>Added slide</p>"})
+	result, err := b.Build(cfg, pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesCopied != 1 {
+		t.Errorf("FilesCopied = %d, want 1 (index.html)", result.FilesCopied)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "Added slide") {
+		t.Error("index.html should contain the added slide")
+	}
+}
+
+func TestBuild_PrunesChangedImage(t *testing.T) {
+	b, outputDir, imgPath := setupIncrementalBuild(t)
+	cfg := config.DefaultConfig()
+
+	if _, err := b.Build(cfg, incrementalPresentation()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	oldAssets := photoAssets(t, outputDir)
+	if len(oldAssets) != 1 {
+		t.Fatalf("assets = %v, want one copy of photo.png", oldAssets)
+	}
+
+	// Same name, same size, new content and modification time
+	if err := os.WriteFile(imgPath, []byte("secnd version"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(imgPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := b.Build(cfg, incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesCopied != 2 || result.FilesPruned != 1 {
+		t.Errorf("rebuild copied %d and pruned %d files, want 2 (image and index.html) and 1", result.FilesCopied, result.FilesPruned)
+	}
+
+	newAssets := photoAssets(t, outputDir)
+	if len(newAssets) != 1 || newAssets[0] == oldAssets[0] {
+		t.Fatalf("assets = %v, want only a new copy replacing %s", newAssets, oldAssets[0])
+	}
+	content, err := os.ReadFile(filepath.Join(outputDir, "assets", newAssets[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "secnd version" {
+		t.Errorf("copied image = %q, want the new content", content)
+	}
+
+	index, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(index), newAssets[0]) || strings.Contains(string(index), oldAssets[0]) {
+		t.Error("index.html should reference only the new copy of the image")
+	}
+}
+
+func TestBuild_TouchedImageNotRewritten(t *testing.T) {
+	b, outputDir, imgPath := setupIncrementalBuild(t)
+	cfg := config.DefaultConfig()
+
+	if _, err := b.Build(cfg, incrementalPresentation()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(imgPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := b.Build(cfg, incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesCopied != 0 || result.FilesPruned != 0 {
+		t.Errorf("rebuild copied %d and pruned %d files, want none", result.FilesCopied, result.FilesPruned)
+	}
+	if assets := photoAssets(t, outputDir); len(assets) != 1 {
+		t.Errorf("assets = %v, want one copy of photo.png", assets)
+	}
+}
+
+func TestBuild_RewritesModifiedOutput(t *testing.T) {
+	b, outputDir, _ := setupIncrementalBuild(t)
+	cfg := config.DefaultConfig()
+
+	if _, err := b.Build(cfg, incrementalPresentation()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	indexPath := filepath.Join(outputDir, "index.html")
+	if err := os.WriteFile(indexPath, []byte("edited by hand"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := b.Build(cfg, incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesCopied != 1 {
+		t.Errorf("FilesCopied = %d, want 1 (index.html)", result.FilesCopied)
+	}
+	content, err := os.ReadFile(indexPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "presentation-data") {
+		t.Error("index.html should be regenerated after being edited")
+	}
+}
+
+func TestBuild_ForceRewritesEverything(t *testing.T) {
+	b, _, _ := setupIncrementalBuild(t)
+	cfg := config.DefaultConfig()
+
+	if _, err := b.Build(cfg, incrementalPresentation()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	b.SetForce(true)
+	if !b.Force() {
+		t.Error("Force() should return true after SetForce(true)")
+	}
+	result, err := b.Build(cfg, incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesSkipped != 0 || result.FilesCopied != result.FileCount {
+		t.Errorf("forced build copied %d and skipped %d of %d files, want all copied", result.FilesCopied, result.FilesSkipped, result.FileCount)
+	}
+}
+
+func TestBuild_IgnoresInvalidManifest(t *testing.T) {
+	b, outputDir, _ := setupIncrementalBuild(t)
+	cfg := config.DefaultConfig()
+
+	if _, err := b.Build(cfg, incrementalPresentation()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputDir, ManifestFileName), []byte("{not json"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := b.Build(cfg, incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesSkipped != 0 {
+		t.Errorf("FilesSkipped = %d, want 0 with an invalid manifest", result.FilesSkipped)
+	}
+	if len(b.loadManifest().Files) != result.FileCount {
+		t.Error("the invalid manifest should be replaced")
+	}
+}
+
+func TestBuild_MultiPagePrunesRemovedSlides(t *testing.T) {
+	b, outputDir, _ := setupIncrementalBuild(t)
+	b.SetMultiPage(true)
+	cfg := config.DefaultConfig()
+
+	pres := incrementalPresentation()
+	pres.Slides = append(pres.Slides, parser.Slide{Index: 1, HTML: "<h1>Second</h1>"})
+	if _, err := b.Build(cfg, pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "slide-02.html")); err != nil {
+		t.Fatalf("slide-02.html should exist: %v", err)
+	}
+
+	result, err := b.Build(cfg, incrementalPresentation())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if result.FilesPruned != 1 {
+		t.Errorf("FilesPruned = %d, want 1", result.FilesPruned)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, "slide-02.html")); !os.IsNotExist(err) {
+		t.Error("slide-02.html should be removed when the slide is gone")
+	}
+}
+
+func TestBuild_SingleFileRemovesManifest(t *testing.T) {
+	b, outputDir, _ := setupIncrementalBuild(t)
+	cfg := config.DefaultConfig()
+
+	if _, err := b.Build(cfg, incrementalPresentation()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	b.SetSingleFile(true)
+	if _, err := b.Build(cfg, incrementalPresentation()); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, ManifestFileName)); !os.IsNotExist(err) {
+		t.Error("single-file build should remove the manifest")
+	}
+}
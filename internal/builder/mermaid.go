@@ -0,0 +1,25 @@
+package builder
+
+import (
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// MermaidRuntime is the embedded frontend asset that renders mermaid diagrams.
+// It is large, so builds only include it when the presentation has diagrams.
+const MermaidRuntime = "assets/mermaid.js"
+
+// mermaidScript loads the mermaid runtime. The data-tap-mermaid attribute
+// tells the frontend the runtime is part of the page, even once single-file
+// builds inline it.
+const mermaidScript = `<script type="module" data-tap-mermaid src="/` + MermaidRuntime + `"></script>`
+
+// includeMermaidRuntime adds the mermaid runtime to a page when its
+// presentation has mermaid diagrams, and returns the page unchanged otherwise.
+func includeMermaidRuntime(page string, pres *transformer.TransformedPresentation) string {
+	if !transformer.UsesMermaid(pres) {
+		return page
+	}
+	return strings.Replace(page, "</head>", mermaidScript+"\n</head>", 1)
+}
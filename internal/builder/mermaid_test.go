@@ -0,0 +1,101 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// parseFixture parses a presentation from the repository's testdata directory.
+func parseFixture(t *testing.T, name string) *parser.Presentation {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("..", "..", "testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pres, err := parser.New().Parse(content)
+	if err != nil {
+		t.Fatalf("failed to parse %s: %v", name, err)
+	}
+	return pres
+}
+
+// mermaidScriptSrc is the start of the mermaid runtime's script tag, which
+// builds follow with its integrity attributes.
+const mermaidScriptSrc = `<script type="module" data-tap-mermaid src="/` + MermaidRuntime + `"`
+
+func TestBuild_MermaidRuntime(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "dist")
+	runtimePath := filepath.Join(outputDir, filepath.FromSlash(MermaidRuntime))
+
+	b := NewWithOutput(outputDir)
+	if _, err := b.Build(config.DefaultConfig(), parseFixture(t, "mermaid-test.md")); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := os.Stat(runtimePath); err != nil {
+		t.Errorf("expected the mermaid runtime to be copied: %v", err)
+	}
+	if !strings.Contains(readFile(t, filepath.Join(outputDir, "index.html")), mermaidScriptSrc) {
+		t.Error("index.html should load the mermaid runtime")
+	}
+
+	// A deck without diagrams doesn't ship the runtime
+	plain := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<h1>Welcome</h1>`, CodeBlocks: []parser.CodeBlock{{Language: "go", Code: "x := 1"}}},
+		},
+	}
+	if _, err := b.Build(config.DefaultConfig(), plain); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := os.Stat(runtimePath); !os.IsNotExist(err) {
+		t.Error("expected the mermaid runtime to be removed")
+	}
+	if strings.Contains(readFile(t, filepath.Join(outputDir, "index.html")), "mermaid") {
+		t.Error("index.html should not load the mermaid runtime")
+	}
+}
+
+func TestBuild_MermaidRuntimeMultiPage(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "dist")
+
+	b := NewWithOutput(outputDir)
+	b.SetMultiPage(true)
+	if _, err := b.Build(config.DefaultConfig(), parseFixture(t, "mermaid-test.md")); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// Only the pages of slides with diagrams load the runtime
+	for page, want := range map[string]bool{
+		"slide-01.html": false,
+		"slide-02.html": true,
+		"slide-03.html": false,
+		"slide-04.html": true,
+	} {
+		if got := strings.Contains(readFile(t, filepath.Join(outputDir, page)), mermaidScriptSrc); got != want {
+			t.Errorf("%s loads the mermaid runtime: %v, want %v", page, got, want)
+		}
+	}
+}
+
+func TestBuild_MermaidRuntimeSingleFile(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "dist")
+
+	b := NewWithOutput(outputDir)
+	b.SetSingleFile(true)
+	if _, err := b.Build(config.DefaultConfig(), parseFixture(t, "mermaid-test.md")); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	index := readFile(t, filepath.Join(outputDir, "index.html"))
+	if strings.Contains(index, `src="/`+MermaidRuntime+`"`) {
+		t.Error("expected the mermaid runtime to be inlined")
+	}
+	if !strings.Contains(index, `<script type="module" data-tap-mermaid>console.log("tap mermaid")`) {
+		t.Errorf("expected the inlined mermaid runtime, got:\n%s", index)
+	}
+}
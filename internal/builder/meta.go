@@ -0,0 +1,121 @@
+package builder
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+)
+
+// defaultTitle is the page title of presentations without a title.
+const defaultTitle = "Tap Presentation"
+
+// PageMeta is the metadata written into the head of a built page: its title,
+// and the Open Graph and Twitter card tags that link previews are made from.
+// Empty fields are omitted.
+type PageMeta struct {
+	Title       string
+	Description string
+	Image       string // URL of the preview image
+	URL         string // Canonical URL of the page
+}
+
+// pageMeta returns the metadata of the presentation's index page from its
+// frontmatter, with the preview image at image (see copyPreviewImage).
+func pageMeta(cfg config.Config, image string) PageMeta {
+	meta := PageMeta{
+		Title:       cfg.Title,
+		Description: strings.TrimSpace(cfg.Description),
+		URL:         cfg.URL,
+	}
+	if meta.Title == "" {
+		meta.Title = defaultTitle
+	}
+	if image != "" {
+		meta.Image = meta.resolve(image)
+	}
+	return meta
+}
+
+// forPage returns the metadata of another page of the same build, such as a
+// slide page in multi-page mode, with its own title and filename.
+func (m PageMeta) forPage(title, filename string) PageMeta {
+	page := m
+	page.Title = title
+	if m.URL != "" {
+		page.URL = m.resolve(filename)
+	}
+	return page
+}
+
+// resolve returns ref, a path in the build, as an absolute URL under the
+// canonical URL, which is treated as a directory unless it names a file.
+// Without a canonical URL, ref is returned unchanged.
+func (m PageMeta) resolve(ref string) string {
+	base, err := url.Parse(m.URL)
+	if m.URL == "" || err != nil || isAbsoluteURL(ref) {
+		return ref
+	}
+	if !strings.HasSuffix(base.Path, "/") && path.Ext(base.Path) == "" {
+		base.Path += "/"
+	}
+	target, err := base.Parse(ref)
+	if err != nil {
+		return ref
+	}
+	return target.String()
+}
+
+// HeadHTML returns the title element and metadata tags for the page, with
+// all values escaped for HTML.
+func (m PageMeta) HeadHTML() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<title>%s</title>", html.EscapeString(m.Title))
+
+	writeMeta := func(attr, key, value string) {
+		if value != "" {
+			fmt.Fprintf(&b, "\n<meta %s=\"%s\" content=\"%s\">", attr, key, html.EscapeString(value))
+		}
+	}
+	writeMeta("name", "description", m.Description)
+	writeMeta("property", "og:type", "website")
+	writeMeta("property", "og:title", m.Title)
+	writeMeta("property", "og:description", m.Description)
+	writeMeta("property", "og:url", m.URL)
+	writeMeta("property", "og:image", m.Image)
+
+	card := "summary"
+	if m.Image != "" {
+		card = "summary_large_image"
+	}
+	writeMeta("name", "twitter:card", card)
+
+	if m.URL != "" {
+		fmt.Fprintf(&b, "\n<link rel=\"canonical\" href=\"%s\">", html.EscapeString(m.URL))
+	}
+	return b.String()
+}
+
+// copyPreviewImage copies the ogImage from the frontmatter into assetsDir
+// and returns its path in the build. An absolute URL is returned as is. If
+// the image can't be copied, a warning is added and "" is returned.
+func (b *Builder) copyPreviewImage(cfg config.Config, assetsDir string, result *BuildResult) string {
+	if cfg.OGImage == "" || isAbsoluteURL(cfg.OGImage) {
+		return cfg.OGImage
+	}
+	hashedPath, err := b.copyAsset(b.resolveSourcePath(cfg.OGImage), assetsDir, result)
+	if err != nil {
+		result.Warnings = append(result.Warnings, previewImageWarning(cfg.OGImage))
+		return ""
+	}
+	return hashedPath
+}
+
+// previewImageWarning returns the build warning for an ogImage that couldn't
+// be copied.
+func previewImageWarning(path string) string {
+	return fmt.Sprintf("ogImage %s not found; link previews will have no image", path)
+}
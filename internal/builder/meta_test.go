@@ -0,0 +1,222 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+func TestPageMeta_HeadHTMLEscapes(t *testing.T) {
+	meta := PageMeta{
+		Title:       `Tom & Jerry's "Best" <Bits>`,
+		Description: `Cats & mice, "live"`,
+		Image:       "https://example.com/og.png?a=1&b=2",
+		URL:         "https://example.com/talk/?ref=a&b",
+	}
+	head := meta.HeadHTML()
+
+	for _, want := range []string{
+		`<title>Tom &amp; Jerry&#39;s &#34;Best&#34; &lt;Bits&gt;</title>`,
+		`<meta property="og:title" content="Tom &amp; Jerry&#39;s &#34;Best&#34; &lt;Bits&gt;">`,
+		`<meta name="description" content="Cats &amp; mice, &#34;live&#34;">`,
+		`<meta property="og:description" content="Cats &amp; mice, &#34;live&#34;">`,
+		`<meta property="og:image" content="https://example.com/og.png?a=1&amp;b=2">`,
+		`<meta property="og:url" content="https://example.com/talk/?ref=a&amp;b">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+		`<link rel="canonical" href="https://example.com/talk/?ref=a&amp;b">`,
+	} {
+		if !strings.Contains(head, want) {
+			t.Errorf("expected %s in:\n%s", want, head)
+		}
+	}
+	if strings.Contains(head, `"Best"`) || strings.Contains(head, "& ") {
+		t.Errorf("expected every value to be escaped, got:\n%s", head)
+	}
+}
+
+func TestPageMeta_HeadHTMLOmitsMissingFields(t *testing.T) {
+	head := pageMeta(config.Config{}, "").HeadHTML()
+
+	if !strings.Contains(head, "<title>Tap Presentation</title>") {
+		t.Errorf("expected the default title, got:\n%s", head)
+	}
+	for _, unwanted := range []string{`name="description"`, "og:description", "og:url", "og:image", "canonical"} {
+		if strings.Contains(head, unwanted) {
+			t.Errorf("expected no %s without a value, got:\n%s", unwanted, head)
+		}
+	}
+	if !strings.Contains(head, `<meta name="twitter:card" content="summary">`) {
+		t.Errorf("expected a summary card without an image, got:\n%s", head)
+	}
+}
+
+func TestPageMeta_Resolve(t *testing.T) {
+	tests := []struct {
+		url  string
+		ref  string
+		want string
+	}{
+		{"https://example.com/talk/", "assets/og.1234.png", "https://example.com/talk/assets/og.1234.png"},
+		{"https://example.com/talk", "slide-02.html", "https://example.com/talk/slide-02.html"},
+		{"https://example.com/talk/index.html", "slide-02.html", "https://example.com/talk/slide-02.html"},
+		{"https://example.com/talk/", "https://cdn.example.com/og.png", "https://cdn.example.com/og.png"},
+		{"", "assets/og.1234.png", "assets/og.1234.png"},
+	}
+
+	for _, tt := range tests {
+		if got := (PageMeta{URL: tt.url}).resolve(tt.ref); got != tt.want {
+			t.Errorf("resolve(%q) with URL %q = %q, want %q", tt.ref, tt.url, got, tt.want)
+		}
+	}
+}
+
+// newMetaBuild returns a builder for a presentation directory with an og.png
+// and a config with the link preview fields set.
+func newMetaBuild(t *testing.T) (*Builder, *config.Config, string) {
+	t.Helper()
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "og.png"), []byte("preview"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	cfg := config.DefaultConfig()
+	cfg.Title = "Q&A Session"
+	cfg.Description = "Answers to your questions"
+	cfg.OGImage = "og.png"
+	cfg.URL = "https://example.com/qa/"
+	return b, cfg, outputDir
+}
+
+func TestBuild_PageMeta(t *testing.T) {
+	b, cfg, outputDir := newMetaBuild(t)
+	pres := &parser.Presentation{Slides: []parser.Slide{{Index: 0, HTML: "<h1>Hello</h1>"}}}
+
+	result, err := b.Build(cfg, pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+
+	index := readFile(t, filepath.Join(outputDir, "index.html"))
+	image := regexp.MustCompile(`<meta property="og:image" content="https://example\.com/qa/(assets/og\.[0-9a-f]+\.png)">`).FindStringSubmatch(index)
+	if image == nil {
+		t.Fatalf("expected the preview image under the canonical URL, got:\n%s", index)
+	}
+	if readFile(t, filepath.Join(outputDir, image[1])) != "preview" {
+		t.Error("expected the preview image to be copied into assets")
+	}
+	for _, want := range []string{
+		"<title>Q&amp;A Session</title>",
+		`<meta property="og:description" content="Answers to your questions">`,
+		`<link rel="canonical" href="https://example.com/qa/">`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("expected %s in index.html", want)
+		}
+	}
+}
+
+func TestBuild_PageMetaMultiPage(t *testing.T) {
+	b, cfg, outputDir := newMetaBuild(t)
+	b.SetMultiPage(true)
+	pres := &parser.Presentation{Slides: []parser.Slide{
+		{Index: 0, HTML: "<h1>Hello</h1>"},
+		{Index: 1, HTML: "<h1>Goodbye</h1>"},
+	}}
+
+	if _, err := b.Build(cfg, pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	index := readFile(t, filepath.Join(outputDir, "index.html"))
+	if !strings.Contains(index, `<link rel="canonical" href="https://example.com/qa/">`) || !strings.Contains(index, "og:image") {
+		t.Errorf("expected the slide index to have the deck's metadata, got:\n%s", index)
+	}
+
+	second := readFile(t, filepath.Join(outputDir, "slide-02.html"))
+	for _, want := range []string{
+		`<meta property="og:title" content="Goodbye - Q&amp;A Session">`,
+		`<link rel="canonical" href="https://example.com/qa/slide-02.html">`,
+		`<meta property="og:image" content="https://example.com/qa/assets/og.`,
+	} {
+		if !strings.Contains(second, want) {
+			t.Errorf("expected %s in slide-02.html", want)
+		}
+	}
+}
+
+func TestBuild_PageMetaMissingImage(t *testing.T) {
+	b, cfg, outputDir := newMetaBuild(t)
+	cfg.OGImage = "missing.png"
+	pres := &parser.Presentation{Slides: []parser.Slide{{Index: 0, HTML: "<h1>Hello</h1>"}}}
+
+	result, err := b.Build(cfg, pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "missing.png") {
+		t.Errorf("expected a warning for the missing image, got %v", result.Warnings)
+	}
+	index := readFile(t, filepath.Join(outputDir, "index.html"))
+	if strings.Contains(index, "og:image") || !strings.Contains(index, `content="summary"`) {
+		t.Errorf("expected no preview image, got:\n%s", index)
+	}
+}
+
+func TestBuild_PageMetaSingleFile(t *testing.T) {
+	b, cfg, outputDir := newMetaBuild(t)
+	b.SetSingleFile(true)
+	pres := &parser.Presentation{Slides: []parser.Slide{{Index: 0, HTML: "<h1>Hello</h1>"}}}
+
+	result, err := b.Build(cfg, pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "absolute URL") {
+		t.Errorf("expected a warning that the local image is left out, got %v", result.Warnings)
+	}
+
+	// An absolute URL is used as is
+	cfg.OGImage = "https://cdn.example.com/og.png"
+	if _, err := b.Build(cfg, pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	index := readFile(t, filepath.Join(outputDir, "index.html"))
+	if !strings.Contains(index, `<meta property="og:image" content="https://cdn.example.com/og.png">`) {
+		t.Errorf("expected the image URL, got:\n%s", index)
+	}
+}
+
+func TestRenderPageHTML_Meta(t *testing.T) {
+	b := New()
+	pres := &transformer.TransformedPresentation{Config: config.Config{Title: "Ignored"}}
+
+	page, err := b.renderPageHTML(pres, PageMeta{Title: `"Rock" & Roll`, Description: "Loud"})
+	if err != nil {
+		t.Fatalf("renderPageHTML failed: %v", err)
+	}
+	if !strings.Contains(page, "<title>&#34;Rock&#34; &amp; Roll</title>") {
+		t.Error("expected the escaped title from the metadata")
+	}
+	if !strings.Contains(page, `<meta name="description" content="Loud">`) {
+		t.Error("expected the description in the page")
+	}
+	if strings.Index(page, "og:title") > strings.Index(page, "</head>") {
+		t.Error("expected the metadata in the head")
+	}
+}
@@ -0,0 +1,139 @@
+package builder
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// SetMultiPage enables or disables multi-page mode.
+// In multi-page mode, Build writes one HTML file per slide (slide-01.html,
+// slide-02.html, ...) with previous/next links, and an index.html that lists
+// the slides by title. Pages share the assets/ directory, and each page embeds
+// only its own slide, so they stay small and can be linked to directly.
+func (b *Builder) SetMultiPage(multiPage bool) {
+	b.multiPage = multiPage
+}
+
+// MultiPage returns whether multi-page mode is enabled.
+func (b *Builder) MultiPage() bool {
+	return b.multiPage
+}
+
+// slidePage describes a page written in multi-page mode.
+type slidePage struct {
+	slide    transformer.TransformedSlide
+	title    string
+	filename string
+}
+
+// writeSlidePages writes a page for each visible slide and an index.html that
+// lists them. Hidden slides get no page. The pages share the metadata of the
+// index, meta, with their own title and canonical URL.
+func (b *Builder) writeSlidePages(pres *transformer.TransformedPresentation, meta PageMeta, result *BuildResult) error {
+	pages := slidePages(pres)
+
+	for i, page := range pages {
+		// Embed only this slide, keeping the config and themes it needs
+		pagePres := &transformer.TransformedPresentation{
+			Themes: pres.Themes,
+			Config: pres.Config,
+			Slides: []transformer.TransformedSlide{page.slide},
+		}
+
+		content, err := b.renderPageHTML(pagePres, meta.forPage(page.title+" - "+meta.Title, page.filename))
+		if err != nil {
+			return err
+		}
+
+		var prev, next *slidePage
+		if i > 0 {
+			prev = &pages[i-1]
+		}
+		if i < len(pages)-1 {
+			next = &pages[i+1]
+		}
+		content = strings.Replace(content, "</body>", renderPageNav(prev, next)+"\n</body>", 1)
+
+		if err := b.writeOutput("page:"+page.filename, page.filename, []byte(content), result); err != nil {
+			return err
+		}
+	}
+
+	index := b.registerServiceWorker(renderSlideIndex(meta, pages))
+	return b.writeOutput("page:index.html", "index.html", []byte(index), result)
+}
+
+// slidePages returns the pages for the visible slides. Filenames use the slide
+// number, zero-padded to at least two digits so they sort in order.
+func slidePages(pres *transformer.TransformedPresentation) []slidePage {
+	titles := make(map[int]string)
+	collectTOCTitles(pres.TOC, titles)
+
+	width := len(strconv.Itoa(len(pres.Slides)))
+	if width < 2 {
+		width = 2
+	}
+
+	var pages []slidePage
+	for i, slide := range pres.Slides {
+		if slide.Hidden {
+			continue
+		}
+		title, ok := titles[slide.Index]
+		if !ok {
+			title = fmt.Sprintf("Slide %d", i+1)
+		}
+		pages = append(pages, slidePage{
+			slide:    slide,
+			title:    title,
+			filename: fmt.Sprintf("slide-%0*d.html", width, i+1),
+		})
+	}
+	return pages
+}
+
+// collectTOCTitles maps slide indices to their titles from the table of contents.
+func collectTOCTitles(entries []transformer.TOCEntry, titles map[int]string) {
+	for _, entry := range entries {
+		titles[entry.SlideIndex] = entry.Title
+		collectTOCTitles(entry.Children, titles)
+	}
+}
+
+// renderPageNav returns the navigation links added to each slide page.
+func renderPageNav(prev, next *slidePage) string {
+	var b strings.Builder
+	b.WriteString(`<nav class="tap-page-nav" style="position:fixed;bottom:0.5rem;left:0.5rem;z-index:1000;font:14px sans-serif;display:flex;gap:1rem">`)
+	if prev != nil {
+		fmt.Fprintf(&b, `<a href="%s" rel="prev">&larr; %s</a>`, prev.filename, html.EscapeString(prev.title))
+	}
+	b.WriteString(`<a href="index.html">All slides</a>`)
+	if next != nil {
+		fmt.Fprintf(&b, `<a href="%s" rel="next">%s &rarr;</a>`, next.filename, html.EscapeString(next.title))
+	}
+	b.WriteString(`</nav>`)
+	return b.String()
+}
+
+// renderSlideIndex returns the index.html for multi-page builds, with the
+// deck's metadata, listing every slide page by title.
+func renderSlideIndex(meta PageMeta, pages []slidePage) string {
+	title := html.EscapeString(meta.Title)
+
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html lang=\"en\">\n<head>\n")
+	b.WriteString("<meta charset=\"UTF-8\">\n")
+	b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1.0\">\n")
+	b.WriteString(meta.HeadHTML() + "\n")
+	b.WriteString("</head>\n<body>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n<ol>\n", title)
+	for _, page := range pages {
+		fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", page.filename, html.EscapeString(page.title))
+	}
+	b.WriteString("</ol>\n</body>\n</html>\n")
+	return b.String()
+}
@@ -0,0 +1,153 @@
+package builder
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+func TestSetMultiPage(t *testing.T) {
+	b := New()
+	if b.MultiPage() {
+		t.Error("expected multi-page mode to be disabled by default")
+	}
+	b.SetMultiPage(true)
+	if !b.MultiPage() {
+		t.Error("expected multi-page mode to be enabled")
+	}
+}
+
+func TestBuild_MultiPage(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "photo.png"), []byte("fake png content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	b.SetMultiPage(true)
+	cfg := config.DefaultConfig()
+	cfg.Title = "My Deck"
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<h1>Welcome</h1><img src="photo.png">`},
+			{Index: 1, HTML: `<p>No heading</p><img src="photo.png">`},
+			{Index: 2, HTML: `<h1>Secret</h1>`, Directives: parser.SlideDirectives{Hidden: true}},
+			{Index: 3, HTML: `<h2>Q &amp; A</h2><p>Questions</p>`},
+		},
+	}
+
+	if _, err := b.Build(cfg, pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	// The shared image is copied once
+	images, err := filepath.Glob(filepath.Join(outputDir, "assets", "photo.*.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 1 {
+		t.Errorf("expected image to be copied once, got %v", images)
+	}
+
+	// Hidden slides get no page
+	if _, err := os.Stat(filepath.Join(outputDir, "slide-03.html")); !os.IsNotExist(err) {
+		t.Error("expected no page for the hidden slide")
+	}
+
+	first := readFile(t, filepath.Join(outputDir, "slide-01.html"))
+	if !strings.Contains(first, "<title>Welcome - My Deck</title>") {
+		t.Error("expected slide page title to include the slide title")
+	}
+	if strings.Contains(first, `rel="prev"`) || !strings.Contains(first, `<a href="slide-02.html" rel="next">Slide 2 &rarr;</a>`) {
+		t.Error("expected first page to link only to the next page")
+	}
+
+	second := readFile(t, filepath.Join(outputDir, "slide-02.html"))
+	if !strings.Contains(second, `href="slide-01.html" rel="prev"`) || !strings.Contains(second, `href="slide-04.html" rel="next"`) {
+		t.Error("expected second page to link around the hidden slide")
+	}
+
+	// Each page embeds only its own slide, with rewritten asset paths
+	embedded := embeddedPresentation(t, second)
+	if len(embedded.Slides) != 1 || embedded.Slides[0].Index != 1 {
+		t.Fatalf("expected only slide 2 in the page data, got %+v", embedded.Slides)
+	}
+	if !strings.Contains(embedded.Slides[0].HTML, `src="assets/photo.`) {
+		t.Errorf("expected rewritten image path, got %s", embedded.Slides[0].HTML)
+	}
+	if embedded.Config.Title != "My Deck" {
+		t.Errorf("expected config in the page data, got title %q", embedded.Config.Title)
+	}
+
+	index := readFile(t, filepath.Join(outputDir, "index.html"))
+	for _, want := range []string{
+		`<a href="slide-01.html">Welcome</a>`,
+		`<a href="slide-02.html">Slide 2</a>`,
+		`<a href="slide-04.html">Q &amp; A</a>`,
+	} {
+		if !strings.Contains(index, want) {
+			t.Errorf("expected index to contain %q, got:\n%s", want, index)
+		}
+	}
+	if strings.Contains(index, "Secret") {
+		t.Error("expected hidden slide to be left out of the index")
+	}
+}
+
+func TestBuild_MultiPageWithSingleFile(t *testing.T) {
+	b := NewWithOutput(t.TempDir())
+	b.SetSingleFile(true)
+	b.SetMultiPage(true)
+
+	if _, err := b.Build(config.DefaultConfig(), &parser.Presentation{}); err == nil {
+		t.Error("expected error when combining single-file and multi-page modes")
+	}
+}
+
+func TestSlidePages_FilenameWidth(t *testing.T) {
+	pres := &transformer.TransformedPresentation{Slides: make([]transformer.TransformedSlide, 120)}
+	for i := range pres.Slides {
+		pres.Slides[i].Index = i
+	}
+
+	pages := slidePages(pres)
+	if pages[0].filename != "slide-001.html" || pages[119].filename != "slide-120.html" {
+		t.Errorf("unexpected filenames %q, %q", pages[0].filename, pages[119].filename)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return string(content)
+}
+
+func embeddedPresentation(t *testing.T, page string) transformer.TransformedPresentation {
+	t.Helper()
+	match := regexp.MustCompile(`(?s)<script id="presentation-data" type="application/json">(.*?)</script>`).FindStringSubmatch(page)
+	if match == nil {
+		t.Fatal("expected embedded presentation data")
+	}
+	var pres transformer.TransformedPresentation
+	if err := json.Unmarshal([]byte(match[1]), &pres); err != nil {
+		t.Fatalf("failed to decode embedded presentation: %v", err)
+	}
+	return pres
+}
@@ -0,0 +1,120 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ServiceWorkerFileName is the name of the service worker written by offline builds.
+const ServiceWorkerFileName = "sw.js"
+
+// serviceWorkerScript registers the service worker. It is added to every
+// page of offline builds.
+const serviceWorkerScript = `<script>if ('serviceWorker' in navigator) { window.addEventListener('load', function () { navigator.serviceWorker.register('./` + ServiceWorkerFileName + `'); }); }</script>`
+
+// serviceWorkerTemplate is the service worker of offline builds. It precaches
+// the files of the build on install, serves them from the cache, and deletes
+// the caches of other builds on activation. The placeholders are the cache
+// name and the JSON array of files to precache.
+const serviceWorkerTemplate = `// Generated by tap build --offline. Precaches the presentation so it works offline.
+const CACHE = %q;
+const FILES = %s;
+
+self.addEventListener('install', (event) => {
+  event.waitUntil(
+    caches.open(CACHE)
+      .then((cache) => cache.addAll(FILES))
+      .then(() => self.skipWaiting())
+  );
+});
+
+self.addEventListener('activate', (event) => {
+  event.waitUntil(
+    caches.keys()
+      .then((keys) => Promise.all(keys.filter((key) => key.startsWith('tap-') && key !== CACHE).map((key) => caches.delete(key))))
+      .then(() => self.clients.claim())
+  );
+});
+
+self.addEventListener('fetch', (event) => {
+  if (event.request.method !== 'GET') {
+    return;
+  }
+  event.respondWith(
+    caches.match(event.request, { ignoreSearch: true })
+      .then((cached) => cached || fetch(event.request))
+  );
+});
+`
+
+// SetOffline enables or disables offline mode.
+// In offline mode, Build writes a service worker (sw.js) that precaches the
+// pages and every file in assets/, and registers it from each page, so the
+// presentation works without a network after the first load. The cache is
+// named after a hash of the files written, so redeploying a changed build
+// replaces the old cache.
+func (b *Builder) SetOffline(offline bool) {
+	b.offline = offline
+}
+
+// Offline returns whether offline mode is enabled.
+func (b *Builder) Offline() bool {
+	return b.offline
+}
+
+// registerServiceWorker adds the service worker registration to a page in
+// offline mode, and returns the page unchanged otherwise.
+func (b *Builder) registerServiceWorker(page string) string {
+	if !b.offline {
+		return page
+	}
+	return strings.Replace(page, "</body>", serviceWorkerScript+"\n</body>", 1)
+}
+
+// writeServiceWorker writes the service worker for the files recorded in the
+// current build's manifest, which must already hold every other file of the build.
+func (b *Builder) writeServiceWorker(result *BuildResult) error {
+	content, err := renderServiceWorker(b.manifest)
+	if err != nil {
+		return err
+	}
+	if err := b.writeOutput("page:"+ServiceWorkerFileName, ServiceWorkerFileName, content, result); err != nil {
+		return err
+	}
+	result.Offline = true
+	return nil
+}
+
+// renderServiceWorker returns the service worker that precaches the outputs
+// of m. The cache version is a hash of the outputs and their content hashes.
+func renderServiceWorker(m *manifest) ([]byte, error) {
+	outputs := make([]string, 0, len(m.Files))
+	hashes := make(map[string]string, len(m.Files))
+	for _, entry := range m.Files {
+		output := filepath.ToSlash(entry.Output)
+		if _, exists := hashes[output]; !exists {
+			outputs = append(outputs, output)
+		}
+		hashes[output] = entry.Hash
+	}
+	sort.Strings(outputs)
+
+	// The root URL serves index.html, so cache it too
+	files := []string{"./"}
+	var version strings.Builder
+	for _, output := range outputs {
+		files = append(files, "./"+output)
+		version.WriteString(output + " " + hashes[output] + "\n")
+	}
+
+	filesJSON, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal service worker files: %w", err)
+	}
+
+	cache := "tap-" + contentHash([]byte(version.String()))[:12]
+	return []byte(fmt.Sprintf(serviceWorkerTemplate, cache, filesJSON)), nil
+}
@@ -0,0 +1,166 @@
+package builder
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// cachePattern matches the cache name in a service worker.
+var cachePattern = regexp.MustCompile(`const CACHE = "(tap-[0-9a-f]+)";`)
+
+func TestSetOffline(t *testing.T) {
+	b := New()
+	if b.Offline() {
+		t.Error("expected offline mode to be disabled by default")
+	}
+	b.SetOffline(true)
+	if !b.Offline() {
+		t.Error("expected offline mode to be enabled")
+	}
+}
+
+// offlineTestBuild builds a presentation with one image into outputDir.
+func offlineTestBuild(t *testing.T, b *Builder) *BuildResult {
+	t.Helper()
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<h1>Welcome</h1><img src="photo.png">`},
+			{Index: 1, HTML: `<h2>Thanks</h2>`},
+		},
+	}
+	result, err := b.Build(config.DefaultConfig(), pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	return result
+}
+
+func TestBuild_Offline(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	photo := filepath.Join(tmpDir, "photo.png")
+	if err := os.WriteFile(photo, []byte("fake png content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(tmpDir)
+	b.SetOffline(true)
+	result := offlineTestBuild(t, b)
+
+	if !result.Offline {
+		t.Error("expected the result to note offline mode")
+	}
+
+	sw := readFile(t, filepath.Join(outputDir, ServiceWorkerFileName))
+
+	// Every file in assets/, including the hashed image, is precached
+	var assets []string
+	err := filepath.WalkDir(filepath.Join(outputDir, "assets"), func(path string, d os.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(outputDir, path)
+		if err != nil {
+			return err
+		}
+		assets = append(assets, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`assets/photo\.[0-9a-f]{8}\.png`).MatchString(strings.Join(assets, "\n")) {
+		t.Fatalf("expected the hashed image in assets/, got %v", assets)
+	}
+	for _, asset := range assets {
+		if !strings.Contains(sw, `"./`+asset+`"`) {
+			t.Errorf("service worker should precache %s", asset)
+		}
+	}
+	for _, file := range []string{`"./"`, `"./index.html"`} {
+		if !strings.Contains(sw, file) {
+			t.Errorf("service worker should precache %s", file)
+		}
+	}
+	if strings.Contains(sw, ManifestFileName) || strings.Contains(sw, `"./`+ServiceWorkerFileName+`"`) {
+		t.Error("service worker should not precache the manifest or itself")
+	}
+
+	index := readFile(t, filepath.Join(outputDir, "index.html"))
+	if !strings.Contains(index, "navigator.serviceWorker.register('./sw.js')") {
+		t.Error("index.html should register the service worker")
+	}
+
+	// An unchanged rebuild keeps the cache; a changed image replaces it
+	version := cachePattern.FindStringSubmatch(sw)
+	if version == nil {
+		t.Fatalf("expected a versioned cache name, got:\n%s", sw)
+	}
+	offlineTestBuild(t, b)
+	if got := cachePattern.FindStringSubmatch(readFile(t, filepath.Join(outputDir, ServiceWorkerFileName))); got == nil || got[1] != version[1] {
+		t.Errorf("unchanged rebuild changed the cache to %v, want %s", got, version[1])
+	}
+
+	if err := os.WriteFile(photo, []byte("different png content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	offlineTestBuild(t, b)
+	if got := cachePattern.FindStringSubmatch(readFile(t, filepath.Join(outputDir, ServiceWorkerFileName))); got == nil || got[1] == version[1] {
+		t.Errorf("changed build should use a new cache, got %v", got)
+	}
+
+	// Turning offline mode off removes the service worker and its registration
+	b.SetOffline(false)
+	if result := offlineTestBuild(t, b); result.Offline {
+		t.Error("expected the result not to note offline mode")
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, ServiceWorkerFileName)); !os.IsNotExist(err) {
+		t.Error("expected sw.js to be removed")
+	}
+	if strings.Contains(readFile(t, filepath.Join(outputDir, "index.html")), "serviceWorker") {
+		t.Error("index.html should not register a service worker")
+	}
+}
+
+func TestBuild_OfflineMultiPage(t *testing.T) {
+	outputDir := filepath.Join(t.TempDir(), "dist")
+
+	b := NewWithOutput(outputDir)
+	b.SetMultiPage(true)
+	b.SetOffline(true)
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<h1>Welcome</h1>`},
+			{Index: 1, HTML: `<h2>Thanks</h2>`},
+		},
+	}
+	if _, err := b.Build(config.DefaultConfig(), pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	sw := readFile(t, filepath.Join(outputDir, ServiceWorkerFileName))
+	for _, page := range []string{"index.html", "slide-01.html", "slide-02.html"} {
+		if !strings.Contains(sw, `"./`+page+`"`) {
+			t.Errorf("service worker should precache %s", page)
+		}
+		if !strings.Contains(readFile(t, filepath.Join(outputDir, page)), "navigator.serviceWorker.register") {
+			t.Errorf("%s should register the service worker", page)
+		}
+	}
+}
+
+func TestBuild_OfflineSingleFile(t *testing.T) {
+	b := NewWithOutput(filepath.Join(t.TempDir(), "dist"))
+	b.SetSingleFile(true)
+	b.SetOffline(true)
+	if _, err := b.Build(config.DefaultConfig(), &parser.Presentation{}); err == nil {
+		t.Error("expected an error when combining single-file and offline builds")
+	}
+}
@@ -0,0 +1,255 @@
+package builder
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/embedded"
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// LargeAssetThreshold is the size above which an inlined asset produces a
+// warning in single-file builds. Inlining is still performed.
+const LargeAssetThreshold = 5 * 1024 * 1024 // 5MB
+
+// SetSingleFile enables or disables single-file mode.
+// In single-file mode, Build writes one self-contained index.html with all
+// images inlined as data URIs and all JS/CSS inlined into the document.
+func (b *Builder) SetSingleFile(singleFile bool) {
+	b.singleFile = singleFile
+}
+
+// SingleFile returns whether single-file mode is enabled.
+func (b *Builder) SingleFile() bool {
+	return b.singleFile
+}
+
+// buildSingleFile generates a single self-contained index.html.
+func (b *Builder) buildSingleFile(cfg *config.Config, pres *parser.Presentation, startTime time.Time) (*BuildResult, error) {
+	result := &BuildResult{
+		OutputDir: b.outputDir,
+	}
+
+	if err := os.MkdirAll(b.outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Transform presentation to frontend-ready format
+	trans := transformer.NewWithBaseDir(cfg, b.baseDir)
+	transformed := trans.Transform(pres)
+	result.Warnings = append(result.Warnings, trans.Warnings(pres)...)
+	if numbers := transformer.OverflowingSlides(transformed); len(numbers) > 0 {
+		result.Warnings = append(result.Warnings, overflowWarning(numbers))
+	}
+
+	// Inline all referenced images and .cast files as data URIs
+	pathMapping := make(map[string]string)
+	for i := range transformed.Slides {
+		slide := &transformed.Slides[i]
+		paths := append(extractImagePaths(slide.HTML+slide.NotesHTML), extractAsciinemaPaths(slide.HTML)...)
+		for _, assetPath := range paths {
+			if _, exists := pathMapping[assetPath]; exists {
+				continue
+			}
+
+			// Absolute URLs are left as references
+			if isAbsoluteURL(assetPath) || strings.HasPrefix(assetPath, "data:") {
+				continue
+			}
+
+			content, err := os.ReadFile(b.resolveSourcePath(assetPath))
+			if err != nil {
+				// Skip assets that can't be found
+				continue
+			}
+
+			if len(content) > LargeAssetThreshold {
+				result.Warnings = append(result.Warnings, fmt.Sprintf(
+					"%s is %d bytes and was inlined; consider compressing it", assetPath, len(content)))
+			}
+
+			pathMapping[assetPath] = toDataURI(assetPath, content)
+		}
+	}
+
+	b.inlineCastFiles(transformed, result)
+
+	// Inline background images and videos set via slide directives
+	for i := range transformed.Slides {
+		bg := transformed.Slides[i].Background
+		if !isLocalBackgroundFile(bg) {
+			continue
+		}
+		if dataURI, exists := pathMapping[bg.Value]; exists {
+			bg.Value = dataURI
+			continue
+		}
+
+		content, err := os.ReadFile(b.resolveSourcePath(bg.Value))
+		if err != nil {
+			result.Warnings = append(result.Warnings, backgroundWarning(i, bg))
+			continue
+		}
+		if len(content) > LargeAssetThreshold {
+			result.Warnings = append(result.Warnings, fmt.Sprintf(
+				"%s is %d bytes and was inlined; consider compressing it", bg.Value, len(content)))
+		}
+
+		pathMapping[bg.Value] = toDataURI(bg.Value, content)
+		bg.Value = pathMapping[bg.Value]
+	}
+
+	// Inline custom theme stylesheets
+	for _, name := range themeNames(transformed.Themes) {
+		stylesheet := transformed.Themes[name]
+		content, err := os.ReadFile(b.resolveSourcePath(stylesheet))
+		if err != nil {
+			result.Warnings = append(result.Warnings, themeWarning(name))
+			delete(transformed.Themes, name)
+			continue
+		}
+		transformed.Themes[name] = toDataURI(stylesheet, content)
+	}
+
+	for i := range transformed.Slides {
+		rewriteSlidePaths(&transformed.Slides[i], pathMapping)
+	}
+
+	// The preview image can't be inlined, since link previews need a URL
+	previewImage := transformed.Config.OGImage
+	if previewImage != "" && !isAbsoluteURL(previewImage) {
+		result.Warnings = append(result.Warnings, fmt.Sprintf(
+			"ogImage %s is left out of single-file builds; use an absolute URL for link previews", previewImage))
+		previewImage = ""
+	}
+
+	html, err := b.renderPageHTML(transformed, pageMeta(transformed.Config, previewImage))
+	if err != nil {
+		return nil, err
+	}
+	html = inlineFrontendAssets(html, embedded.GetFile)
+
+	indexPath := filepath.Join(b.outputDir, "index.html")
+	if err := os.WriteFile(indexPath, []byte(html), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write index.html: %w", err)
+	}
+
+	// The manifest no longer describes the output directory
+	if err := b.removeManifest(); err != nil {
+		return nil, err
+	}
+
+	result.FileCount = 1
+	result.FilesCopied = 1
+	result.TotalSize = int64(len(html))
+	result.BuildTime = time.Since(startTime)
+	return result, nil
+}
+
+// resolveSourcePath converts a transformed asset path back to a path on disk.
+// The transformer rewrites relative paths to /local/... URLs for the dev server,
+// so the prefix is stripped and the result is resolved against the base directory.
+func (b *Builder) resolveSourcePath(assetPath string) string {
+	resolvedPath := strings.TrimPrefix(assetPath, "/local/")
+	if !filepath.IsAbs(resolvedPath) && b.baseDir != "" {
+		return filepath.Join(b.baseDir, resolvedPath)
+	}
+	return resolvedPath
+}
+
+// toDataURI encodes file content as a base64 data URI.
+func toDataURI(path string, content []byte) string {
+	return "data:" + mimeTypeForPath(path) + ";base64," + base64.StdEncoding.EncodeToString(content)
+}
+
+// mimeTypeForPath returns the MIME type used for data URIs based on file extension.
+func mimeTypeForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return "image/png"
+	case ".jpg", ".jpeg":
+		return "image/jpeg"
+	case ".gif":
+		return "image/gif"
+	case ".webp":
+		return "image/webp"
+	case ".svg":
+		return "image/svg+xml"
+	case ".mp4":
+		return "video/mp4"
+	case ".webm":
+		return "video/webm"
+	case ".mov":
+		return "video/quicktime"
+	case ".cast", ".json":
+		return "application/json"
+	case ".css":
+		return "text/css"
+	case ".woff":
+		return "font/woff"
+	case ".woff2":
+		return "font/woff2"
+	case ".ttf":
+		return "font/ttf"
+	default:
+		return "application/octet-stream"
+	}
+}
+
+// scriptTagPattern matches Vite-emitted module script tags referencing /assets/.
+var scriptTagPattern = regexp.MustCompile(`<script([^>]*)\ssrc="/?(assets/[^"]+\.js)"([^>]*)></script>`)
+
+// stylesheetTagPattern matches Vite-emitted stylesheet links referencing /assets/.
+var stylesheetTagPattern = regexp.MustCompile(`<link[^>]*rel="stylesheet"[^>]*href="/?(assets/[^"]+\.css)"[^>]*>`)
+
+// modulePreloadPattern matches modulepreload links, which are meaningless once scripts are inlined.
+var modulePreloadPattern = regexp.MustCompile(`<link[^>]*rel="modulepreload"[^>]*>\s*`)
+
+// cssURLPattern matches url(...) references to bundled assets inside CSS.
+var cssURLPattern = regexp.MustCompile(`url\((?:"|')?(?:/assets/|\./)([^"')]+)(?:"|')?\)`)
+
+// inlineFrontendAssets replaces external JS and CSS references in the Vite-built
+// template with inline <script> and <style> elements. Assets referenced from the
+// CSS (such as fonts) are inlined as data URIs. References that cannot be read
+// are left untouched.
+func inlineFrontendAssets(html string, readFile func(name string) ([]byte, error)) string {
+	html = modulePreloadPattern.ReplaceAllString(html, "")
+
+	html = stylesheetTagPattern.ReplaceAllStringFunc(html, func(match string) string {
+		name := stylesheetTagPattern.FindStringSubmatch(match)[1]
+		content, err := readFile(name)
+		if err != nil {
+			return match
+		}
+		css := cssURLPattern.ReplaceAllStringFunc(string(content), func(ref string) string {
+			assetName := cssURLPattern.FindStringSubmatch(ref)[1]
+			data, err := readFile("assets/" + assetName)
+			if err != nil {
+				return ref
+			}
+			return "url(" + toDataURI(assetName, data) + ")"
+		})
+		return "<style>" + css + "</style>"
+	})
+
+	html = scriptTagPattern.ReplaceAllStringFunc(html, func(match string) string {
+		submatches := scriptTagPattern.FindStringSubmatch(match)
+		content, err := readFile(submatches[2])
+		if err != nil {
+			return match
+		}
+		attrs := strings.ReplaceAll(submatches[1]+submatches[3], " crossorigin", "")
+		// Escape closing script tags so the inlined code cannot terminate the element early
+		js := strings.ReplaceAll(string(content), "</script", `<\/script`)
+		return "<script" + attrs + ">" + js + "</script>"
+	})
+
+	return html
+}
@@ -0,0 +1,296 @@
+package builder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/themes"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+func TestSetSingleFile(t *testing.T) {
+	b := New()
+	if b.SingleFile() {
+		t.Error("expected single-file mode to be disabled by default")
+	}
+	b.SetSingleFile(true)
+	if !b.SingleFile() {
+		t.Error("expected single-file mode to be enabled")
+	}
+}
+
+func TestBuild_SingleFileWritesOnlyIndex(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	imgContent := []byte("fake png content")
+	if err := os.WriteFile(filepath.Join(baseDir, "photo.png"), imgContent, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	b.SetSingleFile(true)
+	cfg := config.DefaultConfig()
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<img src="photo.png"><img src="https://example.com/remote.png">`},
+		},
+	}
+
+	result, err := b.Build(cfg, pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if result.FileCount != 1 {
+		t.Errorf("expected FileCount 1, got %d", result.FileCount)
+	}
+
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "index.html" {
+		t.Errorf("expected only index.html in output, got %v", entries)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.TotalSize != int64(len(content)) {
+		t.Errorf("expected TotalSize %d, got %d", len(content), result.TotalSize)
+	}
+
+	html := string(content)
+	if !strings.Contains(html, "data:image/png;base64,") {
+		t.Error("expected local image to be inlined as a data URI")
+	}
+	if !strings.Contains(html, "https://example.com/remote.png") {
+		t.Error("expected absolute URL to be left unchanged")
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+}
+
+func TestBuild_SingleFileInlinesColumnImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	if err := os.WriteFile(filepath.Join(tmpDir, "a.png"), []byte("fake png content"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(tmpDir)
+	b.SetSingleFile(true)
+	pres, err := parser.New().Parse([]byte("<!-- layout: three-column -->\n\nLeft\n\n|||\n\n![a](a.png)\n\n|||\n\nRight"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Build(config.DefaultConfig(), pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := regexp.MustCompile(`(?s)<script id="presentation-data" type="application/json">(.*?)</script>`).FindSubmatch(content)
+	if match == nil {
+		t.Fatal("presentation data not found in index.html")
+	}
+	var data transformer.TransformedPresentation
+	if err := json.Unmarshal(match[1], &data); err != nil {
+		t.Fatalf("failed to decode presentation data: %v", err)
+	}
+
+	// The columns have the image inlined, like the slide HTML
+	columns := data.Slides[0].Columns
+	if len(columns) != 3 {
+		t.Fatalf("expected 3 columns, got %q", columns)
+	}
+	if !strings.Contains(columns[1], `src="data:image/png;base64,`) {
+		t.Errorf("column image should be inlined as a data URI, got %q", columns[1])
+	}
+}
+
+func TestBuild_SingleFileWarnsOnLargeImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+
+	large := make([]byte, LargeAssetThreshold+1)
+	if err := os.WriteFile(filepath.Join(tmpDir, "huge.png"), large, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(tmpDir)
+	b.SetSingleFile(true)
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<img src="huge.png">`},
+		},
+	}
+
+	result, err := b.Build(config.DefaultConfig(), pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if len(result.Warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", result.Warnings)
+	}
+	if !strings.Contains(result.Warnings[0], "huge.png") {
+		t.Errorf("expected warning to mention huge.png, got %q", result.Warnings[0])
+	}
+}
+
+func TestBuild_SingleFileInlinesBackgroundImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(baseDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "hero.png"), []byte("hero"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	b.SetSingleFile(true)
+	cfg := config.DefaultConfig()
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>Hero</h1>", Directives: parser.SlideDirectives{Background: "hero.png"}},
+			{Index: 1, HTML: "<h1>Missing</h1>", Directives: parser.SlideDirectives{Background: "missing.png"}},
+		},
+	}
+
+	result, err := b.Build(cfg, pres)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "data:image/png;base64,aGVybw==") {
+		t.Error("background image should be inlined as a data URI")
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "slide 2") {
+		t.Errorf("expected a warning for the missing background on slide 2, got %v", result.Warnings)
+	}
+}
+
+func TestBuild_SingleFileInlinesCustomThemes(t *testing.T) {
+	tmpDir := t.TempDir()
+	outputDir := filepath.Join(tmpDir, "dist")
+	baseDir := filepath.Join(tmpDir, "presentation")
+
+	if err := os.MkdirAll(filepath.Join(baseDir, "themes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(baseDir, "themes", "brand.css"), []byte("css"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewWithOutput(outputDir)
+	b.SetBaseDir(baseDir)
+	b.SetSingleFile(true)
+	cfg := config.DefaultConfig()
+	cfg.SetCustomThemes([]themes.Theme{{Name: "brand", Path: filepath.Join(baseDir, "themes", "brand.css")}})
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{{Index: 0, HTML: "<h1>Brand</h1>"}},
+	}
+
+	if _, err := b.Build(cfg, pres); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(outputDir, "index.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `"brand":"data:text/css;base64,Y3Nz"`) {
+		t.Error("custom theme stylesheet should be inlined as a data URI")
+	}
+}
+
+func TestInlineFrontendAssets(t *testing.T) {
+	files := map[string]string{
+		"assets/main.js":     `console.log("</script>")`,
+		"assets/main.css":    `@font-face{src:url(./inter.woff2)}body{background:url(/assets/bg.png)}`,
+		"assets/inter.woff2": "font",
+		"assets/bg.png":      "png",
+	}
+	readFile := func(name string) ([]byte, error) {
+		if content, ok := files[name]; ok {
+			return []byte(content), nil
+		}
+		return nil, fmt.Errorf("not found: %s", name)
+	}
+
+	html := `<head>
+<script type="module" crossorigin src="/assets/main.js"></script>
+<link rel="modulepreload" crossorigin href="/assets/chunk.js">
+<link rel="stylesheet" crossorigin href="/assets/main.css">
+<link rel="stylesheet" href="/assets/missing.css">
+</head>`
+
+	result := inlineFrontendAssets(html, readFile)
+
+	if strings.Contains(result, `src="/assets/main.js"`) {
+		t.Error("expected script reference to be inlined")
+	}
+	if !strings.Contains(result, `<script type="module">console.log("<\/script>")</script>`) {
+		t.Errorf("expected inlined, escaped script, got:\n%s", result)
+	}
+	if strings.Contains(result, "modulepreload") {
+		t.Error("expected modulepreload links to be removed")
+	}
+	if !strings.Contains(result, "url(data:font/woff2;base64,") {
+		t.Error("expected font reference in CSS to be inlined")
+	}
+	if !strings.Contains(result, "url(data:image/png;base64,") {
+		t.Error("expected image reference in CSS to be inlined")
+	}
+	if !strings.Contains(result, `href="/assets/missing.css"`) {
+		t.Error("expected unreadable stylesheet reference to be left unchanged")
+	}
+}
+
+func TestMimeTypeForPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"a.png", "image/png"},
+		{"a.JPG", "image/jpeg"},
+		{"a.svg", "image/svg+xml"},
+		{"demo.cast", "application/json"},
+		{"font.woff2", "font/woff2"},
+		{"unknown.bin", "application/octet-stream"},
+	}
+
+	for _, tt := range tests {
+		if got := mimeTypeForPath(tt.path); got != tt.expected {
+			t.Errorf("mimeTypeForPath(%q) = %q, want %q", tt.path, got, tt.expected)
+		}
+	}
+}
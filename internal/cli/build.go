@@ -2,6 +2,7 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,13 +12,47 @@ import (
 	"github.com/MiniCodeMonkey/tap/internal/builder"
 	"github.com/MiniCodeMonkey/tap/internal/config"
 	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/report"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
 )
 
+// errStrictWarnings is returned by buildPresentation when the frontmatter has
+// warnings in strict mode.
+var errStrictWarnings = errors.New("frontmatter warnings in strict mode")
+
+// validationError is an error caused by an invalid presentation or
+// configuration rather than by the operation failing, so the command exits
+// with report.ExitValidation.
+type validationError struct {
+	err error
+}
+
+func (e *validationError) Error() string { return e.err.Error() }
+func (e *validationError) Unwrap() error { return e.err }
+
+// isValidationError reports whether err means the presentation or its
+// configuration is invalid, including frontmatter warnings in strict mode.
+func isValidationError(err error) bool {
+	var invalid *validationError
+	return errors.As(err, &invalid) || errors.Is(err, errStrictWarnings)
+}
+
 // Flags for the build command
 var (
-	buildOutput string
+	buildOutput     string
+	buildSingleFile bool
+	buildMultiPage  bool
+	buildOffline    bool
+	buildStrict     bool
+	buildForce      bool
+	buildWatch      bool
+	buildLang       string
 )
 
+// buildOverrides are the build flags that take precedence over tap.yaml and
+// the frontmatter.
+var buildOverrides config.Overrides
+
 // buildCmd represents the build command
 var buildCmd = &cobra.Command{
 	Use:   "build <file>",
@@ -33,12 +68,60 @@ The generated files include:
   - All referenced images and assets
   - Necessary JavaScript and CSS
 
+Use --single-file to produce one self-contained index.html with all
+images, scripts, and styles inlined. This is handy for emailing a deck.
+
+Use --multi-page to write one page per slide (slide-01.html, slide-02.html,
+...) with previous/next links and an index.html listing every slide, so
+individual slides can be linked to and indexed.
+
+Use --offline to add a service worker (sw.js) that caches the pages and
+assets on the first load, so the presentation keeps working without a
+network, for example on conference Wi-Fi. Redeploying a changed build
+replaces the cached copy.
+
+Builds are incremental: a manifest (.tap-manifest.json) in the output
+directory records what was written, so unchanged files are not copied
+again and files that are no longer needed are removed. Use --force to
+ignore the manifest and rewrite every file.
+
+Each build also writes manifest.json, which maps every file to its output
+path and a sha384 subresource integrity hash for deploy pipelines. The
+scripts and stylesheets of the bundled frontend are loaded with integrity
+attributes, so browsers refuse them if a CDN serves changed files.
+
+Problems in the frontmatter, such as unknown keys, are reported as warnings.
+Use --strict to fail the build on them instead, for example in CI.
+
+Use --json (or TAP_OUTPUT=json) to write progress and the result as JSON
+lines for CI. An invalid presentation or configuration exits with code 2,
+other failures with 1.
+
+Use --watch to keep the output up to date while you edit: the presentation
+is rebuilt incrementally whenever the markdown file, its includes, the
+images and themes directories, or the custom theme change. Failed rebuilds
+are reported and retried on the next change. Press Ctrl+C to stop.
+
+The output directory can also be set with the output option in tap.yaml or
+the frontmatter; --output takes precedence over both.
+
+For presentations in several languages, --lang selects the language to
+build; it defaults to the first of the languages frontmatter option.
+
 Note: Live code execution is not available in static builds.
 
 Examples:
   tap build slides.md                   # Build to dist/ directory
   tap build slides.md --output public   # Build to custom directory
-  tap build slides.md -o ./build        # Short form`,
+  tap build slides.md -o ./build        # Short form
+  tap build slides.md --single-file     # One self-contained index.html
+  tap build slides.md --multi-page      # One HTML page per slide
+  tap build slides.md --offline         # Works offline after the first load
+  tap build slides.md --strict          # Fail on frontmatter warnings
+  tap build slides.md --force           # Rewrite every file
+  tap build slides.md --watch           # Rebuild on every change
+  tap build slides.md --lang da         # Build the Danish version
+  tap build slides.md --json            # JSON lines for CI`,
 	Args: cobra.ExactArgs(1),
 	Run:  runBuild,
 }
@@ -46,144 +129,174 @@ Examples:
 func init() {
 	// Register the build command with root
 	rootCmd.AddCommand(buildCmd)
+	addJSONFlag(buildCmd)
 
 	// Command-specific flags
-	buildCmd.Flags().StringVarP(&buildOutput, "output", "o", "dist", "output directory for static files")
+	buildCmd.Flags().StringVarP(&buildOutput, "output", "o", config.DefaultOutputDir, "output directory for static files")
+	buildCmd.Flags().BoolVar(&buildSingleFile, "single-file", false, "inline all assets into a single index.html")
+	buildCmd.Flags().BoolVar(&buildMultiPage, "multi-page", false, "write one HTML page per slide")
+	buildCmd.Flags().BoolVar(&buildOffline, "offline", false, "add a service worker so the build works offline")
+	buildCmd.Flags().BoolVar(&buildStrict, "strict", false, "fail on frontmatter warnings such as unknown keys")
+	buildCmd.Flags().BoolVar(&buildForce, "force", false, "rewrite every file, ignoring the previous build's manifest")
+	buildCmd.Flags().BoolVarP(&buildWatch, "watch", "w", false, "rebuild when the presentation or its assets change")
+	buildCmd.Flags().StringVar(&buildLang, "lang", "", "language to build, for presentations in several languages")
+	buildCmd.MarkFlagsMutuallyExclusive("single-file", "multi-page")
+	buildCmd.MarkFlagsMutuallyExclusive("single-file", "offline")
 }
 
 // runBuild executes the build command logic
 func runBuild(cmd *cobra.Command, args []string) {
 	file := args[0]
+	rep := newReporter()
 
 	// Validate that the file exists
 	if _, err := os.Stat(file); os.IsNotExist(err) {
-		Errorln("Error: file not found:", file)
-		os.Exit(1)
+		exitWithError(rep, "build", report.ExitError, fmt.Errorf("file not found: %s", file))
 	}
 
 	// Get absolute path for base directory resolution
 	absPath, err := filepath.Abs(file)
 	if err != nil {
-		Errorln("Error: failed to resolve file path:", err)
-		os.Exit(1)
+		exitWithError(rep, "build", report.ExitError, fmt.Errorf("failed to resolve file path: %w", err))
 	}
 	baseDir := filepath.Dir(absPath)
 
-	// Start spinner
-	spinner := newSpinner("Building presentation")
-	spinner.start()
+	rep.Progress("build", "Building presentation", 0, 0)
+
+	// The output flag overrides tap.yaml and the frontmatter only when given
+	if cmd.Flags().Changed("output") {
+		buildOverrides.Output = buildOutput
+	}
+	buildOverrides.Lang = buildLang
+
+	b := builder.New()
+	b.SetBaseDir(baseDir)
+	b.SetSingleFile(buildSingleFile)
+	b.SetMultiPage(buildMultiPage)
+	b.SetOffline(buildOffline)
+	b.SetForce(buildForce)
+
+	cfg, pres, result, err := buildPresentation(file, b, func(step string) {
+		rep.Progress("build", step, 0, 0)
+	})
+	if err != nil {
+		code := report.ExitError
+		if errors.Is(err, errStrictWarnings) {
+			for _, warning := range cfg.Warnings() {
+				rep.Error("build", fmt.Errorf("frontmatter %s", warning))
+			}
+		} else {
+			rep.Error("build", err)
+		}
+		if isValidationError(err) {
+			code = report.ExitValidation
+		}
+		if !buildWatch {
+			rep.Close()
+			os.Exit(code)
+		}
+		fmt.Println()
+	} else {
+		reportBuildResult(rep, cfg, result)
+	}
+
+	if buildWatch {
+		// The first build already rewrote every file if forced
+		b.SetForce(false)
+		if err := runBuildWatch(absPath, baseDir, b, cfg, pres, rep); err != nil {
+			exitWithError(rep, "build", report.ExitError, err)
+		}
+	}
+	rep.Close()
+}
+
+// buildPresentation loads the configuration, parses the presentation, and
+// builds it with b. progress, if not nil, is called with each step. The
+// configuration and presentation are returned as far as they were loaded, even
+// when the build fails.
+func buildPresentation(file string, b *builder.Builder, progress func(step string)) (*config.Config, *parser.Presentation, *builder.BuildResult, error) {
+	if progress == nil {
+		progress = func(string) {}
+	}
 
 	// Step 1: Load configuration from frontmatter
-	spinner.update("Loading configuration")
-	cfg, err := config.Load(file)
+	progress("Loading configuration")
+	cfg, err := config.Load(file, nil, buildOverrides)
 	if err != nil {
-		spinner.stop()
-		Errorln("Error: failed to load configuration:", err)
-		os.Exit(1)
+		return nil, nil, nil, fmt.Errorf("failed to load configuration: %w", err)
 	}
+	b.SetOutputDir(cfg.OutputDir())
 
 	// Validate configuration
 	if err := cfg.Validate(); err != nil {
-		spinner.stop()
-		Errorln("Error: invalid configuration:", err)
-		os.Exit(1)
+		return cfg, nil, nil, &validationError{fmt.Errorf("invalid configuration: %w", err)}
+	}
+
+	// In strict mode, frontmatter warnings fail the build
+	if buildStrict && len(cfg.Warnings()) > 0 {
+		return cfg, nil, nil, errStrictWarnings
 	}
 
 	// Step 2: Read and parse the presentation file
-	spinner.update("Parsing presentation")
-	content, err := os.ReadFile(file)
+	progress("Parsing presentation")
+	p := newParser(cfg)
+	pres, err := p.ParseFile(file)
 	if err != nil {
-		spinner.stop()
-		Errorln("Error: failed to read file:", err)
-		os.Exit(1)
+		return cfg, nil, nil, fmt.Errorf("failed to parse presentation: %w", err)
 	}
 
-	p := parser.New()
-	pres, err := p.Parse(content)
-	if err != nil {
-		spinner.stop()
-		Errorln("Error: failed to parse presentation:", err)
-		os.Exit(1)
+	// Keep the slides in the selected language, and check that code blocks
+	// only use defined connections
+	trans := transformer.New(cfg)
+	pres = trans.SelectLanguage(pres)
+	if err := trans.ValidateConnections(pres); err != nil {
+		return cfg, pres, nil, &validationError{fmt.Errorf("invalid connections: %w", err)}
 	}
 
 	// Step 3: Build static files
-	spinner.update("Generating static files")
-	b := builder.NewWithOutput(buildOutput)
-	b.SetBaseDir(baseDir)
-
+	progress("Generating static files")
 	result, err := b.Build(cfg, pres)
 	if err != nil {
-		spinner.stop()
-		Errorln("Error: build failed:", err)
-		os.Exit(1)
+		return cfg, pres, nil, fmt.Errorf("build failed: %w", err)
 	}
-
-	// Stop spinner and show results
-	spinner.stop()
-
-	// Print success message and build stats
-	Successln("\nBuild complete!")
-	fmt.Println()
-	fmt.Printf("  Output:     %s\n", result.OutputDir)
-	fmt.Printf("  Files:      %d\n", result.FileCount)
-	fmt.Printf("  Total size: %s\n", formatSize(result.TotalSize))
-	fmt.Printf("  Build time: %s\n", formatDuration(result.BuildTime))
-	fmt.Println()
-
-	// Show next steps
-	Muted("Run 'tap serve %s' to preview the build.\n", result.OutputDir)
-}
-
-// spinner provides a simple terminal spinner for progress display
-type spinner struct {
-	done    chan bool
-	message string
-	running bool
+	return cfg, pres, result, nil
 }
 
-// newSpinner creates a new spinner with the given message
-func newSpinner(message string) *spinner {
-	return &spinner{
-		message: message,
-		done:    make(chan bool),
+// reportBuildResult reports the statistics and warnings of a completed build.
+func reportBuildResult(rep report.Reporter, cfg *config.Config, result *builder.BuildResult) {
+	summary := report.Summary{
+		Title: "Build complete!",
+		Fields: []report.Field{
+			{Label: "Output", Value: result.OutputDir},
+			{Label: "Files", Value: fmt.Sprintf("%d (%d written, %d unchanged)", result.FileCount, result.FilesCopied, result.FilesSkipped)},
+		},
 	}
-}
-
-// start begins the spinner animation
-func (s *spinner) start() {
-	s.running = true
-	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
-	frameIndex := 0
-
-	go func() {
-		ticker := time.NewTicker(80 * time.Millisecond)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-s.done:
-				return
-			case <-ticker.C:
-				// Clear line and print spinner
-				fmt.Printf("\r%s %s", InfoSprint(frames[frameIndex]), s.message)
-				frameIndex = (frameIndex + 1) % len(frames)
-			}
-		}
-	}()
-}
+	if result.FilesPruned > 0 {
+		summary.Fields = append(summary.Fields, report.Field{Label: "Removed", Value: fmt.Sprintf("%d stale file(s)", result.FilesPruned)})
+	}
+	summary.Fields = append(summary.Fields, report.Field{Label: "Total size", Value: formatSize(result.TotalSize)})
+	if result.Offline {
+		summary.Fields = append(summary.Fields, report.Field{Label: "Offline", Value: "service worker in " + builder.ServiceWorkerFileName})
+	}
+	if result.ManifestPath != "" {
+		summary.Fields = append(summary.Fields, report.Field{Label: "Manifest", Value: result.ManifestPath})
+	}
+	summary.Fields = append(summary.Fields, report.Field{Label: "Build time", Value: formatDuration(result.BuildTime)})
+	rep.Result("build", result, summary)
 
-// update changes the spinner message
-func (s *spinner) update(message string) {
-	s.message = message
-}
+	for _, warning := range cfg.Warnings() {
+		rep.Warning("build", fmt.Sprintf("frontmatter %s", warning))
+	}
+	for _, warning := range cfg.ThemeWarnings() {
+		rep.Warning("build", "themes: "+warning)
+	}
+	for _, warning := range result.Warnings {
+		rep.Warning("build", warning)
+	}
 
-// stop stops the spinner animation
-func (s *spinner) stop() {
-	if s.running {
-		s.running = false
-		s.done <- true
-		// Clear the spinner line
-		fmt.Print("\r\033[K")
+	// Show next steps
+	if !buildWatch {
+		rep.Info("build", fmt.Sprintf("Run 'tap serve %s' to preview the build.", result.OutputDir))
 	}
 }
 
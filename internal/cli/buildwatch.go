@@ -0,0 +1,233 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/builder"
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/report"
+	"github.com/MiniCodeMonkey/tap/internal/watcher"
+)
+
+// maxChangedPaths is the number of changed files named in a rebuild line.
+const maxChangedPaths = 3
+
+// buildWatcher rebuilds a presentation whenever its sources change. It reuses
+// one builder, so rebuilds are incremental.
+type buildWatcher struct {
+	file        string // Absolute path of the markdown file
+	baseDir     string
+	builder     *builder.Builder
+	fileWatcher *watcher.Watcher
+	// report is called with an event type ("reload", "warning", or "error")
+	// and a message for every rebuild. It has the signature of
+	// tui.DevModel.SendEvent, so rebuilds can be shown in the TUI as well.
+	report func(eventType, message string)
+}
+
+// runBuildWatch rebuilds the presentation on every change until SIGINT or
+// SIGTERM. cfg and pres are the result of the first build and may be nil if
+// it failed; they determine the files to watch. Rebuilds are reported as
+// timestamped lines, or through rep with JSON output.
+func runBuildWatch(absFile, baseDir string, b *builder.Builder, cfg *config.Config, pres *parser.Presentation, rep report.Reporter) error {
+	fileWatcher, err := watcher.New(buildWatchPaths(absFile, baseDir, cfg, pres)...)
+	if err != nil {
+		return fmt.Errorf("failed to start file watcher: %w", err)
+	}
+	defer func() { _ = fileWatcher.Close() }()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	rep.Info("build", "Watching for changes. Press Ctrl+C to stop.")
+
+	w := &buildWatcher{
+		file:        absFile,
+		baseDir:     baseDir,
+		builder:     b,
+		fileWatcher: fileWatcher,
+		report:      printBuildEvent,
+	}
+	if _, ok := rep.(*report.JSONReporter); ok {
+		w.report = reportBuildEvent(rep)
+	}
+	w.run(sigCh)
+
+	if _, ok := rep.(*report.JSONReporter); ok {
+		rep.Info("build", "Stopped watching.")
+		return nil
+	}
+	fmt.Println()
+	Info("Stopped watching.\n")
+	return nil
+}
+
+// buildWatchPaths returns the paths to watch for a build: the same as the dev
+// server watches.
+func buildWatchPaths(absFile, baseDir string, cfg *config.Config, pres *parser.Presentation) []string {
+	var includes []string
+	if pres != nil {
+		includes = pres.Includes
+	}
+	customThemePath := ""
+	if cfg != nil {
+		// A custom theme that can't be resolved is reported by the build
+		customThemePath, _ = cfg.ResolveCustomThemePath(baseDir)
+	}
+	return watchPaths(absFile, baseDir, customThemePath, includes)
+}
+
+// run rebuilds once per change until a signal is received on stop. Changes
+// made while a build runs are coalesced into one rebuild, and a signal
+// received while a build runs takes effect once the build has finished, so
+// the output is never left half written.
+func (w *buildWatcher) run(stop <-chan os.Signal) {
+	events := w.fileWatcher.Events()
+	for {
+		// A pending signal wins over pending changes
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		select {
+		case <-stop:
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			w.rebuild(pendingChanges(events, event.Paths))
+		}
+	}
+}
+
+// pendingChanges adds the paths of the change events already queued on
+// events to paths, without waiting for new ones, and removes duplicates.
+func pendingChanges(events <-chan watcher.Event, paths []string) []string {
+	seen := make(map[string]bool)
+	var changed []string
+	add := func(paths []string) {
+		for _, path := range paths {
+			if !seen[path] {
+				seen[path] = true
+				changed = append(changed, path)
+			}
+		}
+	}
+
+	add(paths)
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return changed
+			}
+			add(event.Paths)
+		default:
+			return changed
+		}
+	}
+}
+
+// rebuild builds the presentation after paths changed and reports the
+// result. A failed build is reported and the next change is built again.
+func (w *buildWatcher) rebuild(paths []string) {
+	cfg, pres, result, err := buildPresentation(w.file, w.builder, nil)
+
+	// Watch files referenced since the previous build, such as new includes,
+	// even if the build failed, so that fixing them triggers a rebuild
+	w.watchNew(cfg, pres)
+
+	changes := describeChanges(w.baseDir, paths)
+	if err != nil {
+		w.report("error", fmt.Sprintf("Rebuild failed (%s): %v", changes, err))
+	} else {
+		w.report("reload", fmt.Sprintf("Rebuilt in %s: %d written, %d removed (%s)",
+			formatDuration(result.BuildTime), result.FilesCopied, result.FilesPruned, changes))
+	}
+
+	if cfg != nil {
+		for _, warning := range cfg.Warnings() {
+			w.report("warning", "Frontmatter "+warning.String())
+		}
+		for _, warning := range cfg.ThemeWarnings() {
+			w.report("warning", "Themes: "+warning)
+		}
+	}
+	if result != nil {
+		for _, warning := range result.Warnings {
+			w.report("warning", warning)
+		}
+	}
+}
+
+// watchNew starts watching the includes and custom theme of a build that
+// weren't watched yet. Already watched paths are ignored by the watcher.
+func (w *buildWatcher) watchNew(cfg *config.Config, pres *parser.Presentation) {
+	paths := buildWatchPaths(w.file, w.baseDir, cfg, pres)
+	for _, path := range paths {
+		if err := w.fileWatcher.AddPath(path); err != nil {
+			w.report("warning", fmt.Sprintf("File not watched: %v", err))
+		}
+	}
+}
+
+// describeChanges lists changed paths relative to baseDir, naming at most
+// maxChangedPaths of them.
+func describeChanges(baseDir string, paths []string) string {
+	names := make([]string, 0, maxChangedPaths)
+	for i, path := range paths {
+		if i == maxChangedPaths {
+			break
+		}
+		if rel, err := filepath.Rel(baseDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+		names = append(names, filepath.ToSlash(path))
+	}
+
+	description := strings.Join(names, ", ")
+	if more := len(paths) - len(names); more > 0 {
+		description += fmt.Sprintf(" and %d more", more)
+	}
+	return description
+}
+
+// printBuildEvent prints a rebuild event as one line with a timestamp.
+func printBuildEvent(eventType, message string) {
+	timestamp := MutedSprint(time.Now().Format("15:04:05"))
+	switch eventType {
+	case "error":
+		fmt.Printf("%s %s\n", timestamp, ErrorSprint(message))
+	case "warning":
+		fmt.Printf("%s %s\n", timestamp, WarningSprint(message))
+	default:
+		fmt.Printf("%s %s\n", timestamp, message)
+	}
+}
+
+// reportBuildEvent returns a buildWatcher report function that passes rebuild
+// events to rep.
+func reportBuildEvent(rep report.Reporter) func(eventType, message string) {
+	return func(eventType, message string) {
+		switch eventType {
+		case "error":
+			rep.Error("build", errors.New(message))
+		case "warning":
+			rep.Warning("build", message)
+		default:
+			rep.Info("build", message)
+		}
+	}
+}
@@ -3,25 +3,45 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/MiniCodeMonkey/tap/internal/builder"
 	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/devexport"
+	"github.com/MiniCodeMonkey/tap/internal/driver"
 	"github.com/MiniCodeMonkey/tap/internal/parser"
 	"github.com/MiniCodeMonkey/tap/internal/server"
+	"github.com/MiniCodeMonkey/tap/internal/slidediff"
+	"github.com/MiniCodeMonkey/tap/internal/stats"
+	"github.com/MiniCodeMonkey/tap/internal/themes"
+	"github.com/MiniCodeMonkey/tap/internal/thumbnail"
 	"github.com/MiniCodeMonkey/tap/internal/transformer"
 	"github.com/MiniCodeMonkey/tap/internal/tui"
+	"github.com/MiniCodeMonkey/tap/internal/watcher"
 )
 
 // Flags for the dev command
 var (
 	devPort              int
+	devPortRange         int
 	devPresenterPassword string
+	devAudiencePassword  string
 	devHeadless          bool
+	devAllowExec         bool
+	devLogFile           string
+	devTLS               bool
+	devTLSRedirect       bool
+	devLang              string
 )
 
 // devCmd represents the dev command
@@ -34,13 +54,37 @@ The dev server provides:
   - Live preview of your presentation at http://localhost:<port>
   - Hot reload on file changes
   - Presenter view with speaker notes
-  - Live code execution for supported drivers
+  - Remote control page for changing slides from a phone
+  - PDF export from the running server (press x, or POST /api/export)
+  - Live code execution for supported drivers (with --allow-exec)
+
+Code execution runs the commands and queries in your slides on this machine,
+so it is disabled unless --allow-exec is given.
+
+With --tls, the server uses HTTPS with a self-signed certificate, which browser
+features like device orientation and the clipboard need on other devices. The
+certificate is kept in your config directory, so browsers only ask you to
+accept it once per machine.
+
+When the port is in use, the server tries the next ports, up to --port-range
+ports in all, and shows the URL it ended up on. Quitting the TUI, Ctrl+C, or
+closing the terminal shuts the server down, disconnecting browsers and
+stopping the browsers used for PDF export and thumbnails.
+
+For presentations in several languages, --lang selects the language to
+show; it defaults to the first of the languages frontmatter option.
 
 Examples:
   tap dev slides.md                      # Start server on port 3000
   tap dev slides.md --port 8080          # Use custom port
   tap dev slides.md -p 8080              # Short form
-  tap dev slides.md --presenter-password secret  # Protect presenter view`,
+  tap dev slides.md -p 8080 --port-range 1  # Fail if port 8080 is in use
+  tap dev slides.md --presenter-password secret  # Protect presenter view
+  tap dev slides.md --audience-password secret   # Protect audience view
+  tap dev slides.md --allow-exec         # Enable running code blocks
+  tap dev slides.md --log-file tap.log   # Keep a log of the dev server activity
+  tap dev slides.md --tls --tls-redirect # Serve HTTPS and redirect HTTP to it
+  tap dev slides.md --lang da            # Show the Danish version`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var file string
@@ -67,7 +111,7 @@ Examples:
 			file = args[0]
 		}
 
-		return runDevServer(file, devPort, devPresenterPassword, devHeadless)
+		return runDevServer(file, devPort, devPortRange, devPresenterPassword, devAudiencePassword, devHeadless, devAllowExec, devLogFile, devTLS, devTLSRedirect, devLang)
 	},
 }
 
@@ -77,12 +121,28 @@ func init() {
 
 	// Command-specific flags
 	devCmd.Flags().IntVarP(&devPort, "port", "p", 3000, "port for the dev server")
+	devCmd.Flags().IntVar(&devPortRange, "port-range", 10, "number of ports to try, starting at --port, when it is in use")
 	devCmd.Flags().StringVar(&devPresenterPassword, "presenter-password", "", "password to protect the presenter view")
+	devCmd.Flags().StringVar(&devAudiencePassword, "audience-password", "", "password to protect the audience view")
 	devCmd.Flags().BoolVar(&devHeadless, "headless", false, "run without TUI (for testing/automation)")
+	devCmd.Flags().BoolVar(&devAllowExec, "allow-exec", false, "allow running code blocks from the browser")
+	devCmd.Flags().StringVar(&devLogFile, "log-file", "", "append the activity shown in the TUI to a file")
+	devCmd.Flags().BoolVar(&devTLS, "tls", false, "serve HTTPS with a self-signed certificate")
+	devCmd.Flags().StringVar(&devLang, "lang", "", "language to show, for presentations in several languages")
+	devCmd.Flags().BoolVar(&devTLSRedirect, "tls-redirect", false, "redirect HTTP requests from other devices to HTTPS (with --tls)")
 }
 
 // runDevServer starts the dev server with hot reload and TUI.
-func runDevServer(file string, port int, presenterPassword string, headless bool) error {
+func runDevServer(file string, port, portRange int, presenterPassword, audiencePassword string, headless, allowExec bool, logFile string, useTLS, tlsRedirect bool, lang string) error {
+	overrides := config.Overrides{Lang: lang}
+
+	if tlsRedirect && !useTLS {
+		return fmt.Errorf("--tls-redirect requires --tls")
+	}
+	if portRange < 1 {
+		return fmt.Errorf("--port-range must be at least 1")
+	}
+
 	// Resolve absolute path
 	absFile, err := filepath.Abs(file)
 	if err != nil {
@@ -97,7 +157,7 @@ func runDevServer(file string, port int, presenterPassword string, headless bool
 	baseDir := filepath.Dir(absFile)
 
 	// Load configuration from frontmatter
-	cfg, err := config.Load(absFile)
+	cfg, err := config.Load(absFile, nil, overrides)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -105,12 +165,18 @@ func runDevServer(file string, port int, presenterPassword string, headless bool
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("invalid config: %w", err)
 	}
+	if headless {
+		printConfigWarnings(cfg)
+	}
 
 	// Parse and transform the presentation
-	pres, err := loadPresentation(absFile, cfg, baseDir)
+	pres, parsed, err := loadPresentation(absFile, cfg, baseDir, allowExec)
 	if err != nil {
 		return fmt.Errorf("failed to load presentation: %w", err)
 	}
+	if headless {
+		printSlideWarnings(cfg, parsed)
+	}
 
 	// Resolve custom theme path if configured
 	customThemePath, err := cfg.ResolveCustomThemePath(baseDir)
@@ -126,87 +192,148 @@ func runDevServer(file string, port int, presenterPassword string, headless bool
 
 	// Create and configure the server
 	srv := server.New(port)
+	srv.SetPortRange(portRange)
 	srv.SetPresentation(pres)
 	srv.SetPresenterPassword(presenterPassword)
+	srv.SetAudiencePassword(audiencePassword)
 	srv.SetBaseDir(baseDir) // Enable serving local files (images, etc.)
+	srv.SetMarkdownFile(absFile)
+	srv.SetVersion(Version)
+	if allowExec {
+		srv.SetAllowExec(true)
+		srv.SetRegistry(newDriverRegistry(cfg, baseDir))
+	}
 	if customThemePath != "" {
 		srv.SetCustomThemePath(customThemePath)
 	}
+	var cert *server.Certificate
+	if useTLS {
+		cert, err = loadDevCertificate()
+		if err != nil {
+			return err
+		}
+		srv.SetTLS(cert.TLS, tlsRedirect)
+	}
 	srv.SetupRoutes()
 
 	// Register WebSocket handler
-	srv.RegisterHandlerFunc("GET /ws", hub.HandleConnection)
+	srv.SetWebSocketHub(hub)
+
+	// Register the PDF export API; its browser is stopped on exit
+	exports := devexport.New(srv)
+	defer func() { _ = exports.Close() }()
+	srv.RegisterHandlerFunc("POST /api/export", exports.HandleExport)
+
+	// Register the slide thumbnails API for the presenter view's filmstrip
+	thumbnails := thumbnail.New(srv)
+	defer func() { _ = thumbnails.Close() }()
+	srv.RegisterHandlerFunc("GET /api/thumbnail/{index}", thumbnails.HandleThumbnail)
 
 	// Start the server
 	if err := srv.Start(); err != nil {
+		var inUse *server.PortInUseError
+		if errors.As(err, &inUse) {
+			return fmt.Errorf("failed to start server: %w; stop it or choose another port with --port", err)
+		}
 		return fmt.Errorf("failed to start server: %w", err)
 	}
+	var portNotice string
+	if srv.Port() != port {
+		portNotice = fmt.Sprintf("Port %d is in use, so the server is on port %d", port, srv.Port())
+	}
 
 	// Set up file watcher
-	watcher, err := server.NewWatcher(absFile)
+	fileWatcher, err := watcher.New(watchPaths(absFile, baseDir, customThemePath, parsed.Includes)...)
 	if err != nil {
-		return fmt.Errorf("failed to create file watcher: %w", err)
-	}
-
-	watcher.SetOnChange(func(path string) {
-		// Reload config and presentation
-		newCfg, err := config.Load(absFile)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reloading config: %v\n", err)
-			return
-		}
-
-		newPres, err := loadPresentation(absFile, newCfg, baseDir)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error reloading presentation: %v\n", err)
-			return
-		}
-
-		srv.SetPresentation(newPres)
-		_ = hub.BroadcastReload()
-	})
-
-	if err := watcher.Start(); err != nil {
 		return fmt.Errorf("failed to start file watcher: %w", err)
 	}
-	defer func() { _ = watcher.Stop() }()
+	defer func() { _ = fileWatcher.Close() }()
+	watchAssets(fileWatcher, pres, parsed, baseDir)
+	srv.SetWatcherRunning(true)
 
-	// Generate URLs
-	audienceURL := fmt.Sprintf("http://localhost:%d", port)
-	presenterURL := fmt.Sprintf("http://localhost:%d/presenter", port)
-	if presenterPassword != "" {
-		presenterURL += "?key=" + presenterPassword
+	// Generate shareable URLs on the LAN address, falling back to localhost
+	qrCfg := server.QRConfig{Port: srv.Port(), PresenterPassword: presenterPassword, TLS: useTLS}
+	audienceURL, err := server.GenerateAudienceURL(qrCfg)
+	if err != nil {
+		return fmt.Errorf("failed to generate audience URL: %w", err)
+	}
+	presenterURL, err := server.GeneratePresenterURL(qrCfg)
+	if err != nil {
+		return fmt.Errorf("failed to generate presenter URL: %w", err)
 	}
 
-	// Set up signal handling for graceful shutdown
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	// Shut down gracefully on Ctrl+C, kill, or the terminal closing
+	sigCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer stopSignals()
 
 	if headless {
 		// Headless mode - no TUI, just log and wait for signal
 		fmt.Println()
 		Success("  Dev server running (headless mode)\n")
 		fmt.Println()
+		if portNotice != "" {
+			Warning("  %s.\n", portNotice)
+			fmt.Println()
+		}
 		fmt.Printf("  Audience:  %s\n", audienceURL)
 		fmt.Printf("  Presenter: %s\n", presenterURL)
+		fmt.Printf("  Remote:    %s/remote (pairing code %s)\n", audienceURL, srv.RemoteCode())
 		fmt.Println()
+		if audiencePassword != "" {
+			Muted("  Audience view is password protected.\n")
+			fmt.Println()
+		}
+		if cert != nil {
+			Muted("  HTTPS certificate SHA-256 fingerprint:\n  %s\n", cert.Fingerprint)
+			fmt.Println()
+		}
+		if allowExec {
+			Warning("  Code execution is enabled. Anyone who can reach the server can run code blocks.\n")
+			fmt.Println()
+		}
 		Muted("  Press Ctrl+C to stop\n")
 		fmt.Println()
 
-		// Update watcher for headless mode
-		watcher.SetOnChange(func(path string) {
+		// Print PDF exports requested through the API
+		exports.SetEventHandler(func(eventType, message string) {
+			if eventType == "error" {
+				Errorln(message)
+				return
+			}
+			Info("%s\n", message)
+		})
+
+		// Reload on file changes
+		fileMissing := false
+		go handleWatchEvents(fileWatcher, func(path string) {
+			// Keep serving the last good presentation while the file is gone,
+			// and reload once it's back
+			if err := tui.CheckMarkdownFile(absFile); err != nil {
+				if !fileMissing {
+					Warning("Warning: %v. Serving the last version until it's back.\n", err)
+					fileMissing = true
+				}
+				return
+			}
+			if fileMissing {
+				Info("Markdown file is back: %s\n", file)
+				fileMissing = false
+			}
+
 			// Reload config and presentation
-			newCfg, err := config.Load(absFile)
+			newCfg, err := config.Load(absFile, nil, overrides)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error reloading config: %v\n", err)
 				return
 			}
+			printConfigWarnings(newCfg)
 
-			newPres, err := loadPresentation(absFile, newCfg, baseDir)
+			newPres, newParsed, err := loadPresentation(absFile, newCfg, baseDir, allowExec)
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Error reloading presentation: %v\n", err)
 				return
 			}
+			printSlideWarnings(newCfg, newParsed)
 
 			// Update custom theme path if changed
 			newCustomThemePath, err := newCfg.ResolveCustomThemePath(baseDir)
@@ -215,52 +342,97 @@ func runDevServer(file string, port int, presenterPassword string, headless bool
 				srv.SetCustomThemePath("")
 			} else {
 				srv.SetCustomThemePath(newCustomThemePath)
+				watchFiles(fileWatcher, newCustomThemePath)
 			}
 
+			watchFiles(fileWatcher, newParsed.Includes...)
+			watchAssets(fileWatcher, newPres, newParsed, baseDir)
+			if allowExec {
+				srv.SetRegistry(newDriverRegistry(newCfg, baseDir))
+			}
+			oldPres := srv.GetPresentation()
 			srv.SetPresentation(newPres)
-			_ = hub.BroadcastReload()
-			Info("Reloaded: %s\n", path)
+			if summary := broadcastChanges(hub, oldPres, newPres); summary != "" {
+				Info("%s: %s\n", summary, path)
+			} else if asset := changedAsset(path, absFile, baseDir, newParsed.Includes); asset != "" {
+				Info("Asset changed: %s\n", asset)
+			} else {
+				Info("Reloaded: %s\n", path)
+			}
 		})
 
 		// Wait for signal
-		<-sigCh
+		<-sigCtx.Done()
 		fmt.Println()
 		Info("Shutting down...\n")
 	} else {
 		// Run the TUI
 		tuiCfg := tui.DevConfig{
 			MarkdownFile:      file,
-			Port:              port,
+			Port:              srv.Port(),
 			AudienceURL:       audienceURL,
 			PresenterURL:      presenterURL,
+			QRCodeASCII:       tui.AudienceQRCode(audienceURL),
 			PresenterPassword: presenterPassword,
 			CurrentTheme:      cfg.Theme,
+			CustomThemes:      cfg.CustomThemes(),
+			TalkDuration:      cfg.TalkDuration(),
+			LogFile:           logFile,
+			PromptHistoryFile: tui.DefaultPromptHistoryPath(),
+			AudienceProtected: audiencePassword != "",
+		}
+		if cert != nil {
+			tuiCfg.TLSFingerprint = cert.Fingerprint
 		}
 
 		// Create TUI model
 		model := tui.NewDevModel(tuiCfg)
-		model.UpdateWatcherStatus(true)
 		model.SetThemeBroadcaster(hub)
+		model.SetSlideBroadcaster(hub)
+		model.SetTimerBroadcaster(hub)
+		model.SetAutoplayBroadcaster(hub)
+		model.SetPresenterTokenRotator(srv)
+		model.SetStatusSource(srv)
+		model.SetRemoteController(srv)
+		model.SetPDFExporter(exports)
+		exports.SetEventHandler(model.SendEvent)
+		sendConfigWarnings(model, cfg)
+		sendSlideWarnings(model, cfg, parsed)
+		if portNotice != "" {
+			model.SendEvent("warning", portNotice)
+		}
+		if cert != nil && cert.Created {
+			model.SendEvent("action", "Generated a new HTTPS certificate; browsers will ask you to accept it once")
+		}
+		model.SetSpeakingTime(stats.Compute(parsed, stats.DefaultOptions()).SpeakingTime)
+		model.SetSlideTitles(tui.SlideTitles(parsed))
+		hub.SetOnPositionChange(model.SetCurrentSlide)
+
+		// Reload on file changes and report them in the TUI
+		go handleWatchEvents(fileWatcher, func(path string) {
+			// Keep serving the last good presentation while the file is gone,
+			// and reload once it's back
+			if err := tui.CheckMarkdownFile(absFile); err != nil {
+				model.SetFileWarning(err)
+				return
+			}
+			model.ClearFileWarning()
 
-		// Track WebSocket client count
-		hub.SetOnClientCountChange(func(count int) {
-			model.UpdateWebSocketCount(count)
-		})
-
-		// Update watcher to also update TUI
-		watcher.SetOnChange(func(path string) {
 			// Reload config and presentation
-			newCfg, err := config.Load(absFile)
+			newCfg, err := config.Load(absFile, nil, overrides)
 			if err != nil {
 				model.SetError(err)
 				return
 			}
+			sendConfigWarnings(model, newCfg)
+			model.SetTalkDuration(newCfg.TalkDuration())
 
-			newPres, err := loadPresentation(absFile, newCfg, baseDir)
+			newPres, newParsed, err := loadPresentation(absFile, newCfg, baseDir, allowExec)
 			if err != nil {
 				model.SetError(err)
 				return
 			}
+			sendSlideWarnings(model, newCfg, newParsed)
 
 			// Update custom theme path if changed
 			newCustomThemePath, err := newCfg.ResolveCustomThemePath(baseDir)
@@ -270,43 +442,268 @@ func runDevServer(file string, port int, presenterPassword string, headless bool
 				srv.SetCustomThemePath("")
 			} else {
 				srv.SetCustomThemePath(newCustomThemePath)
+				watchFiles(fileWatcher, newCustomThemePath)
 			}
 
 			model.ClearError()
+			model.SetSpeakingTime(stats.Compute(newParsed, stats.DefaultOptions()).SpeakingTime)
+			model.SetSlideTitles(tui.SlideTitles(newParsed))
+			watchFiles(fileWatcher, newParsed.Includes...)
+			watchAssets(fileWatcher, newPres, newParsed, baseDir)
+			if allowExec {
+				srv.SetRegistry(newDriverRegistry(newCfg, baseDir))
+			}
+			oldPres := srv.GetPresentation()
 			srv.SetPresentation(newPres)
-			_ = hub.BroadcastReload()
-			model.SendReloadEvent(path)
+			if summary := broadcastChanges(hub, oldPres, newPres); summary != "" {
+				model.SendEvent("reload", summary)
+			} else if asset := changedAsset(path, absFile, baseDir, newParsed.Includes); asset != "" {
+				model.SendAssetEvent(asset)
+			} else {
+				model.SendReloadEvent(path)
+			}
 		})
 
-		// Run the TUI (blocks until user quits)
-		if err := tui.RunDevTUIWithModel(model); err != nil {
+		// Run the TUI (blocks until user quits or a signal arrives)
+		if err := tui.RunDevTUIWithModel(sigCtx, model); err != nil {
 			return fmt.Errorf("TUI error: %w", err)
 		}
 	}
 
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 5*1e9) // 5 seconds
+	return shutdownDevServer(srv, fileWatcher, exports, thumbnails)
+}
+
+// devShutdownTimeout is how long the dev server waits for requests to finish
+// and browsers to acknowledge the WebSocket close when it shuts down.
+const devShutdownTimeout = 5 * time.Second
+
+// shutdownDevServer stops the dev server in order: it stops serving and
+// disconnects the browsers, then stops watching files, then closes the
+// headless browsers of the given services, so no export or thumbnail starts
+// one after it was closed.
+func shutdownDevServer(srv *server.Server, fileWatcher *watcher.Watcher, services ...io.Closer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), devShutdownTimeout)
 	defer cancel()
 
-	return srv.Shutdown(ctx)
+	err := srv.Shutdown(ctx)
+	_ = fileWatcher.Close()
+	for _, service := range services {
+		_ = service.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to shut down server: %w", err)
+	}
+	return nil
 }
 
-// loadPresentation reads, parses, and transforms a presentation file.
-func loadPresentation(file string, cfg *config.Config, baseDir string) (*transformer.TransformedPresentation, error) {
-	// Read file content
-	content, err := os.ReadFile(file)
+// loadDevCertificate returns the dev server's HTTPS certificate from the
+// config directory, generating one for this machine's addresses if needed.
+func loadDevCertificate() (*server.Certificate, error) {
+	dir := server.DefaultCertDir()
+	if dir == "" {
+		return nil, fmt.Errorf("failed to find a config directory for the HTTPS certificate")
+	}
+	cert, err := server.LoadOrCreateCertificate(dir, server.CertHosts())
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return nil, fmt.Errorf("failed to load HTTPS certificate: %w", err)
 	}
+	return cert, nil
+}
 
-	// Parse markdown
-	p := parser.New()
-	parsed, err := p.Parse(content)
+// printConfigWarnings prints the problems found in the frontmatter and the
+// themes directory.
+func printConfigWarnings(cfg *config.Config) {
+	for _, warning := range cfg.Warnings() {
+		Warning("  Warning: frontmatter %s\n", warning)
+	}
+	for _, warning := range cfg.ThemeWarnings() {
+		Warning("  Warning: themes: %s\n", warning)
+	}
+}
+
+// sendConfigWarnings shows the problems found in the frontmatter and the
+// themes directory in the TUI.
+func sendConfigWarnings(model *tui.DevModel, cfg *config.Config) {
+	for _, warning := range cfg.Warnings() {
+		model.SendEvent("warning", "Frontmatter "+warning.String())
+	}
+	for _, warning := range cfg.ThemeWarnings() {
+		model.SendEvent("warning", "Themes: "+warning)
+	}
+}
+
+// printSlideWarnings prints the problems found in the slide directives, such
+// as invalid transitions, embeds, and class names.
+func printSlideWarnings(cfg *config.Config, parsed *parser.Presentation) {
+	for _, warning := range slideWarnings(cfg, parsed) {
+		Warning("  Warning: %s\n", warning)
+	}
+}
+
+// sendSlideWarnings shows the problems found in the slide directives in the TUI.
+func sendSlideWarnings(model *tui.DevModel, cfg *config.Config, parsed *parser.Presentation) {
+	for _, warning := range slideWarnings(cfg, parsed) {
+		model.SendEvent("warning", "Slide "+strings.TrimPrefix(warning, "slide "))
+	}
+}
+
+// slideWarnings returns the problems found in the slide directives.
+func slideWarnings(cfg *config.Config, parsed *parser.Presentation) []string {
+	return transformer.New(cfg).Warnings(parsed)
+}
+
+// watchPaths returns the paths the dev server watches for changes: the markdown
+// file, its included files, the images and themes directories next to it, and
+// the custom theme, if any.
+func watchPaths(absFile, baseDir, customThemePath string, includes []string) []string {
+	paths := append([]string{absFile}, includes...)
+	for _, dir := range []string{filepath.Join(baseDir, "images"), themes.Dir(baseDir)} {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			paths = append(paths, dir)
+		}
+	}
+	if customThemePath != "" {
+		paths = append(paths, customThemePath)
+	}
+	return paths
+}
+
+// watchFiles starts watching files that were referenced after the dev server
+// started, such as a newly configured theme or new includes. Already watched
+// paths are ignored by the watcher.
+func watchFiles(fileWatcher *watcher.Watcher, paths ...string) {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := fileWatcher.AddPath(path); err != nil {
+			Warning("File not watched: %v\n", err)
+		}
+	}
+}
+
+// watchAssets replaces the assets the watcher reloads on with the local files
+// the presentation references now, since they change as it is edited.
+func watchAssets(fileWatcher *watcher.Watcher, pres *transformer.TransformedPresentation, parsed *parser.Presentation, baseDir string) {
+	if err := fileWatcher.SetAssets(assetPaths(pres, parsed, baseDir)); err != nil {
+		Warning("File not watched: %v\n", err)
+	}
+}
+
+// assetPaths returns the local files a presentation references: the images,
+// backgrounds, and stylesheets a build copies, and files named in slide
+// directives, such as data: ./sales.csv.
+func assetPaths(pres *transformer.TransformedPresentation, parsed *parser.Presentation, baseDir string) []string {
+	paths := builder.AssetPaths(pres, baseDir)
+	for _, slide := range parsed.Slides {
+		for _, value := range slide.Directives.Raw {
+			if path, ok := directiveFile(value, baseDir); ok {
+				paths = append(paths, path)
+			}
+		}
+	}
+	return paths
+}
+
+// directiveFile returns the file a directive value refers to, if it looks
+// like a relative file path: a single line with a file extension, in a
+// directory that exists. The file itself may be missing, so it's picked up
+// when it's recreated.
+func directiveFile(value, baseDir string) (string, bool) {
+	if value == "" || strings.ContainsAny(value, "\n:") || filepath.IsAbs(value) || filepath.Ext(value) == "" {
+		return "", false
+	}
+	path := filepath.Join(baseDir, value)
+	if info, err := os.Stat(filepath.Dir(path)); err != nil || !info.IsDir() {
+		return "", false
+	}
+	return path, true
+}
+
+// changedAsset returns the path of a changed file relative to baseDir, or ""
+// if the file is the markdown file or one of its includes.
+func changedAsset(path, absFile, baseDir string, includes []string) string {
+	if path == absFile || slices.Contains(includes, path) {
+		return ""
+	}
+	if rel, err := filepath.Rel(baseDir, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// handleWatchEvents calls onChange once per debounced change until the watcher is closed.
+func handleWatchEvents(fileWatcher *watcher.Watcher, onChange func(path string)) {
+	for event := range fileWatcher.Events() {
+		onChange(event.Path)
+	}
+}
+
+// broadcastChanges sends clients the slides that changed between two versions
+// of the presentation and returns a summary such as "Updated slide 4". When the
+// config or frontmatter changed, or no slides did, as when an image or the
+// custom theme changed, it sends a full reload instead and returns "".
+func broadcastChanges(hub *server.WebSocketHub, oldPres, newPres *transformer.TransformedPresentation) string {
+	if slidediff.NeedsFullReload(oldPres, newPres) {
+		_ = hub.BroadcastReload()
+		return ""
+	}
+	diff := slidediff.Compare(oldPres, newPres)
+	if diff.Empty() {
+		_ = hub.BroadcastReload()
+		return ""
+	}
+	_ = hub.BroadcastSlides(diff)
+	return diff.Summary()
+}
+
+// loadPresentation reads, parses, and transforms a presentation file.
+// It also returns the parsed presentation, which lists the files spliced in
+// via include directives.
+func loadPresentation(file string, cfg *config.Config, baseDir string, allowExec bool) (*transformer.TransformedPresentation, *parser.Presentation, error) {
+	// Read and parse markdown, expanding includes
+	p := newParser(cfg)
+	parsed, err := p.ParseFile(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse markdown: %w", err)
+		return nil, nil, fmt.Errorf("failed to parse markdown: %w", err)
 	}
 
-	// Transform to frontend format
+	// Transform to frontend format in the selected language, checking code
+	// block connections first
 	t := transformer.NewWithBaseDir(cfg, baseDir)
-	return t.Transform(parsed), nil
+	t.SetAllowExec(allowExec)
+	parsed = t.SelectLanguage(parsed)
+	if err := t.ValidateConnections(parsed); err != nil {
+		return nil, nil, fmt.Errorf("invalid connections: %w", err)
+	}
+	return t.Transform(parsed), parsed, nil
+}
+
+// newParser returns a parser with the text replacements and slide delimiters
+// configured in the frontmatter.
+func newParser(cfg *config.Config) *parser.Parser {
+	return parser.NewWithOptions(parser.Options{
+		Emoji:            cfg.Emoji,
+		Smartypants:      cfg.Smartypants,
+		StrictDelimiters: cfg.StrictDelimiters,
+	})
+}
+
+// newDriverRegistry returns the built-in drivers plus the custom drivers
+// defined in the frontmatter. Commands run in the presentation's directory.
+func newDriverRegistry(cfg *config.Config, baseDir string) *driver.Registry {
+	registry := driver.NewDefaultRegistry(baseDir)
+	for name, driverCfg := range cfg.Drivers {
+		if driverCfg.Command == "" {
+			continue
+		}
+		registry.Register(driver.NewCustomDriver(driver.CustomDriverConfig{
+			Name:       name,
+			Command:    driverCfg.Command,
+			Args:       driverCfg.Args,
+			WorkingDir: baseDir,
+			Timeout:    driverCfg.Timeout,
+		}))
+	}
+	return registry
 }
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/imagecache"
+	"github.com/MiniCodeMonkey/tap/internal/imagereport"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the images command
+var (
+	imagesDryRun     bool
+	imagesJSON       bool
+	imagesPrice      float64
+	imagesClearCache bool
+)
+
+// imagesCmd represents the images command
+var imagesCmd = &cobra.Command{
+	Use:   "images <file>",
+	Short: "Report the AI images that still need to be generated",
+	Long: `Report the AI-generated images of a presentation without calling the
+image provider.
+
+The images command finds every <!-- ai-prompt: ... --> comment followed by an
+image, including in included files, and reports:
+  - The slide number
+  - The prompt that would be sent
+  - The target image path and whether the file exists
+  - The estimated cost of generating the missing images
+
+Images whose file is missing are the ones the image generator's batch mode in
+tap dev generates. The price per image defaults to the imageGen.pricePerImage
+setting in tap.yaml.
+
+With imageGen.cache enabled in tap.yaml, generated images are also kept in
+images/.tap-cache, so generating the same prompt again can reuse them.
+--clear-cache removes that folder.
+
+Examples:
+  tap images slides.md --dry-run               # List prompts and missing images
+  tap images slides.md --dry-run --price 0.08  # Estimate with another price
+  tap images slides.md --dry-run --json        # Print the report as JSON
+  tap images slides.md --clear-cache           # Remove the cached images`,
+	Args: cobra.ExactArgs(1),
+	Run:  runImages,
+}
+
+func init() {
+	// Register the images command with root
+	rootCmd.AddCommand(imagesCmd)
+
+	// Command-specific flags
+	imagesCmd.Flags().BoolVar(&imagesDryRun, "dry-run", false, "report the images without generating them")
+	imagesCmd.Flags().BoolVar(&imagesJSON, "json", false, "print the report as JSON")
+	imagesCmd.Flags().Float64Var(&imagesPrice, "price", 0, "estimated price per image in US dollars (default from tap.yaml, or $0.04)")
+	imagesCmd.Flags().BoolVar(&imagesClearCache, "clear-cache", false, "remove the cache of generated images in images/.tap-cache")
+}
+
+// imagesJSONReport is the report printed with --json.
+type imagesJSONReport struct {
+	imagereport.Report
+	Pending       int     `json:"pending"`
+	PricePerImage float64 `json:"pricePerImage"`
+	EstimatedCost float64 `json:"estimatedCost"`
+}
+
+// runImages executes the images command logic
+func runImages(cmd *cobra.Command, args []string) {
+	file := args[0]
+
+	// Images are only generated in tap dev for now
+	if !imagesDryRun && !imagesClearCache {
+		Errorln("Error: tap images only supports --dry-run and --clear-cache; generate images with the image generator in tap dev (press i)")
+		os.Exit(1)
+	}
+	if imagesPrice < 0 {
+		Errorln("Error: --price must not be negative")
+		os.Exit(1)
+	}
+
+	// Validate that the file exists
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		Errorln("Error: file not found:", file)
+		os.Exit(1)
+	}
+
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		Errorln("Error: failed to resolve file path:", err)
+		os.Exit(1)
+	}
+
+	if imagesClearCache {
+		clearImageCache(absPath)
+		if !imagesDryRun {
+			return
+		}
+	}
+
+	price := imagesPrice
+	if price == 0 {
+		price = imagereport.DefaultPricePerImage
+		if cfg, err := config.Load(absPath, nil, config.Overrides{}); err == nil && cfg.ImageGen.PricePerImage > 0 {
+			price = cfg.ImageGen.PricePerImage
+		}
+	}
+
+	report, err := imagereport.Scan(absPath)
+	if err != nil {
+		Errorln("Error:", err)
+		os.Exit(1)
+	}
+	pending := report.Pending()
+
+	if imagesJSON {
+		data, err := json.MarshalIndent(imagesJSONReport{
+			Report:        report,
+			Pending:       len(pending),
+			PricePerImage: price,
+			EstimatedCost: report.EstimateCost(price),
+		}, "", "  ")
+		if err != nil {
+			Errorln("Error: failed to encode report:", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(data))
+		return
+	}
+
+	if len(report.Images) == 0 {
+		Infoln("No AI images found. Add a <!-- ai-prompt: ... --> comment above an image to generate it.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Slide\tStatus\tPath\tPrompt")
+	for _, img := range report.Images {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", img.Slide, imageStatus(img), img.ImagePath, truncateTitle(img.Prompt, 60))
+	}
+	w.Flush()
+
+	fmt.Println()
+	if len(pending) == 0 {
+		Successln("All images exist; nothing to generate.")
+		return
+	}
+	Info("%d of %d image(s) missing. Estimated cost: $%.2f at $%.2f per image\n", len(pending), len(report.Images), report.EstimateCost(price), price)
+}
+
+// clearImageCache removes the cache of generated images next to the
+// presentation in absPath.
+func clearImageCache(absPath string) {
+	dir := imagecache.Dir(filepath.Join(filepath.Dir(absPath), "images"))
+	count, size, err := imagecache.New(dir, 0).Clear()
+	if err != nil {
+		Errorln("Error:", err)
+		os.Exit(1)
+	}
+	if count == 0 {
+		Infoln("The image cache is empty.")
+		return
+	}
+	Successln(fmt.Sprintf("Removed %d cached image(s), %.1f MB.", count, float64(size)/(1<<20)))
+}
+
+// imageStatus describes whether an image's file exists.
+func imageStatus(img imagereport.Image) string {
+	switch {
+	case img.Remote:
+		return "remote"
+	case img.Exists:
+		return "exists"
+	default:
+		return "missing"
+	}
+}
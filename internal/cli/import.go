@@ -0,0 +1,77 @@
+// Package cli provides the command-line interface for Tap.
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the import command
+var (
+	importOutput string
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import <file.pptx>",
+	Short: "Convert a PowerPoint file to a Tap presentation",
+	Long: `Convert a PowerPoint (.pptx) file to a Tap markdown presentation.
+
+Each slide becomes a Tap slide with:
+  - Its title as a heading
+  - Text as paragraphs and nested bullet lists
+  - Tables as markdown tables
+  - Speaker notes in a notes directive
+  - Embedded images extracted into images/
+
+The import keeps the structure and notes, not the layout: positions, fonts,
+and colors are left for the theme. Hidden slides are marked hidden: true.
+
+Google Slides decks can be imported after File > Download > Microsoft
+PowerPoint (.pptx), and Keynote decks after File > Export To > PowerPoint.
+
+The presentation is written to <name>.md in the output directory. Existing
+files are never overwritten.
+
+Examples:
+  tap import talk.pptx                 # Create talk.md in the current directory
+  tap import talk.pptx -o talk         # Create talk/talk.md and talk/images/`,
+	Args: cobra.ExactArgs(1),
+	Run:  runImport,
+}
+
+func init() {
+	// Register the import command with root
+	rootCmd.AddCommand(importCmd)
+
+	// Command-specific flags
+	importCmd.Flags().StringVarP(&importOutput, "output", "o", ".", "directory to write the presentation and images to")
+}
+
+// runImport executes the import command logic
+func runImport(cmd *cobra.Command, args []string) {
+	file := args[0]
+
+	// Validate that the file exists
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		Errorln("Error: file not found:", file)
+		os.Exit(1)
+	}
+	if ext := strings.ToLower(filepath.Ext(file)); ext != ".pptx" {
+		Error("Error: unsupported file type %q: only .pptx files can be imported; save older .ppt files as .pptx first\n", ext)
+		os.Exit(1)
+	}
+
+	markdownPath, err := importer.FromPPTX(file, importOutput)
+	if err != nil {
+		Errorln("Error: failed to import presentation:", err)
+		os.Exit(1)
+	}
+
+	Success("Created %s\n", markdownPath)
+	Info("Run 'tap dev %s' to preview\n", markdownPath)
+}
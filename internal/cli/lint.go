@@ -0,0 +1,120 @@
+// Package cli provides the command-line interface for Tap.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/report"
+	"github.com/MiniCodeMonkey/tap/internal/validate"
+)
+
+// Flags for the lint command
+var (
+	lintMaxLength int
+)
+
+// lintCmd represents the lint command
+var lintCmd = &cobra.Command{
+	Use:   "lint <file>",
+	Short: "Check a presentation for common mistakes",
+	Long: `Check a presentation for common mistakes before presenting or deploying.
+
+The lint command reports:
+  - Local images and backgrounds that don't exist on disk
+  - ai-prompt comments that aren't followed by an image
+  - Code blocks using a connection that isn't defined in the config
+  - Empty slides and duplicate slide titles
+  - fragments directives with no pause markers or list items
+  - Slides with more content than fits comfortably
+  - Tables with too many columns or too much text to fit across a slide
+
+Errors exit with code 2, so lint can be used in CI. Warnings are reported
+but don't fail the command. Use --json (or TAP_OUTPUT=json) to write each
+issue as a JSON line instead of text.
+
+Examples:
+  tap lint slides.md                   # Check a presentation
+  tap lint slides.md --max-length 800  # Use a stricter content length limit
+  tap lint slides.md --max-length 0    # Disable the content length check
+  tap lint slides.md --json            # Write issues as JSON lines`,
+	Args: cobra.ExactArgs(1),
+	Run:  runLint,
+}
+
+func init() {
+	// Register the lint command with root
+	rootCmd.AddCommand(lintCmd)
+	addJSONFlag(lintCmd)
+
+	// Command-specific flags
+	lintCmd.Flags().IntVar(&lintMaxLength, "max-length", validate.DefaultMaxContentLength, "maximum characters of content per slide (0 to disable)")
+}
+
+// lintResult is the result of a lint run in JSON output.
+type lintResult struct {
+	Slides   int `json:"slides"`
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+}
+
+// runLint executes the lint command logic
+func runLint(cmd *cobra.Command, args []string) {
+	file := args[0]
+	rep := newReporter()
+
+	// Validate that the file exists
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		exitWithError(rep, "lint", report.ExitError, fmt.Errorf("file not found: %s", file))
+	}
+
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		exitWithError(rep, "lint", report.ExitError, fmt.Errorf("failed to resolve file path: %w", err))
+	}
+
+	cfg, err := config.Load(absPath, nil, config.Overrides{})
+	if err != nil {
+		exitWithError(rep, "lint", report.ExitError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	if err := cfg.Validate(); err != nil {
+		exitWithError(rep, "lint", report.ExitValidation, fmt.Errorf("invalid configuration: %w", err))
+	}
+	for _, warning := range cfg.ThemeWarnings() {
+		rep.Warning("lint", "themes: "+warning)
+	}
+
+	pres, err := newParser(cfg).ParseFile(absPath)
+	if err != nil {
+		exitWithError(rep, "lint", report.ExitError, fmt.Errorf("failed to parse presentation: %w", err))
+	}
+
+	v := validate.New(cfg)
+	v.SetMaxContentLength(lintMaxLength)
+	issues := v.Validate(pres, filepath.Dir(absPath))
+
+	result := lintResult{Slides: len(pres.Slides)}
+	for _, issue := range issues {
+		if issue.Severity == validate.SeverityError {
+			result.Errors++
+		} else {
+			result.Warnings++
+		}
+		rep.Issue("lint", string(issue.Severity), issue.String(), issue)
+	}
+
+	summary := report.Summary{Title: fmt.Sprintf("%d errors, %d warnings", result.Errors, result.Warnings)}
+	if len(issues) == 0 {
+		summary.Title = fmt.Sprintf("No issues found in %d slides", len(pres.Slides))
+	}
+	summary.Failed = validate.HasErrors(issues)
+	rep.Result("lint", result, summary)
+	rep.Close()
+
+	if summary.Failed {
+		os.Exit(report.ExitValidation)
+	}
+}
@@ -2,33 +2,58 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/MiniCodeMonkey/tap/internal/scaffold"
 	"github.com/MiniCodeMonkey/tap/internal/tui"
 )
 
 // Flags for the new command
 var (
-	newTheme  string
-	newOutput string
+	newTheme    string
+	newOutput   string
+	newTemplate string
+	newTitle    string
+	newForce    bool
 )
 
 // newCmd represents the new command
 var newCmd = &cobra.Command{
-	Use:   "new",
+	Use:   "new [directory]",
 	Short: "Create a new presentation",
 	Long: `Create a new markdown presentation with the specified theme.
 
-This command creates a new presentation file with frontmatter configuration
-and example slides to help you get started quickly.
+Without a directory, this command creates a single presentation file with
+frontmatter configuration and example slides to help you get started quickly.
+
+With a directory, it creates the directory with a deck.md rendered from a
+built-in template (basic, code-heavy, or workshop), an images/ folder, and a
+.gitignore for the build output. Without --template, an interactive picker
+asks for the title, theme, and template. The directory must be empty unless
+--force is given.
 
 Examples:
   tap new                          # Interactive mode
   tap new --theme paper            # Create with Paper theme
   tap new --output my-talk.md      # Create with custom filename
-  tap new -t aurora -o demo.md     # Combine options`,
+  tap new -t aurora -o demo.md     # Combine options
+  tap new my-talk                  # Create a presentation directory
+  tap new my-talk --template workshop --theme noir`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 1 {
+			runNewProject(args[0])
+			return
+		}
+		if newTemplate != "" || newForce {
+			Error("--template and --force require a directory, e.g. tap new my-talk\n")
+			os.Exit(1)
+		}
+
 		result, err := tui.RunNewWizard(newTheme, newOutput)
 		if err != nil {
 			Error("Failed to create presentation: %v", err)
@@ -48,4 +73,61 @@ func init() {
 	// Command-specific flags
 	newCmd.Flags().StringVarP(&newTheme, "theme", "t", "", "theme for the new presentation (paper, noir, aurora, phosphor, poster)")
 	newCmd.Flags().StringVarP(&newOutput, "output", "o", "", "output filename for the presentation")
+	newCmd.Flags().StringVar(&newTemplate, "template", "", "template for a new presentation directory ("+strings.Join(scaffold.TemplateNames(), ", ")+")")
+	newCmd.Flags().StringVar(&newTitle, "title", "", "title for a new presentation directory (default: derived from the directory name)")
+	newCmd.Flags().BoolVarP(&newForce, "force", "f", false, "create the presentation directory even if it is not empty")
+}
+
+// runNewProject creates a presentation directory. With --template, it is
+// created right away; otherwise the wizard asks for the missing choices.
+func runNewProject(dir string) {
+	if newOutput != "" {
+		Error("--output cannot be used with a directory; the presentation is written to %s\n", filepath.Join(dir, scaffold.DeckFile))
+		os.Exit(1)
+	}
+	if newTemplate != "" {
+		if _, ok := scaffold.FindTemplate(newTemplate); !ok {
+			Error("Unknown template %q (available: %s)\n", newTemplate, strings.Join(scaffold.TemplateNames(), ", "))
+			os.Exit(1)
+		}
+	}
+
+	// Fail before asking any questions
+	if err := scaffold.CheckDir(dir, newForce); err != nil {
+		Error("Failed to create presentation: %v\n", err)
+		os.Exit(1)
+	}
+
+	opts := scaffold.Options{
+		Dir:      dir,
+		Title:    newTitle,
+		Theme:    strings.ToLower(newTheme),
+		Template: newTemplate,
+		Force:    newForce,
+	}
+
+	if newTemplate == "" {
+		result, err := tui.RunNewProjectWizard(opts)
+		if err != nil {
+			Error("Failed to create presentation: %v\n", err)
+			os.Exit(1)
+		}
+		if result.Aborted {
+			os.Exit(0)
+		}
+		return
+	}
+
+	result, err := scaffold.Create(opts)
+	if err != nil {
+		Error("Failed to create presentation: %v\n", err)
+		os.Exit(1)
+	}
+
+	Success("Created %s\n", result.Dir)
+	for _, file := range result.Files {
+		fmt.Printf("  %s\n", file)
+	}
+	fmt.Println()
+	Info("Run 'tap dev %s' to preview\n", result.DeckPath)
 }
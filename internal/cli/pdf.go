@@ -3,6 +3,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,9 +12,9 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/MiniCodeMonkey/tap/internal/config"
-	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/notes"
 	"github.com/MiniCodeMonkey/tap/internal/pdf"
-	"github.com/MiniCodeMonkey/tap/internal/server"
+	"github.com/MiniCodeMonkey/tap/internal/report"
 	"github.com/MiniCodeMonkey/tap/internal/transformer"
 )
 
@@ -21,6 +22,14 @@ import (
 var (
 	pdfOutput  string
 	pdfContent string
+	pdfSlides  string
+	pdfFormat  string
+	pdfMode    string
+	pdfLang    string
+
+	pdfSlidesPerPage int
+	pdfHandoutNotes  bool
+	pdfEmbedSource   bool
 )
 
 // pdfCmd represents the pdf command
@@ -36,13 +45,51 @@ You can choose what content to include in the PDF:
   - slides: Only the slide content (default)
   - notes:  Only the speaker notes
   - both:   Slides with speaker notes below
+  - handout: Several slides per page with lines for notes, for printing
+
+Handouts have 3 slides per portrait page by default, each next to lines
+for attendee notes. Use --slides-per-page 2, 4, or 6 for a landscape grid
+with the lines below each slide, and --handout-notes to print the speaker
+notes instead of lines.
+
+Slides are captured as screenshots by default. With --mode vector, they are
+printed as PDF pages instead: text can be selected and searched, and the
+file is much smaller. Use the default raster mode for themes and slides
+that rely on canvas or WebGL effects.
+
+Slides marked with a hidden: true or skip: true directive are left out.
+
+Set SOURCE_DATE_EPOCH to a Unix timestamp to write it as the PDF's creation
+date, so exporting the same presentation gives the same bytes.
+
+With --format md or --format txt, the speaker notes are written as markdown
+or plain text instead, one section per slide. This doesn't need a browser.
+
+For presentations in several languages, --lang selects the language to
+export; it defaults to the first of the languages frontmatter option.
+
+With --embed-source, the markdown file, the files it includes, and the local
+images it references are attached to the PDF, with a sources.json listing
+their paths, so the presentation can be recovered from an archived PDF.
+
+Use --json (or TAP_OUTPUT=json) to write progress and the result as JSON
+lines for CI.
 
 Examples:
   tap pdf slides.md                        # Export to slides.pdf
   tap pdf slides.md --output handout.pdf   # Custom output filename
   tap pdf slides.md -o talk.pdf            # Short form
   tap pdf slides.md --content notes        # Export only speaker notes
-  tap pdf slides.md --content both         # Slides with notes`,
+  tap pdf slides.md --content both         # Slides with notes
+  tap pdf slides.md --content handout      # 3 slides per page, for printing
+  tap pdf slides.md --content handout --slides-per-page 6
+  tap pdf slides.md --slides 1-5,8         # Export only some slides
+  tap pdf slides.md --mode vector          # Selectable text, smaller file
+  tap pdf slides.md --format md            # Notes as markdown (slides-notes.md)
+  tap pdf slides.md --format txt           # Notes as plain text
+  tap pdf slides.md --lang da              # Export the Danish version
+  tap pdf slides.md --embed-source         # Attach the markdown and images
+  tap pdf slides.md --json                 # JSON lines for CI`,
 	Args: cobra.ExactArgs(1),
 	Run:  runPDF,
 }
@@ -50,35 +97,67 @@ Examples:
 func init() {
 	// Register the pdf command with root
 	rootCmd.AddCommand(pdfCmd)
+	addJSONFlag(pdfCmd)
 
 	// Command-specific flags
 	pdfCmd.Flags().StringVarP(&pdfOutput, "output", "o", "", "output PDF file path (default: <input>.pdf)")
-	pdfCmd.Flags().StringVar(&pdfContent, "content", "slides", "content to include: slides, notes, or both")
+	pdfCmd.Flags().StringVar(&pdfContent, "content", "slides", "content to include: slides, notes, both, or handout")
+	pdfCmd.Flags().IntVar(&pdfSlidesPerPage, "slides-per-page", pdf.DefaultSlidesPerPage, "slides on each handout page: 2, 3, 4, or 6")
+	pdfCmd.Flags().BoolVar(&pdfHandoutNotes, "handout-notes", false, "print speaker notes next to each handout slide instead of lines")
+	pdfCmd.Flags().StringVar(&pdfSlides, "slides", "", "slides to export, e.g. 1-5,8,10-12 (default: all)")
+	pdfCmd.Flags().StringVar(&pdfLang, "lang", "", "language to export, for presentations in several languages")
+	pdfCmd.Flags().StringVar(&pdfMode, "mode", "raster", "how slides are rendered: raster or vector")
+	pdfCmd.Flags().StringVar(&pdfFormat, "format", "pdf", "output format: pdf, or md or txt for speaker notes")
+	pdfCmd.Flags().BoolVar(&pdfEmbedSource, "embed-source", false, "attach the markdown file and its local images to the PDF")
 }
 
 // runPDF executes the pdf command logic
 func runPDF(cmd *cobra.Command, args []string) {
 	file := args[0]
+	rep := newReporter()
 
 	// Validate that the file exists
 	if _, err := os.Stat(file); os.IsNotExist(err) {
-		Errorln("Error: file not found:", file)
-		os.Exit(1)
+		exitWithError(rep, "pdf", report.ExitError, fmt.Errorf("file not found: %s", file))
 	}
 
-	// Get absolute path for base directory resolution
-	absPath, err := filepath.Abs(file)
+	// Validate content type
+	contentType, err := pdf.ValidateContentType(pdfContent)
 	if err != nil {
-		Errorln("Error: failed to resolve file path:", err)
-		os.Exit(1)
+		exitWithError(rep, "pdf", report.ExitError, err)
 	}
-	baseDir := filepath.Dir(absPath)
 
-	// Validate content type
-	contentType, err := pdf.ValidateContentType(pdfContent)
+	// Validate rendering mode
+	mode, err := pdf.ValidateMode(pdfMode)
 	if err != nil {
-		Errorln("Error:", err)
-		os.Exit(1)
+		exitWithError(rep, "pdf", report.ExitError, err)
+	}
+	if mode == pdf.ModeVector && contentType != pdf.ContentSlides {
+		exitWithError(rep, "pdf", report.ExitError, errors.New("--mode vector only applies to --content slides"))
+	}
+
+	// Validate handout options
+	if contentType != pdf.ContentHandout && (cmd.Flags().Changed("slides-per-page") || cmd.Flags().Changed("handout-notes")) {
+		exitWithError(rep, "pdf", report.ExitError, errors.New("--slides-per-page and --handout-notes only apply to --content handout"))
+	}
+	if err := pdf.ValidateSlidesPerPage(pdfSlidesPerPage); err != nil {
+		exitWithError(rep, "pdf", report.ExitError, err)
+	}
+
+	// Notes as text don't need the PDF exporter
+	if pdfFormat != "pdf" {
+		format, err := notes.ValidateFormat(pdfFormat)
+		if err != nil {
+			exitWithError(rep, "pdf", report.ExitError, fmt.Errorf("invalid format %s: must be pdf, md, or txt", pdfFormat))
+		}
+		if cmd.Flags().Changed("content") && contentType != pdf.ContentNotes {
+			exitWithError(rep, "pdf", report.ExitError, fmt.Errorf("--format %s exports speaker notes only; use --content notes", pdfFormat))
+		}
+		if err := runNotesExport(file, format, rep); err != nil {
+			exitWithError(rep, "notes", report.ExitError, fmt.Errorf("notes export failed: %w", err))
+		}
+		rep.Close()
+		return
 	}
 
 	// Determine output path
@@ -89,111 +168,119 @@ func runPDF(cmd *cobra.Command, args []string) {
 		outputPath = strings.TrimSuffix(file, ext) + ".pdf"
 	}
 
-	// Start spinner
-	spinner := newSpinner("Preparing PDF export")
-	spinner.start()
-
-	// Step 1: Load configuration from frontmatter
-	spinner.update("Loading configuration")
-	cfg, err := config.Load(file)
+	// SOURCE_DATE_EPOCH fixes the PDF's dates for reproducible exports
+	creationDate, err := pdf.ParseSourceDateEpoch(os.Getenv("SOURCE_DATE_EPOCH"))
 	if err != nil {
-		spinner.stop()
-		Errorln("Error: failed to load configuration:", err)
-		os.Exit(1)
+		exitWithError(rep, "pdf", report.ExitError, err)
 	}
 
-	// Validate configuration
-	if err := cfg.Validate(); err != nil {
-		spinner.stop()
-		Errorln("Error: invalid configuration:", err)
-		os.Exit(1)
-	}
+	rep.Progress("pdf", "Preparing PDF export", 0, 0)
 
-	// Step 2: Read and parse the presentation file
-	spinner.update("Parsing presentation")
-	content, err := os.ReadFile(file)
+	// Create PDF exporter
+	rep.Progress("pdf", "Initializing PDF exporter", 0, 0)
+	exporter, err := pdf.New()
 	if err != nil {
-		spinner.stop()
-		Errorln("Error: failed to read file:", err)
-		os.Exit(1)
+		exitWithError(rep, "pdf", report.ExitError, fmt.Errorf("failed to create PDF exporter: %w", err))
 	}
 
-	p := parser.New()
-	pres, err := p.Parse(content)
+	// Ensure exporter is cleaned up on exit
+	defer func() {
+		_ = exporter.Close()
+	}()
+
+	// Export to PDF (builds and serves the presentation internally)
+	rep.Progress("pdf", "Generating PDF (this may take a moment)", 0, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	result, err := exporter.ExportFile(ctx, file, pdf.ExportOptions{
+		Content:       contentType,
+		Mode:          mode,
+		SlidesPerPage: pdfSlidesPerPage,
+		HandoutNotes:  pdfHandoutNotes,
+		Output:        outputPath,
+		Slides:        pdfSlides,
+		Lang:          pdfLang,
+		EmbedSource:   pdfEmbedSource,
+		CreationDate:  creationDate,
+		Progress: func(current, total int, stage string) {
+			if stage == pdf.StageAssemble {
+				rep.Progress(stage, "Assembling PDF", 0, 0)
+				return
+			}
+			rep.Progress(stage, "Capturing slides", current, total)
+		},
+	})
 	if err != nil {
-		spinner.stop()
-		Errorln("Error: failed to parse presentation:", err)
-		os.Exit(1)
+		_ = exporter.Close()
+		exitWithError(rep, "pdf", report.ExitError, fmt.Errorf("PDF export failed: %w", err))
 	}
 
-	// Step 3: Transform the presentation
-	spinner.update("Transforming presentation")
-	trans := transformer.NewWithBaseDir(cfg, baseDir)
-	transformed := trans.Transform(pres)
+	summary := report.Summary{
+		Title: "PDF export complete!",
+		Fields: []report.Field{
+			{Label: "Output", Value: result.OutputPath},
+			{Label: "Pages", Value: fmt.Sprint(result.PageCount)},
+			{Label: "File size", Value: formatSize(result.FileSize)},
+		},
+	}
+	if pdfEmbedSource {
+		summary.Fields = append(summary.Fields, report.Field{Label: "Sources", Value: fmt.Sprintf("%d files attached", result.SourceFiles)})
+	}
+	summary.Fields = append(summary.Fields, report.Field{Label: "Time", Value: formatDuration(result.Duration)})
+	rep.Result("pdf", result, summary)
+	for _, warning := range result.Warnings {
+		rep.Warning("pdf", warning)
+	}
+	rep.Close()
+}
 
-	// Step 4: Start temporary dev server (port 0 = random available port)
-	spinner.update("Starting temporary server")
-	srv := server.New(0)
-	srv.SetPresentation(transformed)
-	srv.SetBaseDir(baseDir) // Required for serving local images
-	srv.SetupRoutes()
+// notesResult is the result of a notes export in JSON output.
+type notesResult struct {
+	OutputPath string `json:"outputPath"`
+}
 
-	if err := srv.Start(); err != nil {
-		spinner.stop()
-		Errorln("Error: failed to start temporary server:", err)
-		os.Exit(1)
+// runNotesExport writes the speaker notes of a presentation as markdown or
+// plain text, and reports the output path to rep.
+func runNotesExport(file string, format notes.Format, rep report.Reporter) error {
+	cfg, err := config.Load(file, nil, config.Overrides{Lang: pdfLang})
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
 	}
+	pres, err := newParser(cfg).ParseFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to parse presentation: %w", err)
+	}
+	pres = transformer.New(cfg).SelectLanguage(pres)
 
-	// Ensure server is cleaned up on exit
-	defer func() {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		_ = srv.Shutdown(ctx)
-	}()
-
-	// Get the server URL
-	serverURL := fmt.Sprintf("http://localhost:%d", srv.Port())
+	opts := notes.Options{Format: format, Title: cfg.Title}
+	if pdfSlides != "" {
+		opts.Slides, err = pdf.ParseSlideRange(pdfSlides, len(pres.Slides))
+		if err != nil {
+			return err
+		}
+	}
 
-	// Step 5: Create PDF exporter
-	spinner.update("Initializing PDF exporter")
-	exporter, err := pdf.New()
+	data, err := notes.Export(pres, opts)
 	if err != nil {
-		spinner.stop()
-		Errorln("Error: failed to create PDF exporter:", err)
-		os.Exit(1)
+		return err
 	}
 
-	// Ensure exporter is cleaned up on exit
-	defer func() {
-		_ = exporter.Close()
-	}()
+	// Default: slides.md becomes slides-notes.md, so the input is never overwritten
+	outputPath := pdfOutput
+	if outputPath == "" {
+		outputPath = strings.TrimSuffix(file, filepath.Ext(file)) + "-notes." + string(format)
+	}
+	if absOutput, err := filepath.Abs(outputPath); err == nil {
+		if absInput, err := filepath.Abs(file); err == nil && absOutput == absInput {
+			return fmt.Errorf("output file %s is the presentation itself", outputPath)
+		}
+	}
 
-	// Step 6: Export to PDF
-	spinner.update("Generating PDF (this may take a moment)")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write notes: %w", err)
+	}
 
-	result, err := exporter.Export(ctx, serverURL, pdf.ExportOptions{
-		Content: contentType,
-		Output:  outputPath,
-		Title:   cfg.Title,
-		Author:  cfg.Author,
-	})
-	if err != nil {
-		spinner.stop()
-		Errorln("Error: PDF export failed:", err)
-		os.Exit(1)
-	}
-
-	// Stop spinner and show results
-	spinner.stop()
-
-	// Print success message and export stats
-	Successln("\nPDF export complete!")
-	fmt.Println()
-	fmt.Printf("  Output:    %s\n", result.OutputPath)
-	fmt.Printf("  Pages:     %d\n", result.PageCount)
-	fmt.Printf("  File size: %s\n", formatSize(result.FileSize))
-	fmt.Printf("  Time:      %s\n", formatDuration(result.Duration))
-	fmt.Println()
+	rep.Result("notes", notesResult{OutputPath: outputPath}, report.Summary{Title: "Notes exported to " + outputPath})
+	return nil
 }
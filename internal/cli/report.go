@@ -0,0 +1,38 @@
+// Package cli provides the command-line interface for Tap.
+package cli
+
+import (
+	"os"
+
+	"github.com/MiniCodeMonkey/tap/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// outputJSON is set by the --json flag of the commands that report through
+// the report package.
+var outputJSON bool
+
+// addJSONFlag adds the --json flag, which writes progress and results as JSON
+// lines, to cmd.
+func addJSONFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&outputJSON, "json", false, "write progress and results as JSON lines, for CI (or set "+report.EnvOutput+"=json)")
+}
+
+// newReporter returns a reporter writing to stdout in the format selected by
+// the --json flag or the TAP_OUTPUT environment variable.
+func newReporter() report.Reporter {
+	format, err := report.ResolveFormat(outputJSON, os.Getenv(report.EnvOutput))
+	if err != nil {
+		Errorln("Error:", err)
+		os.Exit(report.ExitError)
+	}
+	return report.New(format, os.Stdout)
+}
+
+// exitWithError reports err as the error that stopped stage and exits with
+// code, one of the report package's exit codes.
+func exitWithError(rep report.Reporter, stage string, code int, err error) {
+	rep.Error(stage, err)
+	rep.Close()
+	os.Exit(code)
+}
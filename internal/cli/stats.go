@@ -0,0 +1,138 @@
+// Package cli provides the command-line interface for Tap.
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/stats"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+	"github.com/spf13/cobra"
+)
+
+// Flags for the stats command
+var (
+	statsWordsPerMinute int
+	statsOverhead       time.Duration
+)
+
+// statsCmd represents the stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats <file>",
+	Short: "Show word counts and the estimated speaking time",
+	Long: `Show word counts and the estimated speaking time of a presentation.
+
+For each slide, the stats command reports:
+  - Words of visible content, not counting code blocks
+  - Words of the speaker notes
+  - Lines of code
+  - Estimated speaking time
+
+The speaking time is the slide's words and note words at the speaking rate,
+plus a fixed overhead per slide for pauses and slide changes. Hidden slides
+are left out. If the frontmatter sets a duration, stats warns when the
+estimated total exceeds it.
+
+Examples:
+  tap stats slides.md                  # Show statistics per slide
+  tap stats slides.md --wpm 160        # Estimate for a faster speaker
+  tap stats slides.md --overhead 30s   # Allow more time per slide`,
+	Args: cobra.ExactArgs(1),
+	Run:  runStats,
+}
+
+func init() {
+	// Register the stats command with root
+	rootCmd.AddCommand(statsCmd)
+
+	// Command-specific flags
+	statsCmd.Flags().IntVar(&statsWordsPerMinute, "wpm", stats.DefaultWordsPerMinute, "speaking rate in words per minute")
+	statsCmd.Flags().DurationVar(&statsOverhead, "overhead", stats.DefaultSlideOverhead, "time added to each slide for pauses and slide changes")
+}
+
+// runStats executes the stats command logic
+func runStats(cmd *cobra.Command, args []string) {
+	file := args[0]
+
+	if statsWordsPerMinute <= 0 {
+		Errorln("Error: --wpm must be greater than 0")
+		os.Exit(1)
+	}
+	if statsOverhead < 0 {
+		Errorln("Error: --overhead must not be negative")
+		os.Exit(1)
+	}
+
+	// Validate that the file exists
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		Errorln("Error: file not found:", file)
+		os.Exit(1)
+	}
+
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		Errorln("Error: failed to resolve file path:", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load(absPath, nil, config.Overrides{})
+	if err != nil {
+		Errorln("Error: failed to load configuration:", err)
+		os.Exit(1)
+	}
+	if err := cfg.Validate(); err != nil {
+		Errorln("Error: invalid configuration:", err)
+		os.Exit(1)
+	}
+
+	pres, err := newParser(cfg).ParseFile(absPath)
+	if err != nil {
+		Errorln("Error: failed to parse presentation:", err)
+		os.Exit(1)
+	}
+	// Count a presentation in several languages in its default language
+	pres = transformer.New(cfg).SelectLanguage(pres)
+
+	s := stats.Compute(pres, stats.Options{
+		WordsPerMinute: statsWordsPerMinute,
+		SlideOverhead:  statsOverhead,
+		Duration:       cfg.TalkDuration(),
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Slide\tTitle\tWords\tNotes\tCode\tTime")
+	for _, slide := range s.Slides {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%s\n", slide.Number, truncateTitle(slide.Title, 40), slide.Words, slide.NoteWords, slide.CodeLines, formatSpeakingTime(slide.SpeakingTime))
+	}
+	fmt.Fprintf(w, "Total\t\t%d\t%d\t%d\t%s\n", s.Words, s.NoteWords, s.CodeLines, formatSpeakingTime(s.SpeakingTime))
+	w.Flush()
+
+	fmt.Println()
+	if s.OverDuration() {
+		Warning("Estimated %s exceeds the %s duration by %s\n", formatSpeakingTime(s.SpeakingTime), formatSpeakingTime(s.Duration), formatSpeakingTime(s.SpeakingTime-s.Duration))
+		return
+	}
+	Info("Estimated speaking time: %s at %d words per minute\n", formatSpeakingTime(s.SpeakingTime), statsWordsPerMinute)
+}
+
+// formatSpeakingTime formats a duration as m:ss, or h:mm:ss for an hour or more.
+func formatSpeakingTime(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// truncateTitle shortens a title to at most max characters.
+func truncateTitle(title string, max int) string {
+	runes := []rune(title)
+	if len(runes) <= max {
+		return title
+	}
+	return string(runes[:max-1]) + "…"
+}
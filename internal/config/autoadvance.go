@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseAutoAdvance parses how long a slide is shown before auto-play moves
+// to the next one: a length like 8s or 1m, or a whole number of seconds.
+// Zero and negative lengths are errors.
+func ParseAutoAdvance(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds > 0 {
+			return time.Duration(seconds) * time.Second, nil
+		}
+	} else if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d, nil
+	}
+	return 0, fmt.Errorf("invalid autoAdvance %q: must be a positive length like 8s or 1m, or a number of seconds", value)
+}
+
+// DefaultAutoAdvance returns how long slides without an autoAdvance
+// directive are shown before auto-play moves on, or 0 if they wait for the
+// presenter. An invalid autoAdvance option, which the frontmatter reports as
+// a warning, is 0.
+func (c *Config) DefaultAutoAdvance() time.Duration {
+	if c.AutoAdvance == "" {
+		return 0
+	}
+	d, err := ParseAutoAdvance(c.AutoAdvance)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// autoAdvanceWarning checks the autoAdvance option set by a frontmatter
+// mapping. An invalid value is dropped, so slides wait for the presenter,
+// and returned as a warning on the line of its key.
+func (c *Config) autoAdvanceWarning(root *yaml.Node) (Warning, bool) {
+	if c.AutoAdvance == "" {
+		return Warning{}, false
+	}
+	_, err := ParseAutoAdvance(c.AutoAdvance)
+	if err == nil {
+		return Warning{}, false
+	}
+	c.AutoAdvance = ""
+
+	warning := Warning{Key: "autoAdvance", Message: err.Error() + "; slides don't advance on their own"}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == warning.Key {
+			warning.Line = root.Content[i].Line
+		}
+	}
+	return warning, true
+}
@@ -0,0 +1,81 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAutoAdvance(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"8s", 8 * time.Second},
+		{"1m", time.Minute},
+		{"1m30s", 90 * time.Second},
+		{"500ms", 500 * time.Millisecond},
+		{"8", 8 * time.Second},
+		{" 12 ", 12 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseAutoAdvance(tt.value)
+			if err != nil {
+				t.Fatalf("ParseAutoAdvance(%q) returned error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAutoAdvance(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAutoAdvance_Invalid(t *testing.T) {
+	for _, value := range []string{"", "0", "-5", "0s", "-2s", "abc", "8 seconds"} {
+		t.Run(value, func(t *testing.T) {
+			_, err := ParseAutoAdvance(value)
+			if err == nil {
+				t.Fatalf("ParseAutoAdvance(%q) should return an error", value)
+			}
+			if !strings.Contains(err.Error(), "invalid autoAdvance") {
+				t.Errorf("error %q should name the option", err)
+			}
+		})
+	}
+}
+
+func TestParseFrontmatter_AutoAdvance(t *testing.T) {
+	cfg, warnings, err := ParseFrontmatter([]byte("title: Kiosk\nautoAdvance: 10\nloop: true\n"))
+	if err != nil {
+		t.Fatalf("ParseFrontmatter() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %v, want none", warnings)
+	}
+	if got := cfg.DefaultAutoAdvance(); got != 10*time.Second {
+		t.Errorf("DefaultAutoAdvance() = %v, want 10s", got)
+	}
+	if !cfg.Loop {
+		t.Error("Loop = false, want true")
+	}
+}
+
+func TestParseFrontmatter_InvalidAutoAdvance(t *testing.T) {
+	cfg, warnings, err := ParseFrontmatter([]byte("title: Kiosk\nloop: true\nautoAdvance: -3s\n"))
+	if err != nil {
+		t.Fatalf("ParseFrontmatter() error = %v", err)
+	}
+	if len(warnings) != 1 {
+		t.Fatalf("warnings = %v, want one", warnings)
+	}
+	if got := warnings[0]; got.Key != "autoAdvance" || got.Line != 3 || !strings.Contains(got.Message, `"-3s"`) {
+		t.Errorf("warning = %+v, want the autoAdvance key on line 3", got)
+	}
+
+	// The invalid value is dropped, so slides wait for the presenter
+	if cfg.AutoAdvance != "" || cfg.DefaultAutoAdvance() != 0 {
+		t.Errorf("AutoAdvance = %q, want it dropped", cfg.AutoAdvance)
+	}
+}
@@ -0,0 +1,216 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Warning describes a problem in the frontmatter that doesn't stop the
+// presentation from loading, such as an unknown key.
+type Warning struct {
+	Key     string // Frontmatter key the warning is about
+	Line    int    // Line of the key, starting at 1; 0 if unknown
+	Message string
+}
+
+// String returns the warning message, prefixed with its line if known.
+func (w Warning) String() string {
+	if w.Line > 0 {
+		return fmt.Sprintf("line %d: %s", w.Line, w.Message)
+	}
+	return w.Message
+}
+
+// frontmatterKeys are the keys accepted at the top level of the frontmatter,
+// taken from the yaml tags of Config.
+var frontmatterKeys = yamlKeys(reflect.TypeOf(Config{}))
+
+// ParseFrontmatter parses YAML frontmatter, without its "---" delimiters, into
+// a Config with default values for missing keys. Unknown keys are returned as
+// warnings with a suggestion when they look like a typo of a known key, and
+// so is an invalid autoAdvance, which is dropped; line numbers are relative
+// to data. An invalid aspectRatio or transition is an
+// error listing the valid values. The theme is checked by Validate, since
+// custom themes depend on the presentation's directory.
+func ParseFrontmatter(data []byte) (*Config, []Warning, error) {
+	cfg := DefaultConfig()
+	warnings, err := cfg.applyFrontmatter(data)
+	if err != nil {
+		return nil, warnings, err
+	}
+	return cfg, warnings, nil
+}
+
+// applyFrontmatter sets the options in YAML frontmatter on c, keeping the
+// values of missing keys. Maps such as connections are merged by key. It
+// returns the same warnings and errors as ParseFrontmatter.
+func (c *Config) applyFrontmatter(data []byte) ([]Warning, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		// Empty frontmatter
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	if err := root.Decode(c); err != nil {
+		return nil, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+
+	warnings := unknownKeyWarnings(root)
+	if warning, ok := c.autoAdvanceWarning(root); ok {
+		warnings = append(warnings, warning)
+	}
+
+	if err := c.validateOptions(); err != nil {
+		return warnings, err
+	}
+
+	return warnings, nil
+}
+
+// unknownKeyWarnings returns a warning for each top-level key in a frontmatter
+// mapping that Config doesn't define.
+func unknownKeyWarnings(root *yaml.Node) []Warning {
+	if root.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var warnings []Warning
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if containsString(frontmatterKeys, key.Value) {
+			continue
+		}
+
+		message := fmt.Sprintf("unknown key %q is ignored", key.Value) + didYouMean(key.Value, frontmatterKeys)
+		warnings = append(warnings, Warning{Key: key.Value, Line: key.Line, Message: message})
+	}
+	return warnings
+}
+
+// validateOptions checks the values that must be one of a fixed set of options.
+func (c *Config) validateOptions() error {
+	if c.AspectRatio != "" && !containsString(validAspectRatios, c.AspectRatio) {
+		return invalidOptionError("aspectRatio", c.AspectRatio, validAspectRatios)
+	}
+	if c.Transition != "" {
+		if _, err := ParseTransition(c.Transition); err != nil {
+			return err
+		}
+	}
+	if c.Duration != "" {
+		if d, err := time.ParseDuration(c.Duration); err != nil || d <= 0 {
+			return fmt.Errorf("invalid duration %q: must be a length like 30m or 1h15m", c.Duration)
+		}
+	}
+	if c.URL != "" {
+		if u, err := url.Parse(c.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid url %q: must be an absolute URL like https://example.com/talk/", c.URL)
+		}
+	}
+	for _, lang := range c.Languages {
+		if !languagePattern.MatchString(lang) {
+			return fmt.Errorf("invalid language %q in languages: must be a code like en or pt-BR", lang)
+		}
+	}
+	if c.Lang != "" && len(c.Languages) > 0 && !containsString(c.Languages, c.Lang) {
+		return invalidOptionError("lang", c.Lang, c.Languages)
+	}
+	return nil
+}
+
+// languagePattern matches the language codes of the languages option, which
+// are written after :::lang in slides.
+var languagePattern = regexp.MustCompile(`^[A-Za-z][\w-]*$`)
+
+// invalidOptionError returns an error for a value that is not one of options,
+// listing the options and suggesting the closest one.
+func invalidOptionError(key, value string, options []string) error {
+	message := fmt.Sprintf("invalid %s %q: must be one of %s", key, value, joinOptions(options))
+	if suggestion := suggest(value, options); suggestion != "" {
+		message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+	}
+	return fmt.Errorf("%s", message)
+}
+
+// didYouMean returns "; did you mean ...?" with the option closest to value,
+// or "" if no option is close.
+func didYouMean(value string, options []string) string {
+	if suggestion := suggest(value, options); suggestion != "" {
+		return fmt.Sprintf("; did you mean %q?", suggestion)
+	}
+	return ""
+}
+
+// joinOptions joins options into a list like "a, b, or c".
+func joinOptions(options []string) string {
+	switch len(options) {
+	case 0:
+		return ""
+	case 1:
+		return options[0]
+	case 2:
+		return options[0] + " or " + options[1]
+	}
+	return strings.Join(options[:len(options)-1], ", ") + ", or " + options[len(options)-1]
+}
+
+// suggest returns the option closest to value, ignoring case, if it is close
+// enough to be a likely typo. It returns "" when no option is close.
+func suggest(value string, options []string) string {
+	value = strings.ToLower(value)
+	maxDistance := max(2, len(value)/3)
+
+	best := ""
+	bestDistance := maxDistance + 1
+	for _, option := range options {
+		if d := levenshtein(value, strings.ToLower(option)); d < bestDistance {
+			best = option
+			bestDistance = d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between a and b: the number of
+// single-character insertions, deletions, and substitutions to turn a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// containsString reports whether values contains s.
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/themes"
+)
+
+func TestParseFrontmatter_UnknownKeys(t *testing.T) {
+	data := []byte("title: Talk\nthem: noir\naspectratio: \"16:9\"\nfavoriteColor: blue\n")
+
+	cfg, warnings, err := ParseFrontmatter(data)
+	if err != nil {
+		t.Fatalf("ParseFrontmatter() error = %v", err)
+	}
+	if cfg.Title != "Talk" || cfg.Theme != "paper" {
+		t.Errorf("expected title to be set and theme to keep its default, got %q and %q", cfg.Title, cfg.Theme)
+	}
+
+	if len(warnings) != 3 {
+		t.Fatalf("expected 3 warnings, got %v", warnings)
+	}
+	want := []struct {
+		key        string
+		line       int
+		suggestion string
+	}{
+		{"them", 2, `did you mean "theme"?`},
+		{"aspectratio", 3, `did you mean "aspectRatio"?`},
+		{"favoriteColor", 4, ""},
+	}
+	for i, w := range want {
+		got := warnings[i]
+		if got.Key != w.key || got.Line != w.line {
+			t.Errorf("warning %d = %+v, want key %q on line %d", i, got, w.key, w.line)
+		}
+		if w.suggestion != "" && !strings.Contains(got.Message, w.suggestion) {
+			t.Errorf("warning %q should suggest %s", got.Message, w.suggestion)
+		}
+		if w.suggestion == "" && strings.Contains(got.Message, "did you mean") {
+			t.Errorf("warning %q should not suggest a key", got.Message)
+		}
+	}
+}
+
+func TestParseFrontmatter_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "aspect ratio",
+			data: "aspectRatio: 16x9",
+			want: []string{`invalid aspectRatio "16x9"`, "16:9, 4:3, or 16:10", `did you mean "16:9"?`},
+		},
+		{
+			name: "transition",
+			data: "transition: fdae",
+			want: []string{`invalid transition "fdae"`, "none, fade, slide, push, or zoom", `did you mean "fade"?`},
+		},
+		{
+			name: "transition without a close match",
+			data: "transition: dissolve",
+			want: []string{`invalid transition "dissolve"`},
+		},
+		{
+			name: "duration without a unit",
+			data: "duration: 30",
+			want: []string{`invalid duration "30"`, "30m or 1h15m"},
+		},
+		{
+			name: "negative duration",
+			data: "duration: -5m",
+			want: []string{`invalid duration "-5m"`},
+		},
+		{
+			name: "relative url",
+			data: "url: talks/intro/",
+			want: []string{`invalid url "talks/intro/"`, "absolute URL"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseFrontmatter([]byte(tt.data))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q should contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseFrontmatter_Duration(t *testing.T) {
+	cfg, _, err := ParseFrontmatter([]byte("duration: 1h15m"))
+	if err != nil {
+		t.Fatalf("ParseFrontmatter() error = %v", err)
+	}
+	if got := cfg.TalkDuration(); got != 75*time.Minute {
+		t.Errorf("TalkDuration() = %v, want 1h15m0s", got)
+	}
+
+	if got := DefaultConfig().TalkDuration(); got != 0 {
+		t.Errorf("TalkDuration() without a duration = %v, want 0", got)
+	}
+}
+
+func TestParseFrontmatter_TextReplacements(t *testing.T) {
+	cfg, warnings, err := ParseFrontmatter([]byte("emoji: false\nsmartypants: false\n"))
+	if err != nil {
+		t.Fatalf("ParseFrontmatter() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+	if cfg.Emoji || cfg.Smartypants {
+		t.Errorf("expected emoji and smartypants to be disabled, got %v and %v", cfg.Emoji, cfg.Smartypants)
+	}
+
+	if defaults := DefaultConfig(); !defaults.Emoji || !defaults.Smartypants {
+		t.Error("emoji and smartypants should be enabled by default")
+	}
+}
+
+func TestParseFrontmatter_Empty(t *testing.T) {
+	cfg, warnings, err := ParseFrontmatter(nil)
+	if err != nil {
+		t.Fatalf("ParseFrontmatter() error = %v", err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", warnings)
+	}
+	if cfg.Theme != "paper" || cfg.AspectRatio != "16:9" {
+		t.Errorf("expected defaults, got %+v", cfg)
+	}
+}
+
+func TestLoad_Warnings(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("---\ntitle: Talk\nthem: noir\n---\n\n# Slide"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(mdFile, nil, Overrides{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	warnings := cfg.Warnings()
+	if len(warnings) != 1 {
+		t.Fatalf("expected 1 warning, got %v", warnings)
+	}
+	if warnings[0].Line != 3 {
+		t.Errorf("expected the warning on line 3 of the file, got %d", warnings[0].Line)
+	}
+	if got := warnings[0].String(); !strings.HasPrefix(got, "line 3: ") {
+		t.Errorf("String() = %q, want line prefix", got)
+	}
+}
+
+func TestLoad_Languages(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("---\nlanguages: [en, da]\n---\n\n# Slide"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(mdFile, nil, Overrides{Lang: "da"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(cfg.Languages) != 2 || cfg.Languages[0] != "en" || cfg.Lang != "da" {
+		t.Errorf("Languages = %q, Lang = %q, want [en da] and da", cfg.Languages, cfg.Lang)
+	}
+
+	_, err = Load(mdFile, nil, Overrides{Lang: "de"})
+	if err == nil || !strings.Contains(err.Error(), `invalid lang "de"`) {
+		t.Errorf("expected an undeclared language to be an error, got %v", err)
+	}
+
+	cfg = DefaultConfig()
+	cfg.Languages = []string{"en", "da dk"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `invalid language "da dk"`) {
+		t.Errorf("expected an invalid language code to be an error, got %v", err)
+	}
+}
+
+func TestValidate_InvalidThemeSuggestion(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Theme = "my-brnd"
+	cfg.SetCustomThemes([]themes.Theme{{Name: "my-brand", Path: "themes/my-brand.css"}})
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected an error for an unknown theme")
+	}
+	for _, want := range []string{"paper", "my-brand", `did you mean "my-brand"?`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should contain %q", err, want)
+		}
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"theme", "theme", 0},
+		{"them", "theme", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
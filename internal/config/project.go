@@ -0,0 +1,176 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultOutputDir is the build output directory when none is configured.
+const DefaultOutputDir = "dist"
+
+// projectConfig holds the options a project config file can set for all the
+// presentations below it.
+type projectConfig struct {
+	Connections map[string]ConnectionConfig `yaml:"connections"`
+	ImageGen    *ImageGenConfig             `yaml:"imageGen"`
+	Theme       string                      `yaml:"theme"`
+	Transition  string                      `yaml:"transition"`
+	AspectRatio string                      `yaml:"aspectRatio"`
+	Author      string                      `yaml:"author"`
+	Output      string                      `yaml:"output"`
+}
+
+// projectConfigKeys are the keys accepted at the top level of a project
+// config file, taken from the yaml tags of projectConfig.
+var projectConfigKeys = yamlKeys(reflect.TypeOf(projectConfig{}))
+
+// Overrides are options set on the command line. They take precedence over
+// the frontmatter and the project config file. Empty fields are not set.
+type Overrides struct {
+	Theme       string
+	Transition  string
+	AspectRatio string
+	Author      string
+	Output      string // Build output directory, relative to the working directory
+	Lang        string // Language to render, for presentations in several languages
+}
+
+// apply sets the options of o that are set on cfg.
+func (o Overrides) apply(cfg *Config) {
+	for _, option := range []struct {
+		value string
+		field *string
+	}{
+		{o.Theme, &cfg.Theme},
+		{o.Transition, &cfg.Transition},
+		{o.AspectRatio, &cfg.AspectRatio},
+		{o.Author, &cfg.Author},
+		{o.Output, &cfg.Output},
+		{o.Lang, &cfg.Lang},
+	} {
+		if option.value != "" {
+			*option.field = option.value
+		}
+	}
+}
+
+// FindProjectConfig returns the path of the project config file that applies
+// to the presentations in dir: the one in dir, or else in the nearest parent
+// directory. The search stops at the repository root, the first directory
+// with a .git entry, or at the filesystem root. It returns "" if there is none.
+func FindProjectConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, ProjectConfigFile)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// applyProjectConfig sets the options in the project config file that applies
+// to the presentations in dir, if any. Unknown keys are an error, since a
+// typo would otherwise silently change every presentation. Connections are
+// added by name, so a presentation can override some and keep the others.
+func (c *Config) applyProjectConfig(dir string) error {
+	path := FindProjectConfig(dir)
+	if path == "" {
+		return nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		// Empty file
+		return nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			key := root.Content[i]
+			if !containsString(projectConfigKeys, key.Value) {
+				return fmt.Errorf("invalid %s: line %d: unknown key %q%s", path, key.Line, key.Value, didYouMean(key.Value, projectConfigKeys))
+			}
+		}
+	}
+
+	var project projectConfig
+	if err := root.Decode(&project); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	Overrides{
+		Theme:       project.Theme,
+		Transition:  project.Transition,
+		AspectRatio: project.AspectRatio,
+		Author:      project.Author,
+		Output:      resolvePath(filepath.Dir(path), project.Output),
+	}.apply(c)
+
+	if project.ImageGen != nil {
+		c.ImageGen = *project.ImageGen
+	}
+
+	for name, conn := range project.Connections {
+		if c.Connections == nil {
+			c.Connections = make(map[string]ConnectionConfig)
+		}
+		c.Connections[name] = conn
+	}
+
+	if err := c.validateOptions(); err != nil {
+		return fmt.Errorf("invalid %s: %w", path, err)
+	}
+	return nil
+}
+
+// OutputDir returns the build output directory: the output option, or
+// DefaultOutputDir if it is not set.
+func (c *Config) OutputDir() string {
+	if c.Output == "" {
+		return DefaultOutputDir
+	}
+	return c.Output
+}
+
+// resolvePath resolves a relative path against dir. Empty and absolute paths
+// are returned unchanged.
+func resolvePath(dir, path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// yamlKeys returns the yaml keys of the fields of struct type t.
+func yamlKeys(t reflect.Type) []string {
+	var keys []string
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTestFile writes content to path, creating its directory.
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const testProjectConfig = `theme: noir
+transition: slide
+aspectRatio: "4:3"
+author: Project Author
+output: public
+`
+
+func TestLoad_ProjectDefaults(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "slides.md")
+	writeTestFile(t, mdFile, "# Slide\n")
+	writeTestFile(t, filepath.Join(dir, ProjectConfigFile), testProjectConfig)
+
+	cfg, err := Load(mdFile, nil, Overrides{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "noir" || cfg.Transition != "slide" || cfg.AspectRatio != "4:3" || cfg.Author != "Project Author" {
+		t.Errorf("expected the tap.yaml defaults, got theme=%q transition=%q aspectRatio=%q author=%q", cfg.Theme, cfg.Transition, cfg.AspectRatio, cfg.Author)
+	}
+	if want := filepath.Join(dir, "public"); cfg.OutputDir() != want {
+		t.Errorf("OutputDir() = %q, want %q", cfg.OutputDir(), want)
+	}
+
+	// Options tap.yaml doesn't set keep their defaults
+	if cfg.CodeTheme != "github-dark" || !cfg.Fragments {
+		t.Errorf("expected defaults for other options, got codeTheme=%q fragments=%v", cfg.CodeTheme, cfg.Fragments)
+	}
+}
+
+func TestLoad_ProjectConfigInParentDirectory(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ProjectConfigFile), testProjectConfig)
+	mdFile := filepath.Join(root, "talks", "2026", "slides.md")
+	writeTestFile(t, mdFile, "# Slide\n")
+
+	cfg, err := Load(mdFile, nil, Overrides{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "noir" {
+		t.Errorf("expected the theme from the parent tap.yaml, got %q", cfg.Theme)
+	}
+	// The output is relative to tap.yaml, not the presentation
+	if want := filepath.Join(root, "public"); cfg.OutputDir() != want {
+		t.Errorf("OutputDir() = %q, want %q", cfg.OutputDir(), want)
+	}
+}
+
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	writeTestFile(t, filepath.Join(root, ProjectConfigFile), testProjectConfig)
+	repo := filepath.Join(root, "repo")
+	if err := os.MkdirAll(filepath.Join(repo, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	talks := filepath.Join(repo, "talks")
+	if err := os.MkdirAll(talks, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// The search stops at the repository root
+	if got := FindProjectConfig(talks); got != "" {
+		t.Errorf("FindProjectConfig() = %q, want no config outside the repository", got)
+	}
+
+	writeTestFile(t, filepath.Join(repo, ProjectConfigFile), "theme: ink\n")
+	if got, want := FindProjectConfig(talks), filepath.Join(repo, ProjectConfigFile); got != want {
+		t.Errorf("FindProjectConfig() = %q, want %q", got, want)
+	}
+
+	// The nearest config wins
+	writeTestFile(t, filepath.Join(talks, ProjectConfigFile), "theme: mono\n")
+	if got, want := FindProjectConfig(talks), filepath.Join(talks, ProjectConfigFile); got != want {
+		t.Errorf("FindProjectConfig() = %q, want %q", got, want)
+	}
+}
+
+func TestLoad_FrontmatterOverridesProjectConfig(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ProjectConfigFile), testProjectConfig)
+	mdFile := filepath.Join(dir, "deck", "slides.md")
+	writeTestFile(t, mdFile, "---\ntheme: aurora\noutput: site\n---\n\n# Slide\n")
+
+	cfg, err := Load(mdFile, nil, Overrides{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "aurora" {
+		t.Errorf("expected the frontmatter theme, got %q", cfg.Theme)
+	}
+	if cfg.Transition != "slide" || cfg.Author != "Project Author" {
+		t.Errorf("expected tap.yaml for options the frontmatter doesn't set, got transition=%q author=%q", cfg.Transition, cfg.Author)
+	}
+	// The output is relative to the presentation
+	if want := filepath.Join(dir, "deck", "site"); cfg.OutputDir() != want {
+		t.Errorf("OutputDir() = %q, want %q", cfg.OutputDir(), want)
+	}
+}
+
+func TestLoad_GivenFrontmatter(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "slides.md")
+	writeTestFile(t, mdFile, "---\ntheme: aurora\n---\n\n# Slide\n")
+	writeTestFile(t, filepath.Join(dir, ProjectConfigFile), testProjectConfig)
+
+	// Given frontmatter is used instead of the file's
+	cfg, err := Load(mdFile, []byte("theme: ink\nunknown: true\n"), Overrides{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "ink" {
+		t.Errorf("expected the given frontmatter theme, got %q", cfg.Theme)
+	}
+	if warnings := cfg.Warnings(); len(warnings) != 1 || warnings[0].Line != 3 {
+		t.Errorf("expected a warning on line 3 of the file, got %v", warnings)
+	}
+
+	// Empty frontmatter leaves the tap.yaml options
+	cfg, err = Load(mdFile, []byte{}, Overrides{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "noir" {
+		t.Errorf("expected the tap.yaml theme, got %q", cfg.Theme)
+	}
+}
+
+func TestLoad_FlagsOverrideAll(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, ProjectConfigFile), testProjectConfig)
+	mdFile := filepath.Join(dir, "slides.md")
+	writeTestFile(t, mdFile, "---\ntheme: aurora\ntransition: zoom\noutput: site\n---\n\n# Slide\n")
+
+	cfg, err := Load(mdFile, nil, Overrides{Theme: "ink", Output: "build"})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Theme != "ink" {
+		t.Errorf("expected the flag theme, got %q", cfg.Theme)
+	}
+	if cfg.Transition != "zoom" || cfg.AspectRatio != "4:3" {
+		t.Errorf("expected unset flags to keep the other layers, got transition=%q aspectRatio=%q", cfg.Transition, cfg.AspectRatio)
+	}
+	// The output flag is used as given
+	if cfg.OutputDir() != "build" {
+		t.Errorf("OutputDir() = %q, want %q", cfg.OutputDir(), "build")
+	}
+
+	if _, err := Load(mdFile, nil, Overrides{Transition: "spin"}); err == nil || !strings.Contains(err.Error(), "invalid transition") {
+		t.Errorf("expected an invalid transition flag to be an error, got %v", err)
+	}
+}
+
+func TestLoad_OutputDefault(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "slides.md")
+	writeTestFile(t, mdFile, "# Slide\n")
+
+	cfg, err := Load(mdFile, nil, Overrides{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.OutputDir() != DefaultOutputDir {
+		t.Errorf("OutputDir() = %q, want %q", cfg.OutputDir(), DefaultOutputDir)
+	}
+}
+
+func TestLoad_InvalidProjectConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr []string
+	}{
+		{"unknown key with suggestion", "theme: noir\ntransiton: fade\n", []string{"line 2", `unknown key "transiton"`, `did you mean "transition"?`}},
+		{"unknown key", "fragments: false\n", []string{`unknown key "fragments"`}},
+		{"invalid value", "aspectRatio: \"21:9\"\n", []string{"invalid aspectRatio"}},
+		{"invalid yaml", "theme: [noir\n", []string{"failed to parse"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			mdFile := filepath.Join(dir, "slides.md")
+			writeTestFile(t, mdFile, "# Slide\n")
+			writeTestFile(t, filepath.Join(dir, ProjectConfigFile), tt.content)
+
+			_, err := Load(mdFile, nil, Overrides{})
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("expected error to contain %q, got %v", want, err)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_ProjectImageGen(t *testing.T) {
+	t.Setenv("TEST_IMAGE_KEY", "secret")
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "slides.md")
+	writeTestFile(t, mdFile, "# Slide\n")
+	writeTestFile(t, filepath.Join(dir, ProjectConfigFile), "imageGen:\n  provider: openai-compatible\n  baseURL: http://localhost:9000/v1\n  model: sdxl\n  apiKey: ${TEST_IMAGE_KEY}\n")
+
+	cfg, err := Load(mdFile, nil, Overrides{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := ImageGenConfig{Provider: "openai-compatible", BaseURL: "http://localhost:9000/v1", Model: "sdxl", APIKey: "secret"}
+	if cfg.ImageGen != want {
+		t.Errorf("ImageGen = %+v, want %+v", cfg.ImageGen, want)
+	}
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// transitionDirections contains the allowed directions of the slide and push
+// transitions.
+var transitionDirections = []string{"left", "right", "up", "down"}
+
+// directionalTransitions contains the transitions that take a direction.
+var directionalTransitions = []string{"slide", "push"}
+
+// TransitionSpec is a slide transition. Going back to the previous slide
+// plays it in reverse.
+type TransitionSpec struct {
+	Name string `json:"name"`
+	// Direction the slides move when advancing: left, right, up, or down.
+	// Empty for the transition's default, which is left.
+	Direction string `json:"direction,omitempty"`
+	// Duration in milliseconds, or 0 for the frontend default.
+	Duration int `json:"duration,omitempty"`
+}
+
+// ParseTransition parses a transition value like "fade", "slide 400ms", or
+// "slide-left 0.5s". A duration without a unit is in milliseconds.
+func ParseTransition(value string) (TransitionSpec, error) {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || len(fields) > 2 {
+		return TransitionSpec{}, fmt.Errorf("invalid transition %q: must be a name like fade or slide-left, optionally followed by a duration like 400ms", value)
+	}
+
+	name, direction, _ := strings.Cut(fields[0], "-")
+	duration := ""
+	if len(fields) == 2 {
+		duration = fields[1]
+	}
+	return ParseTransitionParts(name, direction, duration)
+}
+
+// ParseTransitionParts builds a transition from its name, direction, and
+// duration, as written in the mapping form of the transition directive:
+// transition: {name: slide, direction: up, duration: 300ms}. The direction
+// and duration may be empty.
+func ParseTransitionParts(name, direction, duration string) (TransitionSpec, error) {
+	if !containsString(validTransitions, name) {
+		return TransitionSpec{}, invalidOptionError("transition", name, validTransitions)
+	}
+	spec := TransitionSpec{Name: name, Direction: direction}
+
+	if direction != "" {
+		if !containsString(directionalTransitions, name) {
+			return TransitionSpec{}, fmt.Errorf("invalid transition %q: only %s take a direction", name+"-"+direction, joinOptions(directionalTransitions))
+		}
+		if !containsString(transitionDirections, direction) {
+			return TransitionSpec{}, invalidOptionError("transition direction", direction, transitionDirections)
+		}
+	}
+
+	if duration != "" {
+		ms, err := parseTransitionDuration(duration)
+		if err != nil {
+			return TransitionSpec{}, err
+		}
+		spec.Duration = ms
+	}
+	return spec, nil
+}
+
+// parseTransitionDuration parses a transition duration like 400ms, 0.5s, or
+// 400 into milliseconds.
+func parseTransitionDuration(value string) (int, error) {
+	if ms, err := strconv.Atoi(value); err == nil && ms > 0 {
+		return ms, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < time.Millisecond {
+		return 0, fmt.Errorf("invalid transition duration %q: must be a length like 400ms or 0.5s", value)
+	}
+	return int(d / time.Millisecond), nil
+}
+
+// DefaultTransition returns the presentation's transition, which slides
+// without a transition directive use. Its duration defaults to
+// transitionDuration. An invalid transition, which Validate rejects, falls
+// back to fade.
+func (c *Config) DefaultTransition() TransitionSpec {
+	spec, err := ParseTransition(c.Transition)
+	if err != nil {
+		spec = TransitionSpec{Name: "fade"}
+	}
+	if spec.Duration == 0 && c.TransitionDuration > 0 {
+		spec.Duration = c.TransitionDuration
+	}
+	return spec
+}
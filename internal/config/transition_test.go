@@ -0,0 +1,113 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTransition(t *testing.T) {
+	tests := []struct {
+		value string
+		want  TransitionSpec
+	}{
+		{"fade", TransitionSpec{Name: "fade"}},
+		{"none", TransitionSpec{Name: "none"}},
+		{"slide 400ms", TransitionSpec{Name: "slide", Duration: 400}},
+		{"slide-left 0.5s", TransitionSpec{Name: "slide", Direction: "left", Duration: 500}},
+		{"push-up", TransitionSpec{Name: "push", Direction: "up"}},
+		{"zoom 250", TransitionSpec{Name: "zoom", Duration: 250}},
+		{"  slide-down   1s ", TransitionSpec{Name: "slide", Direction: "down", Duration: 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseTransition(tt.value)
+			if err != nil {
+				t.Fatalf("ParseTransition(%q) returned error: %v", tt.value, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTransition(%q) = %+v, want %+v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTransition_Invalid(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{"", []string{"invalid transition"}},
+		{"fdae", []string{`invalid transition "fdae"`, `did you mean "fade"?`}},
+		{"slide-sideways", []string{`invalid transition direction "sideways"`, "left, right, up, or down"}},
+		{"fade-left", []string{`invalid transition "fade-left"`, "slide or push"}},
+		{"slide fast", []string{`invalid transition duration "fast"`}},
+		{"slide -400ms", []string{`invalid transition duration "-400ms"`}},
+		{"slide 0", []string{`invalid transition duration "0"`}},
+		{"slide 400ms ease", []string{"invalid transition"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			_, err := ParseTransition(tt.value)
+			if err == nil {
+				t.Fatalf("ParseTransition(%q) should return an error", tt.value)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q should contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestParseTransitionParts(t *testing.T) {
+	got, err := ParseTransitionParts("push", "right", "300ms")
+	if err != nil {
+		t.Fatalf("ParseTransitionParts returned error: %v", err)
+	}
+	want := TransitionSpec{Name: "push", Direction: "right", Duration: 300}
+	if got != want {
+		t.Errorf("ParseTransitionParts = %+v, want %+v", got, want)
+	}
+
+	if _, err := ParseTransitionParts("slid", "", ""); err == nil {
+		t.Error("ParseTransitionParts should reject an unknown name")
+	}
+}
+
+func TestDefaultTransition(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+		want TransitionSpec
+	}{
+		{"default", *DefaultConfig(), TransitionSpec{Name: "fade"}},
+		{"transitionDuration", Config{Transition: "slide-up", TransitionDuration: 600}, TransitionSpec{Name: "slide", Direction: "up", Duration: 600}},
+		{"duration in value wins", Config{Transition: "push 200ms", TransitionDuration: 600}, TransitionSpec{Name: "push", Duration: 200}},
+		{"invalid falls back to fade", Config{Transition: "wipe"}, TransitionSpec{Name: "fade"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.DefaultTransition(); got != tt.want {
+				t.Errorf("DefaultTransition() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFrontmatter_TransitionSpec(t *testing.T) {
+	cfg, _, err := ParseFrontmatter([]byte("transition: slide-left 300ms"))
+	if err != nil {
+		t.Fatalf("ParseFrontmatter returned error: %v", err)
+	}
+	if cfg.Transition != "slide-left 300ms" {
+		t.Errorf("Transition = %q, want %q", cfg.Transition, "slide-left 300ms")
+	}
+
+	if _, _, err := ParseFrontmatter([]byte("transition: fade-up")); err == nil {
+		t.Error("ParseFrontmatter should reject a direction on fade")
+	}
+}
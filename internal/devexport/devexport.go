@@ -0,0 +1,309 @@
+// Package devexport exports the presentation served by the dev server to a PDF.
+package devexport
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/MiniCodeMonkey/tap/internal/pdf"
+	"github.com/MiniCodeMonkey/tap/internal/server"
+)
+
+// Request is the body of POST /api/export.
+type Request struct {
+	Content string `json:"content,omitempty"` // slides, notes, both, or handout; default slides
+	Mode    string `json:"mode,omitempty"`    // raster or vector; default raster
+	Output  string `json:"output,omitempty"`  // Relative to the presentation's directory; default <file>.pdf
+	Slides  string `json:"slides,omitempty"`  // Slides to export, e.g. 1-5,8; default all
+}
+
+// Response is the response of POST /api/export.
+type Response struct {
+	OutputPath string `json:"outputPath,omitempty"`
+	Error      string `json:"error,omitempty"`
+	PageCount  int    `json:"pageCount,omitempty"`
+	Duration   int64  `json:"durationMs,omitempty"`
+	FileSize   int64  `json:"fileSize,omitempty"`
+	Success    bool   `json:"success"`
+}
+
+// ErrExportRunning is returned by Service.ExportPDF while another export is running.
+var ErrExportRunning = errors.New("a PDF export is already running")
+
+// ErrServiceClosed is returned by Service.ExportPDF after Close, while the
+// dev server shuts down.
+var ErrServiceClosed = errors.New("the dev server is shutting down")
+
+// exportRequestError is an invalid export request, as opposed to a failed export.
+type exportRequestError struct {
+	err error
+}
+
+func (e *exportRequestError) Error() string { return e.err.Error() }
+func (e *exportRequestError) Unwrap() error { return e.err }
+
+// Service exports the presentation of a dev server to PDFs. Its exporter
+// keeps the browser running between exports until Close is called.
+type Service struct {
+	server   *server.Server
+	exporter *pdf.Exporter
+	events   func(eventType, message string) // Receives export progress; may be nil
+	mu       sync.Mutex
+	running  bool
+	closed   bool
+}
+
+// New creates a Service that exports the presentation served by srv.
+func New(srv *server.Server) *Service {
+	return &Service{server: srv}
+}
+
+// SetEventHandler sets the function that receives export progress, by event
+// type ("action", "error") and message.
+func (s *Service) SetEventHandler(handler func(eventType, message string)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = handler
+}
+
+// sendEvent passes an event to the event handler, if one is set.
+func (s *Service) sendEvent(eventType, message string) {
+	s.mu.Lock()
+	handler := s.events
+	s.mu.Unlock()
+	if handler != nil {
+		handler(eventType, message)
+	}
+}
+
+// ExportPDF exports the presentation to a PDF by rendering the server's
+// pages in a headless browser, which is launched on the first export and
+// reused by later ones. Only one export runs at a time; others fail with
+// ErrExportRunning. The output must be a .pdf file inside the presentation's
+// directory.
+func (s *Service) ExportPDF(ctx context.Context, req Request) (*pdf.ExportResult, error) {
+	opts, err := s.exportOptions(req)
+	if err != nil {
+		return nil, &exportRequestError{err: err}
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrServiceClosed
+	}
+	if s.running {
+		s.mu.Unlock()
+		return nil, ErrExportRunning
+	}
+	if s.exporter == nil {
+		if s.exporter, err = pdf.New(); err != nil {
+			s.mu.Unlock()
+			return nil, fmt.Errorf("failed to create PDF exporter: %w", err)
+		}
+	}
+	s.running = true
+	exporter := s.exporter
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	s.sendEvent("action", "Exporting PDF → "+s.displayPath(opts.Output))
+	lastPercent := -1
+	opts.Progress = func(current, total int, stage string) {
+		if stage == pdf.StageAssemble {
+			s.sendEvent("action", "Assembling PDF")
+			return
+		}
+		// Report every quarter, so large decks don't flood the event log
+		if total > 0 {
+			if percent := current * 100 / total / 25 * 25; percent > lastPercent {
+				lastPercent = percent
+				s.sendEvent("action", fmt.Sprintf("Capturing slides %d/%d", current, total))
+			}
+		}
+	}
+
+	result, err := exporter.Export(ctx, fmt.Sprintf("http://127.0.0.1:%d", s.server.Port()), opts)
+	if err != nil {
+		s.sendEvent("error", "PDF export failed: "+err.Error())
+		return nil, err
+	}
+	s.sendEvent("action", "PDF exported → "+s.displayPath(result.OutputPath))
+	return result, nil
+}
+
+// exportOptions validates an export request and returns the options for the
+// exporter, with credentials for the server's passwords.
+func (s *Service) exportOptions(req Request) (pdf.ExportOptions, error) {
+	opts := pdf.ExportOptions{Slides: req.Slides}
+
+	content, err := pdf.ValidateContentType(valueOr(req.Content, string(pdf.ContentSlides)))
+	if err != nil {
+		return opts, err
+	}
+	mode, err := pdf.ValidateMode(valueOr(req.Mode, string(pdf.ModeRaster)))
+	if err != nil {
+		return opts, err
+	}
+	if mode == pdf.ModeVector && content != pdf.ContentSlides {
+		return opts, fmt.Errorf("mode vector only applies to content slides")
+	}
+	opts.Content, opts.Mode = content, mode
+
+	baseDir, markdownFile := s.server.GetBaseDir(), s.server.Status().MarkdownFile
+	pres := s.server.GetPresentation()
+	presenterPassword, audiencePassword := s.server.GetPresenterPassword(), s.server.GetAudiencePassword()
+
+	if pres == nil {
+		return opts, fmt.Errorf("no presentation loaded")
+	}
+	if _, err := pdf.ParseSlideRange(req.Slides, len(pres.Slides)); err != nil {
+		return opts, err
+	}
+	opts.Title, opts.Author = pres.Config.Title, pres.Config.Author
+
+	opts.Output, err = resolveExportOutput(baseDir, markdownFile, req.Output)
+	if err != nil {
+		return opts, err
+	}
+
+	opts.Headers = make(map[string]string)
+	if audiencePassword != "" {
+		opts.Headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+audiencePassword))
+	}
+	if presenterPassword != "" {
+		opts.Headers[server.PresenterTokenHeader] = presenterPassword
+	}
+	return opts, nil
+}
+
+// resolveExportOutput returns the absolute path of an export's output file.
+// Relative paths are resolved against baseDir, and the default is the
+// markdown file with a .pdf extension. Paths outside baseDir, and files
+// other than PDFs, are rejected so exports can't overwrite arbitrary files.
+func resolveExportOutput(baseDir, markdownFile, output string) (string, error) {
+	if baseDir == "" {
+		return "", fmt.Errorf("no presentation directory set")
+	}
+	if output == "" {
+		if markdownFile == "" {
+			return "", fmt.Errorf("no output path given")
+		}
+		output = strings.TrimSuffix(filepath.Base(markdownFile), filepath.Ext(markdownFile)) + ".pdf"
+	}
+
+	path := filepath.FromSlash(output)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	path = filepath.Clean(path)
+
+	rel, err := filepath.Rel(filepath.Clean(baseDir), path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("output %s is outside the presentation directory", output)
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".pdf") {
+		return "", fmt.Errorf("output %s must be a .pdf file", output)
+	}
+	return path, nil
+}
+
+// displayPath returns path relative to the presentation's directory, for events.
+func (s *Service) displayPath(path string) string {
+	if rel, err := filepath.Rel(s.server.GetBaseDir(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return path
+}
+
+// Close stops the exporter's browser, if it was launched. Exports fail with
+// ErrServiceClosed afterwards.
+func (s *Service) Close() error {
+	s.mu.Lock()
+	exporter := s.exporter
+	s.exporter = nil
+	s.closed = true
+	s.mu.Unlock()
+
+	if exporter == nil {
+		return nil
+	}
+	return exporter.Close()
+}
+
+// valueOr returns value, or fallback if value is empty.
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// HandleExport handles POST /api/export requests to export the
+// presentation to a PDF. The request must have a JSON body and come from the
+// same origin (see server.IsSameOriginJSON), so other web pages can't make
+// the dev server write files. Writing files is a presenter action, so when a
+// presenter password is set the request must also carry the presenter token.
+// The response is sent when the export completes.
+func (s *Service) HandleExport(w http.ResponseWriter, r *http.Request) {
+	if !server.IsSameOriginJSON(r) {
+		writeResponse(w, http.StatusForbidden, Response{Error: "exports need a same-origin JSON request"})
+		return
+	}
+	if !s.server.PresenterAuthorized(r) {
+		writeResponse(w, http.StatusForbidden, Response{Error: "presenter token required"})
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(w, http.StatusBadRequest, Response{
+			Error: fmt.Sprintf("Invalid request body: %v", err),
+		})
+		return
+	}
+
+	result, err := s.ExportPDF(r.Context(), req)
+	var requestErr *exportRequestError
+	switch {
+	case errors.Is(err, ErrExportRunning):
+		writeResponse(w, http.StatusConflict, Response{Error: err.Error()})
+		return
+	case errors.Is(err, ErrServiceClosed):
+		writeResponse(w, http.StatusServiceUnavailable, Response{Error: err.Error()})
+		return
+	case errors.As(err, &requestErr):
+		writeResponse(w, http.StatusBadRequest, Response{Error: err.Error()})
+		return
+	case err != nil:
+		writeResponse(w, http.StatusInternalServerError, Response{Error: err.Error()})
+		return
+	}
+
+	writeResponse(w, http.StatusOK, Response{
+		Success:    true,
+		OutputPath: result.OutputPath,
+		PageCount:  result.PageCount,
+		Duration:   result.Duration.Milliseconds(),
+		FileSize:   result.FileSize,
+	})
+}
+
+// writeResponse writes an export response as JSON with the given status.
+func writeResponse(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
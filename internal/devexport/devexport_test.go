@@ -0,0 +1,226 @@
+package devexport
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/pdf"
+	"github.com/MiniCodeMonkey/tap/internal/server"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// newTestService returns a service for a server with a presentation of
+// slides slides in a temporary directory.
+func newTestService(t *testing.T, slides int) (*Service, *server.Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	s := server.New(0)
+	s.SetBaseDir(dir)
+	s.SetMarkdownFile(filepath.Join(dir, "talk.md"))
+	pres := &transformer.TransformedPresentation{Config: *config.DefaultConfig()}
+	pres.Config.Title = "Talk"
+	for i := 0; i < slides; i++ {
+		pres.Slides = append(pres.Slides, transformer.TransformedSlide{Index: i})
+	}
+	s.SetPresentation(pres)
+	return New(s), s, dir
+}
+
+// postExport sends a POST /api/export request with body and returns the
+// response.
+func postExport(t *testing.T, s *Service, body, token string) (int, Response) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/api/export", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set(server.PresenterTokenHeader, token)
+	}
+	w := httptest.NewRecorder()
+	s.HandleExport(w, req)
+
+	var resp Response
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return w.Code, resp
+}
+
+func TestResolveExportOutput(t *testing.T) {
+	dir := t.TempDir()
+	markdown := filepath.Join(dir, "talk.md")
+
+	tests := []struct {
+		output string
+		want   string
+	}{
+		{"", filepath.Join(dir, "talk.pdf")},
+		{"deck.pdf", filepath.Join(dir, "deck.pdf")},
+		{"out/deck.pdf", filepath.Join(dir, "out", "deck.pdf")},
+		{"out/../deck.PDF", filepath.Join(dir, "deck.PDF")},
+		{filepath.Join(dir, "abs.pdf"), filepath.Join(dir, "abs.pdf")},
+	}
+	for _, tt := range tests {
+		got, err := resolveExportOutput(dir, markdown, tt.output)
+		if err != nil {
+			t.Errorf("resolveExportOutput(%q) error = %v", tt.output, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("resolveExportOutput(%q) = %q, want %q", tt.output, got, tt.want)
+		}
+	}
+
+	for _, output := range []string{"../deck.pdf", "out/../../deck.pdf", "/tmp/deck.pdf", "talk.md", "deck", "."} {
+		if _, err := resolveExportOutput(dir, markdown, output); err == nil {
+			t.Errorf("resolveExportOutput(%q) should be rejected", output)
+		}
+	}
+}
+
+func TestExportOptions(t *testing.T) {
+	svc, s, dir := newTestService(t, 3)
+	s.SetPresenterPassword("token")
+	s.SetAudiencePassword("secret")
+
+	opts, err := svc.exportOptions(Request{Content: "both", Output: "out/deck.pdf", Slides: "1-2"})
+	if err != nil {
+		t.Fatalf("exportOptions() error = %v", err)
+	}
+	if opts.Content != pdf.ContentBoth || opts.Mode != pdf.ModeRaster {
+		t.Errorf("content, mode = %q, %q, want both, raster", opts.Content, opts.Mode)
+	}
+	if opts.Output != filepath.Join(dir, "out", "deck.pdf") {
+		t.Errorf("output = %q", opts.Output)
+	}
+	if opts.Title != "Talk" || opts.Slides != "1-2" {
+		t.Errorf("title, slides = %q, %q", opts.Title, opts.Slides)
+	}
+
+	// The exporter's requests are authorized like the presenter's
+	req := httptest.NewRequest(http.MethodGet, "/presenter", nil)
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
+	if !s.PresenterAuthorized(req) {
+		t.Errorf("headers %v should authorize the exporter", opts.Headers)
+	}
+	if want := "Basic " + base64.StdEncoding.EncodeToString([]byte(":secret")); opts.Headers["Authorization"] != want {
+		t.Errorf("Authorization = %q, want %q", opts.Headers["Authorization"], want)
+	}
+}
+
+func TestHandleExport_InvalidRequests(t *testing.T) {
+	svc, _, _ := newTestService(t, 3)
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{`, "Invalid request body"},
+		{``, "Invalid request body"},
+		{`{"content":"slide"}`, "content"},
+		{`{"mode":"vector","content":"notes"}`, "vector only applies"},
+		{`{"output":"../deck.pdf"}`, "outside the presentation directory"},
+		{`{"output":"talk.md"}`, "must be a .pdf file"},
+		{`{"slides":"7"}`, "7"},
+	}
+	for _, tt := range tests {
+		status, resp := postExport(t, svc, tt.body, "")
+		if status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want %d", tt.body, status, http.StatusBadRequest)
+		}
+		if resp.Success || !strings.Contains(resp.Error, tt.want) {
+			t.Errorf("%s: error = %q, want it to contain %q", tt.body, resp.Error, tt.want)
+		}
+	}
+}
+
+func TestHandleExport_RequiresPresenterToken(t *testing.T) {
+	svc, s, _ := newTestService(t, 1)
+	s.SetPresenterPassword("token")
+
+	for _, token := range []string{"", "wrong"} {
+		if status, _ := postExport(t, svc, `{}`, token); status != http.StatusForbidden {
+			t.Errorf("token %q: status = %d, want %d", token, status, http.StatusForbidden)
+		}
+	}
+
+	// With the token, the request gets past authorization to validation
+	if status, resp := postExport(t, svc, `{"output":"../x.pdf"}`, "token"); status != http.StatusBadRequest {
+		t.Errorf("status = %d (%s), want %d", status, resp.Error, http.StatusBadRequest)
+	}
+}
+
+func TestHandleExport_RequiresSameOriginJSON(t *testing.T) {
+	svc, _, _ := newTestService(t, 1)
+
+	tests := []struct {
+		name        string
+		contentType string
+		origin      string
+	}{
+		{"form post", "application/x-www-form-urlencoded", ""},
+		{"text body", "text/plain", ""},
+		{"no content type", "", ""},
+		{"foreign origin", "application/json", "https://evil.example"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/export", strings.NewReader(`{}`))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.origin != "" {
+				req.Header.Set("Origin", tt.origin)
+			}
+			w := httptest.NewRecorder()
+			svc.HandleExport(w, req)
+			if w.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestHandleExport_RejectsConcurrentExports(t *testing.T) {
+	svc, _, _ := newTestService(t, 1)
+	svc.running = true
+
+	status, resp := postExport(t, svc, `{}`, "")
+	if status != http.StatusConflict {
+		t.Errorf("status = %d, want %d", status, http.StatusConflict)
+	}
+	if resp.Error != ErrExportRunning.Error() {
+		t.Errorf("error = %q, want %q", resp.Error, ErrExportRunning)
+	}
+
+	if _, err := svc.ExportPDF(context.Background(), Request{}); !errors.Is(err, ErrExportRunning) {
+		t.Errorf("ExportPDF() error = %v, want ErrExportRunning", err)
+	}
+}
+
+func TestHandleExport_RejectsExportsAfterClose(t *testing.T) {
+	svc, _, _ := newTestService(t, 1)
+	if err := svc.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	status, resp := postExport(t, svc, `{}`, "")
+	if status != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", status, http.StatusServiceUnavailable)
+	}
+	if resp.Error != ErrServiceClosed.Error() {
+		t.Errorf("error = %q, want %q", resp.Error, ErrServiceClosed)
+	}
+	if svc.exporter != nil {
+		t.Error("exporter was created after Close")
+	}
+}
@@ -20,6 +20,17 @@ func NewRegistry() *Registry {
 	}
 }
 
+// NewDefaultRegistry creates a registry with the built-in drivers (shell,
+// sqlite, mysql, and postgres), running commands in workingDir.
+func NewDefaultRegistry(workingDir string) *Registry {
+	r := NewRegistry()
+	r.Register(NewShellDriver(workingDir))
+	r.Register(NewSQLiteDriver(workingDir))
+	r.Register(NewMySQLDriver(workingDir))
+	r.Register(NewPostgresDriver(workingDir))
+	return r
+}
+
 // Register adds a driver to the registry.
 // If a driver with the same name already exists, it will be replaced.
 func (r *Registry) Register(driver Driver) {
@@ -30,6 +30,27 @@ func TestNewRegistry(t *testing.T) {
 	}
 }
 
+func TestNewDefaultRegistry(t *testing.T) {
+	r := NewDefaultRegistry("/tmp")
+
+	names := r.List()
+	sort.Strings(names)
+	expected := []string{"mysql", "postgres", "shell", "sqlite"}
+	if len(names) != len(expected) {
+		t.Fatalf("expected drivers %v, got %v", expected, names)
+	}
+	for i, name := range expected {
+		if names[i] != name {
+			t.Errorf("expected drivers %v, got %v", expected, names)
+			break
+		}
+	}
+
+	if shell, ok := r.Get("shell").(*ShellDriver); !ok || shell.WorkingDir != "/tmp" {
+		t.Errorf("expected shell driver with working directory /tmp, got %#v", r.Get("shell"))
+	}
+}
+
 func TestRegistry_Register(t *testing.T) {
 	r := NewRegistry()
 	driver := &mockDriver{name: "test"}
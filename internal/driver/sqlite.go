@@ -34,6 +34,7 @@ func (d *SQLiteDriver) Name() string {
 // Execute runs the provided SQL query against a SQLite database.
 // The config map supports the following keys:
 //   - database: path to the SQLite database file, or ":memory:" for in-memory (default: ":memory:")
+//   - path: alias for database, as used by connections in the frontmatter
 //   - timeout: execution timeout in seconds (default: 30)
 //   - workdir: override the working directory for resolving relative database paths
 func (d *SQLiteDriver) Execute(ctx context.Context, code string, config map[string]string) Result {
@@ -53,6 +54,8 @@ func (d *SQLiteDriver) Execute(ctx context.Context, code string, config map[stri
 	database := ":memory:"
 	if dbPath, ok := config["database"]; ok && dbPath != "" {
 		database = dbPath
+	} else if dbPath, ok := config["path"]; ok && dbPath != "" {
+		database = dbPath
 	}
 
 	// Build sqlite3 command with table-formatted output
@@ -147,6 +147,25 @@ func TestSQLiteDriver_Execute_FileDatabase(t *testing.T) {
 	}
 }
 
+func TestSQLiteDriver_Execute_PathAlias(t *testing.T) {
+	if !hasSQLite3() {
+		t.Skip("sqlite3 not installed")
+	}
+
+	dir := t.TempDir()
+	driver := NewSQLiteDriver(dir)
+	ctx := context.Background()
+
+	result := driver.Execute(ctx, "CREATE TABLE test (value INTEGER);", map[string]string{"path": "demo.db"})
+	if !result.Success {
+		t.Fatalf("failed to create table: %s", result.Error)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "demo.db")); err != nil {
+		t.Errorf("expected database file at path, got %v", err)
+	}
+}
+
 func TestSQLiteDriver_Execute_SyntaxError(t *testing.T) {
 	if !hasSQLite3() {
 		t.Skip("sqlite3 not installed")
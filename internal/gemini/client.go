@@ -6,12 +6,15 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strings"
 	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/imagegen/retry"
 )
 
 const (
@@ -26,6 +29,12 @@ const (
 
 	// EnvAPIKey is the environment variable name for the Gemini API key.
 	EnvAPIKey = "GEMINI_API_KEY"
+
+	// DefaultMaxRetries is the default number of retries after a rate limit or server error.
+	DefaultMaxRetries = 3
+
+	// DefaultRetryBaseDelay is the default delay before the first retry. It doubles with each retry.
+	DefaultRetryBaseDelay = 2 * time.Second
 )
 
 // ErrorType represents different types of API errors.
@@ -46,6 +55,8 @@ const (
 	ErrorTypeNetwork ErrorType = "network"
 	// ErrorTypeNoImage indicates the response contained no image.
 	ErrorTypeNoImage ErrorType = "no_image"
+	// ErrorTypeInvalidImage indicates the returned image data was empty, truncated, or too large.
+	ErrorTypeInvalidImage ErrorType = "invalid_image"
 )
 
 // APIError represents a structured error from the Gemini API.
@@ -53,13 +64,110 @@ type APIError struct {
 	Type    ErrorType `json:"type"`
 	Message string    `json:"message"`
 	Code    int       `json:"code,omitempty"`
+	// Attempts is the number of requests made before giving up, including retries.
+	Attempts int `json:"attempts,omitempty"`
+	// RetryAfter is the delay requested by the server's Retry-After header, if any.
+	RetryAfter time.Duration `json:"-"`
+	// Details says why a request was blocked, for content policy errors
+	// whose response explains it.
+	Details *ErrorDetails `json:"details,omitempty"`
+}
+
+// SafetyRating is the API's rating of how likely a prompt or image is to be
+// harmful in a category.
+type SafetyRating struct {
+	// Category is the harm category, such as "HARM_CATEGORY_DANGEROUS_CONTENT".
+	Category string `json:"category"`
+	// Probability is how likely the content is harmful, such as "HIGH".
+	Probability string `json:"probability,omitempty"`
+	// Blocked is true if the content was blocked because of this rating.
+	Blocked bool `json:"blocked,omitempty"`
+}
+
+// ErrorDetails says why the API blocked a request.
+type ErrorDetails struct {
+	// BlockReason is why the prompt was blocked, such as "SAFETY" or "OTHER".
+	BlockReason string `json:"blockReason,omitempty"`
+	// FinishReason is why generation stopped without an image, such as "IMAGE_SAFETY".
+	FinishReason string `json:"finishReason,omitempty"`
+	// SafetyRatings are the ratings of the prompt or the generated image.
+	SafetyRatings []SafetyRating `json:"safetyRatings,omitempty"`
+}
+
+// Categories returns the readable names of the harm categories that caused
+// the block, such as "dangerous content". Ratings marked as blocked are
+// used if there are any; otherwise those rated a medium or high probability.
+func (d *ErrorDetails) Categories() []string {
+	if d == nil {
+		return nil
+	}
+
+	var blocked, likely []string
+	for _, rating := range d.SafetyRatings {
+		name := categoryName(rating.Category)
+		switch {
+		case rating.Blocked:
+			blocked = append(blocked, name)
+		case rating.Probability == "MEDIUM" || rating.Probability == "HIGH":
+			likely = append(likely, name)
+		}
+	}
+	if len(blocked) > 0 {
+		return blocked
+	}
+	return likely
+}
+
+// categoryName returns a harm category such as "HARM_CATEGORY_SEXUALLY_EXPLICIT"
+// as "sexually explicit".
+func categoryName(category string) string {
+	name := strings.TrimPrefix(category, "HARM_CATEGORY_")
+	return strings.ToLower(strings.ReplaceAll(name, "_", " "))
+}
+
+// blockedFinishReasons are the finish reasons of candidates that were stopped
+// by a content policy rather than failing to make an image.
+var blockedFinishReasons = map[string]bool{
+	"SAFETY":                   true,
+	"IMAGE_SAFETY":             true,
+	"PROHIBITED_CONTENT":       true,
+	"IMAGE_PROHIBITED_CONTENT": true,
+	"BLOCKLIST":                true,
+	"SPII":                     true,
 }
 
 func (e *APIError) Error() string {
+	msg := fmt.Sprintf("%s: %s", e.Type, e.Message)
 	if e.Code != 0 {
-		return fmt.Sprintf("%s: %s (code: %d)", e.Type, e.Message, e.Code)
+		msg = fmt.Sprintf("%s (code: %d)", msg, e.Code)
+	}
+	switch retries := e.Retries(); {
+	case retries == 1:
+		msg += " (failed after 1 retry)"
+	case retries > 1:
+		msg = fmt.Sprintf("%s (failed after %d retries)", msg, retries)
+	}
+	return msg
+}
+
+// Retries returns the number of retries made before the error was returned.
+func (e *APIError) Retries() int {
+	if e.Attempts <= 1 {
+		return 0
 	}
-	return fmt.Sprintf("%s: %s", e.Type, e.Message)
+	return e.Attempts - 1
+}
+
+// Retryable reports whether the request may succeed when retried.
+// Rate limit and server errors are retryable.
+func (e *APIError) Retryable() bool {
+	return e.Type == ErrorTypeRateLimit || e.Type == ErrorTypeServer
+}
+
+// RetryDelay returns the delay requested by the server's Retry-After header,
+// or 0 if there was none.
+func (e *APIError) RetryDelay() time.Duration {
+	return e.RetryAfter
 }
 
 // ImageResult represents a successfully generated image.
@@ -75,6 +183,10 @@ type Client struct {
 	model      string
 	httpClient *http.Client
 	timeout    time.Duration
+	maxRetries int
+	baseDelay  time.Duration
+	// wait pauses between retries; it returns early with an error when ctx is done.
+	wait func(ctx context.Context, d time.Duration) error
 }
 
 // Option is a function that configures a Client.
@@ -108,6 +220,16 @@ func WithHTTPClient(client *http.Client) Option {
 	}
 }
 
+// WithRetry sets how often rate limit and server errors are retried, and the
+// delay before the first retry. The delay doubles with each retry. A
+// maxRetries of 0 disables retries.
+func WithRetry(maxRetries int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.baseDelay = baseDelay
+	}
+}
+
 // NewClient creates a new Gemini API client.
 // If apiKey is empty, it reads from the GEMINI_API_KEY environment variable.
 func NewClient(apiKey string, opts ...Option) (*Client, error) {
@@ -129,6 +251,9 @@ func NewClient(apiKey string, opts ...Option) (*Client, error) {
 		httpClient: &http.Client{
 			Timeout: DefaultTimeout,
 		},
+		maxRetries: DefaultMaxRetries,
+		baseDelay:  DefaultRetryBaseDelay,
+		wait:       retry.Wait,
 	}
 
 	for _, opt := range opts {
@@ -173,6 +298,21 @@ type generationConfig struct {
 
 type imageConfig struct {
 	AspectRatio string `json:"aspectRatio,omitempty"`
+	ImageSize   string `json:"imageSize,omitempty"`
+}
+
+// AspectRatios lists the aspect ratios supported for image generation.
+var AspectRatios = []string{"16:9", "4:3", "3:2", "1:1", "2:3", "3:4", "9:16", "21:9"}
+
+// ImageSizes lists the output resolutions supported for image generation.
+var ImageSizes = []string{"1K", "2K", "4K"}
+
+// ImageOptions configures image generation.
+type ImageOptions struct {
+	// AspectRatio is the desired aspect ratio (e.g., "16:9"). Empty uses the model default.
+	AspectRatio string
+	// ImageSize is the desired output resolution ("1K", "2K", or "4K"). Empty uses the model default.
+	ImageSize string
 }
 
 // generateContentResponse is the response body from the generateContent API.
@@ -183,8 +323,10 @@ type generateContentResponse struct {
 }
 
 type candidate struct {
-	Content      *contentResponse `json:"content,omitempty"`
-	FinishReason string           `json:"finishReason,omitempty"`
+	Content       *contentResponse `json:"content,omitempty"`
+	FinishReason  string           `json:"finishReason,omitempty"`
+	FinishMessage string           `json:"finishMessage,omitempty"`
+	SafetyRatings []SafetyRating   `json:"safetyRatings,omitempty"`
 }
 
 type contentResponse struct {
@@ -197,7 +339,8 @@ type partResponse struct {
 }
 
 type promptFeedback struct {
-	BlockReason string `json:"blockReason,omitempty"`
+	BlockReason   string         `json:"blockReason,omitempty"`
+	SafetyRatings []SafetyRating `json:"safetyRatings,omitempty"`
 }
 
 type apiErrorResponse struct {
@@ -208,12 +351,20 @@ type apiErrorResponse struct {
 
 // GenerateImage generates an image from a text prompt.
 func (c *Client) GenerateImage(ctx context.Context, prompt string) (*ImageResult, error) {
-	return c.GenerateImageWithAspectRatio(ctx, prompt, "")
+	return c.GenerateImageWithOptions(ctx, prompt, ImageOptions{})
 }
 
 // GenerateImageWithAspectRatio generates an image with a specific aspect ratio.
 // Valid aspect ratios: "1:1", "16:9", "9:16", "4:3", "3:4"
 func (c *Client) GenerateImageWithAspectRatio(ctx context.Context, prompt string, aspectRatio string) (*ImageResult, error) {
+	return c.GenerateImageWithOptions(ctx, prompt, ImageOptions{AspectRatio: aspectRatio})
+}
+
+// GenerateImageWithOptions generates an image with the given aspect ratio and size.
+// Empty options use the model defaults. Rate limit and server errors are retried
+// with exponential backoff, honoring the server's Retry-After header; cancel ctx
+// to stop retrying.
+func (c *Client) GenerateImageWithOptions(ctx context.Context, prompt string, opts ImageOptions) (*ImageResult, error) {
 	if prompt == "" {
 		return nil, &APIError{
 			Type:    ErrorTypeInvalidRequest,
@@ -221,12 +372,57 @@ func (c *Client) GenerateImageWithAspectRatio(ctx context.Context, prompt string
 		}
 	}
 
+	return c.generate(ctx, []part{{Text: prompt}}, opts)
+}
+
+// EditImage generates an image from a text prompt and a base image, such as
+// "make it darker" for a previously generated image. baseMime is the MIME type
+// of the base image; if empty, it is detected from the data.
+func (c *Client) EditImage(ctx context.Context, prompt string, baseImage []byte, baseMime string) (*ImageResult, error) {
+	return c.EditImageWithOptions(ctx, prompt, baseImage, baseMime, ImageOptions{})
+}
+
+// EditImageWithOptions is like EditImage, with the given aspect ratio and size.
+// Errors are retried like in GenerateImageWithOptions.
+func (c *Client) EditImageWithOptions(ctx context.Context, prompt string, baseImage []byte, baseMime string, opts ImageOptions) (*ImageResult, error) {
+	if prompt == "" {
+		return nil, &APIError{
+			Type:    ErrorTypeInvalidRequest,
+			Message: "prompt cannot be empty",
+		}
+	}
+	if len(baseImage) == 0 {
+		return nil, &APIError{
+			Type:    ErrorTypeInvalidRequest,
+			Message: "base image cannot be empty",
+		}
+	}
+	if baseMime == "" {
+		baseMime = http.DetectContentType(baseImage)
+	}
+	if !strings.HasPrefix(baseMime, "image/") {
+		return nil, &APIError{
+			Type:    ErrorTypeInvalidRequest,
+			Message: fmt.Sprintf("base image has unsupported type %s", baseMime),
+		}
+	}
+
+	return c.generate(ctx, []part{
+		{Text: prompt},
+		{InlineData: &inlineData{
+			MimeType: baseMime,
+			Data:     base64.StdEncoding.EncodeToString(baseImage),
+		}},
+	}, opts)
+}
+
+// generate requests an image for the given request parts, retrying rate
+// limit and server errors.
+func (c *Client) generate(ctx context.Context, parts []part, opts ImageOptions) (*ImageResult, error) {
 	reqBody := generateContentRequest{
 		Contents: []content{
 			{
-				Parts: []part{
-					{Text: prompt},
-				},
+				Parts: parts,
 			},
 		},
 		GenerationConfig: &generationConfig{
@@ -234,9 +430,10 @@ func (c *Client) GenerateImageWithAspectRatio(ctx context.Context, prompt string
 		},
 	}
 
-	if aspectRatio != "" {
+	if opts.AspectRatio != "" || opts.ImageSize != "" {
 		reqBody.GenerationConfig.ImageConfig = &imageConfig{
-			AspectRatio: aspectRatio,
+			AspectRatio: opts.AspectRatio,
+			ImageSize:   opts.ImageSize,
 		}
 	}
 
@@ -249,6 +446,38 @@ func (c *Client) GenerateImageWithAspectRatio(ctx context.Context, prompt string
 	}
 
 	url := fmt.Sprintf("%s/models/%s:generateContent", c.baseURL, c.model)
+
+	policy := retry.Policy{MaxRetries: c.maxRetries, BaseDelay: c.baseDelay, Wait: c.wait}
+	result, attempts, err := retry.Do(ctx, policy, func() (*ImageResult, error) {
+		result, apiErr := c.generateContent(ctx, url, jsonBody)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		return result, nil
+	})
+	if err != nil {
+		return nil, WithAttempts(err, attempts)
+	}
+	return result, nil
+}
+
+// WithAttempts returns err from retry.Do as an *APIError that records the
+// number of attempts. An error that isn't an *APIError means ctx was done
+// while waiting for the next retry.
+func WithAttempts(err error, attempts int) *APIError {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		apiErr = &APIError{
+			Type:    ErrorTypeNetwork,
+			Message: "request was canceled",
+		}
+	}
+	apiErr.Attempts = attempts
+	return apiErr
+}
+
+// generateContent sends a single generateContent request and extracts the image.
+func (c *Client) generateContent(ctx context.Context, url string, jsonBody []byte) (*ImageResult, *APIError) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(jsonBody))
 	if err != nil {
 		return nil, &APIError{
@@ -291,7 +520,9 @@ func (c *Client) GenerateImageWithAspectRatio(ctx context.Context, prompt string
 
 	// Handle HTTP error status codes
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.parseHTTPError(resp.StatusCode, body)
+		apiErr := c.parseHTTPError(resp.StatusCode, body)
+		apiErr.RetryAfter = retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return nil, apiErr
 	}
 
 	var genResp generateContentResponse
@@ -312,6 +543,10 @@ func (c *Client) GenerateImageWithAspectRatio(ctx context.Context, prompt string
 		return nil, &APIError{
 			Type:    ErrorTypeContentPolicy,
 			Message: fmt.Sprintf("prompt was blocked: %s", genResp.PromptFeedback.BlockReason),
+			Details: &ErrorDetails{
+				BlockReason:   genResp.PromptFeedback.BlockReason,
+				SafetyRatings: genResp.PromptFeedback.SafetyRatings,
+			},
 		}
 	}
 
@@ -401,7 +636,7 @@ func (c *Client) classifyAPIError(errResp *apiErrorResponse) *APIError {
 }
 
 // extractImage extracts the generated image from the API response.
-func (c *Client) extractImage(resp *generateContentResponse) (*ImageResult, error) {
+func (c *Client) extractImage(resp *generateContentResponse) (*ImageResult, *APIError) {
 	if len(resp.Candidates) == 0 {
 		return nil, &APIError{
 			Type:    ErrorTypeNoImage,
@@ -431,6 +666,25 @@ func (c *Client) extractImage(resp *generateContentResponse) (*ImageResult, erro
 		}
 	}
 
+	// A candidate stopped by a content policy explains why there is no image
+	for _, candidate := range resp.Candidates {
+		if !blockedFinishReasons[candidate.FinishReason] {
+			continue
+		}
+		message := fmt.Sprintf("image was blocked: %s", candidate.FinishReason)
+		if candidate.FinishMessage != "" {
+			message += ": " + candidate.FinishMessage
+		}
+		return nil, &APIError{
+			Type:    ErrorTypeContentPolicy,
+			Message: message,
+			Details: &ErrorDetails{
+				FinishReason:  candidate.FinishReason,
+				SafetyRatings: candidate.SafetyRatings,
+			},
+		}
+	}
+
 	return nil, &APIError{
 		Type:    ErrorTypeNoImage,
 		Message: "response did not contain an image",
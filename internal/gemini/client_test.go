@@ -7,7 +7,9 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -214,6 +216,67 @@ func TestGenerateImage_WithAspectRatio(t *testing.T) {
 	}
 }
 
+func TestGenerateImageWithOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody generateContentRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		if reqBody.GenerationConfig == nil || reqBody.GenerationConfig.ImageConfig == nil {
+			t.Fatal("expected imageConfig in request")
+		}
+		if reqBody.GenerationConfig.ImageConfig.AspectRatio != "4:3" {
+			t.Errorf("expected aspectRatio '4:3', got '%s'", reqBody.GenerationConfig.ImageConfig.AspectRatio)
+		}
+		if reqBody.GenerationConfig.ImageConfig.ImageSize != "2K" {
+			t.Errorf("expected imageSize '2K', got '%s'", reqBody.GenerationConfig.ImageConfig.ImageSize)
+		}
+
+		imageData := base64.StdEncoding.EncodeToString([]byte("image"))
+		resp := generateContentResponse{
+			Candidates: []candidate{
+				{Content: &contentResponse{Parts: []partResponse{{InlineData: &inlineData{MimeType: "image/png", Data: imageData}}}}},
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-key", WithBaseURL(server.URL))
+	_, err := client.GenerateImageWithOptions(context.Background(), "test", ImageOptions{AspectRatio: "4:3", ImageSize: "2K"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestGenerateImageWithOptions_EmptyOptionsOmitImageConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody generateContentRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		if reqBody.GenerationConfig != nil && reqBody.GenerationConfig.ImageConfig != nil {
+			t.Error("expected no imageConfig for empty options")
+		}
+
+		imageData := base64.StdEncoding.EncodeToString([]byte("image"))
+		resp := generateContentResponse{
+			Candidates: []candidate{
+				{Content: &contentResponse{Parts: []partResponse{{InlineData: &inlineData{MimeType: "image/png", Data: imageData}}}}},
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-key", WithBaseURL(server.URL))
+	if _, err := client.GenerateImageWithOptions(context.Background(), "test", ImageOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
 func TestGenerateImage_EmptyPrompt(t *testing.T) {
 	client, _ := NewClient("test-key")
 	_, err := client.GenerateImage(context.Background(), "")
@@ -229,6 +292,85 @@ func TestGenerateImage_EmptyPrompt(t *testing.T) {
 	}
 }
 
+func TestEditImage(t *testing.T) {
+	baseImage := []byte("\x89PNG\r\n\x1a\nold-image")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody generateContentRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+
+		parts := reqBody.Contents[0].Parts
+		if len(parts) != 2 {
+			t.Fatalf("expected a text and an image part, got %d parts", len(parts))
+		}
+		if parts[0].Text != "make it darker" {
+			t.Errorf("expected prompt 'make it darker', got '%s'", parts[0].Text)
+		}
+		if parts[1].InlineData == nil {
+			t.Fatal("expected inline image data in the second part")
+		}
+		if parts[1].InlineData.MimeType != "image/png" {
+			t.Errorf("expected mime type 'image/png', got '%s'", parts[1].InlineData.MimeType)
+		}
+		if decoded, _ := base64.StdEncoding.DecodeString(parts[1].InlineData.Data); string(decoded) != string(baseImage) {
+			t.Errorf("expected the base image to be sent, got '%s'", decoded)
+		}
+		if reqBody.GenerationConfig == nil || reqBody.GenerationConfig.ImageConfig == nil || reqBody.GenerationConfig.ImageConfig.AspectRatio != "4:3" {
+			t.Error("expected aspectRatio '4:3' in request")
+		}
+
+		imageData := base64.StdEncoding.EncodeToString([]byte("new-image"))
+		resp := generateContentResponse{
+			Candidates: []candidate{
+				{Content: &contentResponse{Parts: []partResponse{{InlineData: &inlineData{MimeType: "image/png", Data: imageData}}}}},
+			},
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-key", WithBaseURL(server.URL))
+
+	// An empty MIME type is detected from the data
+	result, err := client.EditImageWithOptions(context.Background(), "make it darker", baseImage, "", ImageOptions{AspectRatio: "4:3"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Data) != "new-image" {
+		t.Errorf("expected image data 'new-image', got '%s'", string(result.Data))
+	}
+}
+
+func TestEditImage_InvalidRequest(t *testing.T) {
+	client, _ := NewClient("test-key")
+
+	tests := []struct {
+		name      string
+		prompt    string
+		baseImage []byte
+		baseMime  string
+	}{
+		{"empty prompt", "", []byte("image"), "image/png"},
+		{"empty base image", "make it darker", nil, "image/png"},
+		{"not an image", "make it darker", []byte("plain text"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.EditImage(context.Background(), tt.prompt, tt.baseImage, tt.baseMime)
+			apiErr, ok := err.(*APIError)
+			if !ok {
+				t.Fatalf("expected *APIError, got %T", err)
+			}
+			if apiErr.Type != ErrorTypeInvalidRequest {
+				t.Errorf("expected error type '%s', got '%s'", ErrorTypeInvalidRequest, apiErr.Type)
+			}
+		})
+	}
+}
+
 func TestGenerateImage_AuthError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusUnauthorized)
@@ -257,7 +399,9 @@ func TestGenerateImage_AuthError(t *testing.T) {
 }
 
 func TestGenerateImage_RateLimitError(t *testing.T) {
+	var requests atomic.Int32
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
 		w.WriteHeader(http.StatusTooManyRequests)
 		json.NewEncoder(w).Encode(map[string]interface{}{
 			"error": map[string]interface{}{
@@ -269,7 +413,7 @@ func TestGenerateImage_RateLimitError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client, _ := NewClient("test-key", WithBaseURL(server.URL))
+	client, _ := NewClient("test-key", WithBaseURL(server.URL), WithRetry(2, time.Millisecond))
 	_, err := client.GenerateImage(context.Background(), "test")
 	if err == nil {
 		t.Fatal("expected error for rate limit")
@@ -281,6 +425,12 @@ func TestGenerateImage_RateLimitError(t *testing.T) {
 	if apiErr.Type != ErrorTypeRateLimit {
 		t.Errorf("expected error type '%s', got '%s'", ErrorTypeRateLimit, apiErr.Type)
 	}
+	if apiErr.Attempts != 3 || requests.Load() != 3 {
+		t.Errorf("expected 3 attempts, got %d (%d requests)", apiErr.Attempts, requests.Load())
+	}
+	if !strings.Contains(apiErr.Error(), "failed after 2 retries") {
+		t.Errorf("expected retry count in error, got %q", apiErr.Error())
+	}
 }
 
 func TestGenerateImage_ContentPolicyError(t *testing.T) {
@@ -308,6 +458,86 @@ func TestGenerateImage_ContentPolicyError(t *testing.T) {
 	}
 }
 
+func TestGenerateImage_ContentPolicyDetails(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		wantMessage    string
+		wantCategories []string
+		wantDetails    ErrorDetails
+	}{
+		{
+			name: "blocked prompt",
+			body: `{"promptFeedback":{"blockReason":"SAFETY","safetyRatings":[
+				{"category":"HARM_CATEGORY_HARASSMENT","probability":"NEGLIGIBLE"},
+				{"category":"HARM_CATEGORY_DANGEROUS_CONTENT","probability":"HIGH","blocked":true}]}}`,
+			wantMessage:    "prompt was blocked: SAFETY",
+			wantCategories: []string{"dangerous content"},
+			wantDetails: ErrorDetails{BlockReason: "SAFETY", SafetyRatings: []SafetyRating{
+				{Category: "HARM_CATEGORY_HARASSMENT", Probability: "NEGLIGIBLE"},
+				{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Probability: "HIGH", Blocked: true},
+			}},
+		},
+		{
+			name: "blocked image",
+			body: `{"candidates":[{"finishReason":"IMAGE_SAFETY","finishMessage":"Unable to show the generated image.","safetyRatings":[
+				{"category":"HARM_CATEGORY_SEXUALLY_EXPLICIT","probability":"MEDIUM"},
+				{"category":"HARM_CATEGORY_HATE_SPEECH","probability":"LOW"}]}]}`,
+			wantMessage:    "image was blocked: IMAGE_SAFETY: Unable to show the generated image.",
+			wantCategories: []string{"sexually explicit"},
+			wantDetails: ErrorDetails{FinishReason: "IMAGE_SAFETY", SafetyRatings: []SafetyRating{
+				{Category: "HARM_CATEGORY_SEXUALLY_EXPLICIT", Probability: "MEDIUM"},
+				{Category: "HARM_CATEGORY_HATE_SPEECH", Probability: "LOW"},
+			}},
+		},
+		{
+			name:        "blocked without ratings",
+			body:        `{"promptFeedback":{"blockReason":"OTHER"}}`,
+			wantMessage: "prompt was blocked: OTHER",
+			wantDetails: ErrorDetails{BlockReason: "OTHER"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, _ := NewClient("test-key", WithBaseURL(server.URL))
+			_, err := client.GenerateImage(context.Background(), "test")
+			apiErr, ok := err.(*APIError)
+			if !ok {
+				t.Fatalf("expected *APIError, got %T: %v", err, err)
+			}
+			if apiErr.Type != ErrorTypeContentPolicy || apiErr.Message != tt.wantMessage {
+				t.Errorf("got %s %q, want %s %q", apiErr.Type, apiErr.Message, ErrorTypeContentPolicy, tt.wantMessage)
+			}
+			if apiErr.Details == nil || !reflect.DeepEqual(*apiErr.Details, tt.wantDetails) {
+				t.Fatalf("Details = %+v, want %+v", apiErr.Details, tt.wantDetails)
+			}
+			if got := apiErr.Details.Categories(); !reflect.DeepEqual(got, tt.wantCategories) {
+				t.Errorf("Categories() = %q, want %q", got, tt.wantCategories)
+			}
+		})
+	}
+}
+
+func TestGenerateImage_NoImageWithoutBlock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"candidates":[{"finishReason":"STOP","content":{"parts":[{"text":"Here is a poem instead."}]}}]}`))
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-key", WithBaseURL(server.URL))
+	_, err := client.GenerateImage(context.Background(), "test")
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Type != ErrorTypeNoImage || apiErr.Details != nil {
+		t.Errorf("expected a no image error without details, got %#v", err)
+	}
+}
+
 func TestGenerateImage_ServerError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -315,7 +545,7 @@ func TestGenerateImage_ServerError(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client, _ := NewClient("test-key", WithBaseURL(server.URL))
+	client, _ := NewClient("test-key", WithBaseURL(server.URL), WithRetry(1, time.Millisecond))
 	_, err := client.GenerateImage(context.Background(), "test")
 	if err == nil {
 		t.Fatal("expected error for server error")
@@ -471,7 +701,7 @@ func TestGenerateImage_InvalidJSON(t *testing.T) {
 	}))
 	defer server.Close()
 
-	client, _ := NewClient("test-key", WithBaseURL(server.URL))
+	client, _ := NewClient("test-key", WithBaseURL(server.URL), WithRetry(0, 0))
 	_, err := client.GenerateImage(context.Background(), "test")
 	if err == nil {
 		t.Fatal("expected error for invalid JSON response")
@@ -577,3 +807,134 @@ func TestWithHTTPClient(t *testing.T) {
 		t.Error("expected custom HTTP client to be set")
 	}
 }
+
+// imageResponse writes a successful generateContent response.
+func imageResponse(w http.ResponseWriter) {
+	resp := generateContentResponse{
+		Candidates: []candidate{
+			{Content: &contentResponse{Parts: []partResponse{{InlineData: &inlineData{
+				MimeType: "image/png",
+				Data:     base64.StdEncoding.EncodeToString([]byte("image")),
+			}}}}},
+		},
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func TestGenerateImage_RetriesUntilSuccess(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch requests.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			imageResponse(w)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-key", WithBaseURL(server.URL), WithRetry(3, time.Millisecond))
+	result, err := client.GenerateImage(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Data) != "image" {
+		t.Errorf("unexpected image data %q", result.Data)
+	}
+	if requests.Load() != 3 {
+		t.Errorf("expected 3 requests, got %d", requests.Load())
+	}
+}
+
+func TestGenerateImage_DoesNotRetryClientErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantType ErrorType
+	}{
+		{"auth", http.StatusUnauthorized, `{"error":{"code":401,"message":"bad key"}}`, ErrorTypeAuth},
+		{"content policy", http.StatusBadRequest, `{"error":{"code":400,"message":"blocked by safety filters"}}`, ErrorTypeContentPolicy},
+		{"invalid request", http.StatusBadRequest, `{"error":{"code":400,"message":"bad prompt"}}`, ErrorTypeInvalidRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client, _ := NewClient("test-key", WithBaseURL(server.URL), WithRetry(3, time.Millisecond))
+			_, err := client.GenerateImage(context.Background(), "test")
+			apiErr, ok := err.(*APIError)
+			if !ok {
+				t.Fatalf("expected *APIError, got %T", err)
+			}
+			if apiErr.Type != tt.wantType {
+				t.Errorf("expected error type '%s', got '%s'", tt.wantType, apiErr.Type)
+			}
+			if requests.Load() != 1 || apiErr.Retries() != 0 {
+				t.Errorf("expected no retries, got %d requests", requests.Load())
+			}
+		})
+	}
+}
+
+func TestGenerateImage_HonorsRetryAfter(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		imageResponse(w)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-key", WithBaseURL(server.URL), WithRetry(3, time.Millisecond))
+	var waited []time.Duration
+	client.wait = func(ctx context.Context, d time.Duration) error {
+		waited = append(waited, d)
+		return nil
+	}
+
+	if _, err := client.GenerateImage(context.Background(), "test"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(waited) != 1 || waited[0] != 7*time.Second {
+		t.Errorf("expected a single 7s wait, got %v", waited)
+	}
+}
+
+func TestGenerateImage_CancelStopsRetries(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client, _ := NewClient("test-key", WithBaseURL(server.URL), WithRetry(5, time.Hour))
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.GenerateImage(ctx, "test")
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("cancel should stop waiting for the next retry")
+	}
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		t.Fatalf("expected *APIError, got %T", err)
+	}
+	if apiErr.Type != ErrorTypeNetwork || apiErr.Attempts != 1 || requests.Load() != 1 {
+		t.Errorf("expected canceled error after 1 attempt, got %v (%d requests)", apiErr, requests.Load())
+	}
+}
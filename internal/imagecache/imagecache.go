@@ -0,0 +1,232 @@
+// Package imagecache keeps the AI images generated for a presentation in a
+// local cache, keyed by the provider, model, prompt, and options they were
+// generated with, so generating the same image again doesn't call the image
+// provider twice. The cache is a folder next to the images, which builds
+// don't copy because only the images slides reference are copied.
+package imagecache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+)
+
+// DirName is the name of the cache folder in a presentation's images folder.
+const DirName = ".tap-cache"
+
+// DefaultMaxSize is the size limit of the cache in bytes, used when the
+// imageGen section of tap.yaml doesn't set cacheMaxMB.
+const DefaultMaxSize int64 = 200 << 20
+
+// metaSuffix is the suffix of the file next to each cached image that holds
+// its content type and when it was generated.
+const metaSuffix = ".json"
+
+// Entry is a cached image.
+type Entry struct {
+	Data        []byte
+	ContentType string
+	Created     time.Time // When the image was generated
+}
+
+// meta is the content of an entry's metadata file.
+type meta struct {
+	ContentType string    `json:"contentType"`
+	Created     time.Time `json:"created"`
+}
+
+// Cache is a folder of generated images, limited in size. When it grows past
+// the limit, the least recently used images are removed.
+type Cache struct {
+	dir     string
+	maxSize int64
+	now     func() time.Time
+}
+
+// New returns the cache in dir, which is created when the first image is
+// stored. maxSize is the size limit in bytes; 0 or less uses DefaultMaxSize.
+func New(dir string, maxSize int64) *Cache {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSize
+	}
+	return &Cache{dir: dir, maxSize: maxSize, now: time.Now}
+}
+
+// Dir returns the cache folder of a presentation's images folder.
+func Dir(imagesDir string) string {
+	return filepath.Join(imagesDir, DirName)
+}
+
+// Key returns the cache key of an image generated with a provider and model
+// from a prompt and options. The reference image of an edit is part of the
+// key, by its hash.
+func Key(provider, model, prompt string, opts imagegen.Options) string {
+	reference := ""
+	if opts.Reference != nil {
+		sum := sha256.Sum256(opts.Reference)
+		reference = hex.EncodeToString(sum[:])
+	}
+
+	// Fields are separated by NUL, which none of them contains, so different
+	// fields never produce the same key
+	h := sha256.New()
+	for _, field := range []string{provider, model, prompt, opts.AspectRatio, opts.ImageSize, reference} {
+		h.Write([]byte(field))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Get returns the cached image with the given key, and marks it as recently
+// used. It reports false if there is none or it can't be read.
+func (c *Cache) Get(key string) (*Entry, bool) {
+	metaData, err := os.ReadFile(c.metaPath(key))
+	if err != nil {
+		return nil, false
+	}
+	var m meta
+	if err := json.Unmarshal(metaData, &m); err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.dataPath(key))
+	if err != nil || len(data) == 0 {
+		return nil, false
+	}
+
+	now := c.now()
+	_ = os.Chtimes(c.dataPath(key), now, now)
+	return &Entry{Data: data, ContentType: m.ContentType, Created: m.Created}, true
+}
+
+// Put stores an image under key, then removes the least recently used images
+// while the cache is larger than its limit.
+func (c *Cache) Put(key string, data []byte, contentType string) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create image cache: %w", err)
+	}
+
+	metaData, err := json.Marshal(meta{ContentType: contentType, Created: c.now()})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+	if err := os.WriteFile(c.dataPath(key), data, 0644); err != nil {
+		return fmt.Errorf("failed to write cached image: %w", err)
+	}
+	if err := os.WriteFile(c.metaPath(key), metaData, 0644); err != nil {
+		_ = os.Remove(c.dataPath(key))
+		return fmt.Errorf("failed to write cached image: %w", err)
+	}
+
+	now := c.now()
+	_ = os.Chtimes(c.dataPath(key), now, now)
+	return c.evict()
+}
+
+// Clear removes the cache folder. It returns how many images were removed
+// and their size in bytes.
+func (c *Cache) Clear() (int, int64, error) {
+	entries, err := c.entries()
+	if err != nil {
+		return 0, 0, err
+	}
+	var size int64
+	for _, entry := range entries {
+		size += entry.size
+	}
+	if err := os.RemoveAll(c.dir); err != nil {
+		return 0, 0, fmt.Errorf("failed to remove image cache: %w", err)
+	}
+	return len(entries), size, nil
+}
+
+// cacheEntry is a cached image on disk, for eviction.
+type cacheEntry struct {
+	key     string
+	size    int64     // Size of the image and its metadata file
+	lastUse time.Time // Modification time of the image, which Get updates
+}
+
+// entries lists the cached images. A missing cache has none.
+func (c *Cache) entries() ([]cacheEntry, error) {
+	files, err := os.ReadDir(c.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image cache: %w", err)
+	}
+
+	var entries []cacheEntry
+	for _, file := range files {
+		key, ok := strings.CutSuffix(file.Name(), metaSuffix)
+		if !ok || file.IsDir() {
+			continue
+		}
+		metaInfo, err := file.Info()
+		if err != nil {
+			continue
+		}
+		dataInfo, err := os.Stat(c.dataPath(key))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, cacheEntry{
+			key:     key,
+			size:    dataInfo.Size() + metaInfo.Size(),
+			lastUse: dataInfo.ModTime(),
+		})
+	}
+	return entries, nil
+}
+
+// evict removes the least recently used images until the cache fits in its
+// size limit.
+func (c *Cache) evict() error {
+	entries, err := c.entries()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.size
+	}
+	if total <= c.maxSize {
+		return nil
+	}
+
+	slices.SortFunc(entries, func(a, b cacheEntry) int {
+		return a.lastUse.Compare(b.lastUse)
+	})
+	for _, entry := range entries {
+		if total <= c.maxSize {
+			break
+		}
+		if err := os.Remove(c.dataPath(entry.key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to evict cached image: %w", err)
+		}
+		_ = os.Remove(c.metaPath(entry.key))
+		total -= entry.size
+	}
+	return nil
+}
+
+// dataPath returns the path of the image with the given key.
+func (c *Cache) dataPath(key string) string {
+	return filepath.Join(c.dir, key)
+}
+
+// metaPath returns the path of the metadata file of the image with the given key.
+func (c *Cache) metaPath(key string) string {
+	return filepath.Join(c.dir, key+metaSuffix)
+}
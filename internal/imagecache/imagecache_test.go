@@ -0,0 +1,159 @@
+package imagecache
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+)
+
+// newTestCache returns a cache in a temporary folder whose clock advances a
+// minute on every call, so the order of use is deterministic.
+func newTestCache(t *testing.T, maxSize int64) *Cache {
+	t.Helper()
+	c := New(filepath.Join(t.TempDir(), "images", DirName), maxSize)
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	return c
+}
+
+func TestKey(t *testing.T) {
+	opts := imagegen.Options{AspectRatio: "16:9", ImageSize: "2K"}
+	base := Key("gemini", "", "a lighthouse at dusk", opts)
+
+	if len(base) != 64 {
+		t.Errorf("Key() = %q, want a hex sha256", base)
+	}
+	if again := Key("gemini", "", "a lighthouse at dusk", opts); again != base {
+		t.Errorf("Key() = %q on the second call, want the same key %q", again, base)
+	}
+
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		prompt   string
+		opts     imagegen.Options
+	}{
+		{"provider", "openai", "", "a lighthouse at dusk", opts},
+		{"model", "gemini", "gemini-2.5-flash-image", "a lighthouse at dusk", opts},
+		{"prompt", "gemini", "", "a lighthouse at dawn", opts},
+		{"aspect ratio", "gemini", "", "a lighthouse at dusk", imagegen.Options{AspectRatio: "1:1", ImageSize: "2K"}},
+		{"image size", "gemini", "", "a lighthouse at dusk", imagegen.Options{AspectRatio: "16:9", ImageSize: "4K"}},
+		{"reference", "gemini", "", "a lighthouse at dusk", imagegen.Options{AspectRatio: "16:9", ImageSize: "2K", Reference: []byte("old image")}},
+		// Fields are separated, so moving text between them changes the key
+		{"field boundary", "gemini", "a lighthouse", " at dusk", opts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Key(tt.provider, tt.model, tt.prompt, tt.opts); got == base {
+				t.Errorf("Key() with another %s = the base key", tt.name)
+			}
+		})
+	}
+
+	// The reference is keyed by its content
+	withRef := imagegen.Options{AspectRatio: "16:9", ImageSize: "2K", Reference: []byte("old image")}
+	sameRef := imagegen.Options{AspectRatio: "16:9", ImageSize: "2K", Reference: []byte("old image")}
+	if Key("gemini", "", "p", withRef) != Key("gemini", "", "p", sameRef) {
+		t.Error("Key() differs for equal reference images")
+	}
+}
+
+func TestPutGet(t *testing.T) {
+	c := newTestCache(t, 0)
+	key := Key("gemini", "", "a lighthouse", imagegen.Options{})
+
+	if _, ok := c.Get(key); ok {
+		t.Fatal("Get() on an empty cache reported a hit")
+	}
+
+	data := []byte("\x89PNG image data")
+	if err := c.Put(key, data, "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	entry, ok := c.Get(key)
+	if !ok {
+		t.Fatal("Get() after Put() reported a miss")
+	}
+	if !bytes.Equal(entry.Data, data) || entry.ContentType != "image/png" {
+		t.Errorf("Get() = %q (%s), want %q (image/png)", entry.Data, entry.ContentType, data)
+	}
+	if entry.Created.IsZero() {
+		t.Error("Created is not set")
+	}
+}
+
+func TestGetIgnoresIncompleteEntries(t *testing.T) {
+	c := newTestCache(t, 0)
+	if err := c.Put("abc", []byte("data"), "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := os.Remove(c.dataPath("abc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Get("abc"); ok {
+		t.Error("Get() reported a hit for an entry without its image")
+	}
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	image := bytes.Repeat([]byte("x"), 1000)
+
+	// Room for two images and their metadata, but not three
+	c := newTestCache(t, 2500)
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(key, image, "image/png"); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+
+	// Using a makes b the least recently used
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("Get(a) reported a miss")
+	}
+	if err := c.Put("c", image, "image/png"); err != nil {
+		t.Fatalf("Put(c) error = %v", err)
+	}
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := c.Get(key); ok != want {
+			t.Errorf("Get(%s) hit = %v, want %v", key, ok, want)
+		}
+	}
+	if _, err := os.Stat(c.metaPath("b")); !os.IsNotExist(err) {
+		t.Error("metadata of the evicted image was not removed")
+	}
+}
+
+func TestClear(t *testing.T) {
+	c := newTestCache(t, 0)
+	for _, key := range []string{"a", "b"} {
+		if err := c.Put(key, []byte("image"), "image/png"); err != nil {
+			t.Fatalf("Put(%s) error = %v", key, err)
+		}
+	}
+
+	count, size, err := c.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if count != 2 || size == 0 {
+		t.Errorf("Clear() = %d images, %d bytes, want 2 images", count, size)
+	}
+	if _, err := os.Stat(c.dir); !os.IsNotExist(err) {
+		t.Error("cache folder still exists after Clear()")
+	}
+
+	// Clearing a missing cache is fine
+	if count, _, err := c.Clear(); err != nil || count != 0 {
+		t.Errorf("Clear() on a missing cache = %d, %v, want 0, nil", count, err)
+	}
+}
@@ -0,0 +1,220 @@
+// Package imagegen generates images from text prompts with a configurable AI
+// image provider.
+package imagegen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/gemini"
+)
+
+// Provider names.
+const (
+	// ProviderGemini generates images with the Gemini API. It is the default.
+	ProviderGemini = "gemini"
+	// ProviderOpenAI generates images with the OpenAI images API (DALL-E).
+	ProviderOpenAI = "openai"
+	// ProviderOpenAICompatible generates images with a server implementing
+	// the OpenAI images API, such as a self-hosted SDXL endpoint.
+	ProviderOpenAICompatible = "openai-compatible"
+)
+
+// Providers lists the supported provider names.
+var Providers = []string{ProviderGemini, ProviderOpenAI, ProviderOpenAICompatible}
+
+// Environment variables that override the image provider configured in the
+// project config file.
+const (
+	EnvProvider = "TAP_IMAGE_PROVIDER"
+	EnvBaseURL  = "TAP_IMAGE_BASE_URL"
+	EnvModel    = "TAP_IMAGE_MODEL"
+	EnvAPIKey   = "TAP_IMAGE_API_KEY"
+)
+
+// APIError and ErrorType are the errors returned by all providers. Each
+// provider classifies its errors into the same types, so callers handle them
+// alike whichever provider is configured.
+type (
+	APIError  = gemini.APIError
+	ErrorType = gemini.ErrorType
+)
+
+// ErrorDetails and SafetyRating say why a provider blocked a request, for
+// content policy errors whose response explains it.
+type (
+	ErrorDetails = gemini.ErrorDetails
+	SafetyRating = gemini.SafetyRating
+)
+
+// Error types returned by providers.
+const (
+	ErrorTypeAuth           = gemini.ErrorTypeAuth
+	ErrorTypeRateLimit      = gemini.ErrorTypeRateLimit
+	ErrorTypeContentPolicy  = gemini.ErrorTypeContentPolicy
+	ErrorTypeInvalidRequest = gemini.ErrorTypeInvalidRequest
+	ErrorTypeServer         = gemini.ErrorTypeServer
+	ErrorTypeNetwork        = gemini.ErrorTypeNetwork
+	ErrorTypeNoImage        = gemini.ErrorTypeNoImage
+	ErrorTypeInvalidImage   = gemini.ErrorTypeInvalidImage
+)
+
+// AspectRatios lists the aspect ratios that can be requested. Providers that
+// support fewer use the closest one.
+var AspectRatios = gemini.AspectRatios
+
+// ImageSizes lists the output resolutions that can be requested.
+var ImageSizes = gemini.ImageSizes
+
+// Options configures image generation.
+type Options struct {
+	// AspectRatio is the desired aspect ratio (e.g., "16:9"). Empty uses the provider default.
+	AspectRatio string
+	// ImageSize is the desired output resolution ("1K", "2K", or "4K"). Empty uses the provider default.
+	ImageSize string
+	// Reference is an existing image to edit instead of generating from the
+	// prompt alone. ReferenceType is its MIME type; if empty, it is detected.
+	Reference     []byte
+	ReferenceType string
+}
+
+// Result is a generated image.
+type Result struct {
+	Data        []byte
+	ContentType string
+}
+
+// Generator generates images from text prompts.
+type Generator interface {
+	// GenerateImage generates an image from a prompt. Errors are *APIError
+	// values classified by ErrorType.
+	GenerateImage(ctx context.Context, prompt string, opts Options) (Result, error)
+}
+
+// ResolveConfig returns cfg with the environment variables applied, which
+// take precedence over the project config file, and the provider defaulted to
+// Gemini.
+func ResolveConfig(cfg config.ImageGenConfig) config.ImageGenConfig {
+	for _, setting := range []struct {
+		env   string
+		field *string
+	}{
+		{EnvProvider, &cfg.Provider},
+		{EnvBaseURL, &cfg.BaseURL},
+		{EnvModel, &cfg.Model},
+		{EnvAPIKey, &cfg.APIKey},
+	} {
+		if value := os.Getenv(setting.env); value != "" {
+			*setting.field = value
+		}
+	}
+	if cfg.Provider == "" {
+		cfg.Provider = ProviderGemini
+	}
+	return cfg
+}
+
+// APIKeyEnv returns the environment variable that holds the API key for a
+// provider when the configuration doesn't set one, or "" if the provider
+// doesn't need one.
+func APIKeyEnv(provider string) string {
+	switch provider {
+	case ProviderGemini, "":
+		return gemini.EnvAPIKey
+	case ProviderOpenAI:
+		return EnvOpenAIAPIKey
+	}
+	return ""
+}
+
+// CheckConfig reports a configuration that can't be used to generate images:
+// an unknown provider, a missing API key, or an OpenAI-compatible provider
+// without a base URL or model. cfg should be resolved with ResolveConfig.
+func CheckConfig(cfg config.ImageGenConfig) error {
+	switch cfg.Provider {
+	case ProviderGemini, ProviderOpenAI:
+		if cfg.APIKey == "" && os.Getenv(APIKeyEnv(cfg.Provider)) == "" {
+			return fmt.Errorf("%s not set. Add it to your .env file to use AI image generation", APIKeyEnv(cfg.Provider))
+		}
+	case ProviderOpenAICompatible:
+		if cfg.BaseURL == "" {
+			return fmt.Errorf("the %s image provider needs a base URL: set imageGen.baseURL in %s or %s", cfg.Provider, config.ProjectConfigFile, EnvBaseURL)
+		}
+		if cfg.Model == "" {
+			return fmt.Errorf("the %s image provider needs a model: set imageGen.model in %s or %s", cfg.Provider, config.ProjectConfigFile, EnvModel)
+		}
+	default:
+		return fmt.Errorf("unknown image provider %q: must be %s", cfg.Provider, strings.Join(Providers, ", "))
+	}
+	return nil
+}
+
+// New returns the generator for a provider configuration, resolved with
+// ResolveConfig. Errors are *APIError values, like those of GenerateImage.
+func New(cfg config.ImageGenConfig) (Generator, error) {
+	if err := CheckConfig(cfg); err != nil {
+		return nil, &APIError{Type: ErrorTypeAuth, Message: err.Error()}
+	}
+
+	switch cfg.Provider {
+	case ProviderGemini:
+		var opts []gemini.Option
+		if cfg.BaseURL != "" {
+			opts = append(opts, gemini.WithBaseURL(cfg.BaseURL))
+		}
+		if cfg.Model != "" {
+			opts = append(opts, gemini.WithModel(cfg.Model))
+		}
+		client, err := gemini.NewClient(cfg.APIKey, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &geminiGenerator{client: client}, nil
+
+	case ProviderOpenAI:
+		apiKey := cfg.APIKey
+		if apiKey == "" {
+			apiKey = os.Getenv(EnvOpenAIAPIKey)
+		}
+		return NewOpenAIClient(apiKey, withDefault(cfg.BaseURL, DefaultOpenAIBaseURL), withDefault(cfg.Model, DefaultOpenAIModel)), nil
+
+	default:
+		return NewOpenAIClient(cfg.APIKey, cfg.BaseURL, cfg.Model), nil
+	}
+}
+
+// withDefault returns value, or def if value is empty.
+func withDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// geminiGenerator generates images with the Gemini client.
+type geminiGenerator struct {
+	client *gemini.Client
+}
+
+// GenerateImage implements Generator.
+func (g *geminiGenerator) GenerateImage(ctx context.Context, prompt string, opts Options) (Result, error) {
+	imageOpts := gemini.ImageOptions{
+		AspectRatio: opts.AspectRatio,
+		ImageSize:   opts.ImageSize,
+	}
+
+	var result *gemini.ImageResult
+	var err error
+	if opts.Reference != nil {
+		result, err = g.client.EditImageWithOptions(ctx, prompt, opts.Reference, opts.ReferenceType, imageOpts)
+	} else {
+		result, err = g.client.GenerateImageWithOptions(ctx, prompt, imageOpts)
+	}
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Data: result.Data, ContentType: result.ContentType}, nil
+}
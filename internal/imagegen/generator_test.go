@@ -0,0 +1,123 @@
+package imagegen
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/gemini"
+)
+
+// clearEnv unsets the environment variables that configure providers.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, env := range []string{EnvProvider, EnvBaseURL, EnvModel, EnvAPIKey, gemini.EnvAPIKey, EnvOpenAIAPIKey} {
+		t.Setenv(env, "")
+	}
+}
+
+func TestResolveConfig(t *testing.T) {
+	clearEnv(t)
+
+	if got := ResolveConfig(config.ImageGenConfig{}); got.Provider != ProviderGemini {
+		t.Errorf("expected the Gemini provider by default, got %q", got.Provider)
+	}
+
+	t.Setenv(EnvProvider, ProviderOpenAICompatible)
+	t.Setenv(EnvModel, "flux")
+	got := ResolveConfig(config.ImageGenConfig{Provider: ProviderOpenAI, BaseURL: "http://localhost:9000", Model: "sdxl"})
+	want := config.ImageGenConfig{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost:9000", Model: "flux"}
+	if got != want {
+		t.Errorf("ResolveConfig() = %+v, want %+v", got, want)
+	}
+}
+
+func TestCheckConfig(t *testing.T) {
+	clearEnv(t)
+
+	tests := []struct {
+		name    string
+		cfg     config.ImageGenConfig
+		wantErr string
+	}{
+		{"gemini without key", config.ImageGenConfig{Provider: ProviderGemini}, "GEMINI_API_KEY not set"},
+		{"gemini with key", config.ImageGenConfig{Provider: ProviderGemini, APIKey: "key"}, ""},
+		{"openai without key", config.ImageGenConfig{Provider: ProviderOpenAI}, "OPENAI_API_KEY not set"},
+		{"compatible without base URL", config.ImageGenConfig{Provider: ProviderOpenAICompatible, Model: "sdxl"}, "needs a base URL"},
+		{"compatible without model", config.ImageGenConfig{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost"}, "needs a model"},
+		{"compatible", config.ImageGenConfig{Provider: ProviderOpenAICompatible, BaseURL: "http://localhost", Model: "sdxl"}, ""},
+		{"unknown provider", config.ImageGenConfig{Provider: "midjourney"}, `unknown image provider "midjourney"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckConfig(tt.cfg)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// The provider's API key can come from the environment
+	t.Setenv(EnvOpenAIAPIKey, "key")
+	if err := CheckConfig(config.ImageGenConfig{Provider: ProviderOpenAI}); err != nil {
+		t.Errorf("expected OPENAI_API_KEY to be used, got %v", err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	clearEnv(t)
+
+	_, err := New(config.ImageGenConfig{Provider: ProviderGemini})
+	if apiErr, ok := err.(*APIError); !ok || apiErr.Type != ErrorTypeAuth {
+		t.Errorf("expected an auth error without an API key, got %v", err)
+	}
+
+	generator, err := New(config.ImageGenConfig{Provider: ProviderOpenAI, APIKey: "key"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	client, ok := generator.(*OpenAIClient)
+	if !ok {
+		t.Fatalf("expected *OpenAIClient, got %T", generator)
+	}
+	if client.baseURL != DefaultOpenAIBaseURL || client.model != DefaultOpenAIModel {
+		t.Errorf("expected the OpenAI defaults, got baseURL=%q model=%q", client.baseURL, client.model)
+	}
+}
+
+func TestGeminiGenerator(t *testing.T) {
+	clearEnv(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.URL.Path, "/models/custom-model:") {
+			t.Errorf("expected the configured model, got path %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"candidates":[{"content":{"parts":[{"inlineData":{"mime_type":"image/png","data":%q}}]}}]}`,
+			base64.StdEncoding.EncodeToString(testPNG))
+	}))
+	defer server.Close()
+
+	generator, err := New(config.ImageGenConfig{Provider: ProviderGemini, APIKey: "key", BaseURL: server.URL, Model: "custom-model"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result, err := generator.GenerateImage(context.Background(), "A lighthouse", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Data) != string(testPNG) || result.ContentType != "image/png" {
+		t.Errorf("unexpected result %q (%s)", result.Data, result.ContentType)
+	}
+}
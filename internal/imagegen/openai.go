@@ -0,0 +1,422 @@
+package imagegen
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/gemini"
+	"github.com/MiniCodeMonkey/tap/internal/imagegen/retry"
+)
+
+const (
+	// DefaultOpenAIBaseURL is the base URL for the OpenAI API.
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+	// DefaultOpenAIModel is the OpenAI image model.
+	DefaultOpenAIModel = "dall-e-3"
+
+	// EnvOpenAIAPIKey is the environment variable name for the OpenAI API key.
+	EnvOpenAIAPIKey = "OPENAI_API_KEY"
+
+	// openAITimeout is the timeout for image requests, which can take a while.
+	openAITimeout = 120 * time.Second
+
+	// openAIMaxRetries is the number of retries after a rate limit or server error.
+	openAIMaxRetries = 3
+
+	// openAIRetryBaseDelay is the delay before the first retry. It doubles with each retry.
+	openAIRetryBaseDelay = 2 * time.Second
+)
+
+// OpenAIClient generates images with the OpenAI images API, or a server that
+// implements it. Images are requested as base64 data; servers that return a
+// URL instead are supported too.
+type OpenAIClient struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	httpClient *http.Client
+	maxRetries int
+	baseDelay  time.Duration
+}
+
+// NewOpenAIClient returns a client for the images API at baseURL, such as
+// "https://api.openai.com/v1". The API key may be empty for servers that
+// don't need one.
+func NewOpenAIClient(apiKey, baseURL, model string) *OpenAIClient {
+	return &OpenAIClient{
+		apiKey:     apiKey,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		model:      model,
+		httpClient: &http.Client{Timeout: openAITimeout},
+		maxRetries: openAIMaxRetries,
+		baseDelay:  openAIRetryBaseDelay,
+	}
+}
+
+// SetRetry sets how often rate limit and server errors are retried, and the
+// delay before the first retry. The delay doubles with each retry. A
+// maxRetries of 0 disables retries.
+func (c *OpenAIClient) SetRetry(maxRetries int, baseDelay time.Duration) {
+	c.maxRetries = maxRetries
+	c.baseDelay = baseDelay
+}
+
+// imageRequest holds the parameters of an images API request.
+type imageRequest struct {
+	Model          string `json:"model,omitempty"`
+	Prompt         string `json:"prompt"`
+	N              int    `json:"n"`
+	Size           string `json:"size,omitempty"`
+	Quality        string `json:"quality,omitempty"`
+	ResponseFormat string `json:"response_format"`
+}
+
+// imageResponse is the response body of the images API.
+type imageResponse struct {
+	Data  []imageData     `json:"data"`
+	Error *openAIAPIError `json:"error,omitempty"`
+}
+
+type imageData struct {
+	B64JSON string `json:"b64_json,omitempty"`
+	URL     string `json:"url,omitempty"`
+}
+
+type openAIAPIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    any    `json:"code"`
+}
+
+// GenerateImage implements Generator. With a reference image, the image is
+// edited with the edits endpoint. Rate limit and server errors are retried
+// with exponential backoff, honoring the server's Retry-After header; cancel
+// ctx to stop retrying.
+func (c *OpenAIClient) GenerateImage(ctx context.Context, prompt string, opts Options) (Result, error) {
+	if prompt == "" {
+		return Result{}, &APIError{
+			Type:    ErrorTypeInvalidRequest,
+			Message: "prompt cannot be empty",
+		}
+	}
+
+	policy := retry.Policy{MaxRetries: c.maxRetries, BaseDelay: c.baseDelay}
+	result, attempts, err := retry.Do(ctx, policy, func() (Result, error) {
+		result, apiErr := c.request(ctx, prompt, opts)
+		if apiErr != nil {
+			return Result{}, apiErr
+		}
+		return result, nil
+	})
+	if err != nil {
+		return Result{}, gemini.WithAttempts(err, attempts)
+	}
+	return result, nil
+}
+
+// request sends a single generation or edit request and extracts the image.
+func (c *OpenAIClient) request(ctx context.Context, prompt string, opts Options) (Result, *APIError) {
+	req, apiErr := c.newRequest(ctx, prompt, opts)
+	if apiErr != nil {
+		return Result{}, apiErr
+	}
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Result{}, c.networkError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, &APIError{
+			Type:    ErrorTypeNetwork,
+			Message: fmt.Sprintf("failed to read response: %v", err),
+		}
+	}
+
+	var imageResp imageResponse
+	parseErr := json.Unmarshal(body, &imageResp)
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := c.classifyError(resp.StatusCode, imageResp.Error, body)
+		apiErr.RetryAfter = retry.ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		return Result{}, apiErr
+	}
+	if parseErr != nil {
+		return Result{}, &APIError{
+			Type:    ErrorTypeServer,
+			Message: fmt.Sprintf("failed to parse response: %v", parseErr),
+		}
+	}
+
+	return c.extractImage(ctx, &imageResp)
+}
+
+// newRequest returns the request for a prompt: JSON for the generations
+// endpoint, or a multipart form with the reference image for the edits
+// endpoint.
+func (c *OpenAIClient) newRequest(ctx context.Context, prompt string, opts Options) (*http.Request, *APIError) {
+	params := imageRequest{
+		Model:          c.model,
+		Prompt:         prompt,
+		N:              1,
+		Size:           openAISize(opts.AspectRatio),
+		ResponseFormat: "b64_json",
+	}
+
+	var body bytes.Buffer
+	var url, contentType string
+	var err error
+	if opts.Reference == nil {
+		params.Quality = openAIQuality(opts.ImageSize)
+		err = json.NewEncoder(&body).Encode(params)
+		url, contentType = c.baseURL+"/images/generations", "application/json"
+	} else {
+		contentType, err = writeEditForm(&body, params, opts.Reference, opts.ReferenceType)
+		url = c.baseURL + "/images/edits"
+	}
+	if err != nil {
+		return nil, &APIError{
+			Type:    ErrorTypeInvalidRequest,
+			Message: fmt.Sprintf("failed to build request: %v", err),
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, &body)
+	if err != nil {
+		return nil, &APIError{
+			Type:    ErrorTypeNetwork,
+			Message: fmt.Sprintf("failed to create request: %v", err),
+		}
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
+}
+
+// writeEditForm writes the multipart form of an edit request to w and returns
+// its content type. The edits endpoint takes the same parameters as the
+// generations endpoint, except quality, and the image to edit.
+func writeEditForm(w io.Writer, params imageRequest, image []byte, imageType string) (string, error) {
+	form := multipart.NewWriter(w)
+	for _, field := range [][2]string{
+		{"model", params.Model},
+		{"prompt", params.Prompt},
+		{"n", strconv.Itoa(params.N)},
+		{"size", params.Size},
+		{"response_format", params.ResponseFormat},
+	} {
+		if field[1] == "" {
+			continue
+		}
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return "", err
+		}
+	}
+
+	if imageType == "" {
+		imageType = http.DetectContentType(image)
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="image"; filename="image%s"`, imageExtension(imageType)))
+	header.Set("Content-Type", imageType)
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(image); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+	return form.FormDataContentType(), nil
+}
+
+// extractImage returns the first image in the response, downloading it if
+// the server returned a URL.
+func (c *OpenAIClient) extractImage(ctx context.Context, resp *imageResponse) (Result, *APIError) {
+	for _, image := range resp.Data {
+		switch {
+		case image.B64JSON != "":
+			data, err := base64.StdEncoding.DecodeString(image.B64JSON)
+			if err != nil {
+				return Result{}, &APIError{
+					Type:    ErrorTypeServer,
+					Message: fmt.Sprintf("failed to decode image data: %v", err),
+				}
+			}
+			return Result{Data: data, ContentType: http.DetectContentType(data)}, nil
+
+		case image.URL != "":
+			return c.download(ctx, image.URL)
+		}
+	}
+
+	return Result{}, &APIError{
+		Type:    ErrorTypeNoImage,
+		Message: "response did not contain an image",
+	}
+}
+
+// download fetches a generated image from the URL returned by the server.
+func (c *OpenAIClient) download(ctx context.Context, url string) (Result, *APIError) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return Result{}, &APIError{
+			Type:    ErrorTypeServer,
+			Message: fmt.Sprintf("invalid image URL: %v", err),
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Result{}, c.networkError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, &APIError{
+			Type:    ErrorTypeNetwork,
+			Message: fmt.Sprintf("failed to download image: %v", err),
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, &APIError{
+			Type:    ErrorTypeServer,
+			Message: fmt.Sprintf("failed to download image: %s", resp.Status),
+			Code:    resp.StatusCode,
+		}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = http.DetectContentType(data)
+	}
+	return Result{Data: data, ContentType: contentType}, nil
+}
+
+// networkError converts an error sending a request to an APIError.
+func (c *OpenAIClient) networkError(ctx context.Context, err error) *APIError {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return &APIError{Type: ErrorTypeNetwork, Message: "request timed out"}
+	case context.Canceled:
+		return &APIError{Type: ErrorTypeNetwork, Message: "request was canceled"}
+	}
+	return &APIError{
+		Type:    ErrorTypeNetwork,
+		Message: fmt.Sprintf("failed to send request: %v", c.mask(err.Error())),
+	}
+}
+
+// classifyError converts an error response to an APIError.
+func (c *OpenAIClient) classifyError(statusCode int, errResp *openAIAPIError, body []byte) *APIError {
+	message := strings.TrimSpace(string(body))
+	code := ""
+	if errResp != nil {
+		message = errResp.Message
+		code, _ = errResp.Code.(string)
+	}
+	message = c.mask(message)
+
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return &APIError{Type: ErrorTypeAuth, Message: message, Code: statusCode}
+	case statusCode == http.StatusTooManyRequests:
+		return &APIError{Type: ErrorTypeRateLimit, Message: message, Code: statusCode}
+	case statusCode >= 400 && statusCode < 500:
+		lower := strings.ToLower(message)
+		if code == "content_policy_violation" || strings.Contains(lower, "safety") || strings.Contains(lower, "policy") {
+			return &APIError{Type: ErrorTypeContentPolicy, Message: message, Code: statusCode, Details: openAIErrorDetails(code, message)}
+		}
+		return &APIError{Type: ErrorTypeInvalidRequest, Message: message, Code: statusCode}
+	default:
+		return &APIError{Type: ErrorTypeServer, Message: fmt.Sprintf("server error: %s", message), Code: statusCode}
+	}
+}
+
+// safetyViolationsRe matches the categories OpenAI lists in the message of a
+// request rejected by its safety system, e.g. "safety_violations=[violence]".
+var safetyViolationsRe = regexp.MustCompile(`safety_violations=\[([^\]]*)\]`)
+
+// openAIErrorDetails returns the details of a content policy error from its
+// error code and the categories listed in its message, or nil if there are
+// neither.
+func openAIErrorDetails(code, message string) *ErrorDetails {
+	var ratings []SafetyRating
+	if match := safetyViolationsRe.FindStringSubmatch(message); match != nil {
+		for _, category := range strings.Split(match[1], ",") {
+			if category = strings.Trim(strings.TrimSpace(category), `'"`); category != "" {
+				ratings = append(ratings, SafetyRating{Category: category, Blocked: true})
+			}
+		}
+	}
+	if code == "" && len(ratings) == 0 {
+		return nil
+	}
+	return &ErrorDetails{BlockReason: code, SafetyRatings: ratings}
+}
+
+// mask replaces the API key in a string with [REDACTED].
+func (c *OpenAIClient) mask(s string) string {
+	if c.apiKey == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, c.apiKey, "[REDACTED]")
+}
+
+// openAISize returns the image size for an aspect ratio: landscape, portrait,
+// or square at the sizes DALL-E 3 supports. It returns "" for an empty or
+// invalid aspect ratio, so the server's default is used.
+func openAISize(aspectRatio string) string {
+	w, h, ok := strings.Cut(aspectRatio, ":")
+	width, errW := strconv.Atoi(w)
+	height, errH := strconv.Atoi(h)
+	if !ok || errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return ""
+	}
+	switch {
+	case width > height:
+		return "1792x1024"
+	case width < height:
+		return "1024x1792"
+	}
+	return "1024x1024"
+}
+
+// openAIQuality returns the image quality for an image size: "hd" for 2K and
+// 4K, or "" for the server's default.
+func openAIQuality(imageSize string) string {
+	if imageSize == "2K" || imageSize == "4K" {
+		return "hd"
+	}
+	return ""
+}
+
+// imageExtension returns the file extension for an image MIME type.
+func imageExtension(contentType string) string {
+	switch contentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/webp":
+		return ".webp"
+	}
+	return ".png"
+}
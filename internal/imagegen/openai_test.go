@@ -0,0 +1,225 @@
+package imagegen
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+var testPNG = []byte("\x89PNG\r\n\x1a\nimage")
+
+// writeImageResponse writes a response with testPNG as base64 JSON.
+func writeImageResponse(w http.ResponseWriter) {
+	fmt.Fprintf(w, `{"data":[{"b64_json":%q}]}`, base64.StdEncoding.EncodeToString(testPNG))
+}
+
+func TestOpenAIClient_Generate(t *testing.T) {
+	var got imageRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/images/generations" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "Bearer test-key" {
+			t.Errorf("unexpected Authorization header %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		writeImageResponse(w)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("test-key", server.URL+"/v1", "dall-e-3")
+	result, err := client.GenerateImage(context.Background(), "A lighthouse", Options{AspectRatio: "16:9", ImageSize: "4K"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Data) != string(testPNG) || result.ContentType != "image/png" {
+		t.Errorf("unexpected result %q (%s)", result.Data, result.ContentType)
+	}
+	if got.Model != "dall-e-3" || got.Prompt != "A lighthouse" || got.Size != "1792x1024" || got.Quality != "hd" || got.ResponseFormat != "b64_json" {
+		t.Errorf("unexpected request %+v", got)
+	}
+}
+
+func TestOpenAIClient_EditWithReference(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/images/edits" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Error("expected no Authorization header without an API key")
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatalf("failed to parse form: %v", err)
+		}
+		if r.FormValue("prompt") != "Make it night" || r.FormValue("model") != "sdxl" {
+			t.Errorf("unexpected form values %v", r.MultipartForm.Value)
+		}
+		file, header, err := r.FormFile("image")
+		if err != nil {
+			t.Fatalf("expected an image part: %v", err)
+		}
+		data, _ := io.ReadAll(file)
+		if string(data) != string(testPNG) || header.Header.Get("Content-Type") != "image/png" {
+			t.Errorf("unexpected image part %q (%s)", data, header.Header.Get("Content-Type"))
+		}
+		writeImageResponse(w)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("", server.URL, "sdxl")
+	if _, err := client.GenerateImage(context.Background(), "Make it night", Options{Reference: testPNG}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestOpenAIClient_DownloadsURL(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/files/image.png" {
+			w.Write(testPNG)
+			return
+		}
+		fmt.Fprintf(w, `{"data":[{"url":%q}]}`, server.URL+"/files/image.png")
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("key", server.URL, "dall-e-3")
+	result, err := client.GenerateImage(context.Background(), "A lighthouse", Options{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(result.Data) != string(testPNG) {
+		t.Errorf("expected the downloaded image, got %q", result.Data)
+	}
+}
+
+func TestOpenAIClient_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantType ErrorType
+	}{
+		{"auth", http.StatusUnauthorized, `{"error":{"message":"Incorrect API key provided: secret-key"}}`, ErrorTypeAuth},
+		{"content policy", http.StatusBadRequest, `{"error":{"message":"Your request was rejected","code":"content_policy_violation"}}`, ErrorTypeContentPolicy},
+		{"invalid request", http.StatusBadRequest, `{"error":{"message":"Invalid size"}}`, ErrorTypeInvalidRequest},
+		{"server", http.StatusInternalServerError, "upstream failed", ErrorTypeServer},
+		{"no image", http.StatusOK, `{"data":[]}`, ErrorTypeNoImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			client := NewOpenAIClient("secret-key", server.URL, "dall-e-3")
+			client.SetRetry(0, 0)
+			_, err := client.GenerateImage(context.Background(), "A lighthouse", Options{})
+			apiErr, ok := err.(*APIError)
+			if !ok {
+				t.Fatalf("expected *APIError, got %T (%v)", err, err)
+			}
+			if apiErr.Type != tt.wantType {
+				t.Errorf("expected type %s, got %s (%s)", tt.wantType, apiErr.Type, apiErr.Message)
+			}
+			if strings.Contains(apiErr.Message, "secret-key") {
+				t.Errorf("expected the API key to be masked, got %q", apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestOpenAIClient_ContentPolicyDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		io.WriteString(w, `{"error":{"message":"Your request was rejected by the safety system. safety_violations=[violence, self-harm].","code":"moderation_blocked"}}`)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("key", server.URL, "gpt-image-1")
+	client.SetRetry(0, 0)
+	_, err := client.GenerateImage(context.Background(), "A lighthouse", Options{})
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Type != ErrorTypeContentPolicy {
+		t.Fatalf("expected a content policy error, got %v", err)
+	}
+	if apiErr.Details == nil || apiErr.Details.BlockReason != "moderation_blocked" {
+		t.Fatalf("expected the error code in the details, got %+v", apiErr.Details)
+	}
+	if got := apiErr.Details.Categories(); !reflect.DeepEqual(got, []string{"violence", "self-harm"}) {
+		t.Errorf("Categories() = %q, want the listed safety violations", got)
+	}
+}
+
+func TestOpenAIErrorDetails(t *testing.T) {
+	if got := openAIErrorDetails("", "Your request was rejected"); got != nil {
+		t.Errorf("expected no details without a code or categories, got %+v", got)
+	}
+	got := openAIErrorDetails("content_policy_violation", "Your request was rejected")
+	if got == nil || got.BlockReason != "content_policy_violation" || len(got.SafetyRatings) != 0 {
+		t.Errorf("expected only the code, got %+v", got)
+	}
+}
+
+func TestOpenAIClient_RetriesRateLimit(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"error":{"message":"Rate limit reached"}}`)
+			return
+		}
+		writeImageResponse(w)
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("key", server.URL, "dall-e-3")
+	client.SetRetry(2, 0)
+	if _, err := client.GenerateImage(context.Background(), "A lighthouse", Options{}); err != nil {
+		t.Fatalf("expected the request to succeed after retrying, got %v", err)
+	}
+
+	requests.Store(0)
+	client.SetRetry(1, 0)
+	_, err := client.GenerateImage(context.Background(), "A lighthouse", Options{})
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Type != ErrorTypeRateLimit || apiErr.Attempts != 2 {
+		t.Errorf("expected a rate limit error after 2 attempts, got %v", err)
+	}
+}
+
+func TestOpenAIClient_EmptyPrompt(t *testing.T) {
+	client := NewOpenAIClient("key", "http://localhost", "dall-e-3")
+	_, err := client.GenerateImage(context.Background(), "", Options{})
+	if apiErr, ok := err.(*APIError); !ok || apiErr.Type != ErrorTypeInvalidRequest {
+		t.Errorf("expected an invalid request error, got %v", err)
+	}
+}
+
+func TestOpenAISize(t *testing.T) {
+	tests := map[string]string{
+		"16:9": "1792x1024",
+		"9:16": "1024x1792",
+		"1:1":  "1024x1024",
+		"":     "",
+		"wide": "",
+	}
+	for aspectRatio, want := range tests {
+		if got := openAISize(aspectRatio); got != want {
+			t.Errorf("openAISize(%q) = %q, want %q", aspectRatio, got, want)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+// Package retry retries image generation requests that fail with rate limit
+// or server errors, with capped exponential backoff that honors the server's
+// Retry-After header. It is shared by all image generation providers; it is a
+// package of its own because the imagegen package imports the Gemini client.
+package retry
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxDelay caps the backoff delay between retries.
+const MaxDelay = 30 * time.Second
+
+// Error is implemented by errors that may succeed when the request is retried.
+type Error interface {
+	error
+	// Retryable reports whether the request may succeed when retried.
+	Retryable() bool
+	// RetryDelay returns the delay requested by the server, or 0.
+	RetryDelay() time.Duration
+}
+
+// Policy is how often a request is retried and how long to wait in between.
+type Policy struct {
+	// MaxRetries is the number of retries after the first attempt. 0 disables retries.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry. It doubles with each retry.
+	BaseDelay time.Duration
+	// Wait pauses between retries; it returns early with an error when ctx
+	// is done. Nil uses Wait.
+	Wait func(ctx context.Context, d time.Duration) error
+}
+
+// Do calls fn until it succeeds, fails with an error that isn't a retryable
+// Error, or has been retried p.MaxRetries times. It returns fn's last result
+// and error, and the number of attempts. If ctx is done while waiting for the
+// next retry, it returns ctx's error.
+func Do[T any](ctx context.Context, p Policy, fn func() (T, error)) (T, int, error) {
+	wait := p.Wait
+	if wait == nil {
+		wait = Wait
+	}
+
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		var retryErr Error
+		if err == nil || !errors.As(err, &retryErr) || !retryErr.Retryable() || attempt > p.MaxRetries {
+			return result, attempt, err
+		}
+
+		if err := wait(ctx, Delay(attempt, p.BaseDelay, retryErr.RetryDelay())); err != nil {
+			var zero T
+			return zero, attempt, err
+		}
+	}
+}
+
+// Delay returns how long to wait before the given retry attempt (1-based).
+// A delay requested by the server is used as is; otherwise baseDelay is
+// doubled for each attempt, capped at MaxDelay, and jittered so clients don't
+// retry in lockstep.
+func Delay(attempt int, baseDelay, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	delay := baseDelay
+	for i := 1; i < attempt && delay < MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > MaxDelay {
+		delay = MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	// Wait between half and the full delay
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// ParseRetryAfter parses a Retry-After header given in seconds or as an HTTP
+// date, relative to now. It returns 0 if the header is missing or invalid.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// Wait waits for d, returning ctx's error if ctx is done first.
+func Wait(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
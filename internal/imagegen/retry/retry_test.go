@@ -0,0 +1,128 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// testError is a retry.Error with a fixed retryability and server delay.
+type testError struct {
+	retryable  bool
+	retryAfter time.Duration
+}
+
+func (e *testError) Error() string             { return "test error" }
+func (e *testError) Retryable() bool           { return e.retryable }
+func (e *testError) RetryDelay() time.Duration { return e.retryAfter }
+
+func TestDo(t *testing.T) {
+	var waited []time.Duration
+	policy := Policy{MaxRetries: 3, BaseDelay: time.Millisecond, Wait: func(ctx context.Context, d time.Duration) error {
+		waited = append(waited, d)
+		return nil
+	}}
+
+	calls := 0
+	result, attempts, err := Do(context.Background(), policy, func() (string, error) {
+		calls++
+		if calls == 1 {
+			return "", &testError{retryable: true, retryAfter: 7 * time.Second}
+		}
+		return "ok", nil
+	})
+	if result != "ok" || attempts != 2 || err != nil {
+		t.Errorf("Do() = %q, %d, %v, want ok after 2 attempts", result, attempts, err)
+	}
+	if len(waited) != 1 || waited[0] != 7*time.Second {
+		t.Errorf("expected a single 7s wait for Retry-After, got %v", waited)
+	}
+}
+
+func TestDo_StopsRetrying(t *testing.T) {
+	noWait := func(ctx context.Context, d time.Duration) error { return nil }
+	tests := []struct {
+		name         string
+		maxRetries   int
+		err          error
+		wantAttempts int
+	}{
+		{"not retryable", 3, &testError{retryable: false}, 1},
+		{"not a retry error", 3, errors.New("plain"), 1},
+		{"out of retries", 2, &testError{retryable: true}, 3},
+		{"retries disabled", 0, &testError{retryable: true}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			_, attempts, err := Do(context.Background(), Policy{MaxRetries: tt.maxRetries, Wait: noWait}, func() (int, error) {
+				calls++
+				return 0, tt.err
+			})
+			if err != tt.err || attempts != tt.wantAttempts || calls != tt.wantAttempts {
+				t.Errorf("Do() = %d attempts (%d calls), %v, want %d attempts, %v", attempts, calls, err, tt.wantAttempts, tt.err)
+			}
+		})
+	}
+}
+
+func TestDo_CancelStopsWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, attempts, err := Do(ctx, Policy{MaxRetries: 5, BaseDelay: time.Hour}, func() (int, error) {
+		return 0, &testError{retryable: true}
+	})
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("cancel should stop waiting for the next retry")
+	}
+	if !errors.Is(err, context.Canceled) || attempts != 1 {
+		t.Errorf("Do() = %d attempts, %v, want context.Canceled after 1 attempt", attempts, err)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	for attempt, max := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 10: MaxDelay} {
+		for i := 0; i < 20; i++ {
+			d := Delay(attempt, time.Second, 0)
+			if d < max/2 || d > max {
+				t.Fatalf("Delay(%d) = %v, want between %v and %v", attempt, d, max/2, max)
+			}
+		}
+	}
+
+	if d := Delay(1, time.Second, 90*time.Second); d != 90*time.Second {
+		t.Errorf("Delay() with Retry-After = %v, want 90s", d)
+	}
+	if d := Delay(3, 0, 0); d != 0 {
+		t.Errorf("Delay() without a base delay = %v, want 0", d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"0", 0},
+		{"12", 12 * time.Second},
+		{" 12 ", 12 * time.Second},
+		{"-1", 0},
+		{"soon", 0},
+		{now.Add(30 * time.Second).Format(http.TimeFormat), 30 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+	}
+
+	for _, tt := range tests {
+		if got := ParseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
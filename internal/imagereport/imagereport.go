@@ -0,0 +1,232 @@
+// Package imagereport finds the AI-generated images of a presentation, the
+// <!-- ai-prompt: ... --> comments followed by an image, and reports which of
+// them still need to be generated because their file is missing. The dev
+// TUI's batch generation and tap images --dry-run both use it, so they agree
+// on what is pending.
+package imagereport
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// DefaultPricePerImage is the estimated price in US dollars of generating one
+// image, used when the imageGen section of tap.yaml doesn't set pricePerImage.
+const DefaultPricePerImage = 0.04
+
+// frontmatterRe matches YAML frontmatter at the start of a file, with LF or
+// CRLF line endings.
+var frontmatterRe = regexp.MustCompile(`(?s)^---[ \t]*\r?\n.*?\r?\n---[ \t]*(?:\r?\n|$)`)
+
+// aiImageRe matches AI prompt comments followed by an image on the next line,
+// the image's img comment if it is on the line after the image, and the
+// image's caption if it is followed by a line in italics.
+// Group 1: prompt text, Group 2: alt text, Group 3: image path,
+// Group 4: image hint, without braces, Group 5: caption
+// Only matches if the image is directly on the next line (possibly with leading spaces, but no blank lines).
+var aiImageRe = regexp.MustCompile(`(?m)<!--\s*ai-prompt:\s*(.+?)\s*-->\n[ \t]*!\[([^\]\n]*)\]\(([^)]+)\)(?:\n[ \t]*<!--\s*img:\s*\{([^}\n]*)\}\s*-->[ \t]*$)?(?:\n[ \t]*\*([^*\n]+)\*[ \t]*$)?`)
+
+// promptOptionRe matches a trailing image option in an ai-prompt comment: " | ratio: 16:9".
+var promptOptionRe = regexp.MustCompile(`\s*\|\s*(ratio|size|provider):\s*([^|]+?)\s*$`)
+
+// AIImage contains information about an AI-generated image.
+type AIImage struct {
+	// Prompt is the AI prompt used to generate the image.
+	Prompt string `json:"prompt"`
+	// ImagePath is the path to the generated image file, as written in the markdown.
+	ImagePath string `json:"imagePath"`
+	// AltText is the alt text of the image (empty for images inserted without one).
+	AltText string `json:"altText,omitempty"`
+	// Caption is the italic line under the image (empty if there is none).
+	Caption string `json:"caption,omitempty"`
+	// Hint is the content of the img comment after the image, without
+	// braces, such as "fit: cover, position: top" (empty if there is none).
+	Hint string `json:"hint,omitempty"`
+	// AspectRatio is the aspect ratio the image was generated with (empty if not recorded).
+	AspectRatio string `json:"aspectRatio,omitempty"`
+	// ImageSize is the resolution the image was generated with (empty if not recorded).
+	ImageSize string `json:"imageSize,omitempty"`
+}
+
+// Image is an AI-generated image in a report.
+type Image struct {
+	AIImage
+	// Slide is the one-based number of the slide the image is on.
+	Slide int `json:"slide"`
+	// Path is the image file, resolved relative to the markdown file's
+	// directory. It is empty for remote images.
+	Path string `json:"path,omitempty"`
+	// Exists reports whether the image file exists.
+	Exists bool `json:"exists"`
+	// Remote reports whether the image is a URL rather than a local file.
+	// Remote images are never pending.
+	Remote bool `json:"remote,omitempty"`
+}
+
+// Pending reports whether the image still needs to be generated.
+func (img Image) Pending() bool {
+	return !img.Exists && !img.Remote
+}
+
+// Report lists the AI-generated images of a presentation.
+type Report struct {
+	MarkdownFile string  `json:"markdownFile"`
+	Images       []Image `json:"images"`
+}
+
+// Pending returns the images whose file does not exist yet, such as images
+// from prompts written by hand or files deleted since generation.
+func (r Report) Pending() []Image {
+	var pending []Image
+	for _, img := range r.Images {
+		if img.Pending() {
+			pending = append(pending, img)
+		}
+	}
+	return pending
+}
+
+// EstimateCost returns the estimated cost of generating the pending images at
+// pricePerImage each.
+func (r Report) EstimateCost(pricePerImage float64) float64 {
+	return float64(len(r.Pending())) * pricePerImage
+}
+
+// Scan reads the presentation in markdownPath, with includes expanded, and
+// reports its AI-generated images.
+func Scan(markdownPath string) (Report, error) {
+	content, err := os.ReadFile(markdownPath)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to read markdown file: %w", err)
+	}
+
+	// Split slides like the parser, falling back to the defaults if the
+	// configuration is invalid
+	strict := false
+	if cfg, err := config.Load(markdownPath, nil, config.Overrides{}); err == nil {
+		strict = cfg.StrictDelimiters
+	}
+
+	text := string(content)
+	if parser.HasIncludes(text) {
+		expanded, _, err := parser.ExpandIncludes(text, markdownPath)
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to expand includes: %w", err)
+		}
+		text = expanded
+	}
+
+	parts := SlideParts(text, strict)
+	slides := make([][]AIImage, len(parts))
+	for i, part := range parts {
+		slides[i] = ParseAIImages(part)
+	}
+	return New(markdownPath, slides), nil
+}
+
+// New reports the AI-generated images of each slide of the presentation in
+// markdownPath, checking which image files exist. Image paths are resolved
+// relative to the markdown file's directory.
+func New(markdownPath string, slides [][]AIImage) Report {
+	dir := filepath.Dir(markdownPath)
+
+	report := Report{MarkdownFile: markdownPath, Images: []Image{}}
+	for i, images := range slides {
+		for _, ai := range images {
+			img := Image{AIImage: ai, Slide: i + 1}
+			if IsRemote(ai.ImagePath) {
+				img.Remote = true
+			} else {
+				img.Path = filepath.Join(dir, filepath.FromSlash(ai.ImagePath))
+				_, err := os.Stat(img.Path)
+				img.Exists = !errors.Is(err, os.ErrNotExist)
+			}
+			report.Images = append(report.Images, img)
+		}
+	}
+	return report
+}
+
+// IsRemote reports whether an image path is a URL rather than a local file.
+func IsRemote(path string) bool {
+	return strings.Contains(path, "://") || strings.HasPrefix(path, "data:")
+}
+
+// Frontmatter returns the YAML frontmatter at the start of markdown content,
+// including its delimiters, or an empty string if there is none.
+func Frontmatter(content string) string {
+	return frontmatterRe.FindString(content)
+}
+
+// SlideParts returns the trimmed content of each slide in markdown content,
+// skipping empty slides like the parser does (see parser.IsEmptySlide).
+// strict is the presentation's strictDelimiters setting.
+func SlideParts(content string, strict bool) []string {
+	// Remove frontmatter if present
+	content = content[len(Frontmatter(content)):]
+
+	// Split on slide delimiter, preserving code blocks
+	var parts []string
+	for _, part := range parser.SplitSlidesPreservingCodeBlocks(content, strict) {
+		if !parser.IsEmptySlide(part) {
+			parts = append(parts, strings.TrimSpace(part))
+		}
+	}
+	return parts
+}
+
+// ParseAIImages extracts AI-generated image info from slide content.
+// It looks for <!-- ai-prompt: ... --> comments followed by image references.
+func ParseAIImages(content string) []AIImage {
+	matches := aiImageRe.FindAllStringSubmatch(content, -1)
+	if matches == nil {
+		return nil
+	}
+
+	images := make([]AIImage, 0, len(matches))
+	for _, match := range matches {
+		if len(match) >= 6 {
+			prompt, aspectRatio, imageSize := SplitPromptOptions(match[1])
+			images = append(images, AIImage{
+				Prompt:      prompt,
+				ImagePath:   match[3],
+				AltText:     match[2],
+				Caption:     strings.TrimSpace(match[5]),
+				Hint:        strings.TrimSpace(match[4]),
+				AspectRatio: aspectRatio,
+				ImageSize:   imageSize,
+			})
+		}
+	}
+
+	return images
+}
+
+// SplitPromptOptions separates the prompt text from trailing image options in
+// an ai-prompt comment. Comments without options return the prompt unchanged.
+// The provider option only records which provider generated the image, so it
+// is dropped.
+func SplitPromptOptions(raw string) (prompt, aspectRatio, imageSize string) {
+	prompt = raw
+	for {
+		match := promptOptionRe.FindStringSubmatchIndex(prompt)
+		if match == nil {
+			return prompt, aspectRatio, imageSize
+		}
+		value := prompt[match[4]:match[5]]
+		switch prompt[match[2]:match[3]] {
+		case "ratio":
+			aspectRatio = value
+		case "size":
+			imageSize = value
+		}
+		prompt = prompt[:match[0]]
+	}
+}
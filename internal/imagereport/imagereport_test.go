@@ -0,0 +1,205 @@
+package imagereport
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "images", "cat.png"), "png")
+	writeFile(t, filepath.Join(dir, "intro.md"), "# Intro\n\n<!-- ai-prompt: a sunrise | size: 2K -->\n![Sunrise](images/sunrise.png)\n")
+	mdFile := filepath.Join(dir, "slides.md")
+	writeFile(t, mdFile, `---
+title: Test
+---
+
+<!-- include: intro.md -->
+
+---
+
+# Cats
+
+<!-- ai-prompt: a cat | ratio: 1:1 -->
+![A cat](images/cat.png)
+<!-- img: {fit: cover, position: top} -->
+*Meow*
+
+---
+
+# No images
+
+---
+
+# Remote
+
+<!-- ai-prompt: a logo -->
+![Logo](https://example.com/logo.png)
+`)
+
+	report, err := Scan(mdFile)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	want := []Image{
+		{
+			AIImage: AIImage{Prompt: "a sunrise", ImagePath: "images/sunrise.png", AltText: "Sunrise", ImageSize: "2K"},
+			Slide:   1,
+			Path:    filepath.Join(dir, "images", "sunrise.png"),
+		},
+		{
+			AIImage: AIImage{Prompt: "a cat", ImagePath: "images/cat.png", AltText: "A cat", Caption: "Meow", Hint: "fit: cover, position: top", AspectRatio: "1:1"},
+			Slide:   2,
+			Path:    filepath.Join(dir, "images", "cat.png"),
+			Exists:  true,
+		},
+		{
+			AIImage: AIImage{Prompt: "a logo", ImagePath: "https://example.com/logo.png", AltText: "Logo"},
+			Slide:   4,
+			Remote:  true,
+		},
+	}
+	if report.MarkdownFile != mdFile {
+		t.Errorf("MarkdownFile = %q, want %q", report.MarkdownFile, mdFile)
+	}
+	if !reflect.DeepEqual(report.Images, want) {
+		t.Errorf("Images = %+v, want %+v", report.Images, want)
+	}
+
+	pending := report.Pending()
+	if len(pending) != 1 || pending[0].ImagePath != "images/sunrise.png" {
+		t.Errorf("Pending() = %+v, want the missing sunrise image", pending)
+	}
+	if got := report.EstimateCost(0.25); got != 0.25 {
+		t.Errorf("EstimateCost(0.25) = %v, want 0.25", got)
+	}
+}
+
+func TestScan_StrictDelimiters(t *testing.T) {
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "slides.md")
+	writeFile(t, mdFile, "---\nstrictDelimiters: true\n---\n\nIntro\n---\n\n<!-- ai-prompt: a cat -->\n![Cat](cat.png)\n\n---\n\n<!-- ai-prompt: a dog -->\n![Dog](dog.png)\n")
+
+	report, err := Scan(mdFile)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	// The --- right after "Intro" is a setext underline, not a slide break
+	var slides []int
+	for _, img := range report.Images {
+		slides = append(slides, img.Slide)
+	}
+	if !reflect.DeepEqual(slides, []int{1, 2}) {
+		t.Errorf("image slides = %v, want [1 2]", slides)
+	}
+}
+
+func TestScan_MissingFile(t *testing.T) {
+	if _, err := Scan(filepath.Join(t.TempDir(), "missing.md")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestReport_JSON(t *testing.T) {
+	report := New("/slides/deck.md", [][]AIImage{{{Prompt: "a cat", ImagePath: "cat.png"}}})
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	for _, want := range []string{`"markdownFile":"/slides/deck.md"`, `"prompt":"a cat"`, `"imagePath":"cat.png"`, `"slide":1`, `"exists":false`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON %s missing %s", data, want)
+		}
+	}
+}
+
+func TestNew_Empty(t *testing.T) {
+	report := New("deck.md", [][]AIImage{nil, nil})
+	if report.Images == nil || len(report.Images) != 0 || report.Pending() != nil {
+		t.Errorf("expected an empty report, got %+v", report)
+	}
+	if data, _ := json.Marshal(report); !strings.Contains(string(data), `"images":[]`) {
+		t.Errorf("expected an empty images list in JSON, got %s", data)
+	}
+}
+
+func TestSplitPromptOptions(t *testing.T) {
+	tests := []struct {
+		raw         string
+		prompt      string
+		aspectRatio string
+		imageSize   string
+	}{
+		{"a cat", "a cat", "", ""},
+		{"a cat | ratio: 16:9", "a cat", "16:9", ""},
+		{"a cat | ratio: 1:1 | size: 4K | provider: openai", "a cat", "1:1", "4K"},
+		{"a | b", "a | b", "", ""},
+	}
+	for _, tt := range tests {
+		prompt, aspectRatio, imageSize := SplitPromptOptions(tt.raw)
+		if prompt != tt.prompt || aspectRatio != tt.aspectRatio || imageSize != tt.imageSize {
+			t.Errorf("SplitPromptOptions(%q) = %q, %q, %q; want %q, %q, %q", tt.raw, prompt, aspectRatio, imageSize, tt.prompt, tt.aspectRatio, tt.imageSize)
+		}
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	for path, want := range map[string]bool{
+		"images/cat.png":              false,
+		"https://example.com/cat.png": true,
+		"data:image/png;base64,AAAA":  true,
+		"../shared/images/cat.png":    false,
+		"file:///Users/me/cat.png":    true,
+	} {
+		if got := IsRemote(path); got != want {
+			t.Errorf("IsRemote(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestSlideParts_MatchesParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"footnote-only slide", "# One\n\nText[^1]\n\n---\n\n# Two\n\n---\n\n[^1]: The footnote."},
+		{"footnote with continuation", "# One\n\nText[^1]\n\n---\n\n[^1]: The footnote.\n\n    More of it.\n\n---\n\n# Two"},
+		{"blank slide", "# One\n\n---\n\n   \n\n---\n\n# Two"},
+		{"footnote in code", "# One\n\n---\n\n```\n[^1]: Not a footnote.\n```"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pres, err := parser.New().Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			parts := SlideParts(tt.content, false)
+			spans := parser.SlideSpans(tt.content, false)
+			starts := parser.SlideStartLines(tt.content, false)
+			if len(parts) != len(pres.Slides) || len(spans) != len(pres.Slides) || len(starts) != len(pres.Slides) {
+				t.Errorf("Parse() = %d slides, SlideParts() = %d, SlideSpans() = %d, SlideStartLines() = %d, want the same",
+					len(pres.Slides), len(parts), len(spans), len(starts))
+			}
+		})
+	}
+}
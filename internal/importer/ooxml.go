@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxPartSize limits how much of a single part of the package is read, so a
+// corrupt or malicious file can't exhaust memory.
+const maxPartSize = 256 << 20
+
+// node is an element of an XML document. Elements are matched by their local
+// name only: the PresentationML, DrawingML, and relationship namespaces don't
+// share element names, and the prefixes vary between producers.
+type node struct {
+	name     string
+	attrs    []xml.Attr
+	children []*node
+	text     string
+}
+
+// parseXML parses an XML document into a tree of nodes and returns its root
+// element.
+func parseXML(data []byte) (*node, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	root := &node{}
+	stack := []*node{root}
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: t.Attr}
+			parent.children = append(parent.children, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			parent.text += string(t)
+		}
+	}
+
+	if len(root.children) == 0 {
+		return nil, fmt.Errorf("document has no root element")
+	}
+	return root.children[0], nil
+}
+
+// child returns the first child element with the given name, or nil.
+func (n *node) child(name string) *node {
+	if n == nil {
+		return nil
+	}
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// find follows a path of child element names and returns the element at its
+// end, or nil if any of them is missing.
+func (n *node) find(names ...string) *node {
+	for _, name := range names {
+		n = n.child(name)
+	}
+	return n
+}
+
+// all returns the child elements with the given name.
+func (n *node) all(name string) []*node {
+	if n == nil {
+		return nil
+	}
+	var matches []*node
+	for _, c := range n.children {
+		if c.name == name {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// attr returns the value of an unqualified attribute, or "".
+func (n *node) attr(name string) string {
+	if n == nil {
+		return ""
+	}
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// relAttr returns the value of a namespaced attribute, such as r:id or
+// r:embed, which refer to a relationship of the part. Elements like p:sldId
+// have both an id and an r:id attribute.
+func (n *node) relAttr(name string) string {
+	if n == nil {
+		return ""
+	}
+	for _, a := range n.attrs {
+		if a.Name.Space != "" && a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// relationship links a part of the package to another part or an external
+// resource.
+type relationship struct {
+	Type     string
+	Target   string // Part name for internal targets, or the URL for external ones
+	External bool
+}
+
+// pkg is an Office Open XML package: a zip file of XML parts and media.
+type pkg struct {
+	files map[string]*zip.File
+}
+
+// openPackage indexes the parts of a package.
+func openPackage(r *zip.Reader) *pkg {
+	p := &pkg{files: make(map[string]*zip.File)}
+	for _, f := range r.File {
+		p.files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	return p
+}
+
+// read returns the content of a part.
+func (p *pkg) read(name string) ([]byte, error) {
+	f, ok := p.files[name]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxPartSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(data) > maxPartSize {
+		return nil, fmt.Errorf("%s is too large", name)
+	}
+	return data, nil
+}
+
+// parse reads and parses an XML part.
+func (p *pkg) parse(name string) (*node, error) {
+	data, err := p.read(name)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseXML(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+	}
+	return root, nil
+}
+
+// relationships returns the relationships of a part by ID, or of the package
+// for "". A part without a relationships part has none.
+func (p *pkg) relationships(name string) (map[string]relationship, error) {
+	relsName := "_rels/.rels" // Relationships of the package itself
+	if name != "" {
+		relsName = path.Join(path.Dir(name), "_rels", path.Base(name)+".rels")
+	}
+	if _, ok := p.files[relsName]; !ok {
+		return map[string]relationship{}, nil
+	}
+	root, err := p.parse(relsName)
+	if err != nil {
+		return nil, err
+	}
+
+	rels := make(map[string]relationship)
+	for _, r := range root.all("Relationship") {
+		rel := relationship{
+			Type:     r.attr("Type"),
+			Target:   r.attr("Target"),
+			External: r.attr("TargetMode") == "External",
+		}
+		if !rel.External {
+			rel.Target = resolvePartName(name, rel.Target)
+		}
+		rels[r.attr("Id")] = rel
+	}
+	return rels, nil
+}
+
+// resolvePartName resolves a relationship target against the part that
+// contains the relationship. Targets starting with a slash are relative to
+// the package root.
+func resolvePartName(source, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(path.Clean(target), "/")
+	}
+	return path.Join(path.Dir(source), target)
+}
+
+// relType reports whether a relationship type URI is of the given kind, such
+// as "slide" or "image". The URIs differ between transitional and strict
+// documents but end the same way.
+func relType(uri, kind string) bool {
+	return strings.HasSuffix(uri, "/"+kind)
+}
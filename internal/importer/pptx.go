@@ -0,0 +1,686 @@
+// Package importer converts presentations made with other tools to Tap
+// markdown.
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/scaffold"
+	"gopkg.in/yaml.v3"
+)
+
+// aspectRatios maps the aspect ratios Tap supports to their width/height.
+var aspectRatios = []struct {
+	name  string
+	ratio float64
+}{
+	{"16:9", 16.0 / 9},
+	{"16:10", 16.0 / 10},
+	{"4:3", 4.0 / 3},
+}
+
+// FromPPTX converts a PowerPoint file to a Tap presentation in outDir and
+// returns the path of the markdown file, named after the PowerPoint file.
+// Google Slides and Keynote decks can be imported after downloading or
+// exporting them as .pptx.
+//
+// Each slide becomes a Tap slide: its title a "#" heading, its text
+// paragraphs and nested bullet lists, and its tables markdown tables. Speaker
+// notes go into a notes directive and hidden slides get hidden: true.
+// Embedded images are extracted into the images directory of outDir. Layout,
+// positions, and styling other than bold, italic, and links are not kept.
+//
+// Existing files are never overwritten: FromPPTX fails before writing
+// anything if the markdown file or a different image with the same name
+// already exists.
+func FromPPTX(path, outDir string) (string, error) {
+	r, err := zip.OpenReader(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer r.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	d, err := readPPTX(&r.Reader)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if d.title == "" {
+		d.title = name
+	}
+
+	markdownPath := filepath.Join(outDir, name+".md")
+	if _, err := os.Stat(markdownPath); err == nil {
+		return "", fmt.Errorf("%s already exists", markdownPath)
+	}
+
+	// Check every image before writing, so a conflict leaves no partial import
+	var images []image
+	for _, img := range d.images {
+		dest := filepath.Join(outDir, filepath.FromSlash(img.name))
+		existing, err := os.ReadFile(dest)
+		if err == nil {
+			if !bytes.Equal(existing, img.data) {
+				return "", fmt.Errorf("%s already exists", dest)
+			}
+			continue
+		}
+		images = append(images, image{name: dest, data: img.data})
+	}
+
+	if len(images) > 0 {
+		if err := os.MkdirAll(filepath.Join(outDir, scaffold.ImagesDir), 0755); err != nil {
+			return "", fmt.Errorf("failed to create images directory: %w", err)
+		}
+	} else if err := os.MkdirAll(outDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	for _, img := range images {
+		if err := os.WriteFile(img.name, img.data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write image: %w", err)
+		}
+	}
+
+	content, err := d.markdown()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(markdownPath, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write markdown: %w", err)
+	}
+	return markdownPath, nil
+}
+
+// deck is an imported presentation.
+type deck struct {
+	title       string
+	author      string
+	aspectRatio string
+	slides      []slide
+	images      []image
+}
+
+// slide is an imported slide.
+type slide struct {
+	title  string   // Plain text
+	blocks []string // Markdown blocks: paragraphs, lists, tables, and images
+	notes  string
+	hidden bool
+}
+
+// image is an image extracted from the package.
+type image struct {
+	name string // Path relative to the output directory
+	data []byte
+}
+
+// importer holds the state of reading a package.
+type importer struct {
+	pkg    *pkg
+	deck   *deck
+	images map[string]string // Markdown path of each extracted part
+}
+
+// readPPTX reads the slides of a PowerPoint package.
+func readPPTX(r *zip.Reader) (*deck, error) {
+	im := &importer{
+		pkg:    openPackage(r),
+		deck:   &deck{},
+		images: make(map[string]string),
+	}
+
+	rootRels, err := im.pkg.relationships("")
+	if err != nil {
+		return nil, err
+	}
+	presentationPart := ""
+	for _, rel := range rootRels {
+		switch {
+		case relType(rel.Type, "officeDocument"):
+			presentationPart = rel.Target
+		case relType(rel.Type, "core-properties"):
+			im.readCoreProperties(rel.Target)
+		}
+	}
+	if presentationPart == "" {
+		return nil, fmt.Errorf("not a PowerPoint file: no presentation part")
+	}
+
+	presentation, err := im.pkg.parse(presentationPart)
+	if err != nil {
+		return nil, err
+	}
+	if presentation.name != "presentation" {
+		return nil, fmt.Errorf("not a PowerPoint file: found %s instead of a presentation", presentation.name)
+	}
+	rels, err := im.pkg.relationships(presentationPart)
+	if err != nil {
+		return nil, err
+	}
+
+	size := presentation.child("sldSz")
+	im.deck.aspectRatio = closestAspectRatio(size.attr("cx"), size.attr("cy"))
+
+	for _, id := range presentation.find("sldIdLst").all("sldId") {
+		rel, ok := rels[id.relAttr("id")]
+		if !ok || rel.External {
+			continue
+		}
+		s, err := im.readSlide(rel.Target)
+		if err != nil {
+			return nil, err
+		}
+		im.deck.slides = append(im.deck.slides, s)
+	}
+
+	if im.deck.title == "" {
+		for _, s := range im.deck.slides {
+			if s.title != "" {
+				im.deck.title = s.title
+				break
+			}
+		}
+	}
+	return im.deck, nil
+}
+
+// readCoreProperties reads the title and author from the document
+// properties. They are optional, so errors are ignored.
+func (im *importer) readCoreProperties(part string) {
+	props, err := im.pkg.parse(part)
+	if err != nil {
+		return
+	}
+	im.deck.title = strings.TrimSpace(props.child("title").text)
+	im.deck.author = strings.TrimSpace(props.child("creator").text)
+}
+
+// readSlide reads a slide part and its speaker notes.
+func (im *importer) readSlide(part string) (slide, error) {
+	root, err := im.pkg.parse(part)
+	if err != nil {
+		return slide{}, err
+	}
+	rels, err := im.pkg.relationships(part)
+	if err != nil {
+		return slide{}, err
+	}
+
+	s := slide{hidden: root.attr("show") == "0" || root.attr("show") == "false"}
+	if err := im.readShapes(root.find("cSld", "spTree"), rels, &s); err != nil {
+		return slide{}, fmt.Errorf("failed to read %s: %w", part, err)
+	}
+
+	for _, rel := range rels {
+		if relType(rel.Type, "notesSlide") && !rel.External {
+			s.notes, err = im.readNotes(rel.Target)
+			if err != nil {
+				return slide{}, err
+			}
+		}
+	}
+	return s, nil
+}
+
+// readShapes adds the content of the shapes in a shape tree or group to s, in
+// document order. The first title placeholder becomes the slide title.
+func (im *importer) readShapes(tree *node, rels map[string]relationship, s *slide) error {
+	for _, shape := range tree.children {
+		switch shape.name {
+		case "sp":
+			im.readTextShape(shape, rels, s)
+
+		case "pic":
+			block, err := im.readPicture(shape, rels)
+			if err != nil {
+				return err
+			}
+			if block != "" {
+				s.blocks = append(s.blocks, block)
+			}
+
+		case "graphicFrame":
+			if table := shape.find("graphic", "graphicData", "tbl"); table != nil {
+				if block := tableMarkdown(table, rels); block != "" {
+					s.blocks = append(s.blocks, block)
+				}
+			}
+
+		case "grpSp":
+			if err := im.readShapes(shape, rels, s); err != nil {
+				return err
+			}
+
+		case "AlternateContent":
+			// Newer content comes with a fallback in shapes every reader
+			// understands
+			if err := im.readShapes(shape.child("Fallback"), rels, s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// readTextShape adds the text of a shape to s.
+func (im *importer) readTextShape(shape *node, rels map[string]relationship, s *slide) {
+	placeholder := shape.find("nvSpPr", "nvPr", "ph")
+	body := shape.child("txBody")
+
+	// Body placeholders are bulleted unless a paragraph turns it off; other
+	// shapes only where a paragraph turns it on
+	bulleted := false
+	if placeholder != nil {
+		switch placeholder.attr("type") {
+		case "title", "ctrTitle":
+			title := strings.Join(strings.Fields(plainText(body)), " ")
+			if s.title == "" {
+				s.title = title
+				return
+			}
+			if title != "" {
+				s.blocks = append(s.blocks, "**"+escapeInline(title)+"**")
+			}
+			return
+		case "dt", "ftr", "sldNum", "hdr":
+			return
+		case "", "body", "obj":
+			bulleted = true
+		}
+	}
+
+	s.blocks = append(s.blocks, textBlocks(body, rels, bulleted)...)
+}
+
+// readPicture extracts the image of a picture shape and returns its markdown,
+// using the picture's description as alt text. Linked images are skipped.
+func (im *importer) readPicture(shape *node, rels map[string]relationship) (string, error) {
+	rel, ok := rels[shape.find("blipFill", "blip").relAttr("embed")]
+	if !ok || rel.External {
+		return "", nil
+	}
+	ref, err := im.extractImage(rel.Target)
+	if err != nil {
+		return "", err
+	}
+	alt := strings.Join(strings.Fields(shape.find("nvPicPr", "cNvPr").attr("descr")), " ")
+	return fmt.Sprintf("![%s](%s)", escapeInline(alt), ref), nil
+}
+
+// extractImage adds an image part to the deck, once however many slides use
+// it, and returns its path relative to the markdown file.
+func (im *importer) extractImage(part string) (string, error) {
+	if ref, ok := im.images[part]; ok {
+		return ref, nil
+	}
+	data, err := im.pkg.read(part)
+	if err != nil {
+		return "", err
+	}
+
+	// Media parts have unique names in practice, but they could be in
+	// different directories
+	base := path.Base(part)
+	ext := path.Ext(base)
+	ref := path.Join(scaffold.ImagesDir, base)
+	for i := 2; im.usedImage(ref); i++ {
+		ref = path.Join(scaffold.ImagesDir, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(base, ext), i, ext))
+	}
+
+	im.images[part] = ref
+	im.deck.images = append(im.deck.images, image{name: ref, data: data})
+	return ref, nil
+}
+
+// usedImage reports whether an image path is taken.
+func (im *importer) usedImage(ref string) bool {
+	for _, img := range im.deck.images {
+		if img.name == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// readNotes returns the text of a notes slide: the text of its body
+// placeholder, one line per paragraph.
+func (im *importer) readNotes(part string) (string, error) {
+	root, err := im.pkg.parse(part)
+	if err != nil {
+		return "", err
+	}
+	for _, shape := range root.find("cSld", "spTree").all("sp") {
+		if shape.find("nvSpPr", "nvPr", "ph").attr("type") == "body" {
+			return strings.TrimSpace(plainText(shape.child("txBody"))), nil
+		}
+	}
+	return "", nil
+}
+
+// listItem is a paragraph of a list.
+type listItem struct {
+	level    int
+	numbered bool
+	text     string
+}
+
+// textBlocks converts the paragraphs of a text body to markdown blocks.
+// Consecutive list paragraphs form one list, nested by their level; an empty
+// paragraph ends it.
+func textBlocks(body *node, rels map[string]relationship, bulleted bool) []string {
+	var blocks []string
+	var items []listItem
+	endList := func() {
+		if len(items) > 0 {
+			blocks = append(blocks, listMarkdown(items))
+			items = nil
+		}
+	}
+
+	for _, p := range body.all("p") {
+		text := escapeLineStart(paragraphMarkdown(p, rels))
+		if text == "" {
+			endList()
+			continue
+		}
+
+		props := p.child("pPr")
+		numbered := props.child("buAutoNum") != nil
+		isItem := numbered || props.child("buChar") != nil || (bulleted && props.child("buNone") == nil)
+		if !isItem {
+			endList()
+			blocks = append(blocks, text)
+			continue
+		}
+
+		level, _ := strconv.Atoi(props.attr("lvl"))
+		items = append(items, listItem{level: level, numbered: numbered, text: text})
+	}
+	endList()
+	return blocks
+}
+
+// listMarkdown returns the markdown of a list. Each level is indented to the
+// content of the item above it, so nesting works with both list markers.
+func listMarkdown(items []listItem) string {
+	var lines []string
+	var indents []int // Content indent of the last item at each level
+	for _, item := range items {
+		level := min(item.level, len(indents))
+		indents = indents[:level]
+
+		indent := 0
+		if level > 0 {
+			indent = indents[level-1]
+		}
+		marker := "- "
+		if item.numbered {
+			marker = "1. "
+		}
+		lines = append(lines, strings.Repeat(" ", indent)+marker+item.text)
+		indents = append(indents, indent+len(marker))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableMarkdown converts a table to a markdown table with its first row as
+// the header.
+func tableMarkdown(table *node, rels map[string]relationship) string {
+	var rows [][]string
+	columns := 0
+	for _, tr := range table.all("tr") {
+		var cells []string
+		for _, tc := range tr.all("tc") {
+			var parts []string
+			for _, p := range tc.find("txBody").all("p") {
+				if text := paragraphMarkdown(p, rels); text != "" {
+					parts = append(parts, text)
+				}
+			}
+			cells = append(cells, strings.ReplaceAll(strings.Join(parts, " "), "|", `\|`))
+		}
+		rows = append(rows, cells)
+		columns = max(columns, len(cells))
+	}
+	if len(rows) == 0 || columns == 0 {
+		return ""
+	}
+
+	var lines []string
+	for i, cells := range rows {
+		for len(cells) < columns {
+			cells = append(cells, "")
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+		if i == 0 {
+			lines = append(lines, "|"+strings.Repeat(" --- |", columns))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// run is a span of text with the same formatting.
+type run struct {
+	text   string
+	bold   bool
+	italic bool
+	link   string
+}
+
+// paragraphMarkdown returns the text of a paragraph as inline markdown, with
+// bold, italic, and hyperlinked runs marked up. Line breaks become spaces.
+func paragraphMarkdown(p *node, rels map[string]relationship) string {
+	var runs []run
+	for _, child := range p.children {
+		var r run
+		switch child.name {
+		case "r", "fld":
+			props := child.child("rPr")
+			r = run{
+				text:   child.child("t").text,
+				bold:   isTrue(props.attr("b")),
+				italic: isTrue(props.attr("i")),
+			}
+			if rel, ok := rels[props.child("hlinkClick").relAttr("id")]; ok && rel.External {
+				r.link = rel.Target
+			}
+		case "br":
+			r = run{text: " "}
+		default:
+			continue
+		}
+
+		// Merge runs with the same formatting, which PowerPoint splits for
+		// spelling marks and edits
+		if n := len(runs); n > 0 && runs[n-1].bold == r.bold && runs[n-1].italic == r.italic && runs[n-1].link == r.link {
+			runs[n-1].text += r.text
+			continue
+		}
+		runs = append(runs, r)
+	}
+
+	var b strings.Builder
+	for _, r := range runs {
+		b.WriteString(r.markdown())
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// markdown returns the run as inline markdown. Emphasis markers go inside
+// the surrounding spaces, since "** text**" is not bold.
+func (r run) markdown() string {
+	text := strings.TrimSpace(r.text)
+	if text == "" {
+		return r.text
+	}
+	text = escapeInline(text)
+	if r.italic {
+		text = "*" + text + "*"
+	}
+	if r.bold {
+		text = "**" + text + "**"
+	}
+	if r.link != "" {
+		text = "[" + text + "](" + r.link + ")"
+	}
+
+	leading := r.text[:len(r.text)-len(strings.TrimLeft(r.text, " \t"))]
+	trailing := r.text[len(strings.TrimRight(r.text, " \t")):]
+	return leading + text + trailing
+}
+
+// plainText returns the text of a text body, one line per paragraph.
+func plainText(body *node) string {
+	var lines []string
+	for _, p := range body.all("p") {
+		var b strings.Builder
+		for _, child := range p.children {
+			switch child.name {
+			case "r", "fld":
+				b.WriteString(child.child("t").text)
+			case "br":
+				b.WriteString("\n")
+			}
+		}
+		lines = append(lines, strings.TrimRight(b.String(), " \t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isTrue reports whether an XML boolean attribute is true.
+func isTrue(value string) bool {
+	return value == "1" || value == "true"
+}
+
+// inlineEscaper escapes the characters that would start inline markdown or
+// HTML.
+var inlineEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+)
+
+// escapeInline escapes text so it renders as is in markdown.
+func escapeInline(text string) string {
+	return inlineEscaper.Replace(text)
+}
+
+// escapeLineStart escapes a line that would otherwise start a markdown block,
+// such as a heading or a list, or a Tap slide separator, column separator,
+// container, or speaker notes marker.
+func escapeLineStart(line string) string {
+	if line == "" {
+		return line
+	}
+	if strings.ContainsRune("#>-+|=:?~", rune(line[0])) {
+		return `\` + line
+	}
+
+	digits := len(line) - len(strings.TrimLeft(line, "0123456789"))
+	if digits > 0 && digits < len(line) && (line[digits] == '.' || line[digits] == ')') {
+		return line[:digits] + `\` + line[digits:]
+	}
+	return line
+}
+
+// closestAspectRatio returns the Tap aspect ratio of a slide size in EMUs,
+// or "" if it isn't close to one.
+func closestAspectRatio(cx, cy string) string {
+	width, errW := strconv.ParseFloat(cx, 64)
+	height, errH := strconv.ParseFloat(cy, 64)
+	if errW != nil || errH != nil || width <= 0 || height <= 0 {
+		return ""
+	}
+	for _, candidate := range aspectRatios {
+		if math.Abs(width/height-candidate.ratio) < 0.02 {
+			return candidate.name
+		}
+	}
+	return ""
+}
+
+// frontmatter is the frontmatter of an imported presentation.
+type frontmatter struct {
+	Title       string `yaml:"title"`
+	Author      string `yaml:"author,omitempty"`
+	AspectRatio string `yaml:"aspectRatio,omitempty"`
+}
+
+// directives are the slide directives of an imported slide.
+type directives struct {
+	Hidden bool   `yaml:"hidden,omitempty"`
+	Notes  string `yaml:"notes,omitempty"`
+}
+
+// markdown returns the deck as a Tap presentation.
+func (d *deck) markdown() (string, error) {
+	front, err := marshalYAML(frontmatter{Title: d.title, Author: d.author, AspectRatio: d.aspectRatio})
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("---\n" + front + "---\n")
+	for i, s := range d.slides {
+		if i > 0 {
+			b.WriteString("\n---\n")
+		}
+		content, err := s.markdown()
+		if err != nil {
+			return "", err
+		}
+		if content != "" {
+			b.WriteString("\n" + content + "\n")
+		}
+	}
+	return b.String(), nil
+}
+
+// markdown returns the slide's directive block and content.
+func (s slide) markdown() (string, error) {
+	var parts []string
+	if s.notes != "" || s.hidden {
+		// Trailing spaces would force a quoted string instead of a block,
+		// and "-->" would end the comment early
+		lines := strings.Split(strings.ReplaceAll(s.notes, "-->", "-- >"), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(line, " \t")
+		}
+		block, err := marshalYAML(directives{Hidden: s.hidden, Notes: strings.Join(lines, "\n")})
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "<!--\n"+block+"-->")
+	}
+	if s.title != "" {
+		parts = append(parts, "# "+escapeInline(s.title))
+	}
+	parts = append(parts, s.blocks...)
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// marshalYAML encodes v as YAML indented by two spaces.
+func marshalYAML(v any) (string, error) {
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return "", fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return b.String(), nil
+}
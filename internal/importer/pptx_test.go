@@ -0,0 +1,221 @@
+package importer
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testdata/sample.pptx has three slides, listed out of part order in the
+// presentation: a title slide with speaker notes; a slide with formatted
+// bullets, a link, a numbered list in a group, and a picture; and a hidden
+// slide with a table and the same picture.
+const samplePPTX = "testdata/sample.pptx"
+
+const wantSampleMarkdown = `---
+title: Migrating Old Decks
+author: Ada Lovelace
+aspectRatio: "16:9"
+---
+
+<!--
+notes: |-
+  Welcome everyone.
+
+  Ask who has migrated a deck before.
+-->
+
+# Migrating Old Decks
+
+A field guide
+
+---
+
+# Why Tap?
+
+- Plain text *source*
+  - Diffable
+  - Reviewable
+- Docs: [tap.dev](https://tap.dev)
+
+Closing thought
+
+1. Step one
+1. Step two
+
+![A red square](images/image1.png)
+
+---
+
+<!--
+hidden: true
+-->
+
+# Backup slide
+
+| Format | Diffable |
+| --- | --- |
+| pptx | No |
+| md | Yes |
+
+![](images/image1.png)
+`
+
+func TestFromPPTX(t *testing.T) {
+	outDir := filepath.Join(t.TempDir(), "talk")
+
+	markdownPath, err := FromPPTX(samplePPTX, outDir)
+	if err != nil {
+		t.Fatalf("FromPPTX() error = %v", err)
+	}
+	if want := filepath.Join(outDir, "sample.md"); markdownPath != want {
+		t.Errorf("FromPPTX() = %q, want %q", markdownPath, want)
+	}
+
+	content, err := os.ReadFile(markdownPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != wantSampleMarkdown {
+		t.Errorf("unexpected markdown:\n%s\nwant:\n%s", content, wantSampleMarkdown)
+	}
+
+	// The picture used on two slides is extracted once
+	entries, err := os.ReadDir(filepath.Join(outDir, "images"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != "image1.png" {
+		t.Errorf("expected images/image1.png, got %v", entries)
+	}
+}
+
+func TestFromPPTX_DoesNotOverwrite(t *testing.T) {
+	outDir := t.TempDir()
+	if _, err := FromPPTX(samplePPTX, outDir); err != nil {
+		t.Fatalf("FromPPTX() error = %v", err)
+	}
+
+	_, err := FromPPTX(samplePPTX, outDir)
+	if err == nil || !strings.Contains(err.Error(), "sample.md already exists") {
+		t.Errorf("expected the existing markdown to be an error, got %v", err)
+	}
+
+	// A different image with the same name stops the import before the
+	// markdown is written
+	if err := os.Remove(filepath.Join(outDir, "sample.md")); err != nil {
+		t.Fatal(err)
+	}
+	imagePath := filepath.Join(outDir, "images", "image1.png")
+	if err := os.WriteFile(imagePath, []byte("my own image"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err = FromPPTX(samplePPTX, outDir)
+	if err == nil || !strings.Contains(err.Error(), "image1.png already exists") {
+		t.Errorf("expected the existing image to be an error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "sample.md")); !os.IsNotExist(err) {
+		t.Error("expected no markdown to be written")
+	}
+	if data, _ := os.ReadFile(imagePath); string(data) != "my own image" {
+		t.Error("expected the existing image to be kept")
+	}
+}
+
+func TestFromPPTX_NotPowerPoint(t *testing.T) {
+	dir := t.TempDir()
+
+	text := filepath.Join(dir, "notes.pptx")
+	if err := os.WriteFile(text, []byte("not a zip file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromPPTX(text, dir); err == nil || !strings.Contains(err.Error(), "failed to open") {
+		t.Errorf("expected an error for a file that isn't a zip file, got %v", err)
+	}
+
+	// A zip file without a presentation, such as a Word document
+	archive := filepath.Join(dir, "archive.pptx")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(f)
+	if _, err := w.Create("readme.txt"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	f.Close()
+	if _, err := FromPPTX(archive, dir); err == nil || !strings.Contains(err.Error(), "not a PowerPoint file") {
+		t.Errorf("expected an error for a zip file without a presentation, got %v", err)
+	}
+}
+
+func TestListMarkdown(t *testing.T) {
+	got := listMarkdown([]listItem{
+		{level: 0, numbered: true, text: "First"},
+		{level: 1, text: "Detail"},
+		{level: 3, text: "Skipped a level"},
+		{level: 0, numbered: true, text: "Second"},
+	})
+	want := "1. First\n   - Detail\n     - Skipped a level\n1. Second"
+	if got != want {
+		t.Errorf("listMarkdown() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestEscapeLineStart(t *testing.T) {
+	tests := map[string]string{
+		"# Not a heading":  `\# Not a heading`,
+		"---":              `\---`,
+		"|||":              `\|||`,
+		"???":              `\???`,
+		"2024. A year":     `2024\. A year`,
+		"3) Three":         `3\) Three`,
+		"42 is the answer": "42 is the answer",
+		"Plain text":       "Plain text",
+	}
+	for line, want := range tests {
+		if got := escapeLineStart(line); got != want {
+			t.Errorf("escapeLineStart(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestClosestAspectRatio(t *testing.T) {
+	tests := []struct {
+		cx, cy string
+		want   string
+	}{
+		{"12192000", "6858000", "16:9"},
+		{"9144000", "5143500", "16:9"},
+		{"9144000", "5715000", "16:10"},
+		{"9144000", "6858000", "4:3"},
+		{"6858000", "9144000", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := closestAspectRatio(tt.cx, tt.cy); got != tt.want {
+			t.Errorf("closestAspectRatio(%q, %q) = %q, want %q", tt.cx, tt.cy, got, tt.want)
+		}
+	}
+}
+
+func TestRunMarkdown(t *testing.T) {
+	tests := []struct {
+		run  run
+		want string
+	}{
+		{run{text: "plain"}, "plain"},
+		{run{text: " bold ", bold: true}, " **bold** "},
+		{run{text: "both", bold: true, italic: true}, "***both***"},
+		{run{text: "a_b*c", link: "https://example.com"}, `[a\_b\*c](https://example.com)`},
+		{run{text: " ", bold: true}, " "},
+	}
+	for _, tt := range tests {
+		if got := tt.run.markdown(); got != tt.want {
+			t.Errorf("%+v.markdown() = %q, want %q", tt.run, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,219 @@
+// Package notes exports the speaker notes of a presentation as markdown or
+// plain text. Unlike the PDF export, it works on the parsed presentation and
+// doesn't need a browser.
+package notes
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// Format is the output format of a notes export.
+type Format string
+
+const (
+	// FormatMarkdown keeps the markdown formatting of the notes.
+	FormatMarkdown Format = "md"
+	// FormatText strips the markdown formatting, for teleprompters and the like.
+	FormatText Format = "txt"
+)
+
+// Placeholders written for slides without notes.
+const (
+	markdownPlaceholder = "_No speaker notes._"
+	textPlaceholder     = "(No speaker notes)"
+)
+
+// Options configures a notes export.
+type Options struct {
+	// Format is the output format. Defaults to FormatMarkdown.
+	Format Format
+	// Title is written at the top of the export, if set.
+	Title string
+	// Slides are the zero-based indices of the slides to export, in order.
+	// Nil exports all slides. Hidden slides are never exported, like in PDFs.
+	Slides []int
+}
+
+// ValidateFormat checks that a format string is a valid Format.
+func ValidateFormat(s string) (Format, error) {
+	switch Format(s) {
+	case FormatMarkdown, FormatText:
+		return Format(s), nil
+	default:
+		return "", fmt.Errorf("invalid notes format %q: must be md or txt", s)
+	}
+}
+
+// Export writes one section per slide with the slide number, its title, and
+// its speaker notes, from the notes directive and the notes after ???.
+// Slides without notes get a placeholder line.
+func Export(pres *parser.Presentation, opts Options) ([]byte, error) {
+	if opts.Format == "" {
+		opts.Format = FormatMarkdown
+	}
+	if _, err := ValidateFormat(string(opts.Format)); err != nil {
+		return nil, err
+	}
+
+	indices := opts.Slides
+	if indices == nil {
+		indices = make([]int, len(pres.Slides))
+		for i := range pres.Slides {
+			indices[i] = i
+		}
+	}
+
+	var sections []string
+	if opts.Title != "" {
+		if opts.Format == FormatMarkdown {
+			sections = append(sections, "# "+opts.Title)
+		} else {
+			sections = append(sections, opts.Title+"\n"+strings.Repeat("=", len([]rune(opts.Title))))
+		}
+	}
+
+	for _, i := range indices {
+		if i < 0 || i >= len(pres.Slides) {
+			return nil, fmt.Errorf("slide %d does not exist", i+1)
+		}
+		if pres.Slides[i].Directives.Hidden {
+			continue
+		}
+		sections = append(sections, formatSlide(pres.Slides[i], i+1, opts.Format))
+	}
+
+	return []byte(strings.Join(sections, "\n\n") + "\n"), nil
+}
+
+// formatSlide formats the notes section of a slide.
+func formatSlide(slide parser.Slide, number int, format Format) string {
+	heading := "Slide " + strconv.Itoa(number)
+	if title := parser.SlideTitle(slide.HTML); title != "" {
+		heading += ": " + title
+	}
+
+	notes := strings.TrimSpace(slide.Directives.Notes)
+	if format == FormatMarkdown {
+		if notes == "" {
+			notes = markdownPlaceholder
+		}
+		return "## " + heading + "\n\n" + notes
+	}
+
+	if notes != "" {
+		notes = StripMarkdown(notes)
+	}
+	if notes == "" {
+		notes = textPlaceholder
+	}
+	return heading + "\n" + strings.Repeat("-", len([]rune(heading))) + "\n\n" + notes
+}
+
+// md parses notes for StripMarkdown.
+var md = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// StripMarkdown reduces markdown to plain text: emphasis, links, and code
+// markers are removed, list items keep a "- " or "1. " marker, and code
+// blocks keep their lines. Blocks are separated by a blank line.
+func StripMarkdown(source string) string {
+	src := []byte(source)
+	doc := md.Parser().Parse(text.NewReader(src))
+
+	var b strings.Builder
+	writeBlocks(&b, doc, src, "")
+	return strings.TrimSpace(b.String())
+}
+
+// writeBlocks writes the text of the block children of n, each line indented
+// by indent.
+func writeBlocks(b *strings.Builder, n ast.Node, src []byte, indent string) {
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch node := child.(type) {
+		case *ast.List:
+			number := node.Start
+			for item := node.FirstChild(); item != nil; item = item.NextSibling() {
+				marker := "- "
+				if node.IsOrdered() {
+					marker = strconv.Itoa(number) + ". "
+					number++
+				}
+				var itemText strings.Builder
+				writeBlocks(&itemText, item, src, "")
+				writeLines(b, strings.TrimSpace(itemText.String()), indent+marker, indent+strings.Repeat(" ", len(marker)))
+			}
+			b.WriteString("\n")
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			var code bytes.Buffer
+			lines := node.Lines()
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				code.Write(segment.Value(src))
+			}
+			writeLines(b, strings.TrimRight(code.String(), "\n"), indent, indent)
+			b.WriteString("\n")
+		case *ast.Blockquote:
+			writeBlocks(b, node, src, indent)
+		case *ast.HTMLBlock, *ast.ThematicBreak:
+			// Not text
+		default:
+			if node.Type() == ast.TypeBlock && node.FirstChild() != nil && node.FirstChild().Type() == ast.TypeInline {
+				var line strings.Builder
+				writeInline(&line, node, src)
+				writeLines(b, strings.TrimSpace(line.String()), indent, indent)
+				if node.Kind() != ast.KindTextBlock {
+					b.WriteString("\n")
+				}
+			} else {
+				writeBlocks(b, node, src, indent)
+			}
+		}
+	}
+}
+
+// writeLines writes content line by line, prefixing the first line with
+// first and the others with rest.
+func writeLines(b *strings.Builder, content, first, rest string) {
+	if content == "" {
+		return
+	}
+	for i, line := range strings.Split(content, "\n") {
+		if i == 0 {
+			b.WriteString(first)
+		} else if line != "" {
+			b.WriteString(rest)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+// writeInline writes the text of the inline children of n.
+func writeInline(b *strings.Builder, n ast.Node, src []byte) {
+	for child := n.FirstChild(); child != nil; child = child.NextSibling() {
+		switch node := child.(type) {
+		case *ast.Text:
+			b.Write(node.Segment.Value(src))
+			if node.SoftLineBreak() || node.HardLineBreak() {
+				b.WriteString("\n")
+			}
+		case *ast.String:
+			b.Write(node.Value)
+		case *ast.AutoLink:
+			b.Write(node.URL(src))
+		case *ast.RawHTML:
+			// Not text
+		default:
+			writeInline(b, node, src)
+		}
+	}
+}
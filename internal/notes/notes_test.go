@@ -0,0 +1,161 @@
+package notes
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+const testPresentation = `<!--
+notes: |
+  Say **hello** and introduce [the team](https://example.com).
+-->
+
+# Welcome
+
+---
+
+# No Notes
+
+Nothing to say here.
+
+---
+
+<!-- hidden: true -->
+
+# Backup
+
+???
+Only if asked.
+
+---
+
+## Results
+
+- Faster
+- Cheaper
+
+???
+- Mention the *benchmark*
+- Pause for questions
+`
+
+func parsePresentation(t *testing.T) *parser.Presentation {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(testPresentation))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	return pres
+}
+
+func TestExport_Markdown(t *testing.T) {
+	got, err := Export(parsePresentation(t), Options{Format: FormatMarkdown, Title: "My Talk"})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := `# My Talk
+
+## Slide 1: Welcome
+
+Say **hello** and introduce [the team](https://example.com).
+
+## Slide 2: No Notes
+
+_No speaker notes._
+
+## Slide 4: Results
+
+- Mention the *benchmark*
+- Pause for questions
+`
+	if string(got) != want {
+		t.Errorf("Export() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExport_Text(t *testing.T) {
+	got, err := Export(parsePresentation(t), Options{Format: FormatText})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := `Slide 1: Welcome
+----------------
+
+Say hello and introduce the team.
+
+Slide 2: No Notes
+-----------------
+
+(No speaker notes)
+
+Slide 4: Results
+----------------
+
+- Mention the benchmark
+- Pause for questions
+`
+	if string(got) != want {
+		t.Errorf("Export() =\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExport_SelectedSlides(t *testing.T) {
+	// Hidden slides are left out even if they are selected
+	got, err := Export(parsePresentation(t), Options{Slides: []int{2, 3}})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if !strings.HasPrefix(string(got), "## Slide 4: Results\n") || strings.Contains(string(got), "Only if asked.") {
+		t.Errorf("Export() = %q", got)
+	}
+
+	if _, err := Export(parsePresentation(t), Options{Slides: []int{9}}); err == nil {
+		t.Error("expected an error for a slide that doesn't exist")
+	}
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range []string{"md", "txt"} {
+		if _, err := ValidateFormat(format); err != nil {
+			t.Errorf("ValidateFormat(%q) error = %v", format, err)
+		}
+	}
+	if _, err := ValidateFormat("pdf"); err == nil {
+		t.Error("ValidateFormat(pdf) should return an error")
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"emphasis and code", "Say **hello**, _wave_, and run `tap dev`.", "Say hello, wave, and run tap dev."},
+		{"links and images", "See [the docs](https://example.com) ![logo](logo.png) <https://tap.dev>", "See the docs logo https://tap.dev"},
+		{"line breaks", "First line\nsecond line", "First line\nsecond line"},
+		{"paragraphs", "One.\n\nTwo.", "One.\n\nTwo."},
+		{"lists", "- one\n- two\n  - nested\n\n3. three\n4. four", "- one\n- two\n  - nested\n\n3. three\n4. four"},
+		{"headings and quotes", "# Title\n\n> Quoted **text**", "Title\n\nQuoted text"},
+		{"code block", "```go\nfmt.Println(\"hi\")\n```", "fmt.Println(\"hi\")"},
+		{"html", "<div>\nhidden\n</div>\n\nShown <b>bold</b>", "Shown bold"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripMarkdown(tt.input); got != tt.want {
+				t.Errorf("StripMarkdown() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSlide_Untitled(t *testing.T) {
+	if !strings.HasPrefix(formatSlide(parser.Slide{HTML: "<p>x</p>"}, 7, FormatText), "Slide 7\n-------\n") {
+		t.Error("slides without a heading should be titled by number")
+	}
+}
@@ -259,7 +259,7 @@ func BenchmarkParseFragments(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		p.parseFragments(content)
+		p.parseFragments(content, nil)
 	}
 }
 
@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Container is a :::name ... ::: container block in a slide, such as a
+// :::warning callout or a :::columns group.
+type Container struct {
+	// Name is the lowercase name after the opening :::.
+	Name string
+	// Content is the raw markdown between the fences.
+	Content string
+	// Depth is the nesting level: 0 for top-level containers, 1 for
+	// containers inside one (such as a column in columns), and so on.
+	Depth int
+}
+
+// admonitionNames are the container names rendered as callouts, with the
+// admonition class in addition to their name.
+var admonitionNames = map[string]bool{
+	"note":      true,
+	"tip":       true,
+	"info":      true,
+	"important": true,
+	"warning":   true,
+	"caution":   true,
+	"danger":    true,
+}
+
+// containerOpenPattern matches the opening fence of a container block.
+var containerOpenPattern = regexp.MustCompile(`^\s*:::\s*([A-Za-z][\w-]*)\s*$`)
+
+// containerClosePattern matches the closing fence of a container block.
+var containerClosePattern = regexp.MustCompile(`^\s*:::\s*$`)
+
+// containerClass returns the CSS class of the div a container is rendered as.
+func containerClass(name string) string {
+	if admonitionNames[name] {
+		return "admonition " + name
+	}
+	return name
+}
+
+// transformContainerBlocks replaces the fences of :::name ... ::: container
+// blocks with wrapper divs, so the markdown inside them is still rendered, and
+// returns the containers found. Fences inside code blocks are ignored.
+// If a container is never closed, it and the rest of the slide are left as
+// plain content; a closing fence without an opening one is also left as is.
+func transformContainerBlocks(content string) (string, []Container) {
+	if !strings.Contains(content, ":::") {
+		return content, nil
+	}
+
+	lines := strings.Split(content, "\n")
+
+	// Match opening and closing fences
+	closeOf := make(map[int]int)
+	var open []int
+	insideCodeBlock := false
+	fenceLength := 0
+	for i, line := range lines {
+		wasInsideCodeBlock := insideCodeBlock
+		insideCodeBlock, fenceLength = UpdateCodeFence(line, insideCodeBlock, fenceLength)
+		if wasInsideCodeBlock || insideCodeBlock {
+			continue
+		}
+
+		if containerOpenPattern.MatchString(line) {
+			open = append(open, i)
+		} else if containerClosePattern.MatchString(line) && len(open) > 0 {
+			closeOf[open[len(open)-1]] = i
+			open = open[:len(open)-1]
+		}
+	}
+
+	// Everything from the first unclosed container on is plain content
+	end := len(lines)
+	if len(open) > 0 {
+		end = open[0]
+	}
+
+	var containers []Container
+	var out []string
+	isClose := make(map[int]bool)
+	depth := 0
+	for i, line := range lines {
+		if i >= end {
+			out = append(out, lines[i:]...)
+			break
+		}
+
+		if closeLine, ok := closeOf[i]; ok {
+			name := strings.ToLower(containerOpenPattern.FindStringSubmatch(line)[1])
+			containers = append(containers, Container{
+				Name:    name,
+				Content: strings.Join(lines[i+1:closeLine], "\n"),
+				Depth:   depth,
+			})
+			isClose[closeLine] = true
+			depth++
+			// Blank lines end the HTML block so the content is parsed as markdown
+			out = append(out, "", `<div class="`+containerClass(name)+`">`, "")
+			continue
+		}
+
+		if isClose[i] {
+			depth--
+			out = append(out, "", "</div>", "")
+			continue
+		}
+
+		out = append(out, line)
+	}
+
+	return strings.Join(out, "\n"), containers
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransformContainerBlocks(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantHTML []string
+		wantNot  []string
+		want     []Container
+	}{
+		{
+			name:     "admonition",
+			input:    ":::warning\nDon't do this **live**\n:::",
+			wantHTML: []string{`<div class="admonition warning">`, "<strong>live</strong>", "</div>"},
+			want:     []Container{{Name: "warning", Content: "Don't do this **live**"}},
+		},
+		{
+			name:     "columns with nested columns",
+			input:    ":::columns\n:::column\nLeft\n:::\n:::column\n- Right\n:::\n:::",
+			wantHTML: []string{`<div class="columns">`, `<div class="column">` + "\n<p>Left</p>", "<li>Right</li>"},
+			want: []Container{
+				{Name: "columns", Content: ":::column\nLeft\n:::\n:::column\n- Right\n:::"},
+				{Name: "column", Content: "Left", Depth: 1},
+				{Name: "column", Content: "- Right", Depth: 1},
+			},
+		},
+		{
+			name:     "unknown name",
+			input:    ":::Summary\nText\n:::",
+			wantHTML: []string{`<div class="summary">`},
+			want:     []Container{{Name: "summary", Content: "Text"}},
+		},
+		{
+			name:     "fence inside code block",
+			input:    "```\n:::note\n```",
+			wantHTML: []string{":::note"},
+			wantNot:  []string{"<div"},
+		},
+		{
+			name:     "unclosed container",
+			input:    ":::tip\nClosed\n:::\n\n:::note\nNever closed\n\n:::columns",
+			wantHTML: []string{`<div class="admonition tip">`, ":::note", "Never closed", ":::columns"},
+			wantNot:  []string{`class="admonition note"`, `class="columns"`},
+			want:     []Container{{Name: "tip", Content: "Closed"}},
+		},
+		{
+			name:     "stray closing fence",
+			input:    "Text\n\n:::",
+			wantHTML: []string{"<p>Text</p>", "<p>:::</p>"},
+			wantNot:  []string{"<div"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pres, err := New().Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			slide := pres.Slides[0]
+
+			for _, want := range tt.wantHTML {
+				if !strings.Contains(slide.HTML, want) {
+					t.Errorf("HTML should contain %q, got:\n%s", want, slide.HTML)
+				}
+			}
+			for _, notWant := range tt.wantNot {
+				if strings.Contains(slide.HTML, notWant) {
+					t.Errorf("HTML should not contain %q, got:\n%s", notWant, slide.HTML)
+				}
+			}
+
+			if len(slide.Containers) != len(tt.want) {
+				t.Fatalf("Containers = %+v, want %+v", slide.Containers, tt.want)
+			}
+			for i, want := range tt.want {
+				if slide.Containers[i] != want {
+					t.Errorf("Containers[%d] = %+v, want %+v", i, slide.Containers[i], want)
+				}
+			}
+			if slide.Content != tt.input {
+				t.Errorf("Content should keep the container fences, got %q", slide.Content)
+			}
+		})
+	}
+}
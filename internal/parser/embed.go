@@ -0,0 +1,222 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Embed is a web page shown in an iframe on a slide, such as a live demo,
+// set by an embed comment or an embed code block.
+type Embed struct {
+	// URL is the address of the page.
+	URL string
+	// Height is the CSS height of the iframe, such as "90%" or "600px", or
+	// empty for the full height of the slide.
+	Height string
+	// AllowFullscreen lets the page switch to fullscreen.
+	AllowFullscreen bool
+	// Sandbox holds the iframe's sandbox flags, DefaultEmbedSandbox unless
+	// the embed sets its own. An empty list applies every restriction.
+	Sandbox []string
+
+	// err is the error found while reading the embed, reported by Validate.
+	err error
+}
+
+// DefaultEmbedSandbox are the sandbox flags of embeds that don't set their
+// own: enough for an interactive demo, without letting it navigate the
+// presentation away or open popups.
+var DefaultEmbedSandbox = []string{"allow-scripts", "allow-same-origin", "allow-forms"}
+
+// embedSandboxFlags are the sandbox flags an embed can set.
+var embedSandboxFlags = map[string]bool{
+	"allow-downloads":                         true,
+	"allow-forms":                             true,
+	"allow-modals":                            true,
+	"allow-orientation-lock":                  true,
+	"allow-pointer-lock":                      true,
+	"allow-popups":                            true,
+	"allow-popups-to-escape-sandbox":          true,
+	"allow-presentation":                      true,
+	"allow-same-origin":                       true,
+	"allow-scripts":                           true,
+	"allow-storage-access-by-user-activation": true,
+	"allow-top-navigation":                    true,
+	"allow-top-navigation-by-user-activation": true,
+}
+
+// embedCommentPattern matches an embed comment on its own line, such as
+// <!-- embed: https://localhost:5173 height: 90% -->. Group 1 is the text
+// after "embed:".
+var embedCommentPattern = regexp.MustCompile(`^\s*<!--\s*embed:\s*(.*?)\s*-->\s*$`)
+
+// embedFencePattern matches the opening fence of an embed code block.
+var embedFencePattern = regexp.MustCompile("^\\s*(`{3,}|~{3,})embed\\s*$")
+
+// embedHeightPattern matches the heights an embed can set: a number with an
+// optional CSS unit. Anything else could inject styles into the iframe.
+var embedHeightPattern = regexp.MustCompile(`^\d+(\.\d+)?(%|px|vh|em|rem)?$`)
+
+// Validate reports whether the embed can be shown: its URL must be an
+// absolute http or https URL, its height a number with an optional unit, and
+// its sandbox flags known to browsers.
+func (e *Embed) Validate() error {
+	if e.err != nil {
+		return e.err
+	}
+	if e.URL == "" {
+		return fmt.Errorf("embed has no url")
+	}
+	u, err := url.Parse(e.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("embed url %q must be an http or https url", e.URL)
+	}
+	if e.Height != "" && !embedHeightPattern.MatchString(e.Height) {
+		return fmt.Errorf("invalid embed height %q: use a number with an optional unit, such as 90%% or 600px", e.Height)
+	}
+	for _, flag := range e.Sandbox {
+		if !embedSandboxFlags[flag] {
+			return fmt.Errorf("unknown embed sandbox flag %q", flag)
+		}
+	}
+	return nil
+}
+
+// extractEmbed removes the first embed comment or embed code block outside
+// code blocks from slide content, and returns the remaining content and the
+// embed, or nil if the slide has none. Later embeds are left as content.
+func extractEmbed(content string) (string, *Embed) {
+	if !strings.Contains(content, "embed") {
+		return content, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	insideCodeBlock := false
+	fenceLength := 0
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		wasInsideCodeBlock := insideCodeBlock
+		insideCodeBlock, fenceLength = UpdateCodeFence(line, insideCodeBlock, fenceLength)
+		if wasInsideCodeBlock {
+			continue
+		}
+
+		if match := embedCommentPattern.FindStringSubmatch(line); match != nil {
+			embed := parseEmbedComment(match[1])
+			return joinLines(lines[:i], lines[i+1:]), embed
+		}
+
+		if embedFencePattern.MatchString(line) {
+			// The block runs to its closing fence, or to the end of the slide
+			end := len(lines)
+			for j := i + 1; j < len(lines); j++ {
+				insideCodeBlock, fenceLength = UpdateCodeFence(lines[j], insideCodeBlock, fenceLength)
+				if !insideCodeBlock {
+					end = j
+					break
+				}
+			}
+			embed := parseEmbedBlock(strings.Join(lines[i+1:end], "\n"))
+			return joinLines(lines[:i], lines[min(end+1, len(lines)):]), embed
+		}
+	}
+
+	return content, nil
+}
+
+// joinLines joins the lines before and after a removed embed.
+func joinLines(before, after []string) string {
+	return strings.TrimSpace(strings.Join(append(append([]string{}, before...), after...), "\n"))
+}
+
+// parseEmbedComment parses the text of an embed comment: the URL followed by
+// "key: value" options, where sandbox takes every flag up to the next option.
+// Example: https://localhost:5173 height: 90% sandbox: allow-scripts allow-forms
+func parseEmbedComment(text string) *Embed {
+	fields := strings.Fields(text)
+	embed := &Embed{Sandbox: append([]string(nil), DefaultEmbedSandbox...)}
+	if len(fields) == 0 {
+		return embed
+	}
+	embed.URL = fields[0]
+
+	key := ""
+	sandbox := []string(nil)
+	for _, field := range fields[1:] {
+		if name, value, ok := strings.Cut(field, ":"); ok && isEmbedOption(name) {
+			key = name
+			if key == "sandbox" {
+				sandbox = []string{}
+			}
+			if value == "" {
+				continue
+			}
+			field = value
+		}
+
+		switch key {
+		case "height":
+			embed.Height = field
+		case "allowFullscreen":
+			allow, err := strconv.ParseBool(field)
+			if err != nil {
+				embed.err = fmt.Errorf("invalid embed allowFullscreen %q: use true or false", field)
+			}
+			embed.AllowFullscreen = allow
+		case "sandbox":
+			sandbox = append(sandbox, field)
+		default:
+			embed.err = fmt.Errorf("unexpected %q in embed: options are height, allowFullscreen and sandbox", field)
+		}
+	}
+	if sandbox != nil {
+		embed.Sandbox = sandbox
+	}
+	return embed
+}
+
+// isEmbedOption reports whether name is an option of an embed comment.
+func isEmbedOption(name string) bool {
+	return name == "height" || name == "allowFullscreen" || name == "sandbox"
+}
+
+// parseEmbedBlock parses the YAML body of an embed code block. sandbox is a
+// list of flags or a string of space-separated flags.
+// Example:
+//
+//	url: https://localhost:5173
+//	height: 600px
+//	sandbox: [allow-scripts, allow-forms]
+func parseEmbedBlock(body string) *Embed {
+	embed := &Embed{Sandbox: append([]string(nil), DefaultEmbedSandbox...)}
+	var data struct {
+		URL             string    `yaml:"url"`
+		Height          yaml.Node `yaml:"height"`
+		AllowFullscreen bool      `yaml:"allowFullscreen"`
+		Sandbox         yaml.Node `yaml:"sandbox"`
+	}
+	if err := yaml.Unmarshal([]byte(body), &data); err != nil {
+		embed.err = fmt.Errorf("invalid embed block: %w", err)
+		return embed
+	}
+
+	embed.URL = data.URL
+	embed.Height = data.Height.Value
+	embed.AllowFullscreen = data.AllowFullscreen
+	switch data.Sandbox.Kind {
+	case yaml.ScalarNode:
+		embed.Sandbox = append([]string{}, strings.Fields(data.Sandbox.Value)...)
+	case yaml.SequenceNode:
+		embed.Sandbox = []string{}
+		for _, flag := range data.Sandbox.Content {
+			embed.Sandbox = append(embed.Sandbox, flag.Value)
+		}
+	}
+	return embed
+}
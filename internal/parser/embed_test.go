@@ -0,0 +1,128 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_Embed(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		want        *Embed
+		wantContent string
+	}{
+		{
+			name:        "comment",
+			input:       "# Demo\n\n<!-- embed: https://localhost:5173 height: 90% -->",
+			want:        &Embed{URL: "https://localhost:5173", Height: "90%", Sandbox: DefaultEmbedSandbox},
+			wantContent: "# Demo",
+		},
+		{
+			name:  "comment starting the slide with directives",
+			input: "<!-- embed: http://localhost:3000 allowFullscreen: true sandbox: allow-scripts allow-modals -->\n<!-- layout: blank -->\n# Demo",
+			want: &Embed{
+				URL:             "http://localhost:3000",
+				AllowFullscreen: true,
+				Sandbox:         []string{"allow-scripts", "allow-modals"},
+			},
+			wantContent: "# Demo",
+		},
+		{
+			name:        "fenced block",
+			input:       "# Demo\n\n```embed\nurl: https://example.com/app\nheight: 600px\nallowFullscreen: true\nsandbox: [allow-scripts, allow-forms]\n```\n\nTry it",
+			want:        &Embed{URL: "https://example.com/app", Height: "600px", AllowFullscreen: true, Sandbox: []string{"allow-scripts", "allow-forms"}},
+			wantContent: "Try it",
+		},
+		{
+			name:        "fenced block with no sandbox flags",
+			input:       "```embed\nurl: https://example.com\nsandbox: \"\"\n```",
+			want:        &Embed{URL: "https://example.com", Sandbox: []string{}},
+			wantContent: "",
+		},
+		{
+			name:        "inside a code block",
+			input:       "# Docs\n\n````markdown\n<!-- embed: https://example.com -->\n```embed\nurl: https://example.com\n```\n````",
+			want:        nil,
+			wantContent: "````markdown\n<!-- embed: https://example.com -->",
+		},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pres, err := p.Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse() returned error: %v", err)
+			}
+			slide := pres.Slides[0]
+			if !reflect.DeepEqual(slide.Embed, tt.want) {
+				t.Errorf("Embed = %+v, want %+v", slide.Embed, tt.want)
+			}
+			if !strings.Contains(slide.Content, tt.wantContent) {
+				t.Errorf("Content = %q, want it to contain %q", slide.Content, tt.wantContent)
+			}
+			if tt.want != nil && strings.Contains(slide.HTML, "embed") {
+				t.Errorf("expected the embed to be removed from the HTML, got %q", slide.HTML)
+			}
+			if tt.want != nil {
+				if err := slide.Embed.Validate(); err != nil {
+					t.Errorf("Validate() = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestParse_EmbedWithDirectives(t *testing.T) {
+	pres, err := New().Parse([]byte("<!-- embed: https://localhost:5173 -->\n<!-- layout: blank -->\n# Demo"))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	slide := pres.Slides[0]
+	if slide.Directives.Layout != "blank" {
+		t.Errorf("Layout = %q, want %q", slide.Directives.Layout, "blank")
+	}
+	if _, ok := slide.Directives.Raw["embed"]; ok {
+		t.Error("expected the embed not to be read as a directive")
+	}
+}
+
+func TestEmbedValidate(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr string
+	}{
+		{input: "<!-- embed: https://localhost:5173 height: 75.5vh -->"},
+		{input: "<!-- embed: http://127.0.0.1:8080/demo?x=1 height: 600 -->"},
+		{input: "<!-- embed: javascript:alert(1) -->", wantErr: "must be an http or https url"},
+		{input: "<!-- embed: file:///etc/passwd -->", wantErr: "must be an http or https url"},
+		{input: "<!-- embed: /demo -->", wantErr: "must be an http or https url"},
+		{input: "<!-- embed: -->", wantErr: "embed has no url"},
+		{input: "<!-- embed: https://example.com height: 90%;color:red -->", wantErr: "invalid embed height"},
+		{input: "<!-- embed: https://example.com sandbox: allow-everything -->", wantErr: "unknown embed sandbox flag"},
+		{input: "<!-- embed: https://example.com allowFullscreen: maybe -->", wantErr: "invalid embed allowFullscreen"},
+		{input: "<!-- embed: https://example.com width: 50% -->", wantErr: "unexpected"},
+		{input: "```embed\nurl: [https://example.com\n```", wantErr: "invalid embed block"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, embed := extractEmbed(tt.input)
+			if embed == nil {
+				t.Fatal("expected an embed")
+			}
+			err := embed.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want an error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+)
+
+// footnoteDefinitionPattern matches the first line of a footnote definition,
+// such as "[^1]: The source." Group 1 is the label.
+var footnoteDefinitionPattern = regexp.MustCompile(`^ {0,3}\[\^([^\]\s]+)\]:`)
+
+// footnoteReferencePattern matches footnote references such as [^1] or [^note].
+// Group 1 is the label.
+var footnoteReferencePattern = regexp.MustCompile(`\[\^([^\]\s]+)\]`)
+
+// inlineCodePattern matches inline code spans, which can't contain references.
+var inlineCodePattern = regexp.MustCompile("`+[^`]*`+")
+
+// footnoteSectionStart starts the footnotes list goldmark renders at the
+// bottom of the HTML.
+const footnoteSectionStart = `<div class="footnotes" role="doc-endnotes">`
+
+// footnoteIDPattern matches the id and href attributes goldmark renders for
+// footnotes: fn:1 for a definition and fnref:1, fnref1:1, ... for references.
+var footnoteIDPattern = regexp.MustCompile(`((?:id|href)="#?)(fn|fnref\d*):(\d+)"`)
+
+// extractFootnoteDefinitions removes the footnote definitions from slide
+// content and returns the remaining content and the definitions by label.
+// A definition continues on indented lines, including after blank lines.
+// Lines inside fenced code blocks are left alone.
+func extractFootnoteDefinitions(content string) (string, map[string]string) {
+	if !strings.Contains(content, "[^") {
+		return content, nil
+	}
+
+	lines := strings.Split(content, "\n")
+	definitions := make(map[string]string)
+	var out []string
+	insideCodeBlock := false
+	fenceLength := 0
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		wasInsideCodeBlock := insideCodeBlock
+		insideCodeBlock, fenceLength = UpdateCodeFence(line, insideCodeBlock, fenceLength)
+		match := footnoteDefinitionPattern.FindStringSubmatch(line)
+		if wasInsideCodeBlock || insideCodeBlock || match == nil {
+			out = append(out, line)
+			continue
+		}
+
+		// Collect the indented continuation lines of the definition
+		end := i + 1
+		for j := i + 1; j < len(lines); j++ {
+			if isIndented(lines[j]) {
+				end = j + 1
+			} else if strings.TrimSpace(lines[j]) != "" {
+				break
+			}
+		}
+
+		label := match[1]
+		if _, ok := definitions[label]; !ok {
+			// Like link reference definitions, the first definition wins
+			definitions[label] = strings.Join(lines[i:end], "\n")
+		}
+		i = end - 1
+	}
+
+	return strings.Join(out, "\n"), definitions
+}
+
+// IsEmptySlide reports whether slide content has nothing to show: only
+// whitespace and footnote definitions, which are rendered on the slides that
+// reference them. Parse skips such slides, so anything that counts slides in
+// the markdown must skip them too.
+func IsEmptySlide(content string) bool {
+	content, _ = extractFootnoteDefinitions(content)
+	return strings.TrimSpace(content) == ""
+}
+
+// isIndented reports whether a line is indented enough to continue a footnote
+// definition: by a tab or at least four spaces.
+func isIndented(line string) bool {
+	return strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ")
+}
+
+// footnoteReferences returns the labels of the footnotes referenced in slide
+// content, in the order they are first referenced. References in code are
+// ignored.
+func footnoteReferences(content string) []string {
+	if !strings.Contains(content, "[^") {
+		return nil
+	}
+
+	var labels []string
+	seen := make(map[string]bool)
+	insideCodeBlock := false
+	fenceLength := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		wasInsideCodeBlock := insideCodeBlock
+		insideCodeBlock, fenceLength = UpdateCodeFence(line, insideCodeBlock, fenceLength)
+		if wasInsideCodeBlock || insideCodeBlock {
+			continue
+		}
+
+		line = inlineCodePattern.ReplaceAllString(line, "")
+		for _, match := range footnoteReferencePattern.FindAllStringSubmatch(line, -1) {
+			if label := match[1]; !seen[label] {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+	return labels
+}
+
+// appendFootnoteDefinitions appends the definitions of the footnotes
+// referenced in content, so goldmark renders them at the bottom of it,
+// numbered from 1 in the order they are referenced.
+func appendFootnoteDefinitions(content string, definitions map[string]string) string {
+	if len(definitions) == 0 {
+		return content
+	}
+
+	var b strings.Builder
+	b.WriteString(content)
+	for _, label := range footnoteReferences(content) {
+		if definition, ok := definitions[label]; ok {
+			b.WriteString("\n\n")
+			b.WriteString(definition)
+		}
+	}
+	return b.String()
+}
+
+// prefixFootnoteIDs adds a prefix to the footnote ids and links in rendered
+// HTML. Footnotes are numbered from 1 on every slide, so without a prefix
+// the ids would repeat when several slides are on one page.
+func prefixFootnoteIDs(html, prefix string) string {
+	if !strings.Contains(html, "fn") {
+		return html
+	}
+	return footnoteIDPattern.ReplaceAllString(html, `${1}${2}:`+prefix+`${3}"`)
+}
+
+// splitFootnoteSection splits rendered HTML into the slide content and the
+// footnotes list at the bottom, which is empty if there are no footnotes.
+func splitFootnoteSection(html string) (string, string) {
+	i := strings.LastIndex(html, footnoteSectionStart)
+	if i < 0 {
+		return html, ""
+	}
+	return html[:i], html[i:]
+}
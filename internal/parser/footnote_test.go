@@ -0,0 +1,198 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse_FootnoteOnTwoSlides(t *testing.T) {
+	p := New()
+	content := []byte(`# Results
+
+Latency dropped by half[^bench].
+
+---
+
+# Caveats
+
+Only on warm caches[^bench].
+
+---
+
+[^bench]: Measured on the staging cluster.
+`)
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	// The definitions-only slide disappears
+	if len(pres.Slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(pres.Slides))
+	}
+
+	for i, slide := range pres.Slides {
+		if !strings.Contains(slide.HTML, "Measured on the staging cluster.") {
+			t.Errorf("slide %d should contain the footnote, got %q", i+1, slide.HTML)
+		}
+		if !strings.Contains(slide.HTML, `class="footnote-ref" role="doc-noteref">1</a>`) {
+			t.Errorf("slide %d should number the footnote 1, got %q", i+1, slide.HTML)
+		}
+	}
+
+	// Ids are scoped to the slide so they don't clash when slides share a page
+	if !strings.Contains(pres.Slides[0].HTML, `id="fn:1-1"`) || !strings.Contains(pres.Slides[1].HTML, `href="#fn:2-1"`) {
+		t.Errorf("footnote ids should be prefixed with the slide number, got %q and %q", pres.Slides[0].HTML, pres.Slides[1].HTML)
+	}
+}
+
+func TestParse_FootnotesRenumberedPerSlide(t *testing.T) {
+	p := New()
+	content := []byte(`# First
+
+Uses a[^a].
+
+---
+
+# Second
+
+Uses c[^c] and b[^b].
+
+[^a]: Note A.
+[^b]: Note B.
+[^c]: Note C.
+[^unused]: Never referenced.
+`)
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if len(pres.Slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(pres.Slides))
+	}
+
+	first := pres.Slides[0].HTML
+	if strings.Contains(first, "Note B.") || strings.Contains(first, "Note C.") {
+		t.Errorf("first slide should only contain its own footnotes, got %q", first)
+	}
+
+	second := pres.Slides[1].HTML
+	if strings.Contains(second, "Note A.") {
+		t.Errorf("second slide should not contain footnote a, got %q", second)
+	}
+	c := strings.Index(second, "Note C.")
+	b := strings.Index(second, "Note B.")
+	if c < 0 || b < 0 || c > b {
+		t.Errorf("second slide should number c before b, got %q", second)
+	}
+
+	for i, slide := range pres.Slides {
+		if strings.Contains(slide.HTML, "Never referenced.") {
+			t.Errorf("slide %d should not contain unreferenced footnotes", i+1)
+		}
+	}
+}
+
+func TestParse_FootnoteWithFragments(t *testing.T) {
+	p := New()
+	content := []byte(`<!-- fragments: true -->
+
+# Sources
+
+- First claim[^one]
+- Second claim
+
+[^one]: A source.
+`)
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	slide := pres.Slides[0]
+	if len(slide.Fragments) != 2 {
+		t.Fatalf("expected 2 fragments, got %d", len(slide.Fragments))
+	}
+	if !strings.Contains(slide.HTML, `<div class="footnotes"`) || !strings.Contains(slide.HTML, "A source.") {
+		t.Errorf("footnotes should stay at the bottom of the slide, got %q", slide.HTML)
+	}
+}
+
+func TestParse_FootnoteInPauseFragment(t *testing.T) {
+	p := New()
+	content := []byte(`# Claims
+
+First claim[^one]
+
+<!-- pause -->
+
+Second claim[^two]
+
+[^one]: Source one.
+[^two]: Source two.
+`)
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	fragments := pres.Slides[0].Fragments
+	if len(fragments) != 2 {
+		t.Fatalf("expected 2 fragments, got %d", len(fragments))
+	}
+	if !strings.Contains(fragments[0].Content, "Source one.") || strings.Contains(fragments[0].Content, "Source two.") {
+		t.Errorf("first fragment should only contain its own footnote, got %q", fragments[0].Content)
+	}
+	if !strings.Contains(fragments[1].Content, `id="fn:1-2-1"`) {
+		t.Errorf("fragment footnote ids should be prefixed with the fragment number, got %q", fragments[1].Content)
+	}
+}
+
+func TestParse_DefinitionList(t *testing.T) {
+	p := New()
+	content := []byte(`# Glossary
+
+Latency
+:   Time until the first byte.
+
+Throughput
+:   Requests per second.
+`)
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	html := pres.Slides[0].HTML
+	for _, want := range []string{"<dl>", "<dt>Latency</dt>", "<dd>Time until the first byte.</dd>", "<dt>Throughput</dt>"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML should contain %q, got %q", want, html)
+		}
+	}
+}
+
+func TestExtractFootnoteDefinitions(t *testing.T) {
+	content := "Text[^1]\n\n[^1]: First line.\n    Continued.\n\n    Second paragraph.\n\nAfter.\n\n```\n[^2]: In code.\n```"
+
+	rest, definitions := extractFootnoteDefinitions(content)
+	if len(definitions) != 1 {
+		t.Fatalf("expected 1 definition, got %v", definitions)
+	}
+	if want := "[^1]: First line.\n    Continued.\n\n    Second paragraph."; definitions["1"] != want {
+		t.Errorf("definition = %q, want %q", definitions["1"], want)
+	}
+	if !strings.Contains(rest, "After.") || !strings.Contains(rest, "[^2]: In code.") {
+		t.Errorf("remaining content = %q", rest)
+	}
+}
+
+func TestFootnoteReferences(t *testing.T) {
+	content := "See[^b] and[^a], again[^b]. Not `[^code]`.\n\n```\n[^fenced]\n```"
+
+	got := footnoteReferences(content)
+	if strings.Join(got, ",") != "b,a" {
+		t.Errorf("footnoteReferences() = %v, want [b a]", got)
+	}
+}
@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// headingElementPattern matches a heading element in slide HTML.
+// Captures: (1) heading level, (2) heading content
+var headingElementPattern = regexp.MustCompile(`(?is)<h([1-6])(?:\s[^>]*)?>(.*?)</h[1-6]>`)
+
+// htmlTagPattern matches HTML tags, to reduce heading content to text.
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// SlideTitle returns the text of the first heading in slide HTML, or an
+// empty string if there is none.
+func SlideTitle(slideHTML string) string {
+	title, _ := FirstHeading(slideHTML, 6)
+	return title
+}
+
+// FirstHeading returns the text and level of the first heading in slide HTML
+// whose level is at most maxLevel. Tags are removed from the text, character
+// references are decoded, and whitespace is collapsed. It returns an empty
+// title and level 0 if there is no such heading.
+func FirstHeading(slideHTML string, maxLevel int) (string, int) {
+	for _, match := range headingElementPattern.FindAllStringSubmatch(slideHTML, -1) {
+		level := int(match[1][0] - '0')
+		if level > maxLevel {
+			continue
+		}
+		text := html.UnescapeString(htmlTagPattern.ReplaceAllString(match[2], ""))
+		return strings.Join(strings.Fields(text), " "), level
+	}
+	return "", 0
+}
@@ -0,0 +1,33 @@
+package parser
+
+import "testing"
+
+func TestFirstHeading(t *testing.T) {
+	tests := []struct {
+		name      string
+		html      string
+		maxLevel  int
+		wantTitle string
+		wantLevel int
+	}{
+		{"first heading", `<p>Intro</p><h2 id="x">Fast &amp; <code>safe</code></h2><h1>Later</h1>`, 6, "Fast & safe", 2},
+		{"whitespace is collapsed", "<h3>\n  Two\n  lines\n</h3>", 6, "Two lines", 3},
+		{"deeper headings are skipped", "<h3>Detail</h3><H1 class=\"big\">Title</H1>", 2, "Title", 1},
+		{"no heading", "<p>No heading</p><header>Not one</header>", 6, "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, level := FirstHeading(tt.html, tt.maxLevel)
+			if title != tt.wantTitle || level != tt.wantLevel {
+				t.Errorf("FirstHeading() = %q, %d, want %q, %d", title, level, tt.wantTitle, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestSlideTitle(t *testing.T) {
+	if got := SlideTitle("<p>Intro</p><h4>Deep <em>title</em></h4>"); got != "Deep title" {
+		t.Errorf("SlideTitle() = %q, want %q", got, "Deep title")
+	}
+}
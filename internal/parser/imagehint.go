@@ -0,0 +1,218 @@
+package parser
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Fits of an image hint.
+const (
+	// ImageFitCover fills the image's space, cropping the image.
+	ImageFitCover = "cover"
+	// ImageFitContain shows the whole image in its space, leaving bars.
+	ImageFitContain = "contain"
+	// ImageFitOriginal shows the image at its own size, cropped to its space.
+	ImageFitOriginal = "original"
+)
+
+// ImageFits are the fits an image hint can have, in the order the image
+// generator offers them.
+var ImageFits = []string{ImageFitCover, ImageFitContain, ImageFitOriginal}
+
+// ImageHint is how an image is fitted into its space on the slide, from an
+// <!-- img: {fit: cover, position: top, width: 60%} --> comment on the line
+// after the image. Themes apply it as object-fit, object-position, and width.
+type ImageHint struct {
+	// Src is the image URL as written in the markdown.
+	Src string
+	// Fit is ImageFitCover, ImageFitContain, or ImageFitOriginal; empty to
+	// leave the fit to the layout.
+	Fit string
+	// Position is where the image is anchored when it is cropped, as a CSS
+	// object-position such as "top" or "30% 40%".
+	Position string
+	// Width is the CSS width of the image, such as "60%" or "400px".
+	Width string
+}
+
+// imageHintPattern matches an image, with any {attributes}, followed by an
+// img comment on the next line.
+// Captures: (1) the image, (2) alt text, (3) URL, (4) attributes with braces,
+// (5) the content of the comment, without braces
+var imageHintPattern = regexp.MustCompile(`(?m)(!\[([^\]\n]*)\]\(([^)\n]+)\)(\{[^}\n]+\})?)[ \t]*\n[ \t]*<!--\s*img:\s*\{([^}\n]*)\}\s*-->[ \t]*$`)
+
+// imageHintPositionPattern matches an object-position: up to four keywords,
+// percentages, or lengths.
+var imageHintPositionPattern = regexp.MustCompile(`^(?:top|bottom|left|right|center|-?\d+(?:\.\d+)?(?:%|px|em|rem|vw|vh))(?: +(?:top|bottom|left|right|center|-?\d+(?:\.\d+)?(?:%|px|em|rem|vw|vh))){0,3}$`)
+
+// listItemPattern matches the start of a list item, after which indented
+// lines continue the item rather than start indented code.
+var listItemPattern = regexp.MustCompile(`^ {0,3}(?:[-*+]|\d{1,9}[.)])(?:\s|$)`)
+
+// imageHintWidthPattern matches a width: a percentage or a length.
+var imageHintWidthPattern = regexp.MustCompile(`^\d+(?:\.\d+)?(?:%|px|em|rem|vw|vh)$`)
+
+// ParseImageHint parses the content of an img comment, such as
+// "{fit: cover, position: top, width: 60%}", with or without the braces.
+// Unknown keys and values that aren't a fit, position, or width are ignored.
+func ParseImageHint(s string) ImageHint {
+	hint := ImageHint{}
+
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "{")
+	s = strings.TrimSuffix(s, "}")
+
+	for _, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(part, ":")
+		if !ok {
+			continue
+		}
+		value = strings.Join(strings.Fields(strings.ToLower(value)), " ")
+
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "fit":
+			for _, fit := range ImageFits {
+				if value == fit {
+					hint.Fit = value
+				}
+			}
+		case "position":
+			if imageHintPositionPattern.MatchString(value) {
+				hint.Position = value
+			}
+		case "width":
+			if imageHintWidthPattern.MatchString(value) {
+				hint.Width = value
+			}
+		}
+	}
+
+	return hint
+}
+
+// String returns the hint as the content of an img comment, without braces:
+// "fit: cover, position: top, width: 60%". Empty fields are left out.
+func (h ImageHint) String() string {
+	var parts []string
+	if h.Fit != "" {
+		parts = append(parts, "fit: "+h.Fit)
+	}
+	if h.Position != "" {
+		parts = append(parts, "position: "+h.Position)
+	}
+	if h.Width != "" {
+		parts = append(parts, "width: "+h.Width)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// IsZero reports whether the hint has no fit, position, or width.
+func (h ImageHint) IsZero() bool {
+	return h.Fit == "" && h.Position == "" && h.Width == ""
+}
+
+// transformImageHints converts markdown images followed by an img comment to
+// HTML, and returns the hints. It transforms
+//
+//	![alt](src)
+//	<!-- img: {fit: cover, position: top} -->
+//
+// to <img src="src" alt="alt" data-fit="cover" style="object-position: top">,
+// removing the comment. Attributes such as {width=50%} are kept as styles,
+// before the hint's, so the hint's width wins. Images whose comment has no
+// valid hint are left as they are, without the comment.
+func transformImageHints(content string) (string, []ImageHint) {
+	var hints []ImageHint
+	code := codeLines(content)
+	var sb strings.Builder
+	last := 0
+	for _, loc := range imageHintPattern.FindAllStringSubmatchIndex(content, -1) {
+		// Images shown as source in fenced or indented code are left alone
+		line := strings.Count(content[:loc[0]], "\n")
+		if code[line] || code[line+1] {
+			continue
+		}
+		sb.WriteString(content[last:loc[0]])
+		last = loc[1]
+
+		groups := make([]string, len(loc)/2)
+		for i := range groups {
+			if loc[2*i] >= 0 {
+				groups[i] = content[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		tag, hint := imageHintTag(groups)
+		if !hint.IsZero() {
+			hints = append(hints, hint)
+		}
+		sb.WriteString(tag)
+	}
+	sb.WriteString(content[last:])
+	return sb.String(), hints
+}
+
+// imageHintTag returns the HTML for an image followed by an img comment,
+// given the groups of an imageHintPattern match, and its hint. An image
+// without a valid hint is returned as written, with a zero hint.
+func imageHintTag(groups []string) (string, ImageHint) {
+	hint := ParseImageHint(groups[5])
+	if hint.IsZero() {
+		return groups[1], hint
+	}
+	hint.Src = groups[3]
+
+	styles := imageAttributeStyles(ParseImageAttributes(groups[4]))
+	if hint.Position != "" {
+		styles = append(styles, "object-position: "+hint.Position)
+	}
+	if hint.Width != "" {
+		styles = append(styles, "width: "+hint.Width)
+	}
+
+	tag := `<img src="` + html.EscapeString(hint.Src) + `" alt="` + html.EscapeString(groups[2]) + `"`
+	if hint.Fit != "" {
+		tag += ` data-fit="` + hint.Fit + `"`
+	}
+	if len(styles) > 0 {
+		tag += ` style="` + strings.Join(styles, "; ") + `"`
+	}
+	return tag + ">", hint
+}
+
+// codeLines reports for each line of content whether it is part of a fenced
+// code block, including its fences, or an indented code block: lines indented
+// by a tab or four spaces after a blank line, outside a list.
+func codeLines(content string) []bool {
+	lines := strings.Split(content, "\n")
+	code := make([]bool, len(lines))
+	insideCodeBlock, fenceLength := false, 0
+	inList, inIndentedCode := false, false
+
+	for i, line := range lines {
+		wasInside := insideCodeBlock
+		insideCodeBlock, fenceLength = UpdateCodeFence(line, insideCodeBlock, fenceLength)
+		if wasInside || insideCodeBlock {
+			code[i] = true
+			inIndentedCode = false
+			continue
+		}
+
+		blank := strings.TrimSpace(line) == ""
+		indented := strings.HasPrefix(line, "\t") || strings.HasPrefix(line, "    ")
+		switch {
+		case blank:
+			// Blank lines don't end an indented code block or a list
+		case indented:
+			if !inIndentedCode && !inList && (i == 0 || strings.TrimSpace(lines[i-1]) == "") {
+				inIndentedCode = true
+			}
+			code[i] = inIndentedCode
+		default:
+			inIndentedCode = false
+			inList = listItemPattern.MatchString(line)
+		}
+	}
+
+	return code
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseImageHint(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  ImageHint
+	}{
+		{"all fields", "{fit: cover, position: top, width: 60%}", ImageHint{Fit: "cover", Position: "top", Width: "60%"}},
+		{"without braces", "fit: contain", ImageHint{Fit: "contain"}},
+		{"case and spacing", "{ Fit : ORIGINAL ,position:  30%   40% }", ImageHint{Fit: "original", Position: "30% 40%"}},
+		{"width in pixels", "{width: 400px}", ImageHint{Width: "400px"}},
+		{"invalid fit", "{fit: stretch, position: top}", ImageHint{Position: "top"}},
+		{"invalid position", "{position: url(x)}", ImageHint{}},
+		{"invalid width", "{width: 60%; color: red}", ImageHint{}},
+		{"unknown keys", "{border: none}", ImageHint{}},
+		{"empty", "{}", ImageHint{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseImageHint(tt.input); got != tt.want {
+				t.Errorf("ParseImageHint(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageHint_String(t *testing.T) {
+	hint := ImageHint{Src: "cat.png", Fit: "cover", Position: "top", Width: "60%"}
+	if got := hint.String(); got != "fit: cover, position: top, width: 60%" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (ImageHint{Position: "left"}).String(); got != "position: left" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := ParseImageHint(hint.String()); got != (ImageHint{Fit: "cover", Position: "top", Width: "60%"}) {
+		t.Errorf("ParseImageHint(String()) = %+v", got)
+	}
+}
+
+func TestTransformImageHints(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		want      string
+		wantHints []ImageHint
+	}{
+		{
+			name:      "fit, position, and width",
+			input:     "![A cat](cat.png)\n<!-- img: {fit: cover, position: top, width: 60%} -->\n\nText",
+			want:      `<img src="cat.png" alt="A cat" data-fit="cover" style="object-position: top; width: 60%">` + "\n\nText",
+			wantHints: []ImageHint{{Src: "cat.png", Fit: "cover", Position: "top", Width: "60%"}},
+		},
+		{
+			name:      "with attributes",
+			input:     "![](cat.png){position=left}\n<!-- img: {fit: contain} -->",
+			want:      `<img src="cat.png" alt="" data-fit="contain" style="float: left; margin-right: 1em">`,
+			wantHints: []ImageHint{{Src: "cat.png", Fit: "contain"}},
+		},
+		{
+			name:      "escapes the alt text",
+			input:     "![\"Cats\" & dogs](pets.png)\n<!-- img: {fit: original} -->",
+			want:      `<img src="pets.png" alt="&#34;Cats&#34; &amp; dogs" data-fit="original">`,
+			wantHints: []ImageHint{{Src: "pets.png", Fit: "original"}},
+		},
+		{
+			name:  "invalid hint drops the comment",
+			input: "![](cat.png)\n<!-- img: {fit: stretch} -->",
+			want:  "![](cat.png)",
+		},
+		{
+			name:  "fenced code",
+			input: "```markdown\n![a](b.png)\n<!-- img: {fit: cover} -->\n```",
+			want:  "```markdown\n![a](b.png)\n<!-- img: {fit: cover} -->\n```",
+		},
+		{
+			name:      "indented code",
+			input:     "Example:\n\n    ![a](b.png)\n    <!-- img: {fit: cover} -->\n\n![c](d.png)\n<!-- img: {fit: contain} -->",
+			want:      "Example:\n\n    ![a](b.png)\n    <!-- img: {fit: cover} -->\n\n" + `<img src="d.png" alt="c" data-fit="contain">`,
+			wantHints: []ImageHint{{Src: "d.png", Fit: "contain"}},
+		},
+		{
+			name:      "indented in a list item",
+			input:     "- Photo:\n\n    ![a](b.png)\n    <!-- img: {fit: cover} -->",
+			want:      "- Photo:\n\n    " + `<img src="b.png" alt="a" data-fit="cover">`,
+			wantHints: []ImageHint{{Src: "b.png", Fit: "cover"}},
+		},
+		{
+			name:  "comment not on the next line",
+			input: "![](cat.png)\n\n<!-- img: {fit: cover} -->",
+			want:  "![](cat.png)\n\n<!-- img: {fit: cover} -->",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, hints := transformImageHints(tt.input)
+			if got != tt.want {
+				t.Errorf("transformImageHints() = %q, want %q", got, tt.want)
+			}
+			if !reflect.DeepEqual(hints, tt.wantHints) {
+				t.Errorf("hints = %+v, want %+v", hints, tt.wantHints)
+			}
+		})
+	}
+}
+
+func TestParse_ImageHintsInCode(t *testing.T) {
+	input := "# Syntax\n\n```markdown\n![a](b.png)\n<!-- img: {fit: cover} -->\n```\n"
+	pres, err := New().Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	slide := pres.Slides[0]
+	if slide.ImageHints != nil {
+		t.Errorf("expected no image hints from code, got %+v", slide.ImageHints)
+	}
+	if strings.Contains(slide.HTML, "<img") || !strings.Contains(slide.HTML, "&lt;!-- img: {fit: cover} --&gt;") {
+		t.Errorf("expected the code to be shown as written, got %q", slide.HTML)
+	}
+}
+
+func TestCodeLines(t *testing.T) {
+	content := "text\n```go\ncode\n```\n\n    indented\n\n    more\nafter\n- item\n\n    continued"
+	want := []bool{false, true, true, true, false, true, false, true, false, false, false, false}
+	if got := codeLines(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("codeLines() = %v, want %v", got, want)
+	}
+}
+
+func TestParse_ImageHints(t *testing.T) {
+	input := "# Photo\n\n![A cat](images/cat.png)\n<!-- img: {fit: cover, position: top} -->\n*Our cat*\n"
+	pres, err := New().Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	slide := pres.Slides[0]
+	want := []ImageHint{{Src: "images/cat.png", Fit: "cover", Position: "top"}}
+	if !reflect.DeepEqual(slide.ImageHints, want) {
+		t.Errorf("ImageHints = %+v, want %+v", slide.ImageHints, want)
+	}
+	if strings.Contains(slide.Content, "img:") {
+		t.Errorf("expected the img comment to be removed, got %q", slide.Content)
+	}
+	if !strings.Contains(slide.Content, `data-fit="cover"`) || !strings.Contains(slide.Content, "*Our cat*") {
+		t.Errorf("expected the image with its fit and the caption, got %q", slide.Content)
+	}
+}
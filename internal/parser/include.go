@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MaxIncludeDepth is the maximum nesting depth for include directives.
+const MaxIncludeDepth = 5
+
+// includePattern matches an include directive on its own line.
+// Example: <!-- include: sections/intro.md -->
+var includePattern = regexp.MustCompile(`^\s*<!--\s*include:\s*(.+?)\s*-->\s*$`)
+
+// HasIncludes reports whether content contains any include directives outside code blocks.
+func HasIncludes(content string) bool {
+	found := false
+	forEachLine(content, func(line string, inCode bool) {
+		if !inCode && includePattern.MatchString(line) {
+			found = true
+		}
+	})
+	return found
+}
+
+// ExpandIncludes replaces include directives (<!-- include: path.md -->) with the
+// content of the referenced files. Paths are resolved relative to the including
+// file and frontmatter in included files is stripped. Includes may be nested up
+// to MaxIncludeDepth levels; include cycles are reported as errors.
+//
+// It returns the expanded content and the absolute paths of all included files.
+func ExpandIncludes(content string, path string) (string, []string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+
+	var included []string
+	expanded, err := expandIncludes(content, absPath, []string{absPath}, &included)
+	if err != nil {
+		return "", nil, err
+	}
+	return expanded, included, nil
+}
+
+// ParseFile reads a markdown file, expands its include directives, and parses
+// the merged content. The returned Presentation lists the included files.
+func (p *Parser) ParseFile(path string) (*Presentation, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	expanded, included, err := ExpandIncludes(string(content), path)
+	if err != nil {
+		return nil, err
+	}
+
+	pres, err := p.Parse([]byte(expanded))
+	if err != nil {
+		return nil, err
+	}
+	pres.Includes = included
+	return pres, nil
+}
+
+// expandIncludes recursively expands include directives in content.
+// stack holds the chain of files currently being expanded, for cycle detection.
+func expandIncludes(content string, path string, stack []string, included *[]string) (string, error) {
+	var result strings.Builder
+	var expandErr error
+	first := true
+
+	forEachLine(content, func(line string, inCode bool) {
+		if expandErr != nil {
+			return
+		}
+		if !first {
+			result.WriteString("\n")
+		}
+		first = false
+
+		match := includePattern.FindStringSubmatch(line)
+		if inCode || match == nil {
+			result.WriteString(line)
+			return
+		}
+
+		includePath := filepath.Join(filepath.Dir(path), match[1])
+		if filepath.IsAbs(match[1]) {
+			includePath = match[1]
+		}
+
+		for i, p := range stack {
+			if p == includePath {
+				chain := append(append([]string{}, stack[i:]...), includePath)
+				for j := range chain {
+					chain[j] = filepath.Base(chain[j])
+				}
+				expandErr = fmt.Errorf("include cycle detected: %s", strings.Join(chain, " -> "))
+				return
+			}
+		}
+
+		if len(stack) > MaxIncludeDepth {
+			expandErr = fmt.Errorf("include depth exceeds %d at %s", MaxIncludeDepth, match[1])
+			return
+		}
+
+		data, err := os.ReadFile(includePath)
+		if err != nil {
+			expandErr = fmt.Errorf("failed to read included file %s: %w", match[1], err)
+			return
+		}
+		*included = append(*included, includePath)
+
+		body := strings.TrimRight(skipFrontmatter(string(data)), "\n")
+		expanded, err := expandIncludes(body, includePath, append(stack, includePath), included)
+		if err != nil {
+			expandErr = err
+			return
+		}
+		result.WriteString(expanded)
+	})
+
+	if expandErr != nil {
+		return "", expandErr
+	}
+	return result.String(), nil
+}
+
+// forEachLine calls fn for each line of content, reporting whether the line is
+// part of a fenced code block (including the fences themselves).
+func forEachLine(content string, fn func(line string, inCode bool)) {
+	insideCodeBlock := false
+	codeBlockFenceLength := 0
+
+	for _, line := range strings.Split(content, "\n") {
+		backtickCount := countLeadingBackticks(line)
+		isFence := false
+		if backtickCount >= 3 {
+			if !insideCodeBlock {
+				insideCodeBlock = true
+				codeBlockFenceLength = backtickCount
+				isFence = true
+			} else if backtickCount >= codeBlockFenceLength && strings.TrimSpace(line[backtickCount:]) == "" {
+				insideCodeBlock = false
+				codeBlockFenceLength = 0
+				isFence = true
+			}
+		}
+		fn(line, insideCodeBlock || isFence)
+	}
+}
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to a file under dir, creating parent directories.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExpandIncludes_Basic(t *testing.T) {
+	dir := t.TempDir()
+	intro := writeFile(t, dir, "sections/intro.md", "# Intro\n\n---\n\n# Agenda\n")
+	main := writeFile(t, dir, "slides.md", "")
+
+	content := "# Title\n\n---\n\n<!-- include: sections/intro.md -->\n\n---\n\n# End"
+	expanded, included, err := ExpandIncludes(content, main)
+	if err != nil {
+		t.Fatalf("ExpandIncludes() error = %v", err)
+	}
+
+	if strings.Contains(expanded, "include:") {
+		t.Errorf("include directive should be replaced, got:\n%s", expanded)
+	}
+	if !strings.Contains(expanded, "# Intro") || !strings.Contains(expanded, "# Agenda") {
+		t.Errorf("included content missing, got:\n%s", expanded)
+	}
+	if len(included) != 1 || included[0] != intro {
+		t.Errorf("included = %v, want [%s]", included, intro)
+	}
+
+	pres, err := New().Parse([]byte(expanded))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pres.Slides) != 4 {
+		t.Errorf("expected 4 slides, got %d", len(pres.Slides))
+	}
+}
+
+func TestExpandIncludes_NestedRelativePaths(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "sections/part.md", "# Part\n\n<!-- include: nested/detail.md -->")
+	writeFile(t, dir, "sections/nested/detail.md", "Detail content")
+	main := writeFile(t, dir, "slides.md", "")
+
+	expanded, included, err := ExpandIncludes("<!-- include: sections/part.md -->", main)
+	if err != nil {
+		t.Fatalf("ExpandIncludes() error = %v", err)
+	}
+	if !strings.Contains(expanded, "Detail content") {
+		t.Errorf("nested include should be resolved relative to the including file, got:\n%s", expanded)
+	}
+	if len(included) != 2 {
+		t.Errorf("expected 2 included files, got %v", included)
+	}
+}
+
+func TestExpandIncludes_StripsFrontmatter(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "part.md", "---\ntitle: Part\n---\n\n# Part Slide")
+	main := writeFile(t, dir, "slides.md", "")
+
+	expanded, _, err := ExpandIncludes("<!-- include: part.md -->", main)
+	if err != nil {
+		t.Fatalf("ExpandIncludes() error = %v", err)
+	}
+	if strings.Contains(expanded, "title: Part") {
+		t.Errorf("frontmatter should be stripped, got:\n%s", expanded)
+	}
+	if !strings.Contains(expanded, "# Part Slide") {
+		t.Errorf("content should be included, got:\n%s", expanded)
+	}
+}
+
+func TestExpandIncludes_Cycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "a.md", "<!-- include: b.md -->")
+	writeFile(t, dir, "b.md", "<!-- include: a.md -->")
+	main := writeFile(t, dir, "slides.md", "")
+
+	_, _, err := ExpandIncludes("<!-- include: a.md -->", main)
+	if err == nil {
+		t.Fatal("expected cycle error")
+	}
+	if !strings.Contains(err.Error(), "a.md -> b.md -> a.md") {
+		t.Errorf("error should name the cycle, got %v", err)
+	}
+}
+
+func TestExpandIncludes_MaxDepth(t *testing.T) {
+	dir := t.TempDir()
+	for i := 1; i <= MaxIncludeDepth+1; i++ {
+		writeFile(t, dir, "level"+intToString(i)+".md", "<!-- include: level"+intToString(i+1)+".md -->")
+	}
+	writeFile(t, dir, "level"+intToString(MaxIncludeDepth+2)+".md", "bottom")
+	main := writeFile(t, dir, "slides.md", "")
+
+	_, _, err := ExpandIncludes("<!-- include: level1.md -->", main)
+	if err == nil || !strings.Contains(err.Error(), "include depth") {
+		t.Errorf("expected depth error, got %v", err)
+	}
+
+	// Exactly MaxIncludeDepth levels is allowed
+	_, _, err = ExpandIncludes("<!-- include: level3.md -->", main)
+	if err != nil {
+		t.Errorf("expected %d levels to be allowed, got %v", MaxIncludeDepth, err)
+	}
+}
+
+func TestExpandIncludes_MissingFile(t *testing.T) {
+	main := filepath.Join(t.TempDir(), "slides.md")
+
+	_, _, err := ExpandIncludes("<!-- include: missing.md -->", main)
+	if err == nil || !strings.Contains(err.Error(), "missing.md") {
+		t.Errorf("expected error naming missing file, got %v", err)
+	}
+}
+
+func TestExpandIncludes_IgnoresCodeBlocks(t *testing.T) {
+	content := "```html\n<!-- include: example.md -->\n```"
+
+	expanded, included, err := ExpandIncludes(content, filepath.Join(t.TempDir(), "slides.md"))
+	if err != nil {
+		t.Fatalf("ExpandIncludes() error = %v", err)
+	}
+	if expanded != content {
+		t.Errorf("content in code blocks should be unchanged, got:\n%s", expanded)
+	}
+	if len(included) != 0 {
+		t.Errorf("expected no included files, got %v", included)
+	}
+	if HasIncludes(content) {
+		t.Error("HasIncludes should ignore directives in code blocks")
+	}
+}
+
+func TestParseFile_WithIncludes(t *testing.T) {
+	dir := t.TempDir()
+	part := writeFile(t, dir, "part.md", "# Included\n\n```go\nfmt.Println(\"hi\")\n```")
+	main := writeFile(t, dir, "slides.md", "---\ntitle: Deck\n---\n\n# First\n\n---\n\n<!-- include: part.md -->")
+
+	pres, err := New().ParseFile(main)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	if len(pres.Slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(pres.Slides))
+	}
+	if pres.Slides[1].Index != 1 || len(pres.Slides[1].CodeBlocks) != 1 {
+		t.Errorf("included slide should be parsed with its code block, got %+v", pres.Slides[1])
+	}
+	if len(pres.Includes) != 1 || pres.Includes[0] != part {
+		t.Errorf("Includes = %v, want [%s]", pres.Includes, part)
+	}
+}
@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// SlideVariant is a slide rendered for one of the languages of its :::lang
+// blocks.
+type SlideVariant struct {
+	// Lang is the language, as written after :::lang.
+	Lang string
+	// Slide is the slide with the blocks of Lang and without those of the
+	// other languages.
+	Slide Slide
+}
+
+// langOpenPattern matches the opening fence of a :::lang block, such as
+// ":::lang da", capturing the language.
+var langOpenPattern = regexp.MustCompile(`^\s*:::\s*lang\s+([A-Za-z][\w-]*)\s*$`)
+
+// langBlock is a :::lang block in a slide's content.
+type langBlock struct {
+	lang  string
+	open  int // Line of the opening fence
+	close int // Line of the closing fence
+}
+
+// findLangBlocks returns the :::lang blocks in content, by the lines of their
+// fences. Container blocks may be nested in them, and fences inside code
+// blocks are ignored. A :::lang block that is never closed is not a block.
+func findLangBlocks(lines []string) []langBlock {
+	type openFence struct {
+		line int
+		lang string // "" for other containers
+	}
+
+	var blocks []langBlock
+	var open []openFence
+	insideCodeBlock := false
+	fenceLength := 0
+	for i, line := range lines {
+		wasInsideCodeBlock := insideCodeBlock
+		insideCodeBlock, fenceLength = UpdateCodeFence(line, insideCodeBlock, fenceLength)
+		if wasInsideCodeBlock || insideCodeBlock {
+			continue
+		}
+
+		if match := langOpenPattern.FindStringSubmatch(line); match != nil {
+			open = append(open, openFence{line: i, lang: match[1]})
+		} else if containerOpenPattern.MatchString(line) {
+			open = append(open, openFence{line: i})
+		} else if containerClosePattern.MatchString(line) && len(open) > 0 {
+			fence := open[len(open)-1]
+			open = open[:len(open)-1]
+			if fence.lang != "" {
+				blocks = append(blocks, langBlock{lang: fence.lang, open: fence.line, close: i})
+			}
+		}
+	}
+	return blocks
+}
+
+// slideLanguages returns the languages of the :::lang blocks in content, in
+// the order they first appear.
+func slideLanguages(content string) []string {
+	if !strings.Contains(content, ":::") {
+		return nil
+	}
+
+	// Blocks are found by their closing fence, so nested blocks come first
+	blocks := findLangBlocks(strings.Split(content, "\n"))
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].open < blocks[j].open })
+
+	var languages []string
+	for _, block := range blocks {
+		if !slices.Contains(languages, block.lang) {
+			languages = append(languages, block.lang)
+		}
+	}
+	return languages
+}
+
+// selectLanguage returns content with the :::lang blocks of lang unwrapped
+// and those of the other languages removed.
+func selectLanguage(content, lang string) string {
+	lines := strings.Split(content, "\n")
+	blocks := findLangBlocks(lines)
+
+	blockAt := make(map[int]langBlock)
+	for _, block := range blocks {
+		blockAt[block.open] = block
+	}
+
+	var out []string
+	skipFence := make(map[int]bool)
+	for i := 0; i < len(lines); i++ {
+		if block, ok := blockAt[i]; ok {
+			if block.lang != lang {
+				i = block.close // Drop the whole block
+				continue
+			}
+			skipFence[block.close] = true
+			continue
+		}
+		if skipFence[i] {
+			continue
+		}
+		out = append(out, lines[i])
+	}
+	return strings.Join(out, "\n")
+}
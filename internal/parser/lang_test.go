@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSelectLanguage(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		lang  string
+		want  string
+	}{
+		{
+			name:  "unwraps the language and drops the others",
+			input: "# Title\n:::lang en\nHello\n:::\n:::lang da\nHej\n:::\nShared",
+			lang:  "da",
+			want:  "# Title\nHej\nShared",
+		},
+		{
+			name:  "nested containers",
+			input: ":::lang en\n:::note\nEnglish note\n:::\n:::\n:::lang da\n:::note\nDansk note\n:::\n:::",
+			lang:  "en",
+			want:  ":::note\nEnglish note\n:::",
+		},
+		{
+			name:  "fences inside code blocks",
+			input: "```\n:::lang da\n```\n:::lang en\nHello\n:::",
+			lang:  "da",
+			want:  "```\n:::lang da\n```",
+		},
+		{
+			name:  "unclosed block is kept",
+			input: ":::lang en\nHello",
+			lang:  "da",
+			want:  ":::lang en\nHello",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectLanguage(tt.input, tt.lang); got != tt.want {
+				t.Errorf("selectLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlideLanguages(t *testing.T) {
+	input := ":::lang en\nHello\n:::\n:::lang da\n:::lang en\nNested\n:::\n:::\n```\n:::lang de\n```"
+	if got, want := slideLanguages(input), []string{"en", "da"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("slideLanguages() = %q, want %q", got, want)
+	}
+	if got := slideLanguages("# No blocks"); got != nil {
+		t.Errorf("slideLanguages() = %q, want nil", got)
+	}
+}
+
+func TestParse_LangVariants(t *testing.T) {
+	input := "# Hello\n\n:::lang en\nWelcome\n:::\n\n:::lang da\nVelkommen\n:::\n\n" +
+		"???\n\n:::lang en\nEnglish notes\n:::\n\n:::lang da\nDanske noter\n:::\n\n---\n\n# Shared\n"
+
+	pres, err := New().Parse([]byte(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(pres.Slides) != 2 {
+		t.Fatalf("got %d slides, want 2", len(pres.Slides))
+	}
+
+	slide := pres.Slides[0]
+	if len(slide.Variants) != 2 || slide.Variants[0].Lang != "en" || slide.Variants[1].Lang != "da" {
+		t.Fatalf("Variants = %+v, want en and da", slide.Variants)
+	}
+	for _, tt := range []struct {
+		variant  SlideVariant
+		wantHTML string
+		notHTML  string
+		notes    string
+	}{
+		{slide.Variants[0], "Welcome", "Velkommen", "English notes"},
+		{slide.Variants[1], "Velkommen", "Welcome", "Danske noter"},
+	} {
+		v := tt.variant.Slide
+		if !strings.Contains(v.HTML, tt.wantHTML) || strings.Contains(v.HTML, tt.notHTML) {
+			t.Errorf("%s HTML = %q, want %q without %q", tt.variant.Lang, v.HTML, tt.wantHTML, tt.notHTML)
+		}
+		if !strings.Contains(v.Directives.Notes, tt.notes) {
+			t.Errorf("%s Directives.Notes = %q, want %q", tt.variant.Lang, v.Directives.Notes, tt.notes)
+		}
+		if v.Index != 0 {
+			t.Errorf("%s Index = %d, want 0", tt.variant.Lang, v.Index)
+		}
+	}
+
+	// The slide itself is the first language
+	if !strings.Contains(slide.HTML, "Welcome") || strings.Contains(slide.HTML, "Velkommen") {
+		t.Errorf("HTML = %q, want the English variant", slide.HTML)
+	}
+	if pres.Slides[1].Variants != nil {
+		t.Errorf("slide without :::lang blocks has Variants %+v", pres.Slides[1].Variants)
+	}
+}
+
+func TestParse_LangDirective(t *testing.T) {
+	pres, err := New().Parse([]byte("<!-- lang: da -->\n\n# Tak\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := pres.Slides[0].Directives.Lang; got != "da" {
+		t.Errorf("Directives.Lang = %q, want %q", got, "da")
+	}
+}
@@ -3,10 +3,15 @@ package parser
 
 import (
 	"bytes"
+	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/yuin/goldmark"
+	emoji "github.com/yuin/goldmark-emoji"
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/renderer/html"
@@ -16,6 +21,8 @@ import (
 // Presentation represents a parsed markdown presentation.
 type Presentation struct {
 	Slides []Slide
+	// Includes contains the absolute paths of files spliced in via include directives.
+	Includes []string
 }
 
 // Slide represents a single slide in the presentation.
@@ -24,33 +31,93 @@ type Slide struct {
 	Content string
 	// HTML is the rendered HTML of the slide content.
 	HTML string
+	// NotesHTML is the rendered HTML of the speaker notes in Directives.Notes.
+	NotesHTML string
 	// Directives contains per-slide configuration parsed from HTML comments.
 	Directives SlideDirectives
 	// Fragments contains the fragment groups for incremental reveals.
 	Fragments []Fragment
 	// CodeBlocks contains the code blocks found in this slide.
 	CodeBlocks []CodeBlock
+	// Containers contains the :::name container blocks in this slide, in
+	// the order they open.
+	Containers []Container
+	// Embed is the page the slide shows in an iframe, or nil; see Embed.
+	Embed *Embed
+	// ImageHints contains how the images with an img comment are fitted
+	// into their space, in the order they appear.
+	ImageHints []ImageHint
+	// Variants contains the slide rendered for each language of its :::lang
+	// blocks, in the order the languages first appear, or nil if it has
+	// none. The slide itself is the variant of the first language.
+	Variants []SlideVariant
 	// Index is the zero-based slide index.
 	Index int
+	// StartLine and EndLine are the one-based lines of the slide's first and
+	// last non-blank lines in the content passed to Parse, counting the
+	// frontmatter. With includes expanded, they are lines of the merged
+	// content.
+	StartLine int
+	EndLine   int
 }
 
 // SlideDirectives contains per-slide configuration options.
 type SlideDirectives struct {
 	Layout      string
-	Transition  string
+	Transition  string // Transition value, or its name in the extended form
 	Background  string
 	Notes       string
 	Tag         string // Decorative metadata label (e.g., "// workshop")
 	Badge       string // Decorative metadata badge (e.g., "v2.0")
+	Class       string // CSS classes for the slide's wrapper, as written (e.g., "invert center")
+	Lang        string // Language of the slide, for decks in several languages (e.g., "da")
+	AutoAdvance string // How long auto-play shows the slide, as written (e.g., "8s")
 	Fragments   bool
 	Scroll      bool // Enable scroll reveal for long content
 	ScrollSpeed int  // Animation duration in milliseconds (default: 2000)
+	Hidden      bool // Omit from PDF exports (hidden: true or skip: true)
+	// BackgroundLoop and BackgroundMuted are set by the extended form
+	// background: {src: loop.mp4, loop: true, muted: false}, and nil otherwise.
+	BackgroundLoop  *bool
+	BackgroundMuted *bool
+	// TransitionDirection and TransitionDuration are set by the extended form
+	// transition: {name: slide, direction: up, duration: 300ms}, and empty
+	// otherwise.
+	TransitionDirection string
+	TransitionDuration  string
+	// Raw contains the directives without a field above, such as style
+	// overrides, with their values as strings. Values that aren't scalars
+	// are left out.
+	Raw map[string]string
+}
+
+// knownDirectives are the directive keys parsed into the fields of
+// SlideDirectives rather than into Raw.
+var knownDirectives = map[string]bool{
+	"layout":       true,
+	"transition":   true,
+	"background":   true,
+	"notes":        true,
+	"fragments":    true,
+	"scroll":       true,
+	"scroll-speed": true,
+	"tag":          true,
+	"badge":        true,
+	"class":        true,
+	"lang":         true,
+	"autoAdvance":  true,
+	"hidden":       true,
+	"skip":         true,
 }
 
 // Fragment represents a content fragment for incremental reveals.
 type Fragment struct {
 	Content string
 	Index   int
+	// Inline is true for list items of fragments: true slides, which are
+	// revealed in place in the slide HTML. Content is a copy of the item's
+	// HTML, not a block to be shown after the previous fragments.
+	Inline bool
 }
 
 // CodeBlock represents a fenced code block in a slide.
@@ -64,27 +131,78 @@ type CodeBlock struct {
 type CodeBlockMeta struct {
 	Driver     string
 	Connection string
+	Highlight  []int // 1-based line numbers to highlight, sorted and deduplicated
+
+	// Playback options of cast blocks
+	Autoplay bool
+	Loop     bool
+	StartAt  float64 // Seconds into the recording to start at
 }
 
 // Parser handles markdown parsing for presentations.
 type Parser struct {
-	md goldmark.Markdown
+	md               goldmark.Markdown
+	strictDelimiters bool
+}
+
+// Options configures the optional text replacements of a Parser. Neither
+// applies inside code blocks or inline code.
+type Options struct {
+	// Emoji replaces shortcodes such as :rocket: with emoji.
+	Emoji bool
+	// Smartypants replaces -- and --- with an em dash, ... with an
+	// ellipsis, and straight quotes with curly quotes.
+	Smartypants bool
+	// StrictDelimiters only splits slides on "---" lines with a blank line
+	// on both sides; other "---" lines are horizontal rules.
+	StrictDelimiters bool
+}
+
+// DefaultOptions returns the options used by New, with all replacements on.
+func DefaultOptions() Options {
+	return Options{Emoji: true, Smartypants: true}
 }
 
-// New creates a new Parser with goldmark configured for presentation parsing.
-// It enables the following extensions:
-//   - Table: GFM tables
+// New creates a new Parser with goldmark configured for presentation parsing,
+// using DefaultOptions. See NewWithOptions.
+func New() *Parser {
+	return NewWithOptions(DefaultOptions())
+}
+
+// NewWithOptions creates a new Parser with goldmark configured for
+// presentation parsing. It enables the following extensions:
+//   - Table: GFM tables, with column alignment set as inline styles so
+//     themes' cell alignment doesn't override it
 //   - Strikethrough: ~~strikethrough~~ text
 //   - TaskList: - [x] checkboxes
 //   - Linkify: auto-link URLs
-func New() *Parser {
+//   - Footnote: [^1] references with [^1]: definitions, rendered per slide
+//   - DefinitionList: terms followed by ": definition" lines
+//   - Emoji: :shortcode: emoji, if opts.Emoji is set
+//   - Typographer: dashes, ellipses, and curly quotes, if opts.Smartypants is set
+func NewWithOptions(opts Options) *Parser {
+	extensions := []goldmark.Extender{
+		extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignStyle)),
+		extension.Strikethrough,
+		extension.TaskList,
+		extension.Linkify,
+		extension.Footnote,
+		extension.DefinitionList,
+	}
+	if opts.Emoji {
+		extensions = append(extensions, emoji.New(emoji.WithRenderingMethod(emoji.Unicode)))
+	}
+	if opts.Smartypants {
+		// Like the original SmartyPants, -- is an em dash rather than an en dash
+		extensions = append(extensions, extension.NewTypographer(
+			extension.WithTypographicSubstitutions(extension.TypographicSubstitutions{
+				extension.EnDash: []byte("&mdash;"),
+			}),
+		))
+	}
+
 	md := goldmark.New(
-		goldmark.WithExtensions(
-			extension.Table,
-			extension.Strikethrough,
-			extension.TaskList,
-			extension.Linkify,
-		),
+		goldmark.WithExtensions(extensions...),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
 		),
@@ -94,7 +212,8 @@ func New() *Parser {
 	)
 
 	return &Parser{
-		md: md,
+		md:               md,
+		strictDelimiters: opts.StrictDelimiters,
 	}
 }
 
@@ -107,6 +226,69 @@ func (p *Parser) Markdown() goldmark.Markdown {
 // It matches "---" on its own line (with optional surrounding whitespace).
 var slideDelimiter = regexp.MustCompile(`(?m)^---\s*$`)
 
+// escapedDelimiter matches a "\---" line, which is a horizontal rule rather
+// than a slide delimiter.
+var escapedDelimiter = regexp.MustCompile(`^\\---\s*$`)
+
+// blockStart matches the start of a line that begins a markdown block other
+// than a paragraph: an ATX heading, list item, blockquote, table row, HTML
+// block, or code fence.
+var blockStart = regexp.MustCompile("^(#{1,6}(\\s|$)|[-*+](\\s|$)|\\d{1,9}[.)](\\s|$)|>|\\||<|```|~~~)")
+
+// isParagraphText reports whether line is text that a "---" line below it
+// would underline as a setext heading. Indented lines are left out, since
+// they usually continue a list item or are indented code.
+func isParagraphText(line string) bool {
+	if strings.TrimSpace(line) == "" || line[0] == ' ' || line[0] == '\t' {
+		return false
+	}
+	if blockStart.MatchString(line) || strings.HasSuffix(strings.TrimSpace(line), "-->") {
+		return false
+	}
+	return !slideDelimiter.MatchString(line) && !notesDelimiter.MatchString(line)
+}
+
+// isSlideDelimiter reports whether line, which is outside any fenced code
+// block, splits slides, given the lines before and after it ("" at the start
+// and end of the text). A "---" line right below paragraph text underlines a
+// setext heading instead. With strict set, a delimiter also needs a blank line
+// on both sides, so "---" next to content is a horizontal rule.
+func isSlideDelimiter(prev, line, next string, strict bool) bool {
+	if !slideDelimiter.MatchString(line) || isParagraphText(prev) {
+		return false
+	}
+	if strict {
+		return strings.TrimSpace(prev) == "" && strings.TrimSpace(next) == ""
+	}
+	return true
+}
+
+// unescapeDelimiters turns "\---" lines outside fenced code blocks into
+// horizontal rules.
+func unescapeDelimiters(content string) string {
+	lines := strings.Split(content, "\n")
+	insideCodeBlock := false
+	fenceLength := 0
+
+	for i, line := range lines {
+		insideCodeBlock, fenceLength = UpdateCodeFence(line, insideCodeBlock, fenceLength)
+		if !insideCodeBlock && escapedDelimiter.MatchString(line) {
+			lines[i] = "***"
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// lineAt returns lines[i] without a trailing carriage return, or "" if i is
+// out of range.
+func lineAt(lines []string, i int) string {
+	if i < 0 || i >= len(lines) {
+		return ""
+	}
+	return strings.TrimSuffix(lines[i], "\r")
+}
+
 // countLeadingBackticks returns the number of consecutive backticks at the start of a line.
 func countLeadingBackticks(line string) int {
 	count := 0
@@ -120,58 +302,171 @@ func countLeadingBackticks(line string) int {
 	return count
 }
 
+// UpdateCodeFence returns whether the text after line is inside a fenced code
+// block, given the state before it. A fence is at least 3 backticks; a block is
+// closed by a line of at least as many backticks with nothing after them.
+func UpdateCodeFence(line string, insideCodeBlock bool, fenceLength int) (bool, int) {
+	backtickCount := countLeadingBackticks(line)
+	if backtickCount < 3 {
+		return insideCodeBlock, fenceLength
+	}
+	if !insideCodeBlock {
+		// Opening a code block
+		return true, backtickCount
+	}
+	if backtickCount >= fenceLength && strings.TrimSpace(line[backtickCount:]) == "" {
+		// Closing the code block
+		return false, 0
+	}
+	return insideCodeBlock, fenceLength
+}
+
+// notesDelimiter matches a "???" line, which starts the speaker notes of a slide
+// (the remark.js convention).
+var notesDelimiter = regexp.MustCompile(`^\s*\?\?\?\s*$`)
+
+// splitSpeakerNotes splits slide content on the first "???" line outside fenced
+// code blocks. It returns the content before the delimiter and the notes after
+// it, both trimmed. Content without a delimiter is returned unchanged.
+func splitSpeakerNotes(content string) (string, string) {
+	lines := strings.Split(content, "\n")
+	insideCodeBlock := false
+	fenceLength := 0
+
+	for i, line := range lines {
+		insideCodeBlock, fenceLength = UpdateCodeFence(line, insideCodeBlock, fenceLength)
+		if !insideCodeBlock && notesDelimiter.MatchString(line) {
+			slide := strings.TrimSpace(strings.Join(lines[:i], "\n"))
+			notes := strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+			return slide, notes
+		}
+	}
+
+	return content, ""
+}
+
 // SplitSlidesPreservingCodeBlocks splits text on "---" delimiters while preserving
 // code blocks. Any "---" inside a fenced code block (``` or ````) is NOT treated
-// as a slide delimiter.
-func SplitSlidesPreservingCodeBlocks(text string) []string {
+// as a slide delimiter, and neither is one underlining a setext heading. With
+// strict set, delimiters need a blank line on both sides (see Options).
+func SplitSlidesPreservingCodeBlocks(text string, strict bool) []string {
+	parts := splitSlideParts(text, strict)
+	slides := make([]string, len(parts))
+	for i, part := range parts {
+		slides[i] = part.text
+	}
+	return slides
+}
+
+// slidePart is the text of a slide between two delimiters, with the one-based
+// lines of its first and last non-blank lines, which are 0 if it is blank.
+type slidePart struct {
+	text      string
+	startLine int
+	endLine   int
+}
+
+// splitSlideParts splits text into slides like SplitSlidesPreservingCodeBlocks,
+// keeping track of the lines each slide spans.
+func splitSlideParts(text string, strict bool) []slidePart {
 	lines := strings.Split(text, "\n")
-	var slides []string
+	var parts []slidePart
+	var current slidePart
 	var currentSlide strings.Builder
 	insideCodeBlock := false
 	codeBlockFenceLength := 0
 
 	for i, line := range lines {
-		// Check for code block fence (must be at least 3 backticks)
-		backtickCount := countLeadingBackticks(line)
-		if backtickCount >= 3 {
-			if !insideCodeBlock {
-				// Opening a code block
-				insideCodeBlock = true
-				codeBlockFenceLength = backtickCount
-			} else if backtickCount >= codeBlockFenceLength {
-				// Check if this is a closing fence (just backticks, possibly with trailing whitespace)
-				trimmedAfterBackticks := strings.TrimSpace(line[backtickCount:])
-				if trimmedAfterBackticks == "" {
-					// Closing the code block
-					insideCodeBlock = false
-					codeBlockFenceLength = 0
-				}
-			}
-		}
+		insideCodeBlock, codeBlockFenceLength = UpdateCodeFence(line, insideCodeBlock, codeBlockFenceLength)
 
 		// Check for slide delimiter only when not in a code block
-		if !insideCodeBlock && slideDelimiter.MatchString(line) {
+		if !insideCodeBlock && isSlideDelimiter(lineAt(lines, i-1), line, lineAt(lines, i+1), strict) {
 			// End current slide, start new one
-			slides = append(slides, currentSlide.String())
+			current.text = currentSlide.String()
+			parts = append(parts, current)
+			current = slidePart{}
 			currentSlide.Reset()
-		} else {
-			// Add line to current slide
-			if currentSlide.Len() > 0 || i > 0 {
-				// Add newline before line (except for very first line when builder is empty)
-				if currentSlide.Len() > 0 {
-					currentSlide.WriteString("\n")
-				}
+			continue
+		}
+
+		// Add line to current slide, with a newline before all but its first line
+		if currentSlide.Len() > 0 {
+			currentSlide.WriteString("\n")
+		}
+		currentSlide.WriteString(line)
+		if strings.TrimSpace(line) != "" {
+			if current.startLine == 0 {
+				current.startLine = i + 1
 			}
-			currentSlide.WriteString(line)
+			current.endLine = i + 1
 		}
 	}
 
 	// Don't forget the last slide
 	if currentSlide.Len() > 0 {
-		slides = append(slides, currentSlide.String())
+		current.text = currentSlide.String()
+		parts = append(parts, current)
 	}
 
-	return slides
+	return parts
+}
+
+// SlideSpan is the byte range of a slide in markdown text.
+type SlideSpan struct {
+	Start int // Offset of the slide's first byte, just after the preceding delimiter line
+	End   int // Offset just past the slide's last byte, at the start of the next delimiter line
+}
+
+// SlideSpans returns the byte range of each slide in text, splitting on "---"
+// delimiters the same way as SplitSlidesPreservingCodeBlocks. The ranges
+// exclude the delimiter lines, so text[span.Start:span.End] is the slide
+// exactly as written, including its blank lines and line endings. Empty
+// slides (see IsEmptySlide) are skipped, so the result lines up with the
+// slides returned by Parse.
+func SlideSpans(text string, strict bool) []SlideSpan {
+	var spans []SlideSpan
+	start := 0
+	insideCodeBlock := false
+	codeBlockFenceLength := 0
+
+	lines := strings.Split(text, "\n")
+	offset := 0
+	for i := range lines {
+		line := lineAt(lines, i)
+		next := min(offset+len(lines[i])+1, len(text))
+
+		insideCodeBlock, codeBlockFenceLength = UpdateCodeFence(line, insideCodeBlock, codeBlockFenceLength)
+		if !insideCodeBlock && isSlideDelimiter(lineAt(lines, i-1), line, lineAt(lines, i+1), strict) {
+			spans = appendSlideSpan(spans, text, start, offset)
+			start = next
+		}
+		offset = next
+	}
+
+	return appendSlideSpan(spans, text, start, len(text))
+}
+
+// appendSlideSpan appends the span from start to end to spans, unless the
+// slide in it is empty.
+func appendSlideSpan(spans []SlideSpan, text string, start, end int) []SlideSpan {
+	if IsEmptySlide(text[start:end]) {
+		return spans
+	}
+	return append(spans, SlideSpan{Start: start, End: end})
+}
+
+// SlideStartLines returns the one-based line number of the first non-blank
+// line of each slide in text, splitting on "---" delimiters the same way as
+// SplitSlidesPreservingCodeBlocks. Empty slides (see IsEmptySlide) are
+// skipped, so the result lines up with the slides returned by Parse.
+func SlideStartLines(text string, strict bool) []int {
+	var starts []int
+	for _, part := range splitSlideParts(text, strict) {
+		if !IsEmptySlide(part.text) {
+			starts = append(starts, part.startLine)
+		}
+	}
+	return starts
 }
 
 // Parse parses markdown content and returns a Presentation with slides.
@@ -180,11 +475,27 @@ func (p *Parser) Parse(content []byte) (*Presentation, error) {
 	// Convert to string for easier manipulation
 	text := string(content)
 
-	// Skip frontmatter if present
+	// Skip frontmatter if present, counting its lines so slides know
+	// where they are in the file
+	lineOffset := strings.Count(text[:frontmatterEnd(text)], "\n")
 	text = skipFrontmatter(text)
 
 	// Split content on --- delimiter, preserving code blocks
-	parts := SplitSlidesPreservingCodeBlocks(text)
+	parts := splitSlideParts(text, p.strictDelimiters)
+
+	// Footnote definitions can be anywhere, such as all at the end of the
+	// file, so they are collected first and rendered on the slides that
+	// reference them
+	footnotes := make(map[string]string)
+	for i, part := range parts {
+		content, definitions := extractFootnoteDefinitions(part.text)
+		parts[i].text = content
+		for label, definition := range definitions {
+			if _, ok := footnotes[label]; !ok {
+				footnotes[label] = definition
+			}
+		}
+	}
 
 	presentation := &Presentation{
 		Slides: make([]Slide, 0, len(parts)),
@@ -192,64 +503,141 @@ func (p *Parser) Parse(content []byte) (*Presentation, error) {
 
 	for _, part := range parts {
 		// Trim whitespace from slide content
-		slideContent := strings.TrimSpace(part)
+		slideContent := strings.TrimSpace(part.text)
 
 		// Skip empty slides
 		if slideContent == "" {
 			continue
 		}
 
-		// Parse directives from HTML comments at slide start
-		directives, contentAfterDirectives := parseDirectives(slideContent)
+		// Slides with :::lang blocks are rendered for each language. The
+		// slide itself is the variant of the first language.
+		index := len(presentation.Slides)
+		languages := slideLanguages(slideContent)
+		if len(languages) == 0 {
+			slide, err := p.parseSlide(slideContent, part, index, lineOffset, footnotes)
+			if err != nil {
+				return nil, err
+			}
+			presentation.Slides = append(presentation.Slides, slide)
+			continue
+		}
 
-		// Pre-process images with attributes (e.g., {width=50%}) to HTML
-		contentAfterDirectives = transformImageAttributes(contentAfterDirectives)
+		variants := make([]SlideVariant, 0, len(languages))
+		for _, lang := range languages {
+			variant, err := p.parseSlide(selectLanguage(slideContent, lang), part, index, lineOffset, footnotes)
+			if err != nil {
+				return nil, err
+			}
+			variants = append(variants, SlideVariant{Lang: lang, Slide: variant})
+		}
+		slide := variants[0].Slide
+		slide.Variants = variants
+		presentation.Slides = append(presentation.Slides, slide)
+	}
 
-		// Pre-process asciinema code blocks to move info string meta into body
-		contentAfterDirectives = transformAsciinemaBlocks(contentAfterDirectives)
+	return presentation, nil
+}
 
-		// Render markdown to HTML (use content after directives removed)
-		html, err := p.renderHTML([]byte(contentAfterDirectives))
+// parseSlide parses the content of the slide at index, which comes from part
+// of the content passed to Parse.
+func (p *Parser) parseSlide(slideContent string, part slidePart, index, lineOffset int, footnotes map[string]string) (Slide, error) {
+	// Take out the embed first, as its comment would otherwise be read
+	// as a directive when it starts the slide
+	slideContent, embed := extractEmbed(slideContent)
+
+	// Parse directives from HTML comments at slide start
+	directives, contentAfterDirectives := parseDirectives(slideContent)
+
+	// Move notes after a "???" line into the speaker notes
+	contentAfterDirectives, notes := splitSpeakerNotes(contentAfterDirectives)
+	if notes != "" {
+		if directives.Notes != "" {
+			directives.Notes += "\n\n" + notes
+		} else {
+			directives.Notes = notes
+		}
+	}
+
+	// Turn escaped "\---" delimiters into horizontal rules
+	contentAfterDirectives = unescapeDelimiters(contentAfterDirectives)
+
+	// Pre-process images with an img comment, then images with attributes
+	// (e.g., {width=50%}), to HTML
+	contentAfterDirectives, imageHints := transformImageHints(contentAfterDirectives)
+	contentAfterDirectives = transformImageAttributes(contentAfterDirectives)
+
+	// Pre-process asciinema code blocks to move info string meta into body
+	contentAfterDirectives = transformAsciinemaBlocks(contentAfterDirectives)
+
+	// Turn :::name container blocks into wrapper divs for rendering
+	renderContent, containers := transformContainerBlocks(contentAfterDirectives)
+
+	// Render markdown to HTML (use content after directives removed),
+	// with the definitions of the footnotes referenced on the slide
+	slideNumber := index + 1
+	html, err := p.renderHTML([]byte(appendFootnoteDefinitions(renderContent, footnotes)))
+	if err != nil {
+		return Slide{}, err
+	}
+	html = prefixFootnoteIDs(html, strconv.Itoa(slideNumber)+"-")
+
+	// Render the speaker notes like slide content
+	var notesHTML string
+	if directives.Notes != "" {
+		notesHTML, err = p.renderHTML([]byte(directives.Notes))
 		if err != nil {
-			return nil, err
-		}
-
-		// Parse code blocks from the slide content
-		codeBlocks := parseCodeBlocks(contentAfterDirectives)
-
-		// Parse fragments from pause markers and render to HTML
-		fragments := p.parseFragments(contentAfterDirectives)
-
-		// Auto-fragment list items when fragments: true and no explicit pause markers
-		if directives.Fragments && !hasPauseMarkers(contentAfterDirectives) {
-			transformedHTML, listItemCount := autoFragmentListItems(html)
-			if listItemCount > 0 {
-				html = transformedHTML
-				// Create fragment entries for each list item
-				// This tells the frontend how many fragment steps exist
-				fragments = make([]Fragment, listItemCount)
-				for i := 0; i < listItemCount; i++ {
-					fragments[i] = Fragment{
-						Content: "", // Content is inline in the HTML, not in fragment structs
-						Index:   i,
-					}
-				}
-			}
+			return Slide{}, err
 		}
+	}
 
-		slide := Slide{
-			Content:    contentAfterDirectives,
-			HTML:       html,
-			Index:      len(presentation.Slides),
-			Directives: directives,
-			Fragments:  fragments,
-			CodeBlocks: codeBlocks,
+	// Parse code blocks from the slide content
+	codeBlocks := parseCodeBlocks(contentAfterDirectives)
+
+	// Parse fragments from pause markers and render to HTML
+	fragments := p.parseFragments(renderContent, footnotes)
+	for i := range fragments {
+		fragments[i].Content = prefixFootnoteIDs(fragments[i].Content, strconv.Itoa(slideNumber)+"-"+strconv.Itoa(i+1)+"-")
+	}
+
+	// Auto-fragment top-level list items when fragments: true and no explicit pause markers
+	if directives.Fragments && !hasPauseMarkers(contentAfterDirectives) {
+		// The footnotes list at the bottom is not revealed item by item
+		var footnoteSection string
+		html, footnoteSection = splitFootnoteSection(html)
+		items := topLevelListItems(html)
+		if len(items) > 0 {
+			// Each item is revealed inline in the HTML; the fragment content
+			// holds the item's HTML, including any nested lists
+			fragments = make([]Fragment, len(items))
+			for i, item := range items {
+				fragments[i] = Fragment{
+					Content: strings.TrimSpace(html[item.openEnd:item.closeStart]),
+					Index:   i,
+					Inline:  true,
+				}
+			}
+			html, _ = autoFragmentListItems(html)
 		}
+		html += footnoteSection
+	}
 
-		presentation.Slides = append(presentation.Slides, slide)
+	slide := Slide{
+		Content:    contentAfterDirectives,
+		HTML:       html,
+		NotesHTML:  notesHTML,
+		Index:      index,
+		Directives: directives,
+		Fragments:  fragments,
+		CodeBlocks: codeBlocks,
+		Containers: containers,
+		Embed:      embed,
+		ImageHints: imageHints,
+		StartLine:  part.startLine + lineOffset,
+		EndLine:    part.endLine + lineOffset,
 	}
 
-	return presentation, nil
+	return slide, nil
 }
 
 // skipFrontmatter removes YAML frontmatter from the beginning of the content.
@@ -259,24 +647,31 @@ func skipFrontmatter(text string) string {
 	if !strings.HasPrefix(strings.TrimSpace(text), "---") {
 		return text
 	}
+	return strings.TrimRightFunc(text[frontmatterEnd(text):], unicode.IsSpace)
+}
 
-	// Find the first ---
-	text = strings.TrimSpace(text)
-	if !strings.HasPrefix(text, "---") {
-		return text
+// frontmatterEnd returns the offset in text just past the frontmatter's
+// closing "---" and the newline after it, or 0 if text has no frontmatter.
+// Without a closing delimiter, it is the offset of the opening one, past any
+// leading whitespace.
+func frontmatterEnd(text string) int {
+	start := len(text) - len(strings.TrimLeftFunc(text, unicode.IsSpace))
+	if !strings.HasPrefix(text[start:], "---") {
+		return 0
 	}
 
 	// Find the closing ---
-	rest := text[3:] // Skip the first "---"
-	idx := strings.Index(rest, "\n---")
+	idx := strings.Index(text[start+3:], "\n---")
 	if idx == -1 {
-		// No closing delimiter, return original
-		return text
+		return start
 	}
 
 	// Skip past the closing delimiter and any trailing newline
-	afterFrontmatter := rest[idx+4:] // +4 for "\n---"
-	return strings.TrimPrefix(afterFrontmatter, "\n")
+	end := start + 3 + idx + 4 // +4 for "\n---"
+	if strings.HasPrefix(text[end:], "\n") {
+		end++
+	}
+	return end
 }
 
 // renderHTML converts markdown content to HTML.
@@ -323,11 +718,36 @@ func parseDirectives(content string) (SlideDirectives, string) {
 	if layout, ok := yamlData["layout"].(string); ok {
 		directives.Layout = layout
 	}
-	if transition, ok := yamlData["transition"].(string); ok {
+	switch transition := yamlData["transition"].(type) {
+	case string:
 		directives.Transition = transition
+	case map[string]interface{}:
+		// Extended form: {name: slide, direction: up, duration: 300ms}
+		if name, ok := transition["name"].(string); ok {
+			directives.Transition = name
+		}
+		if direction, ok := transition["direction"].(string); ok {
+			directives.TransitionDirection = direction
+		}
+		switch duration := transition["duration"].(type) {
+		case string, int, float64:
+			directives.TransitionDuration = fmt.Sprint(duration)
+		}
 	}
-	if background, ok := yamlData["background"].(string); ok {
+	switch background := yamlData["background"].(type) {
+	case string:
 		directives.Background = background
+	case map[string]interface{}:
+		// Extended form: {src: loop.mp4, loop: true, muted: false}
+		if src, ok := background["src"].(string); ok {
+			directives.Background = src
+		}
+		if loop, ok := background["loop"].(bool); ok {
+			directives.BackgroundLoop = &loop
+		}
+		if muted, ok := background["muted"].(bool); ok {
+			directives.BackgroundMuted = &muted
+		}
 	}
 	if notes, ok := yamlData["notes"].(string); ok {
 		directives.Notes = notes
@@ -347,6 +767,41 @@ func parseDirectives(content string) (SlideDirectives, string) {
 	if badge, ok := yamlData["badge"].(string); ok {
 		directives.Badge = badge
 	}
+	switch class := yamlData["class"].(type) {
+	case string:
+		directives.Class = class
+	case []interface{}:
+		// List form: [invert, center]
+		names := make([]string, 0, len(class))
+		for _, name := range class {
+			names = append(names, fmt.Sprint(name))
+		}
+		directives.Class = strings.Join(names, " ")
+	}
+	if lang, ok := yamlData["lang"].(string); ok {
+		directives.Lang = lang
+	}
+	switch autoAdvance := yamlData["autoAdvance"].(type) {
+	case string, int, float64:
+		directives.AutoAdvance = fmt.Sprint(autoAdvance)
+	}
+	for _, key := range []string{"hidden", "skip"} {
+		if hidden, ok := yamlData[key].(bool); ok && hidden {
+			directives.Hidden = true
+		}
+	}
+	for key, value := range yamlData {
+		if knownDirectives[key] {
+			continue
+		}
+		switch value.(type) {
+		case string, bool, int, float64:
+			if directives.Raw == nil {
+				directives.Raw = make(map[string]string)
+			}
+			directives.Raw[key] = fmt.Sprint(value)
+		}
+	}
 
 	// Remove the directive comment from content
 	remainingContent := strings.TrimPrefix(content, match[0])
@@ -359,6 +814,14 @@ func parseDirectives(content string) (SlideDirectives, string) {
 // Example: sql {driver: mysql, connection: mydb}
 var metaPattern = regexp.MustCompile(`\{([^}]*)\}\s*$`)
 
+// lineRangesPattern matches a bare line range list used as highlight shorthand.
+// Example: go {1,3-5}
+var lineRangesPattern = regexp.MustCompile(`^[\d\s,-]+$`)
+
+// highlightKeyPattern matches a highlight key and its line ranges inside code block metadata.
+// Example: sql {driver: mysql, highlight: 2-4}
+var highlightKeyPattern = regexp.MustCompile(`highlight\s*[:=]\s*["']?([\d\s,-]*\d)["']?`)
+
 // pausePattern matches <!-- pause --> markers for fragment splitting.
 // Supports variations: <!-- pause -->, <!--pause-->, <!-- pause-->, etc.
 var pausePattern = regexp.MustCompile(`(?m)^\s*<!--\s*pause\s*-->\s*$`)
@@ -392,7 +855,7 @@ func parseCodeBlocks(content string) []CodeBlock {
 
 			// Parse metadata inside {}
 			metaContent := metaMatch[1]
-			block.Meta = parseCodeBlockMeta(metaContent)
+			block.Meta = parseCodeBlockMeta(metaContent, countLines(code))
 		} else {
 			// No metadata, just language
 			block.Language = infoString
@@ -407,9 +870,23 @@ func parseCodeBlocks(content string) []CodeBlock {
 // parseCodeBlockMeta parses the content inside {} in code block info strings.
 // Supports both YAML-like (key: value) and simple (key=value) formats.
 // Example: "driver: mysql, connection: mydb" or "driver=mysql, connection=mydb"
-func parseCodeBlockMeta(content string) CodeBlockMeta {
+// Highlighted lines are limited to the block's n lines.
+func parseCodeBlockMeta(content string, n int) CodeBlockMeta {
 	meta := CodeBlockMeta{}
 
+	// Bare line ranges are shorthand for highlight: {1,3-5}
+	if lineRangesPattern.MatchString(content) {
+		meta.Highlight = parseLineRanges(content, n)
+		return meta
+	}
+
+	// Extract highlight ranges before key/value parsing, since they may contain commas
+	if match := highlightKeyPattern.FindStringSubmatchIndex(content); match != nil {
+		meta.Highlight = parseLineRanges(content[match[2]:match[3]], n)
+		content = strings.Trim(content[:match[0]]+content[match[1]:], " ,")
+		content = strings.ReplaceAll(content, ", ,", ",")
+	}
+
 	// Try parsing as YAML first
 	var yamlData map[string]interface{}
 	// Wrap in braces for valid YAML map format
@@ -420,6 +897,9 @@ func parseCodeBlockMeta(content string) CodeBlockMeta {
 		if connection, ok := yamlData["connection"].(string); ok {
 			meta.Connection = connection
 		}
+		for key, value := range yamlData {
+			setPlaybackOption(&meta, key, fmt.Sprint(value))
+		}
 		return meta
 	}
 
@@ -444,16 +924,88 @@ func parseCodeBlockMeta(content string) CodeBlockMeta {
 			meta.Driver = value
 		case "connection":
 			meta.Connection = value
+		default:
+			setPlaybackOption(&meta, key, value)
 		}
 	}
 
 	return meta
 }
 
+// setPlaybackOption sets the cast playback option key to value. Unknown keys
+// and values that don't parse are ignored.
+func setPlaybackOption(meta *CodeBlockMeta, key, value string) {
+	switch key {
+	case "autoplay", "autoPlay":
+		meta.Autoplay, _ = strconv.ParseBool(value)
+	case "loop":
+		meta.Loop, _ = strconv.ParseBool(value)
+	case "start", "startAt":
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+			meta.StartAt = seconds
+		}
+	}
+}
+
+// parseLineRanges parses a comma-separated list of line numbers and ranges
+// (e.g., "1,3-5") into sorted, deduplicated line numbers of a block of n
+// lines. Ranges are clamped to the block before they are expanded, so a typo
+// like "1-30000000" costs no more than highlighting every line. Invalid
+// entries such as "0", "5-3", or "abc", and lines past the end of the block,
+// are ignored.
+func parseLineRanges(spec string, n int) []int {
+	seen := make(map[int]bool)
+	var lines []int
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		startStr, endStr, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(startStr))
+		if err != nil {
+			continue
+		}
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(endStr))
+			if err != nil {
+				continue
+			}
+		}
+		if start < 1 || end < start || start > n {
+			continue
+		}
+		end = min(end, n)
+
+		for line := start; line <= end; line++ {
+			if !seen[line] {
+				seen[line] = true
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	sort.Ints(lines)
+	return lines
+}
+
+// countLines returns the number of lines in a code block.
+func countLines(code string) int {
+	code = strings.TrimSuffix(code, "\n")
+	if code == "" {
+		return 0
+	}
+	return strings.Count(code, "\n") + 1
+}
+
 // parseFragments splits slide content on <!-- pause --> markers.
 // It returns a slice of Fragment structs, each containing HTML content for incremental reveal.
 // If no pause markers are found, returns a single fragment with all content as HTML.
-func (p *Parser) parseFragments(content string) []Fragment {
+// Each fragment gets the definitions of the footnotes it references.
+func (p *Parser) parseFragments(content string, footnotes map[string]string) []Fragment {
 	// Split content on pause markers
 	parts := pausePattern.Split(content, -1)
 
@@ -467,8 +1019,9 @@ func (p *Parser) parseFragments(content string) []Fragment {
 			continue
 		}
 
-		// Render fragment content to HTML
-		html, err := p.renderHTML([]byte(trimmedContent))
+		// Render fragment content to HTML, with the definitions of the
+		// footnotes referenced in the fragment
+		html, err := p.renderHTML([]byte(appendFootnoteDefinitions(trimmedContent, footnotes)))
 		if err != nil {
 			// If rendering fails, use the raw content
 			html = trimmedContent
@@ -488,39 +1041,85 @@ func (p *Parser) parseFragments(content string) []Fragment {
 	return fragments
 }
 
-// liPattern matches <li> opening tags (with or without attributes).
-var liPattern = regexp.MustCompile(`<li(\s[^>]*)?>`)
+// listTagPattern matches opening and closing list and list item tags.
+// Group 1: "/" for closing tags, Group 2: tag name.
+var listTagPattern = regexp.MustCompile(`<(/?)(ul|ol|li)(?:\s[^>]*)?>`)
+
+// listItemSpan locates a list item in HTML.
+type listItemSpan struct {
+	openStart  int // Start of the <li> tag
+	openEnd    int // End of the <li> tag
+	closeStart int // Start of the matching </li> tag
+}
+
+// topLevelListItems finds the list items that are not nested inside another list item.
+// Items of nested lists are part of their parent item's span.
+func topLevelListItems(html string) []listItemSpan {
+	var items []listItemSpan
+	listDepth := 0
+	current := -1
+
+	for _, loc := range listTagPattern.FindAllStringSubmatchIndex(html, -1) {
+		closing := loc[3] > loc[2]
+		tag := html[loc[4]:loc[5]]
+
+		switch {
+		case tag != "li" && !closing:
+			listDepth++
+		case tag != "li" && closing:
+			listDepth--
+		case listDepth != 1:
+			// Item of a nested list
+		case !closing:
+			items = append(items, listItemSpan{openStart: loc[0], openEnd: loc[1], closeStart: len(html)})
+			current = len(items) - 1
+		case current >= 0:
+			items[current].closeStart = loc[0]
+			current = -1
+		}
+	}
 
-// autoFragmentListItems transforms HTML to add fragment classes to list items.
-// It adds class="fragment fragment-hidden" and data-fragment-index attributes to each <li> element.
+	return items
+}
+
+// autoFragmentListItems transforms HTML to add fragment classes to top-level list items.
+// It adds class="fragment fragment-hidden" and data-fragment-index attributes to each top-level <li> element.
+// Nested list items are left unchanged so they are revealed with their parent item.
 // The fragment-hidden class ensures items are hidden initially until revealed by navigation.
 // Returns the transformed HTML and the number of list items found.
 func autoFragmentListItems(html string) (string, int) {
-	fragmentIndex := 0
-
-	result := liPattern.ReplaceAllStringFunc(html, func(match string) string {
-		index := fragmentIndex
-		fragmentIndex++
+	items := topLevelListItems(html)
+
+	var b strings.Builder
+	last := 0
+	for index, item := range items {
+		b.WriteString(html[last:item.openStart])
+		b.WriteString(fragmentListItemTag(html[item.openStart:item.openEnd], index))
+		last = item.openEnd
+	}
+	b.WriteString(html[last:])
 
-		// Check if the <li> already has attributes
-		if match == "<li>" {
-			return `<li class="fragment fragment-hidden" data-fragment-index="` + intToString(index) + `">`
-		}
+	return b.String(), len(items)
+}
 
-		// Has existing attributes - need to merge class if present or add it
-		// Check if there's already a class attribute
-		if strings.Contains(match, `class="`) {
-			// Insert "fragment fragment-hidden " at the start of the existing class value
-			return strings.Replace(match, `class="`, `class="fragment fragment-hidden `, 1) +
-				` data-fragment-index="` + intToString(index) + `"`
-		}
+// fragmentListItemTag adds the fragment class and index to an <li> opening tag.
+func fragmentListItemTag(match string, index int) string {
+	// Check if the <li> already has attributes
+	if match == "<li>" {
+		return `<li class="fragment fragment-hidden" data-fragment-index="` + intToString(index) + `">`
+	}
 
-		// No class attribute, add both class and data-fragment-index
-		// Insert before the closing >
-		return match[:len(match)-1] + ` class="fragment fragment-hidden" data-fragment-index="` + intToString(index) + `">`
-	})
+	// Has existing attributes - need to merge class if present or add it
+	// Check if there's already a class attribute
+	if strings.Contains(match, `class="`) {
+		// Insert "fragment fragment-hidden " at the start of the existing class value
+		match = strings.Replace(match, `class="`, `class="fragment fragment-hidden `, 1)
+		return match[:len(match)-1] + ` data-fragment-index="` + intToString(index) + `">`
+	}
 
-	return result, fragmentIndex
+	// No class attribute, add both class and data-fragment-index
+	// Insert before the closing >
+	return match[:len(match)-1] + ` class="fragment fragment-hidden" data-fragment-index="` + intToString(index) + `">`
 }
 
 // intToString converts an integer to a string without importing strconv.
@@ -557,25 +1156,7 @@ func transformImageAttributes(content string) string {
 		}
 
 		// Build style attribute
-		var styles []string
-		if img.Attributes.Width != "" {
-			styles = append(styles, "width: "+img.Attributes.Width)
-		}
-		if img.Attributes.Position != "" {
-			switch img.Attributes.Position {
-			case "left":
-				styles = append(styles, "float: left", "margin-right: 1em")
-			case "right":
-				styles = append(styles, "float: right", "margin-left: 1em")
-			case "center":
-				styles = append(styles, "display: block", "margin-left: auto", "margin-right: auto")
-			}
-		}
-
-		if img.Attributes.Border == "none" {
-			styles = append(styles, "border: none", "box-shadow: none")
-		}
-
+		styles := imageAttributeStyles(img.Attributes)
 		styleAttr := ""
 		if len(styles) > 0 {
 			styleAttr = ` style="` + strings.Join(styles, "; ") + `"`
@@ -591,6 +1172,29 @@ func transformImageAttributes(content string) string {
 	return content
 }
 
+// imageAttributeStyles returns the CSS declarations for image attributes.
+func imageAttributeStyles(attrs ImageAttributes) []string {
+	var styles []string
+	if attrs.Width != "" {
+		styles = append(styles, "width: "+attrs.Width)
+	}
+	if attrs.Position != "" {
+		switch attrs.Position {
+		case "left":
+			styles = append(styles, "float: left", "margin-right: 1em")
+		case "right":
+			styles = append(styles, "float: right", "margin-left: 1em")
+		case "center":
+			styles = append(styles, "display: block", "margin-left: auto", "margin-right: auto")
+		}
+	}
+
+	if attrs.Border == "none" {
+		styles = append(styles, "border: none", "box-shadow: none")
+	}
+	return styles
+}
+
 // asciinemaInfoPattern matches the info string of an asciinema fenced code block.
 // Captures: (1) metadata content inside braces
 // Example: ```asciinema {src: "./demo.cast", autoPlay: true}
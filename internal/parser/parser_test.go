@@ -1,6 +1,9 @@
 package parser
 
 import (
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -268,6 +271,80 @@ More content here.`)
 	}
 }
 
+func TestParse_BackgroundVideoDirective(t *testing.T) {
+	p := New()
+	content := []byte(`<!-- background: {src: ./media/loop.mp4, loop: true, muted: false} -->
+# Title
+
+---
+
+<!-- background: ./media/loop.mp4 -->
+# Short form`)
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	extended := pres.Slides[0].Directives
+	if extended.Background != "./media/loop.mp4" {
+		t.Errorf("expected background from src, got %q", extended.Background)
+	}
+	if extended.BackgroundLoop == nil || !*extended.BackgroundLoop {
+		t.Error("expected loop to be true")
+	}
+	if extended.BackgroundMuted == nil || *extended.BackgroundMuted {
+		t.Error("expected muted to be false")
+	}
+	if _, ok := extended.Raw["background"]; ok {
+		t.Error("background should not be a raw directive")
+	}
+
+	short := pres.Slides[1].Directives
+	if short.Background != "./media/loop.mp4" || short.BackgroundLoop != nil || short.BackgroundMuted != nil {
+		t.Errorf("expected only the background to be set, got %+v", short)
+	}
+}
+
+func TestParse_TransitionDirective(t *testing.T) {
+	p := New()
+	content := []byte(`<!-- transition: {name: slide, direction: up, duration: 300ms} -->
+# Extended
+
+---
+
+<!-- transition: {name: push, duration: 250} -->
+# Bare duration
+
+---
+
+<!-- transition: slide-left 400ms -->
+# Short form`)
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	extended := pres.Slides[0].Directives
+	if extended.Transition != "slide" || extended.TransitionDirection != "up" || extended.TransitionDuration != "300ms" {
+		t.Errorf("expected slide, up, 300ms, got %q, %q, %q", extended.Transition, extended.TransitionDirection, extended.TransitionDuration)
+	}
+	if _, ok := extended.Raw["transition"]; ok {
+		t.Error("transition should not be a raw directive")
+	}
+
+	bare := pres.Slides[1].Directives
+	if bare.Transition != "push" || bare.TransitionDirection != "" || bare.TransitionDuration != "250" {
+		t.Errorf("expected push with duration 250, got %q, %q, %q", bare.Transition, bare.TransitionDirection, bare.TransitionDuration)
+	}
+
+	short := pres.Slides[2].Directives
+	if short.Transition != "slide-left 400ms" || short.TransitionDirection != "" || short.TransitionDuration != "" {
+		t.Errorf("expected only the transition to be set, got %+v", short)
+	}
+}
+
 func TestParse_SlideDirectives(t *testing.T) {
 	p := New()
 	content := []byte(`<!--
@@ -358,6 +435,113 @@ func TestParse_DirectivesPartial(t *testing.T) {
 	}
 }
 
+func TestParse_HiddenDirective(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    bool
+	}{
+		{"hidden", "<!-- hidden: true -->\n# Backup", true},
+		{"skip", "<!-- skip: true -->\n# Backup", true},
+		{"hidden false", "<!-- hidden: false -->\n# Visible", false},
+		{"no directive", "# Visible", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pres, err := New().Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() returned error: %v", err)
+			}
+			if len(pres.Slides) != 1 {
+				t.Fatalf("expected 1 slide, got %d", len(pres.Slides))
+			}
+			if got := pres.Slides[0].Directives.Hidden; got != tt.want {
+				t.Errorf("Hidden = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_ClassDirective(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		want       string
+		wantLayout string
+	}{
+		{"single", "<!-- class: invert -->\n# Title", "invert", ""},
+		{"several", "<!-- class: invert center -->\n# Title", "invert center", ""},
+		{"list", "<!-- class: [invert, center] -->\n# Title", "invert center", ""},
+		{"with other directives", "<!--\nlayout: section\nclass: invert, wide\ntransition: fade\n-->\n# Title", "invert, wide", "section"},
+		{"no directive", "# Title", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pres, err := New().Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() returned error: %v", err)
+			}
+			directives := pres.Slides[0].Directives
+			if directives.Class != tt.want {
+				t.Errorf("Class = %q, want %q", directives.Class, tt.want)
+			}
+			if directives.Layout != tt.wantLayout {
+				t.Errorf("Layout = %q, want %q", directives.Layout, tt.wantLayout)
+			}
+			if _, ok := directives.Raw["class"]; ok {
+				t.Error("class should not be in Raw")
+			}
+		})
+	}
+}
+
+func TestParse_RawDirectives(t *testing.T) {
+	p := New()
+	content := []byte(`<!--
+layout: title
+accent: "#ff5500"
+fontScale: 1.2
+padding: 40px
+custom: true
+nested:
+  key: value
+-->
+# Styled`)
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	directives := pres.Slides[0].Directives
+	if directives.Layout != "title" {
+		t.Errorf("expected layout 'title', got %q", directives.Layout)
+	}
+
+	// Known directives and values that aren't scalars are left out
+	want := map[string]string{
+		"accent":    "#ff5500",
+		"fontScale": "1.2",
+		"padding":   "40px",
+		"custom":    "true",
+	}
+	if !reflect.DeepEqual(directives.Raw, want) {
+		t.Errorf("Raw = %v, want %v", directives.Raw, want)
+	}
+}
+
+func TestParse_NoRawDirectives(t *testing.T) {
+	pres, err := New().Parse([]byte("<!-- layout: section -->\n# Section"))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if raw := pres.Slides[0].Directives.Raw; raw != nil {
+		t.Errorf("Raw = %v, want nil", raw)
+	}
+}
+
 func TestParse_DirectivesNotAtStart(t *testing.T) {
 	p := New()
 	// Directive comment not at the start should not be parsed as directives
@@ -464,6 +648,66 @@ notes: |
 	}
 }
 
+func TestParse_SpeakerNotesDelimiter(t *testing.T) {
+	p := New()
+	content := []byte("# Title\n\nVisible text\n\n???\n\nSay this out loud.\n\n- first\n- second\n\n---\n\n# Second\n\nNo notes here")
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if len(pres.Slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(pres.Slides))
+	}
+
+	slide := pres.Slides[0]
+	if slide.Directives.Notes != "Say this out loud.\n\n- first\n- second" {
+		t.Errorf("unexpected notes %q", slide.Directives.Notes)
+	}
+	if strings.Contains(slide.Content, "???") || strings.Contains(slide.Content, "out loud") {
+		t.Errorf("notes should be removed from content, got %q", slide.Content)
+	}
+	if strings.Contains(slide.HTML, "out loud") || !strings.Contains(slide.HTML, "Visible text") {
+		t.Errorf("notes should not be rendered in HTML, got %q", slide.HTML)
+	}
+	if pres.Slides[1].Directives.Notes != "" {
+		t.Errorf("expected no notes on second slide, got %q", pres.Slides[1].Directives.Notes)
+	}
+}
+
+func TestParse_SpeakerNotesDelimiterAppendsToDirectiveNotes(t *testing.T) {
+	p := New()
+	content := []byte("<!-- notes: From the directive -->\n# Title\n\n???\nFrom the slide body")
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	want := "From the directive\n\nFrom the slide body"
+	if got := pres.Slides[0].Directives.Notes; got != want {
+		t.Errorf("expected notes %q, got %q", want, got)
+	}
+}
+
+func TestParse_SpeakerNotesDelimiterIgnoredInCodeBlocks(t *testing.T) {
+	p := New()
+	content := []byte("# Code\n\n```text\n???\nstill code\n```\n\n???\nThe real notes")
+
+	pres, err := p.Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	slide := pres.Slides[0]
+	if slide.Directives.Notes != "The real notes" {
+		t.Errorf("expected notes after the code block, got %q", slide.Directives.Notes)
+	}
+	if len(slide.CodeBlocks) != 1 || !strings.Contains(slide.CodeBlocks[0].Code, "???\nstill code") {
+		t.Errorf("expected ??? to stay inside the code block, got %+v", slide.CodeBlocks)
+	}
+}
+
 func TestParse_CodeBlocks_Simple(t *testing.T) {
 	p := New()
 	content := []byte("# Slide\n\n```sql\nSELECT * FROM users;\n```")
@@ -680,7 +924,7 @@ func TestParse_CodeBlocks_AcrossSlides(t *testing.T) {
 }
 
 func TestParseCodeBlockMeta_YAMLFormat(t *testing.T) {
-	meta := parseCodeBlockMeta("driver: mysql, connection: prod")
+	meta := parseCodeBlockMeta("driver: mysql, connection: prod", 10)
 	if meta.Driver != "mysql" {
 		t.Errorf("expected driver 'mysql', got %q", meta.Driver)
 	}
@@ -690,7 +934,7 @@ func TestParseCodeBlockMeta_YAMLFormat(t *testing.T) {
 }
 
 func TestParseCodeBlockMeta_OnlyDriver(t *testing.T) {
-	meta := parseCodeBlockMeta("driver: postgres")
+	meta := parseCodeBlockMeta("driver: postgres", 10)
 	if meta.Driver != "postgres" {
 		t.Errorf("expected driver 'postgres', got %q", meta.Driver)
 	}
@@ -700,7 +944,7 @@ func TestParseCodeBlockMeta_OnlyDriver(t *testing.T) {
 }
 
 func TestParseCodeBlockMeta_Empty(t *testing.T) {
-	meta := parseCodeBlockMeta("")
+	meta := parseCodeBlockMeta("", 10)
 	if meta.Driver != "" {
 		t.Errorf("expected empty driver, got %q", meta.Driver)
 	}
@@ -728,6 +972,93 @@ func TestParseCodeBlocks_Direct(t *testing.T) {
 	}
 }
 
+func TestParseCodeBlockMeta_HighlightShorthand(t *testing.T) {
+	meta := parseCodeBlockMeta("1,3-5", 10)
+	want := []int{1, 3, 4, 5}
+	if !reflect.DeepEqual(meta.Highlight, want) {
+		t.Errorf("expected highlight %v, got %v", want, meta.Highlight)
+	}
+}
+
+func TestParseCodeBlockMeta_HighlightKey(t *testing.T) {
+	tests := []struct {
+		content    string
+		highlight  []int
+		driver     string
+		connection string
+	}{
+		{"driver: mysql, highlight: 2-4", []int{2, 3, 4}, "mysql", ""},
+		{"highlight: 1,3-4, driver: mysql, connection: prod", []int{1, 3, 4}, "mysql", "prod"},
+		{`highlight: "2,4"`, []int{2, 4}, "", ""},
+	}
+
+	for _, tt := range tests {
+		meta := parseCodeBlockMeta(tt.content, 10)
+		if !reflect.DeepEqual(meta.Highlight, tt.highlight) {
+			t.Errorf("%q: expected highlight %v, got %v", tt.content, tt.highlight, meta.Highlight)
+		}
+		if meta.Driver != tt.driver || meta.Connection != tt.connection {
+			t.Errorf("%q: expected driver %q and connection %q, got %q and %q",
+				tt.content, tt.driver, tt.connection, meta.Driver, meta.Connection)
+		}
+	}
+}
+
+func TestParseLineRanges_IgnoresInvalid(t *testing.T) {
+	lines := parseLineRanges("5-3, 0, 2, x-1, 2-3, 7", 10)
+	want := []int{2, 3, 7}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("expected %v, got %v", want, lines)
+	}
+}
+
+func TestParseLineRanges_ClampsToBlock(t *testing.T) {
+	tests := []struct {
+		spec string
+		n    int
+		want []int
+	}{
+		{"1-30000000", 3, []int{1, 2, 3}},
+		{"2, 5-9, 4", 4, []int{2, 4}},
+		{"99999999999999999999", 3, nil},
+		{"1-99999999999999999999", 3, nil},
+		{"1-3", 0, nil},
+	}
+
+	for _, tt := range tests {
+		if got := parseLineRanges(tt.spec, tt.n); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseLineRanges(%q, %d) = %v, want %v", tt.spec, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestParseCodeBlocks_HugeHighlightRange(t *testing.T) {
+	blocks := parseCodeBlocks("```go {1-30000000}\na\nb\n```")
+
+	if len(blocks) != 1 {
+		t.Fatalf("expected 1 block, got %d", len(blocks))
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(blocks[0].Meta.Highlight, want) {
+		t.Errorf("expected highlight %v, got %v", want, blocks[0].Meta.Highlight)
+	}
+}
+
+func TestParseCodeBlocks_HighlightBeyondCodeLength(t *testing.T) {
+	content := "```go {1,3-5}\nline1\nline2\nline3\n```"
+	blocks := parseCodeBlocks(content)
+
+	if len(blocks) != 1 {
+		t.Fatalf("expected 1 block, got %d", len(blocks))
+	}
+	if blocks[0].Language != "go" {
+		t.Errorf("expected language 'go', got %q", blocks[0].Language)
+	}
+	want := []int{1, 3}
+	if !reflect.DeepEqual(blocks[0].Meta.Highlight, want) {
+		t.Errorf("expected highlight %v, got %v", want, blocks[0].Meta.Highlight)
+	}
+}
+
 // Fragment parsing tests
 
 func TestParse_Fragments_SinglePause(t *testing.T) {
@@ -1065,7 +1396,7 @@ Content 2`)
 func TestParseFragments_Direct(t *testing.T) {
 	p := New()
 	content := "Part 1\n\n<!-- pause -->\n\nPart 2\n\n<!-- pause -->\n\nPart 3"
-	fragments := p.parseFragments(content)
+	fragments := p.parseFragments(content, nil)
 
 	if len(fragments) != 3 {
 		t.Fatalf("expected 3 fragments, got %d", len(fragments))
@@ -1084,7 +1415,7 @@ func TestParseFragments_Direct(t *testing.T) {
 
 func TestParseFragments_EmptyContent(t *testing.T) {
 	p := New()
-	fragments := p.parseFragments("")
+	fragments := p.parseFragments("", nil)
 	if len(fragments) != 0 {
 		t.Errorf("expected 0 fragments for empty content, got %d", len(fragments))
 	}
@@ -1093,7 +1424,7 @@ func TestParseFragments_EmptyContent(t *testing.T) {
 func TestParseFragments_OnlyPauses(t *testing.T) {
 	p := New()
 	content := "<!-- pause -->\n<!-- pause -->\n<!-- pause -->"
-	fragments := p.parseFragments(content)
+	fragments := p.parseFragments(content, nil)
 	// All empty, should result in no fragments
 	if len(fragments) != 0 {
 		t.Errorf("expected 0 fragments for only pause markers, got %d", len(fragments))
@@ -1206,10 +1537,14 @@ fragments: true
 		t.Fatalf("expected 3 fragments for auto-fragmented list, got %d", len(slide.Fragments))
 	}
 
-	// Fragment content should be empty (content is inline in HTML)
+	// Fragment content should be the rendered HTML of each item
 	for i, frag := range slide.Fragments {
-		if frag.Content != "" {
-			t.Errorf("fragment %d should have empty content for inline fragments, got %q", i, frag.Content)
+		want := fmt.Sprintf("Item %d", i+1)
+		if frag.Content != want {
+			t.Errorf("fragment %d content = %q, want %q", i, frag.Content, want)
+		}
+		if frag.Index != i {
+			t.Errorf("fragment %d has incorrect index: %d", i, frag.Index)
 		}
 	}
 
@@ -1222,6 +1557,125 @@ fragments: true
 	}
 }
 
+func TestParse_FragmentsDirectiveNestedLists(t *testing.T) {
+	p := New()
+	content := `<!-- fragments: true -->
+
+# Title
+
+1. First **step**
+   - Detail A
+   - Detail B
+2. Second step
+
+- Other list`
+
+	pres, err := p.Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slide := pres.Slides[0]
+
+	// Nested items stay attached to their parent
+	if len(slide.Fragments) != 3 {
+		t.Fatalf("expected 3 fragments for top-level items, got %d", len(slide.Fragments))
+	}
+	first := slide.Fragments[0].Content
+	if !contains(first, "First <strong>step</strong>") || !contains(first, "<li>Detail A</li>") || !contains(first, "<li>Detail B</li>") {
+		t.Errorf("first fragment should contain the item and its nested list, got %q", first)
+	}
+	if slide.Fragments[1].Content != "Second step" || slide.Fragments[2].Content != "Other list" {
+		t.Errorf("unexpected fragment content: %q, %q", slide.Fragments[1].Content, slide.Fragments[2].Content)
+	}
+
+	// Only top-level items are revealed individually
+	if strings.Count(slide.HTML, "data-fragment-index") != 3 {
+		t.Errorf("expected 3 fragment items in HTML, got:\n%s", slide.HTML)
+	}
+	if !contains(slide.HTML, `<li class="fragment fragment-hidden" data-fragment-index="2">Other list</li>`) {
+		t.Errorf("expected index to continue across lists, got:\n%s", slide.HTML)
+	}
+}
+
+func TestParse_FragmentsDirectiveKeepsHeading(t *testing.T) {
+	p := New()
+	content := `<!-- fragments: true -->
+
+# Agenda
+
+Intro text
+
+- One
+- Two`
+
+	pres, err := p.Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slide := pres.Slides[0]
+
+	if !contains(slide.HTML, "<h1") || !contains(slide.HTML, "Agenda") || !contains(slide.HTML, "<p>Intro text</p>") {
+		t.Errorf("expected the heading and text to stay in the slide HTML, got:\n%s", slide.HTML)
+	}
+	if len(slide.Fragments) != 2 {
+		t.Fatalf("expected 2 fragments, got %d", len(slide.Fragments))
+	}
+	// The frontend shows the slide HTML with the items revealed in place,
+	// instead of replacing it with the fragments' content
+	for i, frag := range slide.Fragments {
+		if !frag.Inline {
+			t.Errorf("fragment %d should be inline", i)
+		}
+	}
+
+	// Fragments from pause markers are blocks
+	pres, err = p.Parse([]byte("# Title\n\n<!-- pause -->\n\nMore"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, frag := range pres.Slides[0].Fragments {
+		if frag.Inline {
+			t.Errorf("pause marker fragment %d should not be inline", i)
+		}
+	}
+}
+
+func TestParse_FragmentsDirectivePauseMarkersTakePrecedence(t *testing.T) {
+	p := New()
+	content := `<!-- fragments: true -->
+
+- Item 1
+- Item 2
+
+<!-- pause -->
+
+Conclusion`
+
+	pres, err := p.Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	slide := pres.Slides[0]
+
+	if len(slide.Fragments) != 2 {
+		t.Fatalf("expected 2 fragments from pause markers, got %d", len(slide.Fragments))
+	}
+	if !contains(slide.Fragments[1].Content, "Conclusion") {
+		t.Errorf("second fragment should contain the content after the pause, got %q", slide.Fragments[1].Content)
+	}
+	if contains(slide.HTML, "data-fragment-index") {
+		t.Error("list items should not be auto-fragmented when pause markers exist")
+	}
+}
+
+func TestAutoFragmentListItems_ExistingClassKeepsTagValid(t *testing.T) {
+	result, _ := autoFragmentListItems(`<ul><li class="done">Item</li></ul>`)
+	want := `<ul><li class="fragment fragment-hidden done" data-fragment-index="0">Item</li></ul>`
+	if result != want {
+		t.Errorf("autoFragmentListItems() = %q, want %q", result, want)
+	}
+}
+
 // helper function to check if a string contains a substring
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(substr) == 0 ||
@@ -1420,9 +1874,34 @@ func TestSplitSlidesPreservingCodeBlocks_Direct(t *testing.T) {
 	}{
 		{
 			name:     "no code blocks",
-			input:    "slide 1\n---\nslide 2",
+			input:    "slide 1\n\n---\nslide 2",
+			expected: 2,
+		},
+		{
+			name:     "setext heading underline",
+			input:    "Title\n---\nslide 1",
+			expected: 1,
+		},
+		{
+			name:     "after a heading",
+			input:    "# slide 1\n---\nslide 2",
 			expected: 2,
 		},
+		{
+			name:     "after a list",
+			input:    "- slide 1\n---\nslide 2",
+			expected: 2,
+		},
+		{
+			name:     "after a directive comment",
+			input:    "<!-- layout: title -->\n---\nslide 2",
+			expected: 2,
+		},
+		{
+			name:     "escaped delimiter",
+			input:    "slide 1\n\n\\---\n\nslide 1",
+			expected: 1,
+		},
 		{
 			name:     "--- in code block",
 			input:    "slide 1\n```\n---\n```\n---\nslide 2",
@@ -1457,7 +1936,7 @@ func TestSplitSlidesPreservingCodeBlocks_Direct(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := SplitSlidesPreservingCodeBlocks(tt.input)
+			result := SplitSlidesPreservingCodeBlocks(tt.input, false)
 			// Filter empty slides (like the real parser does)
 			nonEmpty := 0
 			for _, s := range result {
@@ -1477,3 +1956,438 @@ func TestSplitSlidesPreservingCodeBlocks_Direct(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitSlidesPreservingCodeBlocks_Strict(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "blank lines around delimiter",
+			input:    "slide 1\n\n---\n\nslide 2",
+			expected: []string{"slide 1\n", "slide 2"},
+		},
+		{
+			name:     "content below delimiter",
+			input:    "# slide 1\n\n---\nstill slide 1",
+			expected: []string{"# slide 1\n\n---\nstill slide 1"},
+		},
+		{
+			name:     "content above delimiter",
+			input:    "# slide 1\n---\n\nstill slide 1",
+			expected: []string{"# slide 1\n---\n\nstill slide 1"},
+		},
+		{
+			name:     "delimiter at start and end",
+			input:    "---\n\nslide 1\n\n---",
+			expected: []string{"", "slide 1\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitSlidesPreservingCodeBlocks(tt.input, true); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SplitSlidesPreservingCodeBlocks() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParse_SetextHeading(t *testing.T) {
+	content := []byte(`---
+title: Setext
+---
+
+Big Title
+---------
+
+Subtitle
+---
+
+More text
+
+---
+
+# Second`)
+
+	pres, err := New().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if len(pres.Slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(pres.Slides))
+	}
+	if !contains(pres.Slides[0].HTML, `<h2 id="big-title">Big Title</h2>`) {
+		t.Errorf("expected setext heading, got %s", pres.Slides[0].HTML)
+	}
+	if !contains(pres.Slides[0].HTML, `<h2 id="subtitle">Subtitle</h2>`) || !contains(pres.Slides[0].HTML, "More text") {
+		t.Errorf("expected the second setext heading on the first slide, got %s", pres.Slides[0].HTML)
+	}
+}
+
+func TestParse_YAMLDocumentMarkers(t *testing.T) {
+	// YAML outside a code block keeps its "---" lines on the slide
+	content := []byte(`# Config
+
+name: first
+---
+name: second
+
+---
+
+# Next`)
+
+	pres, err := New().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if len(pres.Slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(pres.Slides))
+	}
+	if !contains(pres.Slides[0].Content, "name: second") {
+		t.Errorf("expected both YAML documents on the first slide, got %q", pres.Slides[0].Content)
+	}
+}
+
+func TestParse_EscapedDelimiter(t *testing.T) {
+	content := []byte("# Rule\n\nAbove\n\\---\nBelow\n\n```\n\\---\n```\n\n---\n\n# Next")
+
+	pres, err := New().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if len(pres.Slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(pres.Slides))
+	}
+	html := pres.Slides[0].HTML
+	if !contains(html, "<p>Above</p>\n<hr>\n<p>Below</p>") {
+		t.Errorf("expected a horizontal rule, got %s", html)
+	}
+	if !contains(html, "<code>\\---\n</code>") {
+		t.Errorf("expected the escape to be kept in code blocks, got %s", html)
+	}
+}
+
+func TestParse_StrictDelimiters(t *testing.T) {
+	content := []byte(`---
+title: Strict
+---
+
+# First
+---
+Below the rule
+
+---
+
+# Second`)
+
+	pres, err := NewWithOptions(Options{StrictDelimiters: true}).Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if len(pres.Slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(pres.Slides))
+	}
+	if !contains(pres.Slides[0].HTML, "<hr>") || !contains(pres.Slides[0].HTML, "Below the rule") {
+		t.Errorf("expected a horizontal rule on the first slide, got %s", pres.Slides[0].HTML)
+	}
+
+	// Without strict delimiters, the "---" below the heading splits slides
+	pres, err = New().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if len(pres.Slides) != 3 {
+		t.Fatalf("expected 3 slides, got %d", len(pres.Slides))
+	}
+}
+
+func TestSlideStartLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []int
+	}{
+		{
+			name:     "single slide",
+			input:    "# Title\n\nText",
+			expected: []int{1},
+		},
+		{
+			name:     "blank lines after delimiter",
+			input:    "# One\n\n---\n\n\n# Two",
+			expected: []int{1, 6},
+		},
+		{
+			name:     "--- in code block",
+			input:    "# One\n```\n---\n```\n---\n# Two",
+			expected: []int{1, 6},
+		},
+		{
+			name:     "empty slides are skipped",
+			input:    "\n---\n\n---\n# Three\n---\n",
+			expected: []int{5},
+		},
+		{
+			name:     "setext heading",
+			input:    "One\n---\n\n---\n# Two",
+			expected: []int{1, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SlideStartLines(tt.input, false); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SlideStartLines() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParse_SlideLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  [][2]int
+	}{
+		{
+			name:  "frontmatter",
+			input: "---\ntitle: Test\ntheme: paper\n---\n\n# One\n\nText\n\n---\n\n# Two\n",
+			want:  [][2]int{{6, 8}, {12, 12}},
+		},
+		{
+			name:  "--- in code block",
+			input: "# One\n\n```yaml\n---\nkey: value\n```\n\n---\n# Two\n\nText",
+			want:  [][2]int{{1, 6}, {9, 11}},
+		},
+		{
+			name:  "CRLF line endings",
+			input: "---\r\ntitle: Test\r\n---\r\n\r\n# One\r\n\r\n---\r\n\r\n# Two\r\nText\r\n",
+			want:  [][2]int{{5, 5}, {9, 10}},
+		},
+		{
+			name:  "empty slides and notes",
+			input: "\n---\n\n---\n# Three\n\n???\nNotes\n\n---\n",
+			want:  [][2]int{{5, 8}},
+		},
+	}
+
+	p := New()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pres, err := p.Parse([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("Parse() returned error: %v", err)
+			}
+			var got [][2]int
+			for _, slide := range pres.Slides {
+				got = append(got, [2]int{slide.StartLine, slide.EndLine})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("slide lines = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlideSpans(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "single slide",
+			input:    "# Title\n\nText",
+			expected: []string{"# Title\n\nText"},
+		},
+		{
+			name:     "blank lines around the delimiter are kept",
+			input:    "# One\n\n---\n\n\n# Two\n",
+			expected: []string{"# One\n\n", "\n\n# Two\n"},
+		},
+		{
+			name:     "trailing spaces after the delimiter",
+			input:    "# One\n---   \n# Two",
+			expected: []string{"# One\n", "# Two"},
+		},
+		{
+			name:     "CRLF line endings",
+			input:    "# One\r\n\r\n---\r\n\r\n# Two\r\n",
+			expected: []string{"# One\r\n\r\n", "\r\n# Two\r\n"},
+		},
+		{
+			name:     "--- in code block",
+			input:    "# One\n```\n---\n```\n---\n# Two",
+			expected: []string{"# One\n```\n---\n```\n", "# Two"},
+		},
+		{
+			name:     "empty slides are skipped",
+			input:    "\n---\n\n---\n# Three\n---\n",
+			expected: []string{"# Three\n"},
+		},
+		{
+			name:     "setext heading with CRLF line endings",
+			input:    "One\r\n---\r\n\r\n---\r\n# Two",
+			expected: []string{"One\r\n---\r\n\r\n", "# Two"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, span := range SlideSpans(tt.input, false) {
+				got = append(got, tt.input[span.Start:span.End])
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("SlideSpans() slides = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParse_EmojiAndSmartypants(t *testing.T) {
+	content := []byte(`# Launch :rocket:
+
+Ship it -- "today" or it's late...
+
+Run ` + "`tap dev --port 8080 :rocket:`" + ` first.
+
+` + "```sh" + `
+tap build --single-file :tada:
+echo "done"
+` + "```" + `
+
+---
+
+| Flag | Meaning |
+|---|---|
+| ` + "`--watch`" + ` | Rebuild -- always |`)
+
+	pres, err := New().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	// The --- delimiter still splits slides
+	if len(pres.Slides) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(pres.Slides))
+	}
+
+	html := pres.Slides[0].HTML
+	for _, want := range []string{
+		"Launch 🚀</h1>",
+		"Ship it &mdash; &ldquo;today&rdquo; or it&rsquo;s late&hellip;",
+		"<code>tap dev --port 8080 :rocket:</code>",
+		"tap build --single-file :tada:\necho &quot;done&quot;",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected HTML to contain %q, got:\n%s", want, html)
+		}
+	}
+	if code := pres.Slides[0].CodeBlocks[0].Code; !strings.Contains(code, `--single-file :tada:`) {
+		t.Errorf("code block should be unchanged, got %q", code)
+	}
+
+	table := pres.Slides[1].HTML
+	if !strings.Contains(table, "<code>--watch</code>") || !strings.Contains(table, "Rebuild &mdash; always") {
+		t.Errorf("expected replacements outside inline code only, got:\n%s", table)
+	}
+}
+
+func TestParse_EmojiAndSmartypantsDisabled(t *testing.T) {
+	p := NewWithOptions(Options{})
+	pres, err := p.Parse([]byte(`Use --verbose for "details" :rocket:`))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	if want := `<p>Use --verbose for &quot;details&quot; :rocket:</p>`; !strings.Contains(pres.Slides[0].HTML, want) {
+		t.Errorf("expected literal text, got %q", pres.Slides[0].HTML)
+	}
+
+	// Each option can be turned off on its own
+	pres, err = NewWithOptions(Options{Emoji: true}).Parse([]byte(`--verbose :rocket:`))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	if want := `<p>--verbose 🚀</p>`; !strings.Contains(pres.Slides[0].HTML, want) {
+		t.Errorf("expected only emoji replacements, got %q", pres.Slides[0].HTML)
+	}
+}
+
+func TestParse_NotesHTML(t *testing.T) {
+	content := []byte(`<!-- notes: Open with the **demo** -->
+# Demo
+
+???
+- Run ` + "`tap dev`" + `
+- Show the reload
+
+---
+
+# No Notes`)
+
+	pres, err := New().Parse(content)
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	notesHTML := pres.Slides[0].NotesHTML
+	for _, want := range []string{
+		"<p>Open with the <strong>demo</strong></p>",
+		"<li>Run <code>tap dev</code></li>",
+		"<li>Show the reload</li>",
+	} {
+		if !strings.Contains(notesHTML, want) {
+			t.Errorf("expected notes HTML to contain %q, got:\n%s", want, notesHTML)
+		}
+	}
+
+	// The markdown of the notes is kept
+	if !strings.Contains(pres.Slides[0].Directives.Notes, "- Run `tap dev`") {
+		t.Errorf("expected the notes markdown to be kept, got %q", pres.Slides[0].Directives.Notes)
+	}
+
+	if pres.Slides[1].NotesHTML != "" {
+		t.Errorf("expected no notes HTML, got %q", pres.Slides[1].NotesHTML)
+	}
+}
+
+func TestParseCodeBlockMeta_Playback(t *testing.T) {
+	tests := []struct {
+		content  string
+		autoplay bool
+		loop     bool
+		startAt  float64
+	}{
+		{"autoplay: true, start: 12", true, false, 12},
+		{"autoPlay: true, loop: true, startAt: 3.5", true, true, 3.5},
+		{"start: soon, autoplay: maybe", false, false, 0},
+		{"start: -4", false, false, 0},
+	}
+
+	for _, tt := range tests {
+		meta := parseCodeBlockMeta(tt.content, 10)
+		if meta.Autoplay != tt.autoplay || meta.Loop != tt.loop || meta.StartAt != tt.startAt {
+			t.Errorf("%q: expected autoplay %v, loop %v and start %v, got %v, %v and %v",
+				tt.content, tt.autoplay, tt.loop, tt.startAt, meta.Autoplay, meta.Loop, meta.StartAt)
+		}
+	}
+}
+
+func TestParseCodeBlocks_Cast(t *testing.T) {
+	content := "```cast {autoplay: true, start: 12}\n./demo.cast\n```"
+	blocks := parseCodeBlocks(content)
+
+	if len(blocks) != 1 || blocks[0].Language != "cast" {
+		t.Fatalf("expected 1 cast block, got %+v", blocks)
+	}
+	if !blocks[0].Meta.Autoplay || blocks[0].Meta.StartAt != 12 {
+		t.Errorf("expected autoplay from 12s, got %+v", blocks[0].Meta)
+	}
+	if blocks[0].Code != "./demo.cast" {
+		t.Errorf("expected the path as the code, got %q", blocks[0].Code)
+	}
+}
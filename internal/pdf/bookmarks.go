@@ -0,0 +1,119 @@
+package pdf
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+// maxBookmarkTitle is the maximum length of a bookmark title in characters.
+const maxBookmarkTitle = 100
+
+// slideTitles returns the title of every slide in the presentation, indexed
+// by slide. Titles from ExportOptions.SlideTitles take precedence over the
+// headings in the presentation's table of contents. Slides without either
+// are titled "Slide N".
+func slideTitles(override []string, pres *presentationInfo) []string {
+	count := len(override)
+	if pres != nil {
+		count = max(count, len(pres.Slides))
+	}
+
+	titles := make([]string, count)
+	if pres != nil {
+		var collect func(entries []tocEntry)
+		collect = func(entries []tocEntry) {
+			for _, entry := range entries {
+				if entry.SlideIndex >= 0 && entry.SlideIndex < count {
+					titles[entry.SlideIndex] = entry.Title
+				}
+				collect(entry.Children)
+			}
+		}
+		collect(pres.TOC)
+	}
+	for i, title := range override {
+		if title != "" {
+			titles[i] = title
+		}
+	}
+	for i, title := range titles {
+		titles[i] = truncateTitle(strings.Join(strings.Fields(title), " "))
+		if titles[i] == "" {
+			titles[i] = fmt.Sprintf("Slide %d", i+1)
+		}
+	}
+	return titles
+}
+
+// truncateTitle shortens a title to maxBookmarkTitle characters, ending it
+// with an ellipsis when it was cut.
+func truncateTitle(title string) string {
+	runes := []rune(title)
+	if len(runes) <= maxBookmarkTitle {
+		return title
+	}
+	return strings.TrimSpace(string(runes[:maxBookmarkTitle-1])) + "…"
+}
+
+// slideBookmarks returns one bookmark per exported slide, where slides holds
+// the zero-based indices of the exported slides in page order. Section slides
+// are top-level bookmarks, and the slides that follow a section are nested
+// under it until the next section slide. Slides after a section that was not
+// exported are top-level.
+func slideBookmarks(slides []int, titles []string, pres *presentationInfo) []pdfcpu.Bookmark {
+	isSection := func(i int) bool {
+		return pres != nil && i < len(pres.Slides) && pres.Slides[i].Layout == "section"
+	}
+
+	var bookmarks []pdfcpu.Bookmark
+	chapter := -1      // index in bookmarks of the current section
+	chapterSlide := -1 // slide index of the current section
+	currentSection := -1
+	next := 0 // next slide to check for a section
+
+	for page, i := range slides {
+		// Find the section slide the exported slide belongs to, which may not be exported
+		for ; next <= i; next++ {
+			if isSection(next) {
+				currentSection = next
+			}
+		}
+
+		title := fmt.Sprintf("Slide %d", i+1)
+		if i < len(titles) {
+			title = titles[i]
+		}
+		bookmark := pdfcpu.Bookmark{Title: title, PageFrom: page + 1}
+
+		switch {
+		case isSection(i):
+			bookmarks = append(bookmarks, bookmark)
+			chapter = len(bookmarks) - 1
+			chapterSlide = i
+		case chapter >= 0 && currentSection == chapterSlide:
+			bookmarks[chapter].Kids = append(bookmarks[chapter].Kids, bookmark)
+		default:
+			bookmarks = append(bookmarks, bookmark)
+		}
+	}
+
+	return bookmarks
+}
+
+// addBookmarks writes bookmarks into the outline of an existing PDF file,
+// replacing any existing outline.
+func (e *Exporter) addBookmarks(pdfPath string, bookmarks []pdfcpu.Bookmark) error {
+	if len(bookmarks) == 0 {
+		return nil
+	}
+
+	conf := model.NewDefaultConfiguration()
+	if err := api.AddBookmarksFile(pdfPath, pdfPath, bookmarks, true, conf); err != nil {
+		return fmt.Errorf("failed to add bookmarks: %w", err)
+	}
+	return nil
+}
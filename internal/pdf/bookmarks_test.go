@@ -0,0 +1,240 @@
+package pdf
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+func TestSlideTitles(t *testing.T) {
+	pres := &presentationInfo{
+		Slides: make([]slideInfo, 4),
+		TOC: []tocEntry{
+			{Title: "Intro", SlideIndex: 0, Children: []tocEntry{{Title: "Agenda", SlideIndex: 1}}},
+			{Title: "Results", SlideIndex: 3},
+		},
+	}
+
+	got := slideTitles([]string{"", "Overridden  \n title"}, pres)
+	want := []string{"Intro", "Overridden title", "Slide 3", "Results"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("slideTitles() = %q, want %q", got, want)
+	}
+
+	// Without presentation data, only the given titles are known
+	got = slideTitles([]string{"First"}, nil)
+	if !reflect.DeepEqual(got, []string{"First"}) {
+		t.Errorf("slideTitles() without presentation = %q", got)
+	}
+}
+
+func TestTruncateTitle(t *testing.T) {
+	short := strings.Repeat("a", maxBookmarkTitle)
+	if got := truncateTitle(short); got != short {
+		t.Errorf("truncateTitle() changed a title of %d characters", maxBookmarkTitle)
+	}
+
+	long := strings.Repeat("é", maxBookmarkTitle+20)
+	got := truncateTitle(long)
+	if n := utf8.RuneCountInString(got); n != maxBookmarkTitle {
+		t.Errorf("truncateTitle() length = %d, want %d", n, maxBookmarkTitle)
+	}
+	if !strings.HasSuffix(got, "…") {
+		t.Errorf("truncateTitle() = %q, want trailing ellipsis", got)
+	}
+}
+
+func TestSlideBookmarks(t *testing.T) {
+	pres := &presentationInfo{Slides: []slideInfo{
+		{},                   // 0: before any section
+		{Layout: "section"},  // 1
+		{},                   // 2
+		{},                   // 3
+		{Layout: "section"},  // 4: not exported
+		{},                   // 5
+		{Layout: "section"},  // 6
+		{Layout: "two-cols"}, // 7
+	}}
+	titles := []string{"Title", "Part 1", "A", "B", "Part 2", "C", "Part 3", "D"}
+
+	got := slideBookmarks([]int{0, 1, 2, 3, 5, 6, 7}, titles, pres)
+	want := []pdfcpu.Bookmark{
+		{Title: "Title", PageFrom: 1},
+		{Title: "Part 1", PageFrom: 2, Kids: []pdfcpu.Bookmark{
+			{Title: "A", PageFrom: 3},
+			{Title: "B", PageFrom: 4},
+		}},
+		{Title: "C", PageFrom: 5}, // its section was not exported
+		{Title: "Part 3", PageFrom: 6, Kids: []pdfcpu.Bookmark{
+			{Title: "D", PageFrom: 7},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("slideBookmarks() =\n%+v\nwant\n%+v", got, want)
+	}
+
+	// Without presentation data, every slide is top-level
+	got = slideBookmarks([]int{0, 2}, nil, nil)
+	want = []pdfcpu.Bookmark{{Title: "Slide 1", PageFrom: 1}, {Title: "Slide 3", PageFrom: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("slideBookmarks() without presentation = %+v, want %+v", got, want)
+	}
+}
+
+func TestAddBookmarks(t *testing.T) {
+	dir := t.TempDir()
+
+	var images []string
+	for i := 0; i < 3; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("slide-%03d.png", i))
+		writeTestPNG(t, path)
+		images = append(images, path)
+	}
+
+	output := filepath.Join(dir, "out.pdf")
+	e := &Exporter{}
+	if err := e.imagesToPDF(images, output); err != nil {
+		t.Fatalf("imagesToPDF() error = %v", err)
+	}
+
+	bookmarks := []pdfcpu.Bookmark{
+		{Title: "Part 1", PageFrom: 1, Kids: []pdfcpu.Bookmark{{Title: "Details", PageFrom: 2}}},
+		{Title: "Summary", PageFrom: 3},
+	}
+	if err := e.addBookmarks(output, bookmarks); err != nil {
+		t.Fatalf("addBookmarks() error = %v", err)
+	}
+
+	f, err := os.Open(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	got, err := api.Bookmarks(f, nil)
+	if err != nil {
+		t.Fatalf("failed to read bookmarks: %v", err)
+	}
+	if len(got) != 2 || got[0].Title != "Part 1" || got[1].Title != "Summary" || got[1].PageFrom != 3 {
+		t.Fatalf("unexpected bookmarks: %+v", got)
+	}
+	if len(got[0].Kids) != 1 || got[0].Kids[0].Title != "Details" || got[0].Kids[0].PageFrom != 2 {
+		t.Errorf("unexpected nested bookmarks: %+v", got[0].Kids)
+	}
+}
+
+func TestRenderNotesHTML(t *testing.T) {
+	bookmarks := []pdfcpu.Bookmark{
+		{Title: "Part <1>", PageFrom: 1, Kids: []pdfcpu.Bookmark{{Title: "Details", PageFrom: 2}}},
+	}
+
+	page := renderNotesHTML([]int{3, 4}, []string{"", "<p>Say <strong>hello</strong></p>\n"}, bookmarks)
+
+	for _, want := range []string{
+		`<h1 class="slide-title">Part &lt;1&gt;</h1>`,
+		`<h2 class="slide-title">Details</h2>`,
+		`<div class="slide-number">Slide 4</div>`,
+		`<div class="notes-body"><p>Say <strong>hello</strong></p>`,
+		`<p class="no-notes">No notes for this slide</p>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("notes HTML missing %q", want)
+		}
+	}
+	if strings.Contains(page, "<h1>Speaker Notes</h1>") {
+		t.Error("document title should not be a heading, so it doesn't appear in the outline")
+	}
+}
+
+func TestRenderNotesHTML_Markdown(t *testing.T) {
+	// Notes are rendered by the parser and read from the presentation JSON,
+	// as exportNotes gets them from the presentation API
+	content, err := os.ReadFile(filepath.Join("..", "..", "testdata", "notes-test.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := parser.New().Parse(content)
+	if err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+	data, err := json.Marshal(transformer.New(config.DefaultConfig()).Transform(parsed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pres presentationInfo
+	if err := json.Unmarshal(data, &pres); err != nil {
+		t.Fatal(err)
+	}
+
+	slides := []int{0, 1, 2}
+	var notes []string
+	for _, i := range slides {
+		notes = append(notes, pres.Slides[i].NotesHTML)
+	}
+	page := renderNotesHTML(slides, notes, nil)
+
+	for _, want := range []string{
+		"<li>Latency &amp; throughput</li>",
+		"<code>tap build --offline</code>",
+		"a &lt; b",
+		"<p>Then take questions.</p>",
+		"<strong>R&amp;D</strong>",
+		`<p class="no-notes">No notes for this slide</p>`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("notes HTML missing %q:\n%s", want, page)
+		}
+	}
+	if strings.Count(page, `<div class="slide-notes">`) != 3 {
+		t.Errorf("expected a section per slide:\n%s", page)
+	}
+}
+
+func TestNotesTextToHTML(t *testing.T) {
+	tests := []struct {
+		notes any
+		want  string
+	}{
+		{"Say <hello>\nThen pause\n", "<p>Say &lt;hello&gt;<br>Then pause</p>"},
+		{"  \n", ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := notesTextToHTML(tt.notes); got != tt.want {
+			t.Errorf("notesTextToHTML(%q) = %q, want %q", tt.notes, got, tt.want)
+		}
+	}
+}
+
+// writeTestPNG writes a small solid PNG image to path.
+func writeTestPNG(t *testing.T, path string) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 16, 9))
+	for y := 0; y < 9; y++ {
+		for x := 0; x < 16; x++ {
+			img.Set(x, y, color.RGBA{R: 200, G: 100, B: 50, A: 255})
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, img); err != nil {
+		t.Fatal(err)
+	}
+}
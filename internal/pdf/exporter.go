@@ -4,14 +4,19 @@ package pdf
 import (
 	"context"
 	"fmt"
+	"html"
 	"image"
 	"image/png"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/MiniCodeMonkey/tap/internal/render"
 	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
 	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
 	"github.com/playwright-community/playwright-go"
@@ -27,13 +32,39 @@ const (
 	ContentNotes ContentType = "notes"
 	// ContentBoth exports both slides and notes.
 	ContentBoth ContentType = "both"
+	// ContentHandout exports several slides per page, each next to space
+	// for notes.
+	ContentHandout ContentType = "handout"
+)
+
+// Mode specifies how slides are rendered into the PDF.
+type Mode string
+
+const (
+	// ModeRaster captures each slide as a screenshot. Text can't be selected
+	// or searched, but canvas and WebGL effects look exactly as on screen.
+	ModeRaster Mode = "raster"
+	// ModeVector prints each slide as a PDF page, so text stays selectable
+	// and searchable and the file is much smaller.
+	ModeVector Mode = "vector"
 )
 
 // ExportOptions configures the PDF export process.
 type ExportOptions struct {
-	// Content specifies what to include: "slides", "notes", or "both".
-	// Default is "slides".
+	// Content specifies what to include: "slides", "notes", "both", or
+	// "handout". Default is "slides".
 	Content ContentType
+	// Mode specifies how slides are rendered: "raster" or "vector".
+	// Default is "raster". It only applies to the "slides" content type.
+	Mode Mode
+	// SlidesPerPage is the number of slides on each page of a handout: 2, 3,
+	// 4, or 6. Default is DefaultSlidesPerPage. It only applies to the
+	// "handout" content type.
+	SlidesPerPage int
+	// HandoutNotes prints each slide's speaker notes next to it in a
+	// handout, instead of lines for attendee notes. Slides without notes
+	// still get lines.
+	HandoutNotes bool
 	// Output is the path for the generated PDF file.
 	// If empty, defaults to "presentation.pdf" in the current directory.
 	Output string
@@ -41,12 +72,63 @@ type ExportOptions struct {
 	Title string
 	// Author is the PDF document author metadata.
 	Author string
+	// Slides selects the slides to export as 1-based numbers and ranges,
+	// e.g. "1-5,8,10-12". If empty, all slides are exported.
+	// Hidden slides are never exported.
+	Slides string
+	// SlideTitles are the bookmark titles of the slides, indexed by zero-based
+	// slide index. Empty or missing titles fall back to the slide's heading from
+	// the presentation's table of contents, then to "Slide N".
+	SlideTitles []string
+	// Lang is the language to export, for presentations in several
+	// languages. Default is the presentation's default language. It only
+	// applies to ExportFile.
+	Lang string
+	// Headers are extra HTTP headers sent with every request to the server,
+	// such as credentials for a password-protected dev server.
+	Headers map[string]string
+	// EmbedSource attaches the markdown file, the files it includes, and the
+	// local images and other files its slides reference to the PDF, with a
+	// sources.json listing their paths, so the presentation can be recovered
+	// from an archived PDF. It only applies to ExportFile.
+	EmbedSource bool
+	// MaxSourceSize is the largest total size in bytes of the files attached
+	// with EmbedSource; larger sources are an error, reported before the
+	// PDF is written. Default is DefaultMaxSourceSize.
+	MaxSourceSize int64
+	// CreationDate, if set, is written as the PDF's creation and modification
+	// dates instead of the time of the export, and the file identifier is
+	// derived from the content, so exporting the same slides gives the same
+	// bytes. See ParseSourceDateEpoch.
+	CreationDate time.Time
+	// Progress is called after each slide is captured and when the PDF is assembled.
+	// It is called synchronously from the export and may be nil.
+	Progress func(current, total int, stage string)
+
+	// sources are the files attached to the PDF, collected by ExportFile
+	// with EmbedSource.
+	sources []sourceFile
+}
+
+const (
+	// StageCapture is reported to Progress after each slide is captured.
+	StageCapture = "capture"
+	// StageAssemble is reported to Progress while the captured slides are combined into a PDF.
+	StageAssemble = "assemble"
+)
+
+// reportProgress calls the Progress callback if one is set.
+func (o ExportOptions) reportProgress(current, total int, stage string) {
+	if o.Progress != nil {
+		o.Progress(current, total, stage)
+	}
 }
 
 // DefaultExportOptions returns the default export options.
 func DefaultExportOptions() ExportOptions {
 	return ExportOptions{
 		Content: ContentSlides,
+		Mode:    ModeRaster,
 		Output:  "presentation.pdf",
 	}
 }
@@ -54,19 +136,23 @@ func DefaultExportOptions() ExportOptions {
 // ExportResult contains information about the completed export.
 type ExportResult struct {
 	// OutputPath is the path to the generated PDF file.
-	OutputPath string
+	OutputPath string `json:"outputPath"`
 	// PageCount is the number of pages in the PDF.
-	PageCount int
+	PageCount int `json:"pageCount"`
 	// Duration is how long the export took.
-	Duration time.Duration
+	Duration time.Duration `json:"durationNs"`
 	// FileSize is the size of the generated PDF in bytes.
-	FileSize int64
+	FileSize int64 `json:"fileSize"`
+	// SourceFiles is the number of source files attached with EmbedSource.
+	SourceFiles int `json:"sourceFiles"`
+	// Warnings are the problems found while loading the presentation that
+	// didn't stop the export, such as broken custom themes.
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // Exporter handles PDF generation from tap presentations.
 type Exporter struct {
-	pw      *playwright.Playwright
-	browser playwright.Browser
+	browser *render.Browser
 }
 
 // New creates a new Exporter.
@@ -75,60 +161,21 @@ func New() (*Exporter, error) {
 	return &Exporter{}, nil
 }
 
-// launchBrowser lazily launches the browser when needed.
-func (e *Exporter) launchBrowser() error {
-	if e.browser != nil {
-		return nil
-	}
-
-	// Install browsers if not already installed
-	err := playwright.Install(&playwright.RunOptions{
-		Browsers: []string{"chromium"},
-	})
-	if err != nil {
-		return fmt.Errorf("failed to install playwright browsers: %w", err)
+// launchBrowser lazily launches the browser when needed, installing it
+// first if it isn't installed.
+func (e *Exporter) launchBrowser() (playwright.Browser, error) {
+	if e.browser == nil {
+		e.browser = render.NewBrowser(true)
 	}
-
-	pw, err := playwright.Run()
-	if err != nil {
-		return fmt.Errorf("failed to start playwright: %w", err)
-	}
-	e.pw = pw
-
-	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
-		Headless: playwright.Bool(true),
-	})
-	if err != nil {
-		_ = e.pw.Stop()
-		return fmt.Errorf("failed to launch chromium: %w", err)
-	}
-	e.browser = browser
-
-	return nil
+	return e.browser.Launch()
 }
 
 // Close cleans up browser resources.
 func (e *Exporter) Close() error {
-	var errs []error
-
-	if e.browser != nil {
-		if err := e.browser.Close(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to close browser: %w", err))
-		}
-		e.browser = nil
-	}
-
-	if e.pw != nil {
-		if err := e.pw.Stop(); err != nil {
-			errs = append(errs, fmt.Errorf("failed to stop playwright: %w", err))
-		}
-		e.pw = nil
-	}
-
-	if len(errs) > 0 {
-		return errs[0]
+	if e.browser == nil {
+		return nil
 	}
-	return nil
+	return e.browser.Close()
 }
 
 // Export generates a PDF from a running presentation server.
@@ -140,9 +187,38 @@ func (e *Exporter) Export(ctx context.Context, serverURL string, opts ExportOpti
 	if opts.Content == "" {
 		opts.Content = ContentSlides
 	}
+	if opts.Mode == "" {
+		opts.Mode = ModeRaster
+	}
 	if opts.Output == "" {
 		opts.Output = "presentation.pdf"
 	}
+	if opts.SlidesPerPage == 0 {
+		opts.SlidesPerPage = DefaultSlidesPerPage
+	}
+	if opts.Content == ContentHandout {
+		if err := ValidateSlidesPerPage(opts.SlidesPerPage); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check the slide selection before launching the browser so an invalid
+	// range fails fast. Hidden slides are only known when the server provides
+	// the presentation API; otherwise all slides are treated as visible.
+	ranges, err := parseSlideRanges(opts.Slides)
+	if err != nil {
+		return nil, err
+	}
+	var selected []int
+	pres, err := fetchPresentation(ctx, serverURL, opts.Headers)
+	if err == nil {
+		if len(pres.Slides) == 0 {
+			return nil, fmt.Errorf("no slides found in presentation")
+		}
+		if selected, err = selectSlides(ranges, pres.Slides); err != nil {
+			return nil, err
+		}
+	}
 
 	// Ensure output directory exists
 	outputDir := filepath.Dir(opts.Output)
@@ -153,16 +229,18 @@ func (e *Exporter) Export(ctx context.Context, serverURL string, opts ExportOpti
 	}
 
 	// Launch browser
-	if err := e.launchBrowser(); err != nil {
+	browser, err := e.launchBrowser()
+	if err != nil {
 		return nil, err
 	}
 
 	// Create a new page
-	page, err := e.browser.NewPage(playwright.BrowserNewPageOptions{
+	page, err := browser.NewPage(playwright.BrowserNewPageOptions{
 		Viewport: &playwright.Size{
 			Width:  1920,
 			Height: 1080,
 		},
+		ExtraHttpHeaders: opts.Headers,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new page: %w", err)
@@ -198,6 +276,15 @@ func (e *Exporter) Export(ctx context.Context, serverURL string, opts ExportOpti
 		return nil, fmt.Errorf("no slides found in presentation")
 	}
 
+	if selected == nil {
+		if selected, err = selectSlides(ranges, make([]slideInfo, slideCount)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Bookmark each exported page with its slide title
+	bookmarks := slideBookmarks(selected, slideTitles(opts.SlideTitles, pres), pres)
+
 	// Remove existing output file to ensure clean overwrite
 	// (pdfcpu may not properly overwrite existing files)
 	if err := os.Remove(opts.Output); err != nil && !os.IsNotExist(err) {
@@ -208,11 +295,17 @@ func (e *Exporter) Export(ctx context.Context, serverURL string, opts ExportOpti
 	var result *ExportResult
 	switch opts.Content {
 	case ContentSlides:
-		result, err = e.exportSlides(ctx, page, serverURL, slideCount, opts.Output, opts)
+		if opts.Mode == ModeVector {
+			result, err = e.exportSlidesVector(ctx, page, serverURL, pres, selected, bookmarks, opts.Output, opts)
+		} else {
+			result, err = e.exportSlides(ctx, page, serverURL, pres, selected, bookmarks, opts.Output, opts)
+		}
 	case ContentNotes:
-		result, err = e.exportNotes(ctx, page, serverURL, slideCount, opts.Output)
+		result, err = e.exportNotes(ctx, page, serverURL, pres, selected, bookmarks, opts.Output, opts)
 	case ContentBoth:
-		result, err = e.exportBoth(ctx, page, serverURL, slideCount, opts.Output)
+		result, err = e.exportBoth(ctx, page, serverURL, pres, selected, bookmarks, opts.Output, opts)
+	case ContentHandout:
+		result, err = e.exportHandout(ctx, page, serverURL, pres, selected, bookmarks, opts.Output, opts)
 	default:
 		return nil, fmt.Errorf("invalid content type: %s", opts.Content)
 	}
@@ -221,6 +314,17 @@ func (e *Exporter) Export(ctx context.Context, serverURL string, opts ExportOpti
 		return nil, err
 	}
 
+	if err := attachSources(opts.Output, opts.sources, opts.CreationDate); err != nil {
+		return nil, fmt.Errorf("failed to embed source files: %w", err)
+	}
+	result.SourceFiles = len(opts.sources)
+
+	if !opts.CreationDate.IsZero() {
+		if err := setCreationDate(opts.Output, opts.CreationDate); err != nil {
+			return nil, fmt.Errorf("failed to set PDF creation date: %w", err)
+		}
+	}
+
 	result.Duration = time.Since(startTime)
 
 	// Get file size
@@ -311,8 +415,8 @@ func (e *Exporter) tryGetSlideCount(page playwright.Page) (int, error) {
 }
 
 // exportSlides exports only the presentation slides to PDF.
-// It captures each slide as a screenshot and combines them into a single PDF.
-func (e *Exporter) exportSlides(ctx context.Context, page playwright.Page, serverURL string, slideCount int, output string, opts ExportOptions) (*ExportResult, error) {
+// It captures each selected slide as a screenshot and combines them into a single PDF.
+func (e *Exporter) exportSlides(ctx context.Context, page playwright.Page, serverURL string, pres *presentationInfo, slides []int, bookmarks []pdfcpu.Bookmark, output string, opts ExportOptions) (*ExportResult, error) {
 	// Create a temporary directory for screenshots
 	tempDir, err := os.MkdirTemp("", "tap-pdf-export-*")
 	if err != nil {
@@ -321,8 +425,37 @@ func (e *Exporter) exportSlides(ctx context.Context, page playwright.Page, serve
 	defer os.RemoveAll(tempDir)
 
 	// Capture each slide as a screenshot
+	screenshotPaths, err := captureSlides(ctx, page, serverURL, pres, slides, tempDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Combine screenshots into a PDF
+	opts.reportProgress(len(slides), len(slides), StageAssemble)
+	if err := e.imagesToPDF(screenshotPaths, output); err != nil {
+		return nil, fmt.Errorf("failed to create PDF from screenshots: %w", err)
+	}
+
+	// Add PDF metadata if provided
+	if err := e.addMetadata(output, opts); err != nil {
+		return nil, fmt.Errorf("failed to add PDF metadata: %w", err)
+	}
+
+	if err := e.addBookmarks(output, bookmarks); err != nil {
+		return nil, err
+	}
+
+	return &ExportResult{
+		OutputPath: output,
+		PageCount:  len(slides),
+	}, nil
+}
+
+// captureSlides captures each selected slide as a PNG screenshot in dir and
+// returns their paths, in order.
+func captureSlides(ctx context.Context, page playwright.Page, serverURL string, pres *presentationInfo, slides []int, dir string, opts ExportOptions) ([]string, error) {
 	var screenshotPaths []string
-	for i := 0; i < slideCount; i++ {
+	for n, i := range slides {
 		// Check for context cancellation
 		select {
 		case <-ctx.Done():
@@ -332,48 +465,88 @@ func (e *Exporter) exportSlides(ctx context.Context, page playwright.Page, serve
 
 		// Navigate to the slide (1-based hash for URL)
 		// Use ?print=true to show all fragments
-		slideURL := fmt.Sprintf("%s?print=true#%d", serverURL, i+1)
-		if _, err := page.Goto(slideURL, playwright.PageGotoOptions{
-			WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-		}); err != nil {
-			return nil, fmt.Errorf("failed to navigate to slide %d: %w", i+1, err)
+		slideURL := render.SlideURL(serverURL, i)
+		if err := render.Slide(page, slideURL, i, pres.isEmbed(i)); err != nil {
+			return nil, err
 		}
 
-		// Wait for slide to render
-		if err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-			State: playwright.LoadStateNetworkidle,
+		// Take a screenshot
+		screenshotPath := filepath.Join(dir, fmt.Sprintf("slide-%03d.png", i))
+		if _, err := page.Screenshot(playwright.PageScreenshotOptions{
+			Path:     playwright.String(screenshotPath),
+			FullPage: playwright.Bool(false),
+			Type:     playwright.ScreenshotTypePng,
 		}); err != nil {
-			return nil, fmt.Errorf("failed to wait for slide %d to load: %w", i+1, err)
+			return nil, fmt.Errorf("failed to capture slide %d: %w", i+1, err)
 		}
+		screenshotPaths = append(screenshotPaths, screenshotPath)
+		opts.reportProgress(n+1, len(slides), StageCapture)
+	}
+	return screenshotPaths, nil
+}
+
+// exportSlidesVector exports only the presentation slides to PDF, keeping
+// their text. It prints each selected slide to a one-page PDF sized to the
+// slide and merges them into a single PDF.
+func (e *Exporter) exportSlidesVector(ctx context.Context, page playwright.Page, serverURL string, pres *presentationInfo, slides []int, bookmarks []pdfcpu.Bookmark, output string, opts ExportOptions) (*ExportResult, error) {
+	// Create a temporary directory for the slide PDFs
+	tempDir, err := os.MkdirTemp("", "tap-pdf-vector-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Lay out the slides as on screen, filling a viewport of the slide's size
+	width, height := slidePageSize(pres.aspectRatio())
+	if err := page.SetViewportSize(width, height); err != nil {
+		return nil, fmt.Errorf("failed to set viewport size: %w", err)
+	}
+	if err := page.EmulateMedia(playwright.PageEmulateMediaOptions{Media: playwright.MediaScreen}); err != nil {
+		return nil, fmt.Errorf("failed to emulate screen media: %w", err)
+	}
 
-		// Wait for all images to be fully loaded
-		if err := e.waitForImages(page); err != nil {
-			return nil, fmt.Errorf("failed to wait for images on slide %d: %w", i+1, err)
+	// Print each slide to its own PDF
+	var pdfPaths []string
+	for n, i := range slides {
+		// Check for context cancellation
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
 		}
 
-		// Wait for map tiles to load (if slide has a map)
-		if err := e.waitForMaps(page); err != nil {
-			return nil, fmt.Errorf("failed to wait for maps on slide %d: %w", i+1, err)
+		// Use ?print=true to show all fragments
+		slideURL := render.SlideURL(serverURL, i)
+		if err := render.Slide(page, slideURL, i, pres.isEmbed(i)); err != nil {
+			return nil, err
 		}
 
-		// Small delay to ensure animations complete
-		time.Sleep(200 * time.Millisecond)
+		// Size the printed page to the slide
+		if _, err := page.AddStyleTag(playwright.PageAddStyleTagOptions{
+			Content: playwright.String(slidePageCSS(width, height)),
+		}); err != nil {
+			return nil, fmt.Errorf("failed to style slide %d for printing: %w", i+1, err)
+		}
 
-		// Take a screenshot
-		screenshotPath := filepath.Join(tempDir, fmt.Sprintf("slide-%03d.png", i))
-		if _, err := page.Screenshot(playwright.PageScreenshotOptions{
-			Path:     playwright.String(screenshotPath),
-			FullPage: playwright.Bool(false),
-			Type:     playwright.ScreenshotTypePng,
+		pdfPath := filepath.Join(tempDir, fmt.Sprintf("slide-%03d.pdf", i))
+		if _, err := page.PDF(playwright.PagePdfOptions{
+			Path:              playwright.String(pdfPath),
+			Width:             playwright.String(fmt.Sprintf("%dpx", width)),
+			Height:            playwright.String(fmt.Sprintf("%dpx", height)),
+			PrintBackground:   playwright.Bool(true),
+			PreferCSSPageSize: playwright.Bool(true),
+			PageRanges:        playwright.String("1"),
 		}); err != nil {
-			return nil, fmt.Errorf("failed to capture slide %d: %w", i+1, err)
+			return nil, fmt.Errorf("failed to print slide %d: %w", i+1, err)
 		}
-		screenshotPaths = append(screenshotPaths, screenshotPath)
+		pdfPaths = append(pdfPaths, pdfPath)
+		opts.reportProgress(n+1, len(slides), StageCapture)
 	}
 
-	// Combine screenshots into a PDF
-	if err := e.imagesToPDF(screenshotPaths, output); err != nil {
-		return nil, fmt.Errorf("failed to create PDF from screenshots: %w", err)
+	// Merge the slide PDFs
+	opts.reportProgress(len(slides), len(slides), StageAssemble)
+	if err := mergePDFs(pdfPaths, output); err != nil {
+		return nil, fmt.Errorf("failed to merge slide PDFs: %w", err)
 	}
 
 	// Add PDF metadata if provided
@@ -381,12 +554,50 @@ func (e *Exporter) exportSlides(ctx context.Context, page playwright.Page, serve
 		return nil, fmt.Errorf("failed to add PDF metadata: %w", err)
 	}
 
+	if err := e.addBookmarks(output, bookmarks); err != nil {
+		return nil, err
+	}
+
 	return &ExportResult{
 		OutputPath: output,
-		PageCount:  slideCount,
+		PageCount:  len(slides),
 	}, nil
 }
 
+// slidePageSize returns the size in pixels of a slide with the given aspect
+// ratio, such as "4:3", at a height of 1080 pixels. An empty or invalid
+// aspect ratio is treated as 16:9.
+func slidePageSize(aspectRatio string) (int, int) {
+	const height = 1080
+	w, h, ok := strings.Cut(aspectRatio, ":")
+	width, errW := strconv.Atoi(w)
+	ratioHeight, errH := strconv.Atoi(h)
+	if !ok || errW != nil || errH != nil || width <= 0 || ratioHeight <= 0 {
+		return 1920, height
+	}
+	return (height*width + ratioHeight/2) / ratioHeight, height
+}
+
+// slidePageCSS returns the stylesheet that prints a slide of the given size
+// on a single page without margins, keeping its backgrounds.
+func slidePageCSS(width, height int) string {
+	return fmt.Sprintf(`@page { size: %[1]dpx %[2]dpx; margin: 0; }
+html, body { width: %[1]dpx; height: %[2]dpx; margin: 0; overflow: hidden; -webkit-print-color-adjust: exact; print-color-adjust: exact; }`, width, height)
+}
+
+// mergePDFs combines PDF files, in order, into a single PDF file.
+func mergePDFs(pdfPaths []string, outputPath string) error {
+	if len(pdfPaths) == 0 {
+		return fmt.Errorf("no PDFs to merge")
+	}
+
+	conf := model.NewDefaultConfiguration()
+	if err := api.MergeCreateFile(pdfPaths, outputPath, false, conf); err != nil {
+		return fmt.Errorf("failed to merge PDFs: %w", err)
+	}
+	return nil
+}
+
 // imagesToPDF combines multiple PNG images into a single PDF file.
 func (e *Exporter) imagesToPDF(imagePaths []string, outputPath string) error {
 	if len(imagePaths) == 0 {
@@ -442,182 +653,36 @@ func loadPNG(path string) (image.Image, error) {
 	return png.Decode(f)
 }
 
-// waitForImages waits for all images on the page to be fully loaded.
-func (e *Exporter) waitForImages(page playwright.Page) error {
-	// Wait for all images to complete loading with a timeout
-	_, err := page.Evaluate(`() => {
-		return new Promise((resolve, reject) => {
-			const timeout = setTimeout(() => {
-				resolve(); // Don't fail on timeout, just continue
-			}, 5000);
-
-			const images = Array.from(document.querySelectorAll('img'));
-			if (images.length === 0) {
-				clearTimeout(timeout);
-				resolve();
-				return;
-			}
-
-			let loaded = 0;
-			const total = images.length;
-
-			const checkComplete = () => {
-				loaded++;
-				if (loaded >= total) {
-					clearTimeout(timeout);
-					resolve();
-				}
-			};
-
-			images.forEach(img => {
-				if (img.complete && img.naturalHeight !== 0) {
-					checkComplete();
-				} else {
-					img.addEventListener('load', checkComplete);
-					img.addEventListener('error', checkComplete); // Count errors as "done"
-				}
-			});
-		});
-	}`)
-	return err
-}
-
-// waitForMaps waits for map tiles to be loaded on the page.
-// Maps use MapLibre GL which exposes __tapMapReady on window when ready.
-func (e *Exporter) waitForMaps(page playwright.Page) error {
-	// Check if page has a map and wait for it to be ready
-	_, err := page.Evaluate(`() => {
-		return new Promise((resolve) => {
-			// If no map on this slide, resolve immediately
-			const mapSlide = document.querySelector('.map-slide');
-			if (!mapSlide) {
-				resolve();
-				return;
-			}
-
-			// Set a timeout for map loading (3 seconds max)
-			const timeout = setTimeout(() => {
-				console.warn('Map tiles timeout - continuing anyway');
-				resolve();
-			}, 3000);
-
-			// Check if map is already ready
-			if (window.__tapMapReady && window.__tapMap) {
-				// Wait for tiles to load
-				const map = window.__tapMap;
-				if (map.loaded() && map.areTilesLoaded && map.areTilesLoaded()) {
-					clearTimeout(timeout);
-					resolve();
-					return;
-				}
-
-				// Wait for idle event (all tiles loaded)
-				map.once('idle', () => {
-					clearTimeout(timeout);
-					resolve();
-				});
-				return;
-			}
-
-			// Poll for map ready state
-			const checkInterval = setInterval(() => {
-				if (window.__tapMapReady && window.__tapMap) {
-					clearInterval(checkInterval);
-					const map = window.__tapMap;
-					if (map.loaded() && map.areTilesLoaded && map.areTilesLoaded()) {
-						clearTimeout(timeout);
-						resolve();
-						return;
-					}
-					map.once('idle', () => {
-						clearTimeout(timeout);
-						resolve();
-					});
-				}
-			}, 100);
-
-			// Also clear interval on timeout
-			setTimeout(() => {
-				clearInterval(checkInterval);
-			}, 3000);
-		});
-	}`)
-	return err
-}
-
 // exportNotes exports only the speaker notes to PDF.
 // It creates an HTML page with all notes and converts it to PDF.
-func (e *Exporter) exportNotes(ctx context.Context, page playwright.Page, serverURL string, slideCount int, output string) (*ExportResult, error) {
-	// First, get all the notes by navigating to each slide
+// The PDF outline is generated from the slide headings in the notes page.
+// The notes are taken as HTML from the presentation API; when the server
+// doesn't provide it, they are read from the presenter view as text.
+func (e *Exporter) exportNotes(ctx context.Context, page playwright.Page, serverURL string, pres *presentationInfo, slides []int, bookmarks []pdfcpu.Bookmark, output string, opts ExportOptions) (*ExportResult, error) {
 	var allNotes []string
-	for i := 0; i < slideCount; i++ {
+	for n, i := range slides {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
 		default:
 		}
 
-		// Navigate to presenter view for this slide
-		slideURL := fmt.Sprintf("%s/presenter#%d", serverURL, i+1)
-		if _, err := page.Goto(slideURL, playwright.PageGotoOptions{
-			WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-		}); err != nil {
-			return nil, fmt.Errorf("failed to navigate to slide %d: %w", i+1, err)
-		}
-
-		if err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-			State: playwright.LoadStateNetworkidle,
-		}); err != nil {
-			return nil, fmt.Errorf("failed to wait for slide %d: %w", i+1, err)
-		}
-
-		// Small delay for content to render
-		time.Sleep(100 * time.Millisecond)
-
-		// Extract notes text
-		notes, err := page.Evaluate(`() => {
-			const notesEl = document.querySelector('.speaker-notes, .notes, [class*="notes"]');
-			return notesEl ? notesEl.innerText : '';
-		}`)
-		if err != nil {
-			return nil, fmt.Errorf("failed to extract notes for slide %d: %w", i+1, err)
-		}
-		noteText := ""
-		if notes != nil {
-			noteText = notes.(string)
-		}
-		allNotes = append(allNotes, noteText)
-	}
-
-	// Create an HTML page with all the notes
-	html := `<!DOCTYPE html>
-<html>
-<head>
-<style>
-body { font-family: Georgia, serif; font-size: 12pt; line-height: 1.6; max-width: 800px; margin: 0 auto; padding: 2cm; }
-.slide-notes { margin-bottom: 2em; padding-bottom: 1em; border-bottom: 1px solid #ccc; page-break-inside: avoid; }
-.slide-number { font-weight: bold; color: #666; margin-bottom: 0.5em; }
-.no-notes { color: #999; font-style: italic; }
-</style>
-</head>
-<body>
-<h1>Speaker Notes</h1>
-`
-	for i, note := range allNotes {
-		html += fmt.Sprintf(`<div class="slide-notes">
-<div class="slide-number">Slide %d</div>
-`, i+1)
-		if note == "" {
-			html += `<p class="no-notes">No notes for this slide</p>`
+		if pres != nil {
+			allNotes = append(allNotes, pres.Slides[i].NotesHTML)
 		} else {
-			html += fmt.Sprintf(`<p>%s</p>`, note)
+			notes, err := readPresenterNotes(page, serverURL, i)
+			if err != nil {
+				return nil, err
+			}
+			allNotes = append(allNotes, notes)
 		}
-		html += "</div>\n"
+		opts.reportProgress(n+1, len(slides), StageCapture)
 	}
-	html += "</body></html>"
+
+	opts.reportProgress(len(slides), len(slides), StageAssemble)
 
 	// Set the page content to our notes HTML
-	if err := page.SetContent(html, playwright.PageSetContentOptions{
+	if err := page.SetContent(renderNotesHTML(slides, allNotes, bookmarks), playwright.PageSetContentOptions{
 		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
 	}); err != nil {
 		return nil, fmt.Errorf("failed to set notes content: %w", err)
@@ -629,6 +694,8 @@ body { font-family: Georgia, serif; font-size: 12pt; line-height: 1.6; max-width
 		Format:          playwright.String("A4"),
 		Landscape:       playwright.Bool(false),
 		PrintBackground: playwright.Bool(true),
+		Outline:         playwright.Bool(true),
+		Tagged:          playwright.Bool(true),
 		Margin: &playwright.Margin{
 			Top:    playwright.String("1cm"),
 			Right:  playwright.String("1cm"),
@@ -642,13 +709,100 @@ body { font-family: Georgia, serif; font-size: 12pt; line-height: 1.6; max-width
 
 	return &ExportResult{
 		OutputPath: output,
-		PageCount:  slideCount, // Approximate, actual pages depend on content
+		PageCount:  len(slides), // Approximate, actual pages depend on content
 	}, nil
 }
 
+// readPresenterNotes navigates the presenter view to a slide and returns its
+// notes as HTML, from the text of the notes panel.
+func readPresenterNotes(page playwright.Page, serverURL string, slide int) (string, error) {
+	slideURL := fmt.Sprintf("%s/presenter#%d", serverURL, slide+1)
+	if _, err := page.Goto(slideURL, playwright.PageGotoOptions{
+		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+	}); err != nil {
+		return "", fmt.Errorf("failed to navigate to slide %d: %w", slide+1, err)
+	}
+
+	if err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+		State: playwright.LoadStateNetworkidle,
+	}); err != nil {
+		return "", fmt.Errorf("failed to wait for slide %d: %w", slide+1, err)
+	}
+
+	// Small delay for content to render
+	time.Sleep(100 * time.Millisecond)
+
+	notes, err := page.Evaluate(`() => {
+		const notesEl = document.querySelector('.speaker-notes, .notes, [class*="notes"]');
+		return notesEl ? notesEl.innerText : '';
+	}`)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract notes for slide %d: %w", slide+1, err)
+	}
+	return notesTextToHTML(notes), nil
+}
+
+// notesTextToHTML returns notes text read from the presenter view as an
+// HTML paragraph, or "" if there is no text.
+func notesTextToHTML(notes any) string {
+	text, ok := notes.(string)
+	if !ok || strings.TrimSpace(text) == "" {
+		return ""
+	}
+	return "<p>" + strings.ReplaceAll(html.EscapeString(strings.TrimSpace(text)), "\n", "<br>") + "</p>"
+}
+
+// renderNotesHTML returns the page that exportNotes prints from the HTML of
+// each slide's notes. Each slide's notes are under a heading with the slide's bookmark title: section slides and
+// top-level slides use h1 and nested slides use h2, so the outline generated
+// by the browser matches the slide bookmarks.
+func renderNotesHTML(slides []int, notes []string, bookmarks []pdfcpu.Bookmark) string {
+	// Map each page to its heading level and title
+	levels := make(map[int]int)
+	titles := make(map[int]string)
+	for _, bookmark := range bookmarks {
+		levels[bookmark.PageFrom], titles[bookmark.PageFrom] = 1, bookmark.Title
+		for _, kid := range bookmark.Kids {
+			levels[kid.PageFrom], titles[kid.PageFrom] = 2, kid.Title
+		}
+	}
+
+	page := `<!DOCTYPE html>
+<html>
+<head>
+<style>
+body { font-family: Georgia, serif; font-size: 12pt; line-height: 1.6; max-width: 800px; margin: 0 auto; padding: 2cm; }
+.document-title { font-size: 2em; font-weight: bold; margin: 0.67em 0; }
+.slide-notes { margin-bottom: 2em; padding-bottom: 1em; border-bottom: 1px solid #ccc; page-break-inside: avoid; }
+.slide-title { font-size: 1.2em; margin: 0; }
+.slide-number { font-weight: bold; color: #666; margin-bottom: 0.5em; }
+.no-notes { color: #999; font-style: italic; }
+</style>
+</head>
+<body>
+<div class="document-title">Speaker Notes</div>
+`
+	for n, note := range notes {
+		page += `<div class="slide-notes">` + "\n"
+		if title, ok := titles[n+1]; ok {
+			page += fmt.Sprintf("<h%d class=\"slide-title\">%s</h%d>\n", levels[n+1], html.EscapeString(title), levels[n+1])
+		}
+		page += fmt.Sprintf(`<div class="slide-number">Slide %d</div>
+`, slides[n]+1)
+		if note == "" {
+			page += `<p class="no-notes">No notes for this slide</p>`
+		} else {
+			page += `<div class="notes-body">` + note + "</div>\n"
+		}
+		page += "</div>\n"
+	}
+	page += "</body></html>"
+	return page
+}
+
 // exportBoth exports both slides and notes to PDF.
 // It captures screenshots of the presenter view (showing slide + notes) for each slide.
-func (e *Exporter) exportBoth(ctx context.Context, page playwright.Page, serverURL string, slideCount int, output string) (*ExportResult, error) {
+func (e *Exporter) exportBoth(ctx context.Context, page playwright.Page, serverURL string, pres *presentationInfo, slides []int, bookmarks []pdfcpu.Bookmark, output string, opts ExportOptions) (*ExportResult, error) {
 	// Create a temporary directory for screenshots
 	tempDir, err := os.MkdirTemp("", "tap-pdf-both-*")
 	if err != nil {
@@ -658,7 +812,7 @@ func (e *Exporter) exportBoth(ctx context.Context, page playwright.Page, serverU
 
 	// Capture each slide's presenter view as a screenshot
 	var screenshotPaths []string
-	for i := 0; i < slideCount; i++ {
+	for n, i := range slides {
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
@@ -668,31 +822,10 @@ func (e *Exporter) exportBoth(ctx context.Context, page playwright.Page, serverU
 		// Navigate to presenter view for this slide
 		// Use ?print=true to show all fragments
 		slideURL := fmt.Sprintf("%s/presenter?print=true#%d", serverURL, i+1)
-		if _, err := page.Goto(slideURL, playwright.PageGotoOptions{
-			WaitUntil: playwright.WaitUntilStateDomcontentloaded,
-		}); err != nil {
-			return nil, fmt.Errorf("failed to navigate to slide %d: %w", i+1, err)
-		}
-
-		if err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-			State: playwright.LoadStateNetworkidle,
-		}); err != nil {
-			return nil, fmt.Errorf("failed to wait for slide %d: %w", i+1, err)
-		}
-
-		// Wait for all images to be fully loaded
-		if err := e.waitForImages(page); err != nil {
-			return nil, fmt.Errorf("failed to wait for images on slide %d: %w", i+1, err)
+		if err := render.Slide(page, slideURL, i, pres.isEmbed(i)); err != nil {
+			return nil, err
 		}
 
-		// Wait for map tiles to load (if slide has a map)
-		if err := e.waitForMaps(page); err != nil {
-			return nil, fmt.Errorf("failed to wait for maps on slide %d: %w", i+1, err)
-		}
-
-		// Small delay for content to render
-		time.Sleep(200 * time.Millisecond)
-
 		// Take a screenshot of the presenter view
 		screenshotPath := filepath.Join(tempDir, fmt.Sprintf("slide-%03d.png", i))
 		if _, err := page.Screenshot(playwright.PageScreenshotOptions{
@@ -703,16 +836,22 @@ func (e *Exporter) exportBoth(ctx context.Context, page playwright.Page, serverU
 			return nil, fmt.Errorf("failed to capture slide %d: %w", i+1, err)
 		}
 		screenshotPaths = append(screenshotPaths, screenshotPath)
+		opts.reportProgress(n+1, len(slides), StageCapture)
 	}
 
 	// Combine screenshots into a PDF
+	opts.reportProgress(len(slides), len(slides), StageAssemble)
 	if err := e.imagesToPDF(screenshotPaths, output); err != nil {
 		return nil, fmt.Errorf("failed to create PDF from screenshots: %w", err)
 	}
 
+	if err := e.addBookmarks(output, bookmarks); err != nil {
+		return nil, err
+	}
+
 	return &ExportResult{
 		OutputPath: output,
-		PageCount:  slideCount,
+		PageCount:  len(slides),
 	}, nil
 }
 
@@ -737,6 +876,18 @@ func (e *Exporter) addMetadata(pdfPath string, opts ExportOptions) error {
 	return api.AddPropertiesFile(pdfPath, pdfPath, properties, conf)
 }
 
+// ValidateMode checks if a rendering mode string is valid.
+func ValidateMode(mode string) (Mode, error) {
+	switch mode {
+	case "raster", "":
+		return ModeRaster, nil
+	case "vector":
+		return ModeVector, nil
+	default:
+		return "", fmt.Errorf("invalid mode %q: must be 'raster' or 'vector'", mode)
+	}
+}
+
 // ValidateContentType checks if a content type string is valid.
 func ValidateContentType(content string) (ContentType, error) {
 	switch content {
@@ -746,7 +897,9 @@ func ValidateContentType(content string) (ContentType, error) {
 		return ContentNotes, nil
 	case "both":
 		return ContentBoth, nil
+	case "handout":
+		return ContentHandout, nil
 	default:
-		return "", fmt.Errorf("invalid content type %q: must be 'slides', 'notes', or 'both'", content)
+		return "", fmt.Errorf("invalid content type %q: must be 'slides', 'notes', 'both', or 'handout'", content)
 	}
 }
@@ -2,6 +2,7 @@ package pdf
 
 import (
 	"context"
+	"encoding/json"
 	"image"
 	"image/png"
 	"io"
@@ -14,6 +15,7 @@ import (
 	"github.com/MiniCodeMonkey/tap/internal/config"
 	"github.com/MiniCodeMonkey/tap/internal/server"
 	"github.com/MiniCodeMonkey/tap/internal/transformer"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
 )
 
 func TestValidateContentType(t *testing.T) {
@@ -26,6 +28,7 @@ func TestValidateContentType(t *testing.T) {
 		{"", ContentSlides, false},
 		{"notes", ContentNotes, false},
 		{"both", ContentBoth, false},
+		{"handout", ContentHandout, false},
 		{"invalid", "", true},
 		{"SLIDES", "", true}, // case sensitive
 	}
@@ -44,12 +47,95 @@ func TestValidateContentType(t *testing.T) {
 	}
 }
 
+func TestValidateMode(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    Mode
+		wantErr bool
+	}{
+		{"raster", ModeRaster, false},
+		{"", ModeRaster, false},
+		{"vector", ModeVector, false},
+		{"svg", "", true},
+		{"VECTOR", "", true}, // case sensitive
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ValidateMode(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateMode(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ValidateMode(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlidePageSize(t *testing.T) {
+	tests := []struct {
+		aspectRatio   string
+		width, height int
+	}{
+		{"16:9", 1920, 1080},
+		{"4:3", 1440, 1080},
+		{"16:10", 1728, 1080},
+		{"", 1920, 1080},
+		{"wide", 1920, 1080},
+		{"16:0", 1920, 1080},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.aspectRatio, func(t *testing.T) {
+			width, height := slidePageSize(tt.aspectRatio)
+			if width != tt.width || height != tt.height {
+				t.Errorf("slidePageSize(%q) = %dx%d, want %dx%d", tt.aspectRatio, width, height, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestMergePDFs(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Build one-page PDFs of different sizes to merge
+	var pdfPaths []string
+	for i, size := range []int{40, 60, 80} {
+		imagePath := filepath.Join(tempDir, "slide-"+itoa(i)+".png")
+		if err := createTestImage(imagePath, size, size); err != nil {
+			t.Fatal(err)
+		}
+		pdfPath := filepath.Join(tempDir, "slide-"+itoa(i)+".pdf")
+		if err := (&Exporter{}).imagesToPDF([]string{imagePath}, pdfPath); err != nil {
+			t.Fatalf("imagesToPDF() error = %v", err)
+		}
+		pdfPaths = append(pdfPaths, pdfPath)
+	}
+
+	output := filepath.Join(tempDir, "merged.pdf")
+	if err := mergePDFs(pdfPaths, output); err != nil {
+		t.Fatalf("mergePDFs() error = %v", err)
+	}
+	if count, err := api.PageCountFile(output); err != nil || count != 3 {
+		t.Errorf("PageCountFile() = %d, %v, want 3 pages", count, err)
+	}
+
+	if err := mergePDFs(nil, output); err == nil {
+		t.Error("expected an error when there is nothing to merge")
+	}
+}
+
 func TestDefaultExportOptions(t *testing.T) {
 	opts := DefaultExportOptions()
 
 	if opts.Content != ContentSlides {
 		t.Errorf("DefaultExportOptions().Content = %v, want %v", opts.Content, ContentSlides)
 	}
+	if opts.Mode != ModeRaster {
+		t.Errorf("DefaultExportOptions().Mode = %v, want %v", opts.Mode, ModeRaster)
+	}
 	if opts.Output != "presentation.pdf" {
 		t.Errorf("DefaultExportOptions().Output = %v, want %v", opts.Output, "presentation.pdf")
 	}
@@ -517,3 +603,93 @@ func itoa(i int) string {
 	}
 	return s
 }
+
+func TestExportOptionsReportProgress(t *testing.T) {
+	// A nil callback must be safe to call
+	ExportOptions{}.reportProgress(1, 2, StageCapture)
+
+	var calls []string
+	opts := ExportOptions{
+		Progress: func(current, total int, stage string) {
+			calls = append(calls, stage+":"+itoa(current)+"/"+itoa(total))
+		},
+	}
+	opts.reportProgress(1, 2, StageCapture)
+	opts.reportProgress(2, 2, StageAssemble)
+
+	if len(calls) != 2 || calls[0] != "capture:1/2" || calls[1] != "assemble:2/2" {
+		t.Errorf("unexpected progress calls: %v", calls)
+	}
+}
+
+// TestExportSlidesVectorIsSmaller is an integration test that requires
+// Playwright. It exports the same slides in both modes and checks that the
+// vector PDF is smaller than the screenshots.
+func TestExportSlidesVectorIsSmaller(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tempDir := t.TempDir()
+
+	pres := &transformer.TransformedPresentation{
+		Config: *config.DefaultConfig(),
+		Slides: []transformer.TransformedSlide{
+			{Index: 0, HTML: "<h1>Quarterly Review</h1>", Layout: "title"},
+			{Index: 1, HTML: "<h1>Highlights</h1><ul><li>Revenue up</li><li>Churn down</li></ul>", Layout: "default"},
+			{Index: 2, HTML: "<h1>Next Steps</h1><p>Ship the roadmap</p>", Layout: "default"},
+		},
+	}
+
+	srv := server.New(0)
+	srv.SetPresentation(pres)
+	srv.SetupRoutes()
+	if err := srv.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer srv.Shutdown(context.Background())
+
+	serverURL := "http://localhost:" + itoa(srv.Port())
+
+	exp, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer exp.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+
+	sizes := make(map[Mode]int64)
+	for _, mode := range []Mode{ModeRaster, ModeVector} {
+		result, err := exp.Export(ctx, serverURL, ExportOptions{
+			Content: ContentSlides,
+			Mode:    mode,
+			Output:  filepath.Join(tempDir, string(mode)+".pdf"),
+		})
+		if err != nil {
+			t.Fatalf("Export(%s) error = %v", mode, err)
+		}
+		if count, err := api.PageCountFile(result.OutputPath); err != nil || count != 3 {
+			t.Errorf("Export(%s) wrote %d pages (%v), want 3", mode, count, err)
+		}
+		sizes[mode] = result.FileSize
+	}
+
+	if sizes[ModeVector] >= sizes[ModeRaster] {
+		t.Errorf("vector PDF is %d bytes, want smaller than the raster PDF of %d bytes", sizes[ModeVector], sizes[ModeRaster])
+	}
+}
+
+func TestExportResultJSON(t *testing.T) {
+	data, err := json.Marshal(ExportResult{OutputPath: "slides.pdf", PageCount: 12, Duration: 2 * time.Second})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	// Field names are part of the JSON output of tap pdf --json
+	want := `{"outputPath":"slides.pdf","pageCount":12,"durationNs":2000000000,"fileSize":0,"sourceFiles":0}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,161 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/embedded"
+	"github.com/MiniCodeMonkey/tap/internal/builder"
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// presentationDataPattern extracts the presentation JSON embedded in a built index.html.
+var presentationDataPattern = regexp.MustCompile(`(?s)<script id="presentation-data" type="application/json">(.*?)</script>`)
+
+// ExportFile generates a PDF directly from a markdown file, without a running dev server.
+// The presentation is built to a temporary static site that is served on an
+// ephemeral localhost port for the duration of the export. The temporary server
+// and directory are removed when the export completes or ctx is cancelled.
+//
+// If opts.Output is empty, the PDF is written next to the markdown file with a .pdf
+// extension. Title and Author default to the presentation's frontmatter.
+func (e *Exporter) ExportFile(ctx context.Context, markdownPath string, opts ExportOptions) (*ExportResult, error) {
+	absPath, err := filepath.Abs(markdownPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve file path: %w", err)
+	}
+
+	cfg, err := config.Load(absPath, nil, config.Overrides{Lang: opts.Lang})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	pres, err := parser.NewWithOptions(parser.Options{
+		Emoji:            cfg.Emoji,
+		Smartypants:      cfg.Smartypants,
+		StrictDelimiters: cfg.StrictDelimiters,
+	}).ParseFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse presentation: %w", err)
+	}
+
+	// Collect the files to attach from all languages, so the whole
+	// presentation can be recovered
+	if opts.EmbedSource {
+		if opts.sources, err = collectSources(absPath, cfg, pres, opts.MaxSourceSize); err != nil {
+			return nil, err
+		}
+	}
+	pres = transformer.New(cfg).SelectLanguage(pres)
+	ignoreAutoAdvance(cfg, pres)
+
+	// Check the slide selection before building
+	if _, err := ParseSlideRange(opts.Slides, len(pres.Slides)); err != nil {
+		return nil, err
+	}
+
+	if opts.Output == "" {
+		opts.Output = strings.TrimSuffix(markdownPath, filepath.Ext(markdownPath)) + ".pdf"
+	}
+	if opts.Title == "" {
+		opts.Title = cfg.Title
+	}
+	if opts.Author == "" {
+		opts.Author = cfg.Author
+	}
+
+	// Build a static site to a temporary directory
+	tempDir, err := os.MkdirTemp("", "tap-pdf-build-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	b := builder.NewWithOutput(tempDir)
+	b.SetBaseDir(filepath.Dir(absPath))
+	if _, err := b.Build(cfg, pres); err != nil {
+		return nil, fmt.Errorf("failed to build presentation: %w", err)
+	}
+
+	handler, err := staticHandler(tempDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Serve the build on an ephemeral localhost port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start temporary server: %w", err)
+	}
+	srv := &http.Server{Handler: handler}
+	go func() { _ = srv.Serve(listener) }()
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	serverURL := fmt.Sprintf("http://%s", listener.Addr().String())
+	result, err := e.Export(ctx, serverURL, opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, warning := range cfg.ThemeWarnings() {
+		result.Warnings = append(result.Warnings, "themes: "+warning)
+	}
+	return result, nil
+}
+
+// staticHandler serves a static build directory. In addition to the built files,
+// it serves the presenter view and the presentation API used by the presenter,
+// so notes can be exported without the dev server.
+func staticHandler(dir string) (http.Handler, error) {
+	indexHTML, err := os.ReadFile(filepath.Join(dir, "index.html"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read built index.html: %w", err)
+	}
+	match := presentationDataPattern.FindSubmatch(indexHTML)
+	if match == nil {
+		return nil, fmt.Errorf("presentation data not found in built index.html")
+	}
+	presentationJSON := match[1]
+
+	presenterHTML, err := embedded.GetPresenterHTML()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded presenter.html: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServer(http.Dir(dir)))
+	mux.HandleFunc("GET /presenter", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(presenterHTML)
+	})
+	mux.HandleFunc("GET /api/presentation", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(presentationJSON)
+	})
+	return mux, nil
+}
+
+// ignoreAutoAdvance clears the auto-play options of a presentation, which
+// don't apply to exports, so the exported build never moves on by itself.
+func ignoreAutoAdvance(cfg *config.Config, pres *parser.Presentation) {
+	cfg.AutoAdvance = ""
+	cfg.Loop = false
+	for i := range pres.Slides {
+		pres.Slides[i].Directives.AutoAdvance = ""
+	}
+}
@@ -0,0 +1,157 @@
+package pdf
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func TestExportFile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tempDir := t.TempDir()
+	mdFile := filepath.Join(tempDir, "deck.md")
+	content := "---\ntitle: Deck\n---\n\n# Slide 1\n\n---\n\n# Slide 2\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer exp.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	var captured, assembled int
+	result, err := exp.ExportFile(ctx, mdFile, ExportOptions{
+		Content: ContentSlides,
+		Progress: func(current, total int, stage string) {
+			switch stage {
+			case StageCapture:
+				captured = current
+			case StageAssemble:
+				assembled++
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("ExportFile() error = %v", err)
+	}
+	if captured != 2 || assembled != 1 {
+		t.Errorf("progress reported %d captured slides and %d assemble stages, want 2 and 1", captured, assembled)
+	}
+
+	wantOutput := filepath.Join(tempDir, "deck.pdf")
+	if result.OutputPath != wantOutput {
+		t.Errorf("ExportFile() OutputPath = %q, want %q", result.OutputPath, wantOutput)
+	}
+	if result.PageCount != 2 {
+		t.Errorf("ExportFile() PageCount = %d, want 2", result.PageCount)
+	}
+	if _, err := os.Stat(wantOutput); err != nil {
+		t.Errorf("output file not found: %v", err)
+	}
+}
+
+func TestExportFile_MissingFile(t *testing.T) {
+	exp, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer exp.Close()
+
+	_, err = exp.ExportFile(context.Background(), filepath.Join(t.TempDir(), "missing.md"), ExportOptions{})
+	if err == nil {
+		t.Error("expected error for missing markdown file")
+	}
+}
+
+func TestIgnoreAutoAdvance(t *testing.T) {
+	pres, err := parser.New().Parse([]byte("<!-- autoAdvance: 8s -->\n# One\n\n---\n\n# Two"))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	cfg := config.DefaultConfig()
+	cfg.AutoAdvance = "5s"
+	cfg.Loop = true
+
+	ignoreAutoAdvance(cfg, pres)
+
+	if cfg.AutoAdvance != "" || cfg.Loop {
+		t.Errorf("AutoAdvance = %q, Loop = %v, want both cleared", cfg.AutoAdvance, cfg.Loop)
+	}
+	if got := pres.Slides[0].Directives.AutoAdvance; got != "" {
+		t.Errorf("slide 1 AutoAdvance = %q, want it cleared", got)
+	}
+}
+
+func TestStaticHandler(t *testing.T) {
+	dir := t.TempDir()
+	indexHTML := `<html><body><script id="presentation-data" type="application/json">{"slides":[]}</script></body></html>`
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte(indexHTML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "assets"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "assets", "image.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	handler, err := staticHandler(dir)
+	if err != nil {
+		t.Fatalf("staticHandler() error = %v", err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	tests := []struct {
+		path     string
+		contains string
+	}{
+		{"/", "presentation-data"},
+		{"/assets/image.png", "png"},
+		{"/api/presentation", `{"slides":[]}`},
+		{"/presenter", "<html"},
+	}
+
+	for _, tt := range tests {
+		resp, err := srv.Client().Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", tt.path, err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode != 200 {
+			t.Errorf("GET %s status = %d, want 200", tt.path, resp.StatusCode)
+		}
+		if !strings.Contains(strings.ToLower(string(body)), strings.ToLower(tt.contains)) {
+			t.Errorf("GET %s body does not contain %q", tt.path, tt.contains)
+		}
+	}
+}
+
+func TestStaticHandler_MissingData(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), []byte("<html></html>"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := staticHandler(dir); err == nil {
+		t.Error("expected error when presentation data is missing")
+	}
+}
@@ -0,0 +1,278 @@
+package pdf
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"html"
+	"os"
+	"strings"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+	"github.com/playwright-community/playwright-go"
+)
+
+// DefaultSlidesPerPage is the number of slides on each handout page when
+// none is set.
+const DefaultSlidesPerPage = 3
+
+// Handout page measurements, in millimeters.
+const (
+	a4Short        = 210.0
+	a4Long         = 297.0
+	handoutMargin  = 12.0
+	handoutGap     = 8.0 // Between cells, and between a slide and its notes beside it
+	handoutLabel   = 6.0 // Height of the slide number above each slide
+	handoutSpacing = 3.0 // Between a slide and its notes below it
+	handoutLine    = 8.0 // Distance between the lines for attendee notes
+
+	// handoutSlideShare is the largest share of a cell's height a slide
+	// takes when its notes are below it
+	handoutSlideShare = 0.6
+)
+
+// handoutRect is a rectangle on a handout page, in millimeters from the top
+// left corner.
+type handoutRect struct {
+	X, Y, Width, Height float64
+}
+
+// handoutCell is where a slide and its notes are placed on a handout page.
+type handoutCell struct {
+	// Label is where the slide number goes, above the slide.
+	Label handoutRect
+	// Slide is where the slide image goes.
+	Slide handoutRect
+	// Notes is where the speaker notes or lines for attendee notes go.
+	Notes handoutRect
+}
+
+// handoutLayout is the layout of every page of a handout.
+type handoutLayout struct {
+	// Landscape is true for pages wider than they are tall.
+	Landscape bool
+	// Width and Height are the size of an A4 page in the orientation, in millimeters.
+	Width, Height float64
+	// Cells are where the slides go, in order.
+	Cells []handoutCell
+}
+
+// ValidateSlidesPerPage checks that a handout can have n slides per page.
+func ValidateSlidesPerPage(n int) error {
+	switch n {
+	case 2, 3, 4, 6:
+		return nil
+	default:
+		return fmt.Errorf("invalid slides per page %d: must be 2, 3, 4, or 6", n)
+	}
+}
+
+// layoutHandout places slidesPerPage slide images of the given size on an A4
+// page. With 3 slides per page, the page is portrait and the slides are
+// stacked with their notes beside them; otherwise the page is landscape and
+// the slides form a grid with their notes below them. Slides are scaled to
+// fit, keeping their aspect ratio, and centered in their space.
+func layoutHandout(slidesPerPage, imageWidth, imageHeight int) (handoutLayout, error) {
+	if err := ValidateSlidesPerPage(slidesPerPage); err != nil {
+		return handoutLayout{}, err
+	}
+	if imageWidth <= 0 || imageHeight <= 0 {
+		return handoutLayout{}, fmt.Errorf("invalid slide image size %dx%d", imageWidth, imageHeight)
+	}
+	aspect := float64(imageHeight) / float64(imageWidth)
+
+	// fit returns the size of the largest slide that fits in width x height
+	fit := func(width, height float64) (float64, float64) {
+		if width*aspect <= height {
+			return width, width * aspect
+		}
+		return height / aspect, height
+	}
+
+	if slidesPerPage == 3 {
+		layout := handoutLayout{Width: a4Short, Height: a4Long}
+		contentWidth := layout.Width - 2*handoutMargin
+		rowHeight := (layout.Height - 2*handoutMargin - 2*handoutGap) / 3
+		slideSpace := (contentWidth - handoutGap) / 2
+
+		for row := range 3 {
+			top := handoutMargin + float64(row)*(rowHeight+handoutGap)
+			width, height := fit(slideSpace, rowHeight-handoutLabel)
+			slideX := handoutMargin + (slideSpace-width)/2
+			notesX := handoutMargin + slideSpace + handoutGap
+			layout.Cells = append(layout.Cells, handoutCell{
+				Label: handoutRect{X: slideX, Y: top, Width: width, Height: handoutLabel},
+				Slide: handoutRect{X: slideX, Y: top + handoutLabel, Width: width, Height: height},
+				Notes: handoutRect{X: notesX, Y: top + handoutLabel, Width: layout.Width - handoutMargin - notesX, Height: rowHeight - handoutLabel},
+			})
+		}
+		return layout, nil
+	}
+
+	columns, rows := slidesPerPage/2, 2
+	if slidesPerPage == 2 {
+		columns, rows = 2, 1
+	}
+	layout := handoutLayout{Landscape: true, Width: a4Long, Height: a4Short}
+	cellWidth := (layout.Width - 2*handoutMargin - float64(columns-1)*handoutGap) / float64(columns)
+	cellHeight := (layout.Height - 2*handoutMargin - float64(rows-1)*handoutGap) / float64(rows)
+
+	for row := range rows {
+		for column := range columns {
+			left := handoutMargin + float64(column)*(cellWidth+handoutGap)
+			top := handoutMargin + float64(row)*(cellHeight+handoutGap)
+			width, height := fit(cellWidth, (cellHeight-handoutLabel)*handoutSlideShare)
+			slideX := left + (cellWidth-width)/2
+			notesY := top + handoutLabel + height + handoutSpacing
+			layout.Cells = append(layout.Cells, handoutCell{
+				Label: handoutRect{X: slideX, Y: top, Width: width, Height: handoutLabel},
+				Slide: handoutRect{X: slideX, Y: top + handoutLabel, Width: width, Height: height},
+				Notes: handoutRect{X: left, Y: notesY, Width: cellWidth, Height: top + cellHeight - notesY},
+			})
+		}
+	}
+	return layout, nil
+}
+
+// handoutPageCount returns the number of handout pages for slideCount slides.
+func handoutPageCount(slideCount, slidesPerPage int) int {
+	return (slideCount + slidesPerPage - 1) / slidesPerPage
+}
+
+// handoutBookmarks returns bookmarks, which point at one page per slide,
+// pointing at the handout pages the slides are on instead.
+func handoutBookmarks(bookmarks []pdfcpu.Bookmark, slidesPerPage int) []pdfcpu.Bookmark {
+	moved := make([]pdfcpu.Bookmark, len(bookmarks))
+	for i, bookmark := range bookmarks {
+		bookmark.PageFrom = (bookmark.PageFrom-1)/slidesPerPage + 1
+		bookmark.Kids = handoutBookmarks(bookmark.Kids, slidesPerPage)
+		moved[i] = bookmark
+	}
+	return moved
+}
+
+// exportHandout exports the slides as a handout, with several slides on each
+// page next to space for notes. It captures each selected slide as a
+// screenshot, like exportSlides, and prints a page that lays them out.
+func (e *Exporter) exportHandout(ctx context.Context, page playwright.Page, serverURL string, pres *presentationInfo, slides []int, bookmarks []pdfcpu.Bookmark, output string, opts ExportOptions) (*ExportResult, error) {
+	tempDir, err := os.MkdirTemp("", "tap-pdf-handout-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	screenshotPaths, err := captureSlides(ctx, page, serverURL, pres, slides, tempDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := loadPNG(screenshotPaths[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to load first image: %w", err)
+	}
+	layout, err := layoutHandout(opts.SlidesPerPage, first.Bounds().Dx(), first.Bounds().Dy())
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]string, len(screenshotPaths))
+	for n, path := range screenshotPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read slide %d: %w", slides[n]+1, err)
+		}
+		images[n] = "data:image/png;base64," + base64.StdEncoding.EncodeToString(data)
+	}
+
+	var allNotes []string
+	if opts.HandoutNotes {
+		for _, i := range slides {
+			if pres != nil {
+				allNotes = append(allNotes, pres.Slides[i].NotesHTML)
+				continue
+			}
+			notes, err := readPresenterNotes(page, serverURL, i)
+			if err != nil {
+				return nil, err
+			}
+			allNotes = append(allNotes, notes)
+		}
+	}
+
+	opts.reportProgress(len(slides), len(slides), StageAssemble)
+	if err := page.SetContent(renderHandoutHTML(layout, slides, images, allNotes), playwright.PageSetContentOptions{
+		WaitUntil: playwright.WaitUntilStateLoad,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to set handout content: %w", err)
+	}
+	if _, err := page.PDF(playwright.PagePdfOptions{
+		Path:              playwright.String(output),
+		Format:            playwright.String("A4"),
+		Landscape:         playwright.Bool(layout.Landscape),
+		PrintBackground:   playwright.Bool(true),
+		PreferCSSPageSize: playwright.Bool(true),
+	}); err != nil {
+		return nil, fmt.Errorf("failed to generate handout PDF: %w", err)
+	}
+
+	if err := e.addMetadata(output, opts); err != nil {
+		return nil, fmt.Errorf("failed to add PDF metadata: %w", err)
+	}
+
+	if err := e.addBookmarks(output, handoutBookmarks(bookmarks, opts.SlidesPerPage)); err != nil {
+		return nil, err
+	}
+
+	return &ExportResult{
+		OutputPath: output,
+		PageCount:  handoutPageCount(len(slides), opts.SlidesPerPage),
+	}, nil
+}
+
+// renderHandoutHTML returns the page that exportHandout prints: the slide
+// images, given as data URLs, placed in the cells of layout, one page after
+// another. Each slide's cell shows its notes HTML if notes are given and the
+// slide has any, and lines for attendee notes otherwise.
+func renderHandoutHTML(layout handoutLayout, slides []int, images []string, notes []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<!DOCTYPE html>
+<html>
+<head>
+<style>
+@page { size: %[1]gmm %[2]gmm; margin: 0; }
+html, body { margin: 0; padding: 0; -webkit-print-color-adjust: exact; print-color-adjust: exact; }
+.handout-page { position: relative; width: %[1]gmm; height: %[2]gmm; overflow: hidden; break-after: page; }
+.handout-page:last-child { break-after: auto; }
+.slide-number { position: absolute; font: 8pt Helvetica, Arial, sans-serif; color: #666; }
+.slide-image { position: absolute; border: 0.2mm solid #ccc; box-sizing: border-box; }
+.notes { position: absolute; overflow: hidden; font: 9pt/1.4 Georgia, serif; }
+.notes p { margin: 0 0 0.5em; }
+.notes-lines { position: absolute; background: repeating-linear-gradient(to bottom, transparent 0, transparent %[3]gmm, #bbb %[3]gmm, #bbb calc(%[3]gmm + 0.2mm)); }
+</style>
+</head>
+<body>
+`, layout.Width, layout.Height, handoutLine-0.2)
+
+	perPage := len(layout.Cells)
+	for start := 0; start < len(images); start += perPage {
+		b.WriteString(`<div class="handout-page">` + "\n")
+		for n := start; n < len(images) && n < start+perPage; n++ {
+			cell := layout.Cells[n-start]
+			fmt.Fprintf(&b, `<div class="slide-number" style="%s">Slide %d</div>`+"\n", rectStyle(cell.Label), slides[n]+1)
+			fmt.Fprintf(&b, `<img class="slide-image" src="%s" style="%s" alt="">`+"\n", html.EscapeString(images[n]), rectStyle(cell.Slide))
+			if n < len(notes) && notes[n] != "" {
+				fmt.Fprintf(&b, `<div class="notes" style="%s">%s</div>`+"\n", rectStyle(cell.Notes), notes[n])
+			} else {
+				fmt.Fprintf(&b, `<div class="notes-lines" style="%s"></div>`+"\n", rectStyle(cell.Notes))
+			}
+		}
+		b.WriteString("</div>\n")
+	}
+	b.WriteString("</body></html>")
+	return b.String()
+}
+
+// rectStyle returns the CSS that positions an element at rect.
+func rectStyle(rect handoutRect) string {
+	return fmt.Sprintf("left: %.2fmm; top: %.2fmm; width: %.2fmm; height: %.2fmm", rect.X, rect.Y, rect.Width, rect.Height)
+}
@@ -0,0 +1,277 @@
+package pdf
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/server"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu"
+)
+
+// inside reports whether inner lies within outer, allowing for rounding.
+func inside(inner, outer handoutRect) bool {
+	const epsilon = 1e-9
+	return inner.X >= outer.X-epsilon && inner.Y >= outer.Y-epsilon &&
+		inner.X+inner.Width <= outer.X+outer.Width+epsilon &&
+		inner.Y+inner.Height <= outer.Y+outer.Height+epsilon
+}
+
+// overlaps reports whether two rectangles share any area.
+func overlaps(a, b handoutRect) bool {
+	const epsilon = 1e-9
+	return a.X+epsilon < b.X+b.Width && b.X+epsilon < a.X+a.Width &&
+		a.Y+epsilon < b.Y+b.Height && b.Y+epsilon < a.Y+a.Height
+}
+
+func TestLayoutHandout(t *testing.T) {
+	tests := []struct {
+		slidesPerPage int
+		width, height int
+		wantLandscape bool
+		wantBeside    bool
+	}{
+		{2, 1920, 1080, true, false},
+		{3, 1920, 1080, false, true},
+		{4, 1920, 1080, true, false},
+		{6, 1920, 1080, true, false},
+		{3, 1440, 1080, false, true},
+		{6, 1440, 1080, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join([]string{itoa(tt.slidesPerPage), itoa(tt.width), itoa(tt.height)}, "-"), func(t *testing.T) {
+			layout, err := layoutHandout(tt.slidesPerPage, tt.width, tt.height)
+			if err != nil {
+				t.Fatalf("layoutHandout() error = %v", err)
+			}
+			if layout.Landscape != tt.wantLandscape {
+				t.Errorf("Landscape = %v, want %v", layout.Landscape, tt.wantLandscape)
+			}
+			if layout.Landscape != (layout.Width > layout.Height) {
+				t.Errorf("page is %gx%g mm, but Landscape = %v", layout.Width, layout.Height, layout.Landscape)
+			}
+			if len(layout.Cells) != tt.slidesPerPage {
+				t.Fatalf("got %d cells, want %d", len(layout.Cells), tt.slidesPerPage)
+			}
+
+			printable := handoutRect{
+				X:      handoutMargin,
+				Y:      handoutMargin,
+				Width:  layout.Width - 2*handoutMargin,
+				Height: layout.Height - 2*handoutMargin,
+			}
+			var rects []handoutRect
+			for n, cell := range layout.Cells {
+				for _, rect := range []handoutRect{cell.Label, cell.Slide, cell.Notes} {
+					if !inside(rect, printable) {
+						t.Errorf("cell %d: %+v is outside the margins", n, rect)
+					}
+					rects = append(rects, rect)
+				}
+
+				// Slides keep their aspect ratio
+				got := cell.Slide.Height / cell.Slide.Width
+				want := float64(tt.height) / float64(tt.width)
+				if math.Abs(got-want) > 1e-9 {
+					t.Errorf("cell %d: slide aspect ratio = %g, want %g", n, got, want)
+				}
+
+				if tt.wantBeside && cell.Notes.X < cell.Slide.X+cell.Slide.Width {
+					t.Errorf("cell %d: expected the notes beside the slide, got slide %+v notes %+v", n, cell.Slide, cell.Notes)
+				}
+				if !tt.wantBeside && cell.Notes.Y < cell.Slide.Y+cell.Slide.Height {
+					t.Errorf("cell %d: expected the notes below the slide, got slide %+v notes %+v", n, cell.Slide, cell.Notes)
+				}
+				if cell.Notes.Height < 20 {
+					t.Errorf("cell %d: notes are only %gmm tall", n, cell.Notes.Height)
+				}
+			}
+
+			for i := range rects {
+				for j := i + 1; j < len(rects); j++ {
+					if overlaps(rects[i], rects[j]) {
+						t.Errorf("%+v overlaps %+v", rects[i], rects[j])
+					}
+				}
+			}
+
+			// Cells are in reading order
+			for n := 1; n < len(layout.Cells); n++ {
+				prev, cur := layout.Cells[n-1].Slide, layout.Cells[n].Slide
+				if cur.Y < prev.Y || (cur.Y == prev.Y && cur.X <= prev.X) {
+					t.Errorf("cell %d at %+v comes before cell %d at %+v", n, cur, n-1, prev)
+				}
+			}
+		})
+	}
+}
+
+func TestLayoutHandoutInvalid(t *testing.T) {
+	tests := []struct {
+		name          string
+		slidesPerPage int
+		width, height int
+		wantErr       string
+	}{
+		{"one per page", 1, 1920, 1080, "must be 2, 3, 4, or 6"},
+		{"five per page", 5, 1920, 1080, "must be 2, 3, 4, or 6"},
+		{"no width", 3, 0, 1080, "invalid slide image size"},
+		{"negative height", 3, 1920, -1, "invalid slide image size"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := layoutHandout(tt.slidesPerPage, tt.width, tt.height)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("layoutHandout() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHandoutPageCount(t *testing.T) {
+	tests := []struct {
+		slides, perPage, want int
+	}{
+		{1, 3, 1},
+		{3, 3, 1},
+		{4, 3, 2},
+		{12, 6, 2},
+		{13, 6, 3},
+		{5, 2, 3},
+	}
+
+	for _, tt := range tests {
+		if got := handoutPageCount(tt.slides, tt.perPage); got != tt.want {
+			t.Errorf("handoutPageCount(%d, %d) = %d, want %d", tt.slides, tt.perPage, got, tt.want)
+		}
+	}
+}
+
+func TestHandoutBookmarks(t *testing.T) {
+	bookmarks := []pdfcpu.Bookmark{
+		{Title: "Intro", PageFrom: 1},
+		{Title: "Part 1", PageFrom: 2, Kids: []pdfcpu.Bookmark{
+			{Title: "Details", PageFrom: 3},
+			{Title: "More", PageFrom: 4},
+		}},
+	}
+
+	got := handoutBookmarks(bookmarks, 3)
+	if got[0].PageFrom != 1 || got[1].PageFrom != 1 {
+		t.Errorf("expected the first three slides on page 1, got %d and %d", got[0].PageFrom, got[1].PageFrom)
+	}
+	if got[1].Kids[0].PageFrom != 1 || got[1].Kids[1].PageFrom != 2 {
+		t.Errorf("expected nested bookmarks moved too, got %+v", got[1].Kids)
+	}
+	if bookmarks[1].Kids[1].PageFrom != 4 {
+		t.Error("expected the original bookmarks to be left alone")
+	}
+}
+
+func TestRenderHandoutHTML(t *testing.T) {
+	layout, err := layoutHandout(2, 1920, 1080)
+	if err != nil {
+		t.Fatalf("layoutHandout() error = %v", err)
+	}
+	images := []string{"data:image/png;base64,AAAA", "data:image/png;base64,BBBB", "data:image/png;base64,CCCC"}
+	page := renderHandoutHTML(layout, []int{0, 4, 7}, images, []string{"<p>Say hello</p>", "", "<p>Wrap up</p>"})
+
+	if got := strings.Count(page, `<div class="handout-page">`); got != 2 {
+		t.Errorf("expected 2 pages, got %d", got)
+	}
+	for _, want := range []string{"Slide 1", "Slide 5", "Slide 8", "<p>Say hello</p>", "<p>Wrap up</p>", "size: 297mm 210mm"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("expected %q in the handout", want)
+		}
+	}
+	// The slide without notes gets lines instead
+	if got := strings.Count(page, `class="notes-lines"`); got != 1 {
+		t.Errorf("expected lines for 1 slide, got %d", got)
+	}
+
+	// Without notes, every slide gets lines
+	page = renderHandoutHTML(layout, []int{0, 4, 7}, images, nil)
+	if got := strings.Count(page, `class="notes-lines"`); got != 3 {
+		t.Errorf("expected lines for 3 slides, got %d", got)
+	}
+}
+
+func TestExportHandout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test in short mode")
+	}
+
+	tempDir := t.TempDir()
+
+	var slides []transformer.TransformedSlide
+	for i := range 4 {
+		slides = append(slides, transformer.TransformedSlide{Index: i, HTML: "<h1>Slide " + itoa(i+1) + "</h1>", Layout: "default"})
+	}
+	srv := server.New(0)
+	srv.SetPresentation(&transformer.TransformedPresentation{Config: *config.DefaultConfig(), Slides: slides})
+	srv.SetupRoutes()
+	if err := srv.Start(); err != nil {
+		t.Fatalf("failed to start server: %v", err)
+	}
+	defer srv.Shutdown(context.Background())
+
+	exp, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer exp.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	outputPath := filepath.Join(tempDir, "handout.pdf")
+	result, err := exp.Export(ctx, "http://localhost:"+itoa(srv.Port()), ExportOptions{
+		Content: ContentHandout,
+		Output:  outputPath,
+	})
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// 4 slides at the default 3 per page
+	if result.PageCount != 2 {
+		t.Errorf("Export() PageCount = %d, want 2", result.PageCount)
+	}
+	pageCount, err := api.PageCountFile(outputPath)
+	if err != nil {
+		t.Fatalf("failed to count pages: %v", err)
+	}
+	if pageCount != result.PageCount {
+		t.Errorf("PDF has %d pages, want %d", pageCount, result.PageCount)
+	}
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Fatalf("output file not found: %v", err)
+	}
+}
+
+func TestExportHandoutInvalidSlidesPerPage(t *testing.T) {
+	exp, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer exp.Close()
+
+	// Fails before the server is contacted or the browser launched
+	_, err = exp.Export(context.Background(), "http://127.0.0.1:0", ExportOptions{
+		Content:       ContentHandout,
+		SlidesPerPage: 5,
+		Output:        filepath.Join(t.TempDir(), "handout.pdf"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "invalid slides per page 5") {
+		t.Errorf("Export() error = %v, want an invalid slides per page error", err)
+	}
+}
@@ -0,0 +1,234 @@
+package pdf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/render"
+	"github.com/playwright-community/playwright-go"
+)
+
+// ImageFormat is the file format of exported slide images.
+type ImageFormat string
+
+const (
+	// ImageFormatPNG exports lossless PNG images.
+	ImageFormatPNG ImageFormat = "png"
+	// ImageFormatJPEG exports JPEG images with ImagesOptions.Quality.
+	ImageFormatJPEG ImageFormat = "jpeg"
+)
+
+// defaultJPEGQuality is the JPEG quality used when ImagesOptions.Quality is 0.
+const defaultJPEGQuality = 90
+
+// ImagesOptions configures the export of slides as images.
+type ImagesOptions struct {
+	// OutDir is the directory the images are written to. It is created if
+	// needed. If empty, defaults to "slides" in the current directory.
+	OutDir string
+	// Format is the image format: "png" or "jpeg". Default is "png".
+	Format ImageFormat
+	// Quality is the JPEG quality from 1 to 100. Default is 90.
+	// It is ignored for PNG images.
+	Quality int
+	// Slides selects the slides to export as 1-based numbers and ranges,
+	// e.g. "1-5,8,10-12". If empty, all slides are exported.
+	// Hidden slides are never exported.
+	Slides string
+	// Scale is the device scale factor: 2 captures a 1920x1080 slide as a
+	// 3840x2160 image for retina-quality output. Default is 1.
+	Scale float64
+	// Progress is called after each slide is captured.
+	// It is called synchronously from the export and may be nil.
+	Progress func(current, total int, stage string)
+}
+
+// ImageFile is a slide image written by ExportImages.
+type ImageFile struct {
+	// Path is the path to the image file.
+	Path string
+	// Slide is the 1-based number of the slide in the image.
+	Slide int
+	// Size is the size of the image file in bytes.
+	Size int64
+}
+
+// ImagesResult contains information about a completed image export.
+type ImagesResult struct {
+	// Files are the exported images, in slide order.
+	Files []ImageFile
+	// Duration is how long the export took.
+	Duration time.Duration
+}
+
+// ValidateImageFormat checks if an image format string is valid.
+// "jpg" is accepted as an alias for "jpeg".
+func ValidateImageFormat(format string) (ImageFormat, error) {
+	switch format {
+	case "png", "":
+		return ImageFormatPNG, nil
+	case "jpeg", "jpg":
+		return ImageFormatJPEG, nil
+	default:
+		return "", fmt.Errorf("invalid image format %q: must be 'png' or 'jpeg'", format)
+	}
+}
+
+// withDefaults returns the options with defaults applied, or an error for
+// options that are out of range.
+func (o ImagesOptions) withDefaults() (ImagesOptions, error) {
+	if o.OutDir == "" {
+		o.OutDir = "slides"
+	}
+
+	format, err := ValidateImageFormat(string(o.Format))
+	if err != nil {
+		return o, err
+	}
+	o.Format = format
+
+	if o.Quality == 0 {
+		o.Quality = defaultJPEGQuality
+	}
+	if o.Quality < 1 || o.Quality > 100 {
+		return o, fmt.Errorf("invalid image quality %d: must be between 1 and 100", o.Quality)
+	}
+
+	if o.Scale == 0 {
+		o.Scale = 1
+	}
+	if o.Scale < 0 {
+		return o, fmt.Errorf("invalid scale %g: must be positive", o.Scale)
+	}
+
+	return o, nil
+}
+
+// imageFileName returns the file name of the image for a 1-based slide number,
+// such as slide-001.png.
+func imageFileName(slide int, format ImageFormat) string {
+	return fmt.Sprintf("slide-%03d.%s", slide, format)
+}
+
+// ExportImages saves each selected slide of a running presentation server as
+// an image file named after its slide number, such as slide-001.png.
+// serverURL should be the base URL of the tap dev server (e.g., "http://localhost:3000").
+// Slides are captured like PDF export: with all fragments shown and after
+// images and maps have loaded.
+func (e *Exporter) ExportImages(ctx context.Context, serverURL string, opts ImagesOptions) (*ImagesResult, error) {
+	startTime := time.Now()
+
+	opts, err := opts.withDefaults()
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the slide selection before launching the browser so an invalid
+	// range fails fast. Hidden slides are only known when the server provides
+	// the presentation API; otherwise all slides are treated as visible.
+	ranges, err := parseSlideRanges(opts.Slides)
+	if err != nil {
+		return nil, err
+	}
+	var selected []int
+	pres, err := fetchPresentation(ctx, serverURL, nil)
+	if err == nil {
+		if len(pres.Slides) == 0 {
+			return nil, fmt.Errorf("no slides found in presentation")
+		}
+		if selected, err = selectSlides(ranges, pres.Slides); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.MkdirAll(opts.OutDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	// Launch browser
+	browser, err := e.launchBrowser()
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new page, scaled for high-resolution images
+	page, err := browser.NewPage(playwright.BrowserNewPageOptions{
+		Viewport: &playwright.Size{
+			Width:  1920,
+			Height: 1080,
+		},
+		DeviceScaleFactor: playwright.Float(opts.Scale),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new page: %w", err)
+	}
+	defer page.Close()
+
+	if selected == nil {
+		// Without the presentation API, count the slides in the page
+		if _, err := page.Goto(serverURL, playwright.PageGotoOptions{
+			WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to navigate to presentation: %w", err)
+		}
+		slideCount, err := e.getSlideCount(page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get slide count: %w", err)
+		}
+		if slideCount == 0 {
+			return nil, fmt.Errorf("no slides found in presentation")
+		}
+		if selected, err = selectSlides(ranges, make([]slideInfo, slideCount)); err != nil {
+			return nil, err
+		}
+	}
+
+	screenshotType := playwright.ScreenshotTypePng
+	var quality *int
+	if opts.Format == ImageFormatJPEG {
+		screenshotType = playwright.ScreenshotTypeJpeg
+		quality = playwright.Int(opts.Quality)
+	}
+
+	result := &ImagesResult{}
+	for n, i := range selected {
+		// Check for context cancellation
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		// Use ?print=true to show all fragments
+		slideURL := render.SlideURL(serverURL, i)
+		if err := render.Slide(page, slideURL, i, pres.isEmbed(i)); err != nil {
+			return nil, err
+		}
+
+		path := filepath.Join(opts.OutDir, imageFileName(i+1, opts.Format))
+		data, err := page.Screenshot(playwright.PageScreenshotOptions{
+			Path:     playwright.String(path),
+			FullPage: playwright.Bool(false),
+			Type:     screenshotType,
+			Quality:  quality,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture slide %d: %w", i+1, err)
+		}
+
+		result.Files = append(result.Files, ImageFile{
+			Path:  path,
+			Slide: i + 1,
+			Size:  int64(len(data)),
+		})
+		if opts.Progress != nil {
+			opts.Progress(n+1, len(selected), StageCapture)
+		}
+	}
+
+	result.Duration = time.Since(startTime)
+	return result, nil
+}
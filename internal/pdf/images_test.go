@@ -0,0 +1,102 @@
+package pdf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateImageFormat(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    ImageFormat
+		wantErr bool
+	}{
+		{"png", ImageFormatPNG, false},
+		{"", ImageFormatPNG, false},
+		{"jpeg", ImageFormatJPEG, false},
+		{"jpg", ImageFormatJPEG, false},
+		{"gif", "", true},
+		{"PNG", "", true}, // case sensitive
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ValidateImageFormat(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateImageFormat(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ValidateImageFormat(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImagesOptionsWithDefaults(t *testing.T) {
+	opts, err := ImagesOptions{}.withDefaults()
+	if err != nil {
+		t.Fatalf("withDefaults() error = %v", err)
+	}
+	if opts.OutDir != "slides" || opts.Format != ImageFormatPNG || opts.Quality != 90 || opts.Scale != 1 {
+		t.Errorf("withDefaults() = %+v, want slides/png/90/1", opts)
+	}
+
+	opts, err = ImagesOptions{OutDir: "out", Format: "jpg", Quality: 75, Scale: 2}.withDefaults()
+	if err != nil {
+		t.Fatalf("withDefaults() error = %v", err)
+	}
+	if opts.OutDir != "out" || opts.Format != ImageFormatJPEG || opts.Quality != 75 || opts.Scale != 2 {
+		t.Errorf("withDefaults() = %+v, want out/jpeg/75/2", opts)
+	}
+
+	for _, invalid := range []ImagesOptions{
+		{Format: "gif"},
+		{Quality: 101},
+		{Quality: -1},
+		{Scale: -2},
+	} {
+		if _, err := invalid.withDefaults(); err == nil {
+			t.Errorf("withDefaults(%+v) should fail", invalid)
+		}
+	}
+}
+
+func TestImageFileName(t *testing.T) {
+	if got := imageFileName(1, ImageFormatPNG); got != "slide-001.png" {
+		t.Errorf("imageFileName(1, png) = %q, want slide-001.png", got)
+	}
+	if got := imageFileName(12, ImageFormatJPEG); got != "slide-012.jpeg" {
+		t.Errorf("imageFileName(12, jpeg) = %q, want slide-012.jpeg", got)
+	}
+}
+
+func TestExportImages_InvalidOptionsFailBeforeBrowser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"slides":[{"index":0},{"index":1}]}`))
+	}))
+	defer srv.Close()
+
+	exp, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer exp.Close()
+
+	for _, opts := range []ImagesOptions{
+		{Slides: "1-3"},
+		{Format: "gif"},
+		{Scale: -1},
+	} {
+		opts.OutDir = filepath.Join(t.TempDir(), "images")
+		if _, err := exp.ExportImages(context.Background(), srv.URL, opts); err == nil {
+			t.Errorf("ExportImages() with %+v should fail", opts)
+		}
+	}
+	if exp.browser != nil {
+		t.Error("browser should not be launched for invalid options")
+	}
+}
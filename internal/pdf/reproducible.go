@@ -0,0 +1,88 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+// pdfDatePattern matches the creation and modification dates in a PDF's
+// document information dictionary. Group 2 is the date string.
+var pdfDatePattern = regexp.MustCompile(`(/(?:CreationDate|ModDate)\s*\()(D:[^)]*)\)`)
+
+// pdfIDPattern matches the file identifier in a PDF's trailer or cross-reference
+// stream. Groups 1 and 2 are the hex strings of its two parts.
+var pdfIDPattern = regexp.MustCompile(`/ID\s*\[\s*<([0-9A-Fa-f]+)>\s*<([0-9A-Fa-f]+)>\s*\]`)
+
+// ParseSourceDateEpoch parses the value of the SOURCE_DATE_EPOCH environment
+// variable, the number of seconds since the Unix epoch used by reproducible
+// builds, into a UTC time. An empty value gives the zero time.
+func ParseSourceDateEpoch(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || seconds < 0 {
+		return time.Time{}, fmt.Errorf("invalid SOURCE_DATE_EPOCH %q: must be a number of seconds since 1970", value)
+	}
+	return time.Unix(seconds, 0).UTC(), nil
+}
+
+// setCreationDate rewrites the PDF at path so it doesn't depend on when it was
+// written: its creation and modification dates become date, and its file
+// identifier becomes a hash of its content. The values are replaced in place
+// with values of the same length, so the offsets in the cross-reference table
+// stay valid.
+func setCreationDate(path string, date time.Time) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	pdfDate := types.DateString(date.UTC())
+	var dateErr error
+	data = pdfDatePattern.ReplaceAllFunc(data, func(match []byte) []byte {
+		sub := pdfDatePattern.FindSubmatch(match)
+		if len(sub[2]) != len(pdfDate) {
+			dateErr = fmt.Errorf("unexpected PDF date %q", sub[2])
+			return match
+		}
+		return []byte(string(sub[1]) + pdfDate + ")")
+	})
+	if dateErr != nil {
+		return dateErr
+	}
+
+	// The identifier is usually derived from the time of writing; derive it
+	// from the rest of the file instead
+	ids := pdfIDPattern.FindAllSubmatchIndex(data, -1)
+	for _, loc := range ids {
+		for _, group := range [][2]int{{loc[2], loc[3]}, {loc[4], loc[5]}} {
+			copy(data[group[0]:group[1]], bytes.Repeat([]byte("0"), group[1]-group[0]))
+		}
+	}
+	sum := sha256.Sum256(data)
+	id := strings.ToUpper(hex.EncodeToString(sum[:]))
+	for _, loc := range ids {
+		for _, group := range [][2]int{{loc[2], loc[3]}, {loc[4], loc[5]}} {
+			if group[1]-group[0] > len(id) {
+				return fmt.Errorf("unexpected PDF file identifier length %d", group[1]-group[0])
+			}
+			copy(data[group[0]:group[1]], id)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
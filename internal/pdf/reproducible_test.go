@@ -0,0 +1,84 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+)
+
+func TestParseSourceDateEpoch(t *testing.T) {
+	got, err := ParseSourceDateEpoch("1700000000")
+	if err != nil {
+		t.Fatalf("ParseSourceDateEpoch() error = %v", err)
+	}
+	if want := time.Date(2023, 11, 14, 22, 13, 20, 0, time.UTC); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("ParseSourceDateEpoch() = %v, want %v", got, want)
+	}
+
+	if got, err := ParseSourceDateEpoch(""); err != nil || !got.IsZero() {
+		t.Errorf("ParseSourceDateEpoch(\"\") = %v, %v, want the zero time", got, err)
+	}
+	for _, value := range []string{"yesterday", "-1", "1.5"} {
+		if _, err := ParseSourceDateEpoch(value); err == nil {
+			t.Errorf("ParseSourceDateEpoch(%q) expected an error", value)
+		}
+	}
+}
+
+func TestSetCreationDate(t *testing.T) {
+	dir := t.TempDir()
+	image := filepath.Join(dir, "slide-000.png")
+	writeTestPNG(t, image)
+	date := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	// Two exports of the same slides differ in their file identifiers, and
+	// their dates when they are a second apart
+	var exports [][]byte
+	for i := 0; i < 2; i++ {
+		output := filepath.Join(dir, fmt.Sprintf("out-%d.pdf", i))
+		e := &Exporter{}
+		if err := e.imagesToPDF([]string{image}, output); err != nil {
+			t.Fatalf("imagesToPDF() error = %v", err)
+		}
+		if err := e.addMetadata(output, ExportOptions{Title: "Talk"}); err != nil {
+			t.Fatalf("addMetadata() error = %v", err)
+		}
+		before, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pdfIDPattern.Match(before) || !pdfDatePattern.Match(before) {
+			t.Fatalf("expected the PDF to have dates and an identifier")
+		}
+
+		if err := setCreationDate(output, date); err != nil {
+			t.Fatalf("setCreationDate() error = %v", err)
+		}
+		if err := api.ValidateFile(output, nil); err != nil {
+			t.Errorf("rewritten PDF is invalid: %v", err)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != len(before) {
+			t.Errorf("size changed from %d to %d bytes", len(before), len(data))
+		}
+		exports = append(exports, data)
+	}
+
+	if !bytes.Equal(exports[0], exports[1]) {
+		t.Error("expected both exports to have the same bytes")
+	}
+	if !bytes.Contains(exports[0], []byte("/CreationDate(D:20240301093000+00'00')")) {
+		t.Error("expected the fixed creation date in the PDF")
+	}
+	if bytes.Contains(exports[0], []byte("<00000000")) {
+		t.Error("expected a file identifier derived from the content")
+	}
+}
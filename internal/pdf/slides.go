@@ -0,0 +1,195 @@
+package pdf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// slideRange is an inclusive range of 1-based slide numbers.
+type slideRange struct {
+	first int
+	last  int
+}
+
+// presentationInfo holds the presentation data used to select and bookmark slides.
+type presentationInfo struct {
+	Slides []slideInfo `json:"slides"`
+	TOC    []tocEntry  `json:"toc"`
+	Config struct {
+		AspectRatio string `json:"aspectRatio"`
+	} `json:"config"`
+}
+
+// aspectRatio returns the presentation's aspect ratio, or "" if it is not
+// known.
+func (p *presentationInfo) aspectRatio() string {
+	if p == nil {
+		return ""
+	}
+	return p.Config.AspectRatio
+}
+
+// isEmbed reports whether the slide with the given zero-based index embeds a
+// page, or false if the presentation is not known.
+func (p *presentationInfo) isEmbed(index int) bool {
+	if p == nil || index < 0 || index >= len(p.Slides) {
+		return false
+	}
+	return p.Slides[index].Embed != nil
+}
+
+// slideInfo holds the per-slide presentation data used to select slides for
+// export and to export their notes.
+type slideInfo struct {
+	Layout    string `json:"layout"`
+	NotesHTML string `json:"notesHTML"`
+	Hidden    bool   `json:"hidden"`
+	Embed     *struct {
+		URL string `json:"url"`
+	} `json:"embed"`
+}
+
+// tocEntry is an entry in the presentation's table of contents.
+type tocEntry struct {
+	Title      string     `json:"title"`
+	Children   []tocEntry `json:"children"`
+	SlideIndex int        `json:"slideIndex"`
+}
+
+// ParseSlideRange parses a comma-separated list of 1-based slide numbers and
+// ranges, such as "1-5,8,10-12", and returns the selected zero-based slide
+// indices in ascending order without duplicates. An empty spec selects all
+// slides. Reversed ranges and slide numbers outside 1..slideCount are errors.
+func ParseSlideRange(spec string, slideCount int) ([]int, error) {
+	ranges, err := parseSlideRanges(spec)
+	if err != nil {
+		return nil, err
+	}
+	return expandSlideRanges(ranges, slideCount)
+}
+
+// parseSlideRanges checks the syntax of a slide range list without knowing the
+// number of slides. It returns nil for an empty spec.
+func parseSlideRanges(spec string) ([]slideRange, error) {
+	if strings.TrimSpace(spec) == "" {
+		return nil, nil
+	}
+
+	var ranges []slideRange
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return nil, fmt.Errorf("invalid slide range %q: empty entry", spec)
+		}
+
+		firstStr, lastStr, isRange := strings.Cut(part, "-")
+		first, err := parseSlideNumber(firstStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid slide range %q: %w", part, err)
+		}
+		last := first
+		if isRange {
+			last, err = parseSlideNumber(lastStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slide range %q: %w", part, err)
+			}
+		}
+		if first > last {
+			return nil, fmt.Errorf("invalid slide range %q: start is after end", part)
+		}
+
+		ranges = append(ranges, slideRange{first: first, last: last})
+	}
+	return ranges, nil
+}
+
+// parseSlideNumber parses a single 1-based slide number.
+func parseSlideNumber(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a slide number", strings.TrimSpace(s))
+	}
+	if n < 1 {
+		return 0, fmt.Errorf("slide numbers start at 1")
+	}
+	return n, nil
+}
+
+// expandSlideRanges converts ranges to sorted, deduplicated zero-based indices.
+// Nil ranges select all slides.
+func expandSlideRanges(ranges []slideRange, slideCount int) ([]int, error) {
+	selected := make([]bool, slideCount)
+	if ranges == nil {
+		for i := range selected {
+			selected[i] = true
+		}
+	}
+	for _, r := range ranges {
+		if r.last > slideCount {
+			return nil, fmt.Errorf("slide %d is out of range: presentation has %d slides", r.last, slideCount)
+		}
+		for n := r.first; n <= r.last; n++ {
+			selected[n-1] = true
+		}
+	}
+
+	var indices []int
+	for i, ok := range selected {
+		if ok {
+			indices = append(indices, i)
+		}
+	}
+	return indices, nil
+}
+
+// selectSlides returns the zero-based indices of the slides to export: the
+// slides in ranges, excluding hidden slides.
+func selectSlides(ranges []slideRange, slides []slideInfo) ([]int, error) {
+	indices, err := expandSlideRanges(ranges, len(slides))
+	if err != nil {
+		return nil, err
+	}
+
+	var visible []int
+	for _, i := range indices {
+		if !slides[i].Hidden {
+			visible = append(visible, i)
+		}
+	}
+	if len(visible) == 0 {
+		return nil, fmt.Errorf("no slides to export: all selected slides are hidden")
+	}
+	return visible, nil
+}
+
+// fetchPresentation reads the slides and table of contents from the server's
+// presentation API, sending headers with the request.
+func fetchPresentation(ctx context.Context, serverURL string, headers map[string]string) (*presentationInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serverURL+"/api/presentation", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create presentation request: %w", err)
+	}
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch presentation: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch presentation: %s", resp.Status)
+	}
+
+	var data presentationInfo
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("failed to decode presentation: %w", err)
+	}
+	return &data, nil
+}
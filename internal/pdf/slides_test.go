@@ -0,0 +1,182 @@
+package pdf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseSlideRange(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    []int
+		wantErr bool
+	}{
+		{"", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, false},
+		{"1-5,8,10-12", []int{0, 1, 2, 3, 4, 7, 9, 10, 11}, false},
+		{"3", []int{2}, false},
+		{" 2 - 3 , 1 ", []int{0, 1, 2}, false},
+		{"4-6,5,1", []int{0, 3, 4, 5}, false}, // sorted and deduplicated
+		{"5-3", nil, true},                    // reversed
+		{"0", nil, true},                      // slide numbers are 1-based
+		{"13", nil, true},                     // out of bounds
+		{"10-13", nil, true},                  // range end out of bounds
+		{"1,,2", nil, true},
+		{"a-b", nil, true},
+		{"-3", nil, true},
+		{"1-", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseSlideRange(tt.spec, 12)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSlideRange(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSlideRange(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectSlides_SkipsHidden(t *testing.T) {
+	slides := []slideInfo{{}, {Hidden: true}, {}, {Hidden: true}}
+
+	got, err := selectSlides(nil, slides)
+	if err != nil {
+		t.Fatalf("selectSlides() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []int{0, 2}) {
+		t.Errorf("selectSlides() = %v, want [0 2]", got)
+	}
+
+	ranges, err := parseSlideRanges("2,4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := selectSlides(ranges, slides); err == nil {
+		t.Error("expected error when all selected slides are hidden")
+	}
+}
+
+func TestFetchPresentation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/presentation" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"slides":[{"index":0,"layout":"section"},{"index":1,"hidden":true}],"toc":[{"title":"Intro","slideIndex":0}]}`))
+	}))
+	defer srv.Close()
+
+	pres, err := fetchPresentation(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("fetchPresentation() error = %v", err)
+	}
+	if !reflect.DeepEqual(pres.Slides, []slideInfo{{Layout: "section"}, {Hidden: true}}) {
+		t.Errorf("fetchPresentation() slides = %+v", pres.Slides)
+	}
+	if !reflect.DeepEqual(pres.TOC, []tocEntry{{Title: "Intro", SlideIndex: 0}}) {
+		t.Errorf("fetchPresentation() toc = %+v", pres.TOC)
+	}
+
+	if _, err := fetchPresentation(context.Background(), srv.URL+"/missing", nil); err == nil {
+		t.Error("expected error for a server without the presentation API")
+	}
+}
+
+func TestPresentationInfoIsEmbed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"slides":[{"index":0},{"index":1,"layout":"embed","embed":{"url":"https://localhost:5173","sandbox":[]}}]}`))
+	}))
+	defer srv.Close()
+
+	pres, err := fetchPresentation(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatalf("fetchPresentation() error = %v", err)
+	}
+	for index, want := range []bool{false, true, false} {
+		if got := pres.isEmbed(index); got != want {
+			t.Errorf("isEmbed(%d) = %v, want %v", index, got, want)
+		}
+	}
+
+	// Without the presentation API, no slide is known to embed a page
+	var unknown *presentationInfo
+	if unknown.isEmbed(0) {
+		t.Error("expected isEmbed to be false without a presentation")
+	}
+}
+
+func TestFetchPresentationSendsHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, password, ok := r.BasicAuth(); !ok || password != "secret" {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"slides":[{"index":0}]}`))
+	}))
+	defer srv.Close()
+
+	if _, err := fetchPresentation(context.Background(), srv.URL, nil); err == nil {
+		t.Error("expected error without credentials")
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.SetBasicAuth("", "secret")
+	headers := map[string]string{"Authorization": req.Header.Get("Authorization")}
+	if _, err := fetchPresentation(context.Background(), srv.URL, headers); err != nil {
+		t.Errorf("fetchPresentation() with credentials error = %v", err)
+	}
+}
+
+func TestExport_InvalidSlideRangeFailsBeforeBrowser(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"slides":[{"index":0},{"index":1}]}`))
+	}))
+	defer srv.Close()
+
+	exp, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer exp.Close()
+
+	for _, spec := range []string{"2-1", "1-3"} {
+		_, err := exp.Export(context.Background(), srv.URL, ExportOptions{
+			Output: filepath.Join(t.TempDir(), "out.pdf"),
+			Slides: spec,
+		})
+		if err == nil {
+			t.Errorf("Export() with slides %q should fail", spec)
+		}
+	}
+	if exp.browser != nil {
+		t.Error("browser should not be launched for an invalid slide range")
+	}
+}
+
+func TestExportFile_InvalidSlideRange(t *testing.T) {
+	mdFile := filepath.Join(t.TempDir(), "deck.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide 1\n\n---\n\n# Slide 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer exp.Close()
+
+	if _, err := exp.ExportFile(context.Background(), mdFile, ExportOptions{Slides: "3"}); err == nil {
+		t.Error("expected error for a slide number past the end of the presentation")
+	}
+	if exp.browser != nil {
+		t.Error("browser should not be launched for an invalid slide range")
+	}
+}
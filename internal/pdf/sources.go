@@ -0,0 +1,252 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+
+	"github.com/MiniCodeMonkey/tap/internal/builder"
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// DefaultMaxSourceSize is the largest total size of the source files attached
+// with ExportOptions.EmbedSource when ExportOptions.MaxSourceSize is 0.
+const DefaultMaxSourceSize int64 = 100 << 20
+
+// SourcesManifestName is the name of the attachment that lists the attached
+// source files and the paths they had relative to the markdown file.
+const SourcesManifestName = "sources.json"
+
+// externalSourceDir is the directory that source files outside the markdown
+// file's directory are listed under in the sources manifest.
+const externalSourceDir = "external"
+
+// sourcesManifestVersion is the version of the sources manifest format.
+const sourcesManifestVersion = 1
+
+// sourceFile is a file attached to an exported PDF with EmbedSource.
+type sourceFile struct {
+	// Name is the name of the attachment: sanitized and unique in the PDF.
+	Name string
+	// Path is where the file goes when the presentation is recovered,
+	// relative to the markdown file's directory, with forward slashes.
+	Path string
+	// Data is the content of the file.
+	Data []byte
+	// ModTime is when the file was last modified.
+	ModTime time.Time
+}
+
+// sourcesManifest is the content of the sources.json attachment.
+type sourcesManifest struct {
+	Version  int                  `json:"version"`
+	Markdown string               `json:"markdown"`
+	Files    []sourceManifestItem `json:"files"`
+}
+
+// sourceManifestItem is a source file listed in the sources manifest.
+type sourceManifestItem struct {
+	Attachment string `json:"attachment"`
+	Path       string `json:"path"`
+	Size       int    `json:"size"`
+}
+
+// collectSources reads the files of a presentation to attach to its PDF: the
+// markdown file, the files it includes, and the local images and other files
+// its slides reference. Referenced files that don't exist are skipped. It
+// returns an error if the files are larger than maxSize in total, before
+// anything is written.
+func collectSources(markdownPath string, cfg *config.Config, pres *parser.Presentation, maxSize int64) ([]sourceFile, error) {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSourceSize
+	}
+	baseDir := filepath.Dir(markdownPath)
+
+	paths := append([]string{markdownPath}, pres.Includes...)
+	paths = append(paths, builder.AssetPaths(transformer.New(cfg).Transform(pres), baseDir)...)
+
+	var sources []sourceFile
+	var total int64
+	seen := make(map[string]bool)
+	names := map[string]bool{strings.ToLower(SourcesManifestName): true}
+	for i, p := range paths {
+		if seen[p] {
+			continue
+		}
+		seen[p] = true
+
+		info, err := os.Stat(p)
+		if err != nil || info.IsDir() {
+			if i == 0 {
+				return nil, fmt.Errorf("failed to read markdown file: %w", err)
+			}
+			continue
+		}
+		total += info.Size()
+		if total > maxSize {
+			return nil, fmt.Errorf("source files are over %s, the limit for embedding them in the PDF", formatLimit(maxSize))
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source file: %w", err)
+		}
+		relPath := sourcePath(baseDir, p)
+		sources = append(sources, sourceFile{
+			Name:    uniqueAttachmentName(path.Base(relPath), names),
+			Path:    relPath,
+			Data:    data,
+			ModTime: info.ModTime(),
+		})
+	}
+	return sources, nil
+}
+
+// sourcePath returns the path of a source file relative to baseDir, with
+// forward slashes. Files outside baseDir are listed under externalSourceDir,
+// so recovering the presentation never writes outside its directory.
+func sourcePath(baseDir, p string) string {
+	rel, err := filepath.Rel(baseDir, p)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		rel = filepath.Join(externalSourceDir, filepath.Base(p))
+	}
+	return filepath.ToSlash(rel)
+}
+
+// sanitizeAttachmentName returns name with only letters, digits, ".", "-",
+// and "_", so it is a safe file name on every system. Leading dots are
+// removed, and an empty name becomes "file".
+func sanitizeAttachmentName(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	sanitized := strings.TrimLeft(b.String(), ".")
+	if sanitized == "" {
+		return "file"
+	}
+	return sanitized
+}
+
+// uniqueAttachmentName returns the sanitized name, with a number added before
+// its extension if the name is already used, ignoring case. The returned
+// name is added to used.
+func uniqueAttachmentName(name string, used map[string]bool) string {
+	name = sanitizeAttachmentName(name)
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+
+	unique := name
+	for n := 2; used[strings.ToLower(unique)]; n++ {
+		unique = fmt.Sprintf("%s-%d%s", stem, n, ext)
+	}
+	used[strings.ToLower(unique)] = true
+	return unique
+}
+
+// formatLimit formats a size limit in bytes, in MB if it is at least 1 MB.
+func formatLimit(size int64) string {
+	if size >= 1<<20 {
+		return fmt.Sprintf("%.0f MB", float64(size)/(1<<20))
+	}
+	return fmt.Sprintf("%d bytes", size)
+}
+
+// sourcesManifestJSON returns the sources.json attachment listing sources.
+func sourcesManifestJSON(sources []sourceFile) ([]byte, error) {
+	manifest := sourcesManifest{Version: sourcesManifestVersion}
+	for i, source := range sources {
+		if i == 0 {
+			manifest.Markdown = source.Path
+		}
+		manifest.Files = append(manifest.Files, sourceManifestItem{
+			Attachment: source.Name,
+			Path:       source.Path,
+			Size:       len(source.Data),
+		})
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", SourcesManifestName, err)
+	}
+	return data, nil
+}
+
+// attachSources embeds sources and a sources.json listing them in the PDF at
+// pdfPath as file attachments. If modTime is set, it is used as the
+// modification time of every attachment, so reproducible exports don't depend
+// on when the files were last changed.
+func attachSources(pdfPath string, sources []sourceFile, modTime time.Time) error {
+	if len(sources) == 0 {
+		return nil
+	}
+
+	manifest, err := sourcesManifestJSON(sources)
+	if err != nil {
+		return err
+	}
+
+	input, err := os.ReadFile(pdfPath)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	conf := model.NewDefaultConfiguration()
+	conf.Cmd = model.ADDATTACHMENTS
+	ctx, err := api.ReadValidateAndOptimize(bytes.NewReader(input), conf)
+	if err != nil {
+		return fmt.Errorf("failed to read PDF: %w", err)
+	}
+
+	manifestTime := modTime
+	if manifestTime.IsZero() {
+		manifestTime = time.Now()
+	}
+	attachments := []model.Attachment{{
+		Reader:  bytes.NewReader(manifest),
+		ID:      SourcesManifestName,
+		Desc:    "Paths of the attached source files",
+		ModTime: &manifestTime,
+	}}
+	for _, source := range sources {
+		t := source.ModTime
+		if !modTime.IsZero() {
+			t = modTime
+		}
+		attachments = append(attachments, model.Attachment{
+			Reader:  bytes.NewReader(source.Data),
+			ID:      source.Name,
+			Desc:    source.Path,
+			ModTime: &t,
+		})
+	}
+	for _, attachment := range attachments {
+		if err := ctx.AddAttachment(attachment, false); err != nil {
+			return fmt.Errorf("failed to attach %s: %w", attachment.ID, err)
+		}
+	}
+
+	var output bytes.Buffer
+	if err := api.Write(ctx, &output, conf); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	if err := os.WriteFile(pdfPath, output.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// writeSourceDeck writes a presentation that includes a file and references
+// images with clashing and unsafe names, one outside its directory, and one
+// that doesn't exist. It returns the path of the markdown file.
+func writeSourceDeck(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	dir := filepath.Join(root, "deck")
+
+	files := map[string]string{
+		"deck/slides.md": "# Intro\n\n![Logo](images/logo.png)\n\n---\n\n<!-- include: sections/more.md -->\n\n---\n\n" +
+			"![Other logo](other/logo.png)\n\n![Space](images/my%20photo%C3%A9.png)\n\n![Shared](../shared/chart.png)\n\n![Missing](images/missing.png)\n",
+		"deck/sections/more.md":     "# More\n\nText",
+		"deck/images/logo.png":      "logo one",
+		"deck/other/logo.png":       "logo two",
+		"deck/images/my photoé.png": "photo",
+		"shared/chart.png":          "chart",
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "slides.md")
+}
+
+// collectTestSources parses the presentation at markdownPath and collects
+// its source files.
+func collectTestSources(t *testing.T, markdownPath string, maxSize int64) ([]sourceFile, error) {
+	t.Helper()
+	pres, err := parser.New().ParseFile(markdownPath)
+	if err != nil {
+		t.Fatalf("ParseFile() error = %v", err)
+	}
+	return collectSources(markdownPath, config.DefaultConfig(), pres, maxSize)
+}
+
+func TestCollectSources(t *testing.T) {
+	sources, err := collectTestSources(t, writeSourceDeck(t), 0)
+	if err != nil {
+		t.Fatalf("collectSources() error = %v", err)
+	}
+
+	want := []struct{ name, path string }{
+		{"slides.md", "slides.md"},
+		{"more.md", "sections/more.md"},
+		{"logo.png", "images/logo.png"},
+		{"logo-2.png", "other/logo.png"},
+		{"my_photo_.png", "images/my photoé.png"},
+		{"chart.png", "external/chart.png"},
+	}
+	if len(sources) != len(want) {
+		for _, s := range sources {
+			t.Logf("source %s: %s", s.Name, s.Path)
+		}
+		t.Fatalf("got %d sources, want %d", len(sources), len(want))
+	}
+	for i, w := range want {
+		if sources[i].Name != w.name || sources[i].Path != w.path {
+			t.Errorf("source %d = %s (%s), want %s (%s)", i, sources[i].Name, sources[i].Path, w.name, w.path)
+		}
+	}
+}
+
+func TestCollectSources_TooLarge(t *testing.T) {
+	_, err := collectTestSources(t, writeSourceDeck(t), 64)
+	if err == nil || !strings.Contains(err.Error(), "64 bytes") {
+		t.Errorf("collectSources() error = %v, want the size limit", err)
+	}
+}
+
+func TestUniqueAttachmentName(t *testing.T) {
+	used := map[string]bool{"sources.json": true}
+	tests := []struct{ name, want string }{
+		{"logo.png", "logo.png"},
+		{"Logo.png", "Logo-2.png"},
+		{"logo.png", "logo-3.png"},
+		{"sources.json", "sources-2.json"},
+		{"../etc/passwd", "_etc_passwd"},
+		{"...", "file"},
+		{"", "file-2"},
+		{"résumé slides.md", "r_sum__slides.md"},
+	}
+	for _, tt := range tests {
+		if got := uniqueAttachmentName(tt.name, used); got != tt.want {
+			t.Errorf("uniqueAttachmentName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAttachSources_RoundTrip(t *testing.T) {
+	markdownPath := writeSourceDeck(t)
+	sources, err := collectTestSources(t, markdownPath, 0)
+	if err != nil {
+		t.Fatalf("collectSources() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	image := filepath.Join(dir, "slide-000.png")
+	writeTestPNG(t, image)
+	output := filepath.Join(dir, "out.pdf")
+	e := &Exporter{}
+	if err := e.imagesToPDF([]string{image}, output); err != nil {
+		t.Fatalf("imagesToPDF() error = %v", err)
+	}
+
+	date := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	if err := attachSources(output, sources, date); err != nil {
+		t.Fatalf("attachSources() error = %v", err)
+	}
+	if err := setCreationDate(output, date); err != nil {
+		t.Fatalf("setCreationDate() error = %v", err)
+	}
+	if err := api.ValidateFile(output, nil); err != nil {
+		t.Fatalf("PDF with attachments is invalid: %v", err)
+	}
+
+	extracted := t.TempDir()
+	if err := api.ExtractAttachmentsFile(output, extracted, nil, nil); err != nil {
+		t.Fatalf("ExtractAttachmentsFile() error = %v", err)
+	}
+
+	// Every attachment has the bytes of the file it was read from
+	deckDir := filepath.Dir(markdownPath)
+	for _, source := range sources {
+		got, err := os.ReadFile(filepath.Join(extracted, source.Name))
+		if err != nil {
+			t.Errorf("attachment %s: %v", source.Name, err)
+			continue
+		}
+		original := filepath.Join(deckDir, filepath.FromSlash(source.Path))
+		if source.Path == "external/chart.png" {
+			original = filepath.Join(deckDir, "..", "shared", "chart.png")
+		}
+		want, err := os.ReadFile(original)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("attachment %s = %q, want the bytes of %s", source.Name, got, original)
+		}
+	}
+
+	// sources.json maps the attachments back to their paths
+	data, err := os.ReadFile(filepath.Join(extracted, SourcesManifestName))
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", SourcesManifestName, err)
+	}
+	var manifest sourcesManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if manifest.Version != 1 || manifest.Markdown != "slides.md" || len(manifest.Files) != len(sources) {
+		t.Fatalf("manifest = %+v", manifest)
+	}
+	for i, file := range manifest.Files {
+		if file.Attachment != sources[i].Name || file.Path != sources[i].Path || file.Size != len(sources[i].Data) {
+			t.Errorf("manifest file %d = %+v, want %s at %s", i, file, sources[i].Name, sources[i].Path)
+		}
+	}
+}
+
+func TestAttachSources_Reproducible(t *testing.T) {
+	markdownPath := writeSourceDeck(t)
+	sources, err := collectTestSources(t, markdownPath, 0)
+	if err != nil {
+		t.Fatalf("collectSources() error = %v", err)
+	}
+
+	dir := t.TempDir()
+	image := filepath.Join(dir, "slide-000.png")
+	writeTestPNG(t, image)
+	date := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	var exports [][]byte
+	for _, name := range []string{"a.pdf", "b.pdf"} {
+		output := filepath.Join(dir, name)
+		e := &Exporter{}
+		if err := e.imagesToPDF([]string{image}, output); err != nil {
+			t.Fatalf("imagesToPDF() error = %v", err)
+		}
+		if err := attachSources(output, sources, date); err != nil {
+			t.Fatalf("attachSources() error = %v", err)
+		}
+		if err := setCreationDate(output, date); err != nil {
+			t.Fatalf("setCreationDate() error = %v", err)
+		}
+		data, err := os.ReadFile(output)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exports = append(exports, data)
+		time.Sleep(1100 * time.Millisecond)
+	}
+	if !bytes.Equal(exports[0], exports[1]) {
+		t.Error("expected exports with the same creation date to have the same bytes")
+	}
+}
@@ -0,0 +1,114 @@
+// Package render renders the slides of a running presentation in a headless
+// browser, for PDF exports, slide images, and thumbnails.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ErrBrowserUnavailable is returned by Browser.Launch when the playwright
+// driver or chromium isn't installed and the browser doesn't install them.
+var ErrBrowserUnavailable = errors.New("chromium is not installed")
+
+// ErrBrowserClosed is returned by Browser.Launch after Close, so a render
+// that starts while the dev server shuts down doesn't leave chromium running.
+var ErrBrowserClosed = errors.New("browser is closed")
+
+// Browser is a headless chromium, launched on first use and kept running
+// until Close is called. It is safe for concurrent use.
+type Browser struct {
+	install bool
+	closed  bool
+	mu      sync.Mutex
+	pw      *playwright.Playwright
+	browser playwright.Browser
+}
+
+// NewBrowser creates a Browser. With install, Launch downloads the playwright
+// driver and chromium when they're missing; without it, Launch fails with
+// ErrBrowserUnavailable instead.
+func NewBrowser(install bool) *Browser {
+	return &Browser{install: install}
+}
+
+// Launch launches the browser if it isn't running, and returns it. It fails
+// with ErrBrowserClosed once Close has been called.
+func (b *Browser) Launch() (playwright.Browser, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBrowserClosed
+	}
+
+	if b.browser != nil {
+		return b.browser, nil
+	}
+
+	if b.install {
+		// Install browsers if not already installed
+		err := playwright.Install(&playwright.RunOptions{
+			Browsers: []string{"chromium"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to install playwright browsers: %w", err)
+		}
+	}
+
+	pw, err := playwright.Run()
+	if err != nil {
+		if !b.install {
+			return nil, fmt.Errorf("%w: %v", ErrBrowserUnavailable, err)
+		}
+		return nil, fmt.Errorf("failed to start playwright: %w", err)
+	}
+
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+		Headless: playwright.Bool(true),
+	})
+	if err != nil {
+		_ = pw.Stop()
+		if !b.install {
+			return nil, fmt.Errorf("%w: %v", ErrBrowserUnavailable, err)
+		}
+		return nil, fmt.Errorf("failed to launch chromium: %w", err)
+	}
+	b.pw = pw
+	b.browser = browser
+
+	return browser, nil
+}
+
+// Close stops the browser, if it was launched. The browser can't be
+// launched again afterwards.
+func (b *Browser) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+
+	var errs []error
+
+	if b.browser != nil {
+		if err := b.browser.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close browser: %w", err))
+		}
+		b.browser = nil
+	}
+
+	if b.pw != nil {
+		if err := b.pw.Stop(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to stop playwright: %w", err))
+		}
+		b.pw = nil
+	}
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
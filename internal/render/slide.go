@@ -0,0 +1,245 @@
+package render
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// SlideURL returns the address of the slide with the given zero-based index
+// on the presentation served at serverURL, in print mode so all fragments
+// are shown.
+func SlideURL(serverURL string, index int) string {
+	return fmt.Sprintf("%s?print=true#%d", serverURL, index+1)
+}
+
+// embedPlaceholderTimeout is how long to wait for the placeholder card an
+// embed slide shows in print mode, in milliseconds.
+const embedPlaceholderTimeout = 10000
+
+// Slide navigates to the slide with the given zero-based index at
+// slideURL and waits until it is ready for a screenshot: the page has loaded,
+// its images, map tiles, and mermaid diagrams are loaded, and animations
+// have completed. Embed slides show a placeholder card instead of the
+// embedded page, so they wait for the card rather than for the network to
+// go idle, which a live demo may never do.
+func Slide(page playwright.Page, slideURL string, index int, embed bool) error {
+	if _, err := page.Goto(slideURL, playwright.PageGotoOptions{
+		WaitUntil: playwright.WaitUntilStateDomcontentloaded,
+	}); err != nil {
+		return fmt.Errorf("failed to navigate to slide %d: %w", index+1, err)
+	}
+
+	// Wait for slide to render
+	if embed {
+		if err := page.Locator(".embed-placeholder").First().WaitFor(playwright.LocatorWaitForOptions{
+			Timeout: playwright.Float(embedPlaceholderTimeout),
+		}); err != nil {
+			return fmt.Errorf("failed to wait for slide %d to load: %w", index+1, err)
+		}
+	} else if err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+		State: playwright.LoadStateNetworkidle,
+	}); err != nil {
+		return fmt.Errorf("failed to wait for slide %d to load: %w", index+1, err)
+	}
+
+	// Wait for all images to be fully loaded
+	if err := waitForImages(page); err != nil {
+		return fmt.Errorf("failed to wait for images on slide %d: %w", index+1, err)
+	}
+
+	// Wait for map tiles to load (if slide has a map)
+	if err := waitForMaps(page); err != nil {
+		return fmt.Errorf("failed to wait for maps on slide %d: %w", index+1, err)
+	}
+
+	// Wait for mermaid diagrams to render (if slide has diagrams)
+	if err := waitForMermaid(page); err != nil {
+		return fmt.Errorf("failed to wait for diagrams on slide %d: %w", index+1, err)
+	}
+
+	// Wait for terminal recordings to show their first frame (if slide has recordings)
+	if err := waitForCasts(page); err != nil {
+		return fmt.Errorf("failed to wait for recordings on slide %d: %w", index+1, err)
+	}
+
+	// Small delay to ensure animations complete
+	time.Sleep(200 * time.Millisecond)
+	return nil
+}
+
+// waitForImages waits for all images on the page to be fully loaded.
+func waitForImages(page playwright.Page) error {
+	// Wait for all images to complete loading with a timeout
+	_, err := page.Evaluate(`() => {
+		return new Promise((resolve, reject) => {
+			const timeout = setTimeout(() => {
+				resolve(); // Don't fail on timeout, just continue
+			}, 5000);
+
+			const images = Array.from(document.querySelectorAll('img'));
+			if (images.length === 0) {
+				clearTimeout(timeout);
+				resolve();
+				return;
+			}
+
+			let loaded = 0;
+			const total = images.length;
+
+			const checkComplete = () => {
+				loaded++;
+				if (loaded >= total) {
+					clearTimeout(timeout);
+					resolve();
+				}
+			};
+
+			images.forEach(img => {
+				if (img.complete && img.naturalHeight !== 0) {
+					checkComplete();
+				} else {
+					img.addEventListener('load', checkComplete);
+					img.addEventListener('error', checkComplete); // Count errors as "done"
+				}
+			});
+		});
+	}`)
+	return err
+}
+
+// waitForMaps waits for map tiles to be loaded on the page.
+// Maps use MapLibre GL which exposes __tapMapReady on window when ready.
+func waitForMaps(page playwright.Page) error {
+	// Check if page has a map and wait for it to be ready
+	_, err := page.Evaluate(`() => {
+		return new Promise((resolve) => {
+			// If no map on this slide, resolve immediately
+			const mapSlide = document.querySelector('.map-slide');
+			if (!mapSlide) {
+				resolve();
+				return;
+			}
+
+			// Set a timeout for map loading (3 seconds max)
+			const timeout = setTimeout(() => {
+				console.warn('Map tiles timeout - continuing anyway');
+				resolve();
+			}, 3000);
+
+			// Check if map is already ready
+			if (window.__tapMapReady && window.__tapMap) {
+				// Wait for tiles to load
+				const map = window.__tapMap;
+				if (map.loaded() && map.areTilesLoaded && map.areTilesLoaded()) {
+					clearTimeout(timeout);
+					resolve();
+					return;
+				}
+
+				// Wait for idle event (all tiles loaded)
+				map.once('idle', () => {
+					clearTimeout(timeout);
+					resolve();
+				});
+				return;
+			}
+
+			// Poll for map ready state
+			const checkInterval = setInterval(() => {
+				if (window.__tapMapReady && window.__tapMap) {
+					clearInterval(checkInterval);
+					const map = window.__tapMap;
+					if (map.loaded() && map.areTilesLoaded && map.areTilesLoaded()) {
+						clearTimeout(timeout);
+						resolve();
+						return;
+					}
+					map.once('idle', () => {
+						clearTimeout(timeout);
+						resolve();
+					});
+				}
+			}, 100);
+
+			// Also clear interval on timeout
+			setTimeout(() => {
+				clearInterval(checkInterval);
+			}, 3000);
+		});
+	}`)
+	return err
+}
+
+// waitForMermaid waits for mermaid diagrams on the page to be rendered.
+// The frontend replaces each mermaid code block with a .mermaid element and
+// marks it data-processed once the diagram (or its error) is in place.
+func waitForMermaid(page playwright.Page) error {
+	_, err := page.Evaluate(`() => {
+		return new Promise((resolve) => {
+			const rendered = () =>
+				document.querySelectorAll('pre > code.language-mermaid').length === 0 &&
+				document.querySelectorAll('.mermaid:not([data-processed])').length === 0;
+
+			// If no diagrams on this slide, or they're already rendered, resolve immediately
+			if (rendered()) {
+				resolve();
+				return;
+			}
+
+			// Set a timeout for diagram rendering (5 seconds max)
+			const timeout = setTimeout(() => {
+				clearInterval(checkInterval);
+				console.warn('Mermaid diagrams timeout - continuing anyway');
+				resolve();
+			}, 5000);
+
+			// Poll until every diagram is processed
+			const checkInterval = setInterval(() => {
+				if (rendered()) {
+					clearInterval(checkInterval);
+					clearTimeout(timeout);
+					resolve();
+				}
+			}, 100);
+		});
+	}`)
+	return err
+}
+
+// waitForCasts waits for the terminal recordings of cast blocks on the page
+// to show the frame they are captured at. In print mode the frontend doesn't
+// play recordings; it replaces each cast block with a player showing the
+// frame at the block's start time, and marks its wrapper data-cast-ready once
+// the frame (or an error) is in place.
+func waitForCasts(page playwright.Page) error {
+	_, err := page.Evaluate(`() => {
+		return new Promise((resolve) => {
+			const rendered = () =>
+				document.querySelectorAll('pre > code.language-cast').length === 0 &&
+				document.querySelectorAll('[data-cast]:not([data-cast-ready])').length === 0;
+
+			if (rendered()) {
+				resolve();
+				return;
+			}
+
+			// Recordings are fetched and parsed by the player, so allow as long as diagrams
+			const timeout = setTimeout(() => {
+				clearInterval(checkInterval);
+				console.warn('Terminal recordings timeout - continuing anyway');
+				resolve();
+			}, 5000);
+
+			const checkInterval = setInterval(() => {
+				if (rendered()) {
+					clearInterval(checkInterval);
+					clearTimeout(timeout);
+					resolve();
+				}
+			}, 100);
+		});
+	}`)
+	return err
+}
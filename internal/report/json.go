@@ -0,0 +1,75 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// JSONReporter writes each event as a JSON object on its own line.
+type JSONReporter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewJSON returns a Reporter that writes JSON lines to w.
+func NewJSON(w io.Writer) *JSONReporter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &JSONReporter{enc: enc}
+}
+
+// Progress writes a progress event. The progress object is left out if total
+// is 0.
+func (r *JSONReporter) Progress(stage, message string, current, total int) {
+	event := Event{Type: EventProgress, Stage: stage, Message: message}
+	if total > 0 {
+		event.Progress = &Progress{Current: current, Total: total}
+	}
+	r.write(event)
+}
+
+// Info writes an info event.
+func (r *JSONReporter) Info(stage, message string) {
+	r.write(Event{Type: EventInfo, Stage: stage, Message: message})
+}
+
+// Warning writes a warning event.
+func (r *JSONReporter) Warning(stage, message string) {
+	r.write(Event{Type: EventWarning, Stage: stage, Message: message})
+}
+
+// Issue writes an issue event with the issue as its issue field.
+func (r *JSONReporter) Issue(stage, severity, message string, issue any) {
+	r.write(Event{Type: EventIssue, Stage: stage, Message: message, Issue: issue})
+}
+
+// Error writes an error event with the error message as its error field.
+func (r *JSONReporter) Error(stage string, err error) {
+	r.write(Event{Type: EventError, Stage: stage, Error: err.Error()})
+}
+
+// Result writes a result event with the result as its result field and the
+// summary's title as its message.
+func (r *JSONReporter) Result(stage string, result any, summary Summary) {
+	r.write(Event{Type: EventResult, Stage: stage, Message: summary.Title, Result: result})
+}
+
+// Close does nothing; every event is written when it happens.
+func (r *JSONReporter) Close() {}
+
+// write encodes event as one line. An event whose issue or result can't be
+// encoded is written as an error event instead, so every line stays valid.
+func (r *JSONReporter) write(event Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.enc.Encode(event); err != nil {
+		_ = r.enc.Encode(Event{
+			Type:  EventError,
+			Stage: event.Stage,
+			Error: fmt.Sprintf("failed to encode %s event: %v", event.Type, err),
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Colors of the pretty sink
+var (
+	successColor = color.New(color.FgGreen)
+	errorColor   = color.New(color.FgRed)
+	infoColor    = color.New(color.FgBlue)
+	warningColor = color.New(color.FgYellow)
+	mutedColor   = color.New(color.FgHiBlack)
+)
+
+// progressBarWidth is the number of cells in the progress bar of steps with
+// a known count.
+const progressBarWidth = 20
+
+// PrettyReporter writes events for people: progress as a spinner on a single
+// line, and warnings, errors, and results in color.
+type PrettyReporter struct {
+	w       io.Writer
+	spinner *spinner
+
+	issues   int // Issues printed since the last result
+	warnings int // Warnings printed since the last result or info message
+}
+
+// NewPretty returns a Reporter that writes styled text to w.
+func NewPretty(w io.Writer) *PrettyReporter {
+	return &PrettyReporter{w: w}
+}
+
+// Progress shows message next to the spinner, starting it if needed. Steps
+// with a known count get a progress bar.
+func (r *PrettyReporter) Progress(stage, message string, current, total int) {
+	if total > 0 {
+		message = progressLine(message, current, total)
+	}
+	if r.spinner == nil {
+		r.spinner = newSpinner(r.w, message)
+		r.spinner.start()
+		return
+	}
+	r.spinner.update(message)
+}
+
+// Info prints message in a muted color.
+func (r *PrettyReporter) Info(stage, message string) {
+	r.Close()
+	if r.warnings > 0 {
+		fmt.Fprintln(r.w)
+		r.warnings = 0
+	}
+	mutedColor.Fprintln(r.w, message)
+}
+
+// Warning prints message as an indented warning.
+func (r *PrettyReporter) Warning(stage, message string) {
+	r.Close()
+	warningColor.Fprintf(r.w, "  Warning: %s\n", message)
+	r.warnings++
+}
+
+// Issue prints message after its severity, in red for errors and yellow for
+// warnings.
+func (r *PrettyReporter) Issue(stage, severity, message string, issue any) {
+	r.Close()
+	if severity == "error" {
+		errorColor.Fprintf(r.w, "  error    %s\n", message)
+	} else {
+		warningColor.Fprintf(r.w, "  warning  %s\n", message)
+	}
+	r.issues++
+}
+
+// Error prints err in red.
+func (r *PrettyReporter) Error(stage string, err error) {
+	r.Close()
+	errorColor.Fprintf(r.w, "Error: %v\n", err)
+}
+
+// Result prints the summary: its title, and its fields aligned below it.
+// A summary without fields is printed as a single line, after a blank line
+// if issues were printed. The title is red if the summary failed, yellow if
+// issues were printed, and green otherwise.
+func (r *PrettyReporter) Result(stage string, result any, summary Summary) {
+	r.Close()
+	titleColor := successColor
+	if summary.Failed {
+		titleColor = errorColor
+	} else if r.issues > 0 {
+		titleColor = warningColor
+	}
+
+	if len(summary.Fields) == 0 {
+		if r.issues > 0 {
+			fmt.Fprintln(r.w)
+		}
+		titleColor.Fprintln(r.w, summary.Title)
+		r.issues = 0
+		return
+	}
+
+	titleColor.Fprintf(r.w, "\n%s\n", summary.Title)
+	fmt.Fprintln(r.w)
+	width := 0
+	for _, field := range summary.Fields {
+		width = max(width, len(field.Label)+1)
+	}
+	for _, field := range summary.Fields {
+		fmt.Fprintf(r.w, "  %-*s %s\n", width, field.Label+":", field.Value)
+	}
+	fmt.Fprintln(r.w)
+	r.issues = 0
+	r.warnings = 0
+}
+
+// Close stops the spinner and clears its line.
+func (r *PrettyReporter) Close() {
+	if r.spinner != nil {
+		r.spinner.stop()
+		r.spinner = nil
+	}
+}
+
+// progressLine renders a step with a known count as a progress bar, such as
+// "Capturing slides ████░░░░ 3/12".
+func progressLine(message string, current, total int) string {
+	filled := min(max(current*progressBarWidth/total, 0), progressBarWidth)
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", progressBarWidth-filled)
+	return fmt.Sprintf("%s %s %d/%d", message, bar, current, total)
+}
+
+// spinner provides a simple terminal spinner for progress display
+type spinner struct {
+	w       io.Writer
+	done    chan bool
+	mu      sync.Mutex
+	message string
+	running bool
+}
+
+// newSpinner creates a new spinner with the given message
+func newSpinner(w io.Writer, message string) *spinner {
+	return &spinner{
+		w:       w,
+		message: message,
+		done:    make(chan bool),
+	}
+}
+
+// start begins the spinner animation
+func (s *spinner) start() {
+	s.running = true
+	frames := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	frameIndex := 0
+
+	go func() {
+		ticker := time.NewTicker(80 * time.Millisecond)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-s.done:
+				return
+			case <-ticker.C:
+				// Clear line and print spinner
+				s.mu.Lock()
+				fmt.Fprintf(s.w, "\r\033[K%s %s", infoColor.Sprint(frames[frameIndex]), s.message)
+				s.mu.Unlock()
+				frameIndex = (frameIndex + 1) % len(frames)
+			}
+		}
+	}()
+}
+
+// update changes the spinner message
+func (s *spinner) update(message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.message = message
+}
+
+// stop stops the spinner animation
+func (s *spinner) stop() {
+	if s.running {
+		s.running = false
+		s.done <- true
+		// Clear the spinner line
+		fmt.Fprint(s.w, "\r\033[K")
+	}
+}
@@ -0,0 +1,137 @@
+// Package report writes the progress and results of long-running commands,
+// such as build, pdf, and lint, either styled for people or as JSON lines
+// that CI can parse.
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Format selects how a Reporter writes its events.
+type Format string
+
+const (
+	// FormatPretty writes events for people, with a spinner and colors.
+	FormatPretty Format = "pretty"
+	// FormatJSON writes one JSON object per event, on its own line.
+	FormatJSON Format = "json"
+)
+
+// EnvOutput is the environment variable that selects the output format when
+// the --json flag isn't given: "json" or "pretty".
+const EnvOutput = "TAP_OUTPUT"
+
+// Exit codes of commands that report through this package.
+const (
+	// ExitOK means the operation succeeded.
+	ExitOK = 0
+	// ExitError means the operation failed, for example because a file
+	// couldn't be read or the browser couldn't be started.
+	ExitError = 1
+	// ExitValidation means the presentation or its configuration is invalid,
+	// for example because lint found errors or strict mode found warnings.
+	ExitValidation = 2
+)
+
+// ResolveFormat returns the output format selected by the --json flag, or by
+// the value of the TAP_OUTPUT environment variable if the flag isn't set.
+// An empty value selects FormatPretty.
+func ResolveFormat(jsonFlag bool, env string) (Format, error) {
+	if jsonFlag {
+		return FormatJSON, nil
+	}
+	switch Format(strings.ToLower(strings.TrimSpace(env))) {
+	case "", FormatPretty:
+		return FormatPretty, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("invalid %s %q: must be json or pretty", EnvOutput, env)
+}
+
+// EventType is the kind of an Event.
+type EventType string
+
+const (
+	// EventProgress reports a step of the operation, with a count if known.
+	EventProgress EventType = "progress"
+	// EventInfo reports a message that is neither progress nor a problem.
+	EventInfo EventType = "info"
+	// EventWarning reports a problem that doesn't stop the operation.
+	EventWarning EventType = "warning"
+	// EventIssue reports a problem found in the presentation, such as a lint
+	// issue; see Event.Issue.
+	EventIssue EventType = "issue"
+	// EventError reports the error that stopped the operation.
+	EventError EventType = "error"
+	// EventResult reports the result of the completed operation; see
+	// Event.Result.
+	EventResult EventType = "result"
+)
+
+// Event is one line of JSON output. The field names are stable, so CI
+// scripts can rely on them.
+type Event struct {
+	Type     EventType `json:"type"`
+	Stage    string    `json:"stage"`
+	Message  string    `json:"message,omitempty"`
+	Progress *Progress `json:"progress,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Issue    any       `json:"issue,omitempty"`
+	Result   any       `json:"result,omitempty"`
+}
+
+// Progress is how far an operation is, such as 3 of 12 slides captured.
+type Progress struct {
+	Current int `json:"current"`
+	Total   int `json:"total"`
+}
+
+// Summary is how the pretty sink prints a result: a title, followed by one
+// aligned line per field. The JSON sink writes the result itself instead.
+type Summary struct {
+	Title  string
+	Fields []Field
+	// Failed prints the title as a failure rather than a success, such as a
+	// lint run that found errors.
+	Failed bool
+}
+
+// Field is a labelled value of a Summary, such as "Pages: 12".
+type Field struct {
+	Label string
+	Value string
+}
+
+// Reporter receives the events of a long-running operation. stage names the
+// operation or the step of it the event belongs to, such as "build" or
+// "capture". Close must be called when the operation is done.
+type Reporter interface {
+	// Progress reports a step of the operation. total is 0 if the number
+	// of steps isn't known.
+	Progress(stage, message string, current, total int)
+	// Info reports a message such as a hint about what to do next.
+	Info(stage, message string)
+	// Warning reports a problem that doesn't stop the operation.
+	Warning(stage, message string)
+	// Issue reports a problem found in the presentation. severity is
+	// "error" or "warning", and issue is written as is to JSON output.
+	Issue(stage, severity, message string, issue any)
+	// Error reports the error that stopped the operation.
+	Error(stage string, err error)
+	// Result reports the result of the completed operation. The JSON sink
+	// writes result, and the pretty sink prints summary.
+	Result(stage string, result any, summary Summary)
+	// Close stops the spinner of the pretty sink.
+	Close()
+}
+
+// New returns a Reporter that writes events to w in format.
+func New(format Format, w io.Writer) Reporter {
+	if format == FormatJSON {
+		return NewJSON(w)
+	}
+	return NewPretty(w)
+}
@@ -0,0 +1,185 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// jsonLines decodes each line of out as a JSON object.
+func jsonLines(t *testing.T, out string) []map[string]any {
+	t.Helper()
+	var events []map[string]any
+	for i, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		var event map[string]any
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v\n%s", i+1, err, line)
+		}
+		events = append(events, event)
+	}
+	return events
+}
+
+// keys returns the sorted keys of a JSON object.
+func keys(object map[string]any) []string {
+	var names []string
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestJSONReporter(t *testing.T) {
+	var buf bytes.Buffer
+	rep := NewJSON(&buf)
+
+	rep.Progress("build", "Parsing presentation", 0, 0)
+	rep.Progress("capture", "Capturing slides", 3, 12)
+	rep.Info("build", "Run 'tap serve dist' to preview the build.")
+	rep.Warning("build", "video is <large>")
+	rep.Issue("lint", "error", "slide 2: image a.png not found", map[string]any{"severity": "error", "slideIndex": 1})
+	rep.Error("pdf", errors.New("browser not found"))
+	rep.Result("build", struct {
+		OutputDir string `json:"outputDir"`
+	}{"dist"}, Summary{Title: "Build complete!", Fields: []Field{{Label: "Output", Value: "dist"}}})
+	rep.Close()
+
+	events := jsonLines(t, buf.String())
+	want := []struct {
+		keys  []string
+		event Event
+	}{
+		{[]string{"message", "stage", "type"}, Event{Type: EventProgress, Stage: "build"}},
+		{[]string{"message", "progress", "stage", "type"}, Event{Type: EventProgress, Stage: "capture"}},
+		{[]string{"message", "stage", "type"}, Event{Type: EventInfo, Stage: "build"}},
+		{[]string{"message", "stage", "type"}, Event{Type: EventWarning, Stage: "build"}},
+		{[]string{"issue", "message", "stage", "type"}, Event{Type: EventIssue, Stage: "lint"}},
+		{[]string{"error", "stage", "type"}, Event{Type: EventError, Stage: "pdf"}},
+		{[]string{"message", "result", "stage", "type"}, Event{Type: EventResult, Stage: "build"}},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(events), len(want), buf.String())
+	}
+	for i, w := range want {
+		if got := keys(events[i]); !reflect.DeepEqual(got, w.keys) {
+			t.Errorf("line %d has fields %v, want %v", i+1, got, w.keys)
+		}
+		if events[i]["type"] != string(w.event.Type) || events[i]["stage"] != w.event.Stage {
+			t.Errorf("line %d = %v, want type %q and stage %q", i+1, events[i], w.event.Type, w.event.Stage)
+		}
+	}
+
+	if progress := events[1]["progress"]; !reflect.DeepEqual(progress, map[string]any{"current": 3.0, "total": 12.0}) {
+		t.Errorf("progress = %v, want current 3 and total 12", progress)
+	}
+	if events[3]["message"] != "video is <large>" {
+		t.Errorf("message = %q, want HTML characters unescaped", events[3]["message"])
+	}
+	if events[5]["error"] != "browser not found" {
+		t.Errorf("error = %q, want the error message", events[5]["error"])
+	}
+	if result := events[6]["result"]; !reflect.DeepEqual(result, map[string]any{"outputDir": "dist"}) {
+		t.Errorf("result = %v, want the result as is", result)
+	}
+}
+
+func TestJSONReporter_UnencodableResult(t *testing.T) {
+	var buf bytes.Buffer
+	rep := NewJSON(&buf)
+
+	rep.Result("build", map[string]any{"bad": func() {}}, Summary{Title: "Build complete!"})
+
+	events := jsonLines(t, buf.String())
+	if len(events) != 1 || events[0]["type"] != string(EventError) || events[0]["stage"] != "build" {
+		t.Errorf("got %v, want a single error event", events)
+	}
+}
+
+func TestPrettyReporter(t *testing.T) {
+	var buf bytes.Buffer
+	rep := NewPretty(&buf)
+
+	rep.Result("pdf", nil, Summary{
+		Title: "PDF export complete!",
+		Fields: []Field{
+			{Label: "Output", Value: "slides.pdf"},
+			{Label: "File size", Value: "1.00 MB"},
+		},
+	})
+	rep.Warning("pdf", "slow")
+	rep.Info("pdf", "Done")
+
+	want := "\nPDF export complete!\n\n" +
+		"  Output:    slides.pdf\n" +
+		"  File size: 1.00 MB\n\n" +
+		"  Warning: slow\n\n" +
+		"Done\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestPrettyReporter_Issues(t *testing.T) {
+	var buf bytes.Buffer
+	rep := NewPretty(&buf)
+
+	rep.Issue("lint", "error", "slide 1: image a.png not found", nil)
+	rep.Issue("lint", "warning", "slide 2: slide is empty", nil)
+	rep.Result("lint", nil, Summary{Title: "1 errors, 1 warnings", Failed: true})
+
+	want := "  error    slide 1: image a.png not found\n" +
+		"  warning  slide 2: slide is empty\n\n" +
+		"1 errors, 1 warnings\n"
+	if got := buf.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestProgressLine(t *testing.T) {
+	tests := []struct {
+		current, total int
+		want           string
+	}{
+		{0, 4, "Capturing slides ░░░░░░░░░░░░░░░░░░░░ 0/4"},
+		{1, 4, "Capturing slides █████░░░░░░░░░░░░░░░ 1/4"},
+		{4, 4, "Capturing slides ████████████████████ 4/4"},
+		{5, 4, "Capturing slides ████████████████████ 5/4"},
+	}
+	for _, tt := range tests {
+		if got := progressLine("Capturing slides", tt.current, tt.total); got != tt.want {
+			t.Errorf("progressLine(%d, %d) = %q, want %q", tt.current, tt.total, got, tt.want)
+		}
+	}
+}
+
+func TestResolveFormat(t *testing.T) {
+	tests := []struct {
+		flag    bool
+		env     string
+		want    Format
+		wantErr bool
+	}{
+		{false, "", FormatPretty, false},
+		{false, "json", FormatJSON, false},
+		{false, " JSON ", FormatJSON, false},
+		{false, "pretty", FormatPretty, false},
+		{true, "pretty", FormatJSON, false},
+		{true, "xml", FormatJSON, false},
+		{false, "xml", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ResolveFormat(tt.flag, tt.env)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ResolveFormat(%v, %q) error = %v, wantErr %v", tt.flag, tt.env, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ResolveFormat(%v, %q) = %q, want %q", tt.flag, tt.env, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+// Package scaffold creates new presentation directories from built-in templates.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/MiniCodeMonkey/tap/internal/themes"
+)
+
+// DeckFile is the name of the presentation file in a new presentation directory.
+const DeckFile = "deck.md"
+
+// ImagesDir is the name of the directory for images in a new presentation directory.
+const ImagesDir = "images"
+
+// DefaultTemplate is the template used when none is given.
+const DefaultTemplate = "basic"
+
+// DefaultTheme is the theme used when none is given.
+const DefaultTheme = "paper"
+
+// DefaultAspectRatio is the aspect ratio written to the frontmatter of a new deck.
+const DefaultAspectRatio = "16:9"
+
+// gitignore is the content of the .gitignore file in a new presentation directory.
+const gitignore = `# Output of tap build
+dist/
+
+.DS_Store
+`
+
+//go:embed templates/*.md
+var templateFS embed.FS
+
+// ErrDirNotEmpty is returned when the target directory already has files in it.
+var ErrDirNotEmpty = errors.New("directory is not empty")
+
+// Template is a built-in presentation template.
+type Template struct {
+	Name        string
+	Description string
+}
+
+// Templates lists the built-in templates, in the order they are offered.
+var Templates = []Template{
+	{Name: "basic", Description: "Title, agenda, key points, and a closing slide"},
+	{Name: "code-heavy", Description: "Code walkthroughs with line highlighting, diffs, and a live demo"},
+	{Name: "workshop", Description: "Schedule, exercises, section slides, and speaker notes"},
+}
+
+// Options configures the presentation directory to create.
+type Options struct {
+	// Dir is the directory to create.
+	Dir string
+	// Title is the presentation title. Defaults to a title derived from Dir.
+	Title string
+	// Theme is the name of a built-in theme. Defaults to DefaultTheme.
+	Theme string
+	// Template is the name of a built-in template. Defaults to DefaultTemplate.
+	Template string
+	// Force allows creating the presentation in a non-empty directory,
+	// overwriting existing files with the same names.
+	Force bool
+}
+
+// Result describes a created presentation directory.
+type Result struct {
+	// Dir is the created directory.
+	Dir string
+	// DeckPath is the path of the presentation file.
+	DeckPath string
+	// Files are the created files and directories, relative to Dir.
+	Files []string
+}
+
+// templateData is the data the templates are executed with.
+type templateData struct {
+	Title       string
+	Theme       string
+	Date        string
+	AspectRatio string
+}
+
+// templateFuncs are the functions available in the templates.
+var templateFuncs = template.FuncMap{
+	"quote": quoteYAML,
+}
+
+// quoteYAML returns s as a double-quoted YAML string.
+// JSON strings are valid YAML double-quoted scalars.
+func quoteYAML(s string) string {
+	quoted, err := json.Marshal(s)
+	if err != nil {
+		return `""`
+	}
+	return string(quoted)
+}
+
+// FindTemplate returns the built-in template with the given name.
+func FindTemplate(name string) (Template, bool) {
+	for _, t := range Templates {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// TemplateNames returns the names of the built-in templates.
+func TemplateNames() []string {
+	names := make([]string, len(Templates))
+	for i, t := range Templates {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// TitleFromDir returns a presentation title derived from a directory name,
+// such as "My Talk" for "my-talk".
+func TitleFromDir(dir string) string {
+	words := strings.FieldsFunc(filepath.Base(filepath.Clean(dir)), func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || unicode.IsSpace(r)
+	})
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	if len(words) == 0 {
+		return "My Presentation"
+	}
+	return strings.Join(words, " ")
+}
+
+// CheckDir returns ErrDirNotEmpty if dir exists and has files in it, unless
+// force is set. A directory that does not exist yet is fine.
+func CheckDir(dir string, force bool) error {
+	info, err := os.Stat(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s exists and is not a directory", dir)
+	}
+	if force {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%s: %w (use --force to create the presentation anyway)", dir, ErrDirNotEmpty)
+	}
+	return nil
+}
+
+// Render returns the deck.md content of a template with the title and theme
+// filled in.
+func Render(templateName, title, theme string) (string, error) {
+	if _, ok := FindTemplate(templateName); !ok {
+		return "", fmt.Errorf("unknown template %q (available: %s)", templateName, strings.Join(TemplateNames(), ", "))
+	}
+
+	source, err := templateFS.ReadFile("templates/" + templateName + ".md")
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := template.New(templateName).Funcs(templateFuncs).Parse(string(source))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, templateData{
+		Title:       title,
+		Theme:       theme,
+		Date:        time.Now().Format("2006-01-02"),
+		AspectRatio: DefaultAspectRatio,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to render template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// Create creates a presentation directory with a deck.md rendered from a
+// template, an images directory, and a .gitignore for the build output.
+func Create(opts Options) (*Result, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("no directory given")
+	}
+	if opts.Title == "" {
+		opts.Title = TitleFromDir(opts.Dir)
+	}
+	if opts.Theme == "" {
+		opts.Theme = DefaultTheme
+	}
+	if opts.Template == "" {
+		opts.Template = DefaultTemplate
+	}
+	if !isBuiltInTheme(opts.Theme) {
+		return nil, fmt.Errorf("unknown theme %q", opts.Theme)
+	}
+
+	deck, err := Render(opts.Template, opts.Title, opts.Theme)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckDir(opts.Dir, opts.Force); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Join(opts.Dir, ImagesDir), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// Keep the empty images directory in version control
+	gitkeep := filepath.Join(ImagesDir, ".gitkeep")
+	files := []struct {
+		name    string
+		content string
+	}{
+		{DeckFile, deck},
+		{".gitignore", gitignore},
+		{gitkeep, ""},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(opts.Dir, f.name), []byte(f.content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+
+	return &Result{
+		Dir:      opts.Dir,
+		DeckPath: filepath.Join(opts.Dir, DeckFile),
+		Files:    []string{DeckFile, ".gitignore", ImagesDir + "/", gitkeep},
+	}, nil
+}
+
+// isBuiltInTheme reports whether name is a built-in theme.
+func isBuiltInTheme(name string) bool {
+	for _, t := range themes.BuiltIn {
+		if t.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,226 @@
+package scaffold
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// fileTree returns the files and directories under dir, relative to dir,
+// with a trailing slash on directories.
+func fileTree(t *testing.T, dir string) []string {
+	t.Helper()
+	var tree []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || path == dir {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			rel += "/"
+		}
+		tree = append(tree, rel)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk %s: %v", dir, err)
+	}
+	sort.Strings(tree)
+	return tree
+}
+
+func TestCreate_FileTree(t *testing.T) {
+	for _, tmpl := range Templates {
+		t.Run(tmpl.Name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "my-talk")
+			result, err := Create(Options{Dir: dir, Template: tmpl.Name, Theme: "noir"})
+			if err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			want := []string{".gitignore", "deck.md", "images/", "images/.gitkeep"}
+			if got := fileTree(t, dir); !reflect.DeepEqual(got, want) {
+				t.Errorf("file tree = %v, want %v", got, want)
+			}
+			if result.DeckPath != filepath.Join(dir, DeckFile) {
+				t.Errorf("DeckPath = %q, want %q", result.DeckPath, filepath.Join(dir, DeckFile))
+			}
+
+			gitignore, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+			if err != nil {
+				t.Fatalf("failed to read .gitignore: %v", err)
+			}
+			if !strings.Contains(string(gitignore), "dist/") {
+				t.Errorf(".gitignore = %q, want it to ignore dist/", gitignore)
+			}
+
+			// The deck is a valid presentation with the title and theme filled in
+			cfg, err := config.Load(result.DeckPath, nil, config.Overrides{})
+			if err != nil {
+				t.Fatalf("config.Load() error = %v", err)
+			}
+			if cfg.Title != "My Talk" || cfg.Theme != "noir" || cfg.AspectRatio != "16:9" {
+				t.Errorf("frontmatter = title %q, theme %q, aspectRatio %q", cfg.Title, cfg.Theme, cfg.AspectRatio)
+			}
+
+			content, err := os.ReadFile(result.DeckPath)
+			if err != nil {
+				t.Fatalf("failed to read deck: %v", err)
+			}
+			pres, err := parser.New().Parse(content)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(pres.Slides) < 3 {
+				t.Errorf("template has %d slides, want at least 3", len(pres.Slides))
+			}
+			if !strings.Contains(string(content), "# My Talk\n") {
+				t.Error("deck should start with a title slide")
+			}
+		})
+	}
+}
+
+func TestCreate_Defaults(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "quarterly_review")
+	result, err := Create(Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	cfg, err := config.Load(result.DeckPath, nil, config.Overrides{})
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if cfg.Title != "Quarterly Review" || cfg.Theme != DefaultTheme {
+		t.Errorf("frontmatter = title %q, theme %q", cfg.Title, cfg.Theme)
+	}
+
+	basic, err := Render(DefaultTemplate, "Quarterly Review", DefaultTheme)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	content, _ := os.ReadFile(result.DeckPath)
+	if string(content) != basic {
+		t.Error("deck should be rendered from the default template")
+	}
+}
+
+func TestCreate_TitleIsQuoted(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "talk")
+	title := `Go & "Concurrency": A Deep Dive`
+	result, err := Create(Options{Dir: dir, Title: title})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+
+	cfg, err := config.Load(result.DeckPath, nil, config.Overrides{})
+	if err != nil {
+		t.Fatalf("config.Load() error = %v", err)
+	}
+	if cfg.Title != title {
+		t.Errorf("Title = %q, want %q", cfg.Title, title)
+	}
+}
+
+func TestCreate_NonEmptyDir(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(existing, []byte("keep me"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	_, err := Create(Options{Dir: dir})
+	if !errors.Is(err, ErrDirNotEmpty) {
+		t.Fatalf("Create() error = %v, want ErrDirNotEmpty", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, DeckFile)); !os.IsNotExist(err) {
+		t.Error("deck.md should not be written to a non-empty directory")
+	}
+
+	if _, err := Create(Options{Dir: dir, Force: true}); err != nil {
+		t.Fatalf("Create() with Force error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, DeckFile)); err != nil {
+		t.Errorf("deck.md should be written with Force: %v", err)
+	}
+	if content, _ := os.ReadFile(existing); string(content) != "keep me" {
+		t.Error("existing files should be left alone")
+	}
+}
+
+func TestCreate_EmptyExistingDir(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Create(Options{Dir: dir}); err != nil {
+		t.Errorf("Create() in an empty directory error = %v", err)
+	}
+}
+
+func TestCreate_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"unknown template", Options{Template: "slideshow"}, `unknown template "slideshow"`},
+		{"unknown theme", Options{Theme: "sparkles"}, `unknown theme "sparkles"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Dir = filepath.Join(t.TempDir(), "talk")
+			_, err := Create(tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Create() error = %v, want %q", err, tt.want)
+			}
+			if _, err := os.Stat(tt.opts.Dir); !os.IsNotExist(err) {
+				t.Error("directory should not be created on error")
+			}
+		})
+	}
+
+	file := filepath.Join(t.TempDir(), "deck.md")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := Create(Options{Dir: file, Force: true}); err == nil {
+		t.Error("Create() should fail when the path is a file")
+	}
+}
+
+func TestTitleFromDir(t *testing.T) {
+	tests := map[string]string{
+		"my-talk":              "My Talk",
+		"talks/go_concurrency": "Go Concurrency",
+		"kubecon.2026/":        "Kubecon 2026",
+		"---":                  "My Presentation",
+	}
+	for dir, want := range tests {
+		if got := TitleFromDir(dir); got != want {
+			t.Errorf("TitleFromDir(%q) = %q, want %q", dir, got, want)
+		}
+	}
+}
+
+func TestTemplatesAreEmbedded(t *testing.T) {
+	for _, tmpl := range Templates {
+		if tmpl.Description == "" {
+			t.Errorf("template %q has no description", tmpl.Name)
+		}
+		if _, err := templateFS.ReadFile("templates/" + tmpl.Name + ".md"); err != nil {
+			t.Errorf("template %q is not embedded: %v", tmpl.Name, err)
+		}
+	}
+}
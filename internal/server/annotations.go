@@ -0,0 +1,190 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// annotationInterval is the shortest time between two annotation messages
+// relayed to the audience, which limits them to about 30 a second.
+const annotationInterval = time.Second / 30
+
+// maxAnnotationStrokes is the number of pen strokes kept for the current
+// slide. Strokes drawn past it are still relayed, but not replayed.
+const maxAnnotationStrokes = 500
+
+// StrokeToolLaser is the tool of laser pointer strokes, which fade out in the
+// audience view and are not replayed to clients that connect later.
+const StrokeToolLaser = "laser"
+
+// Stroke is a line drawn on a slide in the presenter view. The presenter view
+// sends the whole stroke so far while it is drawn, so a newer version of a
+// stroke replaces the older one.
+type Stroke struct {
+	ID    string  `json:"id"`
+	Tool  string  `json:"tool,omitempty"` // "pen" (the default) or "laser"
+	Color string  `json:"color,omitempty"`
+	Width float64 `json:"width,omitempty"`
+	// Points are x, y pairs relative to the slide's size, from 0 to 1
+	Points [][2]float64 `json:"points"`
+}
+
+// Annotations are strokes drawn on a slide.
+type Annotations struct {
+	SlideIndex int      `json:"slideIndex"`
+	Strokes    []Stroke `json:"strokes"`
+}
+
+// annotationState is the hub's state of the annotations on the current slide.
+// It is guarded by the hub's mutex.
+type annotationState struct {
+	slide      int
+	strokes    []Stroke    // Pen strokes on slide, replayed to clients that connect
+	pending    []Stroke    // Strokes received since the last relay
+	relay      *time.Timer // Relays the pending strokes; nil if none is scheduled
+	lastRelay  time.Time
+	lastStroke time.Time // When the presenter view last sent a stroke
+}
+
+// reset drops the strokes and any relay that is scheduled for them.
+func (s *annotationState) reset(slide int) {
+	if s.relay != nil {
+		s.relay.Stop()
+		s.relay = nil
+	}
+	s.slide = slide
+	s.strokes = nil
+	s.pending = nil
+}
+
+// upsertStroke replaces the stroke with the same ID in strokes, or appends it.
+func upsertStroke(strokes []Stroke, stroke Stroke) []Stroke {
+	for i := range strokes {
+		if strokes[i].ID == stroke.ID {
+			strokes[i] = stroke
+			return strokes
+		}
+	}
+	return append(strokes, stroke)
+}
+
+// annotate keeps the strokes of an annotation message from a presenter view
+// and relays them to the audience. Strokes received less than
+// annotationInterval after the last relay are coalesced into the next one.
+func (h *WebSocketHub) annotate(a Annotations) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s := &h.annotations
+	if a.SlideIndex != s.slide {
+		s.reset(a.SlideIndex)
+	}
+	for _, stroke := range a.Strokes {
+		if stroke.ID == "" {
+			continue
+		}
+		if stroke.Tool != StrokeToolLaser && (len(s.strokes) < maxAnnotationStrokes || containsStroke(s.strokes, stroke.ID)) {
+			s.strokes = upsertStroke(s.strokes, stroke)
+		}
+		s.pending = upsertStroke(s.pending, stroke)
+	}
+	s.lastStroke = time.Now()
+
+	if s.relay != nil {
+		return // The pending strokes go out with the scheduled relay
+	}
+	if wait := annotationInterval - time.Since(s.lastRelay); wait > 0 {
+		s.relay = time.AfterFunc(wait, h.flushAnnotations)
+		return
+	}
+	h.relayAnnotations()
+}
+
+// containsStroke reports whether strokes has a stroke with the given ID.
+func containsStroke(strokes []Stroke, id string) bool {
+	for _, stroke := range strokes {
+		if stroke.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// flushAnnotations relays the strokes coalesced since the last relay.
+func (h *WebSocketHub) flushAnnotations() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.annotations.relay = nil
+	h.relayAnnotations()
+}
+
+// relayAnnotations sends the pending strokes to the audience.
+// Must be called with the lock held.
+func (h *WebSocketHub) relayAnnotations() {
+	s := &h.annotations
+	if len(s.pending) == 0 {
+		return
+	}
+	data, err := json.Marshal(Message{
+		Type:        MessageAnnotate,
+		Annotations: &Annotations{SlideIndex: s.slide, Strokes: s.pending},
+	})
+	s.pending = nil
+	s.lastRelay = time.Now()
+	if err != nil {
+		return
+	}
+	h.sendToAudience(data)
+}
+
+// clearAnnotations drops the strokes on the current slide and tells the
+// audience to remove them.
+func (h *WebSocketHub) clearAnnotations() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.annotations.reset(h.annotations.slide)
+
+	data, _ := json.Marshal(Message{Type: MessageClearAnnotations})
+	h.sendToAudience(data)
+}
+
+// sendToAudience queues a message for the audience clients, skipping those
+// whose buffer is full. Must be called with the lock held.
+func (h *WebSocketHub) sendToAudience(data []byte) {
+	for client := range h.clients {
+		if client.presenter || client.remote != 0 {
+			continue
+		}
+		select {
+		case client.send <- data:
+		default:
+		}
+	}
+}
+
+// annotationReplay returns the message that replays the pen strokes on the
+// current slide to a client that connects, and false if there are none.
+func (h *WebSocketHub) annotationReplay() ([]byte, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	s := &h.annotations
+	if len(s.strokes) == 0 {
+		return nil, false
+	}
+	data, err := json.Marshal(Message{
+		Type:        MessageAnnotate,
+		Annotations: &Annotations{SlideIndex: s.slide, Strokes: s.strokes},
+	})
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// LastAnnotation returns when a presenter view last sent a stroke, or the
+// zero time if none did.
+func (h *WebSocketHub) LastAnnotation() time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.annotations.lastStroke
+}
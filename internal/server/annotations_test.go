@@ -0,0 +1,211 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// newAnnotationTestHub returns a running hub with an audience client, a
+// presenter client, and a remote client, which aren't connected to sockets.
+func newAnnotationTestHub(t *testing.T) (hub *WebSocketHub, audience, presenter, remote *Client) {
+	t.Helper()
+	hub = NewWebSocketHub()
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+
+	audience = &Client{hub: hub, send: make(chan []byte, 256)}
+	presenter = &Client{hub: hub, send: make(chan []byte, 256), presenter: true}
+	remote = &Client{hub: hub, send: make(chan []byte, 256), remote: 1}
+	for _, client := range []*Client{audience, presenter, remote} {
+		hub.register <- client
+	}
+	for hub.ClientCount() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	return hub, audience, presenter, remote
+}
+
+// receive returns the next message queued for client, or fails the test if
+// none arrives in time.
+func receive(t *testing.T, client *Client) Message {
+	t.Helper()
+	select {
+	case data := <-client.send:
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("no message received")
+		return Message{}
+	}
+}
+
+// expectNoMessage fails the test if a message is queued for client within
+// a few relay intervals.
+func expectNoMessage(t *testing.T, client *Client) {
+	t.Helper()
+	select {
+	case data := <-client.send:
+		t.Errorf("unexpected message %s", data)
+	case <-time.After(4 * annotationInterval):
+	}
+}
+
+// annotateMessage returns an annotate message with one stroke.
+func annotateMessage(slide int, id string, points ...[2]float64) Message {
+	return Message{
+		Type: MessageAnnotate,
+		Annotations: &Annotations{
+			SlideIndex: slide,
+			Strokes:    []Stroke{{ID: id, Color: "#ff0000", Width: 4, Points: points}},
+		},
+	}
+}
+
+func TestAnnotationsRelayedToAudience(t *testing.T) {
+	hub, audience, presenter, remote := newAnnotationTestHub(t)
+
+	presenter.handleMessage(annotateMessage(2, "s1", [2]float64{0.1, 0.2}))
+
+	msg := receive(t, audience)
+	if msg.Type != MessageAnnotate || msg.Annotations == nil {
+		t.Fatalf("audience received %+v, want an annotate message", msg)
+	}
+	if msg.Annotations.SlideIndex != 2 || len(msg.Annotations.Strokes) != 1 || msg.Annotations.Strokes[0].ID != "s1" {
+		t.Errorf("annotations = %+v, want stroke s1 on slide 2", msg.Annotations)
+	}
+
+	// Presenter views and remotes don't get the strokes back
+	expectNoMessage(t, presenter)
+	expectNoMessage(t, remote)
+
+	if hub.LastAnnotation().IsZero() {
+		t.Error("LastAnnotation() is zero after a stroke")
+	}
+}
+
+func TestAnnotationsFromAudienceRejected(t *testing.T) {
+	hub, audience, _, remote := newAnnotationTestHub(t)
+
+	for _, client := range []*Client{audience, remote} {
+		client.handleMessage(annotateMessage(0, "s1", [2]float64{0.5, 0.5}))
+		client.handleMessage(Message{Type: MessageClearAnnotations})
+	}
+
+	expectNoMessage(t, audience)
+	if _, ok := hub.annotationReplay(); ok {
+		t.Error("strokes from audience or remote clients were kept")
+	}
+	if !hub.LastAnnotation().IsZero() {
+		t.Error("LastAnnotation() is set by strokes from audience or remote clients")
+	}
+}
+
+func TestAnnotationsCoalesced(t *testing.T) {
+	_, audience, presenter, _ := newAnnotationTestHub(t)
+
+	// The first stroke is relayed at once, and the updates that follow
+	// within the interval are coalesced into their latest version
+	points := [][2]float64{{0, 0}}
+	presenter.handleMessage(annotateMessage(0, "s1", points...))
+	for i := 1; i <= 10; i++ {
+		points = append(points, [2]float64{float64(i) / 10, float64(i) / 10})
+		presenter.handleMessage(annotateMessage(0, "s1", points...))
+	}
+	presenter.handleMessage(annotateMessage(0, "s2", [2]float64{0.5, 0.5}))
+
+	first := receive(t, audience)
+	if got := len(first.Annotations.Strokes[0].Points); got != 1 {
+		t.Errorf("first relay has %d points, want 1", got)
+	}
+
+	second := receive(t, audience)
+	strokes := second.Annotations.Strokes
+	if len(strokes) != 2 || strokes[0].ID != "s1" || strokes[1].ID != "s2" {
+		t.Fatalf("second relay = %+v, want strokes s1 and s2", strokes)
+	}
+	if got := len(strokes[0].Points); got != 11 {
+		t.Errorf("coalesced stroke has %d points, want the latest 11", got)
+	}
+
+	expectNoMessage(t, audience)
+}
+
+func TestAnnotationsReplayedToLateJoiners(t *testing.T) {
+	hub, _, presenter, _ := newAnnotationTestHub(t)
+
+	presenter.handleMessage(annotateMessage(1, "s1", [2]float64{0.1, 0.1}))
+	presenter.handleMessage(annotateMessage(1, "s2", [2]float64{0.2, 0.2}))
+	laser := annotateMessage(1, "laser", [2]float64{0.3, 0.3})
+	laser.Annotations.Strokes[0].Tool = StrokeToolLaser
+	presenter.handleMessage(laser)
+
+	data, ok := hub.annotationReplay()
+	if !ok {
+		t.Fatal("annotationReplay() has no strokes")
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if msg.Type != MessageAnnotate || msg.Annotations.SlideIndex != 1 {
+		t.Fatalf("replay = %+v, want an annotate message for slide 1", msg)
+	}
+	// Laser pointer strokes fade, so they aren't replayed
+	if strokes := msg.Annotations.Strokes; len(strokes) != 2 || strokes[0].ID != "s1" || strokes[1].ID != "s2" {
+		t.Errorf("replayed strokes = %+v, want s1 and s2", strokes)
+	}
+}
+
+func TestAnnotationsClear(t *testing.T) {
+	hub, audience, presenter, _ := newAnnotationTestHub(t)
+
+	presenter.handleMessage(annotateMessage(0, "s1", [2]float64{0.1, 0.1}))
+	receive(t, audience)
+
+	presenter.handleMessage(Message{Type: MessageClearAnnotations})
+	if msg := receive(t, audience); msg.Type != MessageClearAnnotations {
+		t.Errorf("audience received %s, want %s", msg.Type, MessageClearAnnotations)
+	}
+	if _, ok := hub.annotationReplay(); ok {
+		t.Error("strokes remain after a clear message")
+	}
+}
+
+func TestAnnotationsClearedOnSlideChange(t *testing.T) {
+	hub, _, presenter, _ := newAnnotationTestHub(t)
+
+	presenter.handleMessage(annotateMessage(0, "s1", [2]float64{0.1, 0.1}))
+
+	// Revealing a fragment keeps the slide, and its strokes
+	if err := hub.BroadcastSlide(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hub.annotationReplay(); !ok {
+		t.Fatal("strokes were dropped without a slide change")
+	}
+
+	if err := hub.BroadcastSlide(1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := hub.annotationReplay(); ok {
+		t.Error("strokes remain after moving to another slide")
+	}
+}
+
+func TestStatusLastAnnotation(t *testing.T) {
+	s := New(0)
+	hub, _, presenter, _ := newAnnotationTestHub(t)
+	s.SetWebSocketHub(hub)
+
+	if status := s.Status(); status.LastAnnotation != nil {
+		t.Errorf("LastAnnotation = %v before any stroke, want nil", status.LastAnnotation)
+	}
+	presenter.handleMessage(annotateMessage(0, "s1", [2]float64{0.1, 0.1}))
+	if status := s.Status(); status.LastAnnotation == nil || time.Since(*status.LastAnnotation) > time.Minute {
+		t.Errorf("LastAnnotation = %v after a stroke, want about now", status.LastAnnotation)
+	}
+}
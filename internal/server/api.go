@@ -5,115 +5,202 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/MiniCodeMonkey/tap/internal/driver"
 )
 
-// ExecuteRequest represents a request to execute code via a driver.
-type ExecuteRequest struct {
-	Driver     string `json:"driver"`
-	Code       string `json:"code"`
-	Connection string `json:"connection,omitempty"`
+// RunRequest represents a request to run a code block of the current presentation.
+type RunRequest struct {
+	Slide int `json:"slide"` // 0-based slide index
+	Block int `json:"block"` // 0-based code block index within the slide
 }
 
 // ExecuteResponse represents the response from code execution.
 type ExecuteResponse struct {
-	Output  string                   `json:"output,omitempty"`
-	Error   string                   `json:"error,omitempty"`
-	Data    []map[string]interface{} `json:"data,omitempty"`
-	Success bool                     `json:"success"`
+	Output    string                   `json:"output,omitempty"`
+	Error     string                   `json:"error,omitempty"`
+	Data      []map[string]interface{} `json:"data,omitempty"`
+	Success   bool                     `json:"success"`
+	Truncated bool                     `json:"truncated,omitempty"`
 }
 
 // DefaultExecuteTimeout is the default timeout for code execution.
-const DefaultExecuteTimeout = 30 * time.Second
-
-// handleAPIExecute handles POST /api/execute requests to execute code via a driver.
-func (s *Server) handleAPIExecute(w http.ResponseWriter, r *http.Request) {
-	// Only allow POST method
-	if r.Method != http.MethodPost {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		_ = json.NewEncoder(w).Encode(ExecuteResponse{
+const DefaultExecuteTimeout = 10 * time.Second
+
+// Limits on the size of execution results sent to the browser.
+const (
+	MaxOutputSize = 64 * 1024 // bytes of output
+	MaxResultRows = 1000      // rows of structured data
+)
+
+// execDisabledMessage is returned when code execution was not enabled.
+const execDisabledMessage = "code execution is disabled; restart tap dev with --allow-exec to enable it"
+
+// handleAPIRun handles POST /api/run requests to run a code block of the
+// current presentation. The code is taken from the presentation rather than
+// the request, and only same-origin JSON requests are accepted, so other web
+// pages can't make the dev server run code.
+func (s *Server) handleAPIRun(w http.ResponseWriter, r *http.Request) {
+	if !s.GetAllowExec() {
+		writeExecuteResponse(w, http.StatusForbidden, ExecuteResponse{
+			Success: false,
+			Error:   execDisabledMessage,
+		})
+		return
+	}
+
+	if !IsSameOriginJSON(r) {
+		writeExecuteResponse(w, http.StatusForbidden, ExecuteResponse{
 			Success: false,
-			Error:   "Method not allowed",
+			Error:   "code can only be run by the presentation, with a same-origin JSON request",
 		})
 		return
 	}
 
-	// Parse request body
-	var req ExecuteRequest
+	var req RunRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(ExecuteResponse{
+		writeExecuteResponse(w, http.StatusBadRequest, ExecuteResponse{
 			Success: false,
 			Error:   fmt.Sprintf("Invalid request body: %v", err),
 		})
 		return
 	}
 
-	// Validate required fields
-	if req.Driver == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(ExecuteResponse{
+	pres := s.GetPresentation()
+	if pres == nil {
+		writeExecuteResponse(w, http.StatusNotFound, ExecuteResponse{
+			Success: false,
+			Error:   "No presentation loaded",
+		})
+		return
+	}
+
+	if req.Slide < 0 || req.Slide >= len(pres.Slides) {
+		writeExecuteResponse(w, http.StatusBadRequest, ExecuteResponse{
 			Success: false,
-			Error:   "driver field is required",
+			Error:   fmt.Sprintf("slide %d not found", req.Slide),
+		})
+		return
+	}
+	blocks := pres.Slides[req.Slide].CodeBlocks
+	if req.Block < 0 || req.Block >= len(blocks) {
+		writeExecuteResponse(w, http.StatusBadRequest, ExecuteResponse{
+			Success: false,
+			Error:   fmt.Sprintf("code block %d not found on slide %d", req.Block, req.Slide),
 		})
 		return
 	}
 
-	// Check if registry is set
-	if s.registry == nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		_ = json.NewEncoder(w).Encode(ExecuteResponse{
+	block := blocks[req.Block]
+	if block.Driver == "" {
+		writeExecuteResponse(w, http.StatusBadRequest, ExecuteResponse{
+			Success: false,
+			Error:   "code block has no driver",
+		})
+		return
+	}
+
+	s.executeCode(w, r, block.Driver, block.Connection, block.Code)
+}
+
+// IsSameOriginJSON reports whether a request has a JSON body and was sent by
+// a page of the dev server, or by a client that isn't a browser. Requiring
+// application/json makes browsers send a CORS preflight for cross-origin
+// requests, which the dev server doesn't answer, and the Origin and
+// Sec-Fetch-Site headers catch requests that skip it. Endpoints that run code
+// or write files check it, so other web pages can't use them.
+func IsSameOriginJSON(r *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return false
+	}
+	if site := r.Header.Get("Sec-Fetch-Site"); site != "" && site != "same-origin" {
+		return false
+	}
+	if origin := r.Header.Get("Origin"); origin != "" {
+		u, err := url.Parse(origin)
+		if err != nil || u.Host != r.Host {
+			return false
+		}
+	}
+	return true
+}
+
+// executeCode runs code with the named driver and writes the result, limited
+// to MaxOutputSize and MaxResultRows, as the response.
+func (s *Server) executeCode(w http.ResponseWriter, r *http.Request, driverName, connectionName, code string) {
+	registry := s.GetRegistry()
+	if registry == nil {
+		writeExecuteResponse(w, http.StatusInternalServerError, ExecuteResponse{
 			Success: false,
 			Error:   "Driver registry not configured",
 		})
 		return
 	}
 
-	// Check if driver exists
-	if !s.registry.Has(req.Driver) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		_ = json.NewEncoder(w).Encode(ExecuteResponse{
+	if !registry.Has(driverName) {
+		writeExecuteResponse(w, http.StatusBadRequest, ExecuteResponse{
 			Success: false,
-			Error:   fmt.Sprintf("driver not found: %s", req.Driver),
+			Error:   fmt.Sprintf("driver not found: %s", driverName),
 		})
 		return
 	}
 
 	// Build config from connection
-	config := s.buildExecutionConfig(req.Driver, req.Connection)
+	config := s.buildExecutionConfig(driverName, connectionName)
 
 	// Create context with timeout
-	timeout := s.getExecutionTimeout(req.Driver)
-	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	ctx, cancel := context.WithTimeout(r.Context(), s.getExecutionTimeout(driverName))
 	defer cancel()
 
-	// Execute code
-	result := s.registry.Execute(ctx, req.Driver, req.Code, config)
+	result := registry.Execute(ctx, driverName, code, config)
+	response := limitResult(result)
 
-	// Determine HTTP status based on result
-	w.Header().Set("Content-Type", "application/json")
-	if result.Success {
-		w.WriteHeader(http.StatusOK)
-	} else {
-		w.WriteHeader(http.StatusInternalServerError)
+	status := http.StatusOK
+	if !result.Success {
+		status = http.StatusInternalServerError
 	}
+	writeExecuteResponse(w, status, response)
+}
 
-	// Return response
-	_ = json.NewEncoder(w).Encode(ExecuteResponse{
+// limitResult converts a driver result to a response, truncating output
+// beyond MaxOutputSize bytes and data beyond MaxResultRows rows.
+func limitResult(result driver.Result) ExecuteResponse {
+	response := ExecuteResponse{
 		Success: result.Success,
 		Output:  result.Output,
 		Error:   result.Error,
 		Data:    result.Data,
-	})
+	}
+
+	if len(response.Output) > MaxOutputSize {
+		output := response.Output[:MaxOutputSize]
+		// Don't cut a multi-byte character in half
+		for len(output) > 0 && !utf8.ValidString(output) {
+			output = output[:len(output)-1]
+		}
+		response.Output = output
+		response.Truncated = true
+	}
+	if len(response.Data) > MaxResultRows {
+		response.Data = response.Data[:MaxResultRows]
+		response.Truncated = true
+	}
+
+	return response
+}
+
+// writeExecuteResponse writes an execution response as JSON with the given status.
+func writeExecuteResponse(w http.ResponseWriter, status int, response ExecuteResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
 }
 
 // buildExecutionConfig builds the config map for driver execution
@@ -127,15 +214,9 @@ func (s *Server) buildExecutionConfig(driverName, connectionName string) map[str
 		return config
 	}
 
-	// Look up driver config
-	driverConfig, exists := pres.Config.Drivers[driverName]
-	if !exists {
-		return config
-	}
-
-	// Look up connection config if specified
+	// Look up connection config if specified, under the driver or in the connections section
 	if connectionName != "" {
-		if connConfig, exists := driverConfig.Connections[connectionName]; exists {
+		if connConfig, err := pres.Config.DriverConnection(driverName, connectionName); err == nil {
 			// Map connection config fields to driver config keys
 			if connConfig.Host != "" {
 				config["host"] = connConfig.Host
@@ -159,7 +240,7 @@ func (s *Server) buildExecutionConfig(driverName, connectionName string) map[str
 	}
 
 	// Add timeout from driver config if specified
-	if driverConfig.Timeout > 0 {
+	if driverConfig := pres.Config.Drivers[driverName]; driverConfig.Timeout > 0 {
 		config["timeout"] = strconv.Itoa(driverConfig.Timeout)
 	}
 
@@ -184,13 +265,28 @@ func (s *Server) getExecutionTimeout(driverName string) time.Duration {
 }
 
 // SetRegistry sets the driver registry for the server.
-// This must be called before SetupRoutes() if you want the execute endpoint to work.
+// This must be called before SetupRoutes() if you want the run endpoint to work.
 func (s *Server) SetRegistry(registry *driver.Registry) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.registry = registry
 }
 
+// SetAllowExec sets whether the run endpoint may run code.
+// Execution is disabled by default because it runs arbitrary commands.
+func (s *Server) SetAllowExec(allow bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.allowExec = allow
+}
+
+// GetAllowExec returns whether the run endpoint may run code.
+func (s *Server) GetAllowExec() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.allowExec
+}
+
 // GetRegistry returns the driver registry.
 func (s *Server) GetRegistry() *driver.Registry {
 	s.mu.RLock()
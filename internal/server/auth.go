@@ -0,0 +1,305 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// audienceCookieName is the cookie set by the login page once the audience
+// password has been entered.
+const audienceCookieName = "tap_audience"
+
+// GeneratePresenterToken returns a new random presenter token, suitable for
+// use as the presenter password.
+func GeneratePresenterToken() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate presenter token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// SetAudiencePassword sets the password required to view the presentation.
+// An empty password leaves the audience view open to anyone.
+func (s *Server) SetAudiencePassword(password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audiencePassword = password
+}
+
+// GetAudiencePassword returns the audience password.
+func (s *Server) GetAudiencePassword() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.audiencePassword
+}
+
+// SetWebSocketHub sets the hub that handles WebSocket connections on /ws.
+// Presenter views connect with ?role=presenter and the presenter token, so
+// they can be disconnected when the token changes. Paired remotes connect
+// with ?role=remote and their token.
+// This should be called before Start().
+func (s *Server) SetWebSocketHub(hub *WebSocketHub) {
+	s.mu.Lock()
+	s.hub = hub
+	if s.presentation != nil {
+		hub.SetSlideCount(len(s.presentation.Slides))
+	}
+	s.mu.Unlock()
+	s.mux.HandleFunc("GET /ws", s.handleWebSocket)
+}
+
+// RotatePresenterToken replaces the presenter password with a new random
+// token and disconnects the presenter views that connected with the old one.
+// It returns the new token.
+func (s *Server) RotatePresenterToken() (string, error) {
+	token, err := GeneratePresenterToken()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.presenterPassword = token
+	hub := s.hub
+	s.mu.Unlock()
+
+	if hub != nil {
+		hub.DisconnectPresenters()
+	}
+	return token, nil
+}
+
+// PresenterTokenHeader is the header that carries the presenter token for
+// requests that can't put it in the URL, such as the PDF exporter's.
+const PresenterTokenHeader = "X-Presenter-Token"
+
+// presenterToken returns the presenter token given in a request, either as the
+// ?token= query parameter, the older ?key= parameter, a path secret in
+// /presenter/<token>, or the X-Presenter-Token header.
+func presenterToken(r *http.Request) string {
+	query := r.URL.Query()
+	if token := query.Get("token"); token != "" {
+		return token
+	}
+	if key := query.Get("key"); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.URL.Path, "/presenter/"); ok {
+		return strings.Trim(token, "/")
+	}
+	return r.Header.Get(PresenterTokenHeader)
+}
+
+// validPresenterToken reports whether token matches the presenter password.
+// It is always false when no presenter password is set.
+func (s *Server) validPresenterToken(token string) bool {
+	password := s.GetPresenterPassword()
+	if password == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(password)) == 1
+}
+
+// PresenterAuthorized reports whether a request may take presenter actions:
+// when no presenter password is set, or with a valid presenter token.
+func (s *Server) PresenterAuthorized(r *http.Request) bool {
+	return s.GetPresenterPassword() == "" || s.validPresenterToken(presenterToken(r))
+}
+
+// audienceCookieValue returns the value of the audience cookie for password.
+// It is an HMAC with a secret generated when the server starts, so the cookie
+// doesn't reveal the password and stops working when the password changes.
+func (s *Server) audienceCookieValue(password string) string {
+	mac := hmac.New(sha256.New, s.authSecret)
+	mac.Write([]byte(password))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// audienceAuthorized reports whether a request may view the presentation:
+// when no audience password is set, with the audience cookie, with the
+// password as HTTP basic auth, or with a valid presenter token.
+func (s *Server) audienceAuthorized(r *http.Request) bool {
+	password := s.GetAudiencePassword()
+	if password == "" {
+		return true
+	}
+
+	if cookie, err := r.Cookie(audienceCookieName); err == nil {
+		if hmac.Equal([]byte(cookie.Value), []byte(s.audienceCookieValue(password))) {
+			return true
+		}
+	}
+	if _, given, ok := r.BasicAuth(); ok {
+		if subtle.ConstantTimeCompare([]byte(given), []byte(password)) == 1 {
+			return true
+		}
+	}
+	return s.validPresenterToken(presenterToken(r))
+}
+
+// setAudienceCookie sets the cookie that keeps a browser logged in to the
+// audience view.
+func (s *Server) setAudienceCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     audienceCookieName,
+		Value:    s.audienceCookieValue(s.GetAudiencePassword()),
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// requireAudienceAuth wraps a handler so that, when an audience password is
+// set, requests must be authorized. Page loads are redirected to the login
+// page; other requests get 401 Unauthorized. Presenter requests with a valid
+// token also get the audience cookie, so the presenter view's own requests
+// are authorized.
+func (s *Server) requireAudienceAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.GetAudiencePassword() == "" || r.URL.Path == "/login" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !s.audienceAuthorized(r) {
+			if r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/html") {
+				http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+				return
+			}
+			http.Error(w, "Unauthorized: audience password required", http.StatusUnauthorized)
+			return
+		}
+
+		if s.validPresenterToken(presenterToken(r)) {
+			s.setAudienceCookie(w)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// loginTemplate is the page that asks for the audience password.
+var loginTemplate = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+    <title>Tap - Password Required</title>
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            display: flex;
+            align-items: center;
+            justify-content: center;
+            min-height: 100vh;
+            margin: 0;
+            background: #1a1a2e;
+            color: #eee;
+        }
+        form {
+            display: flex;
+            flex-direction: column;
+            gap: 1rem;
+            width: 280px;
+        }
+        input, button {
+            padding: 0.6rem 0.8rem;
+            border-radius: 6px;
+            border: 1px solid #444;
+            font-size: 1rem;
+        }
+        button {
+            background: #7c3aed;
+            color: #fff;
+            border: none;
+            cursor: pointer;
+        }
+        .error { color: #f87171; }
+    </style>
+</head>
+<body>
+    <form method="post" action="/login">
+        <h1>Password required</h1>
+        {{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+        <input type="hidden" name="next" value="{{.Next}}">
+        <input type="password" name="password" placeholder="Password" autofocus required>
+        <button type="submit">View presentation</button>
+    </form>
+</body>
+</html>
+`))
+
+// handleLogin serves the audience login page and checks the submitted
+// password, setting the audience cookie and redirecting back on success.
+func (s *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	next := r.FormValue("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		// Only redirect within this server
+		next = "/"
+	}
+
+	password := s.GetAudiencePassword()
+	if password == "" {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+
+	status := http.StatusOK
+	data := struct {
+		Error string
+		Next  string
+	}{Next: next}
+
+	if r.Method == http.MethodPost {
+		given := r.PostFormValue("password")
+		if subtle.ConstantTimeCompare([]byte(given), []byte(password)) == 1 {
+			s.setAudienceCookie(w)
+			http.Redirect(w, r, next, http.StatusSeeOther)
+			return
+		}
+		status = http.StatusUnauthorized
+		data.Error = "Incorrect password"
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = loginTemplate.Execute(w, data)
+}
+
+// handleWebSocket accepts WebSocket connections for the hub. Connections with
+// ?role=presenter must carry the presenter token when one is set, and
+// connections with ?role=remote the token of a paired remote.
+func (s *Server) handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	hub := s.hub
+	s.mu.RUnlock()
+
+	switch r.URL.Query().Get("role") {
+	case "presenter":
+	case "remote":
+		id := s.pairedRemote(r.URL.Query().Get("remote"))
+		if id == 0 {
+			http.Error(w, "Forbidden: remote is not paired", http.StatusForbidden)
+			return
+		}
+		hub.HandleRemoteConnection(w, r, id)
+		return
+	default:
+		hub.HandleConnection(w, r)
+		return
+	}
+
+	if s.GetPresenterPassword() != "" && !s.validPresenterToken(presenterToken(r)) {
+		http.Error(w, "Forbidden: incorrect presenter password", http.StatusForbidden)
+		return
+	}
+	hub.HandlePresenterConnection(w, r)
+}
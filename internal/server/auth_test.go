@@ -0,0 +1,259 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+func TestRequireAudienceAuth(t *testing.T) {
+	s := New(0)
+	s.SetPresenterPassword("presenter-token")
+	s.SetupRoutes()
+	handler := s.requireAudienceAuth(s.mux)
+
+	loginCookie := &http.Cookie{Name: audienceCookieName, Value: s.audienceCookieValue("audience")}
+
+	tests := []struct {
+		name       string
+		password   string
+		target     string
+		accept     string
+		basicAuth  string
+		cookie     *http.Cookie
+		wantStatus int
+		wantCookie bool
+	}{
+		{name: "no audience password", target: "/api/presentation", wantStatus: http.StatusNotFound},
+		{name: "page load redirects to login", password: "audience", target: "/", accept: "text/html", wantStatus: http.StatusSeeOther},
+		{name: "api request is unauthorized", password: "audience", target: "/api/presentation", wantStatus: http.StatusUnauthorized},
+		{name: "login page is open", password: "audience", target: "/login", accept: "text/html", wantStatus: http.StatusOK},
+		{name: "basic auth", password: "audience", target: "/api/presentation", basicAuth: "audience", wantStatus: http.StatusNotFound},
+		{name: "wrong basic auth", password: "audience", target: "/api/presentation", basicAuth: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "login cookie", password: "audience", target: "/api/presentation", cookie: loginCookie, wantStatus: http.StatusNotFound},
+		{name: "cookie for another password", password: "changed", target: "/api/presentation", cookie: loginCookie, wantStatus: http.StatusUnauthorized},
+		{name: "presenter token query", password: "audience", target: "/presenter?token=presenter-token", wantStatus: http.StatusOK, wantCookie: true},
+		{name: "presenter token path", password: "audience", target: "/presenter/presenter-token", wantStatus: http.StatusOK, wantCookie: true},
+		{name: "wrong presenter token", password: "audience", target: "/presenter?token=wrong", wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s.SetAudiencePassword(tt.password)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			if tt.basicAuth != "" {
+				req.SetBasicAuth("", tt.basicAuth)
+			}
+			if tt.cookie != nil {
+				req.AddCookie(tt.cookie)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if resp.StatusCode == http.StatusSeeOther {
+				if location := resp.Header.Get("Location"); location != "/login?next=%2F" {
+					t.Errorf("Location = %q, want /login?next=%%2F", location)
+				}
+			}
+			if gotCookie := len(resp.Cookies()) > 0; gotCookie != tt.wantCookie {
+				t.Errorf("cookie set = %v, want %v", gotCookie, tt.wantCookie)
+			}
+		})
+	}
+}
+
+func TestHandleLogin(t *testing.T) {
+	s := New(0)
+	s.SetAudiencePassword("audience")
+
+	tests := []struct {
+		name         string
+		password     string
+		next         string
+		wantStatus   int
+		wantLocation string
+	}{
+		{name: "correct password", password: "audience", next: "/presenter", wantStatus: http.StatusSeeOther, wantLocation: "/presenter"},
+		{name: "wrong password", password: "nope", next: "/", wantStatus: http.StatusUnauthorized},
+		{name: "external next", password: "audience", next: "//example.com", wantStatus: http.StatusSeeOther, wantLocation: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			form := url.Values{"password": {tt.password}, "next": {tt.next}}
+			req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+
+			s.handleLogin(w, req)
+
+			resp := w.Result()
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantLocation == "" {
+				if len(resp.Cookies()) != 0 {
+					t.Error("expected no cookie for a wrong password")
+				}
+				return
+			}
+			if location := resp.Header.Get("Location"); location != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", location, tt.wantLocation)
+			}
+
+			cookies := resp.Cookies()
+			if len(cookies) != 1 || cookies[0].Name != audienceCookieName {
+				t.Fatalf("expected audience cookie, got %v", cookies)
+			}
+			if cookies[0].Value == "audience" {
+				t.Error("cookie must not contain the password")
+			}
+		})
+	}
+}
+
+func TestGeneratePresenterToken(t *testing.T) {
+	first, err := GeneratePresenterToken()
+	if err != nil {
+		t.Fatalf("GeneratePresenterToken() error = %v", err)
+	}
+	second, err := GeneratePresenterToken()
+	if err != nil {
+		t.Fatalf("GeneratePresenterToken() error = %v", err)
+	}
+
+	if len(first) != 24 {
+		t.Errorf("token length = %d, want 24", len(first))
+	}
+	if first == second {
+		t.Error("expected different tokens")
+	}
+}
+
+func TestRotatePresenterTokenDisconnectsPresenters(t *testing.T) {
+	hub := NewWebSocketHub()
+	go hub.Run()
+	defer hub.Stop()
+
+	s := New(0)
+	s.SetPresenterPassword("old-token")
+	s.SetWebSocketHub(hub)
+	server := httptest.NewServer(s.requireAudienceAuth(s.mux))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// A wrong token is rejected
+	_, resp, err := websocket.Dial(ctx, wsURL+"?role=presenter&token=wrong", nil)
+	if err == nil {
+		t.Fatal("expected dial with wrong token to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for wrong token, got %v", resp)
+	}
+
+	presenter, _, err := websocket.Dial(ctx, wsURL+"?role=presenter&token=old-token", nil)
+	if err != nil {
+		t.Fatalf("failed to connect presenter: %v", err)
+	}
+	defer presenter.Close(websocket.StatusNormalClosure, "")
+
+	audience, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect audience: %v", err)
+	}
+	defer audience.Close(websocket.StatusNormalClosure, "")
+
+	// Both get the connected message
+	for _, conn := range []*websocket.Conn{presenter, audience} {
+		if _, _, err := conn.Read(ctx); err != nil {
+			t.Fatalf("failed to read connected message: %v", err)
+		}
+	}
+
+	token, err := s.RotatePresenterToken()
+	if err != nil {
+		t.Fatalf("RotatePresenterToken() error = %v", err)
+	}
+	if token == "old-token" || s.GetPresenterPassword() != token {
+		t.Errorf("presenter password = %q, want new token %q", s.GetPresenterPassword(), token)
+	}
+
+	_, data, err := presenter.Read(ctx)
+	if err != nil {
+		t.Fatalf("failed to read revoked message: %v", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != MessageRevoked {
+		t.Errorf("expected revoked message, got %s", data)
+	}
+	if _, _, err := presenter.Read(ctx); websocket.CloseStatus(err) != websocket.StatusPolicyViolation {
+		t.Errorf("expected presenter connection closed with policy violation, got %v", err)
+	}
+
+	// The audience stays connected
+	if err := hub.BroadcastReload(); err != nil {
+		t.Fatalf("BroadcastReload() error = %v", err)
+	}
+	_, data, err = audience.Read(ctx)
+	if err != nil {
+		t.Fatalf("audience read error = %v", err)
+	}
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != MessageReload {
+		t.Errorf("expected reload message for audience, got %s", data)
+	}
+}
+
+func TestPresenterTokenHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/presenter", nil)
+	req.Header.Set(PresenterTokenHeader, "abc")
+	if got := presenterToken(req); got != "abc" {
+		t.Errorf("presenterToken() = %q, want %q", got, "abc")
+	}
+
+	// The URL takes precedence over the header
+	req = httptest.NewRequest(http.MethodGet, "/presenter?token=url", nil)
+	req.Header.Set(PresenterTokenHeader, "abc")
+	if got := presenterToken(req); got != "url" {
+		t.Errorf("presenterToken() = %q, want %q", got, "url")
+	}
+}
+
+func TestPresenterAuthorized(t *testing.T) {
+	s := New(0)
+	req := httptest.NewRequest(http.MethodPost, "/api/export", nil)
+	if !s.PresenterAuthorized(req) {
+		t.Error("expected requests to be authorized without a presenter password")
+	}
+
+	s.SetPresenterPassword("token")
+	if s.PresenterAuthorized(req) {
+		t.Error("expected requests without the token to be rejected")
+	}
+	req.Header.Set(PresenterTokenHeader, "token")
+	if !s.PresenterAuthorized(req) {
+		t.Error("expected requests with the token to be authorized")
+	}
+}
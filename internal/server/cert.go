@@ -0,0 +1,177 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Certificate lifetimes. A certificate is replaced once it expires within
+// certRenewBefore, so it doesn't run out during a talk.
+const (
+	certValidity    = 365 * 24 * time.Hour
+	certRenewBefore = 30 * 24 * time.Hour
+)
+
+// Names of the certificate and key files in the certificate directory.
+const (
+	certFileName = "dev-cert.pem"
+	keyFileName  = "dev-key.pem"
+)
+
+// Certificate is the self-signed certificate the dev server uses for HTTPS.
+type Certificate struct {
+	TLS         tls.Certificate
+	Fingerprint string // SHA-256 fingerprint of the certificate, as colon-separated hex
+	Created     bool   // Whether the certificate was generated rather than loaded
+}
+
+// DefaultCertDir returns the directory the dev server's certificate is kept
+// in, such as ~/.config/tap/certs, or "" if there is no config directory.
+func DefaultCertDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "tap", "certs")
+}
+
+// CertHosts returns the names and addresses the dev server's certificate
+// must be valid for: localhost, the loopback addresses, and the LAN addresses
+// that phones and other devices connect to.
+func CertHosts() []string {
+	hosts := []string{"localhost", "127.0.0.1", "::1"}
+	addrs, _ := LANAddresses()
+	return append(hosts, addrs...)
+}
+
+// LoadOrCreateCertificate returns the certificate in dir if it is valid for
+// all of hosts and doesn't expire soon. Otherwise a new self-signed
+// certificate, valid for hosts and the hosts of the old one, is generated
+// and saved in dir, replacing the old one. Keeping the certificate means
+// browsers only warn about it once per machine, until the LAN address
+// changes.
+func LoadOrCreateCertificate(dir string, hosts []string) (*Certificate, error) {
+	certPath := filepath.Join(dir, certFileName)
+	keyPath := filepath.Join(dir, keyFileName)
+
+	existing, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err == nil && existing.Leaf != nil {
+		missing := missingHosts(existing.Leaf, hosts)
+		if len(missing) == 0 && time.Until(existing.Leaf.NotAfter) > certRenewBefore {
+			return &Certificate{TLS: existing, Fingerprint: CertFingerprint(existing.Leaf)}, nil
+		}
+		hosts = append(certHostNames(existing.Leaf), missing...)
+	}
+
+	certPEM, keyPEM, err := generateCertificate(hosts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create certificate directory: %w", err)
+	}
+	if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+		return nil, fmt.Errorf("failed to write certificate key: %w", err)
+	}
+	if err := os.WriteFile(certPath, certPEM, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write certificate: %w", err)
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load generated certificate: %w", err)
+	}
+	return &Certificate{TLS: cert, Fingerprint: CertFingerprint(cert.Leaf), Created: true}, nil
+}
+
+// generateCertificate returns a new self-signed certificate for hosts and
+// its private key, PEM-encoded. IP addresses become IP subject alternative
+// names and other hosts DNS names.
+func generateCertificate(hosts []string, now time.Time) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate certificate key: %w", err)
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate certificate serial number: %w", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{Organization: []string{"Tap"}, CommonName: "Tap dev server"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(certValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			if !slices.ContainsFunc(template.IPAddresses, ip.Equal) {
+				template.IPAddresses = append(template.IPAddresses, ip)
+			}
+		} else if host != "" && !slices.Contains(template.DNSNames, host) {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create certificate: %w", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode certificate key: %w", err)
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// missingHosts returns the hosts that cert isn't valid for.
+func missingHosts(cert *x509.Certificate, hosts []string) []string {
+	var missing []string
+	for _, host := range hosts {
+		if cert.VerifyHostname(host) != nil {
+			missing = append(missing, host)
+		}
+	}
+	return missing
+}
+
+// certHostNames returns the DNS names and IP addresses cert is valid for.
+func certHostNames(cert *x509.Certificate) []string {
+	hosts := slices.Clone(cert.DNSNames)
+	for _, ip := range cert.IPAddresses {
+		hosts = append(hosts, ip.String())
+	}
+	return hosts
+}
+
+// CertFingerprint returns the SHA-256 fingerprint of cert as colon-separated
+// hex, the way browsers show it, so the certificate can be checked before
+// it is trusted.
+func CertFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
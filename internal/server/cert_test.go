@@ -0,0 +1,104 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestLoadOrCreateCertificate(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "certs")
+	hosts := []string{"localhost", "127.0.0.1", "192.168.1.20"}
+
+	cert, err := LoadOrCreateCertificate(dir, hosts)
+	if err != nil {
+		t.Fatalf("LoadOrCreateCertificate failed: %v", err)
+	}
+	if !cert.Created {
+		t.Error("expected a new certificate")
+	}
+	for _, host := range hosts {
+		if err := cert.TLS.Leaf.VerifyHostname(host); err != nil {
+			t.Errorf("expected the certificate to be valid for %s: %v", host, err)
+		}
+	}
+	if !regexp.MustCompile(`^([0-9A-F]{2}:){31}[0-9A-F]{2}$`).MatchString(cert.Fingerprint) {
+		t.Errorf("expected a SHA-256 fingerprint, got %q", cert.Fingerprint)
+	}
+	info, err := os.Stat(filepath.Join(dir, keyFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("expected the key to be readable only by its owner, got %v", info.Mode())
+	}
+
+	// The saved certificate is used again, so browsers don't ask again
+	again, err := LoadOrCreateCertificate(dir, hosts[:2])
+	if err != nil {
+		t.Fatalf("LoadOrCreateCertificate failed: %v", err)
+	}
+	if again.Created || again.Fingerprint != cert.Fingerprint {
+		t.Error("expected the saved certificate to be reused")
+	}
+}
+
+func TestLoadOrCreateCertificate_NewAddress(t *testing.T) {
+	dir := t.TempDir()
+	old, err := LoadOrCreateCertificate(dir, []string{"localhost", "192.168.1.20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := LoadOrCreateCertificate(dir, []string{"localhost", "10.0.0.5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.Created || cert.Fingerprint == old.Fingerprint {
+		t.Fatal("expected a new certificate for the new address")
+	}
+	for _, host := range []string{"localhost", "192.168.1.20", "10.0.0.5"} {
+		if err := cert.TLS.Leaf.VerifyHostname(host); err != nil {
+			t.Errorf("expected the new certificate to keep the old addresses and add the new one, %s: %v", host, err)
+		}
+	}
+}
+
+func TestLoadOrCreateCertificate_RenewsExpiring(t *testing.T) {
+	dir := t.TempDir()
+	certPEM, keyPEM, err := generateCertificate([]string{"localhost"}, time.Now().Add(-certValidity+certRenewBefore/2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, certFileName), certPEM, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, keyFileName), keyPEM, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := LoadOrCreateCertificate(dir, []string{"localhost"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cert.Created || time.Until(cert.TLS.Leaf.NotAfter) < certValidity-time.Hour {
+		t.Errorf("expected a certificate that expires soon to be replaced, got one expiring %v", cert.TLS.Leaf.NotAfter)
+	}
+}
+
+func TestLoadOrCreateCertificate_Corrupted(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, certFileName), []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := LoadOrCreateCertificate(dir, []string{"localhost"})
+	if err != nil {
+		t.Fatalf("expected a corrupted certificate to be replaced, got %v", err)
+	}
+	if !cert.Created {
+		t.Error("expected a new certificate")
+	}
+}
@@ -0,0 +1,194 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// PortInUseError is returned by Start when the requested port, and every
+// other port in the range set with SetPortRange, is in use.
+type PortInUseError struct {
+	Port  int    // Requested port
+	Last  int    // Last port tried; equal to Port without a range
+	Owner string // Process listening on Port, such as "node (pid 1234)"; empty if unknown
+}
+
+func (e *PortInUseError) Error() string {
+	msg := fmt.Sprintf("port %d is already in use", e.Port)
+	if e.Owner != "" {
+		msg = fmt.Sprintf("port %d is already in use by %s", e.Port, e.Owner)
+	}
+	if e.Last > e.Port {
+		msg += fmt.Sprintf(", and so are ports %d-%d", e.Port+1, e.Last)
+	}
+	return msg
+}
+
+// Unwrap returns syscall.EADDRINUSE, so errors.Is works as it does for the
+// error from net.Listen.
+func (e *PortInUseError) Unwrap() error {
+	return syscall.EADDRINUSE
+}
+
+// SetPortRange sets how many ports Start tries, starting at the requested
+// one, when ports are already in use. With n of 1 or less, the default, only
+// the requested port is tried. It has no effect on port 0.
+func (s *Server) SetPortRange(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.portRange = n
+}
+
+// listen listens on the server's address, moving on to the next port while
+// ports are in use, up to the port range.
+func (s *Server) listen() (net.Listener, error) {
+	s.mu.RLock()
+	addr, tries := s.addr, s.portRange
+	s.mu.RUnlock()
+
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse address %s: %w", addr, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse port %s: %w", portStr, err)
+	}
+	if port == 0 || tries < 1 {
+		tries = 1
+	}
+	if port+tries-1 > 65535 {
+		tries = 65535 - port + 1
+	}
+
+	for i := 0; i < tries; i++ {
+		tryAddr := net.JoinHostPort(host, strconv.Itoa(port+i))
+		listener, err := net.Listen("tcp", tryAddr)
+		if err == nil {
+			return listener, nil
+		}
+		if port == 0 || !errors.Is(err, syscall.EADDRINUSE) {
+			return nil, fmt.Errorf("failed to listen on %s: %w", tryAddr, err)
+		}
+	}
+
+	return nil, &PortInUseError{Port: port, Last: port + tries - 1, Owner: portOwner(port)}
+}
+
+// portOwner describes the process listening on a TCP port, such as
+// "node (pid 1234)". It asks lsof, falling back to /proc on Linux, and
+// returns "" if the process can't be found, for example because it belongs
+// to another user.
+func portOwner(port int) string {
+	if owner := lsofPortOwner(port); owner != "" {
+		return owner
+	}
+	if runtime.GOOS == "linux" {
+		return procPortOwner("/proc", port)
+	}
+	return ""
+}
+
+// lsofPortOwner finds the process listening on port with lsof.
+func lsofPortOwner(port int) string {
+	path, err := exec.LookPath("lsof")
+	if err != nil {
+		return ""
+	}
+	out, err := exec.Command(path, "-nP", fmt.Sprintf("-iTCP:%d", port), "-sTCP:LISTEN", "-Fpc").Output()
+	if err != nil {
+		return ""
+	}
+	return parseLsofOwner(string(out))
+}
+
+// parseLsofOwner returns the first process in lsof's -Fpc output, which has
+// a "p<pid>" line followed by a "c<command>" line for each process.
+func parseLsofOwner(output string) string {
+	var pid string
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			pid = line[1:]
+		case 'c':
+			if pid != "" {
+				return formatOwner(line[1:], pid)
+			}
+		}
+	}
+	if pid != "" {
+		return formatOwner("", pid)
+	}
+	return ""
+}
+
+// procPortOwner finds the process listening on port by looking up the
+// listening socket's inode in procDir's net/tcp tables, and then the process
+// with a file descriptor for that socket.
+func procPortOwner(procDir string, port int) string {
+	inode := ""
+	for _, table := range []string{"tcp", "tcp6"} {
+		if inode = listeningInode(filepath.Join(procDir, "net", table), port); inode != "" {
+			break
+		}
+	}
+	if inode == "" {
+		return ""
+	}
+
+	socket := "socket:[" + inode + "]"
+	fds, _ := filepath.Glob(filepath.Join(procDir, "[0-9]*", "fd", "*"))
+	for _, fd := range fds {
+		if target, err := os.Readlink(fd); err != nil || target != socket {
+			continue
+		}
+		pidDir := filepath.Dir(filepath.Dir(fd))
+		comm, _ := os.ReadFile(filepath.Join(pidDir, "comm"))
+		return formatOwner(strings.TrimSpace(string(comm)), filepath.Base(pidDir))
+	}
+	return ""
+}
+
+// listeningInode returns the inode of the socket listening on port in a
+// /proc/net/tcp table, or "" if there is none.
+func listeningInode(table string, port int) string {
+	f, err := os.Open(table)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	// Lines are "sl local_address rem_address st ... uid timeout inode",
+	// with the local port in hex and st 0A for LISTEN
+	wantPort := fmt.Sprintf(":%04X", port)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 || fields[3] != "0A" || !strings.HasSuffix(fields[1], wantPort) {
+			continue
+		}
+		return fields[9]
+	}
+	return ""
+}
+
+// formatOwner formats a process as "name (pid 1234)", or "pid 1234" without
+// a name.
+func formatOwner(name, pid string) string {
+	if name == "" {
+		return "pid " + pid
+	}
+	return fmt.Sprintf("%s (pid %s)", name, pid)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// freePort returns a port that nothing is listening on.
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "0.0.0.0:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+	return port
+}
+
+// startServer starts s and shuts it down when the test ends.
+func startServer(t *testing.T, s *Server) {
+	t.Helper()
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.Shutdown(ctx)
+	})
+}
+
+func TestStartFallsBackToNextPort(t *testing.T) {
+	port := freePort(t)
+
+	first := New(port)
+	first.SetPortRange(10)
+	startServer(t, first)
+
+	second := New(port)
+	second.SetPortRange(10)
+	startServer(t, second)
+
+	if first.Port() != port {
+		t.Errorf("first Port() = %d, want %d", first.Port(), port)
+	}
+	if second.Port() == first.Port() {
+		t.Errorf("second Port() = %d, want a different port than the first server", second.Port())
+	}
+	if second.Port() < port || second.Port() >= port+10 {
+		t.Errorf("second Port() = %d, want a port in %d-%d", second.Port(), port, port+9)
+	}
+}
+
+func TestStartPortInUse(t *testing.T) {
+	port := freePort(t)
+	startServer(t, New(port))
+
+	s := New(port)
+	err := s.Start()
+	if err == nil {
+		t.Fatal("Start() on a port in use should return an error")
+	}
+
+	var inUse *PortInUseError
+	if !errors.As(err, &inUse) {
+		t.Fatalf("Start() error = %v, want a *PortInUseError", err)
+	}
+	if inUse.Port != port || inUse.Last != port {
+		t.Errorf("error ports = %d-%d, want %d-%d", inUse.Port, inUse.Last, port, port)
+	}
+	if !errors.Is(err, syscall.EADDRINUSE) {
+		t.Error("errors.Is(err, syscall.EADDRINUSE) = false, want true")
+	}
+	if s.IsStarted() {
+		t.Error("IsStarted() = true after a failed Start")
+	}
+}
+
+func TestPortInUseErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		err  PortInUseError
+		want string
+	}{
+		{
+			name: "unknown owner",
+			err:  PortInUseError{Port: 3000, Last: 3000},
+			want: "port 3000 is already in use",
+		},
+		{
+			name: "known owner",
+			err:  PortInUseError{Port: 3000, Last: 3000, Owner: "node (pid 1234)"},
+			want: "port 3000 is already in use by node (pid 1234)",
+		},
+		{
+			name: "range",
+			err:  PortInUseError{Port: 3000, Last: 3009, Owner: "node (pid 1234)"},
+			want: "port 3000 is already in use by node (pid 1234), and so are ports 3001-3009",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLsofOwner(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "process", output: "p1234\ncnode\n", want: "node (pid 1234)"},
+		{name: "first of several", output: "p1234\ncnode\np5678\ncpython3\n", want: "node (pid 1234)"},
+		{name: "no command", output: "p1234\n", want: "pid 1234"},
+		{name: "empty", output: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseLsofOwner(tt.output); got != tt.want {
+				t.Errorf("parseLsofOwner() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcPortOwner(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("/proc is only available on Linux")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	got := procPortOwner("/proc", port)
+	if want := fmt.Sprintf("(pid %d)", os.Getpid()); len(got) < len(want) || got[len(got)-len(want):] != want {
+		t.Errorf("procPortOwner() = %q, want this process, %q", got, want)
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+// PositionCallback is called when the slide the clients show changes, with
+// its index, or -1 once no connected client has reported a slide.
+type PositionCallback func(slideIndex int)
+
+// SetOnPositionChange sets a callback to be called when the slide the
+// clients show changes.
+func (h *WebSocketHub) SetOnPositionChange(callback PositionCallback) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.onPositionChange = callback
+}
+
+// Position returns the slide the clients show: the slide the presenter view
+// reported last, or the slide any other client reported last if no presenter
+// view has reported one. It returns false if no connected client has.
+func (h *WebSocketHub) Position() (int, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.position, h.position >= 0
+}
+
+// reportPosition records the slide a client shows, from a position message.
+func (h *WebSocketHub) reportPosition(client *Client, slideIndex int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.positionSeq++
+	client.position = slideIndex
+	client.positionSeq = h.positionSeq
+	h.updatePosition()
+}
+
+// updatePosition resolves the slide the clients show, and calls the callback
+// if it changed. Must be called with the lock held.
+func (h *WebSocketHub) updatePosition() {
+	position := h.resolvePosition()
+	if position == h.position {
+		return
+	}
+	h.position = position
+	if h.onPositionChange != nil {
+		// Call callback without lock to avoid deadlocks
+		callback := h.onPositionChange
+		go callback(position)
+	}
+}
+
+// resolvePosition returns the slide of the latest position message from a
+// presenter view, or from any client if no presenter view sent one, or -1 if
+// no connected client did. The presenter view is preferred because it is on
+// the slide the speaker is presenting, while audience tabs may lag behind or
+// be browsing on their own. Must be called with the lock held.
+func (h *WebSocketHub) resolvePosition() int {
+	var latest, latestPresenter *Client
+	for client := range h.clients {
+		if client.positionSeq == 0 {
+			continue
+		}
+		if latest == nil || client.positionSeq > latest.positionSeq {
+			latest = client
+		}
+		if client.presenter && (latestPresenter == nil || client.positionSeq > latestPresenter.positionSeq) {
+			latestPresenter = client
+		}
+	}
+	switch {
+	case latestPresenter != nil:
+		return latestPresenter.position
+	case latest != nil:
+		return latest.position
+	}
+	return -1
+}
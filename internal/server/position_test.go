@@ -0,0 +1,107 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+// positionClient adds a client to hub as if it had connected.
+func positionClient(hub *WebSocketHub, presenter bool, remote int) *Client {
+	client := &Client{hub: hub, send: make(chan []byte, 1), presenter: presenter, remote: remote}
+	hub.mu.Lock()
+	hub.clients[client] = true
+	hub.mu.Unlock()
+	return client
+}
+
+// waitPosition waits for the position callback to report want.
+func waitPosition(t *testing.T, changes <-chan int, want int) {
+	t.Helper()
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case got := <-changes:
+			if got == want {
+				return
+			}
+		case <-timeout:
+			t.Fatalf("position callback not called with %d", want)
+		}
+	}
+}
+
+func TestWebSocketHubPosition(t *testing.T) {
+	hub := NewWebSocketHub()
+	changes := make(chan int, 10)
+	hub.SetOnPositionChange(func(slideIndex int) { changes <- slideIndex })
+
+	if _, ok := hub.Position(); ok {
+		t.Error("Position() should report no slide before a position message")
+	}
+
+	audience := positionClient(hub, false, 0)
+	audience.handleMessage(Message{Type: MessagePosition, SlideIndex: 3})
+	if index, ok := hub.Position(); !ok || index != 3 {
+		t.Errorf("Position() = %d, %v, want 3, true", index, ok)
+	}
+	waitPosition(t, changes, 3)
+
+	// Slide 0 is sent without a slideIndex, which decodes as 0
+	audience.handleMessage(Message{Type: MessagePosition})
+	if index, _ := hub.Position(); index != 0 {
+		t.Errorf("Position() = %d, want 0", index)
+	}
+}
+
+func TestWebSocketHubPositionPrefersPresenter(t *testing.T) {
+	hub := NewWebSocketHub()
+	audience := positionClient(hub, false, 0)
+	other := positionClient(hub, false, 0)
+	presenter := positionClient(hub, true, 0)
+
+	audience.handleMessage(Message{Type: MessagePosition, SlideIndex: 2})
+	other.handleMessage(Message{Type: MessagePosition, SlideIndex: 4})
+	if index, _ := hub.Position(); index != 4 {
+		t.Errorf("Position() = %d, want 4 from the latest audience client", index)
+	}
+
+	presenter.handleMessage(Message{Type: MessagePosition, SlideIndex: 6})
+	audience.handleMessage(Message{Type: MessagePosition, SlideIndex: 9})
+	if index, _ := hub.Position(); index != 6 {
+		t.Errorf("Position() = %d, want 6 from the presenter view", index)
+	}
+}
+
+func TestWebSocketHubPositionIgnoresRemotes(t *testing.T) {
+	hub := NewWebSocketHub()
+	remote := positionClient(hub, false, 1)
+
+	remote.handleMessage(Message{Type: MessagePosition, SlideIndex: 5})
+	if index, ok := hub.Position(); ok {
+		t.Errorf("Position() = %d, want no slide from a remote", index)
+	}
+}
+
+func TestWebSocketHubPositionAfterDisconnect(t *testing.T) {
+	hub := NewWebSocketHub()
+	changes := make(chan int, 10)
+	hub.SetOnPositionChange(func(slideIndex int) { changes <- slideIndex })
+	go hub.Run()
+	defer hub.Stop()
+
+	audience := positionClient(hub, false, 0)
+	presenter := positionClient(hub, true, 0)
+	audience.handleMessage(Message{Type: MessagePosition, SlideIndex: 7})
+	presenter.handleMessage(Message{Type: MessagePosition, SlideIndex: 5})
+	waitPosition(t, changes, 5)
+
+	// The audience's slide is used once the presenter view disconnects
+	hub.unregister <- presenter
+	waitPosition(t, changes, 7)
+
+	hub.unregister <- audience
+	waitPosition(t, changes, -1)
+	if _, ok := hub.Position(); ok {
+		t.Error("Position() should report no slide once every client disconnected")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net"
+	neturl "net/url"
 	"strings"
 
 	"github.com/skip2/go-qrcode"
@@ -15,11 +16,21 @@ type QRConfig struct {
 	PresenterPassword string
 	PreferredHost     string // Optional: preferred host to use instead of auto-detecting
 	Port              int
+	TLS               bool // Whether the server serves HTTPS
+}
+
+// scheme returns the URL scheme of the server.
+func (cfg QRConfig) scheme() string {
+	if cfg.TLS {
+		return "https"
+	}
+	return "http"
 }
 
 // GeneratePresenterURL generates the presenter URL for the given configuration.
 // It auto-detects the local IP address if no preferred host is specified.
-// If a presenter password is configured, it's included as a query parameter.
+// If a presenter password is configured, it's included as the ?token= query
+// parameter, so the QR code opens the presenter view directly.
 func GeneratePresenterURL(cfg QRConfig) (string, error) {
 	host := cfg.PreferredHost
 	if host == "" {
@@ -33,9 +44,9 @@ func GeneratePresenterURL(cfg QRConfig) (string, error) {
 		}
 	}
 
-	url := fmt.Sprintf("http://%s:%d/presenter", host, cfg.Port)
+	url := fmt.Sprintf("%s://%s:%d/presenter", cfg.scheme(), host, cfg.Port)
 	if cfg.PresenterPassword != "" {
-		url += "?key=" + cfg.PresenterPassword
+		url += "?token=" + neturl.QueryEscape(cfg.PresenterPassword)
 	}
 	return url, nil
 }
@@ -53,7 +64,7 @@ func GenerateAudienceURL(cfg QRConfig) (string, error) {
 		}
 	}
 
-	return fmt.Sprintf("http://%s:%d", host, cfg.Port), nil
+	return fmt.Sprintf("%s://%s:%d", cfg.scheme(), host, cfg.Port), nil
 }
 
 // GenerateQRCodePNG generates a QR code as a PNG image.
@@ -141,6 +152,47 @@ func getLocalIP() (string, error) {
 	return localAddr.IP.String(), nil
 }
 
+// LANAddresses returns the IPv4 addresses of the network interfaces that are up,
+// excluding loopback and link-local addresses. Other devices on the same network
+// can reach the dev server at these addresses. The address of the interface used
+// for outbound traffic, if any, comes first.
+func LANAddresses() ([]string, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network interfaces: %w", err)
+	}
+
+	preferred, _ := getLocalIP()
+
+	var addrs []string
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+			continue
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range ifaceAddrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok {
+				continue
+			}
+			ip := ipNet.IP.To4()
+			if ip == nil || ip.IsLoopback() || ip.IsLinkLocalUnicast() {
+				continue
+			}
+			if ip.String() == preferred {
+				addrs = append([]string{preferred}, addrs...)
+			} else {
+				addrs = append(addrs, ip.String())
+			}
+		}
+	}
+
+	return addrs, nil
+}
+
 // GenerateQRCodeHTML generates an HTML page displaying the QR code.
 // This is used for the /qr endpoint.
 func GenerateQRCodeHTML(audienceURL, presenterURL string) (string, error) {
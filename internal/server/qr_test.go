@@ -2,6 +2,7 @@ package server
 
 import (
 	"fmt"
+	"net"
 	"strings"
 	"testing"
 )
@@ -27,7 +28,7 @@ func TestGeneratePresenterURL(t *testing.T) {
 				PreferredHost:     "192.168.1.100",
 				PresenterPassword: "secret123",
 			},
-			contains: []string{"http://192.168.1.100:3000/presenter", "?key=secret123"},
+			contains: []string{"http://192.168.1.100:3000/presenter", "?token=secret123"},
 		},
 		{
 			name: "different port",
@@ -37,6 +38,15 @@ func TestGeneratePresenterURL(t *testing.T) {
 			},
 			contains: []string{"http://10.0.0.1:8080/presenter"},
 		},
+		{
+			name: "TLS",
+			cfg: QRConfig{
+				Port:          3000,
+				PreferredHost: "192.168.1.100",
+				TLS:           true,
+			},
+			contains: []string{"https://192.168.1.100:3000/presenter"},
+		},
 		{
 			name: "localhost host",
 			cfg: QRConfig{
@@ -105,6 +115,15 @@ func TestGenerateAudienceURL(t *testing.T) {
 			},
 			expected: "http://10.0.0.1:8080",
 		},
+		{
+			name: "TLS",
+			cfg: QRConfig{
+				Port:          3000,
+				PreferredHost: "192.168.1.100",
+				TLS:           true,
+			},
+			expected: "https://192.168.1.100:3000",
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,6 +197,24 @@ func TestGenerateASCIIQRCode(t *testing.T) {
 	}
 }
 
+func TestLANAddresses(t *testing.T) {
+	addrs, err := LANAddresses()
+	if err != nil {
+		t.Fatalf("LANAddresses() error = %v", err)
+	}
+
+	for _, addr := range addrs {
+		ip := net.ParseIP(addr)
+		if ip == nil || ip.To4() == nil {
+			t.Errorf("LANAddresses() returned non-IPv4 address %q", addr)
+			continue
+		}
+		if ip.IsLoopback() {
+			t.Errorf("LANAddresses() returned loopback address %q", addr)
+		}
+	}
+}
+
 func TestGenerateQRCodeHTML(t *testing.T) {
 	audienceURL := "http://192.168.1.100:3000"
 	presenterURL := "http://192.168.1.100:3000/presenter?key=secret"
@@ -206,11 +243,11 @@ func TestGenerateQRCodeHTML(t *testing.T) {
 }
 
 func TestGeneratePresenterURL_PasswordURLEncoding(t *testing.T) {
-	// Test that passwords are included correctly (basic case)
+	// Test that passwords are escaped in the token query parameter
 	cfg := QRConfig{
 		Port:              3000,
 		PreferredHost:     "localhost",
-		PresenterPassword: "mypassword",
+		PresenterPassword: "my pass&word",
 	}
 
 	url, err := GeneratePresenterURL(cfg)
@@ -218,8 +255,8 @@ func TestGeneratePresenterURL_PasswordURLEncoding(t *testing.T) {
 		t.Fatalf("GeneratePresenterURL() error = %v", err)
 	}
 
-	if !strings.HasSuffix(url, "?key=mypassword") {
-		t.Errorf("GeneratePresenterURL() = %q, should end with '?key=mypassword'", url)
+	if !strings.HasSuffix(url, "?token=my+pass%26word") {
+		t.Errorf("GeneratePresenterURL() = %q, should end with '?token=my+pass%%26word'", url)
 	}
 }
 
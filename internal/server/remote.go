@@ -0,0 +1,426 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxPairingAttempts is how many wrong pairing codes a client address may
+	// send within pairingWindow before it has to wait.
+	maxPairingAttempts = 5
+	// pairingWindow is the period over which wrong pairing codes are counted.
+	pairingWindow = time.Minute
+)
+
+// Remote is a device paired on the /remote page to change slides, with the
+// same authority as the presenter view.
+type Remote struct {
+	PairedAt  time.Time `json:"pairedAt"`
+	Name      string    `json:"name"` // Browser and platform from the User-Agent
+	ID        int       `json:"id"`   // Shown in the dev TUI, starting at 1
+	Connected bool      `json:"connected"`
+}
+
+// remoteState holds the pairing code and the paired remotes. It is guarded by
+// the server's mutex.
+type remoteState struct {
+	code     string
+	remotes  map[string]*Remote     // By the token the remote connects with
+	failures map[string][]time.Time // Wrong pairing codes by client address
+	lastID   int
+}
+
+// GenerateRemoteCode returns a new random 4-digit pairing code.
+func GenerateRemoteCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(10000))
+	if err != nil {
+		return "", fmt.Errorf("failed to generate pairing code: %w", err)
+	}
+	return fmt.Sprintf("%04d", n.Int64()), nil
+}
+
+// RemoteCode returns the code that pairs a device on the /remote page.
+func (s *Server) RemoteCode() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.remote.code
+}
+
+// RotateRemoteCode replaces the pairing code, unpairs all remotes, and
+// disconnects them. It returns the new code.
+func (s *Server) RotateRemoteCode() (string, error) {
+	code, err := GenerateRemoteCode()
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	s.remote.code = code
+	s.remote.remotes = make(map[string]*Remote)
+	hub := s.hub
+	s.mu.Unlock()
+
+	if hub != nil {
+		hub.DisconnectRemotes(0)
+	}
+	return code, nil
+}
+
+// Remotes returns the paired remotes in the order they were paired.
+func (s *Server) Remotes() []Remote {
+	s.mu.RLock()
+	remotes := make([]Remote, 0, len(s.remote.remotes))
+	for _, remote := range s.remote.remotes {
+		remotes = append(remotes, *remote)
+	}
+	hub := s.hub
+	s.mu.RUnlock()
+
+	var connected map[int]bool
+	if hub != nil {
+		connected = hub.ConnectedRemotes()
+	}
+	for i := range remotes {
+		remotes[i].Connected = connected[remotes[i].ID]
+	}
+	sort.Slice(remotes, func(i, j int) bool { return remotes[i].ID < remotes[j].ID })
+	return remotes
+}
+
+// KickRemote unpairs the remote with the given ID and disconnects it. It
+// returns false if there is no such remote.
+func (s *Server) KickRemote(id int) bool {
+	s.mu.Lock()
+	found := false
+	for token, remote := range s.remote.remotes {
+		if remote.ID == id {
+			delete(s.remote.remotes, token)
+			found = true
+		}
+	}
+	hub := s.hub
+	s.mu.Unlock()
+
+	if found && hub != nil {
+		hub.DisconnectRemotes(id)
+	}
+	return found
+}
+
+// pairedRemote returns the ID of the remote that token belongs to, or 0 if
+// it doesn't belong to any.
+func (s *Server) pairedRemote(token string) int {
+	if token == "" {
+		return 0
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for t, remote := range s.remote.remotes {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			return remote.ID
+		}
+	}
+	return 0
+}
+
+// pairingAllowed reports whether a client address may try another pairing
+// code, and if not, how long until it may. Must be called with the lock held.
+func (s *Server) pairingAllowed(addr string, now time.Time) (bool, time.Duration) {
+	var recent []time.Time
+	for _, t := range s.remote.failures[addr] {
+		if now.Sub(t) < pairingWindow {
+			recent = append(recent, t)
+		}
+	}
+	if len(recent) == 0 {
+		delete(s.remote.failures, addr)
+	} else {
+		s.remote.failures[addr] = recent
+	}
+
+	if len(recent) >= maxPairingAttempts {
+		return false, pairingWindow - now.Sub(recent[0])
+	}
+	return true, 0
+}
+
+// pair checks a pairing code sent from addr and, if it is correct, pairs a
+// new remote and returns its token. It returns an error if the code is wrong
+// or the address sent too many wrong codes.
+func (s *Server) pair(code, addr, userAgent string) (string, *Remote, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if allowed, wait := s.pairingAllowed(addr, now); !allowed {
+		return "", nil, &pairingLimitError{wait: wait}
+	}
+	if s.remote.code == "" || subtle.ConstantTimeCompare([]byte(code), []byte(s.remote.code)) != 1 {
+		s.remote.failures[addr] = append(s.remote.failures[addr], now)
+		return "", nil, errWrongPairingCode
+	}
+
+	token, err := GeneratePresenterToken()
+	if err != nil {
+		return "", nil, err
+	}
+	s.remote.lastID++
+	remote := &Remote{ID: s.remote.lastID, Name: deviceName(userAgent), PairedAt: now}
+	s.remote.remotes[token] = remote
+	delete(s.remote.failures, addr)
+	return token, remote, nil
+}
+
+// errWrongPairingCode is returned by pair for a wrong pairing code.
+var errWrongPairingCode = errors.New("incorrect pairing code")
+
+// pairingLimitError is returned by pair when an address sent too many wrong
+// pairing codes.
+type pairingLimitError struct {
+	wait time.Duration
+}
+
+func (e *pairingLimitError) Error() string {
+	return "too many pairing attempts; try again later"
+}
+
+// deviceName describes the browser and platform of a User-Agent, such as
+// "Safari on iPhone", so remotes can be told apart in the dev TUI.
+func deviceName(userAgent string) string {
+	browser := "Browser"
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"Firefox/", "Firefox"},
+		{"FxiOS/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	} {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	for _, p := range []struct{ token, name string }{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Mac OS X", "Mac"},
+		{"Windows", "Windows"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, p.token) {
+			return browser + " on " + p.name
+		}
+	}
+	return browser
+}
+
+// clientAddr returns the IP address a request came from.
+func clientAddr(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// handleRemotePair pairs a device that sends the pairing code as the code
+// form value. It responds with the token to connect to /ws?role=remote with.
+func (s *Server) handleRemotePair(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	token, remote, err := s.pair(r.FormValue("code"), clientAddr(r), r.UserAgent())
+	var limitErr *pairingLimitError
+	if errors.As(err, &limitErr) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(limitErr.wait.Seconds())+1))
+		http.Error(w, limitErr.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if errors.Is(err, errWrongPairingCode) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"token": token, "id": remote.ID})
+}
+
+// handleRemote serves the remote control page.
+func (s *Server) handleRemote(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = remoteTemplate.Execute(w, nil)
+}
+
+// remoteTemplate is the remote control page. It asks for the pairing code,
+// then sends next, prev, and goto messages over the WebSocket. The token is
+// kept in session storage, so reloading the page doesn't unpair it.
+var remoteTemplate = template.Must(template.New("remote").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0, user-scalable=no">
+    <title>Tap - Remote</title>
+    <style>
+        body {
+            font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif;
+            display: flex;
+            flex-direction: column;
+            align-items: center;
+            justify-content: center;
+            min-height: 100vh;
+            margin: 0;
+            background: #1a1a2e;
+            color: #eee;
+        }
+        form, #controls {
+            display: flex;
+            flex-direction: column;
+            gap: 1rem;
+            width: 280px;
+        }
+        input, button {
+            padding: 0.6rem 0.8rem;
+            border-radius: 6px;
+            border: 1px solid #444;
+            font-size: 1rem;
+        }
+        input[name=code] {
+            font-size: 2rem;
+            letter-spacing: 0.5em;
+            text-align: center;
+        }
+        button {
+            background: #7c3aed;
+            color: #fff;
+            border: none;
+            cursor: pointer;
+        }
+        #next { font-size: 2rem; padding: 3rem 0; }
+        #prev { font-size: 1.5rem; padding: 1.5rem 0; background: #4c1d95; }
+        .row { display: flex; gap: 0.5rem; }
+        .row input { flex: 1; min-width: 0; }
+        #slide { text-align: center; color: #aaa; }
+        .error { color: #f87171; min-height: 1.2em; }
+        [hidden] { display: none !important; }
+    </style>
+</head>
+<body>
+    <form id="pair">
+        <h1>Remote control</h1>
+        <p>Enter the pairing code shown in the terminal.</p>
+        <input name="code" inputmode="numeric" pattern="[0-9]{4}" maxlength="4" autocomplete="off" autofocus required>
+        <button type="submit">Pair</button>
+        <p class="error" id="pair-error"></p>
+    </form>
+    <div id="controls" hidden>
+        <p id="slide">Connecting...</p>
+        <button id="next" type="button">Next</button>
+        <button id="prev" type="button">Previous</button>
+        <form class="row" id="goto">
+            <input name="slide" type="number" min="1" placeholder="Slide" required>
+            <button type="submit">Go</button>
+        </form>
+    </div>
+    <script>
+    (function () {
+        var pairForm = document.getElementById('pair');
+        var pairError = document.getElementById('pair-error');
+        var controls = document.getElementById('controls');
+        var slideLabel = document.getElementById('slide');
+        var socket = null;
+
+        function showPairing(message) {
+            sessionStorage.removeItem('tapRemoteToken');
+            if (socket) { socket.onclose = null; socket.close(); socket = null; }
+            controls.hidden = true;
+            pairForm.hidden = false;
+            pairError.textContent = message || '';
+        }
+
+        function connect(token) {
+            pairForm.hidden = true;
+            controls.hidden = false;
+            var protocol = location.protocol === 'https:' ? 'wss:' : 'ws:';
+            var opened = false;
+            socket = new WebSocket(protocol + '//' + location.host + '/ws?role=remote&remote=' + encodeURIComponent(token));
+            socket.onopen = function () { opened = true; };
+            socket.onmessage = function (event) {
+                var message = JSON.parse(event.data);
+                if (message.type === 'slide') {
+                    slideLabel.textContent = 'Slide ' + ((message.slideIndex || 0) + 1);
+                } else if (message.type === 'revoked') {
+                    showPairing('This remote was disconnected. Enter the new pairing code.');
+                }
+            };
+            socket.onclose = function () {
+                // A connection with a token that is no longer paired, such as
+                // after the server restarted, is rejected before it opens
+                if (!opened) {
+                    showPairing('Pairing expired. Enter the pairing code.');
+                    return;
+                }
+                slideLabel.textContent = 'Reconnecting...';
+                setTimeout(function () { connect(token); }, 2000);
+            };
+        }
+
+        function send(message) {
+            if (socket && socket.readyState === WebSocket.OPEN) {
+                socket.send(JSON.stringify(message));
+            }
+        }
+
+        pairForm.addEventListener('submit', function (event) {
+            event.preventDefault();
+            fetch('/api/remote/pair', { method: 'POST', body: new URLSearchParams(new FormData(pairForm)) })
+                .then(function (response) {
+                    if (!response.ok) {
+                        return response.text().then(function (text) { throw new Error(text.trim()); });
+                    }
+                    return response.json();
+                })
+                .then(function (data) {
+                    sessionStorage.setItem('tapRemoteToken', data.token);
+                    connect(data.token);
+                })
+                .catch(function (err) { pairError.textContent = err.message; });
+        });
+
+        document.getElementById('next').addEventListener('click', function () { send({ type: 'next' }); });
+        document.getElementById('prev').addEventListener('click', function () { send({ type: 'prev' }); });
+        document.getElementById('goto').addEventListener('submit', function (event) {
+            event.preventDefault();
+            var input = event.target.elements.slide;
+            send({ type: 'goto', slideIndex: parseInt(input.value, 10) - 1 });
+            input.value = '';
+        });
+
+        var token = sessionStorage.getItem('tapRemoteToken');
+        if (token) {
+            connect(token);
+        }
+    })();
+    </script>
+</body>
+</html>
+`))
@@ -0,0 +1,337 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// newRemoteTestServer returns a running server with a hub and a presentation
+// with the given number of slides.
+func newRemoteTestServer(t *testing.T, slides int) (*Server, *WebSocketHub, *httptest.Server) {
+	t.Helper()
+	hub := NewWebSocketHub()
+	go hub.Run()
+	t.Cleanup(hub.Stop)
+
+	s := New(0)
+	s.SetPresentation(&transformer.TransformedPresentation{Slides: make([]transformer.TransformedSlide, slides)})
+	s.SetupRoutes()
+	s.SetWebSocketHub(hub)
+	server := httptest.NewServer(s.requireAudienceAuth(s.mux))
+	t.Cleanup(server.Close)
+	return s, hub, server
+}
+
+// pairRemote sends a pairing code and returns the response.
+func pairRemote(t *testing.T, serverURL, code string) *http.Response {
+	t.Helper()
+	resp, err := http.PostForm(serverURL+"/api/remote/pair", url.Values{"code": {code}})
+	if err != nil {
+		t.Fatalf("POST /api/remote/pair error = %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// pairedToken pairs a remote with the server's current code and returns its token.
+func pairedToken(t *testing.T, s *Server, serverURL string) string {
+	t.Helper()
+	resp := pairRemote(t, serverURL, s.RemoteCode())
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("pairing status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	var body struct {
+		Token string `json:"token"`
+		ID    int    `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Token == "" || body.ID == 0 {
+		t.Fatalf("unexpected pairing response %+v: %v", body, err)
+	}
+	return body.Token
+}
+
+// readMessage reads the next message from a WebSocket connection.
+func readMessage(ctx context.Context, t *testing.T, conn *websocket.Conn) Message {
+	t.Helper()
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("failed to read message: %v", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("invalid message %s: %v", data, err)
+	}
+	return msg
+}
+
+func TestGenerateRemoteCode(t *testing.T) {
+	for range 20 {
+		code, err := GenerateRemoteCode()
+		if err != nil {
+			t.Fatalf("GenerateRemoteCode() error = %v", err)
+		}
+		if len(code) != 4 || strings.Trim(code, "0123456789") != "" {
+			t.Fatalf("code = %q, want 4 digits", code)
+		}
+	}
+}
+
+func TestRemotePage(t *testing.T) {
+	_, _, server := newRemoteTestServer(t, 3)
+
+	resp, err := http.Get(server.URL + "/remote")
+	if err != nil {
+		t.Fatalf("GET /remote error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	for _, want := range []string{"/api/remote/pair", "role=remote", "'next'", "'prev'", "'goto'"} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("remote page missing %q", want)
+		}
+	}
+}
+
+func TestRemotePairing(t *testing.T) {
+	s, _, server := newRemoteTestServer(t, 3)
+
+	wrong := "0000"
+	if s.RemoteCode() == wrong {
+		wrong = "1111"
+	}
+	if resp := pairRemote(t, server.URL, wrong); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("wrong code status = %d, want %d", resp.StatusCode, http.StatusForbidden)
+	}
+
+	token := pairedToken(t, s, server.URL)
+	if s.pairedRemote(token) == 0 {
+		t.Error("expected the token to belong to a paired remote")
+	}
+	if s.pairedRemote("not-a-token") != 0 || s.pairedRemote("") != 0 {
+		t.Error("expected unknown tokens to be rejected")
+	}
+
+	remotes := s.Remotes()
+	if len(remotes) != 1 || remotes[0].ID != 1 || remotes[0].Name != "Browser" || remotes[0].Connected {
+		t.Errorf("Remotes() = %+v, want one unconnected remote", remotes)
+	}
+}
+
+func TestRemotePairingRateLimit(t *testing.T) {
+	s, _, server := newRemoteTestServer(t, 3)
+
+	wrong := "0000"
+	if s.RemoteCode() == wrong {
+		wrong = "1111"
+	}
+	for range maxPairingAttempts {
+		pairRemote(t, server.URL, wrong)
+	}
+
+	// Even the right code is refused until the window has passed
+	resp := pairRemote(t, server.URL, s.RemoteCode())
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	if resp.Header.Get("Retry-After") == "" {
+		t.Error("expected a Retry-After header")
+	}
+
+	// Other addresses can still pair
+	if _, _, err := s.pair(s.RemoteCode(), "192.0.2.1", ""); err != nil {
+		t.Errorf("pair() from another address error = %v", err)
+	}
+
+	// Failures older than the window no longer count
+	s.mu.Lock()
+	for addr, failures := range s.remote.failures {
+		for i := range failures {
+			failures[i] = failures[i].Add(-pairingWindow)
+		}
+		s.remote.failures[addr] = failures
+	}
+	s.mu.Unlock()
+	if resp := pairRemote(t, server.URL, s.RemoteCode()); resp.StatusCode != http.StatusOK {
+		t.Errorf("status after the window = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
+
+func TestRemoteNavigation(t *testing.T) {
+	s, hub, server := newRemoteTestServer(t, 3)
+	token := pairedToken(t, s, server.URL)
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// An unpaired token is rejected
+	_, resp, err := websocket.Dial(ctx, wsURL+"?role=remote&remote=wrong", nil)
+	if err == nil {
+		t.Fatal("expected dial with an unpaired token to fail")
+	}
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("expected 403 for an unpaired token, got %v", resp)
+	}
+
+	remote, _, err := websocket.Dial(ctx, wsURL+"?role=remote&remote="+token, nil)
+	if err != nil {
+		t.Fatalf("failed to connect remote: %v", err)
+	}
+	defer remote.Close(websocket.StatusNormalClosure, "")
+	audience, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("failed to connect audience: %v", err)
+	}
+	defer audience.Close(websocket.StatusNormalClosure, "")
+
+	// The remote is told which slide the presentation is on
+	readMessage(ctx, t, remote)
+	if msg := readMessage(ctx, t, remote); msg.Type != MessageSlide || msg.SlideIndex != 0 {
+		t.Errorf("expected the current slide, got %+v", msg)
+	}
+	readMessage(ctx, t, audience)
+
+	if counts := hub.ClientCounts(); counts.Remote != 1 || counts.Audience != 1 {
+		t.Errorf("ClientCounts() = %+v, want 1 remote and 1 audience", counts)
+	}
+	if remotes := s.Remotes(); len(remotes) != 1 || !remotes[0].Connected {
+		t.Errorf("Remotes() = %+v, want one connected remote", remotes)
+	}
+
+	// Audience clients can't use the remote messages
+	if err := audience.Write(ctx, websocket.MessageText, []byte(`{"type":"next"}`)); err != nil {
+		t.Fatalf("audience write error = %v", err)
+	}
+
+	tests := []struct {
+		send string
+		want int
+	}{
+		{`{"type":"next"}`, 1},
+		{`{"type":"next"}`, 2},
+		{`{"type":"next"}`, 2}, // Stays on the last slide
+		{`{"type":"prev"}`, 1},
+		{`{"type":"goto","slideIndex":0}`, 0},
+		{`{"type":"prev"}`, 0}, // Stays on the first slide
+		{`{"type":"goto","slideIndex":9}`, 2},
+	}
+	for _, tt := range tests {
+		if err := remote.Write(ctx, websocket.MessageText, []byte(tt.send)); err != nil {
+			t.Fatalf("remote write error = %v", err)
+		}
+		if msg := readMessage(ctx, t, audience); msg.Type != MessageSlide || msg.SlideIndex != tt.want {
+			t.Fatalf("after %s: got %+v, want slide %d", tt.send, msg, tt.want)
+		}
+		readMessage(ctx, t, remote)
+	}
+}
+
+func TestRotateRemoteCodeDisconnectsRemotes(t *testing.T) {
+	s, _, server := newRemoteTestServer(t, 3)
+	oldCode := s.RemoteCode()
+	token := pairedToken(t, s, server.URL)
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	remote, _, err := websocket.Dial(ctx, wsURL+"?role=remote&remote="+token, nil)
+	if err != nil {
+		t.Fatalf("failed to connect remote: %v", err)
+	}
+	defer remote.Close(websocket.StatusNormalClosure, "")
+	readMessage(ctx, t, remote)
+	readMessage(ctx, t, remote)
+
+	code, err := s.RotateRemoteCode()
+	if err != nil {
+		t.Fatalf("RotateRemoteCode() error = %v", err)
+	}
+	if s.RemoteCode() != code {
+		t.Errorf("RemoteCode() = %q, want %q", s.RemoteCode(), code)
+	}
+
+	if msg := readMessage(ctx, t, remote); msg.Type != MessageRevoked {
+		t.Errorf("expected revoked message, got %+v", msg)
+	}
+	if _, _, err := remote.Read(ctx); websocket.CloseStatus(err) != websocket.StatusPolicyViolation {
+		t.Errorf("expected remote connection closed with policy violation, got %v", err)
+	}
+
+	if len(s.Remotes()) != 0 || s.pairedRemote(token) != 0 {
+		t.Error("expected remotes to be unpaired")
+	}
+	if code != oldCode {
+		if resp := pairRemote(t, server.URL, oldCode); resp.StatusCode != http.StatusForbidden {
+			t.Errorf("old code status = %d, want %d", resp.StatusCode, http.StatusForbidden)
+		}
+	}
+}
+
+func TestKickRemote(t *testing.T) {
+	s, _, server := newRemoteTestServer(t, 3)
+	first := pairedToken(t, s, server.URL)
+	second := pairedToken(t, s, server.URL)
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	remote, _, err := websocket.Dial(ctx, wsURL+"?role=remote&remote="+first, nil)
+	if err != nil {
+		t.Fatalf("failed to connect remote: %v", err)
+	}
+	defer remote.Close(websocket.StatusNormalClosure, "")
+	readMessage(ctx, t, remote)
+	readMessage(ctx, t, remote)
+
+	if !s.KickRemote(s.pairedRemote(first)) {
+		t.Fatal("KickRemote() = false, want true")
+	}
+	if s.KickRemote(99) {
+		t.Error("KickRemote() of an unknown remote = true, want false")
+	}
+
+	if msg := readMessage(ctx, t, remote); msg.Type != MessageRevoked {
+		t.Errorf("expected revoked message, got %+v", msg)
+	}
+	if s.pairedRemote(first) != 0 || s.pairedRemote(second) == 0 {
+		t.Error("expected only the kicked remote to be unpaired")
+	}
+	if remotes := s.Remotes(); len(remotes) != 1 || remotes[0].ID != 2 {
+		t.Errorf("Remotes() = %+v, want the second remote", remotes)
+	}
+}
+
+func TestDeviceName(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Mobile/15E148 Safari/604.1", "Safari on iPhone"},
+		{"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Mobile Safari/537.36", "Chrome on Android"},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.0.0", "Edge on Windows"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 14.0; rv:121.0) Gecko/20100101 Firefox/121.0", "Firefox on Mac"},
+		{"curl/8.0", "Browser"},
+	}
+	for _, tt := range tests {
+		if got := deviceName(tt.userAgent); got != tt.want {
+			t.Errorf("deviceName(%q) = %q, want %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/MiniCodeMonkey/tap/embedded"
 )
@@ -21,10 +22,16 @@ func (s *Server) SetupRoutes() {
 	// Register all routes on the server's shared mux
 	s.mux.HandleFunc("GET /", s.handleIndex)
 	s.mux.HandleFunc("GET /presenter", s.handlePresenter)
+	s.mux.HandleFunc("GET /presenter/{token}", s.handlePresenter)
+	s.mux.HandleFunc("GET /login", s.handleLogin)
+	s.mux.HandleFunc("POST /login", s.handleLogin)
 	s.mux.HandleFunc("GET /api/presentation", s.handleAPIPresentation)
+	s.mux.HandleFunc("GET /api/status", s.handleAPIStatus)
 	s.mux.HandleFunc("GET /api/custom-theme.css", s.handleCustomTheme)
-	s.mux.HandleFunc("POST /api/execute", s.handleAPIExecute)
+	s.mux.HandleFunc("POST /api/run", s.handleAPIRun)
 	s.mux.HandleFunc("GET /qr", s.handleQR)
+	s.mux.HandleFunc("GET /remote", s.handleRemote)
+	s.mux.HandleFunc("POST /api/remote/pair", s.handleRemotePair)
 
 	// Serve static assets (JS, CSS) from embedded dist/assets/
 	s.mux.HandleFunc("GET /assets/", s.handleAssets)
@@ -55,17 +62,19 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 }
 
 // handlePresenter serves the presenter view.
-// If a presenter password is configured, requires ?key=<password> query parameter.
+// If a presenter password is configured, requires the password as a token,
+// given as ?token=<password> (or the older ?key=<password>) or as a path
+// secret in /presenter/<password>.
 func (s *Server) handlePresenter(w http.ResponseWriter, r *http.Request) {
 	// Check password protection
 	password := s.GetPresenterPassword()
 	if password != "" {
-		key := r.URL.Query().Get("key")
-		if key == "" {
-			http.Error(w, "Forbidden: presenter password required. Use ?key=<password>", http.StatusForbidden)
+		token := presenterToken(r)
+		if token == "" {
+			http.Error(w, "Forbidden: presenter password required. Use ?token=<password>", http.StatusForbidden)
 			return
 		}
-		if key != password {
+		if !s.validPresenterToken(token) {
 			http.Error(w, "Forbidden: incorrect presenter password", http.StatusForbidden)
 			return
 		}
@@ -137,7 +146,8 @@ func (s *Server) handleCustomTheme(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleQR(w http.ResponseWriter, r *http.Request) {
 	cfg := QRConfig{
 		Port:              s.Port(),
-		PresenterPassword: s.presenterPassword,
+		PresenterPassword: s.GetPresenterPassword(),
+		TLS:               s.TLSEnabled(),
 	}
 
 	audienceURL, err := GenerateAudienceURL(cfg)
@@ -219,8 +229,8 @@ func (s *Server) handleLocalFiles(w http.ResponseWriter, r *http.Request) {
 	// Construct the full file path
 	fullPath := path.Join(baseDir, requestedPath)
 
-	// Read the file
-	content, err := os.ReadFile(fullPath)
+	// Open the file
+	file, err := os.Open(fullPath)
 	if err != nil {
 		if os.IsNotExist(err) {
 			http.NotFound(w, r)
@@ -229,13 +239,20 @@ func (s *Server) handleLocalFiles(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to read file", http.StatusInternalServerError)
 		return
 	}
+	defer file.Close()
 
-	// Set content type based on extension
+	info, err := file.Stat()
+	if err != nil || info.IsDir() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Set content type based on extension. ServeContent handles range
+	// requests, which browsers use to stream and seek videos.
 	contentType := getContentType(fullPath)
 	w.Header().Set("Content-Type", contentType)
 	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(content)
+	http.ServeContent(w, r, "", time.Time{}, file)
 }
 
 // getContentType returns the appropriate Content-Type header for a file path.
@@ -268,6 +285,12 @@ func getContentType(filePath string) string {
 		return "application/vnd.ms-fontobject"
 	case ".cast":
 		return "application/json; charset=utf-8"
+	case ".mp4":
+		return "video/mp4"
+	case ".webm":
+		return "video/webm"
+	case ".mov":
+		return "video/quicktime"
 	default:
 		return "application/octet-stream"
 	}
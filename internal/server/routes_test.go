@@ -6,6 +6,8 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -334,7 +336,7 @@ func TestHandleQR_WithPassword(t *testing.T) {
 	bodyStr := string(body)
 
 	// Check that password is included in presenter URL
-	if !strings.Contains(bodyStr, "?key=secretpass") {
+	if !strings.Contains(bodyStr, "?token=secretpass") {
 		t.Error("expected presenter URL to contain password query param")
 	}
 }
@@ -513,3 +515,48 @@ func TestAPIPresentation_JSONEncodesAllFields(t *testing.T) {
 		t.Errorf("expected 2 fragments, got %d", len(result.Slides[2].Fragments))
 	}
 }
+
+func TestHandleLocalFiles_Video(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "media"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "media", "loop.mp4"), []byte("0123456789"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(0)
+	s.SetBaseDir(dir)
+
+	req := httptest.NewRequest(http.MethodGet, "/local/media/loop.mp4", nil)
+	w := httptest.NewRecorder()
+	s.handleLocalFiles(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if contentType := w.Header().Get("Content-Type"); contentType != "video/mp4" {
+		t.Errorf("expected Content-Type video/mp4, got %q", contentType)
+	}
+
+	// Browsers request videos in ranges
+	req = httptest.NewRequest(http.MethodGet, "/local/media/loop.mp4", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	w = httptest.NewRecorder()
+	s.handleLocalFiles(w, req)
+
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("expected status %d, got %d", http.StatusPartialContent, w.Code)
+	}
+	if body := w.Body.String(); body != "2345" {
+		t.Errorf("expected the requested range, got %q", body)
+	}
+
+	// Directories are not served
+	req = httptest.NewRequest(http.MethodGet, "/local/media", nil)
+	w = httptest.NewRecorder()
+	s.handleLocalFiles(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d for a directory, got %d", http.StatusNotFound, w.Code)
+	}
+}
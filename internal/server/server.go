@@ -3,6 +3,8 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -23,14 +25,25 @@ type Server struct {
 	presentation      *transformer.TransformedPresentation
 	registry          *driver.Registry
 	httpServer        *http.Server
+	tlsConfig         *tls.Config // Set to serve HTTPS; see SetTLS
 	mux               *http.ServeMux
 	shutdownCh        chan struct{}
 	addr              string
+	portRange         int // Number of ports Start tries; see SetPortRange
+	hub               *WebSocketHub
 	presenterPassword string
+	audiencePassword  string
 	customThemePath   string
+	markdownFile      string // Reported in the status
+	version           string // Reported in the status
 	baseDir           string // Base directory for serving local files (images, etc.)
+	authSecret        []byte // Key for the audience cookie, random per server
+	remote            remoteState
 	mu                sync.RWMutex
 	started           bool
+	allowExec         bool // Whether the run endpoint may run code
+	watcherRunning    bool // Reported in the status
+	redirectHTTP      bool // Whether plain HTTP requests from other machines are redirected to HTTPS
 }
 
 // New creates a new Server bound to the specified port.
@@ -40,11 +53,18 @@ func New(port int) *Server {
 		addr:       fmt.Sprintf("0.0.0.0:%d", port),
 		mux:        http.NewServeMux(),
 		shutdownCh: make(chan struct{}),
+		authSecret: make([]byte, 32),
+		remote: remoteState{
+			remotes:  make(map[string]*Remote),
+			failures: make(map[string][]time.Time),
+		},
 	}
+	_, _ = rand.Read(s.authSecret)
+	s.remote.code, _ = GenerateRemoteCode()
 
 	s.httpServer = &http.Server{
 		Addr:              s.addr,
-		Handler:           s.mux,
+		Handler:           s.redirectToHTTPS(s.requireAudienceAuth(s.mux)),
 		ReadHeaderTimeout: 10 * time.Second,
 	}
 
@@ -57,6 +77,9 @@ func (s *Server) SetPresentation(pres *transformer.TransformedPresentation) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.presentation = pres
+	if s.hub != nil && pres != nil {
+		s.hub.SetSlideCount(len(pres.Slides))
+	}
 }
 
 // GetPresentation returns the current presentation data.
@@ -120,17 +143,21 @@ func (s *Server) Start() error {
 	s.mu.Unlock()
 
 	// Create listener to verify we can bind to the port
-	listener, err := net.Listen("tcp", s.addr)
+	listener, err := s.listen()
 	if err != nil {
 		s.mu.Lock()
 		s.started = false
 		s.mu.Unlock()
-		return fmt.Errorf("failed to listen on %s: %w", s.addr, err)
+		return err
 	}
 
-	// Update addr with the actual address (important when using port 0)
+	// Update addr with the actual address (important when using port 0, or
+	// when the requested port was in use)
 	s.mu.Lock()
 	s.addr = listener.Addr().String()
+	if s.tlsConfig != nil {
+		listener = newSniffListener(listener, s.tlsConfig)
+	}
 	s.mu.Unlock()
 
 	// Start serving in a goroutine
@@ -172,15 +199,23 @@ func (s *Server) StartWithGracefulShutdown() error {
 }
 
 // Shutdown gracefully shuts down the server.
-// It waits for active connections to complete with a 10-second timeout.
+// It closes the WebSocket connections with a close frame, so browsers know
+// the server went away, and waits for active requests to complete until ctx
+// is done.
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.mu.Lock()
 	if !s.started {
 		s.mu.Unlock()
 		return nil
 	}
+	hub := s.hub
 	s.mu.Unlock()
 
+	// The HTTP server doesn't track WebSocket connections, so the hub closes them
+	if hub != nil {
+		_ = hub.Shutdown(ctx)
+	}
+
 	// Signal the shutdown channel if StartWithGracefulShutdown is waiting
 	select {
 	case s.shutdownCh <- struct{}{}:
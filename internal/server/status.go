@@ -0,0 +1,94 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Status is a snapshot of the dev server's state. It is served as JSON by
+// /api/status and shown in the dev TUI's status panel.
+type Status struct {
+	LastReload     *time.Time   `json:"lastReload"`     // When clients were last told to reload; nil if never
+	LastAnnotation *time.Time   `json:"lastAnnotation"` // When the presenter view last drew on a slide; nil if never
+	MarkdownFile   string       `json:"markdownFile"`
+	Theme          string       `json:"theme"`
+	Version        string       `json:"version"`
+	Clients        ClientCounts `json:"clients"`
+	SlideCount     int          `json:"slideCount"`
+	WatcherRunning bool         `json:"watcherRunning"`
+}
+
+// ClientCounts are the numbers of connected WebSocket clients by view.
+type ClientCounts struct {
+	Audience  int `json:"audience"`
+	Presenter int `json:"presenter"`
+	Remote    int `json:"remote"`
+}
+
+// Total returns the number of connected clients.
+func (c ClientCounts) Total() int {
+	return c.Audience + c.Presenter + c.Remote
+}
+
+// SetMarkdownFile sets the markdown file reported in the status.
+func (s *Server) SetMarkdownFile(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.markdownFile = path
+}
+
+// SetVersion sets the tap version reported in the status.
+func (s *Server) SetVersion(version string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version = version
+}
+
+// SetWatcherRunning sets whether the file watcher is running.
+func (s *Server) SetWatcherRunning(running bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watcherRunning = running
+}
+
+// Status returns the current status of the server.
+// The theme is the last one broadcast to clients, or the presentation's
+// theme if none was broadcast since the last reload.
+// This method is thread-safe.
+func (s *Server) Status() Status {
+	s.mu.RLock()
+	status := Status{
+		MarkdownFile:   s.markdownFile,
+		Version:        s.version,
+		WatcherRunning: s.watcherRunning,
+	}
+	if s.presentation != nil {
+		status.SlideCount = len(s.presentation.Slides)
+		status.Theme = s.presentation.Config.Theme
+	}
+	hub := s.hub
+	s.mu.RUnlock()
+
+	if hub != nil {
+		status.Clients = hub.ClientCounts()
+		if theme := hub.Theme(); theme != "" {
+			status.Theme = theme
+		}
+		if lastReload := hub.LastReload(); !lastReload.IsZero() {
+			status.LastReload = &lastReload
+		}
+		if lastAnnotation := hub.LastAnnotation(); !lastAnnotation.IsZero() {
+			status.LastAnnotation = &lastAnnotation
+		}
+	}
+	return status
+}
+
+// handleAPIStatus returns the server status as JSON.
+func (s *Server) handleAPIStatus(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(s.Status())
+}
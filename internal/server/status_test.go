@@ -0,0 +1,114 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+func TestHandleAPIStatus(t *testing.T) {
+	hub := NewWebSocketHub()
+	go hub.Run()
+	defer hub.Stop()
+
+	s := New(0)
+	s.SetWebSocketHub(hub)
+	s.SetupRoutes()
+	s.SetMarkdownFile("/talks/slides.md")
+	s.SetVersion("1.2.3")
+	s.SetWatcherRunning(true)
+	s.SetPresentation(&transformer.TransformedPresentation{
+		Config: config.Config{Theme: "paper"},
+		Slides: make([]transformer.TransformedSlide, 3),
+	})
+
+	// One audience, one presenter, and one remote client
+	for _, presenter := range []bool{false, true} {
+		hub.register <- &Client{hub: hub, send: make(chan []byte, 256), presenter: presenter}
+	}
+	hub.register <- &Client{hub: hub, send: make(chan []byte, 256), remote: 1}
+
+	server := httptest.NewServer(s.requireAudienceAuth(s.mux))
+	defer server.Close()
+
+	getStatus := func() map[string]any {
+		t.Helper()
+		resp, err := http.Get(server.URL + "/api/status")
+		if err != nil {
+			t.Fatalf("GET /api/status error = %v", err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+
+		var body map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode status: %v", err)
+		}
+		return body
+	}
+
+	body := getStatus()
+
+	var keys []string
+	for key := range body {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	wantKeys := []string{"clients", "lastAnnotation", "lastReload", "markdownFile", "slideCount", "theme", "version", "watcherRunning"}
+	if len(keys) != len(wantKeys) {
+		t.Fatalf("keys = %v, want %v", keys, wantKeys)
+	}
+	for i := range keys {
+		if keys[i] != wantKeys[i] {
+			t.Fatalf("keys = %v, want %v", keys, wantKeys)
+		}
+	}
+
+	if body["markdownFile"] != "/talks/slides.md" || body["version"] != "1.2.3" || body["theme"] != "paper" {
+		t.Errorf("unexpected status: %v", body)
+	}
+	if body["slideCount"] != float64(3) || body["watcherRunning"] != true {
+		t.Errorf("unexpected status: %v", body)
+	}
+	if body["lastReload"] != nil {
+		t.Errorf("lastReload = %v, want null before any reload", body["lastReload"])
+	}
+	clients, ok := body["clients"].(map[string]any)
+	if !ok || clients["audience"] != float64(1) || clients["presenter"] != float64(1) || clients["remote"] != float64(1) {
+		t.Errorf("clients = %v, want 1 audience, 1 presenter, and 1 remote", body["clients"])
+	}
+
+	// A theme broadcast changes the theme until the next reload
+	if err := hub.BroadcastTheme("noir"); err != nil {
+		t.Fatalf("BroadcastTheme() error = %v", err)
+	}
+	if body := getStatus(); body["theme"] != "noir" {
+		t.Errorf("theme = %v, want noir after theme broadcast", body["theme"])
+	}
+
+	before := time.Now()
+	if err := hub.BroadcastReload(); err != nil {
+		t.Fatalf("BroadcastReload() error = %v", err)
+	}
+	body = getStatus()
+	if body["theme"] != "paper" {
+		t.Errorf("theme = %v, want the presentation theme after reload", body["theme"])
+	}
+	reloaded, _ := body["lastReload"].(string)
+	lastReload, err := time.Parse(time.RFC3339Nano, reloaded)
+	if err != nil || lastReload.Before(before.Truncate(time.Second)) {
+		t.Errorf("lastReload = %v, want a time after %v", body["lastReload"], before)
+	}
+}
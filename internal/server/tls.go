@@ -0,0 +1,164 @@
+package server
+
+import (
+	"bufio"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tlsRecordHandshake is the first byte of a TLS connection, the record type
+// of the ClientHello. Plain HTTP requests start with a method name instead.
+const tlsRecordHandshake = 0x16
+
+// sniffTimeout is how long a new connection has to send its first byte
+// before it is closed.
+const sniffTimeout = 10 * time.Second
+
+// SetTLS makes the server serve HTTPS with cert. Plain HTTP requests to the
+// same port are still answered, so local tools like the PDF export keep
+// working; with redirectHTTP, those from other machines are redirected to
+// HTTPS. It must be called before Start.
+func (s *Server) SetTLS(cert tls.Certificate, redirectHTTP bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tlsConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		NextProtos:   []string{"http/1.1"},
+	}
+	s.redirectHTTP = redirectHTTP
+}
+
+// TLSEnabled reports whether the server serves HTTPS.
+func (s *Server) TLSEnabled() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.tlsConfig != nil
+}
+
+// redirectToHTTPS wraps a handler so that, when the server serves HTTPS and
+// redirects plain HTTP, requests over HTTP from other machines are
+// redirected to the same URL over HTTPS. Browsers treat localhost as
+// secure, so local requests are served as they are.
+func (s *Server) redirectToHTTPS(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.RLock()
+		redirect := s.tlsConfig != nil && s.redirectHTTP
+		s.mu.RUnlock()
+
+		if redirect && r.TLS == nil && !isLoopbackRequest(r) {
+			http.Redirect(w, r, "https://"+r.Host+r.URL.RequestURI(), http.StatusTemporaryRedirect)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackRequest reports whether r comes from this machine.
+func isLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// sniffListener accepts both TLS and plain HTTP connections on one port. It
+// looks at the first byte of each connection and returns TLS connections
+// wrapped in a tls.Conn, so the HTTP server sees which requests are secure.
+// Connections are sniffed in their own goroutines, so a client that connects
+// and sends nothing doesn't hold up the others.
+type sniffListener struct {
+	net.Listener
+	config *tls.Config
+	conns  chan net.Conn
+	done   chan struct{} // Closed by Close
+	failed chan struct{} // Closed when the listener fails, after err is set
+	err    error
+	once   sync.Once
+}
+
+// newSniffListener returns a listener that accepts the connections of inner,
+// serving TLS with config to clients that start a TLS handshake.
+func newSniffListener(inner net.Listener, config *tls.Config) *sniffListener {
+	l := &sniffListener{
+		Listener: inner,
+		config:   config,
+		conns:    make(chan net.Conn),
+		done:     make(chan struct{}),
+		failed:   make(chan struct{}),
+	}
+	go l.acceptLoop()
+	return l
+}
+
+// acceptLoop accepts connections until the inner listener fails or is closed.
+func (l *sniffListener) acceptLoop() {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			l.err = err
+			close(l.failed)
+			return
+		}
+		go l.sniff(conn)
+	}
+}
+
+// sniff reads the first byte of conn and passes it on to Accept, as a TLS
+// connection if it starts a handshake.
+func (l *sniffListener) sniff(conn net.Conn) {
+	reader := bufio.NewReader(conn)
+	_ = conn.SetReadDeadline(time.Now().Add(sniffTimeout))
+	first, err := reader.Peek(1)
+	_ = conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	var accepted net.Conn = &peekedConn{Conn: conn, reader: reader}
+	if first[0] == tlsRecordHandshake {
+		accepted = tls.Server(accepted, l.config)
+	}
+	select {
+	case l.conns <- accepted:
+	case <-l.done:
+		_ = conn.Close()
+	}
+}
+
+// Accept returns the next sniffed connection.
+func (l *sniffListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.failed:
+		return nil, l.err
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops accepting connections. Connections still being sniffed are
+// closed.
+func (l *sniffListener) Close() error {
+	l.once.Do(func() { close(l.done) })
+	return l.Listener.Close()
+}
+
+// peekedConn is a connection whose first bytes were read into a buffer to
+// sniff the protocol. Reads return the buffered bytes first.
+type peekedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// Read reads from the buffer, then from the connection.
+func (c *peekedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
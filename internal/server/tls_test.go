@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// startTLSServer starts a server on a free port that serves HTTPS with a new
+// certificate, and returns it with a client that trusts the certificate.
+func startTLSServer(t *testing.T, redirectHTTP bool) (*Server, *http.Client) {
+	t.Helper()
+	cert, err := LoadOrCreateCertificate(t.TempDir(), []string{"localhost", "127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New(0)
+	s.addr = "127.0.0.1:0"
+	s.SetTLS(cert.TLS, redirectHTTP)
+	s.SetupRoutes()
+	s.RegisterHandlerFunc("GET /hello", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "hello over TLS: %v", r.TLS != nil)
+	})
+	if err := s.Start(); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = s.Shutdown(ctx)
+	})
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert.TLS.Leaf)
+	client := &http.Client{
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return s, client
+}
+
+func getBody(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestServer_TLS(t *testing.T) {
+	s, client := startTLSServer(t, true)
+	if !s.TLSEnabled() {
+		t.Fatal("expected TLS to be enabled")
+	}
+
+	// HTTPS and plain HTTP are served on the same port
+	if _, body := getBody(t, client, fmt.Sprintf("https://127.0.0.1:%d/hello", s.Port())); body != "hello over TLS: true" {
+		t.Errorf("expected the request over HTTPS, got %q", body)
+	}
+
+	// Local HTTP requests aren't redirected, so local tools keep working
+	resp, body := getBody(t, client, fmt.Sprintf("http://127.0.0.1:%d/hello", s.Port()))
+	if resp.StatusCode != http.StatusOK || body != "hello over TLS: false" {
+		t.Errorf("expected the local HTTP request to be served, got %d %q", resp.StatusCode, body)
+	}
+}
+
+func TestServer_TLSQRCodes(t *testing.T) {
+	s, client := startTLSServer(t, false)
+
+	_, body := getBody(t, client, fmt.Sprintf("https://127.0.0.1:%d/qr", s.Port()))
+	if !regexp.MustCompile(`https://[^"<]+:\d+/presenter`).MatchString(body) {
+		t.Errorf("expected HTTPS URLs on the QR code page, got:\n%s", body)
+	}
+}
+
+func TestServer_TLSIdleConnection(t *testing.T) {
+	s, client := startTLSServer(t, false)
+
+	// A client that connects and sends nothing doesn't block the others
+	idle, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", s.Port()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = idle.Close() }()
+
+	if _, body := getBody(t, client, fmt.Sprintf("https://127.0.0.1:%d/hello", s.Port())); body != "hello over TLS: true" {
+		t.Errorf("expected the request to be served, got %q", body)
+	}
+}
+
+func TestRedirectToHTTPS(t *testing.T) {
+	s := New(0)
+	s.SetTLS(tls.Certificate{}, true)
+	handler := s.redirectToHTTPS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		tls        bool
+		wantStatus int
+	}{
+		{"HTTP from another device", "192.168.1.30:51000", false, http.StatusTemporaryRedirect},
+		{"HTTPS from another device", "192.168.1.30:51000", true, http.StatusNoContent},
+		{"HTTP from this machine", "127.0.0.1:51000", false, http.StatusNoContent},
+		{"HTTP from this machine over IPv6", "[::1]:51000", false, http.StatusNoContent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "http://192.168.1.20:3000/presenter?token=abc", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if tt.wantStatus == http.StatusTemporaryRedirect {
+				if got := rec.Header().Get("Location"); got != "https://192.168.1.20:3000/presenter?token=abc" {
+					t.Errorf("expected a redirect to the same URL over HTTPS, got %q", got)
+				}
+			}
+		})
+	}
+
+	// Without redirectHTTP, HTTP requests are served
+	s.SetTLS(tls.Certificate{}, false)
+	req := httptest.NewRequest(http.MethodGet, "http://192.168.1.20:3000/", nil)
+	req.RemoteAddr = "192.168.1.30:51000"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Errorf("expected the request to be served without redirectHTTP, got %d", rec.Code)
+	}
+}
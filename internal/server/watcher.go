@@ -10,6 +10,9 @@ import (
 )
 
 // Watcher watches files and directories for changes and triggers callbacks.
+//
+// Deprecated: Use the watcher package, which supports multiple paths and
+// delivers debounced events on a channel.
 type Watcher struct {
 	// Fields ordered by size for better memory alignment
 	watcher      *fsnotify.Watcher
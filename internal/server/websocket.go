@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/coder/websocket"
+
+	"github.com/MiniCodeMonkey/tap/internal/slidediff"
 )
 
 // MessageType represents the type of WebSocket message.
@@ -23,21 +25,78 @@ const (
 	MessageSlide MessageType = "slide"
 	// MessageTheme signals clients to switch to a specific theme.
 	MessageTheme MessageType = "theme"
+	// MessageTimer carries the state of the talk timer, which the dev TUI
+	// controls. It is also sent to clients when they connect.
+	MessageTimer MessageType = "timer"
+	// MessageRevoked tells a presenter view or remote that its token is no
+	// longer valid, just before its connection is closed.
+	MessageRevoked MessageType = "revoked"
+	// MessageSlides carries the slides that changed when the presentation
+	// was reloaded, so clients can update them without reloading the page.
+	MessageSlides MessageType = "slides"
+	// MessageNext is sent by a paired remote to go to the next slide.
+	MessageNext MessageType = "next"
+	// MessagePrev is sent by a paired remote to go to the previous slide.
+	MessagePrev MessageType = "prev"
+	// MessageGoto is sent by a paired remote to go to the slide in SlideIndex.
+	MessageGoto MessageType = "goto"
+	// MessageAnnotate carries strokes drawn on a slide in the presenter view,
+	// which the hub relays to the audience and replays to clients that connect.
+	MessageAnnotate MessageType = "annotate"
+	// MessageClearAnnotations is sent by the presenter view to remove the
+	// strokes on the current slide, and relayed to the audience.
+	MessageClearAnnotations MessageType = "clear-annotations"
+	// MessageAutoplay turns auto-play of slides with autoAdvance on or off,
+	// from the dev TUI's kiosk toggle. It is also sent to clients when they
+	// connect.
+	MessageAutoplay MessageType = "autoplay"
+	// MessagePosition is sent by clients with the slide they show in
+	// SlideIndex whenever it changes, so the dev TUI can follow the talk. It
+	// is not broadcast.
+	MessagePosition MessageType = "position"
 )
 
 // Message represents a WebSocket message sent between server and clients.
 // Fields ordered by size for memory alignment.
 type Message struct {
-	Type       MessageType `json:"type"`
-	Theme      string      `json:"theme,omitempty"`
-	SlideIndex int         `json:"slideIndex,omitempty"`
+	Type   MessageType     `json:"type"`
+	Theme  string          `json:"theme,omitempty"`
+	Timer  *TimerState     `json:"timer,omitempty"`
+	Slides *slidediff.Diff `json:"slides,omitempty"`
+	// Annotations are the strokes of annotate messages
+	Annotations *Annotations `json:"annotations,omitempty"`
+	// Autoplay is whether slides advance on their own, for autoplay messages
+	Autoplay *bool `json:"autoplay,omitempty"`
+	// SlideIndex is the slide to navigate to for slide messages
+	SlideIndex int `json:"slideIndex,omitempty"`
+	// CurrentSlideStillValid tells clients of a slides message that the
+	// slide the presentation is on is unchanged and at the same index, so
+	// they can keep their place, including revealed fragments
+	CurrentSlideStillValid bool `json:"currentSlideStillValid,omitempty"`
+}
+
+// TimerState is the state of the talk timer shown in the presenter view.
+// Times are in milliseconds.
+type TimerState struct {
+	Elapsed  int64 `json:"elapsed"`            // Elapsed time when the state was sent
+	Duration int64 `json:"duration,omitempty"` // Target length of the talk; 0 if not set
+	Running  bool  `json:"running"`
 }
 
 // Client represents a connected WebSocket client.
 type Client struct {
-	hub  *WebSocketHub
-	conn *websocket.Conn
-	send chan []byte
+	hub        *WebSocketHub
+	conn       *websocket.Conn
+	send       chan []byte
+	revoke     chan struct{} // Closed to disconnect a presenter or remote client
+	revokeOnce sync.Once
+	remote     int  // ID of the paired remote the client is; 0 for other clients
+	presenter  bool // Whether the client is a presenter view
+	// position is the slide of the client's last position message, and
+	// positionSeq the order in which the hub received it; 0 if it sent none.
+	// Both are guarded by the hub's mutex.
+	position    int
+	positionSeq uint64
 }
 
 // ClientCountCallback is called when the number of connected clients changes.
@@ -50,7 +109,21 @@ type WebSocketHub struct {
 	register            chan *Client
 	unregister          chan *Client
 	done                chan struct{}
+	stopOnce            sync.Once
+	pumps               sync.WaitGroup // Running client write pumps
+	stopped             bool           // Set by Stop, under mu, so no pumps are added after it
 	onClientCountChange ClientCountCallback
+	lastReload          time.Time // When the last reload or slides message was broadcast
+	theme               string    // Theme of the last theme message since the last reload
+	slide               int       // Slide of the last slide message
+	slideCount          int       // Number of slides, which remotes can't navigate past
+	timer               *TimerState
+	timerAt             time.Time // When the timer state was broadcast
+	autoplay            *bool     // State of the last autoplay message; nil if none was sent
+	annotations         annotationState
+	position            int    // Slide the clients show, from position messages; -1 if unknown
+	positionSeq         uint64 // Number of position messages received
+	onPositionChange    PositionCallback
 	mu                  sync.RWMutex
 }
 
@@ -62,6 +135,7 @@ func NewWebSocketHub() *WebSocketHub {
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 		done:       make(chan struct{}),
+		position:   -1,
 	}
 }
 
@@ -92,6 +166,7 @@ func (h *WebSocketHub) Run() {
 				delete(h.clients, client)
 				close(client.send)
 				h.notifyClientCountChange()
+				h.updatePosition()
 			}
 			h.mu.Unlock()
 
@@ -109,9 +184,35 @@ func (h *WebSocketHub) Run() {
 	}
 }
 
-// Stop stops the hub's event loop.
+// Stop stops the hub's event loop, which closes the client connections.
+// It is safe to call more than once.
 func (h *WebSocketHub) Stop() {
-	close(h.done)
+	h.stopOnce.Do(func() {
+		h.mu.Lock()
+		h.stopped = true
+		h.annotations.reset(h.annotations.slide)
+		h.mu.Unlock()
+		close(h.done)
+	})
+}
+
+// Shutdown stops the hub and waits until every client has been sent a close
+// frame, or ctx is done.
+func (h *WebSocketHub) Shutdown(ctx context.Context) error {
+	h.Stop()
+
+	closed := make(chan struct{})
+	go func() {
+		h.pumps.Wait()
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 // SetOnClientCountChange sets a callback to be called when the client count changes.
@@ -139,6 +240,46 @@ func (h *WebSocketHub) Broadcast(msg Message) error {
 		return err
 	}
 
+	// Track the state reported by the server status
+	switch msg.Type {
+	case MessageReload:
+		h.mu.Lock()
+		h.lastReload = time.Now()
+		h.theme = ""
+		h.mu.Unlock()
+	case MessageSlides:
+		h.mu.Lock()
+		h.lastReload = time.Now()
+		h.mu.Unlock()
+	case MessageSlide:
+		h.mu.Lock()
+		h.slide = msg.SlideIndex
+		// Annotations are cleared when the presentation moves to another slide
+		if msg.SlideIndex != h.annotations.slide {
+			h.annotations.reset(msg.SlideIndex)
+		}
+		h.mu.Unlock()
+	case MessageTheme:
+		h.mu.Lock()
+		h.theme = msg.Theme
+		h.mu.Unlock()
+	case MessageTimer:
+		if msg.Timer != nil {
+			state := *msg.Timer
+			h.mu.Lock()
+			h.timer = &state
+			h.timerAt = time.Now()
+			h.mu.Unlock()
+		}
+	case MessageAutoplay:
+		if msg.Autoplay != nil {
+			enabled := *msg.Autoplay
+			h.mu.Lock()
+			h.autoplay = &enabled
+			h.mu.Unlock()
+		}
+	}
+
 	select {
 	case h.broadcast <- data:
 	default:
@@ -153,6 +294,29 @@ func (h *WebSocketHub) BroadcastReload() error {
 	return h.Broadcast(Message{Type: MessageReload})
 }
 
+// BroadcastSlides sends the slides that changed on reload to all clients.
+// The hub follows the slide the presentation is on from slide messages, and
+// tells clients whether that slide is still valid.
+func (h *WebSocketHub) BroadcastSlides(diff slidediff.Diff) error {
+	h.mu.Lock()
+	newIndex, unchanged := diff.Unchanged(h.slide)
+	valid := unchanged && newIndex == h.slide
+	// Annotations follow an unchanged slide and are dropped with a changed one
+	if h.annotations.slide == h.slide {
+		if unchanged {
+			h.annotations.slide = newIndex
+		} else {
+			h.annotations.reset(h.annotations.slide)
+		}
+	}
+	if unchanged {
+		h.slide = newIndex
+	}
+	h.mu.Unlock()
+
+	return h.Broadcast(Message{Type: MessageSlides, Slides: &diff, CurrentSlideStillValid: valid})
+}
+
 // BroadcastSlide sends a slide navigation message to all clients.
 func (h *WebSocketHub) BroadcastSlide(slideIndex int) error {
 	return h.Broadcast(Message{Type: MessageSlide, SlideIndex: slideIndex})
@@ -163,6 +327,44 @@ func (h *WebSocketHub) BroadcastTheme(themeName string) error {
 	return h.Broadcast(Message{Type: MessageTheme, Theme: themeName})
 }
 
+// BroadcastTimer sends the talk timer state to all clients and keeps it for
+// clients that connect later.
+func (h *WebSocketHub) BroadcastTimer(state TimerState) error {
+	return h.Broadcast(Message{Type: MessageTimer, Timer: &state})
+}
+
+// BroadcastAutoplay turns auto-play on or off in all clients and keeps the
+// state for clients that connect later.
+func (h *WebSocketHub) BroadcastAutoplay(enabled bool) error {
+	return h.Broadcast(Message{Type: MessageAutoplay, Autoplay: &enabled})
+}
+
+// Autoplay returns whether auto-play is on, from the last autoplay message.
+// It returns false for ok if none was sent, in which case clients play.
+func (h *WebSocketHub) Autoplay() (enabled, ok bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.autoplay == nil {
+		return false, false
+	}
+	return *h.autoplay, true
+}
+
+// Timer returns the last timer state broadcast, with the elapsed time of a
+// running timer brought up to date. It returns false if there was none.
+func (h *WebSocketHub) Timer() (TimerState, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.timer == nil {
+		return TimerState{}, false
+	}
+	state := *h.timer
+	if state.Running {
+		state.Elapsed += time.Since(h.timerAt).Milliseconds()
+	}
+	return state, true
+}
+
 // ClientCount returns the number of connected clients.
 func (h *WebSocketHub) ClientCount() int {
 	h.mu.RLock()
@@ -170,9 +372,131 @@ func (h *WebSocketHub) ClientCount() int {
 	return len(h.clients)
 }
 
+// DisconnectPresenters sends a revoked message to the presenter view clients
+// and closes their connections, such as after the presenter token changes.
+// Audience clients stay connected.
+func (h *WebSocketHub) DisconnectPresenters() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if client.presenter {
+			client.revokeOnce.Do(func() { close(client.revoke) })
+		}
+	}
+}
+
+// DisconnectRemotes sends a revoked message to the clients of the paired
+// remote with the given ID, or of all remotes for 0, and closes their
+// connections.
+func (h *WebSocketHub) DisconnectRemotes(id int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for client := range h.clients {
+		if client.remote != 0 && (id == 0 || client.remote == id) {
+			client.revokeOnce.Do(func() { close(client.revoke) })
+		}
+	}
+}
+
+// ConnectedRemotes returns the IDs of the paired remotes with a connection.
+func (h *WebSocketHub) ConnectedRemotes() map[int]bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	connected := make(map[int]bool)
+	for client := range h.clients {
+		if client.remote != 0 {
+			connected[client.remote] = true
+		}
+	}
+	return connected
+}
+
+// SetSlideCount sets the number of slides in the presentation, which remotes
+// can't navigate past.
+func (h *WebSocketHub) SetSlideCount(count int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.slideCount = count
+}
+
+// navigate broadcasts the slide that a next, prev, or goto message from a
+// remote leads to, starting from the slide of the last slide message.
+func (h *WebSocketHub) navigate(msg Message) error {
+	h.mu.RLock()
+	index, count := h.slide, h.slideCount
+	h.mu.RUnlock()
+
+	switch msg.Type {
+	case MessageNext:
+		index++
+	case MessagePrev:
+		index--
+	case MessageGoto:
+		index = msg.SlideIndex
+	}
+	if count > 0 {
+		index = min(index, count-1)
+	}
+	return h.BroadcastSlide(max(index, 0))
+}
+
+// ClientCounts returns the number of connected audience, presenter, and
+// remote clients.
+func (h *WebSocketHub) ClientCounts() ClientCounts {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var counts ClientCounts
+	for client := range h.clients {
+		switch {
+		case client.remote != 0:
+			counts.Remote++
+		case client.presenter:
+			counts.Presenter++
+		default:
+			counts.Audience++
+		}
+	}
+	return counts
+}
+
+// LastReload returns when the last reload or slides message was broadcast,
+// or the zero time if none was.
+func (h *WebSocketHub) LastReload() time.Time {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.lastReload
+}
+
+// Theme returns the theme of the last theme message broadcast since the last
+// reload, or "" if there was none.
+func (h *WebSocketHub) Theme() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.theme
+}
+
 // HandleConnection handles a new WebSocket connection.
 // It should be used as an HTTP handler.
 func (h *WebSocketHub) HandleConnection(w http.ResponseWriter, r *http.Request) {
+	h.handleConnection(w, r, false, 0)
+}
+
+// HandlePresenterConnection handles a new WebSocket connection from a
+// presenter view, which DisconnectPresenters disconnects. The caller checks
+// the presenter token.
+func (h *WebSocketHub) HandlePresenterConnection(w http.ResponseWriter, r *http.Request) {
+	h.handleConnection(w, r, true, 0)
+}
+
+// HandleRemoteConnection handles a new WebSocket connection from the paired
+// remote with the given ID, which may send next, prev, and goto messages and
+// which DisconnectRemotes disconnects. The caller checks the remote's token.
+func (h *WebSocketHub) HandleRemoteConnection(w http.ResponseWriter, r *http.Request, id int) {
+	h.handleConnection(w, r, false, id)
+}
+
+// handleConnection accepts a WebSocket connection and runs its client.
+func (h *WebSocketHub) handleConnection(w http.ResponseWriter, r *http.Request, presenter bool, remote int) {
 	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
 		// Allow connections from any origin in dev mode
 		InsecureSkipVerify: true,
@@ -182,12 +506,31 @@ func (h *WebSocketHub) HandleConnection(w http.ResponseWriter, r *http.Request)
 	}
 
 	client := &Client{
-		hub:  h,
-		conn: conn,
-		send: make(chan []byte, 256),
+		hub:       h,
+		conn:      conn,
+		send:      make(chan []byte, 256),
+		revoke:    make(chan struct{}),
+		remote:    remote,
+		presenter: presenter,
 	}
 
-	h.register <- client
+	// Don't accept clients once the hub has stopped
+	h.mu.Lock()
+	if h.stopped {
+		h.mu.Unlock()
+		_ = conn.Close(websocket.StatusGoingAway, "server shutting down")
+		return
+	}
+	h.pumps.Add(1)
+	h.mu.Unlock()
+
+	select {
+	case h.register <- client:
+	case <-h.done:
+		h.pumps.Done()
+		_ = conn.Close(websocket.StatusGoingAway, "server shutting down")
+		return
+	}
 
 	// Send connected message
 	connectedMsg, _ := json.Marshal(Message{Type: MessageConnected})
@@ -196,6 +539,45 @@ func (h *WebSocketHub) HandleConnection(w http.ResponseWriter, r *http.Request)
 	default:
 	}
 
+	// Send the current timer state, so a presenter view that joins late is in sync
+	if state, ok := h.Timer(); ok {
+		timerMsg, _ := json.Marshal(Message{Type: MessageTimer, Timer: &state})
+		select {
+		case client.send <- timerMsg:
+		default:
+		}
+	}
+
+	// Send the auto-play state, so a kiosk screen that reconnects stays paused
+	if enabled, ok := h.Autoplay(); ok {
+		autoplayMsg, _ := json.Marshal(Message{Type: MessageAutoplay, Autoplay: &enabled})
+		select {
+		case client.send <- autoplayMsg:
+		default:
+		}
+	}
+
+	// Tell a remote which slide the presentation is on
+	if remote != 0 {
+		h.mu.RLock()
+		slideMsg, _ := json.Marshal(Message{Type: MessageSlide, SlideIndex: h.slide})
+		h.mu.RUnlock()
+		select {
+		case client.send <- slideMsg:
+		default:
+		}
+	}
+
+	// Replay the strokes on the current slide, so a late joiner sees them
+	if remote == 0 {
+		if replayMsg, ok := h.annotationReplay(); ok {
+			select {
+			case client.send <- replayMsg:
+			default:
+			}
+		}
+	}
+
 	// Use a context that's independent of the HTTP request
 	// The context will be canceled when the hub is stopped
 	ctx, cancel := context.WithCancel(context.Background())
@@ -210,7 +592,11 @@ func (h *WebSocketHub) HandleConnection(w http.ResponseWriter, r *http.Request)
 // This function blocks and runs in the HTTP handler goroutine.
 func (c *Client) readPump(ctx context.Context) {
 	defer func() {
-		c.hub.unregister <- c
+		select {
+		case c.hub.unregister <- c:
+		case <-c.hub.done:
+			// The hub has stopped and closes the client itself
+		}
 		c.conn.Close(websocket.StatusNormalClosure, "")
 	}()
 
@@ -226,11 +612,34 @@ func (c *Client) readPump(ctx context.Context) {
 		if err := json.Unmarshal(data, &msg); err != nil {
 			continue // Ignore invalid JSON
 		}
+		c.handleMessage(msg)
+	}
+}
 
-		// Broadcast slide and theme messages to all clients
-		switch msg.Type {
-		case MessageSlide, MessageTheme:
-			_ = c.hub.Broadcast(msg)
+// handleMessage handles a message from the client. Slide and theme messages
+// are broadcast to all clients, navigation messages from remotes lead to the
+// slide they navigate to, and annotation messages from presenter views are
+// relayed to the audience. Position messages from presenter views and the
+// audience update the slide the clients show. Other messages are ignored.
+func (c *Client) handleMessage(msg Message) {
+	switch msg.Type {
+	case MessageSlide, MessageTheme:
+		_ = c.hub.Broadcast(msg)
+	case MessageNext, MessagePrev, MessageGoto:
+		if c.remote != 0 {
+			_ = c.hub.navigate(msg)
+		}
+	case MessageAnnotate:
+		if c.presenter && msg.Annotations != nil {
+			c.hub.annotate(*msg.Annotations)
+		}
+	case MessageClearAnnotations:
+		if c.presenter {
+			c.hub.clearAnnotations()
+		}
+	case MessagePosition:
+		if c.remote == 0 && msg.SlideIndex >= 0 {
+			c.hub.reportPosition(c, msg.SlideIndex)
 		}
 	}
 }
@@ -241,13 +650,20 @@ func (c *Client) writePump(ctx context.Context, cancel context.CancelFunc) {
 	defer func() {
 		ticker.Stop()
 		cancel()
+		c.hub.pumps.Done()
 	}()
 
 	for {
 		select {
 		case message, ok := <-c.send:
 			if !ok {
-				// Channel closed
+				// Channel closed; tell the browser the server is going away
+				// if the hub was stopped, rather than dropping the connection
+				select {
+				case <-c.hub.done:
+					_ = c.conn.Close(websocket.StatusGoingAway, "server shutting down")
+				default:
+				}
 				return
 			}
 
@@ -258,6 +674,15 @@ func (c *Client) writePump(ctx context.Context, cancel context.CancelFunc) {
 				return
 			}
 
+		case <-c.revoke:
+			// Tell the presenter view or remote why it is disconnected, then close
+			revokedMsg, _ := json.Marshal(Message{Type: MessageRevoked})
+			writeCtx, writeCancel := context.WithTimeout(ctx, 10*time.Second)
+			_ = c.conn.Write(writeCtx, websocket.MessageText, revokedMsg)
+			writeCancel()
+			_ = c.conn.Close(websocket.StatusPolicyViolation, "access revoked")
+			return
+
 		case <-ticker.C:
 			// Send ping to keep connection alive
 			pingCtx, pingCancel := context.WithTimeout(ctx, 10*time.Second)
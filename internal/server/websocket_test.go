@@ -10,6 +10,9 @@ import (
 	"time"
 
 	"github.com/coder/websocket"
+
+	"github.com/MiniCodeMonkey/tap/internal/slidediff"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
 )
 
 func TestNewWebSocketHub(t *testing.T) {
@@ -81,6 +84,8 @@ func TestMessageTypes(t *testing.T) {
 		{MessageConnected, "connected"},
 		{MessageReload, "reload"},
 		{MessageSlide, "slide"},
+		{MessageSlides, "slides"},
+		{MessagePosition, "position"},
 	}
 
 	for _, tt := range tests {
@@ -410,6 +415,54 @@ func TestWebSocketHubClientDisconnect(t *testing.T) {
 	}
 }
 
+func TestWebSocketHubShutdownSendsCloseFrame(t *testing.T) {
+	hub := NewWebSocketHub()
+	go hub.Run()
+
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleConnection))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket.Dial() error = %v", err)
+	}
+	defer conn.CloseNow()
+
+	// Read until the close frame, so the close handshake completes
+	closeStatus := make(chan websocket.StatusCode, 1)
+	go func() {
+		for {
+			if _, _, err := conn.Read(ctx); err != nil {
+				closeStatus <- websocket.CloseStatus(err)
+				return
+			}
+		}
+	}()
+
+	// Give time for registration
+	time.Sleep(50 * time.Millisecond)
+
+	if err := hub.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	select {
+	case status := <-closeStatus:
+		if status != websocket.StatusGoingAway {
+			t.Errorf("close status = %v, want %v", status, websocket.StatusGoingAway)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("client was not closed after Shutdown")
+	}
+
+	// Stopping again is a no-op
+	hub.Stop()
+}
+
 func TestWebSocketHubMultipleConnections(t *testing.T) {
 	hub := NewWebSocketHub()
 	go hub.Run()
@@ -456,3 +509,200 @@ func TestWebSocketHubMultipleConnections(t *testing.T) {
 		t.Errorf("ClientCount() after one disconnect = %d, want 2", hub.ClientCount())
 	}
 }
+
+func TestWebSocketHubTimerState(t *testing.T) {
+	hub := NewWebSocketHub()
+
+	if _, ok := hub.Timer(); ok {
+		t.Error("Timer() should report no state before a timer message")
+	}
+
+	if err := hub.BroadcastTimer(TimerState{Elapsed: 5000, Duration: 1800000}); err != nil {
+		t.Fatalf("BroadcastTimer() error = %v", err)
+	}
+	state, ok := hub.Timer()
+	if !ok || state.Elapsed != 5000 || state.Duration != 1800000 || state.Running {
+		t.Errorf("Timer() = %+v, %v", state, ok)
+	}
+
+	// A running timer's elapsed time keeps advancing
+	if err := hub.BroadcastTimer(TimerState{Elapsed: 5000, Running: true}); err != nil {
+		t.Fatalf("BroadcastTimer() error = %v", err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if state, _ := hub.Timer(); state.Elapsed < 5020 {
+		t.Errorf("Timer().Elapsed = %d, want it to include the time since the broadcast", state.Elapsed)
+	}
+
+	// Reloads don't reset the timer
+	_ = hub.BroadcastReload()
+	if _, ok := hub.Timer(); !ok {
+		t.Error("Timer() should keep the state after a reload")
+	}
+}
+
+func TestWebSocketHubSendsTimerOnConnect(t *testing.T) {
+	hub := NewWebSocketHub()
+	go hub.Run()
+	defer hub.Stop()
+
+	_ = hub.BroadcastTimer(TimerState{Elapsed: 90000, Duration: 1800000, Running: true})
+
+	server := httptest.NewServer(http.HandlerFunc(hub.HandlePresenterConnection))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket.Dial() error = %v", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	// The connected message comes first, then the timer state
+	if _, _, err := conn.Read(ctx); err != nil {
+		t.Fatalf("conn.Read() connected message error = %v", err)
+	}
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("conn.Read() timer message error = %v", err)
+	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if msg.Type != MessageTimer || msg.Timer == nil {
+		t.Fatalf("message = %s, want a timer message", data)
+	}
+	if !msg.Timer.Running || msg.Timer.Elapsed < 90000 || msg.Timer.Duration != 1800000 {
+		t.Errorf("timer = %+v, want the running timer", *msg.Timer)
+	}
+}
+
+func TestWebSocketHubAutoplayState(t *testing.T) {
+	hub := NewWebSocketHub()
+
+	if _, ok := hub.Autoplay(); ok {
+		t.Error("Autoplay() should report no state before an autoplay message")
+	}
+
+	if err := hub.BroadcastAutoplay(false); err != nil {
+		t.Fatalf("BroadcastAutoplay() error = %v", err)
+	}
+	if enabled, ok := hub.Autoplay(); !ok || enabled {
+		t.Errorf("Autoplay() = %v, %v, want false, true", enabled, ok)
+	}
+
+	// The message carries false explicitly rather than omitting it
+	var msg Message
+	if err := json.Unmarshal(<-hub.broadcast, &msg); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if msg.Type != MessageAutoplay || msg.Autoplay == nil || *msg.Autoplay {
+		t.Errorf("message = %+v, want autoplay false", msg)
+	}
+
+	_ = hub.BroadcastAutoplay(true)
+	if enabled, _ := hub.Autoplay(); !enabled {
+		t.Error("Autoplay() = false after enabling it")
+	}
+}
+
+func TestWebSocketHubSendsAutoplayOnConnect(t *testing.T) {
+	hub := NewWebSocketHub()
+	go hub.Run()
+	defer hub.Stop()
+
+	_ = hub.BroadcastAutoplay(false)
+
+	server := httptest.NewServer(http.HandlerFunc(hub.HandleConnection))
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/"
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("websocket.Dial() error = %v", err)
+	}
+	defer conn.Close(websocket.StatusNormalClosure, "")
+
+	// The connected message comes first, then the auto-play state
+	if _, _, err := conn.Read(ctx); err != nil {
+		t.Fatalf("conn.Read() connected message error = %v", err)
+	}
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("conn.Read() autoplay message error = %v", err)
+	}
+
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if msg.Type != MessageAutoplay || msg.Autoplay == nil || *msg.Autoplay {
+		t.Errorf("message = %s, want autoplay false", data)
+	}
+}
+
+func TestWebSocketHubBroadcastSlides(t *testing.T) {
+	hub := NewWebSocketHub()
+
+	presentation := func(htmls ...string) *transformer.TransformedPresentation {
+		pres := &transformer.TransformedPresentation{}
+		for i, html := range htmls {
+			pres.Slides = append(pres.Slides, transformer.TransformedSlide{Index: i, HTML: html})
+		}
+		return pres
+	}
+
+	// readSlides reads the slides message the hub queued for broadcast
+	readSlides := func() Message {
+		t.Helper()
+		var msg Message
+		if err := json.Unmarshal(<-hub.broadcast, &msg); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+		if msg.Type != MessageSlides || msg.Slides == nil {
+			t.Fatalf("message = %+v, want a slides message", msg)
+		}
+		return msg
+	}
+
+	_ = hub.BroadcastSlide(2)
+	<-hub.broadcast
+
+	// Changing a slide before the current one keeps it valid
+	diff := slidediff.Compare(presentation("a", "b", "c"), presentation("A", "b", "c"))
+	if err := hub.BroadcastSlides(diff); err != nil {
+		t.Fatalf("BroadcastSlides() error = %v", err)
+	}
+	msg := readSlides()
+	if !msg.CurrentSlideStillValid {
+		t.Error("expected the current slide to still be valid")
+	}
+	if len(msg.Slides.Changed) != 1 || msg.Slides.Changed[0].Slide.HTML != "A" || msg.Slides.Total != 3 {
+		t.Errorf("slides = %+v, want only the changed slide", msg.Slides)
+	}
+	if hub.LastReload().IsZero() {
+		t.Error("slides messages should count as reloads")
+	}
+
+	// Inserting a slide before the current one moves it
+	diff = slidediff.Compare(presentation("A", "b", "c"), presentation("A", "x", "b", "c"))
+	_ = hub.BroadcastSlides(diff)
+	if msg := readSlides(); msg.CurrentSlideStillValid {
+		t.Error("expected the moved slide to no longer be valid")
+	}
+
+	// The hub follows the moved slide, so editing it invalidates it
+	diff = slidediff.Compare(presentation("A", "x", "b", "c"), presentation("A", "x", "b", "C"))
+	_ = hub.BroadcastSlides(diff)
+	if msg := readSlides(); msg.CurrentSlideStillValid {
+		t.Error("expected the changed slide to no longer be valid")
+	}
+}
@@ -0,0 +1,208 @@
+// Package slidediff compares two versions of a transformed presentation, so
+// the dev server can send clients only the slides that changed on reload.
+package slidediff
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// Change is a slide that was changed or added.
+type Change struct {
+	Slide    transformer.TransformedSlide `json:"slide"`
+	Index    int                          `json:"index"`    // Index in the new presentation
+	OldIndex int                          `json:"oldIndex"` // Index in the old presentation; -1 for added slides
+}
+
+// Diff describes how to turn the slides of the old presentation into those
+// of the new one. Slides that are in neither Changed nor Removed are kept
+// unchanged, in order, at the indices not taken by Changed and Added.
+type Diff struct {
+	Changed []Change               `json:"changed,omitempty"`
+	Added   []Change               `json:"added,omitempty"`
+	Removed []int                  `json:"removed,omitempty"` // Indices in the old presentation
+	TOC     []transformer.TOCEntry `json:"toc,omitempty"`     // Table of contents of the new presentation
+	Total   int                    `json:"total"`             // Number of slides in the new presentation
+	// Lines holds the start and end line of each slide in the new
+	// presentation, which change for unchanged slides when slides before
+	// them grow or shrink
+	Lines [][2]int `json:"lines,omitempty"`
+
+	// kept maps old indices of unchanged slides to their new index, and
+	// the other old indices to -1
+	kept []int
+}
+
+// Empty reports whether no slides changed.
+func (d Diff) Empty() bool {
+	return len(d.Changed) == 0 && len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// Unchanged returns the new index of the slide at oldIndex in the old
+// presentation, if that slide is unchanged.
+func (d Diff) Unchanged(oldIndex int) (int, bool) {
+	if oldIndex < 0 || oldIndex >= len(d.kept) || d.kept[oldIndex] < 0 {
+		return 0, false
+	}
+	return d.kept[oldIndex], true
+}
+
+// Summary describes the diff with one-based slide numbers, such as
+// "Updated slide 4" or "Updated slides 2, 5, added slide 6".
+func (d Diff) Summary() string {
+	var parts []string
+	if len(d.Changed) > 0 {
+		parts = append(parts, describeSlides("updated", changeIndices(d.Changed)))
+	}
+	if len(d.Added) > 0 {
+		parts = append(parts, describeSlides("added", changeIndices(d.Added)))
+	}
+	if len(d.Removed) > 0 {
+		parts = append(parts, describeSlides("removed", d.Removed))
+	}
+	if len(parts) == 0 {
+		return "No slides changed"
+	}
+	summary := strings.Join(parts, ", ")
+	return strings.ToUpper(summary[:1]) + summary[1:]
+}
+
+// changeIndices returns the new indices of changes.
+func changeIndices(changes []Change) []int {
+	indices := make([]int, len(changes))
+	for i, change := range changes {
+		indices[i] = change.Index
+	}
+	return indices
+}
+
+// describeSlides describes an action on slides, such as "updated slide 4".
+func describeSlides(action string, indices []int) string {
+	numbers := make([]string, len(indices))
+	for i, index := range indices {
+		numbers[i] = strconv.Itoa(index + 1)
+	}
+	if len(numbers) == 1 {
+		return action + " slide " + numbers[0]
+	}
+	return action + " slides " + strings.Join(numbers, ", ")
+}
+
+// NeedsFullReload reports whether a change can't be sent as a slide diff,
+// because the config, which includes the frontmatter, or the custom themes
+// changed.
+func NeedsFullReload(old, new *transformer.TransformedPresentation) bool {
+	if old == nil || new == nil {
+		return true
+	}
+	oldConfig, err1 := json.Marshal(old.Config)
+	newConfig, err2 := json.Marshal(new.Config)
+	if err1 != nil || err2 != nil || !bytes.Equal(oldConfig, newConfig) {
+		return true
+	}
+	oldThemes, err1 := json.Marshal(old.Themes)
+	newThemes, err2 := json.Marshal(new.Themes)
+	return err1 != nil || err2 != nil || !bytes.Equal(oldThemes, newThemes)
+}
+
+// Compare diffs the slides of two presentations. Slides are matched by a hash
+// of their content, which includes the HTML but not the index or lines, so a slide
+// inserted in the middle shows up as one added slide and the slides after it
+// keep their content. Between matched slides, old and new slides are paired
+// up as changed, and the rest are added or removed.
+func Compare(old, new *transformer.TransformedPresentation) Diff {
+	oldHashes := hashSlides(old.Slides)
+	newHashes := hashSlides(new.Slides)
+
+	diff := Diff{
+		TOC:   new.TOC,
+		Total: len(new.Slides),
+		Lines: make([][2]int, len(new.Slides)),
+		kept:  make([]int, len(old.Slides)),
+	}
+	for i, slide := range new.Slides {
+		diff.Lines[i] = [2]int{slide.StartLine, slide.EndLine}
+	}
+	for i := range diff.kept {
+		diff.kept[i] = -1
+	}
+
+	// Walk the longest common subsequence of the hashes. The slides between
+	// two matches, or before the first or after the last, form a gap.
+	oldStart, newStart := 0, 0
+	for _, match := range commonSubsequence(oldHashes, newHashes) {
+		diff.addGap(new.Slides, oldStart, match[0], newStart, match[1])
+		diff.kept[match[0]] = match[1]
+		oldStart, newStart = match[0]+1, match[1]+1
+	}
+	diff.addGap(new.Slides, oldStart, len(old.Slides), newStart, len(new.Slides))
+
+	return diff
+}
+
+// addGap records the old slides [oldStart, oldEnd) as replaced by the new
+// slides [newStart, newEnd).
+func (d *Diff) addGap(slides []transformer.TransformedSlide, oldStart, oldEnd, newStart, newEnd int) {
+	for oldStart < oldEnd && newStart < newEnd {
+		d.Changed = append(d.Changed, Change{Slide: slides[newStart], Index: newStart, OldIndex: oldStart})
+		oldStart++
+		newStart++
+	}
+	for ; newStart < newEnd; newStart++ {
+		d.Added = append(d.Added, Change{Slide: slides[newStart], Index: newStart, OldIndex: -1})
+	}
+	for ; oldStart < oldEnd; oldStart++ {
+		d.Removed = append(d.Removed, oldStart)
+	}
+}
+
+// hashSlides returns a hash of the content of each slide, leaving out where
+// the slide is.
+func hashSlides(slides []transformer.TransformedSlide) [][sha256.Size]byte {
+	hashes := make([][sha256.Size]byte, len(slides))
+	for i, slide := range slides {
+		slide.Index, slide.StartLine, slide.EndLine = 0, 0, 0
+		data, _ := json.Marshal(slide) // Slides only hold strings, numbers, and maps of strings
+		hashes[i] = sha256.Sum256(data)
+	}
+	return hashes
+}
+
+// commonSubsequence returns the index pairs of a longest common subsequence
+// of a and b, in order.
+func commonSubsequence(a, b [][sha256.Size]byte) [][2]int {
+	// lengths[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	var matches [][2]int
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			matches = append(matches, [2]int{i, j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return matches
+}
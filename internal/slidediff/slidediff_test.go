@@ -0,0 +1,211 @@
+package slidediff
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// presentation builds a presentation with one slide per HTML string.
+func presentation(htmls ...string) *transformer.TransformedPresentation {
+	pres := &transformer.TransformedPresentation{Config: *config.DefaultConfig()}
+	for i, html := range htmls {
+		pres.Slides = append(pres.Slides, transformer.TransformedSlide{Index: i, Layout: "default", HTML: html})
+	}
+	return pres
+}
+
+// indices returns the old and new indices of changes.
+func indices(changes []Change) [][2]int {
+	var pairs [][2]int
+	for _, change := range changes {
+		if change.Slide.Index != change.Index {
+			panic("change slide index doesn't match its index")
+		}
+		pairs = append(pairs, [2]int{change.OldIndex, change.Index})
+	}
+	return pairs
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name    string
+		old     []string
+		new     []string
+		changed [][2]int
+		added   [][2]int
+		removed []int
+		kept    []int
+	}{
+		{
+			name: "no changes",
+			old:  []string{"a", "b", "c"},
+			new:  []string{"a", "b", "c"},
+			kept: []int{0, 1, 2},
+		},
+		{
+			name:    "changed slide",
+			old:     []string{"a", "b", "c"},
+			new:     []string{"a", "B", "c"},
+			changed: [][2]int{{1, 1}},
+			kept:    []int{0, -1, 2},
+		},
+		{
+			name:  "inserted in the middle",
+			old:   []string{"a", "b", "c", "d"},
+			new:   []string{"a", "b", "x", "c", "d"},
+			added: [][2]int{{-1, 2}},
+			kept:  []int{0, 1, 3, 4},
+		},
+		{
+			name:    "removed from the middle",
+			old:     []string{"a", "b", "c", "d"},
+			new:     []string{"a", "c", "d"},
+			removed: []int{1},
+			kept:    []int{0, -1, 1, 2},
+		},
+		{
+			name:    "inserted and changed",
+			old:     []string{"a", "b", "c", "d"},
+			new:     []string{"x", "a", "b", "C", "d"},
+			changed: [][2]int{{2, 3}},
+			added:   [][2]int{{-1, 0}},
+			kept:    []int{1, 2, -1, 4},
+		},
+		{
+			name:    "split slide",
+			old:     []string{"a", "bc", "d"},
+			new:     []string{"a", "b", "c", "d"},
+			changed: [][2]int{{1, 1}},
+			added:   [][2]int{{-1, 2}},
+			kept:    []int{0, -1, 3},
+		},
+		{
+			name:    "appended and removed at the end",
+			old:     []string{"a", "b"},
+			new:     []string{"a"},
+			removed: []int{1},
+			kept:    []int{0, -1},
+		},
+		{
+			name:  "duplicate slides",
+			old:   []string{"a", "a"},
+			new:   []string{"a", "a", "a"},
+			added: [][2]int{{-1, 2}},
+			kept:  []int{0, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff := Compare(presentation(tt.old...), presentation(tt.new...))
+
+			if got := indices(diff.Changed); !reflect.DeepEqual(got, tt.changed) {
+				t.Errorf("changed = %v, want %v", got, tt.changed)
+			}
+			if got := indices(diff.Added); !reflect.DeepEqual(got, tt.added) {
+				t.Errorf("added = %v, want %v", got, tt.added)
+			}
+			if !reflect.DeepEqual(diff.Removed, tt.removed) {
+				t.Errorf("removed = %v, want %v", diff.Removed, tt.removed)
+			}
+			if diff.Total != len(tt.new) {
+				t.Errorf("total = %d, want %d", diff.Total, len(tt.new))
+			}
+			for oldIndex, want := range tt.kept {
+				got, ok := diff.Unchanged(oldIndex)
+				if want < 0 && ok {
+					t.Errorf("Unchanged(%d) = %d, want changed", oldIndex, got)
+				} else if want >= 0 && (!ok || got != want) {
+					t.Errorf("Unchanged(%d) = %d, %v, want %d", oldIndex, got, ok, want)
+				}
+			}
+			if diff.Empty() != (tt.changed == nil && tt.added == nil && tt.removed == nil) {
+				t.Errorf("Empty() = %v", diff.Empty())
+			}
+		})
+	}
+}
+
+func TestCompare_Payload(t *testing.T) {
+	old := presentation("a", "b")
+	new := presentation("x", "a", "b")
+	new.Slides[0].Notes = "Introduce the topic"
+
+	diff := Compare(old, new)
+	if len(diff.Added) != 1 || diff.Added[0].Slide.Notes != "Introduce the topic" {
+		t.Fatalf("expected the added slide's payload, got %+v", diff.Added)
+	}
+
+	// Slides that only changed in their notes are changed
+	edited := presentation("x", "a", "b")
+	diff = Compare(new, edited)
+	if len(diff.Changed) != 1 || diff.Changed[0].Index != 0 {
+		t.Errorf("expected slide 1 to be changed, got %+v", diff.Changed)
+	}
+}
+
+func TestCompare_Lines(t *testing.T) {
+	old := presentation("a", "b")
+	old.Slides[0].StartLine, old.Slides[0].EndLine = 1, 1
+	old.Slides[1].StartLine, old.Slides[1].EndLine = 5, 7
+
+	// Growing the first slide moves the second without changing it
+	new := presentation("A", "b")
+	new.Slides[0].StartLine, new.Slides[0].EndLine = 1, 3
+	new.Slides[1].StartLine, new.Slides[1].EndLine = 7, 9
+
+	diff := Compare(old, new)
+	if len(diff.Changed) != 1 || diff.Changed[0].Index != 0 {
+		t.Errorf("expected only slide 1 to be changed, got %+v", diff.Changed)
+	}
+	if want := [][2]int{{1, 3}, {7, 9}}; !reflect.DeepEqual(diff.Lines, want) {
+		t.Errorf("Lines = %v, want %v", diff.Lines, want)
+	}
+}
+
+func TestNeedsFullReload(t *testing.T) {
+	old := presentation("a")
+
+	if NeedsFullReload(old, presentation("b")) {
+		t.Error("slide changes should not need a full reload")
+	}
+
+	frontmatter := presentation("a")
+	frontmatter.Config.Theme = "terminal"
+	if !NeedsFullReload(old, frontmatter) {
+		t.Error("config changes should need a full reload")
+	}
+
+	themes := presentation("a")
+	themes.Themes = map[string]string{"brand": "/themes/brand.css"}
+	if !NeedsFullReload(old, themes) {
+		t.Error("custom theme changes should need a full reload")
+	}
+
+	if !NeedsFullReload(nil, old) {
+		t.Error("a missing presentation should need a full reload")
+	}
+}
+
+func TestDiffSummary(t *testing.T) {
+	tests := []struct {
+		old  []string
+		new  []string
+		want string
+	}{
+		{[]string{"a", "b", "c", "d"}, []string{"a", "b", "c", "D"}, "Updated slide 4"},
+		{[]string{"a", "b", "c"}, []string{"A", "b", "C"}, "Updated slides 1, 3"},
+		{[]string{"a", "b", "c"}, []string{"a", "B", "c", "d"}, "Updated slide 2, added slide 4"},
+		{[]string{"a", "b", "c"}, []string{"a"}, "Removed slides 2, 3"},
+		{[]string{"a"}, []string{"a"}, "No slides changed"},
+	}
+
+	for _, tt := range tests {
+		if got := Compare(presentation(tt.old...), presentation(tt.new...)).Summary(); got != tt.want {
+			t.Errorf("Summary() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+// Package stats computes word counts and an estimated speaking time for each
+// slide of a presentation, to check that a talk fits its time slot. Like the
+// notes export, it works on the parsed presentation.
+package stats
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/MiniCodeMonkey/tap/internal/notes"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// DefaultWordsPerMinute is the speaking rate of a typical presenter.
+const DefaultWordsPerMinute = 130
+
+// DefaultSlideOverhead is the time added to each slide for pauses, slide
+// changes, and audience reactions.
+const DefaultSlideOverhead = 15 * time.Second
+
+// Options configures how the speaking time is estimated.
+type Options struct {
+	// WordsPerMinute is the speaking rate. Defaults to DefaultWordsPerMinute.
+	WordsPerMinute int
+	// SlideOverhead is added to the speaking time of each slide.
+	SlideOverhead time.Duration
+	// Duration is the target length of the talk, such as from the duration
+	// option in the frontmatter; 0 if not set.
+	Duration time.Duration
+}
+
+// DefaultOptions returns the default options, without a target duration.
+func DefaultOptions() Options {
+	return Options{
+		WordsPerMinute: DefaultWordsPerMinute,
+		SlideOverhead:  DefaultSlideOverhead,
+	}
+}
+
+// Slide holds the statistics of one slide.
+type Slide struct {
+	Number       int           // One-based slide number
+	Title        string        // Text of the slide's first heading; empty if it has none
+	Words        int           // Words of the visible content, not counting code blocks
+	NoteWords    int           // Words of the speaker notes
+	CodeLines    int           // Lines of code in code blocks
+	SpeakingTime time.Duration // Estimated time to present the slide
+}
+
+// Stats holds the statistics of a presentation. Hidden slides are left out,
+// since they are not presented.
+type Stats struct {
+	Slides       []Slide
+	Words        int
+	NoteWords    int
+	CodeLines    int
+	SpeakingTime time.Duration // Estimated length of the talk
+	Duration     time.Duration // Target length of the talk, from Options; 0 if not set
+}
+
+// OverDuration reports whether the estimated speaking time exceeds the
+// target length of the talk.
+func (s *Stats) OverDuration() bool {
+	return s.Duration > 0 && s.SpeakingTime > s.Duration
+}
+
+// Patterns for reducing slide HTML to the words shown on the slide.
+var (
+	// codeBlockPattern matches code blocks, which are counted as lines instead of words.
+	codeBlockPattern = regexp.MustCompile(`(?is)<pre\b.*?</pre>`)
+	// commentPattern matches HTML comments, such as ai-prompt comments.
+	commentPattern = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// tagPattern matches HTML tags.
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// Compute returns the statistics of pres. The speaking time of a slide is
+// the time to say its visible words and its speaker notes at
+// opts.WordsPerMinute, plus opts.SlideOverhead. Code is not read out, so it
+// doesn't add to the speaking time.
+func Compute(pres *parser.Presentation, opts Options) *Stats {
+	if opts.WordsPerMinute <= 0 {
+		opts.WordsPerMinute = DefaultWordsPerMinute
+	}
+
+	stats := &Stats{Duration: opts.Duration}
+	for i, slide := range pres.Slides {
+		if slide.Directives.Hidden {
+			continue
+		}
+
+		s := Slide{
+			Number:    i + 1,
+			Title:     parser.SlideTitle(slide.HTML),
+			Words:     countWords(visibleText(slide.HTML)),
+			NoteWords: countWords(notes.StripMarkdown(slide.Directives.Notes)),
+			CodeLines: codeLines(slide.CodeBlocks),
+		}
+		s.SpeakingTime = time.Duration(s.Words+s.NoteWords)*time.Minute/time.Duration(opts.WordsPerMinute) + opts.SlideOverhead
+
+		stats.Slides = append(stats.Slides, s)
+		stats.Words += s.Words
+		stats.NoteWords += s.NoteWords
+		stats.CodeLines += s.CodeLines
+		stats.SpeakingTime += s.SpeakingTime
+	}
+	return stats
+}
+
+// visibleText returns the text of slide HTML without code blocks, comments,
+// and tags. Tags are replaced by spaces so adjacent elements stay separate words.
+func visibleText(slideHTML string) string {
+	text := codeBlockPattern.ReplaceAllString(slideHTML, " ")
+	text = commentPattern.ReplaceAllString(text, " ")
+	text = tagPattern.ReplaceAllString(text, " ")
+	return html.UnescapeString(text)
+}
+
+// countWords counts the whitespace-separated words in text. Tokens without
+// letters or digits, such as dashes and bullets, are not words.
+func countWords(text string) int {
+	count := 0
+	for _, field := range strings.Fields(text) {
+		if strings.IndexFunc(field, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			count++
+		}
+	}
+	return count
+}
+
+// codeLines counts the lines of code in code blocks.
+func codeLines(blocks []parser.CodeBlock) int {
+	lines := 0
+	for _, block := range blocks {
+		code := strings.TrimRight(block.Code, "\n")
+		if code != "" {
+			lines += strings.Count(code, "\n") + 1
+		}
+	}
+	return lines
+}
@@ -0,0 +1,136 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+const testDeck = `---
+title: Stats
+---
+
+# Hello World
+
+This talk has exactly nine words on this slide.
+
+---
+
+## Code Demo
+
+Two words
+
+` + "```go\nfunc main() {\n\tfmt.Println(\"not counted as words\")\n}\n```" + `
+
+---
+
+<!-- notes: One two three four five six seven eight nine ten -->
+
+## Notes Only
+
+---
+
+<!-- hidden: true -->
+
+## Hidden slide with words
+
+---
+
+- First item
+- Second — item
+`
+
+func parse(t *testing.T, markdown string) *parser.Presentation {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(markdown))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	return pres
+}
+
+func TestCompute(t *testing.T) {
+	stats := Compute(parse(t, testDeck), Options{WordsPerMinute: 60, SlideOverhead: 10 * time.Second})
+
+	want := []Slide{
+		{Number: 1, Title: "Hello World", Words: 11, SpeakingTime: 21 * time.Second},
+		{Number: 2, Title: "Code Demo", Words: 4, CodeLines: 3, SpeakingTime: 14 * time.Second},
+		{Number: 3, Title: "Notes Only", Words: 2, NoteWords: 10, SpeakingTime: 22 * time.Second},
+		{Number: 5, Words: 4, SpeakingTime: 14 * time.Second},
+	}
+	if len(stats.Slides) != len(want) {
+		t.Fatalf("expected %d slides, got %+v", len(want), stats.Slides)
+	}
+	for i, slide := range stats.Slides {
+		if slide != want[i] {
+			t.Errorf("slide %d = %+v, want %+v", i, slide, want[i])
+		}
+	}
+
+	if stats.Words != 21 || stats.NoteWords != 10 || stats.CodeLines != 3 {
+		t.Errorf("totals = %d words, %d note words, %d code lines", stats.Words, stats.NoteWords, stats.CodeLines)
+	}
+	if stats.SpeakingTime != 71*time.Second {
+		t.Errorf("SpeakingTime = %v, want 1m11s", stats.SpeakingTime)
+	}
+}
+
+func TestCompute_Defaults(t *testing.T) {
+	// 130 words at the default rate take a minute
+	var words []byte
+	for i := 0; i < DefaultWordsPerMinute; i++ {
+		words = append(words, "word "...)
+	}
+	pres := parse(t, string(words))
+
+	if got := Compute(pres, Options{}).SpeakingTime; got != time.Minute {
+		t.Errorf("SpeakingTime without overhead = %v, want 1m", got)
+	}
+	if got := Compute(pres, DefaultOptions()).SpeakingTime; got != time.Minute+DefaultSlideOverhead {
+		t.Errorf("SpeakingTime with default options = %v, want 1m15s", got)
+	}
+}
+
+func TestStats_OverDuration(t *testing.T) {
+	pres := parse(t, testDeck)
+	opts := Options{WordsPerMinute: 60, SlideOverhead: 10 * time.Second}
+
+	if Compute(pres, opts).OverDuration() {
+		t.Error("expected no warning without a duration")
+	}
+
+	opts.Duration = time.Minute
+	if !Compute(pres, opts).OverDuration() {
+		t.Error("expected 1m11s to exceed 1m")
+	}
+
+	opts.Duration = 2 * time.Minute
+	if Compute(pres, opts).OverDuration() {
+		t.Error("expected 1m11s to fit in 2m")
+	}
+}
+
+func TestCountWords(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"one two  three", 3},
+		{"don't stop — 2 go", 4},
+		{"• - –", 0},
+	}
+	for _, tt := range tests {
+		if got := countWords(tt.text); got != tt.want {
+			t.Errorf("countWords(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestVisibleText(t *testing.T) {
+	got := countWords(visibleText(`<h1>Big&nbsp;Title</h1><!-- ai-prompt: a cat --><ul><li>a</li><li>b</li></ul><pre><code>x := 1</code></pre>`))
+	if got != 4 {
+		t.Errorf("expected 4 visible words, got %d", got)
+	}
+}
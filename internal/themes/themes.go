@@ -0,0 +1,185 @@
+// Package themes lists the built-in presentation themes and discovers custom
+// themes in user directories.
+package themes
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DirName is the name of the directory next to a presentation that holds custom themes.
+const DirName = "themes"
+
+// MetadataFile is the name of the optional file describing the themes in a directory.
+const MetadataFile = "theme.yaml"
+
+// Theme represents a presentation theme.
+type Theme struct {
+	Name        string
+	Description string
+	Path        string // CSS file for custom themes; empty for built-in themes
+}
+
+// IsCustom reports whether the theme was discovered from a user directory.
+func (t Theme) IsCustom() bool {
+	return t.Path != ""
+}
+
+// BuiltIn lists the themes bundled with the frontend.
+var BuiltIn = []Theme{
+	{Name: "paper", Description: "Ultra-clean, airy design with premium paper aesthetic"},
+	{Name: "noir", Description: "Cinematic film noir with sophisticated gold accents"},
+	{Name: "aurora", Description: "Vibrant northern lights with glassmorphism effects"},
+	{Name: "phosphor", Description: "Authentic CRT terminal with glowing phosphor green"},
+	{Name: "poster", Description: "Bold graphic design with massive typography"},
+	{Name: "signal", Description: "Vercel/Nuxt-inspired developer aesthetic with neon green accents"},
+	{Name: "carbon", Description: "IBM Carbon design system with sharp corners and red accents"},
+	{Name: "spectrum", Description: "Gradient-forward modern SaaS with indigo-to-pink spectrum"},
+	{Name: "mono", Description: "Ultra-minimal weight-contrast typography with blue accent"},
+	{Name: "flux", Description: "Polished SaaS product feel with warm indigo accents"},
+}
+
+// defaultDescription is used for custom themes without a description in theme.yaml.
+const defaultDescription = "Custom theme"
+
+// namePattern matches valid theme names. Names are used in the theme-<name> CSS class.
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// metadata is a theme entry in theme.yaml.
+type metadata struct {
+	Name        string `yaml:"name"`
+	Description string `yaml:"description"`
+}
+
+// Dir returns the custom themes directory for a presentation in baseDir.
+func Dir(baseDir string) string {
+	return filepath.Join(baseDir, DirName)
+}
+
+// Discover finds custom themes in the given directories. Each .css file is a
+// theme named after the file, e.g. my-brand.css is the "my-brand" theme.
+// Descriptions are read from an optional theme.yaml in the same directory.
+// Directories that don't exist are skipped. When several directories contain
+// a theme with the same name, the later directory wins.
+//
+// A broken custom theme shouldn't stop presentations that don't use it, so
+// problems are returned as warnings instead of errors: files with an invalid
+// theme name are skipped, and themes in a directory whose theme.yaml can't be
+// read keep the default description.
+func Discover(dirs ...string) ([]Theme, []string) {
+	var found []Theme
+	var warnings []string
+	index := make(map[string]int)
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to read themes directory: %v", err))
+			continue
+		}
+
+		descriptions, err := readMetadata(dir)
+		if err != nil {
+			warnings = append(warnings, err.Error())
+		}
+
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if entry.IsDir() || !strings.EqualFold(ext, ".css") {
+				continue
+			}
+
+			name := strings.TrimSuffix(entry.Name(), ext)
+			if !namePattern.MatchString(name) {
+				warnings = append(warnings, fmt.Sprintf("skipped %s: invalid theme name %q, use lowercase letters, digits, and hyphens", filepath.Join(dir, entry.Name()), name))
+				continue
+			}
+
+			theme := Theme{
+				Name:        name,
+				Description: descriptions[name],
+				Path:        filepath.Join(dir, entry.Name()),
+			}
+			if theme.Description == "" {
+				theme.Description = defaultDescription
+			}
+
+			if i, exists := index[name]; exists {
+				found[i] = theme
+				continue
+			}
+			index[name] = len(found)
+			found = append(found, theme)
+		}
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Name < found[j].Name
+	})
+	return found, warnings
+}
+
+// readMetadata reads theme descriptions from theme.yaml in dir, keyed by theme name.
+// The file holds either a single theme or a list of themes.
+func readMetadata(dir string) (map[string]string, error) {
+	path := filepath.Join(dir, MetadataFile)
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var entries []metadata
+	if err := yaml.Unmarshal(content, &entries); err != nil {
+		var single metadata
+		if err := yaml.Unmarshal(content, &single); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		entries = []metadata{single}
+	}
+
+	descriptions := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		if entry.Name != "" {
+			descriptions[entry.Name] = entry.Description
+		}
+	}
+	return descriptions, nil
+}
+
+// Merge combines built-in and custom themes. A custom theme with the same
+// name as a built-in theme replaces it in place; other custom themes are
+// appended. It returns the merged themes and the names of replaced built-in themes.
+func Merge(builtIn, custom []Theme) ([]Theme, []string) {
+	merged := make([]Theme, len(builtIn), len(builtIn)+len(custom))
+	copy(merged, builtIn)
+
+	index := make(map[string]int, len(builtIn))
+	for i, theme := range builtIn {
+		index[theme.Name] = i
+	}
+
+	var overridden []string
+	for _, theme := range custom {
+		if i, exists := index[theme.Name]; exists {
+			merged[i] = theme
+			overridden = append(overridden, theme.Name)
+			continue
+		}
+		merged = append(merged, theme)
+	}
+
+	return merged, overridden
+}
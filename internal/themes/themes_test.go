@@ -0,0 +1,142 @@
+package themes
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name in dir, failing the test on error.
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "my-brand.css", ".theme-my-brand {}")
+	writeFile(t, dir, "alt.CSS", ".theme-alt {}")
+	writeFile(t, dir, "notes.txt", "ignored")
+	writeFile(t, dir, MetadataFile, "name: my-brand\ndescription: Company colors\n")
+
+	found, warnings := Discover(dir)
+	if warnings != nil {
+		t.Fatalf("Discover() warnings = %q", warnings)
+	}
+
+	want := []Theme{
+		{Name: "alt", Description: defaultDescription, Path: filepath.Join(dir, "alt.CSS")},
+		{Name: "my-brand", Description: "Company colors", Path: filepath.Join(dir, "my-brand.css")},
+	}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("Discover() = %+v, want %+v", found, want)
+	}
+	if !found[0].IsCustom() {
+		t.Error("discovered themes should be custom")
+	}
+}
+
+func TestDiscover_MetadataList(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "one.css", "")
+	writeFile(t, dir, "two.css", "")
+	writeFile(t, dir, MetadataFile, "- name: one\n  description: First\n- name: two\n  description: Second\n")
+
+	found, warnings := Discover(dir)
+	if warnings != nil {
+		t.Fatalf("Discover() warnings = %q", warnings)
+	}
+	if len(found) != 2 || found[0].Description != "First" || found[1].Description != "Second" {
+		t.Errorf("unexpected themes: %+v", found)
+	}
+}
+
+func TestDiscover_MissingDirectory(t *testing.T) {
+	found, warnings := Discover(filepath.Join(t.TempDir(), "missing"))
+	if warnings != nil {
+		t.Fatalf("Discover() warnings = %q", warnings)
+	}
+	if len(found) != 0 {
+		t.Errorf("expected no themes, got %+v", found)
+	}
+}
+
+func TestDiscover_LaterDirectoryWins(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	writeFile(t, first, "brand.css", "")
+	writeFile(t, second, "brand.css", "")
+
+	found, warnings := Discover(first, second)
+	if warnings != nil {
+		t.Fatalf("Discover() warnings = %q", warnings)
+	}
+	if len(found) != 1 || found[0].Path != filepath.Join(second, "brand.css") {
+		t.Errorf("expected theme from the later directory, got %+v", found)
+	}
+}
+
+func TestDiscover_Warnings(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    []string
+		warning string
+	}{
+		{"invalid name", map[string]string{"My Brand.css": "", "brand.css": ""}, []string{"brand"}, `invalid theme name "My Brand"`},
+		{"invalid metadata", map[string]string{"brand.css": "", MetadataFile: "name: [unclosed"}, []string{"brand"}, "failed to parse"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, dir, name, content)
+			}
+
+			// The other themes are still found
+			found, warnings := Discover(dir)
+			var names []string
+			for _, theme := range found {
+				names = append(names, theme.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Discover() themes = %q, want %q", names, tt.want)
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0], tt.warning) {
+				t.Errorf("Discover() warnings = %q, want one containing %q", warnings, tt.warning)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	builtIn := []Theme{
+		{Name: "paper", Description: "Paper"},
+		{Name: "noir", Description: "Noir"},
+	}
+	custom := []Theme{
+		{Name: "brand", Description: "Brand", Path: "themes/brand.css"},
+		{Name: "noir", Description: "My noir", Path: "themes/noir.css"},
+	}
+
+	merged, overridden := Merge(builtIn, custom)
+
+	want := []Theme{
+		{Name: "paper", Description: "Paper"},
+		{Name: "noir", Description: "My noir", Path: "themes/noir.css"},
+		{Name: "brand", Description: "Brand", Path: "themes/brand.css"},
+	}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("Merge() = %+v, want %+v", merged, want)
+	}
+	if !reflect.DeepEqual(overridden, []string{"noir"}) {
+		t.Errorf("overridden = %v, want [noir]", overridden)
+	}
+	if builtIn[1].Description != "Noir" {
+		t.Error("Merge() should not modify the built-in themes")
+	}
+}
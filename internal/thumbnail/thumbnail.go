@@ -0,0 +1,259 @@
+// Package thumbnail renders small images of the slides served by the dev
+// server, for the presenter view's filmstrip.
+package thumbnail
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/MiniCodeMonkey/tap/internal/render"
+	"github.com/MiniCodeMonkey/tap/internal/server"
+	"github.com/playwright-community/playwright-go"
+)
+
+const (
+	// DefaultWidth is the width of thumbnails, in pixels, when none is requested.
+	DefaultWidth = 320
+	// MaxWidth is the largest thumbnail width, that of the rendered slide.
+	MaxWidth = 1920
+	// MinWidth is the smallest thumbnail width.
+	MinWidth = 16
+
+	// workers is how many thumbnails are rendered at once, so opening the
+	// filmstrip doesn't open a browser page for every slide
+	workers = 2
+)
+
+// ErrSlideNotFound is returned by Service.Thumbnail for slides the
+// presentation doesn't have.
+var ErrSlideNotFound = errors.New("slide not found")
+
+// renderFunc renders the slide with the given zero-based index as a PNG of
+// the given width.
+type renderFunc func(index, width int, embed bool) ([]byte, error)
+
+// entry is a rendered thumbnail and the slide it was rendered from.
+type entry struct {
+	hash  [sha256.Size]byte
+	width int
+	png   []byte
+}
+
+// call is a thumbnail being rendered, which requests for the same thumbnail wait for.
+type call struct {
+	done chan struct{}
+	png  []byte
+	err  error
+}
+
+// Service renders thumbnails of the slides of a dev server's presentation.
+// Thumbnails are rendered on first request, at most a few at a time, and
+// cached until their slide changes. Its browser is launched on the first
+// render and kept running until Close is called; it is never installed, so
+// without chromium every request fails with render.ErrBrowserUnavailable.
+type Service struct {
+	server  *server.Server
+	browser *render.Browser
+	render  renderFunc    // Renders thumbnails; replaced in tests
+	slots   chan struct{} // Bounds how many thumbnails render at once
+
+	mu          sync.Mutex
+	cache       map[int]entry    // Latest thumbnail of each slide, by index
+	pending     map[string]*call // Thumbnails being rendered, by cacheKey
+	unavailable error            // Why the browser can't be launched, once it failed
+}
+
+// New creates a Service that renders the slides served by srv.
+func New(srv *server.Server) *Service {
+	s := &Service{
+		server:  srv,
+		browser: render.NewBrowser(false),
+		slots:   make(chan struct{}, workers),
+		cache:   make(map[int]entry),
+		pending: make(map[string]*call),
+	}
+	s.render = s.renderThumbnail
+	return s
+}
+
+// Thumbnail returns a PNG of the slide with the given zero-based index,
+// width pixels wide. Cached thumbnails are returned without touching the
+// browser; others are rendered, and cached as long as their slide doesn't
+// change. Rendering continues when ctx is done, so the thumbnail is cached
+// for the next request.
+func (s *Service) Thumbnail(ctx context.Context, index, width int) ([]byte, error) {
+	hash, embed, err := s.slideHash(index)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if cached, ok := s.cache[index]; ok && cached.hash == hash && cached.width == width {
+		s.mu.Unlock()
+		return cached.png, nil
+	}
+	if s.unavailable != nil {
+		err := s.unavailable
+		s.mu.Unlock()
+		return nil, err
+	}
+	key := cacheKey(index, width, hash)
+	c, ok := s.pending[key]
+	if !ok {
+		c = &call{done: make(chan struct{})}
+		s.pending[key] = c
+		go s.generate(c, key, index, width, hash, embed)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.png, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// generate renders a thumbnail once a worker slot is free, and caches it.
+func (s *Service) generate(c *call, key string, index, width int, hash [sha256.Size]byte, embed bool) {
+	s.slots <- struct{}{}
+	c.png, c.err = s.render(index, width, embed)
+	<-s.slots
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, key)
+	switch {
+	case c.err == nil:
+		s.cache[index] = entry{hash: hash, width: width, png: c.png}
+	case errors.Is(c.err, render.ErrBrowserUnavailable):
+		// Don't try to launch the browser for every slide of the filmstrip
+		s.unavailable = c.err
+	}
+	close(c.done)
+}
+
+// cacheKey identifies a thumbnail of a version of a slide.
+func cacheKey(index, width int, hash [sha256.Size]byte) string {
+	return fmt.Sprintf("%d/%d/%x", index, width, hash)
+}
+
+// slideHash returns a hash of everything that changes how the slide with the
+// given zero-based index looks, and whether it is an embed slide.
+func (s *Service) slideHash(index int) ([sha256.Size]byte, bool, error) {
+	pres := s.server.GetPresentation()
+	if pres == nil || index < 0 || index >= len(pres.Slides) {
+		return [sha256.Size]byte{}, false, ErrSlideNotFound
+	}
+
+	// Line numbers change when earlier slides grow or shrink, but don't
+	// change how the slide looks
+	slide := pres.Slides[index]
+	slide.StartLine, slide.EndLine = 0, 0
+	data, err := json.Marshal(struct {
+		Config any `json:"config"`
+		Slide  any `json:"slide"`
+	}{pres.Config, slide})
+	if err != nil {
+		return [sha256.Size]byte{}, false, fmt.Errorf("failed to hash slide: %w", err)
+	}
+	return sha256.Sum256(data), slide.Embed != nil, nil
+}
+
+// renderThumbnail renders a thumbnail in the browser. The slide is laid out
+// at the full 1920x1080 size the PDF export uses and scaled down, so it
+// looks the same as it does in the presentation.
+func (s *Service) renderThumbnail(index, width int, embed bool) ([]byte, error) {
+	browser, err := s.browser.Launch()
+	if err != nil {
+		return nil, err
+	}
+
+	headers := make(map[string]string)
+	if password := s.server.GetAudiencePassword(); password != "" {
+		headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(":"+password))
+	}
+	page, err := browser.NewPage(playwright.BrowserNewPageOptions{
+		Viewport: &playwright.Size{
+			Width:  MaxWidth,
+			Height: 1080,
+		},
+		DeviceScaleFactor: playwright.Float(float64(width) / MaxWidth),
+		ExtraHttpHeaders:  headers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new page: %w", err)
+	}
+	defer page.Close()
+
+	serverURL := fmt.Sprintf("http://127.0.0.1:%d", s.server.Port())
+	if err := render.Slide(page, render.SlideURL(serverURL, index), index, embed); err != nil {
+		return nil, err
+	}
+	data, err := page.Screenshot(playwright.PageScreenshotOptions{
+		Type: playwright.ScreenshotTypePng,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture slide %d: %w", index+1, err)
+	}
+	return data, nil
+}
+
+// Close stops the browser, if it was launched.
+func (s *Service) Close() error {
+	return s.browser.Close()
+}
+
+// HandleThumbnail handles GET /api/thumbnail/{index}?w=320 requests for a
+// PNG thumbnail of the slide with the given zero-based index. It responds
+// 501 Not Implemented when chromium isn't installed.
+func (s *Service) HandleThumbnail(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid slide index %q", r.PathValue("index")))
+		return
+	}
+	width := DefaultWidth
+	if value := r.URL.Query().Get("w"); value != "" {
+		width, err = strconv.Atoi(value)
+		if err != nil || width < MinWidth || width > MaxWidth {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid width %q: must be between %d and %d", value, MinWidth, MaxWidth))
+			return
+		}
+	}
+
+	data, err := s.Thumbnail(r.Context(), index, width)
+	switch {
+	case errors.Is(err, ErrSlideNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	case errors.Is(err, render.ErrBrowserUnavailable):
+		writeError(w, http.StatusNotImplemented, "thumbnails need chromium; run tap pdf once to install it")
+		return
+	case errors.Is(err, context.Canceled):
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	// The slide may change on reload, so always check for a newer thumbnail
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+// writeError writes a JSON error response with the given status.
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
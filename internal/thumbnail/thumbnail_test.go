@@ -0,0 +1,210 @@
+package thumbnail
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/render"
+	"github.com/MiniCodeMonkey/tap/internal/server"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// newTestService returns a service for a server with a presentation of
+// slides slides, whose thumbnails are rendered by renderer.
+func newTestService(t *testing.T, slides int, renderer renderFunc) (*Service, *server.Server) {
+	t.Helper()
+	s := server.New(0)
+	s.SetPresentation(testPresentation(slides, "Slide"))
+	service := New(s)
+	service.render = renderer
+	return service, s
+}
+
+// testPresentation returns a presentation of slides slides with HTML
+// starting with prefix.
+func testPresentation(slides int, prefix string) *transformer.TransformedPresentation {
+	pres := &transformer.TransformedPresentation{Config: *config.DefaultConfig()}
+	for i := 0; i < slides; i++ {
+		pres.Slides = append(pres.Slides, transformer.TransformedSlide{
+			Index: i,
+			HTML:  fmt.Sprintf("<h1>%s %d</h1>", prefix, i+1),
+		})
+	}
+	return pres
+}
+
+// countingRenderer returns a renderer that counts its calls and returns the
+// index and width as the image.
+func countingRenderer(calls *atomic.Int32) renderFunc {
+	return func(index, width int, embed bool) ([]byte, error) {
+		calls.Add(1)
+		return []byte(fmt.Sprintf("%d@%d", index, width)), nil
+	}
+}
+
+// getThumbnail sends a GET /api/thumbnail request for path and returns the
+// response.
+func getThumbnail(s *Service, index, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/thumbnail/"+index+query, nil)
+	req.SetPathValue("index", index)
+	w := httptest.NewRecorder()
+	s.HandleThumbnail(w, req)
+	return w
+}
+
+func TestThumbnail_CachesUntilSlideChanges(t *testing.T) {
+	var calls atomic.Int32
+	service, srv := newTestService(t, 3, countingRenderer(&calls))
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		data, err := service.Thumbnail(ctx, 1, 320)
+		if err != nil {
+			t.Fatalf("Thumbnail() returned error: %v", err)
+		}
+		if string(data) != "1@320" {
+			t.Errorf("Thumbnail() = %q, want %q", data, "1@320")
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected cache hits not to render, got %d renders", got)
+	}
+
+	// Moving the slide's lines doesn't change how it looks
+	pres := testPresentation(3, "Slide")
+	pres.Slides[1].StartLine, pres.Slides[1].EndLine = 10, 20
+	srv.SetPresentation(pres)
+	if _, err := service.Thumbnail(ctx, 1, 320); err != nil {
+		t.Fatalf("Thumbnail() returned error: %v", err)
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected moved lines not to render again, got %d renders", got)
+	}
+
+	srv.SetPresentation(testPresentation(3, "Changed"))
+	if _, err := service.Thumbnail(ctx, 1, 320); err != nil {
+		t.Fatalf("Thumbnail() returned error: %v", err)
+	}
+	if got := calls.Load(); got != 2 {
+		t.Errorf("expected a changed slide to render again, got %d renders", got)
+	}
+
+	data, err := service.Thumbnail(ctx, 1, 160)
+	if err != nil {
+		t.Fatalf("Thumbnail() returned error: %v", err)
+	}
+	if string(data) != "1@160" || calls.Load() != 3 {
+		t.Errorf("expected another width to render again, got %q after %d renders", data, calls.Load())
+	}
+}
+
+func TestThumbnail_BoundsConcurrentRenders(t *testing.T) {
+	var running, maxRunning, calls atomic.Int32
+	release := make(chan struct{})
+	service, _ := newTestService(t, 10, func(index, width int, embed bool) ([]byte, error) {
+		calls.Add(1)
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		return []byte("png"), nil
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			if _, err := service.Thumbnail(context.Background(), index, DefaultWidth); err != nil {
+				t.Errorf("Thumbnail(%d) returned error: %v", index, err)
+			}
+		}(i % 10)
+	}
+
+	// Let the first renders start before releasing them
+	deadline := time.Now().Add(2 * time.Second)
+	for running.Load() < workers && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	if got := maxRunning.Load(); got > workers {
+		t.Errorf("expected at most %d renders at once, got %d", workers, got)
+	}
+	if got := calls.Load(); got != 10 {
+		t.Errorf("expected one render per slide, got %d", got)
+	}
+}
+
+func TestHandleThumbnail(t *testing.T) {
+	var calls atomic.Int32
+	service, _ := newTestService(t, 2, countingRenderer(&calls))
+
+	w := getThumbnail(service, "1", "?w=200")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Type"); got != "image/png" {
+		t.Errorf("Content-Type = %q, want image/png", got)
+	}
+	if w.Body.String() != "1@200" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "1@200")
+	}
+
+	if w := getThumbnail(service, "0", ""); w.Body.String() != fmt.Sprintf("0@%d", DefaultWidth) {
+		t.Errorf("expected the default width, got %q", w.Body.String())
+	}
+
+	tests := []struct {
+		index, query string
+		want         int
+	}{
+		{"2", "", http.StatusNotFound},
+		{"-1", "", http.StatusNotFound},
+		{"first", "", http.StatusBadRequest},
+		{"0", "?w=wide", http.StatusBadRequest},
+		{"0", "?w=4000", http.StatusBadRequest},
+		{"0", "?w=0", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		if w := getThumbnail(service, tt.index, tt.query); w.Code != tt.want {
+			t.Errorf("GET /api/thumbnail/%s%s status = %d, want %d", tt.index, tt.query, w.Code, tt.want)
+		}
+	}
+}
+
+func TestHandleThumbnail_WithoutChromium(t *testing.T) {
+	var calls atomic.Int32
+	service, _ := newTestService(t, 3, func(index, width int, embed bool) ([]byte, error) {
+		calls.Add(1)
+		return nil, fmt.Errorf("%w: executable doesn't exist", render.ErrBrowserUnavailable)
+	})
+
+	for _, index := range []string{"0", "1", "2"} {
+		w := getThumbnail(service, index, "")
+		if w.Code != http.StatusNotImplemented {
+			t.Fatalf("status = %d, want %d", w.Code, http.StatusNotImplemented)
+		}
+		var resp map[string]string
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil || resp["error"] == "" {
+			t.Errorf("expected a JSON error, got %q", w.Body.String())
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Errorf("expected the browser to be tried once, got %d renders", got)
+	}
+}
@@ -0,0 +1,184 @@
+package transformer
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// headingIDPattern matches a heading with an id attribute, as the parser
+// renders markdown headings.
+// Captures: (1) the start tag up to the id value, (2) the id, (3) the rest of
+// the start tag, (4) the heading content
+var headingIDPattern = regexp.MustCompile(`(?s)(<h[1-6]\b[^>]*?\sid=")([^"]*)("[^>]*>)(.*?</h[1-6]>)`)
+
+// idAttributePattern matches the id attribute of any start tag.
+// Captures: (1) the id
+var idAttributePattern = regexp.MustCompile(`<[a-zA-Z][^>]*?\sid="([^"]*)"`)
+
+// anchorLinkPattern matches the start tag of a link to an anchor in the same
+// document, such as <a href="#benchmarks">.
+// Captures: (1) the tag up to the closing ">", (2) the anchor, without "#"
+var anchorLinkPattern = regexp.MustCompile(`(<a\b[^>]*?\shref="#([^"]+)"[^>]*?)>`)
+
+// BrokenAnchor is a link to an anchor that is neither a heading of the
+// presentation nor an element on the same slide.
+type BrokenAnchor struct {
+	Anchor     string
+	SlideIndex int // Index of the slide with the link
+}
+
+// anchorIDs gives the headings of a presentation IDs that are unique in the
+// whole deck. The parser renders each slide on its own, so its heading IDs are
+// only unique within a slide. Each heading gets a slug of its text instead,
+// with a number added if an earlier heading has the same slug: the second
+// "## Benchmarks" becomes "benchmarks-1".
+type anchorIDs struct {
+	slides map[string]int // Slide index by heading ID
+}
+
+func newAnchorIDs() *anchorIDs {
+	return &anchorIDs{slides: make(map[string]int)}
+}
+
+// slide rewrites the heading IDs in one piece of the HTML of the slide at
+// index to be unique in the deck. renames maps the IDs the parser gave the
+// slide's headings to their unique IDs, so a heading that appears in several
+// pieces of the slide, such as its HTML and a fragment, keeps a single ID.
+func (a *anchorIDs) slide(content string, index int, renames map[string]string) string {
+	return headingIDPattern.ReplaceAllStringFunc(content, func(tag string) string {
+		match := headingIDPattern.FindStringSubmatch(tag)
+		id := html.UnescapeString(match[2])
+
+		unique, ok := renames[id]
+		if !ok {
+			slug := headingSlug(match[4])
+			unique = slug
+			for n := 1; a.taken(unique); n++ {
+				unique = slug + "-" + strconv.Itoa(n)
+			}
+			renames[id] = unique
+			a.slides[unique] = index
+		}
+		return match[1] + html.EscapeString(unique) + match[3] + match[4]
+	})
+}
+
+// taken reports whether an earlier heading already has id.
+func (a *anchorIDs) taken(id string) bool {
+	_, ok := a.slides[id]
+	return ok
+}
+
+// headingSlug returns the slug of a heading's HTML content: its text in lower
+// case, with spaces replaced by "-" and characters other than letters,
+// digits, "-", and "_" removed. Letters outside ASCII are kept, so
+// "## Café" can be linked to as "#café".
+func headingSlug(content string) string {
+	text := html.UnescapeString(htmlTagPattern.ReplaceAllString(content, ""))
+
+	var b strings.Builder
+	for _, r := range strings.Join(strings.Fields(text), " ") {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		case r == ' ' || r == '-':
+			b.WriteRune('-')
+		case r == '_':
+			b.WriteRune(r)
+		}
+	}
+	if slug := strings.Trim(b.String(), "-"); slug != "" {
+		return slug
+	}
+	return "heading"
+}
+
+// linkAnchors makes the heading IDs of the slides unique in the deck and
+// marks links to them with a data-goto-slide attribute holding the index of
+// the slide the heading is on, so the frontend can go to that slide when the
+// link is clicked. Links to anything else, including other sites, are left
+// as they are. It returns the slide index of each heading ID.
+func linkAnchors(slides []TransformedSlide) map[string]int {
+	ids := newAnchorIDs()
+	for i := range slides {
+		slide := &slides[i]
+		renames := make(map[string]string)
+		slide.HTML = ids.slide(slide.HTML, slide.Index, renames)
+		for j := range slide.Columns {
+			slide.Columns[j] = ids.slide(slide.Columns[j], slide.Index, renames)
+		}
+		for j := range slide.Fragments {
+			slide.Fragments[j].Content = ids.slide(slide.Fragments[j].Content, slide.Index, renames)
+		}
+	}
+	if len(ids.slides) == 0 {
+		return nil
+	}
+
+	for i := range slides {
+		slide := &slides[i]
+		slide.HTML = markAnchorLinks(slide.HTML, ids.slides)
+		for j := range slide.Columns {
+			slide.Columns[j] = markAnchorLinks(slide.Columns[j], ids.slides)
+		}
+		for j := range slide.Fragments {
+			slide.Fragments[j].Content = markAnchorLinks(slide.Fragments[j].Content, ids.slides)
+		}
+	}
+	return ids.slides
+}
+
+// markAnchorLinks adds a data-goto-slide attribute to the links in content
+// whose anchor is a heading ID in anchors.
+func markAnchorLinks(content string, anchors map[string]int) string {
+	return anchorLinkPattern.ReplaceAllStringFunc(content, func(tag string) string {
+		match := anchorLinkPattern.FindStringSubmatch(tag)
+		index, ok := anchors[anchorTarget(match[2])]
+		if !ok {
+			return tag
+		}
+		return fmt.Sprintf(`%s data-goto-slide="%d">`, match[1], index)
+	})
+}
+
+// anchorTarget returns the ID a link's anchor refers to. The renderer escapes
+// the anchor as a URL, so "#café" is written as "#caf%C3%A9".
+func anchorTarget(anchor string) string {
+	anchor = html.UnescapeString(anchor)
+	if unescaped, err := url.PathUnescape(anchor); err == nil {
+		return unescaped
+	}
+	return anchor
+}
+
+// BrokenAnchors returns the links in pres to anchors that no heading in the
+// presentation has, in slide order. Links to other elements on the same
+// slide, such as footnotes, are not broken.
+func BrokenAnchors(pres *parser.Presentation) []BrokenAnchor {
+	ids := newAnchorIDs()
+	for _, slide := range pres.Slides {
+		ids.slide(slide.HTML, slide.Index, make(map[string]string))
+	}
+
+	var broken []BrokenAnchor
+	for _, slide := range pres.Slides {
+		local := make(map[string]bool)
+		for _, match := range idAttributePattern.FindAllStringSubmatch(slide.HTML, -1) {
+			local[html.UnescapeString(match[1])] = true
+		}
+		for _, match := range anchorLinkPattern.FindAllStringSubmatch(slide.HTML, -1) {
+			target := anchorTarget(match[2])
+			if !ids.taken(target) && !local[target] {
+				broken = append(broken, BrokenAnchor{Anchor: target, SlideIndex: slide.Index})
+			}
+		}
+	}
+	return broken
+}
@@ -0,0 +1,117 @@
+package transformer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func transformSlides(t *testing.T, slides ...string) *TransformedPresentation {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(strings.Join(slides, "\n\n---\n\n")))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return New(config.DefaultConfig()).Transform(pres)
+}
+
+func TestTransformAnchors_DuplicateHeadingsAcrossSlides(t *testing.T) {
+	result := transformSlides(t,
+		"# Intro\n\n[see the benchmarks](#benchmarks) and [the second run](#benchmarks-1)",
+		"## Benchmarks\n\nFirst run",
+		"## Benchmarks\n\nSecond run",
+		"## Benchmarks\n\n### Benchmarks\n\nThird run",
+	)
+
+	want := map[string]int{
+		"intro":        0,
+		"benchmarks":   1,
+		"benchmarks-1": 2,
+		"benchmarks-2": 3,
+		"benchmarks-3": 3,
+	}
+	if !reflect.DeepEqual(result.Anchors, want) {
+		t.Errorf("Anchors = %v, want %v", result.Anchors, want)
+	}
+
+	for i, id := range []string{"benchmarks", "benchmarks-1", "benchmarks-2"} {
+		if html := result.Slides[i+1].HTML; !strings.Contains(html, `<h2 id="`+id+`">`) {
+			t.Errorf("slide %d HTML = %q, want heading id %q", i+1, html, id)
+		}
+	}
+	if html := result.Slides[3].HTML; !strings.Contains(html, `<h3 id="benchmarks-3">`) {
+		t.Errorf("slide 3 HTML = %q, want the h3 to have its own id", html)
+	}
+
+	html := result.Slides[0].HTML
+	for _, link := range []string{
+		`<a href="#benchmarks" data-goto-slide="1">`,
+		`<a href="#benchmarks-1" data-goto-slide="2">`,
+	} {
+		if !strings.Contains(html, link) {
+			t.Errorf("slide 0 HTML = %q, want %s", html, link)
+		}
+	}
+}
+
+func TestTransformAnchors_LeavesOtherLinks(t *testing.T) {
+	result := transformSlides(t,
+		"# Links\n\n[site](https://example.com/#benchmarks) [missing](#missing) [note][^1]\n\n[^1]: A footnote",
+		"## Benchmarks",
+	)
+
+	html := result.Slides[0].HTML
+	if strings.Count(html, "data-goto-slide") != 0 {
+		t.Errorf("HTML = %q, want no data-goto-slide for external, missing, or footnote links", html)
+	}
+	if !strings.Contains(html, `href="https://example.com/#benchmarks"`) {
+		t.Errorf("HTML = %q, want the external link unchanged", html)
+	}
+}
+
+func TestTransformAnchors_NonASCIIHeading(t *testing.T) {
+	result := transformSlides(t, "[menu](#café)", "## Café")
+
+	if _, ok := result.Anchors["café"]; !ok {
+		t.Fatalf("Anchors = %v, want café", result.Anchors)
+	}
+	if html := result.Slides[0].HTML; !strings.Contains(html, `data-goto-slide="1"`) {
+		t.Errorf("HTML = %q, want the escaped link to go to slide 1", html)
+	}
+}
+
+func TestTransformAnchors_NoHeadings(t *testing.T) {
+	result := transformSlides(t, "Just text", "[nowhere](#nowhere)")
+
+	if result.Anchors != nil {
+		t.Errorf("Anchors = %v, want nil", result.Anchors)
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if strings.Contains(string(data), `"anchors"`) {
+		t.Errorf("JSON = %s, want anchors omitted", data)
+	}
+}
+
+func TestBrokenAnchors(t *testing.T) {
+	pres, err := parser.New().Parse([]byte("# Intro\n\n[ok](#benchmarks) [broken](#benchmark)\n\n---\n\n## Benchmarks\n\n[note][^1] [also ok](#benchmarks-1)\n\n[^1]: A footnote\n\n---\n\n## Benchmarks\n\n[gone](#results)"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	got := BrokenAnchors(pres)
+	want := []BrokenAnchor{
+		{Anchor: "benchmark", SlideIndex: 0},
+		{Anchor: "results", SlideIndex: 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BrokenAnchors() = %+v, want %+v", got, want)
+	}
+}
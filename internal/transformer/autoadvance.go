@@ -0,0 +1,48 @@
+package transformer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// SlideAutoAdvance returns how long auto-play shows a slide, from its
+// autoAdvance directive. ok is false if the slide has no autoAdvance directive.
+func SlideAutoAdvance(d parser.SlideDirectives) (duration time.Duration, ok bool, err error) {
+	if d.AutoAdvance == "" {
+		return 0, false, nil
+	}
+	duration, err = config.ParseAutoAdvance(d.AutoAdvance)
+	return duration, true, err
+}
+
+// resolveAutoAdvance returns how long auto-play shows a slide in
+// milliseconds: its autoAdvance directive, or the presentation's autoAdvance
+// if it has none or it is invalid. It returns 0 for slides that wait for the
+// presenter.
+func (t *Transformer) resolveAutoAdvance(d parser.SlideDirectives) int {
+	duration, ok, err := SlideAutoAdvance(d)
+	if !ok || err != nil {
+		duration = t.config.DefaultAutoAdvance()
+	}
+	return int(duration / time.Millisecond)
+}
+
+// AutoAdvanceWarnings returns a warning for each slide of pres with an
+// invalid autoAdvance directive, which falls back to the presentation's.
+func (t *Transformer) AutoAdvanceWarnings(pres *parser.Presentation) []string {
+	fallback := "the slide waits for the presenter"
+	if d := t.config.DefaultAutoAdvance(); d > 0 {
+		fallback = fmt.Sprintf("using %s", d)
+	}
+
+	var warnings []string
+	for i, slide := range pres.Slides {
+		if _, _, err := SlideAutoAdvance(slide.Directives); err != nil {
+			warnings = append(warnings, fmt.Sprintf("slide %d: %v; %s", i+1, err, fallback))
+		}
+	}
+	return warnings
+}
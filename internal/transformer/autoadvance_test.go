@@ -0,0 +1,118 @@
+package transformer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func parseAutoAdvanceDeck(t *testing.T, slides ...string) *parser.Presentation {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(strings.Join(slides, "\n\n---\n\n")))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	return pres
+}
+
+func TestTransformAutoAdvance_RoundTrip(t *testing.T) {
+	pres := parseAutoAdvanceDeck(t,
+		"<!-- autoAdvance: 8s -->\n# Welcome",
+		"<!-- autoAdvance: 12 -->\n# Menu",
+		"# Waits",
+	)
+
+	result := New(config.DefaultConfig()).Transform(pres)
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var decoded struct {
+		Config struct {
+			Loop bool `json:"loop"`
+		} `json:"config"`
+		Slides []map[string]any `json:"slides"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := []any{float64(8000), float64(12000), nil}
+	for i, slide := range decoded.Slides {
+		if got := slide["autoAdvance"]; got != want[i] {
+			t.Errorf("slide %d: autoAdvance = %v, want %v", i+1, got, want[i])
+		}
+	}
+	if decoded.Config.Loop {
+		t.Error("config loop = true, want it left out")
+	}
+	if strings.Contains(string(data), `"autoAdvance":0`) {
+		t.Errorf("JSON should leave out autoAdvance for slides that wait, got %s", data)
+	}
+}
+
+func TestTransformAutoAdvance_Default(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.AutoAdvance = "5s"
+	cfg.Loop = true
+
+	pres := parseAutoAdvanceDeck(t,
+		"# Uses the default",
+		"<!-- autoAdvance: 1m -->\n# Overrides it",
+		"<!-- autoAdvance: 0 -->\n# Invalid",
+	)
+	result := New(cfg).Transform(pres)
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	var decoded TransformedPresentation
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	want := []int{5000, 60000, 5000}
+	for i, slide := range decoded.Slides {
+		if slide.AutoAdvance != want[i] {
+			t.Errorf("slide %d: AutoAdvance = %d, want %d", i+1, slide.AutoAdvance, want[i])
+		}
+	}
+	if !decoded.Config.Loop {
+		t.Error("config loop = false after the round trip, want true")
+	}
+
+	// The presentation-wide default isn't sent as an option of its own
+	if strings.Contains(string(data), `"autoAdvance":"5s"`) {
+		t.Errorf("JSON should not contain the raw autoAdvance option, got %s", data)
+	}
+}
+
+func TestAutoAdvanceWarnings(t *testing.T) {
+	pres := parseAutoAdvanceDeck(t,
+		"<!-- autoAdvance: 8s -->\n# Fine",
+		"<!-- autoAdvance: -2s -->\n# Negative",
+		"<!-- autoAdvance: soon -->\n# Not a length",
+	)
+
+	warnings := New(config.DefaultConfig()).AutoAdvanceWarnings(pres)
+	if len(warnings) != 2 {
+		t.Fatalf("AutoAdvanceWarnings() = %q, want 2 warnings", warnings)
+	}
+	if !strings.Contains(warnings[0], "slide 2") || !strings.Contains(warnings[0], "waits for the presenter") {
+		t.Errorf("warning = %q, want slide 2 waiting for the presenter", warnings[0])
+	}
+	if !strings.Contains(warnings[1], "slide 3") || !strings.Contains(warnings[1], `"soon"`) {
+		t.Errorf("warning = %q, want slide 3", warnings[1])
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.AutoAdvance = "10s"
+	if warnings := New(cfg).AutoAdvanceWarnings(pres); len(warnings) != 2 || !strings.Contains(warnings[0], "using 10s") {
+		t.Errorf("AutoAdvanceWarnings() = %q, want the fallback to the presentation's autoAdvance", warnings)
+	}
+}
@@ -0,0 +1,63 @@
+package transformer
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// Cast is a terminal recording in a cast block, played with the asciinema
+// player. The recording is either a .cast file at Src or asciinema v2 data
+// written in the block itself.
+type Cast struct {
+	Src      string  `json:"src,omitempty"`  // URL of the .cast file; /local/ for relative paths, as for images
+	Data     string  `json:"data,omitempty"` // Inline asciinema v2 recording
+	Autoplay bool    `json:"autoplay,omitempty"`
+	Loop     bool    `json:"loop,omitempty"`
+	StartAt  float64 `json:"startAt,omitempty"` // Seconds into the recording to start at, and the frame the PDF export shows
+}
+
+// castFromBlock returns the recording of a cast block. A block whose content
+// starts with "{" is an inline recording, whose first line is the asciinema
+// header; otherwise its first line is the path or URL of a .cast file.
+func (t *Transformer) castFromBlock(block parser.CodeBlock) *Cast {
+	cast := &Cast{
+		Autoplay: block.Meta.Autoplay,
+		Loop:     block.Meta.Loop,
+		StartAt:  block.Meta.StartAt,
+	}
+
+	code := strings.TrimSpace(block.Code)
+	if strings.HasPrefix(code, "{") {
+		cast.Data = code + "\n"
+		return cast
+	}
+
+	src, _, _ := strings.Cut(code, "\n")
+	cast.Src = t.resolveCastPath(strings.Trim(strings.TrimSpace(src), `"'`))
+	return cast
+}
+
+// resolveCastPath resolves the path of a .cast file like resolveVideoPath:
+// relative paths become /local/ URLs for the dev server.
+func (t *Transformer) resolveCastPath(path string) string {
+	if path == "" || isAbsoluteURL(path) || filepath.IsAbs(path) || t.baseDir == "" {
+		return path
+	}
+	return localURL(path)
+}
+
+// UsesCastPlayer reports whether any slide of pres plays a terminal recording,
+// in a cast or asciinema block, so builds only include the asciinema player
+// when it is needed.
+func UsesCastPlayer(pres *TransformedPresentation) bool {
+	for _, slide := range pres.Slides {
+		for _, block := range slide.CodeBlocks {
+			if block.Render == RenderCast || strings.EqualFold(block.Language, "asciinema") {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package transformer
+
+import (
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// transformCast returns the transformed cast block of a slide with the
+// markdown content, transformed with baseDir.
+func transformCast(t *testing.T, content, baseDir string) TransformedCodeBlock {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("failed to parse: %v", err)
+	}
+	result := NewWithBaseDir(config.DefaultConfig(), baseDir).Transform(pres)
+	if len(result.Slides) != 1 || len(result.Slides[0].CodeBlocks) != 1 {
+		t.Fatalf("expected 1 slide with 1 code block, got %+v", result.Slides)
+	}
+	return result.Slides[0].CodeBlocks[0]
+}
+
+func TestTransformCastFile(t *testing.T) {
+	block := transformCast(t, "# Demo\n\n```cast {autoplay: true, loop: true, start: 12}\n./recordings/demo.cast\n```\n", "/talk")
+
+	if block.Render != RenderCast || block.Cast == nil {
+		t.Fatalf("expected a cast block, got %+v", block)
+	}
+	want := Cast{Src: "/local/recordings/demo.cast", Autoplay: true, Loop: true, StartAt: 12}
+	if *block.Cast != want {
+		t.Errorf("expected %+v, got %+v", want, *block.Cast)
+	}
+}
+
+func TestTransformCastInline(t *testing.T) {
+	recording := `{"version": 2, "width": 80, "height": 24}` + "\n" + `[0.5, "o", "$ ls\r\n"]`
+	block := transformCast(t, "# Demo\n\n```cast\n"+recording+"\n```\n", "/talk")
+
+	if block.Cast == nil || block.Cast.Src != "" {
+		t.Fatalf("expected an inline recording, got %+v", block.Cast)
+	}
+	if block.Cast.Data != recording+"\n" {
+		t.Errorf("expected the block content as the recording, got %q", block.Cast.Data)
+	}
+}
+
+func TestResolveCastPath(t *testing.T) {
+	tr := NewWithBaseDir(config.DefaultConfig(), "/talk")
+	tests := map[string]string{
+		"demo.cast":                     "/local/demo.cast",
+		"./casts/../demo.cast":          "/local/demo.cast",
+		"https://example.com/demo.cast": "https://example.com/demo.cast",
+		"/home/me/recordings/demo.cast": "/home/me/recordings/demo.cast",
+		"":                              "",
+	}
+	for path, want := range tests {
+		if got := tr.resolveCastPath(path); got != want {
+			t.Errorf("resolveCastPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestUsesCastPlayer(t *testing.T) {
+	pres := func(language string) *TransformedPresentation {
+		return New(config.DefaultConfig()).Transform(&parser.Presentation{
+			Slides: []parser.Slide{{CodeBlocks: []parser.CodeBlock{{Language: language, Code: "demo.cast"}}}},
+		})
+	}
+
+	for language, want := range map[string]bool{"cast": true, "asciinema": true, "go": false} {
+		if got := UsesCastPlayer(pres(language)); got != want {
+			t.Errorf("UsesCastPlayer with a %s block = %v, want %v", language, got, want)
+		}
+	}
+	if block := pres("go").Slides[0].CodeBlocks[0]; block.Cast != nil {
+		t.Errorf("expected no recording for other blocks, got %+v", block.Cast)
+	}
+}
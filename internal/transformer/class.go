@@ -0,0 +1,51 @@
+package transformer
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// classNamePattern matches the class names a slide's class directive may
+// add to its wrapper. Other names are dropped and reported by ClassWarnings.
+var classNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// SplitClasses splits a class directive on whitespace and commas. It returns
+// the valid class names, without duplicates, and the invalid ones.
+func SplitClasses(value string) (classes, invalid []string) {
+	names := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	for _, name := range names {
+		switch {
+		case !classNamePattern.MatchString(name):
+			invalid = append(invalid, name)
+		case !slices.Contains(classes, name):
+			classes = append(classes, name)
+		}
+	}
+	return classes, invalid
+}
+
+// resolveClasses returns the valid classes of a slide's class directive, or
+// nil if it has none.
+func resolveClasses(slide parser.Slide) []string {
+	classes, _ := SplitClasses(slide.Directives.Class)
+	return classes
+}
+
+// ClassWarnings returns a warning for each invalid class name in the class
+// directives of pres, which is left out of the slide's classes.
+func (t *Transformer) ClassWarnings(pres *parser.Presentation) []string {
+	var warnings []string
+	for i, slide := range pres.Slides {
+		_, invalid := SplitClasses(slide.Directives.Class)
+		for _, name := range invalid {
+			warnings = append(warnings, fmt.Sprintf("slide %d: invalid class %q; class names may only contain letters, digits, - and _", i+1, name))
+		}
+	}
+	return warnings
+}
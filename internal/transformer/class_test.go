@@ -0,0 +1,115 @@
+package transformer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func TestSplitClasses(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		wantClasses []string
+		wantInvalid []string
+	}{
+		{name: "empty", value: ""},
+		{name: "single", value: "invert", wantClasses: []string{"invert"}},
+		{name: "whitespace", value: "invert  center\tlarge", wantClasses: []string{"invert", "center", "large"}},
+		{name: "commas", value: "invert, center,large", wantClasses: []string{"invert", "center", "large"}},
+		{name: "underscores and digits", value: "col_2 theme-v2", wantClasses: []string{"col_2", "theme-v2"}},
+		{name: "duplicates", value: "invert invert", wantClasses: []string{"invert"}},
+		{
+			name:        "invalid characters",
+			value:       `invert "><script> a.b c:d center`,
+			wantClasses: []string{"invert", "center"},
+			wantInvalid: []string{`"><script>`, "a.b", "c:d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			classes, invalid := SplitClasses(tt.value)
+			if !reflect.DeepEqual(classes, tt.wantClasses) {
+				t.Errorf("classes = %q, want %q", classes, tt.wantClasses)
+			}
+			if !reflect.DeepEqual(invalid, tt.wantInvalid) {
+				t.Errorf("invalid = %q, want %q", invalid, tt.wantInvalid)
+			}
+		})
+	}
+}
+
+func TestTransformClasses(t *testing.T) {
+	pres, err := parser.New().Parse([]byte(strings.Join([]string{
+		"<!-- class: invert center -->\n# Title",
+		"<!--\nlayout: two-column\nclass: [wide, dark]\ntransition: fade\n-->\nLeft\n\n|||\n\nRight",
+		"<!-- class: ok bad.name -->\nSome text",
+		"# Plain",
+	}, "\n\n---\n\n")))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	tr := New(config.DefaultConfig())
+	result := tr.Transform(pres)
+
+	want := [][]string{{"invert", "center"}, {"wide", "dark"}, {"ok"}, nil}
+	for i, slide := range result.Slides {
+		if !reflect.DeepEqual(slide.Classes, want[i]) {
+			t.Errorf("slide %d: Classes = %q, want %q", i+1, slide.Classes, want[i])
+		}
+	}
+
+	// Other directives in the same comment still apply
+	if got := result.Slides[1]; got.Layout != "two-column" || got.Transition != "fade" {
+		t.Errorf("slide 2: Layout = %q, Transition = %q, want two-column and fade", got.Layout, got.Transition)
+	}
+
+	// Invalid names are reported
+	warnings := tr.ClassWarnings(pres)
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "slide 3") || !strings.Contains(warnings[0], `"bad.name"`) {
+		t.Errorf("ClassWarnings() = %q, want one warning for bad.name on slide 3", warnings)
+	}
+
+	// Classes are in the JSON, and left out without any
+	data, err := json.Marshal(result.Slides[0])
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `"classes":["invert","center"]`) {
+		t.Errorf("JSON = %s, want classes", data)
+	}
+	data, _ = json.Marshal(result.Slides[3])
+	if strings.Contains(string(data), `"classes"`) {
+		t.Errorf("JSON = %s, want no classes", data)
+	}
+}
+
+func TestClassesDoNotAffectLayoutDetection(t *testing.T) {
+	tr := New(config.DefaultConfig())
+	for _, content := range []string{
+		"# Title",
+		"## Section",
+		"# Heading\n\n- One\n- Two",
+		"> A quote\n>\n> — Someone",
+		"42%",
+	} {
+		plain, err := parser.New().Parse([]byte(content))
+		if err != nil {
+			t.Fatalf("Parse() returned error: %v", err)
+		}
+		classed, err := parser.New().Parse([]byte("<!-- class: invert center -->\n" + content))
+		if err != nil {
+			t.Fatalf("Parse() returned error: %v", err)
+		}
+
+		want := tr.Transform(plain).Slides[0].Layout
+		if got := tr.Transform(classed).Slides[0].Layout; got != want {
+			t.Errorf("%q: Layout with classes = %q, want %q", content, got, want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package transformer
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// EmbedConfig is the page an embed slide shows in an iframe.
+type EmbedConfig struct {
+	URL             string   `json:"url"`
+	Height          string   `json:"height,omitempty"` // CSS height, such as "90%"; empty for the full slide
+	AllowFullscreen bool     `json:"allowFullscreen,omitempty"`
+	Sandbox         []string `json:"sandbox"` // Sandbox flags of the iframe; empty applies every restriction
+}
+
+// unitlessHeightPattern matches embed heights without a unit, which are in
+// pixels.
+var unitlessHeightPattern = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// resolveEmbed returns the embed of a slide, or nil if it has none or it is
+// invalid. Invalid embeds are reported by EmbedWarnings.
+func resolveEmbed(slide parser.Slide) *EmbedConfig {
+	if slide.Embed == nil || slide.Embed.Validate() != nil {
+		return nil
+	}
+	height := slide.Embed.Height
+	if unitlessHeightPattern.MatchString(height) {
+		height += "px"
+	}
+	return &EmbedConfig{
+		URL:             slide.Embed.URL,
+		Height:          height,
+		AllowFullscreen: slide.Embed.AllowFullscreen,
+		Sandbox:         append([]string{}, slide.Embed.Sandbox...),
+	}
+}
+
+// EmbedWarnings returns a warning for each slide of pres with an invalid
+// embed, which is left out of the slide.
+func (t *Transformer) EmbedWarnings(pres *parser.Presentation) []string {
+	var warnings []string
+	for i, slide := range pres.Slides {
+		if slide.Embed == nil {
+			continue
+		}
+		if err := slide.Embed.Validate(); err != nil {
+			warnings = append(warnings, fmt.Sprintf("slide %d: %v; the embed is not shown", i+1, err))
+		}
+	}
+	return warnings
+}
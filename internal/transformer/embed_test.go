@@ -0,0 +1,58 @@
+package transformer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func TestTransformEmbed(t *testing.T) {
+	pres, err := parser.New().Parse([]byte(strings.Join([]string{
+		"<!-- layout: title -->\n<!-- embed: https://localhost:5173 height: 600 allowFullscreen: true -->\n# Demo",
+		"<!-- embed: javascript:alert(1) -->\n# Invalid",
+		"# Plain",
+	}, "\n\n---\n\n")))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	tr := New(config.DefaultConfig())
+	result := tr.Transform(pres)
+
+	embed := result.Slides[0]
+	if embed.Layout != "embed" {
+		t.Errorf("Layout = %q, want %q", embed.Layout, "embed")
+	}
+	want := &EmbedConfig{
+		URL:             "https://localhost:5173",
+		Height:          "600px",
+		AllowFullscreen: true,
+		Sandbox:         parser.DefaultEmbedSandbox,
+	}
+	if !reflect.DeepEqual(embed.Embed, want) {
+		t.Errorf("Embed = %+v, want %+v", embed.Embed, want)
+	}
+
+	// Invalid embeds are left out, and reported
+	if invalid := result.Slides[1]; invalid.Embed != nil || invalid.Layout == "embed" {
+		t.Errorf("expected the invalid embed to be left out, got layout %q and %+v", invalid.Layout, invalid.Embed)
+	}
+	warnings := tr.EmbedWarnings(pres)
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "slide 2: embed url") {
+		t.Errorf("EmbedWarnings() = %v, want a warning for slide 2", warnings)
+	}
+
+	data, err := json.Marshal(result.Slides)
+	if err != nil {
+		t.Fatalf("failed to marshal slides: %v", err)
+	}
+	if !strings.Contains(string(data), `"embed":{"url":"https://localhost:5173","height":"600px","allowFullscreen":true,"sandbox":["allow-scripts","allow-same-origin","allow-forms"]}`) {
+		t.Errorf("expected the embed in the JSON, got %s", data)
+	}
+	if strings.Count(string(data), `"embed":`) != 1 {
+		t.Errorf("expected the embed to be omitted from other slides, got %s", data)
+	}
+}
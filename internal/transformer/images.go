@@ -0,0 +1,139 @@
+package transformer
+
+import (
+	"fmt"
+	"image"
+	_ "image/gif"  // Register the GIF decoder for image.DecodeConfig
+	_ "image/jpeg" // Register the JPEG decoder for image.DecodeConfig
+	_ "image/png"  // Register the PNG decoder for image.DecodeConfig
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	_ "golang.org/x/image/webp" // Register the WebP decoder for image.DecodeConfig
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// ImageInfo holds the pixel dimensions of a local image on a slide, which the
+// frontend and PDF exporter use to reserve its space before it loads.
+type ImageInfo struct {
+	Src    string `json:"src"` // Resolved /local/ URL, as in the slide HTML
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// ImageHint is how an image on a slide is fitted into its space, from an img
+// comment after the image. The slide HTML already carries it as the image's
+// data-fit attribute and styles, which themes apply.
+type ImageHint struct {
+	Src      string `json:"src"`                // Resolved URL, as in the slide HTML
+	Fit      string `json:"fit,omitempty"`      // "cover", "contain", or "original"
+	Position string `json:"position,omitempty"` // CSS object-position, such as "top"
+	Width    string `json:"width,omitempty"`    // CSS width, such as "60%"
+}
+
+// imgTagPattern matches an img tag.
+var imgTagPattern = regexp.MustCompile(`<img\s[^>]*>`)
+
+// imgAttrPattern captures the src attribute value of an img tag.
+var imgAttrPattern = regexp.MustCompile(`\ssrc=["']([^"']+)["']`)
+
+// imgSizeAttrPattern matches width or height attributes of an img tag.
+var imgSizeAttrPattern = regexp.MustCompile(`\s(?:width|height)=`)
+
+// addImageDimensions adds width and height attributes to the local images in
+// html and returns their dimensions. Images that already have a width or
+// height attribute keep it. Remote images, and images that can't be read or
+// decoded, such as SVGs, are skipped.
+func (t *Transformer) addImageDimensions(html string) (string, []ImageInfo) {
+	if t.baseDir == "" {
+		return html, nil
+	}
+
+	var images []ImageInfo
+	html = imgTagPattern.ReplaceAllStringFunc(html, func(tag string) string {
+		match := imgAttrPattern.FindStringSubmatch(tag)
+		if match == nil {
+			return tag
+		}
+		src := match[1]
+		size, ok := t.imageSize(src)
+		if !ok {
+			return tag
+		}
+		images = append(images, ImageInfo{Src: src, Width: size.X, Height: size.Y})
+
+		if imgSizeAttrPattern.MatchString(tag) {
+			return tag
+		}
+		return fmt.Sprintf(`<img width="%d" height="%d"`, size.X, size.Y) + strings.TrimPrefix(tag, "<img")
+	})
+	return html, images
+}
+
+// resolveImageHints returns the image hints of a slide with their image URLs
+// resolved like the slide HTML's.
+func (t *Transformer) resolveImageHints(hints []parser.ImageHint) []ImageHint {
+	if len(hints) == 0 {
+		return nil
+	}
+	resolved := make([]ImageHint, len(hints))
+	for i, hint := range hints {
+		resolved[i] = ImageHint{
+			Src:      t.resolveImagePath(hint.Src),
+			Fit:      hint.Fit,
+			Position: hint.Position,
+			Width:    hint.Width,
+		}
+	}
+	return resolved
+}
+
+// imageSize returns the pixel dimensions of the local image that a /local/
+// URL points to. Results are memoized for the current Transform call, since
+// the same images, such as logos, often appear on many slides.
+func (t *Transformer) imageSize(src string) (image.Point, bool) {
+	rel, ok := strings.CutPrefix(src, "/local/")
+	if !ok {
+		return image.Point{}, false
+	}
+	if unescaped, err := url.PathUnescape(rel); err == nil {
+		rel = unescaped
+	}
+	// The dev server doesn't serve files outside the base directory either
+	if strings.Contains(rel, "..") {
+		return image.Point{}, false
+	}
+
+	path := filepath.Join(t.baseDir, filepath.FromSlash(rel))
+	if size, ok := t.imageSizes[path]; ok {
+		return size, size != image.Point{}
+	}
+
+	size := decodeImageSize(path)
+	if t.imageSizes == nil {
+		t.imageSizes = make(map[string]image.Point)
+	}
+	t.imageSizes[path] = size
+	return size, size != image.Point{}
+}
+
+// decodeImageSize reads the dimensions from the header of a PNG, JPEG, GIF,
+// or WebP file without decoding the whole image. It returns a zero point if
+// the file can't be read or isn't a supported image.
+func decodeImageSize(path string) image.Point {
+	f, err := os.Open(path)
+	if err != nil {
+		return image.Point{}
+	}
+	defer f.Close()
+
+	cfg, _, err := image.DecodeConfig(f)
+	if err != nil {
+		return image.Point{}
+	}
+	return image.Point{X: cfg.Width, Y: cfg.Height}
+}
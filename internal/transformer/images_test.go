@@ -0,0 +1,196 @@
+package transformer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// writeTestImage writes an image of the given size and format to dir/name.
+func writeTestImage(t *testing.T, dir, name string, width, height int) {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	var buf bytes.Buffer
+	var err error
+	switch filepath.Ext(name) {
+	case ".png":
+		err = png.Encode(&buf, img)
+	case ".jpg":
+		err = jpeg.Encode(&buf, img, nil)
+	case ".gif":
+		err = gif.Encode(&buf, img, nil)
+	case ".webp":
+		buf.Write(webpHeader(width, height))
+	}
+	if err != nil {
+		t.Fatalf("failed to encode %s: %v", name, err)
+	}
+
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// webpHeader returns the start of a lossless WebP file, which is all
+// image.DecodeConfig reads.
+func webpHeader(width, height int) []byte {
+	bits := uint32(width-1) | uint32(height-1)<<14
+	chunk := append([]byte{0x2f}, binary.LittleEndian.AppendUint32(nil, bits)...)
+
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	b.Write(binary.LittleEndian.AppendUint32(nil, uint32(4+8+len(chunk)+1)))
+	b.WriteString("WEBPVP8L")
+	b.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(chunk))))
+	b.Write(chunk)
+	b.WriteByte(0) // Padding to an even chunk size
+	return b.Bytes()
+}
+
+func TestTransformImageDimensions(t *testing.T) {
+	dir := t.TempDir()
+	writeTestImage(t, dir, "images/photo.png", 640, 480)
+	writeTestImage(t, dir, "images/photo.jpg", 320, 200)
+	writeTestImage(t, dir, "anim.gif", 16, 8)
+	writeTestImage(t, dir, "hero.webp", 1200, 630)
+	writeTestImage(t, dir, "my logo.png", 50, 20)
+
+	tests := []struct {
+		name     string
+		html     string
+		wantHTML string
+		want     []ImageInfo
+	}{
+		{
+			name:     "png",
+			html:     `<p><img src="images/photo.png" alt="Photo"></p>`,
+			wantHTML: `<p><img width="640" height="480" src="/local/images/photo.png" alt="Photo"></p>`,
+			want:     []ImageInfo{{Src: "/local/images/photo.png", Width: 640, Height: 480}},
+		},
+		{
+			name:     "jpeg, gif, and webp",
+			html:     `<p><img src="images/photo.jpg" alt="a"><img src="anim.gif" alt="b"><img src="hero.webp" alt="c"></p>`,
+			wantHTML: `<p><img width="320" height="200" src="/local/images/photo.jpg" alt="a"><img width="16" height="8" src="/local/anim.gif" alt="b"><img width="1200" height="630" src="/local/hero.webp" alt="c"></p>`,
+			want: []ImageInfo{
+				{Src: "/local/images/photo.jpg", Width: 320, Height: 200},
+				{Src: "/local/anim.gif", Width: 16, Height: 8},
+				{Src: "/local/hero.webp", Width: 1200, Height: 630},
+			},
+		},
+		{
+			name:     "escaped path",
+			html:     `<p><img src="my%20logo.png" alt="Logo"></p>`,
+			wantHTML: `<p><img width="50" height="20" src="/local/my%20logo.png" alt="Logo"></p>`,
+			want:     []ImageInfo{{Src: "/local/my%20logo.png", Width: 50, Height: 20}},
+		},
+		{
+			name:     "existing size attribute is kept",
+			html:     `<p><img src="images/photo.png" width="100"></p>`,
+			wantHTML: `<p><img src="/local/images/photo.png" width="100"></p>`,
+			want:     []ImageInfo{{Src: "/local/images/photo.png", Width: 640, Height: 480}},
+		},
+		{
+			name:     "remote, missing, and svg images are skipped",
+			html:     `<p><img src="https://example.com/a.png"><img src="missing.png"><img src="diagram.svg"></p>`,
+			wantHTML: `<p><img src="https://example.com/a.png"><img src="/local/missing.png"><img src="/local/diagram.svg"></p>`,
+		},
+		{
+			name:     "paths outside the base directory are skipped",
+			html:     `<p><img src="../photo.png"></p>`,
+			wantHTML: `<p><img src="/local/../photo.png"></p>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tr := NewWithBaseDir(config.DefaultConfig(), dir)
+			result := tr.Transform(&parser.Presentation{Slides: []parser.Slide{{HTML: tt.html}}})
+
+			slide := result.Slides[0]
+			if slide.HTML != tt.wantHTML {
+				t.Errorf("HTML = %q, want %q", slide.HTML, tt.wantHTML)
+			}
+			if !reflect.DeepEqual(slide.Images, tt.want) {
+				t.Errorf("Images = %+v, want %+v", slide.Images, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransformImageDimensionsWithoutBaseDir(t *testing.T) {
+	result := New(config.DefaultConfig()).Transform(&parser.Presentation{
+		Slides: []parser.Slide{{HTML: `<p><img src="photo.png"></p>`}},
+	})
+
+	if strings.Contains(result.Slides[0].HTML, "width=") || result.Slides[0].Images != nil {
+		t.Errorf("expected no dimensions without a base directory, got %+v", result.Slides[0])
+	}
+}
+
+func TestTransformImageDimensionsMemoized(t *testing.T) {
+	dir := t.TempDir()
+	writeTestImage(t, dir, "logo.png", 40, 40)
+
+	tr := NewWithBaseDir(config.DefaultConfig(), dir)
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: `<p><img src="logo.png"></p>`},
+			{Index: 1, HTML: `<p><img src="logo.png"><img src="./logo.png"></p>`},
+		},
+	}
+
+	result := tr.Transform(pres)
+	if len(tr.imageSizes) != 1 {
+		t.Errorf("expected one memoized image, got %v", tr.imageSizes)
+	}
+	if len(result.Slides[1].Images) != 2 {
+		t.Errorf("expected both images on the second slide, got %+v", result.Slides[1].Images)
+	}
+
+	// A later Transform call reads the image again
+	writeTestImage(t, dir, "logo.png", 80, 60)
+	result = tr.Transform(pres)
+	if got := result.Slides[0].Images; len(got) != 1 || got[0].Width != 80 || got[0].Height != 60 {
+		t.Errorf("expected the changed image's dimensions, got %+v", got)
+	}
+}
+
+func TestTransformImageHints(t *testing.T) {
+	tr := NewWithBaseDir(config.DefaultConfig(), t.TempDir())
+	result := tr.Transform(&parser.Presentation{
+		Slides: []parser.Slide{{
+			HTML: `<p><img src="images/cat.png" data-fit="cover" style="object-position: top"></p>`,
+			ImageHints: []parser.ImageHint{
+				{Src: "images/cat.png", Fit: "cover", Position: "top"},
+				{Src: "https://example.com/dog.png", Width: "60%"},
+			},
+		}},
+	})
+
+	want := []ImageHint{
+		{Src: "/local/images/cat.png", Fit: "cover", Position: "top"},
+		{Src: "https://example.com/dog.png", Width: "60%"},
+	}
+	if got := result.Slides[0].ImageHints; !reflect.DeepEqual(got, want) {
+		t.Errorf("ImageHints = %+v, want %+v", got, want)
+	}
+	if !strings.Contains(result.Slides[0].HTML, `data-fit="cover"`) {
+		t.Errorf("expected the fit to be kept in the HTML, got %q", result.Slides[0].HTML)
+	}
+}
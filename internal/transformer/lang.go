@@ -0,0 +1,155 @@
+package transformer
+
+import (
+	"slices"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// Languages returns the languages of pres: declared, the languages option,
+// if it is set, or else the languages of its :::lang blocks and lang
+// directives, in the order they first appear. The first is the default.
+func Languages(pres *parser.Presentation, declared []string) []string {
+	if len(declared) > 0 {
+		return declared
+	}
+	var languages []string
+	for _, slide := range pres.Slides {
+		for _, lang := range SlideLanguages(slide) {
+			if !slices.Contains(languages, lang) {
+				languages = append(languages, lang)
+			}
+		}
+	}
+	return languages
+}
+
+// slideLanguages returns the languages a slide is written in: its lang
+// directive and the languages of its :::lang blocks.
+func SlideLanguages(slide parser.Slide) []string {
+	var languages []string
+	if slide.Directives.Lang != "" {
+		languages = append(languages, slide.Directives.Lang)
+	}
+	for _, variant := range slide.Variants {
+		if !slices.Contains(languages, variant.Lang) {
+			languages = append(languages, variant.Lang)
+		}
+	}
+	return languages
+}
+
+// languageGroups splits slides into groups of slide indices. Consecutive
+// slides with lang directives in different languages are a group, such as
+// the English and Danish versions of a slide, of which one is shown. Other
+// slides are a group of their own.
+func languageGroups(slides []parser.Slide) [][]int {
+	var groups [][]int
+	for i := 0; i < len(slides); {
+		group := []int{i}
+		if lang := slides[i].Directives.Lang; lang != "" {
+			seen := []string{lang}
+			for j := i + 1; j < len(slides); j++ {
+				next := slides[j].Directives.Lang
+				if next == "" || slices.Contains(seen, next) {
+					break
+				}
+				seen = append(seen, next)
+				group = append(group, j)
+			}
+		}
+		groups = append(groups, group)
+		i += len(group)
+	}
+	return groups
+}
+
+// SelectLanguage returns pres with the slides in the language the
+// configuration selects, so the rest of the pipeline sees a deck in one
+// language. Of a group of slides with lang directives, the one in the
+// language is kept, and of a slide's :::lang variants, the one for the
+// language. Slides without one fall back to the default language, and then
+// to their first language. Slides in no particular language are kept.
+func (t *Transformer) SelectLanguage(pres *parser.Presentation) *parser.Presentation {
+	languages := Languages(pres, t.config.Languages)
+	if len(languages) == 0 {
+		return pres
+	}
+	lang := t.config.Lang
+	if lang == "" {
+		lang = languages[0]
+	}
+
+	selected := &parser.Presentation{
+		Slides:   make([]parser.Slide, 0, len(pres.Slides)),
+		Includes: pres.Includes,
+	}
+	for _, group := range languageGroups(pres.Slides) {
+		slide := pres.Slides[pickLanguage(group, lang, languages[0], func(i int) string {
+			return pres.Slides[i].Directives.Lang
+		})]
+
+		if len(slide.Variants) > 0 {
+			variant := pickLanguage(slide.Variants, lang, languages[0], func(v parser.SlideVariant) string {
+				return v.Lang
+			})
+			slide = variant.Slide
+		}
+
+		// The slide is no longer a variant, so selecting again keeps it
+		slide.Directives.Lang = ""
+		slide.Index = len(selected.Slides)
+		selected.Slides = append(selected.Slides, slide)
+	}
+	return selected
+}
+
+// pickLanguage returns the option in lang, or else the one in fallback, or
+// else the first.
+func pickLanguage[T any](options []T, lang, fallback string, langOf func(T) string) T {
+	for _, want := range []string{lang, fallback} {
+		for _, option := range options {
+			if langOf(option) == want {
+				return option
+			}
+		}
+	}
+	return options[0]
+}
+
+// MissingTranslation is a slide that isn't written in some of the languages
+// of its presentation.
+type MissingTranslation struct {
+	// SlideIndex is the zero-based index of the slide, or of the first slide
+	// of a group of slides with lang directives.
+	SlideIndex int
+	// Languages are the languages the slide isn't written in.
+	Languages []string
+}
+
+// MissingTranslations returns the slides of pres that have :::lang blocks or
+// lang directives, but not in every one of languages. Slides without them
+// are the same in every language.
+func MissingTranslations(pres *parser.Presentation, languages []string) []MissingTranslation {
+	var missing []MissingTranslation
+	for _, group := range languageGroups(pres.Slides) {
+		var written []string
+		for _, i := range group {
+			written = append(written, SlideLanguages(pres.Slides[i])...)
+		}
+		if len(written) == 0 {
+			continue
+		}
+
+		var lacking []string
+		for _, lang := range languages {
+			if !slices.Contains(written, lang) {
+				lacking = append(lacking, lang)
+			}
+		}
+		if len(lacking) > 0 {
+			missing = append(missing, MissingTranslation{SlideIndex: group[0], Languages: lacking})
+		}
+	}
+	return missing
+}
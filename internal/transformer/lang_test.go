@@ -0,0 +1,151 @@
+package transformer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// langTestDeck has a slide in English and Danish, one in English only, a
+// slide in two languages by lang directives, and a slide in no language.
+const langTestDeck = "# Hello\n\n:::lang en\nWelcome\n:::\n\n:::lang da\nVelkommen\n:::\n\n---\n\n" +
+	"# Agenda\n\n:::lang en\nToday\n:::\n\n---\n\n" +
+	"<!-- lang: en -->\n\n# Thanks\n\n---\n\n<!-- lang: da -->\n\n# Tak\n\n---\n\n" +
+	"# Code\n"
+
+// parseLangDeck parses content, failing the test on error.
+func parseLangDeck(t *testing.T, content string) *parser.Presentation {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return pres
+}
+
+// slideHTML returns the HTML of each slide of pres.
+func slideHTML(pres *parser.Presentation) []string {
+	html := make([]string, len(pres.Slides))
+	for i, slide := range pres.Slides {
+		html[i] = slide.HTML
+	}
+	return html
+}
+
+func TestLanguages(t *testing.T) {
+	pres := parseLangDeck(t, langTestDeck)
+	if got, want := Languages(pres, nil), []string{"en", "da"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Languages() = %q, want %q", got, want)
+	}
+	if got, want := Languages(pres, []string{"da", "en"}), []string{"da", "en"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Languages() with declared languages = %q, want %q", got, want)
+	}
+	if got := Languages(parseLangDeck(t, "# One\n"), nil); got != nil {
+		t.Errorf("Languages() without languages = %q, want nil", got)
+	}
+}
+
+func TestSelectLanguage(t *testing.T) {
+	tests := []struct {
+		name      string
+		languages []string
+		lang      string
+		want      []string // A phrase from each slide
+		wantNot   []string
+	}{
+		{
+			name: "default is the first language",
+			want: []string{"Welcome", "Today", "Thanks", "Code"},
+		},
+		{
+			name:    "selected language with fallback",
+			lang:    "da",
+			want:    []string{"Velkommen", "Today", "Tak", "Code"},
+			wantNot: []string{"Welcome", "Thanks"},
+		},
+		{
+			name:      "declared default language",
+			languages: []string{"da", "en"},
+			want:      []string{"Velkommen", "Today", "Tak", "Code"},
+		},
+		{
+			name:      "missing language falls back to the default",
+			languages: []string{"da", "en", "de"},
+			lang:      "de",
+			want:      []string{"Velkommen", "Today", "Tak", "Code"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Languages = tt.languages
+			cfg.Lang = tt.lang
+			trans := New(cfg)
+
+			selected := trans.SelectLanguage(parseLangDeck(t, langTestDeck))
+			html := slideHTML(selected)
+			if len(html) != len(tt.want) {
+				t.Fatalf("got %d slides, want %d", len(html), len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(html[i], want) {
+					t.Errorf("slide %d HTML = %q, want %q", i, html[i], want)
+				}
+				if selected.Slides[i].Index != i {
+					t.Errorf("slide %d Index = %d", i, selected.Slides[i].Index)
+				}
+			}
+			all := strings.Join(html, "\n")
+			for _, unwanted := range tt.wantNot {
+				if strings.Contains(all, unwanted) {
+					t.Errorf("selected slides contain %q", unwanted)
+				}
+			}
+
+			// Selecting again keeps the slides
+			again := slideHTML(trans.SelectLanguage(selected))
+			if !reflect.DeepEqual(again, html) {
+				t.Errorf("selecting again gave %q, want %q", again, html)
+			}
+		})
+	}
+}
+
+func TestSelectLanguage_NoLanguages(t *testing.T) {
+	pres := parseLangDeck(t, "# One\n\n---\n\n# Two\n")
+	if got := New(config.DefaultConfig()).SelectLanguage(pres); got != pres {
+		t.Error("SelectLanguage() copied a presentation without languages")
+	}
+}
+
+func TestLanguageGroups(t *testing.T) {
+	slides := []parser.Slide{
+		{Directives: parser.SlideDirectives{Lang: "en"}},
+		{Directives: parser.SlideDirectives{Lang: "da"}},
+		{},
+		{Directives: parser.SlideDirectives{Lang: "en"}},
+		{Directives: parser.SlideDirectives{Lang: "en"}},
+		{Directives: parser.SlideDirectives{Lang: "da"}},
+	}
+	want := [][]int{{0, 1}, {2}, {3}, {4, 5}}
+	if got := languageGroups(slides); !reflect.DeepEqual(got, want) {
+		t.Errorf("languageGroups() = %v, want %v", got, want)
+	}
+}
+
+func TestMissingTranslations(t *testing.T) {
+	pres := parseLangDeck(t, langTestDeck)
+	got := MissingTranslations(pres, []string{"en", "da", "de"})
+	want := []MissingTranslation{
+		{SlideIndex: 0, Languages: []string{"de"}},
+		{SlideIndex: 1, Languages: []string{"da", "de"}},
+		{SlideIndex: 2, Languages: []string{"de"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingTranslations() = %+v, want %+v", got, want)
+	}
+}
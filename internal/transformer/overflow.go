@@ -0,0 +1,108 @@
+package transformer
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Weights of the content score, in characters of text. A slide's score
+// approximates how much room its content takes up at the theme's font size.
+const (
+	blockElementScore = 40  // Margins and line breaks around a paragraph, list item, heading, etc.
+	codeLineScore     = 30  // A line of code in a code block
+	imageScore        = 300 // An image, which usually takes up a large part of the slide
+)
+
+// minFontScale is the smallest suggested font scale for an overflowing slide.
+// Content that needs more shrinking than this should be split instead.
+const minFontScale = 0.5
+
+// overflowThresholds are the default content scores above which a slide is
+// estimated to overflow, per aspect ratio. Narrower slides wrap text sooner.
+var overflowThresholds = map[string]int{
+	"16:9":  1000,
+	"16:10": 1100,
+	"4:3":   850,
+}
+
+// preBlockPattern matches code blocks in rendered slide HTML.
+var preBlockPattern = regexp.MustCompile(`(?s)<pre[\s>].*?</pre>`)
+
+// blockElementPattern matches the opening tags of block elements that take up
+// their own lines on a slide.
+var blockElementPattern = regexp.MustCompile(`(?i)<(?:p|li|h[1-6]|blockquote|tr|dt|dd|hr)[\s>/]`)
+
+// imageTagPattern matches img tags in rendered slide HTML.
+var imageTagPattern = regexp.MustCompile(`(?i)<img[\s/>]`)
+
+// contentScore estimates how much room the content of slide HTML takes up,
+// from its text length, block elements, code lines, and images.
+func contentScore(slideHTML string) int {
+	score := 0
+
+	// Code lines are counted per line rather than per character
+	for _, block := range preBlockPattern.FindAllString(slideHTML, -1) {
+		code := strings.TrimRight(htmlTagPattern.ReplaceAllString(block, ""), "\n")
+		score += (strings.Count(code, "\n") + 1) * codeLineScore
+	}
+	rest := preBlockPattern.ReplaceAllString(slideHTML, "")
+
+	score += len(blockElementPattern.FindAllString(rest, -1)) * blockElementScore
+	score += len(imageTagPattern.FindAllString(rest, -1)) * imageScore
+
+	text := html.UnescapeString(htmlTagPattern.ReplaceAllString(rest, " "))
+	score += len([]rune(strings.Join(strings.Fields(text), " ")))
+
+	return score
+}
+
+// overflowThreshold returns the content score above which a slide is
+// estimated to overflow. It is 0 if the overflow check is disabled.
+func (t *Transformer) overflowThreshold() int {
+	if t.config.OverflowThreshold < 0 {
+		return 0
+	}
+	if t.config.OverflowThreshold > 0 {
+		return t.config.OverflowThreshold
+	}
+	if threshold, ok := overflowThresholds[t.config.AspectRatio]; ok {
+		return threshold
+	}
+	return overflowThresholds["16:9"]
+}
+
+// fontScale returns the suggested font scale for content with the given score
+// to fit under threshold. Content takes up area, so the scale is the square
+// root of the ratio, rounded down to two decimals and at least minFontScale.
+func fontScale(score, threshold int) float64 {
+	scale := math.Floor(math.Sqrt(float64(threshold)/float64(score))*100) / 100
+	return math.Max(scale, minFontScale)
+}
+
+// estimateOverflow sets the content score of a slide, and whether it is
+// estimated to overflow with a font scale that would make it fit.
+// Scroll slides are meant to be taller than the screen and never overflow.
+func (t *Transformer) estimateOverflow(slide *TransformedSlide) {
+	slide.ContentScore = contentScore(slide.HTML)
+
+	threshold := t.overflowThreshold()
+	if threshold == 0 || slide.Scroll || slide.ContentScore <= threshold {
+		return
+	}
+	slide.Overflow = true
+	slide.FontScale = fontScale(slide.ContentScore, threshold)
+}
+
+// OverflowingSlides returns the 1-based numbers of the slides estimated to
+// overflow, for reporting them in build output.
+func OverflowingSlides(pres *TransformedPresentation) []int {
+	var numbers []int
+	for i, slide := range pres.Slides {
+		if slide.Overflow {
+			numbers = append(numbers, i+1)
+		}
+	}
+	return numbers
+}
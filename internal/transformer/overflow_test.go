@@ -0,0 +1,174 @@
+package transformer
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// overflowSlides are representative slides of increasing size, in markdown.
+var overflowSlides = func() map[string]string {
+	var medium, huge, code strings.Builder
+	medium.WriteString("## Agenda\n\n")
+	for i := 1; i <= 5; i++ {
+		fmt.Fprintf(&medium, "- Point %d about the architecture and how it evolved\n", i)
+	}
+
+	huge.WriteString("## Everything\n\n")
+	for i := 1; i <= 30; i++ {
+		fmt.Fprintf(&huge, "- Point %d explains one more detail that really should be on a slide of its own\n", i)
+	}
+
+	code.WriteString("## Code\n\n```go\n")
+	for i := 1; i <= 40; i++ {
+		fmt.Fprintf(&code, "fmt.Println(%d)\n", i)
+	}
+	code.WriteString("```\n")
+
+	return map[string]string{
+		"small":  "# Hello\n\nA short subtitle",
+		"medium": medium.String(),
+		"huge":   huge.String(),
+		"code":   code.String(),
+	}
+}()
+
+// transformMarkdown parses markdown slides and transforms them with cfg.
+func transformMarkdown(t *testing.T, cfg *config.Config, slides ...string) *TransformedPresentation {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(strings.Join(slides, "\n\n---\n\n")))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return New(cfg).Transform(pres)
+}
+
+func TestEstimateOverflow(t *testing.T) {
+	tests := []struct {
+		name         string
+		wantOverflow bool
+	}{
+		{"small", false},
+		{"medium", false},
+		{"huge", true},
+		{"code", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slide := transformMarkdown(t, config.DefaultConfig(), overflowSlides[tt.name]).Slides[0]
+
+			if slide.ContentScore <= 0 {
+				t.Errorf("ContentScore = %d, want a positive score", slide.ContentScore)
+			}
+			if slide.Overflow != tt.wantOverflow {
+				t.Errorf("Overflow = %v, want %v (score %d)", slide.Overflow, tt.wantOverflow, slide.ContentScore)
+			}
+			if tt.wantOverflow && (slide.FontScale < minFontScale || slide.FontScale >= 1) {
+				t.Errorf("FontScale = %v, want a scale in [%v, 1)", slide.FontScale, minFontScale)
+			}
+			if !tt.wantOverflow && slide.FontScale != 0 {
+				t.Errorf("FontScale = %v, want 0 for a slide that fits", slide.FontScale)
+			}
+		})
+	}
+}
+
+func TestContentScoreOrdering(t *testing.T) {
+	small := contentScore(`<h1>Hello</h1>`)
+	withImage := contentScore(`<h1>Hello</h1><p><img src="a.png" alt=""></p>`)
+	if withImage <= small+imageScore {
+		t.Errorf("image should add at least %d to the score: %d vs %d", imageScore, withImage, small)
+	}
+
+	code := contentScore("<pre><code>a\nb\nc\n</code></pre>")
+	if code != 3*codeLineScore {
+		t.Errorf("contentScore(3 code lines) = %d, want %d", code, 3*codeLineScore)
+	}
+
+	text := contentScore("<p>Tom &amp; Jerry</p>")
+	if want := blockElementScore + len("Tom & Jerry"); text != want {
+		t.Errorf("contentScore(paragraph) = %d, want %d", text, want)
+	}
+}
+
+func TestOverflowThresholdPerAspectRatio(t *testing.T) {
+	// A slide that fits on a 16:9 slide but not on a narrower 4:3 one
+	html := "<p>" + strings.Repeat("x", 900) + "</p>"
+	pres := &parser.Presentation{Slides: []parser.Slide{{HTML: html}}}
+
+	wide := config.DefaultConfig()
+	if New(wide).Transform(pres).Slides[0].Overflow {
+		t.Error("slide should fit at 16:9")
+	}
+
+	narrow := config.DefaultConfig()
+	narrow.AspectRatio = "4:3"
+	if !New(narrow).Transform(pres).Slides[0].Overflow {
+		t.Error("slide should overflow at 4:3")
+	}
+}
+
+func TestOverflowThresholdConfig(t *testing.T) {
+	huge := overflowSlides["huge"]
+
+	cfg := config.DefaultConfig()
+	cfg.OverflowThreshold = 100000
+	if transformMarkdown(t, cfg, huge).Slides[0].Overflow {
+		t.Error("slide should fit with a raised threshold")
+	}
+
+	cfg.OverflowThreshold = 50
+	if !transformMarkdown(t, cfg, overflowSlides["medium"]).Slides[0].Overflow {
+		t.Error("slide should overflow with a lowered threshold")
+	}
+
+	cfg.OverflowThreshold = -1
+	slide := transformMarkdown(t, cfg, huge).Slides[0]
+	if slide.Overflow || slide.FontScale != 0 {
+		t.Error("a negative threshold should disable the overflow check")
+	}
+	if slide.ContentScore == 0 {
+		t.Error("the content score should still be set when the check is disabled")
+	}
+}
+
+func TestOverflowScrollSlide(t *testing.T) {
+	slide := transformMarkdown(t, config.DefaultConfig(), "<!--\nscroll: true\n-->\n\n"+overflowSlides["huge"]).Slides[0]
+	if !slide.Scroll {
+		t.Fatal("slide should have scroll enabled")
+	}
+	if slide.Overflow {
+		t.Error("scroll slides should never be reported as overflowing")
+	}
+}
+
+func TestFontScale(t *testing.T) {
+	tests := []struct {
+		score, threshold int
+		want             float64
+	}{
+		{1210, 1000, 0.9},
+		{2000, 1000, 0.7},
+		{4000, 1000, 0.5},
+		{100000, 1000, minFontScale},
+	}
+	for _, tt := range tests {
+		if got := fontScale(tt.score, tt.threshold); got != tt.want {
+			t.Errorf("fontScale(%d, %d) = %v, want %v", tt.score, tt.threshold, got, tt.want)
+		}
+	}
+}
+
+func TestOverflowingSlides(t *testing.T) {
+	pres := transformMarkdown(t, config.DefaultConfig(),
+		overflowSlides["small"], overflowSlides["huge"], overflowSlides["medium"], overflowSlides["code"])
+
+	if got := OverflowingSlides(pres); !reflect.DeepEqual(got, []int{2, 4}) {
+		t.Errorf("OverflowingSlides() = %v, want [2 4]", got)
+	}
+}
@@ -0,0 +1,217 @@
+package transformer
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// removedElements are the elements removed from slide HTML with their
+// content, unless raw HTML is allowed. Besides elements that run scripts or
+// load other documents, these are elements whose content browsers may parse
+// differently than the tokenizer, such as SVG and MathML.
+var removedElements = map[string]bool{
+	"script": true, "iframe": true, "frame": true, "frameset": true,
+	"object": true, "embed": true, "applet": true, "portal": true,
+	"meta": true, "base": true, "link": true,
+	"noscript": true, "noembed": true, "noframes": true,
+	"template": true, "textarea": true, "title": true, "xmp": true, "plaintext": true,
+	"svg": true, "math": true,
+}
+
+// allowedElements are the elements kept in slide HTML. The tags of other
+// elements, such as form, are removed, and their content is kept.
+var allowedElements = map[string]bool{
+	"a": true, "abbr": true, "address": true, "article": true, "aside": true,
+	"audio": true, "b": true, "bdi": true, "bdo": true, "blockquote": true,
+	"br": true, "button": true, "caption": true, "center": true, "cite": true,
+	"code": true, "col": true, "colgroup": true, "data": true, "dd": true,
+	"del": true, "details": true, "dfn": true, "div": true, "dl": true,
+	"dt": true, "em": true, "figcaption": true, "figure": true, "font": true,
+	"footer": true, "h1": true, "h2": true, "h3": true, "h4": true,
+	"h5": true, "h6": true, "header": true, "hgroup": true, "hr": true,
+	"i": true, "img": true, "input": true, "ins": true, "kbd": true,
+	"label": true, "li": true, "main": true, "mark": true, "nav": true,
+	"ol": true, "p": true, "picture": true, "pre": true, "q": true,
+	"rp": true, "rt": true, "ruby": true, "s": true, "samp": true,
+	"section": true, "small": true, "source": true, "span": true, "strike": true,
+	"strong": true, "style": true, "sub": true, "summary": true, "sup": true,
+	"table": true, "tbody": true, "td": true, "tfoot": true, "th": true,
+	"thead": true, "time": true, "tr": true, "track": true, "tt": true,
+	"u": true, "ul": true, "var": true, "video": true, "wbr": true,
+}
+
+// allowedAttributes are the attributes kept in slide HTML, besides data-*
+// and aria-* attributes. Event handlers, and attributes like http-equiv and
+// formaction, are removed.
+var allowedAttributes = map[string]bool{
+	"abbr": true, "align": true, "alt": true, "autoplay": true, "border": true,
+	"checked": true, "cite": true, "class": true, "color": true, "colspan": true,
+	"controls": true, "datetime": true, "decoding": true, "default": true, "dir": true,
+	"disabled": true, "download": true, "face": true, "headers": true, "height": true,
+	"hidden": true, "href": true, "hreflang": true, "id": true, "kind": true,
+	"label": true, "lang": true, "loading": true, "loop": true, "media": true,
+	"muted": true, "name": true, "open": true, "playsinline": true, "poster": true,
+	"preload": true, "rel": true, "reversed": true, "role": true, "rowspan": true,
+	"scope": true, "size": true, "sizes": true, "span": true, "src": true,
+	"srclang": true, "srcset": true, "start": true, "style": true, "tabindex": true,
+	"target": true, "title": true, "type": true, "valign": true, "value": true,
+	"width": true,
+}
+
+// urlAttributes are the attributes whose values are URLs, checked for their
+// scheme.
+var urlAttributes = map[string]bool{
+	"href":   true,
+	"src":    true,
+	"cite":   true,
+	"poster": true,
+}
+
+// allowedSchemes are the URL schemes kept in URL attributes. URLs without a
+// scheme, such as relative paths and #anchors, are always kept.
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+	"tel":    true,
+}
+
+// sanitizeHTML removes content that can run scripts from slide HTML. It
+// tokenizes the HTML like a browser does and keeps only allowed elements and
+// attributes: script, iframe, object, embed, and similar elements are
+// removed with their content, and event handlers and URLs with schemes like
+// javascript: are removed from tags. Text, comments, and tags that need no
+// changes are kept exactly as written.
+func sanitizeHTML(s string) string {
+	z := html.NewTokenizer(strings.NewReader(s))
+	var sb strings.Builder
+
+	// The removed element being skipped, and how deeply it is nested in itself
+	skipping, depth := "", 0
+
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			// io.EOF, as a strings.Reader can't fail
+			return sb.String()
+		}
+		raw := string(z.Raw())
+		token := z.Token()
+
+		if skipping != "" {
+			if token.Data == skipping {
+				switch tt {
+				case html.StartTagToken:
+					depth++
+				case html.EndTagToken:
+					depth--
+				}
+				if depth == 0 {
+					skipping = ""
+				}
+			}
+			continue
+		}
+
+		switch tt {
+		case html.TextToken, html.CommentToken:
+			sb.WriteString(raw)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if removedElements[token.Data] {
+				if tt == html.StartTagToken && !isVoidElement(token.Data) {
+					skipping, depth = token.Data, 1
+				}
+				continue
+			}
+			if !allowedElements[token.Data] {
+				continue
+			}
+			sb.WriteString(sanitizeTag(raw, token, tt == html.SelfClosingTagToken))
+		case html.EndTagToken:
+			if allowedElements[token.Data] {
+				sb.WriteString(raw)
+			}
+		}
+	}
+}
+
+// sanitizeTag returns a start tag without its unsafe attributes. A tag whose
+// attributes are all safe is returned as written.
+func sanitizeTag(raw string, token html.Token, selfClosing bool) string {
+	var attrs []html.Attribute
+	for _, attr := range token.Attr {
+		if isSafeAttribute(attr) {
+			attrs = append(attrs, attr)
+		}
+	}
+	if len(attrs) == len(token.Attr) {
+		return raw
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<" + token.Data)
+	for _, attr := range attrs {
+		sb.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if selfClosing {
+		sb.WriteString(" /")
+	}
+	sb.WriteString(">")
+	return sb.String()
+}
+
+// isSafeAttribute reports whether an attribute is allowed, and its value is
+// a safe URL if it is a URL attribute. The value has its character references
+// decoded, as browsers see it.
+func isSafeAttribute(attr html.Attribute) bool {
+	if attr.Namespace != "" {
+		return false
+	}
+	if !allowedAttributes[attr.Key] && !strings.HasPrefix(attr.Key, "data-") && !strings.HasPrefix(attr.Key, "aria-") {
+		return false
+	}
+	if attr.Key == "srcset" {
+		for _, candidate := range strings.Split(attr.Val, ",") {
+			if fields := strings.Fields(candidate); len(fields) > 0 && !isSafeURL(fields[0], true) {
+				return false
+			}
+		}
+		return true
+	}
+	if urlAttributes[attr.Key] {
+		return isSafeURL(attr.Val, attr.Key == "src")
+	}
+	return true
+}
+
+// isSafeURL reports whether a URL has no scheme or an allowed one. Image
+// data: URLs are allowed for image sources.
+func isSafeURL(url string, image bool) bool {
+	// Browsers ignore whitespace and control characters in the scheme
+	url = strings.ToLower(strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, url))
+
+	scheme, _, found := strings.Cut(url, ":")
+	if !found || strings.ContainsAny(scheme, "/?#") {
+		return true
+	}
+	if image && scheme == "data" {
+		return strings.HasPrefix(url, "data:image/")
+	}
+	return allowedSchemes[scheme]
+}
+
+// isVoidElement reports whether an element has no end tag, so a removed
+// element of this kind has no content to skip.
+func isVoidElement(name string) bool {
+	switch name {
+	case "embed", "frame", "meta", "base", "link":
+		return true
+	}
+	return false
+}
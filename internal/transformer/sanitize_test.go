@@ -0,0 +1,170 @@
+package transformer
+
+import (
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "safe html is unchanged",
+			input:    `<div class="box"><span style="color: red">Hi</span><img src="a.png" alt="A"></div>`,
+			expected: `<div class="box"><span style="color: red">Hi</span><img src="a.png" alt="A"></div>`,
+		},
+		{
+			name:     "script element",
+			input:    `<p>Before</p><script>alert("hi")</script><p>After</p>`,
+			expected: `<p>Before</p><p>After</p>`,
+		},
+		{
+			name:     "uppercase tags",
+			input:    `<SCRIPT type="text/javascript">alert(1)</SCRIPT><IFrame SRC="https://example.com"></iFrame>ok`,
+			expected: `ok`,
+		},
+		{
+			name:     "nested unsafe elements",
+			input:    `<div><object data="a.swf"><embed src="a.swf"></object></div>`,
+			expected: `<div></div>`,
+		},
+		{
+			name:     "nested elements of the same kind",
+			input:    `<iframe><iframe src="x"></iframe></iframe><p>ok</p>`,
+			expected: `<p>ok</p>`,
+		},
+		{
+			name:     "unclosed and self-closing tags",
+			input:    `<embed src="a.swf" /><p>ok</p><script src="x.js">`,
+			expected: `<p>ok</p>`,
+		},
+		{
+			name:     "tag with > in an attribute",
+			input:    `<script data-x="a>b">alert(1)</script>ok`,
+			expected: `ok`,
+		},
+		{
+			name:     "event handler with double quotes",
+			input:    `<img src="a.png" onerror="alert(1)">`,
+			expected: `<img src="a.png">`,
+		},
+		{
+			name:     "event handler with single quotes",
+			input:    `<div class='note' onClick='alert("hi")'>Text</div>`,
+			expected: `<div class="note">Text</div>`,
+		},
+		{
+			name:     "unquoted event handler on self-closing tag",
+			input:    `<img src=a.png onload=alert(1) />`,
+			expected: `<img src="a.png" />`,
+		},
+		{
+			name:     "attribute value mentioning on is kept",
+			input:    `<span title="click onion soup">Soup</span>`,
+			expected: `<span title="click onion soup">Soup</span>`,
+		},
+		{
+			name:     "javascript url",
+			input:    `<a href=" JavaScript:alert(1)">link</a> <a href="https://example.com">ok</a>`,
+			expected: `<a>link</a> <a href="https://example.com">ok</a>`,
+		},
+		{
+			name:     "unbalanced quote before an event handler",
+			input:    "<div>\n<img src=x \"\"\" onerror=alert(1)>\n</div>",
+			expected: "<div>\n<img src=\"x\">\n</div>",
+		},
+		{
+			name:     "javascript url with character references",
+			input:    `<a href="&#106;avascript:alert(1)">link</a><a href="java&#x09;script:alert(2)">tab</a>`,
+			expected: `<a>link</a><a>tab</a>`,
+		},
+		{
+			name:     "tag split by a nested tag",
+			input:    `<scr<script>ipt>alert(1)</script>ok`,
+			expected: `ipt>alert(1)ok`,
+		},
+		{
+			name:     "meta refresh",
+			input:    `<meta http-equiv=refresh content="0;url=javascript:alert(1)"><p>ok</p>`,
+			expected: `<p>ok</p>`,
+		},
+		{
+			name:     "svg with a breakout element",
+			input:    `<svg><style><img src=x onerror=alert(1)></style></svg><p>ok</p>`,
+			expected: `<p>ok</p>`,
+		},
+		{
+			name:     "unknown elements keep their content",
+			input:    `<form action="javascript:alert(1)"><button formaction="javascript:alert(2)">Go</button></form>`,
+			expected: `<button>Go</button>`,
+		},
+		{
+			name:     "safe urls and data attributes",
+			input:    `<a href="#fn:1" class="footnote-ref">1</a><img src="data:image/png;base64,AA==" data-fit="cover"><a href="mailto:a@example.com">mail</a>`,
+			expected: `<a href="#fn:1" class="footnote-ref">1</a><img src="data:image/png;base64,AA==" data-fit="cover"><a href="mailto:a@example.com">mail</a>`,
+		},
+		{
+			name:     "data url outside an image",
+			input:    `<a href="data:text/html,<script>alert(1)</script>">x</a>`,
+			expected: `<a>x</a>`,
+		},
+		{
+			name:     "escaped code is unchanged",
+			input:    `<pre><code>&lt;script&gt;alert(1)&lt;/script&gt;</code></pre>`,
+			expected: `<pre><code>&lt;script&gt;alert(1)&lt;/script&gt;</code></pre>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeHTML(tt.input); got != tt.expected {
+				t.Errorf("sanitizeHTML(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestTransformSanitizesHTML(t *testing.T) {
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{
+				HTML:       `<h1 onclick="x()">Title</h1><script>alert(1)</script>`,
+				Fragments:  []parser.Fragment{{Content: `<p>One</p><iframe src="https://example.com"></iframe>`}},
+				NotesHTML:  `<p><strong>Say</strong> hi</p><script>alert(2)</script>`,
+				Directives: parser.SlideDirectives{Notes: "**Say** hi\n\n<script>alert(2)</script>"},
+			},
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	slide := New(cfg).Transform(pres).Slides[0]
+	if slide.HTML != "<h1>Title</h1>" {
+		t.Errorf("expected sanitized slide HTML, got %q", slide.HTML)
+	}
+	if slide.Fragments[0].Content != "<p>One</p>" {
+		t.Errorf("expected sanitized fragment, got %q", slide.Fragments[0].Content)
+	}
+	if slide.NotesHTML != "<p><strong>Say</strong> hi</p>" {
+		t.Errorf("expected sanitized notes HTML, got %q", slide.NotesHTML)
+	}
+	if slide.Notes != pres.Slides[0].Directives.Notes {
+		t.Errorf("expected the notes markdown unchanged, got %q", slide.Notes)
+	}
+
+	cfg.AllowHTML = true
+	slide = New(cfg).Transform(pres).Slides[0]
+	if slide.HTML != pres.Slides[0].HTML {
+		t.Errorf("expected HTML unchanged with allowHTML, got %q", slide.HTML)
+	}
+	if slide.Fragments[0].Content != pres.Slides[0].Fragments[0].Content {
+		t.Errorf("expected fragment unchanged with allowHTML, got %q", slide.Fragments[0].Content)
+	}
+	if slide.NotesHTML != pres.Slides[0].NotesHTML {
+		t.Errorf("expected notes HTML unchanged with allowHTML, got %q", slide.NotesHTML)
+	}
+}
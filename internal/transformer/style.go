@@ -0,0 +1,113 @@
+package transformer
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Style overrides a slide can set in its directives. The frontend applies
+// them as CSS custom properties on the slide.
+const (
+	StyleAccent    = "accent"     // Accent color, such as "#ff5500"
+	StyleFontScale = "fontScale"  // Content scale, from minStyleFontScale to maxStyleFontScale
+	StyleTextColor = "textColor"  // Text color
+	StylePadding   = "padding"    // Slide padding, such as "40px" or "2rem 4rem"
+	StyleTable     = "tableStyle" // Look of the slide's tables; see tableStyles
+)
+
+// tableStyles are the values of the tableStyle style override. The frontend
+// applies them as a table-<style> class on the slide rather than a property.
+var tableStyles = []string{"compact", "striped", "minimal"}
+
+// Bounds of the fontScale style override.
+const (
+	minStyleFontScale = 0.5
+	maxStyleFontScale = 3
+)
+
+// styleValidators check the value of each supported style override. Other
+// directive keys are ignored.
+var styleValidators = map[string]func(string) (string, bool){
+	StyleAccent:    validateColor,
+	StyleFontScale: validateFontScale,
+	StyleTextColor: validateColor,
+	StylePadding:   validatePadding,
+	StyleTable:     validateTableStyle,
+}
+
+var (
+	// hexColorPattern matches #rgb, #rgba, #rrggbb, and #rrggbbaa colors.
+	hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3,4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+	// rgbColorPattern matches rgb() and rgba() colors, with the components
+	// separated by commas, spaces, or a slash before the alpha.
+	rgbColorPattern = regexp.MustCompile(`^rgba?\(\s*[\d.]+%?((\s*[,/]\s*|\s+)[\d.]+%?){2,3}\s*\)$`)
+	// namedColorPattern matches named colors, such as "tomato".
+	namedColorPattern = regexp.MustCompile(`^[a-zA-Z]+$`)
+	// lengthPattern matches a single CSS length, such as "0", "40px", or "2.5rem".
+	lengthPattern = regexp.MustCompile(`^(0|\d+(\.\d+)?(px|rem|em|%|vw|vh))$`)
+)
+
+// resolveStyle returns the supported style overrides among a slide's
+// directives, or nil if there are none. Values that don't validate are
+// ignored, which also keeps them from injecting other CSS.
+func resolveStyle(raw map[string]string) map[string]string {
+	var style map[string]string
+	for key, value := range raw {
+		validate, ok := styleValidators[key]
+		if !ok {
+			continue
+		}
+		value, ok := validate(strings.TrimSpace(value))
+		if !ok {
+			continue
+		}
+		if style == nil {
+			style = make(map[string]string)
+		}
+		style[key] = value
+	}
+	return style
+}
+
+// validateColor accepts hex, rgb(), rgba(), and named colors.
+func validateColor(value string) (string, bool) {
+	if hexColorPattern.MatchString(value) || rgbColorPattern.MatchString(value) || namedColorPattern.MatchString(value) {
+		return value, true
+	}
+	return "", false
+}
+
+// validateFontScale accepts a number from minStyleFontScale to maxStyleFontScale.
+func validateFontScale(value string) (string, bool) {
+	scale, err := strconv.ParseFloat(value, 64)
+	// Written so that NaN is rejected too
+	if err != nil || !(scale >= minStyleFontScale && scale <= maxStyleFontScale) {
+		return "", false
+	}
+	return strconv.FormatFloat(scale, 'f', -1, 64), true
+}
+
+// validateTableStyle accepts one of tableStyles, in any case.
+func validateTableStyle(value string) (string, bool) {
+	value = strings.ToLower(value)
+	if slices.Contains(tableStyles, value) {
+		return value, true
+	}
+	return "", false
+}
+
+// validatePadding accepts one to four CSS lengths, as in the padding property.
+func validatePadding(value string) (string, bool) {
+	lengths := strings.Fields(value)
+	if len(lengths) == 0 || len(lengths) > 4 {
+		return "", false
+	}
+	for _, length := range lengths {
+		if !lengthPattern.MatchString(length) {
+			return "", false
+		}
+	}
+	return strings.Join(lengths, " "), true
+}
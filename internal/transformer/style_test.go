@@ -0,0 +1,164 @@
+package transformer
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func TestResolveStyle(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  map[string]string
+		want map[string]string
+	}{
+		{
+			name: "all overrides",
+			raw: map[string]string{
+				"accent":     "#ff5500",
+				"fontScale":  "1.20",
+				"textColor":  "rgb(20, 20, 20)",
+				"padding":    " 2rem  4rem ",
+				"tableStyle": "Striped",
+			},
+			want: map[string]string{
+				"accent":     "#ff5500",
+				"fontScale":  "1.2",
+				"textColor":  "rgb(20, 20, 20)",
+				"padding":    "2rem 4rem",
+				"tableStyle": "striped",
+			},
+		},
+		{
+			name: "unknown keys ignored",
+			raw:  map[string]string{"accent": "tomato", "class": "wide", "border": "red"},
+			want: map[string]string{"accent": "tomato"},
+		},
+		{
+			name: "invalid values ignored",
+			raw: map[string]string{
+				"accent":     "red; background: url(x)",
+				"fontScale":  "4",
+				"textColor":  "#12345",
+				"padding":    "40",
+				"tableStyle": "zebra",
+			},
+			want: nil,
+		},
+		{
+			name: "no directives",
+			raw:  nil,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveStyle(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveStyle() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateColor(t *testing.T) {
+	valid := []string{"#fff", "#ffff", "#ff5500", "#ff550080", "rgb(255, 85, 0)", "rgba(255,85,0,0.5)", "rgb(255 85 0 / 50%)", "rebeccapurple"}
+	for _, value := range valid {
+		if _, ok := validateColor(value); !ok {
+			t.Errorf("validateColor(%q) should accept the color", value)
+		}
+	}
+
+	invalid := []string{"", "#ff", "#ff55zz", "rgb(255)", "url(x)", "red;", "light blue", "var(--x)"}
+	for _, value := range invalid {
+		if _, ok := validateColor(value); ok {
+			t.Errorf("validateColor(%q) should reject the color", value)
+		}
+	}
+}
+
+func TestValidateFontScale(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+		ok    bool
+	}{
+		{"1", "1", true},
+		{"0.5", "0.5", true},
+		{"3", "3", true},
+		{"1.25", "1.25", true},
+		{"0.4", "", false},
+		{"3.1", "", false},
+		{"NaN", "", false},
+		{"big", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := validateFontScale(tt.value)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("validateFontScale(%q) = %q, %v, want %q, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestValidatePadding(t *testing.T) {
+	valid := []string{"0", "40px", "2.5rem", "1em 2em", "0 5% 10vw 1vh"}
+	for _, value := range valid {
+		if _, ok := validatePadding(value); !ok {
+			t.Errorf("validatePadding(%q) should accept the padding", value)
+		}
+	}
+
+	invalid := []string{"", "40", "-1px", "1px 2px 3px 4px 5px", "auto", "calc(1px)"}
+	for _, value := range invalid {
+		if _, ok := validatePadding(value); ok {
+			t.Errorf("validatePadding(%q) should reject the padding", value)
+		}
+	}
+}
+
+func TestTransformStyleJSONRoundTrip(t *testing.T) {
+	pres, err := parser.New().Parse([]byte(`<!--
+accent: "#ff5500"
+fontScale: 1.2
+padding: 40px
+shadow: large
+-->
+# Styled
+
+---
+
+# Plain`))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	result := New(config.DefaultConfig()).Transform(pres)
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("failed to marshal to JSON: %v", err)
+	}
+
+	var unmarshaled TransformedPresentation
+	if err := json.Unmarshal(data, &unmarshaled); err != nil {
+		t.Fatalf("failed to unmarshal from JSON: %v", err)
+	}
+
+	want := map[string]string{"accent": "#ff5500", "fontScale": "1.2", "padding": "40px"}
+	if got := unmarshaled.Slides[0].Style; !reflect.DeepEqual(got, want) {
+		t.Errorf("Style after round-trip = %v, want %v", got, want)
+	}
+	if got := unmarshaled.Slides[1].Style; got != nil {
+		t.Errorf("Style of a plain slide = %v, want nil", got)
+	}
+
+	// Slides without overrides leave the field out
+	if strings.Count(string(data), `"style":`) != 1 {
+		t.Errorf("expected style only on the first slide, got %s", data)
+	}
+}
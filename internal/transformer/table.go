@@ -0,0 +1,69 @@
+package transformer
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits above which a table is estimated too wide for a slide at the
+// theme's font size.
+const (
+	MaxTableColumns = 6  // Columns in a row
+	MaxTableWidth   = 90 // Characters of text in a row, plus tableCellWidth per cell
+	tableCellWidth  = 3  // Padding and borders around a cell, in characters
+)
+
+var (
+	// tablePattern matches tables in rendered slide HTML.
+	tablePattern = regexp.MustCompile(`(?is)<table[\s>].*?</table>`)
+	// tableRowPattern matches the rows of a table.
+	tableRowPattern = regexp.MustCompile(`(?is)<tr[\s>].*?</tr>`)
+	// tableCellPattern matches the header and data cells of a table row.
+	tableCellPattern = regexp.MustCompile(`(?is)<(th|td)[\s>].*?</(?:th|td)>`)
+)
+
+// TableSize is the estimated size of a table on a slide.
+type TableSize struct {
+	// Columns is the number of cells in the table's longest row.
+	Columns int
+	// Width is the estimated width of the table's widest row, in characters.
+	Width int
+}
+
+// Wide reports whether a table of this size is estimated not to fit across
+// a slide.
+func (s TableSize) Wide() bool {
+	return s.Columns > MaxTableColumns || s.Width > MaxTableWidth
+}
+
+// WidestTable returns the size of the tables in slide HTML: the most columns
+// and the widest row of any of them. It is the zero TableSize if there are
+// no tables.
+func WidestTable(slideHTML string) TableSize {
+	var widest TableSize
+	for _, table := range tablePattern.FindAllString(slideHTML, -1) {
+		size := measureTable(table)
+		widest.Columns = max(widest.Columns, size.Columns)
+		widest.Width = max(widest.Width, size.Width)
+	}
+	return widest
+}
+
+// measureTable returns the estimated size of a table's HTML, from the number
+// of cells and the length of their text in each row.
+func measureTable(table string) TableSize {
+	var size TableSize
+	for _, row := range tableRowPattern.FindAllString(table, -1) {
+		cells := tableCellPattern.FindAllString(row, -1)
+		width := 0
+		for _, cell := range cells {
+			text := html.UnescapeString(htmlTagPattern.ReplaceAllString(cell, " "))
+			width += utf8.RuneCountInString(strings.Join(strings.Fields(text), " ")) + tableCellWidth
+		}
+		size.Columns = max(size.Columns, len(cells))
+		size.Width = max(size.Width, width)
+	}
+	return size
+}
@@ -0,0 +1,119 @@
+package transformer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+)
+
+// markdownTable returns a markdown table with the given number of columns
+// and rows of cells reading "cell".
+func markdownTable(columns, rows int) string {
+	var b strings.Builder
+	for c := 1; c <= columns; c++ {
+		fmt.Fprintf(&b, "| Col %d ", c)
+	}
+	b.WriteString("|\n" + strings.Repeat("|---", columns) + "|\n")
+	for range rows {
+		b.WriteString(strings.Repeat("| cell ", columns) + "|\n")
+	}
+	return b.String()
+}
+
+func TestWidestTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     TableSize
+		wantWide bool
+	}{
+		{
+			name:     "no table",
+			markdown: "# Title\n\nJust text",
+			want:     TableSize{},
+		},
+		{
+			name:     "narrow table",
+			markdown: "| Name | Role |\n|------|------|\n| Ada | Engineer |",
+			want:     TableSize{Columns: 2, Width: 17},
+		},
+		{
+			name:     "ten columns",
+			markdown: markdownTable(10, 3),
+			want:     TableSize{Columns: 10, Width: 81},
+			wantWide: true,
+		},
+		{
+			name:     "few columns with long cells",
+			markdown: "| Option | Description |\n|---|---|\n| `--watch` | " + strings.Repeat("Rebuilds the presentation whenever a file changes. ", 2) + "|",
+			want:     TableSize{Columns: 2, Width: 114},
+			wantWide: true,
+		},
+		{
+			name:     "widest of two tables",
+			markdown: markdownTable(3, 1) + "\nBetween\n\n" + markdownTable(7, 1),
+			want:     TableSize{Columns: 7, Width: 56},
+			wantWide: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pres := transformMarkdown(t, config.DefaultConfig(), tt.markdown)
+			got := WidestTable(pres.Slides[0].HTML)
+			if got != tt.want {
+				t.Errorf("WidestTable() = %+v, want %+v", got, tt.want)
+			}
+			if got.Wide() != tt.wantWide {
+				t.Errorf("Wide() = %v, want %v", got.Wide(), tt.wantWide)
+			}
+			if pres.Slides[0].WideTable != tt.wantWide {
+				t.Errorf("WideTable = %v, want %v", pres.Slides[0].WideTable, tt.wantWide)
+			}
+		})
+	}
+}
+
+func TestTransformTableSlide(t *testing.T) {
+	// Empty cells put ||| in a row, which isn't a column separator
+	table := markdownTable(10, 2) + "| a ||| b |||||||\n"
+	pres := transformMarkdown(t, config.DefaultConfig(),
+		"<!-- tableStyle: compact -->\n\n"+table,
+		"## Results\n\n"+markdownTable(3, 2),
+	)
+
+	wide := pres.Slides[0]
+	if wide.Layout != "default" {
+		t.Errorf("expected a table-only slide to use the default layout, got %q", wide.Layout)
+	}
+	if len(wide.Columns) != 0 {
+		t.Errorf("expected the table not to be split into columns, got %d", len(wide.Columns))
+	}
+	if !wide.WideTable {
+		t.Error("expected the 10-column table to be marked wide")
+	}
+	if wide.Style[StyleTable] != "compact" {
+		t.Errorf("expected the table style in the style map, got %v", wide.Style)
+	}
+
+	if titled := pres.Slides[1]; titled.Layout != "default" || titled.WideTable {
+		t.Errorf("expected a default layout with a narrow table, got %q with WideTable %v", titled.Layout, titled.WideTable)
+	}
+}
+
+func TestTransformTableAlignment(t *testing.T) {
+	pres := transformMarkdown(t, config.DefaultConfig(), "| Left | Center | Right |\n|:-----|:------:|------:|\n| a | b | c |")
+
+	html := pres.Slides[0].HTML
+	for _, want := range []string{
+		`<th style="text-align:left">Left</th>`,
+		`<th style="text-align:center">Center</th>`,
+		`<td style="text-align:right">c</td>`,
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected %s in the table, got:\n%s", want, html)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package transformer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// TOCEntry is an entry in the presentation's table of contents.
+type TOCEntry struct {
+	Title      string     `json:"title"`
+	Children   []TOCEntry `json:"children,omitempty"` // Slides in the chapter started by a section slide
+	SlideIndex int        `json:"slideIndex"`
+	Level      int        `json:"level"` // 1 for h1, 2 for h2
+	Section    bool       `json:"section,omitempty"`
+}
+
+// markdownHeadingPattern matches level 1 and 2 markdown headings, for slides
+// whose heading is not in the rendered HTML.
+// Captures: (1) heading markers, (2) heading text
+var markdownHeadingPattern = regexp.MustCompile(`(?m)^(#{1,2})\s+(.+)$`)
+
+// fencedCodePattern matches fenced code blocks, whose comment lines can look like headings.
+var fencedCodePattern = regexp.MustCompile("(?ms)^```.*?^```")
+
+// htmlTagPattern matches HTML tags, to reduce HTML content to text.
+var htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+
+// buildTOC returns the table of contents for the transformed slides. Slides
+// without an h1 or h2 heading are omitted. Slides with the section layout
+// start a chapter, and the slides that follow are nested under them until the
+// next section slide.
+func buildTOC(slides []parser.Slide, transformed []TransformedSlide) []TOCEntry {
+	var toc []TOCEntry
+	chapter := -1 // index in toc of the current section entry
+
+	for i, slide := range slides {
+		isSection := transformed[i].Layout == "section"
+
+		title, level := slideHeading(slide)
+		if title == "" {
+			if isSection {
+				chapter = -1
+			}
+			continue
+		}
+
+		entry := TOCEntry{
+			Title:      title,
+			SlideIndex: transformed[i].Index,
+			Level:      level,
+			Section:    isSection,
+		}
+
+		switch {
+		case isSection:
+			toc = append(toc, entry)
+			chapter = len(toc) - 1
+		case chapter >= 0:
+			toc[chapter].Children = append(toc[chapter].Children, entry)
+		default:
+			toc = append(toc, entry)
+		}
+	}
+
+	return toc
+}
+
+// slideHeading returns the text and level of the first h1 or h2 heading in a
+// slide. It checks the rendered HTML first and falls back to the markdown
+// content. It returns an empty title if the slide has no such heading.
+func slideHeading(slide parser.Slide) (string, int) {
+	if title, level := parser.FirstHeading(slide.HTML, 2); title != "" {
+		return title, level
+	}
+
+	content := fencedCodePattern.ReplaceAllString(slide.Content, "")
+	if match := markdownHeadingPattern.FindStringSubmatch(content); match != nil {
+		if title := strings.TrimSpace(match[2]); title != "" {
+			return title, len(match[1])
+		}
+	}
+
+	return "", 0
+}
@@ -0,0 +1,113 @@
+package transformer
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func TestTransformTOC(t *testing.T) {
+	tr := New(config.DefaultConfig())
+
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>Welcome</h1>", Directives: parser.SlideDirectives{Layout: "title"}},
+			{Index: 1, HTML: "<h2>Agenda</h2><ul><li>One</li></ul>"},
+			{Index: 2, HTML: "<h1>Part One</h1>", Directives: parser.SlideDirectives{Layout: "section"}},
+			{Index: 3, HTML: "<h2 id=\"setup\">Setup &amp; <code>install</code></h2><p>Steps</p>"},
+			{Index: 4, HTML: "<p>No heading here</p>"},
+			{Index: 5, HTML: "<h3>Too deep</h3>"},
+			{Index: 6, HTML: "<h2>Part Two</h2>"}, // detected as a section slide
+			{Index: 7, HTML: "<h1>Details</h1>"},
+		},
+	}
+
+	result := tr.Transform(pres)
+
+	// Section slides start a chapter; headings deeper than h2 are ignored
+	want := []TOCEntry{
+		{SlideIndex: 0, Title: "Welcome", Level: 1},
+		{SlideIndex: 1, Title: "Agenda", Level: 2},
+		{SlideIndex: 2, Title: "Part One", Level: 1, Section: true, Children: []TOCEntry{
+			{SlideIndex: 3, Title: "Setup & install", Level: 2},
+		}},
+		{SlideIndex: 6, Title: "Part Two", Level: 2, Section: true, Children: []TOCEntry{
+			{SlideIndex: 7, Title: "Details", Level: 1},
+		}},
+	}
+	if !reflect.DeepEqual(result.TOC, want) {
+		t.Errorf("TOC = %+v, want %+v", result.TOC, want)
+	}
+}
+
+func TestTransformTOC_SectionWithoutHeadingEndsChapter(t *testing.T) {
+	tr := New(config.DefaultConfig())
+
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>Chapter</h1>", Directives: parser.SlideDirectives{Layout: "section"}},
+			{Index: 1, HTML: "<h2>Inside</h2><p>Text</p>"},
+			{Index: 2, HTML: "<p>Intermission</p>", Directives: parser.SlideDirectives{Layout: "section"}},
+			{Index: 3, HTML: "<h2>Outside</h2><p>Text</p>"},
+		},
+	}
+
+	result := tr.Transform(pres)
+
+	if len(result.TOC) != 2 {
+		t.Fatalf("expected 2 top-level entries, got %+v", result.TOC)
+	}
+	if len(result.TOC[0].Children) != 1 || result.TOC[0].Children[0].SlideIndex != 1 {
+		t.Errorf("expected slide 1 in the chapter, got %+v", result.TOC[0].Children)
+	}
+	if result.TOC[1].SlideIndex != 3 {
+		t.Errorf("expected slide 3 at the top level, got %+v", result.TOC[1])
+	}
+}
+
+func TestSlideHeading(t *testing.T) {
+	tests := []struct {
+		name      string
+		slide     parser.Slide
+		wantTitle string
+		wantLevel int
+	}{
+		{
+			name:      "h1",
+			slide:     parser.Slide{HTML: "<h1>Title</h1>"},
+			wantTitle: "Title",
+			wantLevel: 1,
+		},
+		{
+			name:      "first of h2 and h1",
+			slide:     parser.Slide{HTML: "<h2>Sub</h2><h1>Main</h1>"},
+			wantTitle: "Sub",
+			wantLevel: 2,
+		},
+		{
+			name:      "markdown fallback",
+			slide:     parser.Slide{Content: "<div>raw</div>\n\n## From Markdown", HTML: "<div>raw</div>"},
+			wantTitle: "From Markdown",
+			wantLevel: 2,
+		},
+		{
+			name:  "comment in code block",
+			slide: parser.Slide{Content: "```bash\n# install\nmake\n```", HTML: "<pre><code># install\nmake</code></pre>"},
+		},
+		{
+			name:  "no heading",
+			slide: parser.Slide{Content: "Just text", HTML: "<p>Just text</p>"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			title, level := slideHeading(tt.slide)
+			if title != tt.wantTitle || level != tt.wantLevel {
+				t.Errorf("slideHeading() = (%q, %d), want (%q, %d)", title, level, tt.wantTitle, tt.wantLevel)
+			}
+		})
+	}
+}
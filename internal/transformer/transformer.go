@@ -2,9 +2,14 @@
 package transformer
 
 import (
+	"errors"
+	"fmt"
+	"html"
+	"image"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/MiniCodeMonkey/tap/internal/config"
 	"github.com/MiniCodeMonkey/tap/internal/parser"
@@ -12,8 +17,12 @@ import (
 
 // TransformedPresentation is the JSON-serializable output for the frontend.
 type TransformedPresentation struct {
+	Themes map[string]string  `json:"themes,omitempty"` // Custom theme name to stylesheet URL
 	Config config.Config      `json:"config"`
 	Slides []TransformedSlide `json:"slides"`
+	TOC    []TOCEntry         `json:"toc,omitempty"`
+	// Slide index of each heading ID, for links to headings on other slides
+	Anchors map[string]int `json:"anchors,omitempty"`
 }
 
 // TransformedSlide represents a slide ready for frontend rendering.
@@ -21,15 +30,46 @@ type TransformedSlide struct {
 	Background  *BackgroundConfig      `json:"background,omitempty"`
 	Layout      string                 `json:"layout"`
 	HTML        string                 `json:"html"`
-	Transition  string                 `json:"transition,omitempty"`
-	Notes       string                 `json:"notes,omitempty"`
+	Transition  string                 `json:"transition,omitempty"` // Deprecated: the name of TransitionSpec, kept for one release
+	Notes       string                 `json:"notes,omitempty"`      // Speaker notes as written, in markdown
+	NotesHTML   string                 `json:"notesHTML,omitempty"`  // Speaker notes rendered and sanitized like the slide HTML
 	Tag         string                 `json:"tag,omitempty"`
 	Badge       string                 `json:"badge,omitempty"`
+	Classes     []string               `json:"classes,omitempty"` // CSS classes for the slide's wrapper, from the class directive
 	CodeBlocks  []TransformedCodeBlock `json:"codeBlocks,omitempty"`
 	Fragments   []TransformedFragment  `json:"fragments,omitempty"`
+	Columns     []string               `json:"columns,omitempty"`
+	ImageSrc    string                 `json:"imageSrc,omitempty"` // Source of the image on image-focus slides
+	Images      []ImageInfo            `json:"images,omitempty"`   // Dimensions of the local images in the HTML
 	Index       int                    `json:"index"`
 	Scroll      bool                   `json:"scroll,omitempty"`
 	ScrollSpeed int                    `json:"scrollSpeed,omitempty"`
+	Hidden      bool                   `json:"hidden,omitempty"`
+	Embed       *EmbedConfig           `json:"embed,omitempty"` // Page shown on embed slides
+	Stat        *Stat                  `json:"stat,omitempty"`  // The number on big-stat slides
+	// Resolved transition into the slide; the frontend plays it in reverse
+	// when going back to the previous slide
+	TransitionSpec *config.TransitionSpec `json:"transitionSpec,omitempty"`
+	// How long auto-play shows the slide in milliseconds, from its
+	// autoAdvance directive or the presentation's; 0 waits for the presenter
+	AutoAdvance int `json:"autoAdvance,omitempty"`
+	// How the images with an img comment are fitted, which the HTML
+	// carries as data-fit attributes and styles
+	ImageHints []ImageHint `json:"imageHints,omitempty"`
+	// Style overrides from the slide's directives, by name; see StyleAccent
+	Style map[string]string `json:"style,omitempty"`
+	// Lines of the slide in the markdown, for pointing errors at the source;
+	// see parser.Slide.StartLine
+	StartLine int `json:"startLine,omitempty"`
+	EndLine   int `json:"endLine,omitempty"`
+
+	// Estimated size of the content; see contentScore
+	ContentScore int     `json:"contentScore"`
+	Overflow     bool    `json:"overflow,omitempty"`  // Content is estimated not to fit on the slide
+	FontScale    float64 `json:"fontScale,omitempty"` // Suggested font scale for overflowing content to fit
+	// A table is estimated too wide for the slide; see TableSize.Wide. The
+	// frontend marks the slide with the has-wide-table class.
+	WideTable bool `json:"wideTable,omitempty"`
 }
 
 // TransformedCodeBlock represents a code block ready for frontend rendering.
@@ -38,24 +78,50 @@ type TransformedCodeBlock struct {
 	Code       string `json:"code"`
 	Driver     string `json:"driver,omitempty"`
 	Connection string `json:"connection,omitempty"`
+	Highlight  []int  `json:"highlight,omitempty"`
+	Runnable   bool   `json:"runnable,omitempty"` // Can be run with POST /api/run
+	Render     string `json:"render,omitempty"`   // How the frontend renders the block instead of highlighting it; see RenderMermaid
+	Cast       *Cast  `json:"cast,omitempty"`     // Recording and playback options of cast blocks
 }
 
+// RenderMermaid is the render hint of mermaid code blocks, which the frontend
+// renders as diagrams with the mermaid runtime.
+const RenderMermaid = "mermaid"
+
+// RenderCast is the render hint of cast blocks, which the frontend plays
+// with the asciinema player.
+const RenderCast = "cast"
+
 // TransformedFragment represents a fragment group for incremental reveals.
 type TransformedFragment struct {
 	Content string `json:"content"`
 	Index   int    `json:"index"`
+	Inline  bool   `json:"inline,omitempty"` // A list item revealed in place in the slide HTML
 }
 
 // BackgroundConfig holds background styling for a slide.
 type BackgroundConfig struct {
 	Value string `json:"value"`
-	Type  string `json:"type"` // "color", "image", or "gradient"
+	Type  string `json:"type"` // "color", "image", "gradient", or "video"
+	// Playback options for video backgrounds. Videos are muted unless the
+	// directive sets muted: false, and don't loop unless it sets loop: true.
+	Loop  bool `json:"loop,omitempty"`
+	Muted bool `json:"muted,omitempty"`
+}
+
+// Stat is the number a big-stat slide is about, such as "42%", and the
+// line of text explaining it, so themes can style the number on its own.
+type Stat struct {
+	Value   string `json:"value"`
+	Caption string `json:"caption,omitempty"`
 }
 
 // Transformer converts parser.Presentation to TransformedPresentation.
 type Transformer struct {
-	config  *config.Config
-	baseDir string // Base directory for resolving relative paths
+	config     *config.Config
+	imageSizes map[string]image.Point // Image dimensions by file path, for the current Transform call
+	baseDir    string                 // Base directory for resolving relative paths
+	allowExec  bool                   // Whether code blocks with a driver can be run
 }
 
 // New creates a new Transformer with the given configuration.
@@ -79,6 +145,12 @@ func (t *Transformer) SetBaseDir(baseDir string) {
 	t.baseDir = baseDir
 }
 
+// SetAllowExec sets whether code blocks with a driver are marked as runnable.
+// This is only enabled by the dev server when code execution is allowed.
+func (t *Transformer) SetAllowExec(allow bool) {
+	t.allowExec = allow
+}
+
 // Transform converts a parsed Presentation into a TransformedPresentation
 // suitable for JSON serialization and frontend consumption.
 func (t *Transformer) Transform(pres *parser.Presentation) *TransformedPresentation {
@@ -87,50 +159,174 @@ func (t *Transformer) Transform(pres *parser.Presentation) *TransformedPresentat
 		Slides: make([]TransformedSlide, 0, len(pres.Slides)),
 	}
 
+	// Read each image's dimensions once per call, so changed images are picked up
+	t.imageSizes = make(map[string]image.Point)
+
 	for _, slide := range pres.Slides {
 		transformed := t.transformSlide(slide)
 		result.Slides = append(result.Slides, transformed)
 	}
 
+	result.Anchors = linkAnchors(result.Slides)
+	result.TOC = buildTOC(pres.Slides, result.Slides)
+	result.Themes = t.resolveThemeStylesheets()
+
 	return result
 }
 
+// Warnings returns the problems found in the slide directives of pres, each
+// prefixed with its slide number: invalid transitions, embeds, classes, and
+// autoAdvance values. Builds and the dev server report them all from here, so
+// a new kind of warning only needs to be added once.
+func (t *Transformer) Warnings(pres *parser.Presentation) []string {
+	var warnings []string
+	for _, collect := range []func(*parser.Presentation) []string{
+		t.TransitionWarnings,
+		t.EmbedWarnings,
+		t.ClassWarnings,
+		t.AutoAdvanceWarnings,
+	} {
+		warnings = append(warnings, collect(pres)...)
+	}
+	return warnings
+}
+
+// renderHint returns the render hint for a code block in language, or an
+// empty string for blocks rendered as highlighted code.
+func renderHint(language string) string {
+	if strings.EqualFold(language, "mermaid") {
+		return RenderMermaid
+	}
+	if strings.EqualFold(language, "cast") {
+		return RenderCast
+	}
+	return ""
+}
+
+// UsesMermaid reports whether any slide of pres has a mermaid diagram, so
+// builds only include the mermaid runtime when it is needed.
+func UsesMermaid(pres *TransformedPresentation) bool {
+	for _, slide := range pres.Slides {
+		for _, block := range slide.CodeBlocks {
+			if block.Render == RenderMermaid {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ValidateConnections checks that every connection used by a code block is
+// defined in the config, either under the block's driver or in the
+// connections section. The error lists each undefined connection with the
+// number of the slide that uses it.
+func (t *Transformer) ValidateConnections(pres *parser.Presentation) error {
+	var errs []error
+	for i, slide := range pres.Slides {
+		for _, block := range slide.CodeBlocks {
+			if block.Meta.Driver == "" || block.Meta.Connection == "" {
+				continue
+			}
+			if _, err := t.config.DriverConnection(block.Meta.Driver, block.Meta.Connection); err != nil {
+				errs = append(errs, fmt.Errorf("slide %d: %w", i+1, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// sanitize removes script content from slide HTML, unless the presentation
+// allows raw HTML.
+func (t *Transformer) sanitize(html string) string {
+	if t.config.AllowHTML {
+		return html
+	}
+	return sanitizeHTML(html)
+}
+
+// resolveThemeStylesheets returns the stylesheet URL of each custom theme, so
+// the frontend can load a custom theme when it is selected.
+// Themes outside the base directory can't be served and are skipped.
+func (t *Transformer) resolveThemeStylesheets() map[string]string {
+	if t.baseDir == "" {
+		return nil
+	}
+
+	var stylesheets map[string]string
+	for _, theme := range t.config.CustomThemes() {
+		relPath, err := filepath.Rel(t.baseDir, theme.Path)
+		if err != nil || strings.HasPrefix(relPath, "..") {
+			continue
+		}
+		if stylesheets == nil {
+			stylesheets = make(map[string]string)
+		}
+		stylesheets[theme.Name] = "/local/" + filepath.ToSlash(relPath)
+	}
+	return stylesheets
+}
+
 // transformSlide converts a single parser.Slide to TransformedSlide.
 func (t *Transformer) transformSlide(slide parser.Slide) TransformedSlide {
 	layout := t.resolveLayout(slide)
-	html := t.resolveImagePaths(slide.HTML)
+	html := t.resolveImagePaths(t.sanitize(slide.HTML))
 	html = t.resolveAsciinemaPaths(html)
+	html, images := t.addImageDimensions(html)
 
 	// Process HTML for layouts that use ||| column separator
+	var columns []string
 	if layout == "two-column" || layout == "split-media" || layout == "sidebar" {
-		html = processTwoColumnHTML(html)
+		html, columns = processTwoColumnHTML(html)
 	} else if layout == "three-column" {
-		html = processThreeColumnHTML(html)
+		html, columns = processThreeColumnHTML(html)
 	}
 
 	transformed := TransformedSlide{
-		Index:  slide.Index,
-		HTML:   html,
-		Layout: layout,
-		Notes:  slide.Directives.Notes,
-		Tag:    slide.Directives.Tag,
-		Badge:  slide.Directives.Badge,
+		Index:     slide.Index,
+		HTML:      html,
+		Layout:    layout,
+		Columns:   columns,
+		Images:    images,
+		Notes:     slide.Directives.Notes,
+		NotesHTML: t.resolveImagePaths(t.sanitize(slide.NotesHTML)),
+		Tag:       slide.Directives.Tag,
+		Badge:     slide.Directives.Badge,
+		Classes:   resolveClasses(slide),
+		Hidden:    slide.Directives.Hidden,
+		Style:     resolveStyle(slide.Directives.Raw),
+		Embed:     resolveEmbed(slide),
+		StartLine: slide.StartLine,
+		EndLine:   slide.EndLine,
 	}
 
-	// Set transition (per-slide directive overrides global config)
-	if slide.Directives.Transition != "" {
-		transformed.Transition = slide.Directives.Transition
-	} else {
-		transformed.Transition = t.config.Transition
+	transformed.ImageHints = t.resolveImageHints(slide.ImageHints)
+
+	// Big-stat slides name their number, so it can be styled on its own
+	if layout == "big-stat" {
+		transformed.Stat = extractStat(slide)
 	}
 
+	// Image-focus slides render their image full-bleed
+	if layout == "image-focus" {
+		if match := imgSrcPattern.FindStringSubmatch(html); match != nil {
+			transformed.ImageSrc = match[2]
+		}
+	}
+
+	// Set transition (per-slide directive overrides global config)
+	transition := t.resolveTransition(slide.Directives)
+	transformed.Transition = transition.Name
+	transformed.TransitionSpec = &transition
+	transformed.AutoAdvance = t.resolveAutoAdvance(slide.Directives)
+
 	// Transform fragments
 	if len(slide.Fragments) > 0 {
 		transformed.Fragments = make([]TransformedFragment, len(slide.Fragments))
 		for i, frag := range slide.Fragments {
 			transformed.Fragments[i] = TransformedFragment{
-				Content: frag.Content,
+				Content: t.sanitize(frag.Content),
 				Index:   frag.Index,
+				Inline:  frag.Inline,
 			}
 		}
 	}
@@ -144,6 +340,12 @@ func (t *Transformer) transformSlide(slide parser.Slide) TransformedSlide {
 				Code:       block.Code,
 				Driver:     block.Meta.Driver,
 				Connection: block.Meta.Connection,
+				Highlight:  block.Meta.Highlight,
+				Runnable:   t.allowExec && block.Meta.Driver != "",
+				Render:     renderHint(block.Language),
+			}
+			if transformed.CodeBlocks[i].Render == RenderCast {
+				transformed.CodeBlocks[i].Cast = t.castFromBlock(block)
 			}
 		}
 	}
@@ -151,6 +353,15 @@ func (t *Transformer) transformSlide(slide parser.Slide) TransformedSlide {
 	// Transform background
 	if slide.Directives.Background != "" {
 		transformed.Background = t.parseBackground(slide.Directives.Background)
+		if transformed.Background.Type == "video" {
+			transformed.Background.Muted = true
+			if loop := slide.Directives.BackgroundLoop; loop != nil {
+				transformed.Background.Loop = *loop
+			}
+			if muted := slide.Directives.BackgroundMuted; muted != nil {
+				transformed.Background.Muted = *muted
+			}
+		}
 	}
 
 	// Transform scroll settings
@@ -161,13 +372,20 @@ func (t *Transformer) transformSlide(slide parser.Slide) TransformedSlide {
 		}
 	}
 
+	t.estimateOverflow(&transformed)
+	transformed.WideTable = WidestTable(transformed.HTML).Wide()
+
 	return transformed
 }
 
 // resolveLayout determines the layout for a slide.
-// If a layout directive is specified, it takes precedence.
+// Slides with a valid embed use the embed layout. Otherwise, if a layout
+// directive is specified, it takes precedence.
 // Otherwise, auto-detects layout based on content.
 func (t *Transformer) resolveLayout(slide parser.Slide) string {
+	if resolveEmbed(slide) != nil {
+		return "embed"
+	}
 	if slide.Directives.Layout != "" {
 		return slide.Directives.Layout
 	}
@@ -177,11 +395,14 @@ func (t *Transformer) resolveLayout(slide parser.Slide) string {
 // detectLayout auto-detects the appropriate layout based on slide content.
 // Detection priority:
 //  1. two-column: contains ||| separator
-//  2. title: only H1, optional subtitle (paragraph or small text)
-//  3. section: only H2 (large section header)
-//  4. code-focus: single code block taking >50% of content
-//  5. quote: blockquote as primary content
-//  6. default: everything else
+//  2. big-stat: a short number, such as "42%", optionally with a short caption
+//  3. title: only H1, optional subtitle (paragraph or small text)
+//  4. section: only H2 (large section header)
+//  5. code-focus: single code block taking >50% of content
+//  6. quote: blockquote as primary content, or a slide that is a single
+//     :::quote container
+//  7. image-focus: a single image, optionally with a short caption
+//  8. default: everything else
 func detectLayout(slide parser.Slide) string {
 	html := slide.HTML
 	content := slide.Content
@@ -191,6 +412,12 @@ func detectLayout(slide parser.Slide) string {
 		return "two-column"
 	}
 
+	// Check for big-stat layout (a number, optional caption), which would
+	// otherwise be taken for a title
+	if extractStat(slide) != nil {
+		return "big-stat"
+	}
+
 	// Check for title layout (only H1, optional subtitle)
 	if isTitleLayout(html) {
 		return "title"
@@ -210,21 +437,85 @@ func detectLayout(slide parser.Slide) string {
 	if isQuoteLayout(html) {
 		return "quote"
 	}
+	if container, ok := soleContainer(slide); ok && container.Name == "quote" && isQuoteContainer(html) {
+		return "quote"
+	}
+
+	// Check for image-focus layout (single image, short caption at most)
+	if isImageFocusLayout(html) {
+		return "image-focus"
+	}
 
 	return "default"
 }
 
 // containsTwoColumnSeparator checks if the content has a ||| column separator.
+// Separators inside fenced code blocks or inline code are ignored, as are
+// table rows, where ||| is a run of empty cells.
 func containsTwoColumnSeparator(content string) bool {
-	// Look for ||| on its own line or as a separator
-	for i := 0; i <= len(content)-3; i++ {
-		if content[i:i+3] == "|||" {
+	inCodeBlock := false
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock || isTableRow(line) {
+			continue
+		}
+		// Look for ||| on its own line or as a separator
+		if strings.Contains(inlineCodePattern.ReplaceAllString(line, ""), "|||") {
 			return true
 		}
 	}
 	return false
 }
 
+// isTableRow reports whether a markdown line is a table row: a line starting
+// with a pipe that isn't a bare ||| separator.
+func isTableRow(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "|") && trimmed != "|||"
+}
+
+// soleContainer returns the container block holding all of a slide's content,
+// if the slide is a single top-level :::name container.
+func soleContainer(slide parser.Slide) (parser.Container, bool) {
+	var sole parser.Container
+	count := 0
+	for _, container := range slide.Containers {
+		if container.Depth == 0 {
+			sole = container
+			count++
+		}
+	}
+	if count != 1 {
+		return parser.Container{}, false
+	}
+
+	// Outside the container there may only be its fences
+	outside := strings.Replace(slide.Content, sole.Content, "", 1)
+	for _, line := range strings.Split(outside, "\n") {
+		if text := strings.TrimSpace(line); text != "" && !strings.HasPrefix(text, ":::") {
+			return parser.Container{}, false
+		}
+	}
+	return sole, true
+}
+
+// isQuoteContainer checks that the HTML of a :::quote container is quote-like
+// text: no headers, code blocks, or tables.
+func isQuoteContainer(html string) bool {
+	for _, tag := range []string{"h1", "h2", "h3", "h4", "h5", "h6", "pre", "table"} {
+		if countHTMLTag(html, tag) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// inlineCodePattern matches inline code spans in markdown.
+var inlineCodePattern = regexp.MustCompile("`[^`]*`")
+
 // isTitleLayout checks if the HTML contains only an H1, with an optional subtitle.
 // Subtitle can be a paragraph (<p>) following the H1.
 func isTitleLayout(html string) bool {
@@ -334,6 +625,90 @@ func isQuoteLayout(html string) bool {
 	return true
 }
 
+// imageFocusMaxText is the number of characters of text, such as a caption,
+// below which a slide with a single image uses the image-focus layout.
+const imageFocusMaxText = 80
+
+// isImageFocusLayout checks if the HTML is a single image with at most a short
+// caption: no headers, lists, code blocks, tables, or blockquotes.
+func isImageFocusLayout(slideHTML string) bool {
+	if countHTMLTag(slideHTML, "img") != 1 {
+		return false
+	}
+
+	for _, tag := range []string{"h1", "h2", "h3", "h4", "h5", "h6", "ul", "ol", "pre", "table", "blockquote"} {
+		if countHTMLTag(slideHTML, tag) > 0 {
+			return false
+		}
+	}
+
+	text := strings.TrimSpace(html.UnescapeString(htmlTagPattern.ReplaceAllString(slideHTML, "")))
+	return utf8.RuneCountInString(strings.Join(strings.Fields(text), " ")) < imageFocusMaxText
+}
+
+// statMaxLength is the number of characters of the longest number a
+// big-stat slide is detected for.
+const statMaxLength = 12
+
+// statCaptionMaxText is the number of characters of text that the caption of
+// a big-stat slide has fewer of.
+const statCaptionMaxText = 80
+
+// statValuePattern matches a number as shown on a big-stat slide: digits
+// with optional separators, a currency sign, a sign, a k/M/B magnitude, and
+// a %, x, or + suffix. Examples: 42%, $1.2M, 10x, 500+, -3.5%
+var statValuePattern = regexp.MustCompile(`^[+-]?[$€£]?\d[\d.,]*[kKMB]?(?:%|x|×|\+)?$`)
+
+// statBlockPattern matches a heading or paragraph, capturing its content.
+var statBlockPattern = regexp.MustCompile(`(?s)<(h[1-6]|p)(?:\s[^>]*)?>(.*?)</(?:h[1-6]|p)>`)
+
+// extractStat returns the number and caption of a slide that is only a short
+// number, such as "42%", in a heading or paragraph, optionally followed by
+// one short line of text. Other slides, including any with lists, code, or
+// images, return nil.
+func extractStat(slide parser.Slide) *Stat {
+	if len(slide.CodeBlocks) > 0 {
+		return nil
+	}
+	for _, tag := range []string{"img", "video", "iframe", "svg"} {
+		if countHTMLTag(slide.HTML, tag) > 0 {
+			return nil
+		}
+	}
+
+	matches := statBlockPattern.FindAllStringSubmatch(slide.HTML, -1)
+	if len(matches) == 0 || len(matches) > 2 {
+		return nil
+	}
+	if strings.TrimSpace(statBlockPattern.ReplaceAllString(slide.HTML, "")) != "" {
+		return nil
+	}
+
+	value := blockText(matches[0][2])
+	if utf8.RuneCountInString(value) > statMaxLength || !statValuePattern.MatchString(value) {
+		return nil
+	}
+
+	stat := &Stat{Value: value}
+	if len(matches) == 2 {
+		if strings.Contains(matches[1][2], "<br") {
+			return nil
+		}
+		stat.Caption = blockText(matches[1][2])
+		if utf8.RuneCountInString(stat.Caption) >= statCaptionMaxText {
+			return nil
+		}
+	}
+	return stat
+}
+
+// blockText returns the text of the HTML content of a block, with tags
+// removed and whitespace collapsed.
+func blockText(content string) string {
+	text := html.UnescapeString(htmlTagPattern.ReplaceAllString(content, ""))
+	return strings.Join(strings.Fields(text), " ")
+}
+
 // countHTMLTag counts occurrences of an HTML tag (opening tags only).
 func countHTMLTag(html, tag string) int {
 	count := 0
@@ -360,70 +735,98 @@ func countHTMLTag(html, tag string) int {
 // columnSeparatorPattern matches ||| in HTML, possibly wrapped in <p> tags.
 var columnSeparatorPattern = regexp.MustCompile(`(?s)<p>\s*\|\|\|\s*</p>|\|\|\|`)
 
+// codeElementPattern matches <pre> and <code> elements, whose contents are never split into columns.
+var codeElementPattern = regexp.MustCompile(`(?s)<pre[\s>].*?</pre>|<code[\s>].*?</code>`)
+
+// splitColumns splits HTML at ||| separators, ignoring separators inside code.
+// An inline separator (<p>Left ||| Right</p>) closes the paragraph on the left
+// and reopens it on the right, so each part is well-formed.
+func splitColumns(html string) []string {
+	codeRanges := codeElementPattern.FindAllStringIndex(html, -1)
+	inCode := func(pos int) bool {
+		for _, r := range codeRanges {
+			if pos >= r[0] && pos < r[1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var parts []string
+	reopen := ""
+	last := 0
+	for _, loc := range columnSeparatorPattern.FindAllStringIndex(html, -1) {
+		if inCode(loc[0]) {
+			continue
+		}
+
+		part := reopen + html[last:loc[0]]
+		reopen = ""
+		if html[loc[0]] != '<' && strings.Count(part, "<p>") > strings.Count(part, "</p>") {
+			// Inline separator inside a paragraph
+			part = strings.TrimRight(part, " \t") + "</p>"
+			reopen = "<p>"
+		}
+		parts = append(parts, cleanColumn(part))
+		last = loc[1]
+		if reopen != "" {
+			last += len(html[last:]) - len(strings.TrimLeft(html[last:], " \t"))
+		}
+	}
+	parts = append(parts, cleanColumn(reopen+html[last:]))
+
+	return parts
+}
+
+// cleanColumn trims whitespace and paragraphs left empty by an inline separator.
+func cleanColumn(html string) string {
+	html = strings.TrimSpace(html)
+	html = strings.TrimSpace(strings.TrimPrefix(html, "<p></p>"))
+	return strings.TrimSpace(strings.TrimSuffix(html, "<p></p>"))
+}
+
 // processTwoColumnHTML transforms HTML content for two-column layout.
 // It finds the ||| separator and wraps content before and after in column divs.
-func processTwoColumnHTML(html string) string {
-	// Find the separator
-	loc := columnSeparatorPattern.FindStringIndex(html)
-	if loc == nil {
+// It returns the processed HTML and the HTML of each column.
+func processTwoColumnHTML(html string) (string, []string) {
+	parts := splitColumns(html)
+	if len(parts) < 2 {
 		// No separator found, return as-is
-		return html
+		return html, nil
 	}
 
-	// Split at the first separator
-	leftContent := strings.TrimSpace(html[:loc[0]])
-	rightContent := strings.TrimSpace(html[loc[1]:])
-
-	// Check for a second separator (for content before the columns)
-	loc2 := columnSeparatorPattern.FindStringIndex(rightContent)
-	if loc2 != nil {
-		// Three parts: header, left column, right column
-		// The first part (leftContent) is the header
-		// The middle part is the actual left column
-		// The last part is the right column
-		headerContent := leftContent
-		leftContent = strings.TrimSpace(rightContent[:loc2[0]])
-		rightContent = strings.TrimSpace(rightContent[loc2[1]:])
-
-		return headerContent + "\n" +
-			`<div class="column column-left">` + leftContent + `</div>` +
-			`<div class="column column-right">` + rightContent + `</div>`
+	// Three parts: header, left column, right column
+	header := ""
+	if len(parts) > 2 {
+		header = parts[0] + "\n"
+		parts = parts[1:]
 	}
+	leftContent := parts[0]
+	rightContent := strings.Join(parts[1:], "\n")
 
-	// Two parts: left column, right column
-	return `<div class="column column-left">` + leftContent + `</div>` +
-		`<div class="column column-right">` + rightContent + `</div>`
+	return header +
+		`<div class="column column-left">` + leftContent + `</div>` +
+		`<div class="column column-right">` + rightContent + `</div>`, []string{leftContent, rightContent}
 }
 
 // processThreeColumnHTML transforms HTML content for three-column layout.
 // It finds the ||| separators and wraps content in column divs.
-func processThreeColumnHTML(html string) string {
-	// Find the first separator
-	loc1 := columnSeparatorPattern.FindStringIndex(html)
-	if loc1 == nil {
+// It returns the processed HTML and the HTML of each column.
+func processThreeColumnHTML(html string) (string, []string) {
+	parts := splitColumns(html)
+	if len(parts) < 2 {
 		// No separator found, return as-is
-		return html
+		return html, nil
 	}
-
-	// Split at the first separator
-	leftContent := strings.TrimSpace(html[:loc1[0]])
-	remaining := strings.TrimSpace(html[loc1[1]:])
-
-	// Find the second separator
-	loc2 := columnSeparatorPattern.FindStringIndex(remaining)
-	if loc2 == nil {
-		// Only two parts, treat as two-column
-		return `<div class="column">` + leftContent + `</div>` +
-			`<div class="column">` + remaining + `</div>`
+	if len(parts) > 3 {
+		parts = append(parts[:2], strings.Join(parts[2:], "\n"))
 	}
 
-	// Three parts
-	middleContent := strings.TrimSpace(remaining[:loc2[0]])
-	rightContent := strings.TrimSpace(remaining[loc2[1]:])
-
-	return `<div class="column">` + leftContent + `</div>` +
-		`<div class="column">` + middleContent + `</div>` +
-		`<div class="column">` + rightContent + `</div>`
+	var b strings.Builder
+	for _, part := range parts {
+		b.WriteString(`<div class="column">` + part + `</div>`)
+	}
+	return b.String(), parts
 }
 
 // parseBackground parses a background directive value and determines its type.
@@ -432,8 +835,12 @@ func (t *Transformer) parseBackground(value string) *BackgroundConfig {
 	bgType := "color"
 	resolvedValue := value
 
-	// Check for image (URL or file path)
-	if isImageURL(value) {
+	// Check for video before image, since any URL is taken for an image
+	if IsVideoPath(value) {
+		bgType = "video"
+		// Resolve relative video paths to /local/ URLs like images
+		resolvedValue = t.resolveVideoPath(value)
+	} else if isImageURL(value) {
 		bgType = "image"
 		// Resolve relative image paths to /local/ URLs
 		resolvedValue = t.resolveImagePath(value)
@@ -465,6 +872,25 @@ func isImageURL(value string) bool {
 	return false
 }
 
+// videoExtensions lists the video formats supported as backgrounds.
+var videoExtensions = []string{".mp4", ".webm", ".mov"}
+
+// IsVideoPath reports whether a file path or URL has a video extension, so
+// a background with it is a video. A query string or fragment in a URL is
+// ignored.
+func IsVideoPath(value string) bool {
+	if i := strings.IndexAny(value, "?#"); i >= 0 && isAbsoluteURL(value) {
+		value = value[:i]
+	}
+	lowerValue := strings.ToLower(value)
+	for _, ext := range videoExtensions {
+		if strings.HasSuffix(lowerValue, ext) {
+			return true
+		}
+	}
+	return false
+}
+
 // isGradient checks if the value looks like a CSS gradient.
 func isGradient(value string) bool {
 	gradientPrefixes := []string{"linear-gradient(", "radial-gradient(", "conic-gradient("}
@@ -496,9 +922,9 @@ func (t *Transformer) resolveImagePaths(html string) string {
 			return match
 		}
 
-		prefix := submatches[1]  // <img ... src="
-		src := submatches[2]     // the path
-		suffix := submatches[3]  // " ...>
+		prefix := submatches[1] // <img ... src="
+		src := submatches[2]    // the path
+		suffix := submatches[3] // " ...>
 
 		resolved := t.resolveImagePath(src)
 		return prefix + resolved + suffix
@@ -536,7 +962,20 @@ func (t *Transformer) resolveImagePath(path string) string {
 		return path
 	}
 
-	// Convert to /local/ URL for the dev server
+	return localURL(path)
+}
+
+// resolveVideoPath resolves a video path like resolveImagePath resolves
+// image paths: relative paths become /local/ URLs for the dev server.
+func (t *Transformer) resolveVideoPath(path string) string {
+	if path == "" || isAbsoluteURL(path) || filepath.IsAbs(path) || t.baseDir == "" {
+		return path
+	}
+	return localURL(path)
+}
+
+// localURL converts a relative file path to a /local/ URL for the dev server.
+func localURL(path string) string {
 	// Clean the path to remove . and .. components
 	cleanPath := filepath.Clean(path)
 	// Convert Windows backslashes to forward slashes for URL
@@ -2,10 +2,16 @@ package transformer
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/MiniCodeMonkey/tap/internal/config"
 	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/themes"
 )
 
 func TestNewTransformer(t *testing.T) {
@@ -116,6 +122,33 @@ func TestTransformWithDirectives(t *testing.T) {
 	}
 }
 
+func TestTransformHiddenSlide(t *testing.T) {
+	tr := New(config.DefaultConfig())
+
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>Visible</h1>"},
+			{Index: 1, HTML: "<h1>Backup</h1>", Directives: parser.SlideDirectives{Hidden: true}},
+		},
+	}
+
+	result := tr.Transform(pres)
+	if result.Slides[0].Hidden {
+		t.Error("expected first slide not to be hidden")
+	}
+	if !result.Slides[1].Hidden {
+		t.Error("expected second slide to be hidden")
+	}
+
+	data, err := json.Marshal(result.Slides[0])
+	if err != nil {
+		t.Fatalf("failed to marshal slide: %v", err)
+	}
+	if strings.Contains(string(data), `"hidden"`) {
+		t.Errorf("hidden should be omitted for visible slides: %s", data)
+	}
+}
+
 func TestTransformWithDefaultTransition(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.Transition = "zoom"
@@ -176,6 +209,49 @@ func TestTransformWithFragments(t *testing.T) {
 	}
 }
 
+func TestTransformWithListItemFragments(t *testing.T) {
+	pres, err := parser.New().Parse([]byte("<!-- fragments: true -->\n\n- One\n- Two\n  - Nested"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	result := New(config.DefaultConfig()).Transform(pres)
+	slide := result.Slides[0]
+
+	if len(slide.Fragments) != 2 {
+		t.Fatalf("expected 2 fragments, got %d", len(slide.Fragments))
+	}
+	if slide.Fragments[0].Content != "One" || slide.Fragments[1].Index != 1 || !slide.Fragments[0].Inline {
+		t.Errorf("unexpected fragments: %+v", slide.Fragments)
+	}
+	if !strings.Contains(slide.Fragments[1].Content, "<li>Nested</li>") {
+		t.Errorf("nested list should stay in its parent fragment, got %q", slide.Fragments[1].Content)
+	}
+	if !strings.Contains(slide.HTML, `data-fragment-index="1"`) {
+		t.Errorf("expected fragment attributes in slide HTML, got %q", slide.HTML)
+	}
+}
+
+func TestTransformCustomThemeStylesheets(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SetCustomThemes([]themes.Theme{
+		{Name: "my-brand", Path: filepath.Join("/slides", "themes", "my-brand.css")},
+		{Name: "outside", Path: filepath.Join("/elsewhere", "outside.css")},
+	})
+	pres := &parser.Presentation{Slides: []parser.Slide{{Index: 0, HTML: "<p>Content</p>"}}}
+
+	result := NewWithBaseDir(cfg, "/slides").Transform(pres)
+	want := map[string]string{"my-brand": "/local/themes/my-brand.css"}
+	if !reflect.DeepEqual(result.Themes, want) {
+		t.Errorf("Themes = %v, want %v", result.Themes, want)
+	}
+
+	// Without a base directory stylesheets can't be served
+	if result := New(cfg).Transform(pres); result.Themes != nil {
+		t.Errorf("expected no themes without a base directory, got %v", result.Themes)
+	}
+}
+
 func TestTransformWithCodeBlocks(t *testing.T) {
 	cfg := config.DefaultConfig()
 	tr := New(cfg)
@@ -192,6 +268,7 @@ func TestTransformWithCodeBlocks(t *testing.T) {
 						Meta: parser.CodeBlockMeta{
 							Driver:     "mysql",
 							Connection: "prod",
+							Highlight:  []int{1},
 						},
 					},
 				},
@@ -219,6 +296,9 @@ func TestTransformWithCodeBlocks(t *testing.T) {
 	if block.Connection != "prod" {
 		t.Errorf("expected connection 'prod', got %q", block.Connection)
 	}
+	if len(block.Highlight) != 1 || block.Highlight[0] != 1 {
+		t.Errorf("expected highlight [1], got %v", block.Highlight)
+	}
 }
 
 func TestTransformMultipleSlides(t *testing.T) {
@@ -294,6 +374,10 @@ func TestBackgroundTypeDetection(t *testing.T) {
 		{"linear-gradient(to right, red, blue)", "gradient"},
 		{"radial-gradient(circle, red, blue)", "gradient"},
 		{"conic-gradient(red, blue)", "gradient"},
+		{"./media/loop.mp4", "video"},
+		{"intro.webm", "video"},
+		{"clip.MOV", "video"},
+		{"https://example.com/loop.mp4?v=2", "video"},
 	}
 
 	for _, tc := range testCases {
@@ -322,6 +406,41 @@ func TestBackgroundTypeDetection(t *testing.T) {
 	}
 }
 
+func TestVideoBackground(t *testing.T) {
+	loop, unmuted := true, false
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, Directives: parser.SlideDirectives{Background: "./media/loop.mp4"}},
+			{Index: 1, Directives: parser.SlideDirectives{Background: "media/loop.mp4", BackgroundLoop: &loop, BackgroundMuted: &unmuted}},
+			{Index: 2, Directives: parser.SlideDirectives{Background: "https://example.com/loop.webm"}},
+			{Index: 3, Directives: parser.SlideDirectives{Background: "hero.png", BackgroundLoop: &loop}},
+		},
+	}
+
+	result := NewWithBaseDir(config.DefaultConfig(), "/presentation").Transform(pres)
+
+	want := []BackgroundConfig{
+		{Value: "/local/media/loop.mp4", Type: "video", Muted: true},
+		{Value: "/local/media/loop.mp4", Type: "video", Loop: true},
+		{Value: "https://example.com/loop.webm", Type: "video", Muted: true},
+		{Value: "/local/hero.png", Type: "image"},
+	}
+	for i, bg := range want {
+		if got := result.Slides[i].Background; got == nil || *got != bg {
+			t.Errorf("slide %d background = %+v, want %+v", i+1, got, bg)
+		}
+	}
+
+	// Playback options are left out of the JSON of other backgrounds
+	data, err := json.Marshal(result.Slides[3].Background)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"value":"/local/hero.png","type":"image"}` {
+		t.Errorf("image background JSON = %s", data)
+	}
+}
+
 func TestTransformJSONSerializable(t *testing.T) {
 	cfg := config.DefaultConfig()
 	cfg.Title = "JSON Test"
@@ -371,6 +490,121 @@ func TestTransformJSONSerializable(t *testing.T) {
 	if unmarshaled.Slides[0].Layout != "title" {
 		t.Errorf("slide layout not preserved: got %q", unmarshaled.Slides[0].Layout)
 	}
+	if !reflect.DeepEqual(unmarshaled.TOC, result.TOC) || len(unmarshaled.TOC) != 1 {
+		t.Errorf("TOC not preserved: got %+v, want %+v", unmarshaled.TOC, result.TOC)
+	}
+}
+
+func TestValidateConnections(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Connections = map[string]config.ConnectionConfig{
+		"production": {Driver: "postgres", Password: "secret"},
+	}
+	tr := New(cfg)
+
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{CodeBlocks: []parser.CodeBlock{
+				{Language: "sql", Meta: parser.CodeBlockMeta{Driver: "postgres", Connection: "production"}},
+				{Language: "go", Code: "fmt.Println()"},
+			}},
+			{CodeBlocks: []parser.CodeBlock{
+				{Language: "sql", Meta: parser.CodeBlockMeta{Driver: "postgres", Connection: "staging"}},
+			}},
+		},
+	}
+
+	err := tr.ValidateConnections(pres)
+	if err == nil {
+		t.Fatal("expected error for undefined connection")
+	}
+	if !strings.Contains(err.Error(), "slide 2") || !strings.Contains(err.Error(), `"staging"`) {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	pres.Slides = pres.Slides[:1]
+	if err := tr.ValidateConnections(pres); err != nil {
+		t.Errorf("expected no error, got %v", err)
+	}
+
+	// Connection credentials must never reach the presentation JSON
+	data, err := json.Marshal(tr.Transform(pres))
+	if err != nil {
+		t.Fatalf("failed to marshal to JSON: %v", err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("presentation JSON contains connection password: %s", data)
+	}
+}
+
+func TestTransformRunnableCodeBlocks(t *testing.T) {
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{CodeBlocks: []parser.CodeBlock{
+				{Language: "sql", Code: "SELECT 1;", Meta: parser.CodeBlockMeta{Driver: "sqlite"}},
+				{Language: "go", Code: "fmt.Println()"},
+			}},
+		},
+	}
+
+	tr := New(config.DefaultConfig())
+	blocks := tr.Transform(pres).Slides[0].CodeBlocks
+	if blocks[0].Runnable || blocks[1].Runnable {
+		t.Error("expected no runnable blocks when execution is not allowed")
+	}
+
+	tr.SetAllowExec(true)
+	blocks = tr.Transform(pres).Slides[0].CodeBlocks
+	if !blocks[0].Runnable {
+		t.Error("expected block with a driver to be runnable")
+	}
+	if blocks[1].Runnable {
+		t.Error("expected block without a driver not to be runnable")
+	}
+}
+
+func TestTransformMermaidCodeBlocks(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("..", "..", "testdata", "mermaid-test.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pres, err := parser.New().Parse(content)
+	if err != nil {
+		t.Fatalf("failed to parse fixture: %v", err)
+	}
+
+	result := New(config.DefaultConfig()).Transform(pres)
+	var diagrams []int
+	for _, slide := range result.Slides {
+		for _, block := range slide.CodeBlocks {
+			switch {
+			case block.Language == "mermaid" && block.Render == RenderMermaid:
+				diagrams = append(diagrams, slide.Index)
+			case block.Render != "":
+				t.Errorf("expected no render hint for %s code, got %q", block.Language, block.Render)
+			}
+		}
+	}
+	if !reflect.DeepEqual(diagrams, []int{1, 3}) {
+		t.Errorf("expected mermaid diagrams on slides 1 and 3, got %v", diagrams)
+	}
+	if !UsesMermaid(result) {
+		t.Error("expected the presentation to use mermaid")
+	}
+
+	// The hint is case-insensitive, and omitted from the JSON of other blocks
+	plain := New(config.DefaultConfig()).Transform(&parser.Presentation{
+		Slides: []parser.Slide{{CodeBlocks: []parser.CodeBlock{{Language: "go", Code: "x := 1"}}}},
+	})
+	if UsesMermaid(plain) {
+		t.Error("expected a presentation without diagrams not to use mermaid")
+	}
+	if data, _ := json.Marshal(plain.Slides[0].CodeBlocks[0]); containsField(string(data), "render") {
+		t.Errorf("expected no render field, got %s", data)
+	}
+	if got := renderHint("Mermaid"); got != RenderMermaid {
+		t.Errorf("renderHint(Mermaid) = %q, want %q", got, RenderMermaid)
+	}
 }
 
 func TestTransformNoBackgroundWhenEmpty(t *testing.T) {
@@ -395,6 +629,27 @@ func TestTransformNoBackgroundWhenEmpty(t *testing.T) {
 	}
 }
 
+func TestTransformSlideLines(t *testing.T) {
+	pres, err := parser.New().Parse([]byte("---\ntitle: Test\n---\n\n# One\n\n---\n\n# Two\n\nText"))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	result := New(config.DefaultConfig()).Transform(pres)
+
+	slide := result.Slides[1]
+	if slide.StartLine != 9 || slide.EndLine != 11 {
+		t.Errorf("lines = %d-%d, want 9-11", slide.StartLine, slide.EndLine)
+	}
+
+	data, err := json.Marshal(slide)
+	if err != nil {
+		t.Fatalf("failed to marshal slide: %v", err)
+	}
+	if !strings.Contains(string(data), `"startLine":9,"endLine":11`) {
+		t.Errorf("expected the lines in the slide JSON, got %s", data)
+	}
+}
+
 func TestTransformNoFragmentsOmittedInJSON(t *testing.T) {
 	cfg := config.DefaultConfig()
 	tr := New(cfg)
@@ -529,6 +784,52 @@ func TestDetectLayoutTitle(t *testing.T) {
 	}
 }
 
+func TestDetectLayoutContainers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "text in quote container",
+			content:  ":::quote\nThe best way to predict the future is to invent it.\n\nAlan Kay\n:::",
+			expected: "quote",
+		},
+		{
+			name:     "blockquote in quote container",
+			content:  ":::quote\n> Simplicity is prerequisite for reliability.\n:::",
+			expected: "quote",
+		},
+		{
+			name:     "quote container with heading before it",
+			content:  "## Quotes\n\n:::quote\nText\n:::",
+			expected: "default",
+		},
+		{
+			name:     "warning container",
+			content:  ":::warning\nDon't do this live\n:::",
+			expected: "default",
+		},
+		{
+			name:     "title in note container",
+			content:  ":::note\n# Welcome\n:::",
+			expected: "title",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pres, err := parser.New().Parse([]byte(tc.content))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if got := detectLayout(pres.Slides[0]); got != tc.expected {
+				t.Errorf("detectLayout() = %q, want %q", got, tc.expected)
+			}
+		})
+	}
+}
+
 func TestDetectLayoutSection(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -739,6 +1040,105 @@ func TestDetectLayoutTwoColumn(t *testing.T) {
 	}
 }
 
+func TestDetectLayoutImageFocus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		html     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "Image only",
+			html:     "<p><img src=\"https://example.com/hero.png\" alt=\"Hero\"></p>",
+			content:  "![Hero](https://example.com/hero.png)",
+			expected: "image-focus",
+		},
+		{
+			name:     "Image with short caption",
+			html:     "<p><img src=\"https://example.com/hero.png\" alt=\"Hero\"></p>\n<p><em>The launch, March 2024</em></p>",
+			content:  "![Hero](https://example.com/hero.png)\n\n*The launch, March 2024*",
+			expected: "image-focus",
+		},
+		{
+			name:     "Image with long text - not image-focus",
+			html:     "<p><img src=\"https://example.com/hero.png\" alt=\"Hero\"></p>\n<p>This paragraph explains the diagram in a lot more detail than a caption would, so it needs room.</p>",
+			content:  "![Hero](https://example.com/hero.png)\n\nThis paragraph explains the diagram in a lot more detail than a caption would, so it needs room.",
+			expected: "default",
+		},
+		{
+			name:     "Image with bullet list - not image-focus",
+			html:     "<p><img src=\"https://example.com/hero.png\" alt=\"Hero\"></p>\n<ul>\n<li>Fast</li>\n<li>Cheap</li>\n</ul>",
+			content:  "![Hero](https://example.com/hero.png)\n\n- Fast\n- Cheap",
+			expected: "default",
+		},
+		{
+			name:     "Image with header - not image-focus",
+			html:     "<h3>Architecture</h3>\n<p><img src=\"https://example.com/diagram.png\" alt=\"Diagram\"></p>",
+			content:  "### Architecture\n\n![Diagram](https://example.com/diagram.png)",
+			expected: "default",
+		},
+		{
+			name:     "Two images - not image-focus",
+			html:     "<p><img src=\"https://example.com/a.png\" alt=\"A\"> <img src=\"https://example.com/b.png\" alt=\"B\"></p>",
+			content:  "![A](https://example.com/a.png) ![B](https://example.com/b.png)",
+			expected: "default",
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	tr := New(cfg)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pres := &parser.Presentation{
+				Slides: []parser.Slide{
+					{Index: 0, HTML: tc.html, Content: tc.content},
+				},
+			}
+			result := tr.Transform(pres)
+			if result.Slides[0].Layout != tc.expected {
+				t.Errorf("expected layout %q, got %q", tc.expected, result.Slides[0].Layout)
+			}
+		})
+	}
+}
+
+func TestImageFocusImageSrc(t *testing.T) {
+	cfg := config.DefaultConfig()
+	tr := NewWithBaseDir(cfg, "/presentations")
+
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{
+				Index:   0,
+				HTML:    "<p><img src=\"images/hero.png\" alt=\"Hero\"></p>",
+				Content: "![Hero](images/hero.png)",
+			},
+			{
+				Index:   1,
+				HTML:    "<p><img src=\"images/hero.png\" alt=\"Hero\"></p>",
+				Content: "![Hero](images/hero.png)",
+				Directives: parser.SlideDirectives{
+					Layout: "cover",
+				},
+			},
+		},
+	}
+
+	result := tr.Transform(pres)
+	if result.Slides[0].ImageSrc != "/local/images/hero.png" {
+		t.Errorf("expected ImageSrc '/local/images/hero.png', got %q", result.Slides[0].ImageSrc)
+	}
+
+	// The directive wins over detection, and other layouts have no ImageSrc
+	if result.Slides[1].Layout != "cover" {
+		t.Errorf("directive should override auto-detection: expected 'cover', got %q", result.Slides[1].Layout)
+	}
+	if result.Slides[1].ImageSrc != "" {
+		t.Errorf("expected no ImageSrc for the cover layout, got %q", result.Slides[1].ImageSrc)
+	}
+}
+
 func TestDetectLayoutDefault(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -790,6 +1190,142 @@ func TestDetectLayoutDefault(t *testing.T) {
 	}
 }
 
+func TestDetectLayoutBigStat(t *testing.T) {
+	testCases := []struct {
+		name    string
+		html    string
+		content string
+		layout  string
+		stat    *Stat
+	}{
+		{
+			name:    "Percent heading with caption",
+			html:    `<h1 id="42">42%</h1>` + "\n<p>of developers use Go daily</p>",
+			content: "# 42%\n\nof developers use Go daily",
+			layout:  "big-stat",
+			stat:    &Stat{Value: "42%", Caption: "of developers use Go daily"},
+		},
+		{
+			name:    "Currency paragraph alone",
+			html:    "<p><strong>$1.2M</strong></p>",
+			content: "**$1.2M**",
+			layout:  "big-stat",
+			stat:    &Stat{Value: "$1.2M"},
+		},
+		{
+			name:    "Multiplier with heading caption",
+			html:    "<h2>10x</h2>\n<h3>faster builds</h3>",
+			content: "## 10x\n\n### faster builds",
+			layout:  "big-stat",
+			stat:    &Stat{Value: "10x", Caption: "faster builds"},
+		},
+		{
+			name:    "Plus suffix and thousands separator",
+			html:    "<h1>1,500+</h1>\n<p>contributors</p>",
+			content: "# 1,500+\n\ncontributors",
+			layout:  "big-stat",
+			stat:    &Stat{Value: "1,500+", Caption: "contributors"},
+		},
+		{
+			name:    "Number too long",
+			html:    "<h1>1,234,567,890.12%</h1>",
+			content: "# 1,234,567,890.12%",
+			layout:  "title",
+		},
+		{
+			name:    "Words, not a number",
+			html:    "<h1>Version 2</h1>\n<p>What changed</p>",
+			content: "# Version 2\n\nWhat changed",
+			layout:  "title",
+		},
+		{
+			name:    "Caption too long",
+			html:    "<h1>42%</h1>\n<p>" + strings.Repeat("of developers ", 8) + "</p>",
+			content: "# 42%\n\n" + strings.Repeat("of developers ", 8),
+			layout:  "title",
+		},
+		{
+			name:    "Caption on two lines",
+			html:    "<h1>42%</h1>\n<p>of developers<br>\nuse Go</p>",
+			content: "# 42%\n\nof developers  \nuse Go",
+			layout:  "title",
+		},
+		{
+			name:    "More than one line of text",
+			html:    "<h1>42%</h1>\n<p>of developers</p>\n<p>use Go daily</p>",
+			content: "# 42%\n\nof developers\n\nuse Go daily",
+			layout:  "default",
+		},
+		{
+			name:    "Number with a list",
+			html:    "<h1>42%</h1>\n<ul>\n<li>of developers</li>\n</ul>",
+			content: "# 42%\n\n- of developers",
+			layout:  "default",
+		},
+		{
+			name:    "Number with an image",
+			html:    `<p>42%</p>` + "\n" + `<p><img src="chart.png" alt="Chart"></p>`,
+			content: "42%\n\n![Chart](chart.png)",
+			layout:  "image-focus",
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	tr := New(cfg)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pres := &parser.Presentation{
+				Slides: []parser.Slide{
+					{Index: 0, HTML: tc.html, Content: tc.content},
+				},
+			}
+			slide := tr.Transform(pres).Slides[0]
+			if slide.Layout != tc.layout {
+				t.Errorf("expected layout %q, got %q", tc.layout, slide.Layout)
+			}
+			if !reflect.DeepEqual(slide.Stat, tc.stat) {
+				t.Errorf("expected stat %+v, got %+v", tc.stat, slide.Stat)
+			}
+		})
+	}
+}
+
+func TestDetectLayoutBigStatCode(t *testing.T) {
+	// Slides with code never match, even when the code is a number
+	slide := parser.Slide{
+		HTML:       "<pre><code>42</code></pre>",
+		Content:    "```\n42\n```",
+		CodeBlocks: []parser.CodeBlock{{Code: "42"}},
+	}
+	if stat := extractStat(slide); stat != nil {
+		t.Errorf("expected no stat for a code block, got %+v", stat)
+	}
+}
+
+func TestDetectLayoutBigStatDirectiveOverride(t *testing.T) {
+	tr := New(config.DefaultConfig())
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<h1>42%</h1>", Content: "# 42%", Directives: parser.SlideDirectives{Layout: "title"}},
+			{Index: 1, HTML: "<h1>42%</h1>\n<p>uptime</p>", Content: "# 42%\n\nuptime", Directives: parser.SlideDirectives{Layout: "big-stat"}},
+			{Index: 2, HTML: "<h1>Uptime</h1>", Content: "# Uptime", Directives: parser.SlideDirectives{Layout: "big-stat"}},
+		},
+	}
+	result := tr.Transform(pres)
+
+	if result.Slides[0].Layout != "title" || result.Slides[0].Stat != nil {
+		t.Errorf("expected the directive's title layout without a stat, got %q with %+v", result.Slides[0].Layout, result.Slides[0].Stat)
+	}
+	if stat := result.Slides[1].Stat; stat == nil || stat.Value != "42%" || stat.Caption != "uptime" {
+		t.Errorf("expected the stat of a big-stat directive slide, got %+v", stat)
+	}
+	// Slides set to big-stat whose content isn't a number keep their HTML as it is
+	if result.Slides[2].Layout != "big-stat" || result.Slides[2].Stat != nil {
+		t.Errorf("expected big-stat without a stat, got %q with %+v", result.Slides[2].Layout, result.Slides[2].Stat)
+	}
+}
+
 func TestDetectLayoutDirectiveOverride(t *testing.T) {
 	// Directive should always override auto-detection
 	cfg := config.DefaultConfig()
@@ -1185,3 +1721,116 @@ func TestIsSupportedImageFormat(t *testing.T) {
 		})
 	}
 }
+
+func TestTransformTwoColumnColumns(t *testing.T) {
+	testCases := []struct {
+		name    string
+		html    string
+		content string
+		layout  string
+		columns []string
+	}{
+		{
+			name:    "Separator paragraph with multiple blocks",
+			html:    "<h3>Left</h3>\n<p>One</p>\n<p>|||</p>\n<ul>\n<li>Two</li>\n</ul>\n<p>Three</p>",
+			content: "### Left\n\nOne\n\n|||\n\n- Two\n\nThree",
+			layout:  "two-column",
+			columns: []string{"<h3>Left</h3>\n<p>One</p>", "<ul>\n<li>Two</li>\n</ul>\n<p>Three</p>"},
+		},
+		{
+			name:    "Inline separator",
+			html:    "<p>Left ||| Right</p>",
+			content: "Left ||| Right",
+			layout:  "two-column",
+			columns: []string{"<p>Left</p>", "<p>Right</p>"},
+		},
+		{
+			name:    "Separator inside code block",
+			html:    "<pre><code class=\"language-text\">a ||| b\n</code></pre>",
+			content: "```text\na ||| b\n```",
+			layout:  "default",
+			columns: nil,
+		},
+		{
+			name:    "Code block in a column",
+			html:    "<pre><code>x ||| y\n</code></pre>\n<p>|||</p>\n<p>Right</p>",
+			content: "```\nx ||| y\n```\n\n|||\n\nRight",
+			layout:  "two-column",
+			columns: []string{"<pre><code>x ||| y\n</code></pre>", "<p>Right</p>"},
+		},
+	}
+
+	cfg := config.DefaultConfig()
+	tr := New(cfg)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			slide := parser.Slide{Index: 0, HTML: tc.html, Content: tc.content}
+			result := tr.Transform(&parser.Presentation{Slides: []parser.Slide{slide}})
+			got := result.Slides[0]
+
+			if got.Layout != tc.layout {
+				t.Errorf("expected layout %q, got %q", tc.layout, got.Layout)
+			}
+			if !reflect.DeepEqual(got.Columns, tc.columns) {
+				t.Errorf("expected columns %q, got %q", tc.columns, got.Columns)
+			}
+			if tc.columns != nil && strings.Contains(strings.ReplaceAll(got.HTML, "x ||| y", ""), "|||") {
+				t.Errorf("separator should be removed from HTML, got %q", got.HTML)
+			}
+		})
+	}
+}
+
+func TestDetectLayoutEmoji(t *testing.T) {
+	testCases := []struct {
+		name     string
+		markdown string
+		layout   string
+	}{
+		{"Only an emoji shortcode", ":rocket:", "default"},
+		{"Emoji title", "# :tada:", "title"},
+		{"Emoji section", "## :wave: Questions?", "section"},
+	}
+
+	tr := New(config.DefaultConfig())
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pres, err := parser.New().Parse([]byte(tc.markdown))
+			if err != nil {
+				t.Fatalf("Parse() returned error: %v", err)
+			}
+			result := tr.Transform(pres)
+
+			// Emoji are text, not images, so they don't make image-focus slides
+			if result.Slides[0].Layout != tc.layout {
+				t.Errorf("expected layout %q, got %q (HTML %q)", tc.layout, result.Slides[0].Layout, result.Slides[0].HTML)
+			}
+		})
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	pres, err := parser.New().Parse([]byte(strings.Join([]string{
+		"<!-- transition: fdae -->\n# Transition",
+		"<!-- embed: javascript:alert(1) -->\n# Embed",
+		"<!-- class: bad.name -->\n# Class",
+		"<!-- autoAdvance: soon -->\n# Auto-advance",
+		"# Fine",
+	}, "\n\n---\n\n")))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+
+	// One warning from each kind, in slide order
+	warnings := New(config.DefaultConfig()).Warnings(pres)
+	if len(warnings) != 4 {
+		t.Fatalf("Warnings() = %q, want 4 warnings", warnings)
+	}
+	for i, warning := range warnings {
+		if prefix := fmt.Sprintf("slide %d:", i+1); !strings.HasPrefix(warning, prefix) {
+			t.Errorf("warning %d = %q, want it to start with %q", i, warning, prefix)
+		}
+	}
+}
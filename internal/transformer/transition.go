@@ -0,0 +1,50 @@
+package transformer
+
+import (
+	"fmt"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// SlideTransition returns the transition set by a slide's transition
+// directive, in either its string form or its extended form. ok is false if
+// the slide has no transition directive.
+func SlideTransition(d parser.SlideDirectives) (spec config.TransitionSpec, ok bool, err error) {
+	if d.Transition == "" && d.TransitionDirection == "" && d.TransitionDuration == "" {
+		return config.TransitionSpec{}, false, nil
+	}
+	if d.TransitionDirection != "" || d.TransitionDuration != "" {
+		spec, err = config.ParseTransitionParts(d.Transition, d.TransitionDirection, d.TransitionDuration)
+	} else {
+		spec, err = config.ParseTransition(d.Transition)
+	}
+	return spec, true, err
+}
+
+// resolveTransition returns the transition of a slide: its transition
+// directive, or the presentation's transition if it has none or it is
+// invalid. A directive without a duration uses the presentation's.
+func (t *Transformer) resolveTransition(d parser.SlideDirectives) config.TransitionSpec {
+	fallback := t.config.DefaultTransition()
+	spec, ok, err := SlideTransition(d)
+	if !ok || err != nil {
+		return fallback
+	}
+	if spec.Duration == 0 {
+		spec.Duration = fallback.Duration
+	}
+	return spec
+}
+
+// TransitionWarnings returns a warning for each slide of pres with an invalid
+// transition directive, which falls back to the presentation's transition.
+func (t *Transformer) TransitionWarnings(pres *parser.Presentation) []string {
+	var warnings []string
+	for i, slide := range pres.Slides {
+		if _, _, err := SlideTransition(slide.Directives); err != nil {
+			warnings = append(warnings, fmt.Sprintf("slide %d: %v; using %s", i+1, err, t.config.DefaultTransition().Name))
+		}
+	}
+	return warnings
+}
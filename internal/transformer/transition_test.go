@@ -0,0 +1,90 @@
+package transformer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+func TestTransformTransitionSpec(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Transition = "push-up"
+	cfg.TransitionDuration = 600
+	tr := New(cfg)
+
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, HTML: "<p>Default</p>"},
+			{Index: 1, HTML: "<p>String</p>", Directives: parser.SlideDirectives{Transition: "slide-left 300ms"}},
+			{Index: 2, HTML: "<p>Extended</p>", Directives: parser.SlideDirectives{
+				Transition:          "slide",
+				TransitionDirection: "down",
+			}},
+			{Index: 3, HTML: "<p>None</p>", Directives: parser.SlideDirectives{Transition: "none"}},
+			{Index: 4, HTML: "<p>Invalid</p>", Directives: parser.SlideDirectives{Transition: "wipe"}},
+		},
+	}
+
+	result := tr.Transform(pres)
+
+	tests := []struct {
+		name       string
+		transition string
+		spec       config.TransitionSpec
+	}{
+		{"default", "push", config.TransitionSpec{Name: "push", Direction: "up", Duration: 600}},
+		{"string", "slide", config.TransitionSpec{Name: "slide", Direction: "left", Duration: 300}},
+		{"extended", "slide", config.TransitionSpec{Name: "slide", Direction: "down", Duration: 600}},
+		{"none", "none", config.TransitionSpec{Name: "none", Duration: 600}},
+		{"invalid falls back", "push", config.TransitionSpec{Name: "push", Direction: "up", Duration: 600}},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slide := result.Slides[i]
+			if slide.Transition != tt.transition {
+				t.Errorf("Transition = %q, want %q", slide.Transition, tt.transition)
+			}
+			if slide.TransitionSpec == nil {
+				t.Fatal("expected TransitionSpec to be set")
+			}
+			if *slide.TransitionSpec != tt.spec {
+				t.Errorf("TransitionSpec = %+v, want %+v", *slide.TransitionSpec, tt.spec)
+			}
+		})
+	}
+
+	data, err := json.Marshal(result.Slides[1])
+	if err != nil {
+		t.Fatalf("failed to marshal slide: %v", err)
+	}
+	if !strings.Contains(string(data), `"transitionSpec":{"name":"slide","direction":"left","duration":300}`) {
+		t.Errorf("expected transitionSpec in JSON, got %s", data)
+	}
+}
+
+func TestTransitionWarnings(t *testing.T) {
+	tr := New(config.DefaultConfig())
+
+	pres := &parser.Presentation{
+		Slides: []parser.Slide{
+			{Index: 0, Directives: parser.SlideDirectives{Transition: "slide-up 200ms"}},
+			{Index: 1, Directives: parser.SlideDirectives{Transition: "fdae"}},
+			{Index: 2},
+			{Index: 3, Directives: parser.SlideDirectives{Transition: "slide", TransitionDuration: "quick"}},
+		},
+	}
+
+	warnings := tr.TransitionWarnings(pres)
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, got %d: %v", len(warnings), warnings)
+	}
+	if !strings.HasPrefix(warnings[0], `slide 2: invalid transition "fdae"`) || !strings.HasSuffix(warnings[0], "; using fade") {
+		t.Errorf("unexpected warning: %q", warnings[0])
+	}
+	if !strings.Contains(warnings[1], `slide 4: invalid transition duration "quick"`) {
+		t.Errorf("unexpected warning: %q", warnings[1])
+	}
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ConfirmModel is a yes/no dialog that guards a destructive action.
+// The action only runs when the user confirms: y confirms, n and esc cancel,
+// and enter picks the highlighted button, which is No until the user moves
+// to Yes, so a stray enter never confirms.
+type ConfirmModel struct {
+	// Title is the question shown at the top of the dialog.
+	Title string
+	// Message explains what happens on yes.
+	Message string
+	// action runs when the user confirms; the returned command is passed on.
+	action func() tea.Cmd
+	// yesSelected indicates whether the Yes button is highlighted.
+	yesSelected bool
+	// done indicates the user answered the dialog.
+	done bool
+	// confirmed indicates the user answered yes.
+	confirmed bool
+}
+
+// NewConfirmModel creates a confirm dialog that runs action on yes.
+func NewConfirmModel(title, message string, action func() tea.Cmd) *ConfirmModel {
+	return &ConfirmModel{
+		Title:   title,
+		Message: message,
+		action:  action,
+	}
+}
+
+// Done reports whether the user answered the dialog.
+func (m *ConfirmModel) Done() bool {
+	return m.done
+}
+
+// Confirmed reports whether the user answered yes.
+func (m *ConfirmModel) Confirmed() bool {
+	return m.confirmed
+}
+
+// Init implements tea.Model.
+func (m *ConfirmModel) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model.
+// Keys are ignored once the dialog is answered, so the action runs at most once.
+func (m *ConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.done {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "y", "Y":
+		return m, m.answer(true)
+	case "n", "N", "esc":
+		return m, m.answer(false)
+	case "enter":
+		return m, m.answer(m.yesSelected)
+	case "left", "right", "h", "l", "tab", "shift+tab":
+		m.yesSelected = !m.yesSelected
+	}
+	return m, nil
+}
+
+// answer closes the dialog and runs the action on yes.
+func (m *ConfirmModel) answer(yes bool) tea.Cmd {
+	m.done = true
+	m.confirmed = yes
+	if !yes || m.action == nil {
+		return nil
+	}
+	return m.action()
+}
+
+// View implements tea.Model.
+func (m *ConfirmModel) View() string {
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorWarning)
+
+	b.WriteString(titleStyle.Render(m.Title))
+	b.WriteString("\n")
+	if m.Message != "" {
+		b.WriteString("\n")
+		b.WriteString(RenderMuted(m.Message))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Buttons, with the highlighted one in the primary color
+	buttonStyle := lipgloss.NewStyle().
+		Padding(0, 2).
+		Foreground(ColorMuted)
+
+	selectedStyle := buttonStyle.
+		Bold(true).
+		Foreground(ColorWhite).
+		Background(ColorPrimary)
+
+	yes, no := buttonStyle, selectedStyle
+	if m.yesSelected {
+		yes, no = selectedStyle, buttonStyle
+	}
+	b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, yes.Render("Yes"), "  ", no.Render("No")))
+	b.WriteString("\n\n")
+
+	// Help text
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	help := fmt.Sprintf(
+		"%s yes • %s no • %s select",
+		keyStyle.Render("y"),
+		keyStyle.Render("n/esc"),
+		keyStyle.Render("←/→"),
+	)
+	b.WriteString(RenderMuted(help))
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorWarning).
+		Padding(1, 2)
+
+	return box.Render(b.String())
+}
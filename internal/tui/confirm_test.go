@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// confirmActionMsg is returned by the action of the test confirm dialogs.
+type confirmActionMsg struct{}
+
+// newTestConfirm returns a confirm dialog whose action counts its calls.
+func newTestConfirm(calls *int) *ConfirmModel {
+	return NewConfirmModel("Delete images/old.png?", "The file is removed.", func() tea.Cmd {
+		*calls++
+		return func() tea.Msg { return confirmActionMsg{} }
+	})
+}
+
+func TestConfirmModel_Keys(t *testing.T) {
+	tests := []struct {
+		name      string
+		keys      []tea.KeyMsg
+		confirmed bool
+	}{
+		{"y confirms", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("y")}}, true},
+		{"n cancels", []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("n")}}, false},
+		{"esc cancels", []tea.KeyMsg{{Type: tea.KeyEsc}}, false},
+		{"enter defaults to no", []tea.KeyMsg{{Type: tea.KeyEnter}}, false},
+		{"enter on yes confirms", []tea.KeyMsg{{Type: tea.KeyLeft}, {Type: tea.KeyEnter}}, true},
+		{"tab toggles back to no", []tea.KeyMsg{{Type: tea.KeyTab}, {Type: tea.KeyTab}, {Type: tea.KeyEnter}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			confirm := newTestConfirm(&calls)
+
+			var cmd tea.Cmd
+			for _, key := range tt.keys {
+				_, cmd = confirm.Update(key)
+			}
+
+			if !confirm.Done() {
+				t.Fatal("expected the dialog to be answered")
+			}
+			if confirm.Confirmed() != tt.confirmed {
+				t.Errorf("Confirmed() = %v, want %v", confirm.Confirmed(), tt.confirmed)
+			}
+			if tt.confirmed {
+				if calls != 1 {
+					t.Errorf("expected the action to run once, ran %d times", calls)
+				}
+				if cmd == nil {
+					t.Fatal("expected the action's command")
+				}
+				if _, ok := cmd().(confirmActionMsg); !ok {
+					t.Error("expected the action's command to be returned")
+				}
+			} else if calls != 0 || cmd != nil {
+				t.Errorf("expected the action not to run, ran %d times", calls)
+			}
+		})
+	}
+}
+
+func TestConfirmModel_IgnoresKeysOnceAnswered(t *testing.T) {
+	calls := 0
+	confirm := newTestConfirm(&calls)
+
+	confirm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	confirm.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if calls != 1 {
+		t.Errorf("expected the action to run once, ran %d times", calls)
+	}
+
+	// Other keys and messages leave the dialog open
+	open := newTestConfirm(&calls)
+	open.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	open.Update(tea.WindowSizeMsg{Width: 80, Height: 24})
+	if open.Done() {
+		t.Error("expected the dialog to stay open")
+	}
+}
+
+func TestConfirmModel_View(t *testing.T) {
+	calls := 0
+	view := newTestConfirm(&calls).View()
+
+	for _, want := range []string{"Delete images/old.png?", "The file is removed.", "Yes", "No", "n/esc"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected view to contain %q, got:\n%s", want, view)
+		}
+	}
+}
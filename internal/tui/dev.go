@@ -2,7 +2,12 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -11,10 +16,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/MiniCodeMonkey/tap/internal/config"
-	"github.com/MiniCodeMonkey/tap/internal/gemini"
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/server"
+	"github.com/MiniCodeMonkey/tap/internal/themes"
 )
 
 // ThemeBroadcaster is an interface for broadcasting theme changes via WebSocket.
@@ -22,25 +32,59 @@ type ThemeBroadcaster interface {
 	BroadcastTheme(themeName string) error
 }
 
+// SlideBroadcaster is an interface for broadcasting slide navigation via WebSocket.
+type SlideBroadcaster interface {
+	BroadcastSlide(slideIndex int) error
+}
+
+// TimerBroadcaster is an interface for broadcasting the talk timer state via WebSocket.
+type TimerBroadcaster interface {
+	BroadcastTimer(state server.TimerState) error
+}
+
+// AutoplayBroadcaster is an interface for turning auto-play of slides with
+// autoAdvance on or off via WebSocket.
+type AutoplayBroadcaster interface {
+	BroadcastAutoplay(enabled bool) error
+}
+
+// PresenterTokenRotator is an interface for replacing the presenter token and
+// disconnecting presenter views that still use the old one.
+type PresenterTokenRotator interface {
+	RotatePresenterToken() (string, error)
+}
+
 // DevConfig holds configuration for the dev TUI.
 // Fields ordered by size for memory alignment.
 type DevConfig struct {
+	CustomThemes      []Theme // Themes discovered next to the presentation, added to the theme picker
 	AudienceURL       string
 	PresenterURL      string
 	QRCodeASCII       string
 	PresenterPassword string
 	MarkdownFile      string
 	CurrentTheme      string
+	LogFile           string // File that events are mirrored to; empty to disable
+	PromptHistoryFile string // File that image prompts are recalled from and saved to; empty to disable
+	TLSFingerprint    string // SHA-256 fingerprint of the HTTPS certificate; empty when serving HTTP
 	Port              int
+	TalkDuration      time.Duration // Target length of the talk for the timer; 0 if not set
+	AudienceProtected bool          // Whether the audience view requires a password
+}
+
+// StatusSource is an interface for reading the dev server's status, which
+// the status panel shows.
+type StatusSource interface {
+	Status() server.Status
 }
 
 // DevState holds the current state of the dev server.
 // Fields ordered by size for memory alignment.
 type DevState struct {
-	Error            error
-	RecentEvents     []DevEvent
-	WebSocketClients int
-	WatcherRunning   bool
+	Error        error
+	FileWarning  error // Set while the markdown file is missing or unreadable; cleared when it's back
+	RecentEvents []DevEvent // Up to maxEvents events, oldest first
+	Status       server.Status // Last status read from the server
 }
 
 // DevEvent represents a hot reload or server event.
@@ -60,16 +104,7 @@ type devEventMsg struct {
 type pdfExportMsg struct {
 	outputPath string
 	err        error
-}
-
-// wsCountMsg is sent when WebSocket client count changes.
-type wsCountMsg struct {
-	count int
-}
-
-// watcherStatusMsg is sent when watcher status changes.
-type watcherStatusMsg struct {
-	running bool
+	reported   bool // The exporter already sent events for the result
 }
 
 // errorMsg is sent when an error occurs.
@@ -77,9 +112,31 @@ type errorMsg struct {
 	err error
 }
 
+// annotationsActiveFor is how long after the presenter view last drew on a
+// slide the status shows annotations as active.
+const annotationsActiveFor = 10 * time.Second
+
 // tickMsg is sent periodically to update the display.
 type tickMsg struct{}
 
+// networkTickMsg is sent periodically to check for network address changes.
+type networkTickMsg struct{}
+
+// networkCheckMsg is sent with the result of a network address check.
+type networkCheckMsg struct {
+	err    error
+	addrs  []string
+	manual bool // Requested with the n key rather than by the periodic check
+}
+
+// clipboardMsg is sent when copying to the clipboard completes.
+type clipboardMsg struct {
+	err error
+}
+
+// networkCheckInterval is how often the dev server checks whether its network address changed.
+const networkCheckInterval = 10 * time.Second
+
 // DevModel is the Bubble Tea model for the dev server TUI.
 type DevModel struct { //nolint:govet // embedded structs prevent optimal alignment
 	config             DevConfig
@@ -87,18 +144,54 @@ type DevModel struct { //nolint:govet // embedded structs prevent optimal alignm
 	eventsCh           chan DevEvent
 	closeCh            chan struct{}
 	themeBroadcaster   ThemeBroadcaster
+	slideBroadcaster   SlideBroadcaster
+	timerBroadcaster   TimerBroadcaster
+	autoplay           AutoplayBroadcaster
+	tokenRotator       PresenterTokenRotator
+	statusSource       StatusSource
+	remoteController   RemoteController
+	pdfExporter        PDFExporter
+	detectAddresses    func() ([]string, error)
+	now                func() time.Time
 	imageGenModel      *ImageGenModel
 	addModel           *AddModel
+	confirm            *ConfirmModel // Confirm dialog shown over everything else, if any
+	search             *SearchModel  // Slide search, while it's open
+	logFile            *os.File      // File that events are mirrored to, if any
+	logViewport        viewport.Model
+	logFilterInput     textinput.Model
+	pdfSpinner         spinner.Model // Shown in the status panel during a PDF export
+	logTypeFilter      string // Event type shown in the log panel; empty for all
+	outlineSlides      []SlideInfo
+	slideTitles        []string // Title of each slide by index, for the current slide
+	remotes            []server.Remote // Paired remotes, read from the remote controller
+	themeOptions       []Theme
 	mu                 sync.RWMutex
+	timerStarted       time.Time     // When the running timer was last started
+	timerElapsed       time.Duration // Elapsed time before timerStarted
+	timerDuration      time.Duration // Target length of the talk
+	speakingTime       time.Duration // Estimated length of the talk; 0 if unknown
 	windowWidth        int
 	windowHeight       int
 	currentTheme       string
+	previewedTheme     string // Theme the browser shows while the theme picker is open
+	themePreviewSeq    int    // Identifies the latest themePreviewMsg; older ones are dropped
+	remoteCode         string // Pairing code shown for the remote control page
 	themePickerIndex   int
+	outlineIndex       int
+	remoteIndex        int
+	currentSlide       int // Slide the browser shows; -1 if unknown
+	droppedEvents      int // Events SendEvent dropped because the channel was full
 	quitting           bool
 	showThemePicker    bool
+	showOutline        bool
+	showLog            bool
+	showRemotes        bool
 	showImageGenerator bool
 	showSlideBuilder   bool
 	exportingPDF       bool
+	timerRunning       bool
+	autoplayPaused     bool // Whether the kiosk toggle paused auto-play
 }
 
 // NewDevModel creates a new DevModel for the dev server TUI.
@@ -109,25 +202,60 @@ func NewDevModel(cfg DevConfig) *DevModel {
 		currentTheme = "paper"
 	}
 
+	// Custom themes replace built-in themes with the same name
+	themeOptions, overridden := themes.Merge(AvailableThemes, cfg.CustomThemes)
+
 	// Find the index of the current theme
 	themeIndex := 0
-	for i, t := range AvailableThemes {
+	for i, t := range themeOptions {
 		if t.Name == currentTheme {
 			themeIndex = i
 			break
 		}
 	}
 
-	return &DevModel{
+	m := &DevModel{
 		config: cfg,
 		state: DevState{
-			RecentEvents: make([]DevEvent, 0, 10),
+			RecentEvents: make([]DevEvent, 0, summaryEvents),
+			Status: server.Status{
+				MarkdownFile: cfg.MarkdownFile,
+				Theme:        currentTheme,
+			},
 		},
 		eventsCh:         make(chan DevEvent, 100),
 		closeCh:          make(chan struct{}),
+		themeOptions:     themeOptions,
 		currentTheme:     currentTheme,
 		themePickerIndex: themeIndex,
+		detectAddresses:  server.LANAddresses,
+		now:              time.Now,
+		timerDuration:    cfg.TalkDuration,
+		logViewport:      viewport.New(0, 0),
+		logFilterInput:   newLogFilterInput(),
+		pdfSpinner:       newPDFSpinner(),
+		currentSlide:     -1,
 	}
+
+	if cfg.LogFile != "" {
+		if err := m.openLogFile(cfg.LogFile); err != nil {
+			m.addEvent(DevEvent{
+				Type:      "warning",
+				Message:   err.Error(),
+				Timestamp: time.Now(),
+			})
+		}
+	}
+
+	for _, name := range overridden {
+		m.addEvent(DevEvent{
+			Type:      "warning",
+			Message:   fmt.Sprintf("Custom theme %q replaces the built-in theme", name),
+			Timestamp: time.Now(),
+		})
+	}
+
+	return m
 }
 
 // SetThemeBroadcaster sets the theme broadcaster for WebSocket communication.
@@ -135,11 +263,40 @@ func (m *DevModel) SetThemeBroadcaster(tb ThemeBroadcaster) {
 	m.themeBroadcaster = tb
 }
 
+// SetSlideBroadcaster sets the slide broadcaster for WebSocket communication.
+func (m *DevModel) SetSlideBroadcaster(sb SlideBroadcaster) {
+	m.slideBroadcaster = sb
+}
+
+// SetPresenterTokenRotator sets the rotator used to regenerate the presenter token.
+func (m *DevModel) SetPresenterTokenRotator(tr PresenterTokenRotator) {
+	m.tokenRotator = tr
+}
+
+// SetStatusSource sets the source of the server status shown in the status
+// panel, which is read every second.
+func (m *DevModel) SetStatusSource(ss StatusSource) {
+	m.statusSource = ss
+	m.refreshStatus()
+}
+
+// refreshStatus reads the server status from the status source, if set.
+func (m *DevModel) refreshStatus() {
+	if m.statusSource == nil {
+		return
+	}
+	status := m.statusSource.Status()
+	m.mu.Lock()
+	m.state.Status = status
+	m.mu.Unlock()
+}
+
 // Init implements tea.Model.
 func (m *DevModel) Init() tea.Cmd {
 	return tea.Batch(
 		m.listenForEvents(),
 		tickCmd(),
+		networkTickCmd(),
 	)
 }
 
@@ -162,8 +319,47 @@ func tickCmd() tea.Cmd {
 	})
 }
 
+// networkTickCmd returns a command that schedules the next network address check.
+func networkTickCmd() tea.Cmd {
+	return tea.Tick(networkCheckInterval, func(t time.Time) tea.Msg {
+		return networkTickMsg{}
+	})
+}
+
+// detectNetworkCmd returns a command that looks up the current LAN addresses.
+func (m *DevModel) detectNetworkCmd(manual bool) tea.Cmd {
+	detect := m.detectAddresses
+	return func() tea.Msg {
+		addrs, err := detect()
+		return networkCheckMsg{addrs: addrs, err: err, manual: manual}
+	}
+}
+
 // Update implements tea.Model.
 func (m *DevModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Network checks and PDF exports continue while an overlay is open
+	switch msg := msg.(type) {
+	case networkTickMsg:
+		return m, m.detectNetworkCmd(false)
+	case networkCheckMsg:
+		m.handleNetworkCheck(msg)
+		if msg.manual {
+			return m, nil
+		}
+		return m, networkTickCmd()
+	case pdfExportMsg:
+		return m.handlePDFExportMsg(msg)
+	case spinner.TickMsg:
+		if msg.ID == m.pdfSpinner.ID() {
+			if !m.exportingPDF {
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.pdfSpinner, cmd = m.pdfSpinner.Update(msg)
+			return m, cmd
+		}
+	}
+
 	// Forward non-key messages to image generator when active (for spinner animation, API results, etc.)
 	if m.showImageGenerator && m.imageGenModel != nil {
 		// Only forward certain message types to the image generator
@@ -175,62 +371,7 @@ func (m *DevModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			newModel, cmd := m.imageGenModel.Update(msg)
 			if newModel != nil {
 				m.imageGenModel = newModel.(*ImageGenModel)
-				// Check if generation completed successfully - save image and update markdown
-				if m.imageGenModel.Step == ImageGenStepDone && m.imageGenModel.GeneratedImage != nil && m.imageGenModel.SavedImagePath == "" {
-					// Save the generated image
-					savedPath, err := m.imageGenModel.SaveGeneratedImage()
-					if err != nil {
-						m.SetError(err)
-						m.addEvent(DevEvent{
-							Type:      "error",
-							Message:   "Failed to save generated image",
-							Timestamp: time.Now(),
-						})
-						return m, cmd
-					}
-					m.imageGenModel.SavedImagePath = savedPath
-
-					// Insert or replace image in markdown
-					if m.imageGenModel.SelectedImage != nil {
-						// Regenerating - replace existing image
-						if err := m.imageGenModel.ReplaceImageInMarkdown(savedPath); err != nil {
-							m.SetError(err)
-							m.addEvent(DevEvent{
-								Type:      "error",
-								Message:   "Failed to update markdown",
-								Timestamp: time.Now(),
-							})
-							return m, cmd
-						}
-						// Delete old image file
-						if err := m.imageGenModel.DeleteOldImage(); err != nil {
-							// Log but don't fail - the new image is already saved
-							m.addEvent(DevEvent{
-								Type:      "error",
-								Message:   "Failed to delete old image (non-fatal)",
-								Timestamp: time.Now(),
-							})
-						}
-					} else {
-						// Adding new image
-						if err := m.imageGenModel.InsertImageIntoMarkdown(savedPath); err != nil {
-							m.SetError(err)
-							m.addEvent(DevEvent{
-								Type:      "error",
-								Message:   "Failed to update markdown",
-								Timestamp: time.Now(),
-							})
-							return m, cmd
-						}
-					}
-
-					// Send reload event
-					m.addEvent(DevEvent{
-						Type:      "reload",
-						Message:   fmt.Sprintf("Generated image: %s", savedPath),
-						Timestamp: time.Now(),
-					})
-				}
+				m.commitGeneratedImage()
 			}
 			return m, cmd
 		}
@@ -262,53 +403,267 @@ func (m *DevModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case devEventMsg:
 		m.addEvent(msg.event)
+		if msg.event.Type == "reload" {
+			m.refreshSearch()
+		}
 		return m, m.listenForEvents()
 
-	case wsCountMsg:
-		m.state.WebSocketClients = msg.count
+	case errorMsg:
+		m.state.Error = msg.err
 		return m, nil
 
-	case watcherStatusMsg:
-		m.state.WatcherRunning = msg.running
+	case editorFinishedMsg:
+		if msg.err != nil {
+			m.SetError(msg.err)
+			m.addEvent(DevEvent{
+				Type:      "error",
+				Message:   fmt.Sprintf("Failed to edit slide %d", msg.slide+1),
+				Timestamp: time.Now(),
+			})
+		} else {
+			m.addEvent(DevEvent{
+				Type:      "action",
+				Message:   fmt.Sprintf("Edited slide %d → %s", msg.slide+1, filepath.Base(msg.file)),
+				Timestamp: time.Now(),
+			})
+		}
 		return m, nil
 
-	case errorMsg:
-		m.state.Error = msg.err
+	case themePreviewMsg:
+		m.previewTheme(msg)
 		return m, nil
 
-	case pdfExportMsg:
-		m.exportingPDF = false
+	case clipboardMsg:
 		if msg.err != nil {
 			m.SetError(msg.err)
 			m.addEvent(DevEvent{
 				Type:      "error",
-				Message:   "PDF export failed",
+				Message:   "Failed to copy audience URL",
 				Timestamp: time.Now(),
 			})
 		} else {
 			m.addEvent(DevEvent{
 				Type:      "action",
-				Message:   fmt.Sprintf("PDF exported → %s", msg.outputPath),
+				Message:   "Copied audience URL to clipboard",
 				Timestamp: time.Now(),
 			})
 		}
 		return m, nil
 
 	case tickMsg:
-		// Periodic tick - just redraw
+		// Periodic tick - refresh the server status and redraw
+		m.refreshStatus()
+		m.refreshRemotes()
 		return m, tickCmd()
 	}
 
 	return m, nil
 }
 
+// handleNetworkCheck updates the URLs and QR code when the LAN address changed.
+// The current address is kept while it is still available, so machines with
+// several interfaces don't switch between them. Without any LAN address the
+// URLs fall back to localhost.
+func (m *DevModel) handleNetworkCheck(msg networkCheckMsg) {
+	current := urlHost(m.config.AudienceURL)
+	if current == "" {
+		return
+	}
+
+	if msg.err != nil {
+		if msg.manual {
+			m.SetError(msg.err)
+			m.addEvent(DevEvent{
+				Type:      "error",
+				Message:   "Network detection failed",
+				Timestamp: time.Now(),
+			})
+		}
+		return
+	}
+
+	host := "localhost"
+	if len(msg.addrs) > 0 {
+		host = msg.addrs[0]
+	}
+	for _, addr := range msg.addrs {
+		if addr == current {
+			host = current
+		}
+	}
+
+	if host == current {
+		if msg.manual {
+			m.addEvent(DevEvent{
+				Type:      "action",
+				Message:   fmt.Sprintf("Network address unchanged: %s", m.config.AudienceURL),
+				Timestamp: time.Now(),
+			})
+		}
+		return
+	}
+
+	m.config.AudienceURL = replaceURLHost(m.config.AudienceURL, host)
+	m.config.PresenterURL = replaceURLHost(m.config.PresenterURL, host)
+	m.config.QRCodeASCII = AudienceQRCode(m.config.AudienceURL)
+
+	m.addEvent(DevEvent{
+		Type:      "action",
+		Message:   fmt.Sprintf("Network address changed: %s", m.config.AudienceURL),
+		Timestamp: time.Now(),
+	})
+}
+
+// AudienceQRCode returns the terminal QR code for an audience URL. It returns
+// an empty string for localhost URLs, which other devices can't open.
+func AudienceQRCode(audienceURL string) string {
+	if host := urlHost(audienceURL); host == "" || host == "localhost" {
+		return ""
+	}
+	qr, err := server.GenerateASCIIQRCode(audienceURL)
+	if err != nil {
+		return ""
+	}
+	return qr
+}
+
+// urlHost returns the host of a URL without the port, or an empty string if
+// the URL can't be parsed.
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
+
+// replaceURLHost replaces the host of a URL, keeping its port, path, and query.
+func replaceURLHost(rawURL, host string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	if port := u.Port(); port != "" {
+		u.Host = net.JoinHostPort(host, port)
+	} else {
+		u.Host = host
+	}
+	return u.String()
+}
+
+// rotatePresenterToken replaces the presenter token, which disconnects the
+// presenter views using the old one, and updates the presenter URL.
+func (m *DevModel) rotatePresenterToken() {
+	if m.tokenRotator == nil {
+		return
+	}
+
+	token, err := m.tokenRotator.RotatePresenterToken()
+	if err != nil {
+		m.SetError(err)
+		return
+	}
+
+	m.config.PresenterPassword = token
+	m.config.PresenterURL = setURLToken(m.config.PresenterURL, token)
+	m.addEvent(DevEvent{
+		Type:      "action",
+		Message:   "Presenter token regenerated; old presenter views disconnected",
+		Timestamp: time.Now(),
+	})
+}
+
+// setURLToken sets the presenter token query parameter of a URL, replacing
+// the older key parameter.
+func setURLToken(rawURL, token string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	query := u.Query()
+	query.Del("key")
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// maskToken hides all but the last four characters of a token.
+func maskToken(token string) string {
+	if len(token) <= 4 {
+		return strings.Repeat("•", len(token))
+	}
+	return strings.Repeat("•", 8) + token[len(token)-4:]
+}
+
+// shortFingerprint returns the first eight bytes of a colon-separated
+// certificate fingerprint, enough to recognize the certificate in a browser's
+// certificate viewer.
+func shortFingerprint(fingerprint string) string {
+	const shortLength = 8*3 - 1 // Eight bytes of two hex digits and a colon, without the last colon
+	if len(fingerprint) <= shortLength {
+		return fingerprint
+	}
+	return fingerprint[:shortLength] + "…"
+}
+
+// maskURLToken returns a URL with its presenter token masked, for display.
+func maskURLToken(rawURL, token string) string {
+	if token == "" {
+		return rawURL
+	}
+	return strings.Replace(rawURL, url.QueryEscape(token), maskToken(token), 1)
+}
+
 // handleKeyPress handles keyboard input.
 func (m *DevModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Handle the confirm dialog if it's open
+	if m.confirm != nil {
+		_, cmd := m.confirm.Update(msg)
+		if m.confirm.Done() {
+			m.confirm = nil
+		}
+		return m, cmd
+	}
+
+	// Quitting while an image is being generated would lose it, so ask first
+	if (msg.String() == "q" || msg.String() == "ctrl+c") && m.imageGenerationInFlight() {
+		m.confirm = NewConfirmModel(
+			"Quit while an image is being generated?",
+			"The image generation is canceled and the image is not saved.",
+			func() tea.Cmd {
+				m.imageGenModel.stopBatch() // Also cancels a single generation
+				m.quitting = true
+				return tea.Quit
+			},
+		)
+		return m, nil
+	}
+
+	// Handle slide search if it's open
+	if m.search != nil {
+		return m.handleSearchKey(msg)
+	}
+
 	// Handle theme picker if it's open
 	if m.showThemePicker {
 		return m.handleThemePickerKey(msg)
 	}
 
+	// Handle slide outline if it's open
+	if m.showOutline {
+		return m.handleOutlineKey(msg)
+	}
+
+	// Handle log panel if it's open
+	if m.showLog {
+		return m.handleLogKey(msg)
+	}
+
+	// Handle remotes panel if it's open
+	if m.showRemotes {
+		return m.handleRemotesKey(msg)
+	}
+
 	// Handle image generator if it's open
 	if m.showImageGenerator && m.imageGenModel != nil {
 		return m.handleImageGeneratorKey(msg)
@@ -349,6 +704,11 @@ func (m *DevModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		})
 		return m, nil
 
+	case "l":
+		// Show the full activity log
+		m.openLog()
+		return m, nil
+
 	case "o":
 		// Open in browser
 		m.addEvent(DevEvent{
@@ -367,6 +727,48 @@ func (m *DevModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		})
 		return m, openBrowserCmd(m.config.PresenterURL)
 
+	case "c":
+		// Copy the audience URL to share it
+		return m, copyToClipboardCmd(m.config.AudienceURL)
+
+	case "k":
+		// Regenerate the presenter token
+		m.rotatePresenterToken()
+		return m, nil
+
+	case "m":
+		// Show the paired remotes
+		if m.remoteController == nil {
+			return m, nil
+		}
+		m.refreshRemotes()
+		m.showRemotes = true
+		return m, nil
+
+	case "n":
+		// Check for a new network address now
+		m.addEvent(DevEvent{
+			Type:      "action",
+			Message:   "Checking network address...",
+			Timestamp: time.Now(),
+		})
+		return m, m.detectNetworkCmd(true)
+
+	case " ":
+		// Start or pause the talk timer
+		m.toggleTimer()
+		return m, nil
+
+	case "R":
+		// Reset the talk timer
+		m.resetTimer()
+		return m, nil
+
+	case "A":
+		// Pause or resume auto-play of slides with autoAdvance
+		m.toggleAutoplay()
+		return m, nil
+
 	case "r":
 		// Manual reload
 		m.addEvent(DevEvent{
@@ -378,28 +780,34 @@ func (m *DevModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 	case "t":
 		// Open theme picker
-		m.showThemePicker = true
-		// Set picker index to current theme
-		for i, t := range AvailableThemes {
-			if t.Name == m.currentTheme {
-				m.themePickerIndex = i
-				break
-			}
-		}
+		m.openThemePicker()
 		return m, nil
 
-	case "e":
-		// Export to PDF
-		if m.exportingPDF {
+	case "s":
+		// Open slide outline
+		if err := CheckMarkdownFile(m.config.MarkdownFile); err != nil {
+			m.SetError(err)
 			return m, nil
 		}
-		m.exportingPDF = true
-		m.addEvent(DevEvent{
-			Type:      "action",
-			Message:   "Exporting PDF...",
-			Timestamp: time.Now(),
-		})
-		return m, m.exportPDFCmd()
+		slides, err := loadOutlineSlides(m.config.MarkdownFile)
+		if err != nil {
+			m.SetError(fmt.Errorf("failed to load slides: %w", err))
+			return m, nil
+		}
+		m.outlineSlides = slides
+		if m.outlineIndex >= len(slides) {
+			m.outlineIndex = 0
+		}
+		m.showOutline = true
+		return m, nil
+
+	case "x", "e":
+		// Export to PDF; e is the old key
+		return m.startPDFExport()
+
+	case "/":
+		// Search slides, text, and notes
+		return m, m.openSearch()
 
 	case "i":
 		// Open image generator
@@ -412,12 +820,23 @@ func (m *DevModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		// Check for GEMINI_API_KEY
-		if !gemini.HasAPIKey() {
-			m.SetError(fmt.Errorf("GEMINI_API_KEY not set. Add it to your .env file to use AI image generation"))
+		// Check that the image provider is configured, such as its API key
+		if err := imagegen.CheckConfig(ImageProviderConfig(m.config.MarkdownFile)); err != nil {
+			m.SetError(err)
+			m.addEvent(DevEvent{
+				Type:      "error",
+				Message:   "Image generator unavailable: " + err.Error(),
+				Timestamp: time.Now(),
+			})
+			return m, nil
+		}
+
+		// Without the markdown file there are no slides to choose from
+		if err := CheckMarkdownFile(m.config.MarkdownFile); err != nil {
+			m.SetError(err)
 			m.addEvent(DevEvent{
 				Type:      "error",
-				Message:   "Missing GEMINI_API_KEY environment variable",
+				Message:   "Image generator unavailable: " + err.Error(),
 				Timestamp: time.Now(),
 			})
 			return m, nil
@@ -435,6 +854,15 @@ func (m *DevModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		if m.config.PromptHistoryFile != "" {
+			imageGen.SetPromptHistory(LoadPromptHistory(m.config.PromptHistoryFile))
+		}
+
+		// Report what the image generator does in the activity log
+		imageGen.Events = m.imageGenEvent
+		imageGen.now = m.now
+		m.selectCurrentSlide(imageGen)
+
 		// API key is present, show image generator
 		m.imageGenModel = imageGen
 		m.showImageGenerator = true
@@ -449,59 +877,184 @@ func (m *DevModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// handleThemePickerKey handles keyboard input when the theme picker is open.
-func (m *DevModel) handleThemePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+// handleOutlineKey handles keyboard input when the slide outline is open.
+func (m *DevModel) handleOutlineKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc", "q":
-		m.showThemePicker = false
+		m.showOutline = false
 		return m, nil
 
 	case "up", "k":
-		if m.themePickerIndex > 0 {
-			m.themePickerIndex--
+		if m.outlineIndex > 0 {
+			m.outlineIndex--
 		}
 		return m, nil
 
 	case "down", "j":
-		if m.themePickerIndex < len(AvailableThemes)-1 {
-			m.themePickerIndex++
+		if m.outlineIndex < len(m.outlineSlides)-1 {
+			m.outlineIndex++
 		}
 		return m, nil
 
 	case "enter":
-		// Select theme and broadcast
-		selectedTheme := AvailableThemes[m.themePickerIndex].Name
-		m.currentTheme = selectedTheme
-		m.showThemePicker = false
-
-		// Broadcast theme change via WebSocket
-		if m.themeBroadcaster != nil {
-			_ = m.themeBroadcaster.BroadcastTheme(selectedTheme)
+		m.showOutline = false
+		if len(m.outlineSlides) == 0 {
+			return m, nil
+		}
+		m.jumpToSlide(m.outlineSlides[m.outlineIndex].Index)
+		return m, nil
+
+	case "e":
+		// Open the selected slide in the user's editor
+		if len(m.outlineSlides) == 0 {
+			return m, nil
+		}
+		m.showOutline = false
+		strict := loadPresentationConfig(m.config.MarkdownFile).StrictDelimiters
+		return m, editSlideCmd(m.outlineSlides[m.outlineIndex], strict)
+	}
+
+	return m, nil
+}
+
+// jumpToSlide moves the audience and presenter views to the slide with the
+// given zero-based index.
+func (m *DevModel) jumpToSlide(index int) {
+	// Broadcast slide navigation via WebSocket
+	if m.slideBroadcaster != nil {
+		if err := m.slideBroadcaster.BroadcastSlide(index); err != nil {
+			m.SetError(fmt.Errorf("failed to broadcast slide: %w", err))
+			return
+		}
+	}
+
+	m.addEvent(DevEvent{
+		Type:      "action",
+		Message:   fmt.Sprintf("Jumped to slide %d", index+1),
+		Timestamp: time.Now(),
+	})
+}
+
+// loadOutlineSlides reads the markdown file and returns its slides, with includes expanded.
+func loadOutlineSlides(markdownFile string) ([]SlideInfo, error) {
+	content, err := os.ReadFile(markdownFile)
+	if err != nil {
+		if fileErr := CheckMarkdownFile(markdownFile); fileErr != nil {
+			return nil, fileErr
+		}
+		return nil, fmt.Errorf("failed to read markdown file: %w", err)
+	}
+
+	expanded, includes, err := parser.ExpandIncludes(string(content), markdownFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand includes: %w", err)
+	}
+
+	strict := loadPresentationConfig(markdownFile).StrictDelimiters
+	slides := parseSlides(expanded, strict)
+	if len(includes) == 0 {
+		for i := range slides {
+			slides[i].File = markdownFile
+			slides[i].FileIndex = i
+		}
+	} else {
+		locateSlideFiles(slides, slideParts(expanded, strict), append([]string{markdownFile}, includes...), strict)
+	}
+	return slides, nil
+}
+
+// commitGeneratedImage saves an accepted generated image and inserts it into
+// the markdown, or replaces the image being regenerated. It does nothing until
+// the image generator reaches the done step.
+func (m *DevModel) commitGeneratedImage() {
+	if m.imageGenModel.Step == ImageGenStepDone && m.imageGenModel.GeneratedImage != nil && m.imageGenModel.SavedImagePath == "" {
+		// Save the generated image
+		savedPath, err := m.imageGenModel.SaveGeneratedImage()
+		if err != nil {
+			// Keep the image, so saving it can be retried without generating another
+			m.imageGenModel.SaveFailed(err)
+			m.SetError(err)
+			m.addEvent(DevEvent{
+				Type:      "error",
+				Message:   "Failed to save generated image",
+				Timestamp: time.Now(),
+			})
+			return
+		}
+		m.imageGenModel.SavedImagePath = savedPath
+
+		// Keep what is about to change, so it can be undone from the done step
+		if err := m.imageGenModel.SnapshotForUndo(savedPath); err != nil {
+			m.addEvent(DevEvent{
+				Type:      "warning",
+				Message:   fmt.Sprintf("Undo unavailable: %v", err),
+				Timestamp: time.Now(),
+			})
 		}
 
-		// Persist theme change to markdown file
-		if m.config.MarkdownFile != "" {
-			absPath, err := filepath.Abs(m.config.MarkdownFile)
-			if err == nil {
-				if err := config.UpdateThemeInFile(absPath, selectedTheme); err != nil {
-					m.addEvent(DevEvent{
-						Type:      "error",
-						Message:   fmt.Sprintf("Failed to save theme: %v", err),
-						Timestamp: time.Now(),
-					})
-				}
+		// Insert or replace image in markdown
+		if m.imageGenModel.SelectedImage != nil {
+			// Regenerating - replace existing image
+			if err := m.imageGenModel.ReplaceImageInMarkdown(savedPath); err != nil {
+				m.SetError(err)
+				m.addEvent(DevEvent{
+					Type:      "error",
+					Message:   "Failed to update markdown",
+					Timestamp: time.Now(),
+				})
+				return
+			}
+			// Delete old image file
+			if err := m.imageGenModel.DeleteOldImage(); err != nil {
+				// Log but don't fail - the new image is already saved
+				m.addEvent(DevEvent{
+					Type:      "error",
+					Message:   "Failed to delete old image (non-fatal)",
+					Timestamp: time.Now(),
+				})
+			}
+		} else {
+			// Adding new image
+			if err := m.imageGenModel.InsertImageIntoMarkdown(savedPath); err != nil {
+				m.SetError(err)
+				m.addEvent(DevEvent{
+					Type:      "error",
+					Message:   "Failed to update markdown",
+					Timestamp: time.Now(),
+				})
+				return
 			}
 		}
+	}
+}
 
+// imageGenEvent adds an event reported by the image generator, such as a
+// generation starting or failing, to the activity log.
+func (m *DevModel) imageGenEvent(eventType, message string) {
+	m.addEvent(DevEvent{
+		Type:      eventType,
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+}
+
+// undoGeneratedImage undoes the changes made by the accepted generated image.
+func (m *DevModel) undoGeneratedImage() {
+	if err := m.imageGenModel.UndoLastChange(); err != nil {
+		m.SetError(err)
 		m.addEvent(DevEvent{
-			Type:      "action",
-			Message:   fmt.Sprintf("Theme changed to %s", selectedTheme),
+			Type:      "error",
+			Message:   "Failed to undo image change",
 			Timestamp: time.Now(),
 		})
-		return m, nil
+		return
 	}
 
-	return m, nil
+	m.addEvent(DevEvent{
+		Type:      "reload",
+		Message:   fmt.Sprintf("Undid image change: removed %s", m.imageGenModel.SavedImagePath),
+		Timestamp: time.Now(),
+	})
 }
 
 // handleImageGeneratorKey handles keyboard input when the image generator is open.
@@ -509,6 +1062,14 @@ func (m *DevModel) handleImageGeneratorKey(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 	// Check if we're in the Done step - save the saved path before delegating
 	wasInDoneStep := m.imageGenModel.Step == ImageGenStepDone
 	savedPath := m.imageGenModel.SavedImagePath
+	undone := m.imageGenModel.Undone()
+
+	if wasInDoneStep && msg.String() == "u" {
+		if m.imageGenModel.CanUndo() {
+			m.undoGeneratedImage()
+		}
+		return m, nil
+	}
 
 	// Delegate to the image generator model
 	newModel, cmd := m.imageGenModel.Update(msg)
@@ -518,14 +1079,17 @@ func (m *DevModel) handleImageGeneratorKey(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		m.showImageGenerator = false
 		m.imageGenModel = nil
 
-		// Check if this was a successful completion (was in Done step with saved image)
-		if wasInDoneStep && savedPath != "" {
+		// Check if this was a successful completion (was in Done step with saved
+		// image). An undone image was already reported.
+		switch {
+		case undone:
+		case wasInDoneStep && savedPath != "":
 			m.addEvent(DevEvent{
 				Type:      "action",
 				Message:   fmt.Sprintf("Image generation complete: %s", savedPath),
 				Timestamp: time.Now(),
 			})
-		} else {
+		default:
 			m.addEvent(DevEvent{
 				Type:      "action",
 				Message:   "Image generator cancelled",
@@ -535,11 +1099,18 @@ func (m *DevModel) handleImageGeneratorKey(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 		return m, nil
 	}
 
-	// Update the image generator model
+	// Update the image generator model, saving the image once it is accepted
 	m.imageGenModel = newModel.(*ImageGenModel)
+	m.commitGeneratedImage()
 	return m, cmd
 }
 
+// imageGenerationInFlight reports whether the image generator is waiting for
+// a generated image, including a batch backing off after a rate limit error.
+func (m *DevModel) imageGenerationInFlight() bool {
+	return m.imageGenModel != nil && (m.imageGenModel.IsGenerating || m.imageGenModel.batchWaiting)
+}
+
 // handleSlideBuilderKey handles keyboard input when the slide builder is open.
 func (m *DevModel) handleSlideBuilderKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	// Check if we're in the Done step before delegating
@@ -623,16 +1194,40 @@ func openBrowserCmd(url string) tea.Cmd {
 	}
 }
 
-// addEvent adds a new event to the recent events list.
+// copyToClipboardCmd returns a command that copies text to the system clipboard.
+func copyToClipboardCmd(text string) tea.Cmd {
+	return func() tea.Msg {
+		var cmd *exec.Cmd
+		switch runtime.GOOS {
+		case "darwin":
+			cmd = exec.Command("pbcopy")
+		case "linux":
+			cmd = exec.Command("xclip", "-selection", "clipboard")
+		case "windows":
+			cmd = exec.Command("clip.exe")
+		default:
+			return clipboardMsg{err: fmt.Errorf("copying to the clipboard is not supported on %s", runtime.GOOS)}
+		}
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			return clipboardMsg{err: fmt.Errorf("failed to copy to clipboard: %w", err)}
+		}
+		return clipboardMsg{}
+	}
+}
+
+// addEvent adds a new event to the recent events list and mirrors it to the
+// log file, if set.
 func (m *DevModel) addEvent(event DevEvent) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Keep only the most recent 5 events
+	// Keep only the most recent events
 	m.state.RecentEvents = append(m.state.RecentEvents, event)
-	if len(m.state.RecentEvents) > 5 {
-		m.state.RecentEvents = m.state.RecentEvents[len(m.state.RecentEvents)-5:]
+	if len(m.state.RecentEvents) > maxEvents {
+		m.state.RecentEvents = m.state.RecentEvents[len(m.state.RecentEvents)-maxEvents:]
 	}
+	m.writeLogFile(event)
 }
 
 // View implements tea.Model.
@@ -641,11 +1236,36 @@ func (m *DevModel) View() string {
 		return RenderMuted("Shutting down server...\n")
 	}
 
+	// Show confirm dialog if active
+	if m.confirm != nil {
+		return m.confirm.View()
+	}
+
+	// Show slide search overlay if active
+	if m.search != nil {
+		return m.search.View()
+	}
+
 	// Show theme picker overlay if active
 	if m.showThemePicker {
 		return m.viewThemePicker()
 	}
 
+	// Show slide outline overlay if active
+	if m.showOutline {
+		return m.viewOutline()
+	}
+
+	// Show log panel if active
+	if m.showLog {
+		return m.viewLog()
+	}
+
+	// Show remotes panel if active
+	if m.showRemotes {
+		return m.viewRemotes()
+	}
+
 	// Show image generator overlay if active
 	if m.showImageGenerator && m.imageGenModel != nil {
 		return m.imageGenModel.View()
@@ -680,6 +1300,12 @@ func (m *DevModel) View() string {
 	b.WriteString(m.viewEvents())
 	b.WriteString("\n")
 
+	// Missing file warning, shown until the file is back
+	if m.state.FileWarning != nil {
+		b.WriteString(m.viewFileWarning())
+		b.WriteString("\n")
+	}
+
 	// Error display
 	if m.state.Error != nil {
 		b.WriteString(m.viewError())
@@ -726,7 +1352,7 @@ func (m *DevModel) viewURLs() string {
 	b.WriteString("\n")
 
 	b.WriteString(labelStyle.Render("Presenter view:"))
-	b.WriteString(urlStyle.Render(m.config.PresenterURL))
+	b.WriteString(urlStyle.Render(maskURLToken(m.config.PresenterURL, m.config.PresenterPassword)))
 
 	if m.config.PresenterPassword != "" {
 		b.WriteString("\n")
@@ -734,12 +1360,24 @@ func (m *DevModel) viewURLs() string {
 		b.WriteString(RenderMuted("(password protected)"))
 	}
 
+	if m.remoteController != nil {
+		m.mu.RLock()
+		code := m.remoteCode
+		m.mu.RUnlock()
+
+		b.WriteString("\n")
+		b.WriteString(labelStyle.Render("Remote control:"))
+		b.WriteString(urlStyle.Render(remoteURL(m.config.AudienceURL)))
+		b.WriteString(RenderMuted(" (code " + code + ")"))
+	}
+
 	return b.String()
 }
 
-// viewStatus renders the status section.
+// viewStatus renders the status section from the server status.
 func (m *DevModel) viewStatus() string {
 	var b strings.Builder
+	status := m.state.Status
 
 	labelStyle := lipgloss.NewStyle().
 		Foreground(ColorMuted).
@@ -750,28 +1388,102 @@ func (m *DevModel) viewStatus() string {
 	// Current theme
 	b.WriteString(labelStyle.Render("Theme:"))
 	themeStyle := lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
-	b.WriteString(themeStyle.Render(m.currentTheme))
+	b.WriteString(themeStyle.Render(status.Theme))
 	b.WriteString("\n")
 
+	// Slide count
+	if status.SlideCount > 0 {
+		b.WriteString(labelStyle.Render("Slides:"))
+		b.WriteString(fmt.Sprintf("%d", status.SlideCount))
+		b.WriteString("\n")
+	}
+
+	// Slide the browser shows
+	if current := m.currentSlideText(status.SlideCount); current != "" {
+		b.WriteString(labelStyle.Render("Current slide:"))
+		b.WriteString(current)
+		b.WriteString("\n")
+	}
+
 	// WebSocket connections
 	b.WriteString(labelStyle.Render("Connections:"))
-	connCount := m.state.WebSocketClients
-	if connCount == 0 {
+	clients := status.Clients
+	if clients.Total() == 0 {
 		b.WriteString(RenderMuted("none"))
 	} else {
 		connStyle := lipgloss.NewStyle().Foreground(ColorSecondary)
-		b.WriteString(connStyle.Render(fmt.Sprintf("%d client(s)", connCount)))
+		b.WriteString(connStyle.Render(fmt.Sprintf("%d client(s)", clients.Total())))
+		b.WriteString(RenderMuted(fmt.Sprintf(" (%d audience, %d presenter, %d remote)", clients.Audience, clients.Presenter, clients.Remote)))
+	}
+	b.WriteString("\n")
+
+	// Drawing from the presenter view
+	if status.LastAnnotation != nil && time.Since(*status.LastAnnotation) < annotationsActiveFor {
+		b.WriteString(labelStyle.Render("Annotations:"))
+		b.WriteString(RenderSuccess("● active"))
+		b.WriteString("\n")
+	}
+
+	// Access protection
+	b.WriteString(labelStyle.Render("Audience auth:"))
+	if m.config.AudienceProtected {
+		b.WriteString(RenderSuccess("● on"))
+	} else {
+		b.WriteString(RenderMuted("○ off"))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(labelStyle.Render("HTTPS:"))
+	if m.config.TLSFingerprint != "" {
+		b.WriteString(RenderSuccess("● on"))
+		b.WriteString(RenderMuted(" SHA-256 " + shortFingerprint(m.config.TLSFingerprint)))
+	} else {
+		b.WriteString(RenderMuted("○ off"))
+	}
+	b.WriteString("\n")
+
+	b.WriteString(labelStyle.Render("Presenter token:"))
+	if m.config.PresenterPassword != "" {
+		b.WriteString(maskToken(m.config.PresenterPassword))
+	} else {
+		b.WriteString(RenderMuted("none"))
 	}
 	b.WriteString("\n")
 
 	// Watcher status
 	b.WriteString(labelStyle.Render("File watcher:"))
-	if m.state.WatcherRunning {
+	if status.WatcherRunning {
 		b.WriteString(RenderSuccess("● watching"))
 	} else {
 		b.WriteString(RenderMuted("○ not running"))
 	}
 
+	// Running PDF export
+	if export := m.viewPDFExport(); export != "" {
+		b.WriteString("\n")
+		b.WriteString(labelStyle.Render("PDF export:"))
+		b.WriteString(export)
+	}
+
+	// Last reload sent to clients
+	if status.LastReload != nil {
+		b.WriteString("\n")
+		b.WriteString(labelStyle.Render("Last reload:"))
+		b.WriteString(status.LastReload.Format("15:04:05"))
+	}
+
+	// Talk timer
+	b.WriteString("\n")
+	b.WriteString(labelStyle.Render("Timer:"))
+	b.WriteString(m.viewTimer())
+
+	// Estimated speaking time
+	if estimate := m.viewSpeakingTime(); estimate != "" {
+		b.WriteString("\n")
+		b.WriteString(labelStyle.Render("Est. talk:"))
+		b.WriteString(estimate)
+	}
+
 	return b.String()
 }
 
@@ -799,17 +1511,24 @@ func (m *DevModel) viewQRCode() string {
 	return b.String()
 }
 
-// viewEvents renders the recent events section.
+// viewEvents renders the recent events section with the last few events.
+// The log panel shows all of them.
 func (m *DevModel) viewEvents() string {
 	m.mu.RLock()
-	events := make([]DevEvent, len(m.state.RecentEvents))
-	copy(events, m.state.RecentEvents)
+	recent := m.state.RecentEvents[max(len(m.state.RecentEvents)-summaryEvents, 0):]
+	events := make([]DevEvent, len(recent))
+	copy(events, recent)
+	dropped := m.droppedEvents
 	m.mu.RUnlock()
 
 	var b strings.Builder
 
 	b.WriteString("\n")
 	b.WriteString(RenderSubtitle("Recent activity:"))
+	if dropped > 0 {
+		warningStyle := lipgloss.NewStyle().Foreground(ColorWarning)
+		b.WriteString(warningStyle.Render(fmt.Sprintf(" (%d dropped)", dropped)))
+	}
 	b.WriteString("\n")
 
 	if len(events) == 0 {
@@ -830,23 +1549,7 @@ func (m *DevModel) formatEvent(event DevEvent) string {
 		Foreground(ColorMuted).
 		Width(10)
 
-	var msgStyle lipgloss.Style
-	var icon string
-
-	switch event.Type {
-	case "reload":
-		msgStyle = lipgloss.NewStyle().Foreground(ColorSecondary)
-		icon = "↻"
-	case "action":
-		msgStyle = lipgloss.NewStyle().Foreground(ColorPrimary)
-		icon = "→"
-	case "error":
-		msgStyle = lipgloss.NewStyle().Foreground(ColorError)
-		icon = "✗"
-	default:
-		msgStyle = lipgloss.NewStyle().Foreground(ColorWhite)
-		icon = "•"
-	}
+	icon, msgStyle := eventStyle(event.Type)
 
 	timeStr := event.Timestamp.Format("15:04:05")
 	return fmt.Sprintf("  %s %s %s",
@@ -855,6 +1558,22 @@ func (m *DevModel) formatEvent(event DevEvent) string {
 		msgStyle.Render(event.Message))
 }
 
+// eventStyle returns the icon and message style for an event type.
+func eventStyle(eventType string) (string, lipgloss.Style) {
+	switch eventType {
+	case "reload":
+		return "↻", lipgloss.NewStyle().Foreground(ColorSecondary)
+	case "action":
+		return "→", lipgloss.NewStyle().Foreground(ColorPrimary)
+	case "error":
+		return "✗", lipgloss.NewStyle().Foreground(ColorError)
+	case "warning":
+		return "!", lipgloss.NewStyle().Foreground(ColorWarning)
+	default:
+		return "•", lipgloss.NewStyle().Foreground(ColorWhite)
+	}
+}
+
 // viewError renders the error section.
 func (m *DevModel) viewError() string {
 	if m.state.Error == nil {
@@ -870,6 +1589,26 @@ func (m *DevModel) viewError() string {
 	return errorBox.Render(RenderError("Error: " + m.state.Error.Error()))
 }
 
+// viewFileWarning renders the warning shown while the markdown file is
+// missing or unreadable.
+func (m *DevModel) viewFileWarning() string {
+	if m.state.FileWarning == nil {
+		return ""
+	}
+
+	warningBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorWarning).
+		Padding(0, 1).
+		MarginTop(1)
+
+	warningStyle := lipgloss.NewStyle().Foreground(ColorWarning)
+	return warningBox.Render(
+		warningStyle.Render("Warning: "+m.state.FileWarning.Error()) + "\n" +
+			RenderMuted("Serving the last version of the slides until the file is back."),
+	)
+}
+
 // viewHelp renders the keyboard shortcuts section.
 func (m *DevModel) viewHelp() string {
 	helpStyle := lipgloss.NewStyle().
@@ -881,13 +1620,23 @@ func (m *DevModel) viewHelp() string {
 		Bold(true)
 
 	help := fmt.Sprintf(
-		"%s open browser • %s presenter view • %s theme • %s add slide • %s image • %s export pdf • %s reload • %s quit",
+		"%s open browser • %s presenter view • %s copy url • %s new presenter token • %s remotes • %s network • %s theme • %s slides • %s search • %s add slide • %s image • %s export pdf • %s start/pause timer • %s reset timer • %s auto-play • %s log • %s reload • %s quit",
 		keyStyle.Render("o"),
 		keyStyle.Render("p"),
+		keyStyle.Render("c"),
+		keyStyle.Render("k"),
+		keyStyle.Render("m"),
+		keyStyle.Render("n"),
 		keyStyle.Render("t"),
+		keyStyle.Render("s"),
+		keyStyle.Render("/"),
 		keyStyle.Render("a"),
 		keyStyle.Render("i"),
-		keyStyle.Render("e"),
+		keyStyle.Render("x"),
+		keyStyle.Render("space"),
+		keyStyle.Render("R"),
+		keyStyle.Render("A"),
+		keyStyle.Render("l"),
 		keyStyle.Render("r"),
 		keyStyle.Render("q"),
 	)
@@ -909,7 +1658,7 @@ func (m *DevModel) viewThemePicker() string {
 	b.WriteString("\n\n")
 
 	// Theme list
-	for i, theme := range AvailableThemes {
+	for i, theme := range m.themeOptions {
 		// Check if this is the current theme (from frontmatter)
 		isCurrent := theme.Name == m.currentTheme
 
@@ -919,6 +1668,10 @@ func (m *DevModel) viewThemePicker() string {
 				Bold(true).
 				Foreground(ColorSecondary)
 			b.WriteString(selectedStyle.Render("> " + theme.Name))
+			if theme.IsCustom() {
+				customStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+				b.WriteString(customStyle.Render(" (custom)"))
+			}
 			if isCurrent {
 				currentStyle := lipgloss.NewStyle().Foreground(ColorMuted)
 				b.WriteString(currentStyle.Render(" (current)"))
@@ -935,6 +1688,10 @@ func (m *DevModel) viewThemePicker() string {
 			unselectedStyle := lipgloss.NewStyle().
 				Foreground(ColorWhite)
 			b.WriteString(unselectedStyle.Render("  " + theme.Name))
+			if theme.IsCustom() {
+				customStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+				b.WriteString(customStyle.Render(" (custom)"))
+			}
 			if isCurrent {
 				currentStyle := lipgloss.NewStyle().Foreground(ColorMuted)
 				b.WriteString(currentStyle.Render(" (current)"))
@@ -964,6 +1721,93 @@ func (m *DevModel) viewThemePicker() string {
 	return b.String()
 }
 
+// viewOutline renders the slide outline overlay.
+func (m *DevModel) viewOutline() string {
+	var b strings.Builder
+
+	// Title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
+
+	b.WriteString(titleStyle.Render("📑 Jump to Slide"))
+	b.WriteString("\n\n")
+
+	if len(m.outlineSlides) == 0 {
+		b.WriteString(RenderMuted("No slides found"))
+		b.WriteString("\n")
+	}
+
+	// Slide list
+	indicatorStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Italic(true)
+	for i, slide := range m.outlineSlides {
+		slideNum := fmt.Sprintf("%2d.", slide.Index+1)
+
+		// Build AI image indicator if slide has AI images
+		aiIndicator := ""
+		if slide.HasAIImages {
+			if slide.AIImageCount == 1 {
+				aiIndicator = " [has 1 AI image]"
+			} else {
+				aiIndicator = fmt.Sprintf(" [has %d AI images]", slide.AIImageCount)
+			}
+		}
+
+		if i == m.outlineIndex {
+			// Selected item
+			selectedStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(ColorSecondary)
+			numStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(ColorPrimary)
+
+			b.WriteString(numStyle.Render("> " + slideNum))
+			b.WriteString(" ")
+			b.WriteString(selectedStyle.Render(slide.Title))
+		} else {
+			// Unselected item
+			unselectedStyle := lipgloss.NewStyle().
+				Foreground(ColorWhite)
+			numStyle := lipgloss.NewStyle().
+				Foreground(ColorMuted)
+
+			b.WriteString("  ")
+			b.WriteString(numStyle.Render(slideNum))
+			b.WriteString(" ")
+			b.WriteString(unselectedStyle.Render(slide.Title))
+		}
+		if aiIndicator != "" {
+			b.WriteString(indicatorStyle.Render(aiIndicator))
+		}
+		b.WriteString("\n")
+	}
+
+	// Help text
+	b.WriteString("\n")
+	helpStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	help := fmt.Sprintf(
+		"%s/%s navigate • %s go to slide • %s edit • %s cancel",
+		keyStyle.Render("↑"),
+		keyStyle.Render("↓"),
+		keyStyle.Render("enter"),
+		keyStyle.Render("e"),
+		keyStyle.Render("esc"),
+	)
+	b.WriteString(helpStyle.Render(help))
+
+	return b.String()
+}
+
 // External update methods - these can be called from outside the TUI
 
 // SendEvent sends an event to be displayed in the TUI.
@@ -975,7 +1819,10 @@ func (m *DevModel) SendEvent(eventType, message string) {
 		Timestamp: time.Now(),
 	}:
 	default:
-		// Channel full, skip
+		// Channel full; count the dropped event so the TUI can show it
+		m.mu.Lock()
+		m.droppedEvents++
+		m.mu.Unlock()
 	}
 }
 
@@ -984,18 +1831,10 @@ func (m *DevModel) SendReloadEvent(path string) {
 	m.SendEvent("reload", fmt.Sprintf("File changed: %s", path))
 }
 
-// UpdateWebSocketCount updates the WebSocket client count.
-func (m *DevModel) UpdateWebSocketCount(count int) {
-	m.mu.Lock()
-	m.state.WebSocketClients = count
-	m.mu.Unlock()
-}
-
-// UpdateWatcherStatus updates the file watcher status.
-func (m *DevModel) UpdateWatcherStatus(running bool) {
-	m.mu.Lock()
-	m.state.WatcherRunning = running
-	m.mu.Unlock()
+// SendAssetEvent sends a reload event for a changed asset, such as an image
+// or stylesheet the presentation references.
+func (m *DevModel) SendAssetEvent(path string) {
+	m.SendEvent("reload", fmt.Sprintf("Asset changed: %s", path))
 }
 
 // SetError sets an error to be displayed.
@@ -1012,9 +1851,62 @@ func (m *DevModel) ClearError() {
 	m.mu.Unlock()
 }
 
-// Close signals the model to stop listening for events.
+// SetFileWarning shows a warning that the markdown file is missing or
+// unreadable. Unlike errors, it stays until ClearFileWarning is called.
+func (m *DevModel) SetFileWarning(err error) {
+	m.mu.Lock()
+	wasSet := m.state.FileWarning != nil
+	m.state.FileWarning = err
+	m.mu.Unlock()
+
+	if !wasSet {
+		m.SendEvent("warning", err.Error())
+	}
+}
+
+// ClearFileWarning clears the missing file warning once the file is back.
+func (m *DevModel) ClearFileWarning() {
+	m.mu.Lock()
+	wasSet := m.state.FileWarning != nil
+	m.state.FileWarning = nil
+	m.mu.Unlock()
+
+	if wasSet {
+		m.SendEvent("action", "Markdown file is back")
+	}
+}
+
+// FileWarning returns the missing file warning, or nil if the file is readable.
+func (m *DevModel) FileWarning() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.FileWarning
+}
+
+// CheckMarkdownFile returns a short error such as "slides.md was deleted or
+// moved" if the markdown file can't be opened, or nil if it can.
+func CheckMarkdownFile(path string) error {
+	f, err := os.Open(path)
+	if err == nil {
+		_ = f.Close()
+		return nil
+	}
+
+	name := filepath.Base(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%s was deleted or moved", name)
+	}
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		err = pathErr.Err
+	}
+	return fmt.Errorf("%s can't be read: %w", name, err)
+}
+
+// Close signals the model to stop listening for events and closes the log file.
 func (m *DevModel) Close() {
 	close(m.closeCh)
+	m.closeLogFile()
 }
 
 // WasQuit returns true if the user quit the TUI.
@@ -1052,9 +1944,14 @@ func RunDevTUI(cfg DevConfig) error {
 }
 
 // RunDevTUIWithModel runs the dev server TUI with a pre-configured model.
-func RunDevTUIWithModel(model *DevModel) error {
+// It returns when the user quits, or when ctx is done, which quits the TUI
+// so the caller can shut down the dev server.
+func RunDevTUIWithModel(ctx context.Context, model *DevModel) error {
 	p := tea.NewProgram(model, tea.WithAltScreen())
 
+	stop := context.AfterFunc(ctx, p.Quit)
+	defer stop()
+
 	_, err := p.Run()
 	model.Close()
 	return err
@@ -1,12 +1,17 @@
 package tui
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/server"
 )
 
 func TestNewDevModel(t *testing.T) {
@@ -211,22 +216,34 @@ func TestDevModel_WindowSize(t *testing.T) {
 func TestDevModel_AddEvent(t *testing.T) {
 	model := NewDevModel(DevConfig{})
 
-	// Add multiple events
-	for i := 0; i < 10; i++ {
+	// Add more events than are kept
+	for i := 0; i < maxEvents+10; i++ {
 		model.addEvent(DevEvent{
 			Timestamp: time.Now(),
 			Type:      "test",
-			Message:   "test event",
+			Message:   fmt.Sprintf("test event %d", i),
 		})
 	}
 
-	// Should only keep last 5 events
+	// Should only keep the last maxEvents events
 	model.mu.RLock()
-	eventCount := len(model.state.RecentEvents)
+	events := model.state.RecentEvents
 	model.mu.RUnlock()
 
-	if eventCount != 5 {
-		t.Errorf("expected 5 events, got %d", eventCount)
+	if len(events) != maxEvents {
+		t.Errorf("expected %d events, got %d", maxEvents, len(events))
+	}
+	if events[0].Message != "test event 10" {
+		t.Errorf("expected the oldest events to be dropped, first is %q", events[0].Message)
+	}
+
+	// The main screen only shows the last few
+	view := model.viewEvents()
+	if strings.Count(view, "test event") != summaryEvents {
+		t.Errorf("expected %d events in the summary, got:\n%s", summaryEvents, view)
+	}
+	if !strings.Contains(view, fmt.Sprintf("test event %d", maxEvents+9)) {
+		t.Errorf("expected the newest event in the summary, got:\n%s", view)
 	}
 }
 
@@ -273,6 +290,93 @@ func TestDevModel_View_WithPassword(t *testing.T) {
 	}
 }
 
+func TestDevModel_View_MasksPresenterToken(t *testing.T) {
+	model := NewDevModel(DevConfig{
+		AudienceURL:       "http://localhost:3000",
+		PresenterURL:      "http://localhost:3000/presenter?token=0123456789abcdef",
+		PresenterPassword: "0123456789abcdef",
+		MarkdownFile:      "slides.md",
+		AudienceProtected: true,
+	})
+	model.windowWidth = 120
+	model.windowHeight = 40
+
+	view := model.View()
+
+	if strings.Contains(view, "0123456789abcdef") {
+		t.Error("view should not show the full presenter token")
+	}
+	if !strings.Contains(view, "••••••••cdef") {
+		t.Error("view should show the masked presenter token")
+	}
+	if !strings.Contains(view, "Audience auth:") || !strings.Contains(view, "● on") {
+		t.Error("view should show that audience auth is on")
+	}
+}
+
+func TestDevModel_View_TLS(t *testing.T) {
+	fingerprint := "AB:CD:EF:01:23:45:67:89:" + strings.Repeat("00:", 23) + "FF"
+	model := NewDevModel(DevConfig{
+		AudienceURL:    "https://192.168.1.20:3000",
+		PresenterURL:   "https://192.168.1.20:3000/presenter",
+		MarkdownFile:   "slides.md",
+		TLSFingerprint: fingerprint,
+	})
+	model.windowWidth = 120
+	model.windowHeight = 40
+
+	view := model.View()
+	if !strings.Contains(view, "HTTPS:") || !strings.Contains(view, "SHA-256 AB:CD:EF:01:23:45:67:89…") {
+		t.Errorf("view should show that HTTPS is on with the certificate fingerprint, got:\n%s", view)
+	}
+	if !strings.Contains(view, "https://192.168.1.20:3000") {
+		t.Error("view should show the HTTPS audience URL")
+	}
+
+	// Changing networks keeps the scheme
+	model.handleNetworkCheck(networkCheckMsg{addrs: []string{"10.0.0.5"}})
+	if model.config.AudienceURL != "https://10.0.0.5:3000" || model.config.PresenterURL != "https://10.0.0.5:3000/presenter" {
+		t.Errorf("expected HTTPS URLs on the new address, got %s and %s", model.config.AudienceURL, model.config.PresenterURL)
+	}
+}
+
+// fakeTokenRotator returns a fixed new presenter token.
+type fakeTokenRotator struct {
+	token   string
+	rotated int
+}
+
+func (r *fakeTokenRotator) RotatePresenterToken() (string, error) {
+	r.rotated++
+	return r.token, nil
+}
+
+func TestDevModel_RotatePresenterToken(t *testing.T) {
+	model := NewDevModel(DevConfig{
+		AudienceURL:       "http://localhost:3000",
+		PresenterURL:      "http://192.168.1.10:3000/presenter?key=secret",
+		PresenterPassword: "secret",
+		MarkdownFile:      "slides.md",
+	})
+	rotator := &fakeTokenRotator{token: "newtoken1234"}
+	model.SetPresenterTokenRotator(rotator)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'k'}})
+
+	if rotator.rotated != 1 {
+		t.Fatalf("expected token to be rotated once, got %d", rotator.rotated)
+	}
+	if model.config.PresenterPassword != "newtoken1234" {
+		t.Errorf("PresenterPassword = %q, want newtoken1234", model.config.PresenterPassword)
+	}
+	if want := "http://192.168.1.10:3000/presenter?token=newtoken1234"; model.config.PresenterURL != want {
+		t.Errorf("PresenterURL = %q, want %q", model.config.PresenterURL, want)
+	}
+	if len(model.state.RecentEvents) == 0 || !strings.Contains(model.state.RecentEvents[0].Message, "Presenter token regenerated") {
+		t.Errorf("expected a token regenerated event, got %v", model.state.RecentEvents)
+	}
+}
+
 func TestDevModel_View_WithQRCode(t *testing.T) {
 	model := NewDevModel(DevConfig{
 		AudienceURL:  "http://localhost:3000",
@@ -290,6 +394,47 @@ func TestDevModel_View_WithQRCode(t *testing.T) {
 	}
 }
 
+func TestDevModel_QuitConfirmWhileGeneratingImage(t *testing.T) {
+	for _, key := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("n")}, {Type: tea.KeyEsc}, {Type: tea.KeyRunes, Runes: []rune("y")}} {
+		t.Run(key.String(), func(t *testing.T) {
+			imageGen := newReviewTestModel(t)
+			canceled := false
+			imageGen.Step = ImageGenStepGenerating
+			imageGen.IsGenerating = true
+			imageGen.cancelGenerate = func() { canceled = true }
+
+			model := NewDevModel(DevConfig{MarkdownFile: imageGen.MarkdownFile})
+			model.showImageGenerator = true
+			model.imageGenModel = imageGen
+
+			_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+			if model.quitting || cmd != nil {
+				t.Fatal("expected to ask before quitting")
+			}
+			if !strings.Contains(model.View(), "Quit while an image is being generated?") {
+				t.Fatalf("expected the confirm dialog, got:\n%s", model.View())
+			}
+
+			_, cmd = model.Update(key)
+			if model.confirm != nil {
+				t.Fatal("expected the confirm dialog to close")
+			}
+			if key.String() != "y" {
+				if model.quitting || cmd != nil || canceled || !imageGen.IsGenerating {
+					t.Error("declining should keep generating")
+				}
+				return
+			}
+			if !model.quitting || cmd == nil {
+				t.Error("expected to quit after confirming")
+			}
+			if !canceled || imageGen.IsGenerating {
+				t.Error("expected the generation to be canceled")
+			}
+		})
+	}
+}
+
 func TestDevModel_View_Quitting(t *testing.T) {
 	model := NewDevModel(DevConfig{})
 	model.quitting = true
@@ -349,13 +494,17 @@ func TestDevModel_View_WithConnections(t *testing.T) {
 	})
 	model.windowWidth = 80
 	model.windowHeight = 24
-	model.state.WebSocketClients = 3
+	model.windowWidth = 120
+	model.state.Status.Clients = server.ClientCounts{Audience: 2, Presenter: 1, Remote: 1}
 
 	view := model.View()
 
-	if !strings.Contains(view, "3 client") {
+	if !strings.Contains(view, "4 client") {
 		t.Error("view should display connection count")
 	}
+	if !strings.Contains(view, "2 audience, 1 presenter, 1 remote") {
+		t.Error("view should split connections by view")
+	}
 }
 
 func TestDevModel_View_WatcherStatus(t *testing.T) {
@@ -364,7 +513,7 @@ func TestDevModel_View_WatcherStatus(t *testing.T) {
 	})
 	model.windowWidth = 80
 	model.windowHeight = 24
-	model.state.WatcherRunning = true
+	model.state.Status.WatcherRunning = true
 
 	view := model.View()
 
@@ -422,41 +571,90 @@ func TestDevModel_SendReloadEvent(t *testing.T) {
 	<-done
 }
 
-func TestDevModel_UpdateWebSocketCount(t *testing.T) {
+func TestDevModel_SendAssetEvent(t *testing.T) {
 	model := NewDevModel(DevConfig{})
 
-	model.UpdateWebSocketCount(5)
-
-	model.mu.RLock()
-	count := model.state.WebSocketClients
-	model.mu.RUnlock()
+	// The channel is buffered, so the event can be read after sending
+	model.SendAssetEvent("styles/custom.css")
 
-	if count != 5 {
-		t.Errorf("expected WebSocket count 5, got %d", count)
+	select {
+	case event := <-model.eventsCh:
+		if event.Type != "reload" {
+			t.Errorf("expected reload event, got %q", event.Type)
+		}
+		if event.Message != "Asset changed: styles/custom.css" {
+			t.Errorf("unexpected message %q", event.Message)
+		}
+	default:
+		t.Fatal("expected an event")
 	}
 }
 
-func TestDevModel_UpdateWatcherStatus(t *testing.T) {
-	model := NewDevModel(DevConfig{})
+// fakeStatusSource returns a fixed server status.
+type fakeStatusSource struct {
+	status server.Status
+}
 
-	model.UpdateWatcherStatus(true)
+func (f *fakeStatusSource) Status() server.Status {
+	return f.status
+}
 
-	model.mu.RLock()
-	running := model.state.WatcherRunning
-	model.mu.RUnlock()
+func TestDevModel_StatusSource(t *testing.T) {
+	model := NewDevModel(DevConfig{MarkdownFile: "slides.md", CurrentTheme: "paper"})
+	model.windowWidth = 120
+	model.windowHeight = 40
 
-	if !running {
-		t.Error("expected watcher to be running")
+	if model.state.Status.Theme != "paper" || model.state.Status.MarkdownFile != "slides.md" {
+		t.Errorf("initial status = %+v, want theme and file from config", model.state.Status)
 	}
 
-	model.UpdateWatcherStatus(false)
+	lastReload := time.Date(2024, 5, 1, 14, 30, 5, 0, time.Local)
+	source := &fakeStatusSource{status: server.Status{
+		MarkdownFile:   "slides.md",
+		Theme:          "noir",
+		SlideCount:     12,
+		Clients:        server.ClientCounts{Audience: 4},
+		WatcherRunning: true,
+		LastReload:     &lastReload,
+	}}
+	model.SetStatusSource(source)
 
-	model.mu.RLock()
-	running = model.state.WatcherRunning
-	model.mu.RUnlock()
+	view := model.View()
+	for _, want := range []string{"noir", "12", "4 client(s)", "watching", "14:30:05"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view should contain %q", want)
+		}
+	}
 
-	if running {
-		t.Error("expected watcher to not be running")
+	// The status is read again on each tick
+	source.status.WatcherRunning = false
+	model.Update(tickMsg{})
+	if model.state.Status.WatcherRunning {
+		t.Error("expected status to be refreshed on tick")
+	}
+}
+
+func TestDevModel_AnnotationsIndicator(t *testing.T) {
+	model := NewDevModel(DevConfig{})
+	source := &fakeStatusSource{}
+	model.SetStatusSource(source)
+
+	if strings.Contains(model.viewStatus(), "Annotations:") {
+		t.Error("expected no annotations indicator before any stroke")
+	}
+
+	recent := time.Now().Add(-3 * time.Second)
+	source.status.LastAnnotation = &recent
+	model.Update(tickMsg{})
+	if view := model.viewStatus(); !strings.Contains(view, "Annotations:") || !strings.Contains(view, "active") {
+		t.Errorf("expected annotations to be active, got:\n%s", view)
+	}
+
+	old := time.Now().Add(-time.Minute)
+	source.status.LastAnnotation = &old
+	model.Update(tickMsg{})
+	if strings.Contains(model.viewStatus(), "Annotations:") {
+		t.Error("expected no annotations indicator a minute after the last stroke")
 	}
 }
 
@@ -683,6 +881,49 @@ func TestDevModel_HandleKeyPress_Image_AlreadyGenerating(t *testing.T) {
 	}
 }
 
+func TestDevModel_ImageGeneratorSavesOnlyAfterReview(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide 1\n\nContent"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	imageGen, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create image generator: %v", err)
+	}
+	imageGen.PreviewProtocol = PreviewHalfBlock
+	imageGen.Step = ImageGenStepGenerating
+	imageGen.IsGenerating = true
+	imageGen.Prompt = "a cat"
+
+	model := NewDevModel(DevConfig{MarkdownFile: mdFile})
+	model.showImageGenerator = true
+	model.imageGenModel = imageGen
+
+	// The result moves to the review step without writing anything
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: syntheticPNG(t, 4, 4), ContentType: "image/png"}})
+	if model.imageGenModel.Step != ImageGenStepReview {
+		t.Fatalf("expected review step, got %d", model.imageGenModel.Step)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "images")); !os.IsNotExist(err) {
+		t.Error("no image should be saved before it is accepted")
+	}
+
+	// Accepting saves the image and updates the markdown
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.imageGenModel.Step != ImageGenStepDone || model.imageGenModel.SavedImagePath == "" {
+		t.Fatalf("expected saved image after accepting, got step %d", model.imageGenModel.Step)
+	}
+	content, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "![]("+model.imageGenModel.SavedImagePath+")") {
+		t.Errorf("expected markdown to reference the saved image, got:\n%s", content)
+	}
+}
+
 func TestDevModel_ShowImageGenerator(t *testing.T) {
 	model := NewDevModel(DevConfig{})
 
@@ -721,3 +962,373 @@ func TestDevModel_View_HelpIncludesImage(t *testing.T) {
 		t.Error("help text should include 'i' shortcut for image generation")
 	}
 }
+
+// mockSlideBroadcaster records broadcast slide indices.
+type mockSlideBroadcaster struct {
+	indices []int
+}
+
+func (b *mockSlideBroadcaster) BroadcastSlide(slideIndex int) error {
+	b.indices = append(b.indices, slideIndex)
+	return nil
+}
+
+func TestDevModel_HandleKeyPress_Outline(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := tmpDir + "/slides.md"
+	content := "# First\n\n---\n\n# Second\n\n<!-- ai-prompt: a cat -->\n![](images/cat.png)\n\n---\n\n# Third"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model := NewDevModel(DevConfig{MarkdownFile: mdFile})
+	broadcaster := &mockSlideBroadcaster{}
+	model.SetSlideBroadcaster(broadcaster)
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if !model.showOutline {
+		t.Fatal("expected outline to be shown after pressing 's'")
+	}
+	if len(model.outlineSlides) != 3 {
+		t.Fatalf("expected 3 slides, got %d", len(model.outlineSlides))
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "Second") || !strings.Contains(view, "[has 1 AI image]") {
+		t.Errorf("outline should list slides with AI image indicators, got:\n%s", view)
+	}
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("j")})
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("k")})
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if model.showOutline {
+		t.Error("outline should close after selecting a slide")
+	}
+	if len(broadcaster.indices) != 1 || broadcaster.indices[0] != 1 {
+		t.Errorf("expected broadcast of slide 1, got %v", broadcaster.indices)
+	}
+}
+
+func TestDevModel_HandleKeyPress_OutlineEsc(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := tmpDir + "/slides.md"
+	if err := os.WriteFile(mdFile, []byte("# First\n\n---\n\n# Second"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model := NewDevModel(DevConfig{MarkdownFile: mdFile})
+	broadcaster := &mockSlideBroadcaster{}
+	model.SetSlideBroadcaster(broadcaster)
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if model.showOutline {
+		t.Error("outline should close on esc")
+	}
+	if len(broadcaster.indices) != 0 {
+		t.Errorf("esc should not change slides, got broadcasts %v", broadcaster.indices)
+	}
+}
+
+// mockThemeBroadcaster records broadcast theme names.
+type mockThemeBroadcaster struct {
+	themes []string
+}
+
+func (b *mockThemeBroadcaster) BroadcastTheme(themeName string) error {
+	b.themes = append(b.themes, themeName)
+	return nil
+}
+
+func TestDevModel_ThemePicker_CustomThemes(t *testing.T) {
+	model := NewDevModel(DevConfig{
+		CurrentTheme: "my-brand",
+		CustomThemes: []Theme{
+			{Name: "my-brand", Description: "Company colors", Path: "themes/my-brand.css"},
+			{Name: "noir", Description: "Custom noir", Path: "themes/noir.css"},
+		},
+	})
+	broadcaster := &mockThemeBroadcaster{}
+	model.SetThemeBroadcaster(broadcaster)
+
+	// Custom themes are added after the built-in themes and replace collisions
+	if len(model.themeOptions) != len(AvailableThemes)+1 {
+		t.Fatalf("expected %d themes, got %d", len(AvailableThemes)+1, len(model.themeOptions))
+	}
+	events := model.state.RecentEvents
+	if len(events) != 1 || events[0].Type != "warning" || !strings.Contains(events[0].Message, `"noir"`) {
+		t.Errorf("expected a warning event for the noir collision, got %+v", events)
+	}
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if got := model.themeOptions[model.themePickerIndex].Name; got != "my-brand" {
+		t.Errorf("picker should start at the current custom theme, got %q", got)
+	}
+	view := model.View()
+	if !strings.Contains(view, "my-brand (custom) (current)") || !strings.Contains(view, "Company colors") || !strings.Contains(view, "noir (custom)") {
+		t.Errorf("theme picker should list custom themes, got:\n%s", view)
+	}
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	if len(broadcaster.themes) != 1 || broadcaster.themes[0] != "my-brand" {
+		t.Errorf("expected broadcast of my-brand, got %v", broadcaster.themes)
+	}
+}
+
+func TestDevModel_NetworkCheck_AddressChanged(t *testing.T) {
+	model := NewDevModel(DevConfig{
+		AudienceURL:  "http://192.168.1.10:3000",
+		PresenterURL: "http://192.168.1.10:3000/presenter?key=secret",
+		MarkdownFile: "slides.md",
+	})
+	model.windowHeight = 60
+
+	_, cmd := model.Update(networkCheckMsg{addrs: []string{"10.0.0.5"}})
+	if cmd == nil {
+		t.Error("expected the next periodic check to be scheduled")
+	}
+
+	if model.config.AudienceURL != "http://10.0.0.5:3000" {
+		t.Errorf("AudienceURL = %q, want http://10.0.0.5:3000", model.config.AudienceURL)
+	}
+	if model.config.PresenterURL != "http://10.0.0.5:3000/presenter?key=secret" {
+		t.Errorf("PresenterURL = %q, want the new host with path and key kept", model.config.PresenterURL)
+	}
+	if model.config.QRCodeASCII == "" {
+		t.Error("expected QR code to be regenerated for the new address")
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "10.0.0.5") || !strings.Contains(view, "█") {
+		t.Error("view should show the new URL and QR code")
+	}
+	if !strings.Contains(view, "Network address changed") {
+		t.Error("expected an event for the address change")
+	}
+}
+
+func TestDevModel_NetworkCheck_KeepsCurrentAddress(t *testing.T) {
+	model := NewDevModel(DevConfig{
+		AudienceURL:  "http://192.168.1.10:3000",
+		PresenterURL: "http://192.168.1.10:3000/presenter",
+	})
+
+	model.Update(networkCheckMsg{addrs: []string{"10.0.0.5", "192.168.1.10"}})
+
+	if model.config.AudienceURL != "http://192.168.1.10:3000" {
+		t.Errorf("AudienceURL = %q, expected the current address to be kept", model.config.AudienceURL)
+	}
+	if len(model.state.RecentEvents) != 0 {
+		t.Errorf("expected no events for a periodic check without changes, got %v", model.state.RecentEvents)
+	}
+}
+
+func TestDevModel_NetworkCheck_FallsBackToLocalhost(t *testing.T) {
+	model := NewDevModel(DevConfig{
+		AudienceURL:  "http://192.168.1.10:3000",
+		PresenterURL: "http://192.168.1.10:3000/presenter",
+		QRCodeASCII:  "██",
+	})
+
+	model.Update(networkCheckMsg{})
+
+	if model.config.AudienceURL != "http://localhost:3000" {
+		t.Errorf("AudienceURL = %q, want http://localhost:3000", model.config.AudienceURL)
+	}
+	if model.config.QRCodeASCII != "" {
+		t.Error("expected no QR code for a localhost URL")
+	}
+}
+
+func TestDevModel_HandleKeyPress_Network(t *testing.T) {
+	model := NewDevModel(DevConfig{
+		AudienceURL:  "http://192.168.1.10:3000",
+		PresenterURL: "http://192.168.1.10:3000/presenter",
+	})
+	model.detectAddresses = func() ([]string, error) {
+		return []string{"192.168.1.10"}, nil
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if cmd == nil {
+		t.Fatal("expected network check command")
+	}
+
+	msg, ok := cmd().(networkCheckMsg)
+	if !ok || !msg.manual {
+		t.Fatalf("expected manual networkCheckMsg, got %#v", msg)
+	}
+
+	_, cmd = model.Update(msg)
+	if cmd != nil {
+		t.Error("manual checks should not schedule another periodic check")
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "Network address unchanged") {
+		t.Error("manual check should confirm the unchanged address")
+	}
+}
+
+func TestDevModel_HandleKeyPress_CopyURL(t *testing.T) {
+	model := NewDevModel(DevConfig{AudienceURL: "http://192.168.1.10:3000"})
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if cmd == nil {
+		t.Fatal("expected clipboard command")
+	}
+
+	model.Update(clipboardMsg{})
+	if !strings.Contains(model.View(), "Copied audience URL") {
+		t.Error("expected confirmation event after copying")
+	}
+
+	model.Update(clipboardMsg{err: errors.New("xclip not found")})
+	if model.state.Error == nil {
+		t.Error("expected clipboard error to be shown")
+	}
+}
+
+func TestReplaceURLHost(t *testing.T) {
+	tests := []struct {
+		url  string
+		host string
+		want string
+	}{
+		{"http://localhost:3000", "10.0.0.5", "http://10.0.0.5:3000"},
+		{"http://10.0.0.5:3000/presenter?key=a%20b", "localhost", "http://localhost:3000/presenter?key=a%20b"},
+		{"http://example.com/path", "10.0.0.5", "http://10.0.0.5/path"},
+		{"not a url", "10.0.0.5", "not a url"},
+	}
+
+	for _, tt := range tests {
+		if got := replaceURLHost(tt.url, tt.host); got != tt.want {
+			t.Errorf("replaceURLHost(%q, %q) = %q, want %q", tt.url, tt.host, got, tt.want)
+		}
+	}
+}
+
+// nextEvent returns the next event sent to the model, or fails if there is none.
+func nextEvent(t *testing.T, model *DevModel) DevEvent {
+	t.Helper()
+	select {
+	case event := <-model.eventsCh:
+		return event
+	default:
+		t.Fatal("expected an event")
+	}
+	return DevEvent{}
+}
+
+func TestDevModel_FileWarning_DeleteAndRecreate(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "test-api-key")
+
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	content := []byte("# Slide 1\n\n---\n\n# Slide 2\n")
+	if err := os.WriteFile(mdFile, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	model := NewDevModel(DevConfig{MarkdownFile: mdFile})
+	model.windowWidth = 80
+	model.windowHeight = 24
+
+	if err := CheckMarkdownFile(mdFile); err != nil {
+		t.Fatalf("CheckMarkdownFile() error = %v", err)
+	}
+
+	// Deleting the file shows a warning that stays until the file is back
+	if err := os.Remove(mdFile); err != nil {
+		t.Fatal(err)
+	}
+	fileErr := CheckMarkdownFile(mdFile)
+	if fileErr == nil || fileErr.Error() != "slides.md was deleted or moved" {
+		t.Fatalf("CheckMarkdownFile() error = %v, want a missing file error", fileErr)
+	}
+	model.SetFileWarning(fileErr)
+	if event := nextEvent(t, model); event.Type != "warning" || event.Message != fileErr.Error() {
+		t.Errorf("expected a warning event, got %+v", event)
+	}
+
+	// Further changes while the file is missing don't repeat the event
+	model.SetFileWarning(fileErr)
+	if len(model.eventsCh) != 0 {
+		t.Error("expected a single warning event while the file is missing")
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "Warning: slides.md was deleted or moved") || !strings.Contains(view, "Serving the last version") {
+		t.Errorf("view should show the missing file warning, got:\n%s", view)
+	}
+	if model.state.Error != nil {
+		t.Error("a missing file should not set an error")
+	}
+
+	// The image generator and outline report the missing file without a stack of wrapped errors
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if model.showImageGenerator {
+		t.Error("image generator should not open while the file is missing")
+	}
+	if model.state.Error == nil || model.state.Error.Error() != fileErr.Error() {
+		t.Errorf("expected the missing file error, got %v", model.state.Error)
+	}
+	model.ClearError()
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	if model.showOutline || model.state.Error == nil || model.state.Error.Error() != fileErr.Error() {
+		t.Errorf("expected the outline to report the missing file, got %v", model.state.Error)
+	}
+	if _, err := NewImageGenModel(mdFile); err == nil || err.Error() != fileErr.Error() {
+		t.Errorf("NewImageGenModel() error = %v, want %q", err, fileErr)
+	}
+	model.ClearError()
+
+	// Recreating the file clears the warning
+	if err := os.WriteFile(mdFile, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckMarkdownFile(mdFile); err != nil {
+		t.Fatalf("CheckMarkdownFile() error = %v after recreating the file", err)
+	}
+	model.ClearFileWarning()
+	if model.FileWarning() != nil {
+		t.Error("expected the warning to be cleared")
+	}
+	if event := nextEvent(t, model); event.Type != "action" || !strings.Contains(event.Message, "back") {
+		t.Errorf("expected an event when the file is back, got %+v", event)
+	}
+	if strings.Contains(model.View(), "Warning: slides.md") {
+		t.Error("view should not show the warning once the file is back")
+	}
+
+	// Clearing again is a no-op
+	model.ClearFileWarning()
+	if len(model.eventsCh) != 0 {
+		t.Error("clearing without a warning should not send an event")
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if !model.showImageGenerator {
+		t.Error("image generator should open once the file is back")
+	}
+}
+
+func TestCheckMarkdownFile_Unreadable(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("file permissions are not enforced for root")
+	}
+
+	mdFile := filepath.Join(t.TempDir(), "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide"), 0000); err != nil {
+		t.Fatal(err)
+	}
+
+	err := CheckMarkdownFile(mdFile)
+	if err == nil || err.Error() != "slides.md can't be read: permission denied" {
+		t.Errorf("CheckMarkdownFile() error = %v", err)
+	}
+}
@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/devexport"
+	"github.com/MiniCodeMonkey/tap/internal/pdf"
+)
+
+// pdfExportTimeout is how long a PDF export from the TUI may take.
+const pdfExportTimeout = 5 * time.Minute
+
+// PDFExporter is an interface for exporting the presentation to a PDF from
+// the running dev server, which reports the export's progress as events.
+type PDFExporter interface {
+	ExportPDF(ctx context.Context, req devexport.Request) (*pdf.ExportResult, error)
+}
+
+// SetPDFExporter sets the exporter used by the x key. Without one, PDFs are
+// exported by running tap pdf.
+func (m *DevModel) SetPDFExporter(pe PDFExporter) {
+	m.pdfExporter = pe
+}
+
+// newPDFSpinner returns the spinner shown in the status panel during a PDF export.
+func newPDFSpinner() spinner.Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	return s
+}
+
+// startPDFExport starts exporting the presentation to a PDF next to the
+// markdown file, unless an export is already running.
+func (m *DevModel) startPDFExport() (tea.Model, tea.Cmd) {
+	if m.exportingPDF {
+		return m, nil
+	}
+	m.exportingPDF = true
+
+	if m.pdfExporter == nil {
+		m.addEvent(DevEvent{
+			Type:      "action",
+			Message:   "Exporting PDF...",
+			Timestamp: time.Now(),
+		})
+		return m, tea.Batch(m.pdfSpinner.Tick, m.exportPDFCmd())
+	}
+	return m, tea.Batch(m.pdfSpinner.Tick, m.serverExportPDFCmd())
+}
+
+// serverExportPDFCmd exports the presentation with the PDF exporter, which
+// sends its own progress and result events.
+func (m *DevModel) serverExportPDFCmd() tea.Cmd {
+	exporter := m.pdfExporter
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), pdfExportTimeout)
+		defer cancel()
+
+		result, err := exporter.ExportPDF(ctx, devexport.Request{})
+		if err != nil {
+			return pdfExportMsg{err: fmt.Errorf("PDF export failed: %w", err), reported: true}
+		}
+		return pdfExportMsg{outputPath: result.OutputPath, reported: true}
+	}
+}
+
+// handlePDFExportMsg ends a PDF export, showing its result unless the
+// exporter already reported it.
+func (m *DevModel) handlePDFExportMsg(msg pdfExportMsg) (tea.Model, tea.Cmd) {
+	m.exportingPDF = false
+	if msg.err != nil {
+		m.SetError(msg.err)
+		if !msg.reported {
+			m.addEvent(DevEvent{
+				Type:      "error",
+				Message:   "PDF export failed",
+				Timestamp: time.Now(),
+			})
+		}
+	} else if !msg.reported {
+		m.addEvent(DevEvent{
+			Type:      "action",
+			Message:   fmt.Sprintf("PDF exported → %s", msg.outputPath),
+			Timestamp: time.Now(),
+		})
+	}
+	return m, nil
+}
+
+// viewPDFExport renders the PDF export row of the status panel, or "" when
+// no export is running.
+func (m *DevModel) viewPDFExport() string {
+	if !m.exportingPDF {
+		return ""
+	}
+	return m.pdfSpinner.View() + " exporting..."
+}
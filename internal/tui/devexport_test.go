@@ -0,0 +1,114 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/devexport"
+	"github.com/MiniCodeMonkey/tap/internal/pdf"
+)
+
+// fakePDFExporter records export requests and returns a fixed result.
+type fakePDFExporter struct {
+	err      error
+	requests []devexport.Request
+}
+
+func (e *fakePDFExporter) ExportPDF(_ context.Context, req devexport.Request) (*pdf.ExportResult, error) {
+	e.requests = append(e.requests, req)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &pdf.ExportResult{OutputPath: "/talks/slides.pdf", PageCount: 3}, nil
+}
+
+// runExportCmd runs the commands batched by the x key and returns the
+// message of the export command.
+func runExportCmd(t *testing.T, cmd tea.Cmd) pdfExportMsg {
+	t.Helper()
+	if cmd == nil {
+		t.Fatal("expected a command")
+	}
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatal("expected a batch of the spinner tick and the export")
+	}
+	for _, c := range batch {
+		if msg, ok := c().(pdfExportMsg); ok {
+			return msg
+		}
+	}
+	t.Fatal("expected a PDF export message")
+	return pdfExportMsg{}
+}
+
+func TestDevModel_ExportPDFWithExporter(t *testing.T) {
+	model := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+	exporter := &fakePDFExporter{}
+	model.SetPDFExporter(exporter)
+
+	_, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if !model.exportingPDF {
+		t.Fatal("expected an export to be running")
+	}
+	if view := model.viewStatus(); !strings.Contains(view, "PDF export:") || !strings.Contains(view, "exporting") {
+		t.Errorf("expected the status panel to show the running export, got:\n%s", view)
+	}
+
+	// A second export is ignored while one runs
+	if _, again := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")}); again != nil {
+		t.Error("expected no command while an export is running")
+	}
+
+	msg := runExportCmd(t, cmd)
+	if len(exporter.requests) != 1 {
+		t.Fatalf("expected one export request, got %d", len(exporter.requests))
+	}
+	events := len(model.state.RecentEvents)
+	model.Update(msg)
+
+	if model.exportingPDF {
+		t.Error("expected the export to be finished")
+	}
+	if strings.Contains(model.viewStatus(), "PDF export:") {
+		t.Error("expected the export row to be gone")
+	}
+	// The exporter reports its own events
+	if len(model.state.RecentEvents) != events {
+		t.Errorf("expected no extra events, got %v", model.state.RecentEvents[events:])
+	}
+}
+
+func TestDevModel_ExportPDFFailure(t *testing.T) {
+	model := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+	model.SetPDFExporter(&fakePDFExporter{err: errors.New("chromium crashed")})
+
+	_, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	model.Update(runExportCmd(t, cmd))
+
+	if model.exportingPDF {
+		t.Error("expected the export to be finished")
+	}
+	if model.state.Error == nil || !strings.Contains(model.state.Error.Error(), "chromium crashed") {
+		t.Errorf("expected the export error, got %v", model.state.Error)
+	}
+}
+
+func TestDevModel_ExportPDFFinishesUnderOverlay(t *testing.T) {
+	model := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+	model.SetPDFExporter(&fakePDFExporter{})
+	_, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	msg := runExportCmd(t, cmd)
+
+	model.showSlideBuilder = true
+	model.addModel = &AddModel{}
+	model.Update(msg)
+
+	if model.exportingPDF {
+		t.Error("expected the export to finish while an overlay is open")
+	}
+}
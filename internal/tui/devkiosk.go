@@ -0,0 +1,32 @@
+package tui
+
+import "time"
+
+// SetAutoplayBroadcaster sets the broadcaster that the kiosk toggle uses to
+// pause and resume auto-play in the browser.
+func (m *DevModel) SetAutoplayBroadcaster(ab AutoplayBroadcaster) {
+	m.autoplay = ab
+}
+
+// toggleAutoplay pauses auto-play of slides with autoAdvance, or resumes it
+// if it is paused.
+func (m *DevModel) toggleAutoplay() {
+	m.autoplayPaused = !m.autoplayPaused
+
+	message := "Auto-play resumed"
+	if m.autoplayPaused {
+		message = "Auto-play paused"
+	}
+	m.addEvent(DevEvent{
+		Type:      "action",
+		Message:   message,
+		Timestamp: time.Now(),
+	})
+
+	if m.autoplay == nil {
+		return
+	}
+	if err := m.autoplay.BroadcastAutoplay(!m.autoplayPaused); err != nil {
+		m.SetError(err)
+	}
+}
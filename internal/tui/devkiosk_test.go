@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mockAutoplayBroadcaster records broadcast auto-play states.
+type mockAutoplayBroadcaster struct {
+	states []bool
+}
+
+func (b *mockAutoplayBroadcaster) BroadcastAutoplay(enabled bool) error {
+	b.states = append(b.states, enabled)
+	return nil
+}
+
+func TestDevModel_AutoplayKey(t *testing.T) {
+	m := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+	broadcaster := &mockAutoplayBroadcaster{}
+	m.SetAutoplayBroadcaster(broadcaster)
+
+	// Nothing is sent until the toggle is used, so clients play by default
+	if len(broadcaster.states) != 0 {
+		t.Fatalf("states = %v, want none before the toggle", broadcaster.states)
+	}
+
+	key := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")}
+	m.Update(key)
+	m.Update(key)
+
+	if len(broadcaster.states) != 2 || broadcaster.states[0] || !broadcaster.states[1] {
+		t.Errorf("states = %v, want [false true]", broadcaster.states)
+	}
+
+	events := m.state.RecentEvents
+	if len(events) < 2 || events[len(events)-2].Message != "Auto-play paused" || events[len(events)-1].Message != "Auto-play resumed" {
+		t.Errorf("events = %+v, want paused then resumed", events)
+	}
+}
+
+func TestDevModel_AutoplayKeyWithoutBroadcaster(t *testing.T) {
+	m := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+	if !m.autoplayPaused {
+		t.Error("autoplayPaused = false, want the toggle to work without a broadcaster")
+	}
+}
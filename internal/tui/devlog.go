@@ -0,0 +1,319 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxEvents is the number of events kept for the log panel.
+const maxEvents = 500
+
+// summaryEvents is the number of events shown in the recent activity section
+// of the main screen.
+const summaryEvents = 5
+
+// Event type filters of the log panel. The error filter also shows warnings.
+const (
+	logFilterErrors  = "error"
+	logFilterReloads = "reload"
+	logFilterActions = "action"
+)
+
+// newLogFilterInput returns the input for the log panel's substring filter.
+func newLogFilterInput() textinput.Model {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "filter"
+	ti.CharLimit = 100
+	ti.Width = 40
+	return ti
+}
+
+// openLogFile opens the file that events are mirrored to, appending to it if
+// it exists.
+func (m *DevModel) openLogFile(path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	m.logFile = f
+	return nil
+}
+
+// writeLogFile mirrors an event to the log file, if set. The caller must hold
+// m.mu. After a failed write the log file is closed and the error shown.
+func (m *DevModel) writeLogFile(event DevEvent) {
+	if m.logFile == nil {
+		return
+	}
+	line := fmt.Sprintf("%s [%s] %s\n", event.Timestamp.Format("2006-01-02 15:04:05"), event.Type, event.Message)
+	if _, err := m.logFile.WriteString(line); err != nil {
+		_ = m.logFile.Close()
+		m.logFile = nil
+		m.state.Error = fmt.Errorf("failed to write log file: %w", err)
+	}
+}
+
+// closeLogFile closes the log file, if set.
+func (m *DevModel) closeLogFile() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.logFile != nil {
+		_ = m.logFile.Close()
+		m.logFile = nil
+	}
+}
+
+// DroppedEvents returns the number of events SendEvent dropped because the
+// events channel was full.
+func (m *DevModel) DroppedEvents() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.droppedEvents
+}
+
+// filterEvents returns the events matching an event type filter and a
+// case-insensitive substring of the message. Empty filters match everything.
+func filterEvents(events []DevEvent, typeFilter, text string) []DevEvent {
+	text = strings.ToLower(text)
+	var matched []DevEvent
+	for _, event := range events {
+		if typeFilter != "" && !matchesTypeFilter(event.Type, typeFilter) {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(event.Message), text) {
+			continue
+		}
+		matched = append(matched, event)
+	}
+	return matched
+}
+
+// matchesTypeFilter reports whether an event type passes a type filter.
+func matchesTypeFilter(eventType, typeFilter string) bool {
+	if typeFilter == logFilterErrors {
+		return eventType == "error" || eventType == "warning"
+	}
+	return eventType == typeFilter
+}
+
+// openLog shows the log panel, scrolled to the newest event.
+func (m *DevModel) openLog() {
+	m.showLog = true
+	m.syncLogViewport()
+	m.logViewport.GotoBottom()
+}
+
+// toggleLogTypeFilter shows only events of a type, or all events if the
+// filter is already active.
+func (m *DevModel) toggleLogTypeFilter(typeFilter string) {
+	if m.logTypeFilter == typeFilter {
+		m.logTypeFilter = ""
+	} else {
+		m.logTypeFilter = typeFilter
+	}
+	m.syncLogViewport()
+	m.logViewport.GotoBottom()
+}
+
+// handleLogKey handles keyboard input in the log panel.
+func (m *DevModel) handleLogKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Typing a filter: enter keeps it, esc clears it
+	if m.logFilterInput.Focused() {
+		switch msg.String() {
+		case "enter":
+			m.logFilterInput.Blur()
+		case "esc":
+			m.logFilterInput.Blur()
+			m.logFilterInput.SetValue("")
+		default:
+			var cmd tea.Cmd
+			m.logFilterInput, cmd = m.logFilterInput.Update(msg)
+			m.syncLogViewport()
+			m.logViewport.GotoBottom()
+			return m, cmd
+		}
+		m.syncLogViewport()
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "l", "q":
+		m.showLog = false
+
+	case "esc":
+		// Clear the filters first, then close
+		if m.logTypeFilter == "" && m.logFilterInput.Value() == "" {
+			m.showLog = false
+			return m, nil
+		}
+		m.logTypeFilter = ""
+		m.logFilterInput.SetValue("")
+		m.syncLogViewport()
+		m.logViewport.GotoBottom()
+
+	case "/":
+		return m, m.logFilterInput.Focus()
+
+	case "e":
+		m.toggleLogTypeFilter(logFilterErrors)
+
+	case "r":
+		m.toggleLogTypeFilter(logFilterReloads)
+
+	case "a":
+		m.toggleLogTypeFilter(logFilterActions)
+
+	case "home", "g":
+		m.logViewport.GotoTop()
+
+	case "end", "G":
+		m.logViewport.GotoBottom()
+
+	default:
+		// Scrolling
+		var cmd tea.Cmd
+		m.logViewport, cmd = m.logViewport.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+// logViewportSize returns the size of the log panel's viewport, leaving room
+// for its title, status line, and help.
+func (m *DevModel) logViewportSize() (int, int) {
+	width, height := m.windowWidth, m.windowHeight-8
+	if width <= 0 {
+		width = 80
+	}
+	if m.windowHeight <= 0 {
+		height = 20
+	}
+	return width, max(height, 3)
+}
+
+// syncLogViewport updates the log panel's viewport to the filtered events and
+// the window size. A viewport scrolled to the bottom follows new events.
+func (m *DevModel) syncLogViewport() {
+	m.mu.RLock()
+	events := filterEvents(m.state.RecentEvents, m.logTypeFilter, m.logFilterInput.Value())
+	m.mu.RUnlock()
+
+	width, height := m.logViewportSize()
+	follow := m.logViewport.AtBottom()
+	m.logViewport.Width = width
+	m.logViewport.Height = height
+
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		lines = append(lines, m.formatLogEvent(event, width))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, RenderMuted("  No matching events"))
+	}
+	m.logViewport.SetContent(strings.Join(lines, "\n"))
+
+	if follow {
+		m.logViewport.GotoBottom()
+	}
+}
+
+// formatLogEvent formats an event for the log panel, wrapping long messages
+// to width instead of cutting them off.
+func (m *DevModel) formatLogEvent(event DevEvent, width int) string {
+	timeStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(10)
+
+	icon, msgStyle := eventStyle(event.Type)
+	prefix := fmt.Sprintf("  %s %s ", timeStyle.Render(event.Timestamp.Format("15:04:05")), icon)
+	msgWidth := max(width-lipgloss.Width(prefix), 20)
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, prefix, msgStyle.Width(msgWidth).Render(event.Message))
+}
+
+// viewLog renders the full-screen log panel.
+func (m *DevModel) viewLog() string {
+	m.syncLogViewport()
+
+	m.mu.RLock()
+	total := len(m.state.RecentEvents)
+	shown := len(filterEvents(m.state.RecentEvents, m.logTypeFilter, m.logFilterInput.Value()))
+	dropped := m.droppedEvents
+	m.mu.RUnlock()
+
+	var b strings.Builder
+
+	// Title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
+
+	b.WriteString(titleStyle.Render("📜 Activity Log"))
+	b.WriteString("\n\n")
+
+	// Filters and counts
+	status := fmt.Sprintf("%d of %d events", shown, total)
+	switch m.logTypeFilter {
+	case logFilterErrors:
+		status += " • errors and warnings"
+	case logFilterReloads:
+		status += " • reloads"
+	case logFilterActions:
+		status += " • actions"
+	}
+	if dropped > 0 {
+		status += fmt.Sprintf(" • %d dropped", dropped)
+	}
+	if m.logFilterInput.Focused() || m.logFilterInput.Value() != "" {
+		b.WriteString(m.logFilterInput.View())
+		b.WriteString("  ")
+	}
+	b.WriteString(RenderMuted(status))
+	b.WriteString("\n")
+
+	b.WriteString(m.logViewport.View())
+	b.WriteString("\n")
+
+	// Help text
+	helpStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		MarginTop(1)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	var help string
+	if m.logFilterInput.Focused() {
+		help = fmt.Sprintf(
+			"%s keep filter • %s clear filter",
+			keyStyle.Render("enter"),
+			keyStyle.Render("esc"),
+		)
+	} else {
+		help = fmt.Sprintf(
+			"%s/%s scroll • %s/%s oldest/newest • %s filter • %s errors • %s reloads • %s actions • %s clear filters • %s close",
+			keyStyle.Render("↑"),
+			keyStyle.Render("↓"),
+			keyStyle.Render("home"),
+			keyStyle.Render("end"),
+			keyStyle.Render("/"),
+			keyStyle.Render("e"),
+			keyStyle.Render("r"),
+			keyStyle.Render("a"),
+			keyStyle.Render("esc"),
+			keyStyle.Render("l"),
+		)
+	}
+	b.WriteString(helpStyle.Render(help))
+
+	return b.String()
+}
@@ -0,0 +1,222 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newLogTestModel returns a dev model with one event of each type.
+func newLogTestModel() *DevModel {
+	m := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	for _, event := range []DevEvent{
+		{Type: "reload", Message: "File changed: slides.md"},
+		{Type: "action", Message: "Opening browser..."},
+		{Type: "error", Message: "PDF export failed"},
+		{Type: "warning", Message: "slides.md was deleted or moved"},
+	} {
+		event.Timestamp = now
+		m.addEvent(event)
+	}
+	return m
+}
+
+// pressKeys sends keys to the model, one rune or named key at a time.
+func pressKeys(m *DevModel, keys ...string) {
+	for _, key := range keys {
+		switch key {
+		case "esc":
+			m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+		case "enter":
+			m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		default:
+			for _, r := range key {
+				m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+			}
+		}
+	}
+}
+
+func TestFilterEvents(t *testing.T) {
+	m := newLogTestModel()
+	events := m.state.RecentEvents
+
+	tests := []struct {
+		name       string
+		typeFilter string
+		text       string
+		want       []string
+	}{
+		{"no filters", "", "", []string{"File changed: slides.md", "Opening browser...", "PDF export failed", "slides.md was deleted or moved"}},
+		{"errors include warnings", logFilterErrors, "", []string{"PDF export failed", "slides.md was deleted or moved"}},
+		{"reloads", logFilterReloads, "", []string{"File changed: slides.md"}},
+		{"substring ignores case", "", "SLIDES.MD", []string{"File changed: slides.md", "slides.md was deleted or moved"}},
+		{"type and substring", logFilterErrors, "pdf", []string{"PDF export failed"}},
+		{"no match", logFilterActions, "pdf", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, event := range filterEvents(events, tt.typeFilter, tt.text) {
+				got = append(got, event.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filterEvents() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDevModel_LogPanelKeys(t *testing.T) {
+	m := newLogTestModel()
+
+	pressKeys(m, "l")
+	if !m.showLog {
+		t.Fatal("l should open the log panel")
+	}
+	view := m.View()
+	if !strings.Contains(view, "Activity Log") || !strings.Contains(view, "4 of 4 events") {
+		t.Errorf("expected the log panel with all events, got:\n%s", view)
+	}
+
+	// Quick filters toggle
+	pressKeys(m, "e")
+	view = m.View()
+	if !strings.Contains(view, "2 of 4 events") || strings.Contains(view, "Opening browser") {
+		t.Errorf("e should show errors and warnings only, got:\n%s", view)
+	}
+	pressKeys(m, "r")
+	if m.logTypeFilter != logFilterReloads {
+		t.Errorf("r should show reloads, got filter %q", m.logTypeFilter)
+	}
+	pressKeys(m, "r")
+	if m.logTypeFilter != "" {
+		t.Errorf("r again should clear the filter, got %q", m.logTypeFilter)
+	}
+
+	// Typing a filter, where e/r/a/l are text rather than commands
+	pressKeys(m, "/", "pdf", "enter")
+	if m.logFilterInput.Focused() || m.logFilterInput.Value() != "pdf" || !m.showLog {
+		t.Fatalf("expected the pdf filter to be kept, got %q", m.logFilterInput.Value())
+	}
+	view = m.View()
+	if !strings.Contains(view, "1 of 4 events") || !strings.Contains(view, "PDF export failed") {
+		t.Errorf("expected only the PDF event, got:\n%s", view)
+	}
+	pressKeys(m, "/", "x", "esc")
+	if m.logFilterInput.Value() != "" {
+		t.Errorf("esc while typing should clear the filter, got %q", m.logFilterInput.Value())
+	}
+
+	// esc clears the filters first, then closes
+	pressKeys(m, "a", "esc")
+	if !m.showLog || m.logTypeFilter != "" {
+		t.Errorf("esc should clear the filters and keep the panel open")
+	}
+	pressKeys(m, "esc")
+	if m.showLog {
+		t.Error("esc without filters should close the log panel")
+	}
+
+	pressKeys(m, "l", "l")
+	if m.showLog {
+		t.Error("l should close the log panel")
+	}
+}
+
+func TestDevModel_LogPanelWrapsLongMessages(t *testing.T) {
+	m := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+	m.Update(tea.WindowSizeMsg{Width: 60, Height: 30})
+	message := strings.TrimSpace(strings.Repeat("connection refused by the database ", 6))
+	m.addEvent(DevEvent{Type: "error", Message: message, Timestamp: time.Now()})
+
+	pressKeys(m, "l")
+	view := m.View()
+	if got := strings.Count(view, "database"); got != 6 {
+		t.Errorf("expected the whole message to be shown, found %d of 6 words, got:\n%s", got, view)
+	}
+	for _, line := range strings.Split(m.formatLogEvent(m.state.RecentEvents[0], 60), "\n") {
+		if w := len([]rune(line)); w > 60 {
+			t.Errorf("line is %d characters wide, want at most 60: %q", w, line)
+		}
+	}
+}
+
+func TestDevModel_LogPanelScrollback(t *testing.T) {
+	m := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+	m.Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	for i := 0; i < 50; i++ {
+		m.addEvent(DevEvent{Type: "reload", Message: fmt.Sprintf("event %02d", i), Timestamp: time.Now()})
+	}
+
+	pressKeys(m, "l")
+	view := m.View()
+	if !strings.Contains(view, "event 49") || strings.Contains(view, "event 00") {
+		t.Errorf("expected the log to start at the newest events, got:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyHome})
+	view = m.View()
+	if !strings.Contains(view, "event 00") || strings.Contains(view, "event 49") {
+		t.Errorf("home should scroll to the oldest events, got:\n%s", view)
+	}
+}
+
+func TestDevModel_SendEventCountsDropped(t *testing.T) {
+	m := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+
+	// Fill the channel from several goroutines; nothing reads it
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 30; j++ {
+				m.SendEvent("reload", "File changed: slides.md")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := m.DroppedEvents(), 120-cap(m.eventsCh); got != want {
+		t.Errorf("DroppedEvents() = %d, want %d", got, want)
+	}
+	if view := m.viewEvents(); !strings.Contains(view, fmt.Sprintf("(%d dropped)", m.DroppedEvents())) {
+		t.Errorf("expected the dropped events in the summary, got:\n%s", view)
+	}
+}
+
+func TestDevModel_LogFile(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "tap.log")
+	if err := os.WriteFile(logFile, []byte("earlier session\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewDevModel(DevConfig{MarkdownFile: "slides.md", LogFile: logFile})
+	m.addEvent(DevEvent{Type: "error", Message: "PDF export failed", Timestamp: time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)})
+	m.Close()
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "earlier session\n2026-01-01 10:00:00 [error] PDF export failed\n"
+	if string(data) != want {
+		t.Errorf("log file = %q, want %q", data, want)
+	}
+
+	// A log file that can't be opened is reported, not fatal
+	m = NewDevModel(DevConfig{MarkdownFile: "slides.md", LogFile: filepath.Join(t.TempDir(), "missing", "tap.log")})
+	if events := m.state.RecentEvents; len(events) != 1 || !strings.Contains(events[0].Message, "failed to open log file") {
+		t.Errorf("expected a warning about the log file, got %v", events)
+	}
+}
@@ -0,0 +1,80 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// SetCurrentSlide sets the slide the browser shows, as the dev server hears
+// from its clients, or -1 if no browser reported one. It is safe to call
+// from other goroutines.
+func (m *DevModel) SetCurrentSlide(index int) {
+	m.mu.Lock()
+	m.currentSlide = index
+	m.mu.Unlock()
+}
+
+// SetSlideTitles sets the title of each slide by index, shown next to the
+// current slide in the status section, such as after the presentation was
+// reloaded. It is safe to call from other goroutines.
+func (m *DevModel) SetSlideTitles(titles []string) {
+	m.mu.Lock()
+	m.slideTitles = titles
+	m.mu.Unlock()
+}
+
+// SlideTitles returns the title of each slide of pres by index: its first
+// heading, or its first line if it has none.
+func SlideTitles(pres *parser.Presentation) []string {
+	titles := make([]string, len(pres.Slides))
+	for i, slide := range pres.Slides {
+		titles[i] = extractSlideTitle(slide.Content)
+	}
+	return titles
+}
+
+// currentSlideText returns the slide the browser shows as "7/34 — Benchmarks",
+// numbered from 1, or "" if it is unknown. The title is left out if the
+// slide has none. count is the number of slides, or 0 to use the number of
+// titles.
+func (m *DevModel) currentSlideText(count int) string {
+	m.mu.RLock()
+	index, titles := m.currentSlide, m.slideTitles
+	m.mu.RUnlock()
+
+	if index < 0 {
+		return ""
+	}
+	if count == 0 {
+		count = len(titles)
+	}
+	text := fmt.Sprintf("%d", index+1)
+	if count > 0 {
+		text += fmt.Sprintf("/%d", count)
+	}
+	if index < len(titles) && titles[index] != "" {
+		text += " — " + titles[index]
+	}
+	return text
+}
+
+// selectCurrentSlide selects the slide the browser shows in the image
+// generator's slide list, so pressing i while presenting targets the slide
+// on screen. The selection is left alone if the slide is unknown or not in
+// the list.
+func (m *DevModel) selectCurrentSlide(imageGen *ImageGenModel) {
+	m.mu.RLock()
+	index := m.currentSlide
+	m.mu.RUnlock()
+
+	if index < 0 {
+		return
+	}
+	for i, slide := range imageGen.Slides {
+		if slide.Index == index {
+			imageGen.SelectedIndex = i
+			return
+		}
+	}
+}
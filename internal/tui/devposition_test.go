@@ -0,0 +1,116 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/server"
+)
+
+func TestDevModel_CurrentSlide(t *testing.T) {
+	model := NewDevModel(DevConfig{})
+	model.SetStatusSource(&fakeStatusSource{status: server.Status{SlideCount: 34}})
+	titles := make([]string, 34)
+	titles[6] = "Benchmarks"
+	model.SetSlideTitles(titles)
+
+	if strings.Contains(model.viewStatus(), "Current slide:") {
+		t.Error("expected no current slide before the browser reports one")
+	}
+
+	model.SetCurrentSlide(6)
+	if view := model.viewStatus(); !strings.Contains(view, "Current slide:") || !strings.Contains(view, "7/34 — Benchmarks") {
+		t.Errorf("expected current slide 7/34 — Benchmarks, got:\n%s", view)
+	}
+
+	// A new position replaces the old one, and untitled slides show no title
+	model.SetCurrentSlide(9)
+	if got := model.currentSlideText(34); got != "10/34" {
+		t.Errorf("currentSlideText() = %q, want %q", got, "10/34")
+	}
+
+	// Once no browser reports a slide, the line is hidden again
+	model.SetCurrentSlide(-1)
+	if strings.Contains(model.viewStatus(), "Current slide:") {
+		t.Error("expected no current slide once the browser disconnected")
+	}
+}
+
+func TestDevModel_CurrentSlideText(t *testing.T) {
+	tests := []struct {
+		name   string
+		index  int
+		count  int
+		titles []string
+		want   string
+	}{
+		{"unknown", -1, 3, []string{"Intro", "Setup", "Demo"}, ""},
+		{"with title", 1, 3, []string{"Intro", "Setup", "Demo"}, "2/3 — Setup"},
+		{"count from titles", 2, 0, []string{"Intro", "Setup", "Demo"}, "3/3 — Demo"},
+		{"past the titles", 4, 5, []string{"Intro"}, "5/5"},
+		{"no titles or count", 0, 0, nil, "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewDevModel(DevConfig{})
+			model.SetSlideTitles(tt.titles)
+			model.SetCurrentSlide(tt.index)
+			if got := model.currentSlideText(tt.count); got != tt.want {
+				t.Errorf("currentSlideText(%d) = %q, want %q", tt.count, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlideTitles(t *testing.T) {
+	markdown := "---\ntitle: Talk\n---\n\n# Welcome\n\n---\n\n<!-- layout: two-column -->\n\n## Benchmarks\n\n---\n\nJust some text\n"
+	pres, err := parser.New().Parse([]byte(markdown))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []string{"Welcome", "Benchmarks", "Just some text"}
+	if got := SlideTitles(pres); !reflect.DeepEqual(got, want) {
+		t.Errorf("SlideTitles() = %q, want %q", got, want)
+	}
+}
+
+func TestDevModel_ImageGeneratorSelectsCurrentSlide(t *testing.T) {
+	t.Setenv("GEMINI_API_KEY", "test-api-key")
+
+	mdFile := filepath.Join(t.TempDir(), "slides.md")
+	content := "# Intro\n\n---\n\n# Setup\n\n---\n\n# Benchmarks\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		current int
+		want    int
+	}{
+		{"slide on screen", 2, 2},
+		{"unknown slide", -1, 0},
+		{"slide not in the list", 7, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := NewDevModel(DevConfig{MarkdownFile: mdFile})
+			model.SetCurrentSlide(tt.current)
+
+			model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+			if model.imageGenModel == nil {
+				t.Fatal("expected the image generator to open")
+			}
+			if got := model.imageGenModel.SelectedIndex; got != tt.want {
+				t.Errorf("SelectedIndex = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
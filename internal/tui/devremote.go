@@ -0,0 +1,207 @@
+package tui
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/MiniCodeMonkey/tap/internal/server"
+)
+
+// RemoteController is an interface for the remote control pairing: reading
+// and replacing the pairing code, and listing and kicking paired remotes.
+type RemoteController interface {
+	RemoteCode() string
+	RotateRemoteCode() (string, error)
+	Remotes() []server.Remote
+	KickRemote(id int) bool
+}
+
+// SetRemoteController sets the controller used to show the pairing code and
+// manage paired remotes, which are read every second.
+func (m *DevModel) SetRemoteController(rc RemoteController) {
+	m.remoteController = rc
+	m.refreshRemotes()
+}
+
+// refreshRemotes reads the pairing code and paired remotes from the remote
+// controller, if set.
+func (m *DevModel) refreshRemotes() {
+	if m.remoteController == nil {
+		return
+	}
+	code := m.remoteController.RemoteCode()
+	remotes := m.remoteController.Remotes()
+
+	m.mu.Lock()
+	m.remoteCode = code
+	m.remotes = remotes
+	if m.remoteIndex >= len(remotes) {
+		m.remoteIndex = max(len(remotes)-1, 0)
+	}
+	m.mu.Unlock()
+}
+
+// remoteURL returns the URL of the remote control page, which is served next
+// to the audience view.
+func remoteURL(audienceURL string) string {
+	u, err := url.Parse(audienceURL)
+	if err != nil || u.Host == "" {
+		return ""
+	}
+	u.Path = "/remote"
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// rotateRemoteCode replaces the pairing code, which unpairs and disconnects
+// all remotes.
+func (m *DevModel) rotateRemoteCode() {
+	if m.remoteController == nil {
+		return
+	}
+
+	if _, err := m.remoteController.RotateRemoteCode(); err != nil {
+		m.SetError(err)
+		return
+	}
+
+	m.refreshRemotes()
+	m.addEvent(DevEvent{
+		Type:      "action",
+		Message:   "Remote pairing code regenerated; remotes disconnected",
+		Timestamp: time.Now(),
+	})
+}
+
+// kickSelectedRemote unpairs and disconnects the remote selected in the
+// remotes panel.
+func (m *DevModel) kickSelectedRemote() {
+	m.mu.RLock()
+	if m.remoteController == nil || m.remoteIndex >= len(m.remotes) {
+		m.mu.RUnlock()
+		return
+	}
+	remote := m.remotes[m.remoteIndex]
+	m.mu.RUnlock()
+
+	if !m.remoteController.KickRemote(remote.ID) {
+		return
+	}
+
+	m.refreshRemotes()
+	m.addEvent(DevEvent{
+		Type:      "action",
+		Message:   fmt.Sprintf("Kicked remote #%d (%s)", remote.ID, remote.Name),
+		Timestamp: time.Now(),
+	})
+}
+
+// handleRemotesKey handles keyboard input when the remotes panel is open.
+func (m *DevModel) handleRemotesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q", "m":
+		m.showRemotes = false
+
+	case "up", "k":
+		if m.remoteIndex > 0 {
+			m.remoteIndex--
+		}
+
+	case "down", "j":
+		if m.remoteIndex < len(m.remotes)-1 {
+			m.remoteIndex++
+		}
+
+	case "x":
+		m.kickSelectedRemote()
+
+	case "g":
+		m.rotateRemoteCode()
+	}
+
+	return m, nil
+}
+
+// viewRemotes renders the remotes panel.
+func (m *DevModel) viewRemotes() string {
+	m.mu.RLock()
+	code := m.remoteCode
+	remotes := make([]server.Remote, len(m.remotes))
+	copy(remotes, m.remotes)
+	selected := m.remoteIndex
+	m.mu.RUnlock()
+
+	var b strings.Builder
+
+	// Title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
+
+	b.WriteString(titleStyle.Render("📱 Remote Control"))
+	b.WriteString("\n\n")
+
+	labelStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted).
+		Width(18)
+
+	b.WriteString(labelStyle.Render("Open on a phone:"))
+	b.WriteString(lipgloss.NewStyle().Foreground(ColorSecondary).Bold(true).Render(remoteURL(m.config.AudienceURL)))
+	b.WriteString("\n")
+	b.WriteString(labelStyle.Render("Pairing code:"))
+	b.WriteString(lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true).Render(code))
+	b.WriteString("\n\n")
+
+	// Remote list
+	if len(remotes) == 0 {
+		b.WriteString(RenderMuted("  No paired remotes"))
+		b.WriteString("\n")
+	}
+	for i, remote := range remotes {
+		line := fmt.Sprintf("#%d %s", remote.ID, remote.Name)
+		state := fmt.Sprintf(" (paired %s", remote.PairedAt.Format("15:04:05"))
+		if !remote.Connected {
+			state += ", disconnected"
+		}
+		state += ")"
+
+		if i == selected {
+			selectedStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(ColorSecondary)
+			b.WriteString(selectedStyle.Render("> " + line))
+		} else {
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorWhite).Render("  " + line))
+		}
+		b.WriteString(RenderMuted(state))
+		b.WriteString("\n")
+	}
+
+	// Help text
+	b.WriteString("\n")
+	helpStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	help := fmt.Sprintf(
+		"%s/%s navigate • %s kick • %s new code • %s close",
+		keyStyle.Render("↑"),
+		keyStyle.Render("↓"),
+		keyStyle.Render("x"),
+		keyStyle.Render("g"),
+		keyStyle.Render("esc"),
+	)
+	b.WriteString(helpStyle.Render(help))
+
+	return b.String()
+}
@@ -0,0 +1,160 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/server"
+)
+
+// fakeRemoteController keeps a pairing code and paired remotes in memory.
+type fakeRemoteController struct {
+	code    string
+	remotes []server.Remote
+	kicked  []int
+	rotated int
+}
+
+func (c *fakeRemoteController) RemoteCode() string { return c.code }
+
+func (c *fakeRemoteController) RotateRemoteCode() (string, error) {
+	c.rotated++
+	c.code = "5678"
+	c.remotes = nil
+	return c.code, nil
+}
+
+func (c *fakeRemoteController) Remotes() []server.Remote {
+	return append([]server.Remote(nil), c.remotes...)
+}
+
+func (c *fakeRemoteController) KickRemote(id int) bool {
+	for i, remote := range c.remotes {
+		if remote.ID == id {
+			c.kicked = append(c.kicked, id)
+			c.remotes = append(c.remotes[:i], c.remotes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func newRemoteTestModel() (*DevModel, *fakeRemoteController) {
+	model := NewDevModel(DevConfig{
+		AudienceURL:  "http://192.168.1.10:3000",
+		PresenterURL: "http://192.168.1.10:3000/presenter",
+		MarkdownFile: "slides.md",
+	})
+	model.windowWidth = 120
+	model.windowHeight = 24
+	controller := &fakeRemoteController{
+		code: "1234",
+		remotes: []server.Remote{
+			{ID: 1, Name: "Safari on iPhone", PairedAt: time.Date(2026, 1, 1, 10, 0, 0, 0, time.Local), Connected: true},
+			{ID: 2, Name: "Chrome on Android", PairedAt: time.Date(2026, 1, 1, 10, 5, 0, 0, time.Local)},
+		},
+	}
+	model.SetRemoteController(controller)
+	return model, controller
+}
+
+func TestRemoteURL(t *testing.T) {
+	tests := []struct {
+		audienceURL string
+		want        string
+	}{
+		{"http://192.168.1.10:3000", "http://192.168.1.10:3000/remote"},
+		{"http://localhost:3000/?theme=paper", "http://localhost:3000/remote"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := remoteURL(tt.audienceURL); got != tt.want {
+			t.Errorf("remoteURL(%q) = %q, want %q", tt.audienceURL, got, tt.want)
+		}
+	}
+}
+
+func TestDevModel_View_RemoteCode(t *testing.T) {
+	model, _ := newRemoteTestModel()
+
+	view := model.View()
+
+	if !strings.Contains(view, "http://192.168.1.10:3000/remote") {
+		t.Error("view should show the remote control URL")
+	}
+	if !strings.Contains(view, "code 1234") {
+		t.Error("view should show the pairing code")
+	}
+}
+
+func TestDevModel_View_NoRemoteController(t *testing.T) {
+	model := NewDevModel(DevConfig{MarkdownFile: "slides.md"})
+
+	if strings.Contains(model.View(), "Remote control:") {
+		t.Error("view should not show the remote control without a controller")
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if model.showRemotes {
+		t.Error("remotes panel should not open without a controller")
+	}
+}
+
+func TestDevModel_RemotesPanel(t *testing.T) {
+	model, _ := newRemoteTestModel()
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	if !model.showRemotes {
+		t.Fatal("expected the remotes panel to open")
+	}
+
+	view := model.View()
+	for _, want := range []string{"Remote Control", "1234", "#1 Safari on iPhone", "#2 Chrome on Android", "disconnected"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("remotes panel missing %q", want)
+		}
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.showRemotes {
+		t.Error("expected esc to close the remotes panel")
+	}
+}
+
+func TestDevModel_KickRemote(t *testing.T) {
+	model, controller := newRemoteTestModel()
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+
+	if len(controller.kicked) != 1 || controller.kicked[0] != 2 {
+		t.Fatalf("kicked = %v, want [2]", controller.kicked)
+	}
+	if len(model.remotes) != 1 || model.remoteIndex != 0 {
+		t.Errorf("remotes = %v, index %d; want one remote selected", model.remotes, model.remoteIndex)
+	}
+	if len(model.state.RecentEvents) == 0 || !strings.Contains(model.state.RecentEvents[0].Message, "Kicked remote #2") {
+		t.Errorf("expected a kicked remote event, got %v", model.state.RecentEvents)
+	}
+}
+
+func TestDevModel_RotateRemoteCode(t *testing.T) {
+	model, controller := newRemoteTestModel()
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'m'}})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'g'}})
+
+	if controller.rotated != 1 {
+		t.Fatalf("expected the code to be rotated once, got %d", controller.rotated)
+	}
+	if model.remoteCode != "5678" || len(model.remotes) != 0 {
+		t.Errorf("code = %q, remotes = %v; want the new code and no remotes", model.remoteCode, model.remotes)
+	}
+	if !strings.Contains(model.View(), "No paired remotes") {
+		t.Error("remotes panel should show that no remotes are paired")
+	}
+}
@@ -0,0 +1,58 @@
+package tui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// openSearch opens the slide search over the markdown file's slides.
+func (m *DevModel) openSearch() tea.Cmd {
+	entries, err := BuildSearchIndex(m.config.MarkdownFile)
+	if err != nil {
+		m.SetError(fmt.Errorf("failed to load slides: %w", err))
+		return nil
+	}
+	m.search = NewSearchModel(entries)
+	return m.search.Init()
+}
+
+// refreshSearch rebuilds the index of the open slide searches after a
+// reload, keeping their queries. Without the markdown file, they keep
+// searching the last version.
+func (m *DevModel) refreshSearch() {
+	searches := []*SearchModel{m.search}
+	if m.showImageGenerator && m.imageGenModel != nil {
+		searches = append(searches, m.imageGenModel.search)
+	}
+
+	var entries []SearchEntry
+	for _, search := range searches {
+		if search == nil {
+			continue
+		}
+		if entries == nil {
+			var err error
+			if entries, err = BuildSearchIndex(m.config.MarkdownFile); err != nil {
+				return
+			}
+		}
+		search.SetEntries(entries)
+	}
+}
+
+// handleSearchKey handles keyboard input when the slide search is open. On
+// enter, the audience and presenter views jump to the chosen slide.
+func (m *DevModel) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	_, cmd := m.search.Update(msg)
+	if !m.search.Done() {
+		return m, cmd
+	}
+
+	search := m.search
+	m.search = nil
+	if result, ok := search.Selected(); ok && search.Chosen() {
+		m.jumpToSlide(result.Index)
+	}
+	return m, nil
+}
@@ -0,0 +1,122 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// typeKeys sends each rune of text to a key handler.
+func typeKeys(handle func(tea.KeyMsg) (tea.Model, tea.Cmd), text string) {
+	for _, r := range text {
+		handle(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestDevModel_Search(t *testing.T) {
+	model := NewDevModel(DevConfig{MarkdownFile: writeSearchFixture(t)})
+	broadcaster := &mockSlideBroadcaster{}
+	model.SetSlideBroadcaster(broadcaster)
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if model.search == nil {
+		t.Fatal("expected the search to open after pressing '/'")
+	}
+
+	// Keys that are shortcuts on the main screen are typed into the query
+	typeKeys(model.handleKeyPress, "helm")
+	if model.quitting || model.showThemePicker {
+		t.Fatal("expected keys to go to the search")
+	}
+	if !strings.Contains(model.View(), "Ask who has used Helm before") {
+		t.Errorf("expected the matching notes in the view, got:\n%s", model.View())
+	}
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.search != nil {
+		t.Error("expected the search to close after choosing a slide")
+	}
+	if len(broadcaster.indices) != 1 || broadcaster.indices[0] != 2 {
+		t.Errorf("expected broadcast of slide 2, got %v", broadcaster.indices)
+	}
+}
+
+func TestDevModel_SearchEsc(t *testing.T) {
+	model := NewDevModel(DevConfig{MarkdownFile: writeSearchFixture(t)})
+	broadcaster := &mockSlideBroadcaster{}
+	model.SetSlideBroadcaster(broadcaster)
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	typeKeys(model.handleKeyPress, "pods")
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if model.search != nil {
+		t.Error("expected the search to close on esc")
+	}
+	if len(broadcaster.indices) != 0 {
+		t.Errorf("esc should not change slides, got broadcasts %v", broadcaster.indices)
+	}
+}
+
+func TestDevModel_SearchRebuiltOnReload(t *testing.T) {
+	mdFile := writeSearchFixture(t)
+	model := NewDevModel(DevConfig{MarkdownFile: mdFile})
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	typeKeys(model.handleKeyPress, "terraform")
+	if len(model.search.Results()) != 0 {
+		t.Fatalf("expected no results before the edit, got %+v", model.search.Results())
+	}
+
+	if err := os.WriteFile(mdFile, []byte(searchFixtureDeck+"\n\n---\n\n# Terraform"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	model.Update(devEventMsg{event: DevEvent{Type: "action", Message: "Opened browser"}})
+	if len(model.search.Results()) != 0 {
+		t.Error("expected other events not to rebuild the index")
+	}
+
+	model.Update(devEventMsg{event: DevEvent{Type: "reload", Message: "File changed: slides.md"}})
+	results := model.search.Results()
+	if len(results) != 1 || results[0].Index != 4 {
+		t.Errorf("expected the new slide after the reload, got %+v", results)
+	}
+	if model.search.Query() != "terraform" {
+		t.Errorf("expected the query to be kept, got %q", model.search.Query())
+	}
+}
+
+func TestImageGenModel_SlideSearch(t *testing.T) {
+	m, err := NewImageGenModel(writeSearchFixture(t))
+	if err != nil {
+		t.Fatalf("NewImageGenModel() returned error: %v", err)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("/")})
+	if m.search == nil {
+		t.Fatal("expected the search to open after pressing '/'")
+	}
+	if !strings.Contains(m.View(), "Search Slides") {
+		t.Errorf("expected the search in the view, got:\n%s", m.View())
+	}
+
+	// g is typed into the query rather than starting a batch
+	typeKeys(func(msg tea.KeyMsg) (tea.Model, tea.Cmd) { return m.Update(msg) }, "kubectl get")
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.search != nil {
+		t.Error("expected the search to close after choosing a slide")
+	}
+	if m.Step != ImageGenStepSlideSelect {
+		t.Errorf("expected to stay in slide selection, got step %d", m.Step)
+	}
+	if slide := m.GetSelectedSlide(); slide == nil || slide.Index != 3 {
+		t.Errorf("expected slide 3 to be selected, got %+v", slide)
+	}
+
+	// Without a search, esc still cancels the image generator
+	if newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyEsc}); newModel != nil {
+		t.Error("expected esc to cancel the image generator")
+	}
+}
@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+)
+
+// themePreviewDelay is how long the theme picker waits after the highlighted
+// theme changes before the browser previews it, so holding j doesn't send a
+// theme for every step.
+const themePreviewDelay = 200 * time.Millisecond
+
+// themePreviewMsg is sent themePreviewDelay after the highlighted theme
+// changes, to preview it if it is still highlighted.
+type themePreviewMsg struct {
+	seq int
+}
+
+// openThemePicker opens the theme picker at the current theme.
+func (m *DevModel) openThemePicker() {
+	m.showThemePicker = true
+	m.previewedTheme = m.currentTheme
+	for i, t := range m.themeOptions {
+		if t.Name == m.currentTheme {
+			m.themePickerIndex = i
+			break
+		}
+	}
+}
+
+// handleThemePickerKey handles keyboard input when the theme picker is open.
+// The browser previews the highlighted theme; esc goes back to the current
+// theme, and enter keeps the highlighted theme and saves it to the
+// markdown file's frontmatter.
+func (m *DevModel) handleThemePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.closeThemePicker()
+		if m.previewedTheme != m.currentTheme {
+			m.broadcastTheme(m.currentTheme)
+		}
+		return m, nil
+
+	case "up", "k":
+		if m.themePickerIndex > 0 {
+			m.themePickerIndex--
+			return m, m.schedulePreview()
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.themePickerIndex < len(m.themeOptions)-1 {
+			m.themePickerIndex++
+			return m, m.schedulePreview()
+		}
+		return m, nil
+
+	case "enter":
+		// Select theme and broadcast
+		selectedTheme := m.themeOptions[m.themePickerIndex].Name
+		m.currentTheme = selectedTheme
+		m.state.Status.Theme = selectedTheme
+		m.closeThemePicker()
+		m.broadcastTheme(selectedTheme)
+
+		// Persist theme change to markdown file
+		if m.config.MarkdownFile != "" {
+			absPath, err := filepath.Abs(m.config.MarkdownFile)
+			if err == nil {
+				if err := config.UpdateThemeInFile(absPath, selectedTheme); err != nil {
+					m.addEvent(DevEvent{
+						Type:      "error",
+						Message:   fmt.Sprintf("Failed to save theme: %v", err),
+						Timestamp: time.Now(),
+					})
+				}
+			}
+		}
+
+		m.addEvent(DevEvent{
+			Type:      "action",
+			Message:   fmt.Sprintf("Theme changed to %s", selectedTheme),
+			Timestamp: time.Now(),
+		})
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// closeThemePicker closes the theme picker, dropping any pending preview.
+func (m *DevModel) closeThemePicker() {
+	m.showThemePicker = false
+	m.themePreviewSeq++
+}
+
+// schedulePreview returns a command that previews the highlighted theme
+// after themePreviewDelay, unless another theme is highlighted first.
+func (m *DevModel) schedulePreview() tea.Cmd {
+	m.themePreviewSeq++
+	seq := m.themePreviewSeq
+	return tea.Tick(themePreviewDelay, func(time.Time) tea.Msg {
+		return themePreviewMsg{seq: seq}
+	})
+}
+
+// previewTheme shows the highlighted theme in the browser, if msg is for the
+// latest highlight and the picker is still open.
+func (m *DevModel) previewTheme(msg themePreviewMsg) {
+	if !m.showThemePicker || msg.seq != m.themePreviewSeq {
+		return
+	}
+	theme := m.themeOptions[m.themePickerIndex].Name
+	if theme != m.previewedTheme {
+		m.broadcastTheme(theme)
+	}
+}
+
+// broadcastTheme sends a theme to the browser, if there is a theme
+// broadcaster.
+func (m *DevModel) broadcastTheme(theme string) {
+	m.previewedTheme = theme
+	if m.themeBroadcaster != nil {
+		_ = m.themeBroadcaster.BroadcastTheme(theme)
+	}
+}
@@ -0,0 +1,132 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newThemePickerModel returns a dev model for a markdown file with the given
+// content, with the theme picker open at paper.
+func newThemePickerModel(t *testing.T, content string) (*DevModel, *mockThemeBroadcaster, string) {
+	t.Helper()
+	mdFile := filepath.Join(t.TempDir(), "slides.md")
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	model := NewDevModel(DevConfig{MarkdownFile: mdFile, CurrentTheme: "paper"})
+	broadcaster := &mockThemeBroadcaster{}
+	model.SetThemeBroadcaster(broadcaster)
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	if model.themeOptions[model.themePickerIndex].Name != "paper" {
+		t.Fatalf("expected the picker to start at paper, got %q", model.themeOptions[model.themePickerIndex].Name)
+	}
+	return model, broadcaster, mdFile
+}
+
+// moveThemePicker presses a key in the theme picker and returns the preview
+// message it schedules, if any.
+func moveThemePicker(t *testing.T, model *DevModel, key string) tea.Msg {
+	t.Helper()
+	_, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	if cmd == nil {
+		return nil
+	}
+	return cmd()
+}
+
+func TestDevModel_ThemePickerPreview(t *testing.T) {
+	model, broadcaster, _ := newThemePickerModel(t, "# Slide 1\n")
+
+	// Holding j schedules a preview for every step, but only the last is sent
+	var previews []tea.Msg
+	for range 3 {
+		previews = append(previews, moveThemePicker(t, model, "j"))
+	}
+	highlighted := model.themeOptions[model.themePickerIndex].Name
+	for _, msg := range previews {
+		model.Update(msg)
+	}
+	if !reflect.DeepEqual(broadcaster.themes, []string{highlighted}) {
+		t.Errorf("expected a single preview of %s, got %v", highlighted, broadcaster.themes)
+	}
+	if model.currentTheme != "paper" {
+		t.Errorf("previewing should not change the current theme, got %q", model.currentTheme)
+	}
+
+	// Coming back to the previewed theme doesn't send it again
+	model.Update(moveThemePicker(t, model, "k"))
+	model.Update(moveThemePicker(t, model, "j"))
+	if len(broadcaster.themes) != 3 || broadcaster.themes[2] != highlighted {
+		t.Errorf("expected previews of the previous theme and %s again, got %v", highlighted, broadcaster.themes)
+	}
+}
+
+func TestDevModel_ThemePickerEscReverts(t *testing.T) {
+	model, broadcaster, mdFile := newThemePickerModel(t, "# Slide 1\n")
+
+	model.Update(moveThemePicker(t, model, "j"))
+	if len(broadcaster.themes) != 1 {
+		t.Fatalf("expected a preview, got %v", broadcaster.themes)
+	}
+
+	// A preview still pending when the picker closes is dropped
+	pending := moveThemePicker(t, model, "j")
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+	model.Update(pending)
+
+	if model.showThemePicker {
+		t.Error("expected esc to close the picker")
+	}
+	if len(broadcaster.themes) != 2 || broadcaster.themes[1] != "paper" {
+		t.Errorf("expected the browser to go back to paper, got %v", broadcaster.themes)
+	}
+	content, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != "# Slide 1\n" {
+		t.Errorf("esc should not change the markdown file, got:\n%s", content)
+	}
+}
+
+func TestDevModel_ThemePickerEscWithoutPreview(t *testing.T) {
+	model, broadcaster, _ := newThemePickerModel(t, "# Slide 1\n")
+
+	// Moving away and back before the preview is sent leaves the browser as it was
+	pending := moveThemePicker(t, model, "j")
+	moveThemePicker(t, model, "k")
+	model.Update(pending)
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEsc})
+
+	if len(broadcaster.themes) != 0 {
+		t.Errorf("expected no broadcasts, got %v", broadcaster.themes)
+	}
+}
+
+func TestDevModel_ThemePickerEnterSaves(t *testing.T) {
+	model, broadcaster, mdFile := newThemePickerModel(t, "---\n# Conference talk\ntitle: Demo\ntheme: paper\naspectRatio: \"4:3\"\n---\n\n# Slide 1\n")
+
+	model.Update(moveThemePicker(t, model, "j"))
+	selected := model.themeOptions[model.themePickerIndex].Name
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if model.showThemePicker || model.currentTheme != selected {
+		t.Fatalf("expected enter to select %s, got %q", selected, model.currentTheme)
+	}
+	if got := broadcaster.themes[len(broadcaster.themes)-1]; got != selected {
+		t.Errorf("expected the browser to show %s, got %v", selected, broadcaster.themes)
+	}
+
+	content, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "---\n# Conference talk\ntitle: Demo\ntheme: " + selected + "\naspectRatio: \"4:3\"\n---\n\n# Slide 1\n"
+	if string(content) != want {
+		t.Errorf("expected only the theme to change, got:\n%s", content)
+	}
+}
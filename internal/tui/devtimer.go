@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/MiniCodeMonkey/tap/internal/server"
+)
+
+// timerWarningRatio is the fraction of the talk duration after which the
+// timer is shown in the warning color.
+const timerWarningRatio = 0.8
+
+// SetTimerBroadcaster sets the timer broadcaster for WebSocket communication
+// and sends it the current timer state, so presenter views follow the TUI's
+// timer instead of running their own.
+func (m *DevModel) SetTimerBroadcaster(tb TimerBroadcaster) {
+	m.timerBroadcaster = tb
+	m.broadcastTimer()
+}
+
+// SetTalkDuration sets the target length of the talk, such as after the
+// duration option changed. It is safe to call from other goroutines.
+func (m *DevModel) SetTalkDuration(d time.Duration) {
+	m.mu.Lock()
+	changed := m.timerDuration != d
+	m.timerDuration = d
+	m.mu.Unlock()
+
+	if changed {
+		m.broadcastTimer()
+	}
+}
+
+// SetSpeakingTime sets the estimated length of the talk shown in the status
+// section, such as after the presentation was reloaded. It is safe to call
+// from other goroutines.
+func (m *DevModel) SetSpeakingTime(d time.Duration) {
+	m.mu.Lock()
+	m.speakingTime = d
+	m.mu.Unlock()
+}
+
+// toggleTimer starts the talk timer, or pauses it if it is running.
+func (m *DevModel) toggleTimer() {
+	m.mu.Lock()
+	now := m.now()
+	if m.timerRunning {
+		m.timerElapsed += now.Sub(m.timerStarted)
+	} else {
+		m.timerStarted = now
+	}
+	m.timerRunning = !m.timerRunning
+	m.mu.Unlock()
+
+	m.broadcastTimer()
+}
+
+// resetTimer stops the talk timer and sets it back to zero.
+func (m *DevModel) resetTimer() {
+	m.mu.Lock()
+	m.timerRunning = false
+	m.timerElapsed = 0
+	m.mu.Unlock()
+
+	m.broadcastTimer()
+}
+
+// timerStatus returns the elapsed time of the talk timer, the target length
+// of the talk, and whether the timer is running.
+func (m *DevModel) timerStatus() (elapsed, duration time.Duration, running bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	elapsed = m.timerElapsed
+	if m.timerRunning {
+		elapsed += m.now().Sub(m.timerStarted)
+	}
+	return elapsed, m.timerDuration, m.timerRunning
+}
+
+// timerState returns the talk timer state sent to presenter views.
+func (m *DevModel) timerState() server.TimerState {
+	elapsed, duration, running := m.timerStatus()
+	return server.TimerState{
+		Elapsed:  elapsed.Milliseconds(),
+		Duration: duration.Milliseconds(),
+		Running:  running,
+	}
+}
+
+// broadcastTimer sends the talk timer state to presenter views, if a
+// broadcaster is set.
+func (m *DevModel) broadcastTimer() {
+	if m.timerBroadcaster == nil {
+		return
+	}
+	_ = m.timerBroadcaster.BroadcastTimer(m.timerState())
+}
+
+// formatTimer formats a duration as m:ss, or h:mm:ss from an hour on.
+func formatTimer(d time.Duration) string {
+	seconds := int(d / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds%3600/60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// timerColor returns the color of the timer: the warning color from
+// timerWarningRatio of the talk duration, and the error color once it is over.
+func timerColor(elapsed, duration time.Duration) lipgloss.Color {
+	switch {
+	case duration <= 0:
+		return ColorSecondary
+	case elapsed >= duration:
+		return ColorError
+	case float64(elapsed) >= timerWarningRatio*float64(duration):
+		return ColorWarning
+	default:
+		return ColorSecondary
+	}
+}
+
+// viewTimer renders the timer line of the status section: the elapsed time,
+// the time left if the talk has a duration, and whether the timer runs.
+func (m *DevModel) viewTimer() string {
+	elapsed, duration, running := m.timerStatus()
+
+	text := formatTimer(elapsed)
+	if duration > 0 {
+		text += " / " + formatTimer(duration)
+		if elapsed < duration {
+			text += fmt.Sprintf(" (%s left)", formatTimer(duration-elapsed))
+		} else {
+			text += fmt.Sprintf(" (%s over)", formatTimer(elapsed-duration))
+		}
+	}
+
+	timerStyle := lipgloss.NewStyle().Foreground(timerColor(elapsed, duration)).Bold(true)
+	if !running && elapsed == 0 {
+		timerStyle = lipgloss.NewStyle().Foreground(ColorMuted)
+	}
+
+	state := RenderMuted(" ○ paused")
+	if running {
+		state = RenderSuccess(" ● running")
+	} else if elapsed == 0 {
+		state = RenderMuted(" (space to start)")
+	}
+
+	return timerStyle.Render(text) + state
+}
+
+// viewSpeakingTime renders the estimated length of the talk, in the warning
+// color if it exceeds the talk duration. It returns "" if there's no estimate.
+func (m *DevModel) viewSpeakingTime() string {
+	m.mu.RLock()
+	estimate, duration := m.speakingTime, m.timerDuration
+	m.mu.RUnlock()
+
+	if estimate <= 0 {
+		return ""
+	}
+
+	text := "~" + formatTimer(estimate)
+	if duration > 0 && estimate > duration {
+		return lipgloss.NewStyle().Foreground(ColorWarning).Render(text) +
+			RenderMuted(fmt.Sprintf(" (%s over the duration)", formatTimer(estimate-duration)))
+	}
+	return text
+}
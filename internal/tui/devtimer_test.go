@@ -0,0 +1,166 @@
+package tui
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/server"
+)
+
+// mockTimerBroadcaster records broadcast timer states.
+type mockTimerBroadcaster struct {
+	mu     sync.Mutex
+	states []server.TimerState
+}
+
+func (b *mockTimerBroadcaster) BroadcastTimer(state server.TimerState) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.states = append(b.states, state)
+	return nil
+}
+
+func (b *mockTimerBroadcaster) last() server.TimerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.states[len(b.states)-1]
+}
+
+// newTimerTestModel returns a dev model with a fake clock that advance moves forward.
+func newTimerTestModel(duration time.Duration) (m *DevModel, broadcaster *mockTimerBroadcaster, advance func(time.Duration)) {
+	m = NewDevModel(DevConfig{MarkdownFile: "slides.md", TalkDuration: duration})
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	m.now = func() time.Time { return now }
+	broadcaster = &mockTimerBroadcaster{}
+	m.SetTimerBroadcaster(broadcaster)
+	return m, broadcaster, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestDevModel_TimerKeys(t *testing.T) {
+	m, broadcaster, advance := newTimerTestModel(30 * time.Minute)
+
+	// The initial state is sent so presenter views know the duration
+	if got := broadcaster.last(); got.Running || got.Elapsed != 0 || got.Duration != (30*time.Minute).Milliseconds() {
+		t.Errorf("initial state = %+v", got)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if got := broadcaster.last(); !got.Running {
+		t.Errorf("space should start the timer, got %+v", got)
+	}
+
+	advance(90 * time.Second)
+	m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	if got := broadcaster.last(); got.Running || got.Elapsed != 90000 {
+		t.Errorf("space should pause the timer at 1:30, got %+v", got)
+	}
+
+	// Paused time doesn't count
+	advance(time.Minute)
+	m.Update(tea.KeyMsg{Type: tea.KeySpace})
+	advance(30 * time.Second)
+	if elapsed, _, running := m.timerStatus(); elapsed != 2*time.Minute || !running {
+		t.Errorf("timerStatus() = %v, %v, want 2m0s running", elapsed, running)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("R")})
+	if got := broadcaster.last(); got.Running || got.Elapsed != 0 {
+		t.Errorf("shift+r should reset the timer, got %+v", got)
+	}
+}
+
+func TestDevModel_SetTalkDuration(t *testing.T) {
+	m, broadcaster, _ := newTimerTestModel(0)
+	count := len(broadcaster.states)
+
+	m.SetTalkDuration(20 * time.Minute)
+	if got := broadcaster.last(); got.Duration != (20 * time.Minute).Milliseconds() {
+		t.Errorf("Duration = %d, want 20 minutes", got.Duration)
+	}
+
+	// An unchanged duration isn't broadcast again
+	m.SetTalkDuration(20 * time.Minute)
+	if len(broadcaster.states) != count+1 {
+		t.Errorf("broadcast %d states, want %d", len(broadcaster.states), count+1)
+	}
+}
+
+func TestDevModel_ViewTimer(t *testing.T) {
+	m, _, advance := newTimerTestModel(10 * time.Minute)
+
+	if view := m.viewTimer(); !strings.Contains(view, "0:00 / 10:00") || !strings.Contains(view, "space to start") {
+		t.Errorf("viewTimer() = %q, want a stopped timer", view)
+	}
+
+	m.toggleTimer()
+	advance(3*time.Minute + 5*time.Second)
+	if view := m.viewTimer(); !strings.Contains(view, "3:05 / 10:00 (6:55 left)") || !strings.Contains(view, "running") {
+		t.Errorf("viewTimer() = %q, want the elapsed and remaining time", view)
+	}
+
+	advance(8 * time.Minute)
+	if view := m.viewTimer(); !strings.Contains(view, "(1:05 over)") {
+		t.Errorf("viewTimer() = %q, want the time over", view)
+	}
+
+	if !strings.Contains(m.View(), "Timer:") {
+		t.Error("status section should show the timer")
+	}
+}
+
+func TestTimerColor(t *testing.T) {
+	tests := []struct {
+		elapsed, duration time.Duration
+		want              string
+	}{
+		{5 * time.Minute, 0, string(ColorSecondary)},
+		{5 * time.Minute, 10 * time.Minute, string(ColorSecondary)},
+		{8 * time.Minute, 10 * time.Minute, string(ColorWarning)},
+		{10 * time.Minute, 10 * time.Minute, string(ColorError)},
+		{12 * time.Minute, 10 * time.Minute, string(ColorError)},
+	}
+	for _, tt := range tests {
+		if got := string(timerColor(tt.elapsed, tt.duration)); got != tt.want {
+			t.Errorf("timerColor(%v, %v) = %s, want %s", tt.elapsed, tt.duration, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTimer(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                              "0:00",
+		59 * time.Second:               "0:59",
+		25*time.Minute + 3*time.Second: "25:03",
+		time.Hour + 2*time.Minute:      "1:02:00",
+	}
+	for d, want := range tests {
+		if got := formatTimer(d); got != want {
+			t.Errorf("formatTimer(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestDevModel_ViewSpeakingTime(t *testing.T) {
+	m, _, _ := newTimerTestModel(10 * time.Minute)
+
+	if view := m.viewSpeakingTime(); view != "" {
+		t.Errorf("viewSpeakingTime() without an estimate = %q, want empty", view)
+	}
+
+	m.SetSpeakingTime(8*time.Minute + 30*time.Second)
+	if view := m.viewSpeakingTime(); !strings.Contains(view, "~8:30") || strings.Contains(view, "over") {
+		t.Errorf("viewSpeakingTime() = %q, want ~8:30", view)
+	}
+	if view := m.viewStatus(); !strings.Contains(view, "Est. talk:") {
+		t.Errorf("expected the status to show the estimate, got:\n%s", view)
+	}
+
+	m.SetSpeakingTime(12 * time.Minute)
+	if view := m.viewSpeakingTime(); !strings.Contains(view, "~12:00") || !strings.Contains(view, "2:00 over") {
+		t.Errorf("viewSpeakingTime() = %q, want 2:00 over", view)
+	}
+}
@@ -0,0 +1,96 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// editorFinishedMsg is sent when the editor opened for a slide exits.
+type editorFinishedMsg struct {
+	slide int // zero-based slide index
+	file  string
+	err   error
+}
+
+// editorArgs returns the command line that opens file at line in the user's
+// editor, taken from $VISUAL or $EDITOR. VS Code-style editors are passed
+// --goto file:line, and terminal editors such as vim and nano +line. Other
+// editors only get the file. Without either variable, vi is used (notepad on
+// Windows).
+func editorArgs(getenv func(string) string, file string, line int) []string {
+	editor := getenv("VISUAL")
+	if editor == "" {
+		editor = getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		if runtime.GOOS == "windows" {
+			args = []string{"notepad"}
+		} else {
+			args = []string{"vi"}
+		}
+	}
+
+	name := strings.TrimSuffix(strings.ToLower(filepath.Base(args[0])), ".exe")
+	switch {
+	case line <= 0:
+		return append(args, file)
+	case name == "code" || name == "code-insiders" || name == "codium" || name == "cursor":
+		return append(args, "--goto", fmt.Sprintf("%s:%d", file, line))
+	case name == "vi" || name == "vim" || name == "nvim" || name == "nano" ||
+		name == "emacs" || name == "emacsclient" || name == "micro":
+		return append(args, "+"+strconv.Itoa(line), file)
+	default:
+		return append(args, file)
+	}
+}
+
+// slideStartLine returns the one-based line at which the slide with the given
+// zero-based index starts in a markdown file, or 0 if it can't be found.
+func slideStartLine(file string, index int, strict bool) (int, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read markdown file: %w", err)
+	}
+	lines := slideStartLines(string(content), strict)
+	if index < 0 || index >= len(lines) {
+		return 0, nil
+	}
+	return lines[index], nil
+}
+
+// editSlideCmd suspends the TUI and opens the slide in the user's editor,
+// resuming when the editor exits. The file watcher picks up any changes.
+func editSlideCmd(slide SlideInfo, strict bool) tea.Cmd {
+	if slide.File == "" {
+		return func() tea.Msg {
+			return editorFinishedMsg{
+				slide: slide.Index,
+				err:   fmt.Errorf("slide %d combines content from several files; edit it directly", slide.Index+1),
+			}
+		}
+	}
+
+	line, err := slideStartLine(slide.File, slide.FileIndex, strict)
+	if err != nil {
+		return func() tea.Msg {
+			return editorFinishedMsg{slide: slide.Index, file: slide.File, err: err}
+		}
+	}
+
+	args := editorArgs(os.Getenv, slide.File, line)
+	cmd := exec.Command(args[0], args[1:]...)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			err = fmt.Errorf("failed to run editor: %w", err)
+		}
+		return editorFinishedMsg{slide: slide.Index, file: slide.File, err: err}
+	})
+}
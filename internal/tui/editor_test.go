@@ -0,0 +1,107 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestEditorArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		line   int
+		expect []string
+	}{
+		{
+			name:   "vs code",
+			env:    map[string]string{"EDITOR": "code --wait"},
+			line:   12,
+			expect: []string{"code", "--wait", "--goto", "slides.md:12"},
+		},
+		{
+			name:   "vim",
+			env:    map[string]string{"EDITOR": "/usr/bin/vim"},
+			line:   5,
+			expect: []string{"/usr/bin/vim", "+5", "slides.md"},
+		},
+		{
+			name:   "visual takes precedence",
+			env:    map[string]string{"VISUAL": "nvim", "EDITOR": "nano"},
+			line:   3,
+			expect: []string{"nvim", "+3", "slides.md"},
+		},
+		{
+			name:   "unknown editor opens the file",
+			env:    map[string]string{"EDITOR": "subl"},
+			line:   7,
+			expect: []string{"subl", "slides.md"},
+		},
+		{
+			name:   "unknown line",
+			env:    map[string]string{"EDITOR": "vim"},
+			line:   0,
+			expect: []string{"vim", "slides.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			if got := editorArgs(getenv, "slides.md", tt.line); !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("editorArgs() = %v, want %v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestSlideStartLines_Frontmatter(t *testing.T) {
+	content := "---\ntitle: Test\n---\n\n# First\n\n---\n\n```yaml\n---\n```\n\n---\n# Third"
+	got := slideStartLines(content, false)
+	want := []int{5, 9, 14}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("slideStartLines() = %v, want %v", got, want)
+	}
+	if len(got) != len(slideParts(content, false)) {
+		t.Errorf("expected one line per slide part, got %d lines for %d parts", len(got), len(slideParts(content, false)))
+	}
+}
+
+func TestDevModel_OutlineEdit(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# First\n\n---\n\n# Second"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model := NewDevModel(DevConfig{MarkdownFile: mdFile})
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("s")})
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+
+	if slide := model.outlineSlides[1]; slide.File != mdFile || slide.FileIndex != 1 {
+		t.Errorf("expected slide 2 to be located in %s, got %q index %d", mdFile, slide.File, slide.FileIndex)
+	}
+
+	_, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if cmd == nil {
+		t.Fatal("expected a command to open the editor")
+	}
+	if model.showOutline {
+		t.Error("outline should close when opening the editor")
+	}
+
+	model.Update(editorFinishedMsg{slide: 1, file: mdFile})
+	if len(model.state.RecentEvents) == 0 || !strings.Contains(model.state.RecentEvents[len(model.state.RecentEvents)-1].Message, "Edited slide 2") {
+		t.Errorf("expected an edit event, got %v", model.state.RecentEvents)
+	}
+
+	model.Update(editorFinishedMsg{slide: 1, file: mdFile, err: errors.New("editor not found")})
+	if model.state.Error == nil {
+		t.Error("expected editor errors to be shown")
+	}
+}
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxAltTextLength is the length, in characters, that a prompt is truncated
+// to when it is used as the default alt text of an image.
+const maxAltTextLength = 100
+
+// PromptField is an input field of the prompt step.
+type PromptField int
+
+const (
+	// PromptFieldPrompt is the image prompt.
+	PromptFieldPrompt PromptField = iota
+	// PromptFieldAltText is the alt text of the image in the markdown.
+	PromptFieldAltText
+	// PromptFieldCaption is the optional caption shown under the image.
+	PromptFieldCaption
+)
+
+// promptFieldCount is the number of input fields in the prompt step.
+const promptFieldCount = 3
+
+// aiImageMarkdown is the markdown written for an AI-generated image: the
+// ai-prompt comment, the image, an optional img comment, and an optional
+// caption in italics.
+type aiImageMarkdown struct {
+	// Prompt is the prompt text of the ai-prompt comment, including image options.
+	Prompt string
+	// Path is the path of the image.
+	Path string
+	// AltText is the alt text of the image; may be empty.
+	AltText string
+	// Caption is written as an italic line under the image if not empty.
+	Caption string
+	// Hint is written as an img comment on the line after the image if not
+	// empty, such as "fit: cover".
+	Hint string
+}
+
+// String returns the markdown for the image.
+func (i aiImageMarkdown) String() string {
+	markdown := fmt.Sprintf("<!-- ai-prompt: %s -->\n![%s](%s)", i.Prompt, cleanAltText(i.AltText), i.Path)
+	if hint := strings.Join(strings.Fields(i.Hint), " "); hint != "" {
+		markdown += "\n<!-- img: {" + hint + "} -->"
+	}
+	if caption := cleanCaption(i.Caption); caption != "" {
+		markdown += "\n*" + caption + "*"
+	}
+	return markdown
+}
+
+// cleanAltText returns alt text that can be written between the brackets of a
+// markdown image: on one line, without brackets.
+func cleanAltText(alt string) string {
+	alt = strings.NewReplacer("[", "", "]", "").Replace(alt)
+	return strings.Join(strings.Fields(alt), " ")
+}
+
+// cleanCaption returns a caption that can be written as an italic line:
+// on one line, without asterisks.
+func cleanCaption(caption string) string {
+	caption = strings.ReplaceAll(caption, "*", "")
+	return strings.Join(strings.Fields(caption), " ")
+}
+
+// defaultAltText returns the alt text used for an image when none is given:
+// the prompt, truncated at a word boundary to maxAltTextLength characters.
+func defaultAltText(prompt string) string {
+	alt := cleanAltText(prompt)
+	runes := []rune(alt)
+	if len(runes) <= maxAltTextLength {
+		return alt
+	}
+
+	truncated := string(runes[:maxAltTextLength-3])
+	if i := strings.LastIndex(truncated, " "); i > 0 {
+		truncated = truncated[:i]
+	}
+	return strings.TrimRight(truncated, " ,.;:") + "..."
+}
+
+// newPromptFieldInput returns a single-line input for the alt text or caption.
+func newPromptFieldInput(placeholder string) textinput.Model {
+	ti := textinput.New()
+	ti.Placeholder = placeholder
+	ti.CharLimit = 300
+	ti.Width = 58
+	return ti
+}
+
+// setPromptFields fills the prompt step's inputs and focuses the prompt.
+func (m *ImageGenModel) setPromptFields(prompt, altText, caption string) {
+	m.promptInput.SetValue(prompt)
+	m.altInput.SetValue(altText)
+	m.captionInput.SetValue(caption)
+	m.historyPos = 0
+	m.focusPromptField(PromptFieldPrompt)
+}
+
+// focusPromptField moves the focus in the prompt step to field.
+// It returns the command that makes the cursor of the field blink.
+func (m *ImageGenModel) focusPromptField(field PromptField) tea.Cmd {
+	m.blurPromptFields()
+	m.PromptField = field
+	switch field {
+	case PromptFieldAltText:
+		m.altInput.Focus()
+		return textinput.Blink
+	case PromptFieldCaption:
+		m.captionInput.Focus()
+		return textinput.Blink
+	default:
+		m.promptInput.Focus()
+		return textarea.Blink
+	}
+}
+
+// blurPromptFields removes the focus from all inputs of the prompt step.
+func (m *ImageGenModel) blurPromptFields() {
+	m.promptInput.Blur()
+	m.altInput.Blur()
+	m.captionInput.Blur()
+}
+
+// updatePromptField passes a message to the focused input of the prompt step.
+func (m *ImageGenModel) updatePromptField(msg tea.Msg) tea.Cmd {
+	var cmd tea.Cmd
+	switch m.PromptField {
+	case PromptFieldAltText:
+		m.altInput, cmd = m.altInput.Update(msg)
+	case PromptFieldCaption:
+		m.captionInput, cmd = m.captionInput.Update(msg)
+	default:
+		m.promptInput, cmd = m.promptInput.Update(msg)
+	}
+	return cmd
+}
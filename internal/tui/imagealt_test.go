@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestDefaultAltText(t *testing.T) {
+	if got := defaultAltText("A cat on  a\nwindowsill [sunny]"); got != "A cat on a windowsill sunny" {
+		t.Errorf("defaultAltText() = %q, want the prompt on one line without brackets", got)
+	}
+
+	long := strings.Repeat("word ", 40)
+	got := defaultAltText(long)
+	if len([]rune(got)) > maxAltTextLength {
+		t.Errorf("defaultAltText() is %d characters, want at most %d", len([]rune(got)), maxAltTextLength)
+	}
+	if !strings.HasSuffix(got, "word...") {
+		t.Errorf("defaultAltText() = %q, want it truncated at a word boundary", got)
+	}
+}
+
+func TestAIImageMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		image    aiImageMarkdown
+		expected string
+	}{
+		{
+			name:     "no alt text",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/cat.png"},
+			expected: "<!-- ai-prompt: a cat -->\n![](images/cat.png)",
+		},
+		{
+			name:     "alt text",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/cat.png", AltText: "A [gray] cat"},
+			expected: "<!-- ai-prompt: a cat -->\n![A gray cat](images/cat.png)",
+		},
+		{
+			name:     "caption",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/cat.png", AltText: "A cat", Caption: "Our *office* cat"},
+			expected: "<!-- ai-prompt: a cat -->\n![A cat](images/cat.png)\n*Our office cat*",
+		},
+		{
+			name:     "blank caption",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/cat.png", Caption: "  "},
+			expected: "<!-- ai-prompt: a cat -->\n![](images/cat.png)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.image.String(); got != tt.expected {
+				t.Errorf("String() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseAIImages_AltTextAndCaption(t *testing.T) {
+	content := `# Slide
+
+<!-- ai-prompt: a cat | ratio: 1:1 -->
+![A gray cat](images/cat.png)
+*Our office cat*
+
+<!-- ai-prompt: a dog -->
+![](images/dog.png)
+Not a caption
+
+<!-- ai-prompt: a bird -->
+![A bird](images/bird.png)`
+
+	expected := []AIImageInfo{
+		{Prompt: "a cat", ImagePath: "images/cat.png", AltText: "A gray cat", Caption: "Our office cat", AspectRatio: "1:1"},
+		{Prompt: "a dog", ImagePath: "images/dog.png"},
+		{Prompt: "a bird", ImagePath: "images/bird.png", AltText: "A bird"},
+	}
+	if got := parseAIImages(content); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseAIImages() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestReplaceImageInContent_AltTextAndCaption(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		image    aiImageMarkdown
+		expected string
+	}{
+		{
+			name:     "empty alt gets alt text",
+			content:  "# Slide\n\n<!-- ai-prompt: a cat -->\n![](images/old.png)\n",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/new.png", AltText: "a cat"},
+			expected: "# Slide\n\n<!-- ai-prompt: a cat -->\n![a cat](images/new.png)\n",
+		},
+		{
+			name:     "caption is replaced",
+			content:  "<!-- ai-prompt: a cat -->\n![A cat](images/old.png)\n*Old caption*\n\nText",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/new.png", AltText: "A cat", Caption: "New caption"},
+			expected: "<!-- ai-prompt: a cat -->\n![A cat](images/new.png)\n*New caption*\n\nText",
+		},
+		{
+			name:     "caption is removed",
+			content:  "<!-- ai-prompt: a cat -->\n![A cat](images/old.png)\n*Old caption*",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/new.png", AltText: "A cat"},
+			expected: "<!-- ai-prompt: a cat -->\n![A cat](images/new.png)",
+		},
+		{
+			name:     "dollar signs are kept",
+			content:  "<!-- ai-prompt: a cat -->\n![](images/old.png)",
+			image:    aiImageMarkdown{Prompt: "a $5 cat", Path: "images/new.png", AltText: "a $1 cat"},
+			expected: "<!-- ai-prompt: a $5 cat -->\n![a $1 cat](images/new.png)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := replaceImageInContent(tt.content, "a cat", "images/old.png", tt.image)
+			if err != nil {
+				t.Fatalf("replaceImageInContent() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("replaceImageInContent() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImageGenModel_PromptFields(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide\n\nText\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	// Slide without AI images goes straight to the prompt
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(*ImageGenModel)
+	if m.Step != ImageGenStepPrompt || m.PromptField != PromptFieldPrompt {
+		t.Fatalf("expected the prompt field to be focused, got step %d field %d", m.Step, m.PromptField)
+	}
+
+	m.promptInput.SetValue("A lighthouse at dusk")
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m.PromptField != PromptFieldAltText || !m.altInput.Focused() || m.promptInput.Focused() {
+		t.Fatalf("tab should focus the alt text field, got field %d", m.PromptField)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m.PromptField != PromptFieldCaption || !m.captionInput.Focused() {
+		t.Fatalf("tab should focus the caption field, got field %d", m.PromptField)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Built in 1871")})
+	if m.captionInput.Value() != "Built in 1871" {
+		t.Errorf("typing should go to the caption field, got %q", m.captionInput.Value())
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	if m.PromptField != PromptFieldPrompt {
+		t.Errorf("tab should wrap around to the prompt field, got field %d", m.PromptField)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.PromptField != PromptFieldCaption {
+		t.Errorf("shift+tab should go back to the caption field, got field %d", m.PromptField)
+	}
+
+	// The alt text was left empty, so it defaults to the prompt
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.AltText != "A lighthouse at dusk" || m.Caption != "Built in 1871" {
+		t.Errorf("AltText = %q, Caption = %q after submitting", m.AltText, m.Caption)
+	}
+
+	if err := m.InsertImageIntoMarkdown("images/lighthouse.png"); err != nil {
+		t.Fatalf("InsertImageIntoMarkdown failed: %v", err)
+	}
+	updated, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	want := "<!-- ai-prompt: A lighthouse at dusk -->\n![A lighthouse at dusk](images/lighthouse.png)\n*Built in 1871*\n"
+	if !strings.HasSuffix(string(updated), want) {
+		t.Errorf("updated content = %q, want it to end with %q", updated, want)
+	}
+}
+
+func TestImageGenModel_RegeneratePreservesAltTextAndCaption(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	content := "# Slide\n\n<!-- ai-prompt: a cat -->\n![A gray cat](images/cat.png)\n*Our office cat*\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	// Select the slide, then the regenerate option
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(*ImageGenModel)
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.altInput.Value() != "A gray cat" || m.captionInput.Value() != "Our office cat" {
+		t.Fatalf("alt text %q and caption %q should be pre-filled", m.altInput.Value(), m.captionInput.Value())
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if err := m.ReplaceImageInMarkdown("images/cat2.png"); err != nil {
+		t.Fatalf("ReplaceImageInMarkdown failed: %v", err)
+	}
+	updated, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	want := "# Slide\n\n<!-- ai-prompt: a cat -->\n![A gray cat](images/cat2.png)\n*Our office cat*\n"
+	if string(updated) != want {
+		t.Errorf("updated content = %q, want %q", updated, want)
+	}
+}
@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/imagecache"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newImageCache returns the cache of generated images for the markdown file's
+// images folder, or nil if the imageGen section of tap.yaml doesn't enable it.
+func (m *ImageGenModel) newImageCache() *imagecache.Cache {
+	if !m.Provider.Cache {
+		return nil
+	}
+	return imagecache.New(imagecache.Dir(m.GetImagesDir()), int64(m.Provider.CacheMaxMB)<<20)
+}
+
+// lookupCache returns the cached image generated from the current prompt and
+// options, or nil if there is none.
+func (m *ImageGenModel) lookupCache() *imagecache.Entry {
+	if m.Cache == nil {
+		return nil
+	}
+	key := imagecache.Key(m.Provider.Provider, m.Provider.Model, m.Prompt, m.generationOptions())
+	entry, ok := m.Cache.Get(key)
+	if !ok {
+		return nil
+	}
+	return entry
+}
+
+// storeInCache stores a generated image under the key of the request that
+// generated it. The cache only saves money, so failing to write it doesn't
+// fail the generation.
+func (m *ImageGenModel) storeInCache(result ImageGenerateResult) {
+	if m.Cache == nil || m.cacheKey == "" {
+		return
+	}
+	_ = m.Cache.Put(m.cacheKey, result.ImageData, result.ContentType)
+}
+
+// cachedResult returns a cached image as a generation result.
+func cachedResult(entry *imagecache.Entry) ImageGenerateResult {
+	return ImageGenerateResult{ImageData: entry.Data, ContentType: entry.ContentType}
+}
+
+// handleCachedKey handles keyboard input when a cached image was found for
+// the prompt: c uses it, enter generates a new image anyway, and esc returns
+// to the prompt.
+func (m *ImageGenModel) handleCachedKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "c":
+		entry := m.CachedImage
+		m.CachedImage = nil
+		m.cacheKey = ""
+		m.FromCache = true
+		return m.handleImageGenerateResult(cachedResult(entry))
+
+	case "enter":
+		m.CachedImage = nil
+		return m.generateFresh()
+
+	case "esc":
+		m.CachedImage = nil
+		m.Step = ImageGenStepPrompt
+		return m, m.focusPromptField(m.PromptField)
+	}
+	return m, nil
+}
+
+// viewCached renders the choice between the cached image and a new one.
+func (m *ImageGenModel) viewCached() string {
+	var b strings.Builder
+
+	// Title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
+
+	b.WriteString(titleStyle.Render("🖼  Image Already Generated"))
+	b.WriteString("\n\n")
+
+	mutedStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+	promptStyle := lipgloss.NewStyle().
+		Foreground(ColorWhite)
+
+	b.WriteString(mutedStyle.Render("An image was generated from this prompt and these options before:"))
+	b.WriteString("\n\n")
+	b.WriteString(promptStyle.Render(m.Prompt))
+	b.WriteString("\n\n")
+
+	// Help text
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	created := "an earlier generation"
+	if m.CachedImage != nil && !m.CachedImage.Created.IsZero() {
+		created = m.CachedImage.Created.Local().Format("Jan 2, 2006 15:04")
+	}
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("Use cached result from %s (%s)", created, keyStyle.Render("c"))))
+	b.WriteString("\n")
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("Generate fresh (%s)", keyStyle.Render("enter"))))
+	b.WriteString("\n\n")
+	b.WriteString(mutedStyle.Render(fmt.Sprintf("%s back", keyStyle.Render("esc"))))
+
+	return b.String()
+}
@@ -0,0 +1,150 @@
+package tui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/imagecache"
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// enableImageCache gives the model an empty image cache in its images folder.
+func enableImageCache(model *ImageGenModel) {
+	model.Provider.Cache = true
+	model.Cache = model.newImageCache()
+}
+
+func TestImageGenModel_CacheDisabledByDefault(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	if model.Cache != nil {
+		t.Error("expected no image cache without imageGen.cache")
+	}
+}
+
+func TestImageGenModel_OffersCachedImage(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	enableImageCache(model)
+	model.Prompt = "A lighthouse"
+
+	// The first generation calls the provider and caches the image
+	model.startGeneration()
+	if model.Step != ImageGenStepGenerating {
+		t.Fatalf("expected generating step without a cached image, got %d", model.Step)
+	}
+	image := testPNG("lighthouse")
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: image, ContentType: "image/png"}})
+	if model.Step != ImageGenStepReview || model.FromCache {
+		t.Fatalf("expected review of a generated image, got step %d, from cache %v", model.Step, model.FromCache)
+	}
+
+	// Generating the same prompt again offers the cached image
+	model.discardGeneratedImage()
+	model.startGeneration()
+	if model.Step != ImageGenStepCached || model.CachedImage == nil {
+		t.Fatalf("expected the cached step, got %d", model.Step)
+	}
+	view := model.View()
+	if !strings.Contains(view, "Use cached result from") || !strings.Contains(view, "Generate fresh") {
+		t.Errorf("expected the cache choice in the view, got:\n%s", view)
+	}
+
+	// c uses it without calling the provider
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("c")})
+	if cmd != nil {
+		t.Error("expected no generation when using the cached image")
+	}
+	if model.Step != ImageGenStepReview || !model.FromCache {
+		t.Fatalf("expected review of the cached image, got step %d, from cache %v", model.Step, model.FromCache)
+	}
+	if !bytes.Equal(model.GeneratedImage.ImageData, image) {
+		t.Error("expected the cached image data")
+	}
+	if !strings.Contains(model.View(), "From the image cache") {
+		t.Errorf("expected the review to say the image is cached, got:\n%s", model.View())
+	}
+
+	// r generates a new one anyway
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if model.Step != ImageGenStepGenerating || model.FromCache {
+		t.Errorf("expected a new generation, got step %d, from cache %v", model.Step, model.FromCache)
+	}
+}
+
+func TestImageGenModel_CachedStepKeys(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	enableImageCache(model)
+	model.Prompt = "A lighthouse"
+	key := imagecache.Key(model.Provider.Provider, model.Provider.Model, model.Prompt, model.generationOptions())
+	if err := model.Cache.Put(key, testPNG("cached"), "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	// enter generates a fresh image
+	model.startGeneration()
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.Step != ImageGenStepGenerating || cmd == nil {
+		t.Errorf("expected enter to generate a fresh image, got step %d", model.Step)
+	}
+	model.cancelGeneration()
+
+	// esc goes back to the prompt
+	model.IsGenerating = false
+	model.startGeneration()
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.Step != ImageGenStepPrompt || model.CachedImage != nil {
+		t.Errorf("expected esc to return to the prompt, got step %d", model.Step)
+	}
+
+	// Choosing from candidates always generates new images
+	model.CandidateCount = 2
+	model.startGeneration()
+	if model.Step != ImageGenStepGenerating {
+		t.Errorf("expected candidates to skip the cache, got step %d", model.Step)
+	}
+	model.cancelGeneration()
+}
+
+func TestImageGenModel_BatchUsesCache(t *testing.T) {
+	model := newBatchTestModel(t)
+	enableImageCache(model)
+
+	// The first pending image, "a dog" at 1:1, was generated before
+	cached := testPNG("cached dog")
+	key := imagecache.Key(model.Provider.Provider, model.Provider.Model, "a dog", imagegen.Options{AspectRatio: "1:1"})
+	if err := model.Cache.Put(key, cached, "image/png"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if cmd == nil {
+		t.Fatal("expected the batch to start")
+	}
+	msg, ok := cmd().(imageGenerateMsg)
+	if !ok || !bytes.Equal(msg.result.ImageData, cached) {
+		t.Fatal("expected the cached image without calling the provider")
+	}
+	model.Update(msg)
+	if item := model.BatchItems[0]; item.Status != BatchItemDone || !item.Cached {
+		t.Errorf("expected the first image done from the cache, got %+v", item)
+	}
+
+	// The second image is generated, and then cached
+	if model.Prompt != "a bird" {
+		t.Fatalf("expected the second image to be generated, got prompt %q", model.Prompt)
+	}
+	bird := testPNG("bird")
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: bird, ContentType: "image/png"}})
+	if item := model.BatchItems[1]; item.Status != BatchItemDone || item.Cached {
+		t.Errorf("expected the second image generated, got %+v", item)
+	}
+	birdKey := imagecache.Key(model.Provider.Provider, model.Provider.Model, "a bird", imagegen.Options{AspectRatio: model.defaultRatio})
+	if entry, ok := model.Cache.Get(birdKey); !ok || !bytes.Equal(entry.Data, bird) {
+		t.Error("expected the generated image in the cache")
+	}
+
+	if !strings.Contains(model.View(), "(from cache)") {
+		t.Errorf("expected the batch view to show the cached image, got:\n%s", model.View())
+	}
+}
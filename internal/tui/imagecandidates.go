@@ -0,0 +1,337 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+)
+
+// maxCandidates is the most images that can be generated from a prompt to
+// choose from.
+const maxCandidates = 4
+
+// candidateConcurrency is how many candidates are generated at the same
+// time, so several candidates don't run into the provider's rate limit.
+const candidateConcurrency = 2
+
+// ImageCandidate is one of several images generated from the same prompt.
+type ImageCandidate struct {
+	// Number is the candidate's number among those requested, from 1.
+	Number int
+	// Result is the generated image.
+	Result ImageGenerateResult
+	// Elapsed is how long the candidate took to generate.
+	Elapsed time.Duration
+	// Width and Height are the image's size in pixels, or 0 if the format
+	// can't be decoded.
+	Width, Height int
+}
+
+// Label describes the candidate in the candidate selection step, such as
+// "Image 2 • 1024×576 • 1.2 MB • 14.2s".
+func (c ImageCandidate) Label() string {
+	parts := []string{fmt.Sprintf("Image %d", c.Number)}
+	if c.Width > 0 && c.Height > 0 {
+		parts = append(parts, fmt.Sprintf("%d×%d", c.Width, c.Height))
+	}
+	parts = append(parts, formatImageSize(len(c.Result.ImageData)), fmt.Sprintf("%.1fs", c.Elapsed.Seconds()))
+	return strings.Join(parts, " • ")
+}
+
+// formatImageSize formats the size of an image in bytes, such as "1.2 MB".
+func formatImageSize(size int) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%d B", size)
+	}
+}
+
+// candidateMsg is sent when a candidate image finishes generating.
+type candidateMsg struct {
+	run     int // candidateRun of the generation the candidate belongs to
+	number  int
+	result  ImageGenerateResult
+	elapsed time.Duration
+}
+
+// generateCandidatesCmd returns a command that generates CandidateCount
+// images from the prompt, at most candidateConcurrency at a time. Each
+// candidate sends a candidateMsg when it is done. Like generateImageCmd,
+// the requests can be canceled with cancelGeneration.
+func (m *ImageGenModel) generateCandidatesCmd() tea.Cmd {
+	prompt := m.Prompt
+	provider := m.Provider
+	opts := m.generationOptions()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelGenerate = cancel
+	m.candidateRun++
+	run := m.candidateRun
+	m.candidatesPending = m.CandidateCount
+	m.Candidates, m.CandidateFailures, m.candidateErr = nil, 0, nil
+
+	slots := make(chan struct{}, candidateConcurrency)
+	cmds := make([]tea.Cmd, m.CandidateCount)
+	for i := range cmds {
+		number := i + 1
+		cmds[i] = func() tea.Msg {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return nil
+			}
+			defer func() { <-slots }()
+
+			start := time.Now()
+			generator, err := imagegen.New(provider)
+			if err != nil {
+				return candidateMsg{run: run, number: number, result: ImageGenerateResult{Error: err}}
+			}
+			result, err := generator.GenerateImage(ctx, prompt, opts)
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err != nil {
+				return candidateMsg{run: run, number: number, result: ImageGenerateResult{Error: err}, elapsed: time.Since(start)}
+			}
+			return candidateMsg{
+				run:     run,
+				number:  number,
+				result:  ImageGenerateResult{ImageData: result.Data, ContentType: result.ContentType},
+				elapsed: time.Since(start),
+			}
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+// handleCandidateResult handles a candidate that finished generating. Failed
+// and invalid candidates are counted and logged; once all candidates are in,
+// the candidate selection step shows those that succeeded, or the first
+// error if none did.
+func (m *ImageGenModel) handleCandidateResult(msg candidateMsg) (tea.Model, tea.Cmd) {
+	m.candidatesPending--
+
+	var logCmd tea.Cmd
+	err := msg.result.Error
+	if err == nil {
+		msg.result.ContentType, err = ValidateImageData(msg.result.ImageData, m.MaxImageSize)
+	}
+	if err != nil {
+		m.CandidateFailures++
+		if m.candidateErr == nil {
+			m.candidateErr = err
+		}
+		logCmd = m.logFailureCmd(err)
+	} else {
+		m.addCandidate(ImageCandidate{Number: msg.number, Result: msg.result, Elapsed: msg.elapsed})
+	}
+
+	if m.candidatesPending > 0 {
+		return m, logCmd
+	}
+
+	m.cancelGeneration()
+	m.IsGenerating = false
+	if len(m.Candidates) == 0 {
+		m.Error = formatAPIError(m.candidateErr)
+		if m.CandidateFailures > 1 {
+			m.Error = fmt.Sprintf("All %d images failed. %s", m.CandidateFailures, m.Error)
+		}
+		m.generationFailed(m.Error)
+		return m, logCmd
+	}
+	m.generationFinished()
+
+	m.recordPrompt()
+	m.highlightCandidate(0)
+	m.Step = ImageGenStepCandidates
+	return m, logCmd
+}
+
+// addCandidate adds a successful candidate to Candidates, keeping them in
+// the order they were requested.
+func (m *ImageGenModel) addCandidate(candidate ImageCandidate) {
+	if cfg, _, err := image.DecodeConfig(bytes.NewReader(candidate.Result.ImageData)); err == nil {
+		candidate.Width, candidate.Height = cfg.Width, cfg.Height
+	}
+
+	i := len(m.Candidates)
+	for i > 0 && m.Candidates[i-1].Number > candidate.Number {
+		i--
+	}
+	m.Candidates = append(m.Candidates, ImageCandidate{})
+	copy(m.Candidates[i+1:], m.Candidates[i:])
+	m.Candidates[i] = candidate
+}
+
+// highlightCandidate highlights the candidate at index, making it the
+// generated image that is previewed and accepted.
+func (m *ImageGenModel) highlightCandidate(index int) {
+	if index < 0 || index >= len(m.Candidates) {
+		return
+	}
+	m.CandidateIndex = index
+	result := m.Candidates[index].Result
+	m.GeneratedImage = &result
+	m.renderPreview()
+}
+
+// discardCandidates drops the candidates; the generated image, if one was
+// chosen, is kept.
+func (m *ImageGenModel) discardCandidates() {
+	m.Candidates = nil
+	m.CandidateIndex = 0
+	m.CandidateFailures = 0
+	m.candidateErr = nil
+}
+
+// handleCandidatesKey handles keyboard input in the candidate selection step.
+// The arrows or number keys highlight a candidate, enter accepts it, r
+// generates new candidates with the same prompt, and e (or esc) discards
+// them and returns to the prompt for editing.
+func (m *ImageGenModel) handleCandidatesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "up", "k", "left", "h":
+		m.highlightCandidate(m.CandidateIndex - 1)
+		return m, nil
+
+	case "down", "j", "right", "l":
+		m.highlightCandidate(m.CandidateIndex + 1)
+		return m, nil
+
+	case "1", "2", "3", "4":
+		for i, candidate := range m.Candidates {
+			if strconv.Itoa(candidate.Number) == key {
+				m.highlightCandidate(i)
+			}
+		}
+		return m, nil
+
+	case "enter":
+		m.ChosenCandidate = m.Candidates[m.CandidateIndex].Number
+		m.confirmAccept()
+		return m, nil
+
+	case "r":
+		m.discardGeneratedImage()
+		m.Step = ImageGenStepGenerating
+		m.IsGenerating = true
+		return m, tea.Batch(m.spinner.Tick, m.generateCmd())
+
+	case "e", "esc":
+		m.discardGeneratedImage()
+		m.Step = ImageGenStepPrompt
+		return m, m.focusPromptField(m.PromptField)
+	}
+	return m, nil
+}
+
+// candidateProgress describes the progress of generating candidates, such as
+// "Generating 4 images... 1 ready, 1 failed".
+func (m *ImageGenModel) candidateProgress() string {
+	progress := fmt.Sprintf("Generating %d images...", m.CandidateCount)
+	var done []string
+	if ready := len(m.Candidates); ready > 0 {
+		done = append(done, fmt.Sprintf("%d ready", ready))
+	}
+	if m.CandidateFailures > 0 {
+		done = append(done, fmt.Sprintf("%d failed", m.CandidateFailures))
+	}
+	if len(done) > 0 {
+		progress += " " + strings.Join(done, ", ")
+	}
+	return progress
+}
+
+// viewCandidates renders the candidate selection view: the candidates with
+// their size and generation time, and a preview of the highlighted one.
+func (m *ImageGenModel) viewCandidates() string {
+	var b strings.Builder
+
+	// Title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
+
+	b.WriteString(titleStyle.Render("🖼  Choose an Image"))
+	b.WriteString("\n\n")
+
+	// Show selected slide info
+	slide := m.GetSelectedSlide()
+	if slide != nil {
+		slideInfoStyle := lipgloss.NewStyle().
+			Foreground(ColorMuted).
+			Italic(true)
+		b.WriteString(slideInfoStyle.Render(fmt.Sprintf("Slide %d: %s", slide.Index+1, slide.Title)))
+		b.WriteString("\n\n")
+	}
+
+	// Candidate list
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+	normalStyle := lipgloss.NewStyle().
+		Foreground(ColorWhite)
+	for i, candidate := range m.Candidates {
+		if i == m.CandidateIndex {
+			b.WriteString(selectedStyle.Render("▸ " + candidate.Label()))
+		} else {
+			b.WriteString(normalStyle.Render("  " + candidate.Label()))
+		}
+		b.WriteString("\n")
+	}
+	if m.CandidateFailures > 0 {
+		noun := "images"
+		if m.CandidateFailures == 1 {
+			noun = "image"
+		}
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorWarning).Render(
+			fmt.Sprintf("%d %s failed: %s", m.CandidateFailures, noun, formatAPIError(m.candidateErr))))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Show the preview, or why there is none
+	if m.preview != "" {
+		b.WriteString(m.preview)
+	} else {
+		mutedStyle := lipgloss.NewStyle().
+			Foreground(ColorMuted)
+		b.WriteString(mutedStyle.Render("Preview not available: " + m.previewErr))
+	}
+	b.WriteString("\n\n")
+
+	// Help text
+	helpStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	help := fmt.Sprintf(
+		"%s choose • %s accept • %s regenerate all • %s edit prompt",
+		keyStyle.Render("↑/↓"),
+		keyStyle.Render("enter"),
+		keyStyle.Render("r"),
+		keyStyle.Render("e"),
+	)
+	b.WriteString(helpStyle.Render(help))
+
+	return b.String()
+}
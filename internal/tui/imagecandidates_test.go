@@ -0,0 +1,240 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+)
+
+// encodeTestPNG returns a PNG image of the given size.
+func encodeTestPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// newCandidateServer returns an OpenAI-compatible image server that answers
+// each request with a PNG one pixel wider than the last, or with a content
+// policy error for the request numbers in fail. It records the most
+// requests it handled at the same time.
+func newCandidateServer(t *testing.T, fail map[int32]bool) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests, active, maxActive atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		current := active.Add(1)
+		defer active.Add(-1)
+		for {
+			seen := maxActive.Load()
+			if current <= seen || maxActive.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		if fail[n] {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"Rejected by the safety system","code":"content_policy_violation"}}`)
+			return
+		}
+		data := encodeTestPNG(t, 10+int(n), 5)
+		fmt.Fprintf(w, `{"data":[{"b64_json":%q}]}`, base64.StdEncoding.EncodeToString(data))
+	}))
+	t.Cleanup(server.Close)
+	return server, &maxActive
+}
+
+// runCandidates runs the commands of a candidate generation at the same
+// time, as Bubble Tea does, and passes their messages to the model.
+func runCandidates(t *testing.T, model *ImageGenModel, cmd tea.Cmd) {
+	t.Helper()
+	batch, ok := cmd().(tea.BatchMsg)
+	if !ok {
+		t.Fatal("expected a batch of candidate commands")
+	}
+
+	msgs := make([]tea.Msg, len(batch))
+	var wg sync.WaitGroup
+	for i, c := range batch {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			msgs[i] = c()
+		}()
+	}
+	wg.Wait()
+
+	for _, msg := range msgs {
+		if _, ok := msg.(candidateMsg); !ok {
+			t.Fatalf("expected a candidateMsg, got %T", msg)
+		}
+		model.Update(msg)
+	}
+}
+
+// newCandidateModel returns an image generator for a new image on a slide,
+// generating count candidates with the server.
+func newCandidateModel(t *testing.T, server *httptest.Server, count int) *ImageGenModel {
+	t.Helper()
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	model.Provider = config.ImageGenConfig{Provider: imagegen.ProviderOpenAICompatible, BaseURL: server.URL + "/v1", Model: "sdxl"}
+	model.Prompt = "a lighthouse"
+	model.CandidateCount = count
+	model.Step = ImageGenStepGenerating
+	model.IsGenerating = true
+	return model
+}
+
+func TestImageGenModel_CandidateCountCycles(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	model.Step = ImageGenStepPrompt
+	if model.CandidateCount != 1 {
+		t.Fatalf("expected a single image by default, got %d", model.CandidateCount)
+	}
+
+	var got []int
+	for range 4 {
+		model.handleKeyPress(tea.KeyMsg{Type: tea.KeyCtrlX})
+		got = append(got, model.CandidateCount)
+	}
+	if fmt.Sprint(got) != "[2 3 4 1]" {
+		t.Errorf("expected ctrl+x to cycle through 1 to 4, got %v", got)
+	}
+}
+
+func TestImageGenModel_GenerateCandidates(t *testing.T) {
+	server, maxActive := newCandidateServer(t, map[int32]bool{2: true})
+	model := newCandidateModel(t, server, 4)
+
+	runCandidates(t, model, model.generateCmd())
+
+	if got := maxActive.Load(); got > candidateConcurrency {
+		t.Errorf("expected at most %d requests at a time, got %d", candidateConcurrency, got)
+	}
+	if model.Step != ImageGenStepCandidates || model.IsGenerating {
+		t.Fatalf("expected the candidate selection step, got step %d (generating %v)", model.Step, model.IsGenerating)
+	}
+	if len(model.Candidates) != 3 || model.CandidateFailures != 1 {
+		t.Fatalf("expected 3 candidates and 1 failure, got %d and %d", len(model.Candidates), model.CandidateFailures)
+	}
+	for i, candidate := range model.Candidates {
+		if i > 0 && candidate.Number <= model.Candidates[i-1].Number {
+			t.Errorf("expected the candidates in the order they were requested, got %d after %d", candidate.Number, model.Candidates[i-1].Number)
+		}
+		if candidate.Width < 11 || candidate.Height != 5 {
+			t.Errorf("expected the candidate's size to be decoded, got %dx%d", candidate.Width, candidate.Height)
+		}
+	}
+
+	view := model.View()
+	for _, want := range []string{"Choose an Image", model.Candidates[0].Label(), "1 image failed", "content policy"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the view, got:\n%s", want, view)
+		}
+	}
+	if !bytes.Equal(model.GeneratedImage.ImageData, model.Candidates[0].Result.ImageData) {
+		t.Error("expected the first candidate to be highlighted")
+	}
+}
+
+func TestImageGenModel_ChooseCandidate(t *testing.T) {
+	server, _ := newCandidateServer(t, nil)
+	model := newCandidateModel(t, server, 3)
+	runCandidates(t, model, model.generateCmd())
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyDown})
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3")})
+	chosen := model.Candidates[2]
+	if model.CandidateIndex != 2 || !bytes.Equal(model.GeneratedImage.ImageData, chosen.Result.ImageData) {
+		t.Fatalf("expected image 3 to be highlighted, got index %d", model.CandidateIndex)
+	}
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.Step != ImageGenStepDone {
+		t.Fatalf("expected the done step, got %d", model.Step)
+	}
+	if model.ChosenCandidate != 3 || len(model.Candidates) != 0 {
+		t.Errorf("expected image 3 chosen and the others discarded, got %d with %d candidates", model.ChosenCandidate, len(model.Candidates))
+	}
+	if !bytes.Equal(model.GeneratedImage.ImageData, chosen.Result.ImageData) {
+		t.Error("expected the chosen image to be the one saved")
+	}
+	if view := model.View(); !strings.Contains(view, "Chose image 3 of 3") {
+		t.Errorf("expected the done view to name the chosen image, got:\n%s", view)
+	}
+}
+
+func TestImageGenModel_AllCandidatesFail(t *testing.T) {
+	server, _ := newCandidateServer(t, map[int32]bool{1: true, 2: true})
+	model := newCandidateModel(t, server, 2)
+
+	runCandidates(t, model, model.generateCmd())
+
+	if model.Step != ImageGenStepGenerating || model.IsGenerating {
+		t.Fatalf("expected the error in the generating step, got step %d (generating %v)", model.Step, model.IsGenerating)
+	}
+	if !strings.Contains(model.Error, "All 2 images failed") || !strings.Contains(model.Error, "content policy") {
+		t.Errorf("expected the failures in the error, got %q", model.Error)
+	}
+}
+
+func TestImageGenModel_StaleCandidatesIgnored(t *testing.T) {
+	server, _ := newCandidateServer(t, nil)
+	model := newCandidateModel(t, server, 2)
+
+	stale := model.generateCmd()
+	model.cancelGeneration()
+	model.IsGenerating = true
+	runCandidates(t, model, model.generateCmd())
+	if len(model.Candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %d", len(model.Candidates))
+	}
+
+	// Candidates of the earlier generation are canceled, and ignored if they arrive anyway
+	for _, c := range stale().(tea.BatchMsg) {
+		if msg := c(); msg != nil {
+			t.Errorf("expected a canceled candidate to send no message, got %v", msg)
+		}
+	}
+	model.Update(candidateMsg{run: model.candidateRun - 1, number: 1, result: ImageGenerateResult{ImageData: testPNG("old")}})
+	if len(model.Candidates) != 2 {
+		t.Errorf("expected a stale candidate to be ignored, got %d candidates", len(model.Candidates))
+	}
+}
+
+func TestImageCandidateLabel(t *testing.T) {
+	candidate := ImageCandidate{
+		Number:  2,
+		Result:  ImageGenerateResult{ImageData: make([]byte, 1536*1024)},
+		Elapsed: 14200 * time.Millisecond,
+		Width:   1024,
+		Height:  576,
+	}
+	if got, want := candidate.Label(), "Image 2 • 1024×576 • 1.5 MB • 14.2s"; got != want {
+		t.Errorf("Label() = %q, want %q", got, want)
+	}
+
+	// Formats that can't be decoded have no pixel size
+	candidate.Width, candidate.Height = 0, 0
+	candidate.Result.ImageData = make([]byte, 800)
+	if got, want := candidate.Label(), "Image 2 • 800 B • 14.2s"; got != want {
+		t.Errorf("Label() = %q, want %q", got, want)
+	}
+}
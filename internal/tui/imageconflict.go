@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// errMarkdownChanged is returned when a markdown file was edited since the
+// slides were loaded and the slide to change can't be found again.
+var errMarkdownChanged = errors.New("file changed, please retry")
+
+// fileStamp records a markdown file as it was when the slides were loaded.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// readStampedFile reads the file at path and returns its content and stamp.
+func readStampedFile(path string) ([]byte, fileStamp, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fileStamp{}, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fileStamp{}, err
+	}
+	return content, fileStamp{modTime: info.ModTime(), size: info.Size(), hash: sha256.Sum256(content)}, nil
+}
+
+// changedOnDisk reports whether file was edited since the slides were loaded.
+// Files that weren't loaded are reported unchanged.
+func (m *ImageGenModel) changedOnDisk(file string) (bool, error) {
+	loaded, ok := m.fileStamps[file]
+	if !ok {
+		return false, nil
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		return false, err
+	}
+	if info.ModTime().Equal(loaded.modTime) && info.Size() == loaded.size {
+		return false, nil
+	}
+
+	// Saving the file without changes only updates its modification time
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	return sha256.Sum256(content) != loaded.hash, nil
+}
+
+// writeTarget returns the markdown file to edit for the selected slide, the
+// index of the slide within it, and the file's current content. When the file
+// was edited since the slides were loaded, the slide is found again in the
+// new content: by the prompt and path of the image being regenerated, or by
+// its title when adding an image.
+func (m *ImageGenModel) writeTarget() (string, int, []byte, error) {
+	file, slideIndex, err := m.slideTarget()
+	if err != nil {
+		return "", 0, nil, err
+	}
+	changed, err := m.changedOnDisk(file)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to read markdown file: %w", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to read markdown file: %w", err)
+	}
+	if !changed {
+		return file, slideIndex, content, nil
+	}
+
+	slideIndex, err = m.relocateSlide(file, string(content))
+	if err != nil {
+		return "", 0, nil, err
+	}
+	return file, slideIndex, content, nil
+}
+
+// relocateSlide returns the index of the selected slide in content, the
+// edited content of file. It fails with errMarkdownChanged unless exactly
+// one slide matches.
+func (m *ImageGenModel) relocateSlide(file, content string) (int, error) {
+	var matches []int
+	var target, targets string
+	if m.SelectedImage != nil {
+		target = fmt.Sprintf("the image %s", m.SelectedImage.ImagePath)
+		targets = "slides with " + target
+		for _, slide := range parseSlides(content, m.strictDelimiters) {
+			for _, image := range slide.AIImages {
+				if image.Prompt == m.SelectedImage.Prompt && image.ImagePath == m.SelectedImage.ImagePath {
+					matches = append(matches, slide.Index)
+					break
+				}
+			}
+		}
+	} else {
+		selected := m.GetSelectedSlide()
+		if selected == nil {
+			return 0, fmt.Errorf("invalid slide index: %d", m.SelectedIndex)
+		}
+		target = fmt.Sprintf("a slide titled %q", selected.Title)
+		targets = fmt.Sprintf("slides titled %q", selected.Title)
+		for _, slide := range parseSlides(content, m.strictDelimiters) {
+			if slide.Title == selected.Title {
+				matches = append(matches, slide.Index)
+			}
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], nil
+	case 0:
+		return 0, fmt.Errorf("%w: %s was edited and no longer has %s", errMarkdownChanged, filepath.Base(file), target)
+	default:
+		return 0, fmt.Errorf("%w: %s was edited and has %d %s", errMarkdownChanged, filepath.Base(file), len(matches), targets)
+	}
+}
+
+// writeMarkdown writes content to file, then loads the slides again so they
+// match the file, with the slide at slideIndex in file selected.
+func (m *ImageGenModel) writeMarkdown(file string, slideIndex int, content []byte) error {
+	if err := os.WriteFile(file, content, 0644); err != nil {
+		return fmt.Errorf("failed to write markdown file: %w", err)
+	}
+
+	// The image is written either way, so a failed reload leaves the
+	// previous slides in place and the next write looks the slide up again
+	if err := m.loadSlides(); err != nil {
+		return nil
+	}
+	for _, slide := range m.Slides {
+		if slide.File == file && slide.FileIndex == slideIndex {
+			m.SelectedIndex = slide.Index
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newConflictTestModel writes content to a markdown file and returns an image
+// generator with its slides loaded.
+func newConflictTestModel(t *testing.T, content string) *ImageGenModel {
+	t.Helper()
+	mdFile := filepath.Join(t.TempDir(), "slides.md")
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("NewImageGenModel() returned error: %v", err)
+	}
+	return model
+}
+
+// editMarkdown replaces the content of the model's markdown file, as an
+// editor would.
+func editMarkdown(t *testing.T, model *ImageGenModel, content string) {
+	t.Helper()
+	if err := os.WriteFile(model.MarkdownFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to edit test file: %v", err)
+	}
+}
+
+// readMarkdown returns the content of the model's markdown file.
+func readMarkdown(t *testing.T, model *ImageGenModel) string {
+	t.Helper()
+	content, err := os.ReadFile(model.MarkdownFile)
+	if err != nil {
+		t.Fatalf("failed to read test file: %v", err)
+	}
+	return string(content)
+}
+
+func TestImageGenModel_ChangedOnDisk(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	if changed, err := model.changedOnDisk(model.MarkdownFile); err != nil || changed {
+		t.Fatalf("changedOnDisk() = %v, %v before any edit", changed, err)
+	}
+
+	// Saving without changes isn't an edit
+	editMarkdown(t, model, "# Intro\n\nHello")
+	if changed, _ := model.changedOnDisk(model.MarkdownFile); changed {
+		t.Error("expected a save without changes not to count as an edit")
+	}
+
+	editMarkdown(t, model, "# Intro\n\nHello, world")
+	if changed, _ := model.changedOnDisk(model.MarkdownFile); !changed {
+		t.Error("expected the edit to be detected")
+	}
+}
+
+func TestImageGenModel_InsertImageAfterEdit(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello\n\n---\n\n# Architecture\n\nBoxes and arrows")
+	model.SelectedIndex = 1
+	model.Prompt = "a diagram"
+
+	// A slide added before the selected one moves it
+	editMarkdown(t, model, "# Agenda\n\nToday\n\n---\n\n# Intro\n\nHello\n\n---\n\n# Architecture\n\nBoxes and arrows")
+
+	if err := model.InsertImageIntoMarkdown("images/diagram.png"); err != nil {
+		t.Fatalf("InsertImageIntoMarkdown() returned error: %v", err)
+	}
+	slides := parseSlides(readMarkdown(t, model), false)
+	if len(slides) != 3 || slides[2].Title != "Architecture" || slides[2].AIImageCount != 1 {
+		t.Fatalf("expected the image on the Architecture slide, got %+v", slides)
+	}
+	if slides[1].AIImageCount != 0 {
+		t.Error("expected the Intro slide to be left alone")
+	}
+	if model.SelectedIndex != 2 {
+		t.Errorf("expected the selection to follow the slide, got %d", model.SelectedIndex)
+	}
+
+	// The slides were loaded again after the write, so the next image lands on the same slide
+	model.Prompt = "another diagram"
+	if err := model.InsertImageIntoMarkdown("images/diagram-2.png"); err != nil {
+		t.Fatalf("second InsertImageIntoMarkdown() returned error: %v", err)
+	}
+	if slides := parseSlides(readMarkdown(t, model), false); slides[2].AIImageCount != 2 {
+		t.Errorf("expected both images on the Architecture slide, got %+v", slides[2])
+	}
+}
+
+func TestImageGenModel_ReplaceImageAfterEdit(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\n<!-- ai-prompt: a lighthouse -->\n![](images/old.png)")
+	model.SelectedIndex = 0
+	model.SelectedImage = &model.Slides[0].AIImages[0]
+	model.Prompt = "a lighthouse at dusk"
+
+	editMarkdown(t, model, "# Welcome\n\nHi\n\n---\n\n# Intro, renamed\n\n<!-- ai-prompt: a lighthouse -->\n![](images/old.png)")
+
+	if err := model.ReplaceImageInMarkdown("images/new.png"); err != nil {
+		t.Fatalf("ReplaceImageInMarkdown() returned error: %v", err)
+	}
+	content := readMarkdown(t, model)
+	if !strings.Contains(content, "# Intro, renamed\n\n<!-- ai-prompt: a lighthouse at dusk -->\n![](images/new.png)") {
+		t.Errorf("expected the image replaced on the moved slide, got:\n%s", content)
+	}
+}
+
+func TestImageGenModel_WriteAfterAmbiguousEdit(t *testing.T) {
+	original := "# Intro\n\nHello\n\n---\n\n# Demo\n\n<!-- ai-prompt: a robot -->\n![](images/robot.png)"
+	tests := []struct {
+		name   string
+		edit   string
+		write  func(m *ImageGenModel) error
+		reason string
+	}{
+		{
+			name: "duplicated title",
+			edit: "# Intro\n\nHello\n\n---\n\n# Intro\n\nHello again\n\n---\n\n# Demo",
+			write: func(m *ImageGenModel) error {
+				m.SelectedIndex = 0
+				return m.InsertImageIntoMarkdown("images/intro.png")
+			},
+			reason: `has 2 slides titled "Intro"`,
+		},
+		{
+			name: "removed image",
+			edit: "# Intro\n\nHello\n\n---\n\n# Demo\n\nNo image yet",
+			write: func(m *ImageGenModel) error {
+				m.SelectedIndex = 1
+				m.SelectedImage = &m.Slides[1].AIImages[0]
+				return m.ReplaceImageInMarkdown("images/robot-2.png")
+			},
+			reason: "no longer has the image images/robot.png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model := newConflictTestModel(t, original)
+			model.Prompt = "a robot"
+			editMarkdown(t, model, tt.edit)
+
+			err := tt.write(model)
+			if !errors.Is(err, errMarkdownChanged) {
+				t.Fatalf("expected errMarkdownChanged, got %v", err)
+			}
+			if !strings.Contains(err.Error(), "slides.md was edited and "+tt.reason) {
+				t.Errorf("expected the error to say why, got %q", err)
+			}
+			if content := readMarkdown(t, model); content != tt.edit {
+				t.Errorf("expected the edited file to be left alone, got:\n%s", content)
+			}
+		})
+	}
+}
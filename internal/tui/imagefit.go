@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// nextFit returns the fit following current in the done step's fit
+// selection: cover, contain, original, and then none again.
+func nextFit(current string) string {
+	for i, fit := range parser.ImageFits {
+		if fit == current {
+			if i == len(parser.ImageFits)-1 {
+				return ""
+			}
+			return parser.ImageFits[i+1]
+		}
+	}
+	return parser.ImageFits[0]
+}
+
+// imageFit returns the fit of the img comment written after the image, or
+// an empty string if it has none.
+func (m *ImageGenModel) imageFit() string {
+	return parser.ParseImageHint(m.Hint).Fit
+}
+
+// CycleFit sets the fit of the saved image to the next one, and writes it
+// as the img comment after the image in the markdown. The position and width
+// of the comment are kept.
+func (m *ImageGenModel) CycleFit() error {
+	hint := parser.ParseImageHint(m.Hint)
+	hint.Fit = nextFit(hint.Fit)
+	if err := m.setHint(hint.String()); err != nil {
+		return err
+	}
+
+	if hint.Fit == "" {
+		m.emitEvent("action", fmt.Sprintf("Removed the fit of %s", m.SavedImagePath))
+	} else {
+		m.emitEvent("action", fmt.Sprintf("Set the fit of %s to %s", m.SavedImagePath, hint.Fit))
+	}
+	return nil
+}
+
+// setHint rewrites the saved image in the markdown with hint as its img
+// comment, leaving Hint as it was if that fails.
+func (m *ImageGenModel) setHint(hint string) error {
+	if m.SavedImagePath == "" {
+		return fmt.Errorf("no saved image to fit")
+	}
+
+	file, slideIndex, content, err := m.writeTarget()
+	if err != nil {
+		return fmt.Errorf("failed to set image fit: %w", err)
+	}
+
+	previous := m.Hint
+	m.Hint = hint
+	newContent, err := updateSlide(string(content), slideIndex, m.strictDelimiters, func(slideContent string) (string, error) {
+		return replaceImageInContent(slideContent, m.Prompt, toMarkdownPath(m.SavedImagePath), m.imageMarkdown(m.SavedImagePath))
+	})
+	if err != nil {
+		m.Hint = previous
+		return fmt.Errorf("failed to set image fit: %w", err)
+	}
+
+	if err := m.writeMarkdown(file, slideIndex, []byte(newContent)); err != nil {
+		m.Hint = previous
+		return err
+	}
+	return nil
+}
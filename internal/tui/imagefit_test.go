@@ -0,0 +1,188 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestAIImageMarkdown_Hint(t *testing.T) {
+	image := aiImageMarkdown{Prompt: "a cat", Path: "images/cat.png", AltText: "A cat", Caption: "Our cat", Hint: "fit: cover,  position: top"}
+	want := "<!-- ai-prompt: a cat -->\n![A cat](images/cat.png)\n<!-- img: {fit: cover, position: top} -->\n*Our cat*"
+	if got := image.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestParseAIImages_Hint(t *testing.T) {
+	content := `# Slide
+
+<!-- ai-prompt: a cat -->
+![A cat](images/cat.png)
+<!-- img: {fit: cover, position: top} -->
+*Our office cat*
+
+<!-- ai-prompt: a dog -->
+![](images/dog.png)
+<!-- img: {fit: contain} -->
+<!-- ai-prompt: a bird -->
+![](images/bird.png)`
+
+	expected := []AIImageInfo{
+		{Prompt: "a cat", ImagePath: "images/cat.png", AltText: "A cat", Caption: "Our office cat", Hint: "fit: cover, position: top"},
+		{Prompt: "a dog", ImagePath: "images/dog.png", Hint: "fit: contain"},
+		{Prompt: "a bird", ImagePath: "images/bird.png"},
+	}
+	if got := parseAIImages(content); !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseAIImages() = %+v, want %+v", got, expected)
+	}
+}
+
+func TestReplaceImageInContent_Hint(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		image    aiImageMarkdown
+		expected string
+	}{
+		{
+			name:     "hint is kept",
+			content:  "<!-- ai-prompt: a cat -->\n![A cat](images/old.png)\n<!-- img: {fit: cover} -->\n*Caption*\n\nText",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/new.png", AltText: "A cat", Caption: "Caption", Hint: "fit: cover"},
+			expected: "<!-- ai-prompt: a cat -->\n![A cat](images/new.png)\n<!-- img: {fit: cover} -->\n*Caption*\n\nText",
+		},
+		{
+			name:     "hint is replaced, not repeated",
+			content:  "<!-- ai-prompt: a cat -->\n![](images/old.png)\n<!-- img: {fit: cover} -->\n\nText",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/new.png", Hint: "fit: contain"},
+			expected: "<!-- ai-prompt: a cat -->\n![](images/new.png)\n<!-- img: {fit: contain} -->\n\nText",
+		},
+		{
+			name:     "hint is removed",
+			content:  "<!-- ai-prompt: a cat -->\n![](images/old.png)\n<!-- img: {fit: cover} -->",
+			image:    aiImageMarkdown{Prompt: "a cat", Path: "images/new.png"},
+			expected: "<!-- ai-prompt: a cat -->\n![](images/new.png)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := replaceImageInContent(tt.content, "a cat", "images/old.png", tt.image)
+			if err != nil {
+				t.Fatalf("replaceImageInContent() error = %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("replaceImageInContent() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestImageGenModel_RegeneratePreservesHint(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	content := "# Slide\n\n<!-- ai-prompt: a cat -->\n![A cat](images/cat.png)\n<!-- img: {fit: cover, position: top, width: 60%} -->\n*Our office cat*\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	// Select the slide, then the regenerate option
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(*ImageGenModel)
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+
+	if m.Hint != "fit: cover, position: top, width: 60%" {
+		t.Fatalf("Hint = %q, want the hint of the image being regenerated", m.Hint)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if err := m.ReplaceImageInMarkdown("images/cat2.png"); err != nil {
+		t.Fatalf("ReplaceImageInMarkdown failed: %v", err)
+	}
+	updated, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+	want := "# Slide\n\n<!-- ai-prompt: a cat -->\n![A cat](images/cat2.png)\n<!-- img: {fit: cover, position: top, width: 60%} -->\n*Our office cat*\n"
+	if string(updated) != want {
+		t.Errorf("updated content = %q, want %q", updated, want)
+	}
+}
+
+func TestNextFit(t *testing.T) {
+	for current, want := range map[string]string{
+		"":         "cover",
+		"cover":    "contain",
+		"contain":  "original",
+		"original": "",
+		"stretch":  "cover",
+	} {
+		if got := nextFit(current); got != want {
+			t.Errorf("nextFit(%q) = %q, want %q", current, got, want)
+		}
+	}
+}
+
+func TestImageGenModel_DoneStepFitSelection(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	content := "# Slide\n\n<!-- ai-prompt: a cat -->\n![a cat](images/cat.png)\n<!-- img: {position: top} -->\n\nText\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	m, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	var events []string
+	m.Events = func(eventType, message string) { events = append(events, message) }
+
+	// The image was just saved and inserted
+	m.Step = ImageGenStepDone
+	m.Prompt = "a cat"
+	m.AltText = "a cat"
+	m.Hint = "position: top"
+	m.SavedImagePath = "images/cat.png"
+
+	if view := m.View(); !strings.Contains(view, "layout default") || !strings.Contains(view, "fit (cover/contain/original)") {
+		t.Errorf("done view should show the fit and the f key, got:\n%s", view)
+	}
+
+	for _, want := range []string{
+		"<!-- img: {fit: cover, position: top} -->",
+		"<!-- img: {fit: contain, position: top} -->",
+		"<!-- img: {fit: original, position: top} -->",
+		"<!-- img: {position: top} -->",
+	} {
+		newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("f")})
+		if newModel == nil {
+			t.Fatal("f should not close the image generator")
+		}
+		if m.fitError != "" {
+			t.Fatalf("fit selection failed: %s", m.fitError)
+		}
+		updated, err := os.ReadFile(mdFile)
+		if err != nil {
+			t.Fatalf("failed to read updated file: %v", err)
+		}
+		wantContent := "# Slide\n\n<!-- ai-prompt: a cat -->\n![a cat](images/cat.png)\n" + want + "\n\nText\n"
+		if string(updated) != wantContent {
+			t.Errorf("updated content = %q, want %q", updated, wantContent)
+		}
+	}
+
+	if len(events) != 4 || events[0] != "Set the fit of images/cat.png to cover" || events[3] != "Removed the fit of images/cat.png" {
+		t.Errorf("events = %q, want one per fit change", events)
+	}
+}
@@ -2,21 +2,30 @@
 package tui
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
+	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/imagecache"
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+	"github.com/MiniCodeMonkey/tap/internal/imagereport"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
 	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
-	"github.com/MiniCodeMonkey/tap/internal/gemini"
-	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"gopkg.in/yaml.v3"
 )
 
 // ImageGenStep represents the current step in the image generation workflow.
@@ -29,12 +38,64 @@ const (
 	ImageGenStepImageSelect
 	// ImageGenStepPrompt is the prompt input step.
 	ImageGenStepPrompt
+	// ImageGenStepPlacement chooses where a new image is inserted in the slide.
+	ImageGenStepPlacement
+	// ImageGenStepCached offers an image generated earlier from the same
+	// prompt and options, found in the cache, instead of generating one.
+	ImageGenStepCached
 	// ImageGenStepGenerating is the image generation step.
 	ImageGenStepGenerating
+	// ImageGenStepReview previews the generated image before it is saved.
+	ImageGenStepReview
+	// ImageGenStepCandidates chooses one of several images generated from the
+	// prompt, before it is saved.
+	ImageGenStepCandidates
 	// ImageGenStepDone is the completion step.
 	ImageGenStepDone
+	// ImageGenStepBatch generates all pending images (ai-prompt comments whose image file is missing).
+	ImageGenStepBatch
+)
+
+// DefaultBatchBackoff is how long batch generation waits after a rate limit error
+// before generating the next image.
+const DefaultBatchBackoff = 30 * time.Second
+
+// Maximum size of the generated image preview in the review step, in terminal cells.
+const (
+	previewWidth  = 48
+	previewHeight = 16
+)
+
+// BatchItemStatus is the status of an image in batch generation.
+type BatchItemStatus int
+
+const (
+	// BatchItemPending indicates the image has not been generated yet.
+	BatchItemPending BatchItemStatus = iota
+	// BatchItemGenerating indicates the image is being generated.
+	BatchItemGenerating
+	// BatchItemDone indicates the image was generated and the markdown updated.
+	BatchItemDone
+	// BatchItemFailed indicates generation failed.
+	BatchItemFailed
 )
 
+// BatchItem is an image to generate in batch mode.
+type BatchItem struct {
+	// Image is the ai-prompt comment and image path from the markdown.
+	Image AIImageInfo
+	// Error is the error message if generation failed.
+	Error string
+	// SavedImagePath is the relative path to the generated image.
+	SavedImagePath string
+	// SlideIndex is the index of the slide in Slides.
+	SlideIndex int
+	// Status is the current status of the item.
+	Status BatchItemStatus
+	// Cached indicates the image was taken from the cache rather than generated.
+	Cached bool
+}
+
 // SlideInfo contains information about a slide for display in the selector.
 type SlideInfo struct {
 	// Index is the zero-based slide index.
@@ -47,6 +108,13 @@ type SlideInfo struct {
 	AIImageCount int
 	// AIImages contains info about each AI-generated image on the slide.
 	AIImages []AIImageInfo
+	// TwoColumn indicates the slide has a ||| column separator.
+	TwoColumn bool
+	// File is the markdown file containing the slide. It is empty when the
+	// slide combines content from several files via include directives.
+	File string
+	// FileIndex is the zero-based index of the slide within File.
+	FileIndex int
 }
 
 // ImageSelectOption represents an option in the image selection step.
@@ -74,6 +142,9 @@ type imageGenerateMsg struct {
 	result ImageGenerateResult
 }
 
+// batchNextMsg is sent to generate the next pending image in batch mode.
+type batchNextMsg struct{}
+
 // ImageGenModel is the Bubble Tea model for the image generation workflow.
 type ImageGenModel struct {
 	// Slides contains information about all slides.
@@ -94,16 +165,149 @@ type ImageGenModel struct {
 	SelectedImage *AIImageInfo
 	// Prompt is the prompt text for image generation.
 	Prompt string
+	// AltText is the alt text of the image in the markdown.
+	AltText string
+	// Caption is the optional caption inserted as an italic line under the image.
+	Caption string
+	// Hint is the content of the img comment written after the image, such
+	// as "fit: cover", kept from the image being regenerated and set by the
+	// fit selection in the done step; empty for none.
+	Hint string
+	// fitError is why the last fit selection in the done step failed.
+	fitError string
+	// PromptField is the focused input in the prompt step.
+	PromptField PromptField
+	// Placement is where a new image is inserted in the slide.
+	Placement ImagePlacement
+	// PlacementOptions contains the placements offered for the selected slide.
+	PlacementOptions []ImagePlacement
+	// PlacementIndex is the currently selected placement option index.
+	PlacementIndex int
 	// promptInput is the textarea model for prompt input.
 	promptInput textarea.Model
+	// altInput is the text input for the alt text.
+	altInput textinput.Model
+	// captionInput is the text input for the caption.
+	captionInput textinput.Model
 	// spinner is the spinner model for the generating step.
 	spinner spinner.Model
-	// GeneratedImage holds the result of a successful image generation.
+	// GeneratedImage holds the result of a successful image generation. In
+	// the candidate selection step, it is the highlighted candidate.
 	GeneratedImage *ImageGenerateResult
+	// CandidateCount is how many images are generated from the prompt to
+	// choose from, from 1 to maxCandidates.
+	CandidateCount int
+	// Candidates are the images generated successfully when CandidateCount is
+	// above 1, in the order they were requested.
+	Candidates []ImageCandidate
+	// CandidateFailures is how many of the requested candidates failed.
+	CandidateFailures int
+	// CandidateIndex is the highlighted candidate in Candidates.
+	CandidateIndex int
+	// ChosenCandidate is the number of the accepted candidate, or 0 if a
+	// single image was generated.
+	ChosenCandidate int
+	// candidateRun identifies the latest candidate generation, so candidates
+	// of earlier ones are ignored.
+	candidateRun int
+	// candidatesPending is how many candidates are still being generated.
+	candidatesPending int
+	// candidateErr is the error of the first failed candidate.
+	candidateErr error
+	// PreviewProtocol is how the generated image is drawn in the review step.
+	PreviewProtocol PreviewProtocol
+	// preview is the rendered terminal preview of the generated image.
+	preview string
+	// previewErr describes why the generated image could not be previewed.
+	previewErr string
 	// IsGenerating indicates whether generation is in progress.
 	IsGenerating bool
 	// SavedImagePath is the relative path to the saved image file (after saving).
 	SavedImagePath string
+	// Cache holds images generated earlier, by prompt and options; nil
+	// disables it.
+	Cache *imagecache.Cache
+	// CachedImage is the image found in the cache for the prompt, offered in
+	// the cached step.
+	CachedImage *imagecache.Entry
+	// FromCache indicates the generated image was taken from the cache.
+	FromCache bool
+	// cacheKey is the cache key of the image being generated; empty when it
+	// isn't cached.
+	cacheKey string
+	// WriteError explains why generated images can't be saved in the deck's
+	// folder; images can't be generated while it is set.
+	WriteError string
+	// saveFailed indicates the accepted image couldn't be saved, and is back
+	// in the review step to try again.
+	saveFailed bool
+	// SaveToNotes indicates whether the prompt should also be appended to the slide's speaker notes.
+	SaveToNotes bool
+	// UseReference indicates whether a regenerated image is generated from the
+	// existing image file, rather than from the prompt alone.
+	UseReference bool
+	// ReferenceUsed indicates whether the last generation used the existing image as a reference.
+	ReferenceUsed bool
+	// ReferenceWarning explains why the existing image could not be used as a reference.
+	ReferenceWarning string
+	// AspectRatio is the aspect ratio of the generated image (e.g., "16:9").
+	AspectRatio string
+	// ImageSize is the resolution of the generated image ("1K", "2K", "4K"); empty uses the model default.
+	ImageSize string
+	// Provider configures the image provider, from the project config file and
+	// environment variables.
+	Provider config.ImageGenConfig
+	// MaxImageSize is the maximum size in bytes of a generated image that will be saved.
+	MaxImageSize int64
+	// BatchItems contains the images being generated in batch mode.
+	BatchItems []BatchItem
+	// BatchBackoff is how long to wait after a rate limit error in batch mode.
+	BatchBackoff time.Duration
+	// batchIndex is the index in BatchItems of the image being generated.
+	batchIndex int
+	// batchWaiting indicates batch mode is backing off after a rate limit error.
+	batchWaiting bool
+	// cancelGenerate cancels the in-flight generation request, including its retries.
+	cancelGenerate context.CancelFunc
+	// defaultRatio is the aspect ratio matching the presentation, which is not recorded in prompt comments.
+	defaultRatio string
+	// includes contains the files spliced into the markdown file via include directives.
+	includes []string
+	// undo records the changes made by the last accepted image, so they can be undone.
+	undo *imageUndo
+	// confirm is the confirm dialog shown before a destructive action, if any.
+	confirm *ConfirmModel
+	// fileStamps records the markdown file and its includes as they were
+	// when the slides were loaded, to detect edits made in the meantime.
+	fileStamps map[string]fileStamp
+	// strictDelimiters is the presentation's strictDelimiters setting, so
+	// slides are split the same way as by the parser.
+	strictDelimiters bool
+	// HistoryGlobal recalls the prompts used for all decks rather than only
+	// this one.
+	HistoryGlobal bool
+	// history holds the prompts of earlier generations; nil disables recall.
+	history *PromptHistory
+	// historyPrompts are the prompts being recalled, most recent first.
+	historyPrompts []string
+	// historyPos is how many prompts back the prompt input is in
+	// historyPrompts; 0 when it shows the prompt being written.
+	historyPos int
+	// historyDraft is the prompt being written when recalling started.
+	historyDraft string
+	// search is the slide search opened from the slide selection step, if any.
+	search *SearchModel
+	// Events, if set, is called with the type and message of what happened,
+	// such as a generation starting or an image being saved, for the dev
+	// TUI's activity log.
+	Events func(eventType, message string)
+	// now returns the current time; nil uses time.Now.
+	now func() time.Time
+	// generateStarted is when the latest generation started.
+	generateStarted time.Time
+	// generateElapsed is how long the latest generation took, once its
+	// result arrived.
+	generateElapsed time.Duration
 }
 
 // NewImageGenModel creates a new ImageGenModel for image generation.
@@ -121,12 +325,24 @@ func NewImageGenModel(markdownFile string) (*ImageGenModel, error) {
 	s.Spinner = spinner.Dot
 	s.Style = lipgloss.NewStyle().Foreground(ColorPrimary)
 
+	cfg := loadPresentationConfig(markdownFile)
+	aspectRatio := defaultAspectRatio(cfg)
 	m := &ImageGenModel{
-		MarkdownFile:  markdownFile,
-		SelectedIndex: 0,
-		Step:          ImageGenStepSlideSelect,
-		promptInput:   ta,
-		spinner:       s,
+		MarkdownFile:     markdownFile,
+		SelectedIndex:    0,
+		Step:             ImageGenStepSlideSelect,
+		AspectRatio:      aspectRatio,
+		Provider:         imagegen.ResolveConfig(cfg.ImageGen),
+		MaxImageSize:     DefaultMaxImageSize,
+		CandidateCount:   1,
+		BatchBackoff:     DefaultBatchBackoff,
+		PreviewProtocol:  DetectPreviewProtocol(os.Getenv),
+		defaultRatio:     aspectRatio,
+		promptInput:      ta,
+		altInput:         newPromptFieldInput("Defaults to the prompt"),
+		captionInput:     newPromptFieldInput("Optional caption shown under the image"),
+		spinner:          s,
+		strictDelimiters: cfg.StrictDelimiters,
 	}
 
 	// Load slides from the markdown file
@@ -134,62 +350,133 @@ func NewImageGenModel(markdownFile string) (*ImageGenModel, error) {
 		return nil, err
 	}
 
+	// Report a read-only deck before any image is generated
+	m.checkWritable()
+	m.Cache = m.newImageCache()
+
 	return m, nil
 }
 
 // loadSlides parses the markdown file and extracts slide information.
 func (m *ImageGenModel) loadSlides() error {
-	content, err := os.ReadFile(m.MarkdownFile)
+	content, stamp, err := readStampedFile(m.MarkdownFile)
 	if err != nil {
+		if fileErr := CheckMarkdownFile(m.MarkdownFile); fileErr != nil {
+			return fileErr
+		}
 		return fmt.Errorf("failed to read markdown file: %w", err)
 	}
+	m.fileStamps = map[string]fileStamp{m.MarkdownFile: stamp}
+
+	if !parser.HasIncludes(string(content)) {
+		m.includes = nil
+		m.Slides = parseSlides(string(content), m.strictDelimiters)
+		for i := range m.Slides {
+			m.Slides[i].File = m.MarkdownFile
+			m.Slides[i].FileIndex = i
+		}
+		return nil
+	}
 
-	// Parse slides
-	m.Slides = parseSlides(string(content))
+	// Parse the merged document so slides from included files can be selected
+	expanded, includes, err := parser.ExpandIncludes(string(content), m.MarkdownFile)
+	if err != nil {
+		return fmt.Errorf("failed to expand includes: %w", err)
+	}
+	m.includes = includes
+	for _, include := range includes {
+		if _, stamp, err := readStampedFile(include); err == nil {
+			m.fileStamps[include] = stamp
+		}
+	}
+	m.Slides = parseSlides(expanded, m.strictDelimiters)
+	locateSlideFiles(m.Slides, slideParts(expanded, m.strictDelimiters), append([]string{m.MarkdownFile}, includes...), m.strictDelimiters)
 	return nil
 }
 
+// changedSinceLoad returns the file holding the selected slide if it was
+// changed on disk since the slides were loaded, or an empty string otherwise.
+func (m *ImageGenModel) changedSinceLoad() string {
+	file, _, err := m.slideTarget()
+	if err != nil {
+		return ""
+	}
+	if changed, _ := m.changedOnDisk(file); !changed {
+		return ""
+	}
+	return file
+}
+
+// slideLocation identifies a slide within a single markdown file.
+type slideLocation struct {
+	file  string
+	index int
+}
+
+// locateSlideFiles sets File and FileIndex for each slide by matching the
+// slide's content against the slides of each source file. Slides that contain
+// an include directive or span several files are left without a file.
+func locateSlideFiles(slides []SlideInfo, parts []string, files []string, strict bool) {
+	locations := make(map[string][]slideLocation)
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for i, part := range slideParts(string(content), strict) {
+			locations[part] = append(locations[part], slideLocation{file: file, index: i})
+		}
+	}
+
+	for i := range slides {
+		if i >= len(parts) {
+			break
+		}
+		candidates := locations[parts[i]]
+		if len(candidates) == 0 {
+			continue
+		}
+		slides[i].File = candidates[0].file
+		slides[i].FileIndex = candidates[0].index
+		locations[parts[i]] = candidates[1:]
+	}
+}
+
+// slideTarget returns the markdown file and slide index to edit for the selected slide.
+func (m *ImageGenModel) slideTarget() (string, int, error) {
+	if len(m.includes) == 0 {
+		return m.MarkdownFile, m.SelectedIndex, nil
+	}
+	slide := m.GetSelectedSlide()
+	if slide == nil {
+		return "", 0, fmt.Errorf("invalid slide index: %d", m.SelectedIndex)
+	}
+	if slide.File == "" {
+		return "", 0, fmt.Errorf("slide %d combines content from several files; edit it directly", slide.Index+1)
+	}
+	return slide.File, slide.FileIndex, nil
+}
+
 // slideDelimiterRe matches "---" on its own line.
 var slideDelimiterRe = regexp.MustCompile(`(?m)^---\s*$`)
 
 // headingRe matches markdown headings (# Heading).
 var headingRe = regexp.MustCompile(`(?m)^#+\s+(.+)$`)
 
-// frontmatterRe matches YAML frontmatter at the start of a file.
-var frontmatterRe = regexp.MustCompile(`(?s)^---\n.*?\n---\n?`)
-
 // aiPromptRe matches AI prompt comments: <!-- ai-prompt: ... -->
 // It captures the prompt text in group 1.
 var aiPromptRe = regexp.MustCompile(`<!--\s*ai-prompt:\s*(.+?)\s*-->`)
 
-// aiImageRe matches AI prompt comments followed by an image on the next line.
-// Group 1: prompt text, Group 2: image path
-// Only matches if the image is directly on the next line (possibly with leading spaces, but no blank lines).
-var aiImageRe = regexp.MustCompile(`<!--\s*ai-prompt:\s*(.+?)\s*-->\n[ \t]*!\[\]\(([^)]+)\)`)
-
 // AIImageInfo contains information about an AI-generated image.
-type AIImageInfo struct {
-	// Prompt is the AI prompt used to generate the image.
-	Prompt string
-	// ImagePath is the path to the generated image file.
-	ImagePath string
-}
+type AIImageInfo = imagereport.AIImage
 
-// parseSlides extracts slide information from markdown content.
-func parseSlides(content string) []SlideInfo {
-	// Remove frontmatter if present
-	content = frontmatterRe.ReplaceAllString(content, "")
-
-	// Split on slide delimiter, preserving code blocks
-	parts := parser.SplitSlidesPreservingCodeBlocks(content)
+// parseSlides extracts slide information from markdown content. strict is the
+// presentation's strictDelimiters setting.
+func parseSlides(content string, strict bool) []SlideInfo {
+	parts := slideParts(content, strict)
 
 	slides := make([]SlideInfo, 0, len(parts))
 	for _, part := range parts {
-		part = strings.TrimSpace(part)
-		if part == "" {
-			continue
-		}
-
 		aiImages := parseAIImages(part)
 		slide := SlideInfo{
 			Index:        len(slides),
@@ -197,6 +484,7 @@ func parseSlides(content string) []SlideInfo {
 			AIImages:     aiImages,
 			HasAIImages:  len(aiImages) > 0,
 			AIImageCount: len(aiImages),
+			TwoColumn:    hasColumnSeparator(part),
 		}
 
 		slides = append(slides, slide)
@@ -205,25 +493,108 @@ func parseSlides(content string) []SlideInfo {
 	return slides
 }
 
+// slideParts returns the trimmed content of each non-empty slide in markdown content.
+func slideParts(content string, strict bool) []string {
+	return imagereport.SlideParts(content, strict)
+}
+
+// slideStartLines returns the one-based line number at which each slide from
+// slideParts starts in markdown content, counting frontmatter lines.
+func slideStartLines(content string, strict bool) []int {
+	frontmatter := imagereport.Frontmatter(content)
+	offset := strings.Count(frontmatter, "\n")
+
+	lines := parser.SlideStartLines(content[len(frontmatter):], strict)
+	for i := range lines {
+		lines[i] += offset
+	}
+	return lines
+}
+
 // parseAIImages extracts AI-generated image info from slide content.
 // It looks for <!-- ai-prompt: ... --> comments followed by image references.
 func parseAIImages(content string) []AIImageInfo {
-	matches := aiImageRe.FindAllStringSubmatch(content, -1)
-	if matches == nil {
-		return nil
+	return imagereport.ParseAIImages(content)
+}
+
+// formatPromptOptions returns the prompt text for an ai-prompt comment, with
+// image options appended: "sunset | ratio: 16:9 | size: 2K | provider: openai".
+func formatPromptOptions(prompt, aspectRatio, imageSize, provider string) string {
+	if aspectRatio != "" {
+		prompt += " | ratio: " + aspectRatio
+	}
+	if imageSize != "" {
+		prompt += " | size: " + imageSize
+	}
+	if provider != "" {
+		prompt += " | provider: " + provider
+	}
+	return prompt
+}
+
+// promptComment returns the prompt text to record in the ai-prompt comment.
+// The aspect ratio is only recorded when it differs from the presentation
+// default, and the provider when it is not Gemini, which generated all images
+// before other providers were supported.
+func (m *ImageGenModel) promptComment() string {
+	aspectRatio := m.AspectRatio
+	if aspectRatio == m.defaultRatio {
+		aspectRatio = ""
+	}
+	provider := m.Provider.Provider
+	if provider == imagegen.ProviderGemini {
+		provider = ""
+	}
+	return formatPromptOptions(m.Prompt, aspectRatio, m.ImageSize, provider)
+}
+
+// imageMarkdown returns the markdown written for the generated image at imagePath.
+func (m *ImageGenModel) imageMarkdown(imagePath string) aiImageMarkdown {
+	return aiImageMarkdown{
+		Prompt:  m.promptComment(),
+		Path:    toMarkdownPath(imagePath),
+		AltText: m.AltText,
+		Caption: m.Caption,
+		Hint:    m.Hint,
 	}
+}
 
-	images := make([]AIImageInfo, 0, len(matches))
-	for _, match := range matches {
-		if len(match) >= 3 {
-			images = append(images, AIImageInfo{
-				Prompt:    match[1],
-				ImagePath: match[2],
-			})
+// nextOption returns the option following current in options, wrapping around.
+// If current is not an option, the first option is returned.
+func nextOption(options []string, current string) string {
+	for i, option := range options {
+		if option == current {
+			return options[(i+1)%len(options)]
 		}
 	}
+	return options[0]
+}
+
+// loadPresentationConfig returns the configuration of the presentation in
+// markdownFile, or the default configuration if it can't be loaded.
+func loadPresentationConfig(markdownFile string) *config.Config {
+	cfg, err := config.Load(markdownFile, nil, config.Overrides{})
+	if err != nil {
+		return config.DefaultConfig()
+	}
+	return cfg
+}
 
-	return images
+// ImageProviderConfig returns the image provider configured for the
+// presentation in markdownFile, with environment variables applied.
+func ImageProviderConfig(markdownFile string) config.ImageGenConfig {
+	return imagegen.ResolveConfig(loadPresentationConfig(markdownFile).ImageGen)
+}
+
+// defaultAspectRatio returns the image aspect ratio matching the presentation's
+// configured aspect ratio, falling back to 16:9 when it is not supported.
+func defaultAspectRatio(cfg *config.Config) string {
+	for _, ratio := range imagegen.AspectRatios {
+		if ratio == cfg.AspectRatio {
+			return ratio
+		}
+	}
+	return "16:9"
 }
 
 // extractSlideTitle extracts the title from slide content.
@@ -268,30 +639,64 @@ func (m *ImageGenModel) Init() tea.Cmd {
 func (m *ImageGenModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.confirm != nil {
+			return m.handleConfirmKey(msg)
+		}
 		return m.handleKeyPress(msg)
 
 	case imageGenerateMsg:
+		// Ignore results that arrive after the generation was canceled
+		if !m.IsGenerating {
+			return m, nil
+		}
+		m.cancelGeneration()
+		if m.Step == ImageGenStepBatch {
+			return m.handleBatchResult(msg.result)
+		}
 		return m.handleImageGenerateResult(msg.result)
 
+	case candidateMsg:
+		// Ignore candidates of a canceled or earlier generation
+		if !m.IsGenerating || msg.run != m.candidateRun {
+			return m, nil
+		}
+		return m.handleCandidateResult(msg)
+
+	case batchNextMsg:
+		if !m.batchWaiting {
+			return m, nil
+		}
+		m.batchWaiting = false
+		return m, m.startNextBatchItem()
+
 	case spinner.TickMsg:
-		// Update spinner when in generating step
-		if m.Step == ImageGenStepGenerating {
+		// Update spinner when generating
+		if m.Step == ImageGenStepGenerating || (m.Step == ImageGenStepBatch && m.IsGenerating) {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
 		}
 
 	default:
-		// Pass other messages to the textarea when in prompt step
+		// Pass other messages to the focused field when in prompt step
 		if m.Step == ImageGenStepPrompt {
-			var cmd tea.Cmd
-			m.promptInput, cmd = m.promptInput.Update(msg)
-			return m, cmd
+			return m, m.updatePromptField(msg)
 		}
 	}
 	return m, nil
 }
 
+// handleConfirmKey passes keyboard input to the confirm dialog, and closes
+// the dialog once it is answered. A confirmed action may open the next dialog.
+func (m *ImageGenModel) handleConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	confirm := m.confirm
+	_, cmd := confirm.Update(msg)
+	if confirm.Done() && m.confirm == confirm {
+		m.confirm = nil
+	}
+	return m, cmd
+}
+
 // handleKeyPress handles keyboard input for the image generator.
 func (m *ImageGenModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.Step {
@@ -301,17 +706,44 @@ func (m *ImageGenModel) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleImageSelectKey(msg)
 	case ImageGenStepPrompt:
 		return m.handlePromptKey(msg)
+	case ImageGenStepPlacement:
+		return m.handlePlacementKey(msg)
+	case ImageGenStepCached:
+		return m.handleCachedKey(msg)
 	case ImageGenStepGenerating:
 		return m.handleGeneratingKey(msg)
+	case ImageGenStepReview:
+		return m.handleReviewKey(msg)
+	case ImageGenStepCandidates:
+		return m.handleCandidatesKey(msg)
 	case ImageGenStepDone:
 		return m.handleDoneKey(msg)
+	case ImageGenStepBatch:
+		return m.handleBatchKey(msg)
 	}
 	return m, nil
 }
 
 // handleSlideSelectKey handles keyboard input during slide selection.
 func (m *ImageGenModel) handleSlideSelectKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.search != nil {
+		return m.handleSearchKey(msg)
+	}
+
 	switch msg.String() {
+	case "/":
+		// Search slides, text, and notes
+		entries, err := BuildSearchIndex(m.MarkdownFile)
+		if err != nil {
+			// Search the titles of the slides already loaded instead
+			entries = make([]SearchEntry, 0, len(m.Slides))
+			for _, slide := range m.Slides {
+				entries = append(entries, SearchEntry{Index: slide.Index, Title: slide.Title})
+			}
+		}
+		m.search = NewSearchModel(entries)
+		return m, m.search.Init()
+
 	case "esc", "q":
 		// Return nil to signal cancellation to the parent
 		return nil, nil
@@ -328,7 +760,19 @@ func (m *ImageGenModel) handleSlideSelectKey(msg tea.KeyMsg) (tea.Model, tea.Cmd
 		}
 		return m, nil
 
+	case "g":
+		// Generate all pending images
+		if !m.checkWritable() {
+			return m, nil
+		}
+		return m, m.startBatch()
+
 	case "enter":
+		// Check again, in case the folder's permissions were fixed
+		if !m.checkWritable() {
+			return m, nil
+		}
+
 		// Select the slide and proceed to next step
 		slide := m.GetSelectedSlide()
 		if slide != nil && slide.HasAIImages {
@@ -339,8 +783,8 @@ func (m *ImageGenModel) handleSlideSelectKey(msg tea.KeyMsg) (tea.Model, tea.Cmd
 			// No AI images, go directly to prompt input
 			m.SelectedImage = nil
 			m.Prompt = ""
-			m.promptInput.SetValue("")
-			m.promptInput.Focus()
+			m.Placement = PlacementEnd
+			m.setPromptFields("", "", "")
 			m.Step = ImageGenStepPrompt
 			return m, textarea.Blink
 		}
@@ -350,6 +794,27 @@ func (m *ImageGenModel) handleSlideSelectKey(msg tea.KeyMsg) (tea.Model, tea.Cmd
 	return m, nil
 }
 
+// handleSearchKey handles keyboard input when the slide search is open. On
+// enter, the chosen slide is selected.
+func (m *ImageGenModel) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	_, cmd := m.search.Update(msg)
+	if !m.search.Done() {
+		return m, cmd
+	}
+
+	search := m.search
+	m.search = nil
+	if result, ok := search.Selected(); ok && search.Chosen() {
+		for i, slide := range m.Slides {
+			if slide.Index == result.Index {
+				m.SelectedIndex = i
+				break
+			}
+		}
+	}
+	return m, nil
+}
+
 // handleImageSelectKey handles keyboard input during image selection (add new or regenerate).
 func (m *ImageGenModel) handleImageSelectKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
@@ -380,17 +845,28 @@ func (m *ImageGenModel) handleImageSelectKey(msg tea.KeyMsg) (tea.Model, tea.Cmd
 				// Adding new image, proceed to prompt input
 				m.SelectedImage = nil
 				m.Prompt = ""
-				m.promptInput.SetValue("")
+				m.Hint = ""
+				m.Placement = PlacementEnd
+				m.UseReference = false
+				m.setPromptFields("", "", "")
 			} else {
-				// Regenerating existing image, pre-fill prompt
+				// Regenerating existing image, pre-fill prompt, alt text, caption, and options
 				m.SelectedImage = option.AIImage
 				if option.AIImage != nil {
 					m.Prompt = option.AIImage.Prompt
-					m.promptInput.SetValue(option.AIImage.Prompt)
+					m.Hint = option.AIImage.Hint
+					m.setPromptFields(option.AIImage.Prompt, option.AIImage.AltText, option.AIImage.Caption)
+					m.AspectRatio = m.defaultRatio
+					if option.AIImage.AspectRatio != "" {
+						m.AspectRatio = option.AIImage.AspectRatio
+					}
+					m.ImageSize = option.AIImage.ImageSize
 				}
+				// Edit the existing image by default, if there is one
+				m.UseReference = m.oldImageExists()
 			}
-			m.promptInput.Focus()
 			m.Step = ImageGenStepPrompt
+			return m, m.focusPromptField(PromptFieldPrompt)
 		}
 		return m, textarea.Blink
 	}
@@ -403,7 +879,7 @@ func (m *ImageGenModel) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "esc":
 		// Go back to previous step
-		m.promptInput.Blur()
+		m.blurPromptFields()
 		slide := m.GetSelectedSlide()
 		if slide != nil && slide.HasAIImages {
 			// Go back to image select
@@ -417,6 +893,68 @@ func (m *ImageGenModel) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "ctrl+d":
 		// Submit the prompt
 		return m.submitPrompt()
+
+	case "tab":
+		// Move to the next field: prompt, alt text, caption
+		return m, m.focusPromptField((m.PromptField + 1) % promptFieldCount)
+
+	case "shift+tab":
+		return m, m.focusPromptField((m.PromptField + promptFieldCount - 1) % promptFieldCount)
+
+	case "ctrl+t":
+		// Toggle saving the prompt to speaker notes
+		m.SaveToNotes = !m.SaveToNotes
+		return m, nil
+
+	case "ctrl+p", "ctrl+n":
+		// Recall older or newer prompts from the history
+		if m.PromptField == PromptFieldPrompt {
+			if msg.String() == "ctrl+p" {
+				m.recallPrompt(1)
+			} else {
+				m.recallPrompt(-1)
+			}
+		}
+		return m, nil
+
+	case "up", "down":
+		// Recall prompts while the prompt is empty or shows a recalled one;
+		// otherwise the arrows move the cursor
+		if m.PromptField == PromptFieldPrompt && (m.promptInput.Value() == "" || m.recallingPrompt()) {
+			if msg.String() == "up" {
+				m.recallPrompt(1)
+			} else {
+				m.recallPrompt(-1)
+			}
+			return m, nil
+		}
+
+	case "ctrl+g":
+		// Toggle recalling the prompts of all decks
+		m.toggleHistoryScope()
+		return m, nil
+
+	case "ctrl+r":
+		// Cycle through aspect ratios
+		m.AspectRatio = nextOption(imagegen.AspectRatios, m.AspectRatio)
+		return m, nil
+
+	case "ctrl+s":
+		// Cycle through image sizes (empty = model default)
+		m.ImageSize = nextOption(append([]string{""}, imagegen.ImageSizes...), m.ImageSize)
+		return m, nil
+
+	case "ctrl+x":
+		// Cycle through the number of images to choose from
+		m.CandidateCount = m.CandidateCount%maxCandidates + 1
+		return m, nil
+
+	case "ctrl+o":
+		// Toggle using the existing image as a reference when regenerating
+		if m.SelectedImage != nil {
+			m.UseReference = !m.UseReference
+		}
+		return m, nil
 	}
 
 	// Check for enter key - submit if not empty
@@ -425,10 +963,8 @@ func (m *ImageGenModel) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.submitPrompt()
 	}
 
-	// Pass other keys to the textarea
-	var cmd tea.Cmd
-	m.promptInput, cmd = m.promptInput.Update(msg)
-	return m, cmd
+	// Pass other keys to the focused field
+	return m, m.updatePromptField(msg)
 }
 
 // submitPrompt validates and submits the prompt, moving to the generating step.
@@ -440,25 +976,146 @@ func (m *ImageGenModel) submitPrompt() (tea.Model, tea.Cmd) {
 	}
 
 	m.Prompt = prompt
+	m.AltText = cleanAltText(m.altInput.Value())
+	if m.AltText == "" {
+		m.AltText = defaultAltText(prompt)
+	}
+	m.Caption = cleanCaption(m.captionInput.Value())
 	m.Error = ""
-	m.promptInput.Blur()
+	m.blurPromptFields()
+
+	// New images need a position in the slide; regenerated images keep theirs
+	if m.SelectedImage == nil {
+		twoColumn := false
+		if slide := m.GetSelectedSlide(); slide != nil {
+			twoColumn = slide.TwoColumn
+		}
+		m.PlacementOptions = placementOptions(twoColumn)
+		m.PlacementIndex = 0
+		for i, placement := range m.PlacementOptions {
+			if placement == m.Placement {
+				m.PlacementIndex = i
+			}
+		}
+		m.Step = ImageGenStepPlacement
+		return m, nil
+	}
+
+	return m.startGeneration()
+}
+
+// startGeneration offers the cached image for the prompt if there is one, and
+// otherwise starts generating the image. Choosing from several candidates
+// always generates new ones.
+func (m *ImageGenModel) startGeneration() (tea.Model, tea.Cmd) {
+	if m.CandidateCount > 1 {
+		return m.generateFresh()
+	}
+	if entry := m.lookupCache(); entry != nil {
+		m.CachedImage = entry
+		m.Step = ImageGenStepCached
+		return m, nil
+	}
+	return m.generateFresh()
+}
+
+// generateFresh moves to the generating step and starts generating the image.
+func (m *ImageGenModel) generateFresh() (tea.Model, tea.Cmd) {
 	m.Step = ImageGenStepGenerating
 	m.IsGenerating = true
 
 	// Start spinner and image generation
-	return m, tea.Batch(m.spinner.Tick, m.generateImageCmd())
+	return m, tea.Batch(m.spinner.Tick, m.generateCmd())
+}
+
+// handlePlacementKey handles keyboard input while choosing where to insert a new image.
+func (m *ImageGenModel) handlePlacementKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		// Go back to the prompt
+		m.Step = ImageGenStepPrompt
+		return m, m.focusPromptField(m.PromptField)
+
+	case "up", "k":
+		if m.PlacementIndex > 0 {
+			m.PlacementIndex--
+		}
+		return m, nil
+
+	case "down", "j":
+		if m.PlacementIndex < len(m.PlacementOptions)-1 {
+			m.PlacementIndex++
+		}
+		return m, nil
+
+	case "enter":
+		if m.PlacementIndex >= 0 && m.PlacementIndex < len(m.PlacementOptions) {
+			m.Placement = m.PlacementOptions[m.PlacementIndex]
+		}
+		return m.startGeneration()
+	}
+
+	return m, nil
+}
+
+// generateCmd returns a command that generates the image, or CandidateCount
+// images to choose from.
+func (m *ImageGenModel) generateCmd() tea.Cmd {
+	m.cacheKey = ""
+	m.FromCache = false
+	m.generationStarted()
+	if m.CandidateCount > 1 {
+		return m.generateCandidatesCmd()
+	}
+	return m.generateImageCmd()
+}
+
+// generationOptions returns the options of a generation request.
+// With UseReference, the existing image is sent along to be edited; if it can't
+// be read, the image is generated from the prompt alone and ReferenceWarning
+// says why.
+func (m *ImageGenModel) generationOptions() imagegen.Options {
+	opts := imagegen.Options{
+		AspectRatio: m.AspectRatio,
+		ImageSize:   m.ImageSize,
+	}
+
+	m.ReferenceUsed, m.ReferenceWarning = false, ""
+	if m.UseReference && m.SelectedImage != nil {
+		data, contentType, err := m.readReferenceImage()
+		if err != nil {
+			m.ReferenceWarning = fmt.Sprintf("Existing image not used as reference: %v", err)
+		} else {
+			opts.Reference, opts.ReferenceType = data, contentType
+			m.ReferenceUsed = true
+		}
+	}
+	return opts
 }
 
-// generateImageCmd returns a command that generates an image using the Gemini API.
+// generateImageCmd returns a command that generates an image with the
+// configured provider, with the options of generationOptions. The request
+// can be canceled with cancelGeneration; canceled requests send no message.
 func (m *ImageGenModel) generateImageCmd() tea.Cmd {
 	prompt := m.Prompt
+	provider := m.Provider
+	opts := m.generationOptions()
+	if m.Cache != nil {
+		m.cacheKey = imagecache.Key(provider.Provider, provider.Model, prompt, opts)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelGenerate = cancel
 	return func() tea.Msg {
-		client, err := gemini.NewClientFromEnv()
+		generator, err := imagegen.New(provider)
 		if err != nil {
 			return imageGenerateMsg{result: ImageGenerateResult{Error: err}}
 		}
 
-		result, err := client.GenerateImage(context.Background(), prompt)
+		result, err := generator.GenerateImage(ctx, prompt, opts)
+		if ctx.Err() != nil {
+			return nil
+		}
 		if err != nil {
 			return imageGenerateMsg{result: ImageGenerateResult{Error: err}}
 		}
@@ -470,24 +1127,38 @@ func (m *ImageGenModel) generateImageCmd() tea.Cmd {
 	}
 }
 
+// cancelGeneration cancels the in-flight generation request, if any.
+func (m *ImageGenModel) cancelGeneration() {
+	if m.cancelGenerate != nil {
+		m.cancelGenerate()
+		m.cancelGenerate = nil
+	}
+}
+
 // handleGeneratingKey handles keyboard input during image generation.
 func (m *ImageGenModel) handleGeneratingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	// Only allow cancel when there's an error (not while generating)
+	// While generating, esc cancels the request and any pending retries
+	if m.Error == "" && m.IsGenerating && msg.String() == "esc" {
+		m.cancelGeneration()
+		m.IsGenerating = false
+		m.Step = ImageGenStepPrompt
+		return m, m.focusPromptField(m.PromptField)
+	}
+
 	if m.Error != "" {
 		switch msg.String() {
 		case "r":
 			// Retry generation
 			m.Error = ""
 			m.IsGenerating = true
-			return m, tea.Batch(m.spinner.Tick, m.generateImageCmd())
+			return m, tea.Batch(m.spinner.Tick, m.generateCmd())
 
 		case "esc":
 			// Go back to prompt step
 			m.Error = ""
 			m.IsGenerating = false
-			m.promptInput.Focus()
 			m.Step = ImageGenStepPrompt
-			return m, textarea.Blink
+			return m, m.focusPromptField(m.PromptField)
 		}
 	}
 	// While generating, ignore all key presses
@@ -495,12 +1166,22 @@ func (m *ImageGenModel) handleGeneratingKey(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 }
 
 // handleDoneKey handles keyboard input in the done step.
-// Any key press returns nil to signal completion to the parent.
+// Enter, esc, and space return nil to signal completion to the parent, and f
+// changes the fit of the saved image.
 func (m *ImageGenModel) handleDoneKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "enter", "esc", " ":
 		// Return nil to signal completion to the parent
 		return nil, nil
+	case "f":
+		// Fit the saved image into its space: cover, contain, original, or none
+		if m.SavedImagePath != "" && !m.Undone() {
+			m.fitError = ""
+			if err := m.CycleFit(); err != nil {
+				m.fitError = err.Error()
+			}
+		}
+		return m, nil
 	}
 	// Ignore other keys
 	return m, nil
@@ -513,76 +1194,437 @@ func (m *ImageGenModel) handleImageGenerateResult(result ImageGenerateResult) (t
 	if result.Error != nil {
 		// Show user-friendly error message
 		m.Error = formatAPIError(result.Error)
-		return m, nil
+		m.generationFailed(m.Error)
+		return m, m.logFailureCmd(result.Error)
+	}
+
+	// Reject empty, truncated, or oversized images so they can be retried
+	contentType, err := ValidateImageData(result.ImageData, m.MaxImageSize)
+	if err != nil {
+		m.Error = formatAPIError(err)
+		m.generationFailed(m.Error)
+		return m, m.logFailureCmd(err)
 	}
+	result.ContentType = contentType
+	m.generationFinished()
 
-	// Success - store the result and proceed to done step
+	// Success - store the result and preview it before saving
+	if !m.FromCache {
+		m.storeInCache(result)
+	}
 	m.GeneratedImage = &result
-	m.Step = ImageGenStepDone
+	m.recordPrompt()
+	m.renderPreview()
+	m.Step = ImageGenStepReview
 	return m, nil
 }
 
-// formatAPIError converts an API error to a user-friendly message.
-func formatAPIError(err error) string {
-	if err == nil {
-		return ""
+// renderPreview renders the terminal preview of the generated image.
+func (m *ImageGenModel) renderPreview() {
+	m.preview, m.previewErr = "", ""
+	preview, err := RenderImagePreview(m.GeneratedImage.ImageData, previewWidth, previewHeight, m.PreviewProtocol)
+	if err != nil {
+		m.previewErr = err.Error()
+		return
 	}
+	m.preview = preview
+}
 
-	// Check if it's a Gemini API error
-	if apiErr, ok := err.(*gemini.APIError); ok {
-		switch apiErr.Type {
-		case gemini.ErrorTypeAuth:
-			return "Authentication failed. Please check your GEMINI_API_KEY."
-		case gemini.ErrorTypeRateLimit:
-			return "Rate limit exceeded. Please wait a moment and try again."
-		case gemini.ErrorTypeContentPolicy:
-			return "The prompt was blocked by content policy. Please try a different prompt."
-		case gemini.ErrorTypeInvalidRequest:
-			return "Invalid request. Please try a different prompt."
-		case gemini.ErrorTypeNoImage:
-			return "No image was generated. Please try a different prompt."
-		case gemini.ErrorTypeNetwork:
-			return "Network error. Please check your connection and try again."
-		case gemini.ErrorTypeServer:
-			return "Server error. Please try again later."
+// handleReviewKey handles keyboard input in the review step.
+// Enter accepts the image, r regenerates it with the same prompt, and e (or esc)
+// discards it and returns to the prompt for editing.
+func (m *ImageGenModel) handleReviewKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// After a failed save, r and enter only try saving the same image again
+	if m.saveFailed {
+		switch msg.String() {
+		case "r", "enter":
+			m.retrySave()
+			return m, nil
+		case "e", "esc":
+			m.Error = ""
+			m.saveFailed = false
 		}
 	}
 
-	// Generic error
-	return fmt.Sprintf("Failed to generate image: %v", err)
-}
+	switch msg.String() {
+	case "enter":
+		m.confirmAccept()
+		return m, nil
 
-// View implements tea.Model.
-func (m *ImageGenModel) View() string {
-	switch m.Step {
-	case ImageGenStepSlideSelect:
-		return m.viewSlideSelect()
-	case ImageGenStepImageSelect:
-		return m.viewImageSelect()
-	case ImageGenStepPrompt:
-		return m.viewPrompt()
-	case ImageGenStepGenerating:
-		return m.viewGenerating()
-	case ImageGenStepDone:
-		return m.viewDone()
-	default:
-		return m.viewSlideSelect()
+	case "r":
+		m.discardGeneratedImage()
+		m.Step = ImageGenStepGenerating
+		m.IsGenerating = true
+		return m, tea.Batch(m.spinner.Tick, m.generateCmd())
+
+	case "e", "esc":
+		m.discardGeneratedImage()
+		m.Step = ImageGenStepPrompt
+		return m, m.focusPromptField(m.PromptField)
 	}
+	return m, nil
 }
 
-// viewSlideSelect renders the slide selection view.
-func (m *ImageGenModel) viewSlideSelect() string {
-	var b strings.Builder
+// confirmAccept accepts the generated image, first asking before the image
+// being regenerated is deleted and before a markdown file that changed on
+// disk since the slides were loaded is overwritten.
+func (m *ImageGenModel) confirmAccept() {
+	// The parent saves the image and updates the markdown once the done step is reached
+	accept := func() tea.Cmd {
+		m.discardCandidates()
+		m.Step = ImageGenStepDone
+		return nil
+	}
 
-	// Title
-	titleStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(ColorPrimary).
-		MarginBottom(1)
+	checkMarkdown := accept
+	if file := m.changedSinceLoad(); file != "" {
+		checkMarkdown = func() tea.Cmd {
+			m.confirm = NewConfirmModel(
+				fmt.Sprintf("%s changed on disk. Overwrite it?", filepath.Base(file)),
+				"It was edited after the image generator loaded the slides. The slide is found again before the image is added; if that fails, nothing is changed.",
+				accept,
+			)
+			return nil
+		}
+	}
+
+	if m.oldImageExists() {
+		m.confirm = NewConfirmModel(
+			fmt.Sprintf("Delete %s?", m.SelectedImage.ImagePath),
+			"The old image file is replaced by the generated image. Press u on the next screen to undo.",
+			checkMarkdown,
+		)
+		return
+	}
+	checkMarkdown()
+}
+
+// discardGeneratedImage drops the generated image and its preview, and any
+// other candidates.
+func (m *ImageGenModel) discardGeneratedImage() {
+	m.GeneratedImage = nil
+	m.preview, m.previewErr = "", ""
+	m.discardCandidates()
+	m.ChosenCandidate = 0
+}
+
+// PendingImages returns the AI images whose image file does not exist yet,
+// such as images from prompts written by hand or files deleted since generation.
+// It uses the same report as tap images --dry-run.
+func (m *ImageGenModel) PendingImages() []BatchItem {
+	slides := make([][]AIImageInfo, len(m.Slides))
+	for i, slide := range m.Slides {
+		slides[i] = slide.AIImages
+	}
+
+	var items []BatchItem
+	for _, img := range imagereport.New(m.MarkdownFile, slides).Pending() {
+		items = append(items, BatchItem{
+			Image:      img.AIImage,
+			SlideIndex: img.Slide - 1,
+			Status:     BatchItemPending,
+		})
+	}
+	return items
+}
+
+// startBatch switches to batch mode and starts generating all pending images.
+func (m *ImageGenModel) startBatch() tea.Cmd {
+	items := m.PendingImages()
+	if len(items) == 0 {
+		return nil
+	}
+
+	m.BatchItems = items
+	m.SaveToNotes = false
+	m.Step = ImageGenStepBatch
+	return m.startNextBatchItem()
+}
+
+// startNextBatchItem starts generating the next pending image in batch mode.
+// It returns nil when there are no pending images left.
+func (m *ImageGenModel) startNextBatchItem() tea.Cmd {
+	for i := range m.BatchItems {
+		item := &m.BatchItems[i]
+		if item.Status != BatchItemPending {
+			continue
+		}
+
+		item.Status = BatchItemGenerating
+		item.Error = ""
+		item.Cached = false
+		m.batchIndex = i
+
+		// Generate with the same settings as regenerating the image by hand
+		image := item.Image
+		m.SelectedIndex = item.SlideIndex
+		m.SelectedImage = &image
+		m.Prompt = image.Prompt
+		m.AltText = image.AltText
+		if m.AltText == "" {
+			m.AltText = defaultAltText(image.Prompt)
+		}
+		m.Caption = image.Caption
+		m.Hint = image.Hint
+		m.AspectRatio = image.AspectRatio
+		if m.AspectRatio == "" {
+			m.AspectRatio = m.defaultRatio
+		}
+		m.ImageSize = image.ImageSize
+		m.UseReference = false // Pending images have no file to use
+		m.IsGenerating = true
+		m.FromCache = false
+
+		// Use the image generated earlier from the same prompt, if cached
+		if entry := m.lookupCache(); entry != nil {
+			item.Cached = true
+			m.FromCache = true
+			m.cacheKey = ""
+			return func() tea.Msg {
+				return imageGenerateMsg{result: cachedResult(entry)}
+			}
+		}
+		m.generationStarted()
+		return tea.Batch(m.spinner.Tick, m.generateImageCmd())
+	}
+
+	// Reload slides so they reference the generated images; the slide list is
+	// unchanged, so a failed reload leaves the previous slides in place
+	m.IsGenerating = false
+	_ = m.loadSlides()
+	return nil
+}
+
+// handleBatchResult handles the result of generating an image in batch mode.
+// Successful images are saved and replace the missing image in the markdown.
+// After a rate limit error, the next image is generated after BatchBackoff.
+func (m *ImageGenModel) handleBatchResult(result ImageGenerateResult) (tea.Model, tea.Cmd) {
+	if m.batchIndex < 0 || m.batchIndex >= len(m.BatchItems) {
+		return m, nil
+	}
+	item := &m.BatchItems[m.batchIndex]
+
+	m.generationFinished()
+	if err := m.saveBatchResult(result); err != nil {
+		item.Status = BatchItemFailed
+		item.Error = formatAPIError(err)
+		m.generationFailed(item.Error)
+
+		// Failures to save the image aren't failed generations, so aren't logged
+		var logCmd tea.Cmd
+		var apiErr *imagegen.APIError
+		isAPIErr := errors.As(err, &apiErr)
+		if result.Error != nil || isAPIErr {
+			logCmd = m.logFailureCmd(err)
+		}
+
+		if isAPIErr && apiErr.Type == imagegen.ErrorTypeRateLimit {
+			m.batchWaiting = true
+			return m, tea.Batch(logCmd, tea.Tick(m.BatchBackoff, func(time.Time) tea.Msg {
+				return batchNextMsg{}
+			}))
+		}
+		return m, tea.Batch(logCmd, m.startNextBatchItem())
+	}
+
+	item.Status = BatchItemDone
+	item.SavedImagePath = m.SavedImagePath
+	if !item.Cached {
+		m.storeInCache(*m.GeneratedImage)
+	}
+	return m, m.startNextBatchItem()
+}
+
+// saveBatchResult validates and saves a generated image, then replaces the
+// selected image in the markdown with it.
+func (m *ImageGenModel) saveBatchResult(result ImageGenerateResult) error {
+	if result.Error != nil {
+		return result.Error
+	}
+
+	contentType, err := ValidateImageData(result.ImageData, m.MaxImageSize)
+	if err != nil {
+		return err
+	}
+	result.ContentType = contentType
+	m.GeneratedImage = &result
+
+	savedPath, err := m.SaveGeneratedImage()
+	if err != nil {
+		return err
+	}
+	if err := m.ReplaceImageInMarkdown(savedPath); err != nil {
+		return err
+	}
+	m.SavedImagePath = savedPath
+	return nil
+}
+
+// handleBatchKey handles keyboard input in batch mode.
+// While images are being processed, only esc is handled: it stops the batch,
+// and the image being generated is marked as failed so it can be retried.
+func (m *ImageGenModel) handleBatchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.IsGenerating || m.batchWaiting {
+		if msg.String() == "esc" {
+			m.stopBatch()
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "r":
+		// Retry failed images
+		retry := false
+		for i := range m.BatchItems {
+			if m.BatchItems[i].Status == BatchItemFailed {
+				m.BatchItems[i].Status = BatchItemPending
+				retry = true
+			}
+		}
+		if retry {
+			return m, m.startNextBatchItem()
+		}
+		return m, nil
+
+	case "enter", "esc", " ":
+		// Return nil to signal completion to the parent
+		return nil, nil
+	}
+	return m, nil
+}
+
+// stopBatch cancels the in-flight request and stops generating further images.
+// Images that were not started stay pending and are generated on retry.
+func (m *ImageGenModel) stopBatch() {
+	m.cancelGeneration()
+	if m.IsGenerating && m.batchIndex >= 0 && m.batchIndex < len(m.BatchItems) {
+		item := &m.BatchItems[m.batchIndex]
+		item.Status = BatchItemFailed
+		item.Error = "Canceled"
+	}
+	m.IsGenerating = false
+	m.batchWaiting = false
+}
+
+// formatAPIError converts an API error to a user-friendly message.
+func formatAPIError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	// Check if it's an image provider error
+	if apiErr, ok := err.(*imagegen.APIError); ok {
+		switch apiErr.Type {
+		case imagegen.ErrorTypeAuth:
+			return "Authentication failed: " + apiErr.Message
+		case imagegen.ErrorTypeRateLimit:
+			return fmt.Sprintf("Rate limit exceeded%s. Please wait a moment and try again.", formatRetries(apiErr))
+		case imagegen.ErrorTypeContentPolicy:
+			if categories := apiErr.Details.Categories(); len(categories) > 0 {
+				return fmt.Sprintf("The prompt was blocked by content policy (%s). Please try a different prompt.", strings.Join(categories, ", "))
+			}
+			return "The prompt was blocked by content policy. Please try a different prompt."
+		case imagegen.ErrorTypeInvalidRequest:
+			return "Invalid request. Please try a different prompt."
+		case imagegen.ErrorTypeNoImage:
+			return "No image was generated. Please try a different prompt."
+		case imagegen.ErrorTypeNetwork:
+			return "Network error. Please check your connection and try again."
+		case imagegen.ErrorTypeServer:
+			return fmt.Sprintf("Server error%s. Please try again later.", formatRetries(apiErr))
+		case imagegen.ErrorTypeInvalidImage:
+			return fmt.Sprintf("The generated image was invalid (%s). Please try again.", apiErr.Message)
+		}
+	}
+
+	// Errors saving the image, such as a full disk
+	if isSaveError(err) {
+		return formatSaveError(err)
+	}
+
+	// Generic error
+	return fmt.Sprintf("Failed to generate image: %v", err)
+}
+
+// formatRetries describes how often a request was retried, e.g. " (failed after 3 retries)".
+// It returns an empty string if the request was not retried.
+func formatRetries(apiErr *imagegen.APIError) string {
+	switch retries := apiErr.Retries(); retries {
+	case 0:
+		return ""
+	case 1:
+		return " (failed after 1 retry)"
+	default:
+		return fmt.Sprintf(" (failed after %d retries)", retries)
+	}
+}
+
+// View implements tea.Model.
+func (m *ImageGenModel) View() string {
+	if m.confirm != nil {
+		return m.confirm.View()
+	}
+
+	switch m.Step {
+	case ImageGenStepSlideSelect:
+		return m.viewSlideSelect()
+	case ImageGenStepImageSelect:
+		return m.viewImageSelect()
+	case ImageGenStepPrompt:
+		return m.viewPrompt()
+	case ImageGenStepPlacement:
+		return m.viewPlacement()
+	case ImageGenStepCached:
+		return m.viewCached()
+	case ImageGenStepGenerating:
+		return m.viewGenerating()
+	case ImageGenStepReview:
+		return m.viewReview()
+	case ImageGenStepCandidates:
+		return m.viewCandidates()
+	case ImageGenStepDone:
+		return m.viewDone()
+	case ImageGenStepBatch:
+		return m.viewBatch()
+	default:
+		return m.viewSlideSelect()
+	}
+}
+
+// viewSlideSelect renders the slide selection view.
+func (m *ImageGenModel) viewSlideSelect() string {
+	if m.search != nil {
+		return m.search.View()
+	}
+
+	var b strings.Builder
+
+	// Title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
 
 	b.WriteString(titleStyle.Render("🖼  Select Slide for Image"))
 	b.WriteString("\n\n")
 
+	// Images can't be saved, so generating one would waste the request
+	if m.WriteError != "" {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555"))
+		b.WriteString(errorStyle.Render("Error: " + m.WriteError))
+		b.WriteString("\n\n")
+	}
+
+	// Pending images that can be generated in one pass
+	pending := len(m.PendingImages())
+	if pending > 0 {
+		pendingStyle := lipgloss.NewStyle().
+			Foreground(ColorSecondary)
+		b.WriteString(pendingStyle.Render(fmt.Sprintf("%d pending image(s) without a file", pending)))
+		b.WriteString("\n\n")
+	}
+
 	// Slide list
 	for i, slide := range m.Slides {
 		slideNum := fmt.Sprintf("%2d.", slide.Index+1)
@@ -647,12 +1689,24 @@ func (m *ImageGenModel) viewSlideSelect() string {
 		Bold(true)
 
 	help := fmt.Sprintf(
-		"%s/%s navigate • %s select • %s cancel",
+		"%s/%s navigate • %s select • %s search • %s cancel",
 		keyStyle.Render("↑"),
 		keyStyle.Render("↓"),
 		keyStyle.Render("enter"),
+		keyStyle.Render("/"),
 		keyStyle.Render("esc"),
 	)
+	if pending > 0 {
+		help = fmt.Sprintf(
+			"%s/%s navigate • %s select • %s search • %s generate all pending • %s cancel",
+			keyStyle.Render("↑"),
+			keyStyle.Render("↓"),
+			keyStyle.Render("enter"),
+			keyStyle.Render("/"),
+			keyStyle.Render("g"),
+			keyStyle.Render("esc"),
+		)
+	}
 	b.WriteString(helpStyle.Render(help))
 
 	return b.String()
@@ -799,9 +1853,58 @@ func (m *ImageGenModel) viewPrompt() string {
 		b.WriteString("\n\n")
 	}
 
-	// Textarea
+	// Prompt, alt text, and caption fields; the focused field's label is highlighted
+	fieldLabel := func(field PromptField, label string) string {
+		style := lipgloss.NewStyle().Foreground(ColorMuted)
+		if m.PromptField == field {
+			style = lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+		}
+		return style.Render(label)
+	}
+	b.WriteString(fieldLabel(PromptFieldPrompt, "Prompt"))
+	b.WriteString("\n")
 	b.WriteString(m.promptInput.View())
 	b.WriteString("\n\n")
+	b.WriteString(fieldLabel(PromptFieldAltText, "Alt text"))
+	b.WriteString("\n")
+	b.WriteString(m.altInput.View())
+	b.WriteString("\n")
+	b.WriteString(fieldLabel(PromptFieldCaption, "Caption"))
+	b.WriteString("\n")
+	b.WriteString(m.captionInput.View())
+	b.WriteString("\n\n")
+
+	// Speaker notes toggle
+	checkbox := "[ ]"
+	if m.SaveToNotes {
+		checkbox = "[x]"
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(ColorWhite).Render(checkbox + " Save prompt to speaker notes"))
+	b.WriteString("\n")
+
+	// Reference toggle, when regenerating
+	if m.SelectedImage != nil {
+		reference := "[ ]"
+		if m.UseReference {
+			reference = "[x]"
+		}
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorWhite).Render(reference + " Use existing image as reference"))
+		b.WriteString("\n")
+	}
+
+	// Image options
+	size := m.ImageSize
+	if size == "" {
+		size = "default"
+	}
+	b.WriteString(lipgloss.NewStyle().Foreground(ColorWhite).Render(
+		fmt.Sprintf("Aspect ratio: %s • Size: %s • Images: %d", m.AspectRatio, size, m.CandidateCount)))
+	b.WriteString("\n")
+	if history := m.viewPromptHistory(); history != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render(history))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	// Help text
 	helpStyle := lipgloss.NewStyle().
@@ -812,9 +1915,88 @@ func (m *ImageGenModel) viewPrompt() string {
 		Bold(true)
 
 	help := fmt.Sprintf(
-		"%s submit • %s submit • %s back",
+		"%s submit • %s submit • %s next field • %s notes • %s ratio • %s size • %s images",
 		keyStyle.Render("enter"),
 		keyStyle.Render("ctrl+d"),
+		keyStyle.Render("tab"),
+		keyStyle.Render("ctrl+t"),
+		keyStyle.Render("ctrl+r"),
+		keyStyle.Render("ctrl+s"),
+		keyStyle.Render("ctrl+x"),
+	)
+	if m.history != nil {
+		help += fmt.Sprintf(" • %s history • %s all decks", keyStyle.Render("ctrl+p/n"), keyStyle.Render("ctrl+g"))
+	}
+	if m.SelectedImage != nil {
+		help += fmt.Sprintf(" • %s reference", keyStyle.Render("ctrl+o"))
+	}
+	help += fmt.Sprintf(" • %s back", keyStyle.Render("esc"))
+	b.WriteString(helpStyle.Render(help))
+
+	return b.String()
+}
+
+// viewPlacement renders the placement selection view for a new image.
+func (m *ImageGenModel) viewPlacement() string {
+	var b strings.Builder
+
+	// Title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
+
+	b.WriteString(titleStyle.Render("🖼  Choose Image Position"))
+	b.WriteString("\n\n")
+
+	// Show selected slide info
+	slide := m.GetSelectedSlide()
+	if slide != nil {
+		slideInfoStyle := lipgloss.NewStyle().
+			Foreground(ColorMuted).
+			Italic(true)
+		b.WriteString(slideInfoStyle.Render(fmt.Sprintf("Slide %d: %s", slide.Index+1, slide.Title)))
+		b.WriteString("\n\n")
+	}
+
+	// Option list
+	for i, placement := range m.PlacementOptions {
+		if i == m.PlacementIndex {
+			// Selected item
+			selectedStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(ColorSecondary)
+			indicatorStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(ColorPrimary)
+
+			b.WriteString(indicatorStyle.Render("> "))
+			b.WriteString(selectedStyle.Render(placement.Label()))
+		} else {
+			// Unselected item
+			unselectedStyle := lipgloss.NewStyle().
+				Foreground(ColorWhite)
+
+			b.WriteString("  ")
+			b.WriteString(unselectedStyle.Render(placement.Label()))
+		}
+		b.WriteString("\n")
+	}
+
+	// Help text
+	b.WriteString("\n")
+	helpStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	help := fmt.Sprintf(
+		"%s/%s navigate • %s generate • %s back",
+		keyStyle.Render("↑"),
+		keyStyle.Render("↓"),
+		keyStyle.Render("enter"),
 		keyStyle.Render("esc"),
 	)
 	b.WriteString(helpStyle.Render(help))
@@ -861,6 +2043,14 @@ func (m *ImageGenModel) viewGenerating() string {
 	b.WriteString(promptValueStyle.Render(displayPrompt))
 	b.WriteString("\n\n")
 
+	// Show why the existing image is not used as a reference
+	if m.ReferenceWarning != "" {
+		warningStyle := lipgloss.NewStyle().
+			Foreground(ColorWarning)
+		b.WriteString(warningStyle.Render("Warning: " + m.ReferenceWarning))
+		b.WriteString("\n\n")
+	}
+
 	// Show error or progress
 	if m.Error != "" {
 		// Show error with retry/cancel options
@@ -889,18 +2079,98 @@ func (m *ImageGenModel) viewGenerating() string {
 			Foreground(ColorSecondary)
 		b.WriteString(m.spinner.View())
 		b.WriteString(" ")
-		b.WriteString(progressStyle.Render("Generating image..."))
+		progress := "Generating image..."
+		if m.ReferenceUsed {
+			progress = "Generating image from the existing image..."
+		}
+		if m.CandidateCount > 1 {
+			progress = m.candidateProgress()
+		}
+		b.WriteString(progressStyle.Render(progress))
 		b.WriteString("\n\n")
 
 		// Help text while generating
 		helpStyle := lipgloss.NewStyle().
 			Foreground(ColorMuted)
+		keyStyle := lipgloss.NewStyle().
+			Foreground(ColorPrimary).
+			Bold(true)
 		b.WriteString(helpStyle.Render("Please wait, this may take a moment..."))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render(fmt.Sprintf("%s cancel", keyStyle.Render("esc"))))
 	}
 
 	return b.String()
 }
 
+// viewReview renders the generated image preview with accept, regenerate, and edit actions.
+func (m *ImageGenModel) viewReview() string {
+	var b strings.Builder
+
+	// Title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
+
+	b.WriteString(titleStyle.Render("🖼  Review Generated Image"))
+	b.WriteString("\n\n")
+
+	// Show selected slide info
+	slide := m.GetSelectedSlide()
+	if slide != nil {
+		slideInfoStyle := lipgloss.NewStyle().
+			Foreground(ColorMuted).
+			Italic(true)
+		b.WriteString(slideInfoStyle.Render(fmt.Sprintf("Slide %d: %s", slide.Index+1, slide.Title)))
+		b.WriteString("\n\n")
+	}
+
+	// Show the preview, or why there is none
+	if m.preview != "" {
+		b.WriteString(m.preview)
+	} else {
+		mutedStyle := lipgloss.NewStyle().
+			Foreground(ColorMuted)
+		b.WriteString(mutedStyle.Render("Preview not available: " + m.previewErr))
+	}
+	b.WriteString("\n\n")
+	if m.FromCache {
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorMuted).Render("From the image cache; press r to generate a new one"))
+		b.WriteString("\n\n")
+	}
+
+	// Help text
+	helpStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	help := fmt.Sprintf(
+		"%s accept • %s regenerate • %s edit prompt",
+		keyStyle.Render("enter"),
+		keyStyle.Render("r"),
+		keyStyle.Render("e"),
+	)
+	if m.saveFailed {
+		errorStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#ff5555"))
+		b.WriteString(errorStyle.Render("Error: " + m.Error))
+		b.WriteString("\n\n")
+
+		help = fmt.Sprintf(
+			"%s retry saving • %s discard and edit prompt",
+			keyStyle.Render("r"),
+			keyStyle.Render("e"),
+		)
+	}
+	b.WriteString(helpStyle.Render(help))
+
+	return b.String()
+}
+
 // viewDone renders the completion view with success message.
 func (m *ImageGenModel) viewDone() string {
 	var b strings.Builder
@@ -941,10 +2211,47 @@ func (m *ImageGenModel) viewDone() string {
 		Foreground(ColorMuted)
 	if m.SelectedImage != nil {
 		b.WriteString(actionStyle.Render("(Regenerated existing image)"))
+		b.WriteString("\n")
+		switch {
+		case m.ReferenceUsed:
+			b.WriteString(actionStyle.Render("(Used existing image as reference)"))
+		case m.ReferenceWarning != "":
+			b.WriteString(lipgloss.NewStyle().Foreground(ColorWarning).Render("(" + m.ReferenceWarning + ")"))
+		default:
+			b.WriteString(actionStyle.Render("(Generated from the prompt only)"))
+		}
 	} else {
 		b.WriteString(actionStyle.Render("(Added new image to slide)"))
 	}
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if m.ChosenCandidate > 0 {
+		b.WriteString(actionStyle.Render(fmt.Sprintf("(Chose image %d of %d; the others were discarded)", m.ChosenCandidate, m.CandidateCount)))
+		b.WriteString("\n")
+	}
+	if m.SaveToNotes {
+		b.WriteString(actionStyle.Render("(Prompt saved to speaker notes)"))
+		b.WriteString("\n")
+	}
+	if m.Undone() {
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorWarning).Render("(Change undone: markdown and previous image restored)"))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	// Show the fit of the image in its space, which f changes
+	if m.SavedImagePath != "" && !m.Undone() {
+		fit := m.imageFit()
+		if fit == "" {
+			fit = "layout default"
+		}
+		b.WriteString(actionStyle.Render("Fit: "))
+		b.WriteString(lipgloss.NewStyle().Foreground(ColorSecondary).Render(fit))
+		b.WriteString("\n\n")
+	}
+	if m.fitError != "" {
+		b.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("#ff5555")).Render("Error: " + m.fitError))
+		b.WriteString("\n\n")
+	}
 
 	// Help text
 	helpStyle := lipgloss.NewStyle().
@@ -959,11 +2266,138 @@ func (m *ImageGenModel) viewDone() string {
 		keyStyle.Render("enter"),
 		keyStyle.Render("esc"),
 	)
+	if m.SavedImagePath != "" && !m.Undone() {
+		help += fmt.Sprintf(" • %s fit (cover/contain/original)", keyStyle.Render("f"))
+	}
+	if m.CanUndo() {
+		help += fmt.Sprintf(" • %s undo last change", keyStyle.Render("u"))
+	}
 	b.WriteString(helpStyle.Render(help))
 
 	return b.String()
 }
 
+// viewBatch renders the batch generation view with the status of each pending image.
+func (m *ImageGenModel) viewBatch() string {
+	var b strings.Builder
+
+	finished := !m.IsGenerating && !m.batchWaiting
+	generated, failed := 0, 0
+	for _, item := range m.BatchItems {
+		switch item.Status {
+		case BatchItemDone:
+			generated++
+		case BatchItemFailed:
+			failed++
+		}
+	}
+
+	// Title
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
+
+	if finished {
+		b.WriteString(titleStyle.Render("🖼  Pending Images Generated"))
+	} else {
+		b.WriteString(titleStyle.Render(fmt.Sprintf("🖼  Generating Pending Images (%d/%d)", generated+failed+1, len(m.BatchItems))))
+	}
+	b.WriteString("\n\n")
+
+	// Item list
+	slideStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+	promptStyle := lipgloss.NewStyle().
+		Foreground(ColorWhite)
+	doneStyle := lipgloss.NewStyle().
+		Foreground(ColorSecondary)
+	errorStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("#ff5555"))
+
+	for _, item := range m.BatchItems {
+		var status string
+		switch item.Status {
+		case BatchItemPending:
+			status = slideStyle.Render("·")
+		case BatchItemGenerating:
+			if m.batchWaiting {
+				status = slideStyle.Render("·")
+			} else {
+				status = m.spinner.View()
+			}
+		case BatchItemDone:
+			status = doneStyle.Render("✓")
+		case BatchItemFailed:
+			status = errorStyle.Render("✗")
+		}
+
+		// Truncate long prompts for display
+		displayPrompt := item.Image.Prompt
+		if len(displayPrompt) > 50 {
+			displayPrompt = displayPrompt[:47] + "..."
+		}
+
+		b.WriteString(status)
+		b.WriteString(" ")
+		b.WriteString(slideStyle.Render(fmt.Sprintf("Slide %d:", item.SlideIndex+1)))
+		b.WriteString(" ")
+		b.WriteString(promptStyle.Render(displayPrompt))
+		b.WriteString("\n")
+		if item.Status == BatchItemDone {
+			saved := "    → " + item.SavedImagePath
+			if item.Cached {
+				saved += " (from cache)"
+			}
+			b.WriteString(slideStyle.Render(saved))
+			b.WriteString("\n")
+		}
+		if item.Status == BatchItemFailed {
+			b.WriteString(errorStyle.Render("    " + item.Error))
+			b.WriteString("\n")
+		}
+	}
+	b.WriteString("\n")
+
+	// Help text
+	helpStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	switch {
+	case m.batchWaiting:
+		b.WriteString(helpStyle.Render(fmt.Sprintf("Rate limited, waiting %s before the next image...", m.BatchBackoff)))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render(fmt.Sprintf("%s stop", keyStyle.Render("esc"))))
+	case !finished:
+		b.WriteString(helpStyle.Render("Please wait, this may take a moment..."))
+		b.WriteString("\n\n")
+		b.WriteString(helpStyle.Render(fmt.Sprintf("%s stop", keyStyle.Render("esc"))))
+	default:
+		b.WriteString(promptStyle.Render(fmt.Sprintf("%d generated, %d failed", generated, failed)))
+		b.WriteString("\n\n")
+		help := fmt.Sprintf(
+			"Press %s or %s to continue",
+			keyStyle.Render("enter"),
+			keyStyle.Render("esc"),
+		)
+		if failed > 0 {
+			help = fmt.Sprintf(
+				"%s retry failed • %s/%s continue",
+				keyStyle.Render("r"),
+				keyStyle.Render("enter"),
+				keyStyle.Render("esc"),
+			)
+		}
+		b.WriteString(helpStyle.Render(help))
+	}
+
+	return b.String()
+}
+
 // GetSelectedSlide returns the currently selected slide info.
 func (m *ImageGenModel) GetSelectedSlide() *SlideInfo {
 	if m.SelectedIndex >= 0 && m.SelectedIndex < len(m.Slides) {
@@ -1038,11 +2472,79 @@ func GetExtensionFromContentType(contentType string) string {
 		return "gif"
 	case "image/webp":
 		return "webp"
+	case "image/svg+xml":
+		return "svg"
 	default:
 		return "png"
 	}
 }
 
+// DefaultMaxImageSize is the default maximum size of a generated image (10MB).
+const DefaultMaxImageSize = 10 << 20
+
+// SniffImageType returns the MIME type of image data based on its magic number,
+// or an empty string if the data is not a PNG, JPEG, GIF, WebP, or SVG image.
+func SniffImageType(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "image/png"
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "image/jpeg"
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "image/gif"
+	case len(data) >= 12 && bytes.Equal(data[:4], []byte("RIFF")) && bytes.Equal(data[8:12], []byte("WEBP")):
+		return "image/webp"
+	}
+
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("<svg")) ||
+		(bytes.HasPrefix(trimmed, []byte("<?xml")) && bytes.Contains(trimmed, []byte("<svg"))) {
+		return "image/svg+xml"
+	}
+	return ""
+}
+
+// ValidateImageData checks that image data is a complete PNG, JPEG, GIF, WebP,
+// or SVG image no larger than maxSize bytes (no limit if maxSize <= 0).
+// It returns the sniffed MIME type, which takes precedence over the type
+// declared by the API. Validation failures are returned as retryable
+// imagegen.APIError values of type imagegen.ErrorTypeInvalidImage.
+func ValidateImageData(data []byte, maxSize int64) (string, error) {
+	invalid := func(format string, args ...any) error {
+		return &imagegen.APIError{Type: imagegen.ErrorTypeInvalidImage, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if len(data) == 0 {
+		return "", invalid("image is empty")
+	}
+	if maxSize > 0 && int64(len(data)) > maxSize {
+		return "", invalid("image is %d bytes, exceeding the %d byte limit", len(data), maxSize)
+	}
+
+	contentType := SniffImageType(data)
+	truncated := false
+	switch contentType {
+	case "":
+		return "", invalid("data is not a PNG, JPEG, GIF, WebP, or SVG image")
+	case "image/png":
+		truncated = !bytes.HasSuffix(data, []byte("IEND\xaeB`\x82"))
+	case "image/jpeg":
+		truncated = !bytes.HasSuffix(data, []byte{0xFF, 0xD9})
+	case "image/gif":
+		truncated = !bytes.HasSuffix(data, []byte{0x3B})
+	case "image/webp":
+		riffSize := int64(binary.LittleEndian.Uint32(data[4:8]))
+		truncated = int64(len(data)) < riffSize+8
+	case "image/svg+xml":
+		truncated = !bytes.Contains(data, []byte("</svg>"))
+	}
+	if truncated {
+		return "", invalid("%s image is truncated", GetExtensionFromContentType(contentType))
+	}
+
+	return contentType, nil
+}
+
 // SaveGeneratedImage saves the generated image to the images directory.
 // It returns the relative path to the saved image (e.g., "images/generated-a1b2c3d4.png").
 func (m *ImageGenModel) SaveGeneratedImage() (string, error) {
@@ -1056,8 +2558,14 @@ func (m *ImageGenModel) SaveGeneratedImage() (string, error) {
 		return "", fmt.Errorf("failed to ensure images directory: %w", err)
 	}
 
+	// Validate the image, preferring the sniffed content type for the extension
+	contentType, err := ValidateImageData(m.GeneratedImage.ImageData, m.MaxImageSize)
+	if err != nil {
+		return "", fmt.Errorf("failed to validate image: %w", err)
+	}
+
 	// Generate filename
-	filename := GenerateImageFilename(m.GeneratedImage.ImageData, m.GeneratedImage.ContentType)
+	filename := GenerateImageFilename(m.GeneratedImage.ImageData, contentType)
 
 	// Full path for saving
 	fullPath := filepath.Join(imagesDir, filename)
@@ -1068,47 +2576,107 @@ func (m *ImageGenModel) SaveGeneratedImage() (string, error) {
 	}
 
 	// Return relative path (images/filename)
-	relativePath := filepath.Join("images", filename)
-	return relativePath, nil
+	savedPath := toMarkdownPath(filepath.Join("images", filename))
+	m.imageSaved(savedPath)
+	return savedPath, nil
+}
+
+// toMarkdownPath converts an image path to the form written into markdown.
+// Markdown and browsers expect forward slashes, so Windows separators are
+// replaced. Remote URLs are returned unchanged.
+func toMarkdownPath(path string) string {
+	if imagereport.IsRemote(path) {
+		return path
+	}
+	return strings.ReplaceAll(path, `\`, "/")
 }
 
 // InsertImageIntoMarkdown inserts an AI-generated image into the markdown file
-// at the end of the selected slide's content (before the next --- separator).
-// The image is inserted with the format: <!-- ai-prompt: {prompt} -->\n![](imagePath)
+// at the chosen placement in the selected slide, by default at the end of the
+// slide's content (before the next --- separator).
+// The image is inserted with the format: <!-- ai-prompt: {prompt} -->\n![{alt}](imagePath),
+// followed by the caption in italics on the next line if there is one.
+// If SaveToNotes is set, the prompt is also appended to the slide's speaker notes.
 func (m *ImageGenModel) InsertImageIntoMarkdown(imagePath string) error {
-	// Read the current markdown content
-	content, err := os.ReadFile(m.MarkdownFile)
+	// Find the file containing the slide (it may be an included file), and
+	// the slide again if the file was edited since the slides were loaded
+	file, slideIndex, content, err := m.writeTarget()
 	if err != nil {
-		return fmt.Errorf("failed to read markdown file: %w", err)
+		return fmt.Errorf("failed to insert image: %w", err)
 	}
 
 	// Insert the image into the content
-	newContent, err := insertImageIntoSlide(string(content), m.SelectedIndex, m.Prompt, imagePath)
+	newContent, err := insertImageIntoSlide(string(content), slideIndex, m.strictDelimiters, m.imageMarkdown(imagePath), m.Placement)
 	if err != nil {
 		return fmt.Errorf("failed to insert image: %w", err)
 	}
 
-	// Write the updated content back to the file
-	if err := os.WriteFile(m.MarkdownFile, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write markdown file: %w", err)
+	if m.SaveToNotes {
+		newContent, err = appendNoteToSlide(newContent, slideIndex, m.strictDelimiters, m.Prompt)
+		if err != nil {
+			return fmt.Errorf("failed to save prompt to notes: %w", err)
+		}
 	}
 
+	// Write the updated content back to the file
+	if err := m.writeMarkdown(file, slideIndex, []byte(newContent)); err != nil {
+		return err
+	}
+	m.emitEvent("reload", fmt.Sprintf("Inserted image %s on slide %d", imagePath, m.slideNumber()))
 	return nil
 }
 
+// oldImagePath returns the path of the image file being regenerated, resolved
+// relative to the markdown file's directory. It returns an empty string when
+// not regenerating or when the image is remote.
+func (m *ImageGenModel) oldImagePath() string {
+	if m.SelectedImage == nil || imagereport.IsRemote(m.SelectedImage.ImagePath) {
+		return ""
+	}
+	mdDir := filepath.Dir(m.MarkdownFile)
+	return filepath.Join(mdDir, filepath.FromSlash(m.SelectedImage.ImagePath))
+}
+
+// oldImageExists reports whether the image being regenerated exists on disk.
+func (m *ImageGenModel) oldImageExists() bool {
+	path := m.oldImagePath()
+	if path == "" {
+		return false
+	}
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+// readReferenceImage reads the image being regenerated so it can be sent as a
+// reference, and returns its data and MIME type.
+func (m *ImageGenModel) readReferenceImage() ([]byte, string, error) {
+	path := m.oldImagePath()
+	if path == "" {
+		return nil, "", fmt.Errorf("image is not a local file")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read existing image: %w", err)
+	}
+
+	// SVG images can't be sent to the API
+	contentType := SniffImageType(data)
+	if contentType == "" || contentType == "image/svg+xml" {
+		return nil, "", fmt.Errorf("%s is not a PNG, JPEG, GIF, or WebP image", m.SelectedImage.ImagePath)
+	}
+	return data, contentType, nil
+}
+
 // DeleteOldImage deletes the old image file when regenerating.
 // It resolves the image path relative to the markdown file's directory.
+// Remote images are left alone.
 func (m *ImageGenModel) DeleteOldImage() error {
-	if m.SelectedImage == nil {
-		return nil // Nothing to delete, not regenerating
+	fullPath := m.oldImagePath()
+	if fullPath == "" {
+		return nil // Nothing to delete, not regenerating or remote
 	}
 
-	oldImagePath := m.SelectedImage.ImagePath
-
-	// Resolve the path relative to the markdown file's directory
-	mdDir := filepath.Dir(m.MarkdownFile)
-	fullPath := filepath.Join(mdDir, oldImagePath)
-
 	// Check if the file exists
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
 		// File doesn't exist, nothing to delete
@@ -1120,6 +2688,7 @@ func (m *ImageGenModel) DeleteOldImage() error {
 		return fmt.Errorf("failed to delete old image: %w", err)
 	}
 
+	m.emitEvent("action", fmt.Sprintf("Deleted old image: %s", m.SelectedImage.ImagePath))
 	return nil
 }
 
@@ -1131,113 +2700,178 @@ func (m *ImageGenModel) ReplaceImageInMarkdown(newImagePath string) error {
 		return fmt.Errorf("no selected image to replace")
 	}
 
-	// Read the current markdown content
-	content, err := os.ReadFile(m.MarkdownFile)
+	// Find the file containing the slide (it may be an included file), and
+	// the slide again if the file was edited since the slides were loaded
+	file, slideIndex, content, err := m.writeTarget()
 	if err != nil {
-		return fmt.Errorf("failed to read markdown file: %w", err)
+		return fmt.Errorf("failed to replace image: %w", err)
 	}
 
-	// Replace the image in the content
-	newContent, err := replaceImageInContent(string(content), m.SelectedImage.Prompt, m.SelectedImage.ImagePath, m.Prompt, newImagePath)
+	// Replace the image in the slide, leaving the rest of the file as it is
+	newContent, err := updateSlide(string(content), slideIndex, m.strictDelimiters, func(slideContent string) (string, error) {
+		return replaceImageInContent(slideContent, m.SelectedImage.Prompt, m.SelectedImage.ImagePath, m.imageMarkdown(newImagePath))
+	})
 	if err != nil {
 		return fmt.Errorf("failed to replace image: %w", err)
 	}
 
-	// Write the updated content back to the file
-	if err := os.WriteFile(m.MarkdownFile, []byte(newContent), 0644); err != nil {
-		return fmt.Errorf("failed to write markdown file: %w", err)
+	if m.SaveToNotes {
+		newContent, err = appendNoteToSlide(newContent, slideIndex, m.strictDelimiters, m.Prompt)
+		if err != nil {
+			return fmt.Errorf("failed to save prompt to notes: %w", err)
+		}
 	}
 
+	// Write the updated content back to the file
+	if err := m.writeMarkdown(file, slideIndex, []byte(newContent)); err != nil {
+		return err
+	}
+	m.emitEvent("reload", fmt.Sprintf("Replaced image %s with %s on slide %d", m.SelectedImage.ImagePath, newImagePath, m.slideNumber()))
 	return nil
 }
 
 // replaceImageInContent replaces an existing AI image reference in markdown content.
-// It finds the old prompt comment + image and replaces it with the new one.
-func replaceImageInContent(content string, oldPrompt string, oldImagePath string, newPrompt string, newImagePath string) (string, error) {
-	// Build the old pattern to find: <!-- ai-prompt: {oldPrompt} -->\n![](oldImagePath)
+// It finds the old prompt comment (with any image options) + image, with any
+// alt text, img comment, and caption, and replaces it with the new one.
+func replaceImageInContent(content string, oldPrompt string, oldImagePath string, image aiImageMarkdown) (string, error) {
+	// Build the old pattern to find: <!-- ai-prompt: {oldPrompt} -->\n![{alt}](oldImagePath)
 	// We need to escape special regex characters in the prompt and path
 	escapedOldPrompt := regexp.QuoteMeta(oldPrompt)
 	escapedOldPath := regexp.QuoteMeta(oldImagePath)
 
 	// Match the comment followed by the image (with possible leading whitespace on the image line)
-	patternStr := fmt.Sprintf(`<!--\s*ai-prompt:\s*%s\s*-->\n[ \t]*!\[\]\(%s\)`, escapedOldPrompt, escapedOldPath)
+	// and the img comment and caption line in italics, if any
+	patternStr := fmt.Sprintf(`(?m)<!--\s*ai-prompt:\s*%s(?:\s*\|[^\n]*?)?\s*-->\n[ \t]*!\[[^\]\n]*\]\(%s\)(?:\n[ \t]*<!--\s*img:[^\n]*?-->[ \t]*$)?(?:\n[ \t]*\*[^*\n]+\*[ \t]*$)?`, escapedOldPrompt, escapedOldPath)
 	pattern, err := regexp.Compile(patternStr)
 	if err != nil {
 		return "", fmt.Errorf("failed to compile replacement pattern: %w", err)
 	}
 
-	// Check if the pattern exists in the content
-	if !pattern.MatchString(content) {
+	// Find the first reference and splice the new one in its place
+	loc := pattern.FindStringIndex(content)
+	if loc == nil {
 		return "", fmt.Errorf("could not find the existing image reference to replace")
 	}
 
-	// Build the new markdown
-	newMarkdown := fmt.Sprintf("<!-- ai-prompt: %s -->\n![](%s)", newPrompt, newImagePath)
-
-	// Replace the old with the new
-	newContent := pattern.ReplaceAllString(content, newMarkdown)
-
-	return newContent, nil
+	return content[:loc[0]] + image.String() + content[loc[1]:], nil
 }
 
 // insertImageIntoSlide inserts an image reference into a specific slide in markdown content.
-// It returns the modified content with the image inserted at the end of the specified slide.
-func insertImageIntoSlide(content string, slideIndex int, prompt string, imagePath string) (string, error) {
-	// Build the image markdown to insert
-	imageMarkdown := fmt.Sprintf("<!-- ai-prompt: %s -->\n![](%s)", prompt, imagePath)
+// It returns the modified content with the image inserted at placement in the specified slide.
+func insertImageIntoSlide(content string, slideIndex int, strict bool, image aiImageMarkdown, placement ImagePlacement) (string, error) {
+	return updateSlide(content, slideIndex, strict, func(slideContent string) (string, error) {
+		return insertAtPlacement(slideContent, image.String(), placement), nil
+	})
+}
 
-	// Check if content has frontmatter
-	hasFrontmatter := false
-	frontmatter := ""
-	contentAfterFrontmatter := content
+// updateSlide applies fn to the raw content of a specific slide and returns the
+// full markdown content with the slide replaced. The slide is spliced in at
+// its byte offsets, so frontmatter, delimiters, and the remaining slides are
+// left byte for byte as they were. fn sees the slide with LF line endings;
+// CRLF line endings and the whitespace before the next delimiter are restored
+// afterwards.
+func updateSlide(content string, slideIndex int, strict bool, fn func(slideContent string) (string, error)) (string, error) {
+	frontmatter := imagereport.Frontmatter(content)
+	spans := parser.SlideSpans(content[len(frontmatter):], strict)
 
-	if frontmatterRe.MatchString(content) {
-		hasFrontmatter = true
-		match := frontmatterRe.FindString(content)
-		frontmatter = match
-		contentAfterFrontmatter = content[len(match):]
+	// Check if slideIndex is valid
+	if slideIndex < 0 || slideIndex >= len(spans) {
+		return "", fmt.Errorf("invalid slide index: %d (have %d slides)", slideIndex, len(spans))
 	}
 
-	// Split the content (after frontmatter) by slide delimiter, preserving code blocks
-	parts := parser.SplitSlidesPreservingCodeBlocks(contentAfterFrontmatter)
+	start := len(frontmatter) + spans[slideIndex].Start
+	end := len(frontmatter) + spans[slideIndex].End
+	slide := content[start:end]
 
-	// Find non-empty slide indices (matching parseSlides behavior)
-	slidePartIndices := []int{}
-	for i, part := range parts {
-		if strings.TrimSpace(part) != "" {
-			slidePartIndices = append(slidePartIndices, i)
-		}
+	crlf := strings.Contains(slide, "\r\n")
+	if crlf {
+		slide = strings.ReplaceAll(slide, "\r\n", "\n")
 	}
+	trailing := slide[len(strings.TrimRight(slide, " \t\n")):]
 
-	// Check if slideIndex is valid
-	if slideIndex < 0 || slideIndex >= len(slidePartIndices) {
-		return "", fmt.Errorf("invalid slide index: %d (have %d slides)", slideIndex, len(slidePartIndices))
+	updated, err := fn(slide)
+	if err != nil {
+		return "", err
+	}
+	updated = strings.TrimRight(updated, " \t\n") + trailing
+	if crlf {
+		updated = strings.ReplaceAll(updated, "\n", "\r\n")
 	}
 
-	// Get the actual part index for this slide
-	partIndex := slidePartIndices[slideIndex]
-
-	// Insert the image at the end of the slide's content
-	slideContent := parts[partIndex]
+	return content[:start] + updated + content[end:], nil
+}
 
-	// Trim trailing whitespace but preserve structure
-	trimmedSlide := strings.TrimRight(slideContent, " \t\n")
+// directiveCommentRe matches a directive comment at the start of a slide.
+// Group 1: leading whitespace, Group 2: the YAML content of the comment.
+var directiveCommentRe = regexp.MustCompile(`(?s)^(\s*)<!--\s*(.*?)\s*-->`)
 
-	// Add the image with proper newlines
-	parts[partIndex] = trimmedSlide + "\n\n" + imageMarkdown + "\n"
+// appendNoteToSlide appends a note to the speaker notes of a specific slide.
+func appendNoteToSlide(content string, slideIndex int, strict bool, note string) (string, error) {
+	return updateSlide(content, slideIndex, strict, func(slideContent string) (string, error) {
+		return appendNoteToDirectives(slideContent, note)
+	})
+}
 
-	// Rebuild the content with separators
-	var result strings.Builder
-	if hasFrontmatter {
-		result.WriteString(frontmatter)
+// appendNoteToDirectives appends a note to the notes directive of a single slide.
+// If the slide starts with a directive comment, its notes are extended (or a notes
+// key is added). Otherwise a new directive comment is created at the top of the slide.
+func appendNoteToDirectives(slideContent string, note string) (string, error) {
+	leading := slideContent[:len(slideContent)-len(strings.TrimLeft(slideContent, " \t\n"))]
+	body := slideContent[len(leading):]
+	rest := body
+	mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+
+	if match := directiveCommentRe.FindStringSubmatch(slideContent); match != nil {
+		var doc yaml.Node
+		// Only treat the comment as directives if it is a YAML mapping.
+		// ai-prompt comments are mappings too, but belong to an image.
+		if err := yaml.Unmarshal([]byte(match[2]), &doc); err == nil &&
+			len(doc.Content) == 1 && doc.Content[0].Kind == yaml.MappingNode &&
+			mappingValue(doc.Content[0], "ai-prompt") == nil {
+			mapping = doc.Content[0]
+			rest = slideContent[len(match[0]):]
+		}
 	}
 
-	for i, part := range parts {
-		result.WriteString(part)
-		if i < len(parts)-1 {
-			result.WriteString("---\n")
+	if notes := mappingValue(mapping, "notes"); notes != nil {
+		existing := strings.TrimRight(notes.Value, "\n")
+		if existing != "" {
+			note = existing + "\n" + note
 		}
+		notes.Value = note
+		notes.Tag = "!!str"
+		notes.Style = yaml.LiteralStyle
+	} else {
+		mapping.Content = append(mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "notes"},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: note, Style: yaml.LiteralStyle},
+		)
+	}
+
+	var buf strings.Builder
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(mapping); err != nil {
+		return "", fmt.Errorf("failed to encode slide directives: %w", err)
 	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode slide directives: %w", err)
+	}
+
+	comment := "<!--\n" + buf.String() + "-->"
+	if rest == body {
+		// New directive comment: keep the slide content on its own line
+		return leading + comment + "\n\n" + body, nil
+	}
+	return leading + comment + rest, nil
+}
 
-	return result.String(), nil
+// mappingValue returns the value node for key in a YAML mapping node, or nil.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
 }
@@ -1,8 +1,11 @@
 package tui
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,7 +13,11 @@ import (
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/MiniCodeMonkey/tap/internal/config"
 	"github.com/MiniCodeMonkey/tap/internal/gemini"
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+	"github.com/MiniCodeMonkey/tap/internal/imagereport"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
 )
 
 func TestParseSlides(t *testing.T) {
@@ -78,7 +85,7 @@ func TestParseSlides(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			slides := parseSlides(tt.content)
+			slides := parseSlides(tt.content, false)
 
 			if len(slides) != tt.expectedCount {
 				t.Errorf("expected %d slides, got %d", tt.expectedCount, len(slides))
@@ -214,6 +221,69 @@ func TestNewImageGenModel_FileNotFound(t *testing.T) {
 	}
 }
 
+func TestImageGenModel_SlidesMatchParser(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		titles  []string
+	}{
+		{
+			name:    "setext heading",
+			content: "Intro\n---\n\nText\n\n---\n\n# Second",
+			titles:  []string{"Intro", "Second"},
+		},
+		{
+			name:    "strict delimiters",
+			content: "---\nstrictDelimiters: true\n---\n\n# First\n---\nBelow the rule\n\n---\n\n# Second",
+			titles:  []string{"First", "Second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mdFile := filepath.Join(t.TempDir(), "talk.md")
+			if err := os.WriteFile(mdFile, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			model, err := NewImageGenModel(mdFile)
+			if err != nil {
+				t.Fatalf("failed to create model: %v", err)
+			}
+			cfg, err := config.Load(mdFile, nil, config.Overrides{})
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+			pres, err := parser.NewWithOptions(parser.Options{StrictDelimiters: cfg.StrictDelimiters}).ParseFile(mdFile)
+			if err != nil {
+				t.Fatalf("failed to parse: %v", err)
+			}
+
+			if len(model.Slides) != len(pres.Slides) {
+				t.Fatalf("expected %d slides like the parser, got %d", len(pres.Slides), len(model.Slides))
+			}
+			for i, title := range tt.titles {
+				if model.Slides[i].Title != title {
+					t.Errorf("slide %d: expected title %q, got %q", i, title, model.Slides[i].Title)
+				}
+			}
+
+			// The image goes into the last slide, not into the middle of the first
+			model.SelectedIndex = len(model.Slides) - 1
+			if err := model.InsertImageIntoMarkdown("images/cat.png"); err != nil {
+				t.Fatalf("failed to insert image: %v", err)
+			}
+			data, err := os.ReadFile(mdFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasSuffix(strings.TrimSpace(string(data)), "![](images/cat.png)") {
+				t.Errorf("expected the image at the end of the last slide, got:\n%s", data)
+			}
+		})
+	}
+}
+
 func TestImageGenModel_Navigation(t *testing.T) {
 	// Create a temporary markdown file with 5 slides
 	tmpDir := t.TempDir()
@@ -609,7 +679,7 @@ Some text
 ![](images/two.png)
 `
 
-	slides := parseSlides(content)
+	slides := parseSlides(content, false)
 
 	if len(slides) != 3 {
 		t.Fatalf("expected 3 slides, got %d", len(slides))
@@ -1369,6 +1439,13 @@ Content
 	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
 	m = newModel.(*ImageGenModel)
 
+	// Confirm the default placement
+	if m.Step != ImageGenStepPlacement {
+		t.Fatalf("expected ImageGenStepPlacement, got %d", m.Step)
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(*ImageGenModel)
+
 	// Should be in generating step
 	if m.Step != ImageGenStepGenerating {
 		t.Errorf("expected ImageGenStepGenerating, got %d", m.Step)
@@ -1410,6 +1487,13 @@ Content
 	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlD})
 	m = newModel.(*ImageGenModel)
 
+	// Confirm the default placement
+	if m.Step != ImageGenStepPlacement {
+		t.Fatalf("expected ImageGenStepPlacement, got %d", m.Step)
+	}
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(*ImageGenModel)
+
 	// Should be in generating step
 	if m.Step != ImageGenStepGenerating {
 		t.Errorf("expected ImageGenStepGenerating, got %d", m.Step)
@@ -1612,6 +1696,13 @@ Content here
 	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
 	m = newModel.(*ImageGenModel)
 
+	// Confirm the default placement
+	if m.Step != ImageGenStepPlacement {
+		t.Fatalf("expected ImageGenStepPlacement, got %d", m.Step)
+	}
+	newModel, cmd = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(*ImageGenModel)
+
 	// Should be in generating step
 	if m.Step != ImageGenStepGenerating {
 		t.Errorf("expected ImageGenStepGenerating, got %d", m.Step)
@@ -1741,15 +1832,15 @@ Content
 
 	// Simulate successful generation
 	result := ImageGenerateResult{
-		ImageData:   []byte("fake image data"),
+		ImageData:   testPNG("fake image data"),
 		ContentType: "image/png",
 	}
 	newModel, _ := model.Update(imageGenerateMsg{result: result})
 	m := newModel.(*ImageGenModel)
 
-	// Should be in done step
-	if m.Step != ImageGenStepDone {
-		t.Errorf("expected ImageGenStepDone, got %d", m.Step)
+	// Should be in review step, before anything is saved
+	if m.Step != ImageGenStepReview {
+		t.Errorf("expected ImageGenStepReview, got %d", m.Step)
 	}
 
 	// IsGenerating should be false
@@ -1762,7 +1853,7 @@ Content
 		t.Fatal("GeneratedImage should not be nil after success")
 	}
 
-	if string(m.GeneratedImage.ImageData) != "fake image data" {
+	if string(m.GeneratedImage.ImageData) != string(testPNG("fake image data")) {
 		t.Error("image data should match")
 	}
 
@@ -1984,22 +2075,216 @@ Content
 	model.Prompt = "Test prompt"
 	model.Error = "" // No error, so keys should be ignored
 
-	// Try to press esc - should be ignored while generating
-	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	// Try to press 'r' - should be ignored while generating
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
 	m := newModel.(*ImageGenModel)
 
 	// Should still be in generating step
 	if m.Step != ImageGenStepGenerating {
 		t.Errorf("expected to stay in ImageGenStepGenerating while generating, got %d", m.Step)
 	}
+}
 
-	// Try to press 'r' - should be ignored while generating
-	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
-	m = newModel.(*ImageGenModel)
+func TestImageGenModel_EscCancelsGeneration(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide 1"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
 
-	// Should still be in generating step
-	if m.Step != ImageGenStepGenerating {
-		t.Errorf("expected to stay in ImageGenStepGenerating while generating, got %d", m.Step)
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	model.Step = ImageGenStepGenerating
+	model.IsGenerating = true
+	model.Prompt = "Test prompt"
+	canceled := false
+	model.cancelGenerate = func() { canceled = true }
+
+	if !strings.Contains(model.View(), "esc") {
+		t.Error("generating view should show the esc cancel hint")
+	}
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m := newModel.(*ImageGenModel)
+
+	if !canceled {
+		t.Error("esc should cancel the in-flight request")
+	}
+	if m.Step != ImageGenStepPrompt || m.IsGenerating {
+		t.Errorf("expected to return to prompt step, got step %d (generating %v)", m.Step, m.IsGenerating)
+	}
+
+	// A result arriving after cancellation is ignored
+	m.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: testPNG("late"), ContentType: "image/png"}})
+	if m.Step != ImageGenStepPrompt || m.GeneratedImage != nil {
+		t.Errorf("late result should be ignored, got step %d", m.Step)
+	}
+}
+
+// newReviewTestModel returns a model in the review step with a decodable generated image.
+func newReviewTestModel(t *testing.T) *ImageGenModel {
+	t.Helper()
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide 1"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	model.PreviewProtocol = PreviewHalfBlock
+	model.Step = ImageGenStepGenerating
+	model.IsGenerating = true
+	model.Prompt = "Test prompt"
+	model.promptInput.SetValue("Test prompt")
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: syntheticPNG(t, 8, 4), ContentType: "image/png"}})
+	if model.Step != ImageGenStepReview {
+		t.Fatalf("expected review step, got %d", model.Step)
+	}
+	return model
+}
+
+func TestImageGenModel_ReviewShowsPreview(t *testing.T) {
+	model := newReviewTestModel(t)
+
+	view := model.View()
+	if !strings.Contains(view, "▀") {
+		t.Errorf("expected half-block preview in review view, got:\n%s", view)
+	}
+	if !strings.Contains(view, "accept") || !strings.Contains(view, "regenerate") || !strings.Contains(view, "edit prompt") {
+		t.Errorf("expected review actions in help, got:\n%s", view)
+	}
+
+	// Undecodable images still reach the review step without a preview
+	model.Step = ImageGenStepGenerating
+	model.IsGenerating = true
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: testPNG("not decodable"), ContentType: "image/png"}})
+	if model.Step != ImageGenStepReview || !strings.Contains(model.View(), "Preview not available") {
+		t.Errorf("expected review step without preview, got step %d", model.Step)
+	}
+}
+
+func TestImageGenModel_ReviewAccept(t *testing.T) {
+	model := newReviewTestModel(t)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.Step != ImageGenStepDone || model.GeneratedImage == nil {
+		t.Errorf("expected done step with the generated image, got step %d", model.Step)
+	}
+}
+
+func TestImageGenModel_ReviewRegenerate(t *testing.T) {
+	model := newReviewTestModel(t)
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil {
+		t.Fatal("expected generation to restart")
+	}
+	if model.Step != ImageGenStepGenerating || !model.IsGenerating || model.GeneratedImage != nil {
+		t.Errorf("expected generating step without the previous image, got step %d", model.Step)
+	}
+	if model.Prompt != "Test prompt" {
+		t.Errorf("expected same prompt, got %q", model.Prompt)
+	}
+	model.cancelGeneration()
+}
+
+func TestImageGenModel_ReviewEditPrompt(t *testing.T) {
+	model := newReviewTestModel(t)
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	if model.Step != ImageGenStepPrompt || model.GeneratedImage != nil {
+		t.Errorf("expected prompt step without the generated image, got step %d", model.Step)
+	}
+	if model.promptInput.Value() != "Test prompt" || !model.promptInput.Focused() {
+		t.Errorf("expected focused prompt input with the previous prompt, got %q", model.promptInput.Value())
+	}
+}
+
+// toReview generates an image in m, moving it to the review step.
+func toReview(t *testing.T, m *ImageGenModel) {
+	t.Helper()
+	m.PreviewProtocol = PreviewHalfBlock
+	m.Step = ImageGenStepGenerating
+	m.IsGenerating = true
+	m.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: syntheticPNG(t, 8, 4), ContentType: "image/png"}})
+	if m.Step != ImageGenStepReview {
+		t.Fatalf("expected review step, got %d", m.Step)
+	}
+}
+
+func TestImageGenModel_ReviewAcceptConfirmsDeletingOldImage(t *testing.T) {
+	model := newReferenceTestModel(t, syntheticPNG(t, 2, 2))
+	toReview(t, model)
+
+	for _, cancel := range []tea.KeyMsg{{Type: tea.KeyRunes, Runes: []rune("n")}, {Type: tea.KeyEsc}} {
+		model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		if model.confirm == nil || !strings.Contains(model.View(), "Delete images/old.png?") {
+			t.Fatalf("expected a confirm dialog before deleting the old image, got:\n%s", model.View())
+		}
+		model.Update(cancel)
+		if model.confirm != nil || model.Step != ImageGenStepReview || model.GeneratedImage == nil {
+			t.Fatalf("%s should return to the review step, got step %d", cancel, model.Step)
+		}
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if model.confirm != nil || model.Step != ImageGenStepDone {
+		t.Errorf("expected done step after confirming, got step %d", model.Step)
+	}
+}
+
+func TestImageGenModel_ReviewAcceptConfirmsChangedMarkdown(t *testing.T) {
+	model := newReviewTestModel(t)
+	if err := os.WriteFile(model.MarkdownFile, []byte("# Slide 1\n\nEdited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.confirm == nil || !strings.Contains(model.View(), "test.md changed on disk") {
+		t.Fatalf("expected a confirm dialog before overwriting the markdown, got:\n%s", model.View())
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if model.Step != ImageGenStepReview {
+		t.Fatalf("expected review step after declining, got step %d", model.Step)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if model.Step != ImageGenStepDone {
+		t.Errorf("expected done step after confirming, got step %d", model.Step)
+	}
+}
+
+func TestImageGenModel_ReviewAcceptConfirmsEachAction(t *testing.T) {
+	model := newReferenceTestModel(t, syntheticPNG(t, 2, 2))
+	toReview(t, model)
+	if err := os.WriteFile(model.MarkdownFile, []byte("# Edited\n\n<!-- ai-prompt: a lighthouse at dusk -->\n![](images/old.png)\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Confirming the deletion asks about the markdown next; declining that keeps the review
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if model.confirm == nil || !strings.Contains(model.View(), "changed on disk") {
+		t.Fatalf("expected a second confirm dialog, got:\n%s", model.View())
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if model.Step != ImageGenStepReview {
+		t.Fatalf("expected review step after declining, got step %d", model.Step)
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if model.Step != ImageGenStepDone {
+		t.Errorf("expected done step after confirming both, got step %d", model.Step)
 	}
 }
 
@@ -2066,6 +2351,16 @@ func TestFormatAPIError(t *testing.T) {
 			err:      &gemini.APIError{Type: gemini.ErrorTypeContentPolicy, Message: "blocked"},
 			contains: "content policy",
 		},
+		{
+			name: "content policy error with categories",
+			err: &gemini.APIError{Type: gemini.ErrorTypeContentPolicy, Message: "blocked", Details: &gemini.ErrorDetails{
+				SafetyRatings: []gemini.SafetyRating{
+					{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Probability: "HIGH", Blocked: true},
+					{Category: "HARM_CATEGORY_HARASSMENT", Probability: "LOW"},
+				},
+			}},
+			contains: "blocked by content policy (dangerous content).",
+		},
 		{
 			name:     "invalid request error",
 			err:      &gemini.APIError{Type: gemini.ErrorTypeInvalidRequest, Message: "bad request"},
@@ -2086,6 +2381,16 @@ func TestFormatAPIError(t *testing.T) {
 			err:      &gemini.APIError{Type: gemini.ErrorTypeServer, Message: "internal error"},
 			contains: "Server error",
 		},
+		{
+			name:     "rate limit error after retries",
+			err:      &gemini.APIError{Type: gemini.ErrorTypeRateLimit, Message: "too many requests", Attempts: 4},
+			contains: "failed after 3 retries",
+		},
+		{
+			name:     "server error after one retry",
+			err:      &gemini.APIError{Type: gemini.ErrorTypeServer, Message: "internal error", Attempts: 2},
+			contains: "failed after 1 retry)",
+		},
 		{
 			name:     "generic error",
 			err:      errors.New("something went wrong"),
@@ -2565,7 +2870,7 @@ func TestImageGenModel_SaveGeneratedImage(t *testing.T) {
 	}
 
 	// Set up generated image
-	imageData := []byte("fake PNG image data for testing")
+	imageData := testPNG("fake PNG image data for testing")
 	model.GeneratedImage = &ImageGenerateResult{
 		ImageData:   imageData,
 		ContentType: "image/png",
@@ -2614,7 +2919,7 @@ func TestImageGenModel_SaveGeneratedImage_JPEG(t *testing.T) {
 
 	// Set up generated JPEG image
 	model.GeneratedImage = &ImageGenerateResult{
-		ImageData:   []byte("fake JPEG image data"),
+		ImageData:   testJPEG("fake JPEG image data"),
 		ContentType: "image/jpeg",
 	}
 
@@ -2678,7 +2983,7 @@ func TestImageGenModel_SaveGeneratedImage_CreatesImagesDirectory(t *testing.T) {
 
 	// Set up generated image
 	model.GeneratedImage = &ImageGenerateResult{
-		ImageData:   []byte("test image data"),
+		ImageData:   testPNG("test image data"),
 		ContentType: "image/png",
 	}
 
@@ -2713,7 +3018,7 @@ func TestImageGenModel_SaveGeneratedImage_FilePermissions(t *testing.T) {
 	}
 
 	model.GeneratedImage = &ImageGenerateResult{
-		ImageData:   []byte("test image data"),
+		ImageData:   testPNG("test image data"),
 		ContentType: "image/png",
 	}
 
@@ -2754,7 +3059,7 @@ func TestImageGenModel_SaveGeneratedImage_ReturnsRelativePath(t *testing.T) {
 	}
 
 	model.GeneratedImage = &ImageGenerateResult{
-		ImageData:   []byte("test image data"),
+		ImageData:   testPNG("test image data"),
 		ContentType: "image/png",
 	}
 
@@ -2780,6 +3085,77 @@ func TestImageGenModel_SaveGeneratedImage_ReturnsRelativePath(t *testing.T) {
 	}
 }
 
+func TestToMarkdownPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.FromSlash("images/generated-x.png"), "images/generated-x.png"},
+		{`images\generated-x.png`, "images/generated-x.png"},
+		{`..\shared\images\logo.png`, "../shared/images/logo.png"},
+		{"images/generated-x.png", "images/generated-x.png"},
+		{"https://example.com/a/b.png", "https://example.com/a/b.png"},
+	}
+
+	for _, tt := range tests {
+		if got := toMarkdownPath(tt.path); got != tt.want {
+			t.Errorf("toMarkdownPath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestImageGenModel_WindowsPathsUseForwardSlashes(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	content := `# Slide 1
+
+<!-- ai-prompt: a sunset -->
+![](images/old.png)
+
+---
+
+# Slide 2
+`
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	// Replacing with an OS-native path writes a forward-slash path
+	model.SelectedIndex = 0
+	model.SelectedImage = &AIImageInfo{Prompt: "a sunset", ImagePath: "images/old.png"}
+	model.Prompt = "a sunrise"
+	if err := model.ReplaceImageInMarkdown(`images\new.png`); err != nil {
+		t.Fatalf("ReplaceImageInMarkdown failed: %v", err)
+	}
+
+	// Inserting with an OS-native path writes a forward-slash path
+	model.SelectedIndex = 1
+	model.SelectedImage = nil
+	model.Prompt = "a forest"
+	if err := model.InsertImageIntoMarkdown(filepath.FromSlash("images/forest.png")); err != nil {
+		t.Fatalf("InsertImageIntoMarkdown failed: %v", err)
+	}
+
+	updated, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(updated), `\`) {
+		t.Errorf("markdown should not contain backslashes, got:\n%s", updated)
+	}
+
+	// The written paths are found again when the markdown is parsed
+	images := parseAIImages(string(updated))
+	if len(images) != 2 || images[0].ImagePath != "images/new.png" || images[1].ImagePath != "images/forest.png" {
+		t.Errorf("unexpected parsed images: %+v", images)
+	}
+}
+
 // Tests for markdown insertion (US-017)
 
 func TestInsertImageIntoSlide_SingleSlide(t *testing.T) {
@@ -2787,7 +3163,7 @@ func TestInsertImageIntoSlide_SingleSlide(t *testing.T) {
 
 Some content here`
 
-	result, err := insertImageIntoSlide(content, 0, "A test prompt", "images/generated-abc123.png")
+	result, err := insertImageIntoSlide(content, 0, false, aiImageMarkdown{Prompt: "A test prompt", Path: "images/generated-abc123.png"}, PlacementEnd)
 	if err != nil {
 		t.Fatalf("insertImageIntoSlide failed: %v", err)
 	}
@@ -2836,7 +3212,7 @@ Content two
 Content three`
 
 	// Insert into second slide
-	result, err := insertImageIntoSlide(content, 1, "Second slide image", "images/second.png")
+	result, err := insertImageIntoSlide(content, 1, false, aiImageMarkdown{Prompt: "Second slide image", Path: "images/second.png"}, PlacementEnd)
 	if err != nil {
 		t.Fatalf("insertImageIntoSlide failed: %v", err)
 	}
@@ -2894,7 +3270,7 @@ Content here
 
 More content`
 
-	result, err := insertImageIntoSlide(content, 0, "First slide prompt", "images/first.png")
+	result, err := insertImageIntoSlide(content, 0, false, aiImageMarkdown{Prompt: "First slide prompt", Path: "images/first.png"}, PlacementEnd)
 	if err != nil {
 		t.Fatalf("insertImageIntoSlide failed: %v", err)
 	}
@@ -2922,7 +3298,7 @@ func TestInsertImageIntoSlide_InvalidSlideIndex(t *testing.T) {
 Content`
 
 	// Try to insert into non-existent slide
-	_, err := insertImageIntoSlide(content, 5, "prompt", "images/test.png")
+	_, err := insertImageIntoSlide(content, 5, false, aiImageMarkdown{Prompt: "prompt", Path: "images/test.png"}, PlacementEnd)
 	if err == nil {
 		t.Error("expected error for invalid slide index")
 	}
@@ -2931,7 +3307,7 @@ Content`
 	}
 
 	// Try negative index
-	_, err = insertImageIntoSlide(content, -1, "prompt", "images/test.png")
+	_, err = insertImageIntoSlide(content, -1, false, aiImageMarkdown{Prompt: "prompt", Path: "images/test.png"}, PlacementEnd)
 	if err == nil {
 		t.Error("expected error for negative slide index")
 	}
@@ -2954,7 +3330,7 @@ More content`
 
 	// Empty slide (index 1 would be empty, but it's skipped)
 	// So slide index 1 should be "Third Slide"
-	result, err := insertImageIntoSlide(content, 1, "Third slide image", "images/third.png")
+	result, err := insertImageIntoSlide(content, 1, false, aiImageMarkdown{Prompt: "Third slide image", Path: "images/third.png"}, PlacementEnd)
 	if err != nil {
 		t.Fatalf("insertImageIntoSlide failed: %v", err)
 	}
@@ -2984,7 +3360,7 @@ Some text
 
 More text`
 
-	result, err := insertImageIntoSlide(content, 0, "new prompt", "images/new.png")
+	result, err := insertImageIntoSlide(content, 0, false, aiImageMarkdown{Prompt: "new prompt", Path: "images/new.png"}, PlacementEnd)
 	if err != nil {
 		t.Fatalf("insertImageIntoSlide failed: %v", err)
 	}
@@ -3023,7 +3399,7 @@ Content
 
 Final content`
 
-	result, err := insertImageIntoSlide(content, 2, "last prompt", "images/last.png")
+	result, err := insertImageIntoSlide(content, 2, false, aiImageMarkdown{Prompt: "last prompt", Path: "images/last.png"}, PlacementEnd)
 	if err != nil {
 		t.Fatalf("insertImageIntoSlide failed: %v", err)
 	}
@@ -3042,6 +3418,87 @@ Final content`
 	}
 }
 
+func TestInsertImageIntoSlide_PreservesOtherBytes(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string // content before the slide, left untouched
+		slide  string
+		after  string // content after the slide, left untouched
+		want   string // slide after inserting the image
+	}{
+		{
+			name:   "spacing around delimiters",
+			before: "---\ntitle: Test\n---\n\n\n# First\n---\n\n\n",
+			slide:  "# Second\n\nText\n\n\n",
+			after:  "---\n# Third",
+			want:   "# Second\n\nText\n\n" + placementImage + "\n\n\n",
+		},
+		{
+			name:   "CRLF line endings",
+			before: "---\r\ntitle: Test\r\n---\r\n\r\n# First\r\n\r\n---\r\n\r\n",
+			slide:  "# Second\r\n\r\nText\r\n\r\n",
+			after:  "---\r\n\r\n# Third\r\n",
+			want:   "# Second\r\n\r\nText\r\n\r\n" + strings.ReplaceAll(placementImage, "\n", "\r\n") + "\r\n\r\n",
+		},
+		{
+			name:   "trailing spaces after ---",
+			before: "# First\n\n---  \n\n",
+			slide:  "# Second\n\nText\n\n",
+			after:  "--- \t\n\n# Third\n",
+			want:   "# Second\n\nText\n\n" + placementImage + "\n\n",
+		},
+		{
+			name:   "--- in a code block",
+			before: "# First\n\n---\n\n",
+			slide:  "# Second\n\n```yaml\n---\nkey: value\n```\n\n",
+			after:  "---\n\n# Third\n",
+			want:   "# Second\n\n```yaml\n---\nkey: value\n```\n\n" + placementImage + "\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := insertImageIntoSlide(tt.before+tt.slide+tt.after, 1, false, aiImageMarkdown{Prompt: "a cat", Path: "images/cat.png"}, PlacementEnd)
+			if err != nil {
+				t.Fatalf("insertImageIntoSlide failed: %v", err)
+			}
+			if want := tt.before + tt.want + tt.after; result != want {
+				t.Errorf("insertImageIntoSlide() = %q, want %q", result, want)
+			}
+		})
+	}
+}
+
+func TestReplaceImageInMarkdown_CRLF(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	content := "# First\r\n\r\n---  \r\n\r\n# Second\r\n\r\n<!-- ai-prompt: a cat -->\r\n![](images/old.png)\r\n\r\n---\r\n\r\n# Third\r\n"
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	model.SelectedIndex = 1
+	model.SelectedImage = &AIImageInfo{Prompt: "a cat", ImagePath: "images/old.png"}
+	model.Prompt = "a cat"
+
+	if err := model.ReplaceImageInMarkdown("images/new.png"); err != nil {
+		t.Fatalf("ReplaceImageInMarkdown failed: %v", err)
+	}
+
+	got, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+	want := strings.Replace(content, "images/old.png", "images/new.png", 1)
+	if string(got) != want {
+		t.Errorf("content = %q, want %q", got, want)
+	}
+}
+
 func TestInsertImageIntoSlide_SpecialCharactersInPrompt(t *testing.T) {
 	content := `# Slide
 
@@ -3049,7 +3506,7 @@ Content`
 
 	// Prompt with special characters
 	prompt := "A beautiful sunset with \"quotes\" and special chars: <>&"
-	result, err := insertImageIntoSlide(content, 0, prompt, "images/special.png")
+	result, err := insertImageIntoSlide(content, 0, false, aiImageMarkdown{Prompt: prompt, Path: "images/special.png"}, PlacementEnd)
 	if err != nil {
 		t.Fatalf("insertImageIntoSlide failed: %v", err)
 	}
@@ -3249,6 +3706,27 @@ func TestDeleteOldImage(t *testing.T) {
 	}
 }
 
+func TestDeleteOldImage_RemoteImage(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Test Slide"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	model.SelectedImage = &AIImageInfo{
+		Prompt:    "remote prompt",
+		ImagePath: "https://example.com/images/remote.png",
+	}
+	if err := model.DeleteOldImage(); err != nil {
+		t.Errorf("DeleteOldImage should ignore remote images, got: %v", err)
+	}
+}
+
 func TestDeleteOldImage_FileDoesNotExist(t *testing.T) {
 	tmpDir := t.TempDir()
 	mdFile := filepath.Join(tmpDir, "test.md")
@@ -3452,7 +3930,7 @@ Content three`,
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := replaceImageInContent(tt.content, tt.oldPrompt, tt.oldImagePath, tt.newPrompt, tt.newImagePath)
+			result, err := replaceImageInContent(tt.content, tt.oldPrompt, tt.oldImagePath, aiImageMarkdown{Prompt: tt.newPrompt, Path: tt.newImagePath})
 			if (err != nil) != tt.wantErr {
 				t.Errorf("replaceImageInContent() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -3695,7 +4173,7 @@ More content below
 	model.Prompt = "updated prompt for regeneration"
 
 	// 2. Simulate successful image generation
-	newImageData := []byte("new image content for testing")
+	newImageData := testPNG("new image content for testing")
 	model.GeneratedImage = &ImageGenerateResult{
 		ImageData:   newImageData,
 		ContentType: "image/png",
@@ -4057,3 +4535,975 @@ Content
 		t.Error("view should mention 'continue' in help text")
 	}
 }
+
+// parseNotes parses markdown content and returns the speaker notes of each slide.
+func parseNotes(t *testing.T, content string) []string {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	notes := make([]string, len(pres.Slides))
+	for i, slide := range pres.Slides {
+		notes[i] = slide.Directives.Notes
+	}
+	return notes
+}
+
+func TestImageGenModel_PromptInputCtrlTTogglesSaveToNotes(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+
+	if err := os.WriteFile(mdFile, []byte("# Test Slide\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	// Go to prompt step
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(*ImageGenModel)
+
+	if m.SaveToNotes {
+		t.Error("expected SaveToNotes to be disabled by default")
+	}
+	if !strings.Contains(m.View(), "[ ] Save prompt to speaker notes") {
+		t.Error("prompt view should show unchecked notes option")
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = newModel.(*ImageGenModel)
+
+	if !m.SaveToNotes {
+		t.Error("expected ctrl+t to enable SaveToNotes")
+	}
+	if m.Step != ImageGenStepPrompt {
+		t.Errorf("expected to stay in prompt step, got %d", m.Step)
+	}
+	if !strings.Contains(m.View(), "[x] Save prompt to speaker notes") {
+		t.Error("prompt view should show checked notes option")
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlT})
+	m = newModel.(*ImageGenModel)
+
+	if m.SaveToNotes {
+		t.Error("expected second ctrl+t to disable SaveToNotes")
+	}
+}
+
+func TestImageGenModel_DoneViewShowsSavedToNotes(t *testing.T) {
+	model := &ImageGenModel{
+		Slides:      []SlideInfo{{Index: 0, Title: "Test"}},
+		Step:        ImageGenStepDone,
+		SaveToNotes: true,
+	}
+
+	if !strings.Contains(model.View(), "Prompt saved to speaker notes") {
+		t.Error("done view should mention that the prompt was saved to notes")
+	}
+}
+
+func TestAppendNoteToSlide_NoDirectives(t *testing.T) {
+	content := `# First Slide
+
+Content
+
+---
+
+# Second Slide`
+
+	result, err := appendNoteToSlide(content, 1, false, "A mountain at dawn")
+	if err != nil {
+		t.Fatalf("appendNoteToSlide failed: %v", err)
+	}
+
+	notes := parseNotes(t, result)
+	if len(notes) != 2 {
+		t.Fatalf("expected 2 slides, got %d", len(notes))
+	}
+	if notes[0] != "" {
+		t.Errorf("first slide should have no notes, got %q", notes[0])
+	}
+	if notes[1] != "A mountain at dawn" {
+		t.Errorf("expected notes %q, got %q", "A mountain at dawn", notes[1])
+	}
+	if !strings.Contains(result, "# Second Slide") {
+		t.Error("slide content should be preserved")
+	}
+}
+
+func TestAppendNoteToSlide_ExistingDirectivesWithoutNotes(t *testing.T) {
+	content := `<!--
+layout: two-column
+-->
+
+# Slide`
+
+	result, err := appendNoteToSlide(content, 0, false, "A prompt")
+	if err != nil {
+		t.Fatalf("appendNoteToSlide failed: %v", err)
+	}
+
+	pres, err := parser.New().Parse([]byte(result))
+	if err != nil {
+		t.Fatalf("failed to parse result: %v", err)
+	}
+	if pres.Slides[0].Directives.Layout != "two-column" {
+		t.Errorf("layout should be preserved, got %q", pres.Slides[0].Directives.Layout)
+	}
+	if pres.Slides[0].Directives.Notes != "A prompt" {
+		t.Errorf("expected notes %q, got %q", "A prompt", pres.Slides[0].Directives.Notes)
+	}
+	if strings.Count(result, "<!--") != 1 {
+		t.Errorf("expected a single directive comment, got:\n%s", result)
+	}
+}
+
+func TestAppendNoteToSlide_ExistingMultilineNotes(t *testing.T) {
+	content := `---
+title: Talk
+---
+
+# Intro
+
+---
+
+<!--
+layout: title
+notes: |
+  Remember to smile.
+  Pause before the reveal.
+-->
+
+# Slide Two`
+
+	result, err := appendNoteToSlide(content, 1, false, "A robot painting a canvas")
+	if err != nil {
+		t.Fatalf("appendNoteToSlide failed: %v", err)
+	}
+
+	if !strings.Contains(result, "title: Talk") {
+		t.Error("frontmatter should be preserved")
+	}
+
+	notes := parseNotes(t, result)
+	expected := "Remember to smile.\nPause before the reveal.\nA robot painting a canvas"
+	if strings.TrimRight(notes[1], "\n") != expected {
+		t.Errorf("expected notes %q, got %q", expected, notes[1])
+	}
+	if notes[0] != "" {
+		t.Errorf("first slide should have no notes, got %q", notes[0])
+	}
+}
+
+func TestAppendNoteToSlide_IgnoresAIPromptComment(t *testing.T) {
+	content := `<!-- ai-prompt: A cat -->
+![](images/cat.png)`
+
+	result, err := appendNoteToSlide(content, 0, false, "A dog")
+	if err != nil {
+		t.Fatalf("appendNoteToSlide failed: %v", err)
+	}
+
+	if !strings.Contains(result, "<!-- ai-prompt: A cat -->") {
+		t.Error("ai-prompt comment should be left untouched")
+	}
+	notes := parseNotes(t, result)
+	if notes[0] != "A dog" {
+		t.Errorf("expected notes %q, got %q", "A dog", notes[0])
+	}
+}
+
+func TestImageGenModel_InsertImageIntoMarkdown_SaveToNotes(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+
+	content := `# Test Slide
+
+Some content here`
+
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	model.Prompt = "A test image"
+	model.SaveToNotes = true
+
+	if err := model.InsertImageIntoMarkdown("images/test-image.png"); err != nil {
+		t.Fatalf("InsertImageIntoMarkdown failed: %v", err)
+	}
+
+	updatedContent, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatalf("failed to read updated file: %v", err)
+	}
+
+	if !strings.Contains(string(updatedContent), "![](images/test-image.png)") {
+		t.Error("updated content should contain image reference")
+	}
+	notes := parseNotes(t, string(updatedContent))
+	if notes[0] != "A test image" {
+		t.Errorf("expected notes %q, got %q", "A test image", notes[0])
+	}
+}
+
+func TestImageGenModel_IncludedSlides(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	partFile := filepath.Join(tmpDir, "part.md")
+
+	main := `# Intro
+
+---
+
+<!-- include: part.md -->
+
+---
+
+# Outro`
+	part := `# Included One
+
+---
+
+# Included Two
+
+<!-- ai-prompt: A cat -->
+![](images/cat.png)`
+
+	if err := os.WriteFile(mdFile, []byte(main), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(partFile, []byte(part), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	if len(model.Slides) != 4 {
+		t.Fatalf("expected 4 slides from merged document, got %d", len(model.Slides))
+	}
+	if model.Slides[2].Title != "Included Two" || !model.Slides[2].HasAIImages {
+		t.Errorf("expected included slide with AI image, got %+v", model.Slides[2])
+	}
+	if model.Slides[2].File != partFile || model.Slides[2].FileIndex != 1 {
+		t.Errorf("expected slide to be located in %s at index 1, got %s at %d",
+			partFile, model.Slides[2].File, model.Slides[2].FileIndex)
+	}
+	if model.Slides[3].File != mdFile || model.Slides[3].FileIndex != 2 {
+		t.Errorf("expected outro in %s at index 2, got %s at %d",
+			mdFile, model.Slides[3].File, model.Slides[3].FileIndex)
+	}
+
+	// Insert into the first included slide
+	model.SelectedIndex = 1
+	model.Prompt = "A dog"
+	if err := model.InsertImageIntoMarkdown("images/dog.png"); err != nil {
+		t.Fatalf("InsertImageIntoMarkdown failed: %v", err)
+	}
+
+	updatedPart, err := os.ReadFile(partFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(updatedPart), "![](images/dog.png)") {
+		t.Errorf("image should be inserted into the included file, got:\n%s", updatedPart)
+	}
+	updatedMain, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(updatedMain) != main {
+		t.Errorf("main file should be unchanged, got:\n%s", updatedMain)
+	}
+}
+
+func TestImageGenModel_SlideSpanningFilesCannotBeEdited(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+
+	if err := os.WriteFile(mdFile, []byte("# Mixed\n\n<!-- include: part.md -->"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "part.md"), []byte("Included text"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	model.Prompt = "A prompt"
+
+	err = model.InsertImageIntoMarkdown("images/test.png")
+	if err == nil || !strings.Contains(err.Error(), "several files") {
+		t.Errorf("expected error for slide spanning files, got %v", err)
+	}
+}
+
+func TestParseAIImages_WithOptions(t *testing.T) {
+	content := "<!-- ai-prompt: a lighthouse | ratio: 9:16 | size: 2K -->\n![](images/a.png)\n\n<!-- ai-prompt: plain prompt -->\n![](images/b.png)"
+
+	images := parseAIImages(content)
+	if len(images) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(images))
+	}
+	if images[0].Prompt != "a lighthouse" || images[0].AspectRatio != "9:16" || images[0].ImageSize != "2K" {
+		t.Errorf("unexpected first image: %+v", images[0])
+	}
+	if images[1].Prompt != "plain prompt" || images[1].AspectRatio != "" || images[1].ImageSize != "" {
+		t.Errorf("unexpected second image: %+v", images[1])
+	}
+}
+
+func TestImageGenModel_OptionKeys(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("---\naspectRatio: \"4:3\"\n---\n\n# Slide"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	if model.AspectRatio != "4:3" {
+		t.Errorf("AspectRatio should default to the presentation's, got %q", model.AspectRatio)
+	}
+
+	model.Step = ImageGenStepPrompt
+	model.handlePromptKey(tea.KeyMsg{Type: tea.KeyCtrlR})
+	if model.AspectRatio != "3:2" {
+		t.Errorf("ctrl+r should cycle to 3:2, got %q", model.AspectRatio)
+	}
+	model.handlePromptKey(tea.KeyMsg{Type: tea.KeyCtrlS})
+	if model.ImageSize != "1K" {
+		t.Errorf("ctrl+s should cycle to 1K, got %q", model.ImageSize)
+	}
+}
+
+func TestImageGenModel_InsertImageIntoMarkdown_RecordsOptions(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	model.Prompt = "A lighthouse"
+	model.AspectRatio = "9:16"
+	model.ImageSize = "2K"
+
+	if err := model.InsertImageIntoMarkdown("images/a.png"); err != nil {
+		t.Fatalf("InsertImageIntoMarkdown failed: %v", err)
+	}
+	images := parseAIImages(string(mustReadFile(t, mdFile)))
+	if len(images) != 1 {
+		t.Fatalf("expected 1 image, got %d", len(images))
+	}
+	if images[0].Prompt != "A lighthouse" || images[0].AspectRatio != "9:16" || images[0].ImageSize != "2K" {
+		t.Errorf("options not recorded: %+v", images[0])
+	}
+
+	// Replacing keeps matching the comment with options
+	model.SelectedImage = &images[0]
+	model.Prompt = "A red lighthouse"
+	model.AspectRatio = model.defaultRatio
+	model.ImageSize = ""
+	if err := model.ReplaceImageInMarkdown("images/b.png"); err != nil {
+		t.Fatalf("ReplaceImageInMarkdown failed: %v", err)
+	}
+	if got := string(mustReadFile(t, mdFile)); !strings.Contains(got, "<!-- ai-prompt: A red lighthouse -->\n![](images/b.png)") {
+		t.Errorf("unexpected content after replace:\n%s", got)
+	}
+}
+
+func mustReadFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// testPNG returns minimal PNG-framed data: the PNG signature, a payload, and an IEND chunk.
+func testPNG(payload string) []byte {
+	data := []byte("\x89PNG\r\n\x1a\n")
+	data = append(data, payload...)
+	return append(data, "\x00\x00\x00\x00IEND\xaeB`\x82"...)
+}
+
+// testJPEG returns minimal JPEG-framed data: the SOI marker, a payload, and the EOI marker.
+func testJPEG(payload string) []byte {
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE0}
+	data = append(data, payload...)
+	return append(data, 0xFF, 0xD9)
+}
+
+func TestSniffImageType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"png", testPNG("x"), "image/png"},
+		{"jpeg", testJPEG("x"), "image/jpeg"},
+		{"gif", []byte("GIF89a\x01\x00;"), "image/gif"},
+		{"webp", []byte("RIFF\x04\x00\x00\x00WEBP"), "image/webp"},
+		{"svg", []byte(`<?xml version="1.0"?><svg></svg>`), "image/svg+xml"},
+		{"text", []byte("not an image"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SniffImageType(tt.data); got != tt.want {
+				t.Errorf("SniffImageType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateImageData_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		maxSize int64
+		want    string
+	}{
+		{"empty", nil, DefaultMaxImageSize, "empty"},
+		{"truncated png", testPNG("x")[:12], DefaultMaxImageSize, "truncated"},
+		{"truncated webp", []byte("RIFF\xff\x00\x00\x00WEBP"), DefaultMaxImageSize, "truncated"},
+		{"unknown", []byte("hello"), DefaultMaxImageSize, "not a PNG"},
+		{"too large", testPNG("0123456789"), 10, "exceeding the 10 byte limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateImageData(tt.data, tt.maxSize)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			apiErr, ok := err.(*gemini.APIError)
+			if !ok || apiErr.Type != gemini.ErrorTypeInvalidImage {
+				t.Errorf("expected invalid image APIError, got %T", err)
+			}
+		})
+	}
+}
+
+func TestImageGenModel_SaveGeneratedImage_PrefersSniffedType(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Test Slide"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	// JPEG bytes labeled as PNG by the API
+	model.GeneratedImage = &ImageGenerateResult{
+		ImageData:   testJPEG("jpeg labeled png"),
+		ContentType: "image/png",
+	}
+
+	relativePath, err := model.SaveGeneratedImage()
+	if err != nil {
+		t.Fatalf("SaveGeneratedImage failed: %v", err)
+	}
+	if !strings.HasSuffix(relativePath, ".jpg") {
+		t.Errorf("expected sniffed .jpg extension, got %q", relativePath)
+	}
+}
+
+func TestImageGenModel_HandleImageGenerateResult_InvalidImageIsRetryable(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Test Slide"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	model.Step = ImageGenStepGenerating
+	model.IsGenerating = true
+
+	model.handleImageGenerateResult(ImageGenerateResult{ImageData: []byte{}, ContentType: "image/png"})
+
+	if model.Step != ImageGenStepGenerating {
+		t.Errorf("expected to stay in generating step, got %d", model.Step)
+	}
+	if !strings.Contains(model.Error, "invalid") || !strings.Contains(model.Error, "try again") {
+		t.Errorf("expected retryable invalid image error, got %q", model.Error)
+	}
+
+	// Retry is available from the error state
+	_, cmd := model.handleGeneratingKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil || !model.IsGenerating {
+		t.Error("expected retry to restart generation")
+	}
+}
+
+// newBatchTestModel creates a model for a presentation with one existing and two pending AI images.
+func newBatchTestModel(t *testing.T) *ImageGenModel {
+	t.Helper()
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	content := `# Intro
+
+<!-- ai-prompt: existing cat -->
+![](images/cat.png)
+
+---
+
+# Dogs
+
+<!-- ai-prompt: a dog | ratio: 1:1 -->
+![](images/dog.png)
+
+---
+
+# Birds
+
+<!-- ai-prompt: a bird -->
+![](images/bird.png)
+`
+	if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "images", "cat.png"), testPNG("cat"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	return model
+}
+
+func TestImageGenModel_PendingImages(t *testing.T) {
+	model := newBatchTestModel(t)
+
+	pending := model.PendingImages()
+	if len(pending) != 2 {
+		t.Fatalf("expected 2 pending images, got %d", len(pending))
+	}
+	if pending[0].SlideIndex != 1 || pending[0].Image.Prompt != "a dog" || pending[0].Image.AspectRatio != "1:1" {
+		t.Errorf("unexpected first pending image: %+v", pending[0])
+	}
+	if pending[1].SlideIndex != 2 || pending[1].Image.ImagePath != "images/bird.png" {
+		t.Errorf("unexpected second pending image: %+v", pending[1])
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "2 pending image(s)") || !strings.Contains(view, "generate all pending") {
+		t.Errorf("slide select view should show pending images, got:\n%s", view)
+	}
+}
+
+func TestImageGenModel_PendingImagesMatchReport(t *testing.T) {
+	model := newBatchTestModel(t)
+
+	report, err := imagereport.Scan(model.MarkdownFile)
+	if err != nil {
+		t.Fatalf("Scan() error = %v", err)
+	}
+
+	pending := model.PendingImages()
+	reported := report.Pending()
+	if len(pending) != len(reported) {
+		t.Fatalf("batch mode has %d pending images, the report %d", len(pending), len(reported))
+	}
+	for i, item := range pending {
+		if item.Image != reported[i].AIImage || item.SlideIndex != reported[i].Slide-1 {
+			t.Errorf("pending image %d = %+v on slide %d, report has %+v", i, item.Image, item.SlideIndex, reported[i])
+		}
+	}
+}
+
+func TestImageGenModel_BatchGeneratesPendingImages(t *testing.T) {
+	model := newBatchTestModel(t)
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if cmd == nil {
+		t.Fatal("expected generation to start")
+	}
+	if model.Step != ImageGenStepBatch || !model.IsGenerating {
+		t.Fatalf("expected batch step while generating, got step %d", model.Step)
+	}
+	if model.Prompt != "a dog" || model.AspectRatio != "1:1" {
+		t.Errorf("expected first pending image settings, got prompt %q ratio %q", model.Prompt, model.AspectRatio)
+	}
+
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: testPNG("dog"), ContentType: "image/png"}})
+	if model.BatchItems[0].Status != BatchItemDone {
+		t.Errorf("expected first item done, got %d", model.BatchItems[0].Status)
+	}
+	if model.Prompt != "a bird" || model.AspectRatio != model.defaultRatio {
+		t.Errorf("expected second pending image settings, got prompt %q ratio %q", model.Prompt, model.AspectRatio)
+	}
+
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: testPNG("bird"), ContentType: "image/png"}})
+	if model.IsGenerating {
+		t.Error("IsGenerating should be false after the last image")
+	}
+
+	content, err := os.ReadFile(model.MarkdownFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range model.BatchItems {
+		if item.Status != BatchItemDone {
+			t.Errorf("expected item %q done, got %d", item.Image.Prompt, item.Status)
+		}
+		if !strings.Contains(string(content), "]("+item.SavedImagePath+")") {
+			t.Errorf("markdown should reference %s", item.SavedImagePath)
+		}
+		if _, err := os.Stat(filepath.Join(filepath.Dir(model.MarkdownFile), item.SavedImagePath)); err != nil {
+			t.Errorf("saved image not found: %v", err)
+		}
+	}
+	if !strings.Contains(string(content), "<!-- ai-prompt: a dog | ratio: 1:1 -->") {
+		t.Errorf("aspect ratio should be preserved in the prompt comment, got:\n%s", content)
+	}
+	if len(model.PendingImages()) != 0 {
+		t.Error("expected no pending images after batch")
+	}
+
+	view := model.View()
+	if !strings.Contains(view, "2 generated, 0 failed") {
+		t.Errorf("expected summary in view, got:\n%s", view)
+	}
+
+	// Enter returns nil to signal completion
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if newModel != nil {
+		t.Error("expected nil model after finishing batch")
+	}
+}
+
+func TestImageGenModel_BatchFailureAndRetry(t *testing.T) {
+	model := newBatchTestModel(t)
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+
+	// A rate limit error fails the item and backs off before the next image
+	rateLimit := &gemini.APIError{Type: gemini.ErrorTypeRateLimit, Message: "quota"}
+	_, cmd := model.Update(imageGenerateMsg{result: ImageGenerateResult{Error: rateLimit}})
+	if cmd == nil || !model.batchWaiting {
+		t.Fatal("expected backoff after rate limit error")
+	}
+	if model.BatchItems[0].Status != BatchItemFailed || !strings.Contains(model.BatchItems[0].Error, "Rate limit") {
+		t.Errorf("expected first item failed with rate limit error, got %+v", model.BatchItems[0])
+	}
+	if !strings.Contains(model.View(), "Rate limited") {
+		t.Error("view should show the backoff")
+	}
+
+	// Keys are ignored while waiting
+	if newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter}); newModel == nil {
+		t.Error("enter should be ignored while the batch is running")
+	}
+
+	model.Update(batchNextMsg{})
+	if model.batchWaiting || model.Prompt != "a bird" {
+		t.Fatalf("expected second image to start after backoff, got prompt %q", model.Prompt)
+	}
+
+	// An invalid image fails the item without backing off
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: []byte{}}})
+	if model.IsGenerating || model.batchWaiting {
+		t.Fatal("expected batch to finish")
+	}
+	if !strings.Contains(model.View(), "0 generated, 2 failed") {
+		t.Errorf("expected failure summary, got:\n%s", model.View())
+	}
+
+	// Retrying regenerates only the failed images
+	_, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if cmd == nil || !model.IsGenerating || model.Prompt != "a dog" {
+		t.Fatalf("expected retry to restart the first failed image, got prompt %q", model.Prompt)
+	}
+	if model.BatchItems[0].Status != BatchItemGenerating || model.BatchItems[1].Status != BatchItemPending {
+		t.Errorf("unexpected statuses after retry: %d, %d", model.BatchItems[0].Status, model.BatchItems[1].Status)
+	}
+}
+
+func TestImageGenModel_BatchEscStops(t *testing.T) {
+	model := newBatchTestModel(t)
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	canceled := false
+	model.cancelGenerate = func() { canceled = true }
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !canceled || model.IsGenerating {
+		t.Fatal("esc should cancel the running batch")
+	}
+	if model.BatchItems[0].Status != BatchItemFailed || model.BatchItems[0].Error != "Canceled" {
+		t.Errorf("expected current item canceled, got %+v", model.BatchItems[0])
+	}
+	if model.BatchItems[1].Status != BatchItemPending {
+		t.Errorf("expected remaining item pending, got %d", model.BatchItems[1].Status)
+	}
+
+	// Late results and backoff ticks do not restart the batch
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: testPNG("dog"), ContentType: "image/png"}})
+	model.Update(batchNextMsg{})
+	if model.IsGenerating || model.BatchItems[0].Status != BatchItemFailed {
+		t.Error("batch should stay stopped after esc")
+	}
+}
+
+func TestImageGenModel_BatchWithoutPendingImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide\n\nNo images"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	_, cmd := model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("g")})
+	if cmd != nil || model.Step != ImageGenStepSlideSelect {
+		t.Error("g should do nothing without pending images")
+	}
+	if strings.Contains(model.View(), "generate all pending") {
+		t.Error("help should not mention batch generation without pending images")
+	}
+}
+
+// newReferenceTestModel returns a model at the prompt step, regenerating
+// images/old.png with the given file content, or without the file if content is nil.
+func newReferenceTestModel(t *testing.T, content []byte) *ImageGenModel {
+	t.Helper()
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+
+	markdown := `# Slide
+
+<!-- ai-prompt: a lighthouse at dusk -->
+![](images/old.png)
+`
+	if err := os.WriteFile(mdFile, []byte(markdown), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if content != nil {
+		if err := os.MkdirAll(filepath.Join(tmpDir, "images"), 0755); err != nil {
+			t.Fatalf("failed to create images directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, "images", "old.png"), content, 0644); err != nil {
+			t.Fatalf("failed to write old image: %v", err)
+		}
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	// Select the slide, then the regenerate option
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyDown})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(*ImageGenModel)
+	if m.Step != ImageGenStepPrompt || m.SelectedImage == nil {
+		t.Fatalf("expected to regenerate at the prompt step, got step %d", m.Step)
+	}
+	return m
+}
+
+func TestImageGenModel_UseReferenceDefault(t *testing.T) {
+	m := newReferenceTestModel(t, []byte("\x89PNG\r\n\x1a\nimage"))
+	if !m.UseReference {
+		t.Error("expected UseReference to default to yes when the old image exists")
+	}
+	if !strings.Contains(m.View(), "[x] Use existing image as reference") {
+		t.Error("prompt view should show the reference toggle")
+	}
+
+	newModel, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m = newModel.(*ImageGenModel)
+	if m.UseReference {
+		t.Error("expected ctrl+o to disable UseReference")
+	}
+
+	m = newReferenceTestModel(t, nil)
+	if m.UseReference {
+		t.Error("expected UseReference to default to no when the old image is missing")
+	}
+}
+
+func TestImageGenModel_UseReferenceNotOfferedForNewImages(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	newModel, _ = newModel.Update(tea.KeyMsg{Type: tea.KeyCtrlO})
+	m := newModel.(*ImageGenModel)
+
+	if m.UseReference {
+		t.Error("ctrl+o should not enable UseReference for a new image")
+	}
+	if strings.Contains(m.View(), "Use existing image as reference") {
+		t.Error("prompt view should not show the reference toggle for a new image")
+	}
+}
+
+func TestImageGenModel_GenerateWithReference(t *testing.T) {
+	m := newReferenceTestModel(t, []byte("\x89PNG\r\n\x1a\nimage"))
+
+	m.generateImageCmd()
+	m.cancelGeneration()
+
+	if !m.ReferenceUsed || m.ReferenceWarning != "" {
+		t.Errorf("expected the old image to be used, got ReferenceUsed %v, warning %q", m.ReferenceUsed, m.ReferenceWarning)
+	}
+
+	m.Step = ImageGenStepDone
+	if !strings.Contains(m.View(), "Used existing image as reference") {
+		t.Error("done view should say the reference was used")
+	}
+}
+
+func TestImageGenModel_GenerateWithUnreadableReferenceFallsBack(t *testing.T) {
+	m := newReferenceTestModel(t, []byte("not an image"))
+	if !m.UseReference {
+		t.Fatal("expected UseReference to default to yes when the old image exists")
+	}
+
+	if cmd := m.generateImageCmd(); cmd == nil {
+		t.Fatal("expected generation to continue without the reference")
+	}
+	m.cancelGeneration()
+
+	if m.ReferenceUsed {
+		t.Error("expected the unreadable image not to be used as a reference")
+	}
+	if !strings.Contains(m.ReferenceWarning, "not a PNG, JPEG, GIF, or WebP image") {
+		t.Errorf("ReferenceWarning = %q", m.ReferenceWarning)
+	}
+
+	m.Step = ImageGenStepGenerating
+	m.IsGenerating = true
+	if !strings.Contains(m.View(), "Warning: Existing image not used as reference") {
+		t.Error("generating view should show the reference warning")
+	}
+
+	m.Step = ImageGenStepDone
+	if !strings.Contains(m.View(), "Existing image not used as reference") {
+		t.Error("done view should say the reference was not used")
+	}
+}
+
+func TestImageGenModel_RecordsProvider(t *testing.T) {
+	t.Setenv(imagegen.EnvProvider, "")
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	projectConfig := "imageGen:\n  provider: openai-compatible\n  baseURL: http://localhost:9000/v1\n  model: sdxl\n"
+	if err := os.WriteFile(filepath.Join(tmpDir, config.ProjectConfigFile), []byte(projectConfig), 0644); err != nil {
+		t.Fatalf("failed to write tap.yaml: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	if model.Provider.Provider != imagegen.ProviderOpenAICompatible || model.Provider.Model != "sdxl" {
+		t.Fatalf("expected the provider from tap.yaml, got %+v", model.Provider)
+	}
+
+	model.Prompt = "A lighthouse"
+	if err := model.InsertImageIntoMarkdown("images/a.png"); err != nil {
+		t.Fatalf("InsertImageIntoMarkdown failed: %v", err)
+	}
+	content := string(mustReadFile(t, mdFile))
+	if !strings.Contains(content, "<!-- ai-prompt: A lighthouse | provider: openai-compatible -->") {
+		t.Errorf("expected the provider in the prompt comment, got:\n%s", content)
+	}
+
+	// The provider is not part of the prompt when regenerating
+	images := parseAIImages(content)
+	if len(images) != 1 || images[0].Prompt != "A lighthouse" {
+		t.Errorf("unexpected images: %+v", images)
+	}
+
+	// Gemini, the default, is not recorded
+	model.Provider.Provider = imagegen.ProviderGemini
+	if got := model.promptComment(); got != "A lighthouse" {
+		t.Errorf("promptComment() = %q, want no provider for Gemini", got)
+	}
+}
+
+func TestImageGenModel_GenerateWithConfiguredProvider(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\nimage")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/images/generations" {
+			t.Errorf("unexpected request path %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `{"data":[{"b64_json":%q}]}`, base64.StdEncoding.EncodeToString(png))
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Slide"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	model.Provider = config.ImageGenConfig{Provider: imagegen.ProviderOpenAICompatible, BaseURL: server.URL + "/v1", Model: "sdxl"}
+	model.Prompt = "A lighthouse"
+
+	msg, ok := model.generateImageCmd()().(imageGenerateMsg)
+	if !ok {
+		t.Fatal("expected an imageGenerateMsg")
+	}
+	if msg.result.Error != nil {
+		t.Fatalf("unexpected error: %v", msg.result.Error)
+	}
+	if string(msg.result.ImageData) != string(png) || msg.result.ContentType != "image/png" {
+		t.Errorf("unexpected result: %q (%s)", msg.result.ImageData, msg.result.ContentType)
+	}
+
+	// A misconfigured provider is reported as an authentication error
+	model.Provider = config.ImageGenConfig{Provider: imagegen.ProviderOpenAICompatible}
+	msg = model.generateImageCmd()().(imageGenerateMsg)
+	if got := formatAPIError(msg.result.Error); !strings.Contains(got, "needs a base URL") {
+		t.Errorf("expected the configuration problem, got %q", got)
+	}
+}
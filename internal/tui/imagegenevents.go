@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxPromptPreviewLength is how many characters of the prompt the image
+// generator's events show.
+const maxPromptPreviewLength = 60
+
+// emitEvent passes an event to Events, if it is set. eventType is one of
+// the DevEvent types, such as "action" or "error".
+func (m *ImageGenModel) emitEvent(eventType, message string) {
+	if m.Events != nil {
+		m.Events(eventType, message)
+	}
+}
+
+// clock returns the current time, from now if it is set.
+func (m *ImageGenModel) clock() time.Time {
+	if m.now != nil {
+		return m.now()
+	}
+	return time.Now()
+}
+
+// generationStarted records when a generation of the prompt started and
+// reports it.
+func (m *ImageGenModel) generationStarted() {
+	m.generateStarted = m.clock()
+	m.generateElapsed = 0
+	m.emitEvent("action", fmt.Sprintf("Generating image: %q", promptPreview(m.Prompt)))
+}
+
+// generationFinished records how long the generation took, once its result
+// arrived.
+func (m *ImageGenModel) generationFinished() {
+	if !m.generateStarted.IsZero() {
+		m.generateElapsed = m.clock().Sub(m.generateStarted).Round(100 * time.Millisecond)
+	}
+}
+
+// generationFailed reports a failed generation with the message shown to the
+// user.
+func (m *ImageGenModel) generationFailed(message string) {
+	m.generationFinished()
+	m.emitEvent("error", fmt.Sprintf("Image generation failed after %s: %s", m.generateElapsed, message))
+}
+
+// imageSaved reports the generated image saved to path.
+func (m *ImageGenModel) imageSaved(path string) {
+	if m.FromCache {
+		m.emitEvent("action", fmt.Sprintf("Used cached image: %s", path))
+		return
+	}
+	m.emitEvent("action", fmt.Sprintf("Generated image in %s: %s", m.generateElapsed, path))
+}
+
+// slideNumber returns the one-based number of the selected slide, for events.
+func (m *ImageGenModel) slideNumber() int {
+	if slide := m.GetSelectedSlide(); slide != nil {
+		return slide.Index + 1
+	}
+	return m.SelectedIndex + 1
+}
+
+// promptPreview returns the prompt on one line, truncated to
+// maxPromptPreviewLength characters.
+func promptPreview(prompt string) string {
+	preview := strings.Join(strings.Fields(prompt), " ")
+	runes := []rune(preview)
+	if len(runes) <= maxPromptPreviewLength {
+		return preview
+	}
+	return strings.TrimRight(string(runes[:maxPromptPreviewLength-3]), " ") + "..."
+}
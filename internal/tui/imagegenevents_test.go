@@ -0,0 +1,129 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+)
+
+// openImageGenerator opens the image generator of a dev model for a deck
+// whose slide has an AI image with an existing file, and moves to the
+// prompt step to regenerate it. advance moves the dev model's clock forward.
+func openImageGenerator(t *testing.T) (model *DevModel, advance func(time.Duration)) {
+	t.Helper()
+	t.Setenv("GEMINI_API_KEY", "test-api-key")
+
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	markdown := "# Slide\n\n<!-- ai-prompt: a lighthouse at dusk -->\n![](images/old.png)\n"
+	if err := os.WriteFile(mdFile, []byte(markdown), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(tmpDir, "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "images", "old.png"), syntheticPNG(t, 2, 2), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	model = NewDevModel(DevConfig{MarkdownFile: mdFile})
+	now := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	model.now = func() time.Time { return now }
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("i")})
+	if !model.showImageGenerator {
+		t.Fatalf("expected the image generator to open, got error %v", model.state.Error)
+	}
+	model.imageGenModel.PreviewProtocol = PreviewHalfBlock
+
+	// Select the slide, then the regenerate option
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	model.Update(tea.KeyMsg{Type: tea.KeyDown})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.imageGenModel.Step != ImageGenStepPrompt || model.imageGenModel.SelectedImage == nil {
+		t.Fatalf("expected to regenerate at the prompt step, got step %d", model.imageGenModel.Step)
+	}
+	return model, func(d time.Duration) { now = now.Add(d) }
+}
+
+// eventsSince returns the messages of the events added after the first n.
+func eventsSince(model *DevModel, n int) []string {
+	var messages []string
+	for _, event := range model.state.RecentEvents[n:] {
+		messages = append(messages, fmt.Sprintf("%s: %s", event.Type, event.Message))
+	}
+	return messages
+}
+
+func TestDevModel_ImageGeneratorEvents(t *testing.T) {
+	model, advance := openImageGenerator(t)
+	start := len(model.state.RecentEvents)
+
+	// Submit the prompt, which starts generating
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !model.imageGenModel.IsGenerating {
+		t.Fatalf("expected generation to start, got step %d", model.imageGenModel.Step)
+	}
+
+	advance(4200 * time.Millisecond)
+	acceptGeneratedImage(t, model, syntheticPNG(t, 4, 4))
+	savedPath := model.imageGenModel.SavedImagePath
+
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.showImageGenerator {
+		t.Fatal("expected enter to close the image generator")
+	}
+
+	want := []string{
+		`action: Generating image: "a lighthouse at dusk"`,
+		"action: Generated image in 4.2s: " + savedPath,
+		fmt.Sprintf("reload: Replaced image images/old.png with %s on slide 1", savedPath),
+		"action: Deleted old image: images/old.png",
+		"action: Image generation complete: " + savedPath,
+	}
+	got := eventsSince(model, start)
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("events =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestDevModel_ImageGeneratorFailureEvent(t *testing.T) {
+	model, advance := openImageGenerator(t)
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	start := len(model.state.RecentEvents)
+
+	advance(2 * time.Second)
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{
+		Error: &imagegen.APIError{Type: imagegen.ErrorTypeNetwork, Message: "connection refused"},
+	}})
+
+	want := "error: Image generation failed after 2s: Network error. Please check your connection and try again."
+	if got := eventsSince(model, start); len(got) != 1 || got[0] != want {
+		t.Errorf("events = %q, want %q", got, want)
+	}
+
+	// Retrying reports a new start
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if got := eventsSince(model, start); len(got) != 2 || !strings.HasPrefix(got[1], "action: Generating image:") {
+		t.Errorf("events = %q, want a new start after retrying", got)
+	}
+}
+
+func TestPromptPreview(t *testing.T) {
+	if got := promptPreview("a  lighthouse\nat dusk"); got != "a lighthouse at dusk" {
+		t.Errorf("promptPreview() = %q, want it on one line", got)
+	}
+
+	long := strings.Repeat("word ", 30)
+	got := promptPreview(long)
+	if len([]rune(got)) > maxPromptPreviewLength || !strings.HasSuffix(got, "...") {
+		t.Errorf("promptPreview() = %q, want it truncated to %d characters", got, maxPromptPreviewLength)
+	}
+}
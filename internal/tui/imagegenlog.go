@@ -0,0 +1,140 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+)
+
+// failureLogName is the name of the log of failed generations in the images
+// directory. It starts with a dot so the dev server's watcher ignores it.
+const failureLogName = ".tap-imagegen.log"
+
+// maxFailureLogSize is the size the failure log is cut back to, keeping the
+// most recent entries, once it grows past it.
+const maxFailureLogSize = 1 << 20
+
+// failureLogMu serializes writes to failure logs, since each is written by a
+// command that runs alongside the others.
+var failureLogMu sync.Mutex
+
+// FailureLogEntry is a failed image generation, written to the failure log
+// as a line of JSON.
+type FailureLogEntry struct {
+	Time      time.Time              `json:"time"`
+	Prompt    string                 `json:"prompt"`
+	Provider  string                 `json:"provider,omitempty"`
+	ErrorType imagegen.ErrorType     `json:"errorType,omitempty"`
+	Message   string                 `json:"message"`
+	Details   *imagegen.ErrorDetails `json:"details,omitempty"`
+}
+
+// newFailureLogEntry returns the log entry for a generation from prompt that
+// failed with err.
+func newFailureLogEntry(prompt, provider string, err error, now time.Time) FailureLogEntry {
+	entry := FailureLogEntry{Time: now, Prompt: prompt, Provider: provider, Message: err.Error()}
+	var apiErr *imagegen.APIError
+	if errors.As(err, &apiErr) {
+		entry.ErrorType = apiErr.Type
+		entry.Message = apiErr.Message
+		entry.Details = apiErr.Details
+	}
+	return entry
+}
+
+// appendFailureLog appends entry to the log at path, creating the file and
+// its directory if needed. When the log grows past maxFailureLogSize, the
+// oldest entries are dropped.
+func appendFailureLog(path string, entry FailureLogEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode log entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	failureLogMu.Lock()
+	defer failureLogMu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create images directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open failure log: %w", err)
+	}
+	_, err = f.Write(line)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write failure log: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Size() <= maxFailureLogSize {
+		return nil
+	}
+	return rotateFailureLog(path)
+}
+
+// rotateFailureLog cuts the log at path back to its last maxFailureLogSize
+// bytes, starting at the first whole entry. The kept entries are written
+// through a temporary file, so a crash can't leave a partial log behind.
+func rotateFailureLog(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read failure log: %w", err)
+	}
+	if len(data) <= maxFailureLogSize {
+		return nil
+	}
+	data = data[len(data)-maxFailureLogSize:]
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[i+1:]
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), failureLogName+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to rotate failure log: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to rotate failure log: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to rotate failure log: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to rotate failure log: %w", err)
+	}
+	return nil
+}
+
+// FailureLogPath returns the path of the log of failed generations, in the
+// images directory next to the markdown file.
+func (m *ImageGenModel) FailureLogPath() string {
+	return filepath.Join(m.GetImagesDir(), failureLogName)
+}
+
+// logFailureCmd returns a command that records a generation from the current
+// prompt that failed with err in the failure log. The log is only there to
+// review later, so the command never reports an error.
+func (m *ImageGenModel) logFailureCmd(err error) tea.Cmd {
+	path := m.FailureLogPath()
+	entry := newFailureLogEntry(m.Prompt, m.Provider.Provider, err, time.Now())
+	return func() tea.Msg {
+		_ = appendFailureLog(path, entry)
+		return nil
+	}
+}
@@ -0,0 +1,131 @@
+package tui
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MiniCodeMonkey/tap/internal/imagegen"
+)
+
+// readFailureLog returns the entries in the failure log at path.
+func readFailureLog(t *testing.T, path string) []FailureLogEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("failed to open failure log: %v", err)
+	}
+	defer f.Close()
+
+	var entries []FailureLogEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry FailureLogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("failed to decode log line %q: %v", scanner.Text(), err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAppendFailureLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "images", failureLogName)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	blocked := &imagegen.APIError{
+		Type:    imagegen.ErrorTypeContentPolicy,
+		Message: "prompt was blocked: SAFETY",
+		Details: &imagegen.ErrorDetails{
+			BlockReason:   "SAFETY",
+			SafetyRatings: []imagegen.SafetyRating{{Category: "HARM_CATEGORY_DANGEROUS_CONTENT", Probability: "HIGH"}},
+		},
+	}
+	if err := appendFailureLog(path, newFailureLogEntry("a volcano erupting", "gemini", blocked, now)); err != nil {
+		t.Fatalf("appendFailureLog() returned error: %v", err)
+	}
+	if err := appendFailureLog(path, newFailureLogEntry("a cat", "openai", errors.New("no API key"), now)); err != nil {
+		t.Fatalf("appendFailureLog() returned error: %v", err)
+	}
+
+	entries := readFailureLog(t, path)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(entries))
+	}
+	first := entries[0]
+	if first.Prompt != "a volcano erupting" || first.Provider != "gemini" || first.ErrorType != imagegen.ErrorTypeContentPolicy || !first.Time.Equal(now) {
+		t.Errorf("unexpected first entry: %+v", first)
+	}
+	if first.Details == nil || first.Details.BlockReason != "SAFETY" || len(first.Details.SafetyRatings) != 1 {
+		t.Errorf("expected the block details to be logged, got %+v", first.Details)
+	}
+	if second := entries[1]; second.ErrorType != "" || second.Message != "no API key" || second.Details != nil {
+		t.Errorf("unexpected second entry: %+v", second)
+	}
+}
+
+func TestAppendFailureLogRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), failureLogName)
+	prompt := strings.Repeat("a very long prompt ", 500)
+	for i := range 150 {
+		entry := newFailureLogEntry(prompt, "gemini", fmt.Errorf("failure %d", i), time.Now())
+		if err := appendFailureLog(path, entry); err != nil {
+			t.Fatalf("appendFailureLog() returned error: %v", err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > maxFailureLogSize {
+		t.Errorf("expected the log cut back to %d bytes, got %d", maxFailureLogSize, info.Size())
+	}
+
+	// Only whole entries are kept, ending with the most recent
+	entries := readFailureLog(t, path)
+	if len(entries) == 0 || len(entries) == 150 {
+		t.Fatalf("expected the oldest entries dropped, got %d entries", len(entries))
+	}
+	if last := entries[len(entries)-1]; last.Message != "failure 149" {
+		t.Errorf("expected the most recent entry last, got %q", last.Message)
+	}
+}
+
+func TestImageGenModel_LogsFailedGeneration(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	model.Prompt = "a lighthouse"
+
+	_, cmd := model.handleImageGenerateResult(ImageGenerateResult{
+		Error: &imagegen.APIError{Type: imagegen.ErrorTypeServer, Message: "internal error"},
+	})
+	if cmd == nil {
+		t.Fatal("expected a command that logs the failure")
+	}
+	if msg := cmd(); msg != nil {
+		t.Errorf("expected the log command to report nothing, got %v", msg)
+	}
+
+	entries := readFailureLog(t, model.FailureLogPath())
+	if len(entries) != 1 || entries[0].Prompt != "a lighthouse" || entries[0].ErrorType != imagegen.ErrorTypeServer {
+		t.Errorf("expected the failure logged, got %+v", entries)
+	}
+}
+
+func TestImageGenModel_FailureLogErrorsAreSwallowed(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+
+	// A file where the images directory should be makes the log unwritable
+	if err := os.WriteFile(model.GetImagesDir(), []byte("not a directory"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if msg := model.logFailureCmd(errors.New("boom"))(); msg != nil {
+		t.Errorf("expected log errors to be swallowed, got %v", msg)
+	}
+}
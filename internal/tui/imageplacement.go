@@ -0,0 +1,173 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// ImagePlacement is where a new image is inserted within its slide.
+type ImagePlacement int
+
+const (
+	// PlacementEnd inserts the image at the end of the slide.
+	PlacementEnd ImagePlacement = iota
+	// PlacementAfterHeading inserts the image right after the slide's first heading.
+	PlacementAfterHeading
+	// PlacementBeforeParagraph inserts the image before the first paragraph
+	// of text, after the heading and any code blocks before it.
+	PlacementBeforeParagraph
+	// PlacementLeftColumn inserts the image at the end of the left column of a
+	// two-column slide, before the ||| separator.
+	PlacementLeftColumn
+	// PlacementRightColumn inserts the image at the end of the right column of
+	// a two-column slide.
+	PlacementRightColumn
+)
+
+// Label returns the name of the placement shown in the placement step.
+func (p ImagePlacement) Label() string {
+	switch p {
+	case PlacementAfterHeading:
+		return "After heading"
+	case PlacementBeforeParagraph:
+		return "Before first paragraph"
+	case PlacementLeftColumn:
+		return "Left column"
+	case PlacementRightColumn:
+		return "Right column"
+	default:
+		return "End of slide"
+	}
+}
+
+// placementOptions returns the placements offered for a slide. The column
+// placements are only offered for two-column slides.
+func placementOptions(twoColumn bool) []ImagePlacement {
+	options := []ImagePlacement{PlacementAfterHeading, PlacementEnd, PlacementBeforeParagraph}
+	if twoColumn {
+		options = append(options, PlacementLeftColumn, PlacementRightColumn)
+	}
+	return options
+}
+
+// slideHeadingRe matches an ATX heading line (# to ######).
+var slideHeadingRe = regexp.MustCompile(`^\s{0,3}#{1,6}(\s|$)`)
+
+// slideLine classifies a line of slide content for image placement.
+type slideLine struct {
+	text string
+	// content is false for lines inside fenced code blocks or comments, and
+	// for fence and comment lines themselves.
+	content bool
+}
+
+// classifySlideLines splits slide content into lines, marking the lines that
+// are outside fenced code blocks and HTML comments (such as directives and
+// ai-prompt comments). Images are only inserted before or after these lines,
+// so they never end up inside a code block or comment.
+func classifySlideLines(slideContent string) []slideLine {
+	texts := strings.Split(slideContent, "\n")
+	lines := make([]slideLine, len(texts))
+	insideCodeBlock := false
+	fenceLength := 0
+	insideComment := false
+
+	for i, text := range texts {
+		lines[i].text = text
+
+		wasInsideCodeBlock := insideCodeBlock
+		insideCodeBlock, fenceLength = parser.UpdateCodeFence(text, insideCodeBlock, fenceLength)
+		if wasInsideCodeBlock || insideCodeBlock {
+			// Fence lines and lines inside the block
+			continue
+		}
+
+		if insideComment || strings.HasPrefix(strings.TrimSpace(text), "<!--") {
+			rest := text
+			if !insideComment {
+				rest = text[strings.Index(text, "<!--")+len("<!--"):]
+			}
+			insideComment = !strings.Contains(rest, "-->")
+			continue
+		}
+
+		lines[i].content = true
+	}
+	return lines
+}
+
+// isColumnSeparator reports whether a line is the ||| separator of a two-column slide.
+func isColumnSeparator(line string) bool {
+	return strings.TrimSpace(line) == "|||"
+}
+
+// hasColumnSeparator reports whether slide content has a ||| column separator
+// outside fenced code blocks.
+func hasColumnSeparator(slideContent string) bool {
+	for _, line := range classifySlideLines(slideContent) {
+		if line.content && isColumnSeparator(line.text) {
+			return true
+		}
+	}
+	return false
+}
+
+// placementLine returns the index of the line before which an image is
+// inserted for placement, or -1 to insert it at the end of the slide.
+func placementLine(lines []slideLine, placement ImagePlacement) int {
+	switch placement {
+	case PlacementAfterHeading:
+		for i, line := range lines {
+			if line.content && slideHeadingRe.MatchString(line.text) {
+				return i + 1
+			}
+		}
+
+	case PlacementBeforeParagraph:
+		for i, line := range lines {
+			text := strings.TrimSpace(line.text)
+			if line.content && text != "" && !slideHeadingRe.MatchString(line.text) && !isColumnSeparator(text) {
+				return i
+			}
+		}
+
+	case PlacementLeftColumn:
+		for i, line := range lines {
+			if line.content && isColumnSeparator(line.text) {
+				return i
+			}
+		}
+	}
+
+	// End of slide, right column, or no line found for the placement
+	return -1
+}
+
+// insertAtPlacement inserts block into slide content at placement, separated
+// from the surrounding content by blank lines. Placements that can't be found
+// in the slide, such as after the heading of a slide without one, fall back to
+// the end of the slide.
+func insertAtPlacement(slideContent string, block string, placement ImagePlacement) string {
+	lines := classifySlideLines(slideContent)
+	at := placementLine(lines, placement)
+
+	texts := strings.Split(slideContent, "\n")
+	if at < 0 || at >= len(lines) || strings.TrimSpace(strings.Join(texts[at:], "\n")) == "" {
+		// Trim trailing whitespace but preserve structure
+		return strings.TrimRight(slideContent, " \t\n") + "\n\n" + block + "\n"
+	}
+
+	before := strings.Join(texts[:at], "\n")
+	after := strings.TrimLeft(strings.Join(texts[at:], "\n"), "\n")
+
+	if strings.TrimSpace(before) == "" {
+		// Inserting before the first line of the slide, keeping leading blank lines
+		if at > 0 {
+			before += "\n"
+		}
+		return before + block + "\n\n" + after
+	}
+	return strings.TrimRight(before, " \t\n") + "\n\n" + block + "\n\n" + after
+}
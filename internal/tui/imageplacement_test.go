@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const placementImage = "<!-- ai-prompt: a cat -->\n![](images/cat.png)"
+
+func TestInsertAtPlacement(t *testing.T) {
+	tests := []struct {
+		name      string
+		slide     string
+		placement ImagePlacement
+		expected  string
+	}{
+		{
+			name:      "end of slide",
+			slide:     "\n# Title\n\nText\n\n",
+			placement: PlacementEnd,
+			expected:  "\n# Title\n\nText\n\n" + placementImage + "\n",
+		},
+		{
+			name:      "after h2 heading",
+			slide:     "\n## Subtitle\n\nText\n",
+			placement: PlacementAfterHeading,
+			expected:  "\n## Subtitle\n\n" + placementImage + "\n\nText\n",
+		},
+		{
+			name:      "after heading followed by a code block",
+			slide:     "# Title\n```bash\n# not a heading\n---\n```\nText\n",
+			placement: PlacementAfterHeading,
+			expected:  "# Title\n\n" + placementImage + "\n\n```bash\n# not a heading\n---\n```\nText\n",
+		},
+		{
+			name:      "before first paragraph skips a code block after the heading",
+			slide:     "# Title\n```go\nfmt.Println(\"hi\")\n```\n\nText\n",
+			placement: PlacementBeforeParagraph,
+			expected:  "# Title\n```go\nfmt.Println(\"hi\")\n```\n\n" + placementImage + "\n\nText\n",
+		},
+		{
+			name:      "before first paragraph skips directive comments",
+			slide:     "<!--\nlayout: default\nnotes: |\n  # talking points\n-->\nText\n",
+			placement: PlacementBeforeParagraph,
+			expected:  "<!--\nlayout: default\nnotes: |\n  # talking points\n-->\n\n" + placementImage + "\n\nText\n",
+		},
+		{
+			name:      "before first paragraph at the start of the slide",
+			slide:     "\nText\n",
+			placement: PlacementBeforeParagraph,
+			expected:  "\n" + placementImage + "\n\nText\n",
+		},
+		{
+			name:      "heading only in a code block falls back to the end",
+			slide:     "```bash\n# comment\n```\n",
+			placement: PlacementAfterHeading,
+			expected:  "```bash\n# comment\n```\n\n" + placementImage + "\n",
+		},
+		{
+			name:      "heading at the end of the slide",
+			slide:     "# Title\n\n",
+			placement: PlacementAfterHeading,
+			expected:  "# Title\n\n" + placementImage + "\n",
+		},
+		{
+			name:      "left column",
+			slide:     "# Compare\n\nLeft text\n\n|||\n\nRight text\n",
+			placement: PlacementLeftColumn,
+			expected:  "# Compare\n\nLeft text\n\n" + placementImage + "\n\n|||\n\nRight text\n",
+		},
+		{
+			name:      "right column",
+			slide:     "# Compare\n\nLeft text\n\n|||\n\nRight text\n",
+			placement: PlacementRightColumn,
+			expected:  "# Compare\n\nLeft text\n\n|||\n\nRight text\n\n" + placementImage + "\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := insertAtPlacement(tt.slide, placementImage, tt.placement); got != tt.expected {
+				t.Errorf("insertAtPlacement() =\n%q\nwant\n%q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestInsertImageIntoSlide_Placement(t *testing.T) {
+	content := "---\ntitle: Test\n---\n\n# First\n\n---\n\n## Second\n\nBody text\n"
+
+	result, err := insertImageIntoSlide(content, 1, false, aiImageMarkdown{Prompt: "a cat", Path: "images/cat.png"}, PlacementAfterHeading)
+	if err != nil {
+		t.Fatalf("insertImageIntoSlide failed: %v", err)
+	}
+	if !strings.HasSuffix(result, "## Second\n\n"+placementImage+"\n\nBody text\n") {
+		t.Errorf("expected image after the second slide's heading, got:\n%s", result)
+	}
+	if strings.Count(result, "ai-prompt") != 1 {
+		t.Errorf("expected one image, got:\n%s", result)
+	}
+}
+
+func TestHasColumnSeparator(t *testing.T) {
+	if !hasColumnSeparator("Left\n\n|||\n\nRight") {
+		t.Error("expected ||| line to be a column separator")
+	}
+	if hasColumnSeparator("```\n|||\n```") {
+		t.Error("||| in a code block should not be a column separator")
+	}
+}
+
+func TestImageGenModel_PlacementStep(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Compare\n\nLeft\n\n|||\n\nRight\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(*ImageGenModel)
+	m.promptInput.SetValue("A chart")
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(*ImageGenModel)
+
+	if m.Step != ImageGenStepPlacement {
+		t.Fatalf("expected ImageGenStepPlacement, got %d", m.Step)
+	}
+	want := []ImagePlacement{PlacementAfterHeading, PlacementEnd, PlacementBeforeParagraph, PlacementLeftColumn, PlacementRightColumn}
+	if !reflect.DeepEqual(m.PlacementOptions, want) {
+		t.Errorf("PlacementOptions = %v, want %v", m.PlacementOptions, want)
+	}
+	if m.PlacementOptions[m.PlacementIndex] != PlacementEnd {
+		t.Errorf("expected end of slide to be preselected, got %s", m.PlacementOptions[m.PlacementIndex].Label())
+	}
+
+	// Esc returns to the prompt
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = newModel.(*ImageGenModel)
+	if m.Step != ImageGenStepPrompt {
+		t.Fatalf("expected ImageGenStepPrompt after esc, got %d", m.Step)
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(*ImageGenModel)
+	for i := 0; i < 2; i++ {
+		newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+		m = newModel.(*ImageGenModel)
+	}
+	newModel, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(*ImageGenModel)
+
+	if m.Step != ImageGenStepGenerating || cmd == nil {
+		t.Fatalf("expected generation to start, got step %d", m.Step)
+	}
+	if m.Placement != PlacementLeftColumn {
+		t.Errorf("Placement = %s, want Left column", m.Placement.Label())
+	}
+	m.cancelGeneration()
+}
+
+func TestImageGenModel_RegenerateSkipsPlacement(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "test.md")
+	if err := os.WriteFile(mdFile, []byte("# Title\n\n"+placementImage+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+
+	// Select the slide, then the regenerate option
+	newModel, _ := model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m := newModel.(*ImageGenModel)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = newModel.(*ImageGenModel)
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(*ImageGenModel)
+	if m.SelectedImage == nil {
+		t.Fatal("expected the existing image to be selected")
+	}
+
+	newModel, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = newModel.(*ImageGenModel)
+	if m.Step != ImageGenStepGenerating {
+		t.Errorf("expected regeneration to skip the placement step, got %d", m.Step)
+	}
+	m.cancelGeneration()
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"  // Register GIF decoding for previews
+	_ "image/jpeg" // Register JPEG decoding for previews
+	"image/png"
+	"strings"
+)
+
+// PreviewProtocol is the method used to draw an image preview in the terminal.
+type PreviewProtocol int
+
+const (
+	// PreviewHalfBlock draws the image with colored half-block characters,
+	// two pixels per cell. It works in any terminal with true color support.
+	PreviewHalfBlock PreviewProtocol = iota
+	// PreviewITerm2 uses the iTerm2 inline image protocol (also supported by WezTerm).
+	PreviewITerm2
+	// PreviewKitty uses the kitty graphics protocol.
+	PreviewKitty
+)
+
+// kittyChunkSize is the maximum payload size of a kitty graphics escape sequence.
+const kittyChunkSize = 4096
+
+// DetectPreviewProtocol picks the best preview protocol for the terminal
+// described by the environment. getenv is typically os.Getenv.
+func DetectPreviewProtocol(getenv func(string) string) PreviewProtocol {
+	switch {
+	case getenv("KITTY_WINDOW_ID") != "" || getenv("TERM") == "xterm-kitty":
+		return PreviewKitty
+	case getenv("TERM_PROGRAM") == "iTerm.app" || getenv("LC_TERMINAL") == "iTerm2" || getenv("TERM_PROGRAM") == "WezTerm":
+		return PreviewITerm2
+	default:
+		return PreviewHalfBlock
+	}
+}
+
+// RenderImagePreview renders image data as a terminal preview at most width
+// cells wide and height cells tall, keeping the image's aspect ratio.
+// PNG, JPEG, and GIF images are supported.
+func RenderImagePreview(data []byte, width, height int, protocol PreviewProtocol) (string, error) {
+	if width <= 0 || height <= 0 {
+		return "", fmt.Errorf("invalid preview size %dx%d", width, height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	cols, rows := previewSize(img.Bounds(), width, height)
+
+	switch protocol {
+	case PreviewITerm2:
+		return renderITerm2(data, cols, rows), nil
+	case PreviewKitty:
+		return renderKitty(img, cols, rows)
+	default:
+		return renderHalfBlocks(img, cols, rows), nil
+	}
+}
+
+// previewSize returns the number of columns and rows that fit the image within
+// width x height cells. Terminal cells are about twice as tall as they are wide,
+// so each cell covers one pixel column and two pixel rows of the scaled image.
+func previewSize(bounds image.Rectangle, width, height int) (int, int) {
+	imgW, imgH := bounds.Dx(), bounds.Dy()
+	if imgW == 0 || imgH == 0 {
+		return 1, 1
+	}
+
+	cols := width
+	rows := cols * imgH / imgW / 2
+	if rows > height {
+		rows = height
+		cols = rows * 2 * imgW / imgH
+	}
+	return max(cols, 1), max(rows, 1)
+}
+
+// renderHalfBlocks draws the image with "▀" characters, using the foreground
+// color for the upper pixel and the background color for the lower pixel.
+// Pixels are sampled with nearest-neighbor scaling.
+func renderHalfBlocks(img image.Image, cols, rows int) string {
+	bounds := img.Bounds()
+	pixel := func(x, y int) (uint32, uint32, uint32) {
+		px := bounds.Min.X + x*bounds.Dx()/cols
+		py := bounds.Min.Y + y*bounds.Dy()/(rows*2)
+		r, g, b, _ := img.At(px, py).RGBA()
+		return r >> 8, g >> 8, b >> 8
+	}
+
+	var sb strings.Builder
+	for row := 0; row < rows; row++ {
+		for col := 0; col < cols; col++ {
+			tr, tg, tb := pixel(col, row*2)
+			br, bg, bb := pixel(col, row*2+1)
+			fmt.Fprintf(&sb, "\x1b[38;2;%d;%d;%dm\x1b[48;2;%d;%d;%dm▀", tr, tg, tb, br, bg, bb)
+		}
+		sb.WriteString("\x1b[0m")
+		if row < rows-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// renderITerm2 returns the iTerm2 inline image escape sequence for the image,
+// sized in terminal cells.
+func renderITerm2(data []byte, cols, rows int) string {
+	return fmt.Sprintf("\x1b]1337;File=inline=1;size=%d;width=%d;height=%d;preserveAspectRatio=1:%s\a",
+		len(data), cols, rows, base64.StdEncoding.EncodeToString(data))
+}
+
+// renderKitty returns the kitty graphics escape sequences for the image, sized
+// in terminal cells. The image is sent as PNG in chunks.
+func renderKitty(img image.Image, cols, rows int) (string, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return "", fmt.Errorf("failed to encode preview: %w", err)
+	}
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var sb strings.Builder
+	for i := 0; i < len(payload); i += kittyChunkSize {
+		end := min(i+kittyChunkSize, len(payload))
+		more := 0
+		if end < len(payload) {
+			more = 1
+		}
+		if i == 0 {
+			fmt.Fprintf(&sb, "\x1b_Ga=T,f=100,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, payload[i:end])
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, payload[i:end])
+		}
+	}
+	return sb.String(), nil
+}
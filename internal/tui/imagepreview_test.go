@@ -0,0 +1,148 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/png"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// syntheticPNG returns a PNG with a red left half and a blue right half.
+func syntheticPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= width/2 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectPreviewProtocol(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		want PreviewProtocol
+	}{
+		{"plain terminal", map[string]string{"TERM": "xterm-256color"}, PreviewHalfBlock},
+		{"iTerm2", map[string]string{"TERM_PROGRAM": "iTerm.app"}, PreviewITerm2},
+		{"iTerm2 over ssh", map[string]string{"LC_TERMINAL": "iTerm2"}, PreviewITerm2},
+		{"WezTerm", map[string]string{"TERM_PROGRAM": "WezTerm"}, PreviewITerm2},
+		{"kitty", map[string]string{"TERM": "xterm-kitty"}, PreviewKitty},
+		{"kitty window", map[string]string{"KITTY_WINDOW_ID": "1"}, PreviewKitty},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectPreviewProtocol(func(key string) string { return tt.env[key] })
+			if got != tt.want {
+				t.Errorf("DetectPreviewProtocol() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderImagePreview_HalfBlock(t *testing.T) {
+	preview, err := RenderImagePreview(syntheticPNG(t, 8, 4), 8, 10, PreviewHalfBlock)
+	if err != nil {
+		t.Fatalf("RenderImagePreview() error = %v", err)
+	}
+
+	// An 8x4 image at 8 columns takes 2 rows of half blocks
+	lines := strings.Split(preview, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(lines))
+	}
+	for _, line := range lines {
+		if n := strings.Count(line, "▀"); n != 8 {
+			t.Errorf("expected 8 cells per row, got %d", n)
+		}
+		if !strings.HasSuffix(line, "\x1b[0m") {
+			t.Error("expected each row to reset colors")
+		}
+		if !strings.HasPrefix(line, "\x1b[38;2;255;0;0m\x1b[48;2;255;0;0m▀") {
+			t.Errorf("expected row to start with a red cell, got %q", line[:30])
+		}
+		if !strings.Contains(line, "\x1b[38;2;0;0;255m\x1b[48;2;0;0;255m▀\x1b[0m") {
+			t.Error("expected row to end with a blue cell")
+		}
+	}
+}
+
+func TestRenderImagePreview_FitsHeight(t *testing.T) {
+	// A tall image is limited by the height and keeps its aspect ratio
+	preview, err := RenderImagePreview(syntheticPNG(t, 4, 16), 40, 4, PreviewHalfBlock)
+	if err != nil {
+		t.Fatalf("RenderImagePreview() error = %v", err)
+	}
+	lines := strings.Split(preview, "\n")
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 rows, got %d", len(lines))
+	}
+	if n := strings.Count(lines[0], "▀"); n != 2 {
+		t.Errorf("expected 2 cells per row, got %d", n)
+	}
+}
+
+func TestRenderImagePreview_ITerm2(t *testing.T) {
+	data := syntheticPNG(t, 8, 4)
+	preview, err := RenderImagePreview(data, 8, 10, PreviewITerm2)
+	if err != nil {
+		t.Fatalf("RenderImagePreview() error = %v", err)
+	}
+	if !strings.HasPrefix(preview, "\x1b]1337;File=inline=1;") || !strings.HasSuffix(preview, "\a") {
+		t.Errorf("expected iTerm2 escape sequence, got %q", preview)
+	}
+	if !strings.Contains(preview, "width=8;height=2") {
+		t.Errorf("expected size in cells, got %q", preview)
+	}
+	if !strings.Contains(preview, base64.StdEncoding.EncodeToString(data)) {
+		t.Error("expected image data in the escape sequence")
+	}
+}
+
+func TestRenderImagePreview_Kitty(t *testing.T) {
+	// A noisy image produces a PNG large enough to need several chunks
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	rng := rand.New(rand.NewPCG(1, 2))
+	for i := range img.Pix {
+		img.Pix[i] = byte(rng.IntN(256))
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+
+	preview, err := RenderImagePreview(buf.Bytes(), 16, 8, PreviewKitty)
+	if err != nil {
+		t.Fatalf("RenderImagePreview() error = %v", err)
+	}
+	if !strings.HasPrefix(preview, "\x1b_Ga=T,f=100,c=16,r=8,m=1;") {
+		t.Errorf("expected kitty escape sequence with more chunks, got %q", preview[:40])
+	}
+	if strings.Count(preview, "m=0;") != 1 || !strings.HasSuffix(preview, "\x1b\\") {
+		t.Error("expected only the last chunk to end the transfer")
+	}
+}
+
+func TestRenderImagePreview_Errors(t *testing.T) {
+	if _, err := RenderImagePreview([]byte("not an image"), 8, 8, PreviewHalfBlock); err == nil {
+		t.Error("expected error for undecodable data")
+	}
+	if _, err := RenderImagePreview(syntheticPNG(t, 2, 2), 0, 8, PreviewHalfBlock); err == nil {
+		t.Error("expected error for zero width")
+	}
+}
@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// writeCheckPattern is the name pattern of the temporary file created to
+// check that generated images can be saved.
+const writeCheckPattern = ".tap-write-check-*"
+
+// CheckImagesDirWritable checks that generated images can be saved, by
+// creating and removing a temporary file in the images directory, or in the
+// markdown file's directory if there is no images directory yet. It is run
+// when the image generator opens, so a read-only deck is reported before
+// any image is paid for.
+func (m *ImageGenModel) CheckImagesDirWritable() error {
+	dir := m.GetImagesDir()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		dir = filepath.Dir(m.MarkdownFile)
+	}
+	return checkDirWritable(dir)
+}
+
+// checkDirWritable returns an error if a file can't be created in dir. The
+// error is an *fs.PathError for dir, so formatSaveError can describe it.
+func checkDirWritable(dir string) error {
+	file, err := os.CreateTemp(dir, writeCheckPattern)
+	if err != nil {
+		var pathErr *fs.PathError
+		if errors.As(err, &pathErr) {
+			err = pathErr.Err
+		}
+		return &fs.PathError{Op: "write", Path: dir, Err: err}
+	}
+	name := file.Name()
+	closeErr := file.Close()
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", name, err)
+	}
+	return closeErr
+}
+
+// checkWritable runs CheckImagesDirWritable and records the result in
+// WriteError. It reports whether images can be saved.
+func (m *ImageGenModel) checkWritable() bool {
+	m.WriteError = ""
+	if err := m.CheckImagesDirWritable(); err != nil {
+		m.WriteError = formatSaveError(err)
+	}
+	return m.WriteError == ""
+}
+
+// formatSaveError converts an error saving an image to a user-friendly
+// message, like formatAPIError does for API errors. A full disk and a folder
+// that can't be written to get their own messages; other errors are
+// returned as they are.
+func formatSaveError(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	path := "the images folder"
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) {
+		path = pathErr.Path
+	}
+
+	switch {
+	case errors.Is(err, syscall.ENOSPC):
+		return fmt.Sprintf("The disk is full, so %s can't be written. Free up some space and try again.", path)
+	case errors.Is(err, syscall.EROFS):
+		return fmt.Sprintf("%s is on a read-only file system. Copy the deck somewhere writable to add images.", path)
+	case errors.Is(err, fs.ErrPermission):
+		return fmt.Sprintf("Permission denied writing to %s. Check that the deck's folder isn't read-only.", path)
+	}
+	return fmt.Sprintf("Failed to save image: %v", err)
+}
+
+// isSaveError reports whether err is a file system error saving an image,
+// rather than an error from the image provider.
+func isSaveError(err error) bool {
+	var pathErr *fs.PathError
+	return errors.As(err, &pathErr)
+}
+
+// SaveFailed returns an accepted image that couldn't be saved to the review
+// step, showing why. The generated image is kept, so pressing r tries saving
+// it again without generating another.
+func (m *ImageGenModel) SaveFailed(err error) {
+	m.Error = formatSaveError(err)
+	m.saveFailed = true
+	m.Step = ImageGenStepReview
+	if m.preview == "" && m.previewErr == "" {
+		m.renderPreview()
+	}
+}
+
+// retrySave clears a save error and accepts the image again, so the parent
+// saves it.
+func (m *ImageGenModel) retrySave() {
+	m.Error = ""
+	m.saveFailed = false
+	m.Step = ImageGenStepDone
+}
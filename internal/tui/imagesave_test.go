@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// blockImagesDir makes the model's images directory unusable by putting a
+// file in its place. Unlike removing write permission, this also stops root.
+func blockImagesDir(t *testing.T, model *ImageGenModel) {
+	t.Helper()
+	if err := os.WriteFile(model.GetImagesDir(), []byte("not a directory"), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckImagesDirWritable(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	if model.WriteError != "" {
+		t.Fatalf("expected a writable deck, got %q", model.WriteError)
+	}
+
+	// Without an images directory, the markdown file's directory is checked
+	if err := model.CheckImagesDirWritable(); err != nil {
+		t.Errorf("expected the deck's directory to be writable, got %v", err)
+	}
+	if err := os.Mkdir(model.GetImagesDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := model.CheckImagesDirWritable(); err != nil {
+		t.Errorf("expected the images directory to be writable, got %v", err)
+	}
+
+	// The check leaves no files behind
+	entries, err := os.ReadDir(model.GetImagesDir())
+	if err != nil || len(entries) != 0 {
+		t.Errorf("expected an empty images directory, got %v (%v)", entries, err)
+	}
+}
+
+func TestCheckDirWritable_ReadOnly(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(dir, 0755) })
+
+	err := checkDirWritable(dir)
+	if got := formatSaveError(err); !strings.HasPrefix(got, "Permission denied writing to "+dir) {
+		t.Errorf("expected a permission error for %s, got %q", dir, got)
+	}
+}
+
+func TestFormatSaveError(t *testing.T) {
+	path := filepath.Join("deck", "images", "generated-1234abcd.png")
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{&fs.PathError{Op: "open", Path: path, Err: syscall.ENOSPC}, "The disk is full, so " + path},
+		{&fs.PathError{Op: "open", Path: path, Err: syscall.EACCES}, "Permission denied writing to " + path},
+		{&fs.PathError{Op: "open", Path: path, Err: syscall.EPERM}, "Permission denied writing to " + path},
+		{&fs.PathError{Op: "open", Path: path, Err: syscall.EROFS}, path + " is on a read-only file system"},
+		{fmt.Errorf("failed to ensure images directory: %w", &fs.PathError{Op: "mkdir", Path: "images", Err: syscall.EACCES}), "Permission denied writing to images"},
+		{fmt.Errorf("no generated image to save"), "Failed to save image: no generated image to save"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSaveError(tt.err); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("formatSaveError(%v) = %q, want prefix %q", tt.err, got, tt.want)
+		}
+	}
+
+	// API errors keep their messages; file system errors are described as save errors
+	full := &fs.PathError{Op: "write", Path: path, Err: syscall.ENOSPC}
+	if got := formatAPIError(full); got != formatSaveError(full) {
+		t.Errorf("expected formatAPIError to describe save errors, got %q", got)
+	}
+}
+
+func TestImageGenModel_WriteErrorBlocksGeneration(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	blockImagesDir(t, model)
+
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.Step != ImageGenStepSlideSelect {
+		t.Fatalf("expected to stay in slide selection, got step %d", model.Step)
+	}
+	if model.WriteError == "" || !strings.Contains(model.View(), model.WriteError) {
+		t.Errorf("expected the write error in the view, got:\n%s", model.View())
+	}
+
+	// Once the folder is fixed, the slide can be selected
+	if err := os.Remove(model.GetImagesDir()); err != nil {
+		t.Fatal(err)
+	}
+	model.handleKeyPress(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.Step != ImageGenStepPrompt || model.WriteError != "" {
+		t.Errorf("expected the prompt step without an error, got step %d (%q)", model.Step, model.WriteError)
+	}
+}
+
+func TestImageGenModel_RetrySave(t *testing.T) {
+	model := newConflictTestModel(t, "# Intro\n\nHello")
+	model.GeneratedImage = &ImageGenerateResult{ImageData: testPNG("retry"), ContentType: "image/png"}
+	model.Step = ImageGenStepDone
+	blockImagesDir(t, model)
+
+	_, err := model.SaveGeneratedImage()
+	if err == nil {
+		t.Fatal("expected saving to fail")
+	}
+	model.SaveFailed(err)
+	if model.Step != ImageGenStepReview || model.GeneratedImage == nil {
+		t.Fatalf("expected the image back in review, got step %d", model.Step)
+	}
+	if view := model.View(); !strings.Contains(view, "retry saving") || !strings.Contains(view, model.Error) {
+		t.Errorf("expected the save error and retry key in the view, got:\n%s", view)
+	}
+
+	// r tries saving the same image, rather than generating another
+	image := model.GeneratedImage
+	if _, cmd := model.handleKeyPress(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")}); cmd != nil {
+		t.Error("expected no generation request")
+	}
+	if model.Step != ImageGenStepDone || model.GeneratedImage != image || model.Error != "" {
+		t.Fatalf("expected the same image accepted again, got step %d (%q)", model.Step, model.Error)
+	}
+
+	if err := os.Remove(model.GetImagesDir()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := model.SaveGeneratedImage(); err != nil {
+		t.Errorf("expected the retried save to succeed, got %v", err)
+	}
+}
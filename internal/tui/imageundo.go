@@ -0,0 +1,162 @@
+package tui
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// trashDirName is the directory in the images directory that keeps images
+// replaced by regeneration, so the change can be undone. The watcher ignores
+// hidden directories, so moving images in and out doesn't trigger reloads.
+const trashDirName = ".tap-trash"
+
+// maxTrashEntries is the number of replaced images kept in the trash directory.
+const maxTrashEntries = 5
+
+// imageUndo records what accepting a generated image changed, so that the
+// change can be undone from the done step.
+type imageUndo struct {
+	file     string // Markdown file that was changed
+	content  []byte // Content of file before the change
+	newImage string // Saved image file
+	oldImage string // Regenerated image file, empty when adding a new image
+	trashed  string // Copy of oldImage in the trash directory
+	undone   bool
+}
+
+// SnapshotForUndo records the markdown file of the selected slide and, when
+// regenerating, copies the old image into the trash directory. It must be
+// called after the generated image is saved to savedPath, and before the
+// markdown is changed and the old image deleted.
+func (m *ImageGenModel) SnapshotForUndo(savedPath string) error {
+	m.undo = nil
+
+	file, _, err := m.slideTarget()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot markdown: %w", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("failed to snapshot markdown: %w", err)
+	}
+
+	undo := &imageUndo{
+		file:     file,
+		content:  content,
+		newImage: filepath.Join(filepath.Dir(m.MarkdownFile), filepath.FromSlash(savedPath)),
+	}
+
+	if m.oldImageExists() {
+		undo.oldImage = m.oldImagePath()
+		undo.trashed, err = m.trashImage(undo.oldImage)
+		if err != nil {
+			return err
+		}
+	}
+
+	m.undo = undo
+	return nil
+}
+
+// trashImage copies an image into the trash directory, named by the hash of
+// its content, and prunes the trash to the last maxTrashEntries images.
+// It returns the path of the copy.
+func (m *ImageGenModel) trashImage(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read old image: %w", err)
+	}
+
+	trashDir := filepath.Join(m.GetImagesDir(), trashDirName)
+	if err := os.MkdirAll(trashDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create trash directory: %w", err)
+	}
+
+	hash := sha256.Sum256(data)
+	trashed := filepath.Join(trashDir, hex.EncodeToString(hash[:8])+filepath.Ext(path))
+	if err := os.WriteFile(trashed, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to copy old image to trash: %w", err)
+	}
+
+	if err := pruneTrash(trashDir, maxTrashEntries); err != nil {
+		return "", err
+	}
+	return trashed, nil
+}
+
+// pruneTrash deletes all but the keep most recently modified files in dir.
+func pruneTrash(dir string, keep int) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read trash directory: %w", err)
+	}
+
+	type trashEntry struct {
+		path    string
+		modTime int64
+	}
+	var files []trashEntry
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, trashEntry{filepath.Join(dir, entry.Name()), info.ModTime().UnixNano()})
+	}
+	if len(files) <= keep {
+		return nil
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime > files[j].modTime })
+	for _, file := range files[keep:] {
+		if err := os.Remove(file.path); err != nil {
+			return fmt.Errorf("failed to prune trash: %w", err)
+		}
+	}
+	return nil
+}
+
+// CanUndo reports whether the last accepted image can be undone.
+func (m *ImageGenModel) CanUndo() bool {
+	return m.undo != nil && !m.undo.undone
+}
+
+// Undone reports whether the last accepted image was undone.
+func (m *ImageGenModel) Undone() bool {
+	return m.undo != nil && m.undo.undone
+}
+
+// UndoLastChange restores the markdown file to its content before the
+// generated image was accepted, deletes the saved image, and moves the
+// regenerated image back from the trash directory.
+func (m *ImageGenModel) UndoLastChange() error {
+	if !m.CanUndo() {
+		return fmt.Errorf("nothing to undo")
+	}
+	undo := m.undo
+
+	if err := os.WriteFile(undo.file, undo.content, 0644); err != nil {
+		return fmt.Errorf("failed to restore markdown file: %w", err)
+	}
+
+	// The new image is removed first, in case it has the same name as the old one
+	if err := os.Remove(undo.newImage); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete generated image: %w", err)
+	}
+
+	if undo.trashed != "" {
+		if err := os.Rename(undo.trashed, undo.oldImage); err != nil {
+			return fmt.Errorf("failed to restore old image: %w", err)
+		}
+	}
+
+	undo.undone = true
+	return nil
+}
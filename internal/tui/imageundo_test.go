@@ -0,0 +1,183 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// acceptGeneratedImage generates image data in the dev model's image generator
+// and accepts it, which saves the image and updates the markdown. Deleting an
+// image being regenerated is confirmed.
+func acceptGeneratedImage(t *testing.T, model *DevModel, data []byte) {
+	t.Helper()
+	model.imageGenModel.PreviewProtocol = PreviewHalfBlock
+	model.imageGenModel.Step = ImageGenStepGenerating
+	model.imageGenModel.IsGenerating = true
+	model.imageGenModel.Prompt = "a lighthouse at night"
+
+	model.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: data, ContentType: "image/png"}})
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.imageGenModel.confirm != nil {
+		// Confirm deleting the image being regenerated
+		model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	}
+	if model.imageGenModel.Step != ImageGenStepDone || model.imageGenModel.SavedImagePath == "" {
+		t.Fatalf("expected saved image after accepting, got step %d", model.imageGenModel.Step)
+	}
+}
+
+func lastEvent(model *DevModel) DevEvent {
+	return model.state.RecentEvents[len(model.state.RecentEvents)-1]
+}
+
+func TestDevModel_UndoRegeneratedImage(t *testing.T) {
+	oldImage := syntheticPNG(t, 2, 2)
+	imageGen := newReferenceTestModel(t, oldImage)
+	mdDir := filepath.Dir(imageGen.MarkdownFile)
+	oldImagePath := filepath.Join(mdDir, "images", "old.png")
+
+	original, err := os.ReadFile(imageGen.MarkdownFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	model := NewDevModel(DevConfig{MarkdownFile: imageGen.MarkdownFile})
+	model.showImageGenerator = true
+	model.imageGenModel = imageGen
+
+	acceptGeneratedImage(t, model, syntheticPNG(t, 4, 4))
+	newImagePath := filepath.Join(mdDir, filepath.FromSlash(model.imageGenModel.SavedImagePath))
+
+	// Regenerating replaced the markdown and moved the old image to the trash
+	if changed, _ := os.ReadFile(imageGen.MarkdownFile); bytes.Equal(changed, original) {
+		t.Fatal("expected the markdown to reference the new image")
+	}
+	if _, err := os.Stat(oldImagePath); !os.IsNotExist(err) {
+		t.Fatal("expected the old image to be deleted")
+	}
+	if !strings.Contains(model.imageGenModel.View(), "undo last change") {
+		t.Error("done view should offer to undo")
+	}
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+
+	restored, err := os.ReadFile(imageGen.MarkdownFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(restored, original) {
+		t.Errorf("markdown after undo = %q, want %q", restored, original)
+	}
+	if data, err := os.ReadFile(oldImagePath); err != nil || !bytes.Equal(data, oldImage) {
+		t.Errorf("expected the old image to be restored, got error %v", err)
+	}
+	if _, err := os.Stat(newImagePath); !os.IsNotExist(err) {
+		t.Error("expected the generated image to be deleted")
+	}
+	if entries, _ := os.ReadDir(filepath.Join(mdDir, "images", trashDirName)); len(entries) != 0 {
+		t.Errorf("expected the old image to be moved out of the trash, got %d entries", len(entries))
+	}
+	if event := lastEvent(model); event.Type != "reload" || !strings.Contains(event.Message, "Undid image change") {
+		t.Errorf("expected an undo event, got %+v", event)
+	}
+
+	// Undo works once, and closing doesn't report the image as generated
+	if model.imageGenModel.CanUndo() || !strings.Contains(model.imageGenModel.View(), "Change undone") {
+		t.Error("done view should show the change was undone")
+	}
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.showImageGenerator {
+		t.Fatal("expected enter to close the image generator")
+	}
+	if event := lastEvent(model); strings.Contains(event.Message, "Image generation complete") {
+		t.Errorf("undone image should not be reported as complete, got %+v", event)
+	}
+}
+
+func TestDevModel_UndoNewImage(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	original := []byte("# Slide 1\n\nContent\n\n---\n\n# Slide 2\n")
+	if err := os.WriteFile(mdFile, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	imageGen, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create image generator: %v", err)
+	}
+	imageGen.SaveToNotes = true
+
+	model := NewDevModel(DevConfig{MarkdownFile: mdFile})
+	model.showImageGenerator = true
+	model.imageGenModel = imageGen
+
+	acceptGeneratedImage(t, model, syntheticPNG(t, 4, 4))
+	newImagePath := filepath.Join(tmpDir, filepath.FromSlash(model.imageGenModel.SavedImagePath))
+
+	model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("u")})
+
+	restored, err := os.ReadFile(mdFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(restored, original) {
+		t.Errorf("markdown after undo = %q, want %q", restored, original)
+	}
+	if _, err := os.Stat(newImagePath); !os.IsNotExist(err) {
+		t.Error("expected the generated image to be deleted")
+	}
+
+	// Nothing was replaced, so nothing is trashed
+	if _, err := os.Stat(filepath.Join(tmpDir, "images", trashDirName)); !os.IsNotExist(err) {
+		t.Error("adding an image should not create the trash directory")
+	}
+}
+
+func TestImageGenModel_UndoWithoutChange(t *testing.T) {
+	m := newReferenceTestModel(t, nil)
+	if m.CanUndo() {
+		t.Error("nothing should be undoable before an image is accepted")
+	}
+	if err := m.UndoLastChange(); err == nil {
+		t.Error("expected an error when there is nothing to undo")
+	}
+}
+
+func TestPruneTrash(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	for i := 0; i < 8; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("image-%d.png", i))
+		if err := os.WriteFile(path, []byte{byte(i)}, 0644); err != nil {
+			t.Fatal(err)
+		}
+		modTime := now.Add(time.Duration(i) * time.Minute)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := pruneTrash(dir, maxTrashEntries); err != nil {
+		t.Fatalf("pruneTrash() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	if want := "image-3.png,image-4.png,image-5.png,image-6.png,image-7.png"; strings.Join(names, ",") != want {
+		t.Errorf("kept %v, want the 5 newest", names)
+	}
+}
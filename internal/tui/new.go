@@ -11,6 +11,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/MiniCodeMonkey/tap/internal/themes"
 )
 
 // newStep represents the current step in the new presentation wizard.
@@ -20,28 +21,15 @@ const (
 	stepTitle newStep = iota
 	stepTheme
 	stepFilename
+	stepTemplate
 	stepDone
 )
 
 // Theme represents a presentation theme option.
-type Theme struct {
-	Name        string
-	Description string
-}
+type Theme = themes.Theme
 
 // AvailableThemes lists the themes available for new presentations.
-var AvailableThemes = []Theme{
-	{Name: "paper", Description: "Ultra-clean, airy design with premium paper aesthetic"},
-	{Name: "noir", Description: "Cinematic film noir with sophisticated gold accents"},
-	{Name: "aurora", Description: "Vibrant northern lights with glassmorphism effects"},
-	{Name: "phosphor", Description: "Authentic CRT terminal with glowing phosphor green"},
-	{Name: "poster", Description: "Bold graphic design with massive typography"},
-	{Name: "signal", Description: "Vercel/Nuxt-inspired developer aesthetic with neon green accents"},
-	{Name: "carbon", Description: "IBM Carbon design system with sharp corners and red accents"},
-	{Name: "spectrum", Description: "Gradient-forward modern SaaS with indigo-to-pink spectrum"},
-	{Name: "mono", Description: "Ultra-minimal weight-contrast typography with blue accent"},
-	{Name: "flux", Description: "Polished SaaS product feel with warm indigo accents"},
-}
+var AvailableThemes = themes.BuiltIn
 
 // NewModel is the Bubble Tea model for creating new presentations.
 type NewModel struct { //nolint:govet // textinput.Model has complex alignment
@@ -56,16 +44,21 @@ type NewModel struct { //nolint:govet // textinput.Model has complex alignment
 	outputPath        string
 	prefilledTheme    string
 	prefilledFilename string
+	prefilledTemplate string
+	projectDir        string // Set when creating a presentation directory
+	createdFiles      []string
 
 	// State integers
-	windowWidth  int
-	windowHeight int
-	themeIndex   int
-	step         newStep
+	windowWidth   int
+	windowHeight  int
+	themeIndex    int
+	templateIndex int
+	step          newStep
 
 	// State booleans
 	quitting bool
 	done     bool
+	force    bool
 }
 
 // NewModelResult contains the result of the new presentation wizard.
@@ -73,6 +66,7 @@ type NewModelResult struct {
 	Title    string
 	Theme    string
 	Filename string
+	Template string // Set when a presentation directory was created
 	Aborted  bool
 }
 
@@ -124,6 +118,8 @@ func (m NewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateTheme(msg)
 	case stepFilename:
 		return m.updateFilename(msg)
+	case stepTemplate:
+		return m.updateTemplate(msg)
 	case stepDone:
 		return m, tea.Quit
 	}
@@ -145,6 +141,9 @@ func (m NewModel) updateTitle(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Skip to filename if theme was prefilled
 			if m.prefilledTheme != "" {
 				m.themeIndex = m.findThemeIndex(m.prefilledTheme)
+				if m.projectDir != "" {
+					return m.toTemplateStep()
+				}
 				m.step = stepFilename
 				// Set default filename based on title
 				m.setDefaultFilename()
@@ -177,6 +176,10 @@ func (m NewModel) updateTheme(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 		case "enter":
+			if m.projectDir != "" {
+				return m.toTemplateStep()
+			}
+
 			// Skip to done if filename was prefilled
 			if m.prefilledFilename != "" {
 				m.filenameInput.SetValue(m.prefilledFilename)
@@ -208,6 +211,10 @@ func (m NewModel) updateFilename(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m NewModel) finalize() (tea.Model, tea.Cmd) {
+	if m.projectDir != "" {
+		return m.createProject()
+	}
+
 	filename := strings.TrimSpace(m.filenameInput.Value())
 	if filename == "" {
 		filename = m.generateDefaultFilename()
@@ -298,6 +305,7 @@ func (m NewModel) GetResult() NewModelResult {
 		Title:    m.titleInput.Value(),
 		Theme:    AvailableThemes[m.themeIndex].Name,
 		Filename: m.filenameInput.Value(),
+		Template: m.templateName(),
 		Aborted:  m.quitting,
 	}
 }
@@ -323,6 +331,9 @@ func (m NewModel) View() string {
 		return RenderMuted("Aborted.\n")
 	}
 
+	if m.done && m.projectDir != "" {
+		return m.viewProjectSuccess()
+	}
 	if m.done {
 		return m.viewSuccess()
 	}
@@ -344,6 +355,8 @@ func (m NewModel) View() string {
 		b.WriteString(m.viewThemeStep())
 	case stepFilename:
 		b.WriteString(m.viewFilenameStep())
+	case stepTemplate:
+		b.WriteString(m.viewTemplateStep())
 	}
 
 	// Help text
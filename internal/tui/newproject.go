@@ -0,0 +1,175 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/MiniCodeMonkey/tap/internal/scaffold"
+)
+
+// NewProjectModel creates a NewModel that scaffolds the presentation directory
+// opts.Dir instead of writing a single file. The title is prefilled from
+// opts.Title or the directory name; the theme and template steps are skipped
+// if opts has them.
+func NewProjectModel(opts scaffold.Options) NewModel {
+	m := NewNewModel(opts.Theme, "")
+	m.projectDir = opts.Dir
+	m.prefilledTemplate = opts.Template
+	m.templateIndex = findTemplateIndex(opts.Template)
+	m.force = opts.Force
+
+	title := opts.Title
+	if title == "" {
+		title = scaffold.TitleFromDir(opts.Dir)
+	}
+	m.titleInput.SetValue(title)
+	m.titleInput.CursorEnd()
+	return m
+}
+
+// findTemplateIndex returns the index of a built-in template, or 0 if there is
+// no template with that name.
+func findTemplateIndex(name string) int {
+	for i, t := range scaffold.Templates {
+		if t.Name == strings.ToLower(name) {
+			return i
+		}
+	}
+	return 0
+}
+
+// templateName returns the selected template, or an empty string if the
+// wizard does not create a presentation directory.
+func (m NewModel) templateName() string {
+	if m.projectDir == "" {
+		return ""
+	}
+	return scaffold.Templates[m.templateIndex].Name
+}
+
+// toTemplateStep moves to the template step, or creates the presentation
+// directory right away if the template was prefilled.
+func (m NewModel) toTemplateStep() (tea.Model, tea.Cmd) {
+	if m.prefilledTemplate != "" {
+		return m.finalize()
+	}
+	m.step = stepTemplate
+	return m, nil
+}
+
+func (m NewModel) updateTemplate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "k":
+			if m.templateIndex > 0 {
+				m.templateIndex--
+			}
+		case "down", "j":
+			if m.templateIndex < len(scaffold.Templates)-1 {
+				m.templateIndex++
+			}
+		case "enter":
+			return m.finalize()
+		}
+	}
+	return m, nil
+}
+
+// createProject scaffolds the presentation directory.
+func (m NewModel) createProject() (tea.Model, tea.Cmd) {
+	result, err := scaffold.Create(scaffold.Options{
+		Dir:      m.projectDir,
+		Title:    strings.TrimSpace(m.titleInput.Value()),
+		Theme:    AvailableThemes[m.themeIndex].Name,
+		Template: m.templateName(),
+		Force:    m.force,
+	})
+	m.step = stepDone
+	if err != nil {
+		m.err = err
+		return m, tea.Quit
+	}
+
+	m.outputPath = result.DeckPath
+	m.createdFiles = result.Files
+	m.done = true
+	return m, tea.Quit
+}
+
+func (m NewModel) viewTemplateStep() string {
+	var b strings.Builder
+	b.WriteString(RenderSubtitle("Select a template:"))
+	b.WriteString("\n\n")
+
+	for i, t := range scaffold.Templates {
+		if i == m.templateIndex {
+			b.WriteString(RenderSelected(t.Name))
+			b.WriteString("\n")
+			descStyle := lipgloss.NewStyle().
+				Foreground(ColorMuted).
+				PaddingLeft(4)
+			b.WriteString(descStyle.Render(t.Description))
+		} else {
+			b.WriteString(RenderUnselected(t.Name))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(RenderHelp("Use ↑/↓ or j/k to navigate"))
+
+	return b.String()
+}
+
+func (m NewModel) viewProjectSuccess() string {
+	var b strings.Builder
+
+	b.WriteString(RenderSuccess("Presentation created successfully!"))
+	b.WriteString("\n\n")
+
+	absDir, _ := filepath.Abs(m.projectDir)
+	b.WriteString(fmt.Sprintf("  Directory: %s\n", RenderHighlight(absDir)))
+	for _, file := range m.createdFiles {
+		b.WriteString(fmt.Sprintf("    %s\n", file))
+	}
+	b.WriteString(fmt.Sprintf("  Title: %s\n", m.titleInput.Value()))
+	b.WriteString(fmt.Sprintf("  Theme: %s\n", AvailableThemes[m.themeIndex].Name))
+	b.WriteString(fmt.Sprintf("  Template: %s\n", m.templateName()))
+
+	b.WriteString("\n")
+	b.WriteString(RenderSubtitle("Next steps:"))
+	b.WriteString("\n")
+	b.WriteString(fmt.Sprintf("  1. Edit %s to add your content\n", m.outputPath))
+	b.WriteString(fmt.Sprintf("  2. Run %s to preview\n", RenderHighlight(fmt.Sprintf("tap dev %s", m.outputPath))))
+	b.WriteString(fmt.Sprintf("  3. Run %s to build static files\n", RenderHighlight(fmt.Sprintf("tap build %s", m.outputPath))))
+
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// RunNewProjectWizard runs the new presentation wizard for a presentation
+// directory and returns the result.
+func RunNewProjectWizard(opts scaffold.Options) (NewModelResult, error) {
+	model := NewProjectModel(opts)
+	p := tea.NewProgram(model)
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return NewModelResult{}, err
+	}
+
+	m, ok := finalModel.(NewModel)
+	if !ok {
+		return NewModelResult{}, fmt.Errorf("unexpected model type: %T", finalModel)
+	}
+	if m.err != nil {
+		return NewModelResult{}, m.err
+	}
+
+	return m.GetResult(), nil
+}
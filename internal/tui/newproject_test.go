@@ -0,0 +1,118 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/MiniCodeMonkey/tap/internal/scaffold"
+)
+
+// pressKey sends a key to the wizard and returns the updated model.
+func pressKey(m NewModel, key tea.KeyType) NewModel {
+	updated, _ := m.Update(tea.KeyMsg{Type: key})
+	return updated.(NewModel)
+}
+
+func TestNewProjectModel_TitleFromDir(t *testing.T) {
+	m := NewProjectModel(scaffold.Options{Dir: "talks/my-talk"})
+	if m.titleInput.Value() != "My Talk" {
+		t.Errorf("title = %q, want it prefilled from the directory name", m.titleInput.Value())
+	}
+
+	m = NewProjectModel(scaffold.Options{Dir: "my-talk", Title: "Custom"})
+	if m.titleInput.Value() != "Custom" {
+		t.Errorf("title = %q, want the given title", m.titleInput.Value())
+	}
+}
+
+func TestNewProjectModel_TemplatePicker(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my-talk")
+	m := NewProjectModel(scaffold.Options{Dir: dir})
+
+	m = pressKey(m, tea.KeyEnter) // title
+	if m.step != stepTheme {
+		t.Fatalf("step = %d, want the theme step", m.step)
+	}
+	m = pressKey(m, tea.KeyDown) // noir
+	m = pressKey(m, tea.KeyEnter)
+	if m.step != stepTemplate {
+		t.Fatalf("step = %d, want the template step", m.step)
+	}
+
+	view := m.View()
+	for _, tmpl := range scaffold.Templates {
+		if !strings.Contains(view, tmpl.Name) {
+			t.Errorf("template step should list %q", tmpl.Name)
+		}
+	}
+
+	m = pressKey(m, tea.KeyDown)
+	m = pressKey(m, tea.KeyDown)
+	m = pressKey(m, tea.KeyDown) // stays on the last template
+	m = pressKey(m, tea.KeyEnter)
+	if m.err != nil {
+		t.Fatalf("wizard error = %v", m.err)
+	}
+
+	result := m.GetResult()
+	if result.Theme != "noir" || result.Template != "workshop" || result.Title != "My Talk" {
+		t.Errorf("GetResult() = %+v", result)
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, scaffold.DeckFile))
+	if err != nil {
+		t.Fatalf("deck.md was not created: %v", err)
+	}
+	if !strings.Contains(string(content), "theme: noir") || !strings.Contains(string(content), "## Exercise 1") {
+		t.Error("deck.md should be rendered from the workshop template with the noir theme")
+	}
+	if !strings.Contains(m.View(), "Template: workshop") {
+		t.Error("success view should show the template")
+	}
+}
+
+func TestNewProjectModel_PrefilledSkipsSteps(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my-talk")
+	m := NewProjectModel(scaffold.Options{Dir: dir, Theme: "aurora", Template: "code-heavy"})
+
+	m = pressKey(m, tea.KeyEnter)
+	if !m.done {
+		t.Fatalf("wizard should be done after the title step, at step %d", m.step)
+	}
+	if m.GetResult().Template != "code-heavy" {
+		t.Errorf("Template = %q, want code-heavy", m.GetResult().Template)
+	}
+	if _, err := os.Stat(filepath.Join(dir, scaffold.ImagesDir)); err != nil {
+		t.Errorf("images directory was not created: %v", err)
+	}
+}
+
+func TestNewProjectModel_NonEmptyDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "existing.md"), nil, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	m := NewProjectModel(scaffold.Options{Dir: dir, Theme: "paper", Template: "basic"})
+	m = pressKey(m, tea.KeyEnter)
+	if m.err == nil || m.done {
+		t.Error("wizard should fail for a non-empty directory")
+	}
+
+	m = NewProjectModel(scaffold.Options{Dir: dir, Theme: "paper", Template: "basic", Force: true})
+	m = pressKey(m, tea.KeyEnter)
+	if m.err != nil || !m.done {
+		t.Errorf("wizard should succeed with Force, error = %v", m.err)
+	}
+}
+
+func TestNewModel_SingleFileHasNoTemplate(t *testing.T) {
+	m := NewNewModel("", "")
+	if m.GetResult().Template != "" {
+		t.Error("single-file wizard should not report a template")
+	}
+}
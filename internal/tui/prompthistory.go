@@ -0,0 +1,211 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxPromptHistory is the number of prompts kept in the prompt history.
+const maxPromptHistory = 100
+
+// PromptHistoryEntry is a prompt that generated an image.
+type PromptHistoryEntry struct {
+	Prompt string    `json:"prompt"`
+	Deck   string    `json:"deck"` // Absolute path of the presentation's markdown file
+	Time   time.Time `json:"time"`
+}
+
+// PromptHistory is the list of prompts that generated images, oldest first,
+// shared by all presentations and saved in a JSON file.
+type PromptHistory struct {
+	path    string
+	Entries []PromptHistoryEntry
+}
+
+// DefaultPromptHistoryPath returns the path of the prompt history file in the
+// user's config directory, such as ~/.config/tap/prompt_history.json, or ""
+// if there is no config directory.
+func DefaultPromptHistoryPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "tap", "prompt_history.json")
+}
+
+// LoadPromptHistory reads the prompt history from path. A missing or
+// corrupted file gives an empty history, which replaces the file on the
+// next save.
+func LoadPromptHistory(path string) *PromptHistory {
+	h := &PromptHistory{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return h
+	}
+	var entries []PromptHistoryEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return h
+	}
+	h.Entries = entries
+	return h
+}
+
+// Add records a prompt used for deck and saves the history. A prompt already
+// in the history for deck moves to the end, and the oldest entries are
+// dropped beyond maxPromptHistory.
+func (h *PromptHistory) Add(prompt, deck string, now time.Time) error {
+	entries := make([]PromptHistoryEntry, 0, len(h.Entries)+1)
+	for _, entry := range h.Entries {
+		if entry.Prompt != prompt || entry.Deck != deck {
+			entries = append(entries, entry)
+		}
+	}
+	entries = append(entries, PromptHistoryEntry{Prompt: prompt, Deck: deck, Time: now})
+	if len(entries) > maxPromptHistory {
+		entries = entries[len(entries)-maxPromptHistory:]
+	}
+	h.Entries = entries
+	return h.save()
+}
+
+// Prompts returns the prompts in the history, most recent first and without
+// duplicates. With deck set, only the prompts used for deck are returned.
+func (h *PromptHistory) Prompts(deck string) []string {
+	var prompts []string
+	seen := make(map[string]bool)
+	for i := len(h.Entries) - 1; i >= 0; i-- {
+		entry := h.Entries[i]
+		if (deck != "" && entry.Deck != deck) || seen[entry.Prompt] {
+			continue
+		}
+		seen[entry.Prompt] = true
+		prompts = append(prompts, entry.Prompt)
+	}
+	return prompts
+}
+
+// save writes the history to its file through a temporary file, so a crash
+// can't leave a partially written history behind.
+func (h *PromptHistory) save() error {
+	data, err := json.MarshalIndent(h.Entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode prompt history: %w", err)
+	}
+
+	dir := filepath.Dir(h.path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".prompt_history-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write prompt history: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write prompt history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write prompt history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), h.path); err != nil {
+		return fmt.Errorf("failed to save prompt history: %w", err)
+	}
+	return nil
+}
+
+// SetPromptHistory sets the history that submitted prompts are recalled
+// from and that prompts of successful generations are added to.
+func (m *ImageGenModel) SetPromptHistory(history *PromptHistory) {
+	m.history = history
+}
+
+// historyDeck returns the deck that recalled prompts are filtered to, or ""
+// for the prompts of all decks.
+func (m *ImageGenModel) historyDeck() string {
+	if m.HistoryGlobal {
+		return ""
+	}
+	return absPath(m.MarkdownFile)
+}
+
+// recallingPrompt reports whether the prompt input shows a recalled prompt
+// that hasn't been edited.
+func (m *ImageGenModel) recallingPrompt() bool {
+	return m.historyPos > 0 && m.promptInput.Value() == m.historyPrompts[m.historyPos-1]
+}
+
+// recallPrompt moves step prompts back (positive) or forward (negative) in
+// the history, showing the recalled prompt in the prompt input. Moving
+// forward past the most recent prompt restores the prompt being written.
+func (m *ImageGenModel) recallPrompt(step int) {
+	if m.history == nil {
+		return
+	}
+	if m.historyPos == 0 {
+		m.historyPrompts = m.history.Prompts(m.historyDeck())
+		m.historyDraft = m.promptInput.Value()
+	}
+
+	pos := m.historyPos + step
+	if pos < 0 || pos > len(m.historyPrompts) {
+		return
+	}
+	m.historyPos = pos
+	if pos == 0 {
+		m.promptInput.SetValue(m.historyDraft)
+	} else {
+		m.promptInput.SetValue(m.historyPrompts[pos-1])
+	}
+}
+
+// toggleHistoryScope switches between recalling the prompts of this deck and
+// those of all decks, starting again from the most recent prompt.
+func (m *ImageGenModel) toggleHistoryScope() {
+	m.HistoryGlobal = !m.HistoryGlobal
+	if m.historyPos > 0 {
+		m.promptInput.SetValue(m.historyDraft)
+	}
+	m.historyPos = 0
+}
+
+// recordPrompt adds the prompt of a successful generation to the history.
+func (m *ImageGenModel) recordPrompt() {
+	if m.history == nil || m.Prompt == "" {
+		return
+	}
+	// The history is a convenience, so failing to save it doesn't fail the generation
+	_ = m.history.Add(m.Prompt, absPath(m.MarkdownFile), time.Now())
+	m.historyPos = 0
+}
+
+// viewPromptHistory renders the history line of the prompt step, or "" when
+// there are no prompts to recall.
+func (m *ImageGenModel) viewPromptHistory() string {
+	if m.history == nil || len(m.history.Entries) == 0 {
+		return ""
+	}
+	scope := "this deck"
+	if m.HistoryGlobal {
+		scope = "all decks"
+	}
+
+	line := fmt.Sprintf("History: %d from %s", len(m.history.Prompts(m.historyDeck())), scope)
+	if m.historyPos > 0 {
+		line += fmt.Sprintf(" (showing %d of %d)", m.historyPos, len(m.historyPrompts))
+	}
+	return line
+}
+
+// absPath returns the absolute form of path, or path itself if it can't be
+// made absolute.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
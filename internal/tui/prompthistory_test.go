@@ -0,0 +1,234 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestPromptHistory_AddAndPrompts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tap", "prompt_history.json")
+	h := LoadPromptHistory(path)
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, add := range []struct{ prompt, deck string }{
+		{"a rocket", "/talks/a.md"},
+		{"a lighthouse", "/talks/b.md"},
+		{"a forest", "/talks/a.md"},
+		{"a rocket", "/talks/a.md"}, // Moves to the end
+		{"a rocket", "/talks/b.md"},
+	} {
+		if err := h.Add(add.prompt, add.deck, now); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	if got, want := h.Prompts("/talks/a.md"), []string{"a rocket", "a forest"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Prompts(a) = %v, want %v", got, want)
+	}
+	if got, want := h.Prompts(""), []string{"a rocket", "a forest", "a lighthouse"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Prompts(all) = %v, want %v", got, want)
+	}
+
+	// The history is saved, without temporary files left behind
+	loaded := LoadPromptHistory(path)
+	if !reflect.DeepEqual(loaded.Entries, h.Entries) {
+		t.Errorf("loaded entries = %+v, want %+v", loaded.Entries, h.Entries)
+	}
+	if !loaded.Entries[0].Time.Equal(now) {
+		t.Errorf("time = %v, want %v", loaded.Entries[0].Time, now)
+	}
+	files, _ := os.ReadDir(filepath.Dir(path))
+	if len(files) != 1 {
+		t.Errorf("expected only the history file, got %d files", len(files))
+	}
+}
+
+func TestPromptHistory_Capped(t *testing.T) {
+	h := LoadPromptHistory(filepath.Join(t.TempDir(), "prompt_history.json"))
+	for i := 0; i < maxPromptHistory+5; i++ {
+		if err := h.Add(fmt.Sprintf("prompt %d", i), "/talk.md", time.Now()); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	if len(h.Entries) != maxPromptHistory {
+		t.Fatalf("expected %d entries, got %d", maxPromptHistory, len(h.Entries))
+	}
+	if h.Entries[0].Prompt != "prompt 5" {
+		t.Errorf("oldest entry = %q, want %q", h.Entries[0].Prompt, "prompt 5")
+	}
+}
+
+func TestLoadPromptHistory_CorruptedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt_history.json")
+	if err := os.WriteFile(path, []byte(`[{"prompt": "a rock`), 0644); err != nil {
+		t.Fatalf("failed to write history: %v", err)
+	}
+
+	h := LoadPromptHistory(path)
+	if len(h.Entries) != 0 {
+		t.Fatalf("expected an empty history, got %+v", h.Entries)
+	}
+
+	// The next save replaces the corrupted file
+	if err := h.Add("a rocket", "/talk.md", time.Now()); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if got := LoadPromptHistory(path).Prompts(""); !reflect.DeepEqual(got, []string{"a rocket"}) {
+		t.Errorf("Prompts() = %v, want [a rocket]", got)
+	}
+}
+
+func TestPromptHistory_SaveError(t *testing.T) {
+	// The config directory can't be created under a file
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tap"), nil, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	h := LoadPromptHistory(filepath.Join(dir, "tap", "prompt_history.json"))
+	if err := h.Add("a rocket", "/talk.md", time.Now()); err == nil {
+		t.Error("expected an error when the history can't be saved")
+	}
+}
+
+// newHistoryTestModel returns an image generator in the prompt step of a deck
+// in dir, with a history holding prompts for it and another deck.
+func newHistoryTestModel(t *testing.T) (*ImageGenModel, *PromptHistory) {
+	t.Helper()
+	dir := t.TempDir()
+	mdFile := filepath.Join(dir, "talk.md")
+	if err := os.WriteFile(mdFile, []byte("# Test Slide\n"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	history := LoadPromptHistory(filepath.Join(dir, "prompt_history.json"))
+	for _, add := range []struct{ prompt, deck string }{
+		{"first", mdFile},
+		{"elsewhere", filepath.Join(dir, "other.md")},
+		{"second", mdFile},
+	} {
+		if err := history.Add(add.prompt, add.deck, time.Now()); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+
+	model, err := NewImageGenModel(mdFile)
+	if err != nil {
+		t.Fatalf("failed to create model: %v", err)
+	}
+	model.SetPromptHistory(history)
+	model.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if model.Step != ImageGenStepPrompt {
+		t.Fatalf("expected the prompt step, got %d", model.Step)
+	}
+	return model, history
+}
+
+func TestImageGenModel_RecallPromptWithArrows(t *testing.T) {
+	m, _ := newHistoryTestModel(t)
+
+	steps := []struct {
+		key  tea.KeyType
+		want string
+	}{
+		{tea.KeyUp, "second"},
+		{tea.KeyUp, "first"},
+		{tea.KeyUp, "first"}, // Stays at the oldest prompt
+		{tea.KeyDown, "second"},
+		{tea.KeyDown, ""},
+	}
+	for i, step := range steps {
+		m.Update(tea.KeyMsg{Type: step.key})
+		if got := m.promptInput.Value(); got != step.want {
+			t.Errorf("step %d: prompt = %q, want %q", i, got, step.want)
+		}
+	}
+}
+
+func TestImageGenModel_ArrowsMoveCursorInTypedPrompt(t *testing.T) {
+	m, _ := newHistoryTestModel(t)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("draft")})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if got := m.promptInput.Value(); got != "draft" {
+		t.Errorf("prompt = %q, want the draft to be kept", got)
+	}
+
+	// A recalled prompt that was edited isn't replaced by the arrows either
+	m.setPromptFields("", "", "")
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("!")})
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if got := m.promptInput.Value(); got != "second!" {
+		t.Errorf("prompt = %q, want %q", got, "second!")
+	}
+}
+
+func TestImageGenModel_RecallPromptWithCtrlKeys(t *testing.T) {
+	m, _ := newHistoryTestModel(t)
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("draft")})
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if got := m.promptInput.Value(); got != "second" {
+		t.Errorf("prompt = %q, want %q", got, "second")
+	}
+	if view := m.View(); !strings.Contains(view, "History: 2 from this deck (showing 1 of 2)") {
+		t.Errorf("expected the history line in the view, got:\n%s", view)
+	}
+
+	// Going forward past the most recent prompt restores the draft
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlN})
+	if got := m.promptInput.Value(); got != "draft" {
+		t.Errorf("prompt = %q, want %q", got, "draft")
+	}
+}
+
+func TestImageGenModel_RecallGlobalHistory(t *testing.T) {
+	m, _ := newHistoryTestModel(t)
+
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	if !m.HistoryGlobal {
+		t.Fatal("expected ctrl+g to recall the prompts of all decks")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	if got := m.promptInput.Value(); got != "elsewhere" {
+		t.Errorf("prompt = %q, want %q", got, "elsewhere")
+	}
+
+	// Switching back starts again from the prompt being written
+	m.Update(tea.KeyMsg{Type: tea.KeyCtrlG})
+	if m.HistoryGlobal || m.promptInput.Value() != "" {
+		t.Errorf("expected this deck's history and an empty prompt, got %v, %q", m.HistoryGlobal, m.promptInput.Value())
+	}
+}
+
+func TestImageGenModel_RecordsSuccessfulPrompts(t *testing.T) {
+	m, history := newHistoryTestModel(t)
+
+	// Failed generations aren't recorded
+	m.Step, m.IsGenerating, m.Prompt = ImageGenStepGenerating, true, "failed"
+	m.Update(imageGenerateMsg{result: ImageGenerateResult{Error: errors.New("quota exceeded")}})
+
+	m.Step, m.IsGenerating, m.Prompt = ImageGenStepGenerating, true, "third"
+	m.Update(imageGenerateMsg{result: ImageGenerateResult{ImageData: testPNG("image"), ContentType: "image/png"}})
+	if m.Step != ImageGenStepReview {
+		t.Fatalf("expected the review step, got %d", m.Step)
+	}
+
+	want := []string{"third", "second", "first"}
+	if got := history.Prompts(absPath(m.MarkdownFile)); !reflect.DeepEqual(got, want) {
+		t.Errorf("Prompts() = %v, want %v", got, want)
+	}
+	if got := LoadPromptHistory(history.path).Prompts(""); got[0] != "third" {
+		t.Errorf("expected the prompt to be saved, got %v", got)
+	}
+}
@@ -0,0 +1,533 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/MiniCodeMonkey/tap/internal/notes"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// SearchField is the part of a slide that a search result matched.
+type SearchField string
+
+const (
+	// SearchFieldTitle is the slide's title.
+	SearchFieldTitle SearchField = "title"
+	// SearchFieldBody is the slide's text, without markdown.
+	SearchFieldBody SearchField = "slide"
+	// SearchFieldNotes is the slide's speaker notes, without markdown.
+	SearchFieldNotes SearchField = "notes"
+)
+
+// searchFieldBonus ranks matches in titles above those in the slide's text,
+// and those above matches in the notes.
+var searchFieldBonus = map[SearchField]int{
+	SearchFieldTitle: 30,
+	SearchFieldBody:  10,
+	SearchFieldNotes: 0,
+}
+
+// maxSearchResults is the number of results the search shows.
+const maxSearchResults = 10
+
+// searchSnippetWidth is the number of characters of a matching line shown.
+const searchSnippetWidth = 70
+
+// SearchEntry is a slide as indexed by the search.
+type SearchEntry struct {
+	// Index is the zero-based slide index.
+	Index int
+	// Title is the slide title (first heading or first line).
+	Title string
+	// Body holds the lines of the slide's text, without markdown.
+	Body []string
+	// Notes holds the lines of the slide's speaker notes, without markdown.
+	Notes []string
+}
+
+// SearchResult is a slide matching the query, with its best matching line.
+type SearchResult struct {
+	// Index is the zero-based slide index.
+	Index int
+	// Title is the slide title.
+	Title string
+	// Field is the part of the slide the line is from.
+	Field SearchField
+	// Line is the best matching line.
+	Line string
+	// Positions are the indices of the runes of Line that matched the query.
+	Positions []int
+	// Score ranks the result; higher is better.
+	Score int
+}
+
+// SearchModel finds slides by fuzzy matching a query against their titles,
+// text, and speaker notes. The query matches a line when its characters
+// appear in the line in order, ignoring case; matches with the characters
+// close together, at the start of words, and in titles rank first.
+type SearchModel struct {
+	// input is the query input.
+	input textinput.Model
+	// entries is the index searched.
+	entries []SearchEntry
+	// results are the best matches for the query, best first.
+	results []SearchResult
+	// selected is the index in results of the highlighted result.
+	selected int
+	// done indicates the search was closed.
+	done bool
+	// chosen indicates the search was closed by choosing a result.
+	chosen bool
+}
+
+// NewSearchModel creates a search over entries.
+func NewSearchModel(entries []SearchEntry) *SearchModel {
+	ti := textinput.New()
+	ti.Prompt = "/"
+	ti.Placeholder = "search titles, slides, and notes"
+	ti.CharLimit = 100
+	ti.Width = 50
+	ti.Focus()
+
+	return &SearchModel{input: ti, entries: entries}
+}
+
+// SetEntries replaces the index searched, such as after the presentation
+// was reloaded, and runs the query again. The highlighted slide stays
+// highlighted if it still matches.
+func (m *SearchModel) SetEntries(entries []SearchEntry) {
+	selected, ok := m.Selected()
+	m.entries = entries
+	m.refresh()
+	if !ok {
+		return
+	}
+	for i, result := range m.results {
+		if result.Index == selected.Index {
+			m.selected = i
+			return
+		}
+	}
+}
+
+// Query returns the current query.
+func (m *SearchModel) Query() string {
+	return m.input.Value()
+}
+
+// Results returns the best matches for the query, best first.
+func (m *SearchModel) Results() []SearchResult {
+	return m.results
+}
+
+// Done reports whether the search was closed.
+func (m *SearchModel) Done() bool {
+	return m.done
+}
+
+// Chosen reports whether the search was closed by choosing a result.
+func (m *SearchModel) Chosen() bool {
+	return m.chosen
+}
+
+// Selected returns the highlighted result, if there is one.
+func (m *SearchModel) Selected() (SearchResult, bool) {
+	if m.selected < 0 || m.selected >= len(m.results) {
+		return SearchResult{}, false
+	}
+	return m.results[m.selected], true
+}
+
+// Init implements tea.Model.
+func (m *SearchModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Update implements tea.Model.
+// Enter chooses the highlighted result and esc closes the search; up and
+// down move between results, and other keys edit the query.
+func (m *SearchModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.done {
+		return m, nil
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "esc", "ctrl+c":
+			m.done = true
+			return m, nil
+		case "enter":
+			if len(m.results) > 0 {
+				m.done = true
+				m.chosen = true
+			}
+			return m, nil
+		case "up", "ctrl+p":
+			if m.selected > 0 {
+				m.selected--
+			}
+			return m, nil
+		case "down", "ctrl+n":
+			if m.selected < len(m.results)-1 {
+				m.selected++
+			}
+			return m, nil
+		}
+	}
+
+	query := m.input.Value()
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	if m.input.Value() != query {
+		m.refresh()
+		m.selected = 0
+	}
+	return m, cmd
+}
+
+// refresh runs the query against the index.
+func (m *SearchModel) refresh() {
+	m.results = searchEntries(m.entries, m.input.Value(), maxSearchResults)
+	if m.selected >= len(m.results) {
+		m.selected = max(len(m.results)-1, 0)
+	}
+}
+
+// View implements tea.Model.
+func (m *SearchModel) View() string {
+	var b strings.Builder
+
+	titleStyle := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorPrimary).
+		MarginBottom(1)
+
+	b.WriteString(titleStyle.Render("🔍 Search Slides"))
+	b.WriteString("\n\n")
+	b.WriteString(m.input.View())
+	b.WriteString("\n\n")
+
+	switch {
+	case strings.TrimSpace(m.input.Value()) == "":
+		b.WriteString(RenderMuted("Type to search slide titles, text, and speaker notes"))
+		b.WriteString("\n")
+	case len(m.results) == 0:
+		b.WriteString(RenderMuted("No matching slides"))
+		b.WriteString("\n")
+	}
+
+	numStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	titleTextStyle := lipgloss.NewStyle().Foreground(ColorWhite)
+	fieldStyle := lipgloss.NewStyle().Foreground(ColorMuted).Italic(true)
+	lineStyle := lipgloss.NewStyle().Foreground(ColorMuted)
+	matchStyle := lipgloss.NewStyle().Bold(true).Foreground(ColorWarning)
+	for i, result := range m.results {
+		slideNum := fmt.Sprintf("%2d.", result.Index+1)
+		if i == m.selected {
+			selectedNumStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(ColorPrimary)
+			selectedStyle := lipgloss.NewStyle().
+				Bold(true).
+				Foreground(ColorSecondary)
+			b.WriteString(selectedNumStyle.Render("> " + slideNum))
+			b.WriteString(" ")
+			b.WriteString(selectedStyle.Render(result.Title))
+		} else {
+			b.WriteString("  ")
+			b.WriteString(numStyle.Render(slideNum))
+			b.WriteString(" ")
+			b.WriteString(titleTextStyle.Render(result.Title))
+		}
+		b.WriteString(fieldStyle.Render(" [" + string(result.Field) + "]"))
+		b.WriteString("\n")
+
+		line, positions := searchSnippet(result.Line, result.Positions, searchSnippetWidth)
+		b.WriteString("     ")
+		b.WriteString(highlightRunes(line, positions, lineStyle, matchStyle))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	helpStyle := lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	keyStyle := lipgloss.NewStyle().
+		Foreground(ColorPrimary).
+		Bold(true)
+
+	help := fmt.Sprintf(
+		"%s/%s navigate • %s select • %s cancel",
+		keyStyle.Render("↑"),
+		keyStyle.Render("↓"),
+		keyStyle.Render("enter"),
+		keyStyle.Render("esc"),
+	)
+	b.WriteString(helpStyle.Render(help))
+
+	return b.String()
+}
+
+// highlightRunes renders line with the runes at positions in matchStyle and
+// the others in style.
+func highlightRunes(line string, positions []int, style, matchStyle lipgloss.Style) string {
+	matched := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		matched[p] = true
+	}
+
+	var b strings.Builder
+	var run []rune
+	runMatched := false
+	flush := func() {
+		if len(run) == 0 {
+			return
+		}
+		if runMatched {
+			b.WriteString(matchStyle.Render(string(run)))
+		} else {
+			b.WriteString(style.Render(string(run)))
+		}
+		run = run[:0]
+	}
+	for i, r := range []rune(line) {
+		if matched[i] != runMatched {
+			flush()
+			runMatched = matched[i]
+		}
+		run = append(run, r)
+	}
+	flush()
+	return b.String()
+}
+
+// searchSnippet returns at most width runes of line around the first match,
+// with "…" where it was cut, and the match positions within the snippet.
+func searchSnippet(line string, positions []int, width int) (string, []int) {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return line, positions
+	}
+
+	start := 0
+	if len(positions) > 0 {
+		// Show some context before the match
+		start = max(positions[0]-width/4, 0)
+	}
+	start = min(start, len(runes)-width)
+	end := start + width
+
+	prefix, suffix := "", ""
+	if start > 0 {
+		prefix = "…"
+		start++
+	}
+	if end < len(runes) {
+		suffix = "…"
+		end--
+	}
+
+	offset := start - len([]rune(prefix))
+	shifted := make([]int, 0, len(positions))
+	for _, p := range positions {
+		if p >= start && p < end {
+			shifted = append(shifted, p-offset)
+		}
+	}
+	return prefix + string(runes[start:end]) + suffix, shifted
+}
+
+// searchEntries returns the best matches for query in entries, at most limit,
+// best first. Each slide is listed once, with its best matching line. An
+// empty query matches nothing.
+func searchEntries(entries []SearchEntry, query string, limit int) []SearchResult {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil
+	}
+
+	var results []SearchResult
+	for _, entry := range entries {
+		best := SearchResult{Score: -1}
+		consider := func(field SearchField, lines []string) {
+			for _, line := range lines {
+				score, positions, ok := fuzzyMatch(line, query)
+				if !ok {
+					continue
+				}
+				score += searchFieldBonus[field]
+				if score > best.Score {
+					best = SearchResult{
+						Index:     entry.Index,
+						Title:     entry.Title,
+						Field:     field,
+						Line:      line,
+						Positions: positions,
+						Score:     score,
+					}
+				}
+			}
+		}
+		consider(SearchFieldTitle, []string{entry.Title})
+		consider(SearchFieldBody, entry.Body)
+		consider(SearchFieldNotes, entry.Notes)
+		if best.Score >= 0 {
+			results = append(results, best)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// fuzzyMatch reports whether the characters of query appear in text in
+// order, ignoring case, and returns a score for the match and the indices of
+// the matched runes of text. A query found as a substring scores highest.
+// Otherwise its words are matched in order, each as a substring if possible
+// and else character by character; gaps between matched characters lower
+// the score, and characters at the start of words raise it.
+func fuzzyMatch(text, query string) (int, []int, bool) {
+	textRunes := lowerRunes(text)
+	queryRunes := lowerRunes(strings.TrimSpace(query))
+	if len(queryRunes) == 0 {
+		return 0, nil, false
+	}
+
+	// Substring matches, such as whole words, rank first
+	if start := indexRunes(textRunes, queryRunes); start >= 0 {
+		score := 100 - min(start, 20)
+		if isWordStart(textRunes, start) {
+			score += 10
+		}
+		return score, runeRange(start, len(queryRunes)), true
+	}
+
+	positions := make([]int, 0, len(queryRunes))
+	score := 50
+	next := 0
+	for _, word := range strings.Fields(string(queryRunes)) {
+		wordRunes := []rune(word)
+		if start := indexRunes(textRunes[next:], wordRunes); start >= 0 {
+			positions = append(positions, runeRange(next+start, len(wordRunes))...)
+			next += start + len(wordRunes)
+			score += 5
+			continue
+		}
+
+		for _, r := range wordRunes {
+			i := slices.Index(textRunes[next:], r)
+			if i < 0 {
+				return 0, nil, false
+			}
+			i += next
+			if len(positions) > 0 {
+				score -= min(i-positions[len(positions)-1]-1, 10)
+			}
+			if isWordStart(textRunes, i) {
+				score += 3
+			}
+			positions = append(positions, i)
+			next = i + 1
+		}
+	}
+	return max(score, 1), positions, true
+}
+
+// runeRange returns the n indices starting at start.
+func runeRange(start, n int) []int {
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = start + i
+	}
+	return indices
+}
+
+// lowerRunes returns the runes of s in lower case. Unlike strings.ToLower,
+// it keeps the number of runes, so positions in it are positions in s.
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// indexRunes returns the index of the first occurrence of sub in runes, or
+// -1 if there is none.
+func indexRunes(runes, sub []rune) int {
+	for i := 0; i+len(sub) <= len(runes); i++ {
+		if slices.Equal(runes[i:i+len(sub)], sub) {
+			return i
+		}
+	}
+	return -1
+}
+
+// isWordStart reports whether the rune at i starts a word.
+func isWordStart(runes []rune, i int) bool {
+	return i == 0 || !unicode.IsLetter(runes[i-1]) && !unicode.IsDigit(runes[i-1])
+}
+
+// BuildSearchIndex reads the markdown file, with includes expanded, and
+// returns its slides for searching.
+func BuildSearchIndex(markdownFile string) ([]SearchEntry, error) {
+	content, err := os.ReadFile(markdownFile)
+	if err != nil {
+		if fileErr := CheckMarkdownFile(markdownFile); fileErr != nil {
+			return nil, fileErr
+		}
+		return nil, fmt.Errorf("failed to read markdown file: %w", err)
+	}
+
+	expanded, _, err := parser.ExpandIncludes(string(content), markdownFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand includes: %w", err)
+	}
+
+	p := parser.NewWithOptions(parser.Options{
+		StrictDelimiters: loadPresentationConfig(markdownFile).StrictDelimiters,
+	})
+	pres, err := p.Parse([]byte(expanded))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse slides: %w", err)
+	}
+	return searchIndex(pres), nil
+}
+
+// searchIndex returns the slides of a parsed presentation for searching.
+func searchIndex(pres *parser.Presentation) []SearchEntry {
+	entries := make([]SearchEntry, 0, len(pres.Slides))
+	for _, slide := range pres.Slides {
+		entries = append(entries, SearchEntry{
+			Index: slide.Index,
+			Title: extractSlideTitle(slide.Content),
+			Body:  searchLines(notes.StripMarkdown(slide.Content)),
+			Notes: searchLines(notes.StripMarkdown(slide.Directives.Notes)),
+		})
+	}
+	return entries
+}
+
+// searchLines returns the non-blank lines of text, trimmed.
+func searchLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,290 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchFixtureDeck is a deck with titles, formatted text, and speaker notes
+// in both forms.
+const searchFixtureDeck = `---
+title: Search Fixture
+---
+
+# Welcome
+
+Thanks for coming to **Kubernetes Basics**.
+
+???
+Introduce yourself and mention the workshop repo.
+
+---
+
+# Pods and Deployments
+
+- A pod runs one or more [containers](https://example.com)
+- Deployments roll out new versions
+
+---
+
+<!-- notes: Ask who has used Helm before -->
+# Packaging
+
+Charts bundle manifests.
+
+---
+
+# Questions
+
+` + "```bash\nkubectl get pods\n```"
+
+// writeSearchFixture writes the fixture deck to a temporary directory and
+// returns its path.
+func writeSearchFixture(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "slides.md")
+	if err := os.WriteFile(path, []byte(searchFixtureDeck), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	return path
+}
+
+// fixtureSearchIndex returns the search index of the fixture deck.
+func fixtureSearchIndex(t *testing.T) []SearchEntry {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(searchFixtureDeck))
+	if err != nil {
+		t.Fatalf("Parse() returned error: %v", err)
+	}
+	return searchIndex(pres)
+}
+
+// typeQuery types query into a search.
+func typeQuery(m *SearchModel, query string) {
+	for _, r := range query {
+		m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	entries := fixtureSearchIndex(t)
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+
+	welcome := entries[0]
+	if welcome.Title != "Welcome" {
+		t.Errorf("Title = %q, want %q", welcome.Title, "Welcome")
+	}
+	if !reflect.DeepEqual(welcome.Body, []string{"Welcome", "Thanks for coming to Kubernetes Basics."}) {
+		t.Errorf("Body = %q, want the text without markdown", welcome.Body)
+	}
+	if !reflect.DeepEqual(welcome.Notes, []string{"Introduce yourself and mention the workshop repo."}) {
+		t.Errorf("Notes = %q, want the notes after ???", welcome.Notes)
+	}
+
+	if got := entries[1].Body; !reflect.DeepEqual(got, []string{"Pods and Deployments", "- A pod runs one or more containers", "- Deployments roll out new versions"}) {
+		t.Errorf("Body = %q, want list items without the link markup", got)
+	}
+	if got := entries[2].Notes; !reflect.DeepEqual(got, []string{"Ask who has used Helm before"}) {
+		t.Errorf("Notes = %q, want the notes directive", got)
+	}
+	if got := entries[3].Body; !reflect.DeepEqual(got, []string{"Questions", "kubectl get pods"}) {
+		t.Errorf("Body = %q, want code lines kept", got)
+	}
+}
+
+func TestBuildSearchIndex(t *testing.T) {
+	entries, err := BuildSearchIndex(writeSearchFixture(t))
+	if err != nil {
+		t.Fatalf("BuildSearchIndex() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(entries, fixtureSearchIndex(t)) {
+		t.Errorf("BuildSearchIndex() = %+v, want the index of the parsed deck", entries)
+	}
+
+	if _, err := BuildSearchIndex(filepath.Join(t.TempDir(), "missing.md")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		text, query   string
+		wantPositions []int
+		wantOK        bool
+	}{
+		{"Pods and Deployments", "deploy", []int{9, 10, 11, 12, 13, 14}, true},
+		{"Pods and Deployments", "DEPLOY", []int{9, 10, 11, 12, 13, 14}, true},
+		{"Pods and Deployments", "pdd", []int{0, 2, 7}, true},
+		{"Pods and Deployments", "pods deploy", []int{0, 1, 2, 3, 9, 10, 11, 12, 13, 14}, true},
+		{"Pods and Deployments", "helm", nil, false},
+		{"Pods and Deployments", "   ", nil, false},
+		{"İstanbul talk", "talk", []int{9, 10, 11, 12}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, positions, ok := fuzzyMatch(tt.text, tt.query)
+			if ok != tt.wantOK {
+				t.Fatalf("fuzzyMatch(%q, %q) ok = %v, want %v", tt.text, tt.query, ok, tt.wantOK)
+			}
+			if !reflect.DeepEqual(positions, tt.wantPositions) {
+				t.Errorf("fuzzyMatch(%q, %q) positions = %v, want %v", tt.text, tt.query, positions, tt.wantPositions)
+			}
+		})
+	}
+}
+
+func TestFuzzyMatch_RanksCloserMatchesFirst(t *testing.T) {
+	substring, _, _ := fuzzyMatch("deploy the app", "deploy")
+	wordStarts, _, _ := fuzzyMatch("delete every pod", "dep")
+	scattered, _, _ := fuzzyMatch("modelled surplus", "dep")
+	if !(substring > wordStarts && wordStarts > scattered) {
+		t.Errorf("expected substring > word starts > scattered, got %d, %d, %d", substring, wordStarts, scattered)
+	}
+}
+
+func TestSearchEntries(t *testing.T) {
+	entries := fixtureSearchIndex(t)
+
+	tests := []struct {
+		query     string
+		wantSlide []int
+		wantField SearchField
+		wantLine  string
+	}{
+		// Matches the title, which ranks above the text of other slides
+		{"pods", []int{1, 3}, SearchFieldTitle, "Pods and Deployments"},
+		// Only in the notes
+		{"helm", []int{2}, SearchFieldNotes, "Ask who has used Helm before"},
+		// Only in the body, without markdown
+		{"kubernetes basics", []int{0}, SearchFieldBody, "Thanks for coming to Kubernetes Basics."},
+		// Fuzzy
+		{"wrkshp", []int{0}, SearchFieldNotes, "Introduce yourself and mention the workshop repo."},
+		{"zebra", nil, "", ""},
+		{"", nil, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			results := searchEntries(entries, tt.query, maxSearchResults)
+			var slides []int
+			for _, result := range results {
+				slides = append(slides, result.Index)
+			}
+			if !reflect.DeepEqual(slides, tt.wantSlide) {
+				t.Fatalf("slides = %v, want %v", slides, tt.wantSlide)
+			}
+			if len(results) == 0 {
+				return
+			}
+			if results[0].Field != tt.wantField || results[0].Line != tt.wantLine {
+				t.Errorf("best match = %s %q, want %s %q", results[0].Field, results[0].Line, tt.wantField, tt.wantLine)
+			}
+		})
+	}
+
+	if got := searchEntries(entries, "e", 2); len(got) != 2 {
+		t.Errorf("expected results limited to 2, got %d", len(got))
+	}
+}
+
+func TestSearchModel(t *testing.T) {
+	m := NewSearchModel(fixtureSearchIndex(t))
+	if !strings.Contains(m.View(), "Type to search") {
+		t.Errorf("expected a hint before typing, got:\n%s", m.View())
+	}
+
+	typeQuery(m, "pods")
+	if m.Query() != "pods" || len(m.Results()) != 2 {
+		t.Fatalf("expected 2 results for %q, got %+v", m.Query(), m.Results())
+	}
+	view := m.View()
+	if !strings.Contains(view, "Pods and Deployments") || !strings.Contains(view, "[title]") {
+		t.Errorf("expected the matching slide in the view, got:\n%s", view)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	if selected, _ := m.Selected(); selected.Index != 3 {
+		t.Errorf("expected down to stop at the last result, got slide %d", selected.Index)
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	if selected, _ := m.Selected(); selected.Index != 1 {
+		t.Errorf("expected up to select the first result, got slide %d", selected.Index)
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.Done() || !m.Chosen() {
+		t.Error("expected enter to choose the selected result")
+	}
+}
+
+func TestSearchModel_EnterWithoutResults(t *testing.T) {
+	m := NewSearchModel(fixtureSearchIndex(t))
+	typeQuery(m, "zebra")
+	if !strings.Contains(m.View(), "No matching slides") {
+		t.Errorf("expected no matches in the view, got:\n%s", m.View())
+	}
+
+	m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if m.Done() {
+		t.Error("expected enter without results to keep the search open")
+	}
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	if !m.Done() || m.Chosen() {
+		t.Error("expected esc to close the search without choosing")
+	}
+}
+
+func TestSearchModel_SetEntries(t *testing.T) {
+	entries := fixtureSearchIndex(t)
+	m := NewSearchModel(entries)
+	typeQuery(m, "pods")
+	m.Update(tea.KeyMsg{Type: tea.KeyDown})
+
+	// A slide inserted at the start moves the others
+	reloaded := append([]SearchEntry{{Index: 0, Title: "Agenda"}}, entries...)
+	for i := 1; i < len(reloaded); i++ {
+		reloaded[i].Index = i
+	}
+	m.SetEntries(reloaded)
+
+	if m.Query() != "pods" {
+		t.Errorf("expected the query to be kept, got %q", m.Query())
+	}
+	if selected, _ := m.Selected(); selected.Title != "Questions" || selected.Index != 4 {
+		t.Errorf("expected the highlighted slide to stay highlighted, got %+v", selected)
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	line := strings.Repeat("a", 40) + "match" + strings.Repeat("b", 40)
+	snippet, positions := searchSnippet(line, []int{40, 41, 42, 43, 44}, 20)
+
+	if len([]rune(snippet)) != 20 {
+		t.Errorf("expected a snippet of 20 runes, got %d: %q", len([]rune(snippet)), snippet)
+	}
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") {
+		t.Errorf("expected ellipses where the line was cut, got %q", snippet)
+	}
+	runes := []rune(snippet)
+	var matched strings.Builder
+	for _, p := range positions {
+		matched.WriteRune(runes[p])
+	}
+	if matched.String() != "match" {
+		t.Errorf("expected positions on the match, got %q", matched.String())
+	}
+
+	if short, _ := searchSnippet("short line", []int{0}, 20); short != "short line" {
+		t.Errorf("expected short lines unchanged, got %q", short)
+	}
+}
@@ -11,6 +11,8 @@ const (
 	ColorSecondary = lipgloss.Color("#10B981")
 	// Error color - red for error states and warnings.
 	ColorError = lipgloss.Color("#EF4444")
+	// Warning color - amber for states that need attention soon.
+	ColorWarning = lipgloss.Color("#F59E0B")
 	// Muted color - gray for secondary text and borders.
 	ColorMuted = lipgloss.Color("#6B7280")
 	// White color - for text on dark backgrounds.
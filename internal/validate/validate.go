@@ -0,0 +1,244 @@
+// Package validate checks tap presentations for common authoring mistakes.
+package validate
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+	"github.com/MiniCodeMonkey/tap/internal/transformer"
+)
+
+// DefaultMaxContentLength is the default maximum number of characters of
+// markdown content on a slide before it is reported as too long.
+const DefaultMaxContentLength = 1200
+
+// Severity indicates how serious an issue is.
+type Severity string
+
+const (
+	// SeverityError indicates a problem that breaks the presentation.
+	SeverityError Severity = "error"
+	// SeverityWarning indicates a likely mistake that does not break the presentation.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a presentation.
+type Issue struct {
+	Severity   Severity `json:"severity"`
+	Message    string   `json:"message"`
+	SlideIndex int      `json:"slideIndex"` // Zero-based index of the slide the issue was found on
+}
+
+// String returns the issue formatted as "slide N: message".
+func (i Issue) String() string {
+	return fmt.Sprintf("slide %d: %s", i.SlideIndex+1, i.Message)
+}
+
+// HasErrors reports whether any of the issues has error severity.
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Validator checks presentations against a configuration.
+type Validator struct {
+	config           *config.Config
+	maxContentLength int
+}
+
+// New creates a new Validator that checks code block drivers against cfg.
+func New(cfg *config.Config) *Validator {
+	if cfg == nil {
+		cfg = config.DefaultConfig()
+	}
+	return &Validator{
+		config:           cfg,
+		maxContentLength: DefaultMaxContentLength,
+	}
+}
+
+// SetMaxContentLength sets the maximum number of characters of markdown
+// content allowed on a slide. A value of 0 or less disables the check.
+func (v *Validator) SetMaxContentLength(n int) {
+	v.maxContentLength = n
+}
+
+// imgSrcPattern matches img src attributes in rendered slide HTML.
+var imgSrcPattern = regexp.MustCompile(`<img\s[^>]*src=["']([^"']+)["']`)
+
+// aiPromptPattern matches an ai-prompt comment and checks whether an image follows it.
+var aiPromptPattern = regexp.MustCompile(`<!--\s*ai-prompt:\s*(.*?)\s*-->(\s*\n[ \t]*!\[[^\]]*\]\([^)]+\))?`)
+
+// Validate checks a presentation and returns the issues found, ordered by slide.
+// Relative image and background paths are resolved against baseDir.
+func (v *Validator) Validate(pres *parser.Presentation, baseDir string) []Issue {
+	var issues []Issue
+	titles := make(map[string]int)
+
+	// Slides in several languages should be written in all of them
+	languages := transformer.Languages(pres, v.config.Languages)
+	untranslated := make(map[int][]string)
+	for _, missing := range transformer.MissingTranslations(pres, languages) {
+		untranslated[missing.SlideIndex] = missing.Languages
+	}
+
+	brokenAnchors := make(map[int][]string)
+	for _, broken := range transformer.BrokenAnchors(pres) {
+		brokenAnchors[broken.SlideIndex] = append(brokenAnchors[broken.SlideIndex], broken.Anchor)
+	}
+
+	for i, slide := range pres.Slides {
+		add := func(severity Severity, format string, args ...any) {
+			issues = append(issues, Issue{
+				Severity:   severity,
+				Message:    fmt.Sprintf(format, args...),
+				SlideIndex: slide.Index,
+			})
+		}
+
+		content := strings.TrimSpace(slide.Content)
+		if content == "" {
+			add(SeverityWarning, "slide is empty")
+		}
+
+		// Duplicate titles make slides hard to tell apart in the outline
+		if title := parser.SlideTitle(slide.HTML); title != "" {
+			if first, exists := titles[title]; exists {
+				add(SeverityWarning, "duplicate title %q (same as slide %d)", title, first+1)
+			} else {
+				titles[title] = slide.Index
+			}
+		}
+
+		for _, match := range imgSrcPattern.FindAllStringSubmatch(slide.HTML, -1) {
+			src := html.UnescapeString(match[1])
+			if path, ok := localPath(src, baseDir); ok && !fileExists(path) {
+				add(SeverityError, "image %s not found", src)
+			}
+		}
+
+		for _, anchor := range brokenAnchors[slide.Index] {
+			add(SeverityWarning, "link to #%s does not match any heading", anchor)
+		}
+
+		for _, match := range aiPromptPattern.FindAllStringSubmatch(slide.Content, -1) {
+			if match[2] == "" {
+				add(SeverityWarning, "ai-prompt %q is not followed by an image", match[1])
+			}
+		}
+
+		// Videos are checked first, like the transformer does
+		if bg := slide.Directives.Background; transformer.IsVideoPath(bg) {
+			if path, ok := localPath(bg, baseDir); ok && !fileExists(path) {
+				add(SeverityError, "background video %s not found", bg)
+			}
+		} else if isImagePath(bg) {
+			if path, ok := localPath(bg, baseDir); ok && !fileExists(path) {
+				add(SeverityError, "background image %s not found", bg)
+			}
+		}
+
+		for _, block := range slide.CodeBlocks {
+			if block.Meta.Driver == "" || block.Meta.Connection == "" {
+				continue
+			}
+			if _, err := v.config.DriverConnection(block.Meta.Driver, block.Meta.Connection); err != nil {
+				add(SeverityError, "%v", err)
+			}
+		}
+
+		if _, _, err := transformer.SlideTransition(slide.Directives); err != nil {
+			add(SeverityWarning, "%v; the presentation's transition is used instead", err)
+		}
+
+		if _, _, err := transformer.SlideAutoAdvance(slide.Directives); err != nil {
+			add(SeverityWarning, "%v; the presentation's autoAdvance is used instead", err)
+		}
+
+		_, invalidClasses := transformer.SplitClasses(slide.Directives.Class)
+		for _, name := range invalidClasses {
+			add(SeverityWarning, "invalid class %q; class names may only contain letters, digits, - and _", name)
+		}
+
+		if slide.Embed != nil {
+			if err := slide.Embed.Validate(); err != nil {
+				add(SeverityError, "%v", err)
+			}
+		}
+
+		if slide.Directives.Fragments && !strings.Contains(slide.HTML, "<li") && len(slide.Fragments) <= 1 {
+			add(SeverityWarning, "fragments directive has no pause markers or list items")
+		}
+
+		if table := transformer.WidestTable(slide.HTML); table.Columns > transformer.MaxTableColumns {
+			add(SeverityWarning, "table has %d columns (limit %d); consider splitting it", table.Columns, transformer.MaxTableColumns)
+		} else if table.Wide() {
+			add(SeverityWarning, "table is about %d characters wide (limit %d); consider shortening its cells",
+				table.Width, transformer.MaxTableWidth)
+		}
+
+		for _, lang := range untranslated[i] {
+			add(SeverityWarning, "missing translation for %s; the default language is shown instead", lang)
+		}
+		if len(v.config.Languages) > 0 {
+			for _, lang := range transformer.SlideLanguages(slide) {
+				if !slices.Contains(v.config.Languages, lang) {
+					add(SeverityWarning, "language %q is not one of the languages option (%s)",
+						lang, strings.Join(v.config.Languages, ", "))
+				}
+			}
+		}
+
+		if v.maxContentLength > 0 && len(content) > v.maxContentLength {
+			add(SeverityWarning, "slide content is %d characters (limit %d); consider splitting it",
+				len(content), v.maxContentLength)
+		}
+	}
+
+	return issues
+}
+
+// localPath resolves a referenced path to a file on disk. It returns false for
+// URLs and data URIs, which are not checked.
+func localPath(ref, baseDir string) (string, bool) {
+	lower := strings.ToLower(ref)
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") ||
+		strings.HasPrefix(lower, "data:") || strings.HasPrefix(ref, "//") {
+		return "", false
+	}
+
+	if unescaped, err := url.PathUnescape(ref); err == nil {
+		ref = unescaped
+	}
+	if filepath.IsAbs(ref) {
+		return ref, true
+	}
+	return filepath.Join(baseDir, ref), true
+}
+
+// isImagePath reports whether a background value refers to an image file.
+func isImagePath(value string) bool {
+	switch strings.ToLower(filepath.Ext(value)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".svg", ".webp":
+		return true
+	}
+	return false
+}
+
+// fileExists reports whether path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,347 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MiniCodeMonkey/tap/internal/config"
+	"github.com/MiniCodeMonkey/tap/internal/parser"
+)
+
+// parse parses markdown content into a presentation, failing the test on error.
+func parse(t *testing.T, content string) *parser.Presentation {
+	t.Helper()
+	pres, err := parser.New().Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return pres
+}
+
+// findIssue returns the first issue whose message contains substr.
+func findIssue(issues []Issue, substr string) (Issue, bool) {
+	for _, issue := range issues {
+		if strings.Contains(issue.Message, substr) {
+			return issue, true
+		}
+	}
+	return Issue{}, false
+}
+
+func TestValidate_CleanPresentation(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "photo.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pres := parse(t, "# Intro\n\n![](photo.png)\n\n---\n\n# Details\n\n- One\n- Two")
+	issues := New(config.DefaultConfig()).Validate(pres, dir)
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %v", issues)
+	}
+	if HasErrors(issues) {
+		t.Error("HasErrors() should be false without issues")
+	}
+}
+
+func TestValidate_MissingImages(t *testing.T) {
+	dir := t.TempDir()
+	pres := parse(t, "# Intro\n\n![](images/missing%20file.png)\n\n![](https://example.com/remote.png)\n\n---\n\n<!-- background: images/bg.jpg -->\n\n# Background\n\n---\n\n<!-- background: {src: media/loop.mp4, loop: true} -->\n\n# Video")
+
+	issues := New(nil).Validate(pres, dir)
+
+	issue, ok := findIssue(issues, "image images/missing%20file.png not found")
+	if !ok || issue.Severity != SeverityError || issue.SlideIndex != 0 {
+		t.Errorf("expected error for missing image on slide 1, got %v", issues)
+	}
+	if _, ok := findIssue(issues, "remote.png"); ok {
+		t.Error("absolute URLs should not be checked")
+	}
+	issue, ok = findIssue(issues, "background image images/bg.jpg not found")
+	if !ok || issue.Severity != SeverityError || issue.SlideIndex != 1 {
+		t.Errorf("expected error for missing background on slide 2, got %v", issues)
+	}
+	issue, ok = findIssue(issues, "background video media/loop.mp4 not found")
+	if !ok || issue.Severity != SeverityError || issue.SlideIndex != 2 {
+		t.Errorf("expected error for missing background video on slide 3, got %v", issues)
+	}
+	if !HasErrors(issues) {
+		t.Error("HasErrors() should be true")
+	}
+}
+
+func TestValidate_BackgroundVideoURLs(t *testing.T) {
+	dir := t.TempDir()
+	pres := parse(t, "<!-- background: https://cdn.example.com/x/loop.mp4?v=1 -->\n\n# Remote\n\n---\n\n<!-- background: media/clip.MOV -->\n\n# Local")
+
+	issues := New(nil).Validate(pres, dir)
+
+	// Like the transformer, the query string is ignored, so the URL is a
+	// video, and remote videos are not checked
+	if _, ok := findIssue(issues, "cdn.example.com"); ok {
+		t.Errorf("expected no issue for the remote video, got %v", issues)
+	}
+	issue, ok := findIssue(issues, "background video media/clip.MOV not found")
+	if !ok || issue.SlideIndex != 1 {
+		t.Errorf("expected error for missing background video on slide 2, got %v", issues)
+	}
+}
+
+func TestValidate_AIPromptWithoutImage(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "cat.png"), []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pres := parse(t, "# Slide\n\n<!-- ai-prompt: a cat -->\n![](cat.png)\n\n<!-- ai-prompt: a dog -->\n\nText")
+
+	issues := New(nil).Validate(pres, dir)
+	if _, ok := findIssue(issues, `"a cat"`); ok {
+		t.Error("ai-prompt followed by an image should not be reported")
+	}
+	issue, ok := findIssue(issues, `ai-prompt "a dog" is not followed by an image`)
+	if !ok || issue.Severity != SeverityWarning {
+		t.Errorf("expected warning for dangling ai-prompt, got %v", issues)
+	}
+}
+
+func TestValidate_UndefinedConnection(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Drivers["mysql"] = config.DriverConfig{
+		Connections: map[string]config.ConnectionConfig{"prod": {Host: "db"}},
+	}
+	pres := parse(t, "# Query\n\n```sql {driver: mysql, connection: prod}\nSELECT 1;\n```\n\n```sql {driver: mysql, connection: staging}\nSELECT 2;\n```\n\n```sql {driver: postgres, connection: main}\nSELECT 3;\n```")
+
+	issues := New(cfg).Validate(pres, t.TempDir())
+	if _, ok := findIssue(issues, `"prod"`); ok {
+		t.Error("defined connection should not be reported")
+	}
+	if _, ok := findIssue(issues, `connection "staging" for driver "mysql"`); !ok {
+		t.Errorf("expected error for undefined connection, got %v", issues)
+	}
+	if _, ok := findIssue(issues, `connection "main" for driver "postgres"`); !ok {
+		t.Errorf("expected error for connection of undefined driver, got %v", issues)
+	}
+}
+
+func TestValidate_Warnings(t *testing.T) {
+	long := strings.Repeat("word ", 50)
+	pres := parse(t, "# Same\n\n---\n\n# Same\n\n---\n\n<!-- fragments: true -->\n\n# Fragments\n\nNo list here\n\n---\n\n# Long\n\n"+long+"\n\n---\n\n<!-- layout: title -->\n")
+
+	v := New(nil)
+	v.SetMaxContentLength(100)
+	issues := v.Validate(pres, t.TempDir())
+
+	tests := []struct {
+		substr     string
+		slideIndex int
+	}{
+		{`duplicate title "Same" (same as slide 1)`, 1},
+		{"fragments directive has no pause markers", 2},
+		{"limit 100", 3},
+		{"slide is empty", 4},
+	}
+	for _, tt := range tests {
+		issue, ok := findIssue(issues, tt.substr)
+		if !ok {
+			t.Errorf("expected issue containing %q, got %v", tt.substr, issues)
+			continue
+		}
+		if issue.Severity != SeverityWarning || issue.SlideIndex != tt.slideIndex {
+			t.Errorf("issue %q: got severity %s on slide index %d, want warning on %d",
+				tt.substr, issue.Severity, issue.SlideIndex, tt.slideIndex)
+		}
+	}
+	if HasErrors(issues) {
+		t.Errorf("expected only warnings, got %v", issues)
+	}
+}
+
+func TestValidate_WideTable(t *testing.T) {
+	columns := "| A | B | C | D | E | F | G | H | I | J |\n" + strings.Repeat("|---", 10) + "|\n" + strings.Repeat("| x ", 10) + "|"
+	long := "| Option | Description |\n|---|---|\n| watch | " + strings.Repeat("Rebuilds the presentation whenever a file changes. ", 2) + "|"
+	pres := parse(t, "# Columns\n\n"+columns+"\n\n---\n\n# Long\n\n"+long+"\n\n---\n\n# Fits\n\n| A | B |\n|---|---|\n| 1 | 2 |")
+
+	issues := New(nil).Validate(pres, t.TempDir())
+	tests := []struct {
+		substr     string
+		slideIndex int
+	}{
+		{"table has 10 columns (limit 6)", 0},
+		{"table is about 112 characters wide (limit 90)", 1},
+	}
+	for _, tt := range tests {
+		issue, ok := findIssue(issues, tt.substr)
+		if !ok {
+			t.Errorf("expected issue containing %q, got %v", tt.substr, issues)
+			continue
+		}
+		if issue.Severity != SeverityWarning || issue.SlideIndex != tt.slideIndex {
+			t.Errorf("issue %q: got severity %s on slide index %d, want warning on %d",
+				tt.substr, issue.Severity, issue.SlideIndex, tt.slideIndex)
+		}
+	}
+	if len(issues) != 2 {
+		t.Errorf("expected only the two wide tables reported, got %v", issues)
+	}
+}
+
+func TestValidate_InvalidTransition(t *testing.T) {
+	pres := parse(t, "<!-- transition: slide-up 300ms -->\n\n# Valid\n\n---\n\n<!-- transition: {name: zoom, direction: left} -->\n\n# Invalid")
+
+	issues := New(nil).Validate(pres, t.TempDir())
+	issue, ok := findIssue(issues, `invalid transition "zoom-left"`)
+	if !ok {
+		t.Fatalf("expected issue for the invalid transition, got %v", issues)
+	}
+	if issue.Severity != SeverityWarning || issue.SlideIndex != 1 {
+		t.Errorf("got severity %s on slide index %d, want warning on 1", issue.Severity, issue.SlideIndex)
+	}
+	if _, ok := findIssue(issues, "slide-up"); ok {
+		t.Errorf("valid transition should not be reported, got %v", issues)
+	}
+}
+
+func TestValidate_InvalidClass(t *testing.T) {
+	pres := parse(t, "<!-- class: invert center -->\n\n# Valid\n\n---\n\n<!-- class: invert bad.name -->\n\n# Invalid")
+
+	issues := New(nil).Validate(pres, t.TempDir())
+	issue, ok := findIssue(issues, `invalid class "bad.name"`)
+	if !ok {
+		t.Fatalf("expected issue for the invalid class, got %v", issues)
+	}
+	if issue.Severity != SeverityWarning || issue.SlideIndex != 1 {
+		t.Errorf("got severity %s on slide index %d, want warning on 1", issue.Severity, issue.SlideIndex)
+	}
+	if _, ok := findIssue(issues, "invert"); ok {
+		t.Errorf("valid classes should not be reported, got %v", issues)
+	}
+}
+
+func TestValidate_InvalidAutoAdvance(t *testing.T) {
+	pres := parse(t, "<!-- autoAdvance: 8s -->\n\n# Valid\n\n---\n\n<!-- autoAdvance: 0 -->\n\n# Invalid")
+
+	issues := New(nil).Validate(pres, t.TempDir())
+	issue, ok := findIssue(issues, `invalid autoAdvance "0"`)
+	if !ok {
+		t.Fatalf("expected issue for the invalid autoAdvance, got %v", issues)
+	}
+	if issue.Severity != SeverityWarning || issue.SlideIndex != 1 {
+		t.Errorf("got severity %s on slide index %d, want warning on 1", issue.Severity, issue.SlideIndex)
+	}
+	if _, ok := findIssue(issues, `"8s"`); ok {
+		t.Errorf("valid autoAdvance should not be reported, got %v", issues)
+	}
+}
+
+func TestValidate_InvalidEmbed(t *testing.T) {
+	pres := parse(t, "<!-- embed: https://localhost:5173 -->\n\n# Valid\n\n---\n\n<!-- embed: file:///etc/passwd -->\n\n# Invalid")
+
+	issues := New(nil).Validate(pres, t.TempDir())
+	issue, ok := findIssue(issues, `embed url "file:///etc/passwd" must be an http or https url`)
+	if !ok {
+		t.Fatalf("expected issue for the invalid embed, got %v", issues)
+	}
+	if issue.Severity != SeverityError || issue.SlideIndex != 1 {
+		t.Errorf("got severity %s on slide index %d, want error on 1", issue.Severity, issue.SlideIndex)
+	}
+	if _, ok := findIssue(issues, "localhost"); ok {
+		t.Errorf("valid embed should not be reported, got %v", issues)
+	}
+}
+
+func TestIssueString(t *testing.T) {
+	issue := Issue{Severity: SeverityError, Message: "image a.png not found", SlideIndex: 2}
+	if got := issue.String(); got != "slide 3: image a.png not found" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestValidate_MissingTranslations(t *testing.T) {
+	pres := parse(t, "# Hello\n\n:::lang en\nWelcome\n:::\n\n:::lang da\nVelkommen\n:::\n\n---\n\n"+
+		"# Agenda\n\n:::lang en\nToday\n:::\n\n---\n\n"+
+		"<!-- lang: en -->\n\n# Thanks\n\n---\n\n<!-- lang: da -->\n\n# Tak\n\n---\n\n"+
+		"<!-- lang: de -->\n\n# Danke\n\n---\n\n# Code\n")
+
+	issues := New(nil).Validate(pres, t.TempDir())
+	issue, ok := findIssue(issues, "missing translation for da")
+	if !ok {
+		t.Fatalf("expected missing translation warning, got %v", issues)
+	}
+	if issue.Severity != SeverityWarning || issue.SlideIndex != 1 {
+		t.Errorf("got severity %s on slide index %d, want warning on 1", issue.Severity, issue.SlideIndex)
+	}
+	// Slides 0 and 1 lack German, and slide 1 Danish. The slides with lang
+	// directives are one slide in all three languages.
+	var missing []string
+	for _, issue := range issues {
+		if strings.Contains(issue.Message, "missing translation") {
+			missing = append(missing, fmt.Sprintf("%d: %s", issue.SlideIndex, issue.Message))
+		}
+	}
+	if len(missing) != 3 {
+		t.Errorf("got %d missing translation warnings, want 3: %v", len(missing), missing)
+	}
+}
+
+func TestValidate_UndeclaredLanguage(t *testing.T) {
+	pres := parse(t, "# Hello\n\n:::lang en\nWelcome\n:::\n\n:::lang fr\nBienvenue\n:::\n")
+	cfg := config.DefaultConfig()
+	cfg.Languages = []string{"en", "da"}
+
+	issues := New(cfg).Validate(pres, t.TempDir())
+	if _, ok := findIssue(issues, `language "fr" is not one of the languages option (en, da)`); !ok {
+		t.Errorf("expected undeclared language warning, got %v", issues)
+	}
+	if _, ok := findIssue(issues, "missing translation for da"); !ok {
+		t.Errorf("expected missing translation warning for a declared language, got %v", issues)
+	}
+}
+
+func TestValidate_UndeclaredLanguageWarnedOnce(t *testing.T) {
+	pres := parse(t, "<!-- lang: fr -->\n\n# Bonjour\n\n:::lang fr\nBienvenue\n:::\n")
+	cfg := config.DefaultConfig()
+	cfg.Languages = []string{"en"}
+
+	var warnings int
+	for _, issue := range New(cfg).Validate(pres, t.TempDir()) {
+		if strings.Contains(issue.Message, `language "fr" is not one of the languages option`) {
+			warnings++
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("got %d undeclared language warnings for fr, want 1", warnings)
+	}
+}
+
+func TestValidate_BrokenAnchor(t *testing.T) {
+	pres := parse(t, "# Intro\n\n[benchmarks](#benchmarks)\n\n---\n\n## Benchmarks\n\n[results](#results)")
+
+	issues := New(nil).Validate(pres, t.TempDir())
+	issue, ok := findIssue(issues, "link to #results does not match any heading")
+	if !ok {
+		t.Fatalf("expected issue for the broken anchor, got %v", issues)
+	}
+	if issue.Severity != SeverityWarning || issue.SlideIndex != 1 {
+		t.Errorf("got severity %s on slide index %d, want warning on 1", issue.Severity, issue.SlideIndex)
+	}
+	if _, ok := findIssue(issues, "#benchmarks"); ok {
+		t.Errorf("link to an existing heading should not be reported, got %v", issues)
+	}
+}
+
+func TestIssueJSON(t *testing.T) {
+	data, err := json.Marshal(Issue{Severity: SeverityError, Message: "image a.png not found", SlideIndex: 2})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	// Field names are part of the JSON output of tap lint --json
+	want := `{"severity":"error","message":"image a.png not found","slideIndex":2}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,449 @@
+// Package watcher provides debounced file system watching for multiple files and directories.
+package watcher
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// DefaultDebounce is the default window in which successive changes are coalesced.
+const DefaultDebounce = 150 * time.Millisecond
+
+// AssetDirThreshold is the number of assets in one directory above which
+// SetAssets watches the directory as a whole instead of each file.
+const AssetDirThreshold = 50
+
+// Event describes one logical change, which may span several file system events.
+type Event struct {
+	// Path is the most recently changed path.
+	Path string
+	// Paths contains every distinct path changed within the debounce window, in order.
+	Paths []string
+}
+
+// Watcher watches a set of files and directories and emits debounced change events.
+//
+// Files are watched through their parent directory, so files that are replaced
+// by editors using atomic writes (write temp file, rename over original) keep
+// being watched. Directories are watched recursively, skipping hidden
+// directories and node_modules.
+type Watcher struct {
+	// Fields ordered by size for better memory alignment
+	fsWatcher *fsnotify.Watcher
+	events    chan Event
+	stopCh    chan struct{}
+	doneCh    chan struct{}
+	files     map[string]struct{} // Watched files (absolute paths)
+	roots     map[string][]string // Watched directory roots and the directories added for them
+	assets    map[string]struct{} // Asset files set with SetAssets
+	assetDirs map[string]struct{} // Directories watched as a whole for SetAssets
+	dirRefs   map[string]int      // Reference counts for directories added to fsnotify
+	mu        sync.Mutex
+	debounce  time.Duration
+	closed    bool
+}
+
+// New creates a watcher for the given files and directories and starts watching.
+// Call Close to release resources.
+func New(paths ...string) (*Watcher, error) {
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	w := &Watcher{
+		fsWatcher: fsWatcher,
+		events:    make(chan Event, 16),
+		stopCh:    make(chan struct{}),
+		doneCh:    make(chan struct{}),
+		files:     make(map[string]struct{}),
+		roots:     make(map[string][]string),
+		assets:    make(map[string]struct{}),
+		assetDirs: make(map[string]struct{}),
+		dirRefs:   make(map[string]int),
+		debounce:  DefaultDebounce,
+	}
+
+	for _, path := range paths {
+		if err := w.AddPath(path); err != nil {
+			fsWatcher.Close()
+			return nil, err
+		}
+	}
+
+	go w.run()
+	return w, nil
+}
+
+// Events returns the channel of debounced change events.
+// The channel is closed when the watcher is closed.
+func (w *Watcher) Events() <-chan Event {
+	return w.events
+}
+
+// SetDebounce sets the window in which successive changes are coalesced into one event.
+// Default is 150ms.
+func (w *Watcher) SetDebounce(d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.debounce = d
+}
+
+// AddPath starts watching a file or directory. Adding a path that is already
+// watched is a no-op.
+func (w *Watcher) AddPath(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+
+	info, err := os.Stat(absPath)
+	if err != nil {
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return fmt.Errorf("watcher is closed")
+	}
+
+	if !info.IsDir() {
+		if _, exists := w.files[absPath]; exists {
+			return nil
+		}
+		if err := w.addDirLocked(filepath.Dir(absPath)); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		w.files[absPath] = struct{}{}
+		return nil
+	}
+
+	if _, exists := w.roots[absPath]; exists {
+		return nil
+	}
+
+	var added []string
+	err = filepath.WalkDir(absPath, func(p string, d os.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if p != absPath && skipDir(d.Name()) {
+			return filepath.SkipDir
+		}
+		if err := w.addDirLocked(p); err != nil {
+			return err
+		}
+		added = append(added, p)
+		return nil
+	})
+	if err != nil {
+		for _, dir := range added {
+			w.removeDirLocked(dir)
+		}
+		return fmt.Errorf("failed to watch %s: %w", path, err)
+	}
+	w.roots[absPath] = added
+	return nil
+}
+
+// Remove stops watching a file or directory previously added with New or AddPath.
+func (w *Watcher) Remove(path string) error {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %s: %w", path, err)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.files[absPath]; exists {
+		delete(w.files, absPath)
+		w.removeDirLocked(filepath.Dir(absPath))
+		return nil
+	}
+
+	if dirs, exists := w.roots[absPath]; exists {
+		delete(w.roots, absPath)
+		for _, dir := range dirs {
+			w.removeDirLocked(dir)
+		}
+		return nil
+	}
+
+	return fmt.Errorf("path is not watched: %s", path)
+}
+
+// SetAssets replaces the asset files being watched, such as the images and
+// stylesheets a presentation references, which change as it is edited. Unlike
+// AddPath, assets don't need to exist: a missing asset is watched through its
+// directory and reported when it is created, so deleting and recreating an
+// asset keeps working. Assets in directories that don't exist are skipped.
+// When more than AssetDirThreshold assets are in one directory, any change in
+// the directory is reported instead of keeping track of each file.
+func (w *Watcher) SetAssets(paths []string) error {
+	byDir := make(map[string][]string)
+	for _, path := range paths {
+		absPath, err := filepath.Abs(path)
+		if err != nil {
+			return fmt.Errorf("failed to resolve path %s: %w", path, err)
+		}
+		dir := filepath.Dir(absPath)
+		byDir[dir] = append(byDir[dir], absPath)
+	}
+
+	assets := make(map[string]struct{})
+	assetDirs := make(map[string]struct{})
+	for dir, files := range byDir {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			continue
+		}
+		if len(files) > AssetDirThreshold {
+			assetDirs[dir] = struct{}{}
+			continue
+		}
+		for _, file := range files {
+			assets[file] = struct{}{}
+		}
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return fmt.Errorf("watcher is closed")
+	}
+
+	// Add the new directories before releasing the old ones, so directories
+	// in both sets stay watched
+	var errs []error
+	for file := range assets {
+		if _, exists := w.assets[file]; exists {
+			continue
+		}
+		if err := w.addDirLocked(filepath.Dir(file)); err != nil {
+			errs = append(errs, fmt.Errorf("failed to watch %s: %w", file, err))
+			delete(assets, file)
+		}
+	}
+	for dir := range assetDirs {
+		if _, exists := w.assetDirs[dir]; exists {
+			continue
+		}
+		if err := w.addDirLocked(dir); err != nil {
+			errs = append(errs, fmt.Errorf("failed to watch %s: %w", dir, err))
+			delete(assetDirs, dir)
+		}
+	}
+
+	for file := range w.assets {
+		if _, exists := assets[file]; !exists {
+			w.removeDirLocked(filepath.Dir(file))
+		}
+	}
+	for dir := range w.assetDirs {
+		if _, exists := assetDirs[dir]; !exists {
+			w.removeDirLocked(dir)
+		}
+	}
+
+	w.assets = assets
+	w.assetDirs = assetDirs
+	return errors.Join(errs...)
+}
+
+// Paths returns the files and directory roots currently being watched.
+func (w *Watcher) Paths() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	paths := make([]string, 0, len(w.files)+len(w.roots))
+	for path := range w.files {
+		paths = append(paths, path)
+	}
+	for path := range w.roots {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Close stops the watcher and closes the events channel.
+func (w *Watcher) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+
+	close(w.stopCh)
+	<-w.doneCh
+
+	return w.fsWatcher.Close()
+}
+
+// addDirLocked adds a directory to the underlying watcher, reference counting
+// directories shared between several watched paths. Caller must hold w.mu.
+func (w *Watcher) addDirLocked(dir string) error {
+	if w.dirRefs[dir] == 0 {
+		if err := w.fsWatcher.Add(dir); err != nil {
+			return err
+		}
+	}
+	w.dirRefs[dir]++
+	return nil
+}
+
+// removeDirLocked releases a reference to a directory and stops watching it
+// when no watched path needs it anymore. Caller must hold w.mu.
+func (w *Watcher) removeDirLocked(dir string) {
+	if w.dirRefs[dir] == 0 {
+		return
+	}
+	w.dirRefs[dir]--
+	if w.dirRefs[dir] == 0 {
+		delete(w.dirRefs, dir)
+		_ = w.fsWatcher.Remove(dir)
+	}
+}
+
+// skipDir reports whether a directory should be skipped when watching recursively.
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "node_modules"
+}
+
+// rootFor returns the watched directory root containing path, or "" if none.
+// Caller must hold w.mu.
+func (w *Watcher) rootFor(path string) string {
+	for root := range w.roots {
+		if strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return root
+		}
+	}
+	return ""
+}
+
+// relevant reports whether a file system event affects a watched path.
+// New directories created inside a watched root are added to the watcher.
+func (w *Watcher) relevant(event fsnotify.Event) bool {
+	// Attribute-only changes don't affect content
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if _, exists := w.files[event.Name]; exists {
+		return true
+	}
+	if _, exists := w.assets[event.Name]; exists {
+		return true
+	}
+	if _, exists := w.assetDirs[filepath.Dir(event.Name)]; exists && !isTempFile(filepath.Base(event.Name)) {
+		return true
+	}
+
+	root := w.rootFor(event.Name)
+	if root == "" {
+		return false
+	}
+
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Stat(event.Name); err == nil && info.IsDir() && !skipDir(filepath.Base(event.Name)) {
+			if err := w.addDirLocked(event.Name); err == nil {
+				w.roots[root] = append(w.roots[root], event.Name)
+			}
+		}
+	}
+
+	return !isTempFile(filepath.Base(event.Name))
+}
+
+// isTempFile reports whether a file name looks like an editor swap or backup file.
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") || name == "4913"
+}
+
+// run is the main watch loop that coalesces events within the debounce window.
+func (w *Watcher) run() {
+	defer close(w.doneCh)
+	defer close(w.events)
+
+	var (
+		timer   *time.Timer
+		timerCh <-chan time.Time
+		pending []string
+	)
+
+	for {
+		select {
+		case <-w.stopCh:
+			if timer != nil {
+				timer.Stop()
+			}
+			return
+
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if !w.relevant(event) {
+				continue
+			}
+
+			pending = appendUnique(pending, event.Name)
+
+			// Debounce: restart the window on each event
+			w.mu.Lock()
+			debounce := w.debounce
+			w.mu.Unlock()
+
+			if timer != nil {
+				timer.Stop()
+			}
+			timer = time.NewTimer(debounce)
+			timerCh = timer.C
+
+		case <-timerCh:
+			timerCh = nil
+			if len(pending) == 0 {
+				continue
+			}
+			ev := Event{Path: pending[len(pending)-1], Paths: pending}
+			pending = nil
+
+			select {
+			case w.events <- ev:
+			case <-w.stopCh:
+				return
+			}
+
+		case _, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			// Ignore errors and continue watching
+		}
+	}
+}
+
+// appendUnique appends path to paths, moving it to the end if already present.
+func appendUnique(paths []string, path string) []string {
+	for i, p := range paths {
+		if p == path {
+			paths = append(paths[:i], paths[i+1:]...)
+			break
+		}
+	}
+	return append(paths, path)
+}
@@ -0,0 +1,470 @@
+package watcher
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitForEvent waits for the next event or fails after the timeout.
+func waitForEvent(t *testing.T, w *Watcher, timeout time.Duration) Event {
+	t.Helper()
+	select {
+	case ev, ok := <-w.Events():
+		if !ok {
+			t.Fatal("events channel closed unexpectedly")
+		}
+		return ev
+	case <-time.After(timeout):
+		t.Fatal("timed out waiting for event")
+	}
+	return Event{}
+}
+
+// expectNoEvent fails if an event arrives within the given duration.
+func expectNoEvent(t *testing.T, w *Watcher, d time.Duration) {
+	t.Helper()
+	select {
+	case ev := <-w.Events():
+		t.Fatalf("unexpected event: %+v", ev)
+	case <-time.After(d):
+	}
+}
+
+func TestNew_MissingPath(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.md"))
+	if err == nil {
+		t.Error("expected error for missing path")
+	}
+}
+
+func TestWatcher_FileWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(mdFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(mdFile, []byte("# Changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ev := waitForEvent(t, w, 2*time.Second)
+	if ev.Path != mdFile {
+		t.Errorf("Path = %q, want %q", ev.Path, mdFile)
+	}
+}
+
+func TestWatcher_IgnoresUnwatchedSiblings(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(mdFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+	w.SetDebounce(20 * time.Millisecond)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "other.md"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	expectNoEvent(t, w, 200*time.Millisecond)
+}
+
+func TestWatcher_CoalescesRapidWrites(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(mdFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := os.WriteFile(mdFile, []byte("# Change"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	waitForEvent(t, w, 2*time.Second)
+	expectNoEvent(t, w, 300*time.Millisecond)
+}
+
+func TestWatcher_AtomicWrite(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(mdFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	// Simulate a vim-style save: back up, write a new file, remove the backup
+	atomicWrite := func(content string) {
+		backup := mdFile + "~"
+		if err := os.Rename(mdFile, backup); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(mdFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Remove(backup); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	atomicWrite("# First save")
+	ev := waitForEvent(t, w, 2*time.Second)
+	if ev.Path != mdFile {
+		t.Errorf("Path = %q, want %q", ev.Path, mdFile)
+	}
+	expectNoEvent(t, w, 300*time.Millisecond)
+
+	// The recreated file must still be watched
+	atomicWrite("# Second save")
+	ev = waitForEvent(t, w, 2*time.Second)
+	if ev.Path != mdFile {
+		t.Errorf("Path = %q, want %q", ev.Path, mdFile)
+	}
+}
+
+func TestWatcher_DirectoryRecursive(t *testing.T) {
+	tmpDir := t.TempDir()
+	imagesDir := filepath.Join(tmpDir, "images")
+	nested := filepath.Join(imagesDir, "nested")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(imagesDir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	imgFile := filepath.Join(nested, "photo.png")
+	if err := os.WriteFile(imgFile, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ev := waitForEvent(t, w, 2*time.Second)
+	if ev.Path != imgFile {
+		t.Errorf("Path = %q, want %q", ev.Path, imgFile)
+	}
+}
+
+func TestWatcher_AddPathAndRemove(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	themeFile := filepath.Join(tmpDir, "theme.css")
+	for _, f := range []string{mdFile, themeFile} {
+		if err := os.WriteFile(f, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := New(mdFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+	w.SetDebounce(20 * time.Millisecond)
+
+	if err := w.AddPath(themeFile); err != nil {
+		t.Fatalf("AddPath() error = %v", err)
+	}
+	if len(w.Paths()) != 2 {
+		t.Errorf("Paths() = %v, want 2 paths", w.Paths())
+	}
+
+	if err := os.WriteFile(themeFile, []byte("body{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ev := waitForEvent(t, w, 2*time.Second)
+	if ev.Path != themeFile {
+		t.Errorf("Path = %q, want %q", ev.Path, themeFile)
+	}
+
+	if err := w.Remove(themeFile); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := os.WriteFile(themeFile, []byte("body{color:red}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectNoEvent(t, w, 200*time.Millisecond)
+
+	// The markdown file shares the directory and must still be watched
+	if err := os.WriteFile(mdFile, []byte("# Changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ev = waitForEvent(t, w, 2*time.Second)
+	if ev.Path != mdFile {
+		t.Errorf("Path = %q, want %q", ev.Path, mdFile)
+	}
+
+	if err := w.Remove(themeFile); err == nil {
+		t.Error("expected error removing a path that is not watched")
+	}
+}
+
+func TestWatcher_MultiplePathsInOneEvent(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	themeFile := filepath.Join(tmpDir, "theme.css")
+	for _, f := range []string{mdFile, themeFile} {
+		if err := os.WriteFile(f, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := New(mdFile, themeFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(mdFile, []byte("a"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(themeFile, []byte("b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ev := waitForEvent(t, w, 2*time.Second)
+	if ev.Path != themeFile {
+		t.Errorf("Path = %q, want %q", ev.Path, themeFile)
+	}
+	if len(ev.Paths) != 2 || ev.Paths[0] != mdFile {
+		t.Errorf("Paths = %v, want [%s %s]", ev.Paths, mdFile, themeFile)
+	}
+}
+
+func TestWatcher_CloseClosesEvents(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	w, err := New(tmpDir)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if _, ok := <-w.Events(); ok {
+		t.Error("expected events channel to be closed")
+	}
+
+	// Closing twice is a no-op
+	if err := w.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+	if err := w.AddPath(tmpDir); err == nil {
+		t.Error("expected AddPath to fail after Close")
+	}
+}
+
+func TestIsTempFile(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"slides.md", false},
+		{".slides.md.swp", true},
+		{"slides.md~", true},
+		{"4913", true},
+	}
+
+	for _, tt := range tests {
+		if got := isTempFile(tt.name); got != tt.want {
+			t.Errorf("isTempFile(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWatcher_DeleteAndRecreate(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(mdFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+
+	if err := os.Remove(mdFile); err != nil {
+		t.Fatal(err)
+	}
+	ev := waitForEvent(t, w, 2*time.Second)
+	if ev.Path != mdFile {
+		t.Errorf("Path = %q, want %q", ev.Path, mdFile)
+	}
+
+	// The file is still watched after it reappears
+	if err := os.WriteFile(mdFile, []byte("# Restored"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ev = waitForEvent(t, w, 2*time.Second)
+	if ev.Path != mdFile {
+		t.Errorf("Path = %q, want %q", ev.Path, mdFile)
+	}
+}
+
+func TestWatcher_SetAssets(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	cssFile := filepath.Join(tmpDir, "styles", "custom.css")
+	dataFile := filepath.Join(tmpDir, "sales.csv")
+	for _, path := range []string{mdFile, cssFile, dataFile} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w, err := New(mdFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+	w.SetDebounce(20 * time.Millisecond)
+
+	if err := w.SetAssets([]string{cssFile, dataFile}); err != nil {
+		t.Fatalf("SetAssets() error = %v", err)
+	}
+
+	if err := os.WriteFile(cssFile, []byte("body {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ev := waitForEvent(t, w, 2*time.Second); ev.Path != cssFile {
+		t.Errorf("Path = %q, want %q", ev.Path, cssFile)
+	}
+
+	// Deleting and recreating an asset keeps it watched
+	if err := os.Remove(dataFile); err != nil {
+		t.Fatal(err)
+	}
+	waitForEvent(t, w, 2*time.Second)
+	if err := os.WriteFile(dataFile, []byte("a,b"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ev := waitForEvent(t, w, 2*time.Second); ev.Path != dataFile {
+		t.Errorf("Path = %q, want %q", ev.Path, dataFile)
+	}
+
+	// Assets that are no longer referenced stop being watched, but the
+	// markdown file in the same directory still is
+	if err := w.SetAssets([]string{dataFile}); err != nil {
+		t.Fatalf("SetAssets() error = %v", err)
+	}
+	if err := os.WriteFile(cssFile, []byte("body { color: red }"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectNoEvent(t, w, 200*time.Millisecond)
+
+	if err := w.SetAssets(nil); err != nil {
+		t.Fatalf("SetAssets() error = %v", err)
+	}
+	if err := os.WriteFile(mdFile, []byte("# Changed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ev := waitForEvent(t, w, 2*time.Second); ev.Path != mdFile {
+		t.Errorf("Path = %q, want %q", ev.Path, mdFile)
+	}
+}
+
+func TestWatcher_SetAssetsMissingFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(mdFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+	w.SetDebounce(20 * time.Millisecond)
+
+	// An asset that doesn't exist yet is reported when it's created; one in
+	// a missing directory is skipped
+	logo := filepath.Join(tmpDir, "logo.png")
+	if err := w.SetAssets([]string{logo, filepath.Join(tmpDir, "missing", "chart.png")}); err != nil {
+		t.Fatalf("SetAssets() error = %v", err)
+	}
+	if err := os.WriteFile(logo, []byte("png"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ev := waitForEvent(t, w, 2*time.Second); ev.Path != logo {
+		t.Errorf("Path = %q, want %q", ev.Path, logo)
+	}
+}
+
+func TestWatcher_SetAssetsDirectoryThreshold(t *testing.T) {
+	tmpDir := t.TempDir()
+	mdFile := filepath.Join(tmpDir, "slides.md")
+	if err := os.WriteFile(mdFile, []byte("# Test"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	photos := filepath.Join(tmpDir, "photos")
+	if err := os.Mkdir(photos, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := New(mdFile)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer w.Close()
+	w.SetDebounce(20 * time.Millisecond)
+
+	var assets []string
+	for i := 0; i <= AssetDirThreshold; i++ {
+		assets = append(assets, filepath.Join(photos, fmt.Sprintf("photo-%d.jpg", i)))
+	}
+	if err := w.SetAssets(assets); err != nil {
+		t.Fatalf("SetAssets() error = %v", err)
+	}
+
+	// The directory is watched as a whole, so any file in it is reported,
+	// except editor temp files
+	if err := os.WriteFile(filepath.Join(photos, ".photo.jpg.swp"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectNoEvent(t, w, 200*time.Millisecond)
+
+	other := filepath.Join(photos, "unreferenced.jpg")
+	if err := os.WriteFile(other, []byte("jpg"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if ev := waitForEvent(t, w, 2*time.Second); ev.Path != other {
+		t.Errorf("Path = %q, want %q", ev.Path, other)
+	}
+}